@@ -23,8 +23,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sliceutil"
@@ -86,10 +88,20 @@ var (
 )
 
 const (
-	ResourceNode    = "node"
-	ResourceCluster = "cluster"
-	ResourceUser    = "user"
-	ResourceRole    = "role"
+	ResourceNode        = "node"
+	ResourceCluster     = "cluster"
+	ResourceUser        = "user"
+	ResourceRole        = "role"
+	ResourceRoleBinding = "rolebinding"
+	ResourcePrecheck    = "precheck"
+	ResourceOperation   = "operation"
+	ResourceBackup      = "backup"
+	// ResourceDiscoveredNode is a machine of an inventory, which is not a node yet.
+	ResourceDiscoveredNode = "discoverednode"
+	// ResourceKubeconfig is a kubeconfig issued for a cluster, it is named after the cluster.
+	ResourceKubeconfig = "kubeconfig"
+	// ResourceEvent is an event recorded about a cluster, node, operation or backup.
+	ResourceEvent = "event"
 )
 
 type IOStreams struct {
@@ -155,6 +167,23 @@ type OpLog struct {
 	Threshold int    `json:"threshold" yaml:"threshold,omitempty"`
 }
 
+// ImageRegistry is the docker registry deployed on a kc node, air-gapped clusters pull images from it.
+type ImageRegistry struct {
+	// Node is the ip of the kc node running the registry, it is not deployed when empty.
+	Node     string `json:"node" yaml:"node,omitempty"`
+	Port     int    `json:"port" yaml:"port,omitempty"`
+	Volume   string `json:"volume" yaml:"volume,omitempty"`
+	DataRoot string `json:"dataRoot" yaml:"dataRoot,omitempty"`
+}
+
+// Address is the host:port images of the registry are tagged with.
+func (r *ImageRegistry) Address() string {
+	if r == nil || r.Node == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", r.Node, r.Port)
+}
+
 type DeployConfig struct {
 	Config           string        `json:"-" yaml:"-"`
 	SSHConfig        *sshutils.SSH `json:"ssh" yaml:"ssh,omitempty"`
@@ -171,6 +200,25 @@ type DeployConfig struct {
 	JWTSecret        string        `json:"jwtSecret" yaml:"jwtSecret,omitempty"`
 	MQ               *MQ           `json:"mq" yaml:"mq,omitempty"`
 	OpLog            *OpLog        `json:"opLog" yaml:"opLog,omitempty"`
+	// SystemdOverrides are rendered as drop-ins of the kc units on deploy and join.
+	SystemdOverrides *SystemdOverrides `json:"systemdOverrides" yaml:"systemdOverrides,omitempty"`
+	// WindowsAgentRegions are the windows agents, they are provisioned with PowerShell instead of systemd.
+	WindowsAgentRegions Agents `json:"windowsAgents" yaml:"windowsAgents,omitempty"`
+	// TrustedKeys are the cosign or minisign public keys the kc packages and the component resources
+	// must be signed with, signatures are not verified when it is empty.
+	TrustedKeys []string `json:"trustedKeys" yaml:"trustedKeys,omitempty"`
+	// TrustedKeyFiles are read into TrustedKeys on Complete.
+	TrustedKeyFiles []string `json:"-" yaml:"-"`
+	// SkipSignatureVerify installs packages without verifying them although trusted keys are configured.
+	SkipSignatureVerify bool `json:"-" yaml:"-"`
+	// NodeIDSource is what the ids of the agents are generated from, random by default. Ids derived from
+	// the machine keep the identity of re-provisioned nodes.
+	NodeIDSource string `json:"nodeIDSource" yaml:"nodeIDSource,omitempty"`
+	// ExternalIDs map the ips of agents to their ids in an external inventory such as a CMDB,
+	// the nodes are labeled with them.
+	ExternalIDs map[string]string `json:"externalIDs" yaml:"externalIDs,omitempty"`
+	// ImageRegistry is deployed along with the platform when its node is set.
+	ImageRegistry *ImageRegistry `json:"imageRegistry" yaml:"imageRegistry,omitempty"`
 }
 
 type Agents map[string][]string // key: region, value: ips
@@ -237,7 +285,13 @@ func NewDeployOptions() *DeployConfig {
 			Dir:       "/var/log/kc-agent",
 			Threshold: 1048576,
 		},
-		AgentRegions: make(Agents),
+		AgentRegions:        make(Agents),
+		WindowsAgentRegions: make(Agents),
+		ImageRegistry: &ImageRegistry{
+			Port:     5000,
+			Volume:   "/opt/registry",
+			DataRoot: "/var/lib/docker",
+		},
 	}
 }
 
@@ -247,25 +301,50 @@ func (c *DeployConfig) MergeDeployOptions() {
 	if c.OpLog == nil {
 		c.OpLog = d.OpLog
 	}
+	if c.ImageRegistry == nil {
+		c.ImageRegistry = d.ImageRegistry
+	}
 
 }
 
 func (c *DeployConfig) Complete() error {
-	if c.Config == "" {
-		return nil
+	if c.Config != "" {
+		if !utils.FileExist(c.Config) {
+			return fmt.Errorf("%s is not exist", c.Config)
+		}
+		data, err := os.ReadFile(c.Config)
+		if err != nil {
+			return err
+		}
+		bytes, err := Omitempty(data)
+		if err != nil {
+			return err
+		}
+		if err = yaml.Unmarshal(bytes, c); err != nil {
+			return err
+		}
 	}
-	if !utils.FileExist(c.Config) {
-		return fmt.Errorf("%s is not exist", c.Config)
+	for _, file := range c.TrustedKeyFiles {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("read trusted key: %v", err)
+		}
+		c.TrustedKeys = append(c.TrustedKeys, string(data))
 	}
-	data, err := os.ReadFile(c.Config)
-	if err != nil {
+	return nil
+}
+
+// ValidateNodeIdentity checks the node id source, and that the external ids can be label values.
+func (c *DeployConfig) ValidateNodeIdentity() error {
+	if err := utils.ValidateNodeIDSource(c.NodeIDSource); err != nil {
 		return err
 	}
-	bytes, err := Omitempty(data)
-	if err != nil {
-		return err
+	for ip, id := range c.ExternalIDs {
+		if errs := validation.IsValidLabelValue(id); len(errs) > 0 {
+			return fmt.Errorf("external id %q of %s: %s", id, ip, strings.Join(errs, "; "))
+		}
 	}
-	return yaml.Unmarshal(bytes, c)
+	return nil
 }
 
 // Omitempty use unmarshal+marshal to omit empty field.
@@ -302,6 +381,7 @@ func (c *DeployConfig) AddFlags(flags *pflag.FlagSet) {
 	flags.StringVarP(&c.Config, "deploy-config", "c", c.Config, "Path to the config file to use for Deploy.")
 	flags.BoolVar(&c.Debug, "debug", c.Debug, "Deploy kc use debug mode")
 	flags.StringVarP(&c.DefaultRegion, "region", "r", c.DefaultRegion, "Kc agent default region")
+	flags.StringVar(&c.NodeIDSource, "node-id-source", c.NodeIDSource, "What kc agent ids are generated from, one of random, machine-id and smbios-uuid")
 	flags.IntVar(&c.ServerPort, "server-port", c.ServerPort, "Kc server port")
 	flags.IntVar(&c.StaticServerPort, "static-server-port", c.StaticServerPort, "Kc static server port")
 	flags.StringVar(&c.StaticServerPath, "static-server-path", c.StaticServerPath, "Kc static server path(absolute path")
@@ -324,13 +404,25 @@ func (c *DeployConfig) AddFlags(flags *pflag.FlagSet) {
 	flags.IntVar(&c.ConsolePort, "console-port", c.ConsolePort, "kc console port")
 	flags.StringVar(&c.OpLog.Dir, "oplog-dir", c.OpLog.Dir, "kc agent operation log dir")
 	flags.IntVar(&c.OpLog.Threshold, "oplog-threshold", c.OpLog.Threshold, "kc agent operation log single threshold")
+	flags.StringArrayVar(&c.TrustedKeyFiles, "trusted-key", c.TrustedKeyFiles, "Cosign or minisign public key file which packages must be signed with, can be repeated")
+	flags.StringVar(&c.ImageRegistry.Node, "image-registry", c.ImageRegistry.Node, "Kc node ip to deploy the built-in image registry on, it is not deployed if empty")
+	flags.IntVar(&c.ImageRegistry.Port, "image-registry-port", c.ImageRegistry.Port, "Built-in image registry port")
+	flags.StringVar(&c.ImageRegistry.Volume, "image-registry-volume", c.ImageRegistry.Volume, "Built-in image registry volume path(absolute path)")
+	c.AddSignatureFlags(flags)
 
 	AddFlagsToSSH(c.SSHConfig, flags)
 }
 
+// AddSignatureFlags adds the flags of commands installing packages verified with the trusted keys.
+func (c *DeployConfig) AddSignatureFlags(flags *pflag.FlagSet) {
+	flags.BoolVar(&c.SkipSignatureVerify, "skip-signature-verify", c.SkipSignatureVerify, "Install packages without verifying their signatures against the trusted keys")
+}
+
 func AddFlagsToSSH(ssh *sshutils.SSH, flags *pflag.FlagSet) {
 	flags.StringVarP(&ssh.User, "user", "u", ssh.User, "Deploy ssh user")
 	flags.StringVar(&ssh.Password, "passwd", ssh.Password, "Deploy ssh password")
 	flags.StringVar(&ssh.PkFile, "pk-file", ssh.PkFile, "ssh pk file which used to remote access other agent nodes")
 	flags.StringVar(&ssh.PkPassword, "pk-passwd", ssh.PkPassword, "the password of the ssh pk file which used to remote access other agent nodes")
+	flags.StringVar(&ssh.Transfer, "ssh-transfer", ssh.Transfer, "the way files are sent to hosts, one of auto, pipeline, sftp and rsync. "+
+		"auto probes every host and falls back to rsync or sftp when shell pipelines are restricted")
 }
@@ -0,0 +1,146 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package options
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+const (
+	UnitKcServer = "kc-server"
+	UnitKcAgent  = "kc-agent"
+	UnitKcEtcd   = "kc-etcd"
+
+	systemdDropInDir  = "/etc/systemd/system"
+	systemdDropInFile = "10-kcctl-override.conf"
+)
+
+// SystemdOverrides holds the drop-in overrides of the kc service units.
+type SystemdOverrides struct {
+	KcServer *UnitOverride `json:"kcServer" yaml:"kcServer,omitempty"`
+	KcAgent  *UnitOverride `json:"kcAgent" yaml:"kcAgent,omitempty"`
+	KcEtcd   *UnitOverride `json:"kcEtcd" yaml:"kcEtcd,omitempty"`
+}
+
+// UnitOverride is rendered into the [Service] section of a drop-in.
+type UnitOverride struct {
+	// Environment sets environment variables, e.g. HTTP_PROXY and NO_PROXY.
+	Environment map[string]string `json:"environment" yaml:"environment,omitempty"`
+	// Service holds raw directives such as LimitNOFILE=65535 or MemoryMax=2G.
+	Service []string `json:"service" yaml:"service,omitempty"`
+}
+
+func (s *SystemdOverrides) unit(name string) *UnitOverride {
+	if s == nil {
+		return nil
+	}
+	switch name {
+	case UnitKcServer:
+		return s.KcServer
+	case UnitKcAgent:
+		return s.KcAgent
+	case UnitKcEtcd:
+		return s.KcEtcd
+	}
+	return nil
+}
+
+func (s *SystemdOverrides) Validate() error {
+	for _, name := range []string{UnitKcServer, UnitKcAgent, UnitKcEtcd} {
+		if err := s.unit(name).validate(); err != nil {
+			return fmt.Errorf("systemd override of %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+func (o *UnitOverride) validate() error {
+	if o == nil {
+		return nil
+	}
+	for k, v := range o.Environment {
+		if k == "" || strings.ContainsAny(k, "= ") {
+			return fmt.Errorf("invalid environment variable name %q", k)
+		}
+		if err := checkDropInValue(v); err != nil {
+			return fmt.Errorf("environment %s: %v", k, err)
+		}
+	}
+	for _, line := range o.Service {
+		if key, _, ok := strings.Cut(line, "="); !ok || strings.TrimSpace(key) == "" {
+			return fmt.Errorf("service directive %q must be in the form KEY=VALUE", line)
+		}
+		if err := checkDropInValue(line); err != nil {
+			return fmt.Errorf("service directive %q: %v", line, err)
+		}
+	}
+	return nil
+}
+
+// checkDropInValue rejects what can not pass through the shell command writing the drop-in.
+func checkDropInValue(v string) error {
+	if strings.ContainsAny(v, "'`$\\\n\"") {
+		return fmt.Errorf("must not contain quotes, backslashes, '$', '`' or line breaks")
+	}
+	return nil
+}
+
+// DropIn renders the drop-in of the unit, it is empty when the unit has no override.
+func (s *SystemdOverrides) DropIn(unit string) string {
+	o := s.unit(unit)
+	if o == nil || (len(o.Environment) == 0 && len(o.Service) == 0) {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("# generated by kcctl from the systemdOverrides of deploy-config\n[Service]\n")
+	keys := make([]string, 0, len(o.Environment))
+	for k := range o.Environment {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "Environment=\"%s=%s\"\n", k, o.Environment[k])
+	}
+	for _, line := range o.Service {
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// SystemdDropInDir returns the drop-in directory of the unit.
+func SystemdDropInDir(unit string) string {
+	return filepath.Join(systemdDropInDir, unit+".service.d")
+}
+
+// DropInCmd returns the command installing the drop-in of the unit. Without an
+// override it removes the drop-in an earlier deploy may have left.
+func (s *SystemdOverrides) DropInCmd(unit string) string {
+	file := filepath.Join(SystemdDropInDir(unit), systemdDropInFile)
+	content := s.DropIn(unit)
+	if content == "" {
+		return fmt.Sprintf("rm -f %s", file)
+	}
+	return fmt.Sprintf("mkdir -p %s && %s", SystemdDropInDir(unit), sshutils.WrapEcho(content, file))
+}
@@ -0,0 +1,67 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package options
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSystemdOverrides(t *testing.T) {
+	var none *SystemdOverrides
+	if err := none.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if cmd := none.DropInCmd(UnitKcAgent); cmd != "rm -f /etc/systemd/system/kc-agent.service.d/10-kcctl-override.conf" {
+		t.Errorf("unexpected command without override: %s", cmd)
+	}
+
+	s := &SystemdOverrides{
+		KcAgent: &UnitOverride{
+			Environment: map[string]string{"NO_PROXY": "127.0.0.1,10.0.0.0/8", "HTTP_PROXY": "http://proxy:3128"},
+			Service:     []string{"LimitNOFILE=65535", "MemoryMax=2G"},
+		},
+	}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := `[Service]
+Environment="HTTP_PROXY=http://proxy:3128"
+Environment="NO_PROXY=127.0.0.1,10.0.0.0/8"
+LimitNOFILE=65535
+MemoryMax=2G
+`
+	if got := s.DropIn(UnitKcAgent); !strings.HasSuffix(got, want) {
+		t.Errorf("unexpected drop-in:\n%s", got)
+	}
+	if s.DropIn(UnitKcServer) != "" {
+		t.Error("units without override must not get a drop-in")
+	}
+
+	for _, o := range []*UnitOverride{
+		{Environment: map[string]string{"A": "$(reboot)"}},
+		{Environment: map[string]string{"A B": "x"}},
+		{Service: []string{"LimitNOFILE"}},
+		{Service: []string{"ExecStartPre=/bin/sh -c 'rm -rf /'"}},
+	} {
+		if err := (&SystemdOverrides{KcEtcd: o}).Validate(); err == nil {
+			t.Errorf("expected %+v to be rejected", o)
+		}
+	}
+}
@@ -21,15 +21,20 @@ package app
 import (
 	"io"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/apply"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/i18n"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/resource"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/restoredeleted"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/registry"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/drain"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/export"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/importer"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/join"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/create"
@@ -39,10 +44,18 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/cli/delete"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/deploy"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/describe"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/get"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/login"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logout"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logs"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/passwd"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/patch"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/preference"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/proxy"
 
 	"github.com/spf13/cobra"
 
@@ -70,6 +83,7 @@ func NewKubeClipperCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 	cmds.ResetFlags()
 	cmds.CompletionOptions.DisableDefaultCmd = true
 	logger.AddFlags(cmds.PersistentFlags())
+	i18n.AddFlags(cmds.PersistentFlags())
 	cmds.PersistentFlags().BoolVarP(&options.AssumeYes, "assumeyes", "y", false, "Assume yes; assume that the answer to any question which would be asked is yes.")
 
 	ioStreams := options.IOStreams{
@@ -80,12 +94,24 @@ func NewKubeClipperCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 	cmds.AddCommand(deploy.NewCmdDeploy(ioStreams))
 	cmds.AddCommand(clean.NewCmdClean(ioStreams))
 	cmds.AddCommand(login.NewCmdLogin(ioStreams))
+	cmds.AddCommand(logout.NewCmdLogout(ioStreams))
+	cmds.AddCommand(passwd.NewCmdPasswd(ioStreams))
+	cmds.AddCommand(preference.NewCmdPreference(ioStreams))
 	cmds.AddCommand(get.NewCmdGet(ioStreams))
+	cmds.AddCommand(describe.NewCmdDescribe(ioStreams))
 	cmds.AddCommand(create.NewCmdCreate(ioStreams))
+	cmds.AddCommand(apply.NewCmdApply(ioStreams))
 	cmds.AddCommand(delete.NewCmdDelete(ioStreams))
+	cmds.AddCommand(restoredeleted.NewCmdRestoreDeleted(ioStreams))
+	cmds.AddCommand(patch.NewCmdPatch(ioStreams))
 	cmds.AddCommand(version.NewCmdVersion(ioStreams))
 	cmds.AddCommand(join.NewCmdJoin(ioStreams))
+	cmds.AddCommand(importer.NewCmdImport(ioStreams))
 	cmds.AddCommand(drain.NewCmdDrain(ioStreams))
+	cmds.AddCommand(export.NewCmdExport(ioStreams))
+	cmds.AddCommand(proxy.NewCmdProxy(ioStreams))
+	cmds.AddCommand(operation.NewCmdOperation(ioStreams))
+	cmds.AddCommand(logs.NewCmdLogs(ioStreams))
 	cmds.AddCommand(registry.NewCmdRegistry(ioStreams))
 	cmds.AddCommand(resource.NewCmdResource(ioStreams))
 	cmds.AddCommand(completion.NewCmdCompletion(ioStreams.Out))
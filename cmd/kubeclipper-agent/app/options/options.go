@@ -43,6 +43,10 @@ func (s *AgentOptions) Validate() []error {
 	errors = append(errors, s.GenericServerRunOptions.Validate()...)
 	errors = append(errors, s.LogOptions.Validate()...)
 	errors = append(errors, s.OpLogOptions.Validate()...)
+	errors = append(errors, s.ContainerExecutorOptions.Validate()...)
+	errors = append(errors, s.FaultInjectionOptions.Validate()...)
+	errors = append(errors, s.MetricsOptions.Validate()...)
+	errors = append(errors, s.TracingOptions.Validate()...)
 	return errors
 }
 
@@ -52,6 +56,10 @@ func (s *AgentOptions) Flags() (fss cliflag.NamedFlagSets) {
 	s.LogOptions.AddFlags(fss.FlagSet("log"))
 	s.MQOptions.AddFlags(fss.FlagSet("mq"))
 	s.OpLogOptions.AddFlags(fss.FlagSet("oplog"))
+	s.ContainerExecutorOptions.AddFlags(fss.FlagSet("container executor"))
+	s.FaultInjectionOptions.AddFlags(fss.FlagSet("fault injection"))
+	s.MetricsOptions.AddFlags(fss.FlagSet("metrics"))
+	s.TracingOptions.AddFlags(fss.FlagSet("tracing"))
 	return fss
 }
 
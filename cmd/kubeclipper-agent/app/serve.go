@@ -47,7 +47,9 @@ func newServeCommand(stopCh <-chan struct{}) *cobra.Command {
 			if errs := s.Validate(); len(errs) != 0 {
 				return utilerrors.NewAggregate(errs)
 			}
-			return Run(s, stopCh)
+			return runService(stopCh, func(stopCh <-chan struct{}) error {
+				return Run(s, stopCh)
+			})
 		},
 		SilenceUsage: true,
 	}
@@ -0,0 +1,88 @@
+//go:build windows
+// +build windows
+
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package app
+
+import (
+	"golang.org/x/sys/windows/svc"
+)
+
+// serviceName is the name kcctl join registers the agent service with.
+const serviceName = "kc-agent"
+
+// runService runs the agent under the service control manager when it is started as a
+// windows service, which stops the agent through control requests instead of signals.
+func runService(stopCh <-chan struct{}, run func(stopCh <-chan struct{}) error) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+	if !isService {
+		return run(stopCh)
+	}
+	h := &serviceHandler{stopCh: stopCh, run: run}
+	if err = svc.Run(serviceName, h); err != nil {
+		return err
+	}
+	return h.err
+}
+
+type serviceHandler struct {
+	stopCh <-chan struct{}
+	run    func(stopCh <-chan struct{}) error
+	err    error
+}
+
+func (h *serviceHandler) Execute(_ []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.StartPending}
+	stop := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- h.run(stop)
+	}()
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+
+	for {
+		select {
+		case r := <-requests:
+			switch r.Cmd {
+			case svc.Interrogate:
+				status <- r.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				close(stop)
+				h.err = <-done
+				return false, 0
+			}
+		case <-h.stopCh:
+			status <- svc.Status{State: svc.StopPending}
+			close(stop)
+			h.err = <-done
+			return false, 0
+		case h.err = <-done:
+			if h.err != nil {
+				// a non-zero exit code lets the recovery actions of the service restart the agent
+				return false, 1
+			}
+			return false, 0
+		}
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kubeclipper-server/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/server/encryption"
+)
+
+const rotateLongDescription = `Rewrite the resources stored in etcd which are not encrypted by the first provider of the
+encryption provider config, so that they are encrypted with its first key.
+
+To rotate a key, add the new key first in the providers file, restart every kubeclipper-server with it
+and run this command. The old key can be removed from the file once the command succeeds. It also
+encrypts the resources stored before they were listed in the file.`
+
+func newEncryptionCommand(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "encryption",
+		Short: "Manage the encryption at rest of the resources stored in etcd",
+	}
+	cmd.AddCommand(newEncryptionRotateCommand(out))
+	return cmd
+}
+
+func newEncryptionRotateCommand(out io.Writer) *cobra.Command {
+	s := options.NewServerOptions()
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rewrite the encrypted resources with the first provider of the encryption provider config",
+		Long:  rotateLongDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			s, err := completionOptions(s)
+			if err != nil {
+				return err
+			}
+			return runEncryptionRotate(out, s)
+		},
+		SilenceUsage: true,
+	}
+	s.EtcdOptions.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func runEncryptionRotate(out io.Writer, s *options.ServerOptions) error {
+	if s.EtcdOptions.EncryptionProviderConfig == "" {
+		return fmt.Errorf("--encryption-provider-config must be specified")
+	}
+	transformers, err := encryption.LoadConfiguration(s.EtcdOptions.EncryptionProviderConfig)
+	if err != nil {
+		return err
+	}
+	cli, err := s.EtcdOptions.NewClient()
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	rewritten, err := encryption.Rotate(context.TODO(), cli, s.EtcdOptions.Prefix, transformers)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "%d resources rewritten\n", rewritten)
+	return nil
+}
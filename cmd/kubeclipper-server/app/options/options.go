@@ -25,6 +25,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"k8s.io/apiserver/pkg/registry/generic"
 	etcdRESTOptions "k8s.io/apiserver/pkg/server/options"
 	"k8s.io/apiserver/pkg/storage/storagebackend"
 	cliflag "k8s.io/component-base/cli/flag"
@@ -34,6 +35,7 @@ import (
 	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/server"
 	serverconfig "github.com/kubeclipper/kubeclipper/pkg/server/config"
+	"github.com/kubeclipper/kubeclipper/pkg/server/encryption"
 )
 
 type ServerOptions struct {
@@ -54,6 +56,10 @@ func (s *ServerOptions) Flags() (fss cliflag.NamedFlagSets) {
 	s.MQOptions.AddFlags(fss.FlagSet("mq"))
 	s.LogOptions.AddFlags(fss.FlagSet("log"))
 	s.AuthenticationOptions.AddFlags(fss.FlagSet("authentication"))
+	s.FaultInjectionOptions.AddFlags(fss.FlagSet("fault injection"))
+	s.MetricsOptions.AddFlags(fss.FlagSet("metrics"))
+	s.TracingOptions.AddFlags(fss.FlagSet("tracing"))
+	s.WorkloadInventoryOptions.AddFlags(fss.FlagSet("workload inventory"))
 	return fss
 }
 
@@ -64,6 +70,13 @@ func (s *ServerOptions) Validate() []error {
 	errors = append(errors, s.MQOptions.Validate()...)
 	errors = append(errors, s.LogOptions.Validate()...)
 	errors = append(errors, s.AuthenticationOptions.Validate()...)
+	errors = append(errors, s.AuditOptions.Validate()...)
+	errors = append(errors, s.FaultInjectionOptions.Validate()...)
+	errors = append(errors, s.InventoryOptions.Validate()...)
+	errors = append(errors, s.MetricsOptions.Validate()...)
+	errors = append(errors, s.TracingOptions.Validate()...)
+	errors = append(errors, s.WorkloadInventoryOptions.Validate()...)
+	errors = append(errors, s.NodeProviderOptions.Validate()...)
 	return errors
 }
 
@@ -83,13 +96,17 @@ func (s *ServerOptions) NewAPIServer(stopCh <-chan struct{}) (*server.APIServer,
 		httpSrv.TLSConfig.Certificates = []tls.Certificate{certificate}
 	}
 
-	apiServer.RESTOptionsGetter = s.CompleteEtcdOptions()
+	optsGetter, err := s.CompleteEtcdOptions()
+	if err != nil {
+		return nil, err
+	}
+	apiServer.RESTOptionsGetter = optsGetter
 
 	apiServer.Server = httpSrv
 	return apiServer, nil
 }
 
-func (s *ServerOptions) CompleteEtcdOptions() *etcdRESTOptions.SimpleRestOptionsFactory {
+func (s *ServerOptions) CompleteEtcdOptions() (generic.RESTOptionsGetter, error) {
 	// grpclog.SetLoggerV2(grpclog.NewLoggerV2(ioutil.Discard, ioutil.Discard, ioutil.Discard))
 	gvks := []schema.GroupVersion{corev1.SchemeGroupVersion, iamv1.SchemeGroupVersion}
 	c := storagebackend.NewDefaultConfig(s.EtcdOptions.Prefix, scheme.Codecs.CodecForVersions(scheme.Encoder, scheme.Codecs.UniversalDeserializer(), schema.GroupVersions(gvks), schema.GroupVersions(gvks)))
@@ -109,7 +126,15 @@ func (s *ServerOptions) CompleteEtcdOptions() *etcdRESTOptions.SimpleRestOptions
 		DefaultWatchCacheSize:   s.EtcdOptions.DefaultWatchCacheSize,
 		WatchCacheSizes:         s.EtcdOptions.WatchCacheSizes,
 	}
-	return &etcdRESTOptions.SimpleRestOptionsFactory{
+	optsGetter := &etcdRESTOptions.SimpleRestOptionsFactory{
 		Options: *completeEtcdOptions,
 	}
+	if s.EtcdOptions.EncryptionProviderConfig == "" {
+		return optsGetter, nil
+	}
+	transformers, err := encryption.LoadConfiguration(s.EtcdOptions.EncryptionProviderConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &encryption.RESTOptionsGetter{RESTOptionsGetter: optsGetter, Transformers: transformers}, nil
 }
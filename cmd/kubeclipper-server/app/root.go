@@ -39,6 +39,7 @@ func NewKiServerCommand(in io.Reader, out, err io.Writer) *cobra.Command {
 	cmds.CompletionOptions.DisableDefaultCmd = true
 	cmds.AddCommand(newCmdVersion(out))
 	cmds.AddCommand(newServeCommand(stopCh))
+	cmds.AddCommand(newEncryptionCommand(out))
 
 	return cmds
 }
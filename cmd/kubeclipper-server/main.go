@@ -26,7 +26,15 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/cmd/kubeclipper-server/app"
 	_ "github.com/kubeclipper/kubeclipper/pkg/authentication/identityprovider/oidc"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/cephrbd"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/helm"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/ingress"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/localpath"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/logging"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/monitoring"
 	_ "github.com/kubeclipper/kubeclipper/pkg/component/nfs"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/nvidia"
+	_ "github.com/kubeclipper/kubeclipper/pkg/component/velero"
 	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/cri"
 	_ "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
 )
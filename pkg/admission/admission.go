@@ -0,0 +1,291 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+// Package admission calls the admission webhooks registered by WebhookConfigurations before
+// kc-server stores the clusters, nodes and backups changed by API requests.
+package admission
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const maxResponseBytes = 1 << 20
+
+// kinds maps the admitted resources to the kind of their objects.
+var kinds = map[string]string{
+	"clusters": "Cluster",
+	"nodes":    "Node",
+	"backups":  "Backup",
+}
+
+// Attributes describe the request sent to the webhooks.
+type Attributes struct {
+	Resource  string
+	Operation v1.AdmissionOperation
+	Name      string
+	// Object is the object to store, nil on delete.
+	Object runtime.Object
+	// OldObject is the stored object, nil on create.
+	OldObject runtime.Object
+	User      user.Info
+	DryRun    bool
+}
+
+// Admitter sends the requests to the matching webhooks, mutating webhooks are called one after
+// the other with the object patched by the previous ones, then validating webhooks are called.
+type Admitter struct {
+	configs platform.WebhookConfigurationReader
+}
+
+func NewAdmitter(configs platform.WebhookConfigurationReader) *Admitter {
+	return &Admitter{configs: configs}
+}
+
+// Admit returns the object patched by the mutating webhooks, or a forbidden error when a webhook
+// denies the request. A nil Admitter admits all requests.
+func (a *Admitter) Admit(ctx context.Context, attrs *Attributes) (runtime.Object, error) {
+	if a == nil {
+		return attrs.Object, nil
+	}
+	q := query.New()
+	q.ResourceVersion = "0"
+	configs, err := a.configs.ListWebhookConfigurations(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	var mutating, validating []v1.AdmissionWebhook
+	for _, c := range configs.Items {
+		for _, w := range c.Webhooks {
+			if !w.Match(attrs.Resource, attrs.Operation) {
+				continue
+			}
+			if w.Type == v1.AdmissionWebhookMutating {
+				mutating = append(mutating, w)
+			} else {
+				validating = append(validating, w)
+			}
+		}
+	}
+	obj := attrs.Object
+	for i := range mutating {
+		if obj, err = a.call(ctx, &mutating[i], attrs, obj); err != nil {
+			return nil, err
+		}
+	}
+	for i := range validating {
+		if _, err = a.call(ctx, &validating[i], attrs, obj); err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+func (a *Admitter) call(ctx context.Context, w *v1.AdmissionWebhook, attrs *Attributes, obj runtime.Object) (runtime.Object, error) {
+	resp, err := review(ctx, w, attrs, obj)
+	if err != nil {
+		if w.FailurePolicy == v1.AdmissionIgnore {
+			logger.Warn("admission webhook failed, request admitted by its failure policy",
+				zap.String("webhook", w.Name), zap.String("resource", attrs.Resource),
+				zap.String("name", attrs.Name), zap.Error(err))
+			return obj, nil
+		}
+		return nil, apierrors.NewInternalError(fmt.Errorf("failed calling admission webhook %q: %v", w.Name, err))
+	}
+	if !resp.Allowed {
+		reason := "no reason given"
+		if resp.Result != nil && resp.Result.Message != "" {
+			reason = resp.Result.Message
+		}
+		return nil, apierrors.NewForbidden(v1.Resource(attrs.Resource), attrs.Name,
+			fmt.Errorf("admission webhook %q denied the request: %s", w.Name, reason))
+	}
+	if w.Type != v1.AdmissionWebhookMutating || len(resp.Patch) == 0 || obj == nil {
+		return obj, nil
+	}
+	if resp.PatchType == nil || *resp.PatchType != admissionv1.PatchTypeJSONPatch {
+		return nil, apierrors.NewInternalError(fmt.Errorf("admission webhook %q returned an unsupported patch type", w.Name))
+	}
+	patched, err := applyPatch(obj, resp.Patch)
+	if err != nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("admission webhook %q returned an invalid patch: %v", w.Name, err))
+	}
+	return patched, nil
+}
+
+func review(ctx context.Context, w *v1.AdmissionWebhook, attrs *Attributes, obj runtime.Object) (*admissionv1.AdmissionResponse, error) {
+	req, err := newRequest(attrs, obj)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(&admissionv1.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: admissionv1.SchemeGroupVersion.String(), Kind: "AdmissionReview"},
+		Request:  req,
+	})
+	if err != nil {
+		return nil, err
+	}
+	client, err := newClient(w)
+	if err != nil {
+		return nil, err
+	}
+	defer client.CloseIdleConnections()
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout())
+	defer cancel()
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	out := &admissionv1.AdmissionReview{}
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out); err != nil {
+		return nil, fmt.Errorf("decode admission review: %v", err)
+	}
+	if out.Response == nil {
+		return nil, fmt.Errorf("admission review has no response")
+	}
+	if out.Response.UID != req.UID {
+		return nil, fmt.Errorf("admission review response uid %q does not match the request uid %q", out.Response.UID, req.UID)
+	}
+	return out.Response, nil
+}
+
+func newRequest(attrs *Attributes, obj runtime.Object) (*admissionv1.AdmissionRequest, error) {
+	gvk := v1.SchemeGroupVersion.WithKind(kinds[attrs.Resource])
+	dryRun := attrs.DryRun
+	req := &admissionv1.AdmissionRequest{
+		UID:       types.UID(uuid.New().String()),
+		Kind:      metav1.GroupVersionKind{Group: gvk.Group, Version: gvk.Version, Kind: gvk.Kind},
+		Resource:  metav1.GroupVersionResource{Group: gvk.Group, Version: gvk.Version, Resource: attrs.Resource},
+		Name:      attrs.Name,
+		Operation: admissionv1.Operation(attrs.Operation),
+		DryRun:    &dryRun,
+	}
+	if attrs.User != nil {
+		req.UserInfo = authenticationv1.UserInfo{
+			Username: attrs.User.GetName(),
+			UID:      attrs.User.GetUID(),
+			Groups:   attrs.User.GetGroups(),
+		}
+		if extra := attrs.User.GetExtra(); len(extra) > 0 {
+			req.UserInfo.Extra = make(map[string]authenticationv1.ExtraValue, len(extra))
+			for k, v := range extra {
+				req.UserInfo.Extra[k] = v
+			}
+		}
+	}
+	var err error
+	if req.Object, err = rawObject(obj, gvk.Kind); err != nil {
+		return nil, err
+	}
+	if req.OldObject, err = rawObject(attrs.OldObject, gvk.Kind); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// rawObject encodes the object with its apiVersion and kind, the objects read from the storage have no type meta.
+func rawObject(obj runtime.Object, kind string) (runtime.RawExtension, error) {
+	if obj == nil {
+		return runtime.RawExtension{}, nil
+	}
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind(kind))
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return runtime.RawExtension{}, err
+	}
+	return runtime.RawExtension{Raw: raw}, nil
+}
+
+func newClient(w *v1.AdmissionWebhook) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: w.InsecureSkipVerify} // #nosec G402
+	if w.CABundle != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(w.CABundle)) {
+			return nil, fmt.Errorf("caBundle has no PEM encoded certificate")
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+	return &http.Client{Transport: transport}, nil
+}
+
+// applyPatch applies the JSON patch of a mutating webhook to a copy of the object, webhooks
+// must not rename the object.
+func applyPatch(obj runtime.Object, patch []byte) (runtime.Object, error) {
+	p, err := jsonpatch.DecodePatch(patch)
+	if err != nil {
+		return nil, err
+	}
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	patched, err := p.Apply(original)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	if err = json.Unmarshal(patched, out); err != nil {
+		return nil, err
+	}
+	before, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	after, err := meta.Accessor(out)
+	if err != nil {
+		return nil, err
+	}
+	if before.GetName() != after.GetName() {
+		return nil, fmt.Errorf("the object name can not be changed")
+	}
+	return out, nil
+}
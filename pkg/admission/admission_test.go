@@ -0,0 +1,162 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/kubeclipper/kubeclipper/pkg/models"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+type fakeConfigs struct {
+	items []v1.WebhookConfiguration
+}
+
+func (f *fakeConfigs) ListWebhookConfigurations(ctx context.Context, query *query.Query) (*v1.WebhookConfigurationList, error) {
+	return &v1.WebhookConfigurationList{Items: f.items}, nil
+}
+
+func (f *fakeConfigs) GetWebhookConfiguration(ctx context.Context, name string) (*v1.WebhookConfiguration, error) {
+	return nil, apierrors.NewNotFound(v1.Resource("webhookconfigurations"), name)
+}
+
+func (f *fakeConfigs) ListWebhookConfigurationsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return &models.PageableResponse{}, nil
+}
+
+func (f *fakeConfigs) GetWebhookConfigurationEx(ctx context.Context, name string, resourceVersion string) (*v1.WebhookConfiguration, error) {
+	return f.GetWebhookConfiguration(ctx, name)
+}
+
+// webhookServer answers the admission reviews with the response built by respond.
+func webhookServer(t *testing.T, respond func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		review := &admissionv1.AdmissionReview{}
+		if err := json.NewDecoder(r.Body).Decode(review); err != nil {
+			t.Errorf("decode admission review: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp := respond(review.Request)
+		resp.UID = review.Request.UID
+		review.Response = resp
+		_ = json.NewEncoder(w).Encode(review)
+	}))
+}
+
+func TestAdmit(t *testing.T) {
+	patchType := admissionv1.PatchTypeJSONPatch
+	mutating := webhookServer(t, func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		return &admissionv1.AdmissionResponse{
+			Allowed:   true,
+			PatchType: &patchType,
+			Patch:     []byte(`[{"op":"add","path":"/metadata/labels","value":{"team":"platform"}}]`),
+		}
+	})
+	defer mutating.Close()
+	var validated *admissionv1.AdmissionRequest
+	validating := webhookServer(t, func(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		validated = req
+		cluster := &v1.Cluster{}
+		if err := json.Unmarshal(req.Object.Raw, cluster); err != nil {
+			t.Errorf("decode cluster: %v", err)
+		}
+		if cluster.Labels["team"] != "platform" {
+			return &admissionv1.AdmissionResponse{Result: &metav1.Status{Message: "cluster must have a team label"}}
+		}
+		if cluster.Kubeadm != nil && cluster.Kubeadm.KubernetesVersion == "v1.18.6" {
+			return &admissionv1.AdmissionResponse{Result: &metav1.Status{Message: "kubernetes v1.18.6 is forbidden"}}
+		}
+		return &admissionv1.AdmissionResponse{Allowed: true}
+	})
+	defer validating.Close()
+
+	rules := []v1.AdmissionRule{{Operations: []v1.AdmissionOperation{v1.AdmissionCreate}, Resources: []string{"clusters"}}}
+	a := NewAdmitter(&fakeConfigs{items: []v1.WebhookConfiguration{{
+		ObjectMeta: metav1.ObjectMeta{Name: "policies"},
+		Webhooks: []v1.AdmissionWebhook{
+			// the validating webhook is listed first, it must still see the patched object
+			{Name: "versions", Type: v1.AdmissionWebhookValidating, URL: validating.URL, Rules: rules},
+			{Name: "labels", Type: v1.AdmissionWebhookMutating, URL: mutating.URL, Rules: rules},
+			{Name: "unreachable", Type: v1.AdmissionWebhookValidating, URL: "http://127.0.0.1:1", Rules: rules,
+				FailurePolicy: v1.AdmissionIgnore},
+		},
+	}}})
+
+	admit := func(version string) (*v1.Cluster, error) {
+		c := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "demo"}, Kubeadm: &v1.Kubeadm{KubernetesVersion: version}}
+		obj, err := a.Admit(context.TODO(), &Attributes{
+			Resource:  "clusters",
+			Operation: v1.AdmissionCreate,
+			Name:      c.Name,
+			Object:    c,
+			User:      &user.DefaultInfo{Name: "admin"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return obj.(*v1.Cluster), nil
+	}
+
+	c, err := admit("v1.23.6")
+	if err != nil {
+		t.Fatalf("Admit() error = %v", err)
+	}
+	if c.Labels["team"] != "platform" {
+		t.Errorf("expected the cluster patched by the mutating webhook, got labels %v", c.Labels)
+	}
+	if validated.UserInfo.Username != "admin" || validated.Kind.Kind != "Cluster" || validated.Operation != admissionv1.Create {
+		t.Errorf("unexpected admission request %+v", validated)
+	}
+
+	if _, err = admit("v1.18.6"); !apierrors.IsForbidden(err) {
+		t.Errorf("expected the denied request to be forbidden, got %v", err)
+	}
+
+	// requests not matching any rule are not sent to the webhooks
+	obj, err := a.Admit(context.TODO(), &Attributes{Resource: "nodes", Operation: v1.AdmissionCreate, Object: &v1.Node{}})
+	if err != nil || obj == nil {
+		t.Errorf("expected the node to be admitted, got %v", err)
+	}
+}
+
+func TestAdmitFailurePolicy(t *testing.T) {
+	rules := []v1.AdmissionRule{{Operations: []v1.AdmissionOperation{v1.AdmissionAll}, Resources: []string{"*"}}}
+	a := NewAdmitter(&fakeConfigs{items: []v1.WebhookConfiguration{{
+		Webhooks: []v1.AdmissionWebhook{
+			{Name: "unreachable", Type: v1.AdmissionWebhookValidating, URL: "http://127.0.0.1:1", Rules: rules},
+		},
+	}}})
+	_, err := a.Admit(context.TODO(), &Attributes{Resource: "backups", Operation: v1.AdmissionDelete, Name: "b1",
+		OldObject: &v1.Backup{}})
+	if !apierrors.IsInternalError(err) {
+		t.Errorf("expected an internal error when the webhook fails, got %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package admission
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	apirequest "github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+var _ rest.StandardStorage = (*storage)(nil)
+
+// storage admits the writes of API requests, the writes without a request user in their context
+// are made by kc-server itself and are not sent to the webhooks.
+type storage struct {
+	rest.StandardStorage
+	resource string
+	admitter *Admitter
+}
+
+// NewStorage wraps the storage of the resource so that its writes are admitted by the admitter.
+func NewStorage(s rest.StandardStorage, resource string, admitter *Admitter) rest.StandardStorage {
+	return &storage{StandardStorage: s, resource: resource, admitter: admitter}
+}
+
+func (s *storage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	u, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return s.StandardStorage.Create(ctx, obj, createValidation, options)
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	admitted, err := s.admitter.Admit(ctx, &Attributes{
+		Resource:  s.resource,
+		Operation: v1.AdmissionCreate,
+		Name:      accessor.GetName(),
+		Object:    obj,
+		User:      u,
+		DryRun:    options != nil && len(options.DryRun) > 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.StandardStorage.Create(ctx, admitted, createValidation, options)
+}
+
+func (s *storage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc,
+	updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	u, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return s.StandardStorage.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	}
+	info := &updatedObjectInfo{
+		UpdatedObjectInfo: objInfo,
+		admitter:          s.admitter,
+		attrs: Attributes{
+			Resource:  s.resource,
+			Operation: v1.AdmissionUpdate,
+			Name:      name,
+			User:      u,
+			DryRun:    options != nil && len(options.DryRun) > 0,
+		},
+	}
+	return s.StandardStorage.Update(ctx, name, info, createValidation, updateValidation, forceAllowCreate, options)
+}
+
+func (s *storage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	u, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return s.StandardStorage.Delete(ctx, name, deleteValidation, options)
+	}
+	validate := func(ctx context.Context, obj runtime.Object) error {
+		if _, err := s.admitter.Admit(ctx, &Attributes{
+			Resource:  s.resource,
+			Operation: v1.AdmissionDelete,
+			Name:      name,
+			OldObject: obj,
+			User:      u,
+			DryRun:    options != nil && len(options.DryRun) > 0,
+		}); err != nil {
+			return err
+		}
+		if deleteValidation != nil {
+			return deleteValidation(ctx, obj)
+		}
+		return nil
+	}
+	return s.StandardStorage.Delete(ctx, name, validate, options)
+}
+
+// updatedObjectInfo admits the updated object against the stored one, it is called again when
+// the update is retried on a conflict.
+type updatedObjectInfo struct {
+	rest.UpdatedObjectInfo
+	admitter *Admitter
+	attrs    Attributes
+}
+
+func (i *updatedObjectInfo) UpdatedObject(ctx context.Context, oldObj runtime.Object) (runtime.Object, error) {
+	obj, err := i.UpdatedObjectInfo.UpdatedObject(ctx, oldObj)
+	if err != nil {
+		return nil, err
+	}
+	attrs := i.attrs
+	attrs.Object = obj
+	attrs.OldObject = oldObj
+	return i.admitter.Admit(ctx, &attrs)
+}
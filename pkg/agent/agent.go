@@ -19,16 +19,29 @@
 package agent
 
 import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
 	"github.com/kubeclipper/kubeclipper/pkg/agent/config"
+	"github.com/kubeclipper/kubeclipper/pkg/component"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/oplog"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 	"github.com/kubeclipper/kubeclipper/pkg/service/task"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
 )
 
 type Server struct {
 	taskService service.Interface
 	Config      *config.Config
+	// configMux serializes config reloads pushed by server
+	configMux sync.Mutex
+	// shutdownTracing flushes the spans not exported yet
+	shutdownTracing func(context.Context) error
 }
 
 func (s *Server) PrepareRun(stopCh <-chan struct{}) error {
@@ -36,20 +49,74 @@ func (s *Server) PrepareRun(stopCh <-chan struct{}) error {
 	if err != nil {
 		return err
 	}
-	s.taskService = task.NewService(s.Config.AgentID, s.Config.Region, s.Config.RegisterNode, s.Config.MQOptions,
+	faults, err := s.Config.FaultInjectionOptions.Injector()
+	if err != nil {
+		return err
+	}
+	if s.shutdownTracing, err = tracing.Setup(context.TODO(), s.Config.TracingOptions, "kc-agent"); err != nil {
+		return err
+	}
+	ts := task.NewService(s.Config.AgentID, s.Config.Region, s.Config.RegisterNode, s.Config.MQOptions,
 		task.WithNodeStatusUpdateFrequency(s.Config.NodeStatusUpdateFrequency),
 		task.WithLeaseDurationSeconds(240),
 		task.WithOplog(opLog),
+		task.WithContainerExecutor(s.Config.ContainerExecutorOptions),
+		task.WithFaultInjector(faults),
+		task.WithExternalID(s.Config.ExternalID),
 	)
+	ts.SetReconfigure(func(ctx context.Context, patch *service.AgentConfigPatch) error {
+		return s.reconfigure(ts, patch)
+	})
+	s.taskService = ts
+	task.RegisterMetrics()
 	return s.taskService.PrepareRun(stopCh)
 }
 
+// reconfigure validates the patch, persists the new config and reloads the parts that
+// can be changed at runtime. MQ server addresses are only persisted, the running connection
+// keeps its current servers and picks up the new ones on the next restart of agent.
+func (s *Server) reconfigure(ts *task.Service, patch *service.AgentConfigPatch) error {
+	s.configMux.Lock()
+	defer s.configMux.Unlock()
+
+	c, err := s.Config.Apply(patch)
+	if err != nil {
+		return err
+	}
+	var opLog component.OperationLogFile
+	if patch.OpLogDir != "" {
+		if opLog, err = oplog.NewOperationLog(c.OpLogOptions); err != nil {
+			return err
+		}
+	}
+	if err = config.TrySaveToDisk(c); err != nil {
+		return err
+	}
+	if patch.LogLevel != "" {
+		logger.ApplyZapLoggerWithOptions(c.LogOptions)
+	}
+	if patch.StaticServerAddress != "" {
+		downloader.SetOptions(c.DownloaderOptions)
+	}
+	if opLog != nil {
+		ts.SetOplog(opLog)
+	}
+	s.Config = c
+	logger.Info("agent reconfigured", zap.Strings("mqServers", patch.MQServers), zap.String("logLevel", patch.LogLevel),
+		zap.String("oplogDir", patch.OpLogDir), zap.String("staticServerAddress", patch.StaticServerAddress))
+	return nil
+}
+
 func (s *Server) Run(stopCh <-chan struct{}) error {
 	if err := s.taskService.Run(stopCh); err != nil {
 		return err
 	}
+	metrics.Serve(s.Config.MetricsOptions, stopCh)
 	<-stopCh
 	logger.Debugf("get stopCh signal, exit...")
 	s.taskService.Close()
+	if s.shutdownTracing != nil {
+		_ = s.shutdownTracing(context.TODO())
+	}
 	return nil
 }
@@ -19,38 +19,49 @@
 package config
 
 import (
+	"fmt"
+	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v2"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
+	"github.com/kubeclipper/kubeclipper/pkg/faultinject"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/service/task"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
 )
 
 const (
 	// DefaultConfigurationName is the default name of configuration
 	defaultConfigurationName = "kubeclipper-agent"
-
-	// DefaultConfigurationPath the default location of the configuration file
-	defaultConfigurationPath = "/etc/kubeclipper-agent"
 )
 
 // Config defines everything needed for apiserver to deal with external services
 type Config struct {
-	AgentID                   string              `json:"agentID,omitempty" yaml:"agentID"`
-	Region                    string              `json:"region,omitempty" yaml:"region"`
-	RegisterNode              bool                `json:"registerNode,omitempty" yaml:"registerNode"`
-	NodeStatusUpdateFrequency time.Duration       `json:"nodeStatusUpdateFrequency,omitempty" yaml:"nodeStatusUpdateFrequency"`
-	DownloaderOptions         *downloader.Options `json:"downloader" yaml:"downloader" mapstructure:"downloader"`
-	LogOptions                *logger.Options     `json:"log,omitempty" yaml:"log,omitempty" mapstructure:"log"`
-	MQOptions                 *natsio.NatsOptions `json:"mq,omitempty" yaml:"mq,omitempty"  mapstructure:"mq"`
-	OpLogOptions              *oplog.Options      `json:"oplog,omitempty" yaml:"oplog,omitempty" mapstructure:"oplog"`
+	AgentID                   string                         `json:"agentID,omitempty" yaml:"agentID"`
+	Region                    string                         `json:"region,omitempty" yaml:"region"`
+	ExternalID                string                         `json:"externalID,omitempty" yaml:"externalID,omitempty"`
+	RegisterNode              bool                           `json:"registerNode,omitempty" yaml:"registerNode"`
+	NodeStatusUpdateFrequency time.Duration                  `json:"nodeStatusUpdateFrequency,omitempty" yaml:"nodeStatusUpdateFrequency"`
+	DownloaderOptions         *downloader.Options            `json:"downloader" yaml:"downloader" mapstructure:"downloader"`
+	LogOptions                *logger.Options                `json:"log,omitempty" yaml:"log,omitempty" mapstructure:"log"`
+	MQOptions                 *natsio.NatsOptions            `json:"mq,omitempty" yaml:"mq,omitempty"  mapstructure:"mq"`
+	OpLogOptions              *oplog.Options                 `json:"oplog,omitempty" yaml:"oplog,omitempty" mapstructure:"oplog"`
+	ContainerExecutorOptions  *task.ContainerExecutorOptions `json:"containerExecutor,omitempty" yaml:"containerExecutor,omitempty" mapstructure:"containerExecutor"`
+	FaultInjectionOptions     *faultinject.Options           `json:"faultInjection,omitempty" yaml:"faultInjection,omitempty" mapstructure:"faultInjection"`
+	MetricsOptions            *metrics.Options               `json:"metrics,omitempty" yaml:"metrics,omitempty" mapstructure:"metrics"`
+	TracingOptions            *tracing.Options               `json:"tracing,omitempty" yaml:"tracing,omitempty" mapstructure:"tracing"`
 }
 
 func New() *Config {
@@ -61,6 +72,10 @@ func New() *Config {
 		MQOptions:                 natsio.NewOptions(),
 		DownloaderOptions:         downloader.NewOptions(),
 		OpLogOptions:              oplog.NewOptions(),
+		ContainerExecutorOptions:  task.NewContainerExecutorOptions(),
+		FaultInjectionOptions:     faultinject.NewOptions(),
+		MetricsOptions:            metrics.NewOptions(0),
+		TracingOptions:            tracing.NewOptions(),
 	}
 }
 
@@ -98,6 +113,48 @@ func (conf *Config) stripEmptyOptions() {
 	}
 }
 
+// Apply returns a copy of the config with the patch pushed by server applied,
+// the receiver is left untouched so that an invalid patch never reaches the running agent.
+func (conf *Config) Apply(patch *service.AgentConfigPatch) (*Config, error) {
+	if patch == nil || patch.IsEmpty() {
+		return nil, fmt.Errorf("agent config patch is empty")
+	}
+	c := *conf
+	if len(patch.MQServers) > 0 {
+		mq := *conf.MQOptions
+		mq.Client.ServerAddress = append([]string(nil), patch.MQServers...)
+		c.MQOptions = &mq
+	}
+	if patch.LogLevel != "" {
+		log := *conf.LogOptions
+		log.Level = patch.LogLevel
+		c.LogOptions = &log
+	}
+	if patch.OpLogDir != "" {
+		ol := *conf.OpLogOptions
+		ol.Dir = patch.OpLogDir
+		c.OpLogOptions = &ol
+	}
+	if patch.StaticServerAddress != "" {
+		dl := *conf.DownloaderOptions
+		dl.Address = patch.StaticServerAddress
+		c.DownloaderOptions = &dl
+	}
+	var errs []error
+	errs = append(errs, c.LogOptions.Validate()...)
+	// only client side of mq options matters to agent
+	for _, addr := range c.MQOptions.Client.ServerAddress {
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			errs = append(errs, fmt.Errorf("mq server %s must be ip:port", addr))
+		}
+	}
+	errs = append(errs, c.OpLogOptions.Validate()...)
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
 func TryLoadFromDisk() (*Config, error) {
 	viper.SetConfigName(defaultConfigurationName)
 	viper.AddConfigPath(defaultConfigurationPath)
@@ -122,21 +179,36 @@ func SetConfig(key string, value interface{}) {
 }
 
 func TrySaveToDisk(c *Config) error {
-	filename := viper.ConfigFileUsed()
-	flags := os.O_CREATE | os.O_TRUNC | os.O_WRONLY
-	f, err := os.OpenFile(filename, flags, os.FileMode(0644))
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	return saveToFile(viper.ConfigFileUsed(), c)
+}
 
+// saveToFile writes config to a temporary file and renames it over the target,
+// so a crash in the middle of writing never leaves a truncated config behind.
+func saveToFile(filename string, c *Config) error {
 	b, err := yaml.Marshal(c)
 	if err != nil {
 		return err
 	}
-	_, err = f.WriteString(string(b))
+	f, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*")
 	if err != nil {
 		return err
 	}
-	return nil
+	tmp := f.Name()
+	defer os.Remove(tmp)
+	if _, err = f.Write(b); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Chmod(0644); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
 }
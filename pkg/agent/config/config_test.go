@@ -0,0 +1,123 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   *service.AgentConfigPatch
+		wantErr bool
+		check   func(c *Config) bool
+	}{
+		{
+			name:    "empty patch",
+			patch:   &service.AgentConfigPatch{},
+			wantErr: true,
+		},
+		{
+			name:  "change log level",
+			patch: &service.AgentConfigPatch{LogLevel: "debug"},
+			check: func(c *Config) bool { return c.LogOptions.Level == "debug" },
+		},
+		{
+			name:    "invalid log level",
+			patch:   &service.AgentConfigPatch{LogLevel: "trace"},
+			wantErr: true,
+		},
+		{
+			name:  "change mq servers",
+			patch: &service.AgentConfigPatch{MQServers: []string{"10.0.0.1:9889", "10.0.0.2:9889"}},
+			check: func(c *Config) bool { return len(c.MQOptions.Client.ServerAddress) == 2 },
+		},
+		{
+			name:    "invalid mq server",
+			patch:   &service.AgentConfigPatch{MQServers: []string{"10.0.0.1"}},
+			wantErr: true,
+		},
+		{
+			name:    "relative oplog dir",
+			patch:   &service.AgentConfigPatch{OpLogDir: "oplog"},
+			wantErr: true,
+		},
+		{
+			name:  "change static server",
+			patch: &service.AgentConfigPatch{StaticServerAddress: "http://10.0.0.1:8081"},
+			check: func(c *Config) bool { return c.DownloaderOptions.Address == "http://10.0.0.1:8081" },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := New()
+			got, err := conf.Apply(tt.patch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !tt.check(got) {
+				t.Errorf("Apply() patch %+v not applied", tt.patch)
+			}
+			if *conf.LogOptions != *New().LogOptions || len(conf.MQOptions.Client.ServerAddress) != 1 ||
+				conf.DownloaderOptions.Address != "" {
+				t.Errorf("Apply() modified the original config")
+			}
+		})
+	}
+}
+
+func TestSaveToFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "kubeclipper-agent.yaml")
+	if err := os.WriteFile(filename, []byte("stale"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conf := New()
+	conf.AgentID = "agent-1"
+	if err := saveToFile(filename, conf); err != nil {
+		t.Fatalf("saveToFile() error = %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := New()
+	if err = yaml.Unmarshal(data, got); err != nil {
+		t.Fatalf("unmarshal saved config error = %v", err)
+	}
+	if got.AgentID != "agent-1" {
+		t.Errorf("saved agentID = %s, want agent-1", got.AgentID)
+	}
+	entries, err := os.ReadDir(filepath.Dir(filename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("temporary file left behind, got %d entries", len(entries))
+	}
+}
@@ -57,6 +57,7 @@ func AddToContainer(c *restful.Container, operator platform.Operator) error {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
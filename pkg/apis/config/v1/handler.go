@@ -19,8 +19,11 @@
 package v1
 
 import (
+	"fmt"
 	"net/http"
 
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/distribution"
+
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -117,6 +120,160 @@ func (h *handler) UpdateTemplate(req *restful.Request, resp *restful.Response) {
 	_ = resp.WriteHeaderAndEntity(http.StatusOK, c)
 }
 
+func (h *handler) DescribeClusterPolicy(req *restful.Request, resp *restful.Response) {
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, setting.Cluster)
+}
+
+func (h *handler) UpdateClusterPolicy(req *restful.Request, resp *restful.Response) {
+	c := &v1.ClusterPolicy{}
+	if err := req.ReadEntity(c); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	setting.Cluster = *c
+	_, err = h.platformOperator.UpdatePlatformSetting(req.Request.Context(), setting)
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+func (h *handler) DescribeClusterDefaults(req *restful.Request, resp *restful.Response) {
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, setting.ClusterDefaults)
+}
+
+func (h *handler) UpdateClusterDefaults(req *restful.Request, resp *restful.Response) {
+	c := &v1.ClusterDefaults{}
+	if err := req.ReadEntity(c); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	if err := c.Validate(); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	setting.ClusterDefaults = *c
+	_, err = h.platformOperator.UpdatePlatformSetting(req.Request.Context(), setting)
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+func (h *handler) DescribeQuota(req *restful.Request, resp *restful.Response) {
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, setting.Quota)
+}
+
+func (h *handler) UpdateQuota(req *restful.Request, resp *restful.Response) {
+	q := &v1.ResourceQuota{}
+	if err := req.ReadEntity(q); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	if err := q.Validate(); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	setting.Quota = *q
+	_, err = h.platformOperator.UpdatePlatformSetting(req.Request.Context(), setting)
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, q)
+}
+
+func (h *handler) DescribeRegistrySync(req *restful.Request, resp *restful.Response) {
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, setting.RegistrySync)
+}
+
+func (h *handler) UpdateRegistrySync(req *restful.Request, resp *restful.Response) {
+	c := &v1.RegistrySync{}
+	if err := req.ReadEntity(c); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	if err := validateRegistrySync(c); err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	// status is owned by the registry sync controller
+	c.Status = setting.RegistrySync.Status
+	setting.RegistrySync = *c
+	_, err = h.platformOperator.UpdatePlatformSetting(req.Request.Context(), setting)
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	_ = resp.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+func validateRegistrySync(c *v1.RegistrySync) error {
+	if len(c.Images) == 0 {
+		return nil
+	}
+	if c.Registry == "" {
+		return fmt.Errorf("registry is required to sync images")
+	}
+	if c.Interval.Duration < 0 {
+		return fmt.Errorf("invalid interval %s", c.Interval.Duration)
+	}
+	for _, img := range c.Images {
+		if _, err := distribution.ParseReference(img.Source); err != nil {
+			return err
+		}
+		if img.Target == "" {
+			continue
+		}
+		if _, err := distribution.ParseReference(c.Registry + "/" + img.Target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (h *handler) GetSSHRSAKey(req *restful.Request, resp *restful.Response) {
 	t := v1.WebTerminal{}
 	setting, err := h.platformOperator.GetPlatformSetting(req.Request.Context())
@@ -117,6 +117,65 @@ func AddToContainer(c *restful.Container, platformOperator platform.Operator, co
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.DockerRegistry{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.GET("/clusterpolicy").
+		Doc("Information about platform cluster policy").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.DescribeClusterPolicy).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.ClusterPolicy{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+	webservice.Route(webservice.PUT("/clusterpolicy").
+		Doc("Update platform cluster policy").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.UpdateClusterPolicy).
+		Reads(v1.ClusterPolicy{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.ClusterPolicy{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/clusterdefaults").
+		Doc("Information about platform cluster defaults").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.DescribeClusterDefaults).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.ClusterDefaults{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+	webservice.Route(webservice.PUT("/clusterdefaults").
+		Doc("Update platform cluster defaults").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.UpdateClusterDefaults).
+		Reads(v1.ClusterDefaults{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.ClusterDefaults{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/quota").
+		Doc("Information about the platform quota of every region").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.DescribeQuota).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.ResourceQuota{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+	webservice.Route(webservice.PUT("/quota").
+		Doc("Update the platform quota of every region").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.UpdateQuota).
+		Reads(v1.ResourceQuota{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.ResourceQuota{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/registrysync").
+		Doc("Information about images synced into the embedded registry").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.DescribeRegistrySync).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.RegistrySync{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+	webservice.Route(webservice.PUT("/registrysync").
+		Doc("Update images synced into the embedded registry").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
+		To(h.UpdateRegistrySync).
+		Reads(v1.RegistrySync{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.RegistrySync{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/terminal.key").
 		Doc("Get rsa public key").
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreConfigTag}).
@@ -0,0 +1,110 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+const (
+	// agentConfigPushTimeout bounds the time waiting for a single agent to apply the patch
+	agentConfigPushTimeout = 30 * time.Second
+	// agentConfigPushConcurrency bounds the number of agents reconfigured at the same time
+	agentConfigPushConcurrency = 20
+)
+
+// AgentConfigRequest pushes a config patch to the agents on the given nodes,
+// all nodes are reconfigured if no node is given.
+type AgentConfigRequest struct {
+	Nodes  []string                 `json:"nodes,omitempty"`
+	Config service.AgentConfigPatch `json:"config"`
+}
+
+// AgentConfigResult is the outcome of reconfiguring the agent on a node.
+type AgentConfigResult struct {
+	Node    string `json:"node"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+func (h *handler) ReconfigureAgents(request *restful.Request, response *restful.Response) {
+	req := &AgentConfigRequest{}
+	if err := request.ReadEntity(req); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if req.Config.IsEmpty() {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("agent config patch is empty"))
+		return
+	}
+	ctx := request.Request.Context()
+	nodes := req.Nodes
+	if len(nodes) == 0 {
+		nodeList, err := h.clusterOperator.ListNodes(ctx, &query.Query{
+			Pagination:      query.NoPagination(),
+			ResourceVersion: "0",
+		})
+		if err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		for _, node := range nodeList.Items {
+			nodes = append(nodes, node.Name)
+		}
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, h.pushAgentConfig(ctx, nodes, &req.Config))
+}
+
+// pushAgentConfig delivers the patch to every node with bounded concurrency,
+// a failure on one node never stops the others from being reconfigured.
+func (h *handler) pushAgentConfig(ctx context.Context, nodes []string, patch *service.AgentConfigPatch) []AgentConfigResult {
+	results := make([]AgentConfigResult, len(nodes))
+	sem := make(chan struct{}, agentConfigPushConcurrency)
+	var wg sync.WaitGroup
+	for i, node := range nodes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, node string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i] = AgentConfigResult{Node: node, Success: true}
+			if err := h.delivery.DeliverAgentConfig(ctx, node, patch, agentConfigPushTimeout); err != nil {
+				logger.Error("push agent config failed", zap.String("node", node), zap.Error(err))
+				results[i].Success = false
+				results[i].Message = err.Error()
+			}
+		}(i, node)
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,200 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/google/uuid"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// RenewClusterCertificates renews the control plane certificates managed by kubeadm on every master.
+func (h *handler) RenewClusterCertificates(request *restful.Request, response *restful.Response) {
+	h.runOnMasters(request, response, v1.OperationRenewCertificates, "renew certificates",
+		func(_ *v1.Kubeadm, masters []v1.StepNode) []v1.Step { return k8s.RenewCertificatesSteps(masters) })
+}
+
+// runOnMasters creates an operation of the running cluster with the steps built for its masters.
+// The cluster is updating until the operation finishes, verb describes it when the cluster is busy.
+func (h *handler) runOnMasters(request *restful.Request, response *restful.Response, action, verb string,
+	steps func(kubeadm *v1.Kubeadm, masters []v1.StepNode) []v1.Step) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	name := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	c, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Kubeadm == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("kubeadm of cluster %s is empty", name))
+		return
+	}
+	if err := kubeadmOnlyCheck(c, verb); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.clusterIdleError(ctx, c, verb); err != nil {
+		restplus.HandleConflict(response, request, err)
+		return
+	}
+	meta, err := h.getClusterMetadata(ctx, c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:     c.Name,
+		common.LabelTopologyRegion:  meta.Masters[0].Region,
+		common.LabelOperationAction: action,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+	}
+	op.Steps = steps(c.Kubeadm, utils.UnwrapNodeList(meta.Masters))
+	op.Status.Status = v1.OperationStatusRunning
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+
+	c.Status.Status = v1.ClusterStatusUpdating
+	if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	v1.SetOwnerReference(op, v1.NewClusterOwnerReference(c))
+	if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	go h.doOperation(context.TODO(), op, &service.Options{})
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+// UpdateClusterCertSANs saves the cert SANs and the external endpoint of the cluster, then regenerates
+// the apiserver certificate of the masters one at a time, so the cluster may be exposed behind new names.
+func (h *handler) UpdateClusterCertSANs(request *restful.Request, response *restful.Response) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	name := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	body := &ClusterCertSANs{}
+	if err := request.ReadEntity(body); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := v1.ValidateCertSANs(body.CertSANs); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := v1.ValidateExternalEndpoint(body.ExternalEndpoint); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	c, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Kubeadm == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("kubeadm of cluster %s is empty", name))
+		return
+	}
+	if err := kubeadmOnlyCheck(c, "updating cert SANs"); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.clusterIdleError(ctx, c, "update cert SANs"); err != nil {
+		restplus.HandleConflict(response, request, err)
+		return
+	}
+	meta, err := h.getClusterMetadata(ctx, c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	kubeadm := c.Kubeadm.DeepCopy()
+	kubeadm.CertSANs = body.CertSANs
+	kubeadm.ExternalEndpoint = body.ExternalEndpoint
+	steps, err := k8s.UpdateCertSANsSteps(utils.UnwrapNodeList(meta.Masters), (&k8s.KubeadmConfig{}).InitStepper(kubeadm, meta))
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:     c.Name,
+		common.LabelTopologyRegion:  meta.Masters[0].Region,
+		common.LabelOperationAction: v1.OperationUpdateCertSANs,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+	}
+	op.Steps = steps
+	op.Status.Status = v1.OperationStatusRunning
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+
+	c.Kubeadm = kubeadm
+	c.Status.Status = v1.ClusterStatusUpdating
+	if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	v1.SetOwnerReference(op, v1.NewClusterOwnerReference(c))
+	if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	go h.doOperation(context.TODO(), op, &service.Options{})
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
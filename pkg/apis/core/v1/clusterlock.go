@@ -0,0 +1,252 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/emicklei/go-restful"
+	"go.uber.org/zap"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	apirequest "github.com/kubeclipper/kubeclipper/pkg/server/request"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// GetClusterLock returns the operation holding the cluster and the ones queued after it.
+func (h *handler) GetClusterLock(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	clu, err := h.clusterOperator.GetClusterEx(ctx, request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	lock, err := h.clusterLock(ctx, clu)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, lock)
+}
+
+// BreakClusterLock releases the cluster held by a crashed operation. The unfinished operations
+// of the cluster are marked failed and the cluster status is set to the failure of the
+// operation it was running, from which it can be retried or reset.
+func (h *handler) BreakClusterLock(request *restful.Request, response *restful.Response) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	ctx := request.Request.Context()
+	clu, err := h.clusterOperator.GetClusterEx(ctx, request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	lock, err := h.clusterLock(ctx, clu)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if !lock.Locked {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster %s is not locked", clu.Name))
+		return
+	}
+	if dryRun {
+		_ = response.WriteHeaderAndEntity(http.StatusOK, lock)
+		return
+	}
+	user := "unknown"
+	if u, ok := apirequest.UserFrom(ctx); ok {
+		user = u.GetName()
+	}
+	holders := lock.Queue
+	if lock.Holder != nil {
+		holders = append([]v1.LockHolder{*lock.Holder}, holders...)
+	}
+	for _, holder := range holders {
+		if err = h.failOperation(ctx, holder.Operation, fmt.Sprintf("cluster lock broken by %s", user)); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+	clu.Status.Status = lockBrokenClusterStatus(clu.Status.Status)
+	if _, err = h.clusterOperator.UpdateCluster(ctx, clu); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	logger.Info("cluster lock broken", zap.String("cluster", clu.Name), zap.String("user", user), zap.Int("operations", len(holders)))
+	lock.Status = clu.Status.Status
+	lock.Locked, lock.Stale, lock.Holder, lock.Queue = false, false, nil, nil
+	_ = response.WriteHeaderAndEntity(http.StatusOK, lock)
+}
+
+// failOperation stops the operation if it still runs somewhere and marks it failed,
+// the cancel annotation stops it at its next step in case the server running it is alive.
+func (h *handler) failOperation(ctx context.Context, name, reason string) error {
+	op, err := h.opOperator.GetOperationEx(ctx, name, "0")
+	if err != nil {
+		return err
+	}
+	if err = h.delivery.CancelOperation(ctx, op); err != nil {
+		logger.Debug("stop operation of broken lock failed", zap.String("operation", name), zap.Error(err))
+	}
+	if op.Annotations == nil {
+		op.Annotations = make(map[string]string)
+	}
+	op.Annotations[common.AnnotationOperationCancel] = "true"
+	op.Status.Status = v1.OperationStatusFailed
+	if _, err = h.opOperator.UpdateOperation(ctx, op); err != nil {
+		return err
+	}
+	logger.Info("operation failed", zap.String("operation", name), zap.String("reason", reason))
+	return nil
+}
+
+// clusterLock finds the unfinished operations of the cluster, from the operation cache once it is synced.
+func (h *handler) clusterLock(ctx context.Context, clu *v1.Cluster) (*v1.ClusterLock, error) {
+	if h.opLister != nil && h.opSynced() {
+		running, err := h.opLister.ListRunning(clu.Name)
+		if err != nil {
+			return nil, err
+		}
+		ops := make([]v1.Operation, 0, len(running))
+		for _, op := range running {
+			ops = append(ops, *op)
+		}
+		return newClusterLock(clu, ops), nil
+	}
+	q := query.New()
+	q.LabelSelector = fmt.Sprintf("%s=%s", common.LabelClusterName, clu.Name)
+	ops, err := h.opOperator.ListOperations(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return newClusterLock(clu, ops.Items), nil
+}
+
+func newClusterLock(clu *v1.Cluster, ops []v1.Operation) *v1.ClusterLock {
+	lock := &v1.ClusterLock{
+		Cluster: clu.Name,
+		Status:  clu.Status.Status,
+	}
+	var holders []v1.LockHolder
+	for _, op := range ops {
+		if op.Status.Status != v1.OperationStatusRunning && op.Status.Status != v1.OperationStatusPaused {
+			continue
+		}
+		holders = append(holders, v1.LockHolder{
+			Operation: op.Name,
+			Action:    op.Labels[common.LabelOperationAction],
+			Status:    op.Status.Status,
+			Since:     op.CreationTimestamp,
+		})
+	}
+	sort.SliceStable(holders, func(i, j int) bool {
+		return holders[i].Since.Before(&holders[j].Since)
+	})
+	if len(holders) > 0 {
+		lock.Holder = &holders[0]
+		lock.Queue = holders[1:]
+	}
+	lock.Locked = lock.Holder != nil || clusterBusy(clu.Status.Status)
+	lock.Stale = lock.Locked && lock.Holder == nil
+	return lock
+}
+
+// clusterBusy tells whether an operation is running on a cluster of the status.
+func clusterBusy(status v1.ClusterStatusType) bool {
+	switch status {
+	case v1.ClusterStatusInstalling, v1.ClusterStatusUpdating, v1.ClusterStatusUpgrading,
+		v1.ClusterStatusBackingUp, v1.ClusterStatusRestoring, v1.ClusterStatusDeleting:
+		return true
+	}
+	return false
+}
+
+// lockBrokenClusterStatus returns the status of a cluster whose operation is failed by breaking its lock.
+func lockBrokenClusterStatus(status v1.ClusterStatusType) v1.ClusterStatusType {
+	switch status {
+	case v1.ClusterStatusInstalling:
+		return v1.ClusterStatusInstallFailed
+	case v1.ClusterStatusUpdating:
+		return v1.ClusterStatusUpdateFailed
+	case v1.ClusterStatusUpgrading:
+		return v1.ClusterStatusUpgradeFailed
+	case v1.ClusterStatusRestoring:
+		return v1.ClusterStatusRestoreFailed
+	case v1.ClusterStatusDeleting:
+		return v1.ClusterStatusDeleteFailed
+	case v1.ClusterStatusBackingUp:
+		return v1.ClusterStatusRunning
+	}
+	return status
+}
+
+// clusterLockedError explains why the cluster can not take another operation,
+// naming the operation holding it.
+func (h *handler) clusterLockedError(ctx context.Context, clu *v1.Cluster, action string) error {
+	lock, err := h.clusterLock(ctx, clu)
+	if err != nil || lock.Holder == nil {
+		return fmt.Errorf("cluster %s current is %s, can't %s", clu.Name, clu.Status.Status, action)
+	}
+	return lockHolderError(clu, lock.Holder, action)
+}
+
+// runningOperationError rejects another operation on the cluster while one of its operations is running.
+// It catches the operations created by concurrent requests before they change the cluster status.
+func (h *handler) runningOperationError(ctx context.Context, clu *v1.Cluster, action string) error {
+	lock, err := h.clusterLock(ctx, clu)
+	if err != nil {
+		logger.Warn("list running operations failed", zap.String("cluster", clu.Name), zap.Error(err))
+		return nil
+	}
+	if lock.Holder == nil {
+		return nil
+	}
+	return lockHolderError(clu, lock.Holder, action)
+}
+
+// clusterIdleError rejects the operations which need a running cluster no other operation is running on.
+func (h *handler) clusterIdleError(ctx context.Context, clu *v1.Cluster, action string) error {
+	if v1.IsDeleted(clu) {
+		return fmt.Errorf("cluster %s is in the recycle bin, restore it to %s", clu.Name, action)
+	}
+	if clu.Status.Status != v1.ClusterStatusRunning {
+		return h.clusterLockedError(ctx, clu, action)
+	}
+	return h.runningOperationError(ctx, clu, action)
+}
+
+func lockHolderError(clu *v1.Cluster, holder *v1.LockHolder, action string) error {
+	return fmt.Errorf("cluster %s is locked by %s operation %s since %s, can't %s",
+		clu.Name, holder.Action, holder.Operation, holder.Since.Format("2006-01-02 15:04:05"), action)
+}
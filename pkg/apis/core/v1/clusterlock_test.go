@@ -0,0 +1,70 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestNewClusterLock(t *testing.T) {
+	now := time.Now()
+	op := func(name string, status v1.OperationStatusType, age time.Duration) v1.Operation {
+		return v1.Operation{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Labels:            map[string]string{common.LabelOperationAction: "upgrade"},
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Status: v1.OperationStatus{Status: status},
+		}
+	}
+	clu := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1"}}
+
+	clu.Status.Status = v1.ClusterStatusUpgrading
+	lock := newClusterLock(clu, []v1.Operation{
+		op("new", v1.OperationStatusPaused, time.Minute),
+		op("done", v1.OperationStatusSuccessful, time.Hour),
+		op("old", v1.OperationStatusRunning, 10*time.Minute),
+	})
+	if !lock.Locked || lock.Stale || lock.Holder == nil || lock.Holder.Operation != "old" || lock.Holder.Action != "upgrade" {
+		t.Fatalf("unexpected lock %+v", lock)
+	}
+	if len(lock.Queue) != 1 || lock.Queue[0].Operation != "new" {
+		t.Errorf("unexpected queue %+v", lock.Queue)
+	}
+
+	lock = newClusterLock(clu, []v1.Operation{op("done", v1.OperationStatusFailed, time.Hour)})
+	if !lock.Locked || !lock.Stale || lock.Holder != nil {
+		t.Errorf("expected stale lock, got %+v", lock)
+	}
+
+	clu.Status.Status = v1.ClusterStatusRunning
+	if lock = newClusterLock(clu, nil); lock.Locked {
+		t.Errorf("expected unlocked, got %+v", lock)
+	}
+	if got := lockBrokenClusterStatus(v1.ClusterStatusUpgrading); got != v1.ClusterStatusUpgradeFailed {
+		t.Errorf("lockBrokenClusterStatus() = %s", got)
+	}
+}
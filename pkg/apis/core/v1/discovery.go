@@ -0,0 +1,75 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+const (
+	ParameterProvider = "provider"
+	ParameterRefresh  = "refresh"
+)
+
+// ListDiscoveredNodes lists the machines of the inventories, the machines which
+// are registered already are marked with their node.
+func (h *handler) ListDiscoveredNodes(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	refresh, _ := strconv.ParseBool(request.QueryParameter(ParameterRefresh))
+	machines, failures, err := h.discoverer.List(ctx, request.QueryParameter(ParameterProvider), refresh)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	nodes, err := h.clusterOperator.ListNodes(ctx, &query.Query{
+		Pagination:      query.NoPagination(),
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	nodeIPs := make(map[string]string, len(nodes.Items))
+	for _, node := range nodes.Items {
+		nodeIPs[node.Status.Ipv4DefaultIP] = node.Name
+	}
+	inventory.MarkRegistered(machines, nodeIPs)
+
+	result := inventory.MachineList{Items: machines, TotalCount: len(machines)}
+	if result.Items == nil {
+		result.Items = []inventory.Machine{}
+	}
+	for provider, err := range failures {
+		logger.Warn("list inventory machines failed", zap.String("provider", provider), zap.Error(err))
+		if result.Failures == nil {
+			result.Failures = make(map[string]string)
+		}
+		result.Failures[provider] = err.Error()
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+}
@@ -26,15 +26,19 @@ import (
 	"math/rand"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/remotecommand"
 
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
 
+	"github.com/kubeclipper/kubeclipper/pkg/scheme"
 	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
 
 	"github.com/kubeclipper/kubeclipper/pkg/controller"
 	"github.com/kubeclipper/kubeclipper/pkg/oplog"
@@ -53,12 +57,15 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/validation"
 
 	"github.com/kubeclipper/kubeclipper/pkg/client/clientrest"
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/quota"
 
 	"github.com/kubeclipper/kubeclipper/pkg/models/lease"
 
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
 
 	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
 
 	"github.com/google/uuid"
 
@@ -68,10 +75,15 @@ import (
 	"github.com/emicklei/go-restful"
 	"go.uber.org/zap"
 
+	"github.com/kubeclipper/kubeclipper/pkg/admission"
 	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/iam"
 	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/nodemetrics"
+	"github.com/kubeclipper/kubeclipper/pkg/precheck"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
@@ -85,6 +97,26 @@ type handler struct {
 	opOperator       operation.Operator
 	platformOperator platform.Operator
 	delivery         service.IDelivery
+	prechecks        *precheck.Manager
+	nodeMetrics      *nodemetrics.Store
+	// discoverer lists the machines of inventories which may be joined as nodes
+	discoverer *inventory.Discoverer
+	// staticServerPath is where the offline resource bundles and their metadata.json are served from.
+	staticServerPath string
+	// metadata caches the metadata.json of the static server and reloads it once the file changes.
+	metadata *scheme.MetadataCache
+	// opLister reads the operations from the cache of the API server, opSynced tells whether it is filled.
+	opLister listerv1.OperationLister
+	opSynced cache.InformerSynced
+	// quota admits the clusters and operations within the quotas of their regions.
+	quota *quota.Checker
+	// projects reads the projects the clusters, nodes and backups are assigned to.
+	projects iam.ProjectReader
+
+	webhooks platform.WebhookConfigurationOperator
+	admitter *admission.Admitter
+	// events reads the events recorded about the clusters, nodes, operations and backups.
+	events platform.ObjectEventReader
 }
 
 const (
@@ -93,21 +125,46 @@ const (
 	ParameterCols              = "cols"
 	ParameterRows              = "rows"
 	resourceExistCheckerHeader = "X-CHECK-EXIST"
+	// operationViewFull returns operations with their step commands and responses,
+	// which are left out by default.
+	operationViewFull = "full"
 )
 
 var (
 	ErrNodesRegionDifferent = errors.New("nodes belongs to different region")
 )
 
-func newHandler(clusterOperator cluster.Operator, op operation.Operator, leaseOperator lease.Operator,
-	platform platform.Operator, delivery service.IDelivery) *handler {
-	return &handler{
+func newHandler(clusterOperator cluster.Operator, op operation.Operator, opInformer cache.SharedIndexInformer, leaseOperator lease.Operator,
+	platform platform.Operator, delivery service.IDelivery, nodeMetrics *nodemetrics.Store, discoverer *inventory.Discoverer,
+	projects iam.ProjectReader, webhooks platform.WebhookConfigurationOperator, events platform.ObjectEventReader, admitter *admission.Admitter,
+	staticServerPath string) *handler {
+	h := &handler{
 		clusterOperator:  clusterOperator,
 		delivery:         delivery,
 		opOperator:       op,
 		platformOperator: platform,
 		leaseOperator:    leaseOperator,
-	}
+		prechecks:        precheck.NewManager(),
+		nodeMetrics:      nodeMetrics,
+		discoverer:       discoverer,
+		staticServerPath: staticServerPath,
+		metadata:         scheme.NewMetadataCache(staticServerPath),
+		projects:         projects,
+		webhooks:         webhooks,
+		events:           events,
+		admitter:         admitter,
+	}
+	if platform != nil {
+		h.quota = quota.NewChecker(platform, clusterOperator, clusterOperator, op)
+	}
+	if opInformer != nil {
+		h.opLister = listerv1.NewOperationLister(opInformer.GetIndexer())
+		h.opSynced = opInformer.HasSynced
+	}
+	h.prechecks.OnCompleted(func(j *precheck.Job) {
+		h.recordPrecheck(context.TODO(), j.Record())
+	})
+	return h
 }
 
 func (h *handler) ListClusters(request *restful.Request, response *restful.Response) {
@@ -119,17 +176,18 @@ func (h *handler) ListClusters(request *restful.Request, response *restful.Respo
 	if clientrest.IsInformerRawQuery(request.Request) {
 		result, err := h.clusterOperator.ListClusters(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
 		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
 	} else {
+		selectRecycleBin(request, q)
 		result, err := h.clusterOperator.ListClusterEx(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
-		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+		writeWithFields(request, response, result, q.Fields)
 	}
 }
 
@@ -145,7 +203,10 @@ func (h *handler) DescribeCluster(request *restful.Request, response *restful.Re
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+	// the admin kubeconfig is only served by the adminkubeconfig route
+	c = c.DeepCopy()
+	c.KubeConfig = nil
+	writeWithFields(request, response, c, requestFields(request))
 }
 
 func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.Response) {
@@ -173,6 +234,58 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 		return
 	}
 
+	op, err := h.makeNodesOperation(ctx, c, pn)
+	if err != nil {
+		var badPatch nodesPatchError
+		if errors.As(err, &badPatch) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if op == nil {
+		// No node needs to be operated.
+		_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+		return
+	}
+
+	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
+	if err = h.runningOperationError(ctx, c, "patch nodes"); err != nil {
+		restplus.HandleConflict(response, request, err)
+		return
+	}
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+	if c, err = h.startNodesOperation(ctx, c, op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+// nodesPatchError is an error of makeNodesOperation caused by the nodes patch itself.
+type nodesPatchError struct {
+	error
+}
+
+func (e nodesPatchError) Unwrap() error {
+	return e.error
+}
+
+// makeNodesOperation applies the nodes patch to the cluster and returns the operation
+// adding or removing the nodes, nil when no node needs to be operated.
+func (h *handler) makeNodesOperation(ctx context.Context, c *v1.Cluster, pn *PatchNodes) (*v1.Operation, error) {
+	if err := kubeadmOnlyCheck(c, "adding or removing nodes"); err != nil {
+		return nil, nodesPatchError{err}
+	}
 	// backing up old masters and workers
 	nodeSet := c.GetAllNodes()
 
@@ -180,20 +293,16 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 	extraMeta, err := h.getClusterMetadata(ctx, c)
 	if err != nil {
 		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
-			restplus.HandleBadRequest(response, request, err)
-			return
+			return nil, nodesPatchError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 
 	if err := pn.MakeCompare(c); err != nil {
-		if errors.Is(err, ErrInvalidNodesOperation) || errors.Is(err, ErrInvalidNodesRole) {
-			restplus.HandleBadRequest(response, request, err)
-			return
+		if errors.Is(err, ErrInvalidNodesOperation) || errors.Is(err, ErrInvalidNodesRole) || errors.Is(err, ErrNodePoolNotFound) {
+			return nil, nodesPatchError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 
 	// Get workers node information must be called before pn.MakeCompare, in order to ensure pn.Nodes = extraMeta.Workers.
@@ -201,11 +310,12 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 	nodes, err := h.getNodeInfo(ctx, pn.Nodes)
 	if err != nil {
 		if err == ErrNodesRegionDifferent {
-			restplus.HandleBadRequest(response, request, err)
-			return
+			return nil, nodesPatchError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
+	}
+	if err = windowsNodeCheck(nodes); err != nil {
+		return nil, nodesPatchError{err}
 	}
 
 	if pn.Role == common.NodeRoleWorker {
@@ -213,29 +323,36 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 	}
 
 	if len(nodes) == 0 {
-		restplus.HandleBadRequest(response, request, fmt.Errorf("nodes is already in use"))
-		return
+		return nil, nodesPatchError{fmt.Errorf("nodes is already in use")}
+	}
+
+	if pn.Operation == NodesOperationAdd {
+		if err = h.resourceArchCheck(c, nodes); err != nil {
+			return nil, nodesPatchError{err}
+		}
+		if err = h.checkNodesProject(ctx, c, component.NodeList(nodes).GetNodeIDs()); err != nil {
+			if errors.Is(err, ErrNodesProjectDifferent) {
+				return nil, nodesPatchError{err}
+			}
+			return nil, err
+		}
 	}
 
 	for _, n := range nodes {
 		switch pn.Operation {
 		case NodesOperationAdd:
 			if n.Disable {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("this node(%s) is disabled", n.IPv4))
-				return
+				return nil, nodesPatchError{fmt.Errorf("this node(%s) is disabled", n.IPv4)}
 			}
 			if nodeSet.Has(n.ID) {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("this node(%s) is already in use", n.IPv4))
-				return
+				return nil, nodesPatchError{fmt.Errorf("this node(%s) is already in use", n.IPv4)}
 			}
 			if n.Region != extraMeta.Masters[0].Region {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("the node(%s) belongs to different region", n.IPv4))
-				return
+				return nil, nodesPatchError{fmt.Errorf("the node(%s) belongs to different region", n.IPv4)}
 			}
 		case NodesOperationRemove:
 			if !nodeSet.Has(n.ID) {
-				restplus.HandleBadRequest(response, request, fmt.Errorf("the node(%s) is not part of this cluster and cannot be removed", n.IPv4))
-				return
+				return nil, nodesPatchError{fmt.Errorf("the node(%s) is not part of this cluster and cannot be removed", n.IPv4)}
 			}
 		}
 	}
@@ -243,35 +360,35 @@ func (h *handler) AddOrRemoveNodes(request *restful.Request, response *restful.R
 	op, err := pn.MakeOperation(*extraMeta, c)
 	if err != nil {
 		if errors.Is(err, ErrZeroNode) {
-			// No node needs to be operated.
-			_ = response.WriteHeaderAndEntity(http.StatusOK, c)
-			return
+			return nil, nil
 		} else if errors.Is(err, ErrInvalidNodesOperation) || errors.Is(err, ErrInvalidNodesRole) {
-			restplus.HandleBadRequest(response, request, err)
-			return
+			return nil, nodesPatchError{err}
 		}
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 
-	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
+	op.Labels[common.LabelTimeoutSeconds] = v1.DefaultOperationTimeoutSecs
+	if op.StepPolicies, err = clusterStepPolicies(c); err != nil {
+		return nil, nodesPatchError{err}
+	}
 	op.Status.Status = v1.OperationStatusRunning
-	if !dryRun {
-		c.Status.Status = v1.ClusterStatusUpdating
-		if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
+	return op, nil
+}
+
+// startNodesOperation saves the patched cluster and delivers the operation of its nodes.
+func (h *handler) startNodesOperation(ctx context.Context, c *v1.Cluster, op *v1.Operation) (*v1.Cluster, error) {
+	c.Status.Status = v1.ClusterStatusUpdating
+	c, err := h.clusterOperator.UpdateCluster(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
+		return nil, err
 	}
 
 	// distribute tasks
-	go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
-
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+	go h.doOperation(context.TODO(), op, &service.Options{})
+	return c, nil
 }
 
 func (h *handler) watchCluster(req *restful.Request, resp *restful.Response, q *query.Query) {
@@ -285,12 +402,26 @@ func (h *handler) watchCluster(req *restful.Request, resp *restful.Response, q *
 
 	watcher, err := h.clusterOperator.WatchClusters(req.Request.Context(), q)
 	if err != nil {
-		restplus.HandleInternalError(resp, req, err)
+		handleListError(resp, req, err)
 		return
 	}
 	restplus.ServeWatch(watcher, v1.SchemeGroupVersion.WithKind("Cluster"), req, resp, timeout)
 }
 
+// handleListError answers http.StatusGone when the resource version to watch from or the continue
+// token of a chunked list is compacted, so that clients know to list again from the start.
+func handleListError(resp *restful.Response, req *restful.Request, err error) {
+	switch {
+	case apimachineryErrors.IsResourceExpired(err) || apimachineryErrors.IsGone(err):
+		restplus.HandleGone(resp, req, err)
+	case apimachineryErrors.IsBadRequest(err):
+		// e.g. the continue token is malformed
+		restplus.HandleBadRequest(resp, req, err)
+	default:
+		restplus.HandleInternalError(resp, req, err)
+	}
+}
+
 func (h *handler) DeleteCluster(request *restful.Request, response *restful.Response) {
 	name := request.PathParameter("name")
 	var timeoutSecs string
@@ -300,6 +431,14 @@ func (h *handler) DeleteCluster(request *restful.Request, response *restful.Resp
 		timeoutSecs = v1.DefaultOperationTimeoutSecs
 	}
 	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	propagation := metav1.DeletionPropagation(strutil.StringDefaultIfEmpty(string(metav1.DeletePropagationBackground),
+		request.QueryParameter(query.ParamPropagationPolicy)))
+	switch propagation {
+	case metav1.DeletePropagationBackground, metav1.DeletePropagationForeground, metav1.DeletePropagationOrphan:
+	default:
+		restplus.HandleBadRequest(response, request, fmt.Errorf("unsupported propagation policy %q, support Background, Foreground and Orphan", propagation))
+		return
+	}
 	c, err := h.clusterOperator.GetClusterEx(request.Request.Context(), name, "0")
 	if err != nil {
 		if apimachineryErrors.IsNotFound(err) {
@@ -309,56 +448,114 @@ func (h *handler) DeleteCluster(request *restful.Request, response *restful.Resp
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	setting, err := h.platformOperator.GetPlatformSetting(request.Request.Context())
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.IsDeleteProtected(setting.Cluster.DeleteProtection) {
+		restplus.HandleForbidden(response, request, fmt.Errorf("cluster %s is protected from deletion, set deleteProtection to false first", name))
+		return
+	}
 
-	extraMeta, err := h.getClusterMetadata(request.Request.Context(), c)
+	op, err := h.makeDeleteClusterOperation(request.Request.Context(), c, timeoutSecs)
 	if err != nil {
-		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
+		var badDelete deleteClusterError
+		if errors.As(err, &badDelete) {
 			restplus.HandleBadRequest(response, request, err)
 			return
 		}
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	if err = h.admitClusterDelete(request, c, dryRun); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+	// deleting a cluster in the recycle bin purges it
+	if setting.RecycleBin.Enabled && !v1.IsDeleted(c) {
+		v1.MarkDeleted(c, time.Now())
+		// the cluster is purged with the policy once the retention passes
+		c.Annotations[common.AnnotationDeletionPropagation] = string(propagation)
+		if _, err = h.clusterOperator.UpdateCluster(request.Request.Context(), c); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		response.WriteHeader(http.StatusOK)
+		return
+	}
+	if err = h.startDeleteCluster(request.Request.Context(), c, op, propagation); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// deleteClusterError is the error of a cluster which can't be uninstalled as it is.
+type deleteClusterError struct {
+	error
+}
+
+// makeDeleteClusterOperation builds the operation uninstalling the cluster.
+func (h *handler) makeDeleteClusterOperation(ctx context.Context, c *v1.Cluster, timeoutSecs string) (*v1.Operation, error) {
+	extraMeta, err := h.getClusterMetadata(ctx, c)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
+			return nil, deleteClusterError{err}
+		}
+		return nil, err
+	}
 
 	q := query.New()
 	q.LabelSelector = fmt.Sprintf("%s=%s", common.LabelClusterName, c.Name)
-	backups, err := h.clusterOperator.ListBackupEx(request.Request.Context(), q)
+	backups, err := h.clusterOperator.ListBackupEx(ctx, q)
 	if err != nil {
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 	if backups.TotalCount > 0 {
-		restplus.HandleInternalError(response, request, fmt.Errorf("before deleting the cluster, please delete the cluster backup file first"))
-		return
+		return nil, fmt.Errorf("before deleting the cluster, please delete the cluster backup file first")
 	}
 
 	op, err := h.parseOperationFromCluster(extraMeta, c, v1.ActionUninstall)
 	if err != nil {
-		restplus.HandleInternalError(response, request, err)
-		return
+		return nil, err
 	}
 	op.Status.Status = v1.OperationStatusRunning
 	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
 	op.Labels[common.LabelOperationAction] = v1.OperationDeleteCluster
-	if !dryRun {
-		c.Status.Status = v1.ClusterStatusDeleting
-		_, err = h.clusterOperator.UpdateCluster(request.Request.Context(), c)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		op, err = h.opOperator.CreateOperation(request.Request.Context(), op)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
+	if op.StepPolicies, err = clusterStepPolicies(c); err != nil {
+		return nil, deleteClusterError{err}
+	}
+	return op, nil
+}
+
+// startDeleteCluster marks the cluster deleting and starts the operation uninstalling it,
+// a cluster purged from the recycle bin leaves it.
+func (h *handler) startDeleteCluster(ctx context.Context, c *v1.Cluster, op *v1.Operation, propagation metav1.DeletionPropagation) error {
+	v1.UnmarkDeleted(c)
+	c.Status.Status = v1.ClusterStatusDeleting
+	if c.Annotations == nil {
+		c.Annotations = make(map[string]string)
+	}
+	// the cluster is deleted with the policy once the uninstall operation succeeds
+	c.Annotations[common.AnnotationDeletionPropagation] = string(propagation)
+	if _, err := h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+		return err
+	}
+	op, err := h.opOperator.CreateOperation(ctx, op)
+	if err != nil {
+		return err
 	}
 	go func(o *v1.Operation, opts *service.Options) {
 		if err := h.delivery.DeliverTaskOperation(context.TODO(), o, opts); err != nil {
 			logger.Error("delivery task error", zap.Error(err))
 		}
-	}(op, &service.Options{DryRun: dryRun})
-	response.WriteHeader(http.StatusOK)
+	}(op, &service.Options{})
+	return nil
 }
 
 func (h *handler) CreateClusters(request *restful.Request, response *restful.Response) {
@@ -367,7 +564,10 @@ func (h *handler) CreateClusters(request *restful.Request, response *restful.Res
 		restplus.HandleBadRequest(response, request, err)
 		return
 	}
+	h.createCluster(request, response, c)
+}
 
+func (h *handler) createCluster(request *restful.Request, response *restful.Response, c v1.Cluster) {
 	if c.Labels[common.LabelBackupPoint] != "" {
 		_, err := h.clusterOperator.GetBackupPointEx(request.Request.Context(), c.Labels[common.LabelBackupPoint], "0")
 		if err != nil {
@@ -397,10 +597,45 @@ func (h *handler) CreateClusters(request *restful.Request, response *restful.Res
 		return
 	}
 
+	if err := assignClusterProject(request.Request.Context(), &c); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.checkNodesProject(request.Request.Context(), &c, extraMeta.GetAllNodes().GetNodeIDs()); err != nil {
+		if errors.Is(err, ErrNodesProjectDifferent) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	if !dryRun {
+		h.recordPrecheck(request.Request.Context(), h.clusterCreatePrecheck(request.Request.Context(), &c, extraMeta.GetAllNodes()))
+	}
 	if err := h.createClusterCheck(request.Request.Context(), &c); err != nil {
 		restplus.HandleBadRequest(response, request, err)
 		return
 	}
+	if err := windowsNodeCheck(extraMeta.GetAllNodes()); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.resourceArchCheck(&c, extraMeta.GetAllNodes()); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.compatibilityCheck(&c, c.Kubeadm.KubernetesVersion); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+
+	defaults, err := h.clusterDefaults(request.Request.Context(), extraMeta.Masters[0].Region)
+	if err != nil && !apimachineryErrors.IsNotFound(err) {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	defaults.ApplyTo(&c)
 
 	c.Complete()
 
@@ -410,28 +645,36 @@ func (h *handler) CreateClusters(request *restful.Request, response *restful.Res
 		return
 	}
 
-	// TODO: make dry run path to etcd
-	if !dryRun {
-		c.Status.Status = v1.ClusterStatusInstalling
-		_, err = h.clusterOperator.CreateCluster(context.TODO(), &c)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-	}
-
 	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
 	op.Labels[common.LabelOperationAction] = v1.OperationCreateCluster
+	if op.StepPolicies, err = clusterStepPolicies(&c); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
 	op.Status.Status = v1.OperationStatusRunning
-	if !dryRun {
-		op, err = h.opOperator.CreateOperation(context.TODO(), op)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
+	if err := h.admitCluster(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+
+	c.Status.Status = v1.ClusterStatusInstalling
+	created, err := h.clusterOperator.CreateCluster(request.Request.Context(), &c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	v1.SetOwnerReference(op, v1.NewClusterOwnerReference(created))
+	op, err = h.opOperator.CreateOperation(context.TODO(), op)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
 	}
 
-	go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
+	go h.doOperation(context.TODO(), op, &service.Options{})
 	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
 }
 
@@ -451,6 +694,14 @@ func (h *handler) UpdateClusters(request *restful.Request, response *restful.Res
 			return
 		}
 	}
+	if !c.NodeReconcileMode.Valid() {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("unsupported node reconcile mode %s", c.NodeReconcileMode))
+		return
+	}
+	if _, err := c.OperationHooks(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
 
 	if !dryRun {
 		clu, err := h.clusterOperator.GetCluster(context.TODO(), name)
@@ -463,9 +714,27 @@ func (h *handler) UpdateClusters(request *restful.Request, response *restful.Res
 			return
 		}
 
+		project, inProject := clu.Labels[common.LabelProject]
 		clu.Labels = c.Labels
+		// only the requests across all projects move a cluster between projects
+		if requestProject(request.Request.Context()) != "" {
+			if clu.Labels == nil {
+				clu.Labels = make(map[string]string)
+			}
+			delete(clu.Labels, common.LabelProject)
+			if inProject {
+				clu.Labels[common.LabelProject] = project
+			}
+		}
 		clu.Annotations = c.Annotations
-		_, err = h.clusterOperator.UpdateCluster(context.TODO(), clu)
+		// protection is only changed when the request asks for it explicitly
+		if c.DeleteProtection != nil {
+			clu.DeleteProtection = c.DeleteProtection
+		}
+		if c.NodeReconcileMode != "" {
+			clu.NodeReconcileMode = c.NodeReconcileMode
+		}
+		_, err = h.clusterOperator.UpdateCluster(request.Request.Context(), clu)
 		if err != nil {
 			restplus.HandleInternalError(response, request, err)
 			return
@@ -475,6 +744,29 @@ func (h *handler) UpdateClusters(request *restful.Request, response *restful.Res
 	response.WriteHeader(http.StatusOK)
 }
 
+func (h *handler) DiffCluster(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	desired := v1.Cluster{}
+	if err := request.ReadEntity(&desired); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if desired.Name != "" && desired.Name != name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster name %s does not match the name %s in path", desired.Name, name))
+		return
+	}
+	clu, err := h.clusterOperator.GetClusterEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, v1.DiffCluster(clu, &desired))
+}
+
 func (h *handler) ListNodes(request *restful.Request, response *restful.Response) {
 	q := query.ParseQueryParameter(request)
 	if q.Watch {
@@ -484,7 +776,7 @@ func (h *handler) ListNodes(request *restful.Request, response *restful.Response
 	if clientrest.IsInformerRawQuery(request.Request) {
 		result, err := h.clusterOperator.ListNodes(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
 		// response.PrettyPrint(false)
@@ -492,10 +784,10 @@ func (h *handler) ListNodes(request *restful.Request, response *restful.Response
 	} else {
 		result, err := h.clusterOperator.ListNodesEx(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
-		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+		writeWithFields(request, response, result, q.Fields)
 	}
 }
 
@@ -511,7 +803,20 @@ func (h *handler) DescribeNode(request *restful.Request, response *restful.Respo
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+	writeWithFields(request, response, c, requestFields(request))
+}
+
+func (h *handler) DescribeNodeMetrics(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	if _, err := h.clusterOperator.GetNodeEx(request.Request.Context(), name, "0"); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, v1.NodeMetrics{Node: name, Usage: h.nodeMetrics.Get(name)})
 }
 
 func (h *handler) DisableNode(request *restful.Request, response *restful.Response) {
@@ -606,12 +911,64 @@ func (h *handler) syncNodeDisable(node *v1.Node, reqDisable bool) error {
 }
 
 // DeleteNode delete node record from etcd,only called by kcctl now.
-func (h *handler) DeleteNode(request *restful.Request, response *restful.Response) {
-	name := request.PathParameter(query.ParameterName)
-	resourceVersion := strutil.StringDefaultIfEmpty("0", request.QueryParameter(query.ParameterResourceVersion))
-	ctx := request.Request.Context()
-	_, err := h.clusterOperator.GetNodeEx(ctx, name, resourceVersion)
-	if err != nil {
+func (h *handler) CreateNodePrecheck(request *restful.Request, response *restful.Response) {
+	req := &precheck.Request{}
+	if err := request.ReadEntity(req); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusAccepted, h.prechecks.Submit(req))
+}
+
+func (h *handler) DescribeNodePrecheck(request *restful.Request, response *restful.Response) {
+	id := request.PathParameter(query.ParameterName)
+	if !query.GetBoolValueWithDefault(request, query.ParameterWatch, false) {
+		job, err := h.prechecks.Get(id)
+		if err != nil {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		_ = response.WriteHeaderAndEntity(http.StatusOK, job)
+		return
+	}
+
+	if _, err := h.prechecks.Get(id); err != nil {
+		restplus.HandleNotFound(response, request, err)
+		return
+	}
+	flusher, ok := response.ResponseWriter.(http.Flusher)
+	if !ok {
+		restplus.HandleInternalError(response, request, fmt.Errorf("streaming unsupported"))
+		return
+	}
+	// stream one json encoded result per line as the checks complete
+	response.Header().Set("Content-Type", "application/json")
+	response.Header().Set("Transfer-Encoding", "chunked")
+	response.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	enc := json.NewEncoder(response.ResponseWriter)
+	err := h.prechecks.Watch(request.Request.Context(), id, func(r precheck.Result) error {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Debug("stream node precheck results stopped", zap.String("job", id), zap.Error(err))
+	}
+}
+
+func (h *handler) DeleteNode(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	resourceVersion := strutil.StringDefaultIfEmpty("0", request.QueryParameter(query.ParameterResourceVersion))
+	ctx := request.Request.Context()
+	_, err := h.clusterOperator.GetNodeEx(ctx, name, resourceVersion)
+	if err != nil {
 		if apimachineryErrors.IsNotFound(err) {
 			restplus.HandleNotFound(response, request, err)
 			return
@@ -629,6 +986,7 @@ func (h *handler) DeleteNode(request *restful.Request, response *restful.Respons
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	h.nodeMetrics.Delete(name)
 	response.WriteHeader(http.StatusOK)
 }
 
@@ -643,7 +1001,7 @@ func (h *handler) watchNodes(req *restful.Request, resp *restful.Response, q *qu
 
 	watcher, err := h.clusterOperator.WatchNodes(req.Request.Context(), q)
 	if err != nil {
-		restplus.HandleInternalError(resp, req, err)
+		handleListError(resp, req, err)
 		return
 	}
 	restplus.ServeWatch(watcher, v1.SchemeGroupVersion.WithKind("Node"), req, resp, timeout)
@@ -661,7 +1019,58 @@ func (h *handler) DescribeOperation(request *restful.Request, response *restful.
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+	if request.QueryParameter(query.ParameterView) != operationViewFull {
+		c = c.Summary()
+	}
+	writeWithFields(request, response, c, requestFields(request))
+}
+
+func (h *handler) ListOperationSteps(request *restful.Request, response *restful.Response) {
+	op, ok := h.getOperation(request, response)
+	if !ok {
+		return
+	}
+	steps := make([]v1.OperationStep, 0, len(op.Steps))
+	for i := range op.Steps {
+		s, _ := op.OperationStep(i)
+		s.Step = s.Step.Summary()
+		for j := range s.Status {
+			s.Status[j].Response = nil
+		}
+		steps = append(steps, s)
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, steps)
+}
+
+func (h *handler) DescribeOperationStep(request *restful.Request, response *restful.Response) {
+	op, ok := h.getOperation(request, response)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(request.PathParameter(query.ParameterStep))
+	if err != nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("invalid step index %q", request.PathParameter(query.ParameterStep)))
+		return
+	}
+	s, ok := op.OperationStep(n)
+	if !ok {
+		restplus.HandleNotFound(response, request, fmt.Errorf("operation %s has no step %d", op.Name, n))
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, s)
+}
+
+func (h *handler) getOperation(request *restful.Request, response *restful.Response) (*v1.Operation, bool) {
+	op, err := h.opOperator.GetOperationEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return nil, false
+		}
+		restplus.HandleInternalError(response, request, err)
+		return nil, false
+	}
+	return op, true
 }
 
 func (h *handler) ListOperations(request *restful.Request, response *restful.Response) {
@@ -673,17 +1082,24 @@ func (h *handler) ListOperations(request *restful.Request, response *restful.Res
 	if clientrest.IsInformerRawQuery(request.Request) {
 		result, err := h.opOperator.ListOperations(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
 		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
 	} else {
 		result, err := h.opOperator.ListOperationsEx(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
-		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+		if request.QueryParameter(query.ParameterView) != operationViewFull {
+			for i := range result.Items {
+				if op, ok := result.Items[i].(*v1.Operation); ok {
+					result.Items[i] = op.Summary()
+				}
+			}
+		}
+		writeWithFields(request, response, result, q.Fields)
 	}
 }
 
@@ -694,7 +1110,7 @@ func (h *handler) watchOperations(req *restful.Request, resp *restful.Response,
 	}
 	watcher, err := h.opOperator.WatchOperations(req.Request.Context(), q)
 	if err != nil {
-		restplus.HandleInternalError(resp, req, err)
+		handleListError(resp, req, err)
 		return
 	}
 	restplus.ServeWatch(watcher, v1.SchemeGroupVersion.WithKind("Operation"), req, resp, timeout)
@@ -702,11 +1118,18 @@ func (h *handler) watchOperations(req *restful.Request, resp *restful.Response,
 
 func (h *handler) getClusterMetadata(ctx context.Context, c *v1.Cluster) (*component.ExtraMetadata, error) {
 	meta := &component.ExtraMetadata{
-		ClusterName:   c.Name,
-		Offline:       c.Kubeadm.Offline,
-		LocalRegistry: c.Kubeadm.LocalRegistry,
-		CRI:           c.Kubeadm.ContainerRuntime.Type.String(),
-		KubeVersion:   c.Kubeadm.KubernetesVersion,
+		ClusterName:    c.Name,
+		Offline:        c.Kubeadm.Offline,
+		LocalRegistry:  c.Kubeadm.LocalRegistry,
+		CRI:            c.Kubeadm.ContainerRuntime.Type.String(),
+		KubeVersion:    c.Kubeadm.KubernetesVersion,
+		KubeletDataDir: c.Kubeadm.KubeComponents.Kubelet.RootDir,
+	}
+	switch c.Kubeadm.ContainerRuntime.Type {
+	case v1.CRIDocker:
+		meta.CRIDataDir = c.Kubeadm.ContainerRuntime.Docker.DataRootDir
+	case v1.CRIContainerd:
+		meta.CRIDataDir = c.Kubeadm.ContainerRuntime.Containerd.DataRootDir
 	}
 	masters, err := h.getNodeInfo(ctx, c.Kubeadm.Masters)
 	if err != nil {
@@ -725,6 +1148,101 @@ func (h *handler) getClusterMetadata(ctx context.Context, c *v1.Cluster) (*compo
 	return meta, nil
 }
 
+// resourceArchCheck makes sure the static server hosts the kubernetes and container runtime
+// bundles built for the architecture of every node joining an offline cluster.
+func (h *handler) resourceArchCheck(c *v1.Cluster, nodes component.NodeList) error {
+	if !c.Kubeadm.Offline || h.staticServerPath == "" {
+		return nil
+	}
+	metas, err := h.metadata.Get()
+	if err != nil {
+		return err
+	}
+	archs := nodes.GetArchs()
+	pkg := k8s.PackageName(c.ClusterType)
+	if missing := metas.MissingArchs(pkg, c.Kubeadm.KubernetesVersion, archs); len(missing) > 0 {
+		return fmt.Errorf("%s %s offline package is not available for %s", pkg, c.Kubeadm.KubernetesVersion, strings.Join(missing, ","))
+	}
+	if c.ClusterType == v1.ClusterK3s {
+		return nil
+	}
+	cri := c.Kubeadm.ContainerRuntime
+	criVersion := cri.Containerd.Version
+	if cri.Type == v1.CRIDocker {
+		criVersion = cri.Docker.Version
+	}
+	if criVersion == "" {
+		return nil
+	}
+	if missing := metas.MissingArchs(cri.Type.String(), criVersion, archs); len(missing) > 0 {
+		return fmt.Errorf("%s %s offline package is not available for %s", cri.Type, criVersion, strings.Join(missing, ","))
+	}
+	return nil
+}
+
+// compatibilityCheck validates the kubernetes, container runtime, cni and component versions of the cluster
+// against the compatible ranges declared in the metadata of the static server.
+func (h *handler) compatibilityCheck(c *v1.Cluster, kubeVersion string) error {
+	if h.staticServerPath == "" {
+		return nil
+	}
+	metas, err := h.metadata.Get()
+	if err != nil {
+		return err
+	}
+	if len(metas) == 0 {
+		return nil
+	}
+	selected := []scheme.Selection{{Name: k8s.PackageName(c.ClusterType), Version: kubeVersion}}
+	// k3s embeds its container runtime and cni.
+	if c.ClusterType != v1.ClusterK3s {
+		cri := c.Kubeadm.ContainerRuntime
+		criVersion := cri.Containerd.Version
+		if cri.Type == v1.CRIDocker {
+			criVersion = cri.Docker.Version
+		}
+		if criVersion != "" {
+			selected = append(selected, scheme.Selection{Name: cri.Type.String(), Version: criVersion})
+		}
+		cni := c.Kubeadm.KubeComponents.CNI
+		cniVersion := cni.Calico.Version
+		if cni.Type == "flannel" {
+			cniVersion = cni.Flannel.Version
+		}
+		if cni.Type != "" && cniVersion != "" {
+			selected = append(selected, scheme.Selection{Name: cni.Type, Version: cniVersion})
+			if c.Kubeadm.Networking.HasIPv6() {
+				if err := metas.MissingFeature(selected[len(selected)-1], v1.FeatureIPv6); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for _, comp := range c.Kubeadm.Components {
+		selected = append(selected, scheme.Selection{Name: comp.Name, Version: comp.Version})
+	}
+	return metas.CheckCompatibility(selected)
+}
+
+// kubeadmOnlyCheck refuses the operations which only render steps for kubeadm clusters.
+func kubeadmOnlyCheck(c *v1.Cluster, operation string) error {
+	if c.ClusterType == v1.ClusterK3s {
+		return fmt.Errorf("%s is not supported by k3s clusters", operation)
+	}
+	return nil
+}
+
+// windowsNodeCheck refuses windows nodes, they register with the platform already
+// but every cluster operation still renders linux steps.
+func windowsNodeCheck(nodes []component.Node) error {
+	for _, node := range nodes {
+		if node.OSFamily == string(osutil.FamilyWindows) {
+			return fmt.Errorf("node %s runs windows, which is not supported by cluster operations yet", node.ID)
+		}
+	}
+	return nil
+}
+
 func (h *handler) regionCheck(master, worker []component.Node) error {
 	list := sets.NewString()
 	for _, node := range master {
@@ -752,6 +1270,8 @@ func (h *handler) getNodeInfo(ctx context.Context, nodes v1.WorkerNodeList) ([]c
 			Region:   n.Labels[common.LabelTopologyRegion],
 			Hostname: n.Labels[common.LabelHostname],
 			Role:     n.Labels[common.LabelNodeRole],
+			Arch:     downloader.NormalizeArch(n.Status.NodeInfo.Arch),
+			OSFamily: n.Status.NodeInfo.OSFamily,
 		}
 		_, item.Disable = n.Labels[common.LabelNodeDisable]
 		meta = append(meta, item)
@@ -858,7 +1378,7 @@ func (h *handler) ListRegions(request *restful.Request, response *restful.Respon
 			restplus.HandleInternalError(response, request, err)
 			return
 		}
-		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+		writeWithFields(request, response, result, q.Fields)
 	}
 }
 
@@ -891,7 +1411,155 @@ func (h *handler) DescribeRegion(request *restful.Request, response *restful.Res
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+	writeWithFields(request, response, c, requestFields(request))
+}
+
+func (h *handler) DescribeRegionClusterDefaults(request *restful.Request, response *restful.Response) {
+	d, err := h.clusterDefaults(request.Request.Context(), request.PathParameter(query.ParameterName))
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, d)
+}
+
+func (h *handler) UpdateRegionClusterDefaults(request *restful.Request, response *restful.Response) {
+	d := &v1.ClusterDefaults{}
+	if err := request.ReadEntity(d); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := d.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	region, err := h.clusterOperator.GetRegionEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	region = region.DeepCopy()
+	region.ClusterDefaults = d
+	region, err = h.clusterOperator.UpdateRegion(request.Request.Context(), region)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, region)
+}
+
+// clusterDefaults merges the platform cluster defaults with the ones of the region.
+func (h *handler) clusterDefaults(ctx context.Context, region string) (v1.ClusterDefaults, error) {
+	setting, err := h.platformOperator.GetPlatformSetting(ctx)
+	if err != nil {
+		return v1.ClusterDefaults{}, err
+	}
+	layers := []*v1.ClusterDefaults{&setting.ClusterDefaults}
+	if region != "" {
+		r, err := h.clusterOperator.GetRegionEx(ctx, region, "0")
+		if err != nil {
+			return v1.ClusterDefaults{}, err
+		}
+		layers = append(layers, r.ClusterDefaults)
+	}
+	return v1.MergeClusterDefaults(layers...), nil
+}
+
+func (h *handler) PrewarmRegion(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	p := v1.RegionPrewarm{}
+	if err := request.ReadEntity(&p); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if p.KubernetesVersion == "" {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("kubernetes version must be specified"))
+		return
+	}
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+
+	ctx := request.Request.Context()
+	if _, err := h.clusterOperator.GetRegionEx(ctx, name, "0"); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	// only nodes that are not part of a cluster are prewarmed
+	nodeList, err := h.clusterOperator.ListNodes(ctx, &query.Query{
+		Pagination:           query.NoPagination(),
+		ResourceVersion:      "0",
+		LabelSelector:        fmt.Sprintf("%s=%s,!%s,!%s", common.LabelTopologyRegion, name, common.LabelNodeRole, common.LabelNodeDisable),
+		ResourceVersionMatch: query.ResourceVersionMatchNotOlderThan,
+	})
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	wanted := sets.NewString(p.Nodes...)
+	var nodes []v1.StepNode
+	for _, n := range nodeList.Items {
+		if wanted.Len() > 0 && !wanted.Has(n.Name) {
+			continue
+		}
+		wanted.Delete(n.Name)
+		nodes = append(nodes, v1.StepNode{
+			ID:       n.Name,
+			IPv4:     n.Status.Ipv4DefaultIP,
+			Hostname: n.Labels[common.LabelHostname],
+			Arch:     downloader.NormalizeArch(n.Status.NodeInfo.Arch),
+			OSFamily: n.Status.NodeInfo.OSFamily,
+		})
+	}
+	if wanted.Len() > 0 {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("nodes %v are not free nodes of region %s", wanted.List(), name))
+		return
+	}
+	if len(nodes) == 0 {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("region %s has no free nodes to prewarm", name))
+		return
+	}
+
+	steps, err := getPrewarmSteps(&p, nodes)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelTopologyRegion:  name,
+		common.LabelOperationAction: v1.OperationPrewarmNodes,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+	}
+	op.Steps = steps
+	op.Status.Status = v1.OperationStatusRunning
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+	op, err = h.opOperator.CreateOperation(context.TODO(), op)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	go h.doOperation(context.TODO(), op, &service.Options{})
+	_ = response.WriteHeaderAndEntity(http.StatusOK, op)
 }
 
 // doOperation should be called in goroutine.
@@ -905,6 +1573,51 @@ func (h *handler) createClusterCheck(ctx context.Context, c *v1.Cluster) error {
 	if len(c.Kubeadm.Masters) == 0 {
 		return fmt.Errorf("cluster must have one master node")
 	}
+	if !c.NodeReconcileMode.Valid() {
+		return fmt.Errorf("unsupported node reconcile mode %s", c.NodeReconcileMode)
+	}
+	switch c.ClusterType {
+	case "", v1.ClusterKubeadm:
+	case v1.ClusterK3s:
+		if err := (*k8s.K3sRunnable)(c.Kubeadm).Validate(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported cluster type %s", c.ClusterType)
+	}
+	if err := c.Kubeadm.KubeComponents.CNI.NodeInterface.Validate(); err != nil {
+		return err
+	}
+	if err := c.Kubeadm.Networking.Validate(c.Kubeadm.KubernetesVersion); err != nil {
+		return err
+	}
+	if err := c.Kubeadm.KubeComponents.Kubelet.Swap.Validate(c.Kubeadm.KubernetesVersion); err != nil {
+		return err
+	}
+	if err := c.Kubeadm.ContainerRuntime.Containerd.ValidateRegistries(); err != nil {
+		return err
+	}
+	if err := v1.ValidateCertSANs(c.Kubeadm.CertSANs); err != nil {
+		return err
+	}
+	if err := v1.ValidateExternalEndpoint(c.Kubeadm.ExternalEndpoint); err != nil {
+		return err
+	}
+	if err := c.Kubeadm.ControlPlaneVIP.Validate(); err != nil {
+		return err
+	}
+	if err := c.Kubeadm.Proxy.Validate(); err != nil {
+		return err
+	}
+	if err := c.Kubeadm.ValidateNodePools(); err != nil {
+		return err
+	}
+	if err := (&k8s.KubeadmConfig{}).InitStepper(c.Kubeadm, &component.ExtraMetadata{ClusterName: c.Name}).ValidatePatches(); err != nil {
+		return err
+	}
+	if _, err := c.OperationHooks(); err != nil {
+		return err
+	}
 
 	cluInfo, err := h.clusterOperator.GetClusterEx(ctx, c.Name, "0")
 	if err != nil && !apimachineryErrors.IsNotFound(err) {
@@ -959,13 +1672,14 @@ func (h *handler) ListBackupsWithCluster(request *restful.Request, response *res
 	q := query.ParseQueryParameter(request)
 	labels := []string{fmt.Sprintf("%s=%s", common.LabelClusterName, cluster.Name)} // always select by cluster name
 	q.LabelSelector = strings.Join(labels, ",")
+	selectRecycleBin(request, q)
 	result, err := h.clusterOperator.ListBackupEx(ctx, q)
 	if err != nil {
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
 
-	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+	writeWithFields(request, response, result, q.Fields)
 }
 
 func (h *handler) ListBackups(request *restful.Request, response *restful.Response) {
@@ -977,17 +1691,18 @@ func (h *handler) ListBackups(request *restful.Request, response *restful.Respon
 	if clientrest.IsInformerRawQuery(request.Request) {
 		result, err := h.clusterOperator.ListBackups(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
 		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
 	} else {
+		selectRecycleBin(request, q)
 		result, err := h.clusterOperator.ListBackupEx(request.Request.Context(), q)
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			handleListError(response, request, err)
 			return
 		}
-		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+		writeWithFields(request, response, result, q.Fields)
 	}
 }
 
@@ -1003,7 +1718,7 @@ func (h *handler) DescribeBackup(request *restful.Request, response *restful.Res
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+	writeWithFields(request, response, c, requestFields(request))
 }
 
 func (h *handler) watchBackups(req *restful.Request, resp *restful.Response, q *query.Query) {
@@ -1017,7 +1732,7 @@ func (h *handler) watchBackups(req *restful.Request, resp *restful.Response, q *
 
 	watcher, err := h.clusterOperator.WatchBackups(req.Request.Context(), q)
 	if err != nil {
-		restplus.HandleInternalError(resp, req, err)
+		handleListError(resp, req, err)
 		return
 	}
 	restplus.ServeWatch(watcher, v1.SchemeGroupVersion.WithKind("Backup"), req, resp, timeout)
@@ -1044,9 +1759,12 @@ func (h *handler) CreateBackup(request *restful.Request, response *restful.Respo
 		return
 	}
 
-	if c.Status.Status != v1.ClusterStatusRunning {
-		restplus.HandleInternalError(response, request, fmt.Errorf("cluster %s current is %s, can't back up",
-			c.Name, c.Status.Status))
+	if err := kubeadmOnlyCheck(c, "backup"); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err = h.clusterIdleError(ctx, c, "back up"); err != nil {
+		restplus.HandleConflict(response, request, err)
 		return
 	}
 
@@ -1066,7 +1784,7 @@ func (h *handler) CreateBackup(request *restful.Request, response *restful.Respo
 		return
 	}
 
-	_, err = h.clusterOperator.GetBackupPoint(ctx, c.Labels[common.LabelBackupPoint], "0")
+	bp, err := h.clusterOperator.GetBackupPoint(ctx, c.Labels[common.LabelBackupPoint], "0")
 	if err != nil {
 		if apimachineryErrors.IsNotFound(err) {
 			restplus.HandleBadRequest(response, request, fmt.Errorf("backup point not found"))
@@ -1075,6 +1793,20 @@ func (h *handler) CreateBackup(request *restful.Request, response *restful.Respo
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	if bp.Quota != nil {
+		backups, err := h.clusterOperator.ListBackups(ctx, query.New())
+		if err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		if usage := bp.ComputeUsage(backups.Items); usage.Full {
+			logger.Warn("backup rejected, backup point is full", zap.String("cluster", clusterName),
+				zap.String("backupPoint", bp.Name), zap.Int64("usedBytes", usage.UsedBytes), zap.String("quota", bp.Quota.String()))
+			restplus.HandleForbidden(response, request, fmt.Errorf("backup point %s is full: %d bytes used, quota %s",
+				bp.Name, usage.UsedBytes, bp.Quota.String()))
+			return
+		}
+	}
 
 	backup.Name = fmt.Sprintf("%s-%s", backup.Name, clusterName)
 	backup.KubernetesVersion = c.Kubeadm.KubernetesVersion
@@ -1116,39 +1848,50 @@ func (h *handler) CreateBackup(request *restful.Request, response *restful.Respo
 	op.Labels[common.LabelBackupName] = backup.Name
 	op.Labels[common.LabelTopologyRegion] = c.Kubeadm.Masters[0].Labels[common.LabelTopologyRegion]
 	op.Status.Status = v1.OperationStatusRunning
+	v1.SetOwnerReference(op, v1.NewClusterOwnerReference(c))
 	// add backup
+	v1.SetOwnerReference(backup, v1.NewClusterOwnerReference(c))
 	backup.Labels = make(map[string]string)
 	backup.Labels[common.LabelClusterName] = c.Name
 	backup.Labels[common.LabelOperationName] = op.Name
+	if project := c.Labels[common.LabelProject]; project != "" {
+		backup.Labels[common.LabelProject] = project
+	}
 	backup.Labels[common.LabelTimeoutSeconds] = strconv.Itoa(v1.DefaultBackupTimeoutSec)
 	backup.ClusterNodes = make(map[string]string)
 	for _, node := range nodeList.Items {
 		backup.ClusterNodes[node.Status.Ipv4DefaultIP] = node.Status.NodeInfo.Hostname
 	}
 
-	if !dryRun {
-		op.Steps, err = h.parseActBackupSteps(c, backup, v1.ActionInstall)
-		if err != nil {
-			logger.Errorf("parse create backup step failed: %s", err.Error())
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		if op, err = h.opOperator.CreateOperation(context.TODO(), op); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		if backup, err = h.clusterOperator.CreateBackup(context.TODO(), backup); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		// update cluster status to backing_up
-		c.Status.Status = v1.ClusterStatusBackingUp
-		if _, err = h.clusterOperator.UpdateCluster(context.TODO(), c); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
+	op.Steps, err = h.parseActBackupSteps(c, backup, v1.ActionInstall)
+	if err != nil {
+		logger.Errorf("parse create backup step failed: %s", err.Error())
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+	if op, err = h.opOperator.CreateOperation(context.TODO(), op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if backup, err = h.clusterOperator.CreateBackup(request.Request.Context(), backup); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	// update cluster status to backing_up
+	c.Status.Status = v1.ClusterStatusBackingUp
+	if _, err = h.clusterOperator.UpdateCluster(context.TODO(), c); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
 	}
+	go h.doOperation(context.TODO(), op, &service.Options{})
 	_ = response.WriteHeaderAndEntity(http.StatusOK, backup)
 }
 
@@ -1178,12 +1921,47 @@ func (h *handler) DeleteBackup(request *restful.Request, response *restful.Respo
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	if b.PreferredNode != "" {
-		_, err = h.clusterOperator.GetNodeEx(ctx, b.PreferredNode, "0")
-		if err != nil {
+	if b.Status.ClusterBackupStatus == v1.ClusterBackupRestoring || b.Status.ClusterBackupStatus == v1.ClusterBackupCreating {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup is %s now, can't delete", b.Status.ClusterBackupStatus))
+		return
+	}
+	op, err := h.makeDeleteBackupOperation(ctx, c, b)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+	setting, err := h.platformOperator.GetPlatformSetting(ctx)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	// deleting a backup in the recycle bin purges it
+	if setting.RecycleBin.Enabled && !v1.IsDeleted(b) {
+		v1.MarkDeleted(b, time.Now())
+		if _, err = h.clusterOperator.UpdateBackup(ctx, b); err != nil {
 			restplus.HandleInternalError(response, request, err)
 			return
 		}
+		response.WriteHeader(http.StatusOK)
+		return
+	}
+	if err = h.startDeleteBackup(ctx, b, op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// makeDeleteBackupOperation builds the operation removing the backup files.
+func (h *handler) makeDeleteBackupOperation(ctx context.Context, c *v1.Cluster, b *v1.Backup) (*v1.Operation, error) {
+	if b.PreferredNode != "" {
+		if _, err := h.clusterOperator.GetNodeEx(ctx, b.PreferredNode, "0"); err != nil {
+			return nil, err
+		}
 	}
 	// create operation
 	op := &v1.Operation{}
@@ -1196,35 +1974,27 @@ func (h *handler) DeleteBackup(request *restful.Request, response *restful.Respo
 	op.Labels[common.LabelTopologyRegion] = c.Kubeadm.Masters[0].Labels[common.LabelTopologyRegion]
 	op.Status.Status = v1.OperationStatusRunning
 
-	if b.Status.ClusterBackupStatus == v1.ClusterBackupRestoring || b.Status.ClusterBackupStatus == v1.ClusterBackupCreating {
-		restplus.HandleBadRequest(response, request, fmt.Errorf("backup is %s now, can't delete", b.Status.ClusterBackupStatus))
-		return
+	// build the backup steps instance
+	var err error
+	op.Steps, err = h.parseActBackupSteps(c, b, v1.ActionUninstall)
+	if err != nil {
+		logger.Errorf("delete backup step parse failed: %s", err.Error())
+		return nil, err
 	}
+	return op, nil
+}
 
-	if !dryRun {
-		c, err = h.clusterOperator.GetCluster(ctx, clusterName)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		// build the backup steps instance
-		op.Steps, err = h.parseActBackupSteps(c, b, v1.ActionUninstall)
-		if err != nil {
-			logger.Errorf("delete backup step parse failed: %s", err.Error())
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		if op, err = h.opOperator.CreateOperation(context.TODO(), op); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		if err = h.clusterOperator.DeleteBackup(context.TODO(), backupName); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
+// startDeleteBackup deletes the backup and starts the operation removing its files.
+func (h *handler) startDeleteBackup(ctx context.Context, b *v1.Backup, op *v1.Operation) error {
+	op, err := h.opOperator.CreateOperation(context.TODO(), op)
+	if err != nil {
+		return err
 	}
-	response.WriteHeader(http.StatusOK)
+	if err = h.clusterOperator.DeleteBackup(ctx, b.Name); err != nil {
+		return err
+	}
+	go h.doOperation(context.TODO(), op, &service.Options{})
+	return nil
 }
 
 func (h *handler) UpdateBackup(request *restful.Request, response *restful.Response) {
@@ -1266,6 +2036,7 @@ func (h *handler) UpdateBackup(request *restful.Request, response *restful.Respo
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	restplus.SetETag(response, backup)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, backup)
 }
 
@@ -1311,7 +2082,8 @@ func (h *handler) RetryCluster(request *restful.Request, response *restful.Respo
 		return
 	}
 
-	// error step index
+	// error step index, the steps skipped by a cancel run again
+	op.Status.TrimSkippedConditions()
 	failedIndex := len(op.Status.Conditions) - 1
 	ctx := component.WithRetry(context.TODO(), true)
 
@@ -1368,27 +2140,31 @@ func (h *handler) RetryCluster(request *restful.Request, response *restful.Respo
 
 	op.Status.Status = v1.OperationStatusRunning
 
-	if !dryRun {
-		_, err = h.opOperator.UpdateOperation(context.TODO(), op)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		var c *v1.Cluster
-		if c, err = h.clusterOperator.GetClusterEx(context.TODO(), op.Labels[common.LabelClusterName], "0"); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-		c.Status.Status = v1.ClusterStatusUpdating
-		if _, err = h.clusterOperator.UpdateCluster(context.TODO(), c); err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
+	if dryRun {
+		retry := op.DeepCopy()
+		retry.Steps = continueSteps
+		h.writeOperationPlan(request, response, retry)
+		return
+	}
+	_, err = h.opOperator.UpdateOperation(context.TODO(), op)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	var c *v1.Cluster
+	if c, err = h.clusterOperator.GetClusterEx(context.TODO(), op.Labels[common.LabelClusterName], "0"); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	c.Status.Status = v1.ClusterStatusUpdating
+	if _, err = h.clusterOperator.UpdateCluster(context.TODO(), c); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
 	}
 
 	op.Steps = continueSteps
 
-	go h.doOperation(ctx, op, &service.Options{DryRun: dryRun})
+	go h.doOperation(ctx, op, &service.Options{})
 	_ = response.WriteHeaderAndEntity(http.StatusOK, nil)
 }
 
@@ -1413,6 +2189,10 @@ func (h *handler) CreateRecovery(request *restful.Request, response *restful.Res
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	if err := kubeadmOnlyCheck(c, "recovery"); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
 
 	q := query.New()
 	q.LabelSelector = fmt.Sprintf("%s=%s", common.LabelClusterName, c.Name)
@@ -1431,6 +2211,10 @@ func (h *handler) CreateRecovery(request *restful.Request, response *restful.Res
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	if v1.IsDeleted(b) {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup %s is in the recycle bin, restore it first", b.Name))
+		return
+	}
 
 	for _, node := range nodeList.Items {
 		val, ok := b.ClusterNodes[node.Status.Ipv4DefaultIP]
@@ -1450,8 +2234,11 @@ func (h *handler) CreateRecovery(request *restful.Request, response *restful.Res
 
 	switch c.Status.Status {
 	case v1.ClusterStatusRestoring, v1.ClusterStatusBackingUp, v1.ClusterStatusDeleting, v1.ClusterStatusUpdating, v1.ClusterStatusInstalling:
-		restplus.HandleInternalError(response, request, fmt.Errorf("cluster %s current is %s, can't recovery",
-			c.Name, c.Status.Conditions[0].OperationStatus))
+		restplus.HandleConflict(response, request, h.clusterLockedError(request.Request.Context(), c, "recovery"))
+		return
+	}
+	if err = h.runningOperationError(request.Request.Context(), c, "recovery"); err != nil {
+		restplus.HandleConflict(response, request, err)
 		return
 	}
 
@@ -1471,24 +2258,36 @@ func (h *handler) CreateRecovery(request *restful.Request, response *restful.Res
 	o.Labels[common.LabelRecoveryName] = rName
 	o.Labels[common.LabelTopologyRegion] = c.Kubeadm.Masters[0].Labels[common.LabelTopologyRegion]
 	o.Status.Status = v1.OperationStatusRunning
+	v1.SetOwnerReference(o, v1.NewClusterOwnerReference(c))
 
 	// add recovery
 	r.Name = rName
+	v1.SetOwnerReference(r, v1.NewBackupOwnerReference(b))
 	r.Labels = make(map[string]string)
 	r.Labels[common.LabelClusterName] = c.Name
 	r.Labels[common.LabelOperationName] = oName
 	r.Labels[common.LabelTimeoutSeconds] = strconv.Itoa(v1.DefaultBackupTimeoutSec)
 
-	if !dryRun {
-		if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+	restoreDir := filepath.Join("/var/lib/kube-restore", c.Name)
+	if err := h.admitOperation(request.Request.Context(), o); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		if o.Steps, err = h.parseRecoverySteps(c, b, restoreDir, v1.ActionInstall); err != nil {
 			restplus.HandleInternalError(response, request, err)
 			return
 		}
+		h.writeOperationPlan(request, response, o)
+		return
+	}
+	if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
 	}
 
 	go func(c *v1.Cluster, op *v1.Operation, r *v1.Recovery, b *v1.Backup) {
 		var err error
-		restoreDir := filepath.Join("/var/lib/kube-restore", c.Name)
 		steps, err := h.parseRecoverySteps(c, b, restoreDir, v1.ActionInstall)
 		if err != nil {
 			logger.Errorf("recovery step parse failed: %s", err.Error())
@@ -1506,7 +2305,7 @@ func (h *handler) CreateRecovery(request *restful.Request, response *restful.Res
 			restplus.HandleInternalError(response, request, err)
 			return
 		}
-		h.doOperation(context.TODO(), newOP, &service.Options{DryRun: dryRun})
+		h.doOperation(context.TODO(), newOP, &service.Options{})
 	}(c, o, r, b)
 
 	_ = response.WriteHeaderAndEntity(http.StatusOK, r)
@@ -1564,48 +2363,155 @@ func (h *handler) InstallOrUninstallPlugins(request *restful.Request, response *
 		restplus.HandleBadRequest(response, request, errors.New("the current operation steps is empty and cannot be performed"))
 		return
 	}
-	if !dryRun {
-		clu.Status.Status = v1.ClusterStatusUpdating
-		_, err = h.clusterOperator.UpdateCluster(context.TODO(), clu)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-	}
 	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
 	op.Labels[common.LabelOperationAction] = operationAction
+	if op.StepPolicies, err = clusterStepPolicies(clu); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
 	op.Status.Status = v1.OperationStatusRunning
-	if !dryRun {
-		op, err = h.opOperator.CreateOperation(context.TODO(), op)
+	if err = h.runningOperationError(ctx, clu, "update components"); err != nil {
+		restplus.HandleConflict(response, request, err)
+		return
+	}
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+	clu.Status.Status = v1.ClusterStatusUpdating
+	_, err = h.clusterOperator.UpdateCluster(context.TODO(), clu)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	op, err = h.opOperator.CreateOperation(context.TODO(), op)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	go func(o *v1.Operation, opts *service.Options, oPcs *PatchComponents) {
+		if err := h.delivery.DeliverTaskOperation(context.TODO(), o, opts); err != nil {
+			logger.Error("delivery task error", zap.Error(err))
+			return
+		}
+		logger.Debugf("the install or uninstall plugins message was delivered successfully")
+		// the database is not updated until the message is delivered successfully
+		latestCluster, err := h.clusterOperator.GetClusterEx(ctx, clusterName, "0")
 		if err != nil {
-			restplus.HandleInternalError(response, request, err)
+			logger.Error("get the latest cluster info error", zap.Error(err))
+			return
+		}
+		newCluster, err := oPcs.addOrRemoveComponentFromCluster(latestCluster)
+		if err != nil {
+			logger.Error("add or remove component from cluster", zap.Error(err))
 			return
 		}
+		for _, r := range oPcs.componentRevisions(o.Name) {
+			newCluster.Status.AddComponentRevision(r)
+		}
+		_, err = h.clusterOperator.UpdateCluster(context.TODO(), newCluster)
+		if err != nil {
+			logger.Error("update cluster metadata error", zap.Error(err))
+		}
+	}(op, &service.Options{}, pcs)
+
+	_ = response.WriteHeaderAndEntity(http.StatusOK, clu)
+}
+
+func (h *handler) UpgradeComponents(request *restful.Request, response *restful.Response) {
+	ucs := &UpgradeComponents{}
+	if err := request.ReadEntity(ucs); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
 	}
-	go func(o *v1.Operation, opts *service.Options, oPcs *PatchComponents) {
+	clusterName := request.PathParameter("cluster")
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	timeoutSecs := v1.DefaultOperationTimeoutSecs
+	if v := request.QueryParameter("timeout"); v != "" {
+		timeoutSecs = v
+	}
+
+	ctx := request.Request.Context()
+	clu, err := h.clusterOperator.GetClusterEx(ctx, clusterName, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	extraMeta, err := h.getClusterMetadata(ctx, clu)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	upgrades, err := ucs.makeUpgrades(clu)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	targets := make([]v1.Component, 0, len(upgrades))
+	for _, u := range upgrades {
+		targets = append(targets, u.Target)
+	}
+	op, err := h.parseOperationFromComponent(extraMeta, targets, clu, v1.ActionUpgrade)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
+	op.Labels[common.LabelOperationAction] = v1.OperationUpgradeComponents
+	if op.StepPolicies, err = clusterStepPolicies(clu); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	op.Status.Status = v1.OperationStatusRunning
+	if err = h.runningOperationError(ctx, clu, "update components"); err != nil {
+		restplus.HandleConflict(response, request, err)
+		return
+	}
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+	clu.Status.Status = v1.ClusterStatusUpdating
+	if _, err = h.clusterOperator.UpdateCluster(context.TODO(), clu); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	op, err = h.opOperator.CreateOperation(context.TODO(), op)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	go func(o *v1.Operation, opts *service.Options) {
 		if err := h.delivery.DeliverTaskOperation(context.TODO(), o, opts); err != nil {
 			logger.Error("delivery task error", zap.Error(err))
 			return
 		}
-		logger.Debugf("the install or uninstall plugins message was delivered successfully")
 		// the database is not updated until the message is delivered successfully
-		if !opts.DryRun {
-			latestCluster, err := h.clusterOperator.GetClusterEx(ctx, clusterName, "0")
-			if err != nil {
-				logger.Error("get the latest cluster info error", zap.Error(err))
-				return
-			}
-			newCluster, err := oPcs.addOrRemoveComponentFromCluster(latestCluster)
-			if err != nil {
-				logger.Error("add or remove component from cluster", zap.Error(err))
-				return
-			}
-			_, err = h.clusterOperator.UpdateCluster(context.TODO(), newCluster)
-			if err != nil {
-				logger.Error("update cluster metadata error", zap.Error(err))
-			}
+		latestCluster, err := h.clusterOperator.GetClusterEx(context.TODO(), clusterName, "0")
+		if err != nil {
+			logger.Error("get the latest cluster info error", zap.Error(err))
+			return
 		}
-	}(op, &service.Options{DryRun: dryRun}, pcs)
+		if _, err = h.clusterOperator.UpdateCluster(context.TODO(), applyComponentUpgrades(latestCluster, upgrades, o.Name)); err != nil {
+			logger.Error("update cluster metadata error", zap.Error(err))
+		}
+	}(op, &service.Options{})
 
 	_ = response.WriteHeaderAndEntity(http.StatusOK, clu)
 }
@@ -1627,7 +2533,10 @@ func (h *handler) UpgradeCluster(request *restful.Request, response *restful.Res
 		return
 	}
 	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
-	// TODO: validate cluster struct
+	if err := h.compatibilityCheck(clu, body.Version); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
 	timeoutSecs := v1.DefaultOperationTimeoutSecs
 	if v := request.QueryParameter("timeout"); v != "" {
 		timeoutSecs = v
@@ -1644,14 +2553,8 @@ func (h *handler) UpgradeCluster(request *restful.Request, response *restful.Res
 	extraMeta.Offline = body.Offline
 	extraMeta.KubeVersion = body.Version
 	extraMeta.LocalRegistry = body.LocalRegistry
-	upgradeComp := &k8s.Upgrade{}
-	upgradeComp.InitStepper(extraMeta, clu.Kubeadm)
-	if err := upgradeComp.Validate(); err != nil {
-		restplus.HandleBadRequest(response, request, err)
-		return
-	}
-
-	if err := upgradeComp.InitSteps(component.WithExtraMetadata(context.TODO(), *extraMeta)); err != nil {
+	steps, err := upgradeSteps(clu, extraMeta, body)
+	if err != nil {
 		restplus.HandleBadRequest(response, request, err)
 		return
 	}
@@ -1662,34 +2565,321 @@ func (h *handler) UpgradeCluster(request *restful.Request, response *restful.Res
 		common.LabelClusterName:    clu.Name,
 		common.LabelTopologyRegion: extraMeta.Masters[0].Region,
 	}
-	op.Steps = upgradeComp.GetInstallSteps()
-
-	// TODO: make dry run path to etcd
-	if !dryRun {
-		clu.Status.Status = v1.ClusterStatusUpgrading
-		_, err = h.clusterOperator.UpdateCluster(request.Request.Context(), clu)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
-	}
+	op.Steps = steps
 
 	op.Labels[common.LabelTimeoutSeconds] = timeoutSecs
 	op.Labels[common.LabelOperationAction] = v1.OperationUpgradeCluster
+	if op.StepPolicies, err = clusterStepPolicies(clu); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
 	op.Labels[common.LabelUpgradeVersion] = body.Version
 	op.Status.Status = v1.OperationStatusRunning
-	if !dryRun {
-		op, err = h.opOperator.CreateOperation(context.TODO(), op)
-		if err != nil {
-			restplus.HandleInternalError(response, request, err)
-			return
-		}
+	if err = h.runningOperationError(request.Request.Context(), clu, "upgrade"); err != nil {
+		restplus.HandleConflict(response, request, err)
+		return
 	}
-	go h.doOperation(context.TODO(), op, &service.Options{DryRun: dryRun})
-	response.WriteHeader(http.StatusOK)
-}
-
-func (h *handler) ResetClusterStatus(request *restful.Request, response *restful.Response) {
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+
+	clu.Status.Status = v1.ClusterStatusUpgrading
+	_, err = h.clusterOperator.UpdateCluster(request.Request.Context(), clu)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	op, err = h.opOperator.CreateOperation(context.TODO(), op)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	go h.doOperation(context.TODO(), op, &service.Options{})
+	response.WriteHeader(http.StatusOK)
+}
+
+// upgradeSteps renders the steps upgrading the cluster to the version of the request body.
+func upgradeSteps(clu *v1.Cluster, extraMeta *component.ExtraMetadata, body *ClusterUpgrade) ([]v1.Step, error) {
+	if clu.ClusterType == v1.ClusterK3s {
+		return (*k8s.K3sRunnable)(clu.Kubeadm).UpgradeSteps(extraMeta, body.Version, body.WorkerBatchSize)
+	}
+	upgradeComp := &k8s.Upgrade{}
+	upgradeComp.InitStepper(extraMeta, clu.Kubeadm)
+	upgradeComp.WorkerBatchSize = body.WorkerBatchSize
+	if err := upgradeComp.Validate(); err != nil {
+		return nil, err
+	}
+	if err := upgradeComp.Kubeadm.ValidatePatches(); err != nil {
+		return nil, err
+	}
+	if err := upgradeComp.InitSteps(component.WithExtraMetadata(context.TODO(), *extraMeta)); err != nil {
+		return nil, err
+	}
+	return upgradeComp.GetInstallSteps(), nil
+}
+
+// latestUpgradeOperation returns the most recent upgrade operation of the cluster.
+func (h *handler) latestUpgradeOperation(ctx context.Context, cluName string) (*v1.Operation, error) {
+	q := query.New()
+	q.LabelSelector = fmt.Sprintf("%s=%s,%s=%s", common.LabelClusterName, cluName,
+		common.LabelOperationAction, v1.OperationUpgradeCluster)
+	q.Pagination.Offset = 0
+	q.Pagination.Limit = 1
+	opList, err := h.opOperator.ListOperationsEx(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(opList.Items) == 0 {
+		return nil, fmt.Errorf("cluster %s has no upgrade operation", cluName)
+	}
+	return opList.Items[0].(*v1.Operation), nil
+}
+
+func (h *handler) PauseClusterUpgrade(request *restful.Request, response *restful.Response) {
+	op, err := h.latestUpgradeOperation(request.Request.Context(), request.PathParameter(query.ParameterName))
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	h.pauseOperation(request, response, op)
+}
+
+func (h *handler) ResumeClusterUpgrade(request *restful.Request, response *restful.Response) {
+	op, err := h.latestUpgradeOperation(request.Request.Context(), request.PathParameter(query.ParameterName))
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if op.Status.Status != v1.OperationStatusPaused {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("upgrade operation %s is not paused", op.Name))
+		return
+	}
+	h.resumeOperation(request, response, op)
+}
+
+func (h *handler) PauseOperation(request *restful.Request, response *restful.Response) {
+	op, err := h.opOperator.GetOperationEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	h.pauseOperation(request, response, op)
+}
+
+func (h *handler) ResumeOperation(request *restful.Request, response *restful.Response) {
+	op, err := h.opOperator.GetOperationEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if op.Status.Status != v1.OperationStatusPaused && op.Status.Status != v1.OperationStatusCancelled {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("operation %s is %s, only a paused or cancelled operation can be resumed", op.Name, op.Status.Status))
+		return
+	}
+	h.resumeOperation(request, response, op)
+}
+
+// CancelOperation stops a running or paused operation. The running step is
+// stopped on the agents and the remaining steps are marked skipped.
+func (h *handler) CancelOperation(request *restful.Request, response *restful.Response) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	ctx := request.Request.Context()
+	op, err := h.opOperator.GetOperationEx(ctx, request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	switch op.Status.Status {
+	case v1.OperationStatusRunning:
+		if dryRun {
+			break
+		}
+		if op.Annotations == nil {
+			op.Annotations = make(map[string]string)
+		}
+		op.Annotations[common.AnnotationOperationCancel] = "true"
+		if op, err = h.opOperator.UpdateOperation(ctx, op); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+		if err = h.delivery.CancelOperation(ctx, op); err != nil {
+			// the operation still stops once the running step ends
+			logger.Warn("stop running step failed", zap.String("operation", op.Name), zap.Error(err))
+		}
+	case v1.OperationStatusPaused:
+		if dryRun {
+			break
+		}
+		if err = h.delivery.CancelOperation(ctx, op); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	default:
+		restplus.HandleBadRequest(response, request, fmt.Errorf("operation %s is %s, only a running or paused operation can be cancelled", op.Name, op.Status.Status))
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// pauseOperation asks the delivery service to stop the running operation at its next
+// checkpoint step, or after the running step when the operation has no checkpoint.
+func (h *handler) pauseOperation(request *restful.Request, response *restful.Response, op *v1.Operation) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	if op.Status.Status != v1.OperationStatusRunning {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("operation %s is %s, only a running operation can be paused", op.Name, op.Status.Status))
+		return
+	}
+	if !dryRun {
+		if op.Annotations == nil {
+			op.Annotations = make(map[string]string)
+		}
+		op.Annotations[common.AnnotationOperationPause] = "true"
+		if _, err := h.opOperator.UpdateOperation(request.Request.Context(), op); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// resumeOperation runs the operation again from its first step that did not complete.
+func (h *handler) resumeOperation(request *restful.Request, response *restful.Response, op *v1.Operation) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	done := op.CompletedSteps()
+	if done >= len(op.Steps) {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("operation %s has no step left to resume", op.Name))
+		return
+	}
+	ctx := context.TODO()
+	if done > 0 {
+		if last := op.Status.Conditions[done-1]; len(last.Status) > 0 && last.Status[0].Response != nil {
+			ctx = component.WithExtraData(ctx, last.Status[0].Response)
+		}
+	}
+	// the conditions of the stopped and skipped steps are recorded again when they run
+	op.Status.Conditions = op.Status.Conditions[:done]
+	cancelled := op.Status.Status == v1.OperationStatusCancelled
+	op.Status.Status = v1.OperationStatusRunning
+	if dryRun {
+		resume := op.DeepCopy()
+		resume.Steps = resume.Steps[done:]
+		h.writeOperationPlan(request, response, resume)
+		return
+	}
+	var err error
+	if op, err = h.opOperator.UpdateOperation(request.Request.Context(), op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if cluName := op.Labels[common.LabelClusterName]; cancelled && cluName != "" {
+		// a cancelled operation has left the cluster in a failed status
+		if err = h.setClusterStatus(request.Request.Context(), cluName, operationClusterStatus(op.Labels[common.LabelOperationAction])); err != nil {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+	op.Steps = op.Steps[done:]
+	go h.doOperation(ctx, op, &service.Options{})
+	response.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) setClusterStatus(ctx context.Context, name string, status v1.ClusterStatusType) error {
+	clu, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		return err
+	}
+	clu.Status.Status = status
+	_, err = h.clusterOperator.UpdateCluster(ctx, clu)
+	return err
+}
+
+// operationClusterStatus returns the cluster status while an operation of the action runs.
+func operationClusterStatus(action string) v1.ClusterStatusType {
+	switch action {
+	case v1.OperationCreateCluster:
+		return v1.ClusterStatusInstalling
+	case v1.OperationDeleteCluster:
+		return v1.ClusterStatusDeleting
+	case v1.OperationUpgradeCluster, v1.OperationRollbackUpgrade:
+		return v1.ClusterStatusUpgrading
+	case v1.OperationBackupCluster:
+		return v1.ClusterStatusBackingUp
+	case v1.OperationRecoverCluster:
+		return v1.ClusterStatusRestoring
+	default:
+		return v1.ClusterStatusUpdating
+	}
+}
+
+// RollbackClusterUpgrade stops a paused upgrade and restores the previous kubernetes
+// binaries on the worker batches it has already upgraded. The control plane stays at
+// the new version, so the cluster records the upgrade version once the rollback is done.
+func (h *handler) RollbackClusterUpgrade(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	op, err := h.latestUpgradeOperation(request.Request.Context(), name)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if op.Status.Status != v1.OperationStatusPaused {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("upgrade operation %s is not paused", op.Name))
+		return
+	}
+	done := len(op.Status.Conditions)
+	if done > len(op.Steps) {
+		done = len(op.Steps)
+	}
+	clu, err := h.clusterOperator.GetClusterEx(request.Request.Context(), name, "0")
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	var images *v1.ToolImages
+	if clu.Kubeadm != nil {
+		images = clu.Kubeadm.ToolImages
+	}
+	steps, err := k8s.RollbackWorkerSteps(op.Steps[:done], op.Steps[done:], images)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+
+	rollback := &v1.Operation{}
+	rollback.Name = uuid.New().String()
+	rollback.Labels = map[string]string{
+		common.LabelClusterName:     name,
+		common.LabelTopologyRegion:  op.Labels[common.LabelTopologyRegion],
+		common.LabelTimeoutSeconds:  op.Labels[common.LabelTimeoutSeconds],
+		common.LabelOperationAction: v1.OperationRollbackUpgrade,
+		common.LabelUpgradeVersion:  op.Labels[common.LabelUpgradeVersion],
+	}
+	rollback.Steps = steps
+	rollback.Status.Status = v1.OperationStatusRunning
+	if dryRun {
+		h.writeOperationPlan(request, response, rollback)
+		return
+	}
+	// the paused upgrade can not be resumed anymore
+	op.Status.Status = v1.OperationStatusFailed
+	if _, err = h.opOperator.UpdateOperation(request.Request.Context(), op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if rollback, err = h.opOperator.CreateOperation(request.Request.Context(), rollback); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	go h.doOperation(context.TODO(), rollback, &service.Options{})
+	response.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) ResetClusterStatus(request *restful.Request, response *restful.Response) {
 	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
 	cluName := request.PathParameter(query.ParameterName)
 	clu, err := h.clusterOperator.GetClusterEx(request.Request.Context(), cluName, "0")
@@ -1766,7 +2956,7 @@ func (h *handler) DescribeLease(request *restful.Request, response *restful.Resp
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+	writeWithFields(request, response, c, requestFields(request))
 }
 
 // ---- domain
@@ -1792,7 +2982,7 @@ func (h *handler) ListDomains(request *restful.Request, response *restful.Respon
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+	writeWithFields(request, response, result, q.Fields)
 }
 
 func (h *handler) watchDomain(req *restful.Request, resp *restful.Response, q *query.Query) {
@@ -1876,7 +3066,7 @@ func (h *handler) GetDomain(request *restful.Request, response *restful.Response
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+	writeWithFields(request, response, result, requestFields(request))
 }
 
 func (h *handler) CreateDomains(request *restful.Request, response *restful.Response) {
@@ -1964,6 +3154,7 @@ func (h *handler) UpdateDomain(request *restful.Request, response *restful.Respo
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	restplus.SetETag(response, updated)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, updated)
 }
 
@@ -2407,7 +3598,7 @@ func (h *handler) ListTemplates(request *restful.Request, response *restful.Resp
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, templates)
+	writeWithFields(request, response, templates, q.Fields)
 }
 
 func (h *handler) DescribeTemplate(request *restful.Request, response *restful.Response) {
@@ -2417,7 +3608,7 @@ func (h *handler) DescribeTemplate(request *restful.Request, response *restful.R
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, template)
+	writeWithFields(request, response, template, requestFields(request))
 }
 
 func (h *handler) CreateTemplate(request *restful.Request, response *restful.Response) {
@@ -2453,6 +3644,7 @@ func (h *handler) UpdateTemplate(request *restful.Request, response *restful.Res
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	restplus.SetETag(response, template)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, template)
 }
 
@@ -2466,6 +3658,406 @@ func (h *handler) DeleteTemplate(request *restful.Request, response *restful.Res
 	response.WriteHeader(http.StatusOK)
 }
 
+func (h *handler) ListClusterTemplates(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	templates, err := h.clusterOperator.ListClusterTemplatesEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, templates, q.Fields)
+}
+
+func (h *handler) DescribeClusterTemplate(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	template, err := h.clusterOperator.GetClusterTemplateEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, template, requestFields(request))
+}
+
+func (h *handler) CreateClusterTemplate(request *restful.Request, response *restful.Response) {
+	template := &v1.ClusterTemplate{}
+	if err := request.ReadEntity(template); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if template.Kubeadm == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster template must contain kubeadm configuration"))
+		return
+	}
+	template, err := h.clusterOperator.CreateClusterTemplate(request.Request.Context(), template)
+	if err != nil {
+		if apimachineryErrors.IsAlreadyExists(err) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusCreated, template)
+}
+
+func (h *handler) UpdateClusterTemplate(request *restful.Request, response *restful.Response) {
+	template := &v1.ClusterTemplate{}
+	if err := request.ReadEntity(template); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	name := request.PathParameter(query.ParameterName)
+	if name != template.Name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster template name not match"))
+		return
+	}
+	template, err := h.clusterOperator.UpdateClusterTemplate(request.Request.Context(), template)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	restplus.SetETag(response, template)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, template)
+}
+
+func (h *handler) DeleteClusterTemplate(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	if err := h.clusterOperator.DeleteClusterTemplate(request.Request.Context(), name); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) ExportCluster(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	format := v1.ClusterExportFormat(strutil.StringDefaultIfEmpty(string(v1.ClusterExportKubeadm), request.QueryParameter("format")))
+	if !format.Valid() {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("unsupported export format %q", format))
+		return
+	}
+	c, err := h.clusterOperator.GetClusterEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	extraMeta, err := h.getClusterMetadata(request.Request.Context(), c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	out, err := k8s.ExportCluster(c, extraMeta, format)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, out)
+}
+
+func (h *handler) ListCronMaintenances(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	list, err := h.clusterOperator.ListCronMaintenancesEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, list, q.Fields)
+}
+
+func (h *handler) DescribeCronMaintenance(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	m, err := h.clusterOperator.GetCronMaintenanceEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, m, requestFields(request))
+}
+
+func (h *handler) CreateCronMaintenance(request *restful.Request, response *restful.Response) {
+	m := &v1.CronMaintenance{}
+	if err := request.ReadEntity(m); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.cronMaintenanceCheck(request.Request.Context(), m); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	m.Status = v1.CronMaintenanceStatus{}
+	m, err := h.clusterOperator.CreateCronMaintenance(request.Request.Context(), m)
+	if err != nil {
+		if apimachineryErrors.IsAlreadyExists(err) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusCreated, m)
+}
+
+func (h *handler) UpdateCronMaintenance(request *restful.Request, response *restful.Response) {
+	m := &v1.CronMaintenance{}
+	if err := request.ReadEntity(m); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	name := request.PathParameter(query.ParameterName)
+	if name != m.Name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cron maintenance name not match"))
+		return
+	}
+	if err := h.cronMaintenanceCheck(request.Request.Context(), m); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	old, err := h.clusterOperator.GetCronMaintenanceEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	// status is owned by the etcd maintenance controller
+	m.Status = old.Status
+	m, err = h.clusterOperator.UpdateCronMaintenance(request.Request.Context(), m)
+	if err != nil {
+		if apimachineryErrors.IsConflict(err) {
+			restplus.HandleConflict(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	restplus.SetETag(response, m)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, m)
+}
+
+func (h *handler) DeleteCronMaintenance(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	if err := h.clusterOperator.DeleteCronMaintenance(request.Request.Context(), name); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) cronMaintenanceCheck(ctx context.Context, m *v1.CronMaintenance) error {
+	if err := m.Spec.Validate(); err != nil {
+		return err
+	}
+	if m.Spec.ClusterName == "" {
+		return nil
+	}
+	_, err := h.clusterOperator.GetClusterEx(ctx, m.Spec.ClusterName, "0")
+	return err
+}
+
+func (h *handler) ListPrechecks(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	if node := request.QueryParameter("node"); node != "" {
+		if q.FuzzySearch == nil {
+			q.FuzzySearch = make(map[string]string)
+		}
+		q.FuzzySearch["node"] = node
+	}
+	list, err := h.clusterOperator.ListPrechecksEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, list, q.Fields)
+}
+
+func (h *handler) DescribePrecheck(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	p, err := h.clusterOperator.GetPrecheckEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, p, requestFields(request))
+}
+
+// CreatePrecheck stores a precheck run recorded outside the server, kcctl uploads
+// the runs of deploy and join with it.
+func (h *handler) CreatePrecheck(request *restful.Request, response *restful.Response) {
+	p := &v1.Precheck{}
+	if err := request.ReadEntity(p); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := p.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if p.Name == "" {
+		p.Name = uuid.New().String()
+	}
+	p.Complete()
+	setPrecheckLabels(p)
+	p, err := h.clusterOperator.CreatePrecheck(request.Request.Context(), p)
+	if err != nil {
+		if apimachineryErrors.IsAlreadyExists(err) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	h.prunePrechecks(request.Request.Context(), p.Source)
+	_ = response.WriteHeaderAndEntity(http.StatusCreated, p)
+}
+
+func (h *handler) DeletePrecheck(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	if err := h.clusterOperator.DeletePrecheck(request.Request.Context(), name); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// precheckHistoryLimit is the number of runs kept for every precheck source.
+const precheckHistoryLimit = 100
+
+func setPrecheckLabels(p *v1.Precheck) {
+	if p.Labels == nil {
+		p.Labels = map[string]string{}
+	}
+	p.Labels[common.LabelPrecheckSource] = string(p.Source)
+}
+
+// recordPrecheck keeps a precheck run in the history. The history is best effort,
+// failing to record a run never fails the request which ran the checks.
+func (h *handler) recordPrecheck(ctx context.Context, p *v1.Precheck) {
+	if p.Name == "" {
+		p.Name = uuid.New().String()
+	}
+	setPrecheckLabels(p)
+	if _, err := h.clusterOperator.CreatePrecheck(ctx, p); err != nil {
+		logger.Error("record precheck failed", zap.String("precheck", p.Name), zap.Error(err))
+		return
+	}
+	h.prunePrechecks(ctx, p.Source)
+}
+
+// prunePrechecks deletes the oldest runs of the source beyond precheckHistoryLimit.
+func (h *handler) prunePrechecks(ctx context.Context, source v1.PrecheckSource) {
+	q := query.New()
+	q.LabelSelector = fmt.Sprintf("%s=%s", common.LabelPrecheckSource, source)
+	list, err := h.clusterOperator.ListPrechecks(ctx, q)
+	if err != nil {
+		logger.Error("list precheck history failed", zap.String("source", string(source)), zap.Error(err))
+		return
+	}
+	if len(list.Items) <= precheckHistoryLimit {
+		return
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].StartedAt.Before(&list.Items[j].StartedAt)
+	})
+	for _, p := range list.Items[:len(list.Items)-precheckHistoryLimit] {
+		if err := h.clusterOperator.DeletePrecheck(ctx, p.Name); err != nil && !apimachineryErrors.IsNotFound(err) {
+			logger.Error("prune precheck history failed", zap.String("precheck", p.Name), zap.Error(err))
+		}
+	}
+}
+
+// clusterCreatePrecheck checks every node of a new cluster on its own, so the history tells
+// which node rejected the cluster. createClusterCheck and resourceArchCheck stay authoritative.
+func (h *handler) clusterCreatePrecheck(ctx context.Context, c *v1.Cluster, nodes component.NodeList) *v1.Precheck {
+	p := &v1.Precheck{Source: v1.PrecheckSourceClusterCreate, StartedAt: metav1.Now()}
+	p.Labels = map[string]string{common.LabelClusterName: c.Name}
+	archErrs := make(map[string]error)
+	for _, node := range nodes {
+		p.Nodes = append(p.Nodes, node.IPv4)
+		p.AddResult(node.IPv4, "available", h.nodeAvailableCheck(ctx, node.ID))
+		p.AddResult(node.IPv4, "os", windowsNodeCheck(component.NodeList{node}))
+		archErr, ok := archErrs[node.Arch]
+		if !ok {
+			archErr = h.resourceArchCheck(c, component.NodeList{node})
+			archErrs[node.Arch] = archErr
+		}
+		p.AddResult(node.IPv4, "offlinePackage", archErr)
+	}
+	p.Complete()
+	return p
+}
+
+func (h *handler) nodeAvailableCheck(ctx context.Context, id string) error {
+	node, err := h.clusterOperator.GetNodeEx(ctx, id, "0")
+	if err != nil {
+		return err
+	}
+	if _, ok := node.Labels[common.LabelNodeDisable]; ok {
+		return fmt.Errorf("node %s is disabled", id)
+	}
+	if _, ok := node.Labels[common.LabelNodeRole]; ok {
+		return fmt.Errorf("node %s is in use by cluster %s", id, node.Labels[common.LabelClusterName])
+	}
+	return nil
+}
+
+func (h *handler) CreateClusterFromTemplate(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	overrides := v1.ClusterTemplateOverrides{}
+	if err := request.ReadEntity(&overrides); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	template, err := h.clusterOperator.GetClusterTemplateEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	c, err := template.Render(&overrides)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	h.createCluster(request, response, *c)
+}
+
 func (h *handler) DescribeBackupPoint(request *restful.Request, response *restful.Response) {
 	name := request.PathParameter(query.ParameterName)
 	resourceVersion := strutil.StringDefaultIfEmpty("0", request.QueryParameter(query.ParameterResourceVersion))
@@ -2474,7 +4066,27 @@ func (h *handler) DescribeBackupPoint(request *restful.Request, response *restfu
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
-	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+	writeWithFields(request, response, result, requestFields(request))
+}
+
+func (h *handler) DescribeBackupPointUsage(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	name := request.PathParameter(query.ParameterName)
+	bp, err := h.clusterOperator.GetBackupPoint(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	backups, err := h.clusterOperator.ListBackups(ctx, query.New())
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, bp.ComputeUsage(backups.Items))
 }
 
 func (h *handler) ListBackupPoints(request *restful.Request, response *restful.Response) {
@@ -2492,7 +4104,7 @@ func (h *handler) ListBackupPoints(request *restful.Request, response *restful.R
 			restplus.HandleInternalError(response, request, err)
 			return
 		}
-		_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+		writeWithFields(request, response, result, q.Fields)
 	}
 }
 
@@ -2508,6 +4120,12 @@ func (h *handler) CreateBackupPoint(request *restful.Request, response *restful.
 		restplus.HandleBadRequest(response, request, fmt.Errorf("bucket name cannot be shorter than 3 characters"))
 		return
 	}
+	if bp.Quota != nil && bp.Quota.Sign() < 0 {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup point quota cannot be negative"))
+		return
+	}
+	// usage is accounted by the server
+	bp.Status = v1.BackupPointStatus{}
 
 	createdBp, err := h.clusterOperator.CreateBackupPoint(request.Request.Context(), bp)
 	if err != nil {
@@ -2561,11 +4179,18 @@ func (h *handler) UpdateBackupPoint(req *restful.Request, resp *restful.Response
 		obp.S3Config.AccessKeySecret = bp.S3Config.AccessKeySecret
 	}
 
-	_, err = h.clusterOperator.UpdateBackupPoint(req.Request.Context(), obp)
+	if bp.Quota != nil && bp.Quota.Sign() < 0 {
+		restplus.HandleBadRequest(resp, req, fmt.Errorf("backup point quota cannot be negative"))
+		return
+	}
+	obp.Quota = bp.Quota
+
+	obp, err = h.clusterOperator.UpdateBackupPoint(req.Request.Context(), obp)
 	if err != nil {
 		restplus.HandleInternalError(resp, req, err)
 		return
 	}
 
+	restplus.SetETag(resp, obp)
 	_ = resp.WriteHeaderAndEntity(http.StatusOK, obp)
 }
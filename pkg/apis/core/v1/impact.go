@@ -0,0 +1,308 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// impactHistoryLimit is the number of latest finished operations the expected durations are estimated from.
+const impactHistoryLimit = 10
+
+var (
+	drainNodeRegexp    = regexp.MustCompile(`kubectl drain ([\w.-]+)`)
+	drainBatchRegexp   = regexp.MustCompile(`for n in ([^;]+); do kubectl drain`)
+	restartRegexp      = regexp.MustCompile(`systemctl restart ([\w.@-]+)`)
+	controlPlaneRegexp = regexp.MustCompile(`kubeadm (upgrade|init|reset)|systemctl (restart|stop) kubelet|/etc/kubernetes/manifests`)
+	// disruptiveServices are the services whose restart disturbs the pods of the node.
+	disruptiveServices = sets.NewString("kubelet", "containerd", "docker", "crio")
+)
+
+// OperationImpact summarizes the disruption an operation causes, so that operators can schedule it
+// before confirming it.
+type OperationImpact struct {
+	// Nodes are the nodes drained or whose services are restarted, in the order they are touched.
+	Nodes []NodeImpact `json:"nodes,omitempty"`
+	// ControlPlane are the steps the control plane components of a node are unavailable during.
+	ControlPlane []UnavailabilityWindow `json:"controlPlane,omitempty"`
+	// ExpectedDuration is the average duration of the latest finished operations of the same action,
+	// it is empty when there is none.
+	ExpectedDuration string `json:"expectedDuration,omitempty"`
+	// Samples is the number of operations ExpectedDuration is estimated from.
+	Samples int `json:"samples"`
+	// MaxDuration is the sum of the step timeouts including retries, the operation fails before it is reached.
+	MaxDuration string `json:"maxDuration"`
+}
+
+type NodeImpact struct {
+	Node      string   `json:"node"`
+	Drained   bool     `json:"drained,omitempty"`
+	Restarted []string `json:"restarted,omitempty"`
+}
+
+type UnavailabilityWindow struct {
+	Step string `json:"step"`
+	Node string `json:"node"`
+	// Outage is true when no other control plane node serves while the step runs.
+	Outage bool `json:"outage"`
+	// ExpectedDuration is the average duration of the step in the latest finished operations.
+	ExpectedDuration string `json:"expectedDuration,omitempty"`
+}
+
+// operationImpact computes the impact of op, the history is best effort and left out when it can not be read.
+func (h *handler) operationImpact(ctx context.Context, op *corev1.Operation) *OperationImpact {
+	masters := sets.NewString()
+	name := op.Labels[common.LabelClusterName]
+	if name != "" {
+		// a cluster being created does not exist yet, and has no control plane to lose
+		if c, err := h.clusterOperator.GetCluster(ctx, name); err == nil {
+			for _, m := range c.Kubeadm.Masters {
+				masters.Insert(m.ID)
+			}
+		}
+	}
+	history, err := h.operationHistory(ctx, name, op.Labels[common.LabelOperationAction])
+	if err != nil {
+		logger.Error("list operation history failed", zap.String("cluster", name), zap.Error(err))
+	}
+	return newOperationImpact(op, masters, history)
+}
+
+// operationHistory returns the latest successful operations of action, those of the cluster are preferred
+// because their nodes and step names match.
+func (h *handler) operationHistory(ctx context.Context, cluster, action string) ([]corev1.Operation, error) {
+	if action == "" {
+		return nil, nil
+	}
+	selectors := []string{fmt.Sprintf("%s=%s", common.LabelOperationAction, action)}
+	if cluster != "" {
+		selectors = append([]string{fmt.Sprintf("%s,%s=%s", selectors[0], common.LabelClusterName, cluster)}, selectors...)
+	}
+	for _, selector := range selectors {
+		q := query.New()
+		q.LabelSelector = selector
+		list, err := h.opOperator.ListOperations(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		var ops []corev1.Operation
+		for _, o := range list.Items {
+			if o.Status.Status == corev1.OperationStatusSuccessful {
+				ops = append(ops, o)
+			}
+		}
+		if len(ops) > 0 {
+			sort.Slice(ops, func(i, j int) bool {
+				return ops[j].CreationTimestamp.Before(&ops[i].CreationTimestamp)
+			})
+			if len(ops) > impactHistoryLimit {
+				ops = ops[:impactHistoryLimit]
+			}
+			return ops, nil
+		}
+	}
+	return nil, nil
+}
+
+func newOperationImpact(op *corev1.Operation, masters sets.String, history []corev1.Operation) *OperationImpact {
+	// the timeouts of the steps are those of the step policies
+	op = op.DeepCopy()
+	op.ApplyStepPolicies()
+	impact := &OperationImpact{}
+	nodes := map[string]*NodeImpact{}
+	var order []string
+	record := func(node string) *NodeImpact {
+		if _, ok := nodes[node]; !ok {
+			nodes[node] = &NodeImpact{Node: node}
+			order = append(order, node)
+		}
+		return nodes[node]
+	}
+	stepDurations := averageStepDurations(history)
+
+	var maxDuration time.Duration
+	for _, step := range op.Steps {
+		maxDuration += step.Timeout.Duration * time.Duration(step.RetryTimes+1)
+		script := stepScript(step)
+		for _, node := range drainedNodes(step, script) {
+			record(node).Drained = true
+		}
+		restarted := restartedServices(script)
+		for _, node := range step.Nodes {
+			if len(restarted) > 0 {
+				ni := record(stepNodeName(node))
+				ni.Restarted = sets.NewString(ni.Restarted...).Insert(restarted...).List()
+			}
+			if masters.Has(node.ID) && controlPlaneRegexp.MatchString(script) {
+				w := UnavailabilityWindow{
+					Step:   step.Name,
+					Node:   stepNodeName(node),
+					Outage: masters.Len() == 1 || len(step.Nodes) >= masters.Len(),
+				}
+				if d, ok := stepDurations[step.Name]; ok {
+					w.ExpectedDuration = d.String()
+				}
+				impact.ControlPlane = append(impact.ControlPlane, w)
+			}
+		}
+	}
+	for _, node := range order {
+		impact.Nodes = append(impact.Nodes, *nodes[node])
+	}
+	impact.MaxDuration = maxDuration.String()
+
+	var total time.Duration
+	for _, o := range history {
+		if d := operationDuration(&o); d > 0 {
+			total += d
+			impact.Samples++
+		}
+	}
+	if impact.Samples > 0 {
+		impact.ExpectedDuration = (total / time.Duration(impact.Samples)).Round(time.Second).String()
+	}
+	return impact
+}
+
+// stepScript joins the shell commands of all phases of step.
+func stepScript(step corev1.Step) string {
+	var b strings.Builder
+	for _, cmds := range [][]corev1.Command{step.BeforeRunCommands, step.Commands, step.AfterRunCommands} {
+		for _, c := range cmds {
+			if c.Type == corev1.CommandShell {
+				b.WriteString(strings.Join(c.ShellCommand, " "))
+				b.WriteString("\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// drainedNodes returns the hostnames step drains, by kubectl or by the drain step of node removal.
+func drainedNodes(step corev1.Step, script string) []string {
+	var hosts []string
+	for _, m := range drainBatchRegexp.FindAllStringSubmatch(script, -1) {
+		hosts = append(hosts, strings.Fields(m[1])...)
+	}
+	for _, m := range drainNodeRegexp.FindAllStringSubmatch(script, -1) {
+		hosts = append(hosts, m[1])
+	}
+	if step.Name == "drainNode" {
+		for _, c := range step.Commands {
+			d := struct {
+				Hostname string `json:"hostname"`
+			}{}
+			if c.Type == corev1.CommandCustom && json.Unmarshal(c.CustomCommand, &d) == nil && d.Hostname != "" {
+				hosts = append(hosts, d.Hostname)
+			}
+		}
+	}
+	return hosts
+}
+
+// restartedServices returns the disruptive services script restarts.
+func restartedServices(script string) []string {
+	restarted := sets.NewString()
+	for _, m := range restartRegexp.FindAllStringSubmatch(script, -1) {
+		svc := strings.TrimSuffix(m[1], ".service")
+		if disruptiveServices.Has(svc) {
+			restarted.Insert(svc)
+		}
+	}
+	return restarted.List()
+}
+
+func stepNodeName(node corev1.StepNode) string {
+	if node.Hostname != "" {
+		return node.Hostname
+	}
+	if node.IPv4 != "" {
+		return node.IPv4
+	}
+	return node.ID
+}
+
+// averageStepDurations returns the average duration of the steps of history by step name,
+// a step runs on its nodes at once so it lasts from the first start to the last end.
+func averageStepDurations(history []corev1.Operation) map[string]time.Duration {
+	names := map[string]string{}
+	total := map[string]time.Duration{}
+	count := map[string]int{}
+	for _, o := range history {
+		for _, s := range o.Steps {
+			names[s.ID] = s.Name
+		}
+		for _, c := range o.Status.Conditions {
+			d := statusDuration(c.Status)
+			name, ok := names[c.StepID]
+			if !ok || d <= 0 {
+				continue
+			}
+			total[name] += d
+			count[name]++
+		}
+	}
+	avg := make(map[string]time.Duration, len(total))
+	for name, d := range total {
+		avg[name] = (d / time.Duration(count[name])).Round(time.Second)
+	}
+	return avg
+}
+
+// operationDuration is the time from the first step start to the last step end of a finished operation.
+func operationDuration(op *corev1.Operation) time.Duration {
+	var all []corev1.StepStatus
+	for _, c := range op.Status.Conditions {
+		all = append(all, c.Status...)
+	}
+	return statusDuration(all)
+}
+
+func statusDuration(status []corev1.StepStatus) time.Duration {
+	var start, end time.Time
+	for _, s := range status {
+		if s.StartAt.IsZero() || s.EndAt.IsZero() {
+			continue
+		}
+		if start.IsZero() || s.StartAt.Time.Before(start) {
+			start = s.StartAt.Time
+		}
+		if s.EndAt.Time.After(end) {
+			end = s.EndAt.Time
+		}
+	}
+	if start.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
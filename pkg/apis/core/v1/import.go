@@ -0,0 +1,383 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+const (
+	// serverAccount is the service account kc-server manages clusters with, installed clusters create it as well.
+	serverAccount             = "kc-server"
+	serverAccountSecret       = "kc-server-token"
+	serverAccountTokenTimeout = 30 * time.Second
+
+	kubeadmConfigMap      = "kubeadm-config"
+	kubeProxyConfigMap    = "kube-proxy"
+	defaultKubeletRootDir = "/var/lib/kubelet"
+)
+
+// cniDaemonSets maps the daemon sets deployed by the supported cni to the cni type and the container
+// whose image tag is the cni version.
+var cniDaemonSets = map[string][2]string{
+	"calico-node":     {"calico", "calico-node"},
+	"kube-flannel-ds": {"flannel", "kube-flannel"},
+	"cilium":          {"cilium", "cilium-agent"},
+}
+
+// importedNode is a node of the cluster being imported.
+type importedNode struct {
+	Name string
+	IP   string
+	Role common.NodeRole
+}
+
+// kubeadmClusterConfiguration holds the fields of the ClusterConfiguration in the kubeadm-config config map
+// the import relies on.
+type kubeadmClusterConfiguration struct {
+	KubernetesVersion string `json:"kubernetesVersion"`
+	Networking        struct {
+		ServiceSubnet string `json:"serviceSubnet"`
+		PodSubnet     string `json:"podSubnet"`
+		DNSDomain     string `json:"dnsDomain"`
+	} `json:"networking"`
+	APIServer struct {
+		CertSANs []string `json:"certSANs"`
+	} `json:"apiServer"`
+	Etcd struct {
+		Local *struct {
+			DataDir string `json:"dataDir"`
+		} `json:"local"`
+	} `json:"etcd"`
+}
+
+// ImportCluster adopts an existing kubeadm cluster. Its nodes are matched to the kubeclipper nodes by ip,
+// and the cluster is created running, so it can be upgraded, backed up and scaled like an installed one.
+func (h *handler) ImportCluster(request *restful.Request, response *restful.Response) {
+	req := v1.ClusterImport{}
+	if err := request.ReadEntity(&req); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if req.Name == "" || req.Kubeconfig == "" {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("name and kubeconfig of the imported cluster are required"))
+		return
+	}
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	ctx := request.Request.Context()
+	clientcfg, clientset, err := client.FromKubeConfig([]byte(req.Kubeconfig))
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	kubeadm, nodes, err := discoverKubeadm(ctx, clientset)
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	kubeadm.Description = req.Description
+	if err = h.matchImportedNodes(ctx, kubeadm, nodes); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	protected := true
+	c := v1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   req.Name,
+			Labels: map[string]string{common.LabelClusterImported: "true"},
+		},
+		ClusterType: v1.ClusterKubeadm,
+		Kubeadm:     kubeadm,
+		// deleting the cluster resets its nodes, imported clusters are protected until it is turned off
+		DeleteProtection: &protected,
+	}
+	if err = h.createClusterCheck(ctx, &c); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if _, err = h.getClusterMetadata(ctx, &c); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if dryRun {
+		_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+		return
+	}
+	token, err := ensureServerAccount(ctx, clientset)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	c.KubeConfig = buildKubeconfig(c.Name, v1.KubeconfigScopeAdmin, clientcfg.Host, clientcfg.CAData, token)
+	c.Status.Status = v1.ClusterStatusRunning
+	created, err := h.clusterOperator.CreateCluster(ctx, &c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, created)
+}
+
+// matchImportedNodes sets the masters and workers of kubeadm to the kubeclipper nodes with the ips of nodes.
+func (h *handler) matchImportedNodes(ctx context.Context, kubeadm *v1.Kubeadm, nodes []importedNode) error {
+	list, err := h.clusterOperator.ListNodes(ctx, &query.Query{
+		Pagination:      query.NoPagination(),
+		ResourceVersion: "0",
+	})
+	if err != nil {
+		return err
+	}
+	byIP := make(map[string]string, len(list.Items))
+	for _, n := range list.Items {
+		byIP[n.Status.Ipv4DefaultIP] = n.Name
+	}
+	var missing []string
+	for _, n := range nodes {
+		id, ok := byIP[n.IP]
+		if !ok {
+			missing = append(missing, fmt.Sprintf("%s(%s)", n.Name, n.IP))
+			continue
+		}
+		if n.Role == common.NodeRoleMaster {
+			kubeadm.Masters = append(kubeadm.Masters, v1.WorkerNode{ID: id})
+		} else {
+			kubeadm.Workers = append(kubeadm.Workers, v1.WorkerNode{ID: id})
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("nodes %s are not kubeclipper nodes, join them as agents first", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// discoverKubeadm reads the topology, versions and networking of a kubeadm cluster.
+func discoverKubeadm(ctx context.Context, clientset kubernetes.Interface) (*v1.Kubeadm, []importedNode, error) {
+	cm, err := clientset.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, kubeadmConfigMap, metav1.GetOptions{})
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("config map %s/%s not found, only kubeadm clusters can be imported", metav1.NamespaceSystem, kubeadmConfigMap)
+		}
+		return nil, nil, err
+	}
+	conf := kubeadmClusterConfiguration{}
+	if err = yaml.Unmarshal([]byte(cm.Data["ClusterConfiguration"]), &conf); err != nil {
+		return nil, nil, fmt.Errorf("parse kubeadm cluster configuration: %v", err)
+	}
+	kubeadm := &v1.Kubeadm{
+		KubernetesVersion: conf.KubernetesVersion,
+		CertSANs:          conf.APIServer.CertSANs,
+		Networking: v1.Networking{
+			ServiceSubnet: conf.Networking.ServiceSubnet,
+			PodSubnet:     conf.Networking.PodSubnet,
+			DNSDomain:     conf.Networking.DNSDomain,
+		},
+	}
+	kubeadm.KubeComponents.Kubelet.RootDir = defaultKubeletRootDir
+	if conf.Etcd.Local != nil {
+		kubeadm.KubeComponents.Etcd.DataDir = conf.Etcd.Local.DataDir
+	}
+	for _, cidr := range strings.Split(conf.Networking.PodSubnet, ",") {
+		ip, _, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			continue
+		}
+		if ip.To4() != nil {
+			kubeadm.KubeComponents.CNI.PodIPv4CIDR = cidr
+		} else {
+			kubeadm.KubeComponents.CNI.PodIPv6CIDR = cidr
+		}
+	}
+
+	nodeList, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	var nodes []importedNode
+	var runtime string
+	for _, n := range nodeList.Items {
+		node := importedNode{Name: n.Name, Role: common.NodeRoleWorker}
+		for _, addr := range n.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP {
+				node.IP = addr.Address
+				break
+			}
+		}
+		if _, ok := n.Labels["node-role.kubernetes.io/control-plane"]; ok {
+			node.Role = common.NodeRoleMaster
+		}
+		if _, ok := n.Labels["node-role.kubernetes.io/master"]; ok {
+			node.Role = common.NodeRoleMaster
+		}
+		if node.Role == common.NodeRoleMaster || runtime == "" {
+			runtime = n.Status.NodeInfo.ContainerRuntimeVersion
+		}
+		if kubeadm.KubernetesVersion == "" && node.Role == common.NodeRoleMaster {
+			kubeadm.KubernetesVersion = n.Status.NodeInfo.KubeletVersion
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		return nil, nil, fmt.Errorf("the cluster has no node")
+	}
+	// masters first, so the first master of the cluster is stable
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].Role == common.NodeRoleMaster && nodes[j].Role != common.NodeRoleMaster
+	})
+	if nodes[0].Role != common.NodeRoleMaster {
+		return nil, nil, fmt.Errorf("no control plane node found, they are labeled with node-role.kubernetes.io/control-plane")
+	}
+	if kubeadm.ContainerRuntime, err = parseContainerRuntime(runtime); err != nil {
+		return nil, nil, err
+	}
+
+	cniType, cniVersion, err := discoverCNI(ctx, clientset)
+	if err != nil {
+		return nil, nil, err
+	}
+	kubeadm.KubeComponents.CNI.Type = cniType
+	switch cniType {
+	case "calico":
+		kubeadm.KubeComponents.CNI.Calico.Version = cniVersion
+	case "flannel":
+		kubeadm.KubeComponents.CNI.Flannel.Version = cniVersion
+	}
+	proxy, err := clientset.CoreV1().ConfigMaps(metav1.NamespaceSystem).Get(ctx, kubeProxyConfigMap, metav1.GetOptions{})
+	if err != nil && !apimachineryErrors.IsNotFound(err) {
+		return nil, nil, err
+	}
+	if err == nil {
+		proxyConf := struct {
+			Mode string `json:"mode"`
+		}{}
+		if err = yaml.Unmarshal([]byte(proxy.Data["config.conf"]), &proxyConf); err == nil {
+			kubeadm.KubeComponents.KubeProxy.IPvs = proxyConf.Mode == "ipvs"
+		}
+	}
+	return kubeadm, nodes, nil
+}
+
+// parseContainerRuntime parses the runtime version reported by the kubelet, e.g. containerd://1.6.8.
+func parseContainerRuntime(v string) (v1.ContainerRuntime, error) {
+	cri := v1.ContainerRuntime{}
+	parts := strings.SplitN(v, "://", 2)
+	if len(parts) != 2 {
+		return cri, fmt.Errorf("unknown container runtime %q", v)
+	}
+	switch v1.CRIType(parts[0]) {
+	case v1.CRIContainerd:
+		cri.Type = v1.CRIContainerd
+		cri.Containerd.Version = parts[1]
+	case v1.CRIDocker:
+		cri.Type = v1.CRIDocker
+		cri.Docker.Version = parts[1]
+	default:
+		return cri, fmt.Errorf("unsupported container runtime %s", parts[0])
+	}
+	return cri, nil
+}
+
+// discoverCNI returns the type and the version of the cni deployed in the cluster.
+// The type is empty when the cni is not recognized.
+func discoverCNI(ctx context.Context, clientset kubernetes.Interface) (cniType, cniVersion string, err error) {
+	daemonSets, err := clientset.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	for _, ds := range daemonSets.Items {
+		cni, ok := cniDaemonSets[ds.Name]
+		if !ok {
+			continue
+		}
+		for _, c := range ds.Spec.Template.Spec.Containers {
+			if c.Name == cni[1] {
+				if i := strings.LastIndex(c.Image, ":"); i > 0 {
+					cniVersion = c.Image[i+1:]
+				}
+			}
+		}
+		return cni[0], cniVersion, nil
+	}
+	return "", "", nil
+}
+
+// ensureServerAccount creates the kc-server service account bound to cluster-admin, and returns the token of its
+// secret. The secret is created explicitly because kubernetes 1.24 and later do not create them for service accounts.
+func ensureServerAccount(ctx context.Context, clientset kubernetes.Interface) (string, error) {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: serverAccount, Namespace: metav1.NamespaceSystem},
+		Secrets:    []corev1.ObjectReference{{Name: serverAccountSecret}},
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts(metav1.NamespaceSystem).Create(ctx, sa, metav1.CreateOptions{}); err != nil && !apimachineryErrors.IsAlreadyExists(err) {
+		return "", err
+	}
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: serverAccount},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: serverAccount, Namespace: metav1.NamespaceSystem}},
+	}
+	if _, err := clientset.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{}); err != nil && !apimachineryErrors.IsAlreadyExists(err) {
+		return "", err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        serverAccountSecret,
+			Namespace:   metav1.NamespaceSystem,
+			Annotations: map[string]string{corev1.ServiceAccountNameKey: serverAccount},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
+	}
+	if _, err := clientset.CoreV1().Secrets(metav1.NamespaceSystem).Create(ctx, secret, metav1.CreateOptions{}); err != nil && !apimachineryErrors.IsAlreadyExists(err) {
+		return "", err
+	}
+	var token string
+	// the token is filled in by the token controller
+	err := wait.PollImmediate(time.Second, serverAccountTokenTimeout, func() (bool, error) {
+		s, err := clientset.CoreV1().Secrets(metav1.NamespaceSystem).Get(ctx, serverAccountSecret, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		token = string(s.Data[corev1.ServiceAccountTokenKey])
+		return token != "", nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("wait for the token of service account %s: %v", serverAccount, err)
+	}
+	return token, nil
+}
@@ -0,0 +1,129 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func importedClusterNode(name, ip string, labels map[string]string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{
+			Addresses: []corev1.NodeAddress{
+				{Type: corev1.NodeHostName, Address: name},
+				{Type: corev1.NodeInternalIP, Address: ip},
+			},
+			NodeInfo: corev1.NodeSystemInfo{ContainerRuntimeVersion: "containerd://1.6.4", KubeletVersion: "v1.23.6"},
+		},
+	}
+}
+
+func TestDiscoverKubeadm(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: kubeadmConfigMap, Namespace: metav1.NamespaceSystem},
+			Data: map[string]string{"ClusterConfiguration": `
+kubernetesVersion: v1.23.6
+networking:
+  serviceSubnet: 10.96.0.0/12
+  podSubnet: 172.25.0.0/16
+  dnsDomain: cluster.local
+apiServer:
+  certSANs: [kube.example.com]
+etcd:
+  local:
+    dataDir: /var/lib/etcd
+`},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: kubeProxyConfigMap, Namespace: metav1.NamespaceSystem},
+			Data:       map[string]string{"config.conf": "mode: ipvs\n"},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "calico-node", Namespace: metav1.NamespaceSystem},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "calico-node", Image: "docker.io/calico/node:v3.22.4"}},
+			}}},
+		},
+		importedClusterNode("worker-1", "192.168.10.12", nil),
+		importedClusterNode("master-1", "192.168.10.11", map[string]string{"node-role.kubernetes.io/control-plane": ""}),
+	)
+
+	kubeadm, nodes, err := discoverKubeadm(context.TODO(), clientset)
+	if err != nil {
+		t.Fatalf("discoverKubeadm() error = %v", err)
+	}
+	if len(nodes) != 2 || nodes[0].IP != "192.168.10.11" || nodes[0].Role != common.NodeRoleMaster ||
+		nodes[1].IP != "192.168.10.12" || nodes[1].Role != common.NodeRoleWorker {
+		t.Errorf("discoverKubeadm() nodes = %+v", nodes)
+	}
+	if kubeadm.KubernetesVersion != "v1.23.6" || kubeadm.Networking.PodSubnet != "172.25.0.0/16" ||
+		kubeadm.KubeComponents.CNI.PodIPv4CIDR != "172.25.0.0/16" || kubeadm.KubeComponents.Etcd.DataDir != "/var/lib/etcd" {
+		t.Errorf("discoverKubeadm() kubeadm = %+v", kubeadm)
+	}
+	if kubeadm.ContainerRuntime.Type != v1.CRIContainerd || kubeadm.ContainerRuntime.Containerd.Version != "1.6.4" {
+		t.Errorf("discoverKubeadm() container runtime = %+v", kubeadm.ContainerRuntime)
+	}
+	if kubeadm.KubeComponents.CNI.Type != "calico" || kubeadm.KubeComponents.CNI.Calico.Version != "v3.22.4" {
+		t.Errorf("discoverKubeadm() cni = %+v", kubeadm.KubeComponents.CNI)
+	}
+	if !kubeadm.KubeComponents.KubeProxy.IPvs {
+		t.Errorf("discoverKubeadm() kube-proxy ipvs = false, want true")
+	}
+}
+
+func TestDiscoverKubeadmNotKubeadm(t *testing.T) {
+	if _, _, err := discoverKubeadm(context.TODO(), fake.NewSimpleClientset()); err == nil {
+		t.Errorf("discoverKubeadm() error = nil, want error for cluster without kubeadm-config")
+	}
+}
+
+func TestParseContainerRuntime(t *testing.T) {
+	tests := []struct {
+		version string
+		want    v1.CRIType
+		wantErr bool
+	}{
+		{version: "containerd://1.6.4", want: v1.CRIContainerd},
+		{version: "docker://20.10.20", want: v1.CRIDocker},
+		{version: "cri-o://1.24.1", wantErr: true},
+		{version: "containerd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := parseContainerRuntime(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseContainerRuntime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got.Type != tt.want {
+				t.Errorf("parseContainerRuntime() = %v, want %v", got.Type, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+const (
+	ParameterScope      = "scope"
+	ParameterExpiration = "expiration"
+
+	kubeconfigNamespace = "kube-system"
+	// rootCAConfigMap is published into every namespace by kube-controller-manager.
+	rootCAConfigMap = "kube-root-ca.crt"
+
+	defaultKubeconfigExpiration = 24 * time.Hour
+	// the token request api rejects shorter expirations
+	minKubeconfigExpiration = 10 * time.Minute
+	maxKubeconfigExpiration = 30 * 24 * time.Hour
+)
+
+// GetKubeconfig issues a view or edit kubeconfig of the cluster.
+func (h *handler) GetKubeconfig(request *restful.Request, response *restful.Response) {
+	scope := v1.KubeconfigScope(query.GetStringValueWithDefault(request, ParameterScope, string(v1.KubeconfigScopeView)))
+	if scope != v1.KubeconfigScopeView && scope != v1.KubeconfigScopeEdit {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("unsupported kubeconfig scope %s, it must be view or edit", scope))
+		return
+	}
+	h.issueKubeconfig(request, response, scope)
+}
+
+// GetAdminKubeconfig issues a cluster-admin kubeconfig of the cluster. It is a route of its own,
+// so that roles may grant scoped kubeconfigs without granting admin ones.
+func (h *handler) GetAdminKubeconfig(request *restful.Request, response *restful.Response) {
+	h.issueKubeconfig(request, response, v1.KubeconfigScopeAdmin)
+}
+
+func (h *handler) issueKubeconfig(request *restful.Request, response *restful.Response, scope v1.KubeconfigScope) {
+	expiration, err := kubeconfigExpiration(request.QueryParameter(ParameterExpiration))
+	if err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	name := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	c, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.KubeConfig == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster %s clientset not init", name))
+		return
+	}
+	clientcfg, clientset, err := client.FromKubeConfig(c.KubeConfig)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if err = ensureKubeconfigAccount(ctx, clientset, scope); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	seconds := int64(expiration.Seconds())
+	token, err := clientset.CoreV1().ServiceAccounts(kubeconfigNamespace).CreateToken(ctx, scope.ServiceAccount(),
+		&authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &seconds}}, metav1.CreateOptions{})
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	var ca []byte
+	cm, err := clientset.CoreV1().ConfigMaps(kubeconfigNamespace).Get(ctx, rootCAConfigMap, metav1.GetOptions{})
+	if err != nil && !apimachineryErrors.IsNotFound(err) {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if cm != nil {
+		ca = []byte(cm.Data["ca.crt"])
+	}
+	server := clientcfg.Host
+	if c.Kubeadm != nil && c.Kubeadm.ExternalServer() != "" {
+		server = c.Kubeadm.ExternalServer()
+	}
+	kubeconfig := buildKubeconfig(name, scope, server, ca, token.Status.Token)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, v1.ClusterKubeconfig{
+		Cluster:             name,
+		Scope:               scope,
+		ExpirationTimestamp: token.Status.ExpirationTimestamp,
+		Kubeconfig:          string(kubeconfig),
+	})
+}
+
+func kubeconfigExpiration(v string) (time.Duration, error) {
+	if v == "" {
+		return defaultKubeconfigExpiration, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid kubeconfig expiration %s: %v", v, err)
+	}
+	if d < minKubeconfigExpiration || d > maxKubeconfigExpiration {
+		return 0, fmt.Errorf("kubeconfig expiration must be between %s and %s", minKubeconfigExpiration, maxKubeconfigExpiration)
+	}
+	return d, nil
+}
+
+// ensureKubeconfigAccount creates the service account of the scope and binds it to the cluster role of the scope.
+// The service account is deleted when the credentials are rotated and created again on the next request.
+func ensureKubeconfigAccount(ctx context.Context, clientset kubernetes.Interface, scope v1.KubeconfigScope) error {
+	labels := map[string]string{common.LabelKubeconfigScope: string(scope)}
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: scope.ServiceAccount(), Namespace: kubeconfigNamespace, Labels: labels},
+	}
+	if _, err := clientset.CoreV1().ServiceAccounts(kubeconfigNamespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil && !apimachineryErrors.IsAlreadyExists(err) {
+		return err
+	}
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: scope.ServiceAccount(), Labels: labels},
+		RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: scope.ClusterRole()},
+		Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: sa.Name, Namespace: kubeconfigNamespace}},
+	}
+	if _, err := clientset.RbacV1().ClusterRoleBindings().Create(ctx, binding, metav1.CreateOptions{}); err != nil && !apimachineryErrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+const kubeconfigFormat = `apiVersion: v1
+clusters:
+- cluster:
+    %s
+    server: %s
+  name: %s
+contexts:
+- context:
+    cluster: %s
+    user: %s
+  name: %s
+current-context: %s
+kind: Config
+preferences: {}
+users:
+- name: %s
+  user:
+    token: %s
+`
+
+// buildKubeconfig returns a kubeconfig authenticating with token. The api server is only
+// verified when its ca is known, clusters before 1.20 do not publish it.
+func buildKubeconfig(clusterName string, scope v1.KubeconfigScope, server string, ca []byte, token string) []byte {
+	user := fmt.Sprintf("%s-%s", clusterName, scope)
+	contextName := fmt.Sprintf("%s@%s", scope, clusterName)
+	verify := "insecure-skip-tls-verify: true"
+	if len(ca) > 0 {
+		verify = "certificate-authority-data: " + base64.StdEncoding.EncodeToString(ca)
+	}
+	return []byte(fmt.Sprintf(kubeconfigFormat, verify, server, clusterName, clusterName, user, contextName, contextName, user, token))
+}
+
+// RotateClusterCredentials renews the admin.conf of the masters and revokes every kubeconfig
+// issued for the cluster, they have to be downloaded again afterwards. The former admin.conf is
+// not revoked, it stays valid until its certificate expires.
+func (h *handler) RotateClusterCredentials(request *restful.Request, response *restful.Response) {
+	h.runOnMasters(request, response, v1.OperationRotateCredentials, "rotate credentials",
+		func(kubeadm *v1.Kubeadm, masters []v1.StepNode) []v1.Step {
+			return k8s.RotateCredentialsSteps(masters, kubeadm.ToolImages)
+		})
+}
@@ -0,0 +1,112 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestKubeconfigExpiration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "", want: defaultKubeconfigExpiration},
+		{value: "8h", want: 8 * time.Hour},
+		{value: "5m", wantErr: true},
+		{value: "1000h", wantErr: true},
+		{value: "day", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := kubeconfigExpiration(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("kubeconfigExpiration(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("kubeconfigExpiration(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestEnsureKubeconfigAccount(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	ctx := context.TODO()
+	// the second run finds everything in place
+	for i := 0; i < 2; i++ {
+		if err := ensureKubeconfigAccount(ctx, clientset, v1.KubeconfigScopeAdmin); err != nil {
+			t.Fatalf("ensureKubeconfigAccount() error = %v", err)
+		}
+	}
+	sa, err := clientset.CoreV1().ServiceAccounts(kubeconfigNamespace).Get(ctx, "kc-kubeconfig-admin", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get service account error = %v", err)
+	}
+	if sa.Labels[common.LabelKubeconfigScope] != "admin" {
+		t.Errorf("service account labels = %v, the rotation selects them by scope label", sa.Labels)
+	}
+	binding, err := clientset.RbacV1().ClusterRoleBindings().Get(ctx, "kc-kubeconfig-admin", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get cluster role binding error = %v", err)
+	}
+	if binding.RoleRef.Name != "cluster-admin" || len(binding.Subjects) != 1 || binding.Subjects[0].Name != sa.Name {
+		t.Errorf("cluster role binding = %+v", binding)
+	}
+}
+
+func TestBuildKubeconfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		ca       []byte
+		insecure bool
+	}{
+		{name: "with ca", ca: []byte("ca-data")},
+		{name: "without ca", insecure: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildKubeconfig("demo", v1.KubeconfigScopeView, "https://10.0.0.1:6443", tt.ca, "token")
+			cfg, err := clientcmd.Load(data)
+			if err != nil {
+				t.Fatalf("load kubeconfig error = %v", err)
+			}
+			if cfg.CurrentContext != "view@demo" {
+				t.Errorf("current context = %s", cfg.CurrentContext)
+			}
+			cluster := cfg.Clusters["demo"]
+			if cluster == nil || cluster.Server != "https://10.0.0.1:6443" || cluster.InsecureSkipTLSVerify != tt.insecure ||
+				string(cluster.CertificateAuthorityData) != string(tt.ca) {
+				t.Errorf("cluster = %+v", cluster)
+			}
+			if user := cfg.AuthInfos["demo-view"]; user == nil || user.Token != "token" {
+				t.Errorf("user = %+v", user)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/emicklei/go-restful"
+	"go.uber.org/zap"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+const (
+	ParameterPath  = "path"
+	ParameterLines = "lines"
+	// maxNodeFileDownloadSize bounds the files downloaded from nodes, which are
+	// relayed a range at a time over the message queue.
+	maxNodeFileDownloadSize = 64 * 1024 * 1024
+)
+
+func (h *handler) ListNodeFiles(request *restful.Request, response *restful.Response) {
+	resp, err := h.browseNodeFile(request, &service.FileRequest{Action: service.FileList})
+	if err != nil {
+		handleNodeFileError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, resp)
+}
+
+func (h *handler) TailNodeFile(request *restful.Request, response *restful.Response) {
+	lines := query.GetIntValueWithDefault(request, ParameterLines, 0)
+	resp, err := h.browseNodeFile(request, &service.FileRequest{Action: service.FileTail, Lines: lines})
+	if err != nil {
+		handleNodeFileError(response, request, err)
+		return
+	}
+	response.Header().Set(restful.HEADER_ContentType, "text/plain; charset=utf-8")
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(resp.Data)
+}
+
+func (h *handler) DownloadNodeFile(request *restful.Request, response *restful.Response) {
+	req := &service.FileRequest{Action: service.FileRead, Length: service.MaxFileReadLength}
+	resp, err := h.browseNodeFile(request, req)
+	if err != nil {
+		handleNodeFileError(response, request, err)
+		return
+	}
+	if resp.Size > maxNodeFileDownloadSize {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("file size %d exceeds the download limit %d", resp.Size, maxNodeFileDownloadSize))
+		return
+	}
+	// the file may grow while downloading, only the size seen first is sent
+	size := resp.Size
+	response.Header().Set(restful.HEADER_ContentType, "application/octet-stream")
+	response.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(resp.Path)))
+	response.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	response.WriteHeader(http.StatusOK)
+	for sent := int64(0); ; {
+		data := resp.Data
+		if int64(len(data)) > size-sent {
+			data = data[:size-sent]
+		}
+		if _, err = response.Write(data); err != nil {
+			return
+		}
+		sent += int64(len(data))
+		if sent >= size || len(data) == 0 {
+			return
+		}
+		req.Offset = sent
+		if resp, err = h.browseNodeFile(request, req); err != nil {
+			// the status is sent already, the client sees a short body
+			logger.Error("download node file error", zap.String("path", req.Path), zap.Error(err))
+			return
+		}
+	}
+}
+
+func (h *handler) browseNodeFile(request *restful.Request, req *service.FileRequest) (*service.FileResponse, error) {
+	nodeName := request.PathParameter(query.ParameterName)
+	req.Path = request.QueryParameter(ParameterPath)
+	if !filepath.IsAbs(req.Path) {
+		return nil, &errors.StatusError{
+			Message: "invalid path",
+			Details: &errors.StatusDetails{Causes: []errors.StatusCause{
+				{Type: errors.BrowseFile, Message: fmt.Sprintf("path %q is not absolute", req.Path)},
+			}},
+			Code: http.StatusBadRequest,
+		}
+	}
+	ctx := request.Request.Context()
+	if _, err := h.clusterOperator.GetNodeEx(ctx, nodeName, "0"); err != nil {
+		return nil, err
+	}
+	return h.delivery.BrowseFile(ctx, nodeName, req)
+}
+
+func handleNodeFileError(response *restful.Response, request *restful.Request, err error) {
+	code := errors.CodeForError(err)
+	// the cause tells what is wrong with the path
+	if cause, ok := errors.StatusErrorCause(err, errors.BrowseFile); ok {
+		err = fmt.Errorf("%s", cause.Message)
+	}
+	switch code {
+	case http.StatusBadRequest:
+		restplus.HandleBadRequest(response, request, err)
+	case http.StatusNotFound:
+		restplus.HandleNotFound(response, request, err)
+	default:
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+	}
+}
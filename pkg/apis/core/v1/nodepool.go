@@ -0,0 +1,125 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// NodePoolPatcher starts the operations adding the nodes supplied by node providers to node pools,
+// and removing the surplus ones, the same way PATCH /clusters/{name}/nodes does.
+type NodePoolPatcher struct {
+	h *handler
+}
+
+func NewNodePoolPatcher(clusterOperator cluster.Operator, op operation.Operator, delivery service.IDelivery,
+	staticServerPath string) *NodePoolPatcher {
+	return &NodePoolPatcher{h: newHandler(clusterOperator, op, nil, nil, nil, delivery, nil, nil, nil, nil, nil, nil, staticServerPath)}
+}
+
+func (p *NodePoolPatcher) PatchNodePool(ctx context.Context, cluster, pool, operation string, nodes []string) error {
+	c, err := p.h.clusterOperator.GetClusterEx(ctx, cluster, "0")
+	if err != nil {
+		return err
+	}
+	if err = p.h.clusterIdleError(ctx, c, "patch node pool "+pool); err != nil {
+		return err
+	}
+	pn := &PatchNodes{
+		Operation: NodesPatchOperation(operation),
+		Role:      common.NodeRoleWorker,
+		Pool:      pool,
+	}
+	for _, id := range nodes {
+		pn.Nodes = append(pn.Nodes, v1.WorkerNode{ID: id, Pool: pool})
+	}
+	op, err := p.h.makeNodesOperation(ctx, c, pn)
+	if err != nil || op == nil {
+		return err
+	}
+	_, err = p.h.startNodesOperation(ctx, c, op)
+	return err
+}
+
+// ScaleNodePool sets the worker count of a node pool, the node pool scale controller then
+// joins or removes the nodes supplied by the provider of the pool.
+func (h *handler) ScaleNodePool(request *restful.Request, response *restful.Response) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	name := request.PathParameter(query.ParameterName)
+	poolName := request.PathParameter("pool")
+	ctx := request.Request.Context()
+	body := &NodePoolScale{}
+	if err := request.ReadEntity(body); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if body.WorkerCount < 0 {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("workerCount must not be negative"))
+		return
+	}
+	c, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Kubeadm == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("kubeadm of cluster %s is empty", name))
+		return
+	}
+	pool := c.Kubeadm.NodePool(poolName)
+	if pool == nil {
+		restplus.HandleNotFound(response, request, fmt.Errorf("%w: %s", ErrNodePoolNotFound, poolName))
+		return
+	}
+	count := body.WorkerCount
+	pool.WorkerCount = &count
+	if body.Provider != nil {
+		pool.Provider = body.Provider
+	}
+	if err = pool.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if dryRun {
+		_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+		return
+	}
+	if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
@@ -0,0 +1,184 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/auditing"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	apirequest "github.com/kubeclipper/kubeclipper/pkg/server/request"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+const (
+	shellMsgCmd    = "cmd"
+	shellMsgResize = "resize"
+)
+
+// shellMsg is the websocket message of the console, the same as the one of the ssh terminal.
+type shellMsg struct {
+	Type string `json:"type"`
+	Cmd  string `json:"cmd"`
+	Cols int    `json:"cols"`
+	Rows int    `json:"rows"`
+}
+
+// ShellToNode opens a shell on the node through its agent, no ssh credential is needed.
+// The transcript is logged by the agent to the operation named after the session.
+func (h *handler) ShellToNode(request *restful.Request, response *restful.Response) {
+	nodeName := request.PathParameter(query.ParameterName)
+	cols := query.GetIntValueWithDefault(request, ParameterCols, 150)
+	rows := query.GetIntValueWithDefault(request, ParameterRows, 35)
+
+	wsConn, err := upGrader.Upgrade(response.ResponseWriter, request.Request, nil)
+	if err != nil {
+		logger.Error("upgrade websocket error", zap.Error(err))
+		return
+	}
+	defer wsConn.Close()
+
+	ctx := request.Request.Context()
+	if _, err = h.clusterOperator.GetNodeEx(ctx, nodeName, "0"); err != nil {
+		closeShell(wsConn, 4000, "BadRequest: parameter error")
+		return
+	}
+	term, err := h.delivery.OpenTerminal(ctx, nodeName, uint16(rows), uint16(cols))
+	if err != nil {
+		logger.Error("open node terminal error", zap.String("node", nodeName), zap.Error(err))
+		closeShell(wsConn, 4002, "open terminal failed")
+		return
+	}
+	defer term.Close()
+	h.auditShell(request, nodeName, term.ID())
+	logger.Info("node shell opened", zap.String("node", nodeName), zap.String("session", term.ID()))
+
+	_ = wsConn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	wsConn.SetPongHandler(func(string) error {
+		return wsConn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	})
+	go h.receiveShellMsg(wsConn, term)
+
+	// only this goroutine writes data to the websocket
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := wsConn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case frame, ok := <-term.Output():
+			if !ok {
+				return
+			}
+			if frame.Type == service.TerminalClose {
+				logger.Info("node shell closed", zap.String("session", term.ID()), zap.ByteString("reason", frame.Data))
+				closeShell(wsConn, websocket.CloseNormalClosure, "")
+				return
+			}
+			if err := wsConn.WriteMessage(websocket.TextMessage, frame.Data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// receiveShellMsg sends the input of the console to the node until the websocket is closed.
+func (h *handler) receiveShellMsg(wsConn *websocket.Conn, term service.Terminal) {
+	defer term.Close()
+	for {
+		_, data, err := wsConn.ReadMessage()
+		if err != nil {
+			return
+		}
+		msg := shellMsg{}
+		if err = json.Unmarshal(data, &msg); err != nil {
+			logger.Error("unmarshal shell message error", zap.Error(err))
+			continue
+		}
+		switch msg.Type {
+		case shellMsgResize:
+			if msg.Cols > 0 && msg.Rows > 0 {
+				err = term.Send(service.TerminalFrame{Type: service.TerminalResize, Rows: uint16(msg.Rows), Cols: uint16(msg.Cols)})
+			}
+		case shellMsgCmd:
+			var cmd []byte
+			if cmd, err = base64.StdEncoding.DecodeString(msg.Cmd); err != nil {
+				logger.Error("decode shell message error", zap.Error(err))
+				continue
+			}
+			err = term.Send(service.TerminalFrame{Type: service.TerminalData, Data: cmd})
+		}
+		if err != nil {
+			logger.Error("send shell message error", zap.String("session", term.ID()), zap.Error(err))
+			return
+		}
+	}
+}
+
+// auditShell records the session, get requests are not audited by the filter.
+func (h *handler) auditShell(request *restful.Request, node, session string) {
+	now := metav1.NowMicro()
+	ev := &v1.Event{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Event",
+			APIVersion: "core.kubeclipper.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "audit-",
+		},
+		AuditID:                  session,
+		RequestURI:               request.Request.URL.Path,
+		SourceIP:                 auditing.RemoteIP(request.Request),
+		UserAgent:                request.Request.UserAgent(),
+		Verb:                     "create",
+		Success:                  true,
+		RequestReceivedTimestamp: now,
+		StageTimestamp:           now,
+		Resource:                 "nodes",
+		ResourceName:             node,
+		Subresource:              "shell",
+		ResourceAPIGroup:         GroupVersion.Group,
+		ResourceAPIVersion:       GroupVersion.Version,
+	}
+	if u, ok := apirequest.UserFrom(request.Request.Context()); ok {
+		ev.UserID = u.GetUID()
+		ev.Username = u.GetName()
+	}
+	if _, err := h.platformOperator.CreateEvent(request.Request.Context(), ev); err != nil {
+		logger.Error("create node shell audit event error", zap.String("session", session), zap.Error(err))
+	}
+}
+
+func closeShell(wsConn *websocket.Conn, code int, text string) {
+	message := websocket.FormatCloseMessage(code, text)
+	if err := wsConn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second)); err != nil {
+		logger.Debug("write websocket close message error", zap.Error(err))
+	}
+}
@@ -0,0 +1,177 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/notification"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+func (h *handler) ListNotifiers(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	list, err := h.platformOperator.ListNotifiersEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, list, q.Fields)
+}
+
+func (h *handler) DescribeNotifier(request *restful.Request, response *restful.Response) {
+	n, err := h.platformOperator.GetNotifierEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, n, requestFields(request))
+}
+
+func (h *handler) CreateNotifier(request *restful.Request, response *restful.Response) {
+	n := &v1.Notifier{}
+	if err := request.ReadEntity(n); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := n.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	n, err := h.platformOperator.CreateNotifier(request.Request.Context(), n)
+	if err != nil {
+		if apimachineryErrors.IsAlreadyExists(err) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusCreated, n)
+}
+
+func (h *handler) UpdateNotifier(request *restful.Request, response *restful.Response) {
+	n := &v1.Notifier{}
+	if err := request.ReadEntity(n); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if name := request.PathParameter(query.ParameterName); name != n.Name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("notifier name not match"))
+		return
+	}
+	if err := n.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	n, err := h.platformOperator.UpdateNotifier(request.Request.Context(), n)
+	if err != nil {
+		switch {
+		case apimachineryErrors.IsNotFound(err):
+			restplus.HandleNotFound(response, request, err)
+		case apimachineryErrors.IsConflict(err):
+			restplus.HandleConflict(response, request, err)
+		default:
+			restplus.HandleInternalError(response, request, err)
+		}
+		return
+	}
+	restplus.SetETag(response, n)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, n)
+}
+
+func (h *handler) DeleteNotifier(request *restful.Request, response *restful.Response) {
+	if err := h.platformOperator.DeleteNotifier(request.Request.Context(), request.PathParameter(query.ParameterName)); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// TestNotifier sends a test event to the notifier right away, the delivery is
+// not retried nor recorded in the history.
+func (h *handler) TestNotifier(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	n, err := h.platformOperator.GetNotifier(request.Request.Context(), name)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	e := &v1.NotificationEvent{
+		Type:    "Test",
+		Object:  name,
+		Message: "This is a test notification sent by KubeClipper.",
+		Time:    metav1.Now(),
+	}
+	if err = notification.Send(request.Request.Context(), n, e); err != nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("send test notification failed: %v", err))
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) ListNotifications(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	if notifier := request.QueryParameter("notifier"); notifier != "" {
+		selector := fmt.Sprintf("%s=%s", common.LabelNotifier, notifier)
+		if q.LabelSelector != "" {
+			selector = q.LabelSelector + "," + selector
+		}
+		q.LabelSelector = selector
+	}
+	list, err := h.platformOperator.ListNotificationsEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, list, q.Fields)
+}
+
+func (h *handler) DescribeNotification(request *restful.Request, response *restful.Response) {
+	n, err := h.platformOperator.GetNotificationEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, n, requestFields(request))
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+const (
+	// parameterKind and parameterObject list the events of the objects of the kind or with the name.
+	parameterKind   = "kind"
+	parameterObject = "object"
+	// parameterCluster lists the events of the cluster and of its nodes, operations and backups.
+	parameterCluster = "cluster"
+)
+
+func (h *handler) ListObjectEvents(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	if kind := request.QueryParameter(parameterKind); kind != "" {
+		q.FieldSelector = appendSelector(q.FieldSelector, fmt.Sprintf("involvedObject.kind=%s", kind))
+	}
+	if object := request.QueryParameter(parameterObject); object != "" {
+		q.FieldSelector = appendSelector(q.FieldSelector, fmt.Sprintf("involvedObject.name=%s", object))
+	}
+	if c := request.QueryParameter(parameterCluster); c != "" {
+		q.LabelSelector = appendSelector(q.LabelSelector, fmt.Sprintf("%s=%s", common.LabelClusterName, c))
+	}
+	list, err := h.events.ListObjectEventsEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, list, q.Fields)
+}
+
+func (h *handler) DescribeObjectEvent(request *restful.Request, response *restful.Response) {
+	e, err := h.events.GetObjectEventEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, e, requestFields(request))
+}
+
+func appendSelector(selector, requirement string) string {
+	if selector == "" {
+		return requirement
+	}
+	return selector + "," + requirement
+}
@@ -0,0 +1,112 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// operationLogCheckInterval is how often a streamed operation is checked to be finished.
+const operationLogCheckInterval = 5 * time.Second
+
+// StreamOperationLog sends the step log chunks of a running operation to the websocket as json messages,
+// optionally filtered by node and step. The websocket is closed normally once the operation is finished.
+// Only the content appended after the websocket is opened is sent, the earlier content is fetched from /logs.
+func (h *handler) StreamOperationLog(request *restful.Request, response *restful.Response) {
+	opName := request.PathParameter(query.ParameterName)
+	node := request.QueryParameter(query.ParameterNode)
+	step := request.QueryParameter(query.ParameterStep)
+
+	wsConn, err := upGrader.Upgrade(response.ResponseWriter, request.Request, nil)
+	if err != nil {
+		logger.Error("upgrade websocket error", zap.Error(err))
+		return
+	}
+	defer wsConn.Close()
+
+	ctx, cancel := context.WithCancel(request.Request.Context())
+	defer cancel()
+	op, err := h.opOperator.GetOperationEx(ctx, opName, "0")
+	if err != nil {
+		closeShell(wsConn, 4000, "BadRequest: parameter error")
+		return
+	}
+	if operationFinished(op) {
+		closeShell(wsConn, websocket.CloseNormalClosure, "")
+		return
+	}
+	chunks := h.delivery.WatchOperationLog(ctx, opName)
+
+	// the client sends nothing, reading is only to notice it is gone
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := wsConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(operationLogCheckInterval)
+	defer ticker.Stop()
+	finished := false
+	for {
+		select {
+		case <-ticker.C:
+			// wait one more interval for the chunks published just before the operation finished
+			if finished {
+				closeShell(wsConn, websocket.CloseNormalClosure, "")
+				return
+			}
+			if op, err = h.opOperator.GetOperationEx(ctx, opName, "0"); err != nil || operationFinished(op) {
+				finished = true
+			}
+			if err := wsConn.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case chunk, ok := <-chunks:
+			if !ok {
+				return
+			}
+			if (node != "" && chunk.Node != node) || (step != "" && chunk.StepID != step) {
+				continue
+			}
+			if err := wsConn.WriteJSON(chunk); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func operationFinished(op *v1.Operation) bool {
+	switch op.Status.Status {
+	case v1.OperationStatusSuccessful, v1.OperationStatusFailed, v1.OperationStatusCancelled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// systemKeyDomain is the domain of the labels and annotations kubeclipper manages itself.
+const systemKeyDomain = "kubeclipper.io/"
+
+// The getters of the resources patched by restplus.PatchHandler.
+
+func (h *handler) getCluster(ctx context.Context, name string) (runtime.Object, error) {
+	return h.clusterOperator.GetClusterEx(ctx, name, "")
+}
+
+func (h *handler) getNode(ctx context.Context, name string) (runtime.Object, error) {
+	return h.clusterOperator.GetNodeEx(ctx, name, "")
+}
+
+func (h *handler) getBackupPoint(ctx context.Context, name string) (runtime.Object, error) {
+	return h.clusterOperator.GetBackupPointEx(ctx, name, "")
+}
+
+func (h *handler) getTemplate(ctx context.Context, name string) (runtime.Object, error) {
+	return h.clusterOperator.GetTemplateEx(ctx, name, "")
+}
+
+func (h *handler) getClusterTemplate(ctx context.Context, name string) (runtime.Object, error) {
+	return h.clusterOperator.GetClusterTemplateEx(ctx, name, "")
+}
+
+func (h *handler) getCronMaintenance(ctx context.Context, name string) (runtime.Object, error) {
+	return h.clusterOperator.GetCronMaintenanceEx(ctx, name, "")
+}
+
+func (h *handler) getDomain(ctx context.Context, name string) (runtime.Object, error) {
+	return h.clusterOperator.GetDomain(ctx, name)
+}
+
+func (h *handler) getNotifier(ctx context.Context, name string) (runtime.Object, error) {
+	return h.platformOperator.GetNotifierEx(ctx, name, "")
+}
+
+func (h *handler) getWebhookConfiguration(ctx context.Context, name string) (runtime.Object, error) {
+	return h.webhooks.GetWebhookConfigurationEx(ctx, name, "")
+}
+
+// updateNode is the update behind the node patches, nodes have no PUT route since agents own
+// them. Only the labels and annotations outside of the kubeclipper.io domain can be changed.
+func (h *handler) updateNode(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	desired := v1.Node{}
+	if err := request.ReadEntity(&desired); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if desired.Name != name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("the name of the object (%s) does not match the name on the URL (%s)", desired.Name, name))
+		return
+	}
+	node, err := h.clusterOperator.GetNodeEx(request.Request.Context(), name, "")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	node.Labels = mergeUserKeys(node.Labels, desired.Labels)
+	node.Annotations = mergeUserKeys(node.Annotations, desired.Annotations)
+	updated, err := h.clusterOperator.UpdateNode(request.Request.Context(), node)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	restplus.SetETag(response, updated)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, updated)
+}
+
+// mergeUserKeys returns the system keys of current with the user keys of desired.
+func mergeUserKeys(current, desired map[string]string) map[string]string {
+	merged := make(map[string]string, len(desired))
+	for k, v := range current {
+		if strings.Contains(k, systemKeyDomain) {
+			merged[k] = v
+		}
+	}
+	for k, v := range desired {
+		if !strings.Contains(k, systemKeyDomain) {
+			merged[k] = v
+		}
+	}
+	return merged
+}
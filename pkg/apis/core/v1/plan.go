@@ -0,0 +1,132 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// OperationPlan is returned by the operation-creating APIs on dry run,
+// it lists the steps the operation would run in order.
+type OperationPlan struct {
+	Operation string        `json:"operation"`
+	Action    string        `json:"action,omitempty"`
+	Cluster   string        `json:"cluster,omitempty"`
+	Steps     []PlannedStep `json:"steps"`
+	// Impact is the disruption the operation causes to the nodes and the control plane of the cluster.
+	Impact *OperationImpact `json:"impact,omitempty"`
+}
+
+type PlannedStep struct {
+	Name       string            `json:"name"`
+	Action     corev1.StepAction `json:"action,omitempty"`
+	Nodes      []corev1.StepNode `json:"nodes"`
+	Timeout    string            `json:"timeout,omitempty"`
+	RetryTimes int32             `json:"retryTimes,omitempty"`
+	ErrIgnore  bool              `json:"errIgnore,omitempty"`
+	Checkpoint bool              `json:"checkpoint,omitempty"`
+	Commands   []PlannedCommand  `json:"commands,omitempty"`
+}
+
+type PlannedCommand struct {
+	// Phase is one of beforeRun, run and afterRun.
+	Phase string             `json:"phase"`
+	Type  corev1.CommandType `json:"type"`
+	// Command is the shell command line for shell commands.
+	Command   string                     `json:"command,omitempty"`
+	Container *corev1.ContainerExecution `json:"container,omitempty"`
+	// Identity and Config describe the component a custom or template command runs,
+	// the agent renders its actual commands from them.
+	Identity string          `json:"identity,omitempty"`
+	Config   json.RawMessage `json:"config,omitempty"`
+}
+
+func newOperationPlan(op *corev1.Operation) *OperationPlan {
+	// step policies are applied on delivery, the plan must show their result
+	op = op.DeepCopy()
+	op.ApplyStepPolicies()
+	plan := &OperationPlan{
+		Operation: op.Name,
+		Action:    op.Labels[common.LabelOperationAction],
+		Cluster:   op.Labels[common.LabelClusterName],
+		Steps:     make([]PlannedStep, 0, len(op.Steps)),
+	}
+	for _, step := range op.Steps {
+		ps := PlannedStep{
+			Name:       step.Name,
+			Action:     step.Action,
+			Nodes:      step.Nodes,
+			RetryTimes: step.RetryTimes,
+			ErrIgnore:  step.ErrIgnore,
+			Checkpoint: step.Checkpoint,
+		}
+		if step.Timeout.Duration > 0 {
+			ps.Timeout = step.Timeout.Duration.String()
+		}
+		ps.Commands = append(ps.Commands, plannedCommands("beforeRun", step.BeforeRunCommands)...)
+		ps.Commands = append(ps.Commands, plannedCommands("run", step.Commands)...)
+		ps.Commands = append(ps.Commands, plannedCommands("afterRun", step.AfterRunCommands)...)
+		plan.Steps = append(plan.Steps, ps)
+	}
+	return plan
+}
+
+func plannedCommands(phase string, cmds []corev1.Command) []PlannedCommand {
+	planned := make([]PlannedCommand, 0, len(cmds))
+	for _, c := range cmds {
+		pc := PlannedCommand{Phase: phase, Type: c.Type}
+		switch c.Type {
+		case corev1.CommandShell:
+			pc.Command = strings.Join(c.ShellCommand, " ")
+			pc.Container = c.Container
+		case corev1.CommandCustom:
+			pc.Identity = c.Identity
+			pc.Config = rawConfig(c.CustomCommand)
+		case corev1.CommandTemplateRender:
+			if c.Template != nil {
+				pc.Identity = c.Template.Identity
+				pc.Config = rawConfig(c.Template.Data)
+			}
+		}
+		planned = append(planned, pc)
+	}
+	return planned
+}
+
+// rawConfig keeps valid json as is, so it is shown as an object rather than base64.
+func rawConfig(data []byte) json.RawMessage {
+	if len(data) == 0 || !json.Valid(data) {
+		return nil
+	}
+	return data
+}
+
+// writeOperationPlan answers a dry run with the plan of op and its impact, nothing is persisted or sent to agents.
+func (h *handler) writeOperationPlan(request *restful.Request, response *restful.Response, op *corev1.Operation) {
+	plan := newOperationPlan(op)
+	plan.Impact = h.operationImpact(request.Request.Context(), op)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, plan)
+}
@@ -0,0 +1,160 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestNewOperationPlan(t *testing.T) {
+	retries := int32(3)
+	op := &corev1.Operation{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "op1",
+			Labels: map[string]string{
+				common.LabelClusterName:     "c1",
+				common.LabelOperationAction: corev1.OperationUpgradeCluster,
+			},
+		},
+		Steps: []corev1.Step{
+			{
+				Name:   "drainNode",
+				Action: corev1.ActionInstall,
+				Nodes:  []corev1.StepNode{{ID: "n1", IPv4: "10.0.0.1"}},
+				BeforeRunCommands: []corev1.Command{
+					{Type: corev1.CommandShell, ShellCommand: []string{"kubectl", "cordon", "n1"}},
+				},
+				Commands: []corev1.Command{
+					{Type: corev1.CommandCustom, Identity: "upgrade", CustomCommand: []byte(`{"version":"v1.24.0"}`)},
+					{Type: corev1.CommandTemplateRender, Template: &corev1.TemplateCommand{Identity: "tmpl", Data: []byte("not json")}},
+				},
+			},
+		},
+		StepPolicies: []corev1.StepPolicy{
+			{Step: "drain*", Timeout: &metav1.Duration{Duration: time.Minute}, RetryTimes: &retries},
+		},
+	}
+
+	plan := newOperationPlan(op)
+	if plan.Operation != "op1" || plan.Cluster != "c1" || plan.Action != corev1.OperationUpgradeCluster {
+		t.Fatalf("unexpected plan header %+v", plan)
+	}
+	if len(plan.Steps) != 1 {
+		t.Fatalf("got %d steps, want 1", len(plan.Steps))
+	}
+	step := plan.Steps[0]
+	if step.Timeout != "1m0s" || step.RetryTimes != 3 {
+		t.Errorf("step policies not applied: timeout=%s retryTimes=%d", step.Timeout, step.RetryTimes)
+	}
+	if op.Steps[0].RetryTimes != 0 {
+		t.Error("planning must not modify the operation")
+	}
+	if len(step.Commands) != 3 {
+		t.Fatalf("got %d commands, want 3", len(step.Commands))
+	}
+	if c := step.Commands[0]; c.Phase != "beforeRun" || c.Command != "kubectl cordon n1" {
+		t.Errorf("unexpected shell command %+v", c)
+	}
+	if c := step.Commands[1]; c.Phase != "run" || c.Identity != "upgrade" || string(c.Config) != `{"version":"v1.24.0"}` {
+		t.Errorf("unexpected custom command %+v", c)
+	}
+	if c := step.Commands[2]; c.Identity != "tmpl" || c.Config != nil {
+		t.Errorf("unexpected template command %+v", c)
+	}
+}
+
+func TestNewOperationImpact(t *testing.T) {
+	master := corev1.StepNode{ID: "m1", Hostname: "master-1"}
+	workers := []corev1.StepNode{{ID: "w1", Hostname: "worker-1"}, {ID: "w2", Hostname: "worker-2"}}
+	op := &corev1.Operation{
+		Steps: []corev1.Step{
+			{
+				ID:      "s1",
+				Name:    "UpgradeControlPlane-master-1",
+				Nodes:   []corev1.StepNode{master},
+				Timeout: metav1.Duration{Duration: 10 * time.Minute},
+				Commands: []corev1.Command{{Type: corev1.CommandShell, ShellCommand: []string{"/bin/bash", "-c",
+					"kubectl drain master-1 --ignore-daemonsets || true\nkubeadm upgrade apply v1.24.0\nsystemctl daemon-reload && systemctl restart kubelet"}}},
+			},
+			{
+				ID:         "s2",
+				Name:       "DrainNodes-batch-0",
+				Nodes:      []corev1.StepNode{master},
+				Timeout:    metav1.Duration{Duration: 2 * time.Minute},
+				RetryTimes: 1,
+				Commands: []corev1.Command{{Type: corev1.CommandShell, ShellCommand: []string{"/bin/bash", "-c",
+					"for n in worker-1 worker-2; do kubectl drain $n --ignore-daemonsets; done"}}},
+			},
+			{
+				ID:      "s3",
+				Name:    "UpgradeWorkers-batch-0",
+				Nodes:   workers,
+				Timeout: metav1.Duration{Duration: 10 * time.Minute},
+				Commands: []corev1.Command{{Type: corev1.CommandShell, ShellCommand: []string{"/bin/bash", "-c",
+					"kubeadm upgrade node\nsystemctl restart containerd.service\nsystemctl restart kc-zram-swap"}}},
+			},
+		},
+	}
+	at := func(minutes int) metav1.Time {
+		return metav1.NewTime(time.Date(2022, 1, 1, 0, minutes, 0, 0, time.UTC))
+	}
+	history := []corev1.Operation{*op.DeepCopy(), *op.DeepCopy()}
+	history[0].Status.Conditions = []corev1.OperationCondition{
+		{StepID: "s1", Status: []corev1.StepStatus{{StartAt: at(0), EndAt: at(4)}}},
+		{StepID: "s3", Status: []corev1.StepStatus{{StartAt: at(5), EndAt: at(7)}, {StartAt: at(5), EndAt: at(10)}}},
+	}
+	history[1].Status.Conditions = []corev1.OperationCondition{
+		{StepID: "s1", Status: []corev1.StepStatus{{StartAt: at(0), EndAt: at(6)}}},
+		{StepID: "s3", Status: []corev1.StepStatus{{StartAt: at(7), EndAt: at(20)}}},
+	}
+
+	impact := newOperationImpact(op, sets.NewString("m1"), history)
+	want := []NodeImpact{
+		{Node: "master-1", Drained: true, Restarted: []string{"kubelet"}},
+		{Node: "worker-1", Drained: true, Restarted: []string{"containerd"}},
+		{Node: "worker-2", Drained: true, Restarted: []string{"containerd"}},
+	}
+	if !reflect.DeepEqual(impact.Nodes, want) {
+		t.Errorf("nodes = %+v, want %+v", impact.Nodes, want)
+	}
+	wantWindows := []UnavailabilityWindow{{Step: "UpgradeControlPlane-master-1", Node: "master-1", Outage: true, ExpectedDuration: "5m0s"}}
+	if !reflect.DeepEqual(impact.ControlPlane, wantWindows) {
+		t.Errorf("control plane = %+v, want %+v", impact.ControlPlane, wantWindows)
+	}
+	if impact.ExpectedDuration != "15m0s" || impact.Samples != 2 {
+		t.Errorf("expected duration = %s from %d samples, want 15m0s from 2", impact.ExpectedDuration, impact.Samples)
+	}
+	if impact.MaxDuration != "24m0s" {
+		t.Errorf("max duration = %s, want 24m0s", impact.MaxDuration)
+	}
+
+	// a control plane node of an HA cluster leaves the others serving
+	impact = newOperationImpact(op, sets.NewString("m1", "m2", "m3"), nil)
+	if len(impact.ControlPlane) != 1 || impact.ControlPlane[0].Outage || impact.Samples != 0 || impact.ExpectedDuration != "" {
+		t.Errorf("unexpected impact without history %+v", impact)
+	}
+}
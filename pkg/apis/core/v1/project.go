@@ -0,0 +1,155 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	apirequest "github.com/kubeclipper/kubeclipper/pkg/server/request"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// ErrNodesProjectDifferent is returned when nodes join a cluster of another project.
+var ErrNodesProjectDifferent = errors.New("nodes belongs to different project")
+
+// NodeProject assigns a node to a project, an empty project returns the node to the platform.
+type NodeProject struct {
+	Project string `json:"project"`
+}
+
+// requestProject returns the project the request is scoped to, empty for requests across all projects.
+func requestProject(ctx context.Context) string {
+	if info, ok := apirequest.InfoFrom(ctx); ok {
+		return info.Project
+	}
+	return ""
+}
+
+// assignClusterProject labels the cluster created by a request with the project of the request.
+// A cluster labeled with another project is rejected.
+func assignClusterProject(ctx context.Context, c *v1.Cluster) error {
+	project := requestProject(ctx)
+	if project == "" {
+		return nil
+	}
+	if p, ok := c.Labels[common.LabelProject]; ok && p != project {
+		return fmt.Errorf("cluster %s can not be created in project %s for project %s", c.Name, p, project)
+	}
+	if c.Labels == nil {
+		c.Labels = make(map[string]string)
+	}
+	c.Labels[common.LabelProject] = project
+	return nil
+}
+
+// checkNodesProject makes sure the nodes belong to the project of the cluster they join,
+// nodes of no project only join the clusters of no project.
+func (h *handler) checkNodesProject(ctx context.Context, c *v1.Cluster, nodes []string) error {
+	project := c.Labels[common.LabelProject]
+	for _, name := range nodes {
+		node, err := h.clusterOperator.GetNodeEx(ctx, name, "0")
+		if err != nil {
+			return err
+		}
+		if p := node.Labels[common.LabelProject]; p != project {
+			return fmt.Errorf("%w: node %s belongs to project %q, cluster %s to project %q", ErrNodesProjectDifferent, name, p, c.Name, project)
+		}
+	}
+	return nil
+}
+
+// checkNodeProjectChange makes sure a request scoped to a project only moves the nodes of that project,
+// and only returns them to the platform. Requests across all projects assign nodes to any project.
+func checkNodeProjectChange(ctx context.Context, node *v1.Node, target string) error {
+	project := requestProject(ctx)
+	if project == "" {
+		return nil
+	}
+	if p := node.Labels[common.LabelProject]; p != project {
+		return fmt.Errorf("node %s belongs to project %q, not to project %s", node.Name, p, project)
+	}
+	if target != "" && target != project {
+		return fmt.Errorf("node %s can not be moved to project %s for project %s", node.Name, target, project)
+	}
+	return nil
+}
+
+func (h *handler) UpdateNodeProject(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	assign := NodeProject{}
+	if err := request.ReadEntity(&assign); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if assign.Project != "" {
+		if _, err := h.projects.GetProjectEx(ctx, assign.Project, "0"); err != nil {
+			if apimachineryErrors.IsNotFound(err) {
+				restplus.HandleBadRequest(response, request, err)
+				return
+			}
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+
+	node, err := h.clusterOperator.GetNodeEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if err = checkNodeProjectChange(ctx, node, assign.Project); err != nil {
+		restplus.HandleForbidden(response, request, err)
+		return
+	}
+	if _, inCluster := node.Labels[common.LabelNodeRole]; inCluster {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("node %s is in use, it can not change project", node.Name))
+		return
+	}
+	if node.Labels == nil {
+		node.Labels = make(map[string]string)
+	}
+	if assign.Project == "" {
+		delete(node.Labels, common.LabelProject)
+	} else {
+		node.Labels[common.LabelProject] = assign.Project
+	}
+	updated, err := h.clusterOperator.UpdateNode(ctx, node)
+	if err != nil {
+		if apimachineryErrors.IsConflict(err) {
+			restplus.HandleConflict(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, updated)
+}
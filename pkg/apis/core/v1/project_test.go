@@ -0,0 +1,85 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	apirequest "github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+func TestAssignClusterProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		labels  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{name: "across projects", want: ""},
+		{name: "across projects keeps label", labels: map[string]string{common.LabelProject: "team-a"}, want: "team-a"},
+		{name: "in project", project: "team-a", want: "team-a"},
+		{name: "in project with same label", project: "team-a", labels: map[string]string{common.LabelProject: "team-a"}, want: "team-a"},
+		{name: "in project with other label", project: "team-a", labels: map[string]string{common.LabelProject: "team-b"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := apirequest.WithInfo(context.TODO(), &apirequest.Info{Project: tt.project})
+			c := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1", Labels: tt.labels}}
+			err := assignClusterProject(ctx, c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("assignClusterProject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && c.Labels[common.LabelProject] != tt.want {
+				t.Errorf("assignClusterProject() project = %q, want %q", c.Labels[common.LabelProject], tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckNodeProjectChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		labels  map[string]string
+		target  string
+		wantErr bool
+	}{
+		{name: "across projects", target: "team-b"},
+		{name: "across projects from project", labels: map[string]string{common.LabelProject: "team-a"}, target: "team-b"},
+		{name: "in project keeps project", project: "team-a", labels: map[string]string{common.LabelProject: "team-a"}, target: "team-a"},
+		{name: "in project returns to platform", project: "team-a", labels: map[string]string{common.LabelProject: "team-a"}},
+		{name: "in project to other project", project: "team-a", labels: map[string]string{common.LabelProject: "team-a"}, target: "team-b", wantErr: true},
+		{name: "in project node of other project", project: "team-a", labels: map[string]string{common.LabelProject: "team-b"}, target: "team-a", wantErr: true},
+		{name: "in project node of platform", project: "team-a", target: "team-a", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := apirequest.WithInfo(context.TODO(), &apirequest.Info{Project: tt.project})
+			node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: tt.labels}}
+			if err := checkNodeProjectChange(ctx, node, tt.target); (err != nil) != tt.wantErr {
+				t.Errorf("checkNodeProjectChange() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
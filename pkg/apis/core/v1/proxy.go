@@ -0,0 +1,168 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+const (
+	ParameterProxyPath = "subpath"
+
+	proxyModeAgent = "agent"
+	// proxyAgentTimeout bounds a request tunneled through the agent, watches are not supported there.
+	proxyAgentTimeout    = 30 * time.Second
+	proxyAgentKubeconfig = "/etc/kubernetes/admin.conf"
+)
+
+// ProxyCluster forwards the request to the apiserver of the cluster, authenticated with the credentials
+// stored in the cluster. Clusters annotated with the agent proxy mode, or whose apiserver can not be
+// dialed, are served through the agent of a master for get requests.
+func (h *handler) ProxyCluster(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	c, err := h.clusterOperator.GetClusterEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Annotations[common.AnnotationProxyMode] == proxyModeAgent {
+		h.proxyThroughAgent(request, response, c)
+		return
+	}
+	if c.KubeConfig == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster %s clientset not init", name))
+		return
+	}
+	cfg, _, err := client.FromKubeConfig(c.KubeConfig)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	target, err := url.Parse(cfg.Host)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	transport, err := rest.TransportFor(cfg)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	p := newClusterProxy(target, transport, proxyPath(request))
+	p.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" && r.Method == http.MethodGet {
+			h.proxyThroughAgent(request, response, c)
+			return
+		}
+		restplus.HandlerErrorWithCustomCode(response, request, http.StatusBadGateway, http.StatusBadGateway, "Bad gateway", err)
+	}
+	p.ServeHTTP(response.ResponseWriter, request.Request)
+}
+
+// proxyThroughAgent runs the get request with kubectl on the first master of the cluster.
+func (h *handler) proxyThroughAgent(request *restful.Request, response *restful.Response, c *v1.Cluster) {
+	if request.Request.Method != http.MethodGet {
+		restplus.HandleBadRequest(response, request,
+			fmt.Errorf("only get requests are proxied through the agent of cluster %s", c.Name))
+		return
+	}
+	if query.GetBoolValueWithDefault(request, query.ParameterWatch, false) {
+		restplus.HandleBadRequest(response, request,
+			fmt.Errorf("watch requests are not proxied through the agent of cluster %s", c.Name))
+		return
+	}
+	if c.Kubeadm == nil || len(c.Kubeadm.Masters) == 0 {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster %s has no master", c.Name))
+		return
+	}
+	raw := proxyPath(request)
+	if q := proxyQuery(request.Request.URL); q != "" {
+		raw += "?" + q
+	}
+	out, err := h.delivery.DeliverCmd(request.Request.Context(), c.Kubeadm.Masters[0].ID,
+		[]string{"kubectl", "--kubeconfig", proxyAgentKubeconfig, "get", "--raw", raw}, proxyAgentTimeout)
+	if err != nil {
+		restplus.HandlerErrorWithCustomCode(response, request, http.StatusBadGateway, http.StatusBadGateway, "Bad gateway", err)
+		return
+	}
+	// kubectl prints nothing to stdout when the apiserver rejects the request
+	if len(out) == 0 {
+		restplus.HandlerErrorWithCustomCode(response, request, http.StatusBadGateway, http.StatusBadGateway, "Bad gateway",
+			fmt.Errorf("the apiserver of cluster %s returned no content for %s", c.Name, raw))
+		return
+	}
+	response.Header().Set("Content-Type", restful.MIME_JSON)
+	response.WriteHeader(http.StatusOK)
+	_, _ = response.Write(out)
+}
+
+func proxyPath(request *restful.Request) string {
+	return "/" + strings.TrimPrefix(request.PathParameter(ParameterProxyPath), "/")
+}
+
+// proxyQuery is the query of the request without the kc-server token.
+func proxyQuery(u *url.URL) string {
+	q := u.Query()
+	if _, ok := q[ParameterToken]; !ok {
+		return u.RawQuery
+	}
+	q.Del(ParameterToken)
+	return q.Encode()
+}
+
+// newClusterProxy forwards requests to path of the apiserver at target. The caller authenticated to
+// kc-server, so its credentials are dropped and the transport authenticates to the apiserver instead.
+func newClusterProxy(target *url.URL, transport http.RoundTripper, path string) *httputil.ReverseProxy {
+	return &httputil.ReverseProxy{
+		Transport: transport,
+		// flush immediately so watch and log follow requests stream through
+		FlushInterval: -1,
+		Director: func(req *http.Request) {
+			req.URL.Scheme = target.Scheme
+			req.URL.Host = target.Host
+			req.URL.Path = strings.TrimSuffix(target.Path, "/") + path
+			req.URL.RawPath = ""
+			req.URL.RawQuery = proxyQuery(req.URL)
+			req.Host = target.Host
+			req.Header.Del("Authorization")
+		},
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+)
+
+func TestClusterProxy(t *testing.T) {
+	var gotAuth, gotPath, gotQuery string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+		gotQuery = r.URL.RawQuery
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL + "/k8s/")
+
+	ws := new(restful.WebService)
+	ws.Route(ws.GET("/clusters/{name}/proxy/{subpath:*}").To(func(request *restful.Request, response *restful.Response) {
+		newClusterProxy(target, http.DefaultTransport, proxyPath(request)).ServeHTTP(response.ResponseWriter, request.Request)
+	}))
+	container := restful.NewContainer()
+	container.Router(restful.CurlyRouter{})
+	container.Add(ws)
+	front := httptest.NewServer(container)
+	defer front.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, front.URL+"/clusters/c1/proxy/api/v1/namespaces/default/pods?limit=10&token=kc", nil)
+	req.Header.Set("Authorization", "Bearer kc")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if gotPath != "/k8s/api/v1/namespaces/default/pods" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotQuery != "limit=10" {
+		t.Errorf("query = %q", gotQuery)
+	}
+	if gotAuth != "" {
+		t.Errorf("caller credentials are forwarded: %q", gotAuth)
+	}
+}
+
+func TestProxyQuery(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "", want: ""},
+		{raw: "labelSelector=a%3Db&watch=1", want: "labelSelector=a%3Db&watch=1"},
+		{raw: "token=x&limit=5", want: "limit=5"},
+	}
+	for _, tt := range tests {
+		u := &url.URL{RawQuery: tt.raw}
+		if got := proxyQuery(u); got != tt.want {
+			t.Errorf("proxyQuery(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
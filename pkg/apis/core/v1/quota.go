@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/quota"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// DescribeRegionQuota returns the quota in effect for the region and what the region uses of it.
+func (h *handler) DescribeRegionQuota(request *restful.Request, response *restful.Response) {
+	ctx := request.Request.Context()
+	name := request.PathParameter(query.ParameterName)
+	if _, err := h.clusterOperator.GetRegionEx(ctx, name, "0"); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	q, err := h.quota.RegionQuota(ctx, name)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, q)
+}
+
+func (h *handler) UpdateRegionQuota(request *restful.Request, response *restful.Response) {
+	q := &v1.ResourceQuota{}
+	if err := request.ReadEntity(q); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := q.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	region, err := h.clusterOperator.GetRegionEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	region = region.DeepCopy()
+	region.Quota = q
+	region, err = h.clusterOperator.UpdateRegion(request.Request.Context(), region)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, region)
+}
+
+// admitCluster checks the new cluster and its install operation against the quota of the region.
+func (h *handler) admitCluster(ctx context.Context, op *v1.Operation) error {
+	if h.quota == nil {
+		return nil
+	}
+	return h.quota.AdmitCluster(ctx, op.Labels[common.LabelTopologyRegion])
+}
+
+// admitOperation checks another running operation against the quota of the region.
+func (h *handler) admitOperation(ctx context.Context, op *v1.Operation) error {
+	if h.quota == nil {
+		return nil
+	}
+	return h.quota.AdmitOperation(ctx, op.Labels[common.LabelTopologyRegion])
+}
+
+// handleQuotaError rejects the requests exceeding a quota as forbidden, like kubernetes does.
+func handleQuotaError(response *restful.Response, request *restful.Request, err error) {
+	if quota.IsExceeded(err) {
+		restplus.HandleForbidden(response, request, err)
+		return
+	}
+	restplus.HandleInternalError(response, request, err)
+}
@@ -0,0 +1,149 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// RecycleBinPurger deletes the clusters and backups whose retention in the recycle bin passed,
+// the same way DELETE /clusters/{name} and DELETE /clusters/{cluster}/backups/{backup} do.
+type RecycleBinPurger struct {
+	h *handler
+}
+
+func NewRecycleBinPurger(clusterOperator cluster.Operator, op operation.Operator, delivery service.IDelivery,
+	staticServerPath string) *RecycleBinPurger {
+	return &RecycleBinPurger{h: newHandler(clusterOperator, op, nil, nil, nil, delivery, nil, nil, nil, nil, nil, nil, staticServerPath)}
+}
+
+func (p *RecycleBinPurger) PurgeCluster(ctx context.Context, name string) error {
+	c, err := p.h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		return err
+	}
+	// restored while waiting
+	if !v1.IsDeleted(c) {
+		return nil
+	}
+	op, err := p.h.makeDeleteClusterOperation(ctx, c, v1.DefaultOperationTimeoutSecs)
+	if err != nil {
+		return err
+	}
+	propagation := metav1.DeletionPropagation(c.Annotations[common.AnnotationDeletionPropagation])
+	if propagation == "" {
+		propagation = metav1.DeletePropagationBackground
+	}
+	return p.h.startDeleteCluster(ctx, c, op, propagation)
+}
+
+func (p *RecycleBinPurger) PurgeBackup(ctx context.Context, cluster, name string) error {
+	c, err := p.h.clusterOperator.GetClusterEx(ctx, cluster, "0")
+	if err != nil {
+		return err
+	}
+	b, err := p.h.clusterOperator.GetBackupEx(ctx, cluster, name)
+	if err != nil {
+		return err
+	}
+	if !v1.IsDeleted(b) {
+		return nil
+	}
+	op, err := p.h.makeDeleteBackupOperation(ctx, c, b)
+	if err != nil {
+		return err
+	}
+	return p.h.startDeleteBackup(ctx, b, op)
+}
+
+// selectRecycleBin hides the objects in the recycle bin from a list, or lists only them
+// when the deleted query parameter is true.
+func selectRecycleBin(request *restful.Request, q *query.Query) {
+	if query.GetBoolValueWithDefault(request, query.ParamDeleted, false) {
+		q.LabelSelector = appendSelector(q.LabelSelector, common.LabelDeleted)
+		return
+	}
+	q.LabelSelector = appendSelector(q.LabelSelector, "!"+common.LabelDeleted)
+}
+
+// UndeleteCluster restores the cluster from the recycle bin.
+func (h *handler) UndeleteCluster(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	c, err := h.clusterOperator.GetClusterEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if !v1.IsDeleted(c) {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster %s is not in the recycle bin", name))
+		return
+	}
+	v1.UnmarkDeleted(c)
+	delete(c.Annotations, common.AnnotationDeletionPropagation)
+	c, err = h.clusterOperator.UpdateCluster(request.Request.Context(), c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+// UndeleteBackup restores the backup from the recycle bin.
+func (h *handler) UndeleteBackup(request *restful.Request, response *restful.Response) {
+	clusterName := request.PathParameter("cluster")
+	backupName := request.PathParameter("backup")
+	ctx := request.Request.Context()
+	b, err := h.clusterOperator.GetBackupEx(ctx, clusterName, backupName)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if !v1.IsDeleted(b) {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("backup %s is not in the recycle bin", backupName))
+		return
+	}
+	v1.UnmarkDeleted(b)
+	b, err = h.clusterOperator.UpdateBackup(ctx, b)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, b)
+}
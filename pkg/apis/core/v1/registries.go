@@ -0,0 +1,142 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	"github.com/google/uuid"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/cri"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// DescribeClusterRegistries returns the registries configured for containerd of the cluster.
+func (h *handler) DescribeClusterRegistries(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	c, err := h.clusterOperator.GetClusterEx(request.Request.Context(), name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Kubeadm == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("kubeadm of cluster %s is empty", name))
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, ClusterRegistries{Registries: c.Kubeadm.ContainerRuntime.Containerd.Registries})
+}
+
+// UpdateClusterRegistries saves the registries of the cluster, and applies them to containerd of every node.
+// Containerd restarts on one node at a time.
+func (h *handler) UpdateClusterRegistries(request *restful.Request, response *restful.Response) {
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	name := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	body := &ClusterRegistries{}
+	if err := request.ReadEntity(body); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	c, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Kubeadm == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("kubeadm of cluster %s is empty", name))
+		return
+	}
+	if c.Kubeadm.ContainerRuntime.Type != v1.CRIContainerd {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("registries are only supported by containerd, cluster %s runs %s",
+			name, c.Kubeadm.ContainerRuntime.Type))
+		return
+	}
+	containerd := c.Kubeadm.ContainerRuntime.Containerd.DeepCopy()
+	containerd.Registries = body.Registries
+	if err = containerd.ValidateRegistries(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err = h.clusterIdleError(ctx, c, "update registries"); err != nil {
+		restplus.HandleConflict(response, request, err)
+		return
+	}
+	meta, err := h.getClusterMetadata(ctx, c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	r := cri.ContainerdRegistryRunnable{}
+	if err = r.InitStep(component.WithExtraMetadata(ctx, *meta), containerd, utils.UnwrapNodeList(meta.GetAllNodes())); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:     c.Name,
+		common.LabelTopologyRegion:  meta.Masters[0].Region,
+		common.LabelOperationAction: v1.OperationUpdateRegistries,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+	}
+	op.Steps = r.GetActionSteps(v1.ActionInstall)
+	op.Status.Status = v1.OperationStatusRunning
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+
+	c.Kubeadm.ContainerRuntime.Containerd = *containerd
+	c.Status.Status = v1.ClusterStatusUpdating
+	if c, err = h.clusterOperator.UpdateCluster(ctx, c); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	v1.SetOwnerReference(op, v1.NewClusterOwnerReference(c))
+	if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	go h.doOperation(context.TODO(), op, &service.Options{})
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
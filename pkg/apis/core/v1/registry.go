@@ -21,7 +21,9 @@ package v1
 import (
 	"net/http"
 
+	"github.com/kubeclipper/kubeclipper/pkg/admission"
 	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/iam"
 
 	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
 
@@ -30,12 +32,20 @@ import (
 
 	"github.com/emicklei/go-restful"
 	restfulspec "github.com/emicklei/go-restful-openapi"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
 
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
 	"github.com/kubeclipper/kubeclipper/pkg/models"
 	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/nodemetrics"
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	"github.com/kubeclipper/kubeclipper/pkg/precheck"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
 	"github.com/kubeclipper/kubeclipper/pkg/server/runtime"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 )
@@ -46,8 +56,18 @@ const (
 	CoreClusterTag = "Core-Cluster"
 	CoreNodeTag    = "Core-Node"
 	CoreRegionTag  = "Core-Region"
+	// CoreNotificationTag groups the notifiers and their delivery history.
+	CoreNotificationTag = "Core-Notification"
+	// CoreAdmissionTag groups the admission webhook configurations.
+	CoreAdmissionTag = "Core-Admission"
+	// CoreEventTag groups the events recorded about kubeclipper objects.
+	CoreEventTag = "Core-Event"
 )
 
+const dryRunPlanDoc = "dry run, return the ordered steps of the operation and its impact on nodes and control plane without running them"
+
+const operationViewDoc = "full to include the step commands and responses, they are fetched from the steps sub-resource by default"
+
 /*
 this is how set up web service route only
 in that case cli tool can simply call it to get api route by pass in a nil parameter
@@ -66,17 +86,34 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
 			Required(false).
 			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamDeleted, "list the ones in the recycle bin instead of the others").
+			Required(false).
+			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
 			Required(false).
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "watch the changes after the resource version, e.g. the resourceVersion of a list").
+			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAllowWatchBookMark, "send bookmark events carrying the latest resource version").
+			Required(false).
+			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
 			DataType("integer").
 			DefaultValue("60").
 			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}))
 
 	webservice.Route(webservice.GET("/clusters/{name}/terminal").
@@ -91,12 +128,35 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
 
+	for _, method := range []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		webservice.Route(webservice.Method(method).Path("/clusters/{name}/proxy/{subpath:*}").
+			To(h.ProxyCluster).
+			Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+			Doc("Proxy the request to the kubernetes apiserver of the cluster.").
+			Param(webservice.PathParameter(query.ParameterName, "cluster name").
+				Required(true).
+				DataType("string")).
+			Param(webservice.PathParameter(ParameterProxyPath, "path of the apiserver, e.g. api/v1/pods").
+				Required(true).
+				DataType("string")).
+			Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
+	}
+
 	webservice.Route(webservice.POST("/clusters").
 		To(h.CreateClusters).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
 		Doc("Create clusters.").
 		Reads(corev1.Cluster{}).
-		Param(webservice.QueryParameter(query.ParamDryRun, "dry run create clusters").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}))
+
+	webservice.Route(webservice.POST("/clusters/import").
+		To(h.ImportCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Import an existing kubeadm cluster, its nodes must be joined as kubeclipper nodes.").
+		Reads(corev1.ClusterImport{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, "return the discovered cluster without importing it").
 			Required(false).DataType("boolean")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}))
 
@@ -110,15 +170,48 @@ func SetupWebService(h *handler) *restful.WebService {
 			Required(false).DataType("boolean")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
 
+	webservice.Route(webservice.PATCH("/clusters/{name}").
+		To(restplus.PatchHandler(h.getCluster, h.UpdateClusters)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Patch cluster with a merge patch or a json patch, the patched cluster is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParamDryRun, "dry run patch clusters").
+			Required(false).
+			DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
 	webservice.Route(webservice.DELETE("/clusters/{name}").
 		To(h.DeleteCluster).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
-		Doc("Delete clusters.").
+		Doc("Delete clusters, they are moved to the recycle bin when it is enabled and purged when they are in it.").
 		Param(webservice.PathParameter("name", "cluster name")).
-		Param(webservice.QueryParameter(query.ParamDryRun, "dry run delete clusters").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
 			Required(false).DataType("boolean")).
-		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
+		Param(webservice.QueryParameter(query.ParamPropagationPolicy, "how the operations and backups of the cluster are deleted once it is uninstalled, "+
+			"Background deletes them after the cluster, Foreground before it and Orphan keeps them").
+			Required(false).
+			DataType("string").
+			DefaultValue(string(metav1.DeletePropagationBackground))).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/clusters/{name}/undelete").
+		To(h.UndeleteCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Restore the cluster from the recycle bin.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
 	webservice.Route(webservice.GET("/clusters/{name}").
 		To(h.DescribeCluster).
@@ -130,6 +223,9 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
 			Required(false).
 			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
@@ -138,12 +234,160 @@ func SetupWebService(h *handler) *restful.WebService {
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
 		Doc("Add or remove cluster node.").
 		Reads(PatchNodes{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.POST("/clusters/{name}/resubmit").
+		To(h.ResubmitCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Resubmit a cluster which failed to install, with a changed node set.").
+		Reads(corev1.Cluster{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.GET("/clusters/{name}/kubeconfig").
+		To(h.GetKubeconfig).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Issue a view or edit kubeconfig of the cluster.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterScope, "kubeconfig scope, view or edit").
+			Required(false).
+			DefaultValue(string(corev1.KubeconfigScopeView)).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterExpiration, "lifetime of the kubeconfig token, between 10m and 720h").
+			Required(false).
+			DefaultValue("24h").
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterKubeconfig{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.GET("/clusters/{name}/adminkubeconfig").
+		To(h.GetAdminKubeconfig).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Issue a cluster-admin kubeconfig of the cluster.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterExpiration, "lifetime of the kubeconfig token, between 10m and 720h").
+			Required(false).
+			DefaultValue("24h").
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterKubeconfig{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.POST("/clusters/{name}/credentials/rotate").
+		To(h.RotateClusterCredentials).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Renew the admin.conf of the masters and revoke the kubeconfigs issued for the cluster. "+
+			"The former admin.conf is not revoked, it stays valid until its certificate expires.").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.POST("/clusters/{name}/certificates/renew").
+		To(h.RenewClusterCertificates).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Renew the control plane certificates of the cluster.").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.PUT("/clusters/{name}/nodepools/{pool}/scale").
+		To(h.ScaleNodePool).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Set the worker count of a node pool, the nodes supplied by the provider of the pool are joined or removed until the pool has the desired workers.").
+		Reads(NodePoolScale{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, "validate the worker count without saving it").
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.PathParameter("pool", "node pool name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.PUT("/clusters/{name}/certsans").
+		To(h.UpdateClusterCertSANs).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Update the extra cert SANs and the external endpoint of the cluster, and regenerate the apiserver certificate of every master.").
+		Reads(ClusterCertSANs{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.GET("/clusters/{name}/registries").
+		To(h.DescribeClusterRegistries).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Describe the registries configured for containerd of the cluster.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), ClusterRegistries{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.PUT("/clusters/{name}/registries").
+		To(h.UpdateClusterRegistries).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Update the registry mirrors, credentials and certificate authorities of the cluster, and apply them to containerd of every node.").
+		Reads(ClusterRegistries{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
 		Param(webservice.PathParameter(query.ParameterName, "cluster name").
 			Required(true).
 			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.GET("/clusters/{name}/export").
+		To(h.ExportCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Export the kubeadm configuration, inventory and scripts to maintain the cluster without kubeclipper.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter("format", "export format, kubeadm or terraform").
+			Required(false).
+			DefaultValue("kubeadm").
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterExport{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/clusters/{name}/backups").
 		To(h.ListBackupsWithCluster).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -155,6 +399,9 @@ func SetupWebService(h *handler) *restful.WebService {
 			Required(false).
 			DataFormat("limit=%d,page=%d").
 			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
@@ -166,7 +413,7 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.PathParameter(query.ParameterName, "cluster name").
 			Required(true).
 			DataType("string")).
-		Param(webservice.QueryParameter(query.ParamDryRun, "dry run create clusters").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
 			Required(false).DataType("boolean")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Backup{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
@@ -174,14 +421,24 @@ func SetupWebService(h *handler) *restful.WebService {
 	webservice.Route(webservice.DELETE("/clusters/{cluster}/backups/{backup}").
 		To(h.DeleteBackup).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
-		Doc("Delete backups.").
+		Doc("Delete backups, they are moved to the recycle bin when it is enabled and purged when they are in it.").
 		Param(webservice.PathParameter("cluster", "cluster name")).
 		Param(webservice.PathParameter("backup", "backup name")).
-		Param(webservice.QueryParameter(query.ParamDryRun, "dry run create clusters").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
 			Required(false).DataType("boolean")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.POST("/clusters/{cluster}/backups/{backup}/undelete").
+		To(h.UndeleteBackup).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Restore the backup from the recycle bin.").
+		Param(webservice.PathParameter("cluster", "cluster name")).
+		Param(webservice.PathParameter("backup", "backup name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Backup{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
 	webservice.Route(webservice.PUT("/clusters/{cluster}/backups/{backup}").
 		To(h.UpdateBackup).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -196,6 +453,8 @@ func SetupWebService(h *handler) *restful.WebService {
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
 		Doc("create recovery.").
 		Reads(corev1.Recovery{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
 		Param(webservice.PathParameter("cluster", "cluster name")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Recovery{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
@@ -205,8 +464,22 @@ func SetupWebService(h *handler) *restful.WebService {
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
 		Doc("Install or uninstall plugins").
 		Reads(PatchComponents{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter("cluster", "cluster name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.POST("/clusters/{cluster}/plugins/upgrade").
+		To(h.UpgradeComponents).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Upgrade installed plugins to the target versions or configs").
+		Reads(UpgradeComponents{}).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
 		Param(webservice.PathParameter("cluster", "cluster name")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
 	webservice.Route(webservice.GET("/nodes").
@@ -225,12 +498,26 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "watch the changes after the resource version, e.g. the resourceVersion of a list").
+			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAllowWatchBookMark, "send bookmark events carrying the latest resource version").
+			Required(false).
+			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
 			DataType("integer").
 			DefaultValue("60").
 			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}))
 
 	webservice.Route(webservice.GET("/regions").
@@ -249,12 +536,21 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
 			DataType("integer").
 			DefaultValue("60").
 			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}))
 
 	webservice.Route(webservice.GET("/regions/{name}").
@@ -267,9 +563,75 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
 			Required(false).
 			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Region{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.GET("/regions/{name}/clusterdefaults").
+		To(h.DescribeRegionClusterDefaults).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
+		Doc("Describe the cluster defaults in effect for the region, merged from platform and region defaults.").
+		Param(webservice.PathParameter(query.ParameterName, "region name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterDefaults{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/regions/{name}/clusterdefaults").
+		To(h.UpdateRegionClusterDefaults).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
+		Doc("Update the cluster defaults of the region, empty fields fall back to the platform defaults.").
+		Reads(corev1.ClusterDefaults{}).
+		Param(webservice.PathParameter(query.ParameterName, "region name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Region{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/regions/{name}/quota").
+		To(h.DescribeRegionQuota).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
+		Doc("Describe the quota in effect for the region, merged from platform and region quotas, and its usage.").
+		Param(webservice.PathParameter(query.ParameterName, "region name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.RegionQuota{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/regions/{name}/quota").
+		To(h.UpdateRegionQuota).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
+		Doc("Update the quota of the region, unset limits fall back to the platform quota.").
+		Reads(corev1.ResourceQuota{}).
+		Param(webservice.PathParameter(query.ParameterName, "region name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Region{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/regions/{name}/prewarm").
+		To(h.PrewarmRegion).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
+		Doc("Cache kubernetes and container runtime packages on the free nodes of a region.").
+		Reads(corev1.RegionPrewarm{}).
+		Param(webservice.PathParameter(query.ParameterName, "region name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Operation{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/nodes/{name}").
 		To(h.DescribeNode).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
@@ -280,9 +642,22 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
 			Required(false).
 			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Node{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.GET("/nodes/{name}/metrics").
+		To(h.DescribeNodeMetrics).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Get the recent resource usage of node, oldest first.").
+		Param(webservice.PathParameter(query.ParameterName, "node name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.NodeMetrics{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
 	webservice.Route(webservice.PATCH("/nodes/{name}/disable").
 		To(h.DisableNode).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
@@ -293,6 +668,19 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Node{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.PATCH("/nodes/{name}/project").
+		To(h.UpdateNodeProject).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Assign the node to a project, an empty project returns it to the platform. Nodes in use can not change project, "+
+			"nodes are only returned to the platform within a project.").
+		Reads(NodeProject{}).
+		Param(webservice.PathParameter(query.ParameterName, "node name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Node{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
+
 	webservice.Route(webservice.PATCH("/nodes/{name}/enable").
 		To(h.EnableNode).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
@@ -313,6 +701,48 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.PATCH("/nodes/{name}").
+		To(restplus.PatchHandler(h.getNode, h.updateNode)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Patch node with a merge patch or a json patch, only the labels and annotations outside of the kubeclipper.io domain can be changed.").
+		Param(webservice.PathParameter(query.ParameterName, "node name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Node{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/nodes/agentconfig").
+		To(h.ReconfigureAgents).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Push config changes to agents, the agents validate, persist and reload the config without restart.").
+		Reads(AgentConfigRequest{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), []AgentConfigResult{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/nodeprechecks").
+		To(h.CreateNodePrecheck).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Start an asynchronous precheck of candidate nodes over ssh, before they are deployed.").
+		Reads(precheck.Request{}).
+		Returns(http.StatusAccepted, http.StatusText(http.StatusAccepted), precheck.Job{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/nodeprechecks/{name}").
+		To(h.DescribeNodePrecheck).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Get a node precheck job and the results completed so far.").
+		Param(webservice.PathParameter(query.ParameterName, "precheck job id").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterWatch, "stream every result as a json line until the job completes").
+			Required(false).
+			DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), precheck.Job{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/logs").
 		To(h.GetOperationLog).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
@@ -348,12 +778,29 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "watch the changes after the resource version, e.g. the resourceVersion of a list").
+			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAllowWatchBookMark, "send bookmark events carrying the latest resource version").
+			Required(false).
+			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
 			DataType("integer").
 			DefaultValue("60").
 			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterView, operationViewDoc).
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.OperationList{}))
 
 	webservice.Route(webservice.GET("/operations/{name}").
@@ -366,32 +813,156 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
 			Required(false).
 			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterView, operationViewDoc).
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Operation{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
-	webservice.Route(webservice.POST("/operations/{name}/retry").
-		To(h.RetryCluster).
+	webservice.Route(webservice.GET("/operations/{name}/logs").
+		To(h.StreamOperationLog).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
-		Doc("clusters retry operation.").
-		Param(webservice.QueryParameter(query.ParamDryRun, "dry run clusters retry operation.").
-			Required(false).DataType("boolean")).
+		Doc("Stream the step logs of a running operation over websocket, one json chunk per message.").
 		Param(webservice.PathParameter(query.ParameterName, "operation name").
 			Required(true).
 			DataType("string")).
-		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}))
+		Param(webservice.QueryParameter(query.ParameterNode, "only stream the logs of the node").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterStep, "only stream the logs of the step").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), oplog.LogChunk{}))
 
-	webservice.Route(webservice.POST("/clusters/{name}/upgrade").
-		To(h.UpgradeCluster).
+	webservice.Route(webservice.GET("/operations/{name}/steps").
+		To(h.ListOperationSteps).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
-		Doc("upgrade cluster.").
+		Doc("List the steps of operation with their status, without step commands and responses.").
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), []corev1.OperationStep{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.GET("/operations/{name}/steps/{step}").
+		To(h.DescribeOperationStep).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Get a step of operation with its commands and status on every node.").
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.PathParameter(query.ParameterStep, "step index, starting from 0").
+			Required(true).
+			DataType("integer")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.OperationStep{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
+
+	webservice.Route(webservice.POST("/operations/{name}/retry").
+		To(h.RetryCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("clusters retry operation.").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}))
+
+	webservice.Route(webservice.POST("/operations/{name}/pause").
+		To(h.PauseOperation).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("pause a running operation at its next checkpoint, or after the running step when it has none.").
+		Param(webservice.QueryParameter(query.ParamDryRun, "dry run pause operation.").
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/operations/{name}/resume").
+		To(h.ResumeOperation).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("resume a paused or cancelled operation from its first step that did not complete.").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/operations/{name}/cancel").
+		To(h.CancelOperation).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("cancel a running or paused operation, the running step is stopped and the remaining steps are skipped.").
+		Param(webservice.QueryParameter(query.ParamDryRun, "dry run cancel operation.").
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "operation name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/clusters/{name}/upgrade").
+		To(h.UpgradeCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("upgrade cluster.").
 		Reads(ClusterUpgrade{}).
-		Param(webservice.QueryParameter(query.ParamDryRun, "dry run upgrade cluster.").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
+
+	webservice.Route(webservice.POST("/clusters/{name}/upgrade/pause").
+		To(h.PauseClusterUpgrade).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("pause a running cluster upgrade at the next batch boundary.").
+		Param(webservice.QueryParameter(query.ParamDryRun, "dry run pause cluster upgrade.").
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
+
+	webservice.Route(webservice.POST("/clusters/{name}/upgrade/resume").
+		To(h.ResumeClusterUpgrade).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("resume a paused cluster upgrade.").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
+
+	webservice.Route(webservice.POST("/clusters/{name}/upgrade/rollback").
+		To(h.RollbackClusterUpgrade).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("roll back the upgraded worker batches of a paused cluster upgrade.").
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
 			Required(false).DataType("boolean")).
 		Param(webservice.PathParameter(query.ParameterName, "cluster name").
 			Required(true).
 			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
 
+	webservice.Route(webservice.POST("/clusters/{name}/diff").
+		To(h.DiffCluster).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Compare the desired cluster with the existing one.").
+		Reads(corev1.Cluster{}).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterDiff{}))
+
 	webservice.Route(webservice.PATCH("/clusters/{name}/status").
 		To(h.ResetClusterStatus).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -403,6 +974,28 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil))
 
+	webservice.Route(webservice.GET("/clusters/{name}/lock").
+		To(h.GetClusterLock).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Get the operation holding the cluster and the operations queued after it.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterLock{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/clusters/{name}/lock").
+		To(h.BreakClusterLock).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Break the cluster lock left by a crashed operation, the unfinished operations of the cluster are marked failed.").
+		Param(webservice.QueryParameter(query.ParamDryRun, "only return the lock to break").
+			Required(false).DataType("boolean")).
+		Param(webservice.PathParameter(query.ParameterName, "cluster name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterLock{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/leases").
 		To(h.ListLeases).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
@@ -419,6 +1012,12 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
@@ -430,14 +1029,17 @@ func SetupWebService(h *handler) *restful.WebService {
 	webservice.Route(webservice.GET("/leases/{name}").
 		To(h.DescribeLease).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
-		Doc("Describe region.").
-		Param(webservice.PathParameter(query.ParameterName, "region name").
+		Doc("Describe lease.").
+		Param(webservice.PathParameter(query.ParameterName, "lease name").
 			Required(true).
 			DataType("string")).
 		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
 			Required(false).
 			DataType("string")).
-		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Region{}).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), coordinationv1.Lease{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
 	webservice.Route(webservice.GET("/backups").
@@ -451,17 +1053,34 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
 			Required(false).
 			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamDeleted, "list the ones in the recycle bin instead of the others").
+			Required(false).
+			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
 			Required(false).
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "watch the changes after the resource version, e.g. the resourceVersion of a list").
+			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterAllowWatchBookMark, "send bookmark events carrying the latest resource version").
+			Required(false).
+			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
 			DataType("integer").
 			DefaultValue("60").
 			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}))
 
 	webservice.Route(webservice.GET("/backups/{name}").
@@ -474,6 +1093,9 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
 			Required(false).
 			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Backup{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
@@ -502,6 +1124,88 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusExpectationFailed, http.StatusText(http.StatusExpectationFailed), errors.HTTPError{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
 
+	webservice.Route(webservice.GET("/nodes/{name}/shell").
+		To(h.ShellToNode).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreRegionTag}).
+		Doc("open a shell on node through its agent, the transcript is logged to the operation of the session").
+		Param(webservice.PathParameter(query.ParameterName, "node name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterToken, "auth token").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterCols, "terminal cols").
+			Required(false).
+			DefaultValue("150").
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterRows, "terminal rows").
+			Required(false).
+			DefaultValue("35").
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/nodes/{name}/files").
+		To(h.ListNodeFiles).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("List a directory on node.").
+		Param(webservice.PathParameter(query.ParameterName, "node name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterPath, "absolute path of the directory, e.g. /var/log").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), service.FileResponse{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/nodes/{name}/files/tail").
+		To(h.TailNodeFile).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Return the last lines of a file on node.").
+		Produces("text/plain").
+		Param(webservice.PathParameter(query.ParameterName, "node name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterPath, "absolute path of the file, e.g. /var/log/messages").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterLines, "number of lines").
+			Required(false).
+			DefaultValue("100").
+			DataType("integer")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), "").
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/nodes/{name}/files/download").
+		To(h.DownloadNodeFile).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("Download a file of at most 64MiB from node.").
+		Produces("application/octet-stream").
+		Param(webservice.PathParameter(query.ParameterName, "node name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterPath, "absolute path of the file").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/discoverednodes").
+		To(h.ListDiscoveredNodes).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNodeTag}).
+		Doc("List the machines of the configured inventories, which can be joined as agent nodes with kcctl join --discovered.").
+		Param(webservice.QueryParameter(ParameterProvider, "only list the machines of the inventory provider").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(ParameterRefresh, "list the machines from the inventories instead of the cache").
+			Required(false).
+			DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), inventory.MachineList{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/domains").
 		To(h.ListDomains).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -518,12 +1222,21 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
 			DataType("integer").
 			DefaultValue("60").
 			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}))
 
 	webservice.Route(webservice.GET("/domains/{name}").
@@ -533,6 +1246,9 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.PathParameter(query.ParameterName, "domain").
 			Required(true).
 			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Domain{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
@@ -580,6 +1296,19 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.PATCH("/domains/{name}").
+		To(restplus.PatchHandler(h.getDomain, h.UpdateDomain)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Patch domain with a merge patch or a json patch, the patched domain is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "domain name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Domain{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/domains/{name}/records").
 		To(h.ListRecords).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -596,6 +1325,7 @@ func SetupWebService(h *handler) *restful.WebService {
 			DefaultValue("limit=10,page=1")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}))
 
 	webservice.Route(webservice.GET("/domains/{name}/records/{subdomain}").
@@ -663,6 +1393,15 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
@@ -673,6 +1412,9 @@ func SetupWebService(h *handler) *restful.WebService {
 		Param(webservice.PathParameter(query.ParameterName, "name").
 			Required(true).
 			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Template{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
@@ -698,6 +1440,19 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.PATCH("/templates/{name}").
+		To(restplus.PatchHandler(h.getTemplate, h.UpdateTemplate)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Patch template with a merge patch or a json patch, the patched template is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "template name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Template{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
 	webservice.Route(webservice.DELETE("/templates/{name}").
 		To(h.DeleteTemplate).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -709,6 +1464,535 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.GET("/clustertemplates").
+		To(h.ListClusterTemplates).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("List cluster templates.").
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/clustertemplates/{name}").
+		To(h.DescribeClusterTemplate).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Describe cluster template.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster template name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterTemplate{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/clustertemplates").
+		To(h.CreateClusterTemplate).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Create cluster template.").
+		Reads(corev1.ClusterTemplate{}).
+		Returns(http.StatusCreated, http.StatusText(http.StatusCreated), corev1.ClusterTemplate{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/clustertemplates/{name}").
+		To(h.UpdateClusterTemplate).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Update cluster template.").
+		Reads(corev1.ClusterTemplate{}).
+		Param(webservice.PathParameter(query.ParameterName, "cluster template name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterTemplate{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PATCH("/clustertemplates/{name}").
+		To(restplus.PatchHandler(h.getClusterTemplate, h.UpdateClusterTemplate)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Patch cluster template with a merge patch or a json patch, the patched cluster template is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster template name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ClusterTemplate{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/clustertemplates/{name}").
+		To(h.DeleteClusterTemplate).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Delete cluster template.").
+		Param(webservice.PathParameter(query.ParameterName, "cluster template name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/cronmaintenances").
+		To(h.ListCronMaintenances).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("List etcd cron maintenances.").
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/cronmaintenances/{name}").
+		To(h.DescribeCronMaintenance).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Describe etcd cron maintenance.").
+		Param(webservice.PathParameter(query.ParameterName, "cron maintenance name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.CronMaintenance{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/cronmaintenances").
+		To(h.CreateCronMaintenance).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Create etcd cron maintenance, the etcd of kubeclipper is maintained when spec.clusterName is empty.").
+		Reads(corev1.CronMaintenance{}).
+		Returns(http.StatusCreated, http.StatusText(http.StatusCreated), corev1.CronMaintenance{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/cronmaintenances/{name}").
+		To(h.UpdateCronMaintenance).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Update etcd cron maintenance.").
+		Reads(corev1.CronMaintenance{}).
+		Param(webservice.PathParameter(query.ParameterName, "cron maintenance name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.CronMaintenance{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PATCH("/cronmaintenances/{name}").
+		To(restplus.PatchHandler(h.getCronMaintenance, h.UpdateCronMaintenance)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Patch cron maintenance with a merge patch or a json patch, the patched cron maintenance is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "cron maintenance name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.CronMaintenance{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/cronmaintenances/{name}").
+		To(h.DeleteCronMaintenance).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Delete etcd cron maintenance.").
+		Param(webservice.PathParameter(query.ParameterName, "cron maintenance name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/prechecks").
+		To(h.ListPrechecks).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("List the history of precheck runs.").
+		Param(webservice.QueryParameter("node", "only list the runs which checked the node IP").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label, e.g. kubeclipper.io/precheck-source=join").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/prechecks/{name}").
+		To(h.DescribePrecheck).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Describe precheck run.").
+		Param(webservice.PathParameter(query.ParameterName, "precheck name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,results").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Precheck{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/prechecks").
+		To(h.CreatePrecheck).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Record precheck run, used by kcctl to upload the prechecks of deploy and join.").
+		Reads(corev1.Precheck{}).
+		Returns(http.StatusCreated, http.StatusText(http.StatusCreated), corev1.Precheck{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/prechecks/{name}").
+		To(h.DeletePrecheck).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Delete precheck run.").
+		Param(webservice.PathParameter(query.ParameterName, "precheck name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/notifiers").
+		To(h.ListNotifiers).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("List notifiers.").
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/notifiers/{name}").
+		To(h.DescribeNotifier).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("Describe notifier.").
+		Param(webservice.PathParameter(query.ParameterName, "notifier name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Notifier{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/notifiers").
+		To(h.CreateNotifier).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("Create notifier, a webhook, slack, dingtalk, wecom or email channel notified of the subscribed events.").
+		Reads(corev1.Notifier{}).
+		Returns(http.StatusCreated, http.StatusText(http.StatusCreated), corev1.Notifier{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/notifiers/{name}").
+		To(h.UpdateNotifier).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("Update notifier.").
+		Param(webservice.PathParameter(query.ParameterName, "notifier name").
+			Required(true).
+			DataType("string")).
+		Reads(corev1.Notifier{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Notifier{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PATCH("/notifiers/{name}").
+		To(restplus.PatchHandler(h.getNotifier, h.UpdateNotifier)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("Patch notifier with a merge patch or a json patch, the patched notifier is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "notifier name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Notifier{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/notifiers/{name}").
+		To(h.DeleteNotifier).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("Delete notifier, its pending notifications are marked failed.").
+		Param(webservice.PathParameter(query.ParameterName, "notifier name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/notifiers/{name}/test").
+		To(h.TestNotifier).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("Send a test notification to the notifier.").
+		Param(webservice.PathParameter(query.ParameterName, "notifier name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/notifications").
+		To(h.ListNotifications).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("List the delivery history of notifications.").
+		Param(webservice.QueryParameter("notifier", "only list the notifications of the notifier").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/notifications/{name}").
+		To(h.DescribeNotification).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreNotificationTag}).
+		Doc("Describe notification.").
+		Param(webservice.PathParameter(query.ParameterName, "notification name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Notification{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/events").
+		To(h.ListObjectEvents).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreEventTag}).
+		Doc("List the events recorded about clusters, nodes, operations and backups.").
+		Param(webservice.QueryParameter(parameterKind, "only list the events of the objects of the kind, e.g. Cluster").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(parameterObject, "only list the events of the objects with the name").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(parameterCluster, "only list the events of the cluster and of the objects belonging to it").
+			Required(false).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. lastTimestamp").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/events/{name}").
+		To(h.DescribeObjectEvent).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreEventTag}).
+		Doc("Describe event.").
+		Param(webservice.PathParameter(query.ParameterName, "event name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.ObjectEvent{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/webhookconfigurations").
+		To(h.ListWebhookConfigurations).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreAdmissionTag}).
+		Doc("List admission webhook configurations.").
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/webhookconfigurations/{name}").
+		To(h.DescribeWebhookConfiguration).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreAdmissionTag}).
+		Doc("Describe admission webhook configuration.").
+		Param(webservice.PathParameter(query.ParameterName, "webhook configuration name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.WebhookConfiguration{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/webhookconfigurations").
+		To(h.CreateWebhookConfiguration).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreAdmissionTag}).
+		Doc("Create admission webhook configuration, its webhooks are called before the matching clusters, nodes and backups are created, updated or deleted.").
+		Reads(corev1.WebhookConfiguration{}).
+		Returns(http.StatusCreated, http.StatusText(http.StatusCreated), corev1.WebhookConfiguration{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/webhookconfigurations/{name}").
+		To(h.UpdateWebhookConfiguration).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreAdmissionTag}).
+		Doc("Update admission webhook configuration.").
+		Param(webservice.PathParameter(query.ParameterName, "webhook configuration name").
+			Required(true).
+			DataType("string")).
+		Reads(corev1.WebhookConfiguration{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.WebhookConfiguration{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PATCH("/webhookconfigurations/{name}").
+		To(restplus.PatchHandler(h.getWebhookConfiguration, h.UpdateWebhookConfiguration)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreAdmissionTag}).
+		Doc("Patch webhook configuration with a merge patch or a json patch, the patched webhook configuration is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "webhook configuration name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.WebhookConfiguration{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/webhookconfigurations/{name}").
+		To(h.DeleteWebhookConfiguration).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreAdmissionTag}).
+		Doc("Delete admission webhook configuration.").
+		Param(webservice.PathParameter(query.ParameterName, "webhook configuration name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/clustertemplates/{name}/clusters").
+		To(h.CreateClusterFromTemplate).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Create cluster from cluster template.").
+		Reads(corev1.ClusterTemplateOverrides{}).
+		Param(webservice.PathParameter(query.ParameterName, "cluster template name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParamDryRun, dryRunPlanDoc).
+			Required(false).DataType("boolean")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.Cluster{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.GET("/backuppoints").
 		Doc("List of backup point").
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -725,9 +2009,18 @@ func SetupWebService(h *handler) *restful.WebService {
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterFuzzySearch, "fuzzy search conditions").
 			DataFormat("foo~bar,bar~baz").
 			Required(false)).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
@@ -741,9 +2034,23 @@ func SetupWebService(h *handler) *restful.WebService {
 			Required(false).
 			DataType("string")).
 		To(h.DescribeBackupPoint).
+		Param(webservice.QueryParameter(query.ParameterFields, "only return the given fields, e.g. metadata.name,status").
+			Required(false).
+			DataType("string")).
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.BackupPoint{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.GET("/backuppoints/{name}/usage").
+		Doc("Get the storage used by backups of a backup point, grouped by cluster").
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Param(webservice.PathParameter(query.ParameterName, "backup point name").
+			Required(true).
+			DataType("string")).
+		To(h.DescribeBackupPointUsage).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.BackupPointStatus{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.POST("/backuppoints").
 		Doc("Create a backup point").
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -763,6 +2070,19 @@ func SetupWebService(h *handler) *restful.WebService {
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.BackupPoint{}).
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), nil))
 
+	webservice.Route(webservice.PATCH("/backuppoints/{name}").
+		To(restplus.PatchHandler(h.getBackupPoint, h.UpdateBackupPoint)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
+		Doc("Patch backup point with a merge patch or a json patch, the patched backup point is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "backup point name").
+			Required(true).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), corev1.BackupPoint{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
 	webservice.Route(webservice.DELETE("/backuppoints/{name}").
 		To(h.DeleteBackupPoint).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreClusterTag}).
@@ -776,9 +2096,12 @@ func SetupWebService(h *handler) *restful.WebService {
 	return webservice
 }
 
-func AddToContainer(c *restful.Container, clusterOperator cluster.Operator, op operation.Operator, platform platform.Operator,
-	leaseOperator lease.Operator, delivery service.IDelivery) error {
-	h := newHandler(clusterOperator, op, leaseOperator, platform, delivery)
+func AddToContainer(c *restful.Container, clusterOperator cluster.Operator, op operation.Operator, opInformer cache.SharedIndexInformer, platform platform.Operator,
+	leaseOperator lease.Operator, delivery service.IDelivery, nodeMetrics *nodemetrics.Store, discoverer *inventory.Discoverer,
+	projects iam.ProjectReader, webhooks platform.WebhookConfigurationOperator, events platform.ObjectEventReader, admitter *admission.Admitter,
+	staticServerPath string) error {
+	h := newHandler(clusterOperator, op, opInformer, leaseOperator, platform, delivery, nodeMetrics, discoverer, projects, webhooks, events,
+		admitter, staticServerPath)
 	webservice := SetupWebService(h)
 	c.Add(webservice)
 	return nil
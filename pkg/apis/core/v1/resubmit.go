@@ -0,0 +1,423 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/emicklei/go-restful"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// initControlPlaneStep is the name of the step running kubeadm init on the first master.
+const initControlPlaneStep = "initControlPlane"
+
+// ResubmitCluster installs a cluster whose creation failed again with a changed node set.
+//
+// When only the workers changed and the control plane has been initialized, the failed
+// creation is continued: the removed workers are cleaned and the added ones are joined.
+// Otherwise all nodes of the failed creation are cleaned and the cluster is installed from scratch.
+func (h *handler) ResubmitCluster(request *restful.Request, response *restful.Response) {
+	desired := v1.Cluster{}
+	if err := request.ReadEntity(&desired); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	dryRun := query.GetBoolValueWithDefault(request, query.ParamDryRun, false)
+	name := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	if desired.Name != name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("the name of the object (%s) does not match the name on the URL (%s)", desired.Name, name))
+		return
+	}
+	if desired.Kubeadm == nil {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("kubeadm of cluster %s is empty", name))
+		return
+	}
+
+	c, err := h.clusterOperator.GetClusterEx(ctx, name, "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if c.Status.Status != v1.ClusterStatusInstallFailed {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("cluster %s is %s, only clusters failed to install can be resubmitted", name, c.Status.Status))
+		return
+	}
+	failedOp, err := h.latestOperation(ctx, name)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if failedOp == nil || failedOp.Labels[common.LabelOperationAction] != v1.OperationCreateCluster ||
+		failedOp.Status.Status == v1.OperationStatusRunning || failedOp.Status.Status == v1.OperationStatusSuccessful {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("the latest operation of cluster %s is not a failed creation", name))
+		return
+	}
+
+	oldMeta, err := h.getClusterMetadata(ctx, c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	newMeta, err := h.getClusterMetadata(ctx, &desired)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) || err == ErrNodesRegionDifferent {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	if err = h.resubmitCheck(c, &desired, newMeta); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	defaults, err := h.clusterDefaults(ctx, newMeta.Masters[0].Region)
+	if err != nil && !apimachineryErrors.IsNotFound(err) {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	defaults.ApplyTo(&desired)
+	desired.Complete()
+
+	removed, added := diffClusterNodes(c, &desired)
+	var (
+		op    *v1.Operation
+		opCtx = context.TODO()
+	)
+	if controlPlaneReusable(c, &desired, failedOp) {
+		op, opCtx, err = h.continueCreation(c, &desired, failedOp, oldMeta, newMeta, removed, added)
+	} else {
+		op, err = h.reinstallCluster(c, &desired, oldMeta, newMeta)
+	}
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	op.Labels[common.LabelTimeoutSeconds] = failedOp.Labels[common.LabelTimeoutSeconds]
+	op.Labels[common.LabelOperationAction] = v1.OperationCreateCluster
+	if op.StepPolicies, err = clusterStepPolicies(&desired); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	op.Status.Status = v1.OperationStatusRunning
+	if err := h.admitOperation(request.Request.Context(), op); err != nil {
+		handleQuotaError(response, request, err)
+		return
+	}
+	if dryRun {
+		h.writeOperationPlan(request, response, op)
+		return
+	}
+
+	c.Labels, c.Annotations = desired.Labels, desired.Annotations
+	c.Kubeadm = desired.Kubeadm
+	c.Status.Status = v1.ClusterStatusInstalling
+	updated, err := h.clusterOperator.UpdateCluster(ctx, c)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	h.releaseNodes(ctx, name, removed.List())
+	v1.SetOwnerReference(op, v1.NewClusterOwnerReference(updated))
+	if op, err = h.opOperator.CreateOperation(ctx, op); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	go h.doOperation(opCtx, op, &service.Options{})
+	_ = response.WriteHeaderAndEntity(http.StatusOK, updated)
+}
+
+func (h *handler) latestOperation(ctx context.Context, clusterName string) (*v1.Operation, error) {
+	q := query.New()
+	q.LabelSelector = fmt.Sprintf("%s=%s", common.LabelClusterName, clusterName)
+	q.Pagination.Offset = 0
+	q.Pagination.Limit = 1
+	opList, err := h.opOperator.ListOperationsEx(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if len(opList.Items) == 0 {
+		return nil, nil
+	}
+	return opList.Items[0].(*v1.Operation), nil
+}
+
+// resubmitCheck validates the desired cluster like a creation does, except that the nodes
+// of the failed creation may be used again.
+func (h *handler) resubmitCheck(c, desired *v1.Cluster, newMeta *component.ExtraMetadata) error {
+	if len(desired.Kubeadm.Masters) == 0 {
+		return fmt.Errorf("cluster must have one master node")
+	}
+	if !desired.NodeReconcileMode.Valid() {
+		return fmt.Errorf("unsupported node reconcile mode %s", desired.NodeReconcileMode)
+	}
+	if err := desired.Kubeadm.KubeComponents.CNI.NodeInterface.Validate(); err != nil {
+		return err
+	}
+	if err := desired.Kubeadm.KubeComponents.Kubelet.Swap.Validate(desired.Kubeadm.KubernetesVersion); err != nil {
+		return err
+	}
+	if err := desired.Kubeadm.ContainerRuntime.Containerd.ValidateRegistries(); err != nil {
+		return err
+	}
+	if err := v1.ValidateCertSANs(desired.Kubeadm.CertSANs); err != nil {
+		return err
+	}
+	if err := v1.ValidateExternalEndpoint(desired.Kubeadm.ExternalEndpoint); err != nil {
+		return err
+	}
+	if err := desired.Kubeadm.ControlPlaneVIP.Validate(); err != nil {
+		return err
+	}
+	if err := desired.Kubeadm.ValidateNodePools(); err != nil {
+		return err
+	}
+	if err := (&k8s.KubeadmConfig{}).InitStepper(desired.Kubeadm, &component.ExtraMetadata{ClusterName: desired.Name}).ValidatePatches(); err != nil {
+		return err
+	}
+	if _, err := desired.OperationHooks(); err != nil {
+		return err
+	}
+	nodes := c.GetAllNodes()
+	for _, n := range newMeta.GetAllNodes() {
+		if nodes.Has(n.ID) {
+			continue
+		}
+		if n.Disable {
+			return fmt.Errorf("this node(%s) is disabled", n.IPv4)
+		}
+		if n.Role != "" {
+			return fmt.Errorf("this node(%s) is already in use", n.IPv4)
+		}
+	}
+	if err := windowsNodeCheck(newMeta.GetAllNodes()); err != nil {
+		return err
+	}
+	return h.resourceArchCheck(desired, newMeta.GetAllNodes())
+}
+
+// diffClusterNodes returns the IDs of the nodes removed from and added to the cluster.
+func diffClusterNodes(c, desired *v1.Cluster) (removed, added sets.String) {
+	old := c.GetAllNodes()
+	current := desired.GetAllNodes()
+	return old.Difference(current), current.Difference(old)
+}
+
+// controlPlaneReusable reports whether the failed creation can be continued, which is
+// the case when kubeadm init succeeded and nothing but the workers changed.
+func controlPlaneReusable(c, desired *v1.Cluster, failedOp *v1.Operation) bool {
+	oldSpec, newSpec := c.Kubeadm.DeepCopy(), desired.Kubeadm.DeepCopy()
+	oldSpec.Workers, newSpec.Workers = nil, nil
+	if !reflect.DeepEqual(oldSpec, newSpec) {
+		return false
+	}
+	for i, step := range failedOp.Steps {
+		if step.Name != initControlPlaneStep {
+			continue
+		}
+		if i >= len(failedOp.Status.Conditions) || len(failedOp.Status.Conditions[i].Status) == 0 {
+			return false
+		}
+		for _, st := range failedOp.Status.Conditions[i].Status {
+			if st.Status != v1.StepStatusSuccessful {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// continueCreation runs the steps left by the failed creation without the removed workers,
+// then cleans the removed workers and joins the added ones.
+func (h *handler) continueCreation(c, desired *v1.Cluster, failedOp *v1.Operation, oldMeta, newMeta *component.ExtraMetadata,
+	removed, added sets.String) (*v1.Operation, context.Context, error) {
+	ctx := component.WithRetry(context.TODO(), true)
+	steps, lastResponse := remainingSteps(failedOp)
+	if lastResponse != nil {
+		// the first remaining step may need the output of the step before, e.g. the join command
+		ctx = component.WithExtraData(ctx, lastResponse)
+	}
+	steps = withoutNodes(steps, removed)
+
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelClusterName:    c.Name,
+		common.LabelTopologyRegion: newMeta.Masters[0].Region,
+	}
+
+	if removed.Len() > 0 {
+		clean := &PatchNodes{Operation: NodesOperationRemove, Role: common.NodeRoleWorker, Nodes: nodesOf(c.Kubeadm.Workers, removed)}
+		cleanOp, err := clean.MakeOperation(workersOnly(oldMeta, removed), c)
+		if err != nil {
+			return nil, nil, err
+		}
+		steps = append(steps, ignoreStepErrors(cleanOp.Steps)...)
+	}
+	if added.Len() > 0 {
+		join := &PatchNodes{Operation: NodesOperationAdd, Role: common.NodeRoleWorker, Nodes: nodesOf(desired.Kubeadm.Workers, added)}
+		joinOp, err := join.MakeOperation(workersOnly(newMeta, added), desired)
+		if err != nil {
+			return nil, nil, err
+		}
+		steps = append(steps, joinOp.Steps...)
+	}
+	op.Steps = steps
+	return op, ctx, nil
+}
+
+// reinstallCluster cleans every node of the failed creation and installs the desired cluster.
+func (h *handler) reinstallCluster(c, desired *v1.Cluster, oldMeta, newMeta *component.ExtraMetadata) (*v1.Operation, error) {
+	cleanOp, err := h.parseOperationFromCluster(oldMeta, c, v1.ActionUninstall)
+	if err != nil {
+		return nil, err
+	}
+	op, err := h.parseOperationFromCluster(newMeta, desired, v1.ActionInstall)
+	if err != nil {
+		return nil, err
+	}
+	op.Steps = append(ignoreStepErrors(cleanOp.Steps), op.Steps...)
+	return op, nil
+}
+
+// remainingSteps returns the steps of a failed operation from the failed one on, which only
+// runs on the nodes it failed on, and the output of the step before the failed one.
+func remainingSteps(op *v1.Operation) ([]v1.Step, []byte) {
+	status := op.Status.DeepCopy()
+	status.TrimSkippedConditions()
+	failedIndex := len(status.Conditions) - 1
+	if failedIndex < 0 {
+		return append([]v1.Step(nil), op.Steps...), nil
+	}
+	if failedIndex >= len(op.Steps) {
+		return nil, nil
+	}
+	failed := sets.NewString()
+	for _, st := range status.Conditions[failedIndex].Status {
+		if st.Status == v1.StepStatusFailed {
+			failed.Insert(st.Node)
+		}
+	}
+	steps := append([]v1.Step(nil), op.Steps[failedIndex:]...)
+	var nodes []v1.StepNode
+	for _, n := range steps[0].Nodes {
+		if failed.Has(n.ID) {
+			nodes = append(nodes, n)
+		}
+	}
+	steps[0].Nodes = nodes
+
+	var lastResponse []byte
+	if failedIndex > 0 && len(status.Conditions[failedIndex-1].Status) > 0 {
+		lastResponse = status.Conditions[failedIndex-1].Status[0].Response
+	}
+	return steps, lastResponse
+}
+
+// withoutNodes drops the given nodes from the steps, and the steps left without nodes.
+func withoutNodes(steps []v1.Step, ids sets.String) []v1.Step {
+	result := make([]v1.Step, 0, len(steps))
+	for _, step := range steps {
+		var nodes []v1.StepNode
+		for _, n := range step.Nodes {
+			if !ids.Has(n.ID) {
+				nodes = append(nodes, n)
+			}
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+		step.Nodes = nodes
+		result = append(result, step)
+	}
+	return result
+}
+
+// ignoreStepErrors marks cleanup steps as best effort, the nodes may be partly installed.
+func ignoreStepErrors(steps []v1.Step) []v1.Step {
+	for i := range steps {
+		steps[i].ErrIgnore = true
+	}
+	return steps
+}
+
+// workersOnly returns the metadata with only the given workers, the worker steps run on all workers of the metadata.
+func workersOnly(meta *component.ExtraMetadata, ids sets.String) component.ExtraMetadata {
+	result := *meta
+	result.Workers = nil
+	for _, n := range meta.Workers {
+		if ids.Has(n.ID) {
+			result.Workers = append(result.Workers, n)
+		}
+	}
+	return result
+}
+
+func nodesOf(nodes v1.WorkerNodeList, ids sets.String) v1.WorkerNodeList {
+	var result v1.WorkerNodeList
+	for _, n := range nodes {
+		if ids.Has(n.ID) {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// releaseNodes removes the cluster labels of the nodes taken out of the cluster, so that they
+// can be used again. Failures are only logged, the nodes can be released by deleting the cluster.
+func (h *handler) releaseNodes(ctx context.Context, clusterName string, ids []string) {
+	for _, id := range ids {
+		node, err := h.clusterOperator.GetNodeEx(ctx, id, "0")
+		if err != nil {
+			logger.Warn("get node failed when release it", zap.String("cluster", clusterName), zap.String("node", id), zap.Error(err))
+			continue
+		}
+		if node.Labels[common.LabelClusterName] != clusterName {
+			continue
+		}
+		delete(node.Labels, common.LabelNodeRole)
+		delete(node.Labels, common.LabelClusterName)
+		if _, err = h.clusterOperator.UpdateNode(ctx, node); err != nil {
+			logger.Warn("release node failed", zap.String("cluster", clusterName), zap.String("node", id), zap.Error(err))
+		}
+	}
+}
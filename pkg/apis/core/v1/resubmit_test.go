@@ -0,0 +1,110 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func stepNodes(ids ...string) []v1.StepNode {
+	var nodes []v1.StepNode
+	for _, id := range ids {
+		nodes = append(nodes, v1.StepNode{ID: id})
+	}
+	return nodes
+}
+
+func stepNodeIDs(step v1.Step) []string {
+	var ids []string
+	for _, n := range step.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func failedCreation() *v1.Operation {
+	return &v1.Operation{
+		Steps: []v1.Step{
+			{Name: "installPackages", Nodes: stepNodes("m1", "w1", "w2")},
+			{Name: initControlPlaneStep, Nodes: stepNodes("m1")},
+			{Name: "joinNode", Nodes: stepNodes("w1", "w2")},
+			{Name: "installCNI", Nodes: stepNodes("m1")},
+		},
+		Status: v1.OperationStatus{Conditions: []v1.OperationCondition{
+			{Status: []v1.StepStatus{{Node: "m1", Status: v1.StepStatusSuccessful}, {Node: "w1", Status: v1.StepStatusSuccessful}, {Node: "w2", Status: v1.StepStatusSuccessful}}},
+			{Status: []v1.StepStatus{{Node: "m1", Status: v1.StepStatusSuccessful, Response: []byte("join")}}},
+			{Status: []v1.StepStatus{{Node: "w1", Status: v1.StepStatusSuccessful}, {Node: "w2", Status: v1.StepStatusFailed}}},
+			{Status: []v1.StepStatus{{Node: "m1", Status: v1.StepStatusSkipped}}},
+		}},
+	}
+}
+
+func TestRemainingSteps(t *testing.T) {
+	steps, lastResponse := remainingSteps(failedCreation())
+	if len(steps) != 2 {
+		t.Fatalf("got %d remaining steps, want 2", len(steps))
+	}
+	if got := stepNodeIDs(steps[0]); !reflect.DeepEqual(got, []string{"w2"}) {
+		t.Errorf("failed step nodes = %v, want [w2]", got)
+	}
+	if string(lastResponse) != "join" {
+		t.Errorf("last response = %q, want join", lastResponse)
+	}
+
+	steps = withoutNodes(steps, sets.NewString("w2"))
+	if len(steps) != 1 || steps[0].Name != "installCNI" {
+		t.Errorf("steps without w2 = %v, want installCNI only", steps)
+	}
+}
+
+func TestControlPlaneReusable(t *testing.T) {
+	c := &v1.Cluster{Kubeadm: &v1.Kubeadm{
+		Masters:           v1.WorkerNodeList{{ID: "m1"}},
+		Workers:           v1.WorkerNodeList{{ID: "w1"}, {ID: "w2"}},
+		KubernetesVersion: "v1.23.6",
+	}}
+	desired := c.DeepCopy()
+	desired.Kubeadm.Workers = v1.WorkerNodeList{{ID: "w1"}, {ID: "w3"}}
+	op := failedCreation()
+
+	if !controlPlaneReusable(c, desired, op) {
+		t.Error("changed workers should reuse the control plane")
+	}
+	removed, added := diffClusterNodes(c, desired)
+	if !removed.Equal(sets.NewString("w2")) || !added.Equal(sets.NewString("w3")) {
+		t.Errorf("removed %v added %v, want [w2] [w3]", removed.List(), added.List())
+	}
+
+	changedMaster := desired.DeepCopy()
+	changedMaster.Kubeadm.Masters = v1.WorkerNodeList{{ID: "m2"}}
+	if controlPlaneReusable(c, changedMaster, op) {
+		t.Error("changed masters should not reuse the control plane")
+	}
+
+	notInitialized := failedCreation()
+	notInitialized.Status.Conditions[1].Status[0].Status = v1.StepStatusFailed
+	if controlPlaneReusable(c, desired, notInitialized) {
+		t.Error("failed kubeadm init should not reuse the control plane")
+	}
+}
@@ -47,6 +47,8 @@ type PatchNodes struct {
 	Operation NodesPatchOperation   `json:"operation"`
 	Nodes     corev1.WorkerNodeList `json:"nodes"`
 	Role      common.NodeRole       `json:"role"`
+	// Pool the nodes are added to, or removed from. Removing a pool without nodes removes all of its nodes.
+	Pool string `json:"pool,omitempty"`
 }
 
 type PatchComponents struct {
@@ -54,12 +56,25 @@ type PatchComponents struct {
 	Components []corev1.Component `json:"components"`
 }
 
+// UpgradeComponents are the target versions or configs of components installed in the cluster.
+type UpgradeComponents struct {
+	Components []corev1.Component `json:"components"`
+}
+
+// componentUpgrade is an installed component and the target it is upgraded to.
+type componentUpgrade struct {
+	Current corev1.Component
+	Target  corev1.Component
+}
+
 var (
 	ErrInvalidNodesOperation      = errors.New("invalid nodes patch operation")
 	ErrInvalidNodesRole           = errors.New("invalid node role")
 	ErrZeroNode                   = errors.New("zero node")
 	ErrUninstallNotExistComponent = errors.New("the component is not installed in the current cluster")
 	ErrInstallExistingComponent   = errors.New("the component has been installed in the current cluster")
+	ErrComponentsUpToDate         = errors.New("the components are already up to date")
+	ErrNodePoolNotFound           = errors.New("node pool not found")
 )
 
 // MakeCompare compares and filters node to be operated with master/worker nodes in cluster already,
@@ -82,19 +97,44 @@ func (p *PatchNodes) makeMasterCompare(cluster *corev1.Cluster) error {
 
 func (p *PatchNodes) makeWorkerCompare(cluster *corev1.Cluster) error {
 	// Compare between nodes unfiltered and existed worker nodes in cluster.
+	if p.Pool != "" && cluster.Kubeadm.NodePool(p.Pool) == nil {
+		return fmt.Errorf("%w: %s", ErrNodePoolNotFound, p.Pool)
+	}
 	switch p.Operation {
 	case NodesOperationAdd:
 		// Add nodes to cluster.
 		// Check nodes in cluster already.
 		// Filter out nodes to be added.
 		p.Nodes = p.Nodes.Complement(cluster.Kubeadm.Workers...)
+		for i := range p.Nodes {
+			if p.Pool != "" {
+				p.Nodes[i].Pool = p.Pool
+			}
+			if p.Nodes[i].Pool != "" && cluster.Kubeadm.NodePool(p.Nodes[i].Pool) == nil {
+				return fmt.Errorf("%w: %s", ErrNodePoolNotFound, p.Nodes[i].Pool)
+			}
+		}
 		cluster.Kubeadm.Workers = append(cluster.Kubeadm.Workers, p.Nodes...)
 	case NodesOperationRemove:
 		// Remove nodes from cluster.
 		// Filter out nodes in cluster already.
 		// Filter out nodes to be removed.
 		// TODO: if len(p.nodes)==0, should return error
+		if p.Pool != "" {
+			// only the nodes of the pool, all of them when none is given
+			workers := cluster.Kubeadm.PoolWorkers(p.Pool)
+			if len(p.Nodes) > 0 {
+				workers = p.Nodes.Intersect(workers...)
+			}
+			p.Nodes = workers
+		}
 		p.Nodes = cluster.Kubeadm.Workers.Intersect(p.Nodes...)
+		// keep the pools of the nodes, the container runtime of their pool is removed with them
+		for i := range p.Nodes {
+			if pool := cluster.Kubeadm.PoolOf(p.Nodes[i].ID); pool != nil {
+				p.Nodes[i].Pool = pool.Name
+			}
+		}
 		cluster.Kubeadm.Workers = cluster.Kubeadm.Workers.Complement(p.Nodes...)
 	default:
 		return ErrInvalidNodesOperation
@@ -142,9 +182,17 @@ func (p *PatchNodes) makeWorkerOperation(extra component.ExtraMetadata, cluster
 			ID:       nodeID,
 			IPv4:     workerIPs[nodeID],
 			Hostname: extra.GetWorkerHostname(nodeID),
+			Arch:     extra.GetWorkerArch(nodeID),
+			OSFamily: extra.GetWorkerOSFamily(nodeID),
 		}
 		stepNodes = append(stepNodes, stepNode)
 	}
+	// the removed nodes are no longer workers of the cluster, their pools still select the steps
+	kubeadm := cluster.Kubeadm
+	if p.Operation == NodesOperationRemove {
+		kubeadm = cluster.Kubeadm.DeepCopy()
+		kubeadm.Workers = append(kubeadm.Workers, p.Nodes...)
+	}
 
 	var action corev1.StepAction
 	switch p.Operation {
@@ -158,15 +206,18 @@ func (p *PatchNodes) makeWorkerOperation(extra component.ExtraMetadata, cluster
 		action = corev1.ActionInstall
 		op.Labels[common.LabelOperationAction] = corev1.OperationAddNodes
 
+		// proxy, before the container runtime starts
+		op.Steps = append(op.Steps, k8s.ProxySteps(kubeadm, &extra, action, stepNodes)...)
+
 		// container runtime
-		steps, err := getCriStep(ctx, &cluster.Kubeadm.ContainerRuntime, action, stepNodes)
+		steps, err := getNodesCriSteps(ctx, kubeadm, action, stepNodes)
 		if err != nil {
 			return nil, err
 		}
 		op.Steps = append(op.Steps, steps...)
 
 		// kubernetes
-		steps, err = p.getPackageSteps(cluster, action, stepNodes)
+		steps, err = p.getPackageSteps(kubeadm, action, stepNodes)
 		if err != nil {
 			return nil, err
 		}
@@ -194,18 +245,19 @@ func (p *PatchNodes) makeWorkerOperation(extra component.ExtraMetadata, cluster
 		op.Steps = append(op.Steps, steps...)
 
 		// kubernetes
-		steps, err = p.getPackageSteps(cluster, action, stepNodes)
+		steps, err = p.getPackageSteps(kubeadm, action, stepNodes)
 		if err != nil {
 			return nil, err
 		}
 		op.Steps = append(op.Steps, steps...)
 
 		// container runtime
-		steps, err = getCriStep(ctx, &cluster.Kubeadm.ContainerRuntime, action, stepNodes)
+		steps, err = getNodesCriSteps(ctx, kubeadm, action, stepNodes)
 		if err != nil {
 			return nil, err
 		}
 		op.Steps = append(op.Steps, steps...)
+		op.Steps = append(op.Steps, k8s.ProxySteps(kubeadm, &extra, action, stepNodes)...)
 	default:
 		return nil, ErrInvalidNodesOperation
 	}
@@ -213,9 +265,9 @@ func (p *PatchNodes) makeWorkerOperation(extra component.ExtraMetadata, cluster
 	return op, nil
 }
 
-func (p *PatchNodes) getPackageSteps(cluster *corev1.Cluster, action corev1.StepAction, pNodes []corev1.StepNode) ([]corev1.Step, error) {
+func (p *PatchNodes) getPackageSteps(kubeadm *corev1.Kubeadm, action corev1.StepAction, pNodes []corev1.StepNode) ([]corev1.Step, error) {
 	pack := &k8s.Package{}
-	pack = pack.InitStepper(cluster.Kubeadm)
+	pack = pack.InitStepper(kubeadm)
 
 	switch action {
 	case corev1.ActionInstall:
@@ -323,6 +375,113 @@ func (p *PatchComponents) addOrRemoveComponentFromCluster(cluster *corev1.Cluste
 	return cluster, nil
 }
 
+// componentRevisions returns the history records of the components installed or uninstalled by the operation.
+func (p *PatchComponents) componentRevisions(operation string) []corev1.ComponentRevision {
+	action := corev1.OperationInstallComponents
+	if p.Uninstall {
+		action = corev1.OperationUninstallComponents
+	}
+	now := metav1.Now()
+	revisions := make([]corev1.ComponentRevision, 0, len(p.Components))
+	for _, c := range p.Components {
+		r := corev1.ComponentRevision{Name: c.Name, Action: action, Operation: operation, Time: now}
+		if p.Uninstall {
+			r.FromVersion = c.Version
+		} else {
+			r.ToVersion = c.Version
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions
+}
+
+// loadComponent returns the registered component with the config of c.
+func loadComponent(c corev1.Component) (component.Interface, error) {
+	itf, ok := component.Load(fmt.Sprintf(component.RegisterFormat, c.Name, c.Version))
+	if !ok {
+		return nil, fmt.Errorf("kubeclipper does not support %s-%s component", c.Name, c.Version)
+	}
+	instance := itf.NewInstance()
+	if err := json.Unmarshal(c.Config.Raw, instance); err != nil {
+		return nil, fmt.Errorf("%s-%s component configuration resolution error: %s", c.Name, c.Version, err.Error())
+	}
+	comp, ok := instance.(component.Interface)
+	if !ok {
+		return nil, fmt.Errorf("%s-%s is not a component", c.Name, c.Version)
+	}
+	return comp, nil
+}
+
+// makeUpgrades computes the delta between the installed components and the targets. A target is matched
+// with the installed component of the same name, or the same instance name if the component is not unique.
+// Targets equal to the installed components are left out.
+func (u *UpgradeComponents) makeUpgrades(cluster *corev1.Cluster) ([]componentUpgrade, error) {
+	var upgrades []componentUpgrade
+	for _, target := range u.Components {
+		targetComp, err := loadComponent(target)
+		if err != nil {
+			return nil, err
+		}
+		current, err := matchInstalledComponent(cluster.Kubeadm.Components, target.Name, targetComp.GetInstanceName())
+		if err != nil {
+			return nil, err
+		}
+		if current.Equal(target) {
+			continue
+		}
+		upgrades = append(upgrades, componentUpgrade{Current: *current, Target: target})
+	}
+	if len(upgrades) == 0 {
+		return nil, ErrComponentsUpToDate
+	}
+	return upgrades, nil
+}
+
+func matchInstalledComponent(installed []corev1.Component, name, instanceName string) (*corev1.Component, error) {
+	var candidates []corev1.Component
+	for _, c := range installed {
+		if c.Name == name {
+			candidates = append(candidates, c)
+		}
+	}
+	if len(candidates) == 1 {
+		return &candidates[0], nil
+	}
+	for i := range candidates {
+		comp, err := loadComponent(candidates[i])
+		if err != nil {
+			return nil, err
+		}
+		if comp.GetInstanceName() == instanceName {
+			return &candidates[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s component %s is not installed in the current cluster", name, instanceName)
+}
+
+// applyComponentUpgrades replaces the upgraded components of the cluster with their targets and
+// records the upgrades in the component history.
+func applyComponentUpgrades(cluster *corev1.Cluster, upgrades []componentUpgrade, operation string) *corev1.Cluster {
+	now := metav1.Now()
+	for _, u := range upgrades {
+		for i, c := range cluster.Kubeadm.Components {
+			if c.Equal(u.Current) {
+				cluster.Kubeadm.Components[i] = u.Target
+				break
+			}
+		}
+		cluster.Status.AddComponentRevision(corev1.ComponentRevision{
+			Name:        u.Target.Name,
+			Action:      corev1.OperationUpgradeComponents,
+			FromVersion: u.Current.Version,
+			ToVersion:   u.Target.Version,
+			Operation:   operation,
+			Time:        now,
+		})
+	}
+	return cluster
+}
+
 type StepLog struct {
 	Content      string          `json:"content,omitempty"`
 	Node         string          `json:"node,omitempty"`
@@ -336,4 +495,24 @@ type ClusterUpgrade struct {
 	Version       string `json:"version"`
 	Offline       bool   `json:"offline"`
 	LocalRegistry string `json:"localRegistry"`
+	// WorkerBatchSize is the number of workers upgraded together, defaults to 1.
+	WorkerBatchSize int `json:"workerBatchSize,omitempty"`
+}
+
+// ClusterCertSANs are the extra subject alternative names of the apiserver certificate
+// and the endpoint exposing the apiserver outside the cluster.
+type ClusterCertSANs struct {
+	CertSANs         []string `json:"certSANs"`
+	ExternalEndpoint string   `json:"externalEndpoint,omitempty"`
+}
+
+// NodePoolScale is the desired worker count of a node pool and, optionally, the provider supplying its nodes.
+type NodePoolScale struct {
+	WorkerCount int                      `json:"workerCount"`
+	Provider    *corev1.NodePoolProvider `json:"provider,omitempty"`
+}
+
+// ClusterRegistries are the registries containerd of the cluster nodes pulls images from.
+type ClusterRegistries struct {
+	Registries []corev1.ContainerdRegistry `json:"registries"`
 }
@@ -19,12 +19,13 @@
 package v1
 
 import (
-	"fmt"
+	"errors"
 	"reflect"
 	"testing"
 
 	"k8s.io/apimachinery/pkg/runtime"
 
+	"github.com/kubeclipper/kubeclipper/pkg/component/csi"
 	nfsprovisioner "github.com/kubeclipper/kubeclipper/pkg/component/nfs"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -114,7 +115,7 @@ var (
 
 func init() {
 	nfs := &nfsprovisioner.NFSProvisioner{}
-	_ = component.Register(fmt.Sprintf(component.RegisterFormat, "nfs-provisioner", "v1"), nfs)
+	_ = csi.Register(nfs)
 
 }
 
@@ -186,6 +187,42 @@ func Test_MakeCompare(t *testing.T) {
 	}
 }
 
+func Test_MakeCompareNodePool(t *testing.T) {
+	newCluster := func() *v1.Cluster {
+		return &v1.Cluster{Kubeadm: &v1.Kubeadm{
+			NodePools: []v1.NodePool{{Name: "gpu"}},
+			Workers:   v1.WorkerNodeList{{ID: "w1", Pool: "gpu"}, {ID: "w2"}, {ID: "w3", Pool: "gpu"}},
+		}}
+	}
+
+	c := newCluster()
+	add := &PatchNodes{Operation: NodesOperationAdd, Role: "worker", Pool: "gpu", Nodes: v1.WorkerNodeList{{ID: "w4"}}}
+	if err := add.MakeCompare(c); err != nil {
+		t.Fatal(err)
+	}
+	if p := c.Kubeadm.PoolOf("w4"); p == nil || p.Name != "gpu" {
+		t.Errorf("added node is not in the gpu pool: %+v", c.Kubeadm.Workers)
+	}
+
+	c = newCluster()
+	remove := &PatchNodes{Operation: NodesOperationRemove, Role: "worker", Pool: "gpu"}
+	if err := remove.MakeCompare(c); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(remove.Nodes.GetNodeIDs(), []string{"w1", "w3"}) || !reflect.DeepEqual(c.Kubeadm.Workers.GetNodeIDs(), []string{"w2"}) {
+		t.Errorf("removing the pool removed %v, kept %v", remove.Nodes.GetNodeIDs(), c.Kubeadm.Workers.GetNodeIDs())
+	}
+
+	for _, p := range []*PatchNodes{
+		{Operation: NodesOperationAdd, Role: "worker", Pool: "edge", Nodes: v1.WorkerNodeList{{ID: "w4"}}},
+		{Operation: NodesOperationAdd, Role: "worker", Nodes: v1.WorkerNodeList{{ID: "w4", Pool: "edge"}}},
+	} {
+		if err := p.MakeCompare(newCluster()); !errors.Is(err, ErrNodePoolNotFound) {
+			t.Errorf("MakeCompare() err = %v, want %v", err, ErrNodePoolNotFound)
+		}
+	}
+}
+
 func Test_MakeOperation(t *testing.T) {
 	type args struct {
 		cluster    *v1.Cluster
@@ -395,3 +432,61 @@ func Test_addOrRemoveComponentFromCluster(t *testing.T) {
 		})
 	}
 }
+
+func Test_makeUpgrades(t *testing.T) {
+	nfs := func(sc, addr string) v1.Component {
+		return v1.Component{
+			Name:    "nfs-provisioner",
+			Version: "v1",
+			Config:  runtime.RawExtension{Raw: []byte(`{"scName":"` + sc + `","serverAddr":"` + addr + `"}`)},
+		}
+	}
+	cluster := &v1.Cluster{
+		Kubeadm: &v1.Kubeadm{
+			Components: []v1.Component{nfs("nfs-a", "10.0.0.1"), nfs("nfs-b", "10.0.0.2")},
+		},
+	}
+	tests := []struct {
+		name    string
+		targets []v1.Component
+		want    []componentUpgrade
+		wantErr bool
+	}{
+		{
+			name:    "match by instance name",
+			targets: []v1.Component{nfs("nfs-b", "10.0.0.3")},
+			want:    []componentUpgrade{{Current: nfs("nfs-b", "10.0.0.2"), Target: nfs("nfs-b", "10.0.0.3")}},
+		},
+		{
+			name:    "up to date",
+			targets: []v1.Component{nfs("nfs-a", "10.0.0.1")},
+			wantErr: true,
+		},
+		{
+			name:    "not installed",
+			targets: []v1.Component{nfs("nfs-c", "10.0.0.1")},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u := &UpgradeComponents{Components: test.targets}
+			got, err := u.makeUpgrades(cluster)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("makeUpgrades() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("makeUpgrades() = %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	upgrades := []componentUpgrade{{Current: nfs("nfs-b", "10.0.0.2"), Target: nfs("nfs-b", "10.0.0.3")}}
+	got := applyComponentUpgrades(cluster.DeepCopy(), upgrades, "op1")
+	if !reflect.DeepEqual(got.Kubeadm.Components, []v1.Component{nfs("nfs-a", "10.0.0.1"), nfs("nfs-b", "10.0.0.3")}) {
+		t.Errorf("applyComponentUpgrades() components = %v", got.Kubeadm.Components)
+	}
+	if h := got.Status.ComponentHistory; len(h) != 1 || h[0].Action != v1.OperationUpgradeComponents || h[0].Operation != "op1" {
+		t.Errorf("applyComponentUpgrades() history = %v", h)
+	}
+}
@@ -22,17 +22,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"path/filepath"
 
+	"github.com/emicklei/go-restful"
 	"github.com/google/uuid"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/models"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/cri"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
 	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
 )
 
@@ -43,10 +47,11 @@ import (
 	}
 }*/
 
-func getCriStep(ctx context.Context, c *v1.ContainerRuntime, action v1.StepAction, nodes []v1.StepNode) ([]v1.Step, error) {
+func getCriStep(ctx context.Context, c *v1.ContainerRuntime, proxy *v1.ProxyEnv, action v1.StepAction, nodes []v1.StepNode) ([]v1.Step, error) {
 	switch c.Type {
 	case v1.CRIDocker:
 		r := cri.DockerRunnable{}
+		r.Proxy = proxy
 		err := r.InitStep(ctx, &c.Docker, nodes)
 		if err != nil {
 			return nil, err
@@ -54,6 +59,7 @@ func getCriStep(ctx context.Context, c *v1.ContainerRuntime, action v1.StepActio
 		return r.GetActionSteps(action), nil
 	case v1.CRIContainerd:
 		r := cri.ContainerdRunnable{}
+		r.Proxy = proxy
 		err := r.InitStep(ctx, &c.Containerd, nodes)
 		if err != nil {
 			return nil, err
@@ -63,7 +69,42 @@ func getCriStep(ctx context.Context, c *v1.ContainerRuntime, action v1.StepActio
 	return nil, fmt.Errorf("no support %v type cri", c.Type)
 }
 
+// getNodesCriSteps installs or removes the container runtime of the node pool of every node.
+func getNodesCriSteps(ctx context.Context, kubeadm *v1.Kubeadm, action v1.StepAction, nodes []v1.StepNode) ([]v1.Step, error) {
+	var steps []v1.Step
+	pools, groups := k8s.GroupNodesByPool(kubeadm, nodes)
+	for _, pool := range pools {
+		s, err := getCriStep(ctx, kubeadm.ContainerRuntimeOf(groups[pool][0].ID), kubeadm.ProxyEnv(nil), action, groups[pool])
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s...)
+	}
+	return steps, nil
+}
+
+func getPrewarmSteps(p *v1.RegionPrewarm, nodes []v1.StepNode) ([]v1.Step, error) {
+	r := cri.PrewarmRunnable{}
+	if err := r.InitStep(&p.ContainerRuntime, p.Offline); err != nil {
+		return nil, err
+	}
+	criSteps, err := r.InstallSteps(nodes)
+	if err != nil {
+		return nil, err
+	}
+	stepper := &k8s.Prewarm{}
+	k8sSteps, err := stepper.InitStepper(p.KubernetesVersion, p.Offline, p.LocalRegistry).InstallSteps(nodes)
+	if err != nil {
+		return nil, err
+	}
+	return append(criSteps, k8sSteps...), nil
+}
+
 func getK8sSteps(ctx context.Context, c *v1.Cluster, action v1.StepAction) ([]v1.Step, error) {
+	if c.ClusterType == v1.ClusterK3s {
+		runnable := k8s.K3sRunnable(*c.Kubeadm)
+		return runnable.GetStep(ctx, action)
+	}
 	runnable := k8s.KubeadmRunnable(*c.Kubeadm)
 
 	return runnable.GetStep(ctx, action)
@@ -82,6 +123,19 @@ func getSteps(c component.Interface, action v1.StepAction) ([]v1.Step, error) {
 	}
 }
 
+// clusterStepPolicies returns the step policies the cluster carries in its annotation.
+func clusterStepPolicies(c *v1.Cluster) ([]v1.StepPolicy, error) {
+	v, ok := c.Annotations[common.AnnotationStepPolicies]
+	if !ok || v == "" {
+		return nil, nil
+	}
+	var policies []v1.StepPolicy
+	if err := json.Unmarshal([]byte(v), &policies); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", common.AnnotationStepPolicies, err)
+	}
+	return policies, nil
+}
+
 func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetadata, c *v1.Cluster, action v1.StepAction) (*v1.Operation, error) {
 	var steps []v1.Step
 	region := extraMetadata.Masters[0].Region
@@ -97,14 +151,20 @@ func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetada
 		common.LabelTopologyRegion: region,
 	}
 
-	// Container runtime should be installed on all nodes.
+	// Container runtime should be installed on all nodes, k3s runs its embedded containerd.
 	ctx := component.WithExtraMetadata(context.TODO(), *extraMetadata)
 	stepNodes := utils.UnwrapNodeList(extraMetadata.GetAllNodes())
-	cSteps, err := getCriStep(ctx, &c.Kubeadm.ContainerRuntime, action, stepNodes)
-	if err != nil {
-		return nil, err
+	var cSteps []v1.Step
+	if c.ClusterType != v1.ClusterK3s {
+		var err error
+		if cSteps, err = getNodesCriSteps(ctx, c.Kubeadm, action, stepNodes); err != nil {
+			return nil, err
+		}
 	}
 
+	// proxy of the container runtime, the kubelet and k3s
+	proxySteps := k8s.ProxySteps(c.Kubeadm, extraMetadata, action, stepNodes)
+
 	// kubernetes
 	k8sSteps, err := getK8sSteps(ctx, c, action)
 	if err != nil {
@@ -118,6 +178,7 @@ func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetada
 		// reverseComponents(carr)
 	} else {
 		copy(carr, c.Kubeadm.Components)
+		steps = append(steps, proxySteps...)
 		steps = append(steps, cSteps...)
 		steps = append(steps, k8sSteps...)
 	}
@@ -137,6 +198,9 @@ func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetada
 			if err := h.initComponentExtraCluster(ctx, newComp); err != nil {
 				return nil, err
 			}
+			if err := h.initComponentBackupPoint(ctx, newComp); err != nil {
+				return nil, err
+			}
 		}
 		if err := newComp.Validate(); err != nil {
 			return nil, err
@@ -154,6 +218,7 @@ func (h *handler) parseOperationFromCluster(extraMetadata *component.ExtraMetada
 	if action == v1.ActionUninstall {
 		steps = append(steps, k8sSteps...)
 		steps = append(steps, cSteps...)
+		steps = append(steps, proxySteps...)
 	}
 
 	op.Steps = steps
@@ -177,6 +242,18 @@ func (h *handler) initComponentExtraCluster(ctx context.Context, p component.Int
 	return p.CompleteWithExtraCluster(extraClulsterMeta)
 }
 
+func (h *handler) initComponentBackupPoint(ctx context.Context, p component.Interface) error {
+	consumer, ok := p.(component.BackupPointConsumer)
+	if !ok || consumer.RequireBackupPoint() == "" {
+		return nil
+	}
+	bp, err := h.clusterOperator.GetBackupPointEx(ctx, consumer.RequireBackupPoint(), "0")
+	if err != nil {
+		return err
+	}
+	return consumer.CompleteWithBackupPoint(bp)
+}
+
 func (h *handler) parseRecoverySteps(c *v1.Cluster, b *v1.Backup, restoreDir string, action v1.StepAction) ([]v1.Step, error) {
 	steps := make([]v1.Step, 0)
 
@@ -285,6 +362,12 @@ func (h *handler) parseOperationFromComponent(extraMetadata *component.ExtraMeta
 		if !ok {
 			continue
 		}
+		// uninstalling does not need the backup point, which may be gone already
+		if action != v1.ActionUninstall {
+			if err := h.initComponentBackupPoint(ctx, newComp); err != nil {
+				return nil, err
+			}
+		}
 		if err := newComp.Validate(); err != nil {
 			return nil, err
 		}
@@ -295,6 +378,10 @@ func (h *handler) parseOperationFromComponent(extraMetadata *component.ExtraMeta
 		if err != nil {
 			return nil, err
 		}
+		// components without upgrade steps of their own are upgraded by applying the manifests again
+		if action == v1.ActionUpgrade && len(s) == 0 {
+			s = newComp.GetInstallSteps()
+		}
 		steps = append(steps, s...)
 	}
 
@@ -357,9 +444,50 @@ func getActBackupStep(c *v1.Cluster, b *v1.Backup, bp *v1.BackupPoint, pNode *v1
 		}
 	}
 
+	actBackup.Verify = b.Verify
 	if err = actBackup.InitSteps(ctx); err != nil {
 		return
 	}
 
 	return actBackup.GetStep(action), nil
 }
+
+// writeWithFields writes entity with status ok, only the given fields of it are kept.
+func writeWithFields(request *restful.Request, response *restful.Response, entity interface{}, fields []string) {
+	restplus.SetETag(response, entity)
+	if len(fields) == 0 {
+		_ = response.WriteHeaderAndEntity(http.StatusOK, entity)
+		return
+	}
+	result, err := projectFields(entity, fields)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+}
+
+// requestFields returns the fields parameter of the requests which are not parsed to a query, e.g. gets by name.
+func requestFields(request *restful.Request) []string {
+	return query.ParseFields(request.QueryParameter(query.ParameterFields))
+}
+
+// projectFields applies the projection to every item of a pageable response
+// so that totalCount is always returned.
+func projectFields(entity interface{}, fields []string) (interface{}, error) {
+	page, ok := entity.(*models.PageableResponse)
+	if !ok {
+		return query.ProjectFields(entity, fields)
+	}
+	items := make([]interface{}, len(page.Items))
+	for i := range page.Items {
+		item, err := query.ProjectFields(page.Items[i], fields)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = item
+	}
+	projected := *page
+	projected.Items = items
+	return &projected, nil
+}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/csi"
 	nfsprovisioner "github.com/kubeclipper/kubeclipper/pkg/component/nfs"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
@@ -148,7 +149,7 @@ var (
 )
 
 func Test_parseOperationFromCluster(t *testing.T) {
-	h := newHandler(nil, nil, nil, nil, nil)
+	h := newHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "")
 	type args struct {
 		c      *v1.Cluster
 		meta   *component.ExtraMetadata
@@ -196,18 +197,20 @@ func Test_parseOperationFromComponent(t *testing.T) {
 		cluster    *v1.Cluster
 		components []v1.Component
 	}
-	h := newHandler(nil, nil, nil, nil, nil)
+	h := newHandler(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "")
 	nfs := nfsprovisioner.NFSProvisioner{
-		ManifestsDir:     "/tmp/.nfs",
-		Namespace:        "kube-system",
-		Replicas:         1,
-		ServerAddr:       "172.20.151.105",
-		SharedPath:       "/tmp/nfs/data",
-		StorageClassName: "nfs-sc",
-		IsDefault:        false,
-		ReclaimPolicy:    "Delete",
-		ArchiveOnDelete:  false,
-		MountOptions:     nil,
+		StorageClass: csi.StorageClass{
+			ManifestsDir:     "/tmp/.nfs",
+			Namespace:        "kube-system",
+			Replicas:         1,
+			StorageClassName: "nfs-sc",
+			IsDefault:        false,
+			ReclaimPolicy:    "Delete",
+			MountOptions:     nil,
+		},
+		ServerAddr:      "172.20.151.105",
+		SharedPath:      "/tmp/nfs/data",
+		ArchiveOnDelete: false,
 	}
 	nfsByte, _ := json.Marshal(nfs)
 	com := []v1.Component{
@@ -453,3 +456,47 @@ func Test_parseActBackupSteps(t *testing.T) {
 		})
 	}
 }
+
+func Test_getPrewarmSteps(t *testing.T) {
+	nodes := []v1.StepNode{{ID: "4cf1ad74-704c-4290-a523-e524e930245d", IPv4: "172.18.94.115"}}
+	tests := []struct {
+		name      string
+		prewarm   *v1.RegionPrewarm
+		wantSteps int
+		wantErr   bool
+	}{
+		{
+			name: "test prewarm docker nodes",
+			prewarm: &v1.RegionPrewarm{
+				KubernetesVersion: "v1.23.6",
+				ContainerRuntime:  c1.Kubeadm.ContainerRuntime,
+				Offline:           true,
+			},
+			wantSteps: 2,
+		},
+		{
+			name: "test prewarm unsupported runtime",
+			prewarm: &v1.RegionPrewarm{
+				KubernetesVersion: "v1.23.6",
+				ContainerRuntime:  v1.ContainerRuntime{Type: "unknown"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			steps, err := getPrewarmSteps(test.prewarm, nodes)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("getPrewarmSteps() error = %v, wantErr %v", err, test.wantErr)
+			}
+			if len(steps) != test.wantSteps {
+				t.Errorf("getPrewarmSteps() got %d steps, want %d", len(steps), test.wantSteps)
+			}
+			for _, step := range steps {
+				if len(step.Nodes) != len(nodes) {
+					t.Errorf("getPrewarmSteps() step %s runs on %d nodes, want %d", step.Name, len(step.Nodes), len(nodes))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/admission"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	apirequest "github.com/kubeclipper/kubeclipper/pkg/server/request"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+func (h *handler) ListWebhookConfigurations(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	list, err := h.webhooks.ListWebhookConfigurationsEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, list, q.Fields)
+}
+
+func (h *handler) DescribeWebhookConfiguration(request *restful.Request, response *restful.Response) {
+	c, err := h.webhooks.GetWebhookConfigurationEx(request.Request.Context(), request.PathParameter(query.ParameterName), "0")
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	writeWithFields(request, response, c, requestFields(request))
+}
+
+func (h *handler) CreateWebhookConfiguration(request *restful.Request, response *restful.Response) {
+	c := &v1.WebhookConfiguration{}
+	if err := request.ReadEntity(c); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := c.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	c, err := h.webhooks.CreateWebhookConfiguration(request.Request.Context(), c)
+	if err != nil {
+		if apimachineryErrors.IsAlreadyExists(err) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusCreated, c)
+}
+
+func (h *handler) UpdateWebhookConfiguration(request *restful.Request, response *restful.Response) {
+	c := &v1.WebhookConfiguration{}
+	if err := request.ReadEntity(c); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if name := request.PathParameter(query.ParameterName); name != c.Name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("webhook configuration name not match"))
+		return
+	}
+	if err := c.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	c, err := h.webhooks.UpdateWebhookConfiguration(request.Request.Context(), c)
+	if err != nil {
+		switch {
+		case apimachineryErrors.IsNotFound(err):
+			restplus.HandleNotFound(response, request, err)
+		case apimachineryErrors.IsConflict(err):
+			restplus.HandleConflict(response, request, err)
+		default:
+			restplus.HandleInternalError(response, request, err)
+		}
+		return
+	}
+	restplus.SetETag(response, c)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+func (h *handler) DeleteWebhookConfiguration(request *restful.Request, response *restful.Response) {
+	if err := h.webhooks.DeleteWebhookConfiguration(request.Request.Context(), request.PathParameter(query.ParameterName)); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// admitClusterDelete sends the deletion of the cluster to the webhooks when it is requested,
+// the cluster object itself is removed later by the controller once it is uninstalled.
+func (h *handler) admitClusterDelete(request *restful.Request, c *v1.Cluster, dryRun bool) error {
+	u, ok := apirequest.UserFrom(request.Request.Context())
+	if !ok {
+		return nil
+	}
+	_, err := h.admitter.Admit(request.Request.Context(), &admission.Attributes{
+		Resource:  "clusters",
+		Operation: v1.AdmissionDelete,
+		Name:      c.Name,
+		OldObject: c,
+		User:      u,
+		DryRun:    dryRun,
+	})
+	return err
+}
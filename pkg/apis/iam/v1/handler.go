@@ -66,13 +66,16 @@ type handler struct {
 	iamOperator   iam.Operator
 	authz         authorizer.Authorizer
 	tokenOperator auth.TokenManagementInterface
+	// projectOperator manages the projects isolating the resources of teams.
+	projectOperator iam.ProjectOperator
 }
 
-func newHandler(iamOperator iam.Operator, authz authorizer.Authorizer, tokenOperator auth.TokenManagementInterface) *handler {
+func newHandler(iamOperator iam.Operator, projectOperator iam.ProjectOperator, authz authorizer.Authorizer, tokenOperator auth.TokenManagementInterface) *handler {
 	return &handler{
-		iamOperator:   iamOperator,
-		authz:         authz,
-		tokenOperator: tokenOperator,
+		iamOperator:     iamOperator,
+		authz:           authz,
+		tokenOperator:   tokenOperator,
+		projectOperator: projectOperator,
 	}
 }
 
@@ -142,6 +145,7 @@ func (h *handler) DescribeToken(request *restful.Request, response *restful.Resp
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	restplus.SetETag(response, c)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
 }
 
@@ -290,6 +294,7 @@ func (h *handler) DescribeUser(request *restful.Request, response *restful.Respo
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	restplus.SetETag(response, c)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
 }
 
@@ -440,6 +445,33 @@ func (h *handler) DescribeRole(request *restful.Request, response *restful.Respo
 		restplus.HandleInternalError(response, request, err)
 		return
 	}
+	restplus.SetETag(response, c)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
+}
+
+func (h *handler) ListRoleBindings(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	result, err := h.iamOperator.ListRoleBindingEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+}
+
+func (h *handler) DescribeRoleBinding(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	resourceVersion := strutil.StringDefaultIfEmpty("0", request.QueryParameter(query.ParameterResourceVersion))
+	c, err := h.iamOperator.GetRoleBindingEx(request.Request.Context(), name, resourceVersion)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	restplus.SetETag(response, c)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, c)
 }
 
@@ -522,6 +554,7 @@ func (h *handler) UpdateRole(request *restful.Request, response *restful.Respons
 		return
 
 	}
+	restplus.SetETag(response, updated)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, updated)
 }
 
@@ -585,6 +618,7 @@ func (h *handler) UpdateUser(request *restful.Request, response *restful.Respons
 		}
 		updated.Annotations[common.RoleAnnotation] = role
 	}
+	restplus.SetETag(response, updated)
 	_ = response.WriteHeaderAndEntity(http.StatusOK, updated)
 }
 
@@ -594,6 +628,10 @@ func (h *handler) updateUser(ctx context.Context, user *iamv1.User) (*iamv1.User
 		return nil, err
 	}
 	user.Spec.EncryptedPassword = u.Spec.EncryptedPassword
+	// clients unaware of preferences must not reset them
+	if user.Spec.Preferences == nil {
+		user.Spec.Preferences = u.Spec.Preferences
+	}
 	user.Status = u.Status
 	user.ResourceVersion = u.ResourceVersion
 	return h.iamOperator.UpdateUser(ctx, user)
@@ -687,6 +725,52 @@ func (h *handler) updateRoleBinding(ctx context.Context, operator authuser.Info,
 	return nil
 }
 
+func (h *handler) DescribeUserPreferences(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	user, err := h.iamOperator.GetUserEx(request.Request.Context(), name, "0", false, false)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	prefs := user.Spec.Preferences
+	if prefs == nil {
+		prefs = &iamv1.UserPreferences{}
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, prefs)
+}
+
+func (h *handler) UpdateUserPreferences(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	prefs := &iamv1.UserPreferences{}
+	if err := request.ReadEntity(prefs); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := prefs.Validate(); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	user, err := h.iamOperator.GetUserEx(request.Request.Context(), name, "0", false, false)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	user.Spec.Preferences = prefs
+	if _, err = h.iamOperator.UpdateUser(request.Request.Context(), user); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, prefs)
+}
+
 func (h *handler) UpdateUserPassword(request *restful.Request, response *restful.Response) {
 	username := request.PathParameter(query.ParameterName)
 	var passwordReset PasswordReset
@@ -0,0 +1,38 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// The getters of the resources patched by restplus.PatchHandler.
+
+func (h *handler) getUser(ctx context.Context, name string) (runtime.Object, error) {
+	return h.iamOperator.GetUserEx(ctx, name, "", true, false)
+}
+
+func (h *handler) getRole(ctx context.Context, name string) (runtime.Object, error) {
+	return h.iamOperator.GetRoleEx(ctx, name, "")
+}
+
+func (h *handler) getProject(ctx context.Context, name string) (runtime.Object, error) {
+	return h.projectOperator.GetProjectEx(ctx, name, "")
+}
@@ -0,0 +1,340 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	"go.uber.org/zap"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/iam/validation"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+// ProjectMember binds a user to a role inside a project.
+type ProjectMember struct {
+	User string `json:"user"`
+	Role string `json:"role"`
+}
+
+// validateProjectRole rejects the roles granting more than the project scoped resources,
+// a project member must not be able to reach users, roles or platform settings through it.
+func validateProjectRole(role *iamv1.GlobalRole) error {
+	if _, ok := role.Annotations[common.RegoOverrideAnnotation]; ok {
+		return fmt.Errorf("role %s overrides the rego policy and can not be bound to a project", role.Name)
+	}
+	for _, rule := range role.Rules {
+		if len(rule.NonResourceURLs) > 0 {
+			return fmt.Errorf("role %s grants non resource urls and can not be bound to a project", role.Name)
+		}
+		for _, group := range rule.APIGroups {
+			if group == corev1.GroupName {
+				continue
+			}
+			if group == iamv1.GroupName && onlyProjects(rule.Resources) {
+				continue
+			}
+			return fmt.Errorf("role %s grants %q resources and can not be bound to a project", role.Name, group)
+		}
+	}
+	return nil
+}
+
+func onlyProjects(resources []string) bool {
+	for _, resource := range resources {
+		if resource != "projects" {
+			return false
+		}
+	}
+	return len(resources) > 0
+}
+
+// projectRoleBindingName names the binding holding the users of role in project.
+func projectRoleBindingName(project, role string) string {
+	return fmt.Sprintf("%s:%s", project, role)
+}
+
+func (h *handler) CreateProject(request *restful.Request, response *restful.Response) {
+	p := &iamv1.Project{}
+	if err := request.ReadEntity(p); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if errs := validation.ValidateProject(p); len(errs) > 0 {
+		restplus.HandleBadRequest(response, request, errs.ToAggregate())
+		return
+	}
+	created, err := h.projectOperator.CreateProject(request.Request.Context(), p)
+	if err != nil {
+		if apimachineryErrors.IsAlreadyExists(err) {
+			restplus.HandleBadRequest(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, created)
+}
+
+func (h *handler) ListProjects(request *restful.Request, response *restful.Response) {
+	q := query.ParseQueryParameter(request)
+	if q.Watch {
+		h.watchProject(request, response, q)
+		return
+	}
+	result, err := h.projectOperator.ListProjectEx(request.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, result)
+}
+
+func (h *handler) watchProject(req *restful.Request, resp *restful.Response, q *query.Query) {
+	timeout := query.MinTimeoutSeconds * time.Second
+	if q.TimeoutSeconds != nil {
+		timeout = time.Duration(*q.TimeoutSeconds) * time.Second
+	}
+	watcher, err := h.projectOperator.WatchProjects(req.Request.Context(), q)
+	if err != nil {
+		restplus.HandleInternalError(resp, req, err)
+		return
+	}
+	restplus.ServeWatch(watcher, iamv1.SchemeGroupVersion.WithKind(iamv1.KindProject), req, resp, timeout)
+}
+
+func (h *handler) DescribeProject(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	resourceVersion := strutil.StringDefaultIfEmpty("0", request.QueryParameter(query.ParameterResourceVersion))
+	p, err := h.projectOperator.GetProjectEx(request.Request.Context(), name, resourceVersion)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	restplus.SetETag(response, p)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, p)
+}
+
+func (h *handler) UpdateProject(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	p := &iamv1.Project{}
+	if err := request.ReadEntity(p); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if p.Name != name {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("the name of the object (%s) does not match the name on the URL (%s)", p.Name, name))
+		return
+	}
+	updated, err := h.projectOperator.UpdateProject(request.Request.Context(), p)
+	if err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			restplus.HandleNotFound(response, request, err)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	restplus.SetETag(response, updated)
+	_ = response.WriteHeaderAndEntity(http.StatusOK, updated)
+}
+
+func (h *handler) DeleteProject(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	ctx := request.Request.Context()
+	// remove the project bindings first, so that a project created again with the same name
+	// does not grant the roles of the deleted one.
+	bindings, err := h.listProjectRoleBindings(ctx, name)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	for _, binding := range bindings.Items {
+		if err = h.iamOperator.DeleteRoleBinding(ctx, binding.Name); err != nil && !apimachineryErrors.IsNotFound(err) {
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+	if err = h.projectOperator.DeleteProject(ctx, name); err != nil {
+		if apimachineryErrors.IsNotFound(err) {
+			logger.Debug("project has already not exist when delete", zap.String("project", name))
+			response.WriteHeader(http.StatusOK)
+			return
+		}
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+func (h *handler) ListProjectMembers(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	bindings, err := h.listProjectRoleBindings(request.Request.Context(), name)
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	members := make([]ProjectMember, 0)
+	for _, binding := range bindings.Items {
+		for _, subject := range binding.Subjects {
+			if subject.Kind == rbacv1.UserKind {
+				members = append(members, ProjectMember{User: subject.Name, Role: binding.RoleRef.Name})
+			}
+		}
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, members)
+}
+
+// UpdateProjectMember binds the user to the role in the project, replacing the role the user had in it.
+func (h *handler) UpdateProjectMember(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	username := request.PathParameter("user")
+	member := ProjectMember{}
+	if err := request.ReadEntity(&member); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if member.Role == "" {
+		restplus.HandleBadRequest(response, request, fmt.Errorf("role must be specified"))
+		return
+	}
+	member.User = username
+
+	ctx := request.Request.Context()
+	var role *iamv1.GlobalRole
+	for _, check := range []func() error{
+		func() error { _, err := h.projectOperator.GetProjectEx(ctx, name, "0"); return err },
+		func() error { _, err := h.iamOperator.GetUserEx(ctx, username, "0", true, false); return err },
+		func() (err error) { role, err = h.iamOperator.GetRoleEx(ctx, member.Role, "0"); return err },
+	} {
+		if err := check(); err != nil {
+			if apimachineryErrors.IsNotFound(err) {
+				restplus.HandleNotFound(response, request, err)
+				return
+			}
+			restplus.HandleInternalError(response, request, err)
+			return
+		}
+	}
+	if err := validateProjectRole(role); err != nil {
+		restplus.HandleBadRequest(response, request, err)
+		return
+	}
+	if err := h.removeProjectMember(ctx, name, username, member.Role); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+
+	subject := rbacv1.Subject{APIGroup: rbacv1.SchemeGroupVersion.Group, Kind: rbacv1.UserKind, Name: username}
+	binding, err := h.iamOperator.GetRoleBindingEx(ctx, projectRoleBindingName(name, member.Role), "0")
+	switch {
+	case apimachineryErrors.IsNotFound(err):
+		_, err = h.iamOperator.CreateRoleBinding(ctx, &iamv1.GlobalRoleBinding{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       iamv1.KindGlobalRoleBinding,
+				APIVersion: iamv1.SchemeGroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   projectRoleBindingName(name, member.Role),
+				Labels: map[string]string{common.LabelProject: name},
+			},
+			RoleRef: rbacv1.RoleRef{
+				APIGroup: iamv1.GroupName,
+				Kind:     iamv1.KindGlobalRole,
+				Name:     member.Role,
+			},
+			Subjects: []rbacv1.Subject{subject},
+		})
+	case err == nil && !hasUserSubject(binding.Subjects, username):
+		binding.Subjects = append(binding.Subjects, subject)
+		_, err = h.iamOperator.UpdateRoleBinding(ctx, binding)
+	}
+	if err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusOK, member)
+}
+
+func (h *handler) DeleteProjectMember(request *restful.Request, response *restful.Response) {
+	name := request.PathParameter(query.ParameterName)
+	username := request.PathParameter("user")
+	if err := h.removeProjectMember(request.Request.Context(), name, username, ""); err != nil {
+		restplus.HandleInternalError(response, request, err)
+		return
+	}
+	response.WriteHeader(http.StatusOK)
+}
+
+// removeProjectMember removes the user from the bindings of the project, except the binding of role keep.
+func (h *handler) removeProjectMember(ctx context.Context, project, username, keep string) error {
+	bindings, err := h.listProjectRoleBindings(ctx, project)
+	if err != nil {
+		return err
+	}
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if binding.RoleRef.Name == keep || !hasUserSubject(binding.Subjects, username) {
+			continue
+		}
+		subjects := make([]rbacv1.Subject, 0, len(binding.Subjects))
+		for _, subject := range binding.Subjects {
+			if subject.Kind != rbacv1.UserKind || subject.Name != username {
+				subjects = append(subjects, subject)
+			}
+		}
+		binding.Subjects = subjects
+		if _, err = h.iamOperator.UpdateRoleBinding(ctx, binding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *handler) listProjectRoleBindings(ctx context.Context, project string) (*iamv1.GlobalRoleBindingList, error) {
+	q := query.New()
+	q.LabelSelector = labels.SelectorFromSet(labels.Set{common.LabelProject: project}).String()
+	return h.iamOperator.ListRoleBindings(ctx, q)
+}
+
+func hasUserSubject(subjects []rbacv1.Subject, username string) bool {
+	for _, subject := range subjects {
+		if subject.Kind == rbacv1.UserKind && subject.Name == username {
+			return true
+		}
+	}
+	return false
+}
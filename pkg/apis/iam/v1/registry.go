@@ -35,6 +35,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/models"
 	"github.com/kubeclipper/kubeclipper/pkg/models/iam"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
 	"github.com/kubeclipper/kubeclipper/pkg/server/runtime"
 )
 
@@ -47,11 +48,11 @@ type PasswordReset struct {
 	NewPassword     string `json:"newPassword"`
 }
 
-func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz authorizer.Authorizer, tokenOperator auth.TokenManagementInterface) error {
+func AddToContainer(c *restful.Container, iamOperator iam.Operator, projectOperator iam.ProjectOperator, authz authorizer.Authorizer, tokenOperator auth.TokenManagementInterface) error {
 
 	webservice := runtime.NewWebService(schema.GroupVersion{Group: "iam.kubeclipper.io", Version: "v1"})
 
-	h := newHandler(iamOperator, authz, tokenOperator)
+	h := newHandler(iamOperator, projectOperator, authz, tokenOperator)
 
 	webservice.Route(webservice.GET("/tokens").
 		To(h.ListTokens).
@@ -69,6 +70,12 @@ func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz author
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
@@ -118,6 +125,12 @@ func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz author
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
@@ -174,6 +187,17 @@ func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz author
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.User{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.PATCH("/users/{name}").
+		To(restplus.PatchHandler(h.getUser, h.UpdateUser)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Patch user with a merge patch or a json patch, the patched user is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "user name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.User{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
 	webservice.Route(webservice.PUT("/users/{name}/password").
 		To(h.UpdateUserPassword).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
@@ -197,6 +221,26 @@ func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz author
 		Returns(http.StatusForbidden, http.StatusText(http.StatusForbidden), errors.HTTPError{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.GET("/users/{name}/preferences").
+		To(h.DescribeUserPreferences).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Describe user preferences.").
+		Param(webservice.PathParameter("name", "user name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.UserPreferences{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/users/{name}/preferences").
+		To(h.UpdateUserPreferences).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Update user preferences.").
+		Reads(iamv1.UserPreferences{}).
+		Param(webservice.PathParameter("name", "user name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.UserPreferences{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.PUT("/users/{name}/enable").
 		To(h.EnableUser).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
@@ -249,6 +293,12 @@ func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz author
 			DataFormat("fieldSelector=%s=%s")).
 		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
 			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterLimit, "with chunk, read at most limit resources in the order of their names instead of a page of all of them").Required(false).
+			DataType("integer")).
+		Param(webservice.QueryParameter(query.ParameterChunk, "read a chunk of limit resources, ignored with page or paging").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterContinue, "continue token of the previous chunk, reads the next one").Required(false)).
 		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
 			DataType("boolean")).
 		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
@@ -285,6 +335,37 @@ func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz author
 		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.GET("/rolebindings").
+		To(h.ListRoleBindings).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("List role bindings, a binding is named after its role and holds the users of the role.").
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/rolebindings/{name}").
+		To(h.DescribeRoleBinding).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Describe role binding.").
+		Param(webservice.PathParameter(query.ParameterName, "role binding name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.GlobalRoleBinding{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	webservice.Route(webservice.DELETE("/roles/{name}").
 		To(h.DeleteRole).
 		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
@@ -302,6 +383,125 @@ func AddToContainer(c *restful.Container, iamOperator iam.Operator, authz author
 		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.GlobalRole{}).
 		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
 
+	webservice.Route(webservice.PATCH("/roles/{name}").
+		To(restplus.PatchHandler(h.getRole, h.UpdateRole)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Patch role with a merge patch or a json patch, the patched role is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "role name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.GlobalRole{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
+	webservice.Route(webservice.POST("/projects").
+		To(h.CreateProject).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Create project.").
+		Reads(iamv1.Project{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.Project{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/projects").
+		To(h.ListProjects).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("List projects.").
+		Param(webservice.QueryParameter(query.PagingParam, "paging query, e.g. limit=100,page=1").
+			Required(false).
+			DataFormat("limit=%d,page=%d").
+			DefaultValue("limit=10,page=1")).
+		Param(webservice.QueryParameter(query.ParameterLabelSelector, "resource filter by metadata label").
+			Required(false).
+			DataFormat("labelSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParameterFieldSelector, "resource filter by field").
+			Required(false).
+			DataFormat("fieldSelector=%s=%s")).
+		Param(webservice.QueryParameter(query.ParamReverse, "resource sort reverse or not").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.OrderByParam, "field path the resources are sorted by, e.g. metadata.name").Required(false)).
+		Param(webservice.QueryParameter(query.ParameterWatch, "watch request").Required(false).
+			DataType("boolean")).
+		Param(webservice.QueryParameter(query.ParameterTimeoutSeconds, "watch timeout seconds").
+			DataType("integer").
+			DefaultValue("60").
+			Required(false)).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), models.PageableResponse{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/projects/{name}").
+		To(h.DescribeProject).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Describe project.").
+		Param(webservice.PathParameter(query.ParameterName, "project name").
+			Required(true).
+			DataType("string")).
+		Param(webservice.QueryParameter(query.ParameterResourceVersion, "resource version to query").
+			Required(false).
+			DataType("string")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.Project{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/projects/{name}").
+		To(h.UpdateProject).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Update project.").
+		Reads(iamv1.Project{}).
+		Param(webservice.PathParameter(query.ParameterName, "project name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.Project{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PATCH("/projects/{name}").
+		To(restplus.PatchHandler(h.getProject, h.UpdateProject)).
+		Consumes(restplus.PatchMIMETypes...).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Patch project with a merge patch or a json patch, the patched project is updated like by the PUT route.").
+		Param(webservice.PathParameter(query.ParameterName, "project name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), iamv1.Project{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusConflict, http.StatusText(http.StatusConflict), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/projects/{name}").
+		To(h.DeleteProject).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Delete project and its role bindings, the resources of the project are kept.").
+		Param(webservice.PathParameter(query.ParameterName, "project name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.GET("/projects/{name}/members").
+		To(h.ListProjectMembers).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("List the users of the project and their roles in it.").
+		Param(webservice.PathParameter(query.ParameterName, "project name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), []ProjectMember{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.PUT("/projects/{name}/members/{user}").
+		To(h.UpdateProjectMember).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Bind the user to a role in the project.").
+		Reads(ProjectMember{}).
+		Param(webservice.PathParameter(query.ParameterName, "project name")).
+		Param(webservice.PathParameter("user", "user name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), ProjectMember{}).
+		Returns(http.StatusBadRequest, http.StatusText(http.StatusBadRequest), errors.HTTPError{}).
+		Returns(http.StatusNotFound, http.StatusText(http.StatusNotFound), errors.HTTPError{}).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
+	webservice.Route(webservice.DELETE("/projects/{name}/members/{user}").
+		To(h.DeleteProjectMember).
+		Metadata(restfulspec.KeyOpenAPITags, []string{CoreIAMTag}).
+		Doc("Remove the user from the project.").
+		Param(webservice.PathParameter(query.ParameterName, "project name")).
+		Param(webservice.PathParameter("user", "user name")).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), nil).
+		Returns(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError), errors.HTTPError{}))
+
 	c.Add(webservice)
 
 	return nil
@@ -0,0 +1,201 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+const (
+	appName = "kubeclipper-server"
+	// enterprise number reserved for documentation, see RFC 5612
+	sdID = "audit@32473"
+
+	defaultFacility = 13
+
+	severityWarning = 4
+	severityInfo    = 6
+)
+
+// Formatter serializes an audit event into a single message for an external sink.
+type Formatter interface {
+	Format(e *audit.Event) ([]byte, error)
+}
+
+func NewFormatter(opts *SinkOptions, hostname string) (Formatter, error) {
+	switch opts.Format {
+	case FormatJSON:
+		return jsonFormatter{}, nil
+	case FormatRFC5424:
+		facility := opts.Facility
+		if facility == 0 {
+			facility = defaultFacility
+		}
+		return &rfc5424Formatter{facility: facility, hostname: hostname}, nil
+	case FormatCEF:
+		return cefFormatter{}, nil
+	}
+	return nil, fmt.Errorf("unsupported audit format %q", opts.Format)
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(e *audit.Event) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+type rfc5424Formatter struct {
+	facility int
+	hostname string
+}
+
+// Format renders the event as <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID [SD] MSG,
+// with the request summary as structured data and the full event as json message.
+func (f *rfc5424Formatter) Format(e *audit.Event) ([]byte, error) {
+	msg, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	severity := severityInfo
+	if failed(e) {
+		severity = severityWarning
+	}
+	hostname := f.hostname
+	if hostname == "" {
+		hostname = "-"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s - audit [%s", f.facility*8+severity,
+		eventTime(e).UTC().Format("2006-01-02T15:04:05.000000Z07:00"), hostname, appName, sdID)
+	for _, p := range [][2]string{
+		{"auditID", string(e.AuditID)},
+		{"user", e.User.Username},
+		{"sourceIP", sourceIP(e)},
+		{"verb", e.Verb},
+		{"resource", resource(e)},
+		{"name", objectName(e)},
+		{"code", strconv.Itoa(int(responseCode(e)))},
+	} {
+		if p[1] == "" {
+			continue
+		}
+		fmt.Fprintf(&b, " %s=\"%s\"", p[0], sdEscaper.Replace(p[1]))
+	}
+	b.WriteString("] ")
+	b.Write(msg)
+	return []byte(b.String()), nil
+}
+
+var sdEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+type cefFormatter struct{}
+
+// Format renders the event as CEF:Version|Vendor|Product|Version|SignatureID|Name|Severity|Extension.
+func (cefFormatter) Format(e *audit.Event) ([]byte, error) {
+	severity, outcome := 3, "success"
+	if failed(e) {
+		severity, outcome = 7, "failure"
+	}
+	header := []string{
+		"CEF:0",
+		"KubeClipper",
+		appName,
+		"v1",
+		cefHeaderEscaper.Replace(e.Verb),
+		cefHeaderEscaper.Replace(strings.TrimSpace(e.Verb + " " + resource(e))),
+		strconv.Itoa(severity),
+	}
+	var ext []string
+	for _, p := range [][2]string{
+		{"rt", strconv.FormatInt(eventTime(e).UnixMilli(), 10)},
+		{"externalId", string(e.AuditID)},
+		{"suser", e.User.Username},
+		{"src", sourceIP(e)},
+		{"act", e.Verb},
+		{"request", e.RequestURI},
+		{"requestClientApplication", e.UserAgent},
+		{"outcome", outcome},
+		{"cn1Label", "responseCode"},
+		{"cn1", strconv.Itoa(int(responseCode(e)))},
+		{"cs1Label", "resource"},
+		{"cs1", resource(e)},
+		{"cs2Label", "resourceName"},
+		{"cs2", objectName(e)},
+	} {
+		if p[1] == "" {
+			continue
+		}
+		ext = append(ext, p[0]+"="+cefExtEscaper.Replace(p[1]))
+	}
+	return []byte(strings.Join(header, "|") + "|" + strings.Join(ext, " ")), nil
+}
+
+var (
+	cefHeaderEscaper = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\n", " ", "\r", " ")
+	cefExtEscaper    = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\n", `\n`, "\r", `\r`)
+)
+
+func eventTime(e *audit.Event) time.Time {
+	if !e.StageTimestamp.IsZero() {
+		return e.StageTimestamp.Time
+	}
+	return e.RequestReceivedTimestamp.Time
+}
+
+func failed(e *audit.Event) bool {
+	return responseCode(e) >= http.StatusBadRequest
+}
+
+func responseCode(e *audit.Event) int32 {
+	if e.ResponseStatus == nil {
+		return 0
+	}
+	return e.ResponseStatus.Code
+}
+
+func sourceIP(e *audit.Event) string {
+	if len(e.SourceIPs) == 0 {
+		return ""
+	}
+	return e.SourceIPs[0]
+}
+
+func resource(e *audit.Event) string {
+	if e.ObjectRef == nil {
+		return ""
+	}
+	if e.ObjectRef.Subresource != "" {
+		return e.ObjectRef.Resource + "/" + e.ObjectRef.Subresource
+	}
+	return e.ObjectRef.Resource
+}
+
+func objectName(e *audit.Event) string {
+	if e.ObjectRef == nil {
+		return ""
+	}
+	return e.ObjectRef.Name
+}
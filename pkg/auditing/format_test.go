@@ -0,0 +1,129 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	authnv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/apis/audit"
+)
+
+func newTestEvent(code int32) *audit.Event {
+	ts := metav1.NewMicroTime(time.Date(2022, 6, 1, 8, 0, 0, 0, time.UTC))
+	return &audit.Event{
+		AuditID:                  "3c5b1e0e",
+		Verb:                     "create",
+		RequestURI:               "/api/core.kubeclipper.io/v1/clusters",
+		User:                     authnv1.UserInfo{Username: "ad|min"},
+		SourceIPs:                []string{"10.0.0.1"},
+		UserAgent:                "kcctl",
+		ObjectRef:                &audit.ObjectReference{Resource: "clusters", Name: "demo=1"},
+		ResponseStatus:           &metav1.Status{Code: code},
+		RequestReceivedTimestamp: ts,
+		StageTimestamp:           ts,
+	}
+}
+
+func TestRFC5424Formatter(t *testing.T) {
+	f, err := NewFormatter(&SinkOptions{Format: FormatRFC5424}, "kc-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := f.Format(newTestEvent(200))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `<110>1 2022-06-01T08:00:00.000000Z kc-01 kubeclipper-server - audit [audit@32473 auditID="3c5b1e0e" user="ad|min" sourceIP="10.0.0.1" verb="create" resource="clusters" name="demo=1" code="200"] {`
+	if !strings.HasPrefix(string(msg), want) {
+		t.Errorf("Format() = %s, want prefix %s", msg, want)
+	}
+
+	// failed requests are logged with warning severity
+	msg, _ = f.Format(newTestEvent(403))
+	if !strings.HasPrefix(string(msg), "<108>1 ") {
+		t.Errorf("Format() = %s, want warning priority <108>", msg)
+	}
+}
+
+func TestCEFFormatter(t *testing.T) {
+	f, err := NewFormatter(&SinkOptions{Format: FormatCEF}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := f.Format(newTestEvent(500))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `CEF:0|KubeClipper|kubeclipper-server|v1|create|create clusters|7|rt=1654070400000 externalId=3c5b1e0e suser=ad|min src=10.0.0.1 act=create ` +
+		`request=/api/core.kubeclipper.io/v1/clusters requestClientApplication=kcctl outcome=failure cn1Label=responseCode cn1=500 ` +
+		`cs1Label=resource cs1=clusters cs2Label=resourceName cs2=demo\=1`
+	if string(msg) != want {
+		t.Errorf("Format() =\n%s\nwant\n%s", msg, want)
+	}
+}
+
+func TestSinkOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{
+			name: "valid sinks",
+			opts: Options{Sinks: []SinkOptions{
+				{Name: "a", Format: FormatCEF, Transport: TransportTLS, Address: "siem:6514", TLS: &TLSOptions{CAFile: "ca.crt"}},
+				{Name: "b", Format: FormatRFC5424, Transport: TransportUDP, Address: "127.0.0.1:514"},
+			}},
+		},
+		{
+			name: "duplicate name",
+			opts: Options{Sinks: []SinkOptions{
+				{Name: "a", Format: FormatJSON, Transport: TransportTCP, Address: "127.0.0.1:514"},
+				{Name: "a", Format: FormatJSON, Transport: TransportTCP, Address: "127.0.0.1:514"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown format",
+			opts:    Options{Sinks: []SinkOptions{{Name: "a", Format: "leef", Transport: TransportTCP, Address: "127.0.0.1:514"}}},
+			wantErr: true,
+		},
+		{
+			name:    "missing port",
+			opts:    Options{Sinks: []SinkOptions{{Name: "a", Format: FormatJSON, Transport: TransportTCP, Address: "127.0.0.1"}}},
+			wantErr: true,
+		},
+		{
+			name:    "tls options without tls transport",
+			opts:    Options{Sinks: []SinkOptions{{Name: "a", Format: FormatJSON, Transport: TransportTCP, Address: "127.0.0.1:514", TLS: &TLSOptions{}}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if errs := tt.opts.Validate(); (len(errs) > 0) != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", errs, tt.wantErr)
+			}
+		})
+	}
+}
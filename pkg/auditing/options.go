@@ -0,0 +1,113 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatRFC5424 = "rfc5424"
+	FormatCEF     = "cef"
+
+	TransportUDP = "udp"
+	TransportTCP = "tcp"
+	TransportTLS = "tls"
+)
+
+// Options holds the external sinks audit events are forwarded to, in addition
+// to the console and database backends which are always enabled.
+type Options struct {
+	Sinks []SinkOptions `json:"sinks,omitempty" yaml:"sinks,omitempty" mapstructure:"sinks"`
+}
+
+type SinkOptions struct {
+	Name string `json:"name" yaml:"name" mapstructure:"name"`
+	// Format is one of json, rfc5424 or cef.
+	Format string `json:"format" yaml:"format" mapstructure:"format"`
+	// Transport is one of udp, tcp or tls.
+	Transport string `json:"transport" yaml:"transport" mapstructure:"transport"`
+	Address   string `json:"address" yaml:"address" mapstructure:"address"`
+	// Facility is the syslog facility code used by the rfc5424 format, defaults to 13 (log audit).
+	Facility int `json:"facility,omitempty" yaml:"facility,omitempty" mapstructure:"facility"`
+	// IncludeReadOnly also forwards get, list and watch events.
+	IncludeReadOnly bool        `json:"includeReadOnly,omitempty" yaml:"includeReadOnly,omitempty" mapstructure:"includeReadOnly"`
+	TLS             *TLSOptions `json:"tls,omitempty" yaml:"tls,omitempty" mapstructure:"tls"`
+}
+
+type TLSOptions struct {
+	CAFile             string `json:"caFile,omitempty" yaml:"caFile,omitempty" mapstructure:"caFile"`
+	CertFile           string `json:"certFile,omitempty" yaml:"certFile,omitempty" mapstructure:"certFile"`
+	KeyFile            string `json:"keyFile,omitempty" yaml:"keyFile,omitempty" mapstructure:"keyFile"`
+	ServerName         string `json:"serverName,omitempty" yaml:"serverName,omitempty" mapstructure:"serverName"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" yaml:"insecureSkipVerify,omitempty" mapstructure:"insecureSkipVerify"`
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	names := make(map[string]struct{}, len(o.Sinks))
+	for i := range o.Sinks {
+		s := &o.Sinks[i]
+		if _, ok := names[s.Name]; ok {
+			errs = append(errs, fmt.Errorf("duplicate audit sink name %q", s.Name))
+		}
+		names[s.Name] = struct{}{}
+		errs = append(errs, s.Validate()...)
+	}
+	return errs
+}
+
+func (s *SinkOptions) Validate() []error {
+	var errs []error
+	if s.Name == "" {
+		errs = append(errs, fmt.Errorf("audit sink name must be specified"))
+	}
+	switch s.Format {
+	case FormatJSON, FormatRFC5424, FormatCEF:
+	default:
+		errs = append(errs, fmt.Errorf("audit sink %s: unsupported format %q", s.Name, s.Format))
+	}
+	switch s.Transport {
+	case TransportUDP, TransportTCP, TransportTLS:
+	default:
+		errs = append(errs, fmt.Errorf("audit sink %s: unsupported transport %q", s.Name, s.Transport))
+	}
+	if _, _, err := net.SplitHostPort(s.Address); err != nil {
+		errs = append(errs, fmt.Errorf("audit sink %s: invalid address %q: %v", s.Name, s.Address, err))
+	}
+	if s.Facility < 0 || s.Facility > 23 {
+		errs = append(errs, fmt.Errorf("audit sink %s: syslog facility must be between 0 and 23", s.Name))
+	}
+	if s.TLS != nil && s.Transport != TransportTLS {
+		errs = append(errs, fmt.Errorf("audit sink %s: tls options require the tls transport", s.Name))
+	}
+	if s.TLS != nil && (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+		errs = append(errs, fmt.Errorf("audit sink %s: tls certFile and keyFile must be specified together", s.Name))
+	}
+	return errs
+}
@@ -0,0 +1,185 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/apis/audit"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+)
+
+const (
+	sinkDialTimeout  = 5 * time.Second
+	sinkWriteTimeout = 5 * time.Second
+)
+
+var _ Backend = (*SinkBackend)(nil)
+
+// SinkBackend forwards audit events to an external collector such as a syslog server or SIEM.
+type SinkBackend struct {
+	opts        SinkOptions
+	formatter   Formatter
+	tlsConfig   *tls.Config
+	conn        net.Conn
+	eventCh     chan *audit.Event
+	stopCh      <-chan struct{}
+	ignoreVerbs sets.String
+}
+
+func NewSinkBackend(opts SinkOptions, stopCh <-chan struct{}) (Backend, error) {
+	hostname, _ := os.Hostname()
+	formatter, err := NewFormatter(&opts, hostname)
+	if err != nil {
+		return nil, err
+	}
+	b := &SinkBackend{
+		opts:        opts,
+		formatter:   formatter,
+		eventCh:     make(chan *audit.Event, 1000),
+		stopCh:      stopCh,
+		ignoreVerbs: sets.NewString(),
+	}
+	if !opts.IncludeReadOnly {
+		b.ignoreVerbs.Insert("get", "list", "watch")
+	}
+	if opts.Transport == TransportTLS {
+		if b.tlsConfig, err = newTLSConfig(opts.TLS); err != nil {
+			return nil, fmt.Errorf("audit sink %s: %v", opts.Name, err)
+		}
+	}
+	go b.worker()
+	return b, nil
+}
+
+func newTLSConfig(opts *TLSOptions) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if opts == nil {
+		return cfg, nil
+	}
+	cfg.ServerName = opts.ServerName
+	cfg.InsecureSkipVerify = opts.InsecureSkipVerify
+	if opts.CAFile != "" {
+		ca, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate found in %s", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+func (b *SinkBackend) SendEvent(e audit.Event) {
+	if b.ignoreVerbs.Has(e.Verb) {
+		return
+	}
+	select {
+	case b.eventCh <- &e:
+		return
+	case <-time.After(time.Second):
+		logger.Warn("send event to audit sink timeout", zap.String("sink", b.opts.Name), zap.String("audit_id", string(e.AuditID)))
+	}
+}
+
+func (b *SinkBackend) worker() {
+	defer b.close()
+	for {
+		select {
+		case <-b.stopCh:
+			return
+		case event, ok := <-b.eventCh:
+			if !ok {
+				return
+			}
+			if err := b.write(event); err != nil {
+				logger.Error("forward audit event failed", zap.String("sink", b.opts.Name),
+					zap.String("audit_id", string(event.AuditID)), zap.Error(err))
+			}
+		}
+	}
+}
+
+// write sends one event, reconnecting once when the current connection is broken.
+func (b *SinkBackend) write(e *audit.Event) error {
+	msg, err := b.formatter.Format(e)
+	if err != nil {
+		return err
+	}
+	msg = b.frame(msg)
+	for attempt := 0; attempt < 2; attempt++ {
+		if b.conn == nil {
+			if b.conn, err = b.dial(); err != nil {
+				continue
+			}
+		}
+		_ = b.conn.SetWriteDeadline(time.Now().Add(sinkWriteTimeout))
+		if _, err = b.conn.Write(msg); err == nil {
+			return nil
+		}
+		b.close()
+	}
+	return err
+}
+
+// frame delimits messages on stream transports, rfc5424 uses octet counting as described in RFC 6587.
+func (b *SinkBackend) frame(msg []byte) []byte {
+	switch {
+	case b.opts.Transport == TransportUDP:
+		return msg
+	case b.opts.Format == FormatRFC5424:
+		return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	default:
+		return append(msg, '\n')
+	}
+}
+
+func (b *SinkBackend) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: sinkDialTimeout}
+	if b.opts.Transport == TransportTLS {
+		return tls.DialWithDialer(dialer, "tcp", b.opts.Address, b.tlsConfig)
+	}
+	return dialer.Dial(b.opts.Transport, b.opts.Address)
+}
+
+func (b *SinkBackend) close() {
+	if b.conn != nil {
+		_ = b.conn.Close()
+		b.conn = nil
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package auditing
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSinkBackendTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	b, err := NewSinkBackend(SinkOptions{
+		Name:      "test",
+		Format:    FormatRFC5424,
+		Transport: TransportTCP,
+		Address:   ln.Addr().String(),
+	}, stopCh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// read-only requests are not forwarded by default
+	get := newTestEvent(200)
+	get.Verb = "get"
+	b.SendEvent(*get)
+	b.SendEvent(*newTestEvent(200))
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	// octet counting framing, the length is followed by the syslog message
+	length, err := r.ReadString(' ')
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(length))
+	if err != nil {
+		t.Fatalf("invalid frame length %q", length)
+	}
+	msg := make([]byte, n)
+	if _, err = io.ReadFull(r, msg); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(msg), "<110>1 ") || !strings.Contains(string(msg), `verb="create"`) {
+		t.Errorf("unexpected message %q", msg)
+	}
+}
@@ -35,6 +35,7 @@ type AttributesRecord struct {
 	Name            string
 	ResourceRequest bool
 	Path            string
+	Project         string
 }
 
 func (a *AttributesRecord) GetVerb() string {
@@ -69,6 +70,10 @@ func (a *AttributesRecord) GetPath() string {
 	return a.Path
 }
 
+func (a *AttributesRecord) GetProject() string {
+	return a.Project
+}
+
 func (a *AttributesRecord) GetUser() user.Info {
 	return a.User
 }
@@ -75,6 +75,9 @@ type Attributes interface {
 
 	// GetPath returns the path of the request
 	GetPath() string
+
+	// GetProject returns the project the request is scoped to, empty for requests across all projects.
+	GetProject() string
 }
 
 type Authorizer interface {
@@ -22,6 +22,7 @@ import (
 
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	v12 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
 
 	"github.com/open-policy-agent/opa/rego"
@@ -54,6 +55,21 @@ var (
 	_ authorizer.Authorizer = (*Authorizer)(nil)
 )
 
+// projectScopedResources are the resources the project scope filter limits to a single project,
+// a binding labeled with a project never grants access to anything else.
+var projectScopedResources = map[string][]string{
+	corev1.GroupName: {"clusters", "nodes", "backups", "operations", "events"},
+	v12.GroupName:    {"projects"},
+}
+
+// inProjectScope reports whether a binding labeled with the project applies to the request.
+func inProjectScope(requestAttributes authorizer.Attributes, project string) bool {
+	if !requestAttributes.IsResourceRequest() || project != requestAttributes.GetProject() {
+		return false
+	}
+	return sliceutil.HasString(projectScopedResources[requestAttributes.GetAPIGroup()], requestAttributes.GetResource())
+}
+
 func NewAuthorizer(am iam.Operator) authorizer.Authorizer {
 	return &Authorizer{am: am}
 }
@@ -116,6 +132,10 @@ func (a *Authorizer) visitRulesFor(requestAttributes authorizer.Attributes, visi
 	} else {
 		sourceDescriber := &globalRoleBindingDescriber{}
 		for _, globalRoleBinding := range globalRoleBindings.Items {
+			// a binding labeled with a project grants its role to the requests made in that project only.
+			if project, ok := globalRoleBinding.Labels[v1.LabelProject]; ok && !inProjectScope(requestAttributes, project) {
+				continue
+			}
 			subjectIndex, applies := appliesTo(requestAttributes.GetUser(), globalRoleBinding.Subjects, "")
 			if !applies {
 				continue
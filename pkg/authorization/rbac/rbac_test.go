@@ -48,6 +48,12 @@ var (
 	reqLogout, _     = http.NewRequest("GET", "/oauth/logout", nil)
 	reqOauth, _      = http.NewRequest("POST", "/oauth/token", nil)
 
+	// project scoped API
+	reqListTeamAClusters, _  = http.NewRequest("GET", "/api/core.kubeclipper.io/v1/clusters?project=team-a", nil)
+	reqListTeamBClusters, _  = http.NewRequest("GET", "/api/core.kubeclipper.io/v1/clusters?project=team-b", nil)
+	reqUpdateTeamAUser, _    = http.NewRequest("PUT", "/api/iam.kubeclipper.io/v1/users/admin?project=team-a", nil)
+	reqCreateTeamABinding, _ = http.NewRequest("POST", "/api/iam.kubeclipper.io/v1/globalrolebindings?project=team-a", nil)
+
 	// cluster API
 	reqListClusters, _   = http.NewRequest("GET", "/api/core.kubeclipper.io/v1/clusters", nil)
 	reqCreateClusters, _ = http.NewRequest("POST", "/api/core.kubeclipper.io/v1/clusters", nil)
@@ -110,6 +116,10 @@ var (
 		Name:   "clustermanager",
 		Groups: []string{user.AllAuthenticated},
 	}
+	userTeamMember = &user.DefaultInfo{
+		Name:   "teammember",
+		Groups: []string{user.AllAuthenticated},
+	}
 	userPlatformView = &user.DefaultInfo{
 		Name:   "view",
 		UID:    "",
@@ -399,6 +409,50 @@ func setupIAMMock(op *iammock.MockOperator) {
 						},
 					},
 				},
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "GlobalRoleBinding",
+						APIVersion: "core.kubeclipper.io/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "team-a:cluster-manager",
+						Labels: map[string]string{v1.LabelProject: "team-a"},
+					},
+					RoleRef: rbacv1.RoleRef{
+						APIGroup: "core.kubeclipper.io",
+						Kind:     "GlobalRole",
+						Name:     "cluster-manager",
+					},
+					Subjects: []rbacv1.Subject{
+						{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "User",
+							Name:     "teammember",
+						},
+					},
+				},
+				{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "GlobalRoleBinding",
+						APIVersion: "core.kubeclipper.io/v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "team-a:platform-admin",
+						Labels: map[string]string{v1.LabelProject: "team-a"},
+					},
+					RoleRef: rbacv1.RoleRef{
+						APIGroup: "core.kubeclipper.io",
+						Kind:     "GlobalRole",
+						Name:     "platform-admin",
+					},
+					Subjects: []rbacv1.Subject{
+						{
+							APIGroup: "rbac.authorization.k8s.io",
+							Kind:     "User",
+							Name:     "teammember",
+						},
+					},
+				},
 			},
 		},
 		nil).AnyTimes()
@@ -1505,6 +1559,52 @@ func TestAuthorizer_Authorize(t *testing.T) {
 			want:    authorizer.DecisionNoOpinion,
 			wantErr: false,
 		},
+		// Project
+		{
+			name: "TeamMember ListClusters in own project",
+			args: args{
+				user: userTeamMember,
+				req:  reqListTeamAClusters,
+			},
+			want:    authorizer.DecisionAllow,
+			wantErr: false,
+		},
+		{
+			name: "TeamMember ListClusters in other project",
+			args: args{
+				user: userTeamMember,
+				req:  reqListTeamBClusters,
+			},
+			want:    authorizer.DecisionNoOpinion,
+			wantErr: false,
+		},
+		{
+			name: "TeamMember ListClusters across projects",
+			args: args{
+				user: userTeamMember,
+				req:  reqListClusters,
+			},
+			want:    authorizer.DecisionNoOpinion,
+			wantErr: false,
+		},
+		{
+			name: "TeamMember UpdateUser in own project",
+			args: args{
+				user: userTeamMember,
+				req:  reqUpdateTeamAUser,
+			},
+			want:    authorizer.DecisionNoOpinion,
+			wantErr: false,
+		},
+		{
+			name: "TeamMember CreateGlobalRoleBinding in own project",
+			args: args{
+				user: userTeamMember,
+				req:  reqCreateTeamABinding,
+			},
+			want:    authorizer.DecisionNoOpinion,
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1542,6 +1642,7 @@ func getAuthorizerAttributes(userInfo user.Info, reqInfo *request.Info) *authori
 	attribs.Resource = reqInfo.Resource
 	attribs.Subresource = reqInfo.Subresource
 	attribs.Name = reqInfo.Name
+	attribs.Project = reqInfo.Project
 
 	return &attribs
 }
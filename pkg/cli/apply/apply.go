@@ -0,0 +1,258 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package apply
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	apierror "github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Apply a cluster configuration from a file.
+
+  The cluster is created if it does not exist, otherwise the server compares the file with the existing cluster
+  and only the changed parts are applied, so re-applying an unchanged file is a no-op.
+
+  Supported changes of an existing cluster are labels, annotations, worker nodes, components and kubernetes version.
+  Labels and annotations are merged with the existing ones.
+  A component whose version or config is changed in place is upgraded instead of reinstalled.
+  Each change of worker nodes, components and kubernetes version starts an operation, only one operation is
+  started by once apply, please run apply again after the operation finished to apply the remaining changes.`
+	applyExample = `
+  # Apply the cluster in cluster.yaml
+  kcctl apply -f cluster.yaml
+
+  # Show what would be changed without applying it
+  kcctl apply -f cluster.yaml --dry-run
+
+  Please read 'kcctl apply -h' get more apply flags.`
+)
+
+type ApplyOptions struct {
+	options.IOStreams
+	cliOpts  *options.CliOptions
+	client   *kc.Client
+	Filename string
+	DryRun   bool
+}
+
+func NewApplyOptions(streams options.IOStreams) *ApplyOptions {
+	return &ApplyOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+	}
+}
+
+func NewCmdApply(streams options.IOStreams) *cobra.Command {
+	o := NewApplyOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "apply (--filename | -f <FILE-NAME>)",
+		DisableFlagsInUseLine: true,
+		Short:                 "Apply a cluster configuration from a file",
+		Long:                  longDescription,
+		Example:               applyExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.RunApply())
+		},
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().StringVarP(&o.Filename, "filename", "f", o.Filename, "cluster manifest file, in yaml or json format")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "only print the changes which would be applied")
+	utils.CheckErr(cmd.MarkFlagRequired("filename"))
+	return cmd
+}
+
+func (o *ApplyOptions) Complete() error {
+	if err := o.cliOpts.Complete(); err != nil {
+		return err
+	}
+	c, err := o.cliOpts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
+
+func (o *ApplyOptions) ValidateArgs(cmd *cobra.Command) error {
+	if o.Filename == "" {
+		return utils.UsageErrorf(cmd, "--filename must be specified")
+	}
+	return nil
+}
+
+func (o *ApplyOptions) RunApply() error {
+	desired, err := readCluster(o.Filename)
+	if err != nil {
+		return err
+	}
+	ctx := context.TODO()
+	if _, err = o.client.DescribeCluster(ctx, desired.Name); err != nil {
+		if !apierror.IsNotFound(err) {
+			return err
+		}
+		if o.DryRun {
+			_, _ = fmt.Fprintf(o.Out, "cluster/%s created (dry run)\n", desired.Name)
+			return nil
+		}
+		if _, err = o.client.CreateCluster(ctx, desired); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(o.Out, "cluster/%s created\n", desired.Name)
+		return nil
+	}
+
+	diff, err := o.client.DiffCluster(ctx, desired)
+	if err != nil {
+		return err
+	}
+	if len(diff.Forbidden) > 0 {
+		return fmt.Errorf("cluster/%s: fields %s can not be changed for an existing cluster",
+			desired.Name, strings.Join(diff.Forbidden, ", "))
+	}
+	if diff.Unchanged {
+		_, _ = fmt.Fprintf(o.Out, "cluster/%s unchanged\n", desired.Name)
+		return nil
+	}
+	if o.DryRun {
+		return o.printDiff(desired.Name, diff)
+	}
+	return o.applyDiff(ctx, desired, diff)
+}
+
+func (o *ApplyOptions) applyDiff(ctx context.Context, desired *v1.Cluster, diff *v1.ClusterDiff) error {
+	if len(diff.Labels) > 0 || len(diff.Annotations) > 0 {
+		list, err := o.client.DescribeCluster(ctx, desired.Name)
+		if err != nil {
+			return err
+		}
+		current := &list.Items[0]
+		current.Labels = mergeMap(current.Labels, diff.Labels)
+		current.Annotations = mergeMap(current.Annotations, diff.Annotations)
+		if err = o.client.UpdateCluster(ctx, current); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(o.Out, "cluster/%s metadata configured\n", desired.Name)
+	}
+
+	action, err := o.applyOperation(ctx, desired, diff)
+	if err != nil {
+		return err
+	}
+	if action != "" {
+		_, _ = fmt.Fprintf(o.Out, "cluster/%s configured, operation to %s started\n", desired.Name, action)
+	}
+	return nil
+}
+
+// applyOperation starts the first operation of diff, cluster can run only one operation at the same time.
+func (o *ApplyOptions) applyOperation(ctx context.Context, desired *v1.Cluster, diff *v1.ClusterDiff) (string, error) {
+	name := desired.Name
+	switch {
+	case len(diff.RemoveWorkers) > 0:
+		return "remove workers", o.client.PatchClusterNodes(ctx, name, &kc.PatchNodes{
+			Operation: kc.NodesOperationRemove,
+			Nodes:     diff.RemoveWorkers,
+			Role:      common.NodeRoleWorker,
+		})
+	case len(diff.AddWorkers) > 0:
+		return "add workers", o.client.PatchClusterNodes(ctx, name, &kc.PatchNodes{
+			Operation: kc.NodesOperationAdd,
+			Nodes:     diff.AddWorkers,
+			Role:      common.NodeRoleWorker,
+		})
+	case len(diff.UninstallComponents) > 0:
+		return "uninstall components", o.client.PatchClusterComponents(ctx, name, &kc.PatchComponents{
+			Uninstall:  true,
+			Components: diff.UninstallComponents,
+		})
+	case len(diff.InstallComponents) > 0:
+		return "install components", o.client.PatchClusterComponents(ctx, name, &kc.PatchComponents{
+			Uninstall:  false,
+			Components: diff.InstallComponents,
+		})
+	case len(diff.UpgradeComponents) > 0:
+		return "upgrade components", o.client.UpgradeClusterComponents(ctx, name, &kc.UpgradeComponents{
+			Components: diff.UpgradeComponents,
+		})
+	case diff.KubernetesVersion != "":
+		return "upgrade kubernetes", o.client.UpgradeCluster(ctx, name, &kc.ClusterUpgrade{
+			Version:       diff.KubernetesVersion,
+			Offline:       desired.Kubeadm.Offline,
+			LocalRegistry: desired.Kubeadm.LocalRegistry,
+		})
+	}
+	return "", nil
+}
+
+func (o *ApplyOptions) printDiff(name string, diff *v1.ClusterDiff) error {
+	data, err := yaml.Marshal(diff)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(o.Out, "cluster/%s would be configured (dry run):\n%s", name, data)
+	return nil
+}
+
+func readCluster(filename string) (*v1.Cluster, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := &v1.Cluster{}
+	if err = yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("parse cluster manifest %s failed: %v", filename, err)
+	}
+	if c.Kind != "" && c.Kind != "Cluster" {
+		return nil, fmt.Errorf("unsupported kind %s, only Cluster can be applied", c.Kind)
+	}
+	if c.Name == "" {
+		return nil, fmt.Errorf("metadata.name of cluster must be specified")
+	}
+	if c.Kubeadm == nil {
+		return nil, fmt.Errorf("kubeadm of cluster must be specified")
+	}
+	return c, nil
+}
+
+func mergeMap(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
@@ -129,6 +129,7 @@ func (c *CleanOptions) cleanKcAgent() {
 	cmdList := []string{
 		"systemctl disable kc-agent --now",
 		"rm -rf /usr/lib/systemd/system/kc-agent.service",
+		fmt.Sprintf("rm -rf %s", options.SystemdDropInDir(options.UnitKcAgent)),
 		"rm -rf /etc/kubeclipper-agent",
 		fmt.Sprintf("rm -rf %s", c.deployConfig.OpLog.Dir),
 		"systemctl reset-failed kc-agent || true",
@@ -152,6 +153,7 @@ func (c *CleanOptions) cleanKcServer() {
 		"rm -rf /usr/lib/systemd/system/kc-server.service",
 		"systemctl disable kc-etcd --now",
 		"rm -rf /usr/lib/systemd/system/kc-etcd.service",
+		fmt.Sprintf("rm -rf %s %s", options.SystemdDropInDir(options.UnitKcServer), options.SystemdDropInDir(options.UnitKcEtcd)),
 		"rm -rf /etc/kubeclipper-server",
 		fmt.Sprintf("rm -rf %s", c.deployConfig.EtcdConfig.DataDir),
 		fmt.Sprintf("rm -rf %s", c.deployConfig.StaticServerPath),
@@ -0,0 +1,219 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	// cacheTTL is short, the shell asks for completions on every tab and the names rarely change in between.
+	cacheTTL = 30 * time.Second
+	// requestTimeout keeps the shell responsive when the server is slow or unreachable.
+	requestTimeout = 3 * time.Second
+)
+
+// CacheDir holds the names listed for completion, in a directory per server.
+var CacheDir = filepath.Join(options.HomeDIR, options.DefaultPath, "cache", "completion")
+
+type cacheEntry struct {
+	Time  time.Time `json:"time"`
+	Names []string  `json:"names"`
+}
+
+// Clusters completes cluster names.
+func Clusters(cli *kc.Client, toComplete string) []string {
+	return complete(cli, "clusters", toComplete, func(ctx context.Context) ([]string, error) {
+		list, err := cli.ListClusters(ctx, kc.Queries(*query.New()))
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(list.Items))
+		for _, c := range list.Items {
+			names = append(names, c.Name)
+		}
+		return names, nil
+	})
+}
+
+// Nodes completes node names, which are their ids.
+func Nodes(cli *kc.Client, toComplete string) []string {
+	return complete(cli, "nodes", toComplete, func(ctx context.Context) ([]string, error) {
+		return nodes(ctx, cli, "", func(ip, name string) string { return name })
+	})
+}
+
+// NodeIPs completes the ips of the nodes, or of the nodes not in a cluster when free is set.
+func NodeIPs(cli *kc.Client, free bool, toComplete string) []string {
+	kind, selector := "node-ips", ""
+	if free {
+		kind, selector = "free-node-ips", fmt.Sprintf("!%s", common.LabelNodeRole)
+	}
+	return complete(cli, kind, toComplete, func(ctx context.Context) ([]string, error) {
+		return nodes(ctx, cli, selector, func(ip, name string) string { return ip })
+	})
+}
+
+func nodes(ctx context.Context, cli *kc.Client, selector string, key func(ip, name string) string) ([]string, error) {
+	q := query.New()
+	q.LabelSelector = selector
+	list, err := cli.ListNodes(ctx, kc.Queries(*q))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, n := range list.Items {
+		names = append(names, key(n.Status.Ipv4DefaultIP, n.Name))
+	}
+	return names, nil
+}
+
+// Regions completes region names.
+func Regions(cli *kc.Client, toComplete string) []string {
+	return complete(cli, "regions", toComplete, func(ctx context.Context) ([]string, error) {
+		list, err := cli.ListRegions(ctx, kc.Queries(*query.New()))
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(list.Items))
+		for _, r := range list.Items {
+			names = append(names, r.Name)
+		}
+		return names, nil
+	})
+}
+
+// Users completes user names.
+func Users(cli *kc.Client, toComplete string) []string {
+	return complete(cli, "users", toComplete, func(ctx context.Context) ([]string, error) {
+		list, err := cli.ListUsers(ctx, kc.Queries(*query.New()))
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(list.Items))
+		for _, u := range list.Items {
+			names = append(names, u.Name)
+		}
+		return names, nil
+	})
+}
+
+// Roles completes the names of the roles which may be bound, role templates and hidden roles are left out.
+func Roles(cli *kc.Client, toComplete string) []string {
+	return complete(cli, "roles", toComplete, func(ctx context.Context) ([]string, error) {
+		q := query.New()
+		q.LabelSelector = fmt.Sprintf("!%s,!%s", common.LabelRoleTemplate, common.LabelHidden)
+		list, err := cli.ListRoles(ctx, kc.Queries(*q))
+		if err != nil {
+			return nil, err
+		}
+		names := make([]string, 0, len(list.Items))
+		for _, r := range list.Items {
+			names = append(names, r.Name)
+		}
+		return names, nil
+	})
+}
+
+// Versions completes the versions of the offline resources of the component, e.g. k8s or containerd.
+func Versions(cli *kc.Client, component, toComplete string) []string {
+	return complete(cli, "versions-"+component, toComplete, func(ctx context.Context) ([]string, error) {
+		metas, err := cli.GetComponentMeta(ctx)
+		if err != nil {
+			return nil, err
+		}
+		var versions []string
+		for _, resource := range metas.Items {
+			if resource.Name == component {
+				versions = append(versions, resource.Version)
+			}
+		}
+		return versions, nil
+	})
+}
+
+// complete returns the names of kind starting with toComplete. The names are taken from the
+// cache of the server while it is fresh, and listed from the server otherwise.
+func complete(cli *kc.Client, kind, toComplete string, list func(ctx context.Context) ([]string, error)) []string {
+	file := filepath.Join(CacheDir, cacheKey(cli.Host()), kind+".json")
+	names, ok := readCache(file)
+	if !ok {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+		var err error
+		if names, err = list(ctx); err != nil {
+			logger.V(2).Infof("list %s for completion failed: %v", kind, err)
+			return nil
+		}
+		writeCache(file, names)
+	}
+	set := sets.NewString()
+	for _, name := range names {
+		if name != "" && strings.HasPrefix(name, toComplete) {
+			set.Insert(name)
+		}
+	}
+	return set.List()
+}
+
+func readCache(file string) ([]string, bool) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if err = json.Unmarshal(data, &e); err != nil || time.Since(e.Time) > cacheTTL {
+		return nil, false
+	}
+	return e.Names, true
+}
+
+// writeCache is best effort, completion works without the cache, only slower.
+func writeCache(file string, names []string) {
+	data, err := json.Marshal(cacheEntry{Time: time.Now(), Names: names})
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		logger.V(2).Infof("create completion cache directory failed: %v", err)
+		return
+	}
+	if err = os.WriteFile(file, data, 0600); err != nil {
+		logger.V(2).Infof("write completion cache failed: %v", err)
+	}
+}
+
+// cacheKey turns the server address into a directory name, so that servers do not share names.
+func cacheKey(host string) string {
+	return strings.NewReplacer("/", "_", ":", "_").Replace(host)
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package completion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+func TestComplete(t *testing.T) {
+	CacheDir = t.TempDir()
+	cli, err := kc.NewClientWithOpts(kc.WithHost("http://10.0.0.1:8080"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	list := func(ctx context.Context) ([]string, error) {
+		calls++
+		return []string{"demo", "dev", "prod"}, nil
+	}
+
+	if got := complete(cli, "clusters", "de", list); !reflect.DeepEqual(got, []string{"demo", "dev"}) {
+		t.Errorf("complete() = %v", got)
+	}
+	if got := complete(cli, "clusters", "p", list); !reflect.DeepEqual(got, []string{"prod"}) || calls != 1 {
+		t.Errorf("complete() = %v with %d calls, the second completion must be served from the cache", got, calls)
+	}
+
+	file := filepath.Join(CacheDir, "10.0.0.1_8080", "clusters.json")
+	stale, _ := json.Marshal(cacheEntry{Time: time.Now().Add(-2 * cacheTTL), Names: []string{"old"}})
+	if err = os.WriteFile(file, stale, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if got := complete(cli, "clusters", "", list); len(got) != 3 || calls != 2 {
+		t.Errorf("complete() = %v with %d calls, a stale cache must be listed again", got, calls)
+	}
+
+	failing := func(ctx context.Context) ([]string, error) { return nil, errors.New("unreachable") }
+	if got := complete(cli, "nodes", "", failing); got != nil {
+		t.Errorf("complete() = %v, want nothing when the server fails", got)
+	}
+}
@@ -19,6 +19,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 
 	"gopkg.in/yaml.v2"
@@ -53,6 +54,9 @@ type Config struct {
 	AuthInfos      map[string]*AuthInfo `json:"users" yaml:"users"`
 	CurrentContext string               `json:"current-context" yaml:"current-context"`
 	Contexts       map[string]*Context  `json:"contexts" yaml:"contexts"`
+
+	// credentialsPath is the path of the token cache file, which is next to the config file.
+	credentialsPath string
 }
 
 func New() *Config {
@@ -68,14 +72,32 @@ func TryLoadFromFile(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, err
 	}
+	cfg.credentialsPath = CredentialsPathFor(path)
 
 	return cfg, nil
 }
 
-func (c Config) ToKcClient() (*kc.Client, error) {
-	ctx := c.Contexts[c.CurrentContext]
+// CurrentUser returns the user of the current context, who logged in last.
+func (c Config) CurrentUser() string {
+	if ctx, ok := c.Contexts[c.CurrentContext]; ok {
+		return ctx.AuthInfo
+	}
+	return ""
+}
 
-	return kc.NewClientWithOpts(kc.WithHost(c.Servers[ctx.Server].Server),
-		kc.WithScheme("http"),
-		kc.WithBearerAuth(c.AuthInfos[ctx.AuthInfo].Token))
+func (c Config) ToKcClient() (*kc.Client, error) {
+	ctx, ok := c.Contexts[c.CurrentContext]
+	if !ok || ctx == nil {
+		return nil, fmt.Errorf("context %s not found, please run 'kcctl login' first", c.CurrentContext)
+	}
+	server, ok := c.Servers[ctx.Server]
+	if !ok || server == nil {
+		return nil, fmt.Errorf("server %s of context %s not found", ctx.Server, c.CurrentContext)
+	}
+	token, err := c.token(c.CurrentContext, ctx, server)
+	if err != nil {
+		return nil, err
+	}
+	return kc.NewClientWithOpts(kc.WithHost(server.Server),
+		kc.WithBearerAuth(token))
 }
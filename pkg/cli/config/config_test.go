@@ -0,0 +1,37 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package config
+
+import "testing"
+
+func TestConfig_CurrentUser(t *testing.T) {
+	c := Config{
+		CurrentContext: "admin@default",
+		Contexts: map[string]*Context{
+			"admin@default": {AuthInfo: "admin", Server: "default"},
+		},
+	}
+	if got := c.CurrentUser(); got != "admin" {
+		t.Errorf("CurrentUser() = %q, want %q", got, "admin")
+	}
+	c.CurrentContext = "foo@default"
+	if got := c.CurrentUser(); got != "" {
+		t.Errorf("CurrentUser() of a missing context = %q, want empty", got)
+	}
+}
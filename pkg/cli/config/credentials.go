@@ -0,0 +1,151 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/client-go/util/homedir"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	DefaultCredentialsFile = "credentials"
+	// refresh the access token a little earlier than it expires,
+	// to avoid it expiring in flight.
+	expirySkew = 30 * time.Second
+)
+
+// Credential is the cached token of a context.
+type Credential struct {
+	AccessToken  string    `json:"access-token" yaml:"access-token"`
+	RefreshToken string    `json:"refresh-token,omitempty" yaml:"refresh-token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty" yaml:"expiry,omitempty"`
+}
+
+// NewCredential builds credential from the token issued by kubeclipper server.
+func NewCredential(token *oauth.Token) *Credential {
+	c := &Credential{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+	}
+	if token.ExpiresIn > 0 {
+		c.Expiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return c
+}
+
+// Expired reports whether the access token is expired, token without expiry never expires.
+func (c *Credential) Expired() bool {
+	if c.Expiry.IsZero() {
+		return false
+	}
+	return time.Now().Add(expirySkew).After(c.Expiry)
+}
+
+// Credentials stores tokens by context name, it is saved with file mode 0600
+// since it contains secrets.
+type Credentials struct {
+	Tokens map[string]*Credential `json:"tokens" yaml:"tokens"`
+}
+
+// CredentialsPathFor returns the credentials file path which stores along with the config file.
+func CredentialsPathFor(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), DefaultCredentialsFile)
+}
+
+// LoadCredentials loads credentials from path, an empty credentials is returned if the file not exist.
+func LoadCredentials(path string) (*Credentials, error) {
+	c := &Credentials{Tokens: make(map[string]*Credential)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	if c.Tokens == nil {
+		c.Tokens = make(map[string]*Credential)
+	}
+	return c, nil
+}
+
+// Save writes credentials to path with file mode 0600.
+func (c *Credentials) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	// WriteFile does not change the mode of existing file
+	return os.Chmod(path, 0600)
+}
+
+// token returns the access token of context, the cached token will be refreshed if it expired.
+func (c Config) token(ctxName string, ctx *Context, server *Server) (string, error) {
+	if auth, ok := c.AuthInfos[ctx.AuthInfo]; ok && auth != nil && auth.Token != "" {
+		return auth.Token, nil
+	}
+	path := c.credentialsPath
+	if path == "" {
+		path = filepath.Join(homedir.HomeDir(), DefaultConfigPath, DefaultCredentialsFile)
+	}
+	credentials, err := LoadCredentials(path)
+	if err != nil {
+		return "", err
+	}
+	cred, ok := credentials.Tokens[ctxName]
+	if !ok || cred.AccessToken == "" {
+		return "", fmt.Errorf("no credentials found for context %s, please run 'kcctl login' first", ctxName)
+	}
+	if !cred.Expired() {
+		return cred.AccessToken, nil
+	}
+	if cred.RefreshToken == "" {
+		return "", fmt.Errorf("token of context %s is expired, please run 'kcctl login' again", ctxName)
+	}
+	cli, err := kc.NewClientWithOpts(kc.WithHost(server.Server))
+	if err != nil {
+		return "", err
+	}
+	token, err := cli.RefreshToken(context.TODO(), cred.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("refresh token of context %s failed, please run 'kcctl login' again: %v", ctxName, err)
+	}
+	credentials.Tokens[ctxName] = NewCredential(token)
+	if err = credentials.Save(path); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
@@ -0,0 +1,97 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
+)
+
+func TestCredentials_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".kc", DefaultCredentialsFile)
+
+	c, err := LoadCredentials(path)
+	if err != nil {
+		t.Fatalf("LoadCredentials() on not exist file error = %v", err)
+	}
+	if len(c.Tokens) != 0 {
+		t.Fatalf("LoadCredentials() on not exist file got %d tokens, want 0", len(c.Tokens))
+	}
+
+	c.Tokens["admin@default"] = NewCredential(&oauth.Token{AccessToken: "foo", RefreshToken: "bar", ExpiresIn: 3600})
+	if err = c.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("credentials file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	loaded, err := LoadCredentials(path)
+	if err != nil {
+		t.Fatalf("LoadCredentials() error = %v", err)
+	}
+	got := loaded.Tokens["admin@default"]
+	if got == nil || got.AccessToken != "foo" || got.RefreshToken != "bar" {
+		t.Errorf("LoadCredentials() got %+v", got)
+	}
+}
+
+func TestCredential_Expired(t *testing.T) {
+	tests := []struct {
+		name   string
+		expiry time.Time
+		want   bool
+	}{
+		{
+			name: "never expire",
+			want: false,
+		},
+		{
+			name:   "not expired",
+			expiry: time.Now().Add(time.Hour),
+			want:   false,
+		},
+		{
+			name:   "expire soon",
+			expiry: time.Now().Add(time.Second),
+			want:   true,
+		},
+		{
+			name:   "expired",
+			expiry: time.Now().Add(-time.Hour),
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Credential{AccessToken: "foo", Expiry: tt.expiry}
+			if got := c.Expired(); got != tt.want {
+				t.Errorf("Expired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
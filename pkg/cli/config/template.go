@@ -148,6 +148,26 @@ KillMode=process
 [Install]
 WantedBy=multi-user.target`
 
+// KcAgentWindowsDir is where kcctl join puts the agent binary, config and certs on windows
+// nodes, the agent looks for its config there.
+const KcAgentWindowsDir = `C:\ProgramData\kubeclipper-agent`
+
+// KcAgentWindowsServiceTmpl is the PowerShell script registering and starting the agent
+// as a windows service, which is restarted by the service control manager when it fails.
+const KcAgentWindowsServiceTmpl = `$bin = Join-Path {{psquote .Dir}} 'kubeclipper-agent.exe'
+New-Service -Name 'kc-agent' -DisplayName 'kubeclipper-agent' -StartupType Automatic -BinaryPathName ('"{0}" serve' -f $bin) | Out-Null
+sc.exe failure 'kc-agent' reset= 86400 actions= restart/5000/restart/5000/restart/5000 | Out-Null
+Start-Service -Name 'kc-agent'`
+
+// KcAgentWindowsCheckTmpl exits with 1 when the agent service is registered already.
+const KcAgentWindowsCheckTmpl = `if (Get-Service -Name 'kc-agent' -ErrorAction SilentlyContinue) { exit 1 }
+New-Item -ItemType Directory -Force -Path {{psquote .Dir}} | Out-Null`
+
+// KcAgentWindowsNodeIDTmpl prints the identity of a windows node the agent id is derived from,
+// the MachineGuid stands for the machine-id of linux.
+const KcAgentWindowsNodeIDTmpl = `{{if eq .Source "smbios-uuid"}}(Get-CimInstance -ClassName Win32_ComputerSystemProduct).UUID
+{{- else}}(Get-ItemProperty -Path 'HKLM:\SOFTWARE\Microsoft\Cryptography' -Name MachineGuid).MachineGuid{{end}}`
+
 const KcServerConfigTmpl = `generic:
   bindAddress: {{.ServerAddress}}
   insecurePort: {{.ServerPort}}
@@ -195,6 +215,9 @@ etcd:
   enableWatchCache: true
   defaultWatchCacheSize: 100
   #watchCacheSizes
+  cachedReadResources:
+    - nodes
+    - clusters
 mq:
   external: {{.MQExternal}}
   client:
@@ -238,12 +261,21 @@ mq:
 
 const KcAgentConfigTmpl = `agentID: {{.AgentID}}
 region: {{.Region}}
+{{- if .ExternalID}}
+externalID: {{printf "%q" .ExternalID}}
+{{- end}}
 registerNode: true
 nodeStatusUpdateFrequency: 1m
 downloader:
   address: {{.StaticServerAddress}}
   tlsCertFile: ""
   tlsPrivateKey: ""
+{{- if .TrustedKeys}}
+  trustedKeys:
+  {{- range .TrustedKeys}}
+  - {{printf "%q" .}}
+  {{- end}}
+{{- end}}
 log:
   logFile: ""
   logFileMaxSizeMB: 100
@@ -40,13 +40,14 @@ TODO..
 Available Commands:
   cluster     create kubeclipper cluster resource
   role        create kubeclipper role resource
+  rolebinding bind kubeclipper user to role
   user        create kubeclipper role resource
 
 Flags:
   -c, --config string     Path to the config file to use for CLI requests.
   -f, --filename string   use resource file to create
   -h, --help              help for create
-  -o, --output string     Output format either: json,yaml,table (default "table")
+  -o, --output string     Output format either: json,yaml,table,wide (default "table")
 
 Use "kcctl create [command] --help" for more information about a command.
 */
@@ -55,7 +56,7 @@ const (
 	longDescription = `
   Create specified resource
 
-  Using the create command to create cluster, user, or role resources.
+  Using the create command to create cluster, user, role or role binding resources.
   Or you can choose to create those directly from a file.`
 	createExample = `
   # Using config file to create resource
@@ -105,6 +106,7 @@ func NewCmdCreate(streams options.IOStreams) *cobra.Command {
 	cmd.AddCommand(NewCmdCreateCluster(streams))
 	cmd.AddCommand(NewCmdCreateRole(streams))
 	cmd.AddCommand(NewCmdCreateUser(streams))
+	cmd.AddCommand(NewCmdCreateRoleBinding(streams))
 	return cmd
 }
 
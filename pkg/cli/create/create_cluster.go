@@ -20,12 +20,15 @@ package create
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"os"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
 
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 
@@ -42,6 +45,7 @@ import (
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 )
@@ -62,9 +66,42 @@ const (
   # Create cluster online
   kcctl create cluster --name demo --master 192.168.10.123 --offline false --local-registry 'REGISTRY-ADDRESS'
 
+  # Create cluster with flannel as the cni
+  kcctl create cluster --name demo --master 192.168.10.123 --cni flannel
+
   # Create cluster with taint manage
   kcctl create cluster --name demo --master 192.168.10.123 --untaint-master true
 
+  # Create cluster and retry package downloads up to 3 times
+  kcctl create cluster --name demo --master 192.168.10.123 --step-policy 'Download*:timeout=20m,retries=3,backoff=10s'
+
+  # Create cluster whose cni uses the interface routing to 192.168.10.1 on multi-homed nodes
+  kcctl create cluster --name demo --master 192.168.10.123 --cni-interface can-reach=192.168.10.1
+
+  # Create cluster with three masters behind the virtual ip 192.168.10.100 announced by kube-vip
+  kcctl create cluster --name demo --master 192.168.10.121,192.168.10.122,192.168.10.123 --vip 192.168.10.100
+
+  # Create a dual-stack cluster whose pods and services get ipv4 and ipv6 addresses
+  kcctl create cluster --name demo --master 192.168.10.123 --pod-subnets 172.25.0.0/16,fd00:25::/56 --service-subnets 10.96.0.0/16,fd00:96::/112
+
+  # Create cluster whose nodes reach the internet through a proxy, the cluster subnets and nodes are reached directly
+  kcctl create cluster --name demo --master 192.168.10.123 --offline=false --http-proxy http://10.0.0.1:3128 --no-proxy .corp.example.com
+
+  # Create a k3s cluster for an edge site, k3s runs its embedded containerd and flannel
+  kcctl create cluster --name edge --master 192.168.10.123 --type k3s
+
+  # Create cluster whose upgrades notify a CMDB first and are smoke tested afterwards, hooks.yaml holds e.g.
+  #   - name: notify-cmdb
+  #     phase: Pre
+  #     operations: [UpgradeCluster]
+  #     webhook: {url: 'https://cmdb.example.com/hooks'}
+  #   - name: smoke-test
+  #     phase: Post
+  #     operations: [UpgradeCluster, AddNodes]
+  #     script: {content: 'kubectl get --raw /readyz', target: FirstMaster}
+  #     failurePolicy: Continue
+  kcctl create cluster --name demo --master 192.168.10.123 --hooks-file hooks.yaml
+
   Please read 'kcctl create cluster -h' get more create cluster flags.`
 )
 
@@ -80,12 +117,28 @@ type CreateClusterOptions struct {
 	K8sVersion    string
 	CNI           string
 	Name          string
+	StepPolicies  []string
+	CNIInterface  string
+	HooksFile     string
+	VIP           string
+	VIPMode       string
+	Type          string
+	PodSubnets    []string
+	SvcSubnets    []string
+	HTTPProxy     string
+	HTTPSProxy    string
+	NoProxy       []string
 	createdByIP   bool
+	stepPolicies  []v1.StepPolicy
+	nodeInterface *v1.NodeInterface
+	hooks         []byte
+	vip           *v1.ControlPlaneVIP
 }
 
 var (
-	allowedCRI = sets.NewString("containerd", "docker")
-	allowedCNI = sets.NewString("calico")
+	allowedCRI  = sets.NewString("containerd", "docker")
+	allowedCNI  = sets.NewString("calico", "flannel")
+	allowedType = sets.NewString(v1.ClusterKubeadm.String(), v1.ClusterK3s.String())
 )
 
 func NewCreateClusterOptions(streams options.IOStreams) *CreateClusterOptions {
@@ -99,6 +152,8 @@ func NewCreateClusterOptions(streams options.IOStreams) *CreateClusterOptions {
 		Offline:       true,
 		CRI:           "containerd",
 		CNI:           "calico",
+		VIPMode:       string(v1.ControlPlaneVIPKubeVIP),
+		Type:          v1.ClusterKubeadm.String(),
 		createdByIP:   false,
 	}
 }
@@ -129,6 +184,17 @@ func NewCmdCreateCluster(streams options.IOStreams) *cobra.Command {
 	cmd.Flags().StringVar(&o.CRIVersion, "cri-version", o.CRIVersion, "k8s cri version")
 	cmd.Flags().StringVar(&o.K8sVersion, "k8s-version", o.K8sVersion, "k8s version")
 	cmd.Flags().StringVar(&o.CNI, "cni", o.CNI, "k8s cni type, calico or others")
+	cmd.Flags().StringVar(&o.CNIInterface, "cni-interface", o.CNIInterface, "interface used by the cni on multi-homed nodes, in the form of interface=REGEX, can-reach=ADDRESS or skip-interface=REGEX")
+	cmd.Flags().StringArrayVar(&o.StepPolicies, "step-policy", o.StepPolicies, "override step timeout and retries, in the form of STEP:timeout=10m,retries=3,backoff=10s, STEP may end with *")
+	cmd.Flags().StringVar(&o.HooksFile, "hooks-file", o.HooksFile, "yaml or json file holding the list of hooks run before or after the operations of the cluster")
+	cmd.Flags().StringVar(&o.VIP, "vip", o.VIP, "virtual ip floating between the masters as the stable apiserver endpoint")
+	cmd.Flags().StringVar(&o.Type, "type", o.Type, "k8s distribution of the cluster, kubeadm or k3s")
+	cmd.Flags().StringVar(&o.VIPMode, "vip-mode", o.VIPMode, "how the vip is announced, kube-vip or keepalived")
+	cmd.Flags().StringSliceVar(&o.PodSubnets, "pod-subnets", o.PodSubnets, "pod subnets of a dual-stack or ipv6-only cluster, one per ip family and the primary one first")
+	cmd.Flags().StringSliceVar(&o.SvcSubnets, "service-subnets", o.SvcSubnets, "service subnets, of the ip families of the pod subnets in the same order")
+	cmd.Flags().StringVar(&o.HTTPProxy, "http-proxy", o.HTTPProxy, "http proxy of the container runtime, the kubelet and the package downloads of the nodes")
+	cmd.Flags().StringVar(&o.HTTPSProxy, "https-proxy", o.HTTPSProxy, "https proxy of the nodes, the http proxy by default")
+	cmd.Flags().StringSliceVar(&o.NoProxy, "no-proxy", o.NoProxy, "extra hosts, domains and cidrs reached directly besides the cluster subnets and nodes")
 	o.CliOpts.AddFlags(cmd.Flags())
 	o.PrintFlags.AddFlags(cmd)
 
@@ -147,6 +213,9 @@ func NewCmdCreateCluster(streams options.IOStreams) *cobra.Command {
 	utils.CheckErr(cmd.RegisterFlagCompletionFunc("cni", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return allowedCNI.List(), cobra.ShellCompDirectiveNoFileComp
 	}))
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return allowedType.List(), cobra.ShellCompDirectiveNoFileComp
+	}))
 	utils.CheckErr(cmd.RegisterFlagCompletionFunc("cri-version", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return o.listCRI(toComplete), cobra.ShellCompDirectiveNoFileComp
 	}))
@@ -172,7 +241,7 @@ func (l *CreateClusterOptions) Complete(opts *options.CliOptions) error {
 }
 
 func (l *CreateClusterOptions) PreRun() error {
-	if l.CRIVersion == "" {
+	if l.CRIVersion == "" && l.Type != v1.ClusterK3s.String() {
 		cri := l.listCRI("")
 		if len(cri) == 0 {
 			return errors.New("no valid cri-version")
@@ -192,6 +261,9 @@ func (l *CreateClusterOptions) PreRun() error {
 }
 
 func (l *CreateClusterOptions) ValidateArgs(cmd *cobra.Command) error {
+	if !allowedType.Has(l.Type) {
+		return utils.UsageErrorf(cmd, "unsupported cluster type,support %v now", allowedType.List())
+	}
 	if !allowedCRI.Has(l.CRI) {
 		return utils.UsageErrorf(cmd, "unsupported cri,support %v now", allowedCRI.List())
 	}
@@ -208,9 +280,14 @@ func (l *CreateClusterOptions) ValidateArgs(cmd *cobra.Command) error {
 	if !sliceutil.HasString(k8sVersions, l.K8sVersion) {
 		return utils.UsageErrorf(cmd, "unsupported k8s version,support %v now", k8sVersions)
 	}
-	criVersions := l.listCRI("")
-	if !sliceutil.HasString(criVersions, l.CRIVersion) {
-		return utils.UsageErrorf(cmd, "unsupported cri version,support %v now", criVersions)
+	if err := l.proxy().Validate(); err != nil {
+		return utils.UsageErrorf(cmd, err.Error())
+	}
+	if l.Type != v1.ClusterK3s.String() {
+		criVersions := l.listCRI("")
+		if !sliceutil.HasString(criVersions, l.CRIVersion) {
+			return utils.UsageErrorf(cmd, "unsupported cri version,support %v now", criVersions)
+		}
 	}
 
 	nodes := make([]string, 0)
@@ -227,14 +304,73 @@ func (l *CreateClusterOptions) ValidateArgs(cmd *cobra.Command) error {
 	if pre != nil {
 		l.createdByIP = true
 	}
+	for _, v := range l.StepPolicies {
+		p, err := v1.ParseStepPolicy(v)
+		if err != nil {
+			return utils.UsageErrorf(cmd, err.Error())
+		}
+		l.stepPolicies = append(l.stepPolicies, p)
+	}
+	if l.CNIInterface != "" {
+		n, err := v1.ParseNodeInterface(l.CNIInterface)
+		if err != nil {
+			return utils.UsageErrorf(cmd, err.Error())
+		}
+		l.nodeInterface = n
+	}
+	if l.VIP != "" {
+		vip := &v1.ControlPlaneVIP{Mode: v1.ControlPlaneVIPMode(l.VIPMode), Address: l.VIP}
+		if err := vip.Validate(); err != nil {
+			return utils.UsageErrorf(cmd, err.Error())
+		}
+		l.vip = vip
+	}
+	if l.HooksFile != "" {
+		hooks, err := readHooksFile(l.HooksFile)
+		if err != nil {
+			return utils.UsageErrorf(cmd, err.Error())
+		}
+		l.hooks = hooks
+	}
 	return nil
 }
 
+// readHooksFile returns the hooks of the file as the JSON carried by the cluster annotation.
+func readHooksFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	hooks, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hooks file %s: %v", path, err)
+	}
+	c := &v1.Cluster{}
+	c.Annotations = map[string]string{common.AnnotationOperationHooks: string(hooks)}
+	if _, err = c.OperationHooks(); err != nil {
+		return nil, err
+	}
+	return hooks, nil
+}
+
 func (l *CreateClusterOptions) RunCreate() error {
 	if err := l.transformNodeIP(); err != nil {
 		return err
 	}
 	c := l.newCluster()
+	if len(l.stepPolicies) > 0 {
+		policies, err := json.Marshal(l.stepPolicies)
+		if err != nil {
+			return err
+		}
+		c.Annotations = map[string]string{common.AnnotationStepPolicies: string(policies)}
+	}
+	if l.hooks != nil {
+		if c.Annotations == nil {
+			c.Annotations = map[string]string{}
+		}
+		c.Annotations[common.AnnotationOperationHooks] = string(l.hooks)
+	}
 	// TODO: check node exist
 	resp, err := l.Client.CreateCluster(context.TODO(), c)
 	if err != nil {
@@ -281,7 +417,7 @@ func (l *CreateClusterOptions) transformNodeIP() error {
 
 func (l *CreateClusterOptions) newCluster() *v1.Cluster {
 	c := &v1.Cluster{
-		ClusterType: v1.ClusterKubeadm,
+		ClusterType: v1.ClusterType(l.Type),
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Cluster",
 			APIVersion: "core.kubeclipper.io/v1",
@@ -311,6 +447,7 @@ func (l *CreateClusterOptions) newCluster() *v1.Cluster {
 					PodIPv4CIDR:   "172.25.0.0/24",
 					PodIPv6CIDR:   "",
 					MTU:           1440,
+					NodeInterface: l.nodeInterface,
 					Calico: v1.Calico{
 						IPv4AutoDetection: "first-found",
 						IPv6AutoDetection: "first-found",
@@ -319,11 +456,16 @@ func (l *CreateClusterOptions) newCluster() *v1.Cluster {
 						IPManger:          true,
 						Version:           "v3.21.2",
 					},
+					Flannel: v1.Flannel{
+						Backend: "vxlan",
+						Version: "v0.20.2",
+					},
 				},
 			},
-			Components:    nil,
-			WorkerNodeVip: "169.254.169.100",
-			Offline:       l.Offline,
+			Components:      nil,
+			WorkerNodeVip:   "169.254.169.100",
+			Offline:         l.Offline,
+			ControlPlaneVIP: l.vip,
 		},
 		Status: v1.ClusterStatus{},
 	}
@@ -384,9 +526,29 @@ func (l *CreateClusterOptions) newCluster() *v1.Cluster {
 			},
 		}
 	}
+	if len(l.PodSubnets) > 0 {
+		c.Kubeadm.Networking.PodSubnet, c.Kubeadm.Networking.PodSubnets = "", l.PodSubnets
+		c.Kubeadm.Networking.ServiceSubnet, c.Kubeadm.Networking.ServiceSubnets = "", l.SvcSubnets
+	}
+	c.Kubeadm.Proxy = l.proxy()
+	if c.ClusterType == v1.ClusterK3s {
+		// k3s runs its embedded flannel
+		c.Kubeadm.KubeComponents.CNI.Type = "flannel"
+	}
 	return c
 }
 
+func (l *CreateClusterOptions) proxy() *v1.Proxy {
+	if l.HTTPProxy == "" && l.HTTPSProxy == "" && len(l.NoProxy) == 0 {
+		return nil
+	}
+	return &v1.Proxy{
+		HTTPProxy:  l.HTTPProxy,
+		HTTPSProxy: l.HTTPSProxy,
+		NoProxy:    l.NoProxy,
+	}
+}
+
 func (l *CreateClusterOptions) listCRI(toComplete string) []string {
 	utils.CheckErr(l.Complete(l.CliOpts))
 
@@ -394,34 +556,27 @@ func (l *CreateClusterOptions) listCRI(toComplete string) []string {
 		logger.V(2).Infof("unsupported cri %s,support %s now", l.CRI, allowedCRI)
 		return nil
 	}
-	return l.componentVersions(l.CRI, toComplete)
+	return completion.Versions(l.Client, l.CRI, toComplete)
 }
 
 func (l *CreateClusterOptions) listK8s(toComplete string) []string {
 	utils.CheckErr(l.Complete(l.CliOpts))
 
-	return l.componentVersions("k8s", toComplete)
+	return completion.Versions(l.Client, l.k8sPackage(), toComplete)
 }
 
-func (l *CreateClusterOptions) componentVersions(component, toComplete string) []string {
-	metas, err := l.Client.GetComponentMeta(context.TODO())
-	if err != nil {
-		logger.Errorf("get component meta failed: %s. please check .kc/config", err)
-		return nil
-	}
-	set := sets.NewString()
-	for _, resource := range metas.Items {
-		if resource.Name == component && strings.HasPrefix(resource.Version, toComplete) {
-			set.Insert(resource.Version)
-		}
+// k8sPackage returns the name of the offline package holding the versions of the cluster type.
+func (l *CreateClusterOptions) k8sPackage() string {
+	if l.Type == v1.ClusterK3s.String() {
+		return l.Type
 	}
-	return set.List()
+	return "k8s"
 }
 
 func (l *CreateClusterOptions) listNode(toComplete string, exclude []string) []string {
 	utils.CheckErr(l.Complete(l.CliOpts))
 
-	nodes := l.nodes(toComplete)
+	nodes := completion.NodeIPs(l.Client, true, toComplete)
 	completions := sliceutil.RemoveString(nodes, func(item string) bool {
 		return sliceutil.HasString(exclude, item)
 	})
@@ -447,19 +602,3 @@ func (l *CreateClusterOptions) registry(toComplete string) []string {
 	}
 	return list
 }
-
-func (l *CreateClusterOptions) nodes(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	q.LabelSelector = fmt.Sprintf("!%s", common.LabelNodeRole)
-	data, err := l.Client.ListNodes(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		return nil
-	}
-	for _, v := range data.Items {
-		if strings.HasPrefix(v.Status.Ipv4DefaultIP, toComplete) {
-			list = append(list, v.Status.Ipv4DefaultIP)
-		}
-	}
-	return list
-}
@@ -50,7 +50,7 @@ Flags:
   -c, --config string   Path to the config file to use for CLI requests.
   -h, --help            help for role
       --name string     role name
-  -o, --output string   Output format either: json,yaml,table (default "table")
+  -o, --output string   Output format either: json,yaml,table,wide (default "table")
       --rules strings   role template rules
 */
 
@@ -0,0 +1,152 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package create
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+/*
+create kubeclipper role binding
+
+Usage:
+  kcctl create rolebinding (--user) (--role)
+
+Flags:
+  -c, --config string   Path to the config file to use for CLI requests.
+  -h, --help            help for rolebinding
+  -o, --output string   Output format either: json,yaml,table,wide (default "table")
+      --role string     role name
+      --user string     user name
+*/
+
+const (
+	roleBindingLongDescription = `
+  Bind a user to a role
+
+  A user is bound to a single role, binding the user to another role removes it
+  from the binding of its former role. Role bindings are named after their role.`
+	createRoleBindingExample = `
+  # Bind user foo to role platform-admin
+  kcctl create rolebinding --user foo --role platform-admin
+
+  Please read 'kcctl create rolebinding -h' get more create rolebinding flags.`
+)
+
+type CreateRoleBindingOptions struct {
+	BaseOptions
+	User string
+	Role string
+}
+
+func NewCreateRoleBindingOptions(streams options.IOStreams) *CreateRoleBindingOptions {
+	return &CreateRoleBindingOptions{
+		BaseOptions: BaseOptions{
+			PrintFlags: printer.NewPrintFlags(),
+			CliOpts:    options.NewCliOptions(),
+			IOStreams:  streams,
+		},
+	}
+}
+
+func NewCmdCreateRoleBinding(streams options.IOStreams) *cobra.Command {
+	o := NewCreateRoleBindingOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "rolebinding (--user) (--role)",
+		DisableFlagsInUseLine: true,
+		Short:                 "bind kubeclipper user to role",
+		Long:                  roleBindingLongDescription,
+		Example:               createRoleBindingExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.Complete(o.CliOpts))
+			utils.CheckErr(o.RunCreate())
+		},
+	}
+	cmd.Flags().StringVar(&o.User, "user", "", "user name")
+	cmd.Flags().StringVar(&o.Role, "role", "", "role name")
+	o.CliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("role", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete(o.CliOpts))
+		return completion.Roles(o.Client, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("user", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete(o.CliOpts))
+		return completion.Users(o.Client, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+	_ = cmd.MarkFlagRequired("user")
+	_ = cmd.MarkFlagRequired("role")
+	return cmd
+}
+
+func (l *CreateRoleBindingOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.Client = c
+	return nil
+}
+
+func (l *CreateRoleBindingOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.User == "" {
+		return utils.UsageErrorf(cmd, "user name must be specified")
+	}
+	if l.Role == "" {
+		return utils.UsageErrorf(cmd, "role name must be specified")
+	}
+	return nil
+}
+
+func (l *CreateRoleBindingOptions) RunCreate() error {
+	// the role is checked first, the server would create a binding to a missing role
+	if _, err := l.Client.DescribeRole(context.TODO(), l.Role); err != nil {
+		return err
+	}
+	users, err := l.Client.DescribeUser(context.TODO(), l.User)
+	if err != nil {
+		return err
+	}
+	user := users.Items[0]
+	if user.Annotations == nil {
+		user.Annotations = make(map[string]string)
+	}
+	user.Annotations[common.RoleAnnotation] = l.Role
+	if _, err = l.Client.UpdateUser(context.TODO(), &user); err != nil {
+		return err
+	}
+	resp, err := l.Client.DescribeRoleBinding(context.TODO(), l.Role)
+	if err != nil {
+		return err
+	}
+	return l.PrintFlags.Print(resp, l.IOStreams.Out)
+}
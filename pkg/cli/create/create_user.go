@@ -20,17 +20,14 @@ package create
 
 import (
 	"context"
-	"strings"
 
-	"github.com/kubeclipper/kubeclipper/pkg/logger"
-	"github.com/kubeclipper/kubeclipper/pkg/query"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
-	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 )
@@ -51,7 +48,7 @@ Flags:
       --email string          user email address
   -h, --help                  help for user
       --name string           user name
-  -o, --output string         Output format either: json,yaml,table (default "table")
+  -o, --output string         Output format either: json,yaml,table,wide (default "table")
       --password string       user password
       --phone string          user phone number
       --role string           user role
@@ -194,22 +191,5 @@ func (l *CreateUserOptions) newUser() *v1.User {
 func (l *CreateUserOptions) listRoles(toComplete string) []string {
 	utils.CheckErr(l.Complete(l.CliOpts))
 
-	return l.roles(toComplete)
-}
-
-func (l *CreateUserOptions) roles(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	q.LabelSelector = "!kubeclipper.io/role-template,!kubeclipper.io/hidden"
-	data, err := l.Client.ListRoles(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		logger.Warnf("Failed to list roles: %v", err)
-		return nil
-	}
-	for _, v := range data.Items {
-		if strings.HasPrefix(v.Name, toComplete) {
-			list = append(list, v.Name)
-		}
-	}
-	return list
+	return completion.Roles(l.Client, toComplete)
 }
@@ -21,14 +21,13 @@ package delete
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
-	"github.com/kubeclipper/kubeclipper/pkg/query"
-
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
@@ -63,6 +62,15 @@ const (
   # Delete kubeclipper cluster
   kcctl delete cluster 'CLUSTER-NAME'
 
+  # Turn off deletion protection and delete kubeclipper cluster
+  kcctl delete cluster 'CLUSTER-NAME' --force-unprotect
+
+  # Delete kubeclipper cluster after its operations and backups, keeping it until they are gone
+  kcctl delete cluster 'CLUSTER-NAME' --cascade foreground
+
+  # Delete kubeclipper cluster and keep its operations
+  kcctl delete cluster 'CLUSTER-NAME' --cascade orphan
+
   # Delete kubeclipper user
   kcctl delete user 'USER-NAME'
 
@@ -81,12 +89,20 @@ type BaseOptions struct {
 
 type DeleteOptions struct {
 	BaseOptions
-	resource string
-	name     string
+	resource       string
+	name           string
+	forceUnprotect bool
+	cascade        string
 }
 
 var (
 	allowedResource = sets.NewString(options.ResourceUser, options.ResourceRole, options.ResourceCluster)
+	// cascadePolicies are the propagation policies of the values of --cascade.
+	cascadePolicies = map[string]metav1.DeletionPropagation{
+		"background": metav1.DeletePropagationBackground,
+		"foreground": metav1.DeletePropagationForeground,
+		"orphan":     metav1.DeletePropagationOrphan,
+	}
 )
 
 func NewCmdDelete(streams options.IOStreams) *cobra.Command {
@@ -104,6 +120,8 @@ func NewCmdDelete(streams options.IOStreams) *cobra.Command {
 		},
 		ValidArgsFunction: ValidArgsFunction(o),
 	}
+	cmd.Flags().BoolVar(&o.forceUnprotect, "force-unprotect", false, "turn off the deletion protection of the cluster before deleting it")
+	cmd.Flags().StringVar(&o.cascade, "cascade", "background", "how the operations and backups of the cluster are deleted, one of background, foreground and orphan")
 
 	return cmd
 }
@@ -145,6 +163,9 @@ func (l *DeleteOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
 	if len(args) > 0 {
 		l.name = args[0]
 	}
+	if _, ok := cascadePolicies[l.cascade]; !ok {
+		return utils.UsageErrorf(cmd, "unsupported cascade %s, support background, foreground and orphan", l.cascade)
+	}
 	return nil
 }
 
@@ -162,7 +183,12 @@ func (l *DeleteOptions) RunDelete() error {
 			return err
 		}
 	case options.ResourceCluster:
-		err = l.Client.DeleteCluster(context.TODO(), l.name)
+		if l.forceUnprotect {
+			if err = l.unprotectCluster(); err != nil {
+				return err
+			}
+		}
+		err = l.Client.CoreV1().Clusters().Delete(context.TODO(), l.name, kc.DeleteOptions{PropagationPolicy: cascadePolicies[l.cascade]})
 		if err != nil {
 			return err
 		}
@@ -172,6 +198,20 @@ func (l *DeleteOptions) RunDelete() error {
 	return nil
 }
 
+func (l *DeleteOptions) unprotectCluster() error {
+	clusters, err := l.Client.DescribeCluster(context.TODO(), l.name)
+	if err != nil {
+		return err
+	}
+	if len(clusters.Items) == 0 {
+		return fmt.Errorf("cluster %s not found", l.name)
+	}
+	c := clusters.Items[0]
+	unprotected := false
+	c.DeleteProtection = &unprotected
+	return l.Client.UpdateCluster(context.TODO(), &c)
+}
+
 func ValidArgsFunction(o *DeleteOptions) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		utils.CheckErr(o.Complete(o.CliOpts))
@@ -181,58 +221,12 @@ func ValidArgsFunction(o *DeleteOptions) func(cmd *cobra.Command, args []string,
 
 		switch args[0] {
 		case options.ResourceUser:
-			return o.listUser(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+			return completion.Users(o.Client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 		case options.ResourceRole:
-			return o.listRole(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+			return completion.Roles(o.Client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 		case options.ResourceCluster:
-			return o.listCluster(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+			return completion.Clusters(o.Client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
 		}
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 }
-
-func (l *DeleteOptions) listCluster(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	data, err := l.Client.ListClusters(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		return nil
-	}
-	for _, v := range data.Items {
-		if strings.HasPrefix(v.Name, toComplete) {
-			list = append(list, v.Name)
-		}
-	}
-	return list
-}
-
-func (l *DeleteOptions) listUser(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	users, err := l.Client.ListUsers(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		return nil
-	}
-	for _, v := range users.Items {
-		if strings.HasPrefix(v.Name, toComplete) {
-			list = append(list, v.Name)
-		}
-	}
-	return list
-}
-
-func (l *DeleteOptions) listRole(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	q.LabelSelector = "!kubeclipper.io/role-template,!kubeclipper.io/hidden"
-	data, err := l.Client.ListRoles(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		return nil
-	}
-	for _, v := range data.Items {
-		if strings.HasPrefix(v.Name, toComplete) {
-			list = append(list, v.Name)
-		}
-	}
-	return list
-}
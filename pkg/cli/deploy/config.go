@@ -127,4 +127,19 @@ opLog:
   #dir: /var/log/kc-agent
   # max operation log query threshold,default 1MB.
   #threshold: 1048576
+
+# systemd drop-in overrides of kc units, written to /etc/systemd/system/<unit>.service.d/.
+#systemdOverrides:
+#  kcAgent:
+#    environment:
+#      HTTP_PROXY: http://proxy.example.com:3128
+#      NO_PROXY: 127.0.0.1,localhost
+#    service:
+#    - LimitNOFILE=65535
+#  kcServer:
+#    service:
+#    - MemoryMax=4G
+#  kcEtcd:
+#    service:
+#    - CPUQuota=200%
 `
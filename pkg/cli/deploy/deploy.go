@@ -34,8 +34,8 @@ import (
 	"time"
 
 	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sliceutil"
 
-	"github.com/google/uuid"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/sethvargo/go-password/password"
@@ -45,11 +45,15 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/join"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/precheck"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/registry"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/sudo"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/config"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/i18n"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
 
 	"github.com/spf13/cobra"
@@ -100,6 +104,12 @@ const (
   # Deploy from config.
   kcctl deploy --deploy-config deploy-config.yaml
 
+  # Deploy a package verified with its signature kc.tar.gz.sig, agents verify the resources they download as well.
+  kcctl deploy --server 192.168.234.3 --agent 192.168.234.3 --pk-file ~/.ssh/id_rsa --pkg kc.tar.gz --trusted-key cosign.pub
+
+  # Deploy env with the built-in image registry on a kc node, push offline image bundles with 'kcctl registry push --images'.
+  kcctl deploy --server 192.168.234.3 --agent 192.168.234.4 --pk-file ~/.ssh/id_rsa --pkg kc.tar.gz --image-registry 192.168.234.3
+
   Please read 'kcctl deploy -h' get more deploy flags`
 	defaultPkg              = "https://oss.kubeclipper.io/release/kc-minimal-latest.tar.gz"
 	allInOneEtcdClientPort  = 12379
@@ -113,6 +123,7 @@ type DeployOptions struct {
 	allNodes     []string
 	servers      map[string]string
 	agents       []string // user input's agents,maybe with region,need to parse.
+	prechecks    *precheck.Recorder
 }
 
 func NewDeployOptions(streams options.IOStreams) *DeployOptions {
@@ -211,6 +222,15 @@ func (d *DeployOptions) ValidateArgs() error {
 	if len(d.deployConfig.ServerIPs)%2 == 0 {
 		return fmt.Errorf("the number of servers must be odd")
 	}
+	if err := d.deployConfig.SystemdOverrides.Validate(); err != nil {
+		return err
+	}
+	if err := d.deployConfig.ValidateNodeIdentity(); err != nil {
+		return err
+	}
+	if err := d.validateImageRegistry(); err != nil {
+		return err
+	}
 	if d.deployConfig.MQ.External {
 		if len(d.deployConfig.MQ.IPs) == 0 {
 			return fmt.Errorf("the ips of the external mq cannot be empty")
@@ -288,7 +308,9 @@ func (d *DeployOptions) precheckService(name string, nodes []string, fn precheck
 		wg.Add(1)
 		go func(host string) {
 			defer wg.Done()
-			if err := fn(d.deployConfig.SSHConfig, host); err != nil {
+			err := fn(d.deployConfig.SSHConfig, host)
+			d.prechecks.Add(host, name, err)
+			if err != nil {
 				nodeErrChan <- err
 			}
 		}(node)
@@ -305,7 +327,7 @@ func (d *DeployOptions) precheckService(name string, nodes []string, fn precheck
 	if options.AssumeYes {
 		return true
 	}
-	_, _ = d.IOStreams.Out.Write([]byte("Ignore this error, still install? Please input (yes/no)"))
+	_, _ = d.IOStreams.Out.Write([]byte(i18n.T("Ignore this error, still install? Please input (yes/no)")))
 	return utils.AskForConfirmation()
 }
 
@@ -329,8 +351,11 @@ func (d *DeployOptions) precheckTimeLag() bool {
 			diff := t.Sub(now).Seconds()
 			logger.Infof("[%s] %v seconds", host, diff)
 			if math.Abs(diff) > float64(5) {
+				d.prechecks.Add(host, "TIME-LAG", fmt.Errorf("time lag %v seconds is more than 5 seconds", diff))
 				nodeErrChan <- struct{}{}
+				return
 			}
+			d.prechecks.Add(host, "TIME-LAG", nil)
 		}(node)
 	}
 	wg.Wait()
@@ -343,11 +368,17 @@ func (d *DeployOptions) precheckTimeLag() bool {
 	if options.AssumeYes {
 		return true
 	}
-	_, _ = d.IOStreams.Out.Write([]byte("Ignore this error, still install? Please input (yes/no)"))
+	_, _ = d.IOStreams.Out.Write([]byte(i18n.T("Ignore this error, still install? Please input (yes/no)")))
 	return utils.AskForConfirmation()
 }
 
 func (d *DeployOptions) preCheck() bool {
+	d.prechecks = precheck.NewRecorder(v1.PrecheckSourceDeploy, d.allNodes)
+	defer func() {
+		if err := d.prechecks.Save(precheck.DefaultDir); err != nil {
+			logger.Warnf("save precheck record failed: %v", err)
+		}
+	}()
 	if !d.precheckService("kc-etcd", d.deployConfig.ServerIPs, precheckKcEtcdFunc) {
 		return false
 	}
@@ -363,6 +394,9 @@ func (d *DeployOptions) preCheck() bool {
 	if !d.precheckService("NTP", d.allNodes, precheckNtpFunc) {
 		return false
 	}
+	if !d.precheckNetwork() {
+		return false
+	}
 	if !sudo.PreCheck("sudo", d.deployConfig.SSHConfig, d.IOStreams, d.allNodes) {
 		return false
 	}
@@ -381,6 +415,7 @@ func (d *DeployOptions) RunDeploy() error {
 	time.Sleep(5 * time.Second)
 	d.deployKcAgent()
 	d.deployKcConsole()
+	d.deployImageRegistry()
 	d.removeTempFile()
 	fmt.Printf("\033[1;40;36m%s\033[0m\n", options.Contact)
 	return nil
@@ -409,6 +444,9 @@ func (d *DeployOptions) check() []error {
 }
 
 func (d *DeployOptions) sendPackage() {
+	if err := utils.VerifyPackage(d.deployConfig.Pkg, d.deployConfig.TrustedKeys, d.deployConfig.SkipSignatureVerify); err != nil {
+		logger.Fatalf("verify package err:%s", err.Error())
+	}
 	tar := fmt.Sprintf("rm -rf %s && tar -xvf %s -C %s", filepath.Join(config.DefaultPkgPath, "kc"),
 		filepath.Join(config.DefaultPkgPath, path.Base(d.deployConfig.Pkg)), config.DefaultPkgPath)
 	cp := sshutils.WrapSh(fmt.Sprintf("cp -rf %s /usr/local/bin/", filepath.Join(config.DefaultPkgPath, "kc/bin/*")))
@@ -539,6 +577,7 @@ func (d *DeployOptions) deployEtcd() {
 	for _, host := range d.deployConfig.ServerIPs {
 		data := d.getEtcdTemplateContent(host)
 		cmd := sshutils.WrapEcho(data, "/usr/lib/systemd/system/kc-etcd.service") +
+			" && " + d.deployConfig.SystemdOverrides.DropInCmd(options.UnitKcEtcd) +
 			" && systemctl daemon-reload && systemctl enable kc-etcd --now"
 		ret, err := sshutils.SSHCmdWithSudo(d.deployConfig.SSHConfig, host, cmd)
 		if err != nil {
@@ -656,7 +695,7 @@ func (d *DeployOptions) getKcServerConfigTemplateContent(ip string) string {
 	return buffer.String()
 }
 
-func (d *DeployOptions) getKcAgentConfigTemplateContent(region string) string {
+func (d *DeployOptions) getKcAgentConfigTemplateContent(region, agentID, externalID string) string {
 	tmpl, err := template.New("text").Parse(config.KcAgentConfigTmpl)
 	if err != nil {
 		logger.Fatalf("template parse failed: %s", err.Error())
@@ -667,7 +706,8 @@ func (d *DeployOptions) getKcAgentConfigTemplateContent(region string) string {
 	}
 
 	var data = make(map[string]interface{})
-	data["AgentID"] = uuid.New().String()
+	data["AgentID"] = agentID
+	data["ExternalID"] = externalID
 	data["Region"] = region
 	data["StaticServerAddress"] = fmt.Sprintf("http://%s:%d", d.deployConfig.ServerIPs[0], d.deployConfig.StaticServerPort)
 	if d.deployConfig.Debug {
@@ -688,6 +728,7 @@ func (d *DeployOptions) getKcAgentConfigTemplateContent(region string) string {
 	}
 	data["OpLogDir"] = d.deployConfig.OpLog.Dir
 	data["OpLogThreshold"] = d.deployConfig.OpLog.Threshold
+	data["TrustedKeys"] = d.deployConfig.TrustedKeys
 	var buffer bytes.Buffer
 	if err = tmpl.Execute(&buffer, data); err != nil {
 		logger.Fatalf("template execute failed: %s", err.Error())
@@ -700,6 +741,7 @@ func (d *DeployOptions) deployKcServer() {
 		"mkdir -pv /etc/kubeclipper-server",
 		sshutils.WrapSh(fmt.Sprintf("cp -rf %s/kc/configs/*.json /etc/kubeclipper-server/", config.DefaultPkgPath)),
 		sshutils.WrapEcho(config.KcServerService, "/usr/lib/systemd/system/kc-server.service"),
+		d.deployConfig.SystemdOverrides.DropInCmd(options.UnitKcServer),
 		fmt.Sprintf("mkdir -pv %s ", d.deployConfig.StaticServerPath),
 		sshutils.WrapSh(fmt.Sprintf("cp -rf %s/kc/resource/* %s/", config.DefaultPkgPath, d.deployConfig.StaticServerPath)),
 	}
@@ -743,11 +785,17 @@ func (d *DeployOptions) deployKcConsole() {
 }
 
 func (d *DeployOptions) deployKcAgent() {
+	agentIDs, err := utils.AgentIDs(d.deployConfig.NodeIDSource, d.deployConfig.AgentRegions.ListIP(),
+		utils.SSHNodeIDReader(d.deployConfig.SSHConfig))
+	if err != nil {
+		logger.Fatalf("generate kc agent ids failed due to %s", err.Error())
+	}
 	for region, agents := range d.deployConfig.AgentRegions {
 		for _, agent := range agents {
-			agentConfig := d.getKcAgentConfigTemplateContent(region)
+			agentConfig := d.getKcAgentConfigTemplateContent(region, agentIDs[agent], d.deployConfig.ExternalIDs[agent])
 			cmdList := []string{
 				sshutils.WrapEcho(config.KcAgentService, "/usr/lib/systemd/system/kc-agent.service"),
+				d.deployConfig.SystemdOverrides.DropInCmd(options.UnitKcAgent),
 				"mkdir -pv /etc/kubeclipper-agent",
 				sshutils.WrapEcho(agentConfig, "/etc/kubeclipper-agent/kubeclipper-agent.yaml"),
 				"systemctl daemon-reload && systemctl enable kc-agent --now",
@@ -765,6 +813,45 @@ func (d *DeployOptions) deployKcAgent() {
 	}
 }
 
+func (d *DeployOptions) validateImageRegistry() error {
+	r := d.deployConfig.ImageRegistry
+	if r == nil || r.Node == "" {
+		return nil
+	}
+	if !sliceutil.HasString(d.allNodes, r.Node) {
+		return fmt.Errorf("image registry node %s must be one of the kc nodes", r.Node)
+	}
+	if r.Port <= 0 || r.Port > 65535 {
+		return fmt.Errorf("invalid image registry port %d", r.Port)
+	}
+	if !filepath.IsAbs(r.Volume) {
+		return fmt.Errorf("image registry volume must be an absolute path")
+	}
+	return nil
+}
+
+// deployImageRegistry runs the registry from the extracted kc package and pushes the images of the package into it.
+func (d *DeployOptions) deployImageRegistry() {
+	r := d.deployConfig.ImageRegistry
+	if r == nil || r.Node == "" {
+		return
+	}
+	o := registry.NewRegistryOptions(d.IOStreams)
+	o.SSHConfig = d.deployConfig.SSHConfig
+	o.Node = r.Node
+	o.RegistryPort = r.Port
+	o.RegistryVolume = r.Volume
+	o.DataRoot = r.DataRoot
+	o.Arch = d.deployConfig.SSHConfig.CmdToString(r.Node, "uname -m", "")
+	if err := o.Complete(); err != nil {
+		logger.Fatalf("deploy image registry failed due to %s", err.Error())
+	}
+	if err := o.InstallFromExtracted(); err != nil {
+		logger.Fatalf("deploy image registry failed due to %s", err.Error())
+	}
+	logger.Infof("image registry is available at %s, use it as the local registry of clusters", r.Address())
+}
+
 func (d *DeployOptions) removeTempFile() {
 	cmdList := []string{
 		fmt.Sprintf("rm -rf %s/kc", config.DefaultPkgPath),
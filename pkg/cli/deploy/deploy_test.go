@@ -62,7 +62,7 @@ func TestDeployOptions_getKcAgentConfigTemplateContent(t *testing.T) {
 	}
 
 	for range d.deployConfig.ServerIPs {
-		t.Log(d.getKcAgentConfigTemplateContent(d.deployConfig.DefaultRegion))
+		t.Log(d.getKcAgentConfigTemplateContent(d.deployConfig.DefaultRegion, "6e4b2a4c-8a0c-5f61-9d8e-3c1f0a9b7d21", "ASSET-0001"))
 	}
 }
 
@@ -77,3 +77,29 @@ func TestDeployOptions_getKcConsoleTemplateContent(t *testing.T) {
 
 	t.Log(d.getKcConsoleTemplateContent())
 }
+
+func TestDeployOptions_validateImageRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    string
+		port    int
+		volume  string
+		wantErr bool
+	}{
+		{name: "not deployed", node: ""},
+		{name: "kc node", node: "192.168.234.4", port: 5000, volume: "/opt/registry"},
+		{name: "not a kc node", node: "192.168.234.9", port: 5000, volume: "/opt/registry", wantErr: true},
+		{name: "invalid port", node: "192.168.234.3", port: 0, volume: "/opt/registry", wantErr: true},
+		{name: "relative volume", node: "192.168.234.3", port: 5000, volume: "registry", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDeployOptions(options.IOStreams{})
+			d.allNodes = []string{"192.168.234.3", "192.168.234.4"}
+			d.deployConfig.ImageRegistry = &options.ImageRegistry{Node: tt.node, Port: tt.port, Volume: tt.volume}
+			if err := d.validateImageRegistry(); (err != nil) != tt.wantErr {
+				t.Errorf("validateImageRegistry() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
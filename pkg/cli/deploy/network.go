@@ -0,0 +1,258 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package deploy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/i18n"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+// overlayMinMTU is the smallest node MTU that still leaves room for the 50 byte
+// VXLAN header on top of a standard 1400 byte pod MTU.
+const overlayMinMTU = 1450
+
+// nicInfoScript prints the interface that holds $ip and, when it is a bond or
+// team (possibly underneath a vlan), the state and MTU of every member link.
+// Everything it reads is world readable, so it runs without sudo.
+const nicInfoScript = `
+dev=$(ip -o -4 addr show | awk -v ip="$ip" '{split($4,a,"/"); if (a[1]==ip) {print $2; exit}}')
+if [ -z "$dev" ]; then echo "error=no interface holds $ip"; exit 0; fi
+dev=${dev%@*}
+echo "iface=$dev"
+echo "mtu=$(cat /sys/class/net/$dev/mtu)"
+link=$dev
+kind=$(grep '^DEVTYPE=' /sys/class/net/$dev/uevent 2>/dev/null | cut -d= -f2)
+if [ "$kind" = "vlan" ]; then
+  link=$(ls -d /sys/class/net/$dev/lower_* 2>/dev/null | head -n1 | sed 's/.*lower_//')
+  echo "vlan_of=$link"
+  kind=$(grep '^DEVTYPE=' /sys/class/net/$link/uevent 2>/dev/null | cut -d= -f2)
+fi
+if [ -z "$kind" ] && ip -d link show "$link" 2>/dev/null | grep -q ' team '; then kind=team; fi
+echo "kind=$kind"
+if [ -f /sys/class/net/$link/bonding/mode ]; then echo "mode=$(cut -d' ' -f1 /sys/class/net/$link/bonding/mode)"; fi
+if [ "$kind" = "bond" ] || [ "$kind" = "team" ]; then
+  for l in /sys/class/net/$link/lower_*; do
+    [ -e "$l" ] || continue
+    n=${l##*/lower_}
+    echo "lower=$n $(cat /sys/class/net/$n/operstate) $(cat /sys/class/net/$n/mtu)"
+  done
+fi
+`
+
+type lowerLink struct {
+	name  string
+	state string
+	mtu   int
+}
+
+type nicInfo struct {
+	host   string
+	iface  string
+	mtu    int
+	kind   string
+	vlanOf string
+	mode   string
+	lowers []lowerLink
+}
+
+func (n *nicInfo) aggregated() bool {
+	return n.kind == "bond" || n.kind == "team"
+}
+
+// parseNICInfo parses the key=value output of nicInfoScript.
+func parseNICInfo(host, out string) (*nicInfo, error) {
+	info := &nicInfo{host: host}
+	for _, line := range strings.Split(out, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "error":
+			return nil, fmt.Errorf("%s", value)
+		case "iface":
+			info.iface = value
+		case "mtu":
+			mtu, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid mtu %q of %s", value, info.iface)
+			}
+			info.mtu = mtu
+		case "kind":
+			info.kind = value
+		case "vlan_of":
+			info.vlanOf = value
+		case "mode":
+			info.mode = value
+		case "lower":
+			fields := strings.Fields(value)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("invalid member link %q", value)
+			}
+			mtu, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid mtu %q of %s", fields[2], fields[0])
+			}
+			info.lowers = append(info.lowers, lowerLink{name: fields[0], state: fields[1], mtu: mtu})
+		}
+	}
+	if info.iface == "" || info.mtu == 0 {
+		return nil, fmt.Errorf("unable to detect the interface of %s", host)
+	}
+	return info, nil
+}
+
+// check returns the problems of a bonded or teamed interface that commonly
+// break CNI traffic: members that are down or have a different MTU.
+func (n *nicInfo) check() error {
+	if !n.aggregated() {
+		return nil
+	}
+	if len(n.lowers) == 0 {
+		return fmt.Errorf("%s %s has no member links", n.kind, n.iface)
+	}
+	var problems []string
+	up := 0
+	for _, l := range n.lowers {
+		if l.state == "up" {
+			up++
+		} else {
+			problems = append(problems, fmt.Sprintf("member %s is %s", l.name, l.state))
+		}
+		if l.mtu < n.mtu {
+			problems = append(problems, fmt.Sprintf("member %s mtu %d is smaller than %d", l.name, l.mtu, n.mtu))
+		}
+	}
+	if up == 0 {
+		return fmt.Errorf("%s %s has no member link up", n.kind, n.iface)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%s %s: %s", n.kind, n.iface, strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// checkMTUConsistency reports every node whose MTU differs from the most
+// common one, which leads to silently dropped large packets between pods.
+func checkMTUConsistency(infos []*nicInfo) map[string]error {
+	count := make(map[int]int)
+	for _, info := range infos {
+		count[info.mtu]++
+	}
+	if len(count) < 2 {
+		return nil
+	}
+	mtus := make([]int, 0, len(count))
+	for mtu := range count {
+		mtus = append(mtus, mtu)
+	}
+	// prefer the larger mtu on a tie so the reported node is deterministic
+	sort.Slice(mtus, func(i, j int) bool {
+		if count[mtus[i]] != count[mtus[j]] {
+			return count[mtus[i]] > count[mtus[j]]
+		}
+		return mtus[i] > mtus[j]
+	})
+	errs := make(map[string]error)
+	for _, info := range infos {
+		if info.mtu != mtus[0] {
+			errs[info.host] = fmt.Errorf("mtu %d of %s differs from %d used by other nodes", info.mtu, info.iface, mtus[0])
+		}
+	}
+	return errs
+}
+
+func getNICInfo(sshConfig *sshutils.SSH, host string) (*nicInfo, error) {
+	ret, err := sshutils.SSHCmd(sshConfig, host, fmt.Sprintf("ip=%s\n%s", host, nicInfoScript))
+	if err != nil {
+		return nil, err
+	}
+	if err = ret.Error(); err != nil {
+		return nil, err
+	}
+	return parseNICInfo(host, ret.Stdout)
+}
+
+func (d *DeployOptions) precheckNetwork() bool {
+	logger.Infof("============>NETWORK PRECHECK ...")
+	var (
+		mu    sync.Mutex
+		infos []*nicInfo
+		errs  = make(map[string]error)
+	)
+	wg := sync.WaitGroup{}
+	for _, node := range d.allNodes {
+		wg.Add(1)
+		go func(host string) {
+			defer wg.Done()
+			info, err := getNICInfo(d.deployConfig.SSHConfig, host)
+			if err == nil {
+				err = info.check()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[host] = err
+				return
+			}
+			infos = append(infos, info)
+		}(node)
+	}
+	wg.Wait()
+
+	for host, err := range checkMTUConsistency(infos) {
+		errs[host] = err
+	}
+	for _, info := range infos {
+		if info.aggregated() {
+			logger.Infof("[%s] %s %s (mode %s) mtu %d", info.host, info.kind, info.iface, info.mode, info.mtu)
+		} else {
+			logger.Infof("[%s] %s mtu %d", info.host, info.iface, info.mtu)
+		}
+		if info.mtu < overlayMinMTU {
+			logger.Warnf("[%s] mtu %d of %s is less than %d, overlay CNIs such as calico vxlan need the pod mtu lowered by 50 bytes",
+				info.host, info.mtu, info.iface, overlayMinMTU)
+		}
+	}
+	for _, node := range d.allNodes {
+		d.prechecks.Add(node, "NETWORK", errs[node])
+	}
+	if len(errs) == 0 {
+		logger.Infof("============>NETWORK PRECHECK OK!")
+		return true
+	}
+	for host, err := range errs {
+		logger.Warnf("[%s] %v", host, err)
+	}
+	logger.Errorf("===========>NETWORK PRECHECK FAILED!")
+	if options.AssumeYes {
+		return true
+	}
+	_, _ = d.IOStreams.Out.Write([]byte(i18n.T("Ignore this error, still install? Please input (yes/no)")))
+	return utils.AskForConfirmation()
+}
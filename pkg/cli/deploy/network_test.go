@@ -0,0 +1,122 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package deploy
+
+import (
+	"testing"
+)
+
+func TestParseNICInfoAndCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		out      string
+		parseErr bool
+		checkErr bool
+		kind     string
+	}{
+		{
+			name: "plain interface",
+			out:  "iface=eth0\nmtu=1500\nkind=\n",
+		},
+		{
+			name: "healthy bond",
+			out:  "iface=bond0\nmtu=9000\nkind=bond\nmode=802.3ad\nlower=eth0 up 9000\nlower=eth1 up 9000\n",
+			kind: "bond",
+		},
+		{
+			name:     "bond member down",
+			out:      "iface=bond0\nmtu=1500\nkind=bond\nmode=active-backup\nlower=eth0 up 1500\nlower=eth1 down 1500\n",
+			checkErr: true,
+			kind:     "bond",
+		},
+		{
+			name:     "bond member mtu smaller",
+			out:      "iface=bond0\nmtu=9000\nkind=bond\nlower=eth0 up 9000\nlower=eth1 up 1500\n",
+			checkErr: true,
+			kind:     "bond",
+		},
+		{
+			name:     "team without members",
+			out:      "iface=team0\nmtu=1500\nkind=team\n",
+			checkErr: true,
+			kind:     "team",
+		},
+		{
+			name: "vlan on bond",
+			out:  "iface=bond0.100\nmtu=1500\nvlan_of=bond0\nkind=bond\nlower=eth0 up 1500\n",
+			kind: "bond",
+		},
+		{
+			name:     "ip not found",
+			out:      "error=no interface holds 10.0.0.1\n",
+			parseErr: true,
+		},
+		{
+			name:     "invalid mtu",
+			out:      "iface=eth0\nmtu=abc\n",
+			parseErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := parseNICInfo("10.0.0.1", tt.out)
+			if (err != nil) != tt.parseErr {
+				t.Fatalf("parseNICInfo() error = %v, wantErr %v", err, tt.parseErr)
+			}
+			if err != nil {
+				return
+			}
+			if info.kind != tt.kind {
+				t.Errorf("kind = %q, want %q", info.kind, tt.kind)
+			}
+			if err = info.check(); (err != nil) != tt.checkErr {
+				t.Errorf("check() error = %v, wantErr %v", err, tt.checkErr)
+			}
+		})
+	}
+}
+
+func TestCheckMTUConsistency(t *testing.T) {
+	same := []*nicInfo{
+		{host: "10.0.0.1", iface: "eth0", mtu: 1500},
+		{host: "10.0.0.2", iface: "eth0", mtu: 1500},
+	}
+	if errs := checkMTUConsistency(same); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	mixed := []*nicInfo{
+		{host: "10.0.0.1", iface: "eth0", mtu: 1500},
+		{host: "10.0.0.2", iface: "eth0", mtu: 1500},
+		{host: "10.0.0.3", iface: "bond0", mtu: 9000},
+	}
+	errs := checkMTUConsistency(mixed)
+	if len(errs) != 1 || errs["10.0.0.3"] == nil {
+		t.Errorf("expected only 10.0.0.3 to be reported, got %v", errs)
+	}
+
+	tie := []*nicInfo{
+		{host: "10.0.0.1", iface: "eth0", mtu: 1450},
+		{host: "10.0.0.2", iface: "eth0", mtu: 1500},
+	}
+	errs = checkMTUConsistency(tie)
+	if len(errs) != 1 || errs["10.0.0.1"] == nil {
+		t.Errorf("expected 10.0.0.1 to be reported on a tie, got %v", errs)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package describe
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Show details of a cluster, node or operation.
+
+  Prints a summary of the resource followed by its conditions, component versions, the operations
+  run for it and its recent events, gathered from several requests to the server. Use 'kcctl get'
+  with -o yaml for the full resource.`
+	describeExample = `
+  # Describe cluster demo
+  kcctl describe cluster demo
+
+  # Describe a node without its events
+  kcctl describe node 'NODE-ID' --show-events=false
+
+  # Describe an operation, showing its failed steps
+  kcctl describe operation 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90
+
+  Please read 'kcctl describe -h' get more describe flags.`
+)
+
+const (
+	// defaultEventLimit is the number of the latest events shown.
+	defaultEventLimit = 10
+	// defaultOperationLimit is the number of the latest operations shown.
+	defaultOperationLimit = 5
+)
+
+var allowedResource = sets.NewString(options.ResourceCluster, options.ResourceNode, options.ResourceOperation)
+
+type DescribeOptions struct {
+	options.IOStreams
+	cliOpts    *options.CliOptions
+	client     *kc.Client
+	resource   string
+	name       string
+	ShowEvents bool
+	Events     int
+	Operations int
+}
+
+func NewDescribeOptions(streams options.IOStreams) *DescribeOptions {
+	return &DescribeOptions{
+		IOStreams:  streams,
+		cliOpts:    options.NewCliOptions(),
+		ShowEvents: true,
+		Events:     defaultEventLimit,
+		Operations: defaultOperationLimit,
+	}
+}
+
+func NewCmdDescribe(streams options.IOStreams) *cobra.Command {
+	o := NewDescribeOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "describe (cluster|node|operation) <name> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show details of a cluster, node or operation",
+		Long:                  longDescription,
+		Example:               describeExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunDescribe())
+		},
+		ValidArgsFunction: ValidArgsFunction(o),
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.ShowEvents, "show-events", o.ShowEvents, "If true, display the recent events of the resource.")
+	cmd.Flags().IntVar(&o.Events, "events", o.Events, "The number of the latest events to display.")
+	cmd.Flags().IntVar(&o.Operations, "operations", o.Operations, "The number of the latest operations of a cluster or node to display.")
+	return cmd
+}
+
+func (o *DescribeOptions) Complete() error {
+	if err := o.cliOpts.Complete(); err != nil {
+		return err
+	}
+	c, err := o.cliOpts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
+
+func (o *DescribeOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return utils.UsageErrorf(cmd, "You must specify the type of resource to describe,support %v now", allowedResource.List())
+	}
+	o.resource = args[0]
+	if !allowedResource.Has(o.resource) {
+		return utils.UsageErrorf(cmd, "unsupported resource type,support %v now", allowedResource.List())
+	}
+	if len(args) != 2 {
+		return utils.UsageErrorf(cmd, "You must specify the name of %s to describe", o.resource)
+	}
+	o.name = args[1]
+	if o.Events < 0 || o.Operations < 0 {
+		return utils.UsageErrorf(cmd, "--events and --operations can not be negative")
+	}
+	return nil
+}
+
+func (o *DescribeOptions) RunDescribe() error {
+	if !o.ShowEvents {
+		o.Events = 0
+	}
+	d := &describer{client: o.client, events: o.Events, operations: o.Operations}
+	ctx := context.TODO()
+	switch o.resource {
+	case options.ResourceCluster:
+		return d.describeCluster(ctx, o.name, o.Out)
+	case options.ResourceNode:
+		return d.describeNode(ctx, o.name, o.Out)
+	default:
+		return d.describeOperation(ctx, o.name, o.Out)
+	}
+}
+
+func ValidArgsFunction(o *DescribeOptions) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return allowedResource.List(), cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) > 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		utils.CheckErr(o.Complete())
+		switch args[0] {
+		case options.ResourceCluster:
+			return completion.Clusters(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceNode:
+			return completion.Nodes(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
@@ -0,0 +1,448 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package describe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+// describer gets a resource and the resources related to it, and prints them in sections.
+// The related resources are best effort, a failure to list them is only warned about.
+type describer struct {
+	client *kc.Client
+	// events and operations are the number of the latest ones printed, none are printed when they are 0.
+	events     int
+	operations int
+}
+
+func (d *describer) describeCluster(ctx context.Context, name string, out io.Writer) error {
+	c, err := d.client.CoreV1().Clusters().Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	w := newPrefixWriter(out)
+	printCluster(w, c)
+	d.printOperations(ctx, w, name, nil)
+	d.printEvents(ctx, w, "Cluster", name)
+	return w.Flush()
+}
+
+func (d *describer) describeNode(ctx context.Context, name string, out io.Writer) error {
+	node, err := d.client.CoreV1().Nodes().Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	w := newPrefixWriter(out)
+	printNode(w, node)
+	// the operations run on a node are the ones of its cluster with a step on it
+	if cluster := node.Labels[common.LabelClusterName]; cluster != "" {
+		d.printOperations(ctx, w, cluster, func(op *v1.Operation) bool {
+			return runsOn(op, node.Name)
+		})
+	}
+	d.printEvents(ctx, w, "Node", name)
+	return w.Flush()
+}
+
+func (d *describer) describeOperation(ctx context.Context, name string, out io.Writer) error {
+	op, err := d.client.CoreV1().Operations().Get(ctx, name)
+	if err != nil {
+		return err
+	}
+	w := newPrefixWriter(out)
+	printOperation(w, op)
+	d.printEvents(ctx, w, "Operation", name)
+	return w.Flush()
+}
+
+// printOperations prints the latest operations of the cluster which match filter, all of them when filter is nil.
+func (d *describer) printOperations(ctx context.Context, w *prefixWriter, cluster string, filter func(op *v1.Operation) bool) {
+	if d.operations == 0 {
+		return
+	}
+	list, err := d.client.CoreV1().Operations().List(ctx, kc.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=%s", common.LabelClusterName, cluster),
+		OrderBy:       "metadata.creationTimestamp",
+		Reverse:       true,
+	})
+	if err != nil {
+		logger.Warnf("list operations of cluster %s failed: %v", cluster, err)
+		return
+	}
+	var ops []v1.Operation
+	for i := range list.Items {
+		if filter == nil || filter(&list.Items[i]) {
+			ops = append(ops, list.Items[i])
+		}
+		if len(ops) == d.operations {
+			break
+		}
+	}
+	writeOperations(w, ops)
+}
+
+// printEvents prints the latest events of the object, the latest last.
+func (d *describer) printEvents(ctx context.Context, w *prefixWriter, kind, name string) {
+	if d.events == 0 {
+		return
+	}
+	list, err := d.client.CoreV1().Events().List(ctx, kc.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+		OrderBy:       "lastTimestamp",
+	})
+	if err != nil {
+		logger.Warnf("list events of %s %s failed: %v", strings.ToLower(kind), name, err)
+		return
+	}
+	events := list.Items
+	if len(events) > d.events {
+		events = events[len(events)-d.events:]
+	}
+	writeEvents(w, events)
+}
+
+func printCluster(w *prefixWriter, c *v1.Cluster) {
+	writeObjectMeta(w, &c.ObjectMeta)
+	w.write(0, "Type:\t%s\n", c.ClusterType)
+	w.write(0, "Status:\t%s\n", c.Status.Status)
+	if c.DeleteProtection != nil {
+		w.write(0, "Delete Protection:\t%t\n", *c.DeleteProtection)
+	} else {
+		w.write(0, "Delete Protection:\t<platform default>\n")
+	}
+	if c.Kubeadm == nil {
+		writeHealth(w, c.Status.Health)
+		return
+	}
+	k := c.Kubeadm
+	if k.Description != "" {
+		w.write(0, "Description:\t%s\n", k.Description)
+	}
+	w.write(0, "Masters:\t%s\n", joinOrNone(k.Masters.GetNodeIDs()))
+	w.write(0, "Workers:\t%s\n", joinOrNone(k.Workers.GetNodeIDs()))
+	if k.ExternalEndpoint != "" {
+		w.write(0, "External Endpoint:\t%s\n", k.ExternalEndpoint)
+	}
+	w.write(0, "Networking:\n")
+	w.write(1, "Pod Subnet:\t%s\n", k.Networking.PodSubnet)
+	w.write(1, "Service Subnet:\t%s\n", k.Networking.ServiceSubnet)
+	w.write(1, "DNS Domain:\t%s\n", k.Networking.DNSDomain)
+	w.write(1, "CNI:\t%s\n", k.KubeComponents.CNI.Type)
+
+	w.write(0, "Component Versions:\n")
+	w.write(1, "Name\tVersion\tStatus\n")
+	w.write(1, "----\t-------\t------\n")
+	w.write(1, "kubernetes\t%s\t%s\n", k.KubernetesVersion, "-")
+	w.write(1, "%s\t%s\t%s\n", k.ContainerRuntime.Type, containerRuntimeVersion(&k.ContainerRuntime), "-")
+	if cni := k.KubeComponents.CNI; cni.Type != "" {
+		w.write(1, "%s\t%s\t%s\n", cni.Type, cniVersion(&cni), "-")
+	}
+	status := make(map[string]v1.ComponentStatus, len(c.Status.ComponentConditions))
+	for _, cc := range c.Status.ComponentConditions {
+		status[cc.Name] = cc.Status
+	}
+	for _, comp := range k.Components {
+		s := string(status[comp.Name])
+		if s == "" {
+			s = "-"
+		}
+		w.write(1, "%s\t%s\t%s\n", comp.Name, comp.Version, s)
+	}
+	writeHealth(w, c.Status.Health)
+	if expiring := c.Status.ExpiringCertifications(); len(expiring) > 0 {
+		w.write(0, "Expiring Certificates:\t%s\n", strings.Join(expiring, ", "))
+	}
+}
+
+func printNode(w *prefixWriter, node *v1.Node) {
+	writeObjectMeta(w, &node.ObjectMeta)
+	info := node.Status.NodeInfo
+	w.write(0, "Hostname:\t%s\n", info.Hostname)
+	w.write(0, "Role:\t%s\n", valueOrNone(node.Labels[common.LabelNodeRole]))
+	w.write(0, "Region:\t%s\n", valueOrNone(node.Labels[common.LabelTopologyRegion]))
+	w.write(0, "Cluster:\t%s\n", valueOrNone(node.Labels[common.LabelClusterName]))
+	if _, ok := node.Labels[common.LabelNodeDisable]; ok {
+		w.write(0, "Disabled:\ttrue\n")
+	}
+	w.write(0, "Addresses:\n")
+	w.write(1, "DefaultIP:\t%s\n", node.Status.Ipv4DefaultIP)
+	for _, a := range node.Status.Addresses {
+		w.write(1, "%s:\t%s\n", a.Type, a.Address)
+	}
+	if len(node.Status.Capacity) > 0 {
+		w.write(0, "Capacity:\n")
+		names := make([]string, 0, len(node.Status.Capacity))
+		for name := range node.Status.Capacity {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			q := node.Status.Capacity[v1.ResourceName(name)]
+			w.write(1, "%s:\t%s\n", name, q.String())
+		}
+	}
+	if u := node.Status.Usage; u != nil {
+		w.write(0, "Usage:\n")
+		w.write(1, "CPU:\t%.1f%%\n", u.CPUPercent)
+		w.write(1, "Memory:\t%.1f%%\n", u.MemoryPercent)
+		w.write(1, "Disk:\t%.1f%%\n", u.DiskPercent)
+	}
+	w.write(0, "System Info:\n")
+	w.write(1, "OS:\t%s\n", info.OS)
+	w.write(1, "Architecture:\t%s\n", info.Arch)
+	w.write(1, "Platform:\t%s %s\n", info.Platform, info.PlatformVersion)
+	w.write(1, "Kernel Version:\t%s\n", info.KernelVersion)
+	w.write(0, "Component Versions:\n")
+	w.write(1, "Name\tVersion\n")
+	w.write(1, "----\t-------\n")
+	if rt := node.Status.ContainerRuntimeInfo; rt.Type != "" {
+		w.write(1, "%s\t%s\n", rt.Type, containerRuntimeVersion(&rt))
+	}
+	for _, gpu := range node.Status.GPUs {
+		w.write(1, "%s driver (%s)\t%s\n", gpu.Vendor, gpu.Model, valueOrNone(gpu.DriverVersion))
+	}
+	if len(node.Status.Conditions) == 0 {
+		w.write(0, "Conditions:\t<none>\n")
+		return
+	}
+	w.write(0, "Conditions:\n")
+	w.write(1, "Type\tStatus\tLastHeartbeatTime\tLastTransitionTime\tReason\tMessage\n")
+	w.write(1, "----\t------\t-----------------\t------------------\t------\t-------\n")
+	for _, c := range node.Status.Conditions {
+		w.write(1, "%s\t%s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, formatTime(c.LastHeartbeatTime), formatTime(c.LastTransitionTime), c.Reason, c.Message)
+	}
+}
+
+func printOperation(w *prefixWriter, op *v1.Operation) {
+	writeObjectMeta(w, &op.ObjectMeta)
+	w.write(0, "Cluster:\t%s\n", valueOrNone(op.Labels[common.LabelClusterName]))
+	w.write(0, "Action:\t%s\n", valueOrNone(op.Labels[common.LabelOperationAction]))
+	w.write(0, "Status:\t%s\n", op.Status.Status)
+	if len(op.Steps) == 0 {
+		w.write(0, "Steps:\t<none>\n")
+		return
+	}
+	w.write(0, "Steps:\n")
+	w.write(1, "#\tName\tNodes\tStatus\n")
+	w.write(1, "-\t----\t-----\t------\n")
+	var failures []stepFailure
+	for i, step := range op.Steps {
+		status := "-"
+		for _, cond := range op.Status.Conditions {
+			if cond.StepID != step.ID {
+				continue
+			}
+			status = stepStatus(&cond, len(step.Nodes))
+			for _, s := range cond.Status {
+				if s.Status == v1.StepStatusFailed {
+					failures = append(failures, stepFailure{step: step.Name, StepStatus: s})
+				}
+			}
+		}
+		w.write(1, "%d\t%s\t%d\t%s\n", i+1, step.Name, len(step.Nodes), status)
+	}
+	if len(failures) == 0 {
+		return
+	}
+	w.write(0, "Failures:\n")
+	w.write(1, "Step\tNode\tReason\tMessage\n")
+	w.write(1, "----\t----\t------\t-------\n")
+	for _, f := range failures {
+		w.write(1, "%s\t%s\t%s\t%s\n", f.step, f.Node, f.Reason, f.Message)
+	}
+}
+
+// stepFailure is the status of a step on a node it failed on.
+type stepFailure struct {
+	step string
+	v1.StepStatus
+}
+
+// stepStatus summarizes the status of a step on its nodes, a step failed on any node failed.
+func stepStatus(cond *v1.OperationCondition, nodes int) string {
+	counts := make(map[v1.StepStatusType]int)
+	for _, s := range cond.Status {
+		counts[s.Status]++
+	}
+	switch {
+	case counts[v1.StepStatusFailed] > 0:
+		return string(v1.StepStatusFailed)
+	case len(cond.Status) < nodes:
+		return fmt.Sprintf("running (%d/%d)", len(cond.Status), nodes)
+	case counts[v1.StepStatusSkipped] == len(cond.Status):
+		return string(v1.StepStatusSkipped)
+	}
+	return string(v1.StepStatusSuccessful)
+}
+
+// runsOn reports whether a step of the operation runs on the node.
+func runsOn(op *v1.Operation, node string) bool {
+	for _, step := range op.Steps {
+		for _, n := range step.Nodes {
+			if n.ID == node {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func writeObjectMeta(w *prefixWriter, meta *metav1.ObjectMeta) {
+	w.write(0, "Name:\t%s\n", meta.Name)
+	writeMap(w, "Labels", meta.Labels)
+	writeMap(w, "Annotations", meta.Annotations)
+	w.write(0, "CreationTimestamp:\t%s\n", meta.CreationTimestamp.Time.Format(time.RFC1123Z))
+}
+
+func writeMap(w *prefixWriter, title string, m map[string]string) {
+	if len(m) == 0 {
+		w.write(0, "%s:\t<none>\n", title)
+		return
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		if i == 0 {
+			w.write(0, "%s:\t%s=%s\n", title, k, m[k])
+			continue
+		}
+		w.write(0, "\t%s=%s\n", k, m[k])
+	}
+}
+
+func writeHealth(w *prefixWriter, health []v1.ClusterHealthCondition) {
+	if len(health) == 0 {
+		w.write(0, "Conditions:\t<none>\n")
+		return
+	}
+	w.write(0, "Conditions:\n")
+	w.write(1, "Type\tStatus\tLastTransitionTime\tReason\tMessage\n")
+	w.write(1, "----\t------\t------------------\t------\t-------\n")
+	for _, c := range health {
+		w.write(1, "%s\t%s\t%s\t%s\t%s\n", c.Type, c.Status, formatTime(c.LastTransitionTime), c.Reason, c.Message)
+	}
+}
+
+func writeOperations(w *prefixWriter, ops []v1.Operation) {
+	if len(ops) == 0 {
+		w.write(0, "Operations:\t<none>\n")
+		return
+	}
+	w.write(0, "Operations:\n")
+	w.write(1, "Name\tAction\tStatus\tAge\n")
+	w.write(1, "----\t------\t------\t---\n")
+	for _, op := range ops {
+		w.write(1, "%s\t%s\t%s\t%s\n", op.Name, valueOrNone(op.Labels[common.LabelOperationAction]), op.Status.Status, age(op.CreationTimestamp))
+	}
+}
+
+func writeEvents(w *prefixWriter, events []v1.ObjectEvent) {
+	if len(events) == 0 {
+		w.write(0, "Events:\t<none>\n")
+		return
+	}
+	w.write(0, "Events:\n")
+	w.write(1, "Type\tReason\tAge\tFrom\tMessage\n")
+	w.write(1, "----\t------\t----\t----\t-------\n")
+	for _, e := range events {
+		interval := age(e.LastTimestamp)
+		if e.Count > 1 {
+			interval = fmt.Sprintf("%s (x%d over %s)", interval, e.Count, age(e.FirstTimestamp))
+		}
+		w.write(1, "%s\t%s\t%s\t%s\t%s\n", e.Type, e.Reason, interval, e.Source.Component, strings.TrimSpace(e.Message))
+	}
+}
+
+func containerRuntimeVersion(rt *v1.ContainerRuntime) string {
+	switch rt.Type {
+	case v1.CRIDocker:
+		return rt.Docker.Version
+	case v1.CRIContainerd:
+		return rt.Containerd.Version
+	}
+	return ""
+}
+
+func cniVersion(cni *v1.CNI) string {
+	switch cni.Type {
+	case "calico":
+		return cni.Calico.Version
+	case "flannel":
+		return cni.Flannel.Version
+	}
+	return ""
+}
+
+func age(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
+
+func formatTime(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return t.Time.Format(time.RFC1123Z)
+}
+
+func joinOrNone(values []string) string {
+	if len(values) == 0 {
+		return "<none>"
+	}
+	return strings.Join(values, ", ")
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+// prefixWriter aligns the tab separated columns of the lines written at the same level.
+type prefixWriter struct {
+	*tabwriter.Writer
+}
+
+func newPrefixWriter(out io.Writer) *prefixWriter {
+	return &prefixWriter{Writer: tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)}
+}
+
+func (w *prefixWriter) write(level int, format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(w.Writer, strings.Repeat("  ", level)+format, a...)
+}
@@ -0,0 +1,111 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package describe
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestPrintOperation(t *testing.T) {
+	op := &v1.Operation{
+		ObjectMeta: metav1.ObjectMeta{Name: "op1"},
+		Steps: []v1.Step{
+			{ID: "s1", Name: "installRuntime", Nodes: []v1.StepNode{{ID: "n1"}, {ID: "n2"}}},
+			{ID: "s2", Name: "kubeadmInit", Nodes: []v1.StepNode{{ID: "n1"}}},
+			{ID: "s3", Name: "joinWorkers", Nodes: []v1.StepNode{{ID: "n2"}}},
+		},
+		Status: v1.OperationStatus{
+			Status: v1.OperationStatusFailed,
+			Conditions: []v1.OperationCondition{
+				{StepID: "s1", Status: []v1.StepStatus{{Node: "n1", Status: v1.StepStatusSuccessful}, {Node: "n2", Status: v1.StepStatusSuccessful}}},
+				{StepID: "s2", Status: []v1.StepStatus{{Node: "n1", Status: v1.StepStatusFailed, Reason: "timeout", Message: "kubeadm init timed out"}}},
+			},
+		},
+	}
+	var buf bytes.Buffer
+	w := newPrefixWriter(&buf)
+	printOperation(w, op)
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Name:", "op1", "installRuntime", "successful", "kubeadmInit", "failed", "Failures:", "kubeadm init timed out"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("operation description misses %q:\n%s", want, out)
+		}
+	}
+	if !strings.Contains(lineOf(out, "joinWorkers"), "-") {
+		t.Errorf("step not started yet should have no status:\n%s", out)
+	}
+}
+
+func TestWriteEvents(t *testing.T) {
+	now := metav1.Now()
+	var buf bytes.Buffer
+	w := newPrefixWriter(&buf)
+	writeEvents(w, nil)
+	writeEvents(w, []v1.ObjectEvent{{
+		Type:           v1.ObjectEventWarning,
+		Reason:         v1.EventReasonNodeHeartbeatLost,
+		Message:        "node n1 stopped posting status",
+		Source:         v1.ObjectEventSource{Component: "node-status-monitor"},
+		FirstTimestamp: metav1.NewTime(now.Add(-10 * time.Minute)),
+		LastTimestamp:  now,
+		Count:          3,
+	}})
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(lineOf(out, "Events:"), "<none>") {
+		t.Errorf("no events should print <none>:\n%s", out)
+	}
+	for _, want := range []string{"Warning", "NodeHeartbeatLost", "(x3 over 10m)", "node-status-monitor", "stopped posting status"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("events miss %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunsOn(t *testing.T) {
+	op := &v1.Operation{Steps: []v1.Step{{Nodes: []v1.StepNode{{ID: "n1"}}}, {Nodes: []v1.StepNode{{ID: "n2"}}}}}
+	if !runsOn(op, "n2") {
+		t.Error("operation should run on n2")
+	}
+	if runsOn(op, "n3") {
+		t.Error("operation should not run on n3")
+	}
+}
+
+// lineOf returns the first line of out containing s.
+func lineOf(out, s string) string {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, s) {
+			return line
+		}
+	}
+	return ""
+}
@@ -22,7 +22,6 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
-	"strings"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -34,11 +33,13 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/i18n"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
 )
 
@@ -113,22 +114,8 @@ func NewCmdDrain(streams options.IOStreams) *cobra.Command {
 func (c *DrainOptions) listNode(toComplete string) []string {
 	utils.CheckErr(c.Complete())
 
-	q := query.New()
-	if !c.force { // without force flag only can delete node which is not in used
-		q.LabelSelector = fmt.Sprintf("!%s", common.LabelNodeRole)
-	}
-	nodes, err := c.client.ListNodes(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		return nil
-	}
-	set := sets.NewString()
-	for _, node := range nodes.Items {
-		if strings.HasPrefix(node.Status.Ipv4DefaultIP, toComplete) {
-			set.Insert(node.Status.Ipv4DefaultIP)
-		}
-	}
-
-	return set.List()
+	// without force flag only can delete node which is not in used
+	return completion.NodeIPs(c.client, !c.force, toComplete)
 }
 
 func (c *DrainOptions) Complete() error {
@@ -172,8 +159,8 @@ func (c *DrainOptions) preCheck() bool {
 	}
 
 	if c.force {
-		_, _ = c.IOStreams.Out.Write([]byte("force delete node which is in used maybe cause data inconsistency." +
-			"are you sure this node are not in used or your really want to force delete used node?  Please input (yes/no)"))
+		_, _ = c.IOStreams.Out.Write([]byte(i18n.T("force delete node which is in used maybe cause data inconsistency." +
+			"are you sure this node are not in used or your really want to force delete used node?  Please input (yes/no)")))
 		return utils.AskForConfirmation()
 	}
 
@@ -234,6 +221,7 @@ func (c *DrainOptions) agentFilesAndData(node *v1.Node) error {
 	cmdList := []string{
 		"systemctl disable kc-agent --now", // 	// disable agent service
 		"rm -rf /usr/local/bin/kubeclipper-agent /etc/kubeclipper-agent /usr/lib/systemd/system/kc-agent.service " + c.checkOplogDir(), // remove agent files
+		"rm -rf " + options.SystemdDropInDir(options.UnitKcAgent),                                                                      // remove agent drop-in
 	}
 
 	for _, v := range cmdList {
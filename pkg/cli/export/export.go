@@ -0,0 +1,161 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package export
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Export a cluster managed by kubeclipper as plain artifacts.
+
+  The exported files contain the kubeadm configuration, the node inventory and the
+  scripts needed to rebuild or keep operating the cluster without kubeclipper.
+  Use --format terraform to wrap the same scripts in a terraform configuration.`
+	exportExample = `
+  # Export cluster c1 as kubeadm config and scripts into ./c1-kubeadm
+  kcctl export cluster c1
+
+  # Export cluster c1 as terraform configuration into a specified directory
+  kcctl export cluster c1 --format terraform --output-dir /tmp/c1
+
+  Please read 'kcctl export -h' get more export flags.`
+)
+
+const resourceCluster = "cluster"
+
+type ExportOptions struct {
+	options.IOStreams
+	cliOpts *options.CliOptions
+	client  *kc.Client
+
+	name      string
+	format    string
+	outputDir string
+}
+
+func NewExportOptions(streams options.IOStreams) *ExportOptions {
+	return &ExportOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+		format:    string(v1.ClusterExportKubeadm),
+	}
+}
+
+func NewCmdExport(streams options.IOStreams) *cobra.Command {
+	o := NewExportOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "export cluster <name> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Export cluster as kubeadm or terraform artifacts",
+		Long:                  longDescription,
+		Example:               exportExample,
+		Args:                  cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return []string{resourceCluster}, cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return o.listCluster(toComplete), cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(args))
+			utils.CheckErr(o.Validate(args))
+			utils.CheckErr(o.RunExport())
+		},
+	}
+
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.format, "format", o.format, "export format, support kubeadm and terraform.")
+	cmd.Flags().StringVarP(&o.outputDir, "output-dir", "o", o.outputDir, "directory to write exported files, default is ./<name>-<format>.")
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(v1.ClusterExportKubeadm), string(v1.ClusterExportTerraform)}, cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
+
+func (o *ExportOptions) listCluster(toComplete string) []string {
+	utils.CheckErr(o.cliOpts.Complete())
+	client, err := o.cliOpts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return nil
+	}
+	return completion.Clusters(client, toComplete)
+}
+
+func (o *ExportOptions) Complete(args []string) error {
+	if err := o.cliOpts.Complete(); err != nil {
+		return err
+	}
+	if len(args) == 2 {
+		o.name = args[1]
+	}
+	if o.outputDir == "" {
+		o.outputDir = fmt.Sprintf("%s-%s", o.name, o.format)
+	}
+	var err error
+	o.client, err = o.cliOpts.ToRawConfig().ToKcClient()
+	return err
+}
+
+func (o *ExportOptions) Validate(args []string) error {
+	if args[0] != resourceCluster {
+		return fmt.Errorf("unsupported resource %q, only %q can be exported", args[0], resourceCluster)
+	}
+	if o.name == "" {
+		return fmt.Errorf("cluster name must be specified")
+	}
+	if !v1.ClusterExportFormat(o.format).Valid() {
+		return fmt.Errorf("unsupported export format %q", o.format)
+	}
+	return nil
+}
+
+func (o *ExportOptions) RunExport() error {
+	export, err := o.client.ExportCluster(context.TODO(), o.name, v1.ClusterExportFormat(o.format))
+	if err != nil {
+		return err
+	}
+	for _, f := range export.Files {
+		path := filepath.Join(o.outputDir, f.Path)
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		if err = os.WriteFile(path, []byte(f.Content), os.FileMode(f.Mode)); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(o.Out, "cluster %s exported to %s\n", o.name, o.outputDir)
+	return nil
+}
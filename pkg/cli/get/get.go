@@ -23,15 +23,23 @@ import (
 	"fmt"
 	"strings"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
 
 	"github.com/spf13/cobra"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/precheck"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
 )
 
@@ -54,13 +62,58 @@ const (
   kcctl get user --selector foo=bar
 
   # List user with field-selector
-  kcctl get user --field-selector .metadata.name=foo
+  kcctl get user --field-selector metadata.name=foo
+
+  # List the nodes and print their changes until interrupted
+  kcctl get node --watch
+
+  # Print the changes of cluster demo
+  kcctl get cluster demo -w -o wide
+
+  # List the running clusters, the newest last
+  kcctl get cluster --field-selector status.phase=Running --sort-by .metadata.creationTimestamp
+
+  # List the name and ip of the arm64 nodes
+  kcctl get node --field-selector status.nodeInfo.arch=arm64 -o custom-columns=NAME:.metadata.name,IP:.status.ipv4DefaultIP
+
+  # Print the ips of all nodes
+  kcctl get node -o jsonpath='{.items[*].status.ipv4DefaultIP}'
 
   # Describe user admin
   kcctl get user admin -o yaml
 
   # List other resource
-  kcctl get [role,cluster,node]
+  kcctl get [role,rolebinding,cluster,node]
+
+  # List clusters with their health and node counts
+  kcctl get cluster -o wide
+
+  # List the nodes of region us-west-1, the region preference of the user is used by default
+  kcctl get node --region us-west-1
+
+  # Show the users bound to role platform-admin
+  kcctl get rolebinding platform-admin
+
+  # List the precheck runs which checked node 192.168.10.10
+  kcctl get precheck --node 192.168.10.10
+
+  # List the machines of inventory provider lab which can be joined as nodes
+  kcctl get discoverednode lab --refresh
+
+  # Save a view kubeconfig of cluster demo, which expires in 8 hours
+  kcctl get kubeconfig demo --expiration 8h > demo.kubeconfig
+
+  # Get a cluster-admin kubeconfig of cluster demo
+  kcctl get kubeconfig demo --scope admin
+
+  # List the events of cluster demo, the latest last
+  kcctl get event --for cluster/demo
+
+  # List the warnings of all objects
+  kcctl get event --field-selector type=Warning
+
+  # Show why the nodes were rejected by a precheck run
+  kcctl get precheck 2b1e1b0e-7d3f-4c1e-9b5a-2f0d7d1a6c11 -o yaml
 
   Please read 'kcctl get -h' get more get flags`
 )
@@ -71,14 +124,26 @@ type GetOptions struct {
 	options.IOStreams
 	LabelSelector string
 	FieldSelector string
+	Node          string
+	Region        string
+	AllRegions    bool
 	Watch         bool
+	Refresh       bool
+	Scope         string
+	Expiration    string
+	SortBy        string
+	For           string
 	client        *kc.Client
 	resource      string
 	name          string
 }
 
 var (
-	allowedResource = sets.NewString(options.ResourceUser, options.ResourceRole, options.ResourceNode, options.ResourceCluster)
+	allowedResource = sets.NewString(options.ResourceUser, options.ResourceRole, options.ResourceRoleBinding, options.ResourceNode, options.ResourceCluster, options.ResourcePrecheck, options.ResourceDiscoveredNode, options.ResourceKubeconfig, options.ResourceEvent)
+	// regionalResource are labeled with the region they are in.
+	regionalResource = sets.NewString(options.ResourceNode, options.ResourceCluster)
+	// watchableResource can be watched with --watch.
+	watchableResource = sets.NewString(options.ResourceNode, options.ResourceCluster)
 )
 
 func NewGetOptions(streams options.IOStreams) *GetOptions {
@@ -92,7 +157,7 @@ func NewGetOptions(streams options.IOStreams) *GetOptions {
 func NewCmdGet(streams options.IOStreams) *cobra.Command {
 	o := NewGetOptions(streams)
 	cmd := &cobra.Command{
-		Use:                   "get [(-o|--output=)table|json|yaml] (TYPE [NAME | -l label] | TYPE/NAME ...) [flags]",
+		Use:                   "get [(-o|--output=)table|wide|json|yaml|custom-columns=...|jsonpath=...] (TYPE [NAME | -l label] | TYPE/NAME ...) [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Display one or many resources",
 		Long:                  longDescription,
@@ -100,6 +165,7 @@ func NewCmdGet(streams options.IOStreams) *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			utils.CheckErr(o.Complete(o.cliOpts))
 			utils.CheckErr(o.ValidateArgs(cmd, args))
+			o.applyPreferences(cmd)
 			utils.CheckErr(o.RunGet())
 		},
 		ValidArgsFunction: ValidArgsFunction(o),
@@ -108,7 +174,24 @@ func NewCmdGet(streams options.IOStreams) *cobra.Command {
 	cmd.Flags().BoolVarP(&o.Watch, "watch", "w", o.Watch, "After listing/getting the requested object, watch for changes.")
 	cmd.Flags().StringVarP(&o.LabelSelector, "selector", "l", o.LabelSelector, "Selector (label query) to filter on, supports '=', '==', and '!='.(e.g. -l key1=value1,key2=value2)")
 	cmd.Flags().StringVar(&o.FieldSelector, "field-selector", o.FieldSelector, "Selector (field query) to filter on, supports '=', '==', and '!='.(e.g. --field-selector key1=value1,key2=value2). The server only supports a limited number of field queries per type.")
+	cmd.Flags().StringVar(&o.SortBy, "sort-by", o.SortBy, "Field path the server sorts the list by, e.g. '.metadata.name' or '{.status.phase}'.")
+	cmd.Flags().StringVar(&o.Node, "node", o.Node, "Only list the precheck runs which checked the node IP.")
+	cmd.Flags().BoolVar(&o.Refresh, "refresh", o.Refresh, "List discovered nodes from the inventories instead of the cache of the server.")
+	cmd.Flags().StringVar(&o.Scope, "scope", string(v1.KubeconfigScopeView), "Scope of the kubeconfig, one of view, edit and admin.")
+	cmd.Flags().StringVar(&o.Expiration, "expiration", o.Expiration, "Lifetime of the kubeconfig, e.g. 8h, defaults to the one of the server.")
+	cmd.Flags().StringVar(&o.For, "for", o.For, "Only list the events of the object, in the form kind/name, e.g. cluster/demo.")
+	cmd.Flags().StringVar(&o.Region, "region", o.Region, "Only list the nodes and clusters of the region, defaults to the region preference of the user.")
+	cmd.Flags().BoolVar(&o.AllRegions, "all-regions", o.AllRegions, "List the nodes and clusters of all regions, ignoring the region preference of the user.")
 	o.PrintFlags.AddFlags(cmd)
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("region", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete(o.cliOpts))
+		return completion.Regions(o.client, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("node", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete(o.cliOpts))
+		return completion.NodeIPs(o.client, false, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
 	return cmd
 }
 
@@ -124,6 +207,20 @@ func (l *GetOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
 	if !allowedResource.Has(l.resource) {
 		return utils.UsageErrorf(cmd, "unsupported resource type,support %v now", allowedResource.List())
 	}
+	if l.Watch && !watchableResource.Has(l.resource) {
+		return utils.UsageErrorf(cmd, "--watch only supports %v now", watchableResource.List())
+	}
+	if strings.ContainsAny(query.ParseOrderBy(l.SortBy), "[]*@?") {
+		return utils.UsageErrorf(cmd, "--sort-by only supports field paths, e.g. .metadata.name")
+	}
+	if l.resource == options.ResourceKubeconfig {
+		if l.name == "" {
+			return utils.UsageErrorf(cmd, "You must specify the cluster to get the kubeconfig of")
+		}
+		if !v1.KubeconfigScope(l.Scope).Valid() {
+			return utils.UsageErrorf(cmd, "unsupported kubeconfig scope %s, support admin, edit and view", l.Scope)
+		}
+	}
 	return nil
 }
 
@@ -140,16 +237,60 @@ func (l *GetOptions) Complete(opts *options.CliOptions) error {
 }
 
 func (l *GetOptions) RunGet() error {
+	if l.resource == options.ResourceKubeconfig {
+		return l.kubeconfig()
+	}
+	if l.Watch {
+		return l.listAndWatch()
+	}
 	if l.name != "" {
 		return l.describe()
 	}
 	return l.list()
 }
 
+// applyPreferences takes the output format and region which are not given on the
+// command line from the preferences of the current user.
+func (l *GetOptions) applyPreferences(cmd *cobra.Command) {
+	needOutput := !cmd.Flags().Changed("output")
+	needRegion := !cmd.Flags().Changed("region") && !l.AllRegions && regionalResource.Has(l.resource)
+	if !needOutput && !needRegion {
+		return
+	}
+	user := l.cliOpts.ToRawConfig().CurrentUser()
+	if user == "" {
+		return
+	}
+	prefs, err := l.client.GetUserPreferences(context.TODO(), user)
+	if err != nil {
+		logger.V(2).Infof("get preferences of user %s failed: %v", user, err)
+		return
+	}
+	if needOutput {
+		l.PrintFlags.SetFormat(prefs.Output)
+	}
+	if needRegion {
+		l.Region = prefs.Region
+	}
+}
+
+func (l *GetOptions) labelSelector() string {
+	if l.Region == "" || l.AllRegions || !regionalResource.Has(l.resource) {
+		return l.LabelSelector
+	}
+	region := fmt.Sprintf("%s=%s", common.LabelTopologyRegion, l.Region)
+	if l.LabelSelector == "" {
+		return region
+	}
+	return l.LabelSelector + "," + region
+}
+
 func (l *GetOptions) list() error {
 	q := query.New()
-	q.LabelSelector = l.LabelSelector
+	q.LabelSelector = l.labelSelector()
 	q.FieldSelector = l.FieldSelector
+	q.OrderBy = query.ParseOrderBy(l.SortBy)
+	q.Fields = l.PrintFlags.Fields()
 	var (
 		result printer.ResourcePrinter
 		err    error
@@ -161,8 +302,23 @@ func (l *GetOptions) list() error {
 		result, err = l.client.ListUsers(context.TODO(), kc.Queries(*q))
 	case options.ResourceRole:
 		result, err = l.client.ListRoles(context.TODO(), kc.Queries(*q))
+	case options.ResourceRoleBinding:
+		result, err = l.client.ListRoleBindings(context.TODO(), kc.Queries(*q))
 	case options.ResourceCluster:
 		result, err = l.client.ListClusters(context.TODO(), kc.Queries(*q))
+	case options.ResourcePrecheck:
+		// the runs of deploy and join are kept locally until the server is reachable
+		if err = precheck.Upload(context.TODO(), l.client, precheck.DefaultDir); err != nil {
+			logger.Warnf("upload local precheck records failed: %v", err)
+		}
+		if l.Node != "" {
+			q.FuzzySearch = map[string]string{"node": l.Node}
+		}
+		result, err = l.client.ListPrechecks(context.TODO(), kc.Queries(*q))
+	case options.ResourceEvent:
+		result, err = l.listEvents(q)
+	case options.ResourceDiscoveredNode:
+		result, err = l.discoveredNodes("")
 	default:
 		return fmt.Errorf("unsupported resource")
 	}
@@ -185,8 +341,17 @@ func (l *GetOptions) describe() error {
 		result, err = l.client.DescribeUser(context.TODO(), l.name)
 	case options.ResourceRole:
 		result, err = l.client.DescribeRole(context.TODO(), l.name)
+	case options.ResourceRoleBinding:
+		result, err = l.client.DescribeRoleBinding(context.TODO(), l.name)
 	case options.ResourceCluster:
 		result, err = l.client.DescribeCluster(context.TODO(), l.name)
+	case options.ResourcePrecheck:
+		result, err = l.client.DescribePrecheck(context.TODO(), l.name)
+	case options.ResourceEvent:
+		result, err = l.client.DescribeEvent(context.TODO(), l.name)
+	case options.ResourceDiscoveredNode:
+		// the name of discovered nodes is their provider
+		result, err = l.discoveredNodes(l.name)
 	default:
 		return fmt.Errorf("unsupported resource")
 	}
@@ -196,84 +361,178 @@ func (l *GetOptions) describe() error {
 	return l.PrintFlags.Print(result, l.IOStreams.Out)
 }
 
-func ValidArgsFunction(o *GetOptions) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		utils.CheckErr(o.Complete(o.cliOpts))
-
-		if len(args) == 0 {
-			return allowedResource.List(), cobra.ShellCompDirectiveNoFileComp
+// listAndWatch prints the resources and then their changes, until it is interrupted or the
+// resource version it watches from is compacted.
+func (l *GetOptions) listAndWatch() error {
+	q := query.New()
+	q.LabelSelector = l.labelSelector()
+	q.FieldSelector = l.FieldSelector
+	if l.name != "" {
+		q.FieldSelector = strings.TrimPrefix(q.FieldSelector+",metadata.name="+l.name, ",")
+	}
+	q.OrderBy = query.ParseOrderBy(l.SortBy)
+	var (
+		result          printer.ResourcePrinter
+		resourceVersion string
+	)
+	switch l.resource {
+	case options.ResourceNode:
+		nodes, err := l.client.ListNodes(context.TODO(), kc.Queries(*q))
+		if err != nil {
+			return err
 		}
-		switch resource := args[0]; resource {
-		case options.ResourceUser:
-			return o.listUser(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
-		case options.ResourceRole:
-			return o.listRole(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		result, resourceVersion = nodes, nodes.ResourceVersion
+	case options.ResourceCluster:
+		clusters, err := l.client.ListClusters(context.TODO(), kc.Queries(*q))
+		if err != nil {
+			return err
+		}
+		result, resourceVersion = clusters, clusters.ResourceVersion
+	default:
+		return fmt.Errorf("unsupported resource")
+	}
+	if err := l.PrintFlags.Print(result, l.IOStreams.Out); err != nil {
+		return err
+	}
+	// the server ends the watch when its timeout expires, it is resumed from the last seen change
+	for {
+		q.ResourceVersion = resourceVersion
+		q.AllowWatchBookmarks = true
+		var (
+			w   watch.Interface
+			err error
+		)
+		switch l.resource {
 		case options.ResourceNode:
-			return o.listNode(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+			w, err = l.client.WatchNodes(context.TODO(), kc.Queries(*q))
 		case options.ResourceCluster:
-			return o.listCluster(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+			w, err = l.client.WatchClusters(context.TODO(), kc.Queries(*q))
+		}
+		if err != nil {
+			return err
+		}
+		if resourceVersion, err = l.printEvents(w, resourceVersion); err != nil {
+			return err
 		}
-		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 }
 
-func (l *GetOptions) listUser(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	q.LabelSelector = l.LabelSelector
-	q.FieldSelector = l.FieldSelector
-	users, err := l.client.ListUsers(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		return nil
+// printEvents prints the events until the watch ends, it returns the resource version of the last event.
+func (l *GetOptions) printEvents(w watch.Interface, resourceVersion string) (string, error) {
+	defer w.Stop()
+	for event := range w.ResultChan() {
+		if event.Type == watch.Error {
+			return "", apierrors.FromObject(event.Object)
+		}
+		accessor, err := meta.Accessor(event.Object)
+		if err != nil {
+			return "", err
+		}
+		resourceVersion = accessor.GetResourceVersion()
+		var result printer.ResourcePrinter
+		switch obj := event.Object.(type) {
+		case *v1.Node:
+			result = &kc.NodesList{Items: []v1.Node{*obj}}
+		case *v1.Cluster:
+			result = &kc.ClustersList{Items: []v1.Cluster{*obj}}
+		}
+		// bookmarks only carry the latest resource version
+		if event.Type == watch.Bookmark || result == nil {
+			continue
+		}
+		if err = l.PrintFlags.PrintEvent(string(event.Type), result, l.IOStreams.Out); err != nil {
+			return "", err
+		}
 	}
-	for _, v := range users.Items {
-		if strings.HasPrefix(v.Name, toComplete) {
-			list = append(list, v.Name)
+	return resourceVersion, nil
+}
+
+// kubeconfig writes the kubeconfig as is, so that it can be redirected to a file.
+// listEvents lists the events of the object given by --for, the latest last unless sorted otherwise.
+func (l *GetOptions) listEvents(q *query.Query) (printer.ResourcePrinter, error) {
+	if l.For != "" {
+		kind, name, _ := strings.Cut(l.For, "/")
+		if kind == "" || name == "" {
+			return nil, fmt.Errorf("--for must be in the form kind/name, e.g. cluster/demo")
+		}
+		requirement := fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", eventKind(kind), name)
+		if q.FieldSelector == "" {
+			q.FieldSelector = requirement
+		} else {
+			q.FieldSelector += "," + requirement
 		}
 	}
-	return list
+	if l.SortBy == "" {
+		q.OrderBy = "lastTimestamp"
+	}
+	return l.client.ListEvents(context.TODO(), kc.Queries(*q))
 }
 
-func (l *GetOptions) listRole(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	q.LabelSelector = l.LabelSelector
-	q.FieldSelector = l.FieldSelector
-	data, err := l.client.ListRoles(context.TODO(), kc.Queries(*q))
-	if err != nil {
-		return nil
+// eventKind turns the resource name given to kcctl into the kind of the involved objects.
+func eventKind(resource string) string {
+	switch strings.ToLower(resource) {
+	case "cluster", "clusters":
+		return "Cluster"
+	case "node", "nodes":
+		return "Node"
+	case "operation", "operations":
+		return "Operation"
+	case "backup", "backups":
+		return "Backup"
 	}
-	for _, v := range data.Items {
-		if strings.HasPrefix(v.Name, toComplete) {
-			list = append(list, v.Name)
-		}
+	return resource
+}
+
+func (l *GetOptions) kubeconfig() error {
+	kubeconfig, err := l.client.GetKubeconfig(context.TODO(), l.name, v1.KubeconfigScope(l.Scope), l.Expiration)
+	if err != nil {
+		return err
 	}
-	return list
+	_, err = fmt.Fprint(l.IOStreams.Out, kubeconfig.Kubeconfig)
+	return err
 }
 
-func (l *GetOptions) listNode(toComplete string) []string {
-	list := make([]string, 0)
-	q := query.New()
-	q.LabelSelector = l.LabelSelector
-	q.FieldSelector = l.FieldSelector
-	data, err := l.client.ListNodes(context.TODO(), kc.Queries(*q))
+func (l *GetOptions) discoveredNodes(provider string) (printer.ResourcePrinter, error) {
+	list, err := l.client.ListDiscoveredNodes(context.TODO(), provider, l.Refresh)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	for _, v := range data.Items {
-		if strings.HasPrefix(v.Name, toComplete) {
-			list = append(list, v.Name)
+	for p, msg := range list.Failures {
+		logger.Warnf("list machines of inventory provider %s failed: %s", p, msg)
+	}
+	return list, nil
+}
+
+func ValidArgsFunction(o *GetOptions) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete(o.cliOpts))
+
+		if len(args) == 0 {
+			return allowedResource.List(), cobra.ShellCompDirectiveNoFileComp
 		}
+		switch resource := args[0]; resource {
+		case options.ResourceUser:
+			return completion.Users(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceRole, options.ResourceRoleBinding:
+			// role bindings are named after their role
+			return completion.Roles(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceNode:
+			return completion.Nodes(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceCluster, options.ResourceKubeconfig:
+			return completion.Clusters(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourcePrecheck:
+			return o.listPrecheck(toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	return list
 }
 
-func (l *GetOptions) listCluster(toComplete string) []string {
+func (l *GetOptions) listPrecheck(toComplete string) []string {
 	list := make([]string, 0)
 	q := query.New()
 	q.LabelSelector = l.LabelSelector
 	q.FieldSelector = l.FieldSelector
-	data, err := l.client.ListClusters(context.TODO(), kc.Queries(*q))
+	data, err := l.client.ListPrechecks(context.TODO(), kc.Queries(*q))
 	if err != nil {
 		return nil
 	}
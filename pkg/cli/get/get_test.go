@@ -0,0 +1,66 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package get
+
+import (
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+)
+
+func TestLabelSelector(t *testing.T) {
+	tests := []struct {
+		name string
+		opts GetOptions
+		want string
+	}{
+		{
+			name: "no region",
+			opts: GetOptions{resource: options.ResourceNode, LabelSelector: "a=b"},
+			want: "a=b",
+		},
+		{
+			name: "region only",
+			opts: GetOptions{resource: options.ResourceNode, Region: "us-west-1"},
+			want: "topology.kubeclipper.io/region=us-west-1",
+		},
+		{
+			name: "region and selector",
+			opts: GetOptions{resource: options.ResourceCluster, Region: "us-west-1", LabelSelector: "a=b"},
+			want: "a=b,topology.kubeclipper.io/region=us-west-1",
+		},
+		{
+			name: "all regions",
+			opts: GetOptions{resource: options.ResourceNode, Region: "us-west-1", AllRegions: true},
+			want: "",
+		},
+		{
+			name: "resource without region",
+			opts: GetOptions{resource: options.ResourceUser, Region: "us-west-1"},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.labelSelector(); got != tt.want {
+				t.Errorf("labelSelector() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package i18n translates kcctl messages into the language of the user's locale.
+// Messages are looked up by their english text, format strings are translated
+// before the arguments are substituted.
+package i18n
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+const defaultLanguage = "en"
+
+var catalogs = map[string]map[string]string{
+	"zh": zhMessages,
+}
+
+var (
+	once     sync.Once
+	language string
+)
+
+// AddFlags adds the flag to override the language detected from the locale.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Var(languageValue{}, "lang", "Language of messages, e.g. en or zh_CN, detected from LC_ALL, LC_MESSAGES and LANG by default")
+}
+
+type languageValue struct{}
+
+func (languageValue) String() string {
+	return Language()
+}
+
+func (languageValue) Set(v string) error {
+	SetLanguage(v)
+	return nil
+}
+
+func (languageValue) Type() string {
+	return "string"
+}
+
+// SetLanguage sets the language of messages, it accepts locale names such as zh_CN.UTF-8.
+func SetLanguage(locale string) {
+	once.Do(func() {})
+	language = parseLanguage(locale)
+}
+
+// Language returns the language of messages.
+func Language() string {
+	once.Do(func() {
+		language = defaultLanguage
+		for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if v := os.Getenv(env); v != "" {
+				language = parseLanguage(v)
+				return
+			}
+		}
+	})
+	return language
+}
+
+// parseLanguage returns the language part of a locale, e.g. zh for zh_CN.UTF-8.
+func parseLanguage(locale string) string {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	if lang == "" || lang == "c" || lang == "posix" {
+		return defaultLanguage
+	}
+	return lang
+}
+
+// T returns the translation of msg, or msg itself when it has no translation.
+func T(msg string) string {
+	if t, ok := catalogs[Language()][msg]; ok {
+		return t
+	}
+	return msg
+}
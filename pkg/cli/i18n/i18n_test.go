@@ -0,0 +1,55 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package i18n
+
+import "testing"
+
+func TestParseLanguage(t *testing.T) {
+	tests := map[string]string{
+		"zh_CN.UTF-8":  "zh",
+		"zh-TW":        "zh",
+		"en_US":        "en",
+		"de_DE@euro":   "de",
+		"C":            "en",
+		"POSIX":        "en",
+		"":             "en",
+		"ZH_CN.gb2312": "zh",
+	}
+	for locale, want := range tests {
+		if got := parseLanguage(locale); got != want {
+			t.Errorf("parseLanguage(%q) = %q, want %q", locale, got, want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	defer SetLanguage(Language())
+
+	SetLanguage("zh_CN.UTF-8")
+	if got := T("clean successful"); got != zhMessages["clean successful"] {
+		t.Errorf("got %q, want the chinese translation", got)
+	}
+	if got := T("no translation"); got != "no translation" {
+		t.Errorf("untranslated message changed to %q", got)
+	}
+	SetLanguage("en_US.UTF-8")
+	if got := T("clean successful"); got != "clean successful" {
+		t.Errorf("english message changed to %q", got)
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package i18n
+
+var zhMessages = map[string]string{
+	// confirmations
+	"Ignore this error, still install? Please input (yes/no)":  "忽略此错误，继续安装？请输入 (yes/no)",
+	"Ignore this error, still exec cmd? Please input (yes/no)": "忽略此错误，继续执行命令？请输入 (yes/no)",
+	"force delete node which is in used maybe cause data inconsistency." +
+		"are you sure this node are not in used or your really want to force delete used node?  Please input (yes/no)": "强制删除使用中的节点可能导致数据不一致。" +
+		"确认该节点未被使用，或确实要强制删除使用中的节点？请输入 (yes/no)",
+	"I'm sorry but I didn't get what you meant, please type (y)es or (n)o and then press enter:": "无法识别您的输入，请输入 (y)es 或 (n)o 并按回车：",
+
+	// precheck
+	"============>%s PRECHECK ...":                               "============>%s 预检查中 ...",
+	"============>%s PRECHECK OK!":                               "============>%s 预检查通过！",
+	"===========>%s PRECHECK FAILED!":                            "===========>%s 预检查失败！",
+	"============>TIME-LAG PRECHECK ...":                         "============>时间差预检查中 ...",
+	"============>TIME-LAG PRECHECK OK!":                         "============>时间差预检查通过！",
+	"===========>TIME-LAG PRECHECK FAILED!":                      "===========>时间差预检查失败！",
+	"all nodes time lag less then 5 seconds":                     "所有节点时间差小于 5 秒",
+	"check node %s failed: %s":                                   "检查节点 %s 失败：%s",
+	"node %s is already deployed":                                "节点 %s 已部署",
+	"kc-agent service exist on %s, please clean old environment": "%s 上已存在 kc-agent 服务，请先清理旧环境",
+
+	// node management
+	"agent node join completed. show command: 'kcctl get node'":       "agent 节点加入完成。查看命令：'kcctl get node'",
+	"agent node drain completed. show command: 'kcctl get node'":      "agent 节点移除完成。查看命令：'kcctl get node'",
+	"join an agent node requires specifying at least one server node": "加入 agent 节点需要至少指定一个 server 节点",
+	"agent %s is not in agent nodes":                                  "agent %s 不在 agent 节点列表中",
+
+	// resources and registry
+	"clean successful":                                       "清理成功",
+	"resource push successfully":                             "资源推送成功",
+	"resource delete successfully":                           "资源删除成功",
+	"process package successfully":                           "处理安装包成功",
+	"remove pkg successfully":                                "删除安装包成功",
+	"install registry successfully":                          "安装镜像仓库成功",
+	"registry uninstall successfully":                        "卸载镜像仓库成功",
+	"registry and images install successfully":               "镜像仓库及镜像安装成功",
+	"image push successfully":                                "镜像推送成功",
+	"image load successfully":                                "镜像加载成功",
+	"node(%s) push resource failed: %s":                      "节点(%s)推送资源失败：%s",
+	"get component meta failed: %s. please check .kc/config": "获取组件元数据失败：%s。请检查 .kc/config",
+}
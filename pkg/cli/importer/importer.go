@@ -0,0 +1,247 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package importer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/join"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Import existing clusters into kubeclipper.
+
+  The nodes of the cluster which are not kubeclipper nodes yet are joined as agents over ssh
+  with the deploy config, then the server discovers the topology of the cluster and creates it
+  as imported, so it can be upgraded and backed up like the clusters installed by kubeclipper.`
+	importExample = `
+  # Import the kubeadm cluster of the kubeconfig as cluster 'prod'.
+  kcctl import cluster --name prod --kubeconfig ~/.kube/config
+
+  # Show the cluster discovered from the kubeconfig without joining nodes or importing it.
+  kcctl import cluster --name prod --kubeconfig ~/.kube/config --dry-run -o yaml
+
+  # Import the cluster and join its nodes as agents of region us-west-1.
+  kcctl import cluster --name prod --kubeconfig ~/.kube/config --region us-west-1
+
+  Please read 'kcctl import cluster -h' get more import flags.`
+	// registerTimeout is how long joined agents are waited for to register their nodes.
+	registerTimeout = 3 * time.Minute
+)
+
+type ImportOptions struct {
+	options.IOStreams
+	PrintFlags   *printer.PrintFlags
+	cliOpts      *options.CliOptions
+	client       *kc.Client
+	deployConfig string
+
+	Name                string
+	Description         string
+	Kubeconfig          string
+	Region              string
+	DryRun              bool
+	SkipSignatureVerify bool
+}
+
+func NewImportOptions(streams options.IOStreams) *ImportOptions {
+	return &ImportOptions{
+		IOStreams:    streams,
+		PrintFlags:   printer.NewPrintFlags(),
+		cliOpts:      options.NewCliOptions(),
+		deployConfig: options.DefaultDeployConfigPath,
+	}
+}
+
+func NewCmdImport(streams options.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "import",
+		DisableFlagsInUseLine: true,
+		Short:                 "Import existing resources into kubeclipper",
+		Long:                  longDescription,
+		Example:               importExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	cmd.AddCommand(NewCmdImportCluster(streams))
+	return cmd
+}
+
+func NewCmdImportCluster(streams options.IOStreams) *cobra.Command {
+	o := NewImportOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "cluster (--name NAME) (--kubeconfig FILE) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Import an existing kubeadm cluster",
+		Long:                  longDescription,
+		Example:               importExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.RunImport())
+		},
+	}
+	cmd.Flags().StringVar(&o.Name, "name", o.Name, "Name of the imported cluster.")
+	cmd.Flags().StringVar(&o.Description, "description", o.Description, "Description of the imported cluster.")
+	cmd.Flags().StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "Kubeconfig with cluster-admin access to the cluster.")
+	cmd.Flags().StringVar(&o.Region, "region", o.Region, "Region the nodes of the cluster are joined as agents of, the default region of the deploy config when empty.")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "Print the discovered cluster without joining nodes or importing it.")
+	cmd.Flags().StringVar(&o.deployConfig, "deploy-config", o.deployConfig, "kcctl deploy config path")
+	cmd.Flags().BoolVar(&o.SkipSignatureVerify, "skip-signature-verify", o.SkipSignatureVerify, "Join agents without verifying the signature of the package against the trusted keys")
+	o.cliOpts.AddFlags(cmd.Flags())
+	o.PrintFlags.AddFlags(cmd)
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("region", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if o.Complete() != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return completion.Regions(o.client, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+	utils.CheckErr(cmd.MarkFlagRequired("name"))
+	utils.CheckErr(cmd.MarkFlagRequired("kubeconfig"))
+	return cmd
+}
+
+func (o *ImportOptions) Complete() error {
+	if err := o.cliOpts.Complete(); err != nil {
+		return err
+	}
+	c, err := o.cliOpts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
+
+func (o *ImportOptions) ValidateArgs(cmd *cobra.Command) error {
+	if o.Name == "" {
+		return utils.UsageErrorf(cmd, "--name must be specified")
+	}
+	if o.Kubeconfig == "" {
+		return utils.UsageErrorf(cmd, "--kubeconfig must be specified")
+	}
+	return nil
+}
+
+func (o *ImportOptions) RunImport() error {
+	kubeconfig, err := os.ReadFile(o.Kubeconfig)
+	if err != nil {
+		return err
+	}
+	_, clientset, err := client.FromKubeConfig(kubeconfig)
+	if err != nil {
+		return err
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("list nodes of the cluster: %v", err)
+	}
+	if !o.DryRun {
+		if err = o.joinAgents(nodeIPs(nodes.Items)); err != nil {
+			return err
+		}
+	}
+	clusters, err := o.client.ImportCluster(context.TODO(), &v1.ClusterImport{
+		Name:        o.Name,
+		Description: o.Description,
+		Kubeconfig:  string(kubeconfig),
+	}, o.DryRun)
+	if err != nil {
+		return err
+	}
+	return o.PrintFlags.Print(clusters, o.IOStreams.Out)
+}
+
+// joinAgents joins the ips which are not kubeclipper nodes yet, and waits for them to register.
+func (o *ImportOptions) joinAgents(ips []string) error {
+	missing, err := o.unregistered(ips)
+	if err != nil {
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	logger.Infof("join nodes %v of the cluster as agents", missing)
+	if err = join.JoinAgents(o.IOStreams, o.deployConfig, o.SkipSignatureVerify, o.Region, missing); err != nil {
+		return err
+	}
+	return wait.PollImmediate(5*time.Second, registerTimeout, func() (bool, error) {
+		missing, err = o.unregistered(ips)
+		if err != nil {
+			return false, err
+		}
+		return len(missing) == 0, nil
+	})
+}
+
+// unregistered returns the ips without kubeclipper node.
+func (o *ImportOptions) unregistered(ips []string) ([]string, error) {
+	nodes, err := o.client.ListNodes(context.TODO(), kc.Queries{Pagination: query.NoPagination()})
+	if err != nil {
+		return nil, err
+	}
+	registered := sets.NewString()
+	for _, n := range nodes.Items {
+		registered.Insert(n.Status.Ipv4DefaultIP)
+	}
+	var missing []string
+	for _, ip := range ips {
+		if !registered.Has(ip) {
+			missing = append(missing, ip)
+		}
+	}
+	return missing, nil
+}
+
+// nodeIPs returns the internal ips of nodes.
+func nodeIPs(nodes []corev1.Node) []string {
+	var ips []string
+	for _, n := range nodes {
+		for _, addr := range n.Status.Addresses {
+			if addr.Type == corev1.NodeInternalIP {
+				ips = append(ips, addr.Address)
+				break
+			}
+		}
+	}
+	return ips
+}
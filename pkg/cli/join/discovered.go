@@ -0,0 +1,92 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package join
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
+)
+
+// machineLookup finds a machine of an inventory provider.
+type machineLookup func(provider, id string) (*inventory.Machine, error)
+
+// discoveredAgents turns the discovered machines, given as [region:]provider/id[,provider/id],
+// into agents given as region:ip.
+func discoveredAgents(discovered []string, defaultRegion string, lookup machineLookup) ([]string, error) {
+	var agents []string
+	for _, v := range discovered {
+		region, machines, err := parseAgentRegion(v, defaultRegion)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range strings.Split(machines, ",") {
+			provider, id, ok := strings.Cut(ref, "/")
+			if !ok || provider == "" || id == "" {
+				return nil, fmt.Errorf("invalid discovered node %q, must be provider/id", ref)
+			}
+			m, err := lookup(provider, id)
+			if err != nil {
+				return nil, err
+			}
+			if m.Node != "" {
+				return nil, fmt.Errorf("discovered node %s is registered as node %s already", ref, m.Node)
+			}
+			if len(m.IPs) == 0 {
+				return nil, fmt.Errorf("discovered node %s has no known ip, is it running?", ref)
+			}
+			agents = append(agents, fmt.Sprintf("%s:%s", region, m.IPs[0]))
+		}
+	}
+	return agents, nil
+}
+
+// lookupDiscovered finds the machines through the server, the machines of a provider are listed once.
+func (c *JoinOptions) lookupDiscovered() (machineLookup, error) {
+	if err := c.cliOpts.Complete(); err != nil {
+		return nil, fmt.Errorf("load config failed, please run 'kcctl login' first: %v", err)
+	}
+	client, err := c.cliOpts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return nil, err
+	}
+	listed := make(map[string][]inventory.Machine)
+	return func(provider, id string) (*inventory.Machine, error) {
+		machines, ok := listed[provider]
+		if !ok {
+			list, err := client.ListDiscoveredNodes(context.TODO(), provider, true)
+			if err != nil {
+				return nil, err
+			}
+			if msg, failed := list.Failures[provider]; failed {
+				return nil, fmt.Errorf("list machines of inventory provider %s failed: %s", provider, msg)
+			}
+			machines = list.Items
+			listed[provider] = machines
+		}
+		for i := range machines {
+			if machines[i].ID == id {
+				return &machines[i], nil
+			}
+		}
+		return nil, fmt.Errorf("machine %s not found in inventory provider %s", id, provider)
+	}, nil
+}
@@ -0,0 +1,67 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package join
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
+)
+
+func TestDiscoveredAgents(t *testing.T) {
+	machines := map[string]inventory.Machine{
+		"lab/100": {ID: "100", IPs: []string{"10.0.0.100", "fd00::100"}},
+		"lab/101": {ID: "101", IPs: []string{"10.0.0.101"}},
+		"lab/102": {ID: "102"},
+		"lab/103": {ID: "103", IPs: []string{"10.0.0.103"}, Node: "node-103"},
+	}
+	lookup := func(provider, id string) (*inventory.Machine, error) {
+		m, ok := machines[provider+"/"+id]
+		if !ok {
+			return nil, fmt.Errorf("machine %s not found", id)
+		}
+		return &m, nil
+	}
+	tests := []struct {
+		name       string
+		discovered []string
+		want       []string
+		wantErr    bool
+	}{
+		{name: "default region", discovered: []string{"lab/100,lab/101"}, want: []string{"default:10.0.0.100", "default:10.0.0.101"}},
+		{name: "region", discovered: []string{"us-west-1:lab/101"}, want: []string{"us-west-1:10.0.0.101"}},
+		{name: "no ip", discovered: []string{"lab/102"}, wantErr: true},
+		{name: "registered", discovered: []string{"lab/103"}, wantErr: true},
+		{name: "not found", discovered: []string{"lab/999"}, wantErr: true},
+		{name: "invalid", discovered: []string{"lab"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := discoveredAgents(tt.discovered, "default", lookup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("discoveredAgents() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("discoveredAgents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"path"
 	"path/filepath"
+	"strings"
 	"text/template"
 
 	"github.com/google/uuid"
@@ -31,7 +32,9 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/config"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/precheck"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/sudo"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
@@ -83,6 +86,16 @@ const (
   # this will add 10 agent,1.1.1.1, 1.1.1.2, ... 1.1.1.10.
   kcctl join --agent us-west-1:1.1.1.1-1.1.1.10
 
+  # Add the machine 100 of inventory provider lab as agent node, see 'kcctl get discoverednode'.
+  kcctl join --discovered lab/100
+
+  # Add windows agent node over ssh, the ssh user must be an administrator of the node.
+  kcctl join --windows-agent 192.168.10.125 --windows-agent-binary ./kubeclipper-agent.exe
+
+  # Add agent node with its asset id in the CMDB, it is labeled kubeclipper.io/external-id=ASSET-0042.
+  # Set nodeIDSource of the deploy config to machine-id or smbios-uuid to keep the id of re-provisioned nodes.
+  kcctl join --agent 192.168.10.126 --external-id 192.168.10.126=ASSET-0042
+
 
   Please read 'kcctl join -h' get more deploy flags`
 )
@@ -94,12 +107,26 @@ type JoinOptions struct {
 	agents      []string       // user input agents,maybe with region,need to parse.
 	agentRegion options.Agents // format agents
 	servers     []string
+
+	windowsAgents      []string // user input windows agents, same format as agents.
+	windowsAgentRegion options.Agents
+	windowsAgentBinary string
+
+	// discovered are machines of inventories joined as agents, found through the server.
+	discovered []string
+	cliOpts    *options.CliOptions
+
+	// externalIDs are merged into the external ids of the deploy config.
+	externalIDs map[string]string
+	// agentIDs are the ids of the agents to join by ip.
+	agentIDs map[string]string
 }
 
 func NewJoinOptions(streams options.IOStreams) *JoinOptions {
 	return &JoinOptions{
 		IOStreams:    streams,
 		deployConfig: options.NewDeployOptions(),
+		cliOpts:      options.NewCliOptions(),
 	}
 }
 
@@ -123,23 +150,67 @@ func NewCmdJoin(streams options.IOStreams) *cobra.Command {
 	}
 
 	cmd.Flags().StringArrayVar(&o.agents, "agent", o.agents, "join agent node.")
+	cmd.Flags().StringArrayVar(&o.windowsAgents, "windows-agent", o.windowsAgents, "join windows agent node, provisioned over ssh.")
+	cmd.Flags().StringVar(&o.windowsAgentBinary, "windows-agent-binary", o.windowsAgentBinary, "path of the kubeclipper-agent.exe installed on windows agent nodes.")
+	cmd.Flags().StringArrayVar(&o.discovered, "discovered", o.discovered, "join discovered node as agent, format as [region:]provider/id, see 'kcctl get discoverednode'.")
+	cmd.Flags().StringToStringVar(&o.externalIDs, "external-id", o.externalIDs, "id of an agent node in an external inventory, format as ip=id, the node is labeled kubeclipper.io/external-id with it.")
 	cmd.Flags().StringVar(&o.deployConfig.Config, "deploy-config", options.DefaultDeployConfigPath, "kcctl deploy config path")
-	utils.CheckErr(cmd.MarkFlagRequired("agent"))
+	o.deployConfig.AddSignatureFlags(cmd.Flags())
+	o.cliOpts.AddFlags(cmd.Flags())
 	return cmd
 }
 
 func (c *JoinOptions) preCheck() bool {
+	// windows nodes have no sudo, the ssh user must be an administrator instead
 	if !sudo.PreCheck("sudo", c.deployConfig.SSHConfig, c.IOStreams, append(c.agentRegion.ListIP(), c.servers...)) {
 		return false
 	}
 	// check if the node is already added
+	recorder := precheck.NewRecorder(v1.PrecheckSourceJoin, append(c.agentRegion.ListIP(), c.windowsAgentRegion.ListIP()...))
+	passed := true
 	for _, agent := range c.agentRegion.ListIP() {
-		if !c.preCheckKcAgent(agent) {
-			return false
+		err := c.preCheckKcAgent(agent)
+		recorder.Add(agent, "kc-agent", err)
+		if err != nil {
+			logger.Error(err)
+			passed = false
 		}
 	}
+	for _, agent := range c.windowsAgentRegion.ListIP() {
+		err := c.preCheckWindowsAgent(agent)
+		recorder.Add(agent, "kc-agent", err)
+		if err != nil {
+			logger.Error(err)
+			passed = false
+		}
+	}
+	if err := recorder.Save(precheck.DefaultDir); err != nil {
+		logger.Warnf("save precheck record failed: %v", err)
+	}
+	return passed
+}
 
-	return true
+// JoinAgents joins the nodes at ips as agents of region, the default region when it is empty,
+// like 'kcctl join --agent region:ip,...' with the deploy config at deployConfig.
+func JoinAgents(streams options.IOStreams, deployConfig string, skipSignatureVerify bool, region string, ips []string) error {
+	o := NewJoinOptions(streams)
+	o.deployConfig.Config = deployConfig
+	o.deployConfig.SkipSignatureVerify = skipSignatureVerify
+	agents := strings.Join(ips, ",")
+	if region != "" {
+		agents = region + ":" + agents
+	}
+	o.agents = []string{agents}
+	if err := o.Complete(); err != nil {
+		return err
+	}
+	if err := o.ValidateArgs(); err != nil {
+		return err
+	}
+	if !o.preCheck() {
+		return fmt.Errorf("precheck of agent nodes %s failed", strings.Join(ips, ","))
+	}
+	return o.RunJoinFunc()
 }
 
 func (c *JoinOptions) Complete() error {
@@ -148,23 +219,52 @@ func (c *JoinOptions) Complete() error {
 		return err
 	}
 
+	if len(c.discovered) > 0 {
+		lookup, err := c.lookupDiscovered()
+		if err != nil {
+			return err
+		}
+		discovered, err := discoveredAgents(c.discovered, c.deployConfig.DefaultRegion, lookup)
+		if err != nil {
+			return err
+		}
+		c.agents = append(c.agents, discovered...)
+	}
 	agents, err := BuildAgentRegion(c.agents, c.deployConfig.DefaultRegion)
 	utils.CheckErr(err)
 	c.agentRegion = agents
+	windowsAgents, err := BuildAgentRegion(c.windowsAgents, c.deployConfig.DefaultRegion)
+	utils.CheckErr(err)
+	c.windowsAgentRegion = windowsAgents
+	if c.deployConfig.WindowsAgentRegions == nil {
+		c.deployConfig.WindowsAgentRegions = make(options.Agents)
+	}
 	c.servers = sets.NewString(c.servers...).List()
+	if len(c.externalIDs) > 0 && c.deployConfig.ExternalIDs == nil {
+		c.deployConfig.ExternalIDs = make(map[string]string, len(c.externalIDs))
+	}
+	for ip, id := range c.externalIDs {
+		c.deployConfig.ExternalIDs[ip] = id
+	}
 	return nil
 }
 
 func (c *JoinOptions) ValidateArgs() error {
-	if len(c.agents) == 0 {
+	if len(c.agents) == 0 && len(c.windowsAgents) == 0 {
 		return fmt.Errorf("must specified at least one agent node")
 	}
+	if len(c.windowsAgents) > 0 && c.windowsAgentBinary == "" {
+		return fmt.Errorf("--windows-agent-binary must be specified to join windows agent node")
+	}
 	if len(c.deployConfig.ServerIPs) == 0 {
 		logger.Error("join an agent node requires specifying at least one server node")
 		logger.Info("example: kcctl join --agent 172.10.10.20 --server 172.10.10.10")
 		return fmt.Errorf("join an agent node requires specifying at least one server node")
 	}
-	return nil
+	if err := c.deployConfig.ValidateNodeIdentity(); err != nil {
+		return err
+	}
+	return c.deployConfig.SystemdOverrides.Validate()
 }
 
 func (c *JoinOptions) RunJoinFunc() error {
@@ -177,19 +277,35 @@ func (c *JoinOptions) RunJoinFunc() error {
 }
 
 func (c *JoinOptions) RunJoinNode() error {
-	if err := c.runJoinServerNode(); err != nil {
+	ips := append(c.agentRegion.ListIP(), c.windowsAgentRegion.ListIP()...)
+	agentIDs, err := utils.AgentIDs(c.deployConfig.NodeIDSource, ips, c.readNodeID)
+	if err != nil {
+		return err
+	}
+	c.agentIDs = agentIDs
+
+	if err = c.runJoinServerNode(); err != nil {
 		return fmt.Errorf("join server node failed: %s", err.Error())
 	}
 
-	if err := c.runJoinAgentNode(); err != nil {
+	if err = c.runJoinAgentNode(); err != nil {
 		return fmt.Errorf("join agent node failed: %s", err.Error())
 	}
 
+	if err = c.runJoinWindowsAgentNode(); err != nil {
+		return fmt.Errorf("join windows agent node failed: %s", err.Error())
+	}
+
 	return nil
 }
 
 func (c *JoinOptions) runJoinAgentNode() error {
 	var err error
+	if len(c.agentRegion) > 0 {
+		if err = utils.VerifyPackage(c.deployConfig.Pkg, c.deployConfig.TrustedKeys, c.deployConfig.SkipSignatureVerify); err != nil {
+			return err
+		}
+	}
 	for region, agents := range c.agentRegion {
 		for _, agent := range agents {
 			if err = c.agentNodeFiles(region, agent); err != nil {
@@ -205,24 +321,21 @@ func (c *JoinOptions) runJoinAgentNode() error {
 	return nil
 }
 
-func (c *JoinOptions) preCheckKcAgent(ip string) bool {
+func (c *JoinOptions) preCheckKcAgent(ip string) error {
 	// check if the node is already in deploy config
-	if c.deployConfig.AgentRegions.Exists(ip) {
-		logger.Errorf("node %s is already deployed", ip)
-		return false
+	if c.deployConfig.AgentRegions.Exists(ip) || c.deployConfig.WindowsAgentRegions.Exists(ip) {
+		return fmt.Errorf("node %s is already deployed", ip)
 	}
 	// check if kc-agent is running
 	ret, err := sshutils.SSHCmdWithSudo(c.deployConfig.SSHConfig, ip, "systemctl --all --type service | grep -Fq kc-agent")
 	logger.V(2).Info(ret.String())
 	if err != nil {
-		logger.Errorf("check node %s failed: %s", ip, err.Error())
-		return false
+		return fmt.Errorf("check node %s failed: %s", ip, err.Error())
 	}
 	if ret.ExitCode == 0 && ret.Stdout != "" {
-		logger.Errorf("kc-agent service exist on %s, please clean old environment", ip)
-		return false
+		return fmt.Errorf("kc-agent service exist on %s, please clean old environment", ip)
 	}
-	return true
+	return nil
 }
 
 func (c *JoinOptions) agentNodeFiles(region, node string) error {
@@ -241,9 +354,10 @@ func (c *JoinOptions) agentNodeFiles(region, node string) error {
 	if err != nil {
 		return err
 	}
-	agentConfig := c.getKcAgentConfigTemplateContent(region)
+	agentConfig := c.getKcAgentConfigTemplateContent(region, node)
 	cmdList := []string{
 		sshutils.WrapEcho(config.KcAgentService, "/usr/lib/systemd/system/kc-agent.service"), // write systemd file
+		c.deployConfig.SystemdOverrides.DropInCmd(options.UnitKcAgent),                       // write systemd drop-in
 		"mkdir -pv /etc/kubeclipper-agent ",
 		sshutils.WrapEcho(agentConfig, "/etc/kubeclipper-agent/kubeclipper-agent.yaml"), // write agent.yaml
 	}
@@ -294,15 +408,34 @@ func (c *JoinOptions) enableServerService() error {
 	return nil
 }
 
-func (c *JoinOptions) getKcAgentConfigTemplateContent(region string) string {
-	tmpl, err := template.New("text").Parse(config.KcAgentConfigTmpl)
+// readNodeID reads the identity of linux nodes over ssh, and of windows nodes with PowerShell.
+func (c *JoinOptions) readNodeID(ip, source string) (string, error) {
+	if !c.windowsAgentRegion.Exists(ip) {
+		return utils.SSHNodeIDReader(c.deployConfig.SSHConfig)(ip, source)
+	}
+	ret, err := c.runPowerShell(ip, config.KcAgentWindowsNodeIDTmpl, map[string]string{"Source": source})
 	if err != nil {
-		logger.Fatalf("template parse failed: %s", err.Error())
+		return "", err
 	}
+	if err = ret.Error(); err != nil {
+		return "", err
+	}
+	return ret.Stdout, nil
+}
 
+func (c *JoinOptions) getKcAgentConfigTemplateContent(region, node string) string {
+	return renderAgentConfig(c.agentConfigData(region, node))
+}
+
+func (c *JoinOptions) agentConfigData(region, node string) map[string]interface{} {
 	var data = make(map[string]interface{})
 	data["Region"] = region
-	data["AgentID"] = uuid.New().String()
+	id, ok := c.agentIDs[node]
+	if !ok {
+		id = uuid.New().String()
+	}
+	data["AgentID"] = id
+	data["ExternalID"] = c.deployConfig.ExternalIDs[node]
 	data["StaticServerAddress"] = fmt.Sprintf("http://%s:%d", c.deployConfig.ServerIPs[0], c.deployConfig.StaticServerPort)
 	if c.deployConfig.Debug {
 		data["LogLevel"] = "debug"
@@ -331,6 +464,15 @@ func (c *JoinOptions) getKcAgentConfigTemplateContent(region string) string {
 	}
 	data["OpLogDir"] = c.deployConfig.OpLog.Dir
 	data["OpLogThreshold"] = c.deployConfig.OpLog.Threshold
+	data["TrustedKeys"] = c.deployConfig.TrustedKeys
+	return data
+}
+
+func renderAgentConfig(data map[string]interface{}) string {
+	tmpl, err := template.New("text").Parse(config.KcAgentConfigTmpl)
+	if err != nil {
+		logger.Fatalf("template parse failed: %s", err.Error())
+	}
 	var buffer bytes.Buffer
 	if err = tmpl.Execute(&buffer, data); err != nil {
 		logger.Fatalf("template execute failed: %s", err.Error())
@@ -339,23 +481,8 @@ func (c *JoinOptions) getKcAgentConfigTemplateContent(region string) string {
 }
 
 func (c *JoinOptions) sendCerts() error {
-	// download cert from server
-	files := []string{
-		c.deployConfig.MQ.CA,
-		c.deployConfig.MQ.ClientCert,
-		c.deployConfig.MQ.ClientKey,
-	}
-
-	for _, file := range files {
-		exist, err := sshutils.IsFileExist(file)
-		if err != nil {
-			return errors.WithMessage(err, "check file exist")
-		}
-		if !exist {
-			if err = c.deployConfig.SSHConfig.DownloadSudo(c.deployConfig.ServerIPs[0], file, file); err != nil {
-				return errors.WithMessage(err, "download cert from server")
-			}
-		}
+	if err := c.fetchCerts(); err != nil {
+		return err
 	}
 
 	if c.deployConfig.MQ.TLS {
@@ -385,3 +512,25 @@ func (c *JoinOptions) sendCerts() error {
 
 	return nil
 }
+
+// fetchCerts downloads the mq certs from the first server unless they are local already.
+func (c *JoinOptions) fetchCerts() error {
+	files := []string{
+		c.deployConfig.MQ.CA,
+		c.deployConfig.MQ.ClientCert,
+		c.deployConfig.MQ.ClientKey,
+	}
+
+	for _, file := range files {
+		exist, err := sshutils.IsFileExist(file)
+		if err != nil {
+			return errors.WithMessage(err, "check file exist")
+		}
+		if !exist {
+			if err = c.deployConfig.SSHConfig.DownloadSudo(c.deployConfig.ServerIPs[0], file, file); err != nil {
+				return errors.WithMessage(err, "download cert from server")
+			}
+		}
+	}
+	return nil
+}
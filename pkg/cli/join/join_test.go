@@ -0,0 +1,73 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package join
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+)
+
+func TestRenderAgentConfigTrustedKeys(t *testing.T) {
+	keys := []string{
+		"-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE\n-----END PUBLIC KEY-----\n",
+		"untrusted comment: minisign public key\nRWQBAgMEBQYHCA==",
+	}
+	for _, tt := range [][]string{nil, keys} {
+		out := renderAgentConfig(map[string]interface{}{
+			"AgentID":             "id",
+			"Region":              "default",
+			"StaticServerAddress": "http://127.0.0.1:8081",
+			"TrustedKeys":         tt,
+		})
+		var conf struct {
+			Downloader downloader.Options `yaml:"downloader"`
+		}
+		if err := yaml.Unmarshal([]byte(out), &conf); err != nil {
+			t.Fatalf("unmarshal agent config: %v\n%s", err, out)
+		}
+		if !reflect.DeepEqual(conf.Downloader.TrustedKeys, tt) {
+			t.Errorf("trusted keys = %q, want %q", conf.Downloader.TrustedKeys, tt)
+		}
+	}
+}
+
+func TestRenderAgentConfigExternalID(t *testing.T) {
+	for _, id := range []string{"", "ASSET-0001"} {
+		out := renderAgentConfig(map[string]interface{}{
+			"AgentID":             "id",
+			"ExternalID":          id,
+			"Region":              "default",
+			"StaticServerAddress": "http://127.0.0.1:8081",
+		})
+		var conf struct {
+			AgentID    string `yaml:"agentID"`
+			ExternalID string `yaml:"externalID"`
+		}
+		if err := yaml.Unmarshal([]byte(out), &conf); err != nil {
+			t.Fatalf("unmarshal agent config: %v\n%s", err, out)
+		}
+		if conf.AgentID != "id" || conf.ExternalID != id {
+			t.Errorf("agent config ids = %+v, want external id %q", conf, id)
+		}
+	}
+}
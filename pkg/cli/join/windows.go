@@ -0,0 +1,140 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package join
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/cli/config"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/powershell"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+// runPowerShell renders the script template and runs it on the windows node over ssh.
+func (c *JoinOptions) runPowerShell(node, tmpl string, data interface{}) (sshutils.Result, error) {
+	script, err := powershell.Render(tmpl, data)
+	if err != nil {
+		return sshutils.Result{}, err
+	}
+	return sshutils.SSHCmd(c.deployConfig.SSHConfig, node, strings.Join(powershell.Command(script), " "))
+}
+
+func (c *JoinOptions) preCheckWindowsAgent(ip string) error {
+	if c.deployConfig.AgentRegions.Exists(ip) || c.deployConfig.WindowsAgentRegions.Exists(ip) {
+		return fmt.Errorf("node %s is already deployed", ip)
+	}
+	ret, err := c.runPowerShell(ip, config.KcAgentWindowsCheckTmpl, map[string]string{"Dir": config.KcAgentWindowsDir})
+	logger.V(2).Info(ret.String())
+	if err != nil {
+		return fmt.Errorf("check node %s failed: %s", ip, err.Error())
+	}
+	if ret.ExitCode == 1 {
+		return fmt.Errorf("kc-agent service exist on %s, please clean old environment", ip)
+	}
+	if err = ret.Error(); err != nil {
+		return fmt.Errorf("check node %s failed: %s", ip, err.Error())
+	}
+	return nil
+}
+
+func (c *JoinOptions) runJoinWindowsAgentNode() error {
+	for region, agents := range c.windowsAgentRegion {
+		for _, agent := range agents {
+			if err := c.windowsAgentNodeFiles(region, agent); err != nil {
+				return err
+			}
+			if err := c.enableWindowsAgent(region, agent); err != nil {
+				return err
+			}
+		}
+	}
+	if len(c.windowsAgentRegion) > 0 {
+		logger.Info("windows agent node join completed. show command: 'kcctl get node'")
+	}
+	return nil
+}
+
+// windowsAgentNodeFiles uploads the agent binary, config and mq certs over sftp,
+// they are all kept in config.KcAgentWindowsDir.
+func (c *JoinOptions) windowsAgentNodeFiles(region, node string) error {
+	data := c.agentConfigData(region, node)
+	files := map[string]string{c.windowsAgentBinary: "kubeclipper-agent.exe"}
+	if c.deployConfig.MQ.TLS {
+		if err := c.fetchCerts(); err != nil {
+			return err
+		}
+		certs := map[string]string{
+			"MQCaPath":         c.deployConfig.MQ.CA,
+			"MQClientCertPath": c.deployConfig.MQ.ClientCert,
+			"MQClientKeyPath":  c.deployConfig.MQ.ClientKey,
+		}
+		for key, file := range certs {
+			name := path.Join("pki", filepath.Base(file))
+			files[file] = name
+			data[key] = windowsAgentPath(name)
+		}
+	}
+	data["OpLogDir"] = windowsAgentPath("operations")
+
+	agentConfig, err := os.CreateTemp("", "kubeclipper-agent-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(agentConfig.Name())
+	_, err = agentConfig.WriteString(renderAgentConfig(data))
+	if closeErr := agentConfig.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	files[agentConfig.Name()] = "kubeclipper-agent.yaml"
+
+	for local, name := range files {
+		// sftp of windows OpenSSH takes drive paths with forward slashes
+		remote := path.Join(filepath.ToSlash(config.KcAgentWindowsDir), name)
+		if err = c.deployConfig.SSHConfig.Upload(node, local, remote); err != nil {
+			return errors.WithMessagef(err, "upload %s to %s", local, node)
+		}
+	}
+	return nil
+}
+
+func (c *JoinOptions) enableWindowsAgent(region, node string) error {
+	ret, err := c.runPowerShell(node, config.KcAgentWindowsServiceTmpl, map[string]string{"Dir": config.KcAgentWindowsDir})
+	if err != nil {
+		return errors.Wrap(err, "enable kc agent")
+	}
+	if err = ret.Error(); err != nil {
+		return errors.Wrap(err, "enable kc agent")
+	}
+	c.deployConfig.WindowsAgentRegions.Add(region, node)
+	return c.deployConfig.Write()
+}
+
+func windowsAgentPath(name string) string {
+	return config.KcAgentWindowsDir + `\` + strings.ReplaceAll(name, "/", `\`)
+}
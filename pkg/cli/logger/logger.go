@@ -31,6 +31,8 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/pflag"
+
+	"github.com/kubeclipper/kubeclipper/pkg/cli/i18n"
 )
 
 var _logging = defaultLogging()
@@ -48,6 +50,37 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.Var(&_logging.verbosity, "v", "number for the log level verbosity")
 	flags.BoolVar(&_logging.Colorful, "colorized", _logging.Colorful, "print colorized log")
 	flags.BoolVar(&_logging.Caller, "caller", _logging.Caller, "print log with caller")
+	flags.BoolVarP(&_logging.Quiet, "quiet", "q", _logging.Quiet, "only print the IDs of resources and errors, for scripts and cron jobs")
+	flags.Var(noColorValue{}, "no-color", "disable colorized output")
+	flags.Lookup("no-color").NoOptDefVal = "true"
+}
+
+// Quiet reports whether output is limited to resource IDs and errors.
+func Quiet() bool {
+	return _logging.Quiet
+}
+
+// noColorValue turns off the colors of both logs and printed resources.
+type noColorValue struct{}
+
+func (noColorValue) String() string {
+	return strconv.FormatBool(color.NoColor)
+}
+
+func (noColorValue) Set(v string) error {
+	noColor, err := strconv.ParseBool(v)
+	if err != nil {
+		return err
+	}
+	if noColor {
+		_logging.Colorful = false
+		color.NoColor = true
+	}
+	return nil
+}
+
+func (noColorValue) Type() string {
+	return "bool"
 }
 
 type severity int32 // sync/atomic int32
@@ -79,6 +112,8 @@ type loggingT struct {
 	verbosity Level // V logging level, the value of the -v flag/
 	Colorful  bool
 	Caller    bool
+	// Quiet drops info and warning logs, so only results and errors are printed.
+	Quiet bool
 }
 
 func (l *loggingT) writeTS(buf *bytes.Buffer) {
@@ -148,9 +183,12 @@ func (l *loggingT) output(buf *bytes.Buffer, s severity) {
 }
 
 func (l *loggingT) printf(s severity, format string, args ...interface{}) {
+	if l.Quiet && s < errorLog {
+		return
+	}
 	buf := &bytes.Buffer{}
 	l.addHeader(buf, s)
-	_, _ = fmt.Fprintf(buf, format, args...)
+	_, _ = fmt.Fprintf(buf, i18n.T(format), args...)
 	if buf.Bytes()[buf.Len()-1] != '\n' {
 		buf.WriteByte('\n')
 	}
@@ -158,6 +196,14 @@ func (l *loggingT) printf(s severity, format string, args ...interface{}) {
 }
 
 func (l *loggingT) println(s severity, args ...interface{}) {
+	if l.Quiet && s < errorLog {
+		return
+	}
+	if len(args) > 0 {
+		if msg, ok := args[0].(string); ok {
+			args = append([]interface{}{i18n.T(msg)}, args[1:]...)
+		}
+	}
 	buf := &bytes.Buffer{}
 	l.addHeader(buf, s)
 	_, _ = fmt.Fprintln(buf, args...)
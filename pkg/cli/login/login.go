@@ -48,11 +48,16 @@ const (
 
   This command is the pre-operation of several cli commands, So if you encounter this error 'open /root/.kc/config: no such file or directory', you may need to execute the login command first.
 
-  The command currently stores the results to the /root/.kc/config file by default.`
+  The command currently stores the results to the /root/.kc/config file by default,
+  and the access token is cached in the /root/.kc/credentials file with file mode 0600.
+  The cached token is refreshed automatically when it expires.`
 	loginExample = `
   # Login to the kubeclipper server
   kcctl login --host http://127.0.0.1 --username root --password xxx
 
+  # Login to the kubeclipper server and input password interactively
+  kcctl login --server https://kc:8080 --username admin
+
   Please read 'kcctl login -h' get more login flags.`
 )
 
@@ -78,7 +83,7 @@ func NewCmdLogin(streams options.IOStreams) *cobra.Command {
 
 	o := NewLoginOptions(streams)
 	cmd := &cobra.Command{
-		Use:                   "login (--host | -H <host>) (--username | -u <username>) [flags]",
+		Use:                   "login (--host | -H | --server <host>) (--username | -u <username>) [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Login to the kubeclipper server",
 		Long:                  longDescription,
@@ -92,14 +97,14 @@ func NewCmdLogin(streams options.IOStreams) *cobra.Command {
 	cmd.Flags().StringVarP(&o.Username, "username", "u", o.Username, "kubeclipper username")
 	cmd.Flags().StringVarP(&o.Password, "password", "p", o.Password, "kubeclipper user password")
 	cmd.Flags().StringVarP(&o.Host, "host", "H", o.Host, "kubeclipper server address, format as scheme://host")
-	_ = cmd.MarkFlagRequired("host")
+	cmd.Flags().StringVar(&o.Host, "server", o.Host, "alias of --host")
 	_ = cmd.MarkFlagRequired("username")
 	return cmd
 }
 
 func (l *LoginOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
 	if l.Host == "" {
-		return utils.UsageErrorf(cmd, "--host must be valid")
+		return utils.UsageErrorf(cmd, "--host or --server must be valid")
 	}
 	if l.Username == "" {
 		return utils.UsageErrorf(cmd, "--username must be valid")
@@ -136,6 +141,7 @@ func (l *LoginOptions) RunLogin() error {
 		return err
 	}
 
+	ctxName := fmt.Sprintf("%s@default", l.Username)
 	cfg := &config.Config{
 		Servers: map[string]*config.Server{
 			"default": {
@@ -143,13 +149,11 @@ func (l *LoginOptions) RunLogin() error {
 			},
 		},
 		AuthInfos: map[string]*config.AuthInfo{
-			l.Username: {
-				Token: resp.AccessToken,
-			},
+			l.Username: {},
 		},
-		CurrentContext: fmt.Sprintf("%s@default", l.Username),
+		CurrentContext: ctxName,
 		Contexts: map[string]*config.Context{
-			fmt.Sprintf("%s@default", l.Username): {
+			ctxName: {
 				AuthInfo: l.Username,
 				Server:   "default",
 			},
@@ -171,6 +175,17 @@ func (l *LoginOptions) RunLogin() error {
 			return err
 		}
 	}
+
+	credentialsPath := filepath.Join(fpath, config.DefaultCredentialsFile)
+	credentials, err := config.LoadCredentials(credentialsPath)
+	if err != nil {
+		return err
+	}
+	credentials.Tokens[ctxName] = config.NewCredential(resp)
+	if err = credentials.Save(credentialsPath); err != nil {
+		return err
+	}
+
 	f, err := os.Create(filepath.Join(fpath, "config"))
 	if err != nil {
 		return err
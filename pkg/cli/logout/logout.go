@@ -0,0 +1,127 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/config"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+)
+
+const (
+	longDescription = `
+  Logout from the kubeclipper server.
+
+  The tokens of current user are revoked by the server, and the cached token is removed from the credentials file.`
+	logoutExample = `
+  # Logout from the kubeclipper server
+  kcctl logout
+
+  Please read 'kcctl logout -h' get more logout flags.`
+)
+
+type LogoutOptions struct {
+	options.IOStreams
+	cliOpts *options.CliOptions
+}
+
+func NewLogoutOptions(streams options.IOStreams) *LogoutOptions {
+	return &LogoutOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+	}
+}
+
+func NewCmdLogout(streams options.IOStreams) *cobra.Command {
+	o := NewLogoutOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "logout [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Logout from the kubeclipper server",
+		Long:                  longDescription,
+		Example:               logoutExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.RunLogout())
+		},
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func (l *LogoutOptions) Complete() error {
+	if err := l.cliOpts.Complete(); err != nil {
+		return fmt.Errorf("load config failed, you may not login yet: %v", err)
+	}
+	return nil
+}
+
+func (l *LogoutOptions) RunLogout() error {
+	cfg := l.cliOpts.ToRawConfig()
+	// revoke token at server side, the local token is removed even if the server is unreachable.
+	if c, err := cfg.ToKcClient(); err == nil {
+		if err = c.Logout(context.TODO()); err != nil {
+			_, _ = fmt.Fprintf(l.ErrOut, "WARNING! revoke token from server failed: %v\n", err)
+		}
+	}
+
+	credentialsPath := config.CredentialsPathFor(l.cliOpts.Config)
+	credentials, err := config.LoadCredentials(credentialsPath)
+	if err != nil {
+		return err
+	}
+	if _, ok := credentials.Tokens[cfg.CurrentContext]; ok {
+		delete(credentials.Tokens, cfg.CurrentContext)
+		if err = credentials.Save(credentialsPath); err != nil {
+			return err
+		}
+	}
+
+	// clean up token which stored in config file by old version of kcctl.
+	if ctx, ok := cfg.Contexts[cfg.CurrentContext]; ok && ctx != nil {
+		if auth, ok := cfg.AuthInfos[ctx.AuthInfo]; ok && auth != nil && auth.Token != "" {
+			auth.Token = ""
+			if err = writeConfig(l.cliOpts.Config, &cfg); err != nil {
+				return err
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(l.Out, "logout from context %s successfully\n", cfg.CurrentContext)
+	return nil
+}
+
+func writeConfig(path string, cfg *config.Config) error {
+	cfgBytes, err := json.MarshalIndent(cfg, "", "\t")
+	if err != nil {
+		return err
+	}
+	cfgBytes, err = yaml.JSONToYAML(cfgBytes)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, cfgBytes, 0644)
+}
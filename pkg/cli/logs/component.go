@@ -0,0 +1,246 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+)
+
+// journalTimeLayout is the timestamp of the short-iso-precise journal output.
+const journalTimeLayout = "2006-01-02T15:04:05.999999-0700"
+
+const componentExample = `
+  # Print the logs of kc-server in the last hour on all server nodes
+  kcctl logs kc-server
+
+  # Print the logs of kc-agent in the last 10 minutes on a node
+  kcctl logs kc-agent --node 192.168.10.20 --since 10m
+
+  # Follow the logs of kc-etcd on all server nodes
+  kcctl logs kc-etcd -f`
+
+// platform components are systemd services of the nodes in the deploy config.
+var components = []struct {
+	name  string
+	short string
+	// servers tells the component runs on server nodes, otherwise it runs on agent nodes.
+	servers bool
+}{
+	{name: "kc-server", short: "Print the logs of kc-server", servers: true},
+	{name: "kc-agent", short: "Print the logs of kc-agent"},
+	{name: "kc-etcd", short: "Print the logs of kc-etcd", servers: true},
+}
+
+type ComponentLogsOptions struct {
+	options.IOStreams
+	deployConfig *options.DeployConfig
+
+	component string
+	servers   bool
+	nodes     []string
+	since     time.Duration
+	follow    bool
+}
+
+func newCmdLogsComponents(streams options.IOStreams) []*cobra.Command {
+	cmds := make([]*cobra.Command, 0, len(components))
+	for _, c := range components {
+		o := &ComponentLogsOptions{
+			IOStreams:    streams,
+			deployConfig: options.NewDeployOptions(),
+			component:    c.name,
+			servers:      c.servers,
+			since:        time.Hour,
+		}
+		cmd := &cobra.Command{
+			Use:                   fmt.Sprintf("%s [(--node IP)] [(--since DURATION)] [-f]", c.name),
+			DisableFlagsInUseLine: true,
+			Short:                 c.short,
+			Example:               componentExample,
+			Args:                  cobra.NoArgs,
+			Run: func(cmd *cobra.Command, args []string) {
+				utils.CheckErr(o.Complete())
+				utils.CheckErr(o.Run())
+			},
+		}
+		cmd.Flags().StringSliceVar(&o.nodes, "node", o.nodes, "only print the logs of the nodes, all nodes running the component by default")
+		cmd.Flags().DurationVar(&o.since, "since", o.since, "print the logs newer than the duration, e.g. 30m or 2h")
+		cmd.Flags().BoolVarP(&o.follow, "follow", "f", o.follow, "follow the logs until interrupted")
+		cmd.Flags().StringVar(&o.deployConfig.Config, "deploy-config", options.DefaultDeployConfigPath, "kcctl deploy config path")
+		cmds = append(cmds, cmd)
+	}
+	return cmds
+}
+
+func (o *ComponentLogsOptions) Complete() error {
+	if err := o.deployConfig.Complete(); err != nil {
+		return err
+	}
+	known := sets.NewString(o.deployConfig.AgentRegions.ListIP()...)
+	if o.servers {
+		known = sets.NewString(o.deployConfig.ServerIPs...)
+	}
+	if len(o.nodes) == 0 {
+		o.nodes = known.List()
+	}
+	for _, node := range o.nodes {
+		if !known.Has(node) {
+			return fmt.Errorf("node %s does not run %s, see the deploy config", node, o.component)
+		}
+	}
+	if len(o.nodes) == 0 {
+		return fmt.Errorf("no node runs %s", o.component)
+	}
+	if o.since <= 0 {
+		return fmt.Errorf("--since must be positive")
+	}
+	return nil
+}
+
+// journalCmd prints the journal of the component, the timestamps let the logs of nodes be merged.
+func (o *ComponentLogsOptions) journalCmd() string {
+	cmd := fmt.Sprintf("journalctl -u %s --since=-%ds -o short-iso-precise --no-pager", o.component, int64(o.since.Seconds()))
+	if o.follow {
+		cmd += " -f"
+	}
+	return cmd
+}
+
+func (o *ComponentLogsOptions) Run() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if o.follow {
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		defer signal.Stop(sig)
+		go func() {
+			<-sig
+			cancel()
+		}()
+	}
+
+	var mu sync.Mutex
+	outputs := make(map[string]*bytes.Buffer, len(o.nodes))
+	var wg sync.WaitGroup
+	for _, node := range o.nodes {
+		var out io.Writer
+		if o.follow {
+			out = newPrefixWriter(o.Out, &mu, fmt.Sprintf("[%s] ", node))
+		} else {
+			outputs[node] = &bytes.Buffer{}
+			out = newPrefixWriter(outputs[node], &mu, "")
+		}
+		wg.Add(1)
+		go func(node string, out io.Writer) {
+			defer wg.Done()
+			err := o.deployConfig.SSHConfig.CmdStream(ctx, node, o.journalCmd(), out)
+			if err != nil && ctx.Err() == nil {
+				logger.Errorf("get %s logs of node %s failed: %v", o.component, node, err)
+			}
+		}(node, out)
+	}
+	wg.Wait()
+	if o.follow {
+		return nil
+	}
+	lines := make(map[string][]string, len(outputs))
+	for node, buf := range outputs {
+		lines[node] = strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	}
+	for _, line := range mergeLines(lines) {
+		_, _ = fmt.Fprintln(o.Out, line)
+	}
+	return nil
+}
+
+// mergeLines orders the journal lines of the nodes by their timestamp and prefixes them with the node.
+// The lines of a node stay in order, lines without timestamp, like the journal's own notes,
+// sort with the line before them.
+func mergeLines(nodeLines map[string][]string) []string {
+	type entry struct {
+		ts   time.Time
+		line string
+	}
+	var entries []entry
+	for node, lines := range nodeLines {
+		var ts time.Time
+		for _, line := range lines {
+			if line == "" {
+				continue
+			}
+			if t, err := time.Parse(journalTimeLayout, strings.SplitN(line, " ", 2)[0]); err == nil {
+				ts = t
+			}
+			entries = append(entries, entry{ts: ts, line: fmt.Sprintf("[%s] %s", node, line)})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].ts.Before(entries[j].ts)
+	})
+	merged := make([]string, 0, len(entries))
+	for _, e := range entries {
+		merged = append(merged, e.line)
+	}
+	return merged
+}
+
+// prefixWriter writes whole lines prefixed to out, the writers of all nodes share the lock of out.
+type prefixWriter struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    []byte
+}
+
+func newPrefixWriter(out io.Writer, mu *sync.Mutex, prefix string) *prefixWriter {
+	return &prefixWriter{out: out, mu: mu, prefix: prefix}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		if _, err := fmt.Fprintf(w.out, "%s%s", w.prefix, w.buf[:i+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[i+1:]
+	}
+}
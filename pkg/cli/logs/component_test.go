@@ -0,0 +1,62 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestMergeLines(t *testing.T) {
+	got := mergeLines(map[string][]string{
+		"10.0.0.1": {
+			"2024-01-15T10:00:01.000000+0800 n1 kc-server[1]: first",
+			"2024-01-15T10:00:03.000000+0800 n1 kc-server[1]: third",
+			"  continued",
+			"",
+		},
+		"10.0.0.2": {
+			"-- No entries --",
+			// the same instant as 10:00:02+0800 in another time zone
+			"2024-01-15T02:00:02.000000+0000 n2 kc-server[1]: second",
+		},
+	})
+	want := []string{
+		"[10.0.0.2] -- No entries --",
+		"[10.0.0.1] 2024-01-15T10:00:01.000000+0800 n1 kc-server[1]: first",
+		"[10.0.0.2] 2024-01-15T02:00:02.000000+0000 n2 kc-server[1]: second",
+		"[10.0.0.1] 2024-01-15T10:00:03.000000+0800 n1 kc-server[1]: third",
+		"[10.0.0.1]   continued",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mergeLines() = %q, want %q", got, want)
+	}
+}
+
+func TestPrefixWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	w := newPrefixWriter(out, &sync.Mutex{}, "[n1] ")
+	_, _ = w.Write([]byte("a line\nhalf "))
+	_, _ = w.Write([]byte("line\n"))
+	if want := "[n1] a line\n[n1] half line\n"; out.String() != want {
+		t.Errorf("got %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,58 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+)
+
+const (
+	longDescription = `
+  Print the logs of kubeclipper operations and platform components.
+
+  The logs of platform components are read from the journal of the nodes over ssh,
+  using the nodes and ssh config of the deploy config.`
+	logsExample = `
+  # Print the step logs of an operation
+  kcctl logs operation 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90
+
+  # Follow the step logs of a running operation until it is finished
+  kcctl logs operation 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90 -f
+
+  # Follow the logs of kc-server on all server nodes
+  kcctl logs kc-server -f
+
+  Please read 'kcctl logs -h' get more logs flags.`
+)
+
+func NewCmdLogs(streams options.IOStreams) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "logs",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the logs of operations and platform components",
+		Long:                  longDescription,
+		Example:               logsExample,
+		Args:                  cobra.NoArgs,
+	}
+	cmd.AddCommand(NewCmdLogsOperation(streams))
+	cmd.AddCommand(newCmdLogsComponents(streams)...)
+	return cmd
+}
@@ -0,0 +1,170 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const operationExample = `
+  # Print the step logs of an operation
+  kcctl logs operation 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90
+
+  # Print the logs of a step on a node
+  kcctl logs operation 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90 --step 2f0a0f9c-2a59-4a3c-9f61-21a4b2a3c0a1 --node 192.168.10.10
+
+  # Follow the step logs of a running operation until it is finished
+  kcctl logs operation 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90 -f`
+
+type OperationLogsOptions struct {
+	options.IOStreams
+	cliOpts *options.CliOptions
+	client  *kc.Client
+
+	operation string
+	// node is the id or ip of the node, step is the id or name of the step.
+	node   string
+	step   string
+	follow bool
+}
+
+func NewOperationLogsOptions(streams options.IOStreams) *OperationLogsOptions {
+	return &OperationLogsOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+	}
+}
+
+func NewCmdLogsOperation(streams options.IOStreams) *cobra.Command {
+	o := NewOperationLogsOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "operation OPERATION [(--node NODE)] [(--step STEP)] [-f]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Print the step logs of an operation",
+		Example:               operationExample,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			o.operation = args[0]
+			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.Run())
+		},
+	}
+	cmd.Flags().StringVar(&o.node, "node", o.node, "only print the logs of the node, id or ip")
+	cmd.Flags().StringVar(&o.step, "step", o.step, "only print the logs of the step, id or name")
+	cmd.Flags().BoolVarP(&o.follow, "follow", "f", o.follow, "follow the logs until the operation is finished")
+	o.cliOpts.AddFlags(cmd.Flags())
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("node", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete())
+		return completion.NodeIPs(o.client, false, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
+
+func (o *OperationLogsOptions) Complete() error {
+	if err := o.cliOpts.Complete(); err != nil {
+		return err
+	}
+	c, err := o.cliOpts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
+
+func (o *OperationLogsOptions) Run() error {
+	ctx := context.TODO()
+	steps, err := o.client.ListOperationSteps(ctx, o.operation)
+	if err != nil {
+		return err
+	}
+	w := newLogWriter(o.Out, func(step, node string, offset int64) (*oplog.LogContentResponse, error) {
+		return o.client.GetStepLog(ctx, node, o.operation, step, offset)
+	})
+	var stream *kc.LogStream
+	if o.follow {
+		// watch before fetching the logs written so far, the overlap is skipped by offset
+		if stream, err = o.client.WatchOperationLog(ctx, o.operation, "", ""); err != nil {
+			return err
+		}
+		defer stream.Close()
+	}
+	matched := false
+	for _, step := range steps {
+		if o.step != "" && step.Step.ID != o.step && step.Step.Name != o.step {
+			continue
+		}
+		w.stepNames[step.Step.ID] = step.Step.Name
+		for _, node := range step.Step.Nodes {
+			if o.node != "" && node.ID != o.node && node.IPv4 != o.node {
+				continue
+			}
+			matched = true
+			w.nodeNames[node.ID] = node.IPv4
+			if !stepStarted(step, node.ID) {
+				continue
+			}
+			if err = w.fetch(step.Step.ID, node.ID); err != nil {
+				logger.V(2).Infof("fetch log of step %s on node %s failed: %v", step.Step.Name, node.IPv4, err)
+			}
+		}
+	}
+	if !matched {
+		return fmt.Errorf("no step of operation %s matches", o.operation)
+	}
+	if stream == nil {
+		return nil
+	}
+	for {
+		chunk, err := stream.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if (o.step != "" && w.stepNames[chunk.StepID] == "") || (o.node != "" && w.nodeNames[chunk.Node] == "") {
+			continue
+		}
+		w.write(chunk.StepID, chunk.Node, chunk.Offset, chunk.Data)
+	}
+}
+
+// stepStarted tells whether the step has run on the node, only then it has a log.
+func stepStarted(step v1.OperationStep, node string) bool {
+	for _, s := range step.Status {
+		if s.Node == node {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+)
+
+type logSource struct {
+	step string
+	node string
+}
+
+// logFetcher returns the step log of the node after offset.
+type logFetcher func(step, node string, offset int64) (*oplog.LogContentResponse, error)
+
+// logWriter prints the step logs of several nodes in order of arrival, like tail does for several files.
+// It remembers the offset printed of every log, so content fetched and streamed is printed once.
+type logWriter struct {
+	out       io.Writer
+	fetcher   logFetcher
+	offsets   map[logSource]int64
+	last      logSource
+	stepNames map[string]string
+	nodeNames map[string]string
+}
+
+func newLogWriter(out io.Writer, fetcher logFetcher) *logWriter {
+	return &logWriter{
+		out:       out,
+		fetcher:   fetcher,
+		offsets:   make(map[logSource]int64),
+		stepNames: make(map[string]string),
+		nodeNames: make(map[string]string),
+	}
+}
+
+// fetch prints the log written after the printed offset.
+func (w *logWriter) fetch(step, node string) error {
+	for {
+		src := logSource{step: step, node: node}
+		offset := w.offsets[src]
+		resp, err := w.fetcher(step, node, offset)
+		if err != nil {
+			return err
+		}
+		if resp.DeliverySize == 0 {
+			return nil
+		}
+		w.write(step, node, offset, []byte(resp.Content))
+		if offset+resp.DeliverySize >= resp.LogSize {
+			return nil
+		}
+	}
+}
+
+// write prints data found at offset of the log, skipping what is printed already.
+// Content missing before offset, e.g. chunks dropped by the server, is fetched first.
+func (w *logWriter) write(step, node string, offset int64, data []byte) {
+	src := logSource{step: step, node: node}
+	if offset > w.offsets[src] {
+		if err := w.fetch(step, node); err != nil {
+			_, _ = fmt.Fprintf(w.out, "... %d bytes of the log are missing: %v\n", offset-w.offsets[src], err)
+			w.offsets[src] = offset
+		}
+	}
+	printed := w.offsets[src]
+	if offset+int64(len(data)) <= printed {
+		return
+	}
+	if offset < printed {
+		data = data[printed-offset:]
+	}
+	if w.last != src {
+		_, _ = fmt.Fprintf(w.out, "==> step %s on node %s <==\n", nameOr(w.stepNames, step), nameOr(w.nodeNames, node))
+		w.last = src
+	}
+	_, _ = w.out.Write(data)
+	w.offsets[src] = printed + int64(len(data))
+}
+
+func nameOr(names map[string]string, id string) string {
+	if name := names[id]; name != "" {
+		return name
+	}
+	return id
+}
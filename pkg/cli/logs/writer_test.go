@@ -0,0 +1,70 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logs
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+)
+
+func TestLogWriter(t *testing.T) {
+	logs := map[string]string{
+		"s1/n1": "line 1\nline 2\nline 3\n",
+		"s1/n2": "other\n",
+	}
+	fetcher := func(step, node string, offset int64) (*oplog.LogContentResponse, error) {
+		content, ok := logs[step+"/"+node]
+		if !ok {
+			return nil, fmt.Errorf("no log")
+		}
+		// deliver at most 7 bytes at once to exercise paging
+		end := offset + 7
+		if end > int64(len(content)) {
+			end = int64(len(content))
+		}
+		return &oplog.LogContentResponse{
+			Content:      content[offset:end],
+			LogSize:      int64(len(content)),
+			DeliverySize: end - offset,
+		}, nil
+	}
+	out := &bytes.Buffer{}
+	w := newLogWriter(out, fetcher)
+	w.stepNames["s1"] = "step-1"
+	w.nodeNames["n1"] = "10.0.0.1"
+
+	if err := w.fetch("s1", "n1"); err != nil {
+		t.Fatal(err)
+	}
+	// overlaps the fetched content
+	w.write("s1", "n1", 14, []byte("line 3\nline 4\n"))
+	// dropped chunks before offset are fetched, the new content is printed once
+	logs["s1/n2"] = "other\nmissed\nnew\n"
+	w.write("s1", "n2", 13, []byte("new\n"))
+	w.write("s1", "n1", 21, []byte("line 4\n"))
+
+	want := "==> step step-1 on node 10.0.0.1 <==\nline 1\nline 2\nline 3\nline 4\n" +
+		"==> step step-1 on node n2 <==\nother\nmissed\nnew\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
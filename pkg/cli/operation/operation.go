@@ -0,0 +1,107 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package operation
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Control the lifecycle of a kubeclipper operation.
+
+  A running operation can be paused between two steps or cancelled, a cancelled
+  operation stops its running step and skips the remaining ones. A paused or
+  cancelled operation resumes from its first step that did not complete.`
+	operationExample = `
+  # Pause a running operation
+  kcctl operation pause 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90
+
+  # Resume a paused or cancelled operation
+  kcctl operation resume 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90
+
+  # Cancel a running or paused operation
+  kcctl operation cancel 0d4e5bd5-8e1f-4d5c-9d8a-6f2c1a3b7e90
+
+  Please read 'kcctl operation -h' get more operation flags.`
+)
+
+type OperationOptions struct {
+	options.IOStreams
+	cliOpts *options.CliOptions
+	client  *kc.Client
+}
+
+func NewOperationOptions(streams options.IOStreams) *OperationOptions {
+	return &OperationOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+	}
+}
+
+func NewCmdOperation(streams options.IOStreams) *cobra.Command {
+	o := NewOperationOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "operation",
+		DisableFlagsInUseLine: true,
+		Short:                 "Pause, resume or cancel an operation",
+		Long:                  longDescription,
+		Example:               operationExample,
+		Args:                  cobra.NoArgs,
+	}
+	cmd.AddCommand(o.newCmdAction("pause", "Pause a running operation after its running step or at its next checkpoint", (*kc.Client).PauseOperation))
+	cmd.AddCommand(o.newCmdAction("resume", "Resume a paused or cancelled operation", (*kc.Client).ResumeOperation))
+	cmd.AddCommand(o.newCmdAction("cancel", "Cancel a running or paused operation", (*kc.Client).CancelOperation))
+	return cmd
+}
+
+func (o *OperationOptions) newCmdAction(action, short string, fn func(*kc.Client, context.Context, string) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   fmt.Sprintf("%s OPERATION", action),
+		DisableFlagsInUseLine: true,
+		Short:                 short,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.cliOpts))
+			utils.CheckErr(fn(o.client, context.TODO(), args[0]))
+			_, _ = fmt.Fprintf(o.Out, "operation %s %s requested\n", args[0], action)
+		},
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	return cmd
+}
+
+func (o *OperationOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
@@ -0,0 +1,158 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package passwd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Change the password of a kubeclipper user.
+
+  The password of the current user is changed if no user is given. Users allowed to
+  update the password of others do not need the current password of the user.
+  The tokens of the user are revoked after the change, so the user has to login again.`
+	passwdExample = `
+  # Change the password of the current user, the passwords are input interactively
+  kcctl passwd
+
+  # Reset the password of user foo
+  kcctl passwd foo --password 'NEW-PWD'
+
+  Please read 'kcctl passwd -h' get more passwd flags.`
+)
+
+type PasswdOptions struct {
+	options.IOStreams
+	cliOpts         *options.CliOptions
+	client          *kc.Client
+	user            string
+	self            bool
+	CurrentPassword string
+	Password        string
+}
+
+func NewPasswdOptions(streams options.IOStreams) *PasswdOptions {
+	return &PasswdOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+	}
+}
+
+func NewCmdPasswd(streams options.IOStreams) *cobra.Command {
+	o := NewPasswdOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "passwd [USER] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Change the password of a kubeclipper user",
+		Long:                  longDescription,
+		Example:               passwdExample,
+		Args:                  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(args))
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.RunPasswd())
+		},
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.CurrentPassword, "current-password", o.CurrentPassword, "current password of the user, input interactively when changing the password of the current user")
+	cmd.Flags().StringVar(&o.Password, "password", o.Password, "new password, input interactively if not set")
+	return cmd
+}
+
+func (l *PasswdOptions) Complete(args []string) error {
+	if err := l.cliOpts.Complete(); err != nil {
+		return err
+	}
+	cfg := l.cliOpts.ToRawConfig()
+	c, err := cfg.ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.client = c
+	current := cfg.CurrentUser()
+	l.user = current
+	if len(args) > 0 {
+		l.user = args[0]
+	}
+	l.self = l.user == current
+	return nil
+}
+
+func (l *PasswdOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.user == "" {
+		return utils.UsageErrorf(cmd, "user must be specified")
+	}
+	if l.Password != "" || l.CurrentPassword != "" {
+		_, _ = fmt.Fprintf(l.IOStreams.Out, "WARNING! Using passwords via the CLI is insecure.\n")
+	}
+	return nil
+}
+
+func (l *PasswdOptions) RunPasswd() error {
+	var err error
+	if l.self && l.CurrentPassword == "" {
+		if l.CurrentPassword, err = l.input("Current password: "); err != nil {
+			return err
+		}
+	}
+	if l.Password == "" {
+		if l.Password, err = l.input(fmt.Sprintf("New password for user %s: ", l.user)); err != nil {
+			return err
+		}
+		retyped, err := l.input("Retype new password: ")
+		if err != nil {
+			return err
+		}
+		if retyped != l.Password {
+			return fmt.Errorf("passwords do not match")
+		}
+	}
+	if l.Password == "" {
+		return fmt.Errorf("new password cannot be empty")
+	}
+	err = l.client.UpdateUserPassword(context.TODO(), l.user, &kc.PasswordReset{
+		CurrentPassword: l.CurrentPassword,
+		NewPassword:     l.Password,
+	})
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(l.IOStreams.Out, "password of user %s updated\n", l.user)
+	if l.self {
+		_, _ = fmt.Fprintf(l.IOStreams.Out, "please run 'kcctl login' again\n")
+	}
+	return nil
+}
+
+func (l *PasswdOptions) input(prompt string) (string, error) {
+	_, _ = fmt.Fprint(l.IOStreams.Out, prompt)
+	defer func() {
+		_, _ = fmt.Fprintln(l.IOStreams.Out)
+	}()
+	return utils.WaitInputPasswd()
+}
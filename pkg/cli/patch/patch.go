@@ -0,0 +1,219 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package patch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Update fields of a kubeclipper resource with a patch.
+
+  Only the fields in the patch are changed. The patch is applied by the server to the latest version of the
+  resource, a change made at the same time by someone else is never overwritten, the patch fails instead.
+
+  A merge patch (--type merge, the default) is a partial resource in json or yaml, null deletes a field.
+  A json patch (--type json) is a list of operations, e.g. [{"op":"remove","path":"/metadata/labels/env"}].`
+	patchExample = `
+  # Add a label to a cluster
+  kcctl patch cluster 'CLUSTER-NAME' -p '{"metadata":{"labels":{"env":"prod"}}}'
+
+  # Remove a label of a node
+  kcctl patch node 'NODE-NAME' --type json -p '[{"op":"remove","path":"/metadata/labels/env"}]'
+
+  # Change the email of a user only if the user was not changed since resource version 42
+  kcctl patch user 'USER-NAME' -p 'spec: {email: admin@example.com}' --resource-version 42
+
+  Please read 'kcctl patch -h' get more patch flags.`
+)
+
+var patchTypes = map[string]kc.PatchType{
+	"merge": kc.MergePatchType,
+	"json":  kc.JSONPatchType,
+}
+
+type patchFunc func(ctx context.Context, name string, pt kc.PatchType, data []byte, opts kc.PatchOptions) error
+
+func patcher[T any, PT kc.Object[T]](rc *kc.ResourceClient[T, PT]) patchFunc {
+	return func(ctx context.Context, name string, pt kc.PatchType, data []byte, opts kc.PatchOptions) error {
+		_, err := rc.Patch(ctx, name, pt, data, opts)
+		return err
+	}
+}
+
+// patchers returns the patch functions of the resources which can be patched.
+func patchers(cli *kc.Client) map[string]patchFunc {
+	core, iam := cli.CoreV1(), cli.IAMV1()
+	return map[string]patchFunc{
+		options.ResourceCluster: patcher(core.Clusters()),
+		options.ResourceNode:    patcher(core.Nodes()),
+		"backuppoint":           patcher(core.BackupPoints()),
+		"template":              patcher(core.Templates()),
+		"clustertemplate":       patcher(core.ClusterTemplates()),
+		"cronmaintenance":       patcher(core.CronMaintenances()),
+		"domain":                patcher(core.Domains()),
+		"notifier":              patcher(core.Notifiers()),
+		"webhookconfiguration":  patcher(core.WebhookConfigurations()),
+		options.ResourceUser:    patcher(iam.Users()),
+		options.ResourceRole:    patcher(iam.Roles()),
+		"project":               patcher(iam.Projects()),
+	}
+}
+
+// allowedResources are the sorted names of the resources which can be patched.
+func allowedResources() []string {
+	var names []string
+	for name := range patchers(&kc.Client{}) {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type PatchOptions struct {
+	options.IOStreams
+	cliOpts         *options.CliOptions
+	client          *kc.Client
+	resource        string
+	name            string
+	Patch           string
+	Type            string
+	ResourceVersion string
+	DryRun          bool
+}
+
+func NewPatchOptions(streams options.IOStreams) *PatchOptions {
+	return &PatchOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+		Type:      "merge",
+	}
+}
+
+func NewCmdPatch(streams options.IOStreams) *cobra.Command {
+	o := NewPatchOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "patch <resource> <name> (--patch | -p <PATCH>) [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Update fields of a resource with a patch",
+		Long:                  longDescription,
+		Example:               patchExample,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunPatch())
+		},
+		ValidArgsFunction: ValidArgsFunction(o),
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().StringVarP(&o.Patch, "patch", "p", o.Patch, "the patch to apply, in json or yaml format")
+	cmd.Flags().StringVar(&o.Type, "type", o.Type, "the type of the patch, merge or json")
+	cmd.Flags().StringVar(&o.ResourceVersion, "resource-version", o.ResourceVersion, "only patch the resource if it still has this resource version")
+	cmd.Flags().BoolVar(&o.DryRun, "dry-run", o.DryRun, "validate the patch without applying it")
+	utils.CheckErr(cmd.MarkFlagRequired("patch"))
+	return cmd
+}
+
+func (o *PatchOptions) Complete() error {
+	if err := o.cliOpts.Complete(); err != nil {
+		return err
+	}
+	c, err := o.cliOpts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
+
+func (o *PatchOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if len(args) < 1 {
+		return utils.UsageErrorf(cmd, "You must specify the type of resource to patch,support %v now", allowedResources())
+	}
+	o.resource = args[0]
+	if _, ok := patchers(o.client)[o.resource]; !ok {
+		return utils.UsageErrorf(cmd, "unsupported resource type,support %v now", allowedResources())
+	}
+	if len(args) != 2 {
+		return utils.UsageErrorf(cmd, "You must specify the name of %s to patch", o.resource)
+	}
+	o.name = args[1]
+	if _, ok := patchTypes[o.Type]; !ok {
+		return utils.UsageErrorf(cmd, "unsupported patch type %s, support merge and json", o.Type)
+	}
+	if o.Patch == "" {
+		return utils.UsageErrorf(cmd, "--patch must be specified")
+	}
+	return nil
+}
+
+func (o *PatchOptions) RunPatch() error {
+	// yaml is a superset of json, the patch is sent as json
+	data, err := yaml.YAMLToJSON([]byte(o.Patch))
+	if err != nil {
+		return fmt.Errorf("parse patch: %v", err)
+	}
+	err = patchers(o.client)[o.resource](context.TODO(), o.name, patchTypes[o.Type], data, kc.PatchOptions{
+		DryRun:          o.DryRun,
+		ResourceVersion: o.ResourceVersion,
+	})
+	if err != nil {
+		return err
+	}
+	if o.DryRun {
+		_, _ = fmt.Fprintf(o.Out, "%s/%s patched (dry run)\n", o.resource, o.name)
+		return nil
+	}
+	_, _ = fmt.Fprintf(o.Out, "%s/%s patched\n", o.resource, o.name)
+	return nil
+}
+
+func ValidArgsFunction(o *PatchOptions) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return allowedResources(), cobra.ShellCompDirectiveNoFileComp
+		}
+		if len(args) > 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		utils.CheckErr(o.Complete())
+		switch args[0] {
+		case options.ResourceCluster:
+			return completion.Clusters(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceNode:
+			return completion.Nodes(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceUser:
+			return completion.Users(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		case options.ResourceRole:
+			return completion.Roles(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
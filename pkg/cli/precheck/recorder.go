@@ -0,0 +1,121 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package precheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+	apierror "github.com/kubeclipper/kubeclipper/pkg/errors"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+// DefaultDir keeps the precheck runs of kcctl until they are uploaded to the server,
+// deploy runs before the server exists and join may run without a login.
+var DefaultDir = filepath.Join(options.HomeDIR, options.DefaultPath, "prechecks")
+
+// Recorder collects the results of the prechecks run by kcctl, it is safe for concurrent use.
+type Recorder struct {
+	mu       sync.Mutex
+	precheck *v1.Precheck
+}
+
+func NewRecorder(source v1.PrecheckSource, nodes []string) *Recorder {
+	p := &v1.Precheck{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Precheck",
+			APIVersion: v1.SchemeGroupVersion.String(),
+		},
+		Source:    source,
+		Nodes:     append([]string(nil), nodes...),
+		StartedAt: metav1.Now(),
+	}
+	p.Name = uuid.New().String()
+	return &Recorder{precheck: p}
+}
+
+// Add records the result of the check on the node, the check passes when err is nil.
+func (r *Recorder) Add(node, check string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.precheck.AddResult(node, check, err)
+}
+
+// Save completes the run and writes it to dir, to be uploaded by Upload later.
+func (r *Recorder) Save(dir string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.precheck.Complete()
+	data, err := json.Marshal(r.precheck)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, r.precheck.Name+".json"), data, 0600)
+}
+
+// Upload sends the runs saved in dir to the server, the uploaded runs and the runs
+// rejected by the server are removed, the others are kept for the next upload.
+func Upload(ctx context.Context, cli *kc.Client, dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	var uploadErr error
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		p := &v1.Precheck{}
+		if err = json.Unmarshal(data, p); err != nil {
+			logger.Warnf("remove malformed precheck record %s: %v", file, err)
+			_ = os.Remove(file)
+			continue
+		}
+		if err = cli.CreatePrecheck(ctx, p); err != nil {
+			if apierror.CodeForError(err) != http.StatusBadRequest {
+				if uploadErr == nil {
+					uploadErr = fmt.Errorf("upload precheck record %s failed: %v", file, err)
+				}
+				continue
+			}
+			logger.Warnf("precheck record %s is rejected: %v", file, err)
+		}
+		if err = os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return uploadErr
+}
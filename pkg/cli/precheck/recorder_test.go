@@ -0,0 +1,87 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package precheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+func TestRecorderUpload(t *testing.T) {
+	dir := t.TempDir()
+	accepted := NewRecorder(v1.PrecheckSourceDeploy, []string{"10.0.0.1", "10.0.0.2"})
+	accepted.Add("10.0.0.1", "NTP", nil)
+	accepted.Add("10.0.0.2", "NTP", errors.New("chronyd or ntpd service not running"))
+	rejected := NewRecorder(v1.PrecheckSourceJoin, []string{"10.0.0.3"})
+	failed := NewRecorder(v1.PrecheckSourceJoin, []string{"10.0.0.4"})
+	for _, r := range []*Recorder{accepted, rejected, failed} {
+		if err := r.Save(dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var uploaded []v1.Precheck
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := v1.Precheck{}
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch p.Name {
+		case rejected.precheck.Name:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":400,"message":"already exists"}`))
+		case failed.precheck.Name:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"code":500,"message":"etcd unavailable"}`))
+		default:
+			uploaded = append(uploaded, p)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	cli, err := kc.NewClientWithOpts(kc.WithHost(srv.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = Upload(context.TODO(), cli, dir); err == nil {
+		t.Fatal("expected the server error to be returned")
+	}
+	// only the record which failed to upload is kept for the next upload
+	files, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	if len(files) != 1 || filepath.Base(files[0]) != failed.precheck.Name+".json" {
+		t.Fatalf("unexpected records left %v", files)
+	}
+	if len(uploaded) != 1 {
+		t.Fatalf("expected one uploaded record, got %d", len(uploaded))
+	}
+	if p := uploaded[0]; p.Failed != 1 || p.CompletedAt == nil || len(p.NodeResults("10.0.0.2")) != 1 {
+		t.Fatalf("unexpected uploaded record %+v", p)
+	}
+}
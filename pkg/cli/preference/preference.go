@@ -0,0 +1,162 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package preference
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Show or change the preferences of a kubeclipper user.
+
+  The preferences are stored on the server and shared by kcctl and the console.
+  kcctl uses them when the corresponding flags are not given, e.g. 'kcctl get node'
+  only lists the nodes of the preferred region unless --region or --all-regions is set.
+  The preferences of the current user are used if no user is given.`
+	preferenceExample = `
+  # Show the preferences of the current user
+  kcctl preference
+
+  # Only list the nodes and clusters of region us-west-1 and print them as wide tables by default
+  kcctl preference --region us-west-1 --default-output wide
+
+  # Clear the preferred region of user foo
+  kcctl preference foo --region ''
+
+  Please read 'kcctl preference -h' get more preference flags.`
+)
+
+type PreferenceOptions struct {
+	options.IOStreams
+	cliOpts       *options.CliOptions
+	client        *kc.Client
+	user          string
+	Region        string
+	Project       string
+	DefaultOutput string
+}
+
+func NewPreferenceOptions(streams options.IOStreams) *PreferenceOptions {
+	return &PreferenceOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+	}
+}
+
+func NewCmdPreference(streams options.IOStreams) *cobra.Command {
+	o := NewPreferenceOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "preference [USER] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show or change the preferences of a kubeclipper user",
+		Long:                  longDescription,
+		Example:               preferenceExample,
+		Args:                  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(args))
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.RunPreference(cmd))
+		},
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.Region, "region", o.Region, "default region of the nodes and clusters listed")
+	cmd.Flags().StringVar(&o.Project, "project", o.Project, "default project of the user")
+	cmd.Flags().StringVar(&o.DefaultOutput, "default-output", o.DefaultOutput, "default output format, one of table, wide, json and yaml")
+
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("region", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete(args))
+		return completion.Regions(o.client, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}))
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("default-output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"table", "wide", "json", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
+
+func (l *PreferenceOptions) Complete(args []string) error {
+	if err := l.cliOpts.Complete(); err != nil {
+		return err
+	}
+	cfg := l.cliOpts.ToRawConfig()
+	c, err := cfg.ToKcClient()
+	if err != nil {
+		return err
+	}
+	l.client = c
+	l.user = cfg.CurrentUser()
+	if len(args) > 0 {
+		l.user = args[0]
+	}
+	return nil
+}
+
+func (l *PreferenceOptions) ValidateArgs(cmd *cobra.Command) error {
+	if l.user == "" {
+		return utils.UsageErrorf(cmd, "user must be specified")
+	}
+	if err := (&iamv1.UserPreferences{Output: l.DefaultOutput}).Validate(); err != nil {
+		return utils.UsageErrorf(cmd, "%v", err)
+	}
+	return nil
+}
+
+func (l *PreferenceOptions) RunPreference(cmd *cobra.Command) error {
+	prefs, err := l.client.GetUserPreferences(context.TODO(), l.user)
+	if err != nil {
+		return err
+	}
+	if !l.merge(cmd, prefs) {
+		l.print(prefs)
+		return nil
+	}
+	if err = l.client.UpdateUserPreferences(context.TODO(), l.user, prefs); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(l.IOStreams.Out, "preferences of user %s updated\n", l.user)
+	return nil
+}
+
+// merge sets the preferences given on the command line and reports whether any was given.
+func (l *PreferenceOptions) merge(cmd *cobra.Command, prefs *iamv1.UserPreferences) bool {
+	changed := false
+	if cmd.Flags().Changed("region") {
+		prefs.Region, changed = l.Region, true
+	}
+	if cmd.Flags().Changed("project") {
+		prefs.Project, changed = l.Project, true
+	}
+	if cmd.Flags().Changed("default-output") {
+		prefs.Output, changed = l.DefaultOutput, true
+	}
+	return changed
+}
+
+func (l *PreferenceOptions) print(prefs *iamv1.UserPreferences) {
+	_, _ = fmt.Fprintf(l.IOStreams.Out, "region: %s\nproject: %s\ndefault-output: %s\n", prefs.Region, prefs.Project, prefs.Output)
+}
@@ -0,0 +1,169 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+const (
+	formatCustomColumns = "custom-columns"
+	formatJSONPath      = "jsonpath"
+	// noneValue is printed in the columns whose field the resource lacks.
+	noneValue = "<none>"
+)
+
+// simpleFieldPath matches the column paths the server can project, e.g. .metadata.name.
+var simpleFieldPath = regexp.MustCompile(`^\.?[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// column is a column of the custom-columns output, e.g. IP:.status.ipv4DefaultIP.
+type column struct {
+	header string
+	path   string
+}
+
+// parseCustomColumns parses the spec of -o custom-columns=NAME:.metadata.name,IP:.status.ipv4DefaultIP.
+func parseCustomColumns(spec string) ([]column, error) {
+	if spec == "" {
+		return nil, fmt.Errorf("custom-columns format specified but no custom columns given")
+	}
+	var columns []column
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("unexpected custom-columns spec: %s, expected <header>:<json-path-expr>", part)
+		}
+		columns = append(columns, column{header: header, path: relaxedJSONPath(path)})
+	}
+	return columns, nil
+}
+
+// relaxedJSONPath wraps the path in braces and adds the leading dot, so that both
+// metadata.name and {.metadata.name} are accepted.
+func relaxedJSONPath(path string) string {
+	path = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(path), "{"), "}")
+	if !strings.HasPrefix(path, ".") {
+		path = "." + path
+	}
+	return "{" + path + "}"
+}
+
+// items returns the items of a list, a single resource is a list of itself.
+func items(pr ResourcePrinter) ([]interface{}, error) {
+	data, err := pr.JSONPrint()
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	if m, ok := obj.(map[string]interface{}); ok {
+		if list, ok := m["items"].([]interface{}); ok {
+			return list, nil
+		}
+	}
+	return []interface{}{obj}, nil
+}
+
+func printCustomColumns(pr ResourcePrinter, spec string, w io.Writer) error {
+	columns, err := parseCustomColumns(spec)
+	if err != nil {
+		return err
+	}
+	parsers := make([]*jsonpath.JSONPath, len(columns))
+	headers := make([]string, len(columns))
+	for i, col := range columns {
+		parsers[i] = jsonpath.New(col.header).AllowMissingKeys(true)
+		if err = parsers[i].Parse(col.path); err != nil {
+			return fmt.Errorf("invalid custom-columns path %s: %v", col.path, err)
+		}
+		headers[i] = col.header
+	}
+	list, err := items(pr)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(list))
+	for _, item := range list {
+		row := make([]string, len(columns))
+		for i, parser := range parsers {
+			if row[i], err = columnValue(parser, item); err != nil {
+				return err
+			}
+		}
+		rows = append(rows, row)
+	}
+	renderTable(w, func() ([]string, [][]string) {
+		return headers, rows
+	})
+	return nil
+}
+
+func columnValue(parser *jsonpath.JSONPath, item interface{}) (string, error) {
+	results, err := parser.FindResults(item)
+	if err != nil {
+		return "", err
+	}
+	var values []string
+	for _, result := range results {
+		for _, v := range result {
+			var buf bytes.Buffer
+			if err = parser.PrintResults(&buf, []reflect.Value{v}); err != nil {
+				return "", err
+			}
+			values = append(values, buf.String())
+		}
+	}
+	if len(values) == 0 {
+		return noneValue, nil
+	}
+	return strings.Join(values, ","), nil
+}
+
+func printJSONPath(pr ResourcePrinter, template string, w io.Writer) error {
+	if template == "" {
+		return fmt.Errorf("jsonpath format specified but no template given")
+	}
+	parser := jsonpath.New("output")
+	if err := parser.Parse(template); err != nil {
+		return fmt.Errorf("invalid jsonpath template %s: %v", template, err)
+	}
+	data, err := pr.JSONPrint()
+	if err != nil {
+		return err
+	}
+	var obj interface{}
+	if err = json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	if err = parser.Execute(w, obj); err != nil {
+		return err
+	}
+	_, err = w.Write([]byte("\n"))
+	return err
+}
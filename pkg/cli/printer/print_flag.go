@@ -20,12 +20,16 @@ package printer
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
+	"strings"
 
 	"sigs.k8s.io/yaml"
 
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
 )
 
 type PrintFlags struct {
@@ -36,12 +40,20 @@ func (p *PrintFlags) AllowedFormats() []string {
 	if p == nil {
 		return []string{}
 	}
-	return []string{"json", "yaml", "table"}
+	return []string{"json", "yaml", "table", "wide", formatCustomColumns, formatJSONPath}
 }
 
 // TODO
 func (p *PrintFlags) Print(pr ResourcePrinter, w io.Writer) error {
-	switch p.format {
+	if logger.Quiet() {
+		return printIDs(pr, w)
+	}
+	format, arg, _ := strings.Cut(p.format, "=")
+	switch format {
+	case formatCustomColumns:
+		return printCustomColumns(pr, arg, w)
+	case formatJSONPath:
+		return printJSONPath(pr, arg, w)
 	case "json":
 		data, err := pr.JSONPrint()
 		if err != nil {
@@ -60,25 +72,121 @@ func (p *PrintFlags) Print(pr ResourcePrinter, w io.Writer) error {
 		}
 		_, err = w.Write(data)
 		return err
+	case "wide":
+		if wp, ok := pr.(WidePrinter); ok {
+			renderTable(w, wp.WideTablePrint)
+			return nil
+		}
+		fallthrough
 	case "table":
 		fallthrough
 	default:
-		table := tablewriter.NewWriter(w)
-		headers, data := pr.TablePrint()
-		table.SetHeader(headers)
-		for _, v := range data {
-			table.Append(v)
+		renderTable(w, pr.TablePrint)
+		return nil
+	}
+}
+
+// PrintEvent prints the resource of a watch event, the table formats add the event type as the first column.
+func (p *PrintFlags) PrintEvent(event string, pr ResourcePrinter, w io.Writer) error {
+	format, _, _ := strings.Cut(p.format, "=")
+	switch format {
+	case formatCustomColumns, formatJSONPath, "json", "yaml":
+		return p.Print(pr, w)
+	}
+	if logger.Quiet() {
+		return printIDs(pr, w)
+	}
+	return p.Print(&eventPrinter{ResourcePrinter: pr, event: event}, w)
+}
+
+// eventPrinter prints the table of a resource with the type of the watch event.
+type eventPrinter struct {
+	ResourcePrinter
+	event string
+}
+
+func (e *eventPrinter) TablePrint() ([]string, [][]string) {
+	return e.withEvent(e.ResourcePrinter.TablePrint())
+}
+
+func (e *eventPrinter) WideTablePrint() ([]string, [][]string) {
+	if wp, ok := e.ResourcePrinter.(WidePrinter); ok {
+		return e.withEvent(wp.WideTablePrint())
+	}
+	return e.TablePrint()
+}
+
+func (e *eventPrinter) withEvent(headers []string, data [][]string) ([]string, [][]string) {
+	headers = append([]string{"event"}, headers...)
+	for i := range data {
+		data[i] = append([]string{e.event}, data[i]...)
+	}
+	return headers, data
+}
+
+func renderTable(w io.Writer, tablePrint func() ([]string, [][]string)) {
+	table := tablewriter.NewWriter(w)
+	headers, data := tablePrint()
+	table.SetHeader(headers)
+	for _, v := range data {
+		table.Append(v)
+	}
+	table.Render()
+}
+
+// printIDs prints the first column of the table, which holds the resource ID, one per line.
+func printIDs(pr ResourcePrinter, w io.Writer) error {
+	_, data := pr.TablePrint()
+	for _, row := range data {
+		if len(row) == 0 || row[0] == "" {
+			continue
+		}
+		if _, err := fmt.Fprintln(w, row[0]); err != nil {
+			return err
 		}
-		table.Render()
+	}
+	return nil
+}
+
+// SetFormat replaces the output format, commands use it to apply the preference of the user
+// when the output flag is not given.
+func (p *PrintFlags) SetFormat(format string) {
+	if p == nil || format == "" {
+		return
+	}
+	p.format = format
+}
+
+// Fields returns the field paths the server needs to return for the custom columns, so that large lists
+// are not transferred as a whole. It is nil when the output needs the whole resources.
+func (p *PrintFlags) Fields() []string {
+	if p == nil {
+		return nil
+	}
+	format, spec, _ := strings.Cut(p.format, "=")
+	if format != formatCustomColumns {
 		return nil
 	}
+	columns, err := parseCustomColumns(spec)
+	if err != nil {
+		return nil
+	}
+	fields := make([]string, 0, len(columns))
+	for _, col := range columns {
+		path := strings.TrimSuffix(strings.TrimPrefix(col.path, "{"), "}")
+		if !simpleFieldPath.MatchString(path) {
+			return nil
+		}
+		fields = append(fields, strings.TrimPrefix(path, "."))
+	}
+	return fields
 }
 
 func (p *PrintFlags) AddFlags(c *cobra.Command) {
 	if p == nil {
 		return
 	}
-	c.Flags().StringVarP(&p.format, "output", "o", p.format, "Output format either: json,yaml,table")
+	c.Flags().StringVarP(&p.format, "output", "o", p.format, "Output format either: json,yaml,table,wide,custom-columns=HEADER:PATH,... or jsonpath=TEMPLATE")
 }
 
 func NewPrintFlags() *PrintFlags {
@@ -23,3 +23,8 @@ type ResourcePrinter interface {
 	YAMLPrint() ([]byte, error)
 	TablePrint() ([]string, [][]string)
 }
+
+// WidePrinter is implemented by resources which print additional columns with -o wide.
+type WidePrinter interface {
+	WideTablePrint() ([]string, [][]string)
+}
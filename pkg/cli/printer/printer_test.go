@@ -0,0 +1,118 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type fakeList map[string]interface{}
+
+func (l fakeList) JSONPrint() ([]byte, error) {
+	return json.Marshal(l)
+}
+
+func (l fakeList) YAMLPrint() ([]byte, error) {
+	return YAMLPrinter(l)
+}
+
+func (l fakeList) TablePrint() ([]string, [][]string) {
+	return []string{"NAME"}, nil
+}
+
+var nodes = fakeList{
+	"items": []interface{}{
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "node1"},
+			"status":   map[string]interface{}{"ipv4DefaultIP": "10.0.0.1"},
+		},
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "node2"},
+		},
+	},
+}
+
+func TestPrintCustomColumns(t *testing.T) {
+	var buf bytes.Buffer
+	p := &PrintFlags{format: "custom-columns=NAME:.metadata.name,IP:status.ipv4DefaultIP"}
+	if err := p.Print(nodes, &buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"NAME", "IP", "node1", "10.0.0.1", "node2", noneValue} {
+		if !strings.Contains(out, want) {
+			t.Errorf("custom columns output misses %q:\n%s", want, out)
+		}
+	}
+	if err := (&PrintFlags{format: "custom-columns=NAME"}).Print(nodes, &buf); err == nil {
+		t.Error("custom columns without path should be refused")
+	}
+}
+
+func TestPrintJSONPath(t *testing.T) {
+	var buf bytes.Buffer
+	p := &PrintFlags{format: "jsonpath={.items[*].metadata.name}"}
+	if err := p.Print(nodes, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "node1 node2\n" {
+		t.Errorf("jsonpath output = %q, want %q", got, "node1 node2\n")
+	}
+}
+
+func TestPrintFlagsFields(t *testing.T) {
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"table", nil},
+		{"custom-columns=NAME:.metadata.name,IP:{.status.ipv4DefaultIP}", []string{"metadata.name", "status.ipv4DefaultIP"}},
+		{"custom-columns=NAME:.metadata.name,GPU:.status.gpus[0].model", nil},
+		{"jsonpath={.items[*].metadata.name}", nil},
+	}
+	for _, tt := range tests {
+		if got := (&PrintFlags{format: tt.format}).Fields(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Fields(%s) = %v, want %v", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestPrintEvent(t *testing.T) {
+	var buf bytes.Buffer
+	p := &PrintFlags{format: "table"}
+	list := fakeList{"items": []interface{}{}}
+	if err := p.PrintEvent("ADDED", list, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "EVENT") || !strings.Contains(buf.String(), "NAME") {
+		t.Errorf("missing event column:\n%s", buf.String())
+	}
+	buf.Reset()
+	p = &PrintFlags{format: "json"}
+	if err := p.PrintEvent("ADDED", list, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "ADDED") {
+		t.Errorf("json output should be the resource only:\n%s", buf.String())
+	}
+}
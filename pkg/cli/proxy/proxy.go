@@ -0,0 +1,213 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/completion"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Run a proxy to the kubeclipper server or to the apiserver of a managed cluster.
+
+  The proxy listens on a loopback address without authentication and forwards every
+  request with the credentials of the current kcctl login, so tools and browsers can
+  reach the API without handling tokens or kubeconfig files. Requests whose Host header
+  does not match --accept-hosts are rejected, so that web pages can not reach the proxy
+  through DNS rebinding.`
+	proxyExample = `
+  # Expose the kubeclipper server API on 127.0.0.1:8001
+  kcctl proxy
+
+  # Expose the apiserver of cluster 'c1' on 127.0.0.1:8002
+  kcctl proxy --cluster c1 --port 8002
+
+  # Serve on every address and accept the requests made to host kc-proxy.example.com
+  kcctl proxy --address 0.0.0.0 --accept-hosts '^kc-proxy\.example\.com$'
+
+  Please read 'kcctl proxy -h' get more proxy flags.`
+)
+
+type ProxyOptions struct {
+	options.IOStreams
+	cliOpts *options.CliOptions
+	client  *kc.Client
+
+	Cluster     string
+	Address     string
+	Port        int
+	AcceptHosts string
+
+	acceptHosts []*regexp.Regexp
+}
+
+// defaultAcceptHosts accepts the loopback hosts only, like kubectl proxy.
+const defaultAcceptHosts = `^localhost$,^127\.0\.0\.1$,^\[::1\]$`
+
+func NewProxyOptions(streams options.IOStreams) *ProxyOptions {
+	return &ProxyOptions{
+		IOStreams:   streams,
+		cliOpts:     options.NewCliOptions(),
+		Address:     "127.0.0.1",
+		Port:        8001,
+		AcceptHosts: defaultAcceptHosts,
+	}
+}
+
+func NewCmdProxy(streams options.IOStreams) *cobra.Command {
+	o := NewProxyOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "proxy [--cluster CLUSTER] [--port PORT]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Run a local proxy to the kubeclipper server or a managed cluster",
+		Long:                  longDescription,
+		Example:               proxyExample,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete(o.cliOpts))
+			utils.CheckErr(o.ValidateArgs(cmd))
+			utils.CheckErr(o.RunProxy())
+		},
+	}
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().StringVar(&o.Cluster, "cluster", o.Cluster, "Proxy to the apiserver of this cluster instead of the kubeclipper server.")
+	cmd.Flags().StringVar(&o.Address, "address", o.Address, "The IP address on which to serve.")
+	cmd.Flags().IntVarP(&o.Port, "port", "p", o.Port, "The port on which to run the proxy.")
+	cmd.Flags().StringVar(&o.AcceptHosts, "accept-hosts", o.AcceptHosts, "Comma separated regular expressions of the hosts the proxy accepts requests for.")
+	utils.CheckErr(cmd.RegisterFlagCompletionFunc("cluster", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		utils.CheckErr(o.Complete(o.cliOpts))
+		return completion.Clusters(o.client, toComplete), cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
+
+func (o *ProxyOptions) Complete(opts *options.CliOptions) error {
+	if err := opts.Complete(); err != nil {
+		return err
+	}
+	c, err := opts.ToRawConfig().ToKcClient()
+	if err != nil {
+		return err
+	}
+	o.client = c
+	return nil
+}
+
+func (o *ProxyOptions) ValidateArgs(cmd *cobra.Command) error {
+	if o.Port < 0 || o.Port > 65535 {
+		return utils.UsageErrorf(cmd, "invalid port %d", o.Port)
+	}
+	if net.ParseIP(o.Address) == nil {
+		return utils.UsageErrorf(cmd, "invalid address %s", o.Address)
+	}
+	o.acceptHosts = nil
+	for _, pattern := range strings.Split(o.AcceptHosts, ",") {
+		if pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return utils.UsageErrorf(cmd, "invalid accept host %s: %v", pattern, err)
+		}
+		o.acceptHosts = append(o.acceptHosts, re)
+	}
+	return nil
+}
+
+func (o *ProxyOptions) RunProxy() error {
+	handler, target, err := o.newHandler()
+	if err != nil {
+		return err
+	}
+	if !net.ParseIP(o.Address).IsLoopback() {
+		logger.Warnf("the proxy serves on the non loopback address %s, anyone reaching it acts with the credentials of the current login", o.Address)
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort(o.Address, strconv.Itoa(o.Port)))
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(o.Out, "Starting to serve on %s, forwarding to %s\n", l.Addr().String(), target)
+	return http.Serve(l, handler)
+}
+
+func (o *ProxyOptions) newHandler() (http.Handler, string, error) {
+	target := &url.URL{Scheme: o.client.Scheme(), Host: o.client.Host()}
+	if o.Cluster != "" {
+		// kc-server forwards to the apiserver with the credentials of the cluster,
+		// so they never leave the server.
+		target.Path = kc.ClusterProxyPath(o.Cluster)
+	}
+	p := newReverseProxy(target, o.client.HTTPClient().Transport, o.client.BearerToken())
+	return withAcceptHosts(o.acceptHosts, p), target.String(), nil
+}
+
+// withAcceptHosts rejects the requests whose Host header, without the port, matches none of hosts.
+func withAcceptHosts(hosts []*regexp.Regexp, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+			if strings.Contains(host, ":") {
+				// SplitHostPort strips the brackets of IPv6 hosts
+				host = "[" + host + "]"
+			}
+		}
+		for _, re := range hosts {
+			if re.MatchString(host) {
+				next.ServeHTTP(w, req)
+				return
+			}
+		}
+		http.Error(w, fmt.Sprintf("host %s is not accepted", req.Host), http.StatusForbidden)
+	})
+}
+
+// newReverseProxy forwards requests to target. Any credentials sent by the local caller
+// are dropped; the request is authenticated with token or by the transport instead.
+func newReverseProxy(target *url.URL, transport http.RoundTripper, token string) *httputil.ReverseProxy {
+	p := httputil.NewSingleHostReverseProxy(target)
+	p.Transport = transport
+	// flush immediately so watch and log follow requests stream through
+	p.FlushInterval = -1
+	director := p.Director
+	p.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+		req.Header.Del("Authorization")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	return p
+}
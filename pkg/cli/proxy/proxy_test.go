@@ -0,0 +1,98 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestNewReverseProxy(t *testing.T) {
+	var gotAuth, gotPath string
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotPath = r.URL.Path
+	}))
+	defer backend.Close()
+	target, _ := url.Parse(backend.URL)
+
+	tests := []struct {
+		name     string
+		token    string
+		incoming string
+		want     string
+	}{
+		{name: "inject token", token: "abc", want: "Bearer abc"},
+		{name: "replace caller credentials", token: "abc", incoming: "Bearer evil", want: "Bearer abc"},
+		{name: "strip caller credentials", incoming: "Bearer evil", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			front := httptest.NewServer(newReverseProxy(target, nil, tt.token))
+			defer front.Close()
+			req, _ := http.NewRequest(http.MethodGet, front.URL+"/api/core.kubeclipper.io/v1/clusters", nil)
+			if tt.incoming != "" {
+				req.Header.Set("Authorization", tt.incoming)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if gotAuth != tt.want {
+				t.Errorf("Authorization = %q, want %q", gotAuth, tt.want)
+			}
+			if gotPath != "/api/core.kubeclipper.io/v1/clusters" {
+				t.Errorf("path = %q", gotPath)
+			}
+		})
+	}
+}
+
+func TestWithAcceptHosts(t *testing.T) {
+	o := &ProxyOptions{Address: "127.0.0.1", AcceptHosts: defaultAcceptHosts}
+	if err := o.ValidateArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	handler := withAcceptHosts(o.acceptHosts, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tests := []struct {
+		host string
+		want int
+	}{
+		{host: "localhost:8001", want: http.StatusOK},
+		{host: "127.0.0.1:8001", want: http.StatusOK},
+		{host: "[::1]:8001", want: http.StatusOK},
+		{host: "localhost", want: http.StatusOK},
+		{host: "evil.example.com:8001", want: http.StatusForbidden},
+		{host: "localhost.evil.example.com", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/core.kubeclipper.io/v1/clusters", nil)
+			req.Host = tt.host
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
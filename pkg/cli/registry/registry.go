@@ -69,7 +69,8 @@ Examples:
   kcctl registry clean --pk-file key --node 10.0.0.111 --registry-volume /opt/registry --data-root /var/lib/docker
   kcctl registry clean --pk-file key --node 10.0.0.111 --registry-volume /opt/registry --data-root /var/lib/docker --force true
 
-  kcctl registry push --pk-file key --node 10.0.0.111 --registry-port 5000 --images-pkg images.tar.gz
+  kcctl registry push --pk-file key --node 10.0.0.111 --registry-port 5000 --images images.tar.gz
+  kcctl registry push --images images.tar.gz
 
   kcctl registry list --node 10.0.0.111 --registry-port 5000 --type repository
   kcctl registry list --node 10.0.0.111 --registry-port 5000 --type repository --number 6
@@ -95,7 +96,7 @@ const (
   # Clean docker registry
   kcctl registry clean --pk-file key --node 10.0.0.111
   # Push docker registry
-  kcctl registry push --pk-file key --node 10.0.0.111 --registry-port 5000 --images-pkg images.tar.gz
+  kcctl registry push --pk-file key --node 10.0.0.111 --registry-port 5000 --images images.tar.gz
   # List docker registry
   kcctl registry list --node 10.0.0.111 --registry-port 5000 --type repository
   # Delete docker registry
@@ -126,7 +127,9 @@ const (
   Push docker image by flags.`
 	pushExample = `
   # Push a Docker image
-  kcctl registry push --pk-file key --node 10.0.0.111 --registry-port 5000 --images-pkg images.tar.gz
+  kcctl registry push --pk-file key --node 10.0.0.111 --registry-port 5000 --images images.tar.gz
+  # Push a Docker image to the registry deployed by 'kcctl deploy --image-registry'
+  kcctl registry push --images images.tar.gz
 
   Please read 'kcctl registry push -h' get more registry push flags.`
 	listLongDescription = `
@@ -172,6 +175,8 @@ type RegistryOptions struct {
 	Number int
 
 	SSHConfig *sshutils.SSH
+	// DeployConfig is read for the node and ssh config of the image registry deployed with kcctl deploy.
+	DeployConfig string
 }
 
 var (
@@ -276,7 +281,7 @@ func NewCmdRegistryClean(o *RegistryOptions) *cobra.Command {
 
 func NewCmdRegistryPush(o *RegistryOptions) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:                   "push (--node <node>) (--arch <arch>) (--registry-port <registry-port>) (--images-pkg <images-pkg>) [flags]push (--node <node>) (--arch <arch>) (--registry-port <registry-port>) (--images-pkg <images-pkg>) [flags]",
+		Use:                   "push (--node <node>) (--arch <arch>) (--registry-port <registry-port>) (--images <images>) [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "registry push image",
 		Long:                  pushLongDescription,
@@ -284,6 +289,7 @@ func NewCmdRegistryPush(o *RegistryOptions) *cobra.Command {
 		Args:                  cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.completeFromDeployConfig(cmd))
 			utils.CheckErr(o.ValidateArgsPush())
 			if !o.preCheck() {
 				return
@@ -293,12 +299,13 @@ func NewCmdRegistryPush(o *RegistryOptions) *cobra.Command {
 	}
 
 	options.AddFlagsToSSH(o.SSHConfig, cmd.Flags())
-	cmd.Flags().StringVar(&o.Node, "node", o.Node, "registry node.")
+	cmd.Flags().StringVar(&o.Node, "node", o.Node, "registry node, defaults to the image registry of the deploy config.")
+	cmd.Flags().StringVar(&o.Pkg, "images", o.Pkg, "docker images pkg.")
 	cmd.Flags().StringVar(&o.Pkg, "images-pkg", o.Pkg, "docker images pkg.")
 	cmd.Flags().IntVar(&o.RegistryPort, "registry-port", o.RegistryPort, "set registry container port")
+	cmd.Flags().StringVar(&o.DeployConfig, "deploy-config", options.DefaultDeployConfigPath, "kcctl deploy config path")
+	utils.CheckErr(cmd.Flags().MarkDeprecated("images-pkg", "use --images instead"))
 
-	utils.CheckErr(cmd.MarkFlagRequired("node"))
-	utils.CheckErr(cmd.MarkFlagRequired("images-pkg"))
 	return cmd
 }
 
@@ -383,6 +390,30 @@ func (o *RegistryOptions) Complete() error {
 	return nil
 }
 
+// completeFromDeployConfig fills the node, port and ssh config which are not specified by flags
+// from the image registry deployed along with the platform.
+func (o *RegistryOptions) completeFromDeployConfig(cmd *cobra.Command) error {
+	if o.Node != "" || o.DeployConfig == "" || !utils.FileExist(o.DeployConfig) {
+		return nil
+	}
+	deployConfig := options.NewDeployOptions()
+	deployConfig.Config = o.DeployConfig
+	if err := deployConfig.Complete(); err != nil {
+		return err
+	}
+	if deployConfig.ImageRegistry == nil || deployConfig.ImageRegistry.Node == "" {
+		return nil
+	}
+	o.Node = deployConfig.ImageRegistry.Node
+	if !cmd.Flags().Changed("registry-port") {
+		o.RegistryPort = deployConfig.ImageRegistry.Port
+	}
+	if o.SSHConfig.PkFile == "" && o.SSHConfig.Password == "" {
+		o.SSHConfig = deployConfig.SSHConfig
+	}
+	return nil
+}
+
 func (o *RegistryOptions) ValidateArgs() error {
 	if o.SSHConfig.PkFile == "" && o.SSHConfig.Password == "" {
 		return fmt.Errorf("one of --pk-file or --passwd must be specified")
@@ -401,7 +432,7 @@ func (o *RegistryOptions) ValidateArgsPush() error {
 		return fmt.Errorf("--node must be specified")
 	}
 	if o.Pkg == "" {
-		return fmt.Errorf("--image-pkg must be specified")
+		return fmt.Errorf("--images must be specified")
 	}
 	return nil
 }
@@ -453,17 +484,8 @@ func (o *RegistryOptions) Install() error {
 		return fmt.Errorf("process package error: %s", err.Error())
 	}
 
-	if err := o.installDocker(); err != nil {
-		return fmt.Errorf("install docker error: %s", err.Error())
-	}
-
-	if err := o.installRegistry(); err != nil {
-		return fmt.Errorf("install registry error: %s", err.Error())
-	}
-
-	// load images
-	if err := o.loadImages(); err != nil {
-		return fmt.Errorf("load images error: %s", err.Error())
+	if err := o.setup(); err != nil {
+		return err
 	}
 
 	// remove pkg
@@ -479,6 +501,35 @@ func (o *RegistryOptions) Install() error {
 	return nil
 }
 
+// InstallFromExtracted installs the registry from the kc package already extracted on the node,
+// kcctl deploy uses it to run the registry on a kc node. The extracted package is left in place.
+func (o *RegistryOptions) InstallFromExtracted() error {
+	if err := o.setup(); err != nil {
+		return err
+	}
+	if err := o.push(); err != nil {
+		return fmt.Errorf("push images error: %s", err.Error())
+	}
+	logger.Info("registry and images install successfully")
+	return nil
+}
+
+func (o *RegistryOptions) setup() error {
+	if err := o.installDocker(); err != nil {
+		return fmt.Errorf("install docker error: %s", err.Error())
+	}
+
+	if err := o.installRegistry(); err != nil {
+		return fmt.Errorf("install registry error: %s", err.Error())
+	}
+
+	// load images
+	if err := o.loadImages(); err != nil {
+		return fmt.Errorf("load images error: %s", err.Error())
+	}
+	return nil
+}
+
 func (o *RegistryOptions) Uninstall() error {
 	// dockerd or docker sometimes gets stuck
 	if o.Force {
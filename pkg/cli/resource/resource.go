@@ -42,6 +42,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
 )
 
@@ -247,6 +248,7 @@ func NewCmdResourcePush(o *ResourceOptions) *cobra.Command {
 	cmd.Flags().StringVar(&o.Type, "type", o.Type, "offline resource type.")
 	cmd.Flags().StringVar(&o.Pkg, "pkg", o.Pkg, "docker service and images pkg.")
 	cmd.Flags().StringVar(&o.DeployConfig, "deploy-config", options.DefaultDeployConfigPath, "kcctl deploy config path")
+	o.deployConfig.AddSignatureFlags(cmd.Flags())
 
 	utils.CheckErr(cmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return o.listType(toComplete), cobra.ShellCompDirectiveNoFileComp
@@ -427,6 +429,9 @@ func (o *ResourceOptions) ResourcePush() error {
 	if err != nil {
 		return err
 	}
+	if err = utils.VerifyPackage(o.Pkg, o.deployConfig.TrustedKeys, o.deployConfig.SkipSignatureVerify); err != nil {
+		return err
+	}
 	if _, ok := httputil.IsURL(o.Pkg); !ok {
 		ec, err := cmdutil.RunCmd(false, "tar", "-tf", o.Pkg)
 		if err != nil {
@@ -496,6 +501,18 @@ func (o *ResourceOptions) ResourcePush() error {
 			logger.Errorf("node(%s) push resource failed: %s", node, err.Error())
 			return err
 		}
+
+		// agents look bundles up by GOARCH name, e.g. amd64 rather than x86_64
+		if normalized := downloader.NormalizeArch(arch); normalized != arch {
+			link := fmt.Sprintf(`ln -sfn %s %s/%s/%s/%s`, arch, o.deployConfig.StaticServerPath, name, version, normalized)
+			ret, err = sshutils.SSHCmdWithSudo(o.SSHConfig, node, link)
+			if err != nil {
+				return err
+			}
+			if err = ret.Error(); err != nil {
+				return err
+			}
+		}
 	}
 
 	// send metadata.json
@@ -0,0 +1,210 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package restoredeleted
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/kc"
+)
+
+const (
+	longDescription = `
+  Restore deleted clusters and backups from the recycle bin.
+
+  When the recycle bin is enabled in the platform setting, deleted clusters and backups
+  are hidden but kept until the retention passes, then they are purged. Deleting them
+  again purges them at once.`
+	restoreDeletedExample = `
+  # List the clusters and backups in the recycle bin
+  kcctl restore-deleted --list
+
+  # Restore a deleted cluster
+  kcctl restore-deleted cluster 'CLUSTER-NAME'
+
+  # Restore a deleted backup
+  kcctl restore-deleted backup 'BACKUP-NAME'
+
+  Please read 'kcctl restore-deleted -h' get more restore-deleted flags.`
+)
+
+var allowedResource = sets.NewString(options.ResourceCluster, options.ResourceBackup)
+
+type RestoreDeletedOptions struct {
+	options.IOStreams
+	cliOpts *options.CliOptions
+	client  *kc.Client
+
+	list     bool
+	resource string
+	name     string
+}
+
+func NewRestoreDeletedOptions(streams options.IOStreams) *RestoreDeletedOptions {
+	return &RestoreDeletedOptions{
+		IOStreams: streams,
+		cliOpts:   options.NewCliOptions(),
+	}
+}
+
+func NewCmdRestoreDeleted(streams options.IOStreams) *cobra.Command {
+	o := NewRestoreDeletedOptions(streams)
+	cmd := &cobra.Command{
+		Use:                   "restore-deleted (<cluster> | <backup>) <name> [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Restore deleted clusters and backups from the recycle bin",
+		Long:                  longDescription,
+		Example:               restoreDeletedExample,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return allowedResource.List(), cobra.ShellCompDirectiveNoFileComp
+			case 1:
+				return o.listDeletedNames(args[0]), cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			utils.CheckErr(o.Complete())
+			utils.CheckErr(o.ValidateArgs(cmd, args))
+			utils.CheckErr(o.RunRestoreDeleted())
+		},
+	}
+
+	o.cliOpts.AddFlags(cmd.Flags())
+	cmd.Flags().BoolVar(&o.list, "list", false, "list the clusters and backups in the recycle bin.")
+	return cmd
+}
+
+func (o *RestoreDeletedOptions) Complete() error {
+	if err := o.cliOpts.Complete(); err != nil {
+		return err
+	}
+	var err error
+	o.client, err = o.cliOpts.ToRawConfig().ToKcClient()
+	return err
+}
+
+func (o *RestoreDeletedOptions) ValidateArgs(cmd *cobra.Command, args []string) error {
+	if o.list {
+		return nil
+	}
+	if len(args) < 1 {
+		return utils.UsageErrorf(cmd, "You must specify the type of resource to restore, support %v now", allowedResource.List())
+	}
+	o.resource = args[0]
+	if !allowedResource.Has(o.resource) {
+		return utils.UsageErrorf(cmd, "unsupported resource type, support %v now", allowedResource.List())
+	}
+	if len(args) < 2 {
+		return utils.UsageErrorf(cmd, "You must specify the name of %s to restore", o.resource)
+	}
+	o.name = args[1]
+	return nil
+}
+
+func (o *RestoreDeletedOptions) RunRestoreDeleted() error {
+	ctx := context.TODO()
+	if o.list {
+		clusters, err := o.client.CoreV1().Clusters().List(ctx, kc.ListOptions{Deleted: true})
+		if err != nil {
+			return err
+		}
+		backups, err := o.client.CoreV1().Backups().List(ctx, kc.ListOptions{Deleted: true})
+		if err != nil {
+			return err
+		}
+		return writeDeleted(o.Out, clusters.Items, backups.Items)
+	}
+	switch o.resource {
+	case options.ResourceCluster:
+		if _, err := o.client.UndeleteCluster(ctx, o.name); err != nil {
+			return err
+		}
+	case options.ResourceBackup:
+		// backup names are unique, the cluster in the path is found from the deleted backup
+		backups, err := o.client.CoreV1().Backups().List(ctx, kc.ListOptions{Deleted: true})
+		if err != nil {
+			return err
+		}
+		cluster := ""
+		for _, b := range backups.Items {
+			if b.Name == o.name {
+				cluster = b.Labels[common.LabelClusterName]
+			}
+		}
+		if cluster == "" {
+			return fmt.Errorf("backup %s is not in the recycle bin", o.name)
+		}
+		if _, err = o.client.UndeleteBackup(ctx, cluster, o.name); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(o.Out, "%s %s restored\n", o.resource, o.name)
+	return nil
+}
+
+// listDeletedNames completes the names of the deleted resources of the type.
+func (o *RestoreDeletedOptions) listDeletedNames(resource string) []string {
+	if o.Complete() != nil {
+		return nil
+	}
+	var names []string
+	switch resource {
+	case options.ResourceCluster:
+		clusters, err := o.client.CoreV1().Clusters().List(context.TODO(), kc.ListOptions{Deleted: true})
+		if err != nil {
+			return nil
+		}
+		for _, c := range clusters.Items {
+			names = append(names, c.Name)
+		}
+	case options.ResourceBackup:
+		backups, err := o.client.CoreV1().Backups().List(context.TODO(), kc.ListOptions{Deleted: true})
+		if err != nil {
+			return nil
+		}
+		for _, b := range backups.Items {
+			names = append(names, b.Name)
+		}
+	}
+	return names
+}
+
+// writeDeleted prints the clusters and backups in the recycle bin with when they were deleted.
+func writeDeleted(out io.Writer, clusters []v1.Cluster, backups []v1.Backup) error {
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KIND\tNAME\tCLUSTER\tDELETED_AT")
+	for _, c := range clusters {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", options.ResourceCluster, c.Name, c.Name, c.Annotations[common.AnnotationDeletedAt])
+	}
+	for _, b := range backups {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", options.ResourceBackup, b.Name, b.Labels[common.LabelClusterName], b.Annotations[common.AnnotationDeletedAt])
+	}
+	return w.Flush()
+}
@@ -0,0 +1,48 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package restoredeleted
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestWriteDeleted(t *testing.T) {
+	at := time.Date(2022, 3, 1, 8, 0, 0, 0, time.UTC)
+	c := v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1"}}
+	v1.MarkDeleted(&c, at)
+	b := v1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "b1", Labels: map[string]string{common.LabelClusterName: "c2"}}}
+	v1.MarkDeleted(&b, at)
+
+	out := &bytes.Buffer{}
+	if err := writeDeleted(out, []v1.Cluster{c}, []v1.Backup{b}); err != nil {
+		t.Fatal(err)
+	}
+	want := "KIND     NAME  CLUSTER  DELETED_AT\n" +
+		"cluster  c1    c1       2022-03-01T08:00:00Z\n" +
+		"backup   b1    c2       2022-03-01T08:00:00Z\n"
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/kubeclipper/kubeclipper/cmd/kcctl/app/options"
+	"github.com/kubeclipper/kubeclipper/pkg/cli/i18n"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
@@ -90,6 +91,6 @@ func PreCheck(name string, sshConfig *sshutils.SSH, streams options.IOStreams, a
 		return true
 	}
 
-	_, _ = streams.Out.Write([]byte("Ignore this error, still exec cmd? Please input (yes/no)"))
+	_, _ = streams.Out.Write([]byte(i18n.T("Ignore this error, still exec cmd? Please input (yes/no)")))
 	return utils.AskForConfirmation()
 }
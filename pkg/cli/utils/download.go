@@ -24,6 +24,7 @@ import (
 	"sync"
 
 	"github.com/kubeclipper/kubeclipper/pkg/utils/httputil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sigutil"
 
 	"github.com/pkg/errors"
 
@@ -32,7 +33,33 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
 )
 
-// SendPackageV2 scp file to remote host
+// VerifyPackage verifies the package at location, a path or an http url, against the signature
+// published next to it at location + ".sig". Nothing is verified without trusted keys, or when skip
+// is set. Remote packages are downloaded, so sending them afterwards reuses the verified copy.
+func VerifyPackage(location string, trustedKeys []string, skip bool) error {
+	if len(trustedKeys) == 0 {
+		return nil
+	}
+	if skip {
+		logger.Warnf("skip verifying the signature of %s", location)
+		return nil
+	}
+	keyring, err := sigutil.ParseKeyring(trustedKeys)
+	if err != nil {
+		return err
+	}
+	file, _, err := downloadFile(location)
+	if err != nil {
+		return errors.Wrapf(err, "get package %s", location)
+	}
+	sigFile, _, err := downloadFile(location + sigutil.SignatureSuffix)
+	if err != nil {
+		return errors.Wrapf(err, "get signature of package %s", location)
+	}
+	return keyring.VerifyFile(file, sigFile)
+}
+
+// SendPackageV2 sends file to remote hosts with the transfer selected for each host
 func SendPackageV2(sshConfig *sshutils.SSH, location string, hosts []string, dstDir string, before, after *string) error {
 	var md5 string
 	// download pkg to /tmp/kc/
@@ -64,55 +91,11 @@ func SendPackageV2(sshConfig *sshutils.SSH, location string, hosts []string, dst
 					return
 				}
 			}
-			exists, err := sshConfig.IsFileExistV2(host, fullPath)
-			if err != nil {
-				errCh <- errors.WithMessage(err, "IsFileExistV2")
+			transfer := sshConfig.TransferFor(host)
+			if err := transfer.Send(host, location, fullPath, md5); err != nil {
+				errCh <- errors.WithMessagef(err, "send file with %s transfer", transfer.Name())
 				return
 			}
-			if exists {
-				validate, err := sshConfig.ValidateMd5sumLocalWithRemote(host, location, fullPath)
-				if err != nil {
-					errCh <- errors.WithMessage(err, "ValidateMd5sumLocalWithRemote")
-					return
-				}
-
-				if validate {
-					logger.Infof("[%s]SendPackage:  %s file is exist and ValidateMd5 success", host, fullPath)
-				} else {
-					// del then copy
-					rm := fmt.Sprintf("rm -rf %s", fullPath)
-					ret, err := sshutils.SSHCmdWithSudo(sshConfig, host, rm)
-					if err != nil {
-						logger.Errorf("[%s]remove old file(%s) err %s", host, fullPath, err.Error())
-						return
-					}
-					if err = ret.Error(); err != nil {
-						logger.Errorf("[%s]remove old file(%s) err %s", host, fullPath, err.Error())
-						return
-					}
-					ok, err := sshConfig.CopyForMD5V2(host, location, fullPath, md5)
-					if err != nil {
-						logger.Errorf("[%s]copy file(%s) md5 validate failed err %s", host, location, err.Error())
-						return
-					}
-					if ok {
-						logger.Infof("[%s]copy file(%s) md5 validate success", host, location)
-					} else {
-						logger.Errorf("[%s]copy file(%s) md5 validate failed", host, location)
-					}
-				}
-			} else {
-				ok, err := sshConfig.CopyForMD5V2(host, location, fullPath, md5)
-				if err != nil {
-					logger.Errorf("[%s]copy file(%s) md5 validate failed err %s", host, fullPath, err.Error())
-					return
-				}
-				if ok {
-					logger.Infof("[%s]copy file(%s) md5 validate success", host, location)
-				} else {
-					logger.Errorf("[%s]copy file(%s) md5 validate failed", host, location)
-				}
-			}
 
 			if after != nil {
 				logger.V(2).Infof("[%s]please wait for after hook", host)
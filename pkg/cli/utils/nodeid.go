@@ -0,0 +1,124 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+// Sources the ids of agents are generated from on deploy and join.
+const (
+	// NodeIDSourceRandom generates a new id on every join.
+	NodeIDSourceRandom = "random"
+	// NodeIDSourceMachineID derives the id from /etc/machine-id, or the MachineGuid of windows nodes.
+	NodeIDSourceMachineID = "machine-id"
+	// NodeIDSourceSMBIOS derives the id from the SMBIOS system uuid, which survives reinstalling the node.
+	NodeIDSourceSMBIOS = "smbios-uuid"
+)
+
+// nodeIDNamespace is the uuid namespace of the ids derived from machine identities,
+// the raw identities are not used as is because machine-id is meant to be kept private.
+var nodeIDNamespace = uuid.MustParse("6f0d3e34-7d53-4b6c-9a5e-2f6a3c1d8e90")
+
+// placeholderSMBIOS are system uuids set by vendors who do not fill in a real one, they are shared by many machines.
+var placeholderSMBIOS = []string{
+	"00000000-0000-0000-0000-000000000000",
+	"ffffffff-ffff-ffff-ffff-ffffffffffff",
+	"03000200-0400-0500-0006-000700080009",
+}
+
+// NodeIDReader reads the raw identity of the node at ip from source.
+type NodeIDReader func(ip, source string) (string, error)
+
+func ValidateNodeIDSource(source string) error {
+	switch source {
+	case "", NodeIDSourceRandom, NodeIDSourceMachineID, NodeIDSourceSMBIOS:
+		return nil
+	}
+	return fmt.Errorf("unsupported node id source %q, must be one of %s, %s and %s",
+		source, NodeIDSourceRandom, NodeIDSourceMachineID, NodeIDSourceSMBIOS)
+}
+
+// DeriveNodeID returns the id of a node whose identity read from source is raw.
+func DeriveNodeID(source, raw string) (string, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return "", fmt.Errorf("%s is empty", source)
+	}
+	if source == NodeIDSourceSMBIOS {
+		for _, p := range placeholderSMBIOS {
+			if raw == p {
+				return "", fmt.Errorf("%s %s is a placeholder of the vendor", source, raw)
+			}
+		}
+	}
+	return uuid.NewSHA1(nodeIDNamespace, []byte(source+":"+raw)).String(), nil
+}
+
+// AgentIDs generates the ids of the agents at ips. Nodes with the same identity are refused,
+// they are usually cloned from the same image without resetting it.
+func AgentIDs(source string, ips []string, read NodeIDReader) (map[string]string, error) {
+	ids := make(map[string]string, len(ips))
+	owners := make(map[string]string, len(ips))
+	for _, ip := range ips {
+		if source == "" || source == NodeIDSourceRandom {
+			ids[ip] = uuid.New().String()
+			continue
+		}
+		raw, err := read(ip, source)
+		if err != nil {
+			return nil, fmt.Errorf("read %s of node %s: %v", source, ip, err)
+		}
+		id, err := DeriveNodeID(source, raw)
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %v", ip, err)
+		}
+		if other, ok := owners[id]; ok {
+			return nil, fmt.Errorf("nodes %s and %s have the same %s, regenerate it on one of them or use the %s node id source",
+				other, ip, source, NodeIDSourceRandom)
+		}
+		owners[id] = ip
+		ids[ip] = id
+	}
+	return ids, nil
+}
+
+// SSHNodeIDReader reads the identity of linux nodes over ssh.
+func SSHNodeIDReader(sshConfig *sshutils.SSH) NodeIDReader {
+	return func(ip, source string) (string, error) {
+		file := "/etc/machine-id"
+		if source == NodeIDSourceSMBIOS {
+			// only readable by root
+			file = "/sys/class/dmi/id/product_uuid"
+		}
+		ret, err := sshutils.SSHCmdWithSudo(sshConfig, ip, "cat "+file)
+		if err != nil {
+			return "", err
+		}
+		if err = ret.Error(); err != nil {
+			return "", err
+		}
+		return ret.Stdout, nil
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDeriveNodeID(t *testing.T) {
+	id, err := DeriveNodeID(NodeIDSourceMachineID, "5f1c3f0e0a7b4b0e9e3c2d1a0b9c8d7e\n")
+	if err != nil {
+		t.Fatalf("DeriveNodeID() error = %v", err)
+	}
+	again, _ := DeriveNodeID(NodeIDSourceMachineID, "5F1C3F0E0A7B4B0E9E3C2D1A0B9C8D7E")
+	if id != again {
+		t.Errorf("DeriveNodeID() = %s and %s for the same machine-id", id, again)
+	}
+	if strings.Contains(id, "5f1c3f0e") {
+		t.Errorf("DeriveNodeID() = %s exposes the machine-id", id)
+	}
+	other, _ := DeriveNodeID(NodeIDSourceSMBIOS, "5f1c3f0e0a7b4b0e9e3c2d1a0b9c8d7e")
+	if id == other {
+		t.Errorf("DeriveNodeID() = %s for both sources", id)
+	}
+	for _, raw := range []string{"", " \n", "00000000-0000-0000-0000-000000000000", "03000200-0400-0500-0006-000700080009"} {
+		if _, err = DeriveNodeID(NodeIDSourceSMBIOS, raw); err == nil {
+			t.Errorf("DeriveNodeID(%q) error = nil, want error", raw)
+		}
+	}
+}
+
+func TestAgentIDs(t *testing.T) {
+	machineIDs := map[string]string{"10.0.0.1": "aaaa", "10.0.0.2": "bbbb", "10.0.0.3": "aaaa"}
+	read := func(ip, source string) (string, error) {
+		id, ok := machineIDs[ip]
+		if !ok {
+			return "", fmt.Errorf("no %s", source)
+		}
+		return id, nil
+	}
+
+	ids, err := AgentIDs(NodeIDSourceMachineID, []string{"10.0.0.1", "10.0.0.2"}, read)
+	if err != nil {
+		t.Fatalf("AgentIDs() error = %v", err)
+	}
+	want, _ := DeriveNodeID(NodeIDSourceMachineID, "aaaa")
+	if ids["10.0.0.1"] != want || ids["10.0.0.2"] == "" || ids["10.0.0.1"] == ids["10.0.0.2"] {
+		t.Errorf("AgentIDs() = %v", ids)
+	}
+	if _, err = AgentIDs(NodeIDSourceMachineID, []string{"10.0.0.1", "10.0.0.3"}, read); err == nil {
+		t.Error("AgentIDs() error = nil for cloned nodes")
+	}
+	if _, err = AgentIDs(NodeIDSourceMachineID, []string{"10.0.0.4"}, read); err == nil {
+		t.Error("AgentIDs() error = nil for unreadable identity")
+	}
+	ids, err = AgentIDs("", []string{"10.0.0.1", "10.0.0.3"}, read)
+	if err != nil || len(ids) != 2 || ids["10.0.0.1"] == ids["10.0.0.3"] {
+		t.Errorf("AgentIDs() with random source = %v, %v", ids, err)
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	"golang.org/x/term"
 
+	"github.com/kubeclipper/kubeclipper/pkg/cli/i18n"
 	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
 )
 
@@ -43,7 +44,7 @@ func AskForConfirmation() bool {
 	case "n", "no":
 		return false
 	default:
-		fmt.Println("I'm sorry but I didn't get what you meant, please type (y)es or (n)o and then press enter:")
+		fmt.Println(i18n.T("I'm sorry but I didn't get what you meant, please type (y)es or (n)o and then press enter:"))
 		return AskForConfirmation()
 	}
 }
@@ -72,7 +72,7 @@ func NewFilteredBackupInformer(client clientset.Interface, resyncPeriod time.Dur
 }
 
 func (f *backupInformer) defaultInformer(client clientset.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
-	return NewFilteredBackupInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+	return NewFilteredBackupInformer(client, resyncPeriod, corev1lister.BackupIndexers(), f.tweakListOptions)
 }
 
 func (f *backupInformer) Informer() cache.SharedIndexInformer {
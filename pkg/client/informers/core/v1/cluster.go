@@ -73,7 +73,7 @@ func NewFilteredClusterInformer(client clientset.Interface, resyncPeriod time.Du
 }
 
 func (f *clusterInformer) defaultInformer(client clientset.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
-	return NewFilteredClusterInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+	return NewFilteredClusterInformer(client, resyncPeriod, corev1lister.ClusterIndexers(), f.tweakListOptions)
 }
 
 func (f *clusterInformer) Informer() cache.SharedIndexInformer {
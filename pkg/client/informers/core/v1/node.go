@@ -71,7 +71,7 @@ func NewFilteredNodeInformer(client clientset.Interface, resyncPeriod time.Durat
 }
 
 func (f *nodeInformer) defaultInformer(client clientset.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
-	return NewFilteredNodeInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+	return NewFilteredNodeInformer(client, resyncPeriod, corev1lister.NodeIndexers(), f.tweakListOptions)
 }
 
 func (f *nodeInformer) Informer() cache.SharedIndexInformer {
@@ -71,7 +71,7 @@ func NewFilteredOperationInformer(client clientset.Interface, resyncPeriod time.
 }
 
 func (f *operationInformer) defaultInformer(client clientset.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
-	return NewFilteredOperationInformer(client, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+	return NewFilteredOperationInformer(client, resyncPeriod, corev1lister.OperationIndexers(), f.tweakListOptions)
 }
 
 func (f *operationInformer) Informer() cache.SharedIndexInformer {
@@ -61,3 +61,11 @@ func (l *backupLister) Get(name string) (*v1.Backup, error) {
 	}
 	return obj.(*v1.Backup), nil
 }
+
+func (l *backupLister) ListByCluster(cluster string) ([]*v1.Backup, error) {
+	return byIndex[v1.Backup](l.indexer, ClusterIndex, cluster)
+}
+
+func (l *backupLister) ListByNode(node string) ([]*v1.Backup, error) {
+	return byIndex[v1.Backup](l.indexer, NodeIndex, node)
+}
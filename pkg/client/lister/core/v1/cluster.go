@@ -61,3 +61,11 @@ func (c *clusterLister) Get(name string) (*v1.Cluster, error) {
 	}
 	return obj.(*v1.Cluster), nil
 }
+
+func (c *clusterLister) ListByRegion(region string) ([]*v1.Cluster, error) {
+	return byIndex[v1.Cluster](c.indexer, RegionIndex, region)
+}
+
+func (c *clusterLister) ListByNode(node string) ([]*v1.Cluster, error) {
+	return byIndex[v1.Cluster](c.indexer, NodeIndex, node)
+}
@@ -18,19 +18,46 @@
 
 package v1
 
+import (
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
 type ClusterListerExpansion interface {
+	// ListByRegion lists the clusters of the region.
+	ListByRegion(region string) ([]*v1.Cluster, error)
+	// ListByNode lists the clusters the node is a master or worker of.
+	ListByNode(node string) ([]*v1.Cluster, error)
 }
 
 type NodeListerExpansion interface {
+	// ListByRegion lists the nodes of the region.
+	ListByRegion(region string) ([]*v1.Node, error)
+	// ListByCluster lists the nodes joined to the cluster.
+	ListByCluster(cluster string) ([]*v1.Node, error)
+	// ListByRole lists the nodes of the role in any cluster.
+	ListByRole(role common.NodeRole) ([]*v1.Node, error)
+	// ListFreeNodes lists the enabled nodes which are not joined to any cluster.
+	ListFreeNodes() ([]*v1.Node, error)
 }
 
 type OperationListerExpansion interface {
+	// ListByCluster lists the operations of the cluster.
+	ListByCluster(cluster string) ([]*v1.Operation, error)
+	// ListRunning lists the running and paused operations of the cluster, which hold its lock.
+	ListRunning(cluster string) ([]*v1.Operation, error)
+	// LatestForCluster returns the most recently created operation of the cluster.
+	LatestForCluster(cluster string) (*v1.Operation, error)
 }
 
 type RegionListerExpansion interface {
 }
 
 type BackupListerExpansion interface {
+	// ListByCluster lists the backups of the cluster.
+	ListByCluster(cluster string) ([]*v1.Backup, error)
+	// ListByNode lists the backups executed on the node.
+	ListByNode(node string) ([]*v1.Backup, error)
 }
 
 type RecoveryListerExpansion interface {
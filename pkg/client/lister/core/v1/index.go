@@ -0,0 +1,181 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// The shared informers index their caches, so that the listers look up the resources of
+// a cluster, region or node directly instead of filtering a full list.
+const (
+	// ClusterIndex indexes resources by the name of the cluster they belong to.
+	ClusterIndex = "cluster"
+	// RegionIndex indexes resources by the region they are in.
+	RegionIndex = "region"
+	// NodeIndex indexes resources by the IDs of the nodes they use.
+	NodeIndex = "node"
+	// RoleIndex indexes nodes by their role in the cluster they are joined to.
+	RoleIndex = "role"
+	// FreeIndex indexes the enabled nodes which are not joined to any cluster under freeIndexValue.
+	FreeIndex = "free"
+	// ClusterStatusIndex indexes operations by their cluster and status, keyed by ClusterStatusIndexKey.
+	ClusterStatusIndex = "cluster-status"
+
+	freeIndexValue = "true"
+)
+
+// NodeIndexers are the indexes of the node cache.
+func NodeIndexers() cache.Indexers {
+	return cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		ClusterIndex:         ClusterLabelIndexFunc,
+		RegionIndex:          RegionLabelIndexFunc,
+		RoleIndex:            RoleLabelIndexFunc,
+		FreeIndex:            FreeNodeIndexFunc,
+	}
+}
+
+// ClusterIndexers are the indexes of the cluster cache.
+func ClusterIndexers() cache.Indexers {
+	return cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		RegionIndex:          RegionLabelIndexFunc,
+		NodeIndex:            ClusterNodeIndexFunc,
+	}
+}
+
+// OperationIndexers are the indexes of the operation cache.
+func OperationIndexers() cache.Indexers {
+	return cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		ClusterIndex:         ClusterLabelIndexFunc,
+		ClusterStatusIndex:   OperationClusterStatusIndexFunc,
+	}
+}
+
+// BackupIndexers are the indexes of the backup cache.
+func BackupIndexers() cache.Indexers {
+	return cache.Indexers{
+		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+		ClusterIndex:         ClusterLabelIndexFunc,
+		NodeIndex:            BackupNodeIndexFunc,
+	}
+}
+
+// ClusterLabelIndexFunc indexes resources by their cluster label.
+func ClusterLabelIndexFunc(obj interface{}) ([]string, error) {
+	return labelIndexFunc(obj, common.LabelClusterName)
+}
+
+// RegionLabelIndexFunc indexes resources by their region label.
+func RegionLabelIndexFunc(obj interface{}) ([]string, error) {
+	return labelIndexFunc(obj, common.LabelTopologyRegion)
+}
+
+// RoleLabelIndexFunc indexes nodes by their role label.
+func RoleLabelIndexFunc(obj interface{}) ([]string, error) {
+	return labelIndexFunc(obj, common.LabelNodeRole)
+}
+
+// FreeNodeIndexFunc indexes the nodes which may join a cluster, they have neither a cluster nor a role
+// and are not disabled.
+func FreeNodeIndexFunc(obj interface{}) ([]string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	for _, label := range []string{common.LabelClusterName, common.LabelNodeRole, common.LabelNodeDisable} {
+		if _, ok := accessor.GetLabels()[label]; ok {
+			return nil, nil
+		}
+	}
+	return []string{freeIndexValue}, nil
+}
+
+func labelIndexFunc(obj interface{}, label string) ([]string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if v := accessor.GetLabels()[label]; v != "" {
+		return []string{v}, nil
+	}
+	return nil, nil
+}
+
+// ClusterNodeIndexFunc indexes clusters by their master and worker nodes.
+func ClusterNodeIndexFunc(obj interface{}) ([]string, error) {
+	c, ok := obj.(*v1.Cluster)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T in cluster index", obj)
+	}
+	if c.Kubeadm == nil {
+		return nil, nil
+	}
+	return append(c.Kubeadm.Masters.GetNodeIDs(), c.Kubeadm.Workers.GetNodeIDs()...), nil
+}
+
+// BackupNodeIndexFunc indexes backups by the node executing them.
+func BackupNodeIndexFunc(obj interface{}) ([]string, error) {
+	b, ok := obj.(*v1.Backup)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T in backup index", obj)
+	}
+	if b.PreferredNode == "" {
+		return nil, nil
+	}
+	return []string{b.PreferredNode}, nil
+}
+
+// OperationClusterStatusIndexFunc indexes operations by the cluster they run on and their status.
+func OperationClusterStatusIndexFunc(obj interface{}) ([]string, error) {
+	op, ok := obj.(*v1.Operation)
+	if !ok {
+		return nil, fmt.Errorf("unexpected object %T in operation index", obj)
+	}
+	cluster := op.Labels[common.LabelClusterName]
+	if cluster == "" {
+		return nil, nil
+	}
+	return []string{ClusterStatusIndexKey(cluster, op.Status.Status)}, nil
+}
+
+// ClusterStatusIndexKey returns the ClusterStatusIndex value of the operations of the cluster in the status.
+func ClusterStatusIndexKey(cluster string, status v1.OperationStatusType) string {
+	return cluster + "/" + string(status)
+}
+
+// byIndex returns the cached objects of the indexed value, the lookup does not depend on the size of the cache.
+func byIndex[T any](indexer cache.Indexer, index, value string) ([]*T, error) {
+	objs, err := indexer.ByIndex(index, value)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*T, 0, len(objs))
+	for _, obj := range objs {
+		ret = append(ret, obj.(*T))
+	}
+	return ret, nil
+}
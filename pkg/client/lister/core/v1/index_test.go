@@ -0,0 +1,210 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestClusterListerIndexes(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, ClusterIndexers())
+	for _, c := range []*v1.Cluster{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "c1", Labels: map[string]string{common.LabelTopologyRegion: "r1"}},
+			Kubeadm:    &v1.Kubeadm{Masters: v1.WorkerNodeList{{ID: "n1"}}, Workers: v1.WorkerNodeList{{ID: "n2"}}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "c2", Labels: map[string]string{common.LabelTopologyRegion: "r2"}},
+			Kubeadm:    &v1.Kubeadm{Masters: v1.WorkerNodeList{{ID: "n3"}}},
+		},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c3"}},
+	} {
+		if err := indexer.Add(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := NewClusterLister(indexer)
+
+	clusters, err := lister.ListByRegion("r2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "c2" {
+		t.Errorf("unexpected clusters of region r2: %v", clusters)
+	}
+	clusters, err = lister.ListByNode("n2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(clusters) != 1 || clusters[0].Name != "c1" {
+		t.Errorf("unexpected clusters of node n2: %v", clusters)
+	}
+	if clusters, _ = lister.ListByNode("n4"); len(clusters) != 0 {
+		t.Errorf("node n4 is not used, got %v", clusters)
+	}
+}
+
+func TestBackupListerIndexes(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, BackupIndexers())
+	for _, b := range []*v1.Backup{
+		{ObjectMeta: metav1.ObjectMeta{Name: "b1", Labels: map[string]string{common.LabelClusterName: "c1"}}, PreferredNode: "n1"},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b2", Labels: map[string]string{common.LabelClusterName: "c1"}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b3", Labels: map[string]string{common.LabelClusterName: "c2"}}, PreferredNode: "n1"},
+	} {
+		if err := indexer.Add(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := NewBackupLister(indexer)
+
+	backups, err := lister.ListByCluster("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("expected 2 backups of cluster c1, got %d", len(backups))
+	}
+	backups, err = lister.ListByNode("n1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Errorf("expected 2 backups on node n1, got %d", len(backups))
+	}
+}
+
+func TestNodeListerIndexes(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, NodeIndexers())
+	newNode := func(name string, labels map[string]string) *v1.Node {
+		return &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	for _, n := range []*v1.Node{
+		newNode("n1", map[string]string{common.LabelTopologyRegion: "r1", common.LabelClusterName: "c1", common.LabelNodeRole: string(common.NodeRoleMaster)}),
+		newNode("n2", map[string]string{common.LabelTopologyRegion: "r1", common.LabelClusterName: "c1", common.LabelNodeRole: string(common.NodeRoleWorker)}),
+		newNode("n3", map[string]string{common.LabelTopologyRegion: "r1"}),
+		newNode("n4", map[string]string{common.LabelTopologyRegion: "r2", common.LabelNodeDisable: ""}),
+		newNode("n5", map[string]string{common.LabelTopologyRegion: "r2"}),
+	} {
+		if err := indexer.Add(n); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := NewNodeLister(indexer)
+	names := func(nodes []*v1.Node, err error) sets.String {
+		if err != nil {
+			t.Fatal(err)
+		}
+		set := sets.NewString()
+		for _, n := range nodes {
+			set.Insert(n.Name)
+		}
+		return set
+	}
+
+	if got := names(lister.ListByRegion("r1")); !got.Equal(sets.NewString("n1", "n2", "n3")) {
+		t.Errorf("ListByRegion(r1) = %v", got.List())
+	}
+	if got := names(lister.ListByCluster("c1")); !got.Equal(sets.NewString("n1", "n2")) {
+		t.Errorf("ListByCluster(c1) = %v", got.List())
+	}
+	if got := names(lister.ListByRole(common.NodeRoleWorker)); !got.Equal(sets.NewString("n2")) {
+		t.Errorf("ListByRole(worker) = %v", got.List())
+	}
+	if got := names(lister.ListFreeNodes()); !got.Equal(sets.NewString("n3", "n5")) {
+		t.Errorf("ListFreeNodes() = %v", got.List())
+	}
+
+	// the indexes follow the updates of the nodes
+	joined := newNode("n3", map[string]string{common.LabelTopologyRegion: "r1", common.LabelClusterName: "c1"})
+	if err := indexer.Update(joined); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(lister.ListFreeNodes()); !got.Equal(sets.NewString("n5")) {
+		t.Errorf("ListFreeNodes() after join = %v", got.List())
+	}
+}
+
+func TestOperationListerIndexes(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, OperationIndexers())
+	now := time.Now()
+	for _, op := range []*v1.Operation{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "o1", CreationTimestamp: metav1.NewTime(now.Add(-2 * time.Minute)),
+				Labels: map[string]string{common.LabelClusterName: "c1"}},
+			Status: v1.OperationStatus{Status: v1.OperationStatusSuccessful},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "o2", CreationTimestamp: metav1.NewTime(now.Add(-time.Minute)),
+				Labels: map[string]string{common.LabelClusterName: "c1"}},
+			Status: v1.OperationStatus{Status: v1.OperationStatusPaused},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "o3", CreationTimestamp: metav1.NewTime(now),
+				Labels: map[string]string{common.LabelClusterName: "c2"}},
+			Status: v1.OperationStatus{Status: v1.OperationStatusRunning},
+		},
+	} {
+		if err := indexer.Add(op); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lister := NewOperationLister(indexer)
+
+	ops, err := lister.ListByCluster("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 2 {
+		t.Errorf("expected 2 operations of cluster c1, got %d", len(ops))
+	}
+	ops, err = lister.ListRunning("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 1 || ops[0].Name != "o2" {
+		t.Errorf("unexpected running operations of cluster c1: %v", ops)
+	}
+	latest, err := lister.LatestForCluster("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.Name != "o2" {
+		t.Errorf("expected latest operation o2 of cluster c1, got %s", latest.Name)
+	}
+
+	o2 := latest.DeepCopy()
+	o2.Status.Status = v1.OperationStatusSuccessful
+	if err = indexer.Update(o2); err != nil {
+		t.Fatal(err)
+	}
+	if ops, _ = lister.ListRunning("c1"); len(ops) != 0 {
+		t.Errorf("cluster c1 has no running operation, got %v", ops)
+	}
+	if _, err = lister.LatestForCluster("c3"); !errors.IsNotFound(err) {
+		t.Errorf("expected not found error of cluster c3, got %v", err)
+	}
+}
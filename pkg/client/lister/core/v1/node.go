@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
 
@@ -61,3 +62,19 @@ func (c *nodeLister) Get(name string) (*v1.Node, error) {
 	}
 	return obj.(*v1.Node), nil
 }
+
+func (c *nodeLister) ListByRegion(region string) ([]*v1.Node, error) {
+	return byIndex[v1.Node](c.indexer, RegionIndex, region)
+}
+
+func (c *nodeLister) ListByCluster(cluster string) ([]*v1.Node, error) {
+	return byIndex[v1.Node](c.indexer, ClusterIndex, cluster)
+}
+
+func (c *nodeLister) ListByRole(role common.NodeRole) ([]*v1.Node, error) {
+	return byIndex[v1.Node](c.indexer, RoleIndex, string(role))
+}
+
+func (c *nodeLister) ListFreeNodes() ([]*v1.Node, error) {
+	return byIndex[v1.Node](c.indexer, FreeIndex, freeIndexValue)
+}
@@ -61,3 +61,36 @@ func (c *operationLister) Get(name string) (*v1.Operation, error) {
 	}
 	return obj.(*v1.Operation), nil
 }
+
+func (c *operationLister) ListByCluster(cluster string) ([]*v1.Operation, error) {
+	return byIndex[v1.Operation](c.indexer, ClusterIndex, cluster)
+}
+
+func (c *operationLister) ListRunning(cluster string) ([]*v1.Operation, error) {
+	var ret []*v1.Operation
+	for _, status := range []v1.OperationStatusType{v1.OperationStatusRunning, v1.OperationStatusPaused} {
+		ops, err := byIndex[v1.Operation](c.indexer, ClusterStatusIndex, ClusterStatusIndexKey(cluster, status))
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, ops...)
+	}
+	return ret, nil
+}
+
+func (c *operationLister) LatestForCluster(cluster string) (*v1.Operation, error) {
+	ops, err := c.ListByCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	var latest *v1.Operation
+	for _, op := range ops {
+		if latest == nil || latest.CreationTimestamp.Before(&op.CreationTimestamp) {
+			latest = op
+		}
+	}
+	if latest == nil {
+		return nil, errors.NewNotFound(v1.Resource("operation"), cluster)
+	}
+	return latest, nil
+}
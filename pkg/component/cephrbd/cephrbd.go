@@ -0,0 +1,222 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cephrbd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/csi"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
+)
+
+func init() {
+	if err := csi.Register(&CephRBD{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ csi.Provider  = (*CephRBD)(nil)
+	_ csi.Completer = (*CephRBD)(nil)
+)
+
+const (
+	name              = "ceph-rbd"
+	version           = "v1"
+	namespace         = "ceph-csi-rbd"
+	scName            = "ceph-rbd-sc"
+	defaultKubeletDir = "/var/lib/kubelet"
+)
+
+var (
+	errEmptyClusterID   = errors.New("ceph cluster id must be provided")
+	errEmptyMonitors    = errors.New("ceph monitors must be provided")
+	errEmptyPool        = errors.New("ceph rbd pool must be provided")
+	errEmptyCredentials = errors.New("ceph user id and key must be provided")
+
+	imageFeatures = sets.NewString("layering", "exclusive-lock", "object-map", "fast-diff", "deep-flatten", "journaling")
+	fsTypes       = sets.NewString("ext4", "xfs")
+)
+
+var spec = csi.Spec{
+	Name:             name,
+	Version:          version,
+	Template:         component.TypeTemplate,
+	TitleID:          "cephrbd.metaTitle",
+	Priority:         4,
+	Unique:           true,
+	Namespace:        namespace,
+	StorageClassName: scName,
+	Required:         []string{"clusterID", "monitors", "pool", "userID", "userKey"},
+	Image:            "cephcsi",
+	ImageVersion:     "v3.4.0",
+	NodePlugin:       true,
+	MountOptions:     true,
+}
+
+// CephRBD deploys the rbd plugin of ceph-csi, volumes are provisioned as images of an rbd pool.
+type CephRBD struct {
+	csi.StorageClass
+	ClusterID     string   `json:"clusterID"`     // required
+	Monitors      []string `json:"monitors"`      // required
+	Pool          string   `json:"pool"`          // required
+	UserID        string   `json:"userID"`        // required
+	UserKey       string   `json:"userKey"`       // required
+	ImageFeatures string   `json:"imageFeatures"` // optional
+	FsType        string   `json:"fsType"`        // optional
+	KubeletDir    string   `json:"kubeletDir"`
+}
+
+func (c *CephRBD) Spec() csi.Spec {
+	return spec
+}
+
+// Complete mounts the kubelet dir of the cluster into the node plugin.
+func (c *CephRBD) Complete(metadata *component.ExtraMetadata) {
+	c.KubeletDir = defaultKubeletDir
+	if metadata.KubeletDataDir != "" {
+		c.KubeletDir = metadata.KubeletDataDir
+	}
+}
+
+func (c *CephRBD) Validate() error {
+	if c.ClusterID == "" {
+		return errEmptyClusterID
+	}
+	if len(c.Monitors) == 0 {
+		return errEmptyMonitors
+	}
+	for _, mon := range c.Monitors {
+		if err := validateMonitor(mon); err != nil {
+			return err
+		}
+	}
+	if c.Pool == "" {
+		return errEmptyPool
+	}
+	if c.UserID == "" || c.UserKey == "" {
+		return errEmptyCredentials
+	}
+	for _, f := range strings.Split(c.ImageFeatures, ",") {
+		if !imageFeatures.Has(f) {
+			return fmt.Errorf("unsupported rbd image feature %q, supported features are %v", f, imageFeatures.List())
+		}
+	}
+	if !fsTypes.Has(c.FsType) {
+		return fmt.Errorf("unsupported fs type %q, supported types are %v", c.FsType, fsTypes.List())
+	}
+	return nil
+}
+
+// validateMonitor checks that the monitor is an ip or a hostname, with an optional port.
+func validateMonitor(mon string) error {
+	host := mon
+	if h, _, err := net.SplitHostPort(mon); err == nil {
+		host = h
+	}
+	if !validation.IsHostNameRFC952(host) && !netutil.IsValidIP(host) {
+		return fmt.Errorf("invalid ceph monitor %q", mon)
+	}
+	return nil
+}
+
+func (c *CephRBD) Manifests() string {
+	return manifestsTemplate
+}
+
+func (c *CephRBD) Properties(loc *i18n.Localizer) map[string]component.JSONSchemaProps {
+	return map[string]component.JSONSchemaProps{
+		"clusterID": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "cephrbd.clusterID"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "ceph cluster fsid",
+			Priority:     1,
+			Dependencies: []string{"enabled"},
+		},
+		"monitors": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "cephrbd.monitors"}),
+			Type:         component.JSONSchemaTypeArray,
+			Description:  "ceph monitor addresses, e.g. 192.168.10.10:6789",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+		},
+		"pool": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "cephrbd.pool"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "rbd pool the volumes are created in",
+			Priority:     3,
+			Dependencies: []string{"enabled"},
+		},
+		"userID": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "cephrbd.userID"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("admin"),
+			Description:  "ceph user the volumes are provisioned and mounted with",
+			Priority:     7,
+			Dependencies: []string{"enabled"},
+		},
+		"userKey": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "cephrbd.userKey"}),
+			Type:         component.JSONSchemaTypeString,
+			Mask:         true,
+			Description:  "key of the ceph user",
+			Priority:     8,
+			Dependencies: []string{"enabled"},
+		},
+		"imageFeatures": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "cephrbd.imageFeatures"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("layering"),
+			Description:  "comma separated features of the rbd images",
+			Priority:     9,
+			Dependencies: []string{"enabled"},
+		},
+		"fsType": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "cephrbd.fsType"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("ext4"),
+			Description:  "file system of the volumes",
+			Priority:     10,
+			Dependencies: []string{"enabled"},
+			EnumNames:    []string{"ext4", "xfs"},
+			Enum:         []component.JSON{"ext4", "xfs"},
+		},
+	}
+}
+
+func (c *CephRBD) NewInstance() component.ObjectMeta {
+	return &CephRBD{
+		StorageClass:  csi.NewStorageClass(spec),
+		UserID:        "admin",
+		ImageFeatures: "layering",
+		FsType:        "ext4",
+		KubeletDir:    defaultKubeletDir,
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cephrbd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/csi"
+)
+
+func newComponent(t *testing.T, config string) *csi.Component {
+	itf, ok := component.Load(fmt.Sprintf(component.RegisterFormat, name, version))
+	if !ok {
+		t.Fatal("ceph-rbd component is not registered")
+	}
+	c := itf.NewInstance().(*csi.Component)
+	if err := json.Unmarshal([]byte(config), c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			config: `{"clusterID":"b9127830","monitors":["192.168.10.10:6789","mon-2"],"pool":"kube","userKey":"AQD=="}`,
+		},
+		{
+			name:    "no monitors",
+			config:  `{"clusterID":"b9127830","pool":"kube","userKey":"AQD=="}`,
+			wantErr: true,
+		},
+		{
+			name:    "invalid monitor",
+			config:  `{"clusterID":"b9127830","monitors":["192.168.10.10:6789:1"],"pool":"kube","userKey":"AQD=="}`,
+			wantErr: true,
+		},
+		{
+			name:    "no user key",
+			config:  `{"clusterID":"b9127830","monitors":["192.168.10.10"],"pool":"kube"}`,
+			wantErr: true,
+		},
+		{
+			name:    "unsupported image feature",
+			config:  `{"clusterID":"b9127830","monitors":["192.168.10.10"],"pool":"kube","userKey":"AQD==","imageFeatures":"layering,striping"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := newComponent(t, tt.config).Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitStepsAndRender(t *testing.T) {
+	dir := t.TempDir()
+	c := newComponent(t, fmt.Sprintf(`{"clusterID":"b9127830","monitors":["192.168.10.10:6789"],"pool":"kube","userKey":"AQD==","manifestsDir":%q}`, dir))
+	metadata := component.ExtraMetadata{
+		CRI:            "containerd",
+		Offline:        true,
+		KubeletDataDir: "/data/kubelet",
+		Masters:        component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+		Workers:        component.NodeList{{ID: "w1", IPv4: "192.168.10.11"}},
+	}
+	if err := c.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+		t.Fatal(err)
+	}
+	install := c.GetInstallSteps()
+	if len(install) != 4 || len(install[0].Nodes) != 2 {
+		t.Fatalf("images must be loaded on all nodes before the manifests are applied, got %d install steps", len(install))
+	}
+
+	// the agent renders the manifests from the data of the template command
+	r := newComponent(t, string(install[1].Commands[0].Template.Data))
+	if err := r.Render(context.TODO(), component.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "ceph-rbd-ceph-rbd-sc.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`"192.168.10.10:6789"`,
+		"pool: kube",
+		"--kubelet-registration-path=/data/kubelet/plugins/rbd.csi.ceph.com/csi.sock",
+		"name: ceph-rbd-sc",
+		"namespace: ceph-csi-rbd",
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("manifests do not contain %q", s)
+		}
+	}
+}
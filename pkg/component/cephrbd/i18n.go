@@ -0,0 +1,66 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cephrbd
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "cephrbd.metaTitle",
+			English: "Ceph RBD Setting",
+			Chinese: "Ceph RBD设置",
+		},
+		{
+			ID:      "cephrbd.clusterID",
+			English: "ClusterID",
+			Chinese: "集群ID",
+		},
+		{
+			ID:      "cephrbd.monitors",
+			English: "Monitors",
+			Chinese: "Monitor地址",
+		},
+		{
+			ID:      "cephrbd.pool",
+			English: "Pool",
+			Chinese: "存储池",
+		},
+		{
+			ID:      "cephrbd.userID",
+			English: "UserID",
+			Chinese: "用户",
+		},
+		{
+			ID:      "cephrbd.userKey",
+			English: "UserKey",
+			Chinese: "用户密钥",
+		},
+		{
+			ID:      "cephrbd.imageFeatures",
+			English: "ImageFeatures",
+			Chinese: "镜像特性",
+		},
+		{
+			ID:      "cephrbd.fsType",
+			English: "FsType",
+			Chinese: "文件系统类型",
+		},
+	})
+}
@@ -0,0 +1,469 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package csi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+const (
+	AgentImageLoader = "ImageLoader"
+
+	filenameFormat = "%s-%s.yaml"
+)
+
+var ErrMountOptionsUnsupported = errors.New("mount options are not supported by the storage provider")
+
+// Provider is a storage provisioner deployed to clusters as a storage component. Every provider
+// is registered as its own component, so it is selected at cluster creation or added later like
+// any other component, with its own config.
+type Provider interface {
+	component.ObjectMeta
+	// Spec returns the description shared by all instances of the provider.
+	Spec() Spec
+	// Base returns the storage class settings of the instance.
+	Base() *StorageClass
+	// Validate checks the provider specific settings of the instance.
+	Validate() error
+	// Properties returns the schema of the provider specific settings.
+	Properties(loc *i18n.Localizer) map[string]component.JSONSchemaProps
+	// Manifests returns the template of the resources of the instance, it is rendered with the provider.
+	Manifests() string
+}
+
+// Completer is implemented by providers which need the cluster metadata to render their manifests.
+type Completer interface {
+	Complete(metadata *component.ExtraMetadata)
+}
+
+// Spec describes a provider.
+type Spec struct {
+	// Name and Version are the name and version of the component.
+	Name    string
+	Version string
+	// Template is the last part of the key the manifests template is registered with.
+	Template string
+	// TitleID is the i18n message id of the component title.
+	TitleID string
+	// Priority orders the component in the console.
+	Priority int
+	// Unique is true when a cluster can only have one instance of the provider.
+	Unique bool
+	// Namespace is where the resources of the provider are created by default.
+	Namespace string
+	// StorageClassName is the default name of the storage class.
+	StorageClassName string
+	// Required are the provider specific settings that must be set.
+	Required []string
+	// Image and ImageVersion are the offline image package of the provider.
+	Image        string
+	ImageVersion string
+	// NodePlugin is true when the provider runs on every node, its images are loaded on the workers as well.
+	NodePlugin bool
+	// MountOptions is true when the storage class can have mount options.
+	MountOptions bool
+}
+
+// StorageClass holds the settings shared by the providers. Providers embed it, the settings
+// stay at the top level of the component config.
+type StorageClass struct {
+	ImageRepoMirror  string `json:"imageRepoMirror"` // optional
+	Namespace        string `json:"namespace"`       // optional
+	Replicas         int    `json:"replicas"`
+	ManifestsDir     string `json:"manifestsDir"`  // optional
+	StorageClassName string `json:"scName"`        // required
+	IsDefault        bool   `json:"isDefaultSC"`   // optional
+	ReclaimPolicy    string `json:"reclaimPolicy"` // optional
+	// Dynamically provisioned PersistentVolumes of this storage class are
+	// created with these mountOptions, e.g. ["ro", "soft"].
+	MountOptions []string `json:"mountOptions"` // optional
+}
+
+// NewStorageClass returns the default storage class settings of the provider.
+func NewStorageClass(spec Spec) StorageClass {
+	return StorageClass{
+		Namespace:        spec.Namespace,
+		ManifestsDir:     filepath.Join("/tmp", "."+spec.Name),
+		StorageClassName: spec.StorageClassName,
+		ReclaimPolicy:    "Delete",
+	}
+}
+
+func (s *StorageClass) Base() *StorageClass {
+	return s
+}
+
+func (s *StorageClass) validate(spec Spec) error {
+	if !validation.MatchKubernetesNamespace(s.Namespace) {
+		return validation.ErrInvalidNamespace
+	}
+	if !validation.MatchKubernetesStorageClass(s.StorageClassName) {
+		return validation.ErrInvalidSCName
+	}
+	if !spec.MountOptions && len(s.MountOptions) > 0 {
+		return ErrMountOptionsUnsupported
+	}
+	return validation.MatchKubernetesReclaimPolicy(s.ReclaimPolicy)
+}
+
+// Register registers the provider as a component, together with its manifests template and image loader.
+func Register(p Provider) error {
+	spec := p.Spec()
+	c := &Component{provider: p}
+	if err := component.Register(fmt.Sprintf(component.RegisterFormat, spec.Name, spec.Version), c); err != nil {
+		return err
+	}
+	if err := component.RegisterTemplate(fmt.Sprintf(component.RegisterTemplateKeyFormat, spec.Name, spec.Version, spec.Template), c); err != nil {
+		return err
+	}
+	return component.RegisterAgentStep(fmt.Sprintf(component.RegisterTemplateKeyFormat, spec.Name, spec.Version, AgentImageLoader),
+		&ImageLoader{Name: spec.Image})
+}
+
+var (
+	_ component.Interface      = (*Component)(nil)
+	_ component.TemplateRender = (*Component)(nil)
+	_ component.StepRunnable   = (*ImageLoader)(nil)
+)
+
+// Component deploys the provider it wraps. Its config is the config of the provider.
+type Component struct {
+	provider                                   Provider
+	installSteps, uninstallSteps, upgradeSteps []v1.Step
+}
+
+// Provider returns the provider of the component.
+func (c *Component) Provider() Provider {
+	return c.provider
+}
+
+func (c *Component) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.provider)
+}
+
+func (c *Component) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, c.provider)
+}
+
+func (c *Component) NewInstance() component.ObjectMeta {
+	return &Component{provider: c.provider.NewInstance().(Provider)}
+}
+
+func (c *Component) Ns() string {
+	return ""
+}
+
+func (c *Component) Svc() string {
+	return ""
+}
+
+func (c *Component) RequestPath() string {
+	return ""
+}
+
+func (c *Component) Supported() bool {
+	return false
+}
+
+func (c *Component) GetInstanceName() string {
+	return c.provider.Base().StorageClassName
+}
+
+func (c *Component) RequireExtraCluster() []string {
+	return nil
+}
+
+func (c *Component) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
+	return nil
+}
+
+func (c *Component) GetDependence() []string {
+	return []string{component.InternalCategoryKubernetes}
+}
+
+func (c *Component) Validate() error {
+	if err := c.provider.Base().validate(c.provider.Spec()); err != nil {
+		return err
+	}
+	return c.provider.Validate()
+}
+
+func (c *Component) manifestsFile(name string) string {
+	return filepath.Join(c.provider.Base().ManifestsDir, fmt.Sprintf(filenameFormat, c.provider.Spec().Name, name))
+}
+
+func (c *Component) InitSteps(ctx context.Context) error {
+	metadata := component.GetExtraMetadata(ctx)
+	spec := c.provider.Spec()
+	sc := c.provider.Base()
+	sc.Replicas = len(metadata.Masters.GetNodeIDs())
+	// when the component does not specify an ImageRepoMirror, the cluster LocalRegistry is inherited
+	if sc.ImageRepoMirror == "" {
+		sc.ImageRepoMirror = metadata.LocalRegistry
+	}
+	if completer, ok := c.provider.(Completer); ok {
+		completer.Complete(&metadata)
+	}
+	if metadata.Offline && sc.ImageRepoMirror == "" && spec.Image != "" {
+		loader := &ImageLoader{
+			Name:    spec.Image,
+			Version: spec.ImageVersion,
+			CriType: metadata.CRI,
+			Offline: metadata.Offline,
+		}
+		iData, err := json.Marshal(loader)
+		if err != nil {
+			return err
+		}
+		nodes := utils.UnwrapNodeList(metadata.Masters)
+		if spec.NodePlugin {
+			nodes = append(nodes, utils.UnwrapNodeList(metadata.Workers)...)
+		}
+		c.installSteps = append(c.installSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "ImageLoader",
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, spec.Name, spec.Version, AgentImageLoader),
+					CustomCommand: iData,
+				},
+			},
+		})
+	}
+
+	bytes, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	stepMaster0 := utils.UnwrapNodeList(metadata.Masters[:1])
+	rs := v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       fmt.Sprintf("render-%s-manifests", spec.Name),
+		Timeout:    metav1.Duration{Duration: 3 * time.Second},
+		ErrIgnore:  true,
+		RetryTimes: 1,
+		Nodes:      stepMaster0,
+		Action:     v1.ActionInstall,
+		Commands: []v1.Command{
+			{
+				Type: v1.CommandTemplateRender,
+				Template: &v1.TemplateCommand{
+					Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, spec.Name, spec.Version, spec.Template),
+					Data:     bytes,
+				},
+			},
+		},
+	}
+
+	c.installSteps = append(c.installSteps, []v1.Step{
+		rs,
+		{
+			ID:         strutil.GetUUID(),
+			Name:       fmt.Sprintf("deploy-%s-namespace", spec.Name),
+			Timeout:    metav1.Duration{Duration: 3 * time.Second},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"kubectl", "apply", "-f", c.manifestsFile(sc.Namespace)},
+				},
+			},
+		},
+		{
+			ID:         strutil.GetUUID(),
+			Name:       fmt.Sprintf("deploy-%s", spec.Name),
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"kubectl", "apply", "-f", c.manifestsFile(sc.StorageClassName)},
+				},
+			},
+		},
+	}...)
+
+	c.uninstallSteps = []v1.Step{
+		rs,
+		{
+			ID:         strutil.GetUUID(),
+			Name:       fmt.Sprintf("remove-%s", spec.Name),
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"kubectl", "delete", "-f", c.manifestsFile(sc.StorageClassName)},
+				},
+			},
+		},
+	}
+	return nil
+}
+
+func (c *Component) GetInstallSteps() []v1.Step {
+	return c.installSteps
+}
+
+func (c *Component) GetUninstallSteps() []v1.Step {
+	return c.uninstallSteps
+}
+
+func (c *Component) GetUpgradeSteps() []v1.Step {
+	return c.upgradeSteps
+}
+
+func (c *Component) GetComponentMeta(lang component.Lang) component.Meta {
+	loc := component.GetLocalizer(lang)
+	spec := c.provider.Spec()
+
+	propMap := commonProperties(loc, spec)
+	for k, v := range c.provider.Properties(loc) {
+		propMap[k] = v
+	}
+
+	return component.Meta{
+		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: spec.TitleID}),
+		Name:       spec.Name,
+		Version:    spec.Version,
+		Unique:     spec.Unique,
+		Template:   true,
+		Dependence: []string{component.InternalCategoryKubernetes},
+		Category:   component.InternalCategoryStorage,
+		Priority:   spec.Priority,
+		Schema: &component.JSONSchemaProps{
+			Properties: propMap,
+			Required:   append(append([]string{}, spec.Required...), "scName"),
+			Type:       component.JSONSchemaTypeObject,
+			Default:    nil,
+		},
+	}
+}
+
+func (c *Component) Install(ctx context.Context) error {
+	return nil
+}
+
+func (c *Component) UnInstall(ctx context.Context) error {
+	return nil
+}
+
+func (c *Component) renderNamespace(w io.Writer) error {
+	_, err := tmplutil.New().RenderTo(w, nameSpaceTemplate, c.provider.Base())
+	return err
+}
+
+func (c *Component) renderTo(w io.Writer) error {
+	_, err := tmplutil.New().RenderTo(w, c.provider.Manifests(), c.provider)
+	return err
+}
+
+// Render writes the namespace and the resources of the provider to the manifests dir.
+func (c *Component) Render(ctx context.Context, opts component.Options) error {
+	sc := c.provider.Base()
+	if err := os.MkdirAll(sc.ManifestsDir, 0755); err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileWithContext(ctx, c.manifestsFile(sc.Namespace), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+		c.renderNamespace, opts.DryRun); err != nil {
+		return err
+	}
+	return fileutil.WriteFileWithContext(ctx, c.manifestsFile(sc.StorageClassName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+		c.renderTo, opts.DryRun)
+}
+
+// ImageLoader loads the offline image package of a provider into the cri of the node.
+type ImageLoader struct {
+	Name    string
+	Version string
+	CriType string
+	Offline bool
+}
+
+func (l *ImageLoader) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, l.Name, l.Version, runtime.GOARCH, !l.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	dstFile, err := instance.DownloadImages()
+	if err != nil {
+		return nil, err
+	}
+	// load image package
+	if err = utils.LoadImage(ctx, opts.DryRun, dstFile, l.CriType); err == nil {
+		logger.Infof("%s packages offline install successfully", l.Name)
+	}
+
+	return nil, err
+}
+
+func (l *ImageLoader) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, l.Name, l.Version, runtime.GOARCH, !l.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveImages(); err != nil {
+		logger.Error("remove images compressed file failed", zap.String("package", l.Name), zap.Error(err))
+	}
+	return nil, nil
+}
+
+// NewInstance keeps the package name, image loader steps created before the providers were
+// generalized do not carry it.
+func (l *ImageLoader) NewInstance() component.ObjectMeta {
+	return &ImageLoader{Name: l.Name}
+}
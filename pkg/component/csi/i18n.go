@@ -0,0 +1,126 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package csi
+
+import (
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+)
+
+func init() {
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+// commonProperties returns the schema of the storage class settings shared by the providers.
+func commonProperties(loc *i18n.Localizer, spec Spec) map[string]component.JSONSchemaProps {
+	f := component.JSON(false)
+	sc := component.JSON(spec.StorageClassName)
+
+	props := map[string]component.JSONSchemaProps{
+		"scName": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "csi.scName"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      &sc,
+			Description:  "Storage Class name",
+			Priority:     4,
+			Dependencies: []string{"enabled"},
+		},
+		"isDefaultSC": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "csi.isDefaultSC"}),
+			Type:         component.JSONSchemaTypeBool,
+			Default:      &f,
+			Description:  "set as default Storage Class",
+			Priority:     5,
+			Dependencies: []string{"enabled"},
+		},
+		"reclaimPolicy": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "csi.reclaimPolicy"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("Delete"),
+			Description:  "Storage Class reclaim policy",
+			Priority:     6,
+			Dependencies: []string{"enabled"},
+			EnumNames:    []string{"Retain", "Delete"},
+			Enum:         []component.JSON{"Retain", "Delete"},
+		},
+		"replicas": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "csi.replicas"}),
+			Type:         component.JSONSchemaTypeInt,
+			Default:      component.JSON(1),
+			Description:  "provisioner replicas. It should only run on the master nodes",
+			Priority:     11,
+			Dependencies: []string{"enabled"},
+		},
+		"imageRepoMirror": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "csi.imageRepoMirror"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "image repository mirror, the component official repository is used by default",
+			Priority:     12,
+			Dependencies: []string{"enabled"},
+		},
+	}
+	if spec.MountOptions {
+		props["mountOptions"] = component.JSONSchemaProps{
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "csi.mountOptions"}),
+			Type:         component.JSONSchemaTypeArray,
+			Description:  "mount options of the persistent volumes",
+			Priority:     8,
+			Dependencies: []string{"enabled"},
+		}
+	}
+	return props
+}
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "csi.scName",
+			English: "StorageClassName",
+			Chinese: "存储类名",
+		},
+		{
+			ID:      "csi.isDefaultSC",
+			English: "IsDefault",
+			Chinese: "是否默认存储类",
+		},
+		{
+			ID:      "csi.reclaimPolicy",
+			English: "ReclaimPolicy",
+			Chinese: "回收策略",
+		},
+		{
+			ID:      "csi.mountOptions",
+			English: "MountOptions",
+			Chinese: "挂载选项",
+		},
+		{
+			ID:      "csi.replicas",
+			English: "Replicas",
+			Chinese: "副本数",
+		},
+		{
+			ID:      "csi.imageRepoMirror",
+			English: "Image Repository Mirror",
+			Chinese: "镜像仓库代理",
+		},
+	})
+}
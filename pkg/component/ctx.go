@@ -29,8 +29,12 @@ type (
 	stepKey      struct{}
 	oplogKey     struct{}
 	retryKey     struct{}
+	progressKey  struct{}
 )
 
+// ProgressReporter reports the phase reached by the running step.
+type ProgressReporter func(phase, message string)
+
 type ExtraMetadata struct {
 	// master, worker node info
 	// Offline 代表是在线还是离线安装
@@ -44,6 +48,10 @@ type ExtraMetadata struct {
 	CRI           string
 	ClusterName   string
 	KubeVersion   string
+	// KubeletDataDir is the root directory of kubelet, empty for the default /var/lib/kubelet.
+	KubeletDataDir string
+	// CRIDataDir is the root directory of the container runtime, empty for the default of the runtime.
+	CRIDataDir string
 }
 
 type Node struct {
@@ -52,6 +60,10 @@ type Node struct {
 	Region   string
 	Hostname string
 	Role     string
+	// Arch is the architecture the node reported, in GOARCH naming.
+	Arch string
+	// OSFamily is the family of the linux distribution the node runs.
+	OSFamily string
 	Disable  bool
 }
 
@@ -64,6 +76,26 @@ func (l NodeList) GetNodeIDs() (nodes []string) {
 	return
 }
 
+func (l NodeList) GetNodeIPs() (ips []string) {
+	for _, node := range l {
+		ips = append(ips, node.IPv4)
+	}
+	return
+}
+
+// GetArchs returns the architectures of the nodes in the order they first appear.
+func (l NodeList) GetArchs() (archs []string) {
+	seen := make(map[string]struct{})
+	for _, node := range l {
+		if _, ok := seen[node.Arch]; ok || node.Arch == "" {
+			continue
+		}
+		seen[node.Arch] = struct{}{}
+		archs = append(archs, node.Arch)
+	}
+	return
+}
+
 func (e ExtraMetadata) GetAllNodeIDs() []string {
 	var nodes []string
 	nodes = append(nodes, e.GetMasterNodeIDs()...)
@@ -95,6 +127,24 @@ func (e ExtraMetadata) GetWorkerHostname(id string) string {
 	return ""
 }
 
+func (e ExtraMetadata) GetWorkerArch(id string) string {
+	for _, node := range e.Workers {
+		if node.ID == id {
+			return node.Arch
+		}
+	}
+	return ""
+}
+
+func (e ExtraMetadata) GetWorkerOSFamily(id string) string {
+	for _, node := range e.Workers {
+		if node.ID == id {
+			return node.OSFamily
+		}
+	}
+	return ""
+}
+
 func (e ExtraMetadata) GetMasterNodeIDs() []string {
 	var nodes []string
 	for _, node := range e.Masters {
@@ -192,3 +242,15 @@ func GetRetry(ctx context.Context) bool {
 	}
 	return false
 }
+
+func WithProgressReporter(ctx context.Context, reporter ProgressReporter) context.Context {
+	return context.WithValue(ctx, progressKey{}, reporter)
+}
+
+// GetProgressReporter returns the reporter of the context, a no-op one when it has none.
+func GetProgressReporter(ctx context.Context) ProgressReporter {
+	if v := ctx.Value(progressKey{}); v != nil {
+		return v.(ProgressReporter)
+	}
+	return func(phase, message string) {}
+}
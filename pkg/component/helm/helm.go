@@ -0,0 +1,552 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+func init() {
+	h := &Helm{}
+	if err := component.Register(fmt.Sprintf(component.RegisterFormat, name, version), h); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterTemplate(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, component.TypeTemplate), h); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentInstaller), &Installer{}); err != nil {
+		panic(err)
+	}
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentImageLoader), &ImageLoader{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ component.Interface      = (*Helm)(nil)
+	_ component.TemplateRender = (*Helm)(nil)
+	_ component.StepRunnable   = (*Installer)(nil)
+	_ component.StepRunnable   = (*ImageLoader)(nil)
+)
+
+const (
+	name             = "helm"
+	version          = "v1"
+	manifestsDir     = "/tmp/.helm"
+	AgentInstaller   = "Installer"
+	AgentImageLoader = "ImageLoader"
+
+	// helmVersion is the version of the helm cli package put on the node running the releases.
+	helmVersion = "v3.8.2"
+	// ChartsDir holds the charts of the static server, the configs of a chart package unpack
+	// the chart archive <chart>-<version>.tgz into it.
+	ChartsDir = "/opt/kc/charts"
+
+	ociPrefix      = "oci://"
+	releaseTimeout = 10 * time.Minute
+)
+
+var (
+	errEmptyChart        = errors.New("chart must be provided")
+	errEmptyChartVersion = errors.New("chart version must be provided for the charts of the static server")
+	errInvalidRelease    = errors.New("release name must be a DNS-1123 label of at most 53 characters")
+	errInvalidRepo       = errors.New("chart repository must be a http or https url")
+	errRepoWithOCI       = errors.New("chart repository can not be used with an oci chart")
+)
+
+// Helm installs a helm chart as a release of the cluster, so that third-party addons are deployed
+// without step code of their own. The chart is pulled from an OCI registry or a chart repository,
+// or downloaded from the static server as a package.
+type Helm struct {
+	ReleaseName string `json:"releaseName"`
+	Namespace   string `json:"namespace"`
+	// Chart is the chart name, or the full reference of an OCI chart, e.g. oci://registry/charts/redis.
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion"` // optional for the charts of OCI registries and repositories
+	// Repo is the url of the chart repository, the chart is downloaded from the static server when
+	// both Repo is empty and Chart is not an OCI reference.
+	Repo string `json:"repo"` // optional
+	// Values is the YAML document overriding the default values of the chart.
+	Values string `json:"values"` // optional
+	// ChartImages tells the chart package of the static server carries the images of the chart,
+	// they are loaded on all nodes of offline clusters.
+	ChartImages                                bool   `json:"chartImages"`  // optional
+	ManifestsDir                               string `json:"manifestsDir"` // optional
+	installSteps, uninstallSteps, upgradeSteps []v1.Step
+}
+
+func (h *Helm) Ns() string {
+	return h.Namespace
+}
+
+func (h *Helm) Svc() string {
+	return ""
+}
+
+func (h *Helm) RequestPath() string {
+	return ""
+}
+
+func (h *Helm) Supported() bool {
+	return false
+}
+
+func (h *Helm) GetInstanceName() string {
+	return h.Namespace + "/" + h.ReleaseName
+}
+
+func (h *Helm) RequireExtraCluster() []string {
+	return nil
+}
+
+func (h *Helm) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
+	return nil
+}
+
+func (h *Helm) fromStaticServer() bool {
+	return h.Repo == "" && !strings.HasPrefix(h.Chart, ociPrefix)
+}
+
+// chartRef is the chart argument of helm install.
+func (h *Helm) chartRef() string {
+	if h.fromStaticServer() {
+		return filepath.Join(ChartsDir, fmt.Sprintf("%s-%s.tgz", h.Chart, h.ChartVersion))
+	}
+	return h.Chart
+}
+
+func (h *Helm) valuesFile() string {
+	return filepath.Join(h.ManifestsDir, fmt.Sprintf("%s-%s-values.yaml", h.Namespace, h.ReleaseName))
+}
+
+func (h *Helm) Validate() error {
+	if len(h.ReleaseName) > 53 || len(k8svalidation.IsDNS1123Label(h.ReleaseName)) > 0 {
+		return errInvalidRelease
+	}
+	if !validation.MatchKubernetesNamespace(h.Namespace) {
+		return validation.ErrInvalidNamespace
+	}
+	if h.Chart == "" {
+		return errEmptyChart
+	}
+	if h.Repo != "" {
+		if strings.HasPrefix(h.Chart, ociPrefix) {
+			return errRepoWithOCI
+		}
+		u, err := url.Parse(h.Repo)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errInvalidRepo
+		}
+	}
+	if h.fromStaticServer() && h.ChartVersion == "" {
+		return errEmptyChartVersion
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(h.Values), &values); err != nil {
+		return fmt.Errorf("invalid values of chart %s: %v", h.Chart, err)
+	}
+	return nil
+}
+
+// upgradeCommand installs the release, or upgrades it when it exists already,
+// so the install steps also upgrade the release to a new chart version or values.
+func (h *Helm) upgradeCommand() []string {
+	cmd := []string{"helm", "upgrade", "--install", h.ReleaseName, h.chartRef(),
+		"--namespace", h.Namespace, "--create-namespace",
+		"--values", h.valuesFile(), "--wait", "--timeout", releaseTimeout.String()}
+	if h.Repo != "" {
+		cmd = append(cmd, "--repo", h.Repo)
+	}
+	if h.ChartVersion != "" && !h.fromStaticServer() {
+		cmd = append(cmd, "--version", h.ChartVersion)
+	}
+	return cmd
+}
+
+func (h *Helm) InitSteps(ctx context.Context) error {
+	metadata := component.GetExtraMetadata(ctx)
+	stepMaster0 := utils.UnwrapNodeList(metadata.Masters[:1])
+
+	if h.fromStaticServer() && h.ChartImages && metadata.Offline {
+		loader := &ImageLoader{
+			Chart:        h.Chart,
+			ChartVersion: h.ChartVersion,
+			CriType:      metadata.CRI,
+			Offline:      metadata.Offline,
+		}
+		lData, err := json.Marshal(loader)
+		if err != nil {
+			return err
+		}
+		h.installSteps = append(h.installSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "chartImageLoader",
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      utils.UnwrapNodeList(metadata.GetAllNodes()),
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentImageLoader),
+					CustomCommand: lData,
+				},
+			},
+		})
+	}
+
+	installer := &Installer{Version: helmVersion, Offline: metadata.Offline}
+	if h.fromStaticServer() {
+		installer.Chart, installer.ChartVersion = h.Chart, h.ChartVersion
+	}
+	iData, err := json.Marshal(installer)
+	if err != nil {
+		return err
+	}
+	bytes, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	h.installSteps = append(h.installSteps, []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "helmInstaller",
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentInstaller),
+					CustomCommand: iData,
+				},
+			},
+		},
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "renderHelmValues",
+			Timeout:    metav1.Duration{Duration: 3 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type: v1.CommandTemplateRender,
+					Template: &v1.TemplateCommand{
+						Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, component.TypeTemplate),
+						Data:     bytes,
+					},
+				},
+			},
+		},
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "deployHelmRelease",
+			Timeout:    metav1.Duration{Duration: releaseTimeout + time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: h.upgradeCommand(),
+				},
+			},
+		},
+	}...)
+
+	h.uninstallSteps = []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "removeHelmRelease",
+			Timeout:    metav1.Duration{Duration: releaseTimeout + time.Minute},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"helm", "uninstall", h.ReleaseName, "--namespace", h.Namespace, "--wait", "--timeout", releaseTimeout.String()},
+				},
+			},
+		},
+	}
+	// the helm cli is kept on the node for the other releases, only the chart package is removed.
+	if h.fromStaticServer() {
+		cData, err := json.Marshal(&Installer{Chart: h.Chart, ChartVersion: h.ChartVersion, Offline: metadata.Offline})
+		if err != nil {
+			return err
+		}
+		h.uninstallSteps = append(h.uninstallSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "removeHelmChart",
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentInstaller),
+					CustomCommand: cData,
+				},
+			},
+		})
+	}
+	return nil
+}
+
+func (h *Helm) GetComponentMeta(lang component.Lang) component.Meta {
+	loc := component.GetLocalizer(lang)
+	f := component.JSON(false)
+
+	propMap := map[string]component.JSONSchemaProps{
+		"releaseName": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.releaseName"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "name of the helm release",
+			Priority:     1,
+			Dependencies: []string{"enabled"},
+		},
+		"namespace": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.namespace"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("default"),
+			Description:  "namespace of the helm release, it is created if not exists",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+		},
+		"chart": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.chart"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "chart name, or the reference of an oci chart like oci://registry/charts/redis",
+			Priority:     3,
+			Dependencies: []string{"enabled"},
+		},
+		"chartVersion": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.chartVersion"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "chart version, the latest version of the oci registry or chart repository is used when empty",
+			Priority:     4,
+			Dependencies: []string{"enabled"},
+		},
+		"repo": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.repo"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "chart repository url, the chart is downloaded from the static server when both repository is empty and chart is not an oci reference",
+			Priority:     5,
+			Dependencies: []string{"enabled"},
+		},
+		"values": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.values"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "YAML values overriding the default values of the chart",
+			Priority:     6,
+			Dependencies: []string{"enabled"},
+		},
+		"chartImages": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.chartImages"}),
+			Type:         component.JSONSchemaTypeBool,
+			Default:      &f,
+			Description:  "the chart package of the static server carries images, they are loaded on all nodes of offline clusters",
+			Priority:     7,
+			Dependencies: []string{"enabled"},
+		},
+	}
+
+	return component.Meta{
+		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "helm.metaTitle"}),
+		Name:       name,
+		Version:    version,
+		Unique:     false,
+		Template:   false,
+		Dependence: []string{component.InternalCategoryKubernetes},
+		Category:   component.InternalCategoryPAAS,
+		Priority:   5,
+		Schema: &component.JSONSchemaProps{
+			Properties: propMap,
+			Required:   []string{"releaseName", "namespace", "chart"},
+			Type:       component.JSONSchemaTypeObject,
+			Default:    nil,
+		},
+	}
+}
+
+func (h *Helm) NewInstance() component.ObjectMeta {
+	return &Helm{
+		Namespace:    "default",
+		ManifestsDir: manifestsDir,
+	}
+}
+
+func (h *Helm) GetDependence() []string {
+	return []string{component.InternalCategoryKubernetes}
+}
+
+func (h *Helm) GetInstallSteps() []v1.Step {
+	return h.installSteps
+}
+
+func (h *Helm) GetUninstallSteps() []v1.Step {
+	return h.uninstallSteps
+}
+
+// GetUpgradeSteps is empty, the release is upgraded by the install steps which run helm upgrade --install.
+func (h *Helm) GetUpgradeSteps() []v1.Step {
+	return h.upgradeSteps
+}
+
+func (h *Helm) renderValues(w io.Writer) error {
+	_, err := io.WriteString(w, h.Values)
+	return err
+}
+
+func (h *Helm) Render(ctx context.Context, opts component.Options) error {
+	if err := os.MkdirAll(h.ManifestsDir, 0755); err != nil {
+		return err
+	}
+	return fileutil.WriteFileWithContext(ctx, h.valuesFile(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600,
+		h.renderValues, opts.DryRun)
+}
+
+// Installer puts the helm cli on the node, and the chart package when the chart is from the static server.
+type Installer struct {
+	Version      string
+	Chart        string
+	ChartVersion string
+	Offline      bool
+}
+
+func (n *Installer) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if n.Version != "" {
+		instance, err := downloader.NewInstance(ctx, name, n.Version, runtime.GOARCH, !n.Offline, opts.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = instance.DownloadAndUnpackConfigs(); err != nil {
+			return nil, err
+		}
+	}
+	if n.Chart == "" {
+		return nil, nil
+	}
+	instance, err := downloader.NewInstance(ctx, n.Chart, n.ChartVersion, runtime.GOARCH, !n.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = instance.DownloadAndUnpackConfigs(); err != nil {
+		return nil, err
+	}
+	logger.Info("helm chart package install successfully", zap.String("chart", n.Chart))
+	return nil, nil
+}
+
+// Uninstall removes the chart package only, the helm cli may be used by other releases.
+func (n *Installer) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	if n.Chart == "" {
+		return nil, nil
+	}
+	instance, err := downloader.NewInstance(ctx, n.Chart, n.ChartVersion, runtime.GOARCH, !n.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveConfigs(); err != nil {
+		logger.Error("remove helm chart package failed", zap.String("chart", n.Chart), zap.Error(err))
+	}
+	return nil, nil
+}
+
+func (n *Installer) NewInstance() component.ObjectMeta {
+	return &Installer{}
+}
+
+// ImageLoader loads the images carried by the chart package of the static server.
+type ImageLoader struct {
+	Chart        string
+	ChartVersion string
+	CriType      string
+	Offline      bool
+}
+
+func (l *ImageLoader) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, l.Chart, l.ChartVersion, runtime.GOARCH, !l.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	dstFile, err := instance.DownloadImages()
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.LoadImage(ctx, opts.DryRun, dstFile, l.CriType); err == nil {
+		logger.Info("helm chart images offline install successfully", zap.String("chart", l.Chart))
+	}
+	return nil, err
+}
+
+func (l *ImageLoader) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, l.Chart, l.ChartVersion, runtime.GOARCH, !l.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveImages(); err != nil {
+		logger.Error("remove helm chart images compressed file failed", zap.Error(err))
+	}
+	return nil, nil
+}
+
+func (l *ImageLoader) NewInstance() component.ObjectMeta {
+	return &ImageLoader{}
+}
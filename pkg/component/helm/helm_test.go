@@ -0,0 +1,108 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package helm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		helm    Helm
+		wantErr bool
+	}{
+		{name: "static server chart", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "redis", ChartVersion: "16.8.9"}},
+		{name: "oci chart", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "oci://registry.local/charts/redis"}},
+		{name: "repo chart", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "redis", Repo: "https://charts.bitnami.com/bitnami"}},
+		{name: "values", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "redis", ChartVersion: "16.8.9", Values: "auth:\n  enabled: false\n"}},
+		{name: "invalid values", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "redis", ChartVersion: "16.8.9", Values: "auth: [enabled"}, wantErr: true},
+		{name: "invalid release", helm: Helm{ReleaseName: "Redis", Namespace: "db", Chart: "redis", ChartVersion: "16.8.9"}, wantErr: true},
+		{name: "empty chart", helm: Helm{ReleaseName: "redis", Namespace: "db"}, wantErr: true},
+		{name: "static server chart without version", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "redis"}, wantErr: true},
+		{name: "invalid repo", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "redis", Repo: "charts.bitnami.com"}, wantErr: true},
+		{name: "repo with oci chart", helm: Helm{ReleaseName: "redis", Namespace: "db", Chart: "oci://registry.local/charts/redis", Repo: "https://charts.bitnami.com/bitnami"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.helm.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitSteps(t *testing.T) {
+	metadata := component.ExtraMetadata{
+		CRI:     "containerd",
+		Offline: true,
+		Masters: component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+		Workers: component.NodeList{{ID: "w1", IPv4: "192.168.10.11"}},
+	}
+	tests := []struct {
+		name          string
+		modify        func(h *Helm)
+		wantInstall   int
+		wantUninstall int
+		wantCommand   []string
+	}{
+		{
+			name: "static server chart with images",
+			modify: func(h *Helm) {
+				h.Chart, h.ChartVersion, h.ChartImages = "redis", "16.8.9", true
+			},
+			wantInstall:   4,
+			wantUninstall: 2,
+			wantCommand: []string{"helm", "upgrade", "--install", "redis", "/opt/kc/charts/redis-16.8.9.tgz",
+				"--namespace", "db", "--create-namespace", "--values", "/tmp/.helm/db-redis-values.yaml", "--wait", "--timeout", "10m0s"},
+		},
+		{
+			name: "repo chart",
+			modify: func(h *Helm) {
+				h.Chart, h.ChartVersion, h.Repo = "redis", "16.8.9", "https://charts.bitnami.com/bitnami"
+			},
+			wantInstall:   3,
+			wantUninstall: 1,
+			wantCommand: []string{"helm", "upgrade", "--install", "redis", "redis",
+				"--namespace", "db", "--create-namespace", "--values", "/tmp/.helm/db-redis-values.yaml", "--wait", "--timeout", "10m0s",
+				"--repo", "https://charts.bitnami.com/bitnami", "--version", "16.8.9"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := (&Helm{}).NewInstance().(*Helm)
+			h.ReleaseName, h.Namespace = "redis", "db"
+			tt.modify(h)
+			if err := h.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+				t.Fatal(err)
+			}
+			install, uninstall := h.GetInstallSteps(), h.GetUninstallSteps()
+			if len(install) != tt.wantInstall || len(uninstall) != tt.wantUninstall {
+				t.Fatalf("got %d install and %d uninstall steps", len(install), len(uninstall))
+			}
+			if got := install[len(install)-1].Commands[0].ShellCommand; !reflect.DeepEqual(got, tt.wantCommand) {
+				t.Errorf("install command = %v, want %v", got, tt.wantCommand)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package helm
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "helm.metaTitle",
+			English: "Helm Chart Setting",
+			Chinese: "Helm Chart设置",
+		},
+		{
+			ID:      "helm.releaseName",
+			English: "Release Name",
+			Chinese: "Release名称",
+		},
+		{
+			ID:      "helm.namespace",
+			English: "Namespace",
+			Chinese: "命名空间",
+		},
+		{
+			ID:      "helm.chart",
+			English: "Chart",
+			Chinese: "Chart",
+		},
+		{
+			ID:      "helm.chartVersion",
+			English: "Chart Version",
+			Chinese: "Chart版本",
+		},
+		{
+			ID:      "helm.repo",
+			English: "Chart Repository",
+			Chinese: "Chart仓库",
+		},
+		{
+			ID:      "helm.values",
+			English: "Values",
+			Chinese: "配置值",
+		},
+		{
+			ID:      "helm.chartImages",
+			English: "Chart Images",
+			Chinese: "Chart镜像",
+		},
+	})
+}
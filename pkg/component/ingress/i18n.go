@@ -0,0 +1,81 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ingress
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "ingress.metaTitle",
+			English: "Ingress Controller Setting",
+			Chinese: "Ingress控制器设置",
+		},
+		{
+			ID:      "ingress.controller",
+			English: "Controller",
+			Chinese: "控制器",
+		},
+		{
+			ID:      "ingress.mode",
+			English: "Mode",
+			Chinese: "暴露方式",
+		},
+		{
+			ID:      "ingress.replicas",
+			English: "Replicas",
+			Chinese: "副本数",
+		},
+		{
+			ID:      "ingress.httpNodePort",
+			English: "HTTP NodePort",
+			Chinese: "HTTP节点端口",
+		},
+		{
+			ID:      "ingress.httpsNodePort",
+			English: "HTTPS NodePort",
+			Chinese: "HTTPS节点端口",
+		},
+		{
+			ID:      "ingress.ingressClass",
+			English: "IngressClass",
+			Chinese: "Ingress类名",
+		},
+		{
+			ID:      "ingress.isDefaultClass",
+			English: "IsDefault",
+			Chinese: "是否默认Ingress类",
+		},
+		{
+			ID:      "ingress.defaultCertificate",
+			English: "Default Certificate",
+			Chinese: "默认证书",
+		},
+		{
+			ID:      "ingress.defaultCertificateKey",
+			English: "Default Certificate Key",
+			Chinese: "默认证书私钥",
+		},
+		{
+			ID:      "ingress.imageRepoMirror",
+			English: "Ingress Image Repository Mirror",
+			Chinese: "Ingress镜像仓库代理",
+		},
+	})
+}
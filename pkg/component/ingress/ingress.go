@@ -0,0 +1,493 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ingress
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+func init() {
+	in := &Ingress{}
+	if err := component.Register(fmt.Sprintf(component.RegisterFormat, name, version), in); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterTemplate(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, component.TypeTemplate), in); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentImageLoader), &ImageLoader{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ component.Interface      = (*Ingress)(nil)
+	_ component.TemplateRender = (*Ingress)(nil)
+	_ component.StepRunnable   = (*ImageLoader)(nil)
+)
+
+const (
+	name             = "ingress"
+	version          = "v1"
+	manifestsDir     = "/tmp/.ingress"
+	manifestsFile    = "ingress-controller.yaml"
+	AgentImageLoader = "ImageLoader"
+
+	ControllerNginx   = "nginx"
+	ControllerTraefik = "traefik"
+
+	ModeHostNetwork = "hostNetwork"
+	ModeNodePort    = "NodePort"
+)
+
+// controllers are the ingress controllers which can be deployed, with their default namespace,
+// offline image package and manifests.
+var controllers = map[string]struct {
+	namespace    string
+	imagePackage string
+	imageVersion string
+	manifests    string
+}{
+	ControllerNginx:   {namespace: "ingress-nginx", imagePackage: "ingress-nginx", imageVersion: "v1.1.1", manifests: nginxTemplate},
+	ControllerTraefik: {namespace: "traefik", imagePackage: "traefik", imageVersion: "v2.6.1", manifests: traefikTemplate},
+}
+
+var (
+	errUnsupportedController = errors.New("ingress controller must be one of nginx and traefik")
+	errUnsupportedMode       = errors.New("ingress controller mode must be one of hostNetwork and NodePort")
+	errInvalidReplicas       = errors.New("ingress controller replicas must be at least 1")
+	errInvalidNodePort       = errors.New("node port must be in the range 30000-32767")
+	errInvalidIngressClass   = errors.New("invalid ingress class name")
+	errIncompleteCertificate = errors.New("the default certificate and its key must be provided together")
+)
+
+// Ingress deploys an ingress controller to the cluster. In hostNetwork mode the controller pods
+// listen on ports 80 and 443 of the nodes and are spread on different nodes, in NodePort mode
+// they are exposed with a NodePort service.
+type Ingress struct {
+	ImageRepoMirror string `json:"imageRepoMirror"` // optional
+	Namespace       string `json:"namespace"`       // optional, the default namespace of the controller is used when empty
+	ManifestsDir    string `json:"manifestsDir"`    // optional
+	Controller      string `json:"controller"`
+	Mode            string `json:"mode"`
+	Replicas        int    `json:"replicas"`
+	HTTPNodePort    int    `json:"httpNodePort"`  // optional, only used in NodePort mode
+	HTTPSNodePort   int    `json:"httpsNodePort"` // optional, only used in NodePort mode
+	// NodeSelector restricts the nodes the controller pods are scheduled to, e.g. dedicated edge nodes.
+	NodeSelector   map[string]string `json:"nodeSelector"`   // optional
+	IngressClass   string            `json:"ingressClass"`   // optional
+	IsDefaultClass bool              `json:"isDefaultClass"` // optional
+	// DefaultCertificate and DefaultCertificateKey are the PEM encoded certificate served for
+	// the hosts without a certificate of their own.
+	DefaultCertificate                         string `json:"defaultCertificate"`    // optional
+	DefaultCertificateKey                      string `json:"defaultCertificateKey"` // optional
+	installSteps, uninstallSteps, upgradeSteps []v1.Step
+}
+
+func (n *Ingress) Ns() string {
+	return n.Namespace
+}
+
+func (n *Ingress) Svc() string {
+	return ""
+}
+
+func (n *Ingress) RequestPath() string {
+	return ""
+}
+
+func (n *Ingress) Supported() bool {
+	return false
+}
+
+func (n *Ingress) GetInstanceName() string {
+	return n.IngressClass
+}
+
+func (n *Ingress) RequireExtraCluster() []string {
+	return nil
+}
+
+func (n *Ingress) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
+	return nil
+}
+
+func (n *Ingress) Validate() error {
+	if _, ok := controllers[n.Controller]; !ok {
+		return errUnsupportedController
+	}
+	if n.Namespace != "" && !validation.MatchKubernetesNamespace(n.Namespace) {
+		return validation.ErrInvalidNamespace
+	}
+	if n.Mode != ModeHostNetwork && n.Mode != ModeNodePort {
+		return errUnsupportedMode
+	}
+	if n.Replicas < 1 {
+		return errInvalidReplicas
+	}
+	for _, port := range []int{n.HTTPNodePort, n.HTTPSNodePort} {
+		if port != 0 && (port < 30000 || port > 32767) {
+			return errInvalidNodePort
+		}
+	}
+	if errs := k8svalidation.IsDNS1123Subdomain(n.IngressClass); len(errs) > 0 {
+		return errInvalidIngressClass
+	}
+	for k, v := range n.NodeSelector {
+		if errs := k8svalidation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("invalid node selector key %q", k)
+		}
+		if errs := k8svalidation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("invalid node selector value %q", v)
+		}
+	}
+	if (n.DefaultCertificate == "") != (n.DefaultCertificateKey == "") {
+		return errIncompleteCertificate
+	}
+	if n.DefaultCertificate != "" {
+		if _, err := tls.X509KeyPair([]byte(n.DefaultCertificate), []byte(n.DefaultCertificateKey)); err != nil {
+			return fmt.Errorf("invalid default certificate: %v", err)
+		}
+	}
+	return nil
+}
+
+func (n *Ingress) InitSteps(ctx context.Context) error {
+	metadata := component.GetExtraMetadata(ctx)
+	ctrl := controllers[n.Controller]
+	n.Namespace = strutil.StringDefaultIfEmpty(ctrl.namespace, n.Namespace)
+	if n.ImageRepoMirror == "" {
+		n.ImageRepoMirror = metadata.LocalRegistry
+	}
+	allNodes := metadata.GetAllNodes()
+	// pods of hostNetwork mode all bind the same ports, one node can only run one of them
+	if n.Mode == ModeHostNetwork && len(n.NodeSelector) == 0 && n.Replicas > len(allNodes) {
+		return fmt.Errorf("%d replicas of the ingress controller in hostNetwork mode need as many nodes, the cluster has %d",
+			n.Replicas, len(allNodes))
+	}
+	stepMaster0 := utils.UnwrapNodeList(metadata.Masters[:1])
+
+	if metadata.Offline && n.ImageRepoMirror == "" {
+		loader := &ImageLoader{
+			Name:    ctrl.imagePackage,
+			Version: ctrl.imageVersion,
+			CriType: metadata.CRI,
+			Offline: metadata.Offline,
+		}
+		iData, err := json.Marshal(loader)
+		if err != nil {
+			return err
+		}
+		n.installSteps = append(n.installSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "ImageLoader",
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      utils.UnwrapNodeList(allNodes),
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentImageLoader),
+					CustomCommand: iData,
+				},
+			},
+		})
+	}
+
+	bytes, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	renderStep := func(action v1.StepAction) v1.Step {
+		return v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "renderIngressController",
+			Timeout:    metav1.Duration{Duration: 3 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type: v1.CommandTemplateRender,
+					Template: &v1.TemplateCommand{
+						Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, component.TypeTemplate),
+						Data:     bytes,
+					},
+				},
+			},
+		}
+	}
+	manifest := filepath.Join(n.ManifestsDir, manifestsFile)
+
+	n.installSteps = append(n.installSteps, []v1.Step{
+		renderStep(v1.ActionInstall),
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "deployIngressController",
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"kubectl", "apply", "-f", manifest},
+				},
+			},
+		},
+	}...)
+	n.uninstallSteps = []v1.Step{
+		renderStep(v1.ActionUninstall),
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "removeIngressController",
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"kubectl", "delete", "-f", manifest, "--ignore-not-found"},
+				},
+			},
+		},
+	}
+	return nil
+}
+
+func (n *Ingress) GetComponentMeta(lang component.Lang) component.Meta {
+	loc := component.GetLocalizer(lang)
+	f := component.JSON(false)
+
+	propMap := map[string]component.JSONSchemaProps{
+		"controller": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.controller"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON(ControllerNginx),
+			Description:  "ingress controller",
+			Priority:     1,
+			Dependencies: []string{"enabled"},
+			EnumNames:    []string{"nginx", "traefik"},
+			Enum:         []component.JSON{ControllerNginx, ControllerTraefik},
+		},
+		"mode": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.mode"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON(ModeHostNetwork),
+			Description:  "expose the controller on the host network of the nodes or with a NodePort service",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+			EnumNames:    []string{"hostNetwork", "NodePort"},
+			Enum:         []component.JSON{ModeHostNetwork, ModeNodePort},
+		},
+		"replicas": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.replicas"}),
+			Type:         component.JSONSchemaTypeInt,
+			Default:      component.JSON(2),
+			Description:  "controller replicas, they are spread on different nodes",
+			Priority:     3,
+			Dependencies: []string{"enabled"},
+			Props:        &component.Props{Min: 1},
+		},
+		"httpNodePort": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.httpNodePort"}),
+			Type:         component.JSONSchemaTypeInt,
+			Description:  "node port of http in NodePort mode, allocated when empty",
+			Priority:     4,
+			Dependencies: []string{"enabled"},
+		},
+		"httpsNodePort": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.httpsNodePort"}),
+			Type:         component.JSONSchemaTypeInt,
+			Description:  "node port of https in NodePort mode, allocated when empty",
+			Priority:     5,
+			Dependencies: []string{"enabled"},
+		},
+		"ingressClass": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.ingressClass"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON(ControllerNginx),
+			Description:  "name of the ingress class of the controller",
+			Priority:     6,
+			Dependencies: []string{"enabled"},
+		},
+		"isDefaultClass": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.isDefaultClass"}),
+			Type:         component.JSONSchemaTypeBool,
+			Default:      &f,
+			Description:  "set as default ingress class",
+			Priority:     7,
+			Dependencies: []string{"enabled"},
+		},
+		"defaultCertificate": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.defaultCertificate"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "PEM encoded certificate served for the hosts without certificate",
+			Priority:     8,
+			Dependencies: []string{"enabled"},
+		},
+		"defaultCertificateKey": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.defaultCertificateKey"}),
+			Type:         component.JSONSchemaTypeString,
+			Mask:         true,
+			Description:  "PEM encoded private key of the default certificate",
+			Priority:     9,
+			Dependencies: []string{"enabled"},
+		},
+		"imageRepoMirror": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.imageRepoMirror"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "ingress controller image repository mirror, the component official repository is used by default",
+			Priority:     10,
+			Dependencies: []string{"enabled"},
+		},
+	}
+
+	return component.Meta{
+		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "ingress.metaTitle"}),
+		Name:       name,
+		Version:    version,
+		Unique:     true,
+		Template:   false,
+		Dependence: []string{component.InternalCategoryKubernetes},
+		Category:   component.InternalCategoryIngress,
+		Priority:   4,
+		Schema: &component.JSONSchemaProps{
+			Properties: propMap,
+			Required:   []string{"controller", "mode", "replicas"},
+			Type:       component.JSONSchemaTypeObject,
+			Default:    nil,
+		},
+	}
+}
+
+func (n *Ingress) NewInstance() component.ObjectMeta {
+	return &Ingress{
+		ManifestsDir: manifestsDir,
+		Controller:   ControllerNginx,
+		Mode:         ModeHostNetwork,
+		Replicas:     2,
+		IngressClass: ControllerNginx,
+	}
+}
+
+func (n *Ingress) GetDependence() []string {
+	return []string{component.InternalCategoryKubernetes}
+}
+
+func (n *Ingress) GetInstallSteps() []v1.Step {
+	return n.installSteps
+}
+
+func (n *Ingress) GetUninstallSteps() []v1.Step {
+	return n.uninstallSteps
+}
+
+func (n *Ingress) GetUpgradeSteps() []v1.Step {
+	return n.upgradeSteps
+}
+
+func (n *Ingress) renderTo(w io.Writer) error {
+	ctrl, ok := controllers[n.Controller]
+	if !ok {
+		return errUnsupportedController
+	}
+	_, err := tmplutil.New().RenderTo(w, ctrl.manifests, n)
+	return err
+}
+
+func (n *Ingress) Render(ctx context.Context, opts component.Options) error {
+	if err := os.MkdirAll(n.ManifestsDir, 0755); err != nil {
+		return err
+	}
+	return fileutil.WriteFileWithContext(ctx, filepath.Join(n.ManifestsDir, manifestsFile), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+		n.renderTo, opts.DryRun)
+}
+
+// ImageLoader loads the offline images of the ingress controller.
+type ImageLoader struct {
+	Name    string
+	Version string
+	CriType string
+	Offline bool
+}
+
+func (l *ImageLoader) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, l.Name, l.Version, runtime.GOARCH, !l.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	dstFile, err := instance.DownloadImages()
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.LoadImage(ctx, opts.DryRun, dstFile, l.CriType); err == nil {
+		logger.Info("ingress controller packages offline install successfully", zap.String("controller", l.Name))
+	}
+	return nil, err
+}
+
+func (l *ImageLoader) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, l.Name, l.Version, runtime.GOARCH, !l.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveImages(); err != nil {
+		logger.Error("remove ingress controller images compressed file failed", zap.Error(err))
+	}
+	return nil, nil
+}
+
+func (l *ImageLoader) NewInstance() component.ObjectMeta {
+	return &ImageLoader{}
+}
@@ -0,0 +1,160 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ingress
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(in *Ingress)
+		wantErr bool
+	}{
+		{name: "default", modify: func(in *Ingress) {}},
+		{name: "traefik node port", modify: func(in *Ingress) {
+			in.Controller, in.Mode, in.HTTPNodePort, in.HTTPSNodePort = ControllerTraefik, ModeNodePort, 30080, 30443
+		}},
+		{name: "unknown controller", modify: func(in *Ingress) { in.Controller = "haproxy" }, wantErr: true},
+		{name: "unknown mode", modify: func(in *Ingress) { in.Mode = "LoadBalancer" }, wantErr: true},
+		{name: "no replicas", modify: func(in *Ingress) { in.Replicas = 0 }, wantErr: true},
+		{name: "node port out of range", modify: func(in *Ingress) { in.Mode, in.HTTPNodePort = ModeNodePort, 8080 }, wantErr: true},
+		{name: "invalid ingress class", modify: func(in *Ingress) { in.IngressClass = "Nginx_Class" }, wantErr: true},
+		{name: "invalid node selector", modify: func(in *Ingress) { in.NodeSelector = map[string]string{"edge node": "true"} }, wantErr: true},
+		{name: "certificate without key", modify: func(in *Ingress) { in.DefaultCertificate = "cert" }, wantErr: true},
+		{name: "invalid certificate", modify: func(in *Ingress) { in.DefaultCertificate, in.DefaultCertificateKey = "cert", "key" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := (&Ingress{}).NewInstance().(*Ingress)
+			tt.modify(in)
+			if err := in.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInitSteps(t *testing.T) {
+	metadata := component.ExtraMetadata{
+		CRI:     "containerd",
+		Offline: true,
+		Masters: component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+		Workers: component.NodeList{{ID: "w1", IPv4: "192.168.10.11"}},
+	}
+	in := (&Ingress{}).NewInstance().(*Ingress)
+	if err := in.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+		t.Fatal(err)
+	}
+	install, uninstall := in.GetInstallSteps(), in.GetUninstallSteps()
+	if len(install) != 3 || len(uninstall) != 2 {
+		t.Fatalf("got %d install and %d uninstall steps", len(install), len(uninstall))
+	}
+	if len(install[0].Nodes) != 2 {
+		t.Errorf("images must be loaded on all nodes")
+	}
+	if in.Namespace != "ingress-nginx" {
+		t.Errorf("namespace = %s, want the default namespace of nginx", in.Namespace)
+	}
+
+	in = (&Ingress{}).NewInstance().(*Ingress)
+	in.Replicas = 3
+	if err := in.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err == nil {
+		t.Errorf("InitSteps() must fail when hostNetwork replicas exceed the nodes")
+	}
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(in *Ingress)
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "nginx host network",
+			modify: func(in *Ingress) {
+				in.Namespace, in.IsDefaultClass = "ingress-nginx", true
+				in.NodeSelector = map[string]string{"node-role.kubernetes.io/edge": ""}
+			},
+			want: []string{
+				"hostNetwork: true",
+				"requiredDuringSchedulingIgnoredDuringExecution",
+				"image: 192.168.10.20:5000/caas4/ingress-nginx-controller:v1.1.1",
+				`node-role.kubernetes.io/edge: ""`,
+				`ingressclass.kubernetes.io/is-default-class: "true"`,
+			},
+			notWant: []string{"type: NodePort", "--default-ssl-certificate"},
+		},
+		{
+			name: "nginx default certificate",
+			modify: func(in *Ingress) {
+				in.Namespace, in.DefaultCertificate, in.DefaultCertificateKey = "ingress-nginx", "cert", "key"
+			},
+			want: []string{
+				"--default-ssl-certificate=$(POD_NAMESPACE)/ingress-default-certificate",
+				"tls.crt: Y2VydA==",
+				"tls.key: a2V5",
+			},
+		},
+		{
+			name: "traefik node port",
+			modify: func(in *Ingress) {
+				in.Controller, in.Mode, in.Namespace, in.IngressClass = ControllerTraefik, ModeNodePort, "traefik", "traefik"
+				in.HTTPNodePort, in.DefaultCertificate, in.DefaultCertificateKey = 30080, "cert", "key"
+			},
+			want: []string{
+				"type: NodePort",
+				"nodePort: 30080",
+				"image: 192.168.10.20:5000/caas4/traefik:v2.6.1",
+				"--providers.kubernetesingress.ingressclass=traefik",
+				"--providers.file.directory=/etc/traefik/dynamic",
+				"secretName: ingress-default-certificate",
+			},
+			notWant: []string{"hostNetwork: true", "is-default-class"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := (&Ingress{}).NewInstance().(*Ingress)
+			in.ImageRepoMirror = "192.168.10.20:5000"
+			tt.modify(in)
+			var w bytes.Buffer
+			if err := in.renderTo(&w); err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(w.String(), s) {
+					t.Errorf("manifest does not contain %q", s)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(w.String(), s) {
+					t.Errorf("manifest must not contain %q", s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,569 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ingress
+
+const nginxTemplate = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ingress-nginx
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+automountServiceAccountToken: true
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ingress-nginx-controller
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+data:
+  allow-snippet-annotations: "true"
+{{- if .DefaultCertificate}}
+---
+apiVersion: v1
+kind: Secret
+type: kubernetes.io/tls
+metadata:
+  name: ingress-default-certificate
+  namespace: {{.Namespace}}
+data:
+  tls.crt: {{b64enc .DefaultCertificate}}
+  tls.key: {{b64enc .DefaultCertificateKey}}
+{{- end}}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+rules:
+  - apiGroups: [""]
+    resources: ["configmaps", "endpoints", "nodes", "pods", "secrets", "namespaces"]
+    verbs: ["list", "watch"]
+  - apiGroups: [""]
+    resources: ["nodes"]
+    verbs: ["get"]
+  - apiGroups: [""]
+    resources: ["services"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["ingresses", "ingressclasses"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["ingresses/status"]
+    verbs: ["update"]
+  - apiGroups: [""]
+    resources: ["events"]
+    verbs: ["create", "patch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: ingress-nginx
+subjects:
+  - kind: ServiceAccount
+    name: ingress-nginx
+    namespace: {{.Namespace}}
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: ingress-nginx
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+rules:
+  - apiGroups: [""]
+    resources: ["namespaces"]
+    verbs: ["get"]
+  - apiGroups: [""]
+    resources: ["configmaps", "pods", "secrets", "endpoints"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: [""]
+    resources: ["services"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["ingresses", "ingressclasses"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["networking.k8s.io"]
+    resources: ["ingresses/status"]
+    verbs: ["update"]
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    resourceNames: ["ingress-controller-leader"]
+    verbs: ["get", "update"]
+  - apiGroups: [""]
+    resources: ["configmaps"]
+    verbs: ["create"]
+  - apiGroups: [""]
+    resources: ["events"]
+    verbs: ["create", "patch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: ingress-nginx
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: ingress-nginx
+subjects:
+  - kind: ServiceAccount
+    name: ingress-nginx
+    namespace: {{.Namespace}}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: ingress-nginx-controller
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+spec:
+{{- if eq .Mode "NodePort"}}
+  type: NodePort
+  externalTrafficPolicy: Local
+{{- else}}
+  type: ClusterIP
+{{- end}}
+  ports:
+    - name: http
+      port: 80
+      protocol: TCP
+      targetPort: http
+{{- if and (eq .Mode "NodePort") .HTTPNodePort}}
+      nodePort: {{.HTTPNodePort}}
+{{- end}}
+    - name: https
+      port: 443
+      protocol: TCP
+      targetPort: https
+{{- if and (eq .Mode "NodePort") .HTTPSNodePort}}
+      nodePort: {{.HTTPSNodePort}}
+{{- end}}
+  selector:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: ingress-nginx-controller
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+spec:
+  replicas: {{.Replicas}}
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: ingress-nginx
+      app.kubernetes.io/component: controller
+  revisionHistoryLimit: 10
+  minReadySeconds: 0
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: ingress-nginx
+        app.kubernetes.io/component: controller
+    spec:
+{{- if eq .Mode "hostNetwork"}}
+      hostNetwork: true
+      dnsPolicy: ClusterFirstWithHostNet
+{{- else}}
+      dnsPolicy: ClusterFirst
+{{- end}}
+      affinity:
+        podAntiAffinity:
+{{- if eq .Mode "hostNetwork"}}
+          requiredDuringSchedulingIgnoredDuringExecution:
+            - labelSelector:
+                matchLabels:
+                  app.kubernetes.io/name: ingress-nginx
+                  app.kubernetes.io/component: controller
+              topologyKey: kubernetes.io/hostname
+{{- else}}
+          preferredDuringSchedulingIgnoredDuringExecution:
+            - weight: 100
+              podAffinityTerm:
+                labelSelector:
+                  matchLabels:
+                    app.kubernetes.io/name: ingress-nginx
+                    app.kubernetes.io/component: controller
+                topologyKey: kubernetes.io/hostname
+{{- end}}
+      containers:
+        - name: controller
+          image: {{with .ImageRepoMirror}}{{.}}/{{end}}caas4/ingress-nginx-controller:v1.1.1
+          imagePullPolicy: IfNotPresent
+          lifecycle:
+            preStop:
+              exec:
+                command:
+                  - /wait-shutdown
+          args:
+            - /nginx-ingress-controller
+            - --election-id=ingress-controller-leader
+            - --controller-class=k8s.io/ingress-nginx
+            - --ingress-class={{.IngressClass}}
+            - --configmap=$(POD_NAMESPACE)/ingress-nginx-controller
+            - --publish-service=$(POD_NAMESPACE)/ingress-nginx-controller
+{{- if .DefaultCertificate}}
+            - --default-ssl-certificate=$(POD_NAMESPACE)/ingress-default-certificate
+{{- end}}
+          securityContext:
+            capabilities:
+              drop:
+                - ALL
+              add:
+                - NET_BIND_SERVICE
+            runAsUser: 101
+            allowPrivilegeEscalation: true
+          env:
+            - name: POD_NAME
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.name
+            - name: POD_NAMESPACE
+              valueFrom:
+                fieldRef:
+                  fieldPath: metadata.namespace
+            - name: LD_PRELOAD
+              value: /usr/local/lib/libmimalloc.so
+          livenessProbe:
+            failureThreshold: 5
+            httpGet:
+              path: /healthz
+              port: 10254
+              scheme: HTTP
+            initialDelaySeconds: 10
+            periodSeconds: 10
+            successThreshold: 1
+            timeoutSeconds: 1
+          readinessProbe:
+            failureThreshold: 3
+            httpGet:
+              path: /healthz
+              port: 10254
+              scheme: HTTP
+            initialDelaySeconds: 10
+            periodSeconds: 10
+            successThreshold: 1
+            timeoutSeconds: 1
+          ports:
+            - name: http
+              containerPort: 80
+              protocol: TCP
+            - name: https
+              containerPort: 443
+              protocol: TCP
+          resources:
+            requests:
+              cpu: 100m
+              memory: 90Mi
+      nodeSelector:
+        kubernetes.io/os: linux
+{{- range $k, $v := .NodeSelector}}
+        {{$k}}: "{{$v}}"
+{{- end}}
+      tolerations:
+        - key: node-role.kubernetes.io/master
+          operator: Exists
+          effect: NoSchedule
+      serviceAccountName: ingress-nginx
+      terminationGracePeriodSeconds: 300
+---
+apiVersion: networking.k8s.io/v1
+kind: IngressClass
+metadata:
+  name: {{.IngressClass}}
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+{{- if .IsDefaultClass}}
+  annotations:
+    ingressclass.kubernetes.io/is-default-class: "true"
+{{- end}}
+spec:
+  controller: k8s.io/ingress-nginx
+`
+
+const traefikTemplate = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: traefik
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: traefik
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: traefik
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: traefik
+  labels:
+    app.kubernetes.io/name: traefik
+rules:
+  - apiGroups: [""]
+    resources: ["services", "endpoints", "secrets"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["extensions", "networking.k8s.io"]
+    resources: ["ingresses", "ingressclasses"]
+    verbs: ["get", "list", "watch"]
+  - apiGroups: ["extensions", "networking.k8s.io"]
+    resources: ["ingresses/status"]
+    verbs: ["update"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: traefik
+  labels:
+    app.kubernetes.io/name: traefik
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: traefik
+subjects:
+  - kind: ServiceAccount
+    name: traefik
+    namespace: {{.Namespace}}
+{{- if .DefaultCertificate}}
+---
+apiVersion: v1
+kind: Secret
+type: kubernetes.io/tls
+metadata:
+  name: ingress-default-certificate
+  namespace: {{.Namespace}}
+data:
+  tls.crt: {{b64enc .DefaultCertificate}}
+  tls.key: {{b64enc .DefaultCertificateKey}}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: traefik-default-certificate
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: traefik
+data:
+  tls.yaml: |
+    tls:
+      stores:
+        default:
+          defaultCertificate:
+            certFile: /etc/traefik/certs/tls.crt
+            keyFile: /etc/traefik/certs/tls.key
+{{- end}}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: traefik
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: traefik
+spec:
+{{- if eq .Mode "NodePort"}}
+  type: NodePort
+  externalTrafficPolicy: Local
+{{- else}}
+  type: ClusterIP
+{{- end}}
+  ports:
+    - name: web
+      port: 80
+      protocol: TCP
+      targetPort: web
+{{- if and (eq .Mode "NodePort") .HTTPNodePort}}
+      nodePort: {{.HTTPNodePort}}
+{{- end}}
+    - name: websecure
+      port: 443
+      protocol: TCP
+      targetPort: websecure
+{{- if and (eq .Mode "NodePort") .HTTPSNodePort}}
+      nodePort: {{.HTTPSNodePort}}
+{{- end}}
+  selector:
+    app.kubernetes.io/name: traefik
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: traefik
+  namespace: {{.Namespace}}
+  labels:
+    app.kubernetes.io/name: traefik
+spec:
+  replicas: {{.Replicas}}
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: traefik
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: traefik
+    spec:
+{{- if eq .Mode "hostNetwork"}}
+      hostNetwork: true
+      dnsPolicy: ClusterFirstWithHostNet
+{{- end}}
+      affinity:
+        podAntiAffinity:
+{{- if eq .Mode "hostNetwork"}}
+          requiredDuringSchedulingIgnoredDuringExecution:
+            - labelSelector:
+                matchLabels:
+                  app.kubernetes.io/name: traefik
+              topologyKey: kubernetes.io/hostname
+{{- else}}
+          preferredDuringSchedulingIgnoredDuringExecution:
+            - weight: 100
+              podAffinityTerm:
+                labelSelector:
+                  matchLabels:
+                    app.kubernetes.io/name: traefik
+                topologyKey: kubernetes.io/hostname
+{{- end}}
+      serviceAccountName: traefik
+      terminationGracePeriodSeconds: 60
+      containers:
+        - name: traefik
+          image: {{with .ImageRepoMirror}}{{.}}/{{end}}caas4/traefik:v2.6.1
+          imagePullPolicy: IfNotPresent
+          args:
+            - --entrypoints.web.address=:80/tcp
+            - --entrypoints.websecure.address=:443/tcp
+            - --entrypoints.websecure.http.tls=true
+            - --entrypoints.traefik.address=:9000/tcp
+            - --api.dashboard=true
+            - --ping=true
+            - --providers.kubernetesingress
+            - --providers.kubernetesingress.ingressclass={{.IngressClass}}
+            - --providers.kubernetesingress.ingressendpoint.publishedservice={{.Namespace}}/traefik
+{{- if .DefaultCertificate}}
+            - --providers.file.directory=/etc/traefik/dynamic
+{{- end}}
+          ports:
+            - name: web
+              containerPort: 80
+              protocol: TCP
+            - name: websecure
+              containerPort: 443
+              protocol: TCP
+            - name: traefik
+              containerPort: 9000
+              protocol: TCP
+          securityContext:
+            capabilities:
+              drop:
+                - ALL
+              add:
+                - NET_BIND_SERVICE
+          readinessProbe:
+            httpGet:
+              path: /ping
+              port: 9000
+            failureThreshold: 1
+            initialDelaySeconds: 10
+            periodSeconds: 10
+            successThreshold: 1
+            timeoutSeconds: 2
+          livenessProbe:
+            httpGet:
+              path: /ping
+              port: 9000
+            failureThreshold: 3
+            initialDelaySeconds: 10
+            periodSeconds: 10
+            successThreshold: 1
+            timeoutSeconds: 2
+{{- if .DefaultCertificate}}
+          volumeMounts:
+            - name: dynamic
+              mountPath: /etc/traefik/dynamic
+              readOnly: true
+            - name: certs
+              mountPath: /etc/traefik/certs
+              readOnly: true
+      volumes:
+        - name: dynamic
+          configMap:
+            name: traefik-default-certificate
+        - name: certs
+          secret:
+            secretName: ingress-default-certificate
+{{- end}}
+      nodeSelector:
+        kubernetes.io/os: linux
+{{- range $k, $v := .NodeSelector}}
+        {{$k}}: "{{$v}}"
+{{- end}}
+      tolerations:
+        - key: node-role.kubernetes.io/master
+          operator: Exists
+          effect: NoSchedule
+---
+apiVersion: networking.k8s.io/v1
+kind: IngressClass
+metadata:
+  name: {{.IngressClass}}
+  labels:
+    app.kubernetes.io/name: traefik
+{{- if .IsDefaultClass}}
+  annotations:
+    ingressclass.kubernetes.io/is-default-class: "true"
+{{- end}}
+spec:
+  controller: traefik.io/ingress-controller
+`
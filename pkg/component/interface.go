@@ -41,6 +41,9 @@ const (
 	InternalCategoryNodes      = "nodes"
 	InternalCategoryKubernetes = "kubernetes"
 	InternalCategoryStorage    = "storage"
+	InternalCategoryIngress    = "ingress"
+	InternalCategoryMonitoring = "monitoring"
+	InternalCategoryLogging    = "logging"
 	InternalCategoryPAAS       = "PAAS"
 )
 
@@ -126,6 +129,13 @@ type Interface interface {
 	GetUpgradeSteps() []v1.Step
 }
 
+// BackupPointConsumer is implemented by components storing their data in a backup point,
+// the server resolves the required backup point before the component steps are initialized.
+type BackupPointConsumer interface {
+	RequireBackupPoint() string
+	CompleteWithBackupPoint(bp *v1.BackupPoint) error
+}
+
 // OfflinePackages key must format as version-osVendor-osArch
 // value is packages
 // eg for docker 19.03, docker-19.03-centos7-x86_64
@@ -0,0 +1,36 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package localpath
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "localpath.metaTitle",
+			English: "Local Path Setting",
+			Chinese: "本地路径存储设置",
+		},
+		{
+			ID:      "localpath.hostPath",
+			English: "HostPath",
+			Chinese: "节点存储路径",
+		},
+	})
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package localpath
+
+import (
+	"errors"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/csi"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+)
+
+func init() {
+	if err := csi.Register(&LocalPath{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var _ csi.Provider = (*LocalPath)(nil)
+
+const (
+	name      = "local-path"
+	version   = "v1"
+	namespace = "local-path-storage"
+	scName    = "local-path"
+	hostPath  = "/opt/local-path-provisioner"
+)
+
+var errInvalidHostPath = errors.New("invalid local path of the volumes")
+
+var spec = csi.Spec{
+	Name:             name,
+	Version:          version,
+	Template:         component.TypeTemplate,
+	TitleID:          "localpath.metaTitle",
+	Priority:         5,
+	Unique:           true,
+	Namespace:        namespace,
+	StorageClassName: scName,
+	Required:         []string{"hostPath"},
+	Image:            "local-path-provisioner",
+	ImageVersion:     "v0.0.22",
+	NodePlugin:       true,
+}
+
+// LocalPath deploys the local-path-provisioner, volumes are directories on the node the pod is scheduled to.
+type LocalPath struct {
+	csi.StorageClass
+	HostPath string `json:"hostPath"` // required
+}
+
+func (l *LocalPath) Spec() csi.Spec {
+	return spec
+}
+
+func (l *LocalPath) Validate() error {
+	if !validation.MatchLinuxFilePath(l.HostPath) {
+		return errInvalidHostPath
+	}
+	return nil
+}
+
+func (l *LocalPath) Manifests() string {
+	return manifestsTemplate
+}
+
+func (l *LocalPath) Properties(loc *i18n.Localizer) map[string]component.JSONSchemaProps {
+	return map[string]component.JSONSchemaProps{
+		"hostPath": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "localpath.hostPath"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON(hostPath),
+			Description:  "directory of the nodes the volumes are created in",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+		},
+	}
+}
+
+func (l *LocalPath) NewInstance() component.ObjectMeta {
+	return &LocalPath{
+		StorageClass: csi.NewStorageClass(spec),
+		HostPath:     hostPath,
+	}
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package localpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/csi"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr error
+	}{
+		{
+			name:   "default",
+			config: `{}`,
+		},
+		{
+			name:    "relative host path",
+			config:  `{"hostPath":"relative/path"}`,
+			wantErr: errInvalidHostPath,
+		},
+		{
+			name:    "mount options",
+			config:  `{"mountOptions":["ro"]}`,
+			wantErr: csi.ErrMountOptionsUnsupported,
+		},
+	}
+	itf, ok := component.Load(fmt.Sprintf(component.RegisterFormat, name, version))
+	if !ok {
+		t.Fatal("local-path component is not registered")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := itf.NewInstance().(*csi.Component)
+			if err := json.Unmarshal([]byte(tt.config), c); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Validate(); err != tt.wantErr {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
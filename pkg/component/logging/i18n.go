@@ -0,0 +1,51 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logging
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "logging.metaTitle",
+			English: "Logging Setting",
+			Chinese: "日志设置",
+		},
+		{
+			ID:      "logging.retentionDays",
+			English: "Retention Days",
+			Chinese: "日志保留天数",
+		},
+		{
+			ID:      "logging.storageClass",
+			English: "StorageClass",
+			Chinese: "存储类",
+		},
+		{
+			ID:      "logging.storageSize",
+			English: "Storage Size",
+			Chinese: "存储大小",
+		},
+		{
+			ID:      "logging.imageRepoMirror",
+			English: "Logging Image Repository Mirror",
+			Chinese: "日志镜像仓库代理",
+		},
+	})
+}
@@ -0,0 +1,246 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/helm"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+func init() {
+	if err := component.Register(fmt.Sprintf(component.RegisterFormat, name, version), &Logging{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.Interface = (*Logging)(nil)
+
+const (
+	name         = "logging"
+	version      = "v1"
+	manifestsDir = "/tmp/.logging"
+
+	chart        = "loki-stack"
+	chartVersion = "2.9.10"
+	releaseName  = "loki-stack"
+
+	// dockerContainersDir holds the log files docker links the pod logs to.
+	dockerContainersDir = "/var/lib/docker/containers"
+)
+
+var (
+	errInvalidRetention   = errors.New("retention days must be at least 1")
+	errInvalidStorageSize = errors.New("invalid storage size, e.g. 20Gi")
+)
+
+// Logging deploys loki and promtail to the cluster through the helm component. Promtail runs on
+// every node and ships the pod logs to loki, labeled with the cluster name.
+type Logging struct {
+	ImageRepoMirror string `json:"imageRepoMirror"` // optional
+	Namespace       string `json:"namespace"`       // optional
+	ManifestsDir    string `json:"manifestsDir"`    // optional
+	RetentionDays   int    `json:"retentionDays"`
+	// StorageClass keeps the logs in a persistent volume of StorageSize, emptyDir is used when empty.
+	StorageClass                               string `json:"storageClass"` // optional
+	StorageSize                                string `json:"storageSize"`  // optional
+	installSteps, uninstallSteps, upgradeSteps []v1.Step
+}
+
+// values are the variables of valuesTemplate.
+type values struct {
+	*Logging
+	ClusterName      string
+	ContainerLogsDir string
+}
+
+func (l *Logging) Ns() string {
+	return l.Namespace
+}
+
+func (l *Logging) Svc() string {
+	return "loki:http-metrics"
+}
+
+func (l *Logging) RequestPath() string {
+	return "ready"
+}
+
+func (l *Logging) Supported() bool {
+	return true
+}
+
+func (l *Logging) GetInstanceName() string {
+	return name
+}
+
+func (l *Logging) RequireExtraCluster() []string {
+	return nil
+}
+
+func (l *Logging) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
+	return nil
+}
+
+func (l *Logging) Validate() error {
+	if !validation.MatchKubernetesNamespace(l.Namespace) {
+		return validation.ErrInvalidNamespace
+	}
+	if l.RetentionDays < 1 {
+		return errInvalidRetention
+	}
+	if l.StorageClass != "" {
+		if !validation.MatchKubernetesStorageClass(l.StorageClass) {
+			return validation.ErrInvalidSCName
+		}
+		if _, err := resource.ParseQuantity(l.StorageSize); err != nil {
+			return errInvalidStorageSize
+		}
+	}
+	return nil
+}
+
+func (l *Logging) values(metadata *component.ExtraMetadata) (string, error) {
+	v := values{Logging: l, ClusterName: metadata.ClusterName}
+	// promtail of loki-stack mounts the default docker directory, only other data roots need a mount
+	if metadata.CRI == "docker" && metadata.CRIDataDir != "" && filepath.Join(metadata.CRIDataDir, "containers") != dockerContainersDir {
+		v.ContainerLogsDir = filepath.Join(metadata.CRIDataDir, "containers")
+	}
+	return tmplutil.New().Render(valuesTemplate, v)
+}
+
+func (l *Logging) InitSteps(ctx context.Context) error {
+	metadata := component.GetExtraMetadata(ctx)
+	if l.ImageRepoMirror == "" {
+		l.ImageRepoMirror = metadata.LocalRegistry
+	}
+	vals, err := l.values(&metadata)
+	if err != nil {
+		return err
+	}
+	// the chart package of the static server carries the images
+	r := &helm.Helm{
+		ReleaseName:  releaseName,
+		Namespace:    l.Namespace,
+		Chart:        chart,
+		ChartVersion: chartVersion,
+		Values:       vals,
+		ChartImages:  l.ImageRepoMirror == "",
+		ManifestsDir: l.ManifestsDir,
+	}
+	if err = r.InitSteps(ctx); err != nil {
+		return err
+	}
+	l.installSteps, l.uninstallSteps = r.GetInstallSteps(), r.GetUninstallSteps()
+	return nil
+}
+
+func (l *Logging) GetComponentMeta(lang component.Lang) component.Meta {
+	loc := component.GetLocalizer(lang)
+
+	propMap := map[string]component.JSONSchemaProps{
+		"retentionDays": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "logging.retentionDays"}),
+			Type:         component.JSONSchemaTypeInt,
+			Default:      component.JSON(7),
+			Description:  "days the logs are kept",
+			Priority:     1,
+			Dependencies: []string{"enabled"},
+			Props:        &component.Props{Min: 1},
+		},
+		"storageClass": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "logging.storageClass"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "storage class of the logs, emptyDir is used when empty",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+		},
+		"storageSize": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "logging.storageSize"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("20Gi"),
+			Description:  "size of the logs volume",
+			Priority:     3,
+			Dependencies: []string{"enabled"},
+		},
+		"imageRepoMirror": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "logging.imageRepoMirror"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "logging image repository mirror, the component official repository is used by default",
+			Priority:     4,
+			Dependencies: []string{"enabled"},
+		},
+	}
+
+	return component.Meta{
+		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "logging.metaTitle"}),
+		Name:       name,
+		Version:    version,
+		Unique:     true,
+		Template:   false,
+		Dependence: []string{component.InternalCategoryKubernetes},
+		Category:   component.InternalCategoryLogging,
+		Priority:   6,
+		Schema: &component.JSONSchemaProps{
+			Properties: propMap,
+			Required:   []string{"retentionDays"},
+			Type:       component.JSONSchemaTypeObject,
+			Default:    nil,
+		},
+	}
+}
+
+func (l *Logging) NewInstance() component.ObjectMeta {
+	return &Logging{
+		Namespace:     "logging",
+		ManifestsDir:  manifestsDir,
+		RetentionDays: 7,
+		StorageSize:   "20Gi",
+	}
+}
+
+func (l *Logging) GetDependence() []string {
+	return []string{component.InternalCategoryKubernetes}
+}
+
+func (l *Logging) GetInstallSteps() []v1.Step {
+	return l.installSteps
+}
+
+func (l *Logging) GetUninstallSteps() []v1.Step {
+	return l.uninstallSteps
+}
+
+// GetUpgradeSteps is empty, the release is upgraded by the install steps which run helm upgrade --install.
+func (l *Logging) GetUpgradeSteps() []v1.Step {
+	return l.upgradeSteps
+}
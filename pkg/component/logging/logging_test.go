@@ -0,0 +1,98 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logging
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(l *Logging)
+		wantErr bool
+	}{
+		{name: "default", modify: func(l *Logging) {}},
+		{name: "persistent", modify: func(l *Logging) { l.StorageClass = "nfs-sc" }},
+		{name: "no retention", modify: func(l *Logging) { l.RetentionDays = 0 }, wantErr: true},
+		{name: "invalid storage size", modify: func(l *Logging) { l.StorageClass, l.StorageSize = "nfs-sc", "twenty" }, wantErr: true},
+		{name: "invalid namespace", modify: func(l *Logging) { l.Namespace = "Logging" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := (&Logging{}).NewInstance().(*Logging)
+			tt.modify(l)
+			if err := l.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	metadata := component.ExtraMetadata{ClusterName: "demo", CRI: "docker", CRIDataDir: "/data/docker"}
+	l := (&Logging{}).NewInstance().(*Logging)
+	l.ImageRepoMirror, l.StorageClass, l.RetentionDays = "192.168.10.20:5000", "nfs-sc", 14
+	out, err := l.values(&metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var v map[string]interface{}
+	if err = yaml.Unmarshal([]byte(out), &v); err != nil {
+		t.Fatalf("values are not valid yaml: %v\n%s", err, out)
+	}
+	for _, s := range []string{
+		"repository: 192.168.10.20:5000/grafana/loki",
+		"storageClassName: nfs-sc",
+		"retention_period: 336h",
+		"-client.external-labels=cluster=demo",
+		"path: /data/docker/containers",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("values do not contain %q", s)
+		}
+	}
+
+	metadata.CRIDataDir = ""
+	if out, _ = l.values(&metadata); strings.Contains(out, "extraVolumes") {
+		t.Errorf("default docker data root must not be mounted again")
+	}
+}
+
+func TestInitSteps(t *testing.T) {
+	metadata := component.ExtraMetadata{
+		CRI:           "containerd",
+		LocalRegistry: "192.168.10.20:5000",
+		Masters:       component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+	}
+	l := (&Logging{}).NewInstance().(*Logging)
+	if err := l.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+		t.Fatal(err)
+	}
+	// helm installer, values and release, images are pulled from the local registry
+	if install, uninstall := l.GetInstallSteps(), l.GetUninstallSteps(); len(install) != 3 || len(uninstall) != 2 {
+		t.Fatalf("got %d install and %d uninstall steps", len(install), len(uninstall))
+	}
+}
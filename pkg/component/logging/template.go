@@ -0,0 +1,71 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package logging
+
+// valuesTemplate overrides the default values of loki-stack. Loki keeps the logs in boltdb-shipper
+// indexes of 24h periods, so the retention is a multiple of days.
+const valuesTemplate = `
+loki:
+  enabled: true
+  fullnameOverride: loki
+{{- with .ImageRepoMirror}}
+  image:
+    repository: {{.}}/grafana/loki
+{{- end}}
+{{- if .StorageClass}}
+  persistence:
+    enabled: true
+    storageClassName: {{.StorageClass}}
+    accessModes: ["ReadWriteOnce"]
+    size: {{.StorageSize}}
+{{- end}}
+  config:
+    compactor:
+      retention_enabled: true
+    limits_config:
+      retention_period: {{mul .RetentionDays 24}}h
+    table_manager:
+      retention_deletes_enabled: true
+      retention_period: {{mul .RetentionDays 24}}h
+promtail:
+  enabled: true
+{{- with .ImageRepoMirror}}
+  image:
+    registry: {{.}}
+    repository: grafana/promtail
+{{- end}}
+  extraArgs:
+    - -client.external-labels=cluster={{.ClusterName}}
+{{- if .ContainerLogsDir}}
+  extraVolumes:
+    - name: containerlogs
+      hostPath:
+        path: {{.ContainerLogsDir}}
+  extraVolumeMounts:
+    - name: containerlogs
+      mountPath: {{.ContainerLogsDir}}
+      readOnly: true
+{{- end}}
+grafana:
+  enabled: false
+prometheus:
+  enabled: false
+fluent-bit:
+  enabled: false
+`
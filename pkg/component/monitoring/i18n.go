@@ -0,0 +1,91 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package monitoring
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "monitoring.metaTitle",
+			English: "Monitoring Setting",
+			Chinese: "监控设置",
+		},
+		{
+			ID:      "monitoring.retention",
+			English: "Retention",
+			Chinese: "数据保留时长",
+		},
+		{
+			ID:      "monitoring.scrapeInterval",
+			English: "Scrape Interval",
+			Chinese: "采集间隔",
+		},
+		{
+			ID:      "monitoring.storageClass",
+			English: "StorageClass",
+			Chinese: "存储类",
+		},
+		{
+			ID:      "monitoring.storageSize",
+			English: "Storage Size",
+			Chinese: "存储大小",
+		},
+		{
+			ID:      "monitoring.alertmanager",
+			English: "Alertmanager",
+			Chinese: "告警管理",
+		},
+		{
+			ID:      "monitoring.grafana",
+			English: "Grafana",
+			Chinese: "Grafana",
+		},
+		{
+			ID:      "monitoring.grafanaAdminPassword",
+			English: "Grafana Admin Password",
+			Chinese: "Grafana管理员密码",
+		},
+		{
+			ID:      "monitoring.remoteWriteURL",
+			English: "Remote Write URL",
+			Chinese: "远程写入地址",
+		},
+		{
+			ID:      "monitoring.remoteWriteUsername",
+			English: "Remote Write Username",
+			Chinese: "远程写入用户名",
+		},
+		{
+			ID:      "monitoring.remoteWritePassword",
+			English: "Remote Write Password",
+			Chinese: "远程写入密码",
+		},
+		{
+			ID:      "monitoring.agentMetricsPort",
+			English: "Agent Metrics Port",
+			Chinese: "Agent指标端口",
+		},
+		{
+			ID:      "monitoring.imageRepoMirror",
+			English: "Monitoring Image Repository Mirror",
+			Chinese: "监控镜像仓库代理",
+		},
+	})
+}
@@ -0,0 +1,351 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package monitoring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/helm"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+func init() {
+	if err := component.Register(fmt.Sprintf(component.RegisterFormat, name, version), &Monitoring{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.Interface = (*Monitoring)(nil)
+
+const (
+	name         = "monitoring"
+	version      = "v1"
+	manifestsDir = "/tmp/.monitoring"
+
+	chart        = "kube-prometheus-stack"
+	chartVersion = "45.7.1"
+	releaseName  = "kube-prometheus-stack"
+)
+
+var (
+	durationRegexp = regexp.MustCompile(`^[0-9]+(ms|s|m|h|d|w|y)$`)
+
+	errInvalidRetention      = errors.New("invalid retention, e.g. 15d")
+	errInvalidScrapeInterval = errors.New("invalid scrape interval, e.g. 30s")
+	errInvalidStorageSize    = errors.New("invalid storage size, e.g. 50Gi")
+	errInvalidRemoteWrite    = errors.New("remote write url must be a http or https url")
+	errEmptyGrafanaPassword  = errors.New("grafana admin password must be provided")
+	errIncompleteCredentials = errors.New("remote write username and password must be provided together")
+)
+
+// Monitoring deploys kube-prometheus-stack to the cluster through the helm component, scraping the
+// control plane on the masters and optionally writing the metrics to a central prometheus.
+type Monitoring struct {
+	ImageRepoMirror string `json:"imageRepoMirror"` // optional
+	Namespace       string `json:"namespace"`       // optional
+	ManifestsDir    string `json:"manifestsDir"`    // optional
+	Retention       string `json:"retention"`
+	ScrapeInterval  string `json:"scrapeInterval"`
+	// StorageClass keeps the prometheus data in persistent volumes of StorageSize, emptyDir is used when empty.
+	StorageClass         string `json:"storageClass"` // optional
+	StorageSize          string `json:"storageSize"`  // optional
+	Alertmanager         bool   `json:"alertmanager"`
+	Grafana              bool   `json:"grafana"`
+	GrafanaAdminPassword string `json:"grafanaAdminPassword"` // required if grafana is enabled
+	// RemoteWriteURL is the remote write endpoint of the central prometheus, e.g. http://prometheus:9090/api/v1/write.
+	RemoteWriteURL      string `json:"remoteWriteURL"`      // optional
+	RemoteWriteUsername string `json:"remoteWriteUsername"` // optional
+	RemoteWritePassword string `json:"remoteWritePassword"` // optional
+	// AgentMetricsPort is the metrics port of kubeclipper agents, they are scraped when it is set.
+	AgentMetricsPort                           int `json:"agentMetricsPort"` // optional
+	installSteps, uninstallSteps, upgradeSteps []v1.Step
+}
+
+// values are the variables of valuesTemplate.
+type values struct {
+	*Monitoring
+	ClusterName string
+	MasterIPs   []string
+	NodeIPs     []string
+}
+
+func (m *Monitoring) Ns() string {
+	return m.Namespace
+}
+
+func (m *Monitoring) Svc() string {
+	return releaseName + "-prometheus:http-web"
+}
+
+func (m *Monitoring) RequestPath() string {
+	return "-/healthy"
+}
+
+func (m *Monitoring) Supported() bool {
+	return true
+}
+
+func (m *Monitoring) GetInstanceName() string {
+	return name
+}
+
+func (m *Monitoring) RequireExtraCluster() []string {
+	return nil
+}
+
+func (m *Monitoring) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
+	return nil
+}
+
+func (m *Monitoring) Validate() error {
+	if !validation.MatchKubernetesNamespace(m.Namespace) {
+		return validation.ErrInvalidNamespace
+	}
+	if !durationRegexp.MatchString(m.Retention) {
+		return errInvalidRetention
+	}
+	if !durationRegexp.MatchString(m.ScrapeInterval) {
+		return errInvalidScrapeInterval
+	}
+	if m.StorageClass != "" {
+		if !validation.MatchKubernetesStorageClass(m.StorageClass) {
+			return validation.ErrInvalidSCName
+		}
+		if _, err := resource.ParseQuantity(m.StorageSize); err != nil {
+			return errInvalidStorageSize
+		}
+	}
+	if m.Grafana && m.GrafanaAdminPassword == "" {
+		return errEmptyGrafanaPassword
+	}
+	if m.RemoteWriteURL != "" {
+		u, err := url.Parse(m.RemoteWriteURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errInvalidRemoteWrite
+		}
+	}
+	if (m.RemoteWriteUsername == "") != (m.RemoteWritePassword == "") {
+		return errIncompleteCredentials
+	}
+	if m.AgentMetricsPort < 0 || m.AgentMetricsPort > 65535 {
+		return fmt.Errorf("invalid agent metrics port %d", m.AgentMetricsPort)
+	}
+	return nil
+}
+
+func (m *Monitoring) values(metadata *component.ExtraMetadata) (string, error) {
+	v := values{Monitoring: m, ClusterName: metadata.ClusterName}
+	for _, node := range metadata.Masters {
+		v.MasterIPs = append(v.MasterIPs, node.IPv4)
+	}
+	for _, node := range metadata.GetAllNodes() {
+		v.NodeIPs = append(v.NodeIPs, node.IPv4)
+	}
+	return tmplutil.New().Render(valuesTemplate, v)
+}
+
+// release is the helm release of kube-prometheus-stack, its chart package of the static server carries the images.
+func (m *Monitoring) release(metadata *component.ExtraMetadata) (*helm.Helm, error) {
+	vals, err := m.values(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return &helm.Helm{
+		ReleaseName:  releaseName,
+		Namespace:    m.Namespace,
+		Chart:        chart,
+		ChartVersion: chartVersion,
+		Values:       vals,
+		ChartImages:  m.ImageRepoMirror == "",
+		ManifestsDir: m.ManifestsDir,
+	}, nil
+}
+
+func (m *Monitoring) InitSteps(ctx context.Context) error {
+	metadata := component.GetExtraMetadata(ctx)
+	if m.ImageRepoMirror == "" {
+		m.ImageRepoMirror = metadata.LocalRegistry
+	}
+	r, err := m.release(&metadata)
+	if err != nil {
+		return err
+	}
+	if err = r.InitSteps(ctx); err != nil {
+		return err
+	}
+	m.installSteps, m.uninstallSteps = r.GetInstallSteps(), r.GetUninstallSteps()
+	return nil
+}
+
+func (m *Monitoring) GetComponentMeta(lang component.Lang) component.Meta {
+	loc := component.GetLocalizer(lang)
+	t, f := component.JSON(true), component.JSON(false)
+
+	propMap := map[string]component.JSONSchemaProps{
+		"retention": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.retention"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("15d"),
+			Description:  "how long the metrics are kept",
+			Priority:     1,
+			Dependencies: []string{"enabled"},
+		},
+		"scrapeInterval": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.scrapeInterval"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("30s"),
+			Description:  "interval the targets are scraped",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+		},
+		"storageClass": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.storageClass"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "storage class of the prometheus data, emptyDir is used when empty",
+			Priority:     3,
+			Dependencies: []string{"enabled"},
+		},
+		"storageSize": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.storageSize"}),
+			Type:         component.JSONSchemaTypeString,
+			Default:      component.JSON("50Gi"),
+			Description:  "size of the prometheus data volume",
+			Priority:     4,
+			Dependencies: []string{"enabled"},
+		},
+		"alertmanager": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.alertmanager"}),
+			Type:         component.JSONSchemaTypeBool,
+			Default:      &t,
+			Description:  "deploy alertmanager",
+			Priority:     5,
+			Dependencies: []string{"enabled"},
+		},
+		"grafana": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.grafana"}),
+			Type:         component.JSONSchemaTypeBool,
+			Default:      &f,
+			Description:  "deploy grafana with the default dashboards",
+			Priority:     6,
+			Dependencies: []string{"enabled"},
+		},
+		"grafanaAdminPassword": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.grafanaAdminPassword"}),
+			Type:         component.JSONSchemaTypeString,
+			Mask:         true,
+			Description:  "password of the grafana admin user",
+			Priority:     7,
+			Dependencies: []string{"grafana"},
+		},
+		"remoteWriteURL": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.remoteWriteURL"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "remote write endpoint of the central prometheus",
+			Priority:     8,
+			Dependencies: []string{"enabled"},
+		},
+		"remoteWriteUsername": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.remoteWriteUsername"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "basic auth username of the remote write endpoint",
+			Priority:     9,
+			Dependencies: []string{"remoteWriteURL"},
+		},
+		"remoteWritePassword": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.remoteWritePassword"}),
+			Type:         component.JSONSchemaTypeString,
+			Mask:         true,
+			Description:  "basic auth password of the remote write endpoint",
+			Priority:     10,
+			Dependencies: []string{"remoteWriteURL"},
+		},
+		"agentMetricsPort": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.agentMetricsPort"}),
+			Type:         component.JSONSchemaTypeInt,
+			Description:  "metrics port of kubeclipper agents, they are not scraped when empty",
+			Priority:     11,
+			Dependencies: []string{"enabled"},
+		},
+		"imageRepoMirror": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.imageRepoMirror"}),
+			Type:         component.JSONSchemaTypeString,
+			Description:  "monitoring image repository mirror, the component official repository is used by default",
+			Priority:     12,
+			Dependencies: []string{"enabled"},
+		},
+	}
+
+	return component.Meta{
+		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "monitoring.metaTitle"}),
+		Name:       name,
+		Version:    version,
+		Unique:     true,
+		Template:   false,
+		Dependence: []string{component.InternalCategoryKubernetes},
+		Category:   component.InternalCategoryMonitoring,
+		Priority:   5,
+		Schema: &component.JSONSchemaProps{
+			Properties: propMap,
+			Required:   []string{"retention", "scrapeInterval"},
+			Type:       component.JSONSchemaTypeObject,
+			Default:    nil,
+		},
+	}
+}
+
+func (m *Monitoring) NewInstance() component.ObjectMeta {
+	return &Monitoring{
+		Namespace:      "monitoring",
+		ManifestsDir:   manifestsDir,
+		Retention:      "15d",
+		ScrapeInterval: "30s",
+		StorageSize:    "50Gi",
+		Alertmanager:   true,
+	}
+}
+
+func (m *Monitoring) GetDependence() []string {
+	return []string{component.InternalCategoryKubernetes}
+}
+
+func (m *Monitoring) GetInstallSteps() []v1.Step {
+	return m.installSteps
+}
+
+func (m *Monitoring) GetUninstallSteps() []v1.Step {
+	return m.uninstallSteps
+}
+
+// GetUpgradeSteps is empty, the release is upgraded by the install steps which run helm upgrade --install.
+func (m *Monitoring) GetUpgradeSteps() []v1.Step {
+	return m.upgradeSteps
+}
@@ -0,0 +1,135 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package monitoring
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(m *Monitoring)
+		wantErr bool
+	}{
+		{name: "default", modify: func(m *Monitoring) {}},
+		{name: "remote write", modify: func(m *Monitoring) {
+			m.RemoteWriteURL, m.RemoteWriteUsername, m.RemoteWritePassword = "https://prometheus.local/api/v1/write", "kc", "secret"
+		}},
+		{name: "invalid retention", modify: func(m *Monitoring) { m.Retention = "15 days" }, wantErr: true},
+		{name: "invalid storage size", modify: func(m *Monitoring) { m.StorageClass, m.StorageSize = "nfs-sc", "fifty" }, wantErr: true},
+		{name: "grafana without password", modify: func(m *Monitoring) { m.Grafana = true }, wantErr: true},
+		{name: "invalid remote write", modify: func(m *Monitoring) { m.RemoteWriteURL = "prometheus.local" }, wantErr: true},
+		{name: "username without password", modify: func(m *Monitoring) {
+			m.RemoteWriteURL, m.RemoteWriteUsername = "https://prometheus.local/api/v1/write", "kc"
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := (&Monitoring{}).NewInstance().(*Monitoring)
+			tt.modify(m)
+			if err := m.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	metadata := component.ExtraMetadata{
+		ClusterName: "demo",
+		Masters:     component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+		Workers:     component.NodeList{{ID: "w1", IPv4: "192.168.10.11"}},
+	}
+	m := (&Monitoring{}).NewInstance().(*Monitoring)
+	m.ImageRepoMirror, m.StorageClass, m.AgentMetricsPort = "192.168.10.20:5000", "nfs-sc", 9090
+	m.RemoteWriteURL, m.RemoteWriteUsername, m.RemoteWritePassword = "https://prometheus.local/api/v1/write", "kc", "secret"
+	out, err := m.values(&metadata)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		KubeEtcd struct {
+			Endpoints []string `json:"endpoints"`
+		} `json:"kubeEtcd"`
+		Prometheus struct {
+			PrometheusSpec struct {
+				Image struct {
+					Repository string `json:"repository"`
+				} `json:"image"`
+				ExternalLabels map[string]string `json:"externalLabels"`
+				RemoteWrite    []struct {
+					URL string `json:"url"`
+				} `json:"remoteWrite"`
+				AdditionalScrapeConfigs []struct {
+					StaticConfigs []struct {
+						Targets []string `json:"targets"`
+					} `json:"static_configs"`
+				} `json:"additionalScrapeConfigs"`
+			} `json:"prometheusSpec"`
+		} `json:"prometheus"`
+		ExtraManifests []interface{} `json:"extraManifests"`
+	}
+	if err = yaml.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("values are not valid yaml: %v\n%s", err, out)
+	}
+	spec := got.Prometheus.PrometheusSpec
+	if !reflect.DeepEqual(got.KubeEtcd.Endpoints, []string{"192.168.10.10"}) {
+		t.Errorf("etcd endpoints = %v", got.KubeEtcd.Endpoints)
+	}
+	if spec.Image.Repository != "192.168.10.20:5000/prometheus/prometheus" {
+		t.Errorf("prometheus image = %s", spec.Image.Repository)
+	}
+	if spec.ExternalLabels["cluster"] != "demo" {
+		t.Errorf("external labels = %v", spec.ExternalLabels)
+	}
+	if len(spec.RemoteWrite) != 1 || spec.RemoteWrite[0].URL != m.RemoteWriteURL {
+		t.Errorf("remote write = %v", spec.RemoteWrite)
+	}
+	if len(spec.AdditionalScrapeConfigs) != 1 ||
+		!reflect.DeepEqual(spec.AdditionalScrapeConfigs[0].StaticConfigs[0].Targets, []string{"192.168.10.10:9090", "192.168.10.11:9090"}) {
+		t.Errorf("additional scrape configs = %v", spec.AdditionalScrapeConfigs)
+	}
+	if len(got.ExtraManifests) != 1 {
+		t.Errorf("remote write credentials secret is not created")
+	}
+}
+
+func TestInitSteps(t *testing.T) {
+	metadata := component.ExtraMetadata{
+		CRI:     "containerd",
+		Offline: true,
+		Masters: component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+		Workers: component.NodeList{{ID: "w1", IPv4: "192.168.10.11"}},
+	}
+	m := (&Monitoring{}).NewInstance().(*Monitoring)
+	if err := m.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+		t.Fatal(err)
+	}
+	// chart images, helm installer, values and release
+	if install, uninstall := m.GetInstallSteps(), m.GetUninstallSteps(); len(install) != 4 || len(uninstall) != 2 {
+		t.Fatalf("got %d install and %d uninstall steps", len(install), len(uninstall))
+	}
+}
@@ -0,0 +1,148 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package monitoring
+
+// valuesTemplate overrides the default values of kube-prometheus-stack. The control plane components
+// are scraped on the masters, as kubeadm does not create services of them.
+const valuesTemplate = `
+fullnameOverride: kube-prometheus-stack
+alertmanager:
+  enabled: {{.Alertmanager}}
+{{- with .ImageRepoMirror}}
+  alertmanagerSpec:
+    image:
+      repository: {{.}}/prometheus/alertmanager
+{{- end}}
+grafana:
+  enabled: {{.Grafana}}
+{{- if .Grafana}}
+  adminPassword: {{quote .GrafanaAdminPassword}}
+{{- with .ImageRepoMirror}}
+  image:
+    repository: {{.}}/grafana/grafana
+  sidecar:
+    image:
+      repository: {{.}}/kiwigrid/k8s-sidecar
+{{- end}}
+{{- end}}
+kubeEtcd:
+  endpoints:
+{{- range .MasterIPs}}
+    - {{.}}
+{{- end}}
+  service:
+    port: 2381
+    targetPort: 2381
+kubeControllerManager:
+  endpoints:
+{{- range .MasterIPs}}
+    - {{.}}
+{{- end}}
+  service:
+    port: 10257
+    targetPort: 10257
+  serviceMonitor:
+    https: true
+    insecureSkipVerify: true
+kubeScheduler:
+  endpoints:
+{{- range .MasterIPs}}
+    - {{.}}
+{{- end}}
+  service:
+    port: 10259
+    targetPort: 10259
+  serviceMonitor:
+    https: true
+    insecureSkipVerify: true
+{{- with .ImageRepoMirror}}
+kube-state-metrics:
+  image:
+    repository: {{.}}/kube-state-metrics/kube-state-metrics
+prometheus-node-exporter:
+  image:
+    repository: {{.}}/prometheus/node-exporter
+{{- end}}
+prometheusOperator:
+{{- with .ImageRepoMirror}}
+  image:
+    repository: {{.}}/prometheus-operator/prometheus-operator
+  prometheusConfigReloader:
+    image:
+      repository: {{.}}/prometheus-operator/prometheus-config-reloader
+  admissionWebhooks:
+    patch:
+      image:
+        repository: {{.}}/ingress-nginx/kube-webhook-certgen
+{{- end}}
+  kubeletService:
+    enabled: true
+prometheus:
+  prometheusSpec:
+{{- with .ImageRepoMirror}}
+    image:
+      repository: {{.}}/prometheus/prometheus
+{{- end}}
+    scrapeInterval: {{.ScrapeInterval}}
+    retention: {{.Retention}}
+    externalLabels:
+      cluster: {{quote .ClusterName}}
+{{- if .StorageClass}}
+    storageSpec:
+      volumeClaimTemplate:
+        spec:
+          storageClassName: {{.StorageClass}}
+          accessModes: ["ReadWriteOnce"]
+          resources:
+            requests:
+              storage: {{.StorageSize}}
+{{- end}}
+{{- if .RemoteWriteURL}}
+    remoteWrite:
+      - url: {{quote .RemoteWriteURL}}
+{{- if .RemoteWriteUsername}}
+        basicAuth:
+          username:
+            name: remote-write-credentials
+            key: username
+          password:
+            name: remote-write-credentials
+            key: password
+{{- end}}
+{{- end}}
+{{- if .AgentMetricsPort}}
+    additionalScrapeConfigs:
+      - job_name: kubeclipper-agent
+        static_configs:
+          - targets:
+{{- range .NodeIPs}}
+              - {{.}}:{{$.AgentMetricsPort}}
+{{- end}}
+{{- end}}
+{{- if .RemoteWriteUsername}}
+extraManifests:
+  - apiVersion: v1
+    kind: Secret
+    metadata:
+      name: remote-write-credentials
+    stringData:
+      username: {{quote .RemoteWriteUsername}}
+      password: {{quote .RemoteWritePassword}}
+{{- end}}
+`
@@ -19,46 +19,18 @@
 package nfsprovisioner
 
 import (
-	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"io"
-	"os"
-	"path/filepath"
-	"runtime"
-	"time"
-
-	"go.uber.org/zap"
 
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 
-	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
-
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-
 	"github.com/kubeclipper/kubeclipper/pkg/component"
-	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/component/csi"
 	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
-	"github.com/kubeclipper/kubeclipper/pkg/logger"
-	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
-	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
-	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
-	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
 )
 
 func init() {
-	n := &NFSProvisioner{}
-	if err := component.Register(fmt.Sprintf(component.RegisterFormat, name, version), n); err != nil {
-		panic(err)
-	}
-
-	if err := component.RegisterTemplate(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, nfs), n); err != nil {
-		panic(err)
-	}
-
-	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentImageLoader), &ImageLoader{}); err != nil {
+	if err := csi.Register(&NFSProvisioner{}); err != nil {
 		panic(err)
 	}
 	if err := initI18nForComponentMeta(); err != nil {
@@ -66,22 +38,15 @@ func init() {
 	}
 }
 
-var (
-	_ component.Interface      = (*NFSProvisioner)(nil)
-	_ component.TemplateRender = (*NFSProvisioner)(nil)
-	_ component.StepRunnable   = (*ImageLoader)(nil)
-)
+var _ csi.Provider = (*NFSProvisioner)(nil)
 
 const (
-	nfs              = "nfs"
-	name             = "nfs-provisioner"
-	version          = "v1"
-	namespace        = "kube-system"
-	manifestsDir     = "/tmp/.nfs"
-	scName           = "nfs-sc"
-	filenameFormat   = "nfsprovisioner-%s.yaml"
-	reclaimPolicy    = "Delete"
-	AgentImageLoader = "ImageLoader"
+	nfs          = "nfs"
+	name         = "nfs-provisioner"
+	version      = "v1"
+	namespace    = "kube-system"
+	manifestsDir = "/tmp/.nfs"
+	scName       = "nfs-sc"
 )
 
 var (
@@ -91,56 +56,33 @@ var (
 	errInvalidSharedPath = errors.New("invalid NFS shared path")
 )
 
+var spec = csi.Spec{
+	Name:             name,
+	Version:          version,
+	Template:         nfs,
+	TitleID:          "nfs.metaTitle",
+	Priority:         3,
+	Namespace:        namespace,
+	StorageClassName: scName,
+	Required:         []string{"serverAddr", "sharedPath"},
+	Image:            nfs,
+	ImageVersion:     "v4.0.2",
+	MountOptions:     true,
+}
+
+// NFSProvisioner deploys the nfs-subdir-external-provisioner.
 type NFSProvisioner struct {
-	ImageRepoMirror  string `json:"imageRepoMirror"` // optional
-	Namespace        string `json:"namespace"`       // optional
-	Replicas         int    `json:"replicas"`
-	ManifestsDir     string `json:"manifestsDir"`    // optional
-	ServerAddr       string `json:"serverAddr"`      // required
-	SharedPath       string `json:"sharedPath"`      // required
-	StorageClassName string `json:"scName"`          // optional
-	IsDefault        bool   `json:"isDefaultSC"`     // optional
-	ReclaimPolicy    string `json:"reclaimPolicy"`   // optional
-	ArchiveOnDelete  bool   `json:"archiveOnDelete"` // optional
-	// Dynamically provisioned PersistentVolumes of this storage class are
-	// created with these mountOptions, e.g. ["ro", "soft"].
-	MountOptions                               []string `json:"mountOptions"` // optional
-	installSteps, uninstallSteps, upgradeSteps []v1.Step
-}
-
-func (n *NFSProvisioner) Ns() string {
-	return ""
+	csi.StorageClass
+	ServerAddr      string `json:"serverAddr"`      // required
+	SharedPath      string `json:"sharedPath"`      // required
+	ArchiveOnDelete bool   `json:"archiveOnDelete"` // optional
 }
 
-func (n *NFSProvisioner) Svc() string {
-	return ""
-}
-
-func (n *NFSProvisioner) RequestPath() string {
-	return ""
-}
-
-func (n *NFSProvisioner) Supported() bool {
-	return false
-}
-
-func (n *NFSProvisioner) GetInstanceName() string {
-	return n.StorageClassName
-}
-
-func (n *NFSProvisioner) RequireExtraCluster() []string {
-	return nil
-}
-
-func (n *NFSProvisioner) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
-	return nil
+func (n *NFSProvisioner) Spec() csi.Spec {
+	return spec
 }
 
 func (n *NFSProvisioner) Validate() error {
-	// namespace
-	if !validation.MatchKubernetesNamespace(n.Namespace) {
-		return validation.ErrInvalidNamespace
-	}
 	// server address
 	if n.ServerAddr == "" {
 		return errEmptyServerAddr
@@ -155,346 +97,43 @@ func (n *NFSProvisioner) Validate() error {
 	if !validation.MatchLinuxFilePath(n.SharedPath) {
 		return errInvalidSharedPath
 	}
-	// storage class name
-	if !validation.MatchKubernetesStorageClass(n.StorageClassName) {
-		return validation.ErrInvalidSCName
-	}
-	// reclaim policy
-	return validation.MatchKubernetesReclaimPolicy(n.ReclaimPolicy)
-}
-
-func (n *NFSProvisioner) InitSteps(ctx context.Context) error {
-	metadata := component.GetExtraMetadata(ctx)
-	n.Replicas = len(metadata.Masters.GetNodeIDs())
-	// when the component does not specify an ImageRepoMirror, the cluster LocalRegistry is inherited
-	if n.ImageRepoMirror == "" {
-		n.ImageRepoMirror = metadata.LocalRegistry
-	}
-	if metadata.Offline && n.ImageRepoMirror == "" {
-		// TODO: arch is unnecessary, version can be configured
-		imageloader := &ImageLoader{
-			Version: "v4.0.2",
-			CriType: metadata.CRI,
-			Offline: metadata.Offline,
-		}
-		iData, err := json.Marshal(imageloader)
-		if err != nil {
-			return err
-		}
-		n.installSteps = append(n.installSteps, v1.Step{
-			ID:         strutil.GetUUID(),
-			Name:       "ImageLoader",
-			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
-			ErrIgnore:  false,
-			RetryTimes: 1,
-			Nodes:      utils.UnwrapNodeList(metadata.Masters),
-			Action:     v1.ActionInstall,
-			Commands: []v1.Command{
-				{
-					Type:          v1.CommandCustom,
-					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentImageLoader),
-					CustomCommand: iData,
-				},
-			},
-		})
-	}
-
-	bytes, err := json.Marshal(n)
-	if err != nil {
-		return err
-	}
-
-	stepMaster0 := utils.UnwrapNodeList(metadata.Masters[:1])
-	rs := v1.Step{
-		ID:         strutil.GetUUID(),
-		Name:       "renderNFSProvisionerManifests",
-		Timeout:    metav1.Duration{Duration: 3 * time.Second},
-		ErrIgnore:  true,
-		RetryTimes: 1,
-		Nodes:      stepMaster0,
-		Action:     v1.ActionInstall,
-		Commands: []v1.Command{
-			{
-				Type: v1.CommandTemplateRender,
-				Template: &v1.TemplateCommand{
-					Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, nfs),
-					Data:     bytes,
-				},
-			},
-		},
-	}
-
-	n.installSteps = append(n.installSteps, []v1.Step{
-		rs,
-		{
-			ID:         strutil.GetUUID(),
-			Name:       "deployNFSProvisionerNameSpace",
-			Timeout:    metav1.Duration{Duration: 3 * time.Second},
-			ErrIgnore:  true,
-			RetryTimes: 1,
-			Nodes:      stepMaster0,
-			Action:     v1.ActionInstall,
-			Commands: []v1.Command{
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"kubectl", "apply", "-f", filepath.Join(n.ManifestsDir, fmt.Sprintf(filenameFormat, namespace))},
-				},
-			},
-		},
-		{
-			ID:         strutil.GetUUID(),
-			Name:       "deployNFSProvisioner",
-			Timeout:    metav1.Duration{Duration: 3 * time.Second},
-			ErrIgnore:  true,
-			RetryTimes: 1,
-			Nodes:      stepMaster0,
-			Action:     v1.ActionInstall,
-			Commands: []v1.Command{
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"kubectl", "apply", "-f", filepath.Join(n.ManifestsDir, fmt.Sprintf(filenameFormat, n.StorageClassName))},
-				},
-			},
-		},
-	}...)
-
-	// uninstall
-	n.uninstallSteps = []v1.Step{
-		rs,
-		{
-			ID:         strutil.GetUUID(),
-			Name:       "removeNFSProvisioner",
-			Timeout:    metav1.Duration{Duration: 3 * time.Second},
-			ErrIgnore:  true,
-			RetryTimes: 1,
-			Nodes:      stepMaster0,
-			Action:     v1.ActionUninstall,
-			Commands: []v1.Command{
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"kubectl", "delete", "-f", filepath.Join(n.ManifestsDir, fmt.Sprintf(filenameFormat, n.StorageClassName))},
-				},
-			},
-		},
-	}
 	return nil
 }
 
-func (n *NFSProvisioner) GetName() string {
-	return name
+func (n *NFSProvisioner) Manifests() string {
+	return manifestsTemplate
 }
 
-func (n *NFSProvisioner) GetVersion() string {
-	return version
-}
-
-func (n *NFSProvisioner) GetComponentMeta(lang component.Lang) component.Meta {
-	loc := component.GetLocalizer(lang)
-
+func (n *NFSProvisioner) Properties(loc *i18n.Localizer) map[string]component.JSONSchemaProps {
 	f := component.JSON(false)
-	sc := component.JSON(scName)
-
-	propMap := map[string]component.JSONSchemaProps{
+	return map[string]component.JSONSchemaProps{
 		"serverAddr": {
 			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.serverAddr"}),
-			Properties:   nil,
 			Type:         component.JSONSchemaTypeString,
-			Default:      nil,
 			Description:  "NFS server address",
 			Priority:     2,
 			Dependencies: []string{"enabled"},
 		},
 		"sharedPath": {
 			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.sharedPath"}),
-			Properties:   nil,
 			Type:         component.JSONSchemaTypeString,
-			Default:      nil,
 			Description:  "NFS shared path",
 			Priority:     3,
 			Dependencies: []string{"enabled"},
 		},
-		"scName": {
-			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.scName"}),
-			Properties:   nil,
-			Type:         component.JSONSchemaTypeString,
-			Default:      &sc,
-			Description:  "Storage Class name",
-			Priority:     4,
-			Dependencies: []string{"enabled"},
-		},
-		"isDefaultSC": {
-			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.isDefaultSC"}),
-			Properties:   nil,
-			Type:         component.JSONSchemaTypeBool,
-			Default:      &f,
-			Description:  "set as default Storage Class",
-			Priority:     5,
-			Dependencies: []string{"enabled"},
-		},
-		"reclaimPolicy": {
-			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.reclaimPolicy"}),
-			Properties:   nil,
-			Type:         component.JSONSchemaTypeString,
-			Default:      component.JSON(reclaimPolicy),
-			Description:  "Storage Class reclaim policy",
-			Priority:     6,
-			Dependencies: []string{"enabled"},
-			EnumNames:    []string{"Retain", "Delete"},
-			Enum:         []component.JSON{"Retain", "Delete"},
-		},
 		"archiveOnDelete": {
 			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.archiveOnDelete"}),
-			Properties:   nil,
 			Type:         component.JSONSchemaTypeBool,
 			Default:      &f,
 			Description:  "archive PVC when deleting",
 			Priority:     7,
 			Dependencies: []string{"enabled"},
 		},
-		"mountOptions": {
-			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.mountOptions"}),
-			Properties:   nil,
-			Type:         component.JSONSchemaTypeArray,
-			Default:      nil,
-			Description:  "mount options (e.g. 'nfsvers=3')",
-			Priority:     8,
-			Dependencies: []string{"enabled"},
-		},
-		"replicas": {
-			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.replicas"}),
-			Properties:   nil,
-			Type:         component.JSONSchemaTypeInt,
-			Default:      component.JSON(1),
-			Description:  "nfs provisioner replicas. It should only run on the master nodes",
-			Priority:     11,
-			Dependencies: []string{"enabled"},
-		},
-		"imageRepoMirror": {
-			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.imageRepoMirror"}),
-			Properties:   nil,
-			Type:         component.JSONSchemaTypeString,
-			Default:      nil,
-			Description:  "nfs image repository mirror, the component official repository is used by default",
-			Priority:     12,
-			Dependencies: []string{"enabled"},
-		},
-	}
-
-	return component.Meta{
-		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nfs.metaTitle"}),
-		Name:       name,
-		Version:    version,
-		Unique:     false,
-		Template:   true,
-		Dependence: []string{component.InternalCategoryKubernetes},
-		Category:   component.InternalCategoryStorage,
-		Priority:   3,
-		Schema: &component.JSONSchemaProps{
-			Properties: propMap,
-			Required:   []string{"serverAddr", "sharedPath", "scName"},
-			Type:       component.JSONSchemaTypeObject,
-			Default:    nil,
-		},
 	}
 }
 
 func (n *NFSProvisioner) NewInstance() component.ObjectMeta {
-	return &NFSProvisioner{
-		Namespace:        namespace,
-		ManifestsDir:     manifestsDir,
-		StorageClassName: scName,
-		ReclaimPolicy:    reclaimPolicy,
-	}
-}
-
-func (n *NFSProvisioner) GetDependence() []string {
-	return []string{component.InternalCategoryKubernetes}
-}
-
-func (n *NFSProvisioner) GetInstallSteps() []v1.Step {
-	return n.installSteps
-}
-
-func (n *NFSProvisioner) GetUninstallSteps() []v1.Step {
-	return n.uninstallSteps
-}
-
-func (n *NFSProvisioner) GetUpgradeSteps() []v1.Step {
-	return n.upgradeSteps
-}
-
-func (n *NFSProvisioner) Install(ctx context.Context) error {
-	// TODO:
-	return nil
-}
-
-func (n *NFSProvisioner) UnInstall(ctx context.Context) error {
-	// TODO:
-	return nil
-}
-
-func (n *NFSProvisioner) renderTo(w io.Writer) error {
-	at := tmplutil.New()
-	_, err := at.RenderTo(w, manifestsTemplate, n)
-	return err
-}
-
-func (n *NFSProvisioner) renderNameSpace(w io.Writer) error {
-	at := tmplutil.New()
-	_, err := at.RenderTo(w, nameSpaceTemplate, n)
-	return err
-}
-
-func (n *NFSProvisioner) Render(ctx context.Context, opts component.Options) error {
-	// storage namespace
-	n.Namespace = namespace
-	if err := os.MkdirAll(n.ManifestsDir, 0755); err != nil {
-		return err
-	}
-	nameSpace := filepath.Join(n.ManifestsDir, fmt.Sprintf(filenameFormat, namespace))
-	if err := fileutil.WriteFileWithContext(ctx, nameSpace, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
-		n.renderNameSpace, opts.DryRun); err != nil {
-		return err
-	}
-	manifestsFile := filepath.Join(n.ManifestsDir, fmt.Sprintf(filenameFormat, n.StorageClassName))
-	return fileutil.WriteFileWithContext(ctx, manifestsFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
-		n.renderTo, opts.DryRun)
-}
-
-type ImageLoader struct {
-	Version string
-	CriType string
-	Offline bool
-}
-
-func (n *ImageLoader) Install(ctx context.Context, opts component.Options) ([]byte, error) {
-	instance, err := downloader.NewInstance(ctx, nfs, n.Version, runtime.GOARCH, !n.Offline, opts.DryRun)
-	if err != nil {
-		return nil, err
-	}
-	dstFile, err := instance.DownloadImages()
-	if err != nil {
-		return nil, err
-	}
-	// load image package
-	if err = utils.LoadImage(ctx, opts.DryRun, dstFile, n.CriType); err == nil {
-		logger.Info("nfs packages offline install successfully")
-	}
-
-	return nil, err
-}
-
-func (n *ImageLoader) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
-	instance, err := downloader.NewInstance(ctx, nfs, n.Version, runtime.GOARCH, !n.Offline, opts.DryRun)
-	if err != nil {
-		return nil, err
-	}
-	if err = instance.RemoveImages(); err != nil {
-		logger.Error("remove nfs images compressed file failed", zap.Error(err))
-	}
-	return nil, nil
-}
-
-func (n *ImageLoader) NewInstance() component.ObjectMeta {
-	return &ImageLoader{}
+	sc := csi.NewStorageClass(spec)
+	sc.ManifestsDir = manifestsDir
+	return &NFSProvisioner{StorageClass: sc}
 }
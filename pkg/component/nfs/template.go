@@ -18,13 +18,6 @@
 
 package nfsprovisioner
 
-const nameSpaceTemplate = `
-apiVersion: v1
-kind: Namespace
-metadata:
-  name: {{.Namespace}}
-`
-
 // manifest reference https://github.com/kubernetes-csi/csi-driver-nfs/blob/v2.0.0/deploy/kubernetes/
 const manifestsTemplate = `
 apiVersion: v1
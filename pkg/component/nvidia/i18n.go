@@ -0,0 +1,41 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nvidia
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "nvidia.metaTitle",
+			English: "NVIDIA GPU Setting",
+			Chinese: "NVIDIA GPU设置",
+		},
+		{
+			ID:      "nvidia.namespace",
+			English: "Namespace",
+			Chinese: "命名空间",
+		},
+		{
+			ID:      "nvidia.imageRepoMirror",
+			English: "NVIDIA Device Plugin Image Repository Mirror",
+			Chinese: "NVIDIA设备插件镜像仓库代理",
+		},
+	})
+}
@@ -0,0 +1,474 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nvidia
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sysutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+func init() {
+	n := &NVIDIA{}
+	if err := component.Register(fmt.Sprintf(component.RegisterFormat, name, version), n); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterTemplate(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, devicePlugin), n); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentToolkit), &Toolkit{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ component.Interface      = (*NVIDIA)(nil)
+	_ component.TemplateRender = (*NVIDIA)(nil)
+	_ component.StepRunnable   = (*Toolkit)(nil)
+)
+
+const (
+	name               = "nvidia"
+	version            = "v1"
+	devicePlugin       = "device-plugin"
+	namespace          = "kube-system"
+	manifestsDir       = "/tmp/.nvidia"
+	devicePluginFile   = "nvidia-device-plugin.yaml"
+	AgentToolkit       = "Toolkit"
+	kubeletDataDir     = "/var/lib/kubelet"
+	devicePluginTag    = "v0.14.5"
+	toolkitPackageName = "nvidia-container-toolkit"
+	toolkitVersion     = "v1.14.6"
+	// toolkitPackagesDir holds the rpm and deb packages unpacked from the toolkit config bundle.
+	toolkitPackagesDir = "/usr/local/share/nvidia-container-toolkit"
+	criBackupSuffix    = ".kc-nvidia.bak"
+)
+
+var errUnsupportedCRI = errors.New("nvidia container toolkit only supports containerd and docker")
+
+// criConfigs are the config files rewritten by nvidia-ctk for each container runtime.
+var criConfigs = map[string]string{
+	"containerd": "/etc/containerd/config.toml",
+	"docker":     "/etc/docker/daemon.json",
+}
+
+// toolkitPackages are removed in reverse dependency order on uninstall.
+var toolkitPackages = []string{
+	"nvidia-container-toolkit",
+	"nvidia-container-toolkit-base",
+	"libnvidia-container-tools",
+	"libnvidia-container1",
+}
+
+// NVIDIA exposes the NVIDIA GPUs of cluster nodes to workloads. The container toolkit
+// is installed on the nodes with NVIDIA GPUs and set as the default runtime of the CRI,
+// and the device plugin advertises the GPUs as nvidia.com/gpu resources.
+type NVIDIA struct {
+	ImageRepoMirror string `json:"imageRepoMirror"` // optional
+	Namespace       string `json:"namespace"`       // optional
+	ManifestsDir    string `json:"manifestsDir"`    // optional
+	// KubeletDataDir is filled with the kubelet root dir of the cluster, the device plugin socket lives under it.
+	KubeletDataDir                             string `json:"kubeletDataDir,omitempty"`
+	installSteps, uninstallSteps, upgradeSteps []v1.Step
+}
+
+func (n *NVIDIA) Ns() string {
+	return n.Namespace
+}
+
+func (n *NVIDIA) Svc() string {
+	return ""
+}
+
+func (n *NVIDIA) RequestPath() string {
+	return ""
+}
+
+func (n *NVIDIA) Supported() bool {
+	return false
+}
+
+func (n *NVIDIA) GetInstanceName() string {
+	return name
+}
+
+func (n *NVIDIA) RequireExtraCluster() []string {
+	return nil
+}
+
+func (n *NVIDIA) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
+	return nil
+}
+
+func (n *NVIDIA) Validate() error {
+	if !validation.MatchKubernetesNamespace(n.Namespace) {
+		return validation.ErrInvalidNamespace
+	}
+	return nil
+}
+
+func (n *NVIDIA) InitSteps(ctx context.Context) error {
+	metadata := component.GetExtraMetadata(ctx)
+	if _, ok := criConfigs[metadata.CRI]; !ok {
+		return errUnsupportedCRI
+	}
+	if n.ImageRepoMirror == "" {
+		n.ImageRepoMirror = metadata.LocalRegistry
+	}
+	n.KubeletDataDir = strutil.StringDefaultIfEmpty(kubeletDataDir, metadata.KubeletDataDir)
+	stepMaster0 := utils.UnwrapNodeList(metadata.Masters[:1])
+	// the toolkit step runs on every node and does nothing on the nodes without NVIDIA GPU,
+	// so GPU nodes joined later only need the step to be run again.
+	stepAllNodes := utils.UnwrapNodeList(metadata.GetAllNodes())
+
+	toolkit := &Toolkit{
+		Version: toolkitVersion,
+		CriType: metadata.CRI,
+		Offline: metadata.Offline,
+	}
+	tData, err := json.Marshal(toolkit)
+	if err != nil {
+		return err
+	}
+	toolkitStep := func(action v1.StepAction) v1.Step {
+		return v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "nvidiaContainerToolkit",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  action == v1.ActionUninstall,
+			RetryTimes: 1,
+			Nodes:      stepAllNodes,
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentToolkit),
+					CustomCommand: tData,
+				},
+			},
+		}
+	}
+
+	bytes, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	renderStep := func(action v1.StepAction) v1.Step {
+		return v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "renderNvidiaDevicePlugin",
+			Timeout:    metav1.Duration{Duration: 3 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type: v1.CommandTemplateRender,
+					Template: &v1.TemplateCommand{
+						Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, devicePlugin),
+						Data:     bytes,
+					},
+				},
+			},
+		}
+	}
+	manifest := filepath.Join(n.ManifestsDir, devicePluginFile)
+
+	n.installSteps = []v1.Step{
+		toolkitStep(v1.ActionInstall),
+		renderStep(v1.ActionInstall),
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "deployNvidiaDevicePlugin",
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"kubectl", "apply", "-f", manifest},
+				},
+			},
+		},
+	}
+	n.uninstallSteps = []v1.Step{
+		renderStep(v1.ActionUninstall),
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "removeNvidiaDevicePlugin",
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"kubectl", "delete", "-f", manifest, "--ignore-not-found"},
+				},
+			},
+		},
+		toolkitStep(v1.ActionUninstall),
+	}
+	return nil
+}
+
+func (n *NVIDIA) GetName() string {
+	return name
+}
+
+func (n *NVIDIA) GetVersion() string {
+	return version
+}
+
+func (n *NVIDIA) GetComponentMeta(lang component.Lang) component.Meta {
+	loc := component.GetLocalizer(lang)
+
+	propMap := map[string]component.JSONSchemaProps{
+		"namespace": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nvidia.namespace"}),
+			Properties:   nil,
+			Type:         component.JSONSchemaTypeString,
+			Default:      namespace,
+			Description:  "namespace of the device plugin daemonset",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+		},
+		"imageRepoMirror": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nvidia.imageRepoMirror"}),
+			Properties:   nil,
+			Type:         component.JSONSchemaTypeString,
+			Default:      nil,
+			Description:  "nvidia device plugin image repository mirror, the component official repository is used by default",
+			Priority:     3,
+			Dependencies: []string{"enabled"},
+		},
+	}
+
+	return component.Meta{
+		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "nvidia.metaTitle"}),
+		Name:       name,
+		Version:    version,
+		Unique:     true,
+		Template:   false,
+		Dependence: []string{component.InternalCategoryKubernetes},
+		Category:   component.InternalCategoryNodes,
+		Priority:   5,
+		Schema: &component.JSONSchemaProps{
+			Properties: propMap,
+			Required:   nil,
+			Type:       component.JSONSchemaTypeObject,
+			Default:    nil,
+		},
+	}
+}
+
+func (n *NVIDIA) NewInstance() component.ObjectMeta {
+	return &NVIDIA{
+		Namespace:    namespace,
+		ManifestsDir: manifestsDir,
+	}
+}
+
+func (n *NVIDIA) GetDependence() []string {
+	return []string{component.InternalCategoryKubernetes}
+}
+
+func (n *NVIDIA) GetInstallSteps() []v1.Step {
+	return n.installSteps
+}
+
+func (n *NVIDIA) GetUninstallSteps() []v1.Step {
+	return n.uninstallSteps
+}
+
+func (n *NVIDIA) GetUpgradeSteps() []v1.Step {
+	return n.upgradeSteps
+}
+
+func (n *NVIDIA) Image() string {
+	if n.ImageRepoMirror == "" {
+		return fmt.Sprintf("nvcr.io/nvidia/k8s-device-plugin:%s", devicePluginTag)
+	}
+	return fmt.Sprintf("%s/nvidia/k8s-device-plugin:%s", n.ImageRepoMirror, devicePluginTag)
+}
+
+func (n *NVIDIA) renderDevicePlugin(w io.Writer) error {
+	at := tmplutil.New()
+	_, err := at.RenderTo(w, devicePluginTemplate, n)
+	return err
+}
+
+func (n *NVIDIA) Render(ctx context.Context, opts component.Options) error {
+	if err := os.MkdirAll(n.ManifestsDir, 0755); err != nil {
+		return err
+	}
+	return fileutil.WriteFileWithContext(ctx, filepath.Join(n.ManifestsDir, devicePluginFile), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+		n.renderDevicePlugin, opts.DryRun)
+}
+
+// Toolkit installs the NVIDIA container toolkit on the node and makes it the default
+// runtime of the CRI. Nodes without NVIDIA GPU are left untouched.
+type Toolkit struct {
+	Version string
+	CriType string
+	Offline bool
+}
+
+func hasNvidiaGPU() bool {
+	gpus, err := sysutil.GPUInfo()
+	if err != nil {
+		logger.Warn("get gpu info failed", zap.Error(err))
+		return false
+	}
+	for _, gpu := range gpus {
+		if gpu.Vendor == sysutil.GPUVendorNVIDIA {
+			return true
+		}
+	}
+	return false
+}
+
+// localPackages returns the toolkit packages of the host package format.
+func localPackages() ([]string, error) {
+	format := "rpm"
+	if osutil.Local().Family == osutil.FamilyDebian {
+		format = "deb"
+	}
+	return filepath.Glob(filepath.Join(toolkitPackagesDir, format, "*."+format))
+}
+
+func (t *Toolkit) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if !hasNvidiaGPU() {
+		logger.Info("no nvidia gpu found, skip installing nvidia container toolkit")
+		return nil, nil
+	}
+	config, ok := criConfigs[t.CriType]
+	if !ok {
+		return nil, errUnsupportedCRI
+	}
+	instance, err := downloader.NewInstance(ctx, toolkitPackageName, t.Version, runtime.GOARCH, !t.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = instance.DownloadAndUnpackConfigs(); err != nil {
+		return nil, err
+	}
+	packages, err := localPackages()
+	if err != nil {
+		return nil, err
+	}
+	if len(packages) == 0 && !opts.DryRun {
+		return nil, fmt.Errorf("no nvidia container toolkit package found in %s", toolkitPackagesDir)
+	}
+	host := osutil.Local()
+	if _, err = cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, host.Packages.InstallLocal(packages...)); err != nil {
+		return nil, err
+	}
+	// keep the original config only once, installing again must not overwrite it with the modified one
+	if _, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+		fmt.Sprintf("if [ -f %[1]s ] && [ ! -f %[1]s%[2]s ]; then cp -a %[1]s %[1]s%[2]s; fi", config, criBackupSuffix)); err != nil {
+		return nil, err
+	}
+	if _, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "nvidia-ctk", "runtime", "configure",
+		"--runtime="+t.CriType, "--set-as-default"); err != nil {
+		return nil, err
+	}
+	if _, err = cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, host.Services.Restart(t.CriType)); err != nil {
+		return nil, err
+	}
+	if !t.Offline {
+		logger.Info("nvidia container toolkit install successfully")
+		return nil, nil
+	}
+	// the device plugin image is only needed on GPU nodes
+	dstFile, err := instance.DownloadImages()
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.LoadImage(ctx, opts.DryRun, dstFile, t.CriType); err == nil {
+		logger.Info("nvidia container toolkit offline install successfully")
+	}
+	return nil, err
+}
+
+func (t *Toolkit) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	config, ok := criConfigs[t.CriType]
+	if !ok {
+		return nil, errUnsupportedCRI
+	}
+	backup := config + criBackupSuffix
+	if _, err := os.Stat(backup); err == nil {
+		if _, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "mv", "-f", backup, config); err != nil {
+			return nil, err
+		}
+		if _, err = cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Services.Restart(t.CriType)); err != nil {
+			logger.Error("restart container runtime failed", zap.String("cri", t.CriType), zap.Error(err))
+		}
+	}
+	if _, err := exec.LookPath("nvidia-ctk"); err == nil {
+		if _, err = cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Packages.Remove(toolkitPackages...)); err != nil {
+			logger.Error("remove nvidia container toolkit packages failed", zap.Error(err))
+		}
+	}
+	instance, err := downloader.NewInstance(ctx, toolkitPackageName, t.Version, runtime.GOARCH, !t.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveAll(); err != nil {
+		logger.Error("remove nvidia container toolkit packages failed", zap.Error(err))
+	}
+	return nil, nil
+}
+
+func (t *Toolkit) NewInstance() component.ObjectMeta {
+	return &Toolkit{}
+}
@@ -0,0 +1,68 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nvidia
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+)
+
+func TestInitSteps(t *testing.T) {
+	metadata := component.ExtraMetadata{
+		CRI:            "containerd",
+		LocalRegistry:  "192.168.10.20:5000",
+		KubeletDataDir: "/data/kubelet",
+		Masters:        component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+		Workers:        component.NodeList{{ID: "w1", IPv4: "192.168.10.11"}},
+	}
+	n := (&NVIDIA{}).NewInstance().(*NVIDIA)
+	if err := n.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+		t.Fatal(err)
+	}
+	install, uninstall := n.GetInstallSteps(), n.GetUninstallSteps()
+	if len(install) != 3 || len(uninstall) != 3 {
+		t.Fatalf("got %d install and %d uninstall steps", len(install), len(uninstall))
+	}
+	if len(install[0].Nodes) != 2 || len(install[2].Nodes) != 1 {
+		t.Errorf("toolkit must run on all nodes and the device plugin on the first master")
+	}
+
+	var w bytes.Buffer
+	if err := n.renderDevicePlugin(&w); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"image: 192.168.10.20:5000/nvidia/k8s-device-plugin:" + devicePluginTag,
+		"path: /data/kubelet/device-plugins",
+		"namespace: kube-system",
+	} {
+		if !strings.Contains(w.String(), s) {
+			t.Errorf("device plugin manifest does not contain %q", s)
+		}
+	}
+
+	metadata.CRI = "cri-o"
+	if err := n.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != errUnsupportedCRI {
+		t.Errorf("InitSteps() with cri-o = %v, want %v", err, errUnsupportedCRI)
+	}
+}
@@ -82,7 +82,38 @@ func UnwrapNodeList(nl component.NodeList) (nodes []v1.StepNode) {
 			ID:       v.ID,
 			IPv4:     v.IPv4,
 			Hostname: v.Hostname,
+			Arch:     v.Arch,
+			OSFamily: v.OSFamily,
 		})
 	}
 	return nodes
 }
+
+// GroupNodesByArch splits the nodes by architecture so each group can be handed the
+// bundle built for it. The architectures are returned in the order they first appear,
+// nodes without a reported architecture are grouped under "".
+func GroupNodesByArch(nodes []v1.StepNode) ([]string, map[string][]v1.StepNode) {
+	var archs []string
+	groups := make(map[string][]v1.StepNode)
+	for _, node := range nodes {
+		if _, ok := groups[node.Arch]; !ok {
+			archs = append(archs, node.Arch)
+		}
+		groups[node.Arch] = append(groups[node.Arch], node)
+	}
+	return archs, groups
+}
+
+// GroupNodesByOSFamily splits the nodes by OS family so each group gets commands
+// rendered for its distribution, families are returned in the order they first appear.
+func GroupNodesByOSFamily(nodes []v1.StepNode) ([]string, map[string][]v1.StepNode) {
+	var families []string
+	groups := make(map[string][]v1.StepNode)
+	for _, node := range nodes {
+		if _, ok := groups[node.OSFamily]; !ok {
+			families = append(families, node.OSFamily)
+		}
+		groups[node.OSFamily] = append(groups[node.OSFamily], node)
+	}
+	return families, groups
+}
@@ -0,0 +1,51 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package velero
+
+import "github.com/kubeclipper/kubeclipper/pkg/component"
+
+func initI18nForComponentMeta() error {
+	return component.AddI18nMessages(component.I18nMessages{
+		{
+			ID:      "velero.metaTitle",
+			English: "Velero Setting",
+			Chinese: "Velero设置",
+		},
+		{
+			ID:      "velero.backupPoint",
+			English: "BackupPoint",
+			Chinese: "备份空间",
+		},
+		{
+			ID:      "velero.prefix",
+			English: "Prefix",
+			Chinese: "存储路径前缀",
+		},
+		{
+			ID:      "velero.defaultVolumesToFsBackup",
+			English: "Back Up Volumes By Default",
+			Chinese: "默认备份存储卷",
+		},
+		{
+			ID:      "velero.imageRepoMirror",
+			English: "Velero Image Repository Mirror",
+			Chinese: "Velero镜像仓库代理",
+		},
+	})
+}
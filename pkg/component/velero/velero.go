@@ -0,0 +1,472 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package velero
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/component/validation"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+func init() {
+	v := &Velero{}
+	if err := component.Register(fmt.Sprintf(component.RegisterFormat, name, version), v); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterTemplate(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, credentials), v); err != nil {
+		panic(err)
+	}
+
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentInstaller), &Installer{}); err != nil {
+		panic(err)
+	}
+	if err := initI18nForComponentMeta(); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ component.Interface           = (*Velero)(nil)
+	_ component.TemplateRender      = (*Velero)(nil)
+	_ component.BackupPointConsumer = (*Velero)(nil)
+	_ component.StepRunnable        = (*Installer)(nil)
+)
+
+const (
+	name            = "velero"
+	version         = "v1"
+	credentials     = "credentials"
+	namespace       = "velero"
+	manifestsDir    = "/tmp/.velero"
+	credentialsFile = "credentials-velero"
+	AgentInstaller  = "Installer"
+
+	veleroVersion    = "v1.10.0"
+	awsPluginVersion = "v1.6.0"
+)
+
+var (
+	errEmptyBackupPoint  = errors.New("backup point must be provided")
+	errInvalidPrefix     = errors.New("invalid object store prefix")
+	errUnsupportedBackup = errors.New("velero only supports backup points of s3 storage type")
+)
+
+// Velero installs velero into the cluster, backing up workloads and persistent
+// volumes to the object store of a kubeclipper backup point.
+type Velero struct {
+	ImageRepoMirror string `json:"imageRepoMirror"` // optional
+	Namespace       string `json:"namespace"`       // optional
+	ManifestsDir    string `json:"manifestsDir"`    // optional
+	BackupPoint     string `json:"backupPoint"`     // required
+	// Prefix is the directory inside the bucket holding the backups of this
+	// cluster, defaults to the cluster name so clusters can share a backup point.
+	Prefix string `json:"prefix"` // optional
+	// DefaultVolumesToFsBackup backs up the content of every pod volume with the
+	// node agent unless the pod opts out.
+	DefaultVolumesToFsBackup bool `json:"defaultVolumesToFsBackup"` // optional
+	// ObjectStore is resolved from BackupPoint by the server and never stored with the cluster.
+	ObjectStore                                *ObjectStore `json:"objectStore,omitempty"`
+	installSteps, uninstallSteps, upgradeSteps []v1.Step
+}
+
+type ObjectStore struct {
+	Bucket          string `json:"bucket"`
+	Region          string `json:"region"`
+	URL             string `json:"url"`
+	AccessKeyID     string `json:"accessKeyID"`
+	AccessKeySecret string `json:"accessKeySecret"`
+}
+
+func (v *Velero) Ns() string {
+	return ""
+}
+
+func (v *Velero) Svc() string {
+	return ""
+}
+
+func (v *Velero) RequestPath() string {
+	return ""
+}
+
+func (v *Velero) Supported() bool {
+	return false
+}
+
+func (v *Velero) GetInstanceName() string {
+	return name
+}
+
+func (v *Velero) RequireExtraCluster() []string {
+	return nil
+}
+
+func (v *Velero) CompleteWithExtraCluster(extra map[string]component.ExtraMetadata) error {
+	return nil
+}
+
+func (v *Velero) RequireBackupPoint() string {
+	return v.BackupPoint
+}
+
+func (v *Velero) CompleteWithBackupPoint(bp *v1.BackupPoint) error {
+	if bp.StorageType != bs.S3Storage || bp.S3Config == nil {
+		return errUnsupportedBackup
+	}
+	scheme := "http"
+	if bp.S3Config.SSL {
+		scheme = "https"
+	}
+	url := bp.S3Config.Endpoint
+	if !strings.Contains(url, "://") {
+		url = fmt.Sprintf("%s://%s", scheme, url)
+	}
+	v.ObjectStore = &ObjectStore{
+		Bucket:          bp.S3Config.Bucket,
+		Region:          strutil.StringDefaultIfEmpty("minio", bp.S3Config.Region),
+		URL:             url,
+		AccessKeyID:     bp.S3Config.AccessKeyID,
+		AccessKeySecret: bp.S3Config.AccessKeySecret,
+	}
+	return nil
+}
+
+func (v *Velero) Validate() error {
+	if !validation.MatchKubernetesNamespace(v.Namespace) {
+		return validation.ErrInvalidNamespace
+	}
+	if v.BackupPoint == "" {
+		return errEmptyBackupPoint
+	}
+	if strings.HasPrefix(v.Prefix, "/") || strings.ContainsAny(v.Prefix, " \t\n") {
+		return errInvalidPrefix
+	}
+	return nil
+}
+
+func (v *Velero) InitSteps(ctx context.Context) error {
+	metadata := component.GetExtraMetadata(ctx)
+	if v.ImageRepoMirror == "" {
+		v.ImageRepoMirror = metadata.LocalRegistry
+	}
+	if v.Prefix == "" {
+		v.Prefix = metadata.ClusterName
+	}
+	stepMaster0 := utils.UnwrapNodeList(metadata.Masters[:1])
+
+	installer := &Installer{
+		Version: veleroVersion,
+		CriType: metadata.CRI,
+		Offline: metadata.Offline,
+	}
+	iData, err := json.Marshal(installer)
+	if err != nil {
+		return err
+	}
+	installerStep := func(action v1.StepAction) v1.Step {
+		return v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "veleroInstaller",
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  action == v1.ActionUninstall,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, AgentInstaller),
+					CustomCommand: iData,
+				},
+			},
+		}
+	}
+
+	// the object store is only resolved when installing
+	if v.ObjectStore != nil {
+		bytes, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		credentialsPath := filepath.Join(v.ManifestsDir, credentialsFile)
+		v.installSteps = []v1.Step{
+			installerStep(v1.ActionInstall),
+			{
+				ID:         strutil.GetUUID(),
+				Name:       "renderVeleroCredentials",
+				Timeout:    metav1.Duration{Duration: 3 * time.Second},
+				ErrIgnore:  false,
+				RetryTimes: 1,
+				Nodes:      stepMaster0,
+				Action:     v1.ActionInstall,
+				Commands: []v1.Command{
+					{
+						Type: v1.CommandTemplateRender,
+						Template: &v1.TemplateCommand{
+							Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, name, version, credentials),
+							Data:     bytes,
+						},
+					},
+				},
+			},
+			{
+				ID:         strutil.GetUUID(),
+				Name:       "deployVelero",
+				Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+				ErrIgnore:  false,
+				RetryTimes: 1,
+				Nodes:      stepMaster0,
+				Action:     v1.ActionInstall,
+				Commands: []v1.Command{
+					{
+						Type: v1.CommandShell,
+						// the credentials are stored in a secret by velero, do not leave them on the node
+						ShellCommand: []string{"bash", "-c", fmt.Sprintf("%s; ret=$?; rm -f %s; exit $ret",
+							strings.Join(v.installArgs(credentialsPath), " "), credentialsPath)},
+					},
+				},
+			},
+		}
+	}
+
+	v.uninstallSteps = []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "removeVelero",
+			Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      stepMaster0,
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"velero", "uninstall", "--force", "--namespace", v.Namespace},
+				},
+			},
+		},
+		installerStep(v1.ActionUninstall),
+	}
+	return nil
+}
+
+func (v *Velero) installArgs(credentialsPath string) []string {
+	args := []string{
+		"velero", "install",
+		"--namespace", v.Namespace,
+		"--provider", "aws",
+		"--image", v.image("velero", veleroVersion),
+		"--plugins", v.image("velero-plugin-for-aws", awsPluginVersion),
+		"--bucket", v.ObjectStore.Bucket,
+		"--secret-file", credentialsPath,
+		"--backup-location-config", fmt.Sprintf("region=%s,s3ForcePathStyle=true,s3Url=%s", v.ObjectStore.Region, v.ObjectStore.URL),
+		"--use-volume-snapshots=false",
+		"--use-node-agent",
+	}
+	if v.Prefix != "" {
+		args = append(args, "--prefix", v.Prefix)
+	}
+	if v.DefaultVolumesToFsBackup {
+		args = append(args, "--default-volumes-to-fs-backup")
+	}
+	return args
+}
+
+func (v *Velero) image(repo, tag string) string {
+	if v.ImageRepoMirror == "" {
+		return fmt.Sprintf("velero/%s:%s", repo, tag)
+	}
+	return fmt.Sprintf("%s/velero/%s:%s", v.ImageRepoMirror, repo, tag)
+}
+
+func (v *Velero) GetName() string {
+	return name
+}
+
+func (v *Velero) GetVersion() string {
+	return version
+}
+
+func (v *Velero) GetComponentMeta(lang component.Lang) component.Meta {
+	loc := component.GetLocalizer(lang)
+
+	f := component.JSON(false)
+
+	propMap := map[string]component.JSONSchemaProps{
+		"backupPoint": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "velero.backupPoint"}),
+			Properties:   nil,
+			Type:         component.JSONSchemaTypeString,
+			Default:      nil,
+			Description:  "backup point of s3 storage type holding the backups",
+			Priority:     2,
+			Dependencies: []string{"enabled"},
+		},
+		"prefix": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "velero.prefix"}),
+			Properties:   nil,
+			Type:         component.JSONSchemaTypeString,
+			Default:      nil,
+			Description:  "directory in the bucket holding the backups, the cluster name is used by default",
+			Priority:     3,
+			Dependencies: []string{"enabled"},
+		},
+		"defaultVolumesToFsBackup": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "velero.defaultVolumesToFsBackup"}),
+			Properties:   nil,
+			Type:         component.JSONSchemaTypeBool,
+			Default:      &f,
+			Description:  "back up all pod volumes with the node agent by default",
+			Priority:     4,
+			Dependencies: []string{"enabled"},
+		},
+		"imageRepoMirror": {
+			Title:        loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "velero.imageRepoMirror"}),
+			Properties:   nil,
+			Type:         component.JSONSchemaTypeString,
+			Default:      nil,
+			Description:  "velero image repository mirror, the component official repository is used by default",
+			Priority:     5,
+			Dependencies: []string{"enabled"},
+		},
+	}
+
+	return component.Meta{
+		Title:      loc.MustLocalize(&i18n.LocalizeConfig{MessageID: "velero.metaTitle"}),
+		Name:       name,
+		Version:    version,
+		Unique:     true,
+		Template:   false,
+		Dependence: []string{component.InternalCategoryKubernetes},
+		Category:   component.InternalCategoryStorage,
+		Priority:   4,
+		Schema: &component.JSONSchemaProps{
+			Properties: propMap,
+			Required:   []string{"backupPoint"},
+			Type:       component.JSONSchemaTypeObject,
+			Default:    nil,
+		},
+	}
+}
+
+func (v *Velero) NewInstance() component.ObjectMeta {
+	return &Velero{
+		Namespace:    namespace,
+		ManifestsDir: manifestsDir,
+	}
+}
+
+func (v *Velero) GetDependence() []string {
+	return []string{component.InternalCategoryKubernetes}
+}
+
+func (v *Velero) GetInstallSteps() []v1.Step {
+	return v.installSteps
+}
+
+func (v *Velero) GetUninstallSteps() []v1.Step {
+	return v.uninstallSteps
+}
+
+func (v *Velero) GetUpgradeSteps() []v1.Step {
+	return v.upgradeSteps
+}
+
+func (v *Velero) renderCredentials(w io.Writer) error {
+	at := tmplutil.New()
+	_, err := at.RenderTo(w, credentialsTemplate, v.ObjectStore)
+	return err
+}
+
+func (v *Velero) Render(ctx context.Context, opts component.Options) error {
+	if v.ObjectStore == nil {
+		return errEmptyBackupPoint
+	}
+	if err := os.MkdirAll(v.ManifestsDir, 0755); err != nil {
+		return err
+	}
+	return fileutil.WriteFileWithContext(ctx, filepath.Join(v.ManifestsDir, credentialsFile), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600,
+		v.renderCredentials, opts.DryRun)
+}
+
+// Installer puts the velero cli on the node, and loads the velero images for offline clusters.
+type Installer struct {
+	Version string
+	CriType string
+	Offline bool
+}
+
+func (n *Installer) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, name, n.Version, runtime.GOARCH, !n.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = instance.DownloadAndUnpackConfigs(); err != nil {
+		return nil, err
+	}
+	if !n.Offline {
+		return nil, nil
+	}
+	dstFile, err := instance.DownloadImages()
+	if err != nil {
+		return nil, err
+	}
+	if err = utils.LoadImage(ctx, opts.DryRun, dstFile, n.CriType); err == nil {
+		logger.Info("velero packages offline install successfully")
+	}
+	return nil, err
+}
+
+func (n *Installer) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, name, n.Version, runtime.GOARCH, !n.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveAll(); err != nil {
+		logger.Error("remove velero packages failed", zap.Error(err))
+	}
+	return nil, nil
+}
+
+func (n *Installer) NewInstance() component.ObjectMeta {
+	return &Installer{}
+}
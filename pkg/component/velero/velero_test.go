@@ -0,0 +1,97 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package velero
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestCompleteWithBackupPoint(t *testing.T) {
+	v := &Velero{}
+	if err := v.CompleteWithBackupPoint(&v1.BackupPoint{StorageType: "fs", FsConfig: &v1.FsConfig{BackupRootDir: "/opt/backup"}}); err == nil {
+		t.Fatal("expected fs backup point to be rejected")
+	}
+	err := v.CompleteWithBackupPoint(&v1.BackupPoint{StorageType: "s3", S3Config: &v1.S3Config{
+		Bucket:          "backups",
+		Endpoint:        "192.168.10.20:9000",
+		AccessKeyID:     "ak",
+		AccessKeySecret: "sk",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ObjectStore{Bucket: "backups", Region: "minio", URL: "http://192.168.10.20:9000", AccessKeyID: "ak", AccessKeySecret: "sk"}
+	if *v.ObjectStore != want {
+		t.Errorf("CompleteWithBackupPoint() object store = %+v, want %+v", *v.ObjectStore, want)
+	}
+
+	var w bytes.Buffer
+	if err = v.renderCredentials(&w); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(w.String(), "aws_access_key_id=ak\n") || !strings.Contains(w.String(), "aws_secret_access_key=sk\n") {
+		t.Errorf("unexpected credentials file:\n%s", w.String())
+	}
+}
+
+func TestInitSteps(t *testing.T) {
+	ctx := component.WithExtraMetadata(context.TODO(), component.ExtraMetadata{
+		ClusterName:   "c1",
+		LocalRegistry: "192.168.10.20:5000",
+		Masters:       component.NodeList{{ID: "m1", IPv4: "192.168.10.10"}},
+	})
+
+	// uninstalling works without the backup point being resolved
+	v := (&Velero{}).NewInstance().(*Velero)
+	v.BackupPoint = "bp"
+	if err := v.InitSteps(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.GetInstallSteps()) != 0 || len(v.GetUninstallSteps()) != 2 {
+		t.Fatalf("got %d install and %d uninstall steps", len(v.GetInstallSteps()), len(v.GetUninstallSteps()))
+	}
+
+	v = (&Velero{}).NewInstance().(*Velero)
+	v.BackupPoint = "bp"
+	v.ObjectStore = &ObjectStore{Bucket: "backups", Region: "minio", URL: "http://192.168.10.20:9000"}
+	if err := v.InitSteps(ctx); err != nil {
+		t.Fatal(err)
+	}
+	steps := v.GetInstallSteps()
+	if len(steps) != 3 {
+		t.Fatalf("got %d install steps, want 3", len(steps))
+	}
+	cmd := strings.Join(steps[2].Commands[0].ShellCommand, " ")
+	for _, s := range []string{
+		"--bucket backups",
+		"--prefix c1",
+		"--image 192.168.10.20:5000/velero/velero:" + veleroVersion,
+		"s3Url=http://192.168.10.20:9000",
+	} {
+		if !strings.Contains(cmd, s) {
+			t.Errorf("install command %q does not contain %q", cmd, s)
+		}
+	}
+}
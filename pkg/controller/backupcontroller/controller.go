@@ -26,6 +26,7 @@ import (
 
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -170,6 +171,12 @@ func (r *BackupReconciler) updateBackupStatus(ctx context.Context, log logger.Lo
 			log.Warnf("backup file size is %s, and backup md5 is %s, reconcile again", checkFile.BackupFileSize, checkFile.BackupFileMD5)
 			return fmt.Errorf("backup file size is %d, and backup md5 is %s", checkFile.BackupFileSize, checkFile.BackupFileMD5)
 		}
+		if b.Verify {
+			b.Status.Verification = backupVerification(o, checkFile)
+			if b.Status.Verification.Status == v1.BackupVerificationFailed {
+				log.Warnf("backup(%s) verification failed: %s", b.Name, b.Status.Verification.Message)
+			}
+		}
 		b.Status.ClusterBackupStatus = v1.ClusterBackupAvailable
 		_, err := r.BackupWriter.UpdateBackup(context.TODO(), b)
 		if err != nil {
@@ -236,3 +243,46 @@ func checkBackupTimeout(log logger.Logging, b *v1.Backup) bool {
 
 	return subTime.Seconds() > float64(v1.DefaultBackupTimeoutSec)
 }
+
+// backupVerification reads the response of the verification step of the backup
+// operation and checks the downloaded snapshot matches the one uploaded.
+func backupVerification(o *v1.Operation, checkFile k8s.CheckFile) *v1.BackupVerification {
+	verification := &v1.BackupVerification{
+		Status:     v1.BackupVerificationFailed,
+		VerifiedAt: metav1.Now(),
+	}
+	var stepID string
+	for _, step := range o.Steps {
+		if step.Name == k8s.VerifyBackupStepName {
+			stepID = step.ID
+		}
+	}
+	var response []byte
+	for _, cond := range o.Status.Conditions {
+		if cond.StepID == stepID && len(cond.Status) > 0 {
+			response = cond.Status[0].Response
+		}
+	}
+	if stepID == "" || len(response) == 0 {
+		verification.Message = "no verification result found in the backup operation"
+		return verification
+	}
+	result := k8s.VerifyResult{}
+	if err := json.Unmarshal(response, &result); err != nil {
+		verification.Message = fmt.Sprintf("parse verification result failed: %v", err)
+		return verification
+	}
+	switch {
+	case result.Error != "":
+		verification.Message = result.Error
+	case result.BackupFileSize != checkFile.BackupFileSize || result.BackupFileMD5 != checkFile.BackupFileMD5:
+		verification.Message = fmt.Sprintf("downloaded snapshot (size %d, md5 %s) differs from the uploaded one (size %d, md5 %s)",
+			result.BackupFileSize, result.BackupFileMD5, checkFile.BackupFileSize, checkFile.BackupFileMD5)
+	default:
+		verification.Status = v1.BackupVerified
+	}
+	verification.SnapshotHash = result.SnapshotHash
+	verification.Revision = result.Revision
+	verification.TotalKeys = result.TotalKeys
+	return verification
+}
@@ -0,0 +1,61 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package backupcontroller
+
+import (
+	"encoding/json"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+)
+
+func TestBackupVerification(t *testing.T) {
+	checkFile := k8s.CheckFile{BackupFileSize: 1024, BackupFileMD5: "abc"}
+	op := func(result *k8s.VerifyResult) *v1.Operation {
+		o := &v1.Operation{Steps: []v1.Step{{ID: "1", Name: "createBackup"}, {ID: "2", Name: k8s.VerifyBackupStepName}}}
+		o.Status.Conditions = []v1.OperationCondition{{StepID: "1", Status: []v1.StepStatus{{Response: []byte(`{}`)}}}}
+		if result != nil {
+			resp, _ := json.Marshal(result)
+			o.Status.Conditions = append(o.Status.Conditions, v1.OperationCondition{StepID: "2", Status: []v1.StepStatus{{Response: resp}}})
+		}
+		return o
+	}
+	tests := []struct {
+		name   string
+		result *k8s.VerifyResult
+		want   v1.BackupVerificationStatus
+	}{
+		{name: "verified", result: &k8s.VerifyResult{BackupFileSize: 1024, BackupFileMD5: "abc", SnapshotHash: "ff"}, want: v1.BackupVerified},
+		{name: "checksum mismatch", result: &k8s.VerifyResult{BackupFileSize: 1024, BackupFileMD5: "abd"}, want: v1.BackupVerificationFailed},
+		{name: "snapshot unreadable", result: &k8s.VerifyResult{BackupFileSize: 1024, BackupFileMD5: "abc", Error: "corrupted"}, want: v1.BackupVerificationFailed},
+		{name: "no result", want: v1.BackupVerificationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := backupVerification(op(tt.result), checkFile)
+			if got.Status != tt.want {
+				t.Errorf("backupVerification() status = %s, want %s (%s)", got.Status, tt.want, got.Message)
+			}
+			if tt.want == v1.BackupVerified && got.SnapshotHash != "ff" {
+				t.Errorf("unexpected snapshot hash %q", got.SnapshotHash)
+			}
+		})
+	}
+}
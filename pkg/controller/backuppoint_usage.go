@@ -0,0 +1,82 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const backupPointUsageMonitorPeriod = time.Minute
+
+// BackupPointUsageMon records the storage used by the backups of each backup point in its status.
+type BackupPointUsageMon struct {
+	BackupPointReader cluster.BackupPointReader
+	BackupPointWriter cluster.BackupPointWriter
+	BackupLister      listerv1.BackupLister
+	log               logger.Logging
+}
+
+func (s *BackupPointUsageMon) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("backup-point-usage-monitor")
+	mgr.AddWorkerLoop(s.monitorBackupPointUsage, backupPointUsageMonitorPeriod)
+}
+
+func (s *BackupPointUsageMon) monitorBackupPointUsage() {
+	points, err := s.BackupPointReader.ListBackupPoints(context.TODO(), query.New())
+	if err != nil {
+		s.log.Error("list backup points failed, account usage next period", zap.Error(err))
+		return
+	}
+	list, err := s.BackupLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list backups failed, account usage next period", zap.Error(err))
+		return
+	}
+	backups := make([]v1.Backup, 0, len(list))
+	for _, b := range list {
+		backups = append(backups, *b)
+	}
+	for i := range points.Items {
+		bp := &points.Items[i]
+		status := bp.ComputeUsage(backups)
+		if reflect.DeepEqual(status, bp.Status) {
+			continue
+		}
+		if status.Full && !bp.Status.Full {
+			s.log.Warn("backup point is full, new backups are rejected", zap.String("backupPoint", bp.Name),
+				zap.Int64("usedBytes", status.UsedBytes), zap.String("quota", bp.Quota.String()))
+		}
+		bp.Status = status
+		if _, err = s.BackupPointWriter.UpdateBackupPoint(context.TODO(), bp); err != nil {
+			s.log.Warn("update backup point usage failed", zap.String("backupPoint", bp.Name), zap.Error(err))
+		}
+	}
+}
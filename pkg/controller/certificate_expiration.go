@@ -0,0 +1,192 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+const (
+	certificateMonitorPeriod = time.Hour
+	certificateProbeTimeout  = 10 * time.Second
+	// DefaultCertificateExpirationThreshold is how long before their expiration certificates are reported.
+	DefaultCertificateExpirationThreshold = 30 * 24 * time.Hour
+)
+
+// CertificateMon reads the control plane certificates of the running clusters from their masters
+// and records their expiration in the cluster status. Certificates expiring within the threshold
+// are marked, which is notified to the notifiers subscribed to expiring certificates.
+type CertificateMon struct {
+	ClusterWriter cluster.ClusterWriter
+	ClusterLister listerv1.ClusterLister
+	CmdDelivery   service.CmdDelivery
+	// ExpirationThreshold defaults to DefaultCertificateExpirationThreshold.
+	ExpirationThreshold time.Duration
+	log                 logger.Logging
+}
+
+func (s *CertificateMon) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("certificate-monitor")
+	if s.ExpirationThreshold <= 0 {
+		s.ExpirationThreshold = DefaultCertificateExpirationThreshold
+	}
+	mgr.AddWorkerLoop(s.monitorCertificates, certificateMonitorPeriod)
+}
+
+func (s *CertificateMon) monitorCertificates() {
+	clusters, err := s.ClusterLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list clusters failed, check certificates next period", zap.Error(err))
+		return
+	}
+	for _, clu := range clusters {
+		if clu.Status.Status != v1.ClusterStatusRunning || clu.Kubeadm == nil {
+			continue
+		}
+		certs := s.readCertifications(clu)
+		if len(certs) == 0 || certificationsEqual(clu.Status.Certifications, certs) {
+			continue
+		}
+		clu = clu.DeepCopy()
+		clu.Status.Certifications = certs
+		if _, err = s.ClusterWriter.UpdateCluster(context.TODO(), clu); err != nil {
+			s.log.Warn("update cluster certifications failed", zap.String("cluster", clu.Name), zap.Error(err))
+			continue
+		}
+		if expiring := clu.Status.ExpiringCertifications(); len(expiring) > 0 {
+			s.log.Warn("cluster certificates are about to expire", zap.String("cluster", clu.Name), zap.Strings("certificates", expiring))
+		}
+	}
+}
+
+// readCertifications returns the certificates of the masters which could be read, a master that
+// does not answer is checked again next period.
+func (s *CertificateMon) readCertifications(clu *v1.Cluster) []v1.Certification {
+	earliest := make(map[string]v1.Certification)
+	now := time.Now()
+	for _, id := range clu.Kubeadm.Masters.GetNodeIDs() {
+		out, err := s.CmdDelivery.DeliverCmd(context.TODO(), id, []string{"/bin/bash", "-c", k8s.CertificatesScript}, certificateProbeTimeout)
+		if err != nil {
+			s.log.Warn("read certificates of master failed", zap.String("cluster", clu.Name), zap.String("node", id), zap.Error(err))
+			continue
+		}
+		expirations, err := parseCertificates(out)
+		if err != nil {
+			s.log.Warn("parse certificates of master failed", zap.String("cluster", clu.Name), zap.String("node", id), zap.Error(err))
+			continue
+		}
+		for name, notAfter := range expirations {
+			if c, ok := earliest[name]; ok && !notAfter.Before(c.ExpirationTime.Time) {
+				continue
+			}
+			earliest[name] = v1.Certification{
+				Name:           name,
+				Node:           id,
+				ExpirationTime: metav1.NewTime(notAfter),
+				Expiring:       notAfter.Sub(now) < s.ExpirationThreshold,
+			}
+		}
+	}
+	certs := make([]v1.Certification, 0, len(earliest))
+	for _, c := range earliest {
+		certs = append(certs, c)
+	}
+	sort.Slice(certs, func(i, j int) bool { return certs[i].Name < certs[j].Name })
+	return certs
+}
+
+// parseCertificates returns the expiration of the certificates printed by k8s.CertificatesScript.
+// Only the first certificate of a file is read, it is the leaf of a bundle.
+func parseCertificates(out []byte) (map[string]time.Time, error) {
+	result := make(map[string]time.Time)
+	var (
+		name    string
+		content bytes.Buffer
+	)
+	flush := func() error {
+		if name == "" {
+			return nil
+		}
+		block, _ := pem.Decode(content.Bytes())
+		if block == nil {
+			return fmt.Errorf("no pem data found for certificate %s", name)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("parse certificate %s: %v", name, err)
+		}
+		result[name] = cert.NotAfter
+		return nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# ") {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			name = strings.TrimPrefix(line, "# ")
+			content.Reset()
+			continue
+		}
+		content.WriteString(line)
+		content.WriteByte('\n')
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// certificationsEqual compares the expirations by instant, the stored ones lost their location.
+func certificationsEqual(a, b []v1.Certification) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Node != b[i].Node || a[i].Expiring != b[i].Expiring ||
+			!a[i].ExpirationTime.Equal(&b[i].ExpirationTime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func testCertificatePEM(t *testing.T, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kube-apiserver"},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestParseCertificates(t *testing.T) {
+	apiserver := time.Date(2027, 1, 2, 3, 4, 5, 0, time.UTC)
+	ca := time.Date(2035, 1, 2, 3, 4, 5, 0, time.UTC)
+	out := strings.Join([]string{
+		"# apiserver.crt", testCertificatePEM(t, apiserver),
+		"# etcd/ca.crt", testCertificatePEM(t, ca),
+	}, "\n")
+
+	got, err := parseCertificates([]byte(out))
+	if err != nil {
+		t.Fatalf("parseCertificates() error = %v", err)
+	}
+	if len(got) != 2 || !got["apiserver.crt"].Equal(apiserver) || !got["etcd/ca.crt"].Equal(ca) {
+		t.Errorf("parseCertificates() = %v", got)
+	}
+
+	if _, err = parseCertificates([]byte("# admin.conf\nbase64: invalid input\n")); err == nil {
+		t.Error("expected an error for a certificate without pem data")
+	}
+}
+
+func TestCertificationsEqual(t *testing.T) {
+	at := time.Date(2027, 1, 2, 3, 4, 5, 0, time.UTC)
+	a := []v1.Certification{{Name: "apiserver.crt", Node: "m1", ExpirationTime: metav1.NewTime(at)}}
+	b := []v1.Certification{{Name: "apiserver.crt", Node: "m1", ExpirationTime: metav1.NewTime(at.Local())}}
+	if !certificationsEqual(a, b) {
+		t.Error("the same instant in another location must be equal")
+	}
+	b[0].Expiring = true
+	if certificationsEqual(a, b) {
+		t.Error("certifications turning expiring must not be equal")
+	}
+}
@@ -0,0 +1,247 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	clusterHealthMonitorPeriod = time.Minute
+	clusterHealthProbeTimeout  = 5 * time.Second
+	// maxListedNames bounds how many unhealthy nodes or pods are named in a condition message.
+	maxListedNames = 5
+)
+
+// ClusterHealthMon probes the running clusters and records the result as health
+// conditions in the cluster status.
+type ClusterHealthMon struct {
+	ClusterWriter cluster.ClusterWriter
+	ClusterLister listerv1.ClusterLister
+	mgr           manager.Manager
+	log           logger.Logging
+}
+
+func (s *ClusterHealthMon) SetupWithManager(mgr manager.Manager) {
+	s.mgr = mgr
+	s.log = mgr.GetLogger().WithName("cluster-health-monitor")
+	mgr.AddWorkerLoop(s.monitorClusterHealth, clusterHealthMonitorPeriod)
+}
+
+func (s *ClusterHealthMon) monitorClusterHealth() {
+	clusters, err := s.ClusterLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list clusters failed, probe cluster health next period", zap.Error(err))
+		return
+	}
+	for _, clu := range clusters {
+		// installing and terminating clusters are reported by their status instead
+		if clu.Status.Status != v1.ClusterStatusRunning && clu.Status.Status != v1.ClusterStatusUpgrading {
+			continue
+		}
+		var conditions []v1.ClusterHealthCondition
+		if cc, exist := s.mgr.GetClusterClientSet(clu.Name); exist {
+			conditions = probeClusterHealth(cc.Kubernetes())
+		} else {
+			conditions = unreachableConditions("ClientNotReady", "kubernetes client of the cluster has not been created")
+		}
+		health, changed := mergeHealthConditions(clu.Status.Health, conditions, metav1.Now())
+		if !changed {
+			continue
+		}
+		clu = clu.DeepCopy()
+		clu.Status.Health = health
+		if _, err = s.ClusterWriter.UpdateCluster(context.TODO(), clu); err != nil {
+			s.log.Warn("update cluster health failed", zap.String("cluster", clu.Name), zap.Error(err))
+			continue
+		}
+		s.log.Debug("cluster health changed", zap.String("cluster", clu.Name), zap.String("health", clu.Status.HealthSummary()))
+	}
+}
+
+func probeClusterHealth(clientset kubernetes.Interface) []v1.ClusterHealthCondition {
+	ctx, cancel := context.WithTimeout(context.TODO(), clusterHealthProbeTimeout)
+	defer cancel()
+
+	if err := readyz(ctx, clientset, "/readyz"); err != nil {
+		return unreachableConditions("APIServerUnreachable", err.Error())
+	}
+	conditions := []v1.ClusterHealthCondition{{
+		Type:   v1.ClusterAPIServerReachable,
+		Status: v1.ConditionTrue,
+		Reason: "APIServerReady",
+	}}
+
+	etcd := v1.ClusterHealthCondition{Type: v1.ClusterEtcdHealthy, Status: v1.ConditionTrue, Reason: "EtcdReady"}
+	if err := readyz(ctx, clientset, "/readyz/etcd"); err != nil {
+		etcd.Status, etcd.Reason, etcd.Message = v1.ConditionFalse, "EtcdNotReady", err.Error()
+	}
+	conditions = append(conditions, etcd)
+
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		conditions = append(conditions, unknownCondition(v1.ClusterNodesReady, err))
+	} else {
+		conditions = append(conditions, nodesReadyCondition(nodes.Items))
+	}
+
+	pods, err := clientset.CoreV1().Pods(metav1.NamespaceSystem).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		conditions = append(conditions, unknownCondition(v1.ClusterAddonsHealthy, err))
+	} else {
+		conditions = append(conditions, addonsHealthyCondition(pods.Items))
+	}
+	return conditions
+}
+
+func readyz(ctx context.Context, clientset kubernetes.Interface, path string) error {
+	content, err := clientset.Discovery().RESTClient().Get().AbsPath(path).DoRaw(ctx)
+	if err != nil {
+		return err
+	}
+	if string(content) != "ok" {
+		return fmt.Errorf("%s returned %q", path, content)
+	}
+	return nil
+}
+
+// unreachableConditions reports the apiserver as unreachable, which leaves every other condition unknown.
+func unreachableConditions(reason, message string) []v1.ClusterHealthCondition {
+	conditions := []v1.ClusterHealthCondition{{
+		Type:    v1.ClusterAPIServerReachable,
+		Status:  v1.ConditionFalse,
+		Reason:  reason,
+		Message: message,
+	}}
+	for _, t := range []v1.ClusterHealthConditionType{v1.ClusterEtcdHealthy, v1.ClusterNodesReady, v1.ClusterAddonsHealthy} {
+		conditions = append(conditions, v1.ClusterHealthCondition{Type: t, Status: v1.ConditionUnknown, Reason: reason})
+	}
+	return conditions
+}
+
+func unknownCondition(t v1.ClusterHealthConditionType, err error) v1.ClusterHealthCondition {
+	return v1.ClusterHealthCondition{Type: t, Status: v1.ConditionUnknown, Reason: "ProbeFailed", Message: err.Error()}
+}
+
+func nodesReadyCondition(nodes []corev1.Node) v1.ClusterHealthCondition {
+	var notReady []string
+	for i := range nodes {
+		if !isNodeReady(&nodes[i]) {
+			notReady = append(notReady, nodes[i].Name)
+		}
+	}
+	cond := v1.ClusterHealthCondition{
+		Type:    v1.ClusterNodesReady,
+		Status:  v1.ConditionTrue,
+		Reason:  "NodesReady",
+		Message: fmt.Sprintf("%d/%d nodes ready", len(nodes)-len(notReady), len(nodes)),
+	}
+	if len(notReady) > 0 {
+		cond.Status, cond.Reason = v1.ConditionFalse, "NodesNotReady"
+		cond.Message += ", not ready: " + joinNames(notReady)
+	}
+	return cond
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// addonsHealthyCondition checks the pods of kube-system, which hold the cni, dns and
+// proxy addons. Completed pods, such as those of finished jobs, are ignored.
+func addonsHealthyCondition(pods []corev1.Pod) v1.ClusterHealthCondition {
+	var unhealthy []string
+	for i := range pods {
+		if pods[i].Status.Phase == corev1.PodSucceeded {
+			continue
+		}
+		if !isPodReady(&pods[i]) {
+			unhealthy = append(unhealthy, pods[i].Name)
+		}
+	}
+	if len(unhealthy) == 0 {
+		return v1.ClusterHealthCondition{Type: v1.ClusterAddonsHealthy, Status: v1.ConditionTrue, Reason: "PodsReady"}
+	}
+	return v1.ClusterHealthCondition{
+		Type:    v1.ClusterAddonsHealthy,
+		Status:  v1.ConditionFalse,
+		Reason:  "PodsNotReady",
+		Message: fmt.Sprintf("%d pods in %s not ready: %s", len(unhealthy), metav1.NamespaceSystem, joinNames(unhealthy)),
+	}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func joinNames(names []string) string {
+	sort.Strings(names)
+	if len(names) > maxListedNames {
+		return strings.Join(names[:maxListedNames], ", ") + fmt.Sprintf(" and %d more", len(names)-maxListedNames)
+	}
+	return strings.Join(names, ", ")
+}
+
+// mergeHealthConditions applies the probed conditions to the current ones, keeping the
+// transition time of conditions whose status is unchanged. It reports whether anything changed.
+func mergeHealthConditions(current, probed []v1.ClusterHealthCondition, now metav1.Time) ([]v1.ClusterHealthCondition, bool) {
+	changed := len(current) != len(probed)
+	merged := make([]v1.ClusterHealthCondition, 0, len(probed))
+	for _, cond := range probed {
+		cond.LastTransitionTime = now
+		old := (&v1.ClusterStatus{Health: current}).HealthCondition(cond.Type)
+		if old != nil && old.Status == cond.Status {
+			cond.LastTransitionTime = old.LastTransitionTime
+			changed = changed || old.Reason != cond.Reason || old.Message != cond.Message
+		} else {
+			changed = true
+		}
+		merged = append(merged, cond)
+	}
+	return merged, changed
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func testNode(name string, ready corev1.ConditionStatus) corev1.Node {
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{{
+			Type:   corev1.NodeReady,
+			Status: ready,
+		}}},
+	}
+}
+
+func testPod(name string, phase corev1.PodPhase, ready corev1.ConditionStatus) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.PodStatus{
+			Phase:      phase,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}},
+		},
+	}
+}
+
+func TestNodesReadyCondition(t *testing.T) {
+	cond := nodesReadyCondition([]corev1.Node{
+		testNode("node1", corev1.ConditionTrue),
+		testNode("node2", corev1.ConditionFalse),
+		{ObjectMeta: metav1.ObjectMeta{Name: "node3"}},
+	})
+	if cond.Status != v1.ConditionFalse {
+		t.Errorf("status = %s, want False", cond.Status)
+	}
+	if want := "1/3 nodes ready, not ready: node2, node3"; cond.Message != want {
+		t.Errorf("message = %q, want %q", cond.Message, want)
+	}
+
+	cond = nodesReadyCondition([]corev1.Node{testNode("node1", corev1.ConditionTrue)})
+	if cond.Status != v1.ConditionTrue || cond.Message != "1/1 nodes ready" {
+		t.Errorf("got %s %q, want True \"1/1 nodes ready\"", cond.Status, cond.Message)
+	}
+}
+
+func TestAddonsHealthyCondition(t *testing.T) {
+	cond := addonsHealthyCondition([]corev1.Pod{
+		testPod("coredns-1", corev1.PodRunning, corev1.ConditionTrue),
+		testPod("etcd-backup-1", corev1.PodSucceeded, corev1.ConditionFalse),
+	})
+	if cond.Status != v1.ConditionTrue {
+		t.Errorf("status = %s, want True", cond.Status)
+	}
+
+	cond = addonsHealthyCondition([]corev1.Pod{
+		testPod("coredns-1", corev1.PodRunning, corev1.ConditionFalse),
+		testPod("calico-node-1", corev1.PodPending, corev1.ConditionFalse),
+	})
+	if cond.Status != v1.ConditionFalse {
+		t.Errorf("status = %s, want False", cond.Status)
+	}
+	if want := "2 pods in kube-system not ready: calico-node-1, coredns-1"; cond.Message != want {
+		t.Errorf("message = %q, want %q", cond.Message, want)
+	}
+}
+
+func TestJoinNames(t *testing.T) {
+	got := joinNames([]string{"g", "f", "e", "d", "c", "b", "a"})
+	if want := "a, b, c, d, e and 2 more"; got != want {
+		t.Errorf("joinNames() = %q, want %q", got, want)
+	}
+}
+
+func TestMergeHealthConditions(t *testing.T) {
+	then := metav1.NewTime(time.Now().Add(-time.Hour))
+	now := metav1.Now()
+	current := []v1.ClusterHealthCondition{
+		{Type: v1.ClusterAPIServerReachable, Status: v1.ConditionTrue, Reason: "APIServerReady", LastTransitionTime: then},
+		{Type: v1.ClusterNodesReady, Status: v1.ConditionTrue, Reason: "NodesReady", Message: "2/2 nodes ready", LastTransitionTime: then},
+	}
+
+	merged, changed := mergeHealthConditions(current, current, now)
+	if changed {
+		t.Error("unchanged conditions reported as changed")
+	}
+	if !merged[1].LastTransitionTime.Equal(&then) {
+		t.Error("transition time of unchanged condition was reset")
+	}
+
+	probed := []v1.ClusterHealthCondition{
+		{Type: v1.ClusterAPIServerReachable, Status: v1.ConditionTrue, Reason: "APIServerReady"},
+		{Type: v1.ClusterNodesReady, Status: v1.ConditionFalse, Reason: "NodesNotReady", Message: "1/2 nodes ready, not ready: node2"},
+	}
+	merged, changed = mergeHealthConditions(current, probed, now)
+	if !changed {
+		t.Error("status transition not reported as changed")
+	}
+	if !merged[0].LastTransitionTime.Equal(&then) {
+		t.Error("transition time of unchanged condition was reset")
+	}
+	if !merged[1].LastTransitionTime.Equal(&now) {
+		t.Error("transition time of changed condition was not updated")
+	}
+	if got := (&v1.ClusterStatus{Health: merged}).HealthSummary(); got != "Unhealthy(NodesReady)" {
+		t.Errorf("HealthSummary() = %q", got)
+	}
+}
@@ -30,15 +30,12 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 
-	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
-
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
-	"github.com/kubeclipper/kubeclipper/pkg/query"
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 
 	"github.com/kubeclipper/kubeclipper/pkg/client/informers"
@@ -54,13 +51,12 @@ import (
 )
 
 type ClusterReconciler struct {
-	CmdDelivery     service.CmdDelivery
-	mgr             manager.Manager
-	ClusterLister   listerv1.ClusterLister
-	NodeLister      listerv1.NodeLister
-	NodeWriter      cluster.NodeWriter
-	ClusterWriter   cluster.ClusterWriter
-	OperationWriter operation.Writer
+	CmdDelivery   service.CmdDelivery
+	mgr           manager.Manager
+	ClusterLister listerv1.ClusterLister
+	NodeLister    listerv1.NodeLister
+	NodeWriter    cluster.NodeWriter
+	ClusterWriter cluster.ClusterWriter
 }
 
 func (r *ClusterReconciler) SetupWithManager(mgr manager.Manager, cache informers.InformerCache) error {
@@ -108,16 +104,13 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}()
 		// The object is being deleted
 		if sets.NewString(clu.ObjectMeta.Finalizers...).Has(v1.ClusterFinalizer) {
-			err = r.updateClusterNode(ctx, clu, true)
+			err = r.releaseClusterNodes(ctx, clu.Name)
 			if err != nil {
 				log.Error("Failed to update cluster node", zap.Error(err))
 				return ctrl.Result{}, err
 			}
-			err = r.OperationWriter.DeleteOperationCollection(ctx, &query.Query{LabelSelector: fmt.Sprintf("%s=%s", common.LabelClusterName, clu.Name)})
-			if err != nil {
-				log.Error("Failed to delete operation", zap.Error(err))
-				return ctrl.Result{}, err
-			}
+			// the operations of the cluster are deleted by the garbage collector, following the
+			// propagation policy the cluster is deleted with
 			// remove our cluster finalizer
 			finalizers := sets.NewString(clu.ObjectMeta.Finalizers...)
 			finalizers.Delete(v1.ClusterFinalizer)
@@ -128,54 +121,41 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 		}
 		return ctrl.Result{}, nil
 	}
-	if err = r.updateClusterNode(ctx, clu, false); err != nil {
+	if err = r.updateClusterNode(ctx, clu); err != nil {
 		return ctrl.Result{}, err
 	}
 
 	return ctrl.Result{}, r.syncClusterClient(ctx, log, clu)
 }
 
-func (r *ClusterReconciler) updateClusterNode(ctx context.Context, clu *v1.Cluster, del bool) error {
+func (r *ClusterReconciler) updateClusterNode(ctx context.Context, clu *v1.Cluster) error {
 	for _, item := range clu.Kubeadm.Workers {
-		if err := r.updateNodeRoleLabel(ctx, clu.Name, item.ID, common.NodeRoleWorker, del); err != nil {
+		if err := r.updateNodeRoleLabel(ctx, clu.Name, item.ID, common.NodeRoleWorker); err != nil {
 			return err
 		}
 	}
 	for _, item := range clu.Kubeadm.Masters {
-		if err := r.updateNodeRoleLabel(ctx, clu.Name, item.ID, common.NodeRoleMaster, del); err != nil {
+		if err := r.updateNodeRoleLabel(ctx, clu.Name, item.ID, common.NodeRoleMaster); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *ClusterReconciler) updateNodeRoleLabel(ctx context.Context, clusterName, nodeName string, role common.NodeRole, del bool) error {
+func (r *ClusterReconciler) updateNodeRoleLabel(ctx context.Context, clusterName, nodeName string, role common.NodeRole) error {
 	node, err := r.NodeLister.Get(nodeName)
 	if err != nil {
 		return err
 	}
-	if del {
-		// check node role label exist.
-		// if existed, delete label and update node.
-		// if not return direct.
-		if _, ok := node.Labels[common.LabelNodeRole]; !ok {
-			return nil
-		}
-		if v, ok := node.Labels[common.LabelClusterName]; !ok || v != clusterName {
-			return nil
-		}
-		delete(node.Labels, common.LabelNodeRole)
-		delete(node.Labels, common.LabelClusterName)
-	} else {
-		// check node role label exist.
-		// if existed, return direct
-		// if not add label and update node.
-		if _, ok := node.Labels[common.LabelNodeRole]; ok {
-			return nil
-		}
-		node.Labels[common.LabelNodeRole] = string(role)
-		node.Labels[common.LabelClusterName] = clusterName
+	// check node role label exist.
+	// if existed, return direct
+	// if not add label and update node.
+	if _, ok := node.Labels[common.LabelNodeRole]; ok {
+		return nil
 	}
+	node = node.DeepCopy()
+	node.Labels[common.LabelNodeRole] = string(role)
+	node.Labels[common.LabelClusterName] = clusterName
 
 	if _, err = r.NodeWriter.UpdateNode(ctx, node); err != nil {
 		return err
@@ -183,6 +163,24 @@ func (r *ClusterReconciler) updateNodeRoleLabel(ctx context.Context, clusterName
 	return nil
 }
 
+// releaseClusterNodes removes the cluster and role labels of the nodes joined to the deleted cluster,
+// including the ones which are no longer in its spec, so that they are free again.
+func (r *ClusterReconciler) releaseClusterNodes(ctx context.Context, clusterName string) error {
+	nodes, err := r.NodeLister.ListByCluster(clusterName)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		node = node.DeepCopy()
+		delete(node.Labels, common.LabelNodeRole)
+		delete(node.Labels, common.LabelClusterName)
+		if _, err = r.NodeWriter.UpdateNode(ctx, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *ClusterReconciler) syncClusterClient(ctx context.Context, log logger.Logging, clu *v1.Cluster) error {
 	if clu.Status.Status == v1.ClusterStatusInstalling || clu.Status.Status == v1.ClusterStatusInstallFailed {
 		return nil
@@ -198,6 +196,10 @@ func (r *ClusterReconciler) syncClusterClient(ctx context.Context, log logger.Lo
 		log.Debug("clientset has been init")
 		return nil
 	}
+	// the kubeconfig of imported clusters is stored on import, their masters may have no kubectl configured
+	if _, ok := clu.Labels[common.LabelClusterImported]; ok && clu.KubeConfig != nil {
+		return r.addClusterClientSet(log, clu.Name, string(clu.KubeConfig))
+	}
 
 	token, err := r.CmdDelivery.DeliverCmd(ctx, clu.Kubeadm.Masters[0].ID, []string{"/bin/bash", "-c", `kubectl get secret $(kubectl get sa kc-server -n kube-system -o jsonpath={.secrets[0].name}) -n kube-system -o jsonpath={.data.token} | base64 -d`}, 3*time.Second)
 	if err != nil {
@@ -220,22 +222,26 @@ func (r *ClusterReconciler) syncClusterClient(ctx context.Context, log logger.Lo
 		log.Error("update kube config failed", zap.String("cluster", clu.Name), zap.Error(err))
 		return err
 	}
+	return r.addClusterClientSet(log, clu.Name, kubeconfig)
+}
+
+func (r *ClusterReconciler) addClusterClientSet(log logger.Logging, name, kubeconfig string) error {
 	clientConfig, err := clientcmd.NewClientConfigFromBytes([]byte(kubeconfig))
 	if err != nil {
-		log.Error("create cluster client config failed", zap.String("cluster", clu.Name), zap.Error(err))
+		log.Error("create cluster client config failed", zap.String("cluster", name), zap.Error(err))
 		return err
 	}
 	clientcfg, err := clientConfig.ClientConfig()
 	if err != nil {
-		log.Error("get cluster kubeconfig client failed", zap.String("cluster", clu.Name), zap.Error(err))
+		log.Error("get cluster kubeconfig client failed", zap.String("cluster", name), zap.Error(err))
 		return err
 	}
 	clientset, err := kubernetes.NewForConfig(clientcfg)
 	if err != nil {
-		log.Error("create cluster clientset failed", zap.String("cluster", clu.Name), zap.Error(err))
+		log.Error("create cluster clientset failed", zap.String("cluster", name), zap.Error(err))
 		return err
 	}
-	r.mgr.AddClusterClientSet(clu.Name, client.NewKubernetesClient(clientcfg, clientset))
+	r.mgr.AddClusterClientSet(name, client.NewKubernetesClient(clientcfg, clientset))
 	return nil
 }
 
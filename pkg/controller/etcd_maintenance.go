@@ -0,0 +1,242 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1/k8s"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/etcd"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+const (
+	etcdMaintenanceMonitorPeriod = time.Minute
+	platformEtcdTaskTimeout      = 5 * time.Minute
+)
+
+// EtcdMaintenanceMon starts the runs of the cron maintenances whose window opened.
+// Runs on managed clusters are delivered as operations to the masters, runs on the
+// etcd of kubeclipper itself are done by the server and recorded as operations.
+type EtcdMaintenanceMon struct {
+	MaintenanceReader cluster.CronMaintenanceReader
+	MaintenanceWriter cluster.CronMaintenanceWriter
+	ClusterLister     listerv1.ClusterLister
+	NodeLister        listerv1.NodeLister
+	OperationWriter   operation.Writer
+	CmdDelivery       service.CmdDelivery
+	// PlatformEtcd is the etcd of kubeclipper, maintenances without cluster are skipped when it is nil.
+	PlatformEtcd *etcd.Options
+	log          logger.Logging
+}
+
+func (s *EtcdMaintenanceMon) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("etcd-maintenance-monitor")
+	mgr.AddWorkerLoop(s.monitorEtcdMaintenance, etcdMaintenanceMonitorPeriod)
+}
+
+func (s *EtcdMaintenanceMon) monitorEtcdMaintenance() {
+	list, err := s.MaintenanceReader.ListCronMaintenances(context.TODO(), query.New())
+	if err != nil {
+		s.log.Error("list cron maintenances failed, schedule next period", zap.Error(err))
+		return
+	}
+	now := time.Now()
+	for i := range list.Items {
+		m := &list.Items[i]
+		scheduleTime, due := m.Due(now)
+		if !due {
+			continue
+		}
+		var op *v1.Operation
+		if m.Spec.ClusterName == "" {
+			op, err = s.startPlatformMaintenance(m)
+		} else {
+			op, err = s.startClusterMaintenance(m)
+		}
+		if err != nil {
+			s.log.Warn("start etcd maintenance failed, retry next period", zap.String("maintenance", m.Name), zap.Error(err))
+			continue
+		}
+		m.Status.LastScheduleTime = &metav1.Time{Time: scheduleTime}
+		m.Status.LastOperation = op.Name
+		if _, err = s.MaintenanceWriter.UpdateCronMaintenance(context.TODO(), m); err != nil {
+			s.log.Error("update cron maintenance status failed", zap.String("maintenance", m.Name), zap.Error(err))
+		}
+	}
+}
+
+func (s *EtcdMaintenanceMon) startClusterMaintenance(m *v1.CronMaintenance) (*v1.Operation, error) {
+	clu, err := s.ClusterLister.Get(m.Spec.ClusterName)
+	if err != nil {
+		return nil, err
+	}
+	if clu.Status.Status != v1.ClusterStatusRunning {
+		return nil, fmt.Errorf("cluster %s is %s", clu.Name, clu.Status.Status)
+	}
+	var masters []v1.StepNode
+	for _, id := range clu.Kubeadm.Masters.GetNodeIDs() {
+		node, err := s.NodeLister.Get(id)
+		if err != nil {
+			return nil, err
+		}
+		masters = append(masters, v1.StepNode{
+			ID:       node.Name,
+			IPv4:     node.Status.Ipv4DefaultIP,
+			Hostname: node.Labels[common.LabelHostname],
+		})
+	}
+	op := newMaintenanceOperation(m)
+	op.Labels[common.LabelClusterName] = clu.Name
+	op.Steps = k8s.EtcdMaintenanceSteps(masters, m.Spec.OrderedTasks(), clu.Kubeadm.ToolImages)
+	op, err = s.OperationWriter.CreateOperation(context.TODO(), op)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		if err := s.CmdDelivery.DeliverTaskOperation(context.TODO(), op, &service.Options{}); err != nil {
+			s.log.Error("deliver etcd maintenance operation failed", zap.String("operation", op.Name), zap.Error(err))
+		}
+	}()
+	return op, nil
+}
+
+func (s *EtcdMaintenanceMon) startPlatformMaintenance(m *v1.CronMaintenance) (*v1.Operation, error) {
+	if s.PlatformEtcd == nil || len(s.PlatformEtcd.ServerList) == 0 {
+		return nil, fmt.Errorf("etcd of kubeclipper is not configured")
+	}
+	op := newMaintenanceOperation(m)
+	for _, task := range m.Spec.OrderedTasks() {
+		step := v1.Step{
+			ID:     strutil.GetUUID(),
+			Name:   task.StepName(),
+			Action: v1.ActionInstall,
+		}
+		endpoints := s.PlatformEtcd.ServerList
+		if task == v1.MaintenanceTaskCompact {
+			endpoints = endpoints[:1]
+		}
+		for _, ep := range endpoints {
+			step.Nodes = append(step.Nodes, v1.StepNode{ID: ep, Hostname: ep})
+		}
+		op.Steps = append(op.Steps, step)
+	}
+	op, err := s.OperationWriter.CreateOperation(context.TODO(), op)
+	if err != nil {
+		return nil, err
+	}
+	go s.runPlatformMaintenance(op, m.Spec.OrderedTasks())
+	return op, nil
+}
+
+// runPlatformMaintenance runs the steps of the operation against the etcd of kubeclipper,
+// it stops at the first failed step like the steps delivered to agents.
+func (s *EtcdMaintenanceMon) runPlatformMaintenance(op *v1.Operation, tasks []v1.MaintenanceTask) {
+	defer service.HandlerCrash()
+	status := v1.OperationStatus{Status: v1.OperationStatusSuccessful}
+	cli, err := s.PlatformEtcd.NewClient()
+	if err != nil {
+		s.log.Error("connect to etcd of kubeclipper failed", zap.Error(err))
+		status.Status = v1.OperationStatusFailed
+		s.finishPlatformMaintenance(op, status)
+		return
+	}
+	defer cli.Close()
+
+	for i, step := range op.Steps {
+		cond := v1.OperationCondition{StepID: step.ID}
+		for _, node := range step.Nodes {
+			st := v1.StepStatus{StartAt: metav1.Now(), Node: node.ID, Status: v1.StepStatusSuccessful}
+			msg, err := runPlatformEtcdTask(cli, tasks[i], node.ID)
+			st.EndAt = metav1.Now()
+			st.Message = msg
+			if err != nil {
+				st.Status = v1.StepStatusFailed
+				st.Reason = err.Error()
+				status.Status = v1.OperationStatusFailed
+			}
+			cond.Status = append(cond.Status, st)
+		}
+		status.Conditions = append(status.Conditions, cond)
+		if status.Status == v1.OperationStatusFailed {
+			break
+		}
+	}
+	s.finishPlatformMaintenance(op, status)
+}
+
+func runPlatformEtcdTask(cli *clientv3.Client, task v1.MaintenanceTask, endpoint string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.TODO(), platformEtcdTaskTimeout)
+	defer cancel()
+	switch task {
+	case v1.MaintenanceTaskHealth:
+		st, err := etcd.CheckHealth(ctx, cli, endpoint)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("healthy, db size %d bytes", st.DbSize), nil
+	case v1.MaintenanceTaskCompact:
+		rev, err := etcd.Compact(ctx, cli, endpoint)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("compacted to revision %d", rev), nil
+	case v1.MaintenanceTaskDefrag:
+		released, err := etcd.Defragment(ctx, cli, endpoint)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("defragmented, %d bytes released", released), nil
+	}
+	return "", fmt.Errorf("unsupported maintenance task %q", task)
+}
+
+func (s *EtcdMaintenanceMon) finishPlatformMaintenance(op *v1.Operation, status v1.OperationStatus) {
+	if _, err := s.OperationWriter.UpdateOperationStatus(context.TODO(), op.Name, &status); err != nil {
+		s.log.Error("update etcd maintenance operation status failed", zap.String("operation", op.Name), zap.Error(err))
+	}
+}
+
+func newMaintenanceOperation(m *v1.CronMaintenance) *v1.Operation {
+	op := &v1.Operation{}
+	op.Name = uuid.New().String()
+	op.Labels = map[string]string{
+		common.LabelOperationAction: v1.OperationEtcdMaintenance,
+		common.LabelTimeoutSeconds:  v1.DefaultOperationTimeoutSecs,
+		common.LabelCronMaintenance: m.Name,
+	}
+	op.Status.Status = v1.OperationStatusRunning
+	return op
+}
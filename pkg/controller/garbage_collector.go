@@ -0,0 +1,286 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/record"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	garbageCollectPeriod = time.Minute
+	// orphanGracePeriod is how long a dependent whose owner can not be found by its labels is kept,
+	// the owner may be created right after the dependent.
+	orphanGracePeriod = 10 * time.Minute
+)
+
+// GarbageCollector deletes the backups and operations whose cluster is gone, following the
+// ownerReferences the way kubernetes does. Recoveries reference the backup they restore as
+// well, but they are not stored so there is nothing to collect.
+//
+// The dependents of a cluster deleted with background propagation are deleted after it. A cluster
+// deleted with foreground propagation keeps the foregroundDeletion finalizer until its dependents
+// are deleted, and one deleted with orphan propagation keeps the orphan finalizer until its
+// dependents no longer reference it and are marked orphaned.
+//
+// Resources created without ownerReferences are adopted by the owner named in their labels,
+// and deleted when the owner can not be found for longer than the orphan grace period.
+type GarbageCollector struct {
+	ClusterLister   listerv1.ClusterLister
+	ClusterWriter   cluster.ClusterWriter
+	BackupLister    listerv1.BackupLister
+	OperationLister listerv1.OperationLister
+	BackupWriter    cluster.BackupWriter
+	OperationWriter operation.Writer
+	// Recorder records the deleted dependents as events, when it is set.
+	Recorder record.Recorder
+	log      logger.Logging
+	now      func() time.Time
+}
+
+// dependent is a resource with an owner, ownerName is the owner named in its labels.
+type dependent struct {
+	obj       metav1.Object
+	kind      string
+	ownerKind string
+	ownerName string
+	update    func() error
+	delete    func() error
+}
+
+func (s *GarbageCollector) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("garbage-collector")
+	s.now = time.Now
+	mgr.AddWorkerLoop(s.collect, garbageCollectPeriod)
+}
+
+func (s *GarbageCollector) collect() {
+	deps := s.dependents()
+	s.finalizeOwners(deps)
+	for _, d := range deps {
+		if d.obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := s.collectDependent(d); err != nil {
+			s.log.Warn("collect dependent failed", zap.String("kind", d.kind), zap.String("name", d.obj.GetName()), zap.Error(err))
+		}
+	}
+}
+
+func (s *GarbageCollector) dependents() []dependent {
+	var deps []dependent
+	backups, err := s.BackupLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list backups failed, collect them next period", zap.Error(err))
+	}
+	for _, b := range backups {
+		b := b.DeepCopy()
+		deps = append(deps, dependent{
+			obj: b, kind: "Backup", ownerKind: "Cluster", ownerName: b.Labels[common.LabelClusterName],
+			update: func() error {
+				_, err := s.BackupWriter.UpdateBackup(context.TODO(), b)
+				return err
+			},
+			delete: func() error { return s.BackupWriter.DeleteBackup(context.TODO(), b.Name) },
+		})
+	}
+	operations, err := s.OperationLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list operations failed, collect them next period", zap.Error(err))
+	}
+	for _, o := range operations {
+		o := o.DeepCopy()
+		deps = append(deps, dependent{
+			obj: o, kind: "Operation", ownerKind: "Cluster", ownerName: o.Labels[common.LabelClusterName],
+			update: func() error {
+				_, err := s.OperationWriter.UpdateOperation(context.TODO(), o)
+				return err
+			},
+			delete: func() error { return s.OperationWriter.DeleteOperation(context.TODO(), o.Name) },
+		})
+	}
+	return deps
+}
+
+func (s *GarbageCollector) collectDependent(d dependent) error {
+	ref := v1.GetOwnerReference(d.obj, d.ownerKind)
+	if ref != nil {
+		owner, _, err := s.owner(d.ownerKind, ref.Name)
+		if err != nil {
+			return err
+		}
+		if owner != nil && owner.UID == ref.UID {
+			return nil
+		}
+		s.log.Info("owner is gone, delete dependent", zap.String("kind", d.kind), zap.String("name", d.obj.GetName()),
+			zap.String("owner", ref.Name))
+		return s.deleteDependent(d, ref.Name, "is gone")
+	}
+	if d.ownerName == "" || d.obj.GetAnnotations()[common.AnnotationOrphaned] != "" {
+		return nil
+	}
+	owner, deleting, err := s.owner(d.ownerKind, d.ownerName)
+	if err != nil {
+		return err
+	}
+	if deleting {
+		// the dependents of an owner being deleted are finalized with it
+		return nil
+	}
+	if owner != nil {
+		v1.SetOwnerReference(d.obj, *owner)
+		return d.update()
+	}
+	if s.now().Sub(d.obj.GetCreationTimestamp().Time) < orphanGracePeriod {
+		return nil
+	}
+	s.log.Info("delete orphan dependent", zap.String("kind", d.kind), zap.String("name", d.obj.GetName()),
+		zap.String("owner", d.ownerName))
+	return s.deleteDependent(d, d.ownerName, "is gone")
+}
+
+// finalizeOwners deletes or orphans the dependents of the clusters being deleted with foreground or
+// orphan propagation, and then removes the finalizer of the propagation so that the cluster is deleted.
+func (s *GarbageCollector) finalizeOwners(deps []dependent) {
+	clusters, err := s.ClusterLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list clusters failed, finalize them next period", zap.Error(err))
+		return
+	}
+	for _, c := range clusters {
+		if c.DeletionTimestamp == nil {
+			continue
+		}
+		finalizers := sets.NewString(c.Finalizers...)
+		var finalizer string
+		switch {
+		case finalizers.Has(metav1.FinalizerOrphanDependents):
+			finalizer = metav1.FinalizerOrphanDependents
+		case finalizers.Has(metav1.FinalizerDeleteDependents):
+			finalizer = metav1.FinalizerDeleteDependents
+		default:
+			continue
+		}
+		if done, err := s.finalizeDependents(c, deps, finalizer); err != nil || !done {
+			if err != nil {
+				s.log.Warn("finalize dependents failed", zap.String("cluster", c.Name), zap.String("finalizer", finalizer), zap.Error(err))
+			}
+			continue
+		}
+		c = c.DeepCopy()
+		finalizers.Delete(finalizer)
+		c.Finalizers = finalizers.List()
+		if _, err = s.ClusterWriter.UpdateCluster(context.TODO(), c); err != nil {
+			s.log.Warn("remove finalizer failed", zap.String("cluster", c.Name), zap.String("finalizer", finalizer), zap.Error(err))
+		}
+	}
+}
+
+// finalizeDependents orphans or deletes the dependents of the cluster, it reports whether the cluster has
+// no dependents left. Deleted dependents may be kept by their own finalizers for a while.
+func (s *GarbageCollector) finalizeDependents(c *v1.Cluster, deps []dependent, finalizer string) (bool, error) {
+	done := true
+	for _, d := range deps {
+		if !ownedBy(d, c) {
+			continue
+		}
+		if finalizer == metav1.FinalizerOrphanDependents {
+			v1.RemoveOwnerReference(d.obj, d.ownerKind)
+			annotations := d.obj.GetAnnotations()
+			if annotations == nil {
+				annotations = make(map[string]string)
+			}
+			annotations[common.AnnotationOrphaned] = "true"
+			d.obj.SetAnnotations(annotations)
+			if err := d.update(); err != nil {
+				return false, err
+			}
+			continue
+		}
+		done = false
+		if d.obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := s.deleteDependent(d, c.Name, "is being deleted"); err != nil {
+			return false, err
+		}
+	}
+	return done, nil
+}
+
+// ownedBy reports whether the dependent belongs to the cluster, by its ownerReferences or by its labels
+// when it has not been adopted yet.
+func ownedBy(d dependent, c *v1.Cluster) bool {
+	if d.ownerKind != "Cluster" {
+		return false
+	}
+	if ref := v1.GetOwnerReference(d.obj, d.ownerKind); ref != nil {
+		return ref.UID == c.UID
+	}
+	return d.ownerName == c.Name && d.obj.GetAnnotations()[common.AnnotationOrphaned] == ""
+}
+
+// deleteDependent deletes the dependent of the owner which is gone or being deleted and records it.
+func (s *GarbageCollector) deleteDependent(d dependent, owner, state string) error {
+	if err := d.delete(); err != nil {
+		return ignoreNotFound(err)
+	}
+	if obj, ok := d.obj.(runtime.Object); ok && s.Recorder != nil {
+		s.Recorder.Eventf(obj, v1.ObjectEventNormal, v1.EventReasonGarbageCollected,
+			"%s %s deleted, its %s %s %s", strings.ToLower(d.kind), d.obj.GetName(), strings.ToLower(d.ownerKind), owner, state)
+	}
+	return nil
+}
+
+// owner returns the reference to the owner, nil if it does not exist, and whether it is being deleted.
+func (s *GarbageCollector) owner(kind, name string) (*metav1.OwnerReference, bool, error) {
+	if kind != "Cluster" {
+		return nil, false, nil
+	}
+	c, err := s.ClusterLister.Get(name)
+	if err != nil {
+		return nil, false, ignoreNotFound(err)
+	}
+	ref := v1.NewClusterOwnerReference(c)
+	return &ref, c.DeletionTimestamp != nil, nil
+}
+
+func ignoreNotFound(err error) error {
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
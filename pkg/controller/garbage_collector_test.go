@@ -0,0 +1,166 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	mock_cluster "github.com/kubeclipper/kubeclipper/pkg/models/cluster/mock"
+	"github.com/kubeclipper/kubeclipper/pkg/record"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestCollectDependent(t *testing.T) {
+	now := time.Now()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	live := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1", UID: "uid-1"}}
+	if err := indexer.Add(live); err != nil {
+		t.Fatal(err)
+	}
+	recorder := &record.FakeRecorder{}
+	s := &GarbageCollector{
+		ClusterLister: listerv1.NewClusterLister(indexer),
+		Recorder:      recorder,
+		log:           logger.WithName("garbage-collector"),
+		now:           func() time.Time { return now },
+	}
+	recreated := v1.NewClusterOwnerReference(&v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1", UID: "uid-0"}})
+
+	tests := []struct {
+		name       string
+		refs       []metav1.OwnerReference
+		ownerName  string
+		created    time.Time
+		wantDelete bool
+		wantAdopt  bool
+	}{
+		{name: "owner exists", refs: []metav1.OwnerReference{v1.NewClusterOwnerReference(live)}, ownerName: "c1"},
+		{name: "owner recreated", refs: []metav1.OwnerReference{recreated}, ownerName: "c1", wantDelete: true},
+		{name: "adopted by labels", ownerName: "c1", wantAdopt: true},
+		{name: "recent orphan", ownerName: "c2", created: now.Add(-time.Minute)},
+		{name: "orphan", ownerName: "c2", created: now.Add(-time.Hour), wantDelete: true},
+		{name: "no owner"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &v1.Operation{ObjectMeta: metav1.ObjectMeta{Name: "op", OwnerReferences: tt.refs,
+				CreationTimestamp: metav1.NewTime(tt.created)}}
+			recorder.Events = nil
+			var deleted, updated bool
+			err := s.collectDependent(dependent{
+				obj: o, kind: "Operation", ownerKind: "Cluster", ownerName: tt.ownerName,
+				update: func() error { updated = true; return nil },
+				delete: func() error { deleted = true; return nil },
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if deleted != tt.wantDelete || updated != tt.wantAdopt {
+				t.Errorf("deleted = %v, updated = %v, want %v, %v", deleted, updated, tt.wantDelete, tt.wantAdopt)
+			}
+			if deleted != (len(recorder.Events) == 1) {
+				t.Errorf("events = %v, want the deletion recorded", recorder.Events)
+			}
+			if tt.wantAdopt {
+				if ref := v1.GetOwnerReference(o, "Cluster"); ref == nil || ref.UID != live.UID {
+					t.Errorf("operation is not adopted by the cluster: %v", o.OwnerReferences)
+				}
+			}
+		})
+	}
+}
+
+func TestFinalizeOwners(t *testing.T) {
+	deleting := metav1.Now()
+	foreground := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "fg", UID: "uid-fg", DeletionTimestamp: &deleting,
+		Finalizers: []string{v1.ClusterFinalizer, metav1.FinalizerDeleteDependents}}}
+	orphan := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "or", UID: "uid-or", DeletionTimestamp: &deleting,
+		Finalizers: []string{metav1.FinalizerOrphanDependents}}}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, c := range []*v1.Cluster{foreground, orphan} {
+		if err := indexer.Add(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	finalizers := make(map[string][]string)
+	writer := mock_cluster.NewMockClusterWriter(gomock.NewController(t))
+	writer.EXPECT().UpdateCluster(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, c *v1.Cluster) (*v1.Cluster, error) {
+		finalizers[c.Name] = c.Finalizers
+		return c, nil
+	}).AnyTimes()
+	s := &GarbageCollector{
+		ClusterLister: listerv1.NewClusterLister(indexer),
+		ClusterWriter: writer,
+		log:           logger.WithName("garbage-collector"),
+		now:           time.Now,
+	}
+
+	deleted := make(map[string]bool)
+	updated := make(map[string]*v1.Operation)
+	newDependent := func(name, owner string, ref *metav1.OwnerReference) dependent {
+		o := &v1.Operation{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		if ref != nil {
+			o.OwnerReferences = []metav1.OwnerReference{*ref}
+		}
+		return dependent{
+			obj: o, kind: "Operation", ownerKind: "Cluster", ownerName: owner,
+			update: func() error { updated[name] = o; return nil },
+			delete: func() error { deleted[name] = true; return nil },
+		}
+	}
+	fgRef, orRef := v1.NewClusterOwnerReference(foreground), v1.NewClusterOwnerReference(orphan)
+	deps := []dependent{
+		newDependent("fg-op", "fg", &fgRef),
+		newDependent("or-op", "or", &orRef),
+		newDependent("or-unadopted", "or", nil),
+	}
+
+	s.finalizeOwners(deps)
+	if !deleted["fg-op"] || len(deleted) != 1 {
+		t.Errorf("deleted = %v, want only the dependent of the foreground deletion", deleted)
+	}
+	if _, ok := finalizers["fg"]; ok {
+		t.Error("foreground finalizer removed before the dependents are gone")
+	}
+	for _, name := range []string{"or-op", "or-unadopted"} {
+		o := updated[name]
+		if o == nil || len(o.OwnerReferences) != 0 || o.Annotations[common.AnnotationOrphaned] == "" {
+			t.Errorf("%s is not orphaned: %+v", name, o)
+		}
+	}
+	if got := finalizers["or"]; len(got) != 0 {
+		t.Errorf("orphan cluster finalizers = %v, want none", got)
+	}
+
+	// the dependent is gone in the next period
+	s.finalizeOwners(deps[1:])
+	if got := finalizers["fg"]; !reflect.DeepEqual(got, []string{v1.ClusterFinalizer}) {
+		t.Errorf("foreground cluster finalizers = %v, want %v", got, []string{v1.ClusterFinalizer})
+	}
+}
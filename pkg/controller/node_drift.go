@@ -0,0 +1,313 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	nodeDriftMonitorPeriod = 2 * time.Minute
+	eventReasonReverted    = "NodeDriftReverted"
+	eventReasonImported    = "NodeDriftImported"
+)
+
+// NodeDriftMon finds labels and taints changed directly on the kubernetes nodes
+// of a cluster and handles them according to the cluster NodeReconcileMode.
+type NodeDriftMon struct {
+	ClusterWriter cluster.ClusterWriter
+	ClusterLister listerv1.ClusterLister
+	NodeLister    listerv1.NodeLister
+	mgr           manager.Manager
+	log           logger.Logging
+}
+
+func (s *NodeDriftMon) SetupWithManager(mgr manager.Manager) {
+	s.mgr = mgr
+	s.log = mgr.GetLogger().WithName("node-drift-monitor")
+	mgr.AddWorkerLoop(s.monitorNodeDrift, nodeDriftMonitorPeriod)
+}
+
+func (s *NodeDriftMon) monitorNodeDrift() {
+	clusters, err := s.ClusterLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list clusters failed, check node drift next period", zap.Error(err))
+		return
+	}
+	for _, clu := range clusters {
+		if clu.NodeReconcileMode != v1.NodeReconcileEnforce && clu.NodeReconcileMode != v1.NodeReconcileImport {
+			continue
+		}
+		// nodes are expected to change while an operation runs
+		if clu.Status.Status != v1.ClusterStatusRunning {
+			continue
+		}
+		cc, exist := s.mgr.GetClusterClientSet(clu.Name)
+		if !exist {
+			s.log.Debug("clientset not exist, clientset may have not been finished", zap.String("cluster", clu.Name))
+			continue
+		}
+		s.reconcileCluster(clu.DeepCopy(), cc.Kubernetes())
+	}
+}
+
+func (s *NodeDriftMon) reconcileCluster(clu *v1.Cluster, clientset kubernetes.Interface) {
+	imported := false
+	for _, nodes := range []v1.WorkerNodeList{clu.Kubeadm.Masters, clu.Kubeadm.Workers} {
+		for i := range nodes {
+			changed, err := s.reconcileNode(clu, &nodes[i], clientset)
+			if err != nil {
+				s.log.Warn("reconcile node drift failed", zap.String("cluster", clu.Name), zap.String("node", nodes[i].ID), zap.Error(err))
+				continue
+			}
+			imported = imported || changed
+		}
+	}
+	if !imported {
+		return
+	}
+	if _, err := s.ClusterWriter.UpdateCluster(context.TODO(), clu); err != nil {
+		s.log.Warn("update cluster with imported node labels and taints failed", zap.String("cluster", clu.Name), zap.Error(err))
+	}
+}
+
+// reconcileNode returns true when the declared node has been changed and the cluster must be saved.
+func (s *NodeDriftMon) reconcileNode(clu *v1.Cluster, declared *v1.WorkerNode, clientset kubernetes.Interface) (bool, error) {
+	node, err := s.NodeLister.Get(declared.ID)
+	if err != nil {
+		return false, err
+	}
+	hostname := node.Labels[common.LabelHostname]
+	if hostname == "" {
+		return false, fmt.Errorf("node %s has no hostname label", node.Name)
+	}
+	k8sNode, err := clientset.CoreV1().Nodes().Get(context.TODO(), hostname, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	// the labels and taints of the node pool are declared for its workers as well
+	want := clu.Kubeadm.EffectiveWorker(*declared)
+	conflicts := append(labelConflicts(want.Labels, k8sNode.Labels), taintConflicts(want.Taints, k8sNode.Spec.Taints)...)
+	if len(conflicts) == 0 {
+		return false, nil
+	}
+	msg := strings.Join(conflicts, ", ")
+
+	if clu.NodeReconcileMode == v1.NodeReconcileImport {
+		declared.Labels = importLabels(declared.Labels, k8sNode.Labels)
+		declared.Taints = importTaints(declared.Taints, k8sNode.Spec.Taints)
+		s.log.Info("import node drift", zap.String("cluster", clu.Name), zap.String("node", hostname), zap.String("conflicts", msg))
+		s.recordEvent(clientset, k8sNode, eventReasonImported, "imported into kubeclipper cluster spec: "+msg)
+		return true, nil
+	}
+
+	k8sNode.Labels = enforceLabels(want.Labels, k8sNode.Labels)
+	k8sNode.Spec.Taints = enforceTaints(want.Taints, k8sNode.Spec.Taints)
+	if _, err = clientset.CoreV1().Nodes().Update(context.TODO(), k8sNode, metav1.UpdateOptions{}); err != nil {
+		return false, err
+	}
+	s.log.Info("revert node drift", zap.String("cluster", clu.Name), zap.String("node", hostname), zap.String("conflicts", msg))
+	s.recordEvent(clientset, k8sNode, eventReasonReverted, "reverted to kubeclipper cluster spec: "+msg)
+	return false, nil
+}
+
+// recordEvent leaves a warning event on the kubernetes node, it shows up in kubectl describe node.
+func (s *NodeDriftMon) recordEvent(clientset kubernetes.Interface, node *corev1.Node, reason, message string) {
+	now := metav1.Now()
+	ev := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", node.Name, now.UnixNano()),
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "Node",
+			APIVersion: "v1",
+			Name:       node.Name,
+			UID:        node.UID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           corev1.EventTypeWarning,
+		Source:         corev1.EventSource{Component: "kubeclipper-server"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := clientset.CoreV1().Events(metav1.NamespaceDefault).Create(context.TODO(), ev, metav1.CreateOptions{}); err != nil {
+		s.log.Warn("create node drift event failed", zap.String("node", node.Name), zap.Error(err))
+	}
+}
+
+// isSystemKey reports whether a label or taint key belongs to kubernetes or kubeclipper,
+// such keys are maintained by their owners and never take part in drift detection.
+func isSystemKey(key string) bool {
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	prefix := key[:i]
+	for _, domain := range []string{"kubernetes.io", "k8s.io", "kubeclipper.io"} {
+		if prefix == domain || strings.HasSuffix(prefix, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func userLabels(actual map[string]string) map[string]string {
+	out := make(map[string]string)
+	for k, v := range actual {
+		if !isSystemKey(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func userTaints(actual []corev1.Taint) []v1.Taint {
+	var out []v1.Taint
+	for _, t := range actual {
+		if !isSystemKey(t.Key) {
+			out = append(out, v1.Taint{Key: t.Key, Value: t.Value, Effect: v1.TaintEffect(t.Effect)})
+		}
+	}
+	return out
+}
+
+// labelConflicts lists the user labels whose value on the node differs from the declared one.
+func labelConflicts(declared, actual map[string]string) []string {
+	var conflicts []string
+	for k, v := range userLabels(declared) {
+		if av, ok := actual[k]; !ok {
+			conflicts = append(conflicts, fmt.Sprintf("label %s removed", k))
+		} else if av != v {
+			conflicts = append(conflicts, fmt.Sprintf("label %s changed from %q to %q", k, v, av))
+		}
+	}
+	for k := range userLabels(actual) {
+		if _, ok := declared[k]; !ok {
+			conflicts = append(conflicts, fmt.Sprintf("label %s added", k))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+func taintString(key, value string, effect string) string {
+	if value == "" {
+		return fmt.Sprintf("%s:%s", key, effect)
+	}
+	return fmt.Sprintf("%s=%s:%s", key, value, effect)
+}
+
+// taintConflicts lists the user taints that only exist on one side.
+func taintConflicts(declared []v1.Taint, actual []corev1.Taint) []string {
+	want := make(map[string]struct{}, len(declared))
+	for _, t := range declared {
+		if isSystemKey(t.Key) {
+			continue
+		}
+		want[taintString(t.Key, t.Value, string(t.Effect))] = struct{}{}
+	}
+	have := make(map[string]struct{})
+	for _, t := range userTaints(actual) {
+		have[taintString(t.Key, t.Value, string(t.Effect))] = struct{}{}
+	}
+	var conflicts []string
+	for t := range want {
+		if _, ok := have[t]; !ok {
+			conflicts = append(conflicts, fmt.Sprintf("taint %s removed", t))
+		}
+	}
+	for t := range have {
+		if _, ok := want[t]; !ok {
+			conflicts = append(conflicts, fmt.Sprintf("taint %s added", t))
+		}
+	}
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// importLabels keeps the system labels declared in the spec and takes the user labels from the node.
+func importLabels(declared, actual map[string]string) map[string]string {
+	out := userLabels(actual)
+	for k, v := range declared {
+		if isSystemKey(k) {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func importTaints(declared []v1.Taint, actual []corev1.Taint) []v1.Taint {
+	var out []v1.Taint
+	for _, t := range declared {
+		if isSystemKey(t.Key) {
+			out = append(out, t)
+		}
+	}
+	return append(out, userTaints(actual)...)
+}
+
+// enforceLabels keeps the system labels of the node and replaces the rest with the declared ones.
+func enforceLabels(declared, actual map[string]string) map[string]string {
+	out := make(map[string]string, len(actual))
+	for k, v := range actual {
+		if isSystemKey(k) {
+			out[k] = v
+		}
+	}
+	for k, v := range userLabels(declared) {
+		out[k] = v
+	}
+	return out
+}
+
+func enforceTaints(declared []v1.Taint, actual []corev1.Taint) []corev1.Taint {
+	var out []corev1.Taint
+	for _, t := range actual {
+		if isSystemKey(t.Key) {
+			out = append(out, t)
+		}
+	}
+	for _, t := range declared {
+		if isSystemKey(t.Key) {
+			continue
+		}
+		out = append(out, corev1.Taint{Key: t.Key, Value: t.Value, Effect: corev1.TaintEffect(t.Effect)})
+	}
+	return out
+}
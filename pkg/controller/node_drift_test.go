@@ -0,0 +1,119 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestIsSystemKey(t *testing.T) {
+	tests := map[string]bool{
+		"kubernetes.io/hostname":                true,
+		"node-role.kubernetes.io/control-plane": true,
+		"node.k8s.io/instance-type":             true,
+		"kubeclipper.io/cluster":                true,
+		"disktype":                              false,
+		"example.com/zone":                      false,
+		"fakekubernetes.io/x":                   false,
+	}
+	for key, want := range tests {
+		if got := isSystemKey(key); got != want {
+			t.Errorf("isSystemKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestLabelConflicts(t *testing.T) {
+	declared := map[string]string{"disktype": "ssd", "zone": "a"}
+	actual := map[string]string{
+		"kubernetes.io/hostname": "node1",
+		"disktype":               "hdd",
+		"team":                   "infra",
+	}
+	want := []string{
+		`label disktype changed from "ssd" to "hdd"`,
+		"label team added",
+		"label zone removed",
+	}
+	if got := labelConflicts(declared, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("labelConflicts() = %v, want %v", got, want)
+	}
+	if got := labelConflicts(nil, map[string]string{"kubernetes.io/os": "linux"}); len(got) != 0 {
+		t.Errorf("system labels must not conflict, got %v", got)
+	}
+}
+
+func TestTaintConflictsAndEnforce(t *testing.T) {
+	declared := []v1.Taint{
+		{Key: "node-role.kubernetes.io/master", Effect: v1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+	}
+	actual := []corev1.Taint{
+		{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "maintenance", Effect: corev1.TaintEffectNoExecute},
+	}
+	want := []string{"taint dedicated=gpu:NoSchedule removed", "taint maintenance:NoExecute added"}
+	if got := taintConflicts(declared, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("taintConflicts() = %v, want %v", got, want)
+	}
+
+	enforced := enforceTaints(declared, actual)
+	wantEnforced := []corev1.Taint{
+		{Key: "node-role.kubernetes.io/master", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+	}
+	if !reflect.DeepEqual(enforced, wantEnforced) {
+		t.Errorf("enforceTaints() = %v, want %v", enforced, wantEnforced)
+	}
+	if got := taintConflicts(declared, enforced); len(got) != 0 {
+		t.Errorf("enforced taints still conflict: %v", got)
+	}
+}
+
+func TestEnforceLabels(t *testing.T) {
+	got := enforceLabels(map[string]string{"disktype": "ssd"}, map[string]string{
+		"kubernetes.io/hostname": "node1",
+		"disktype":               "hdd",
+		"team":                   "infra",
+	})
+	want := map[string]string{"kubernetes.io/hostname": "node1", "disktype": "ssd"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("enforceLabels() = %v, want %v", got, want)
+	}
+}
+
+func TestImportTaints(t *testing.T) {
+	declared := []v1.Taint{
+		{Key: "node-role.kubernetes.io/master", Effect: v1.TaintEffectNoSchedule},
+		{Key: "dedicated", Value: "gpu", Effect: v1.TaintEffectNoSchedule},
+	}
+	actual := []corev1.Taint{{Key: "maintenance", Effect: corev1.TaintEffectNoExecute}}
+	want := []v1.Taint{
+		{Key: "node-role.kubernetes.io/master", Effect: v1.TaintEffectNoSchedule},
+		{Key: "maintenance", Effect: v1.TaintEffectNoExecute},
+	}
+	if got := importTaints(declared, actual); !reflect.DeepEqual(got, want) {
+		t.Errorf("importTaints() = %v, want %v", got, want)
+	}
+}
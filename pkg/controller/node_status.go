@@ -35,6 +35,7 @@ import (
 
 	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/record"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
 
@@ -103,6 +104,8 @@ type NodeStatusMon struct {
 	log logger.Logging
 
 	NodeWriter cluster.NodeWriter
+	// Recorder records the nodes which stop posting their status, when it is set.
+	Recorder record.Recorder
 }
 
 func (s *NodeStatusMon) mon() {
@@ -256,6 +259,10 @@ func (s *NodeStatusMon) tryUpdateNodeHealth(ctx context.Context, node *v1.Node)
 				s.log.Debugf("Error updating node %s: %v", node.Name, err)
 				return gracePeriod, observedReadyCondition, currentReadyCondition, err
 			}
+			if s.Recorder != nil && observedReadyCondition.Status == v1.ConditionTrue {
+				s.Recorder.Eventf(node, v1.ObjectEventWarning, v1.EventReasonNodeHeartbeatLost,
+					"kc-agent stopped posting node status for %s", s.now().Sub(nodeHealth.probeTimestamp.Time).Round(time.Second))
+			}
 			nodeHealth = &nodeHealthData{
 				status:                   &node.Status,
 				probeTimestamp:           nodeHealth.probeTimestamp,
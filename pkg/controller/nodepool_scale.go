@@ -0,0 +1,191 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/nodeprovider"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
+)
+
+const nodePoolScaleMonitorPeriod = 30 * time.Second
+
+// NodePoolPatcher starts the operation adding the nodes to, or removing them from, a node pool of a cluster,
+// operation is either add or remove.
+type NodePoolPatcher interface {
+	PatchNodePool(ctx context.Context, cluster, pool, operation string, nodes []string) error
+}
+
+// NodePoolScaleMon scales the node pools with a worker count. It joins the nodes supplied
+// by the provider of a pool until the pool has the desired workers, and removes the surplus
+// nodes when the count decreases. A cluster runs one operation at a time, so at most one
+// pool of a cluster is scaled every period.
+type NodePoolScaleMon struct {
+	ClusterLister listerv1.ClusterLister
+	NodeLister    listerv1.NodeLister
+	Providers     *nodeprovider.Registry
+	Patcher       NodePoolPatcher
+	log           logger.Logging
+}
+
+func (s *NodePoolScaleMon) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("nodepool-scale-monitor")
+	mgr.AddWorkerLoop(s.monitorNodePools, nodePoolScaleMonitorPeriod)
+}
+
+func (s *NodePoolScaleMon) monitorNodePools() {
+	clusters, err := s.ClusterLister.List(labels.Everything())
+	if err != nil {
+		s.log.Error("list clusters failed, scale node pools next period", zap.Error(err))
+		return
+	}
+	free, err := s.NodeLister.ListFreeNodes()
+	if err != nil {
+		s.log.Error("list free nodes failed, scale node pools next period", zap.Error(err))
+		return
+	}
+	used := sets.NewString()
+	for _, clu := range clusters {
+		if clu.Kubeadm != nil {
+			used.Insert(clu.Kubeadm.Masters.GetNodeIDs()...)
+			used.Insert(clu.Kubeadm.Workers.GetNodeIDs()...)
+		}
+	}
+	for _, clu := range clusters {
+		if clu.Kubeadm == nil || clu.Status.Status != v1.ClusterStatusRunning || !clu.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for i := range clu.Kubeadm.NodePools {
+			pool := &clu.Kubeadm.NodePools[i]
+			if pool.WorkerCount == nil {
+				continue
+			}
+			started, err := s.scaleNodePool(clu, pool, s.poolNodes(clu, free), used)
+			if err != nil {
+				s.log.Warn("scale node pool failed, retry next period", zap.String("cluster", clu.Name),
+					zap.String("pool", pool.Name), zap.Error(err))
+				continue
+			}
+			if started {
+				break
+			}
+		}
+	}
+}
+
+// scaleNodePool returns true when an operation of the pool has been started.
+func (s *NodePoolScaleMon) scaleNodePool(clu *v1.Cluster, pool *v1.NodePool, nodes []*v1.Node, used sets.String) (bool, error) {
+	provider, err := s.Providers.Get(pool.ProviderName())
+	if err != nil {
+		return false, err
+	}
+	req := newScaleRequest(clu, pool, nodes, used)
+	ctx := context.TODO()
+	switch current := len(req.Members); {
+	case current > req.Desired:
+		surplus, err := provider.Surplus(ctx, req, current-req.Desired)
+		if err != nil {
+			return false, err
+		}
+		if len(surplus) == 0 {
+			return false, nil
+		}
+		s.log.Info("scale down node pool", zap.String("cluster", clu.Name), zap.String("pool", pool.Name),
+			zap.Int("workers", current), zap.Int("desired", req.Desired), zap.Strings("nodes", surplus))
+		return true, s.Patcher.PatchNodePool(ctx, clu.Name, pool.Name, "remove", surplus)
+	default:
+		// providers creating machines are told the count even if the pool is complete,
+		// the machines of the removed nodes are released then
+		candidates, err := provider.Provision(ctx, req)
+		if err != nil {
+			return false, err
+		}
+		need := req.Desired - current
+		if need <= 0 || len(candidates) == 0 {
+			return false, nil
+		}
+		if len(candidates) > need {
+			candidates = candidates[:need]
+		}
+		s.log.Info("scale up node pool", zap.String("cluster", clu.Name), zap.String("pool", pool.Name),
+			zap.Int("workers", current), zap.Int("desired", req.Desired), zap.Strings("nodes", candidates))
+		return true, s.Patcher.PatchNodePool(ctx, clu.Name, pool.Name, "add", candidates)
+	}
+}
+
+// poolNodes returns the workers of the cluster together with the free nodes, newScaleRequest tells
+// the members of a pool and the nodes which may join it apart.
+func (s *NodePoolScaleMon) poolNodes(clu *v1.Cluster, free []*v1.Node) []*v1.Node {
+	nodes := append([]*v1.Node(nil), free...)
+	for _, id := range clu.Kubeadm.Workers.GetNodeIDs() {
+		if n, err := s.NodeLister.Get(id); err == nil {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+// newScaleRequest collects the members of the pool and the free nodes of the region of the cluster.
+func newScaleRequest(clu *v1.Cluster, pool *v1.NodePool, nodes []*v1.Node, used sets.String) *nodeprovider.Request {
+	req := &nodeprovider.Request{
+		Cluster: clu.Name,
+		Region:  clu.Labels[common.LabelTopologyRegion],
+		Pool:    pool,
+		Desired: *pool.WorkerCount,
+	}
+	members := sets.NewString(clu.Kubeadm.PoolWorkers(pool.Name).GetNodeIDs()...)
+	for _, n := range nodes {
+		switch {
+		case members.Has(n.Name):
+			req.Members = append(req.Members, n)
+		case isFreeNode(n, req.Region, used):
+			req.Free = append(req.Free, n)
+		}
+	}
+	return req
+}
+
+// isFreeNode reports whether the node may join a cluster of the region.
+func isFreeNode(n *v1.Node, region string, used sets.String) bool {
+	if used.Has(n.Name) || n.Labels[common.LabelTopologyRegion] != region {
+		return false
+	}
+	if _, ok := n.Labels[common.LabelNodeDisable]; ok {
+		return false
+	}
+	if _, ok := n.Labels[common.LabelClusterName]; ok {
+		return false
+	}
+	if n.Status.NodeInfo.OSFamily == string(osutil.FamilyWindows) {
+		return false
+	}
+	_, cond := GetNodeCondition(&n.Status, v1.NodeReady)
+	return cond != nil && cond.Status == v1.ConditionTrue
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/nodeprovider"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+type fakePatcher struct {
+	operation string
+	nodes     []string
+}
+
+func (f *fakePatcher) PatchNodePool(ctx context.Context, cluster, pool, operation string, nodes []string) error {
+	f.operation, f.nodes = operation, nodes
+	return nil
+}
+
+func newPoolNode(name, region string, ready bool) *v1.Node {
+	n := &v1.Node{}
+	n.Name = name
+	n.Labels = map[string]string{common.LabelTopologyRegion: region}
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+	n.Status.Conditions = []v1.NodeCondition{{Type: v1.NodeReady, Status: status}}
+	return n
+}
+
+func TestScaleNodePool(t *testing.T) {
+	providers, err := nodeprovider.NewRegistry(nodeprovider.NewOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	used := newPoolNode("used", "r1", true)
+	nodes := []*v1.Node{
+		newPoolNode("w1", "r1", true),
+		newPoolNode("n1", "r1", true),
+		newPoolNode("n2", "r1", true),
+		newPoolNode("n3", "r1", false),
+		newPoolNode("n4", "r2", true),
+		used,
+	}
+	newCluster := func(count int) *v1.Cluster {
+		clu := &v1.Cluster{Kubeadm: &v1.Kubeadm{
+			Workers:   v1.WorkerNodeList{{ID: "w1", Pool: "pool"}},
+			NodePools: []v1.NodePool{{Name: "pool", WorkerCount: &count}},
+		}}
+		clu.Name = "c1"
+		clu.Labels = map[string]string{common.LabelTopologyRegion: "r1"}
+		return clu
+	}
+	tests := []struct {
+		name      string
+		count     int
+		started   bool
+		operation string
+		nodes     []string
+	}{
+		{name: "scale up", count: 3, started: true, operation: "add", nodes: []string{"n1", "n2"}},
+		{name: "scale up limited", count: 2, started: true, operation: "add", nodes: []string{"n1"}},
+		{name: "complete", count: 1},
+		{name: "scale down", count: 0, started: true, operation: "remove", nodes: []string{"w1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patcher := &fakePatcher{}
+			s := &NodePoolScaleMon{Providers: providers, Patcher: patcher, log: logger.WithName("nodepool-scale-monitor")}
+			clu := newCluster(tt.count)
+			started, err := s.scaleNodePool(clu, &clu.Kubeadm.NodePools[0], nodes, sets.NewString("w1", "used"))
+			if err != nil {
+				t.Fatalf("scaleNodePool() error = %v", err)
+			}
+			if started != tt.started || patcher.operation != tt.operation || !reflect.DeepEqual(patcher.nodes, tt.nodes) {
+				t.Errorf("scaleNodePool() = %v, %s %v, want %v, %s %v", started, patcher.operation, patcher.nodes,
+					tt.started, tt.operation, tt.nodes)
+			}
+		})
+	}
+}
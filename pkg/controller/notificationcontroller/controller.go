@@ -0,0 +1,267 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package notificationcontroller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/kubeclipper/kubeclipper/pkg/client/informers"
+	ctrl "github.com/kubeclipper/kubeclipper/pkg/controller-runtime"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/controller"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/event"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/handler"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/reconcile"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/source"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	"github.com/kubeclipper/kubeclipper/pkg/notification"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	retryPeriod = 10 * time.Second
+	// historyLimit is the number of delivered or failed notifications kept.
+	historyLimit = 1000
+)
+
+// NotificationReconciler turns the transitions of operations, clusters, backups and
+// nodes into notifications, one for every matching notifier, and delivers them.
+// The informer handlers only queue the events, a transition seen while the
+// controller is not running is not notified.
+type NotificationReconciler struct {
+	PlatformOperator platform.Operator
+	// Send delivers one notification, notification.Send when nil.
+	Send func(ctx context.Context, n *v1.Notifier, e *v1.NotificationEvent) error
+
+	events sync.Map
+	log    logger.Logging
+}
+
+func (r *NotificationReconciler) SetupWithManager(mgr manager.Manager, cache informers.InformerCache) error {
+	if r.Send == nil {
+		r.Send = notification.Send
+	}
+	r.log = mgr.GetLogger().WithName("notification-controller")
+	c, err := controller.NewUnmanaged("notification", controller.Options{
+		MaxConcurrentReconciles: 2,
+		Reconciler:              r,
+		Log:                     r.log,
+		RecoverPanic:            true,
+	})
+	if err != nil {
+		return err
+	}
+	watches := []struct {
+		obj    client.Object
+		detect func(old, new client.Object) *v1.NotificationEvent
+	}{
+		{&v1.Operation{}, func(old, new client.Object) *v1.NotificationEvent {
+			return operationEvent(old.(*v1.Operation), new.(*v1.Operation))
+		}},
+		{&v1.Cluster{}, func(old, new client.Object) *v1.NotificationEvent {
+			if e := clusterEvent(old.(*v1.Cluster), new.(*v1.Cluster)); e != nil {
+				return e
+			}
+			return certificateEvent(old.(*v1.Cluster), new.(*v1.Cluster))
+		}},
+		{&v1.Backup{}, func(old, new client.Object) *v1.NotificationEvent {
+			return backupEvent(old.(*v1.Backup), new.(*v1.Backup))
+		}},
+		{&v1.Node{}, func(old, new client.Object) *v1.NotificationEvent {
+			return nodeEvent(old.(*v1.Node), new.(*v1.Node))
+		}},
+	}
+	for _, w := range watches {
+		if err = c.Watch(source.NewKindWithCache(w.obj, cache), r.eventHandler(w.detect)); err != nil {
+			return err
+		}
+	}
+	mgr.AddRunnable(c)
+	mgr.AddWorkerLoop(r.retryPending, retryPeriod)
+	return nil
+}
+
+// eventHandler queues the event detected on an update under a unique key, the
+// reconciler picks it up by that key.
+func (r *NotificationReconciler) eventHandler(detect func(old, new client.Object) *v1.NotificationEvent) handler.EventHandler {
+	return handler.Funcs{
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			ev := detect(e.ObjectOld, e.ObjectNew)
+			if ev == nil {
+				return
+			}
+			key := uuid.New().String()
+			r.events.Store(key, ev)
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: key}})
+		},
+	}
+}
+
+func (r *NotificationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	value, ok := r.events.Load(req.Name)
+	if !ok {
+		return ctrl.Result{}, nil
+	}
+	ev := value.(*v1.NotificationEvent)
+	notifiers, err := r.PlatformOperator.ListNotifiers(ctx, query.New())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	r.events.Delete(req.Name)
+	for i := range notifiers.Items {
+		notifier := &notifiers.Items[i]
+		if !notifier.Match(ev) {
+			continue
+		}
+		n := &v1.Notification{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   uuid.New().String(),
+				Labels: map[string]string{common.LabelNotifier: notifier.Name},
+			},
+			Notifier: notifier.Name,
+			Event:    *ev,
+		}
+		if ev.Cluster != "" {
+			n.Labels[common.LabelClusterName] = ev.Cluster
+		}
+		setPhase(n, v1.NotificationPending)
+		if n, err = r.PlatformOperator.CreateNotification(ctx, n); err != nil {
+			r.log.Error("create notification failed", zap.String("notifier", notifier.Name),
+				zap.String("event", string(ev.Type)), zap.Error(err))
+			continue
+		}
+		r.deliver(ctx, n, notifier)
+	}
+	return ctrl.Result{}, nil
+}
+
+// retryPending delivers the pending notifications whose backoff has passed and prunes the history.
+func (r *NotificationReconciler) retryPending() {
+	ctx := context.TODO()
+	q := query.New()
+	q.LabelSelector = fmt.Sprintf("%s=%s", common.LabelNotificationPhase, v1.NotificationPending)
+	list, err := r.PlatformOperator.ListNotifications(ctx, q)
+	if err != nil {
+		r.log.Error("list pending notifications failed", zap.Error(err))
+		return
+	}
+	now := time.Now()
+	for i := range list.Items {
+		n := &list.Items[i]
+		if !n.Due(now) {
+			continue
+		}
+		notifier, err := r.PlatformOperator.GetNotifier(ctx, n.Notifier)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				r.log.Error("get notifier failed", zap.String("notifier", n.Notifier), zap.Error(err))
+				continue
+			}
+			n.Status.LastError = "notifier was deleted"
+			setPhase(n, v1.NotificationFailed)
+			r.update(ctx, n)
+			continue
+		}
+		r.deliver(ctx, n, notifier)
+	}
+	r.pruneHistory(ctx)
+}
+
+func (r *NotificationReconciler) deliver(ctx context.Context, n *v1.Notification, notifier *v1.Notifier) {
+	err := r.Send(ctx, notifier, &n.Event)
+	recordAttempt(n, notifier, err, time.Now())
+	if err != nil {
+		r.log.Warn("deliver notification failed", zap.String("notification", n.Name),
+			zap.String("notifier", notifier.Name), zap.Int("attempts", n.Status.Attempts), zap.Error(err))
+	}
+	r.update(ctx, n)
+}
+
+func (r *NotificationReconciler) update(ctx context.Context, n *v1.Notification) {
+	if _, err := r.PlatformOperator.UpdateNotification(ctx, n); err != nil {
+		r.log.Error("update notification failed", zap.String("notification", n.Name), zap.Error(err))
+	}
+}
+
+// recordAttempt updates the status with the result of a delivery, a failed
+// delivery stays pending until the retries of the notifier are used up.
+func recordAttempt(n *v1.Notification, notifier *v1.Notifier, err error, now time.Time) {
+	n.Status.Attempts++
+	attemptTime := metav1.NewTime(now)
+	n.Status.LastAttemptTime = &attemptTime
+	n.Status.NextAttemptTime = nil
+	if err == nil {
+		n.Status.LastError = ""
+		setPhase(n, v1.NotificationSucceeded)
+		return
+	}
+	n.Status.LastError = err.Error()
+	if n.Status.Attempts > notifier.Retry.RetryLimit() {
+		setPhase(n, v1.NotificationFailed)
+		return
+	}
+	next := metav1.NewTime(now.Add(notifier.Retry.RetryBackoff(n.Status.Attempts)))
+	n.Status.NextAttemptTime = &next
+	setPhase(n, v1.NotificationPending)
+}
+
+func setPhase(n *v1.Notification, phase v1.NotificationPhase) {
+	n.Status.Phase = phase
+	if n.Labels == nil {
+		n.Labels = map[string]string{}
+	}
+	n.Labels[common.LabelNotificationPhase] = string(phase)
+}
+
+// pruneHistory deletes the oldest finished notifications beyond historyLimit.
+func (r *NotificationReconciler) pruneHistory(ctx context.Context) {
+	q := query.New()
+	q.LabelSelector = fmt.Sprintf("%s in (%s,%s)", common.LabelNotificationPhase, v1.NotificationSucceeded, v1.NotificationFailed)
+	list, err := r.PlatformOperator.ListNotifications(ctx, q)
+	if err != nil {
+		r.log.Error("list notification history failed", zap.Error(err))
+		return
+	}
+	if len(list.Items) <= historyLimit {
+		return
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].CreationTimestamp.Before(&list.Items[j].CreationTimestamp)
+	})
+	for _, n := range list.Items[:len(list.Items)-historyLimit] {
+		if err := r.PlatformOperator.DeleteNotification(ctx, n.Name); err != nil && !errors.IsNotFound(err) {
+			r.log.Error("prune notification history failed", zap.String("notification", n.Name), zap.Error(err))
+		}
+	}
+}
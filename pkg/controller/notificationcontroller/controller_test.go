@@ -0,0 +1,137 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package notificationcontroller
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestOperationEvent(t *testing.T) {
+	old := &v1.Operation{Status: v1.OperationStatus{Status: v1.OperationStatusRunning}}
+	old.Name = "op-1"
+	old.Labels = map[string]string{common.LabelClusterName: "demo", common.LabelOperationAction: v1.OperationCreateCluster}
+	failed := old.DeepCopy()
+	failed.Status.Status = v1.OperationStatusFailed
+	failed.Status.Conditions = []v1.OperationCondition{{StepID: "init", Status: []v1.StepStatus{
+		{Node: "n1", Status: v1.StepStatusFailed, Message: "timeout"},
+	}}}
+
+	e := operationEvent(old, failed)
+	if e == nil {
+		t.Fatal("expected an event when the operation fails")
+	}
+	if e.Cluster != "demo" || e.Object != "op-1" || e.Message != "operation CreateCluster failed: step init on node n1: timeout" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if operationEvent(failed, failed.DeepCopy()) != nil {
+		t.Error("an already failed operation must not be notified again")
+	}
+}
+
+func TestNodeEvent(t *testing.T) {
+	ready := &v1.Node{Status: v1.NodeStatus{Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: v1.ConditionTrue}}}}
+	offline := ready.DeepCopy()
+	offline.Status.Conditions[0].Status = v1.ConditionUnknown
+	if nodeEvent(ready, offline) == nil {
+		t.Error("expected an event when a ready node goes offline")
+	}
+	if nodeEvent(offline, offline.DeepCopy()) != nil {
+		t.Error("an offline node must not be notified again")
+	}
+	if nodeEvent(offline, ready) != nil {
+		t.Error("a node coming back must not be notified")
+	}
+}
+
+func TestClusterEvent(t *testing.T) {
+	withStatus := func(s v1.ComponentStatus) *v1.Cluster {
+		c := &v1.Cluster{}
+		c.Name = "demo"
+		c.Status.ComponentConditions = []v1.ComponentConditions{{Name: "kubernetes", Status: s}}
+		return c
+	}
+	if clusterEvent(withStatus(v1.ComponentHealthy), withStatus(v1.ComponentUnhealthy)) == nil {
+		t.Error("expected an event when a healthy cluster turns unhealthy")
+	}
+	if clusterEvent(&v1.Cluster{}, withStatus(v1.ComponentUnKnown)) != nil {
+		t.Error("a cluster never healthy must not be notified")
+	}
+}
+
+func TestCertificateEvent(t *testing.T) {
+	expiration := metav1.NewTime(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC))
+	old := &v1.Cluster{}
+	old.Name = "demo"
+	old.Status.Certifications = []v1.Certification{
+		{Name: "apiserver.crt", ExpirationTime: expiration},
+		{Name: "ca.crt", ExpirationTime: metav1.NewTime(expiration.AddDate(9, 0, 0))},
+	}
+	expiring := old.DeepCopy()
+	expiring.Status.Certifications[0].Expiring = true
+
+	e := certificateEvent(old, expiring)
+	if e == nil {
+		t.Fatal("expected an event when a certificate starts expiring")
+	}
+	if e.Type != v1.NotificationCertificateExpiring ||
+		e.Message != "certificates apiserver.crt of cluster demo are about to expire, the first at 2026-11-01T00:00:00Z" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	if certificateEvent(expiring, expiring.DeepCopy()) != nil {
+		t.Error("an expiring certificate must not be notified again")
+	}
+	if certificateEvent(expiring, old) != nil {
+		t.Error("renewed certificates must not be notified")
+	}
+}
+
+func TestRecordAttempt(t *testing.T) {
+	notifier := &v1.Notifier{Retry: v1.NotifierRetry{Limit: 1, Backoff: metav1.Duration{Duration: time.Minute}}}
+	now := time.Unix(1000, 0)
+	n := &v1.Notification{}
+
+	recordAttempt(n, notifier, errors.New("connection refused"), now)
+	if n.Status.Phase != v1.NotificationPending || n.Labels[common.LabelNotificationPhase] != string(v1.NotificationPending) {
+		t.Fatalf("a failed delivery with retries left must stay pending, got %+v", n.Status)
+	}
+	if !n.Status.NextAttemptTime.Time.Equal(now.Add(time.Minute)) {
+		t.Errorf("next attempt = %v, want %v", n.Status.NextAttemptTime.Time, now.Add(time.Minute))
+	}
+	if n.Due(now) || !n.Due(now.Add(time.Minute)) {
+		t.Error("the notification must be due after the backoff only")
+	}
+
+	recordAttempt(n, notifier, errors.New("connection refused"), now.Add(time.Minute))
+	if n.Status.Phase != v1.NotificationFailed || n.Status.Attempts != 2 {
+		t.Errorf("the notification must fail once the retries are used up, got %+v", n.Status)
+	}
+
+	n = &v1.Notification{}
+	recordAttempt(n, notifier, nil, now)
+	if n.Status.Phase != v1.NotificationSucceeded || n.Status.NextAttemptTime != nil {
+		t.Errorf("unexpected status after a successful delivery %+v", n.Status)
+	}
+}
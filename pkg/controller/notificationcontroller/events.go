@@ -0,0 +1,147 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package notificationcontroller
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func operationEvent(old, new *v1.Operation) *v1.NotificationEvent {
+	if old.Status.Status == v1.OperationStatusFailed || new.Status.Status != v1.OperationStatusFailed {
+		return nil
+	}
+	msg := fmt.Sprintf("operation %s failed", new.Labels[common.LabelOperationAction])
+	if reason := failedStepMessage(new); reason != "" {
+		msg = fmt.Sprintf("%s: %s", msg, reason)
+	}
+	return &v1.NotificationEvent{
+		Type:    v1.NotificationOperationFailed,
+		Cluster: new.Labels[common.LabelClusterName],
+		Object:  new.Name,
+		Message: msg,
+		Time:    metav1.Now(),
+	}
+}
+
+// failedStepMessage returns the message of the last failed step of the operation.
+func failedStepMessage(op *v1.Operation) string {
+	for i := len(op.Status.Conditions) - 1; i >= 0; i-- {
+		for _, s := range op.Status.Conditions[i].Status {
+			if s.Status == v1.StepStatusFailed {
+				return fmt.Sprintf("step %s on node %s: %s", op.Status.Conditions[i].StepID, s.Node, s.Message)
+			}
+		}
+	}
+	return ""
+}
+
+// clusterEvent is sent when the kubernetes component of a healthy cluster turns
+// unhealthy or unknown, clusters which were never healthy are not reported.
+func clusterEvent(old, new *v1.Cluster) *v1.NotificationEvent {
+	before, after := kubernetesStatus(old), kubernetesStatus(new)
+	if before != v1.ComponentHealthy || (after != v1.ComponentUnhealthy && after != v1.ComponentUnKnown) {
+		return nil
+	}
+	return &v1.NotificationEvent{
+		Type:    v1.NotificationClusterUnhealthy,
+		Cluster: new.Name,
+		Object:  new.Name,
+		Message: fmt.Sprintf("kubernetes of cluster %s is %s", new.Name, after),
+		Time:    metav1.Now(),
+	}
+}
+
+// certificateEvent is sent when certificates of a cluster start expiring within the threshold
+// of the certificate monitor, each certificate is reported once until it is renewed.
+func certificateEvent(old, new *v1.Cluster) *v1.NotificationEvent {
+	expiring := sets.NewString(new.Status.ExpiringCertifications()...).
+		Difference(sets.NewString(old.Status.ExpiringCertifications()...))
+	if expiring.Len() == 0 {
+		return nil
+	}
+	var first metav1.Time
+	for _, c := range new.Status.Certifications {
+		if expiring.Has(c.Name) && (first.IsZero() || c.ExpirationTime.Before(&first)) {
+			first = c.ExpirationTime
+		}
+	}
+	return &v1.NotificationEvent{
+		Type:    v1.NotificationCertificateExpiring,
+		Cluster: new.Name,
+		Object:  new.Name,
+		Message: fmt.Sprintf("certificates %s of cluster %s are about to expire, the first at %s",
+			strings.Join(expiring.List(), ","), new.Name, first.UTC().Format(time.RFC3339)),
+		Time: metav1.Now(),
+	}
+}
+
+func kubernetesStatus(c *v1.Cluster) v1.ComponentStatus {
+	for _, cond := range c.Status.ComponentConditions {
+		if cond.Name == "kubernetes" {
+			return cond.Status
+		}
+	}
+	return ""
+}
+
+func backupEvent(old, new *v1.Backup) *v1.NotificationEvent {
+	if old.Status.ClusterBackupStatus == v1.ClusterBackupError || new.Status.ClusterBackupStatus != v1.ClusterBackupError {
+		return nil
+	}
+	cluster := new.Labels[common.LabelClusterName]
+	return &v1.NotificationEvent{
+		Type:    v1.NotificationBackupFailed,
+		Cluster: cluster,
+		Object:  new.Name,
+		Message: fmt.Sprintf("backup %s of cluster %s failed", new.Name, cluster),
+		Time:    metav1.Now(),
+	}
+}
+
+// nodeEvent is sent when a ready node stops being ready, which happens when its
+// agent misses the status updates.
+func nodeEvent(old, new *v1.Node) *v1.NotificationEvent {
+	if nodeReady(old) != v1.ConditionTrue || nodeReady(new) == v1.ConditionTrue {
+		return nil
+	}
+	return &v1.NotificationEvent{
+		Type:    v1.NotificationNodeOffline,
+		Cluster: new.Labels[common.LabelClusterName],
+		Object:  new.Name,
+		Message: fmt.Sprintf("node %s (%s) is offline", new.Labels[common.LabelHostname], new.Status.Ipv4DefaultIP),
+		Time:    metav1.Now(),
+	}
+}
+
+func nodeReady(node *v1.Node) v1.ConditionStatus {
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == v1.NodeReady {
+			return cond.Status
+		}
+	}
+	return ""
+}
@@ -61,6 +61,12 @@ func (r *OperationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	cluName := op.Labels[common.LabelClusterName]
 	if cluName == "" {
+		// prewarm operations run on free nodes and maintenance of the kubeclipper etcd
+		// runs on the server, they are not bound to a cluster
+		switch op.Labels[common.LabelOperationAction] {
+		case v1.OperationPrewarmNodes, v1.OperationEtcdMaintenance:
+			return ctrl.Result{}, nil
+		}
 		// TODO: throw a error here ?
 		log.Warn("unexpected error, operation should always has a cluster name label",
 			zap.String("operation", req.Name))
@@ -0,0 +1,112 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package controller
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const recycleBinMonitorPeriod = time.Minute
+
+// RecycleBinPurger really deletes a cluster or a backup in the recycle bin.
+type RecycleBinPurger interface {
+	PurgeCluster(ctx context.Context, name string) error
+	PurgeBackup(ctx context.Context, cluster, name string) error
+}
+
+// RecycleBinMon purges the clusters and backups kept in the recycle bin longer than its retention.
+// The objects left in the recycle bin when it is disabled are still purged.
+type RecycleBinMon struct {
+	PlatformOperator platform.Operator
+	ClusterLister    listerv1.ClusterLister
+	BackupLister     listerv1.BackupLister
+	Purger           RecycleBinPurger
+	log              logger.Logging
+}
+
+func (s *RecycleBinMon) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("recycle-bin-monitor")
+	mgr.AddWorkerLoop(s.monitorRecycleBin, recycleBinMonitorPeriod)
+}
+
+func (s *RecycleBinMon) monitorRecycleBin() {
+	setting, err := s.PlatformOperator.GetPlatformSetting(context.TODO())
+	if err != nil {
+		s.log.Error("get platform setting failed, purge recycle bin next period", zap.Error(err))
+		return
+	}
+	s.purgeRecycleBin(setting.RecycleBin.RetentionPeriod(), time.Now())
+}
+
+func (s *RecycleBinMon) purgeRecycleBin(retention time.Duration, now time.Time) {
+	deleted, err := labels.NewRequirement(common.LabelDeleted, selection.Exists, nil)
+	if err != nil {
+		s.log.Error("build recycle bin selector failed", zap.Error(err))
+		return
+	}
+	selector := labels.NewSelector().Add(*deleted)
+	clusters, err := s.ClusterLister.List(selector)
+	if err != nil {
+		s.log.Error("list clusters failed, purge recycle bin next period", zap.Error(err))
+		return
+	}
+	backups, err := s.BackupLister.List(selector)
+	if err != nil {
+		s.log.Error("list backups failed, purge recycle bin next period", zap.Error(err))
+		return
+	}
+	// the backups go first, a cluster can't be deleted before its backups
+	for _, b := range backups {
+		if !purgeDue(b, retention, now) {
+			continue
+		}
+		cluster := b.Labels[common.LabelClusterName]
+		s.log.Info("purge backup from recycle bin", zap.String("cluster", cluster), zap.String("backup", b.Name))
+		if err = s.Purger.PurgeBackup(context.TODO(), cluster, b.Name); err != nil {
+			s.log.Warn("purge backup failed, retry next period", zap.String("backup", b.Name), zap.Error(err))
+		}
+	}
+	for _, c := range clusters {
+		if !purgeDue(c, retention, now) {
+			continue
+		}
+		s.log.Info("purge cluster from recycle bin", zap.String("cluster", c.Name))
+		if err = s.Purger.PurgeCluster(context.TODO(), c.Name); err != nil {
+			s.log.Warn("purge cluster failed, retry next period", zap.String("cluster", c.Name), zap.Error(err))
+		}
+	}
+}
+
+// purgeDue reports whether the object in the recycle bin is kept longer than the retention.
+func purgeDue(obj metav1.Object, retention time.Duration, now time.Time) bool {
+	at, ok := v1.PurgeTime(obj, retention)
+	return ok && !now.Before(at)
+}
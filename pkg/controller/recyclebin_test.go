@@ -0,0 +1,81 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+type fakePurger struct {
+	purged []string
+}
+
+func (p *fakePurger) PurgeCluster(ctx context.Context, name string) error {
+	p.purged = append(p.purged, "cluster/"+name)
+	return nil
+}
+
+func (p *fakePurger) PurgeBackup(ctx context.Context, cluster, name string) error {
+	p.purged = append(p.purged, "backup/"+cluster+"/"+name)
+	return nil
+}
+
+func TestPurgeRecycleBin(t *testing.T) {
+	now := time.Now()
+	clusters := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	backups := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	add := func(indexer cache.Indexer, obj metav1.Object, deletedAt *time.Time) {
+		if deletedAt != nil {
+			v1.MarkDeleted(obj, *deletedAt)
+		}
+		if err := indexer.Add(obj); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expired, recent := now.Add(-2*time.Hour), now.Add(-time.Minute)
+	add(clusters, &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c1"}}, &expired)
+	add(clusters, &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c2"}}, &recent)
+	add(clusters, &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c3"}}, nil)
+	add(backups, &v1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "b1",
+		Labels: map[string]string{common.LabelClusterName: "c1"}}}, &expired)
+	add(backups, &v1.Backup{ObjectMeta: metav1.ObjectMeta{Name: "b2",
+		Labels: map[string]string{common.LabelClusterName: "c3"}}}, nil)
+
+	purger := &fakePurger{}
+	s := &RecycleBinMon{
+		ClusterLister: listerv1.NewClusterLister(clusters),
+		BackupLister:  listerv1.NewBackupLister(backups),
+		Purger:        purger,
+		log:           logger.WithName("recycle-bin-monitor"),
+	}
+	s.purgeRecycleBin(time.Hour, now)
+	if want := []string{"backup/c1/b1", "cluster/c1"}; !reflect.DeepEqual(purger.purged, want) {
+		t.Errorf("expected purged %v, got %v", want, purger.purged)
+	}
+}
@@ -23,7 +23,6 @@ import (
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/selection"
 	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/client"
@@ -153,11 +152,7 @@ func (r *RegionReconciler) needDeleteRegions() []reconcile.Request {
 }
 
 func (r *RegionReconciler) needDelete(region string) bool {
-	requirement, err := labels.NewRequirement(common.LabelTopologyRegion, selection.Equals, []string{region})
-	if err != nil {
-		return false
-	}
-	list, err := r.NodeLister.List(labels.NewSelector().Add(*requirement))
+	list, err := r.NodeLister.ListByRegion(region)
 	if err != nil {
 		return errors.IsNotFound(err)
 	}
@@ -0,0 +1,167 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/distribution"
+)
+
+const (
+	registrySyncMonitorPeriod   = time.Minute
+	defaultRegistrySyncInterval = time.Hour
+)
+
+// RegistrySyncMon mirrors the images listed in the platform registry sync setting
+// into the embedded registry and reports the pushed digests in its status.
+type RegistrySyncMon struct {
+	PlatformOperator platform.Operator
+	log              logger.Logging
+}
+
+func (s *RegistrySyncMon) SetupWithManager(mgr manager.Manager) {
+	s.log = mgr.GetLogger().WithName("registry-sync-monitor")
+	mgr.AddWorkerLoop(s.monitorRegistrySync, registrySyncMonitorPeriod)
+}
+
+func (s *RegistrySyncMon) monitorRegistrySync() {
+	setting, err := s.PlatformOperator.GetPlatformSetting(context.TODO())
+	if err != nil {
+		s.log.Error("get platform setting failed, sync registry next period", zap.Error(err))
+		return
+	}
+	rs := setting.RegistrySync
+	if rs.Registry == "" || len(rs.Images) == 0 || !registrySyncDue(&rs, time.Now()) {
+		return
+	}
+
+	status := s.syncImages(&rs)
+
+	// re-read the setting, it may have been edited while images were copied
+	setting, err = s.PlatformOperator.GetPlatformSetting(context.TODO())
+	if err != nil {
+		s.log.Error("get platform setting failed, drop registry sync status", zap.Error(err))
+		return
+	}
+	setting.RegistrySync.Status = status
+	if _, err = s.PlatformOperator.UpdatePlatformSetting(context.TODO(), setting); err != nil {
+		s.log.Error("update registry sync status failed", zap.Error(err))
+	}
+}
+
+// registrySyncDue reports whether the interval passed or an image has never been synced.
+func registrySyncDue(rs *v1.RegistrySync, now time.Time) bool {
+	interval := rs.Interval.Duration
+	if interval <= 0 {
+		interval = defaultRegistrySyncInterval
+	}
+	if now.Sub(rs.Status.LastSyncTime.Time) >= interval {
+		return true
+	}
+	synced := make(map[string]bool, len(rs.Status.Images))
+	for _, st := range rs.Status.Images {
+		synced[st.Source+"|"+st.Target] = true
+	}
+	for _, img := range rs.Images {
+		if !synced[img.Source+"|"+registrySyncTarget(img)] {
+			return true
+		}
+	}
+	return false
+}
+
+// registrySyncTarget returns the repository:tag the image is pushed to.
+func registrySyncTarget(img v1.RegistrySyncImage) string {
+	if img.Target != "" {
+		return img.Target
+	}
+	src, err := distribution.ParseReference(img.Source)
+	if err != nil {
+		return ""
+	}
+	if src.Tag == "" {
+		return src.Repository + "@" + src.Digest
+	}
+	return src.Repository + ":" + src.Tag
+}
+
+func (s *RegistrySyncMon) syncImages(rs *v1.RegistrySync) v1.RegistrySyncStatus {
+	now := metav1.Now()
+	status := v1.RegistrySyncStatus{LastSyncTime: now}
+	previous := make(map[string]v1.RegistrySyncImageStatus, len(rs.Status.Images))
+	for _, st := range rs.Status.Images {
+		previous[st.Source+"|"+st.Target] = st
+	}
+
+	dstOpts := []distribution.Option{distribution.WithBasicAuth(rs.Username, rs.Password)}
+	if rs.Insecure {
+		dstOpts = append(dstOpts, distribution.WithInsecure())
+	}
+	dstClient, err := distribution.NewClient(dstOpts...)
+	if err != nil {
+		s.log.Error("create embedded registry client failed", zap.Error(err))
+		return rs.Status
+	}
+
+	for _, img := range rs.Images {
+		target := registrySyncTarget(img)
+		st := v1.RegistrySyncImageStatus{Source: img.Source, Target: target, LastSyncTime: now}
+		digest, err := s.syncImage(rs, img, target, dstClient)
+		if err != nil {
+			s.log.Warn("sync image to embedded registry failed", zap.String("source", img.Source), zap.Error(err))
+			// keep the digest that is still served by the embedded registry
+			st.Digest = previous[img.Source+"|"+target].Digest
+			st.Message = err.Error()
+		} else {
+			st.Digest = digest
+			st.Synced = true
+		}
+		status.Images = append(status.Images, st)
+	}
+	return status
+}
+
+func (s *RegistrySyncMon) syncImage(rs *v1.RegistrySync, img v1.RegistrySyncImage, target string, dstClient *distribution.Client) (string, error) {
+	src, err := distribution.ParseReference(img.Source)
+	if err != nil {
+		return "", err
+	}
+	dst, err := distribution.ParseReference(rs.Registry + "/" + target)
+	if err != nil {
+		return "", err
+	}
+	srcOpts := []distribution.Option{distribution.WithBasicAuth(img.Username, img.Password)}
+	if rs.Proxy != "" {
+		srcOpts = append(srcOpts, distribution.WithProxy(rs.Proxy))
+	}
+	srcClient, err := distribution.NewClient(srcOpts...)
+	if err != nil {
+		return "", err
+	}
+	return distribution.Copy(context.TODO(), srcClient, src, dstClient, dst)
+}
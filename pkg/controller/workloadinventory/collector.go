@@ -0,0 +1,208 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package workloadinventory
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/pager"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/controller-runtime/manager"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	// collectTimeout bounds the listing of a single cluster, so that a slow apiserver
+	// does not hold back the others.
+	collectTimeout = time.Minute
+	// pageSize is the number of objects listed per request.
+	pageSize = 500
+)
+
+// Collector caches the namespace and workload counts of the running clusters in their status.
+type Collector struct {
+	Options       *Options
+	ClusterWriter cluster.ClusterWriter
+	ClusterLister listerv1.ClusterLister
+	mgr           manager.Manager
+	log           logger.Logging
+}
+
+func (c *Collector) SetupWithManager(mgr manager.Manager) {
+	if c.Options == nil || !c.Options.Enabled {
+		return
+	}
+	c.mgr = mgr
+	c.log = mgr.GetLogger().WithName("workload-inventory")
+	mgr.AddWorkerLoop(c.collectInventories, c.Options.Period)
+}
+
+func (c *Collector) collectInventories() {
+	clusters, err := c.ClusterLister.List(labels.Everything())
+	if err != nil {
+		c.log.Error("list clusters failed, collect workload inventory next period", zap.Error(err))
+		return
+	}
+	for _, clu := range clusters {
+		if clu.Status.Status != v1.ClusterStatusRunning && clu.Status.Status != v1.ClusterStatusUpgrading {
+			continue
+		}
+		cc, exist := c.mgr.GetClusterClientSet(clu.Name)
+		if !exist {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.TODO(), collectTimeout)
+		workloads, err := Collect(ctx, cc.Kubernetes(), c.Options.MaxUnhealthy)
+		cancel()
+		if err != nil {
+			// the previous inventory is kept, the health monitor reports unreachable clusters
+			c.log.Warn("collect workload inventory failed", zap.String("cluster", clu.Name), zap.Error(err))
+			continue
+		}
+		if !Changed(clu.Status.Workloads, workloads) {
+			continue
+		}
+		workloads.UpdateTime = metav1.Now()
+		clu = clu.DeepCopy()
+		clu.Status.Workloads = workloads
+		if _, err = c.ClusterWriter.UpdateCluster(context.TODO(), clu); err != nil {
+			c.log.Warn("update cluster workload inventory failed", zap.String("cluster", clu.Name), zap.Error(err))
+		}
+	}
+}
+
+// Changed reports whether the collected inventory differs from the current one, regardless of their update time.
+func Changed(current, collected *v1.ClusterWorkloads) bool {
+	if current == nil {
+		return true
+	}
+	a, b := *current, *collected
+	a.UpdateTime, b.UpdateTime = metav1.Time{}, metav1.Time{}
+	return !reflect.DeepEqual(a, b)
+}
+
+// Collect counts the namespaces and workloads of a cluster, and records at most maxUnhealthy
+// of its unhealthy workloads.
+func Collect(ctx context.Context, clientset kubernetes.Interface, maxUnhealthy int) (*v1.ClusterWorkloads, error) {
+	w := &v1.ClusterWorkloads{}
+	var unhealthy []v1.UnhealthyWorkload
+	addUnhealthy := func(kind string, meta metav1.ObjectMeta, ready, desired int32) {
+		if ready < desired {
+			unhealthy = append(unhealthy, v1.UnhealthyWorkload{Kind: kind, Namespace: meta.Namespace, Name: meta.Name, Ready: ready, Desired: desired})
+		}
+	}
+
+	err := eachItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return clientset.CoreV1().Namespaces().List(ctx, opts)
+	}, func(obj runtime.Object) {
+		w.Namespaces++
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = eachItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return clientset.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, opts)
+	}, func(obj runtime.Object) {
+		d := obj.(*appsv1.Deployment)
+		w.Deployments++
+		desired := int32(1)
+		if d.Spec.Replicas != nil {
+			desired = *d.Spec.Replicas
+		}
+		addUnhealthy("Deployment", d.ObjectMeta, d.Status.AvailableReplicas, desired)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = eachItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return clientset.AppsV1().StatefulSets(metav1.NamespaceAll).List(ctx, opts)
+	}, func(obj runtime.Object) {
+		s := obj.(*appsv1.StatefulSet)
+		w.StatefulSets++
+		desired := int32(1)
+		if s.Spec.Replicas != nil {
+			desired = *s.Spec.Replicas
+		}
+		addUnhealthy("StatefulSet", s.ObjectMeta, s.Status.ReadyReplicas, desired)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = eachItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return clientset.AppsV1().DaemonSets(metav1.NamespaceAll).List(ctx, opts)
+	}, func(obj runtime.Object) {
+		d := obj.(*appsv1.DaemonSet)
+		w.DaemonSets++
+		addUnhealthy("DaemonSet", d.ObjectMeta, d.Status.NumberReady, d.Status.DesiredNumberScheduled)
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = eachItem(ctx, func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+		return clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, opts)
+	}, func(obj runtime.Object) {
+		w.Pods++
+		if obj.(*corev1.Pod).Status.Phase == corev1.PodRunning {
+			w.RunningPods++
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(unhealthy, func(i, j int) bool {
+		a, b := unhealthy[i], unhealthy[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	w.UnhealthyTotal = len(unhealthy)
+	if len(unhealthy) > maxUnhealthy {
+		unhealthy = unhealthy[:maxUnhealthy]
+	}
+	if len(unhealthy) > 0 {
+		w.Unhealthy = unhealthy
+	}
+	return w, nil
+}
+
+// eachItem lists the objects page by page, so that large clusters are not listed in a single response.
+func eachItem(ctx context.Context, list pager.ListPageFunc, fn func(obj runtime.Object)) error {
+	return pager.New(list).EachListItem(ctx, metav1.ListOptions{Limit: pageSize}, func(obj runtime.Object) error {
+		fn(obj)
+		return nil
+	})
+}
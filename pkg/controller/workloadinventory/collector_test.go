@@ -0,0 +1,107 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package workloadinventory
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func int32Ptr(i int32) *int32 {
+	return &i
+}
+
+func TestCollect(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(3)},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "coredns", Namespace: "kube-system"},
+			Spec:       appsv1.DeploymentSpec{Replicas: int32Ptr(2)},
+			Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(1)},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-proxy", Namespace: "kube-system"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 3},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodRunning}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-2", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodPending}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "default"}, Status: corev1.PodStatus{Phase: corev1.PodFailed}},
+	)
+
+	got, err := Collect(context.TODO(), clientset, 20)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	want := &v1.ClusterWorkloads{
+		Namespaces:   2,
+		Deployments:  2,
+		StatefulSets: 1,
+		DaemonSets:   1,
+		Pods:         3,
+		RunningPods:  1,
+		Unhealthy: []v1.UnhealthyWorkload{
+			{Kind: "Deployment", Namespace: "default", Name: "web", Ready: 1, Desired: 3},
+			{Kind: "StatefulSet", Namespace: "default", Name: "db", Ready: 0, Desired: 1},
+		},
+		UnhealthyTotal: 2,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Collect() = %+v, want %+v", got, want)
+	}
+
+	got, err = Collect(context.TODO(), clientset, 1)
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(got.Unhealthy) != 1 || got.Unhealthy[0].Name != "web" || got.UnhealthyTotal != 2 {
+		t.Errorf("Collect() with max 1 unhealthy = %+v, %d", got.Unhealthy, got.UnhealthyTotal)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	current := &v1.ClusterWorkloads{Namespaces: 2, Pods: 5, UpdateTime: metav1.NewTime(time.Now().Add(-time.Hour))}
+	if Changed(current, &v1.ClusterWorkloads{Namespaces: 2, Pods: 5}) {
+		t.Error("Changed() = true for the same counts collected at another time")
+	}
+	if !Changed(current, &v1.ClusterWorkloads{Namespaces: 2, Pods: 6}) {
+		t.Error("Changed() = false for a different pod count")
+	}
+	if !Changed(nil, &v1.ClusterWorkloads{}) {
+		t.Error("Changed() = false without a previous inventory")
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package workloadinventory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Options configures the workload inventory of the managed clusters. It is off by default
+// because it lists every pod of every cluster each period.
+type Options struct {
+	Enabled bool `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	// Period is how often the inventory of a cluster is collected.
+	Period time.Duration `json:"period,omitempty" yaml:"period,omitempty" mapstructure:"period"`
+	// MaxUnhealthy is the maximum number of unhealthy workloads recorded for a cluster.
+	MaxUnhealthy int `json:"maxUnhealthy,omitempty" yaml:"maxUnhealthy,omitempty" mapstructure:"maxUnhealthy"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Period:       5 * time.Minute,
+		MaxUnhealthy: 20,
+	}
+}
+
+func (o *Options) Validate() []error {
+	if o == nil || !o.Enabled {
+		return nil
+	}
+	var errs []error
+	if o.Period < time.Minute {
+		errs = append(errs, fmt.Errorf("workload inventory period %s must be at least 1m", o.Period))
+	}
+	if o.MaxUnhealthy < 0 {
+		errs = append(errs, fmt.Errorf("workload inventory max unhealthy %d must not be negative", o.MaxUnhealthy))
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "workload-inventory", o.Enabled, "periodically cache the namespace and workload counts of the managed clusters in the cluster status")
+	fs.DurationVar(&o.Period, "workload-inventory-period", o.Period, "how often the workload inventory of a cluster is collected")
+	fs.IntVar(&o.MaxUnhealthy, "workload-inventory-max-unhealthy", o.MaxUnhealthy, "maximum number of unhealthy workloads recorded for a cluster")
+}
@@ -89,4 +89,19 @@ type HTTPError struct {
 	Code    int    `json:"code"`
 	Message string `json:"message"`
 	Reason  string `json:"reason"`
+	// Fields lists the invalid fields of the request body.
+	Fields []FieldError `json:"fields,omitempty" optional:"true"`
+}
+
+// FieldError reports an invalid field of a request body, Field is the JSON path of the field, e.g. spec.nodes[0].id.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
 }
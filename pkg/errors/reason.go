@@ -36,4 +36,7 @@ const (
 	AgentStepUninstall CauseType = "agent uninstall step command"
 	ShellCommand       CauseType = "shell command step error"
 	StepLog            CauseType = "step log error"
+	AgentConfig        CauseType = "agent config error"
+	Terminal           CauseType = "terminal session error"
+	BrowseFile         CauseType = "browse file error"
 )
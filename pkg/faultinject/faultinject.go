@@ -0,0 +1,210 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package faultinject delays, fails or duplicates operation steps and mq messages,
+// so the retry, rollback and cancel paths of the operation engine can be tested
+// end to end against real agents.
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+)
+
+type Target string
+
+const (
+	// TargetStep matches the name of the step an agent runs.
+	TargetStep Target = "step"
+	// TargetMessage matches the subject of a mq message.
+	TargetMessage Target = "message"
+)
+
+type Action string
+
+const (
+	ActionDelay     Action = "delay"
+	ActionFail      Action = "fail"
+	ActionDuplicate Action = "duplicate"
+)
+
+// ErrInjected is returned for the faults of fail rules.
+var ErrInjected = errors.New("injected fault")
+
+type Rule struct {
+	Target Target `json:"target" yaml:"target" mapstructure:"target"`
+	// Match is a shell pattern of the step name or message subject.
+	Match  string        `json:"match" yaml:"match" mapstructure:"match"`
+	Action Action        `json:"action" yaml:"action" mapstructure:"action"`
+	Delay  time.Duration `json:"delay,omitempty" yaml:"delay,omitempty" mapstructure:"delay"`
+	// Probability in (0, 1] that a match injects the fault, 0 means always.
+	Probability float64 `json:"probability,omitempty" yaml:"probability,omitempty" mapstructure:"probability"`
+	// Times limits how many faults the rule injects, 0 means no limit.
+	Times int `json:"times,omitempty" yaml:"times,omitempty" mapstructure:"times"`
+}
+
+func (r *Rule) validate() error {
+	if r.Target != TargetStep && r.Target != TargetMessage {
+		return fmt.Errorf("unsupported fault injection target %q", r.Target)
+	}
+	if _, err := path.Match(r.Match, ""); err != nil || r.Match == "" {
+		return fmt.Errorf("invalid fault injection pattern %q", r.Match)
+	}
+	switch r.Action {
+	case ActionFail, ActionDuplicate:
+	case ActionDelay:
+		if r.Delay <= 0 {
+			return fmt.Errorf("fault injection delay of %s must be positive", r.Match)
+		}
+	default:
+		return fmt.Errorf("unsupported fault injection action %q", r.Action)
+	}
+	if r.Probability < 0 || r.Probability > 1 {
+		return fmt.Errorf("fault injection probability %v must be in [0, 1]", r.Probability)
+	}
+	if r.Times < 0 {
+		return fmt.Errorf("fault injection times %d must not be negative", r.Times)
+	}
+	return nil
+}
+
+// Parse parses rules separated by semicolons, each in the form
+// TARGET:PATTERN:ACTION[,probability=P][,times=N], where a delay action
+// is written as delay=DURATION.
+func Parse(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, item := range strings.Split(spec, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid fault injection rule %q", item)
+		}
+		r := Rule{Target: Target(parts[0]), Match: parts[1]}
+		for i, field := range strings.Split(parts[2], ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(field), "=")
+			var err error
+			switch {
+			case i == 0:
+				r.Action = Action(key)
+				if r.Action == ActionDelay {
+					r.Delay, err = time.ParseDuration(value)
+				}
+			case key == "probability":
+				r.Probability, err = strconv.ParseFloat(value, 64)
+			case key == "times":
+				r.Times, err = strconv.Atoi(value)
+			default:
+				err = fmt.Errorf("unknown option %q", key)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("invalid fault injection rule %q: %v", item, err)
+			}
+		}
+		if err := r.validate(); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Injector applies rules. A nil Injector injects nothing.
+type Injector struct {
+	rules []Rule
+
+	mu   sync.Mutex
+	hits []int
+	rand *rand.Rand
+}
+
+func NewInjector(rules []Rule) *Injector {
+	if len(rules) == 0 {
+		return nil
+	}
+	logger.Warn("fault injection is enabled, operations will be disturbed on purpose", zap.Any("rules", rules))
+	return &Injector{
+		rules: rules,
+		hits:  make([]int, len(rules)),
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Inject applies the rules matching name: it waits for the delays, then returns
+// ErrInjected when a fail rule fires, or reports whether the caller must do the
+// work twice when a duplicate rule fires.
+func (i *Injector) Inject(ctx context.Context, target Target, name string) (duplicate bool, err error) {
+	if i == nil {
+		return false, nil
+	}
+	var (
+		delay time.Duration
+		fail  bool
+	)
+	i.mu.Lock()
+	for idx, r := range i.rules {
+		if r.Target != target {
+			continue
+		}
+		if ok, _ := path.Match(r.Match, name); !ok {
+			continue
+		}
+		if r.Times > 0 && i.hits[idx] >= r.Times {
+			continue
+		}
+		if r.Probability > 0 && i.rand.Float64() >= r.Probability {
+			continue
+		}
+		i.hits[idx]++
+		logger.Info("inject fault", zap.String("target", string(target)), zap.String("name", name), zap.String("action", string(r.Action)))
+		switch r.Action {
+		case ActionDelay:
+			delay += r.Delay
+		case ActionFail:
+			fail = true
+		case ActionDuplicate:
+			duplicate = true
+		}
+	}
+	i.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+	if fail {
+		return false, fmt.Errorf("%w: %s %s", ErrInjected, target, name)
+	}
+	return duplicate, nil
+}
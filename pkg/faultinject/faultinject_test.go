@@ -0,0 +1,95 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package faultinject
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	rules, err := Parse("step:installKubeadm*:fail,times=1; message:*.task:delay=2s,probability=0.5;;step:*:duplicate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Rule{
+		{Target: TargetStep, Match: "installKubeadm*", Action: ActionFail, Times: 1},
+		{Target: TargetMessage, Match: "*.task", Action: ActionDelay, Delay: 2 * time.Second, Probability: 0.5},
+		{Target: TargetStep, Match: "*", Action: ActionDuplicate},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("got %d rules, want %d", len(rules), len(want))
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule %d: got %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+
+	for _, spec := range []string{
+		"step:*",
+		"node:*:fail",
+		"step:[:fail",
+		"step:*:explode",
+		"step:*:delay",
+		"step:*:fail,probability=2",
+		"step:*:fail,times=-1",
+		"step:*:fail,retry=1",
+	} {
+		if _, err := Parse(spec); err == nil {
+			t.Errorf("Parse(%q) expected error", spec)
+		}
+	}
+}
+
+func TestInject(t *testing.T) {
+	var nilInjector *Injector
+	if dup, err := nilInjector.Inject(context.TODO(), TargetStep, "any"); dup || err != nil {
+		t.Fatalf("nil injector injected dup=%v err=%v", dup, err)
+	}
+	if NewInjector(nil) != nil {
+		t.Fatal("expected nil injector without rules")
+	}
+
+	inj := NewInjector([]Rule{
+		{Target: TargetStep, Match: "join*", Action: ActionFail, Times: 1},
+		{Target: TargetStep, Match: "init*", Action: ActionDuplicate},
+		{Target: TargetMessage, Match: "node.*", Action: ActionDelay, Delay: time.Millisecond},
+	})
+	if _, err := inj.Inject(context.TODO(), TargetStep, "joinNode"); !errors.Is(err, ErrInjected) {
+		t.Errorf("first join: got %v, want ErrInjected", err)
+	}
+	if _, err := inj.Inject(context.TODO(), TargetStep, "joinNode"); err != nil {
+		t.Errorf("second join exceeds times, got %v", err)
+	}
+	if dup, err := inj.Inject(context.TODO(), TargetStep, "initNode"); !dup || err != nil {
+		t.Errorf("init: got dup=%v err=%v, want duplicate", dup, err)
+	}
+	if dup, err := inj.Inject(context.TODO(), TargetMessage, "initNode"); dup || err != nil {
+		t.Errorf("rules of another target must not match, got dup=%v err=%v", dup, err)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	inj = NewInjector([]Rule{{Target: TargetMessage, Match: "*", Action: ActionDelay, Delay: time.Hour}})
+	if _, err := inj.Inject(ctx, TargetMessage, "node.a"); !errors.Is(err, context.Canceled) {
+		t.Errorf("delay must stop on cancel, got %v", err)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package faultinject
+
+import (
+	"context"
+
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+// WrapMQ returns a client that injects faults into the messages it sends. A duplicated
+// message is also published without reply subject, as if it had been redelivered.
+func WrapMQ(client natsio.Interface, injector *Injector) natsio.Interface {
+	if injector == nil {
+		return client
+	}
+	return &faultyMQ{Interface: client, injector: injector}
+}
+
+type faultyMQ struct {
+	natsio.Interface
+	injector *Injector
+}
+
+func (f *faultyMQ) inject(ctx context.Context, msg *natsio.Msg) error {
+	duplicate, err := f.injector.Inject(ctx, TargetMessage, msg.Subject)
+	if err != nil || !duplicate {
+		return err
+	}
+	return f.Interface.Publish(msg)
+}
+
+func (f *faultyMQ) Publish(msg *natsio.Msg) error {
+	if err := f.inject(context.TODO(), msg); err != nil {
+		return err
+	}
+	return f.Interface.Publish(msg)
+}
+
+func (f *faultyMQ) Request(msg *natsio.Msg, timeoutHandler natsio.TimeoutHandler) ([]byte, error) {
+	if err := f.inject(context.TODO(), msg); err != nil {
+		return nil, err
+	}
+	return f.Interface.Request(msg, timeoutHandler)
+}
+
+func (f *faultyMQ) RequestWithContext(ctx context.Context, msg *natsio.Msg) ([]byte, error) {
+	if err := f.inject(ctx, msg); err != nil {
+		return nil, err
+	}
+	return f.Interface.RequestWithContext(ctx, msg)
+}
+
+func (f *faultyMQ) RequestAsync(msg *natsio.Msg, handler natsio.ReplyHandler, timeoutHandler natsio.TimeoutHandler) error {
+	if err := f.inject(context.TODO(), msg); err != nil {
+		return err
+	}
+	return f.Interface.RequestAsync(msg, handler, timeoutHandler)
+}
@@ -0,0 +1,78 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package faultinject
+
+import (
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// EnvFaultInjection holds rules in the spec format, they are added to the configured ones.
+const EnvFaultInjection = "KC_FAULT_INJECTION"
+
+// Options configures fault injection. It is meant for tests only and is empty by default.
+type Options struct {
+	Rules []Rule `json:"rules,omitempty" yaml:"rules,omitempty" mapstructure:"rules"`
+	// Spec holds rules in the format parsed by Parse.
+	Spec string `json:"spec,omitempty" yaml:"spec,omitempty" mapstructure:"spec"`
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	for i := range o.Rules {
+		if err := o.Rules[i].validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, spec := range []string{o.Spec, os.Getenv(EnvFaultInjection)} {
+		if _, err := Parse(spec); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Spec, "fault-injection", o.Spec, "inject faults into steps and mq messages for testing, "+
+		"e.g. 'step:kubeadmInit*:fail,times=1;message:*:delay=2s,probability=0.5'. Never use it in production")
+}
+
+// Injector returns the injector of the configured rules, or nil when there is none.
+func (o *Options) Injector() (*Injector, error) {
+	if o == nil {
+		o = NewOptions()
+	}
+	rules := append([]Rule(nil), o.Rules...)
+	for _, spec := range []string{o.Spec, os.Getenv(EnvFaultInjection)} {
+		parsed, err := Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, parsed...)
+	}
+	return NewInjector(rules), nil
+}
@@ -0,0 +1,232 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package inventory discovers the machines of virtualization and bare metal
+// inventories, which are candidates to be joined as agent nodes.
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Machine is a machine found in an inventory.
+type Machine struct {
+	// Provider is the name of the configured provider the machine is found by.
+	Provider     string `json:"provider"`
+	ProviderType string `json:"providerType"`
+	// ID identifies the machine in the inventory.
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	IPs        []string `json:"ips,omitempty"`
+	CPU        int      `json:"cpu"`
+	MemoryMB   int64    `json:"memoryMB"`
+	DiskGB     int64    `json:"diskGB"`
+	OS         string   `json:"os,omitempty"`
+	PowerState string   `json:"powerState,omitempty"`
+	// Node is the registered node having one of the IPs of the machine.
+	Node string `json:"node,omitempty"`
+}
+
+// MachineList is the machines of providers, with the failures of the providers not listed.
+type MachineList struct {
+	Items      []Machine `json:"items"`
+	TotalCount int       `json:"totalCount"`
+	// Failures maps provider names to their errors.
+	Failures map[string]string `json:"failures,omitempty"`
+}
+
+// Provider lists the machines of an inventory.
+type Provider interface {
+	List(ctx context.Context) ([]Machine, error)
+}
+
+type Factory func(opts *ProviderOptions) (Provider, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a provider type available to the configuration.
+func Register(typ string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[typ] = factory
+}
+
+func getFactory(typ string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	f, ok := factories[typ]
+	return f, ok
+}
+
+// Types returns the registered provider types.
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	types := make([]string, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+type cachedList struct {
+	machines []Machine
+	listedAt time.Time
+}
+
+// Discoverer lists the machines of all the configured providers. Inventories
+// are slow to query, so the machines are cached for a while.
+type Discoverer struct {
+	names     []string
+	providers map[string]*ProviderOptions
+	built     map[string]Provider
+	cacheTTL  time.Duration
+	nowFunc   func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedList
+}
+
+func NewDiscoverer(opts *Options) (*Discoverer, error) {
+	d := &Discoverer{
+		providers: make(map[string]*ProviderOptions),
+		built:     make(map[string]Provider),
+		cacheTTL:  DefaultCacheTTL,
+		nowFunc:   time.Now,
+		cache:     make(map[string]cachedList),
+	}
+	if opts == nil {
+		return d, nil
+	}
+	if opts.CacheTTL > 0 {
+		d.cacheTTL = opts.CacheTTL
+	}
+	for i := range opts.Providers {
+		p := &opts.Providers[i]
+		factory, ok := getFactory(p.Type)
+		if !ok {
+			return nil, fmt.Errorf("inventory provider %s: unsupported type %q", p.Name, p.Type)
+		}
+		provider, err := factory(p)
+		if err != nil {
+			return nil, fmt.Errorf("inventory provider %s: %v", p.Name, err)
+		}
+		d.names = append(d.names, p.Name)
+		d.providers[p.Name] = p
+		d.built[p.Name] = provider
+	}
+	return d, nil
+}
+
+// Providers returns the names of the configured providers.
+func (d *Discoverer) Providers() []string {
+	return append([]string(nil), d.names...)
+}
+
+// List returns the machines of the provider, or of all providers if name is empty.
+// The failures of providers are returned by provider name, the machines of the
+// others are still listed.
+func (d *Discoverer) List(ctx context.Context, name string, refresh bool) ([]Machine, map[string]error, error) {
+	names := d.names
+	if name != "" {
+		if _, ok := d.built[name]; !ok {
+			return nil, nil, fmt.Errorf("inventory provider %q not found", name)
+		}
+		names = []string{name}
+	}
+	var (
+		machines []Machine
+		failures map[string]error
+	)
+	for _, n := range names {
+		list, err := d.list(ctx, n, refresh)
+		if err != nil {
+			if failures == nil {
+				failures = make(map[string]error)
+			}
+			failures[n] = err
+			continue
+		}
+		machines = append(machines, list...)
+	}
+	return machines, failures, nil
+}
+
+// Get returns the machine of the provider with the given ID.
+func (d *Discoverer) Get(ctx context.Context, name, id string) (*Machine, error) {
+	if _, ok := d.built[name]; !ok {
+		return nil, fmt.Errorf("inventory provider %q not found", name)
+	}
+	list, err := d.list(ctx, name, false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if list[i].ID == id {
+			m := list[i]
+			return &m, nil
+		}
+	}
+	return nil, fmt.Errorf("machine %s not found in inventory provider %s", id, name)
+}
+
+func (d *Discoverer) list(ctx context.Context, name string, refresh bool) ([]Machine, error) {
+	d.mu.Lock()
+	cached, ok := d.cache[name]
+	d.mu.Unlock()
+	if ok && !refresh && d.nowFunc().Sub(cached.listedAt) < d.cacheTTL {
+		return cached.machines, nil
+	}
+	list, err := d.built[name].List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts := d.providers[name]
+	for i := range list {
+		list[i].Provider = name
+		list[i].ProviderType = opts.Type
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	d.mu.Lock()
+	d.cache[name] = cachedList{machines: list, listedAt: d.nowFunc()}
+	d.mu.Unlock()
+	return list, nil
+}
+
+// MarkRegistered sets the node of the machines having an IP of a registered node,
+// nodes maps node IPs to node names.
+func MarkRegistered(machines []Machine, nodes map[string]string) {
+	for i := range machines {
+		for _, ip := range machines[i].IPs {
+			if node, ok := nodes[ip]; ok {
+				machines[i].Node = node
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,154 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProxmoxList(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "PVEAPIToken=root@pam!kc=secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api2/json/cluster/resources":
+			fmt.Fprint(w, `{"data":[
+{"vmid":100,"name":"vm1","node":"pve1","type":"qemu","status":"running","maxcpu":4,"maxmem":8589934592,"maxdisk":53687091200},
+{"vmid":101,"name":"tmpl","node":"pve1","type":"qemu","status":"stopped","template":1}]}`)
+		case "/api2/json/nodes/pve1/qemu/100/agent/network-get-interfaces":
+			fmt.Fprint(w, `{"data":{"result":[
+{"name":"lo","ip-addresses":[{"ip-address":"127.0.0.1","ip-address-type":"ipv4"}]},
+{"name":"eth0","ip-addresses":[{"ip-address":"10.0.0.10","ip-address-type":"ipv4"},{"ip-address":"fe80::1","ip-address-type":"ipv6"}]}]}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := newProxmox(&ProviderOptions{Endpoint: srv.URL, Token: "root@pam!kc=secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.List(context.TODO())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := []Machine{{ID: "100", Name: "vm1", IPs: []string{"10.0.0.10"}, CPU: 4, MemoryMB: 8192, DiskGB: 50, PowerState: "running"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMAASList(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Authorization"), `oauth_signature="&ts"`) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `[{"system_id":"abc123","hostname":"metal1","ip_addresses":["10.0.0.20"],"cpu_count":32,
+"memory":131072,"storage":960197.124,"osystem":"ubuntu","distro_series":"jammy","power_state":"on","status_name":"Deployed"}]`)
+	}))
+	defer srv.Close()
+
+	p, err := newMAAS(&ProviderOptions{Endpoint: srv.URL, Token: "ck:tk:ts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := p.List(context.TODO())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	want := []Machine{{ID: "abc123", Name: "metal1", IPs: []string{"10.0.0.20"}, CPU: 32, MemoryMB: 131072, DiskGB: 960, OS: "ubuntu/jammy", PowerState: "on/Deployed"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("List() = %+v, want %+v", got, want)
+	}
+}
+
+type fakeProvider struct {
+	machines []Machine
+	err      error
+	calls    int
+}
+
+func (f *fakeProvider) List(ctx context.Context) ([]Machine, error) {
+	f.calls++
+	return append([]Machine(nil), f.machines...), f.err
+}
+
+func TestDiscoverer(t *testing.T) {
+	good := &fakeProvider{machines: []Machine{{ID: "2", Name: "b", IPs: []string{"10.0.0.2"}}, {ID: "1", Name: "a", IPs: []string{"10.0.0.1"}}}}
+	bad := &fakeProvider{err: fmt.Errorf("unauthorized")}
+	Register("fake-good", func(*ProviderOptions) (Provider, error) { return good, nil })
+	Register("fake-bad", func(*ProviderOptions) (Provider, error) { return bad, nil })
+	d, err := NewDiscoverer(&Options{Providers: []ProviderOptions{
+		{Name: "lab", Type: "fake-good"},
+		{Name: "broken", Type: "fake-bad"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	d.nowFunc = func() time.Time { return now }
+
+	machines, failures, err := d.List(context.TODO(), "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(machines) != 2 || machines[0].Name != "a" || machines[0].Provider != "lab" || machines[0].ProviderType != "fake-good" {
+		t.Errorf("List() = %+v", machines)
+	}
+	if len(failures) != 1 || failures["broken"] == nil {
+		t.Errorf("List() failures = %v, want broken", failures)
+	}
+
+	if _, err = d.Get(context.TODO(), "lab", "2"); err != nil {
+		t.Errorf("Get() error = %v", err)
+	}
+	if good.calls != 1 {
+		t.Errorf("provider listed %d times, want the cache used", good.calls)
+	}
+	now = now.Add(DefaultCacheTTL)
+	if _, _, err = d.List(context.TODO(), "lab", false); err != nil || good.calls != 2 {
+		t.Errorf("expired cache is not refreshed, calls = %d, err = %v", good.calls, err)
+	}
+
+	MarkRegistered(machines, map[string]string{"10.0.0.2": "node-b"})
+	if machines[0].Node != "" || machines[1].Node != "node-b" {
+		t.Errorf("MarkRegistered() = %+v", machines)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	opts := &Options{Providers: []ProviderOptions{
+		{Name: "lab", Type: TypeProxmox, Endpoint: "https://pve:8006"},
+		{Name: "lab", Type: "xen", Endpoint: "pve"},
+	}}
+	if errs := opts.Validate(); len(errs) != 3 {
+		t.Errorf("Validate() = %v, want duplicated name, unsupported type and invalid endpoint", errs)
+	}
+}
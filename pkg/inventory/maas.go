@@ -0,0 +1,103 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const TypeMAAS = "maas"
+
+func init() {
+	Register(TypeMAAS, newMAAS)
+}
+
+// maas lists the machines of MAAS, the endpoint is the MAAS url, e.g. http://maas:5240/MAAS.
+type maas struct {
+	client      *restClient
+	consumerKey string
+	tokenKey    string
+	tokenSecret string
+}
+
+func newMAAS(opts *ProviderOptions) (Provider, error) {
+	parts := strings.Split(opts.Token, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("api key must be in the format <consumer key>:<token key>:<token secret>")
+	}
+	return &maas{
+		client:      newRESTClient(opts),
+		consumerKey: parts[0],
+		tokenKey:    parts[1],
+		tokenSecret: parts[2],
+	}, nil
+}
+
+type maasMachine struct {
+	SystemID     string   `json:"system_id"`
+	Hostname     string   `json:"hostname"`
+	IPAddresses  []string `json:"ip_addresses"`
+	CPUCount     int      `json:"cpu_count"`
+	Memory       int64    `json:"memory"`
+	Storage      float64  `json:"storage"`
+	OSystem      string   `json:"osystem"`
+	DistroSeries string   `json:"distro_series"`
+	PowerState   string   `json:"power_state"`
+	StatusName   string   `json:"status_name"`
+}
+
+// authorization signs the request with OAuth 1.0 PLAINTEXT as MAAS requires.
+func (m *maas) authorization() string {
+	return fmt.Sprintf(`OAuth oauth_version="1.0", oauth_signature_method="PLAINTEXT", oauth_consumer_key="%s", oauth_token="%s", oauth_signature="&%s", oauth_nonce="%s", oauth_timestamp="%s"`,
+		m.consumerKey, m.tokenKey, m.tokenSecret, uuid.New().String(), strconv.FormatInt(time.Now().Unix(), 10))
+}
+
+func (m *maas) List(ctx context.Context) ([]Machine, error) {
+	var list []maasMachine
+	header := http.Header{"Authorization": []string{m.authorization()}}
+	if _, err := m.client.do(ctx, http.MethodGet, "/api/2.0/machines/", header, nil, &list); err != nil {
+		return nil, err
+	}
+	machines := make([]Machine, 0, len(list))
+	for _, mm := range list {
+		os := mm.OSystem
+		if mm.DistroSeries != "" {
+			os = strings.TrimPrefix(os+"/"+mm.DistroSeries, "/")
+		}
+		machines = append(machines, Machine{
+			ID:       mm.SystemID,
+			Name:     mm.Hostname,
+			IPs:      mm.IPAddresses,
+			CPU:      mm.CPUCount,
+			MemoryMB: mm.Memory,
+			// storage is reported in MB
+			DiskGB:     int64(mm.Storage / 1000),
+			OS:         os,
+			PowerState: mm.PowerState + "/" + mm.StatusName,
+		})
+	}
+	return machines, nil
+}
@@ -0,0 +1,173 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const TypeOpenStack = "openstack"
+
+func init() {
+	Register(TypeOpenStack, newOpenStack)
+}
+
+// openstack lists the servers of a project, the compute endpoint is found in
+// the catalog of the keystone token.
+type openstack struct {
+	client        *restClient
+	username      string
+	password      string
+	userDomain    string
+	project       string
+	projectDomain string
+	region        string
+	iface         string
+}
+
+func newOpenStack(opts *ProviderOptions) (Provider, error) {
+	if opts.Username == "" || opts.Password == "" {
+		return nil, fmt.Errorf("username and password must be specified")
+	}
+	o := &openstack{
+		client:        newRESTClient(opts),
+		username:      opts.Username,
+		password:      opts.Password,
+		userDomain:    opts.param("userDomain", "Default"),
+		project:       opts.param("project", ""),
+		projectDomain: opts.param("projectDomain", "Default"),
+		region:        opts.param("region", ""),
+		iface:         opts.param("interface", "public"),
+	}
+	if o.project == "" {
+		return nil, fmt.Errorf("param project must be specified")
+	}
+	return o, nil
+}
+
+type keystoneToken struct {
+	Token struct {
+		Catalog []struct {
+			Type      string `json:"type"`
+			Endpoints []struct {
+				Interface string `json:"interface"`
+				Region    string `json:"region"`
+				URL       string `json:"url"`
+			} `json:"endpoints"`
+		} `json:"catalog"`
+	} `json:"token"`
+}
+
+type novaServers struct {
+	Servers []struct {
+		ID        string `json:"id"`
+		Name      string `json:"name"`
+		Status    string `json:"status"`
+		Addresses map[string][]struct {
+			Addr    string `json:"addr"`
+			Version int    `json:"version"`
+		} `json:"addresses"`
+		Flavor struct {
+			VCPUs int   `json:"vcpus"`
+			RAM   int64 `json:"ram"`
+			Disk  int64 `json:"disk"`
+		} `json:"flavor"`
+		Metadata map[string]string `json:"metadata"`
+	} `json:"servers"`
+}
+
+func (o *openstack) authenticate(ctx context.Context) (token, compute string, err error) {
+	body := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"password"},
+				"password": map[string]interface{}{
+					"user": map[string]interface{}{
+						"name":     o.username,
+						"password": o.password,
+						"domain":   map[string]string{"name": o.userDomain},
+					},
+				},
+			},
+			"scope": map[string]interface{}{
+				"project": map[string]interface{}{
+					"name":   o.project,
+					"domain": map[string]string{"name": o.projectDomain},
+				},
+			},
+		},
+	}
+	resp := keystoneToken{}
+	r, err := o.client.do(ctx, http.MethodPost, "/v3/auth/tokens", nil, body, &resp)
+	if err != nil {
+		return "", "", err
+	}
+	token = r.Header.Get("X-Subject-Token")
+	for _, service := range resp.Token.Catalog {
+		if service.Type != "compute" {
+			continue
+		}
+		for _, ep := range service.Endpoints {
+			if ep.Interface == o.iface && (o.region == "" || ep.Region == o.region) {
+				return token, strings.TrimSuffix(ep.URL, "/"), nil
+			}
+		}
+	}
+	return "", "", fmt.Errorf("no %s compute endpoint found in the catalog", o.iface)
+}
+
+func (o *openstack) List(ctx context.Context) ([]Machine, error) {
+	token, compute, err := o.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{
+		"X-Auth-Token": []string{token},
+		// the flavor of servers is detailed since 2.47
+		"Openstack-Api-Version": []string{"compute 2.47"},
+	}
+	resp := novaServers{}
+	if _, err = o.client.do(ctx, http.MethodGet, compute+"/servers/detail", header, nil, &resp); err != nil {
+		return nil, err
+	}
+	machines := make([]Machine, 0, len(resp.Servers))
+	for _, s := range resp.Servers {
+		m := Machine{
+			ID:         s.ID,
+			Name:       s.Name,
+			CPU:        s.Flavor.VCPUs,
+			MemoryMB:   s.Flavor.RAM,
+			DiskGB:     s.Flavor.Disk,
+			OS:         s.Metadata["os_distro"],
+			PowerState: s.Status,
+		}
+		for _, addrs := range s.Addresses {
+			for _, addr := range addrs {
+				if addr.Version == 4 {
+					m.IPs = append(m.IPs, addr.Addr)
+				}
+			}
+		}
+		machines = append(machines, m)
+	}
+	return machines, nil
+}
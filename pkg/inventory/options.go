@@ -0,0 +1,92 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package inventory
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	DefaultCacheTTL       = time.Minute
+	DefaultRequestTimeout = 30 * time.Second
+)
+
+type Options struct {
+	Providers []ProviderOptions `json:"providers,omitempty" yaml:"providers,omitempty" mapstructure:"providers"`
+	// CacheTTL is how long the machines of a provider are cached.
+	CacheTTL time.Duration `json:"cacheTTL,omitempty" yaml:"cacheTTL,omitempty" mapstructure:"cacheTTL"`
+}
+
+// ProviderOptions configures an inventory, which of the credentials are used depends on its type.
+type ProviderOptions struct {
+	Name string `json:"name" yaml:"name" mapstructure:"name"`
+	// Type is one of vsphere, openstack, proxmox and maas.
+	Type     string `json:"type" yaml:"type" mapstructure:"type"`
+	Endpoint string `json:"endpoint" yaml:"endpoint" mapstructure:"endpoint"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty" mapstructure:"username"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty" mapstructure:"password"`
+	// Token is the API token of proxmox, e.g. root@pam!kc=<uuid>, or the API key of MAAS.
+	Token string `json:"token,omitempty" yaml:"token,omitempty" mapstructure:"token"`
+	// Insecure skips the verification of the certificate of the endpoint.
+	Insecure bool `json:"insecure,omitempty" yaml:"insecure,omitempty" mapstructure:"insecure"`
+	// Params holds the settings specific to the type, e.g. the project and domain of openstack.
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty" mapstructure:"params"`
+	// Timeout bounds the requests to the inventory.
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" mapstructure:"timeout"`
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	names := sets.NewString()
+	for _, p := range o.Providers {
+		if p.Name == "" {
+			errs = append(errs, fmt.Errorf("inventory provider name must be specified"))
+			continue
+		}
+		if names.Has(p.Name) {
+			errs = append(errs, fmt.Errorf("inventory provider %s is duplicated", p.Name))
+		}
+		names.Insert(p.Name)
+		if _, ok := getFactory(p.Type); !ok {
+			errs = append(errs, fmt.Errorf("inventory provider %s: unsupported type %q, support %v", p.Name, p.Type, Types()))
+		}
+		if u, err := url.Parse(p.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("inventory provider %s: invalid endpoint %q", p.Name, p.Endpoint))
+		}
+	}
+	return errs
+}
+
+func (p *ProviderOptions) param(key, defaultValue string) string {
+	if v, ok := p.Params[key]; ok && v != "" {
+		return v
+	}
+	return defaultValue
+}
@@ -0,0 +1,142 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const TypeProxmox = "proxmox"
+
+func init() {
+	Register(TypeProxmox, newProxmox)
+}
+
+// proxmox lists the virtual machines and containers of a proxmox VE cluster.
+type proxmox struct {
+	client *restClient
+	header http.Header
+}
+
+func newProxmox(opts *ProviderOptions) (Provider, error) {
+	if opts.Token == "" {
+		return nil, fmt.Errorf("api token must be specified, e.g. root@pam!kc=<uuid>")
+	}
+	return &proxmox{
+		client: newRESTClient(opts),
+		header: http.Header{"Authorization": []string{"PVEAPIToken=" + opts.Token}},
+	}, nil
+}
+
+type proxmoxResources struct {
+	Data []struct {
+		VMID     int    `json:"vmid"`
+		Name     string `json:"name"`
+		Node     string `json:"node"`
+		Type     string `json:"type"`
+		Status   string `json:"status"`
+		MaxCPU   int    `json:"maxcpu"`
+		MaxMem   int64  `json:"maxmem"`
+		MaxDisk  int64  `json:"maxdisk"`
+		Template int    `json:"template"`
+	} `json:"data"`
+}
+
+type proxmoxAgentInterfaces struct {
+	Data struct {
+		Result []struct {
+			Name        string `json:"name"`
+			IPAddresses []struct {
+				IPAddress string `json:"ip-address"`
+				Type      string `json:"ip-address-type"`
+			} `json:"ip-addresses"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+type proxmoxLXCInterfaces struct {
+	Data []struct {
+		Name string `json:"name"`
+		Inet string `json:"inet"`
+	} `json:"data"`
+}
+
+func (p *proxmox) List(ctx context.Context) ([]Machine, error) {
+	resources := proxmoxResources{}
+	if _, err := p.client.do(ctx, http.MethodGet, "/api2/json/cluster/resources?type=vm", p.header, nil, &resources); err != nil {
+		return nil, err
+	}
+	machines := make([]Machine, 0, len(resources.Data))
+	for _, r := range resources.Data {
+		if r.Template == 1 {
+			continue
+		}
+		m := Machine{
+			ID:         strconv.Itoa(r.VMID),
+			Name:       r.Name,
+			CPU:        r.MaxCPU,
+			MemoryMB:   r.MaxMem / (1 << 20),
+			DiskGB:     r.MaxDisk / (1 << 30),
+			PowerState: r.Status,
+		}
+		if r.Status == "running" {
+			// addresses are only known to the guest agent, machines without it are listed without IPs
+			m.IPs = p.addresses(ctx, r.Node, r.Type, r.VMID)
+		}
+		machines = append(machines, m)
+	}
+	return machines, nil
+}
+
+func (p *proxmox) addresses(ctx context.Context, node, typ string, vmid int) []string {
+	var ips []string
+	switch typ {
+	case "qemu":
+		resp := proxmoxAgentInterfaces{}
+		path := fmt.Sprintf("/api2/json/nodes/%s/qemu/%d/agent/network-get-interfaces", node, vmid)
+		if _, err := p.client.do(ctx, http.MethodGet, path, p.header, nil, &resp); err != nil {
+			return nil
+		}
+		for _, iface := range resp.Data.Result {
+			for _, addr := range iface.IPAddresses {
+				if addr.Type == "ipv4" && !net.ParseIP(addr.IPAddress).IsLoopback() {
+					ips = append(ips, addr.IPAddress)
+				}
+			}
+		}
+	case "lxc":
+		resp := proxmoxLXCInterfaces{}
+		path := fmt.Sprintf("/api2/json/nodes/%s/lxc/%d/interfaces", node, vmid)
+		if _, err := p.client.do(ctx, http.MethodGet, path, p.header, nil, &resp); err != nil {
+			return nil
+		}
+		for _, iface := range resp.Data {
+			ip := strings.Split(iface.Inet, "/")[0]
+			if ip != "" && !net.ParseIP(ip).IsLoopback() {
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips
+}
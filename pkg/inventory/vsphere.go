@@ -0,0 +1,113 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package inventory
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const TypeVSphere = "vsphere"
+
+func init() {
+	Register(TypeVSphere, newVSphere)
+}
+
+// vsphere lists the virtual machines of vCenter through its automation API.
+type vsphere struct {
+	client   *restClient
+	username string
+	password string
+	// folder limits the machines to a vm folder, e.g. group-v1001
+	folder string
+}
+
+func newVSphere(opts *ProviderOptions) (Provider, error) {
+	if opts.Username == "" || opts.Password == "" {
+		return nil, fmt.Errorf("username and password must be specified")
+	}
+	return &vsphere{
+		client:   newRESTClient(opts),
+		username: opts.Username,
+		password: opts.Password,
+		folder:   opts.param("folder", ""),
+	}, nil
+}
+
+type vsphereVM struct {
+	VM         string `json:"vm"`
+	Name       string `json:"name"`
+	PowerState string `json:"power_state"`
+	CPUCount   int    `json:"cpu_count"`
+	MemoryMiB  int64  `json:"memory_size_MiB"`
+}
+
+type vsphereGuestIdentity struct {
+	IPAddress string `json:"ip_address"`
+	FullName  struct {
+		DefaultMessage string `json:"default_message"`
+	} `json:"full_name"`
+}
+
+func (v *vsphere) List(ctx context.Context) ([]Machine, error) {
+	auth := base64.StdEncoding.EncodeToString([]byte(v.username + ":" + v.password))
+	var session string
+	_, err := v.client.do(ctx, http.MethodPost, "/api/session", http.Header{"Authorization": []string{"Basic " + auth}}, nil, &session)
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{"Vmware-Api-Session-Id": []string{session}}
+	defer func() {
+		_, _ = v.client.do(context.Background(), http.MethodDelete, "/api/session", header, nil, nil)
+	}()
+
+	path := "/api/vcenter/vm"
+	if v.folder != "" {
+		path += "?" + url.Values{"folders": []string{v.folder}}.Encode()
+	}
+	var vms []vsphereVM
+	if _, err = v.client.do(ctx, http.MethodGet, path, header, nil, &vms); err != nil {
+		return nil, err
+	}
+	machines := make([]Machine, 0, len(vms))
+	for _, vm := range vms {
+		m := Machine{
+			ID:         vm.VM,
+			Name:       vm.Name,
+			CPU:        vm.CPUCount,
+			MemoryMB:   vm.MemoryMiB,
+			PowerState: vm.PowerState,
+		}
+		// the guest identity is only known while vmware tools are running
+		identity := vsphereGuestIdentity{}
+		if vm.PowerState == "POWERED_ON" {
+			if _, err = v.client.do(ctx, http.MethodGet, fmt.Sprintf("/api/vcenter/vm/%s/guest/identity", vm.VM), header, nil, &identity); err == nil {
+				if identity.IPAddress != "" {
+					m.IPs = []string{identity.IPAddress}
+				}
+				m.OS = identity.FullName.DefaultMessage
+			}
+		}
+		machines = append(machines, m)
+	}
+	return machines, nil
+}
@@ -53,11 +53,15 @@ type clusterOperator struct {
 	upgradeStorage     rest.StandardStorage
 	dnsStorage         rest.StandardStorage
 	templateStorage    rest.StandardStorage
+	clusterTmplStorage rest.StandardStorage
+	maintenanceStorage rest.StandardStorage
+	precheckStorage    rest.StandardStorage
 }
 
 func NewClusterOperator(clusterStorage rest.StandardStorage, nodeStorage rest.StandardStorage,
 	regionStorage rest.StandardStorage, backupStorage rest.StandardStorage, recoveryStorage, backupPointStorage,
-	dnsStorage rest.StandardStorage, templateStorage rest.StandardStorage) Operator {
+	dnsStorage rest.StandardStorage, templateStorage rest.StandardStorage, clusterTmplStorage rest.StandardStorage,
+	maintenanceStorage rest.StandardStorage, precheckStorage rest.StandardStorage) Operator {
 	return &clusterOperator{
 		clusterStorage:     clusterStorage,
 		nodeStorage:        nodeStorage,
@@ -67,6 +71,9 @@ func NewClusterOperator(clusterStorage rest.StandardStorage, nodeStorage rest.St
 		backupPointStorage: backupPointStorage,
 		dnsStorage:         dnsStorage,
 		templateStorage:    templateStorage,
+		clusterTmplStorage: clusterTmplStorage,
+		maintenanceStorage: maintenanceStorage,
+		precheckStorage:    precheckStorage,
 	}
 }
 
@@ -99,12 +106,28 @@ func (c *clusterOperator) ListClusters(ctx context.Context, query *query.Query)
 	if err != nil {
 		return nil, err
 	}
-	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("ClusterList"))
-	return list.(*v1.ClusterList), nil
+	clusters := list.DeepCopyObject().(*v1.ClusterList)
+	for i := range clusters.Items {
+		clusters.Items[i].KubeConfig = nil
+	}
+	clusters.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("ClusterList"))
+	return clusters, nil
 }
 
 func (c *clusterOperator) ListClusterEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
-	return models.ListExV2(ctx, c.clusterStorage, query, c.clusterFuzzyFilter, nil, nil)
+	return models.ListExV2(ctx, c.clusterStorage, query, c.clusterFuzzyFilter, nil, withoutKubeConfig)
+}
+
+// withoutKubeConfig is the mutating func of the listed and watched clusters, their admin kubeconfig
+// is only served by the adminkubeconfig route.
+func withoutKubeConfig(obj runtime.Object) runtime.Object {
+	clu, ok := obj.(*v1.Cluster)
+	if !ok || clu.KubeConfig == nil {
+		return obj
+	}
+	clu = clu.DeepCopy()
+	clu.KubeConfig = nil
+	return clu
 }
 
 func (c *clusterOperator) CreateCluster(ctx context.Context, cluster *v1.Cluster) (*v1.Cluster, error) {
@@ -127,16 +150,33 @@ func (c *clusterOperator) GetClusterEx(ctx context.Context, name string, resourc
 	return cluster.(*v1.Cluster), nil
 }
 
+// DeleteCluster deletes the cluster with the propagation policy requested for its dependents,
+// the garbage collector deletes or orphans them.
 func (c *clusterOperator) DeleteCluster(ctx context.Context, name string) error {
-	var err error
+	opts := &metav1.DeleteOptions{}
+	clu, err := c.GetCluster(ctx, name)
+	if err != nil {
+		return err
+	}
+	if policy := clu.Annotations[common.AnnotationDeletionPropagation]; policy != "" {
+		propagation := metav1.DeletionPropagation(policy)
+		opts.PropagationPolicy = &propagation
+	}
 	_, _, err = c.clusterStorage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
 		return nil
-	}, &metav1.DeleteOptions{})
+	}, opts)
 	return err
 }
 
 func (c *clusterOperator) WatchClusters(ctx context.Context, query *query.Query) (watch.Interface, error) {
-	return models.Watch(ctx, c.clusterStorage, query)
+	w, err := models.Watch(ctx, c.clusterStorage, query)
+	if err != nil {
+		return nil, err
+	}
+	return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+		in.Object = withoutKubeConfig(in.Object)
+		return in, true
+	}), nil
 }
 
 func (c *clusterOperator) WatchNodes(ctx context.Context, query *query.Query) (watch.Interface, error) {
@@ -220,6 +260,15 @@ func (c *clusterOperator) CreateRegion(ctx context.Context, region *v1.Region) (
 	return obj.(*v1.Region), nil
 }
 
+func (c *clusterOperator) UpdateRegion(ctx context.Context, region *v1.Region) (*v1.Region, error) {
+	obj, _, err := c.regionStorage.Update(ctx, region.Name, rest.DefaultUpdatedObjectInfo(region),
+		nil, nil, false, &metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Region), nil
+}
+
 func (c *clusterOperator) DeleteRegion(ctx context.Context, name string) error {
 	_, _, err := c.regionStorage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
 		return nil
@@ -652,3 +701,206 @@ func (c *clusterOperator) templateFuzzyFilter(obj runtime.Object, q *query.Query
 	}
 	return objs
 }
+
+func (c *clusterOperator) ListClusterTemplates(ctx context.Context, query *query.Query) (*v1.ClusterTemplateList, error) {
+	list, err := models.List(ctx, c.clusterTmplStorage, query)
+	if err != nil {
+		return nil, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("ClusterTemplateList"))
+	return list.(*v1.ClusterTemplateList), nil
+}
+
+func (c *clusterOperator) GetClusterTemplate(ctx context.Context, name string) (*v1.ClusterTemplate, error) {
+	return c.GetClusterTemplateEx(ctx, name, "")
+}
+
+func (c *clusterOperator) ListClusterTemplatesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return models.ListExV2(ctx, c.clusterTmplStorage, query, c.clusterTemplateFuzzyFilter, nil, nil)
+}
+
+func (c *clusterOperator) GetClusterTemplateEx(ctx context.Context, name string, resourceVersion string) (*v1.ClusterTemplate, error) {
+	obj, err := models.Get(ctx, c.clusterTmplStorage, name, resourceVersion)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.ClusterTemplate), nil
+}
+
+func (c *clusterOperator) CreateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error) {
+	obj, err := c.clusterTmplStorage.Create(ctx, template, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.ClusterTemplate), nil
+}
+
+func (c *clusterOperator) UpdateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error) {
+	obj, _, err := c.clusterTmplStorage.Update(ctx, template.Name, rest.DefaultUpdatedObjectInfo(template),
+		nil, nil, false, &metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.ClusterTemplate), nil
+}
+
+func (c *clusterOperator) DeleteClusterTemplate(ctx context.Context, name string) error {
+	_, _, err := c.clusterTmplStorage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
+		return nil
+	}, &metav1.DeleteOptions{})
+	return err
+}
+
+func (c *clusterOperator) clusterTemplateFuzzyFilter(obj runtime.Object, q *query.Query) []runtime.Object {
+	templates, ok := obj.(*v1.ClusterTemplateList)
+	if !ok {
+		return nil
+	}
+	objs := make([]runtime.Object, 0, len(templates.Items))
+	for index, template := range templates.Items {
+		selected := true
+		for k, v := range q.FuzzySearch {
+			if !models.ObjectMetaFilter(template.ObjectMeta, k, v) {
+				selected = false
+			}
+		}
+		if selected {
+			objs = append(objs, &templates.Items[index])
+		}
+	}
+	return objs
+}
+
+func (c *clusterOperator) ListCronMaintenances(ctx context.Context, query *query.Query) (*v1.CronMaintenanceList, error) {
+	list, err := models.List(ctx, c.maintenanceStorage, query)
+	if err != nil {
+		return nil, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("CronMaintenanceList"))
+	return list.(*v1.CronMaintenanceList), nil
+}
+
+func (c *clusterOperator) GetCronMaintenance(ctx context.Context, name string) (*v1.CronMaintenance, error) {
+	return c.GetCronMaintenanceEx(ctx, name, "")
+}
+
+func (c *clusterOperator) ListCronMaintenancesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return models.ListExV2(ctx, c.maintenanceStorage, query, c.cronMaintenanceFuzzyFilter, nil, nil)
+}
+
+func (c *clusterOperator) GetCronMaintenanceEx(ctx context.Context, name string, resourceVersion string) (*v1.CronMaintenance, error) {
+	obj, err := models.Get(ctx, c.maintenanceStorage, name, resourceVersion)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.CronMaintenance), nil
+}
+
+func (c *clusterOperator) CreateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error) {
+	obj, err := c.maintenanceStorage.Create(ctx, maintenance, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.CronMaintenance), nil
+}
+
+func (c *clusterOperator) UpdateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error) {
+	obj, _, err := c.maintenanceStorage.Update(ctx, maintenance.Name, rest.DefaultUpdatedObjectInfo(maintenance),
+		nil, nil, false, &metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.CronMaintenance), nil
+}
+
+func (c *clusterOperator) DeleteCronMaintenance(ctx context.Context, name string) error {
+	_, _, err := c.maintenanceStorage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
+		return nil
+	}, &metav1.DeleteOptions{})
+	return err
+}
+
+func (c *clusterOperator) cronMaintenanceFuzzyFilter(obj runtime.Object, q *query.Query) []runtime.Object {
+	maintenances, ok := obj.(*v1.CronMaintenanceList)
+	if !ok {
+		return nil
+	}
+	objs := make([]runtime.Object, 0, len(maintenances.Items))
+	for index, maintenance := range maintenances.Items {
+		selected := true
+		for k, v := range q.FuzzySearch {
+			if !models.ObjectMetaFilter(maintenance.ObjectMeta, k, v) {
+				selected = false
+			}
+		}
+		if selected {
+			objs = append(objs, &maintenances.Items[index])
+		}
+	}
+	return objs
+}
+
+func (c *clusterOperator) ListPrechecks(ctx context.Context, query *query.Query) (*v1.PrecheckList, error) {
+	list, err := models.List(ctx, c.precheckStorage, query)
+	if err != nil {
+		return nil, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("PrecheckList"))
+	return list.(*v1.PrecheckList), nil
+}
+
+func (c *clusterOperator) GetPrecheck(ctx context.Context, name string) (*v1.Precheck, error) {
+	return c.GetPrecheckEx(ctx, name, "")
+}
+
+func (c *clusterOperator) ListPrechecksEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return models.ListExV2(ctx, c.precheckStorage, query, c.precheckFuzzyFilter, nil, nil)
+}
+
+func (c *clusterOperator) GetPrecheckEx(ctx context.Context, name string, resourceVersion string) (*v1.Precheck, error) {
+	obj, err := models.Get(ctx, c.precheckStorage, name, resourceVersion)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Precheck), nil
+}
+
+func (c *clusterOperator) CreatePrecheck(ctx context.Context, precheck *v1.Precheck) (*v1.Precheck, error) {
+	obj, err := c.precheckStorage.Create(ctx, precheck, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Precheck), nil
+}
+
+func (c *clusterOperator) DeletePrecheck(ctx context.Context, name string) error {
+	_, _, err := c.precheckStorage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
+		return nil
+	}, &metav1.DeleteOptions{})
+	return err
+}
+
+// precheckFuzzyFilter matches the key node against the checked nodes, so the history of a node can be listed.
+func (c *clusterOperator) precheckFuzzyFilter(obj runtime.Object, q *query.Query) []runtime.Object {
+	prechecks, ok := obj.(*v1.PrecheckList)
+	if !ok {
+		return nil
+	}
+	objs := make([]runtime.Object, 0, len(prechecks.Items))
+	for index, precheck := range prechecks.Items {
+		selected := true
+		for k, v := range q.FuzzySearch {
+			if k == "node" {
+				selected = selected && precheck.HasNode(v)
+				continue
+			}
+			if !models.ObjectMetaFilter(precheck.ObjectMeta, k, v) {
+				selected = false
+			}
+		}
+		if selected {
+			objs = append(objs, &prechecks.Items[index])
+		}
+	}
+	return objs
+}
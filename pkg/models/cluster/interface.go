@@ -69,6 +69,15 @@ type Operator interface {
 
 	TemplateReader
 	TemplateWriter
+
+	ClusterTemplateReader
+	ClusterTemplateWriter
+
+	CronMaintenanceReader
+	CronMaintenanceWriter
+
+	PrecheckReader
+	PrecheckWriter
 }
 
 type ClusterReader interface {
@@ -103,6 +112,7 @@ type RegionReaderEx interface {
 
 type RegionWriter interface {
 	CreateRegion(ctx context.Context, region *v1.Region) (*v1.Region, error)
+	UpdateRegion(ctx context.Context, region *v1.Region) (*v1.Region, error)
 	DeleteRegion(ctx context.Context, name string) error
 }
 
@@ -212,3 +222,53 @@ type TemplateWriter interface {
 	DeleteTemplate(ctx context.Context, name string) error
 	DeleteTemplateCollection(ctx context.Context, query *query.Query) error
 }
+
+type ClusterTemplateReader interface {
+	ListClusterTemplates(ctx context.Context, query *query.Query) (*v1.ClusterTemplateList, error)
+	GetClusterTemplate(ctx context.Context, name string) (*v1.ClusterTemplate, error)
+	ClusterTemplateReaderEx
+}
+
+type ClusterTemplateReaderEx interface {
+	ListClusterTemplatesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+	GetClusterTemplateEx(ctx context.Context, name string, resourceVersion string) (*v1.ClusterTemplate, error)
+}
+
+type ClusterTemplateWriter interface {
+	CreateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error)
+	UpdateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error)
+	DeleteClusterTemplate(ctx context.Context, name string) error
+}
+
+type CronMaintenanceReader interface {
+	ListCronMaintenances(ctx context.Context, query *query.Query) (*v1.CronMaintenanceList, error)
+	GetCronMaintenance(ctx context.Context, name string) (*v1.CronMaintenance, error)
+	CronMaintenanceReaderEx
+}
+
+type CronMaintenanceReaderEx interface {
+	ListCronMaintenancesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+	GetCronMaintenanceEx(ctx context.Context, name string, resourceVersion string) (*v1.CronMaintenance, error)
+}
+
+type CronMaintenanceWriter interface {
+	CreateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error)
+	UpdateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error)
+	DeleteCronMaintenance(ctx context.Context, name string) error
+}
+
+type PrecheckReader interface {
+	ListPrechecks(ctx context.Context, query *query.Query) (*v1.PrecheckList, error)
+	GetPrecheck(ctx context.Context, name string) (*v1.Precheck, error)
+	PrecheckReaderEx
+}
+
+type PrecheckReaderEx interface {
+	ListPrechecksEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+	GetPrecheckEx(ctx context.Context, name string, resourceVersion string) (*v1.Precheck, error)
+}
+
+type PrecheckWriter interface {
+	CreatePrecheck(ctx context.Context, precheck *v1.Precheck) (*v1.Precheck, error)
+	DeletePrecheck(ctx context.Context, name string) error
+}
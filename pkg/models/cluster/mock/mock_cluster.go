@@ -656,6 +656,21 @@ func (mr *MockOperatorWriterMockRecorder) DeleteNode(ctx, name interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNode", reflect.TypeOf((*MockOperatorWriter)(nil).DeleteNode), ctx, name)
 }
 
+// UpdateRegion mocks base method.
+func (m *MockOperatorWriter) UpdateRegion(ctx context.Context, region *v1.Region) (*v1.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRegion", ctx, region)
+	ret0, _ := ret[0].(*v1.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRegion indicates an expected call of UpdateRegion.
+func (mr *MockOperatorWriterMockRecorder) UpdateRegion(ctx, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRegion", reflect.TypeOf((*MockOperatorWriter)(nil).UpdateRegion), ctx, region)
+}
+
 // DeleteRegion mocks base method.
 func (m *MockOperatorWriter) DeleteRegion(ctx context.Context, name string) error {
 	m.ctrl.T.Helper()
@@ -813,6 +828,36 @@ func (mr *MockOperatorMockRecorder) CreateCluster(ctx, cluster interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCluster", reflect.TypeOf((*MockOperator)(nil).CreateCluster), ctx, cluster)
 }
 
+// CreateClusterTemplate mocks base method.
+func (m *MockOperator) CreateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClusterTemplate", ctx, template)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateClusterTemplate indicates an expected call of CreateClusterTemplate.
+func (mr *MockOperatorMockRecorder) CreateClusterTemplate(ctx, template interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClusterTemplate", reflect.TypeOf((*MockOperator)(nil).CreateClusterTemplate), ctx, template)
+}
+
+// CreateCronMaintenance mocks base method.
+func (m *MockOperator) CreateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCronMaintenance", ctx, maintenance)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCronMaintenance indicates an expected call of CreateCronMaintenance.
+func (mr *MockOperatorMockRecorder) CreateCronMaintenance(ctx, maintenance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCronMaintenance", reflect.TypeOf((*MockOperator)(nil).CreateCronMaintenance), ctx, maintenance)
+}
+
 // CreateDomain mocks base method.
 func (m *MockOperator) CreateDomain(ctc context.Context, domain *v1.Domain) (*v1.Domain, error) {
 	m.ctrl.T.Helper()
@@ -843,6 +888,21 @@ func (mr *MockOperatorMockRecorder) CreateNode(ctx, node interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNode", reflect.TypeOf((*MockOperator)(nil).CreateNode), ctx, node)
 }
 
+// CreatePrecheck mocks base method.
+func (m *MockOperator) CreatePrecheck(ctx context.Context, precheck *v1.Precheck) (*v1.Precheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePrecheck", ctx, precheck)
+	ret0, _ := ret[0].(*v1.Precheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePrecheck indicates an expected call of CreatePrecheck.
+func (mr *MockOperatorMockRecorder) CreatePrecheck(ctx, precheck interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePrecheck", reflect.TypeOf((*MockOperator)(nil).CreatePrecheck), ctx, precheck)
+}
+
 // CreateRegion mocks base method.
 func (m *MockOperator) CreateRegion(ctx context.Context, region *v1.Region) (*v1.Region, error) {
 	m.ctrl.T.Helper()
@@ -915,6 +975,34 @@ func (mr *MockOperatorMockRecorder) DeleteCluster(ctx, name interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCluster", reflect.TypeOf((*MockOperator)(nil).DeleteCluster), ctx, name)
 }
 
+// DeleteClusterTemplate mocks base method.
+func (m *MockOperator) DeleteClusterTemplate(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteClusterTemplate", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteClusterTemplate indicates an expected call of DeleteClusterTemplate.
+func (mr *MockOperatorMockRecorder) DeleteClusterTemplate(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClusterTemplate", reflect.TypeOf((*MockOperator)(nil).DeleteClusterTemplate), ctx, name)
+}
+
+// DeleteCronMaintenance mocks base method.
+func (m *MockOperator) DeleteCronMaintenance(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCronMaintenance", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCronMaintenance indicates an expected call of DeleteCronMaintenance.
+func (mr *MockOperatorMockRecorder) DeleteCronMaintenance(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCronMaintenance", reflect.TypeOf((*MockOperator)(nil).DeleteCronMaintenance), ctx, name)
+}
+
 // DeleteDomain mocks base method.
 func (m *MockOperator) DeleteDomain(ctx context.Context, name string) error {
 	m.ctrl.T.Helper()
@@ -943,6 +1031,95 @@ func (mr *MockOperatorMockRecorder) DeleteNode(ctx, name interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNode", reflect.TypeOf((*MockOperator)(nil).DeleteNode), ctx, name)
 }
 
+// DeletePrecheck mocks base method.
+func (m *MockOperator) DeletePrecheck(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePrecheck", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePrecheck indicates an expected call of DeletePrecheck.
+func (mr *MockOperatorMockRecorder) DeletePrecheck(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePrecheck", reflect.TypeOf((*MockOperator)(nil).DeletePrecheck), ctx, name)
+}
+
+// GetPrecheck mocks base method.
+func (m *MockOperator) GetPrecheck(ctx context.Context, name string) (*v1.Precheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrecheck", ctx, name)
+	ret0, _ := ret[0].(*v1.Precheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrecheck indicates an expected call of GetPrecheck.
+func (mr *MockOperatorMockRecorder) GetPrecheck(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrecheck", reflect.TypeOf((*MockOperator)(nil).GetPrecheck), ctx, name)
+}
+
+// GetPrecheckEx mocks base method.
+func (m *MockOperator) GetPrecheckEx(ctx context.Context, name string, resourceVersion string) (*v1.Precheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrecheckEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.Precheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrecheckEx indicates an expected call of GetPrecheckEx.
+func (mr *MockOperatorMockRecorder) GetPrecheckEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrecheckEx", reflect.TypeOf((*MockOperator)(nil).GetPrecheckEx), ctx, name, resourceVersion)
+}
+
+// ListPrechecks mocks base method.
+func (m *MockOperator) ListPrechecks(ctx context.Context, query *query.Query) (*v1.PrecheckList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrechecks", ctx, query)
+	ret0, _ := ret[0].(*v1.PrecheckList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrechecks indicates an expected call of ListPrechecks.
+func (mr *MockOperatorMockRecorder) ListPrechecks(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrechecks", reflect.TypeOf((*MockOperator)(nil).ListPrechecks), ctx, query)
+}
+
+// ListPrechecksEx mocks base method.
+func (m *MockOperator) ListPrechecksEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrechecksEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrechecksEx indicates an expected call of ListPrechecksEx.
+func (mr *MockOperatorMockRecorder) ListPrechecksEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrechecksEx", reflect.TypeOf((*MockOperator)(nil).ListPrechecksEx), ctx, query)
+}
+
+// UpdateRegion mocks base method.
+func (m *MockOperator) UpdateRegion(ctx context.Context, region *v1.Region) (*v1.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRegion", ctx, region)
+	ret0, _ := ret[0].(*v1.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRegion indicates an expected call of UpdateRegion.
+func (mr *MockOperatorMockRecorder) UpdateRegion(ctx, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRegion", reflect.TypeOf((*MockOperator)(nil).UpdateRegion), ctx, region)
+}
+
 // DeleteRegion mocks base method.
 func (m *MockOperator) DeleteRegion(ctx context.Context, name string) error {
 	m.ctrl.T.Helper()
@@ -1075,6 +1252,66 @@ func (mr *MockOperatorMockRecorder) GetClusterEx(ctx, name, resourceVersion inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterEx", reflect.TypeOf((*MockOperator)(nil).GetClusterEx), ctx, name, resourceVersion)
 }
 
+// GetClusterTemplate mocks base method.
+func (m *MockOperator) GetClusterTemplate(ctx context.Context, name string) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterTemplate", ctx, name)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterTemplate indicates an expected call of GetClusterTemplate.
+func (mr *MockOperatorMockRecorder) GetClusterTemplate(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterTemplate", reflect.TypeOf((*MockOperator)(nil).GetClusterTemplate), ctx, name)
+}
+
+// GetCronMaintenance mocks base method.
+func (m *MockOperator) GetCronMaintenance(ctx context.Context, name string) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCronMaintenance", ctx, name)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCronMaintenance indicates an expected call of GetCronMaintenance.
+func (mr *MockOperatorMockRecorder) GetCronMaintenance(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCronMaintenance", reflect.TypeOf((*MockOperator)(nil).GetCronMaintenance), ctx, name)
+}
+
+// GetClusterTemplateEx mocks base method.
+func (m *MockOperator) GetClusterTemplateEx(ctx context.Context, name, resourceVersion string) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterTemplateEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterTemplateEx indicates an expected call of GetClusterTemplateEx.
+func (mr *MockOperatorMockRecorder) GetClusterTemplateEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterTemplateEx", reflect.TypeOf((*MockOperator)(nil).GetClusterTemplateEx), ctx, name, resourceVersion)
+}
+
+// GetCronMaintenanceEx mocks base method.
+func (m *MockOperator) GetCronMaintenanceEx(ctx context.Context, name, resourceVersion string) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCronMaintenanceEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCronMaintenanceEx indicates an expected call of GetCronMaintenanceEx.
+func (mr *MockOperatorMockRecorder) GetCronMaintenanceEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCronMaintenanceEx", reflect.TypeOf((*MockOperator)(nil).GetCronMaintenanceEx), ctx, name, resourceVersion)
+}
+
 // GetDomain mocks base method.
 func (m *MockOperator) GetDomain(ctx context.Context, name string) (*v1.Domain, error) {
 	m.ctrl.T.Helper()
@@ -1255,6 +1492,66 @@ func (mr *MockOperatorMockRecorder) ListClusterEx(ctx, query interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterEx", reflect.TypeOf((*MockOperator)(nil).ListClusterEx), ctx, query)
 }
 
+// ListClusterTemplates mocks base method.
+func (m *MockOperator) ListClusterTemplates(ctx context.Context, query *query.Query) (*v1.ClusterTemplateList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterTemplates", ctx, query)
+	ret0, _ := ret[0].(*v1.ClusterTemplateList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterTemplates indicates an expected call of ListClusterTemplates.
+func (mr *MockOperatorMockRecorder) ListClusterTemplates(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterTemplates", reflect.TypeOf((*MockOperator)(nil).ListClusterTemplates), ctx, query)
+}
+
+// ListCronMaintenances mocks base method.
+func (m *MockOperator) ListCronMaintenances(ctx context.Context, query *query.Query) (*v1.CronMaintenanceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCronMaintenances", ctx, query)
+	ret0, _ := ret[0].(*v1.CronMaintenanceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCronMaintenances indicates an expected call of ListCronMaintenances.
+func (mr *MockOperatorMockRecorder) ListCronMaintenances(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCronMaintenances", reflect.TypeOf((*MockOperator)(nil).ListCronMaintenances), ctx, query)
+}
+
+// ListClusterTemplatesEx mocks base method.
+func (m *MockOperator) ListClusterTemplatesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterTemplatesEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterTemplatesEx indicates an expected call of ListClusterTemplatesEx.
+func (mr *MockOperatorMockRecorder) ListClusterTemplatesEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterTemplatesEx", reflect.TypeOf((*MockOperator)(nil).ListClusterTemplatesEx), ctx, query)
+}
+
+// ListCronMaintenancesEx mocks base method.
+func (m *MockOperator) ListCronMaintenancesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCronMaintenancesEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCronMaintenancesEx indicates an expected call of ListCronMaintenancesEx.
+func (mr *MockOperatorMockRecorder) ListCronMaintenancesEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCronMaintenancesEx", reflect.TypeOf((*MockOperator)(nil).ListCronMaintenancesEx), ctx, query)
+}
+
 // ListClusters mocks base method.
 func (m *MockOperator) ListClusters(ctx context.Context, query *query.Query) (*v1.ClusterList, error) {
 	m.ctrl.T.Helper()
@@ -1450,6 +1747,36 @@ func (mr *MockOperatorMockRecorder) UpdateCluster(ctx, cluster interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCluster", reflect.TypeOf((*MockOperator)(nil).UpdateCluster), ctx, cluster)
 }
 
+// UpdateClusterTemplate mocks base method.
+func (m *MockOperator) UpdateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClusterTemplate", ctx, template)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateClusterTemplate indicates an expected call of UpdateClusterTemplate.
+func (mr *MockOperatorMockRecorder) UpdateClusterTemplate(ctx, template interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClusterTemplate", reflect.TypeOf((*MockOperator)(nil).UpdateClusterTemplate), ctx, template)
+}
+
+// UpdateCronMaintenance mocks base method.
+func (m *MockOperator) UpdateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCronMaintenance", ctx, maintenance)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCronMaintenance indicates an expected call of UpdateCronMaintenance.
+func (mr *MockOperatorMockRecorder) UpdateCronMaintenance(ctx, maintenance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCronMaintenance", reflect.TypeOf((*MockOperator)(nil).UpdateCronMaintenance), ctx, maintenance)
+}
+
 // UpdateDomain mocks base method.
 func (m *MockOperator) UpdateDomain(ctx context.Context, domain *v1.Domain) (*v1.Domain, error) {
 	m.ctrl.T.Helper()
@@ -1992,6 +2319,21 @@ func (mr *MockRegionWriterMockRecorder) CreateRegion(ctx, region interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRegion", reflect.TypeOf((*MockRegionWriter)(nil).CreateRegion), ctx, region)
 }
 
+// UpdateRegion mocks base method.
+func (m *MockRegionWriter) UpdateRegion(ctx context.Context, region *v1.Region) (*v1.Region, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRegion", ctx, region)
+	ret0, _ := ret[0].(*v1.Region)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRegion indicates an expected call of UpdateRegion.
+func (mr *MockRegionWriterMockRecorder) UpdateRegion(ctx, region interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRegion", reflect.TypeOf((*MockRegionWriter)(nil).UpdateRegion), ctx, region)
+}
+
 // DeleteRegion mocks base method.
 func (m *MockRegionWriter) DeleteRegion(ctx context.Context, name string) error {
 	m.ctrl.T.Helper()
@@ -3312,3 +3654,597 @@ func (mr *MockTemplateWriterMockRecorder) UpdateTemplate(ctx, template interface
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTemplate", reflect.TypeOf((*MockTemplateWriter)(nil).UpdateTemplate), ctx, template)
 }
+
+// MockClusterTemplateReader is a mock of ClusterTemplateReader interface.
+type MockClusterTemplateReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockClusterTemplateReaderMockRecorder
+}
+
+// MockClusterTemplateReaderMockRecorder is the mock recorder for MockClusterTemplateReader.
+type MockClusterTemplateReaderMockRecorder struct {
+	mock *MockClusterTemplateReader
+}
+
+// NewMockClusterTemplateReader creates a new mock instance.
+func NewMockClusterTemplateReader(ctrl *gomock.Controller) *MockClusterTemplateReader {
+	mock := &MockClusterTemplateReader{ctrl: ctrl}
+	mock.recorder = &MockClusterTemplateReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClusterTemplateReader) EXPECT() *MockClusterTemplateReaderMockRecorder {
+	return m.recorder
+}
+
+// GetClusterTemplate mocks base method.
+func (m *MockClusterTemplateReader) GetClusterTemplate(ctx context.Context, name string) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterTemplate", ctx, name)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterTemplate indicates an expected call of GetClusterTemplate.
+func (mr *MockClusterTemplateReaderMockRecorder) GetClusterTemplate(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterTemplate", reflect.TypeOf((*MockClusterTemplateReader)(nil).GetClusterTemplate), ctx, name)
+}
+
+// GetClusterTemplateEx mocks base method.
+func (m *MockClusterTemplateReader) GetClusterTemplateEx(ctx context.Context, name, resourceVersion string) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterTemplateEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterTemplateEx indicates an expected call of GetClusterTemplateEx.
+func (mr *MockClusterTemplateReaderMockRecorder) GetClusterTemplateEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterTemplateEx", reflect.TypeOf((*MockClusterTemplateReader)(nil).GetClusterTemplateEx), ctx, name, resourceVersion)
+}
+
+// ListClusterTemplates mocks base method.
+func (m *MockClusterTemplateReader) ListClusterTemplates(ctx context.Context, query *query.Query) (*v1.ClusterTemplateList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterTemplates", ctx, query)
+	ret0, _ := ret[0].(*v1.ClusterTemplateList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterTemplates indicates an expected call of ListClusterTemplates.
+func (mr *MockClusterTemplateReaderMockRecorder) ListClusterTemplates(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterTemplates", reflect.TypeOf((*MockClusterTemplateReader)(nil).ListClusterTemplates), ctx, query)
+}
+
+// ListClusterTemplatesEx mocks base method.
+func (m *MockClusterTemplateReader) ListClusterTemplatesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterTemplatesEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterTemplatesEx indicates an expected call of ListClusterTemplatesEx.
+func (mr *MockClusterTemplateReaderMockRecorder) ListClusterTemplatesEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterTemplatesEx", reflect.TypeOf((*MockClusterTemplateReader)(nil).ListClusterTemplatesEx), ctx, query)
+}
+
+// MockClusterTemplateReaderEx is a mock of ClusterTemplateReaderEx interface.
+type MockClusterTemplateReaderEx struct {
+	ctrl     *gomock.Controller
+	recorder *MockClusterTemplateReaderExMockRecorder
+}
+
+// MockClusterTemplateReaderExMockRecorder is the mock recorder for MockClusterTemplateReaderEx.
+type MockClusterTemplateReaderExMockRecorder struct {
+	mock *MockClusterTemplateReaderEx
+}
+
+// NewMockClusterTemplateReaderEx creates a new mock instance.
+func NewMockClusterTemplateReaderEx(ctrl *gomock.Controller) *MockClusterTemplateReaderEx {
+	mock := &MockClusterTemplateReaderEx{ctrl: ctrl}
+	mock.recorder = &MockClusterTemplateReaderExMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClusterTemplateReaderEx) EXPECT() *MockClusterTemplateReaderExMockRecorder {
+	return m.recorder
+}
+
+// GetClusterTemplateEx mocks base method.
+func (m *MockClusterTemplateReaderEx) GetClusterTemplateEx(ctx context.Context, name, resourceVersion string) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClusterTemplateEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClusterTemplateEx indicates an expected call of GetClusterTemplateEx.
+func (mr *MockClusterTemplateReaderExMockRecorder) GetClusterTemplateEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClusterTemplateEx", reflect.TypeOf((*MockClusterTemplateReaderEx)(nil).GetClusterTemplateEx), ctx, name, resourceVersion)
+}
+
+// ListClusterTemplatesEx mocks base method.
+func (m *MockClusterTemplateReaderEx) ListClusterTemplatesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListClusterTemplatesEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListClusterTemplatesEx indicates an expected call of ListClusterTemplatesEx.
+func (mr *MockClusterTemplateReaderExMockRecorder) ListClusterTemplatesEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListClusterTemplatesEx", reflect.TypeOf((*MockClusterTemplateReaderEx)(nil).ListClusterTemplatesEx), ctx, query)
+}
+
+// MockClusterTemplateWriter is a mock of ClusterTemplateWriter interface.
+type MockClusterTemplateWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockClusterTemplateWriterMockRecorder
+}
+
+// MockClusterTemplateWriterMockRecorder is the mock recorder for MockClusterTemplateWriter.
+type MockClusterTemplateWriterMockRecorder struct {
+	mock *MockClusterTemplateWriter
+}
+
+// NewMockClusterTemplateWriter creates a new mock instance.
+func NewMockClusterTemplateWriter(ctrl *gomock.Controller) *MockClusterTemplateWriter {
+	mock := &MockClusterTemplateWriter{ctrl: ctrl}
+	mock.recorder = &MockClusterTemplateWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClusterTemplateWriter) EXPECT() *MockClusterTemplateWriterMockRecorder {
+	return m.recorder
+}
+
+// CreateClusterTemplate mocks base method.
+func (m *MockClusterTemplateWriter) CreateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClusterTemplate", ctx, template)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateClusterTemplate indicates an expected call of CreateClusterTemplate.
+func (mr *MockClusterTemplateWriterMockRecorder) CreateClusterTemplate(ctx, template interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClusterTemplate", reflect.TypeOf((*MockClusterTemplateWriter)(nil).CreateClusterTemplate), ctx, template)
+}
+
+// DeleteClusterTemplate mocks base method.
+func (m *MockClusterTemplateWriter) DeleteClusterTemplate(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteClusterTemplate", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteClusterTemplate indicates an expected call of DeleteClusterTemplate.
+func (mr *MockClusterTemplateWriterMockRecorder) DeleteClusterTemplate(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteClusterTemplate", reflect.TypeOf((*MockClusterTemplateWriter)(nil).DeleteClusterTemplate), ctx, name)
+}
+
+// UpdateClusterTemplate mocks base method.
+func (m *MockClusterTemplateWriter) UpdateClusterTemplate(ctx context.Context, template *v1.ClusterTemplate) (*v1.ClusterTemplate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClusterTemplate", ctx, template)
+	ret0, _ := ret[0].(*v1.ClusterTemplate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateClusterTemplate indicates an expected call of UpdateClusterTemplate.
+func (mr *MockClusterTemplateWriterMockRecorder) UpdateClusterTemplate(ctx, template interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClusterTemplate", reflect.TypeOf((*MockClusterTemplateWriter)(nil).UpdateClusterTemplate), ctx, template)
+}
+
+// MockCronMaintenanceReader is a mock of CronMaintenanceReader interface.
+type MockCronMaintenanceReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronMaintenanceReaderMockRecorder
+}
+
+// MockCronMaintenanceReaderMockRecorder is the mock recorder for MockCronMaintenanceReader.
+type MockCronMaintenanceReaderMockRecorder struct {
+	mock *MockCronMaintenanceReader
+}
+
+// NewMockCronMaintenanceReader creates a new mock instance.
+func NewMockCronMaintenanceReader(ctrl *gomock.Controller) *MockCronMaintenanceReader {
+	mock := &MockCronMaintenanceReader{ctrl: ctrl}
+	mock.recorder = &MockCronMaintenanceReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCronMaintenanceReader) EXPECT() *MockCronMaintenanceReaderMockRecorder {
+	return m.recorder
+}
+
+// GetCronMaintenance mocks base method.
+func (m *MockCronMaintenanceReader) GetCronMaintenance(ctx context.Context, name string) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCronMaintenance", ctx, name)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCronMaintenance indicates an expected call of GetCronMaintenance.
+func (mr *MockCronMaintenanceReaderMockRecorder) GetCronMaintenance(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCronMaintenance", reflect.TypeOf((*MockCronMaintenanceReader)(nil).GetCronMaintenance), ctx, name)
+}
+
+// GetCronMaintenanceEx mocks base method.
+func (m *MockCronMaintenanceReader) GetCronMaintenanceEx(ctx context.Context, name, resourceVersion string) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCronMaintenanceEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCronMaintenanceEx indicates an expected call of GetCronMaintenanceEx.
+func (mr *MockCronMaintenanceReaderMockRecorder) GetCronMaintenanceEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCronMaintenanceEx", reflect.TypeOf((*MockCronMaintenanceReader)(nil).GetCronMaintenanceEx), ctx, name, resourceVersion)
+}
+
+// ListCronMaintenances mocks base method.
+func (m *MockCronMaintenanceReader) ListCronMaintenances(ctx context.Context, query *query.Query) (*v1.CronMaintenanceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCronMaintenances", ctx, query)
+	ret0, _ := ret[0].(*v1.CronMaintenanceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCronMaintenances indicates an expected call of ListCronMaintenances.
+func (mr *MockCronMaintenanceReaderMockRecorder) ListCronMaintenances(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCronMaintenances", reflect.TypeOf((*MockCronMaintenanceReader)(nil).ListCronMaintenances), ctx, query)
+}
+
+// ListCronMaintenancesEx mocks base method.
+func (m *MockCronMaintenanceReader) ListCronMaintenancesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCronMaintenancesEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCronMaintenancesEx indicates an expected call of ListCronMaintenancesEx.
+func (mr *MockCronMaintenanceReaderMockRecorder) ListCronMaintenancesEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCronMaintenancesEx", reflect.TypeOf((*MockCronMaintenanceReader)(nil).ListCronMaintenancesEx), ctx, query)
+}
+
+// MockCronMaintenanceReaderEx is a mock of CronMaintenanceReaderEx interface.
+type MockCronMaintenanceReaderEx struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronMaintenanceReaderExMockRecorder
+}
+
+// MockCronMaintenanceReaderExMockRecorder is the mock recorder for MockCronMaintenanceReaderEx.
+type MockCronMaintenanceReaderExMockRecorder struct {
+	mock *MockCronMaintenanceReaderEx
+}
+
+// NewMockCronMaintenanceReaderEx creates a new mock instance.
+func NewMockCronMaintenanceReaderEx(ctrl *gomock.Controller) *MockCronMaintenanceReaderEx {
+	mock := &MockCronMaintenanceReaderEx{ctrl: ctrl}
+	mock.recorder = &MockCronMaintenanceReaderExMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCronMaintenanceReaderEx) EXPECT() *MockCronMaintenanceReaderExMockRecorder {
+	return m.recorder
+}
+
+// GetCronMaintenanceEx mocks base method.
+func (m *MockCronMaintenanceReaderEx) GetCronMaintenanceEx(ctx context.Context, name, resourceVersion string) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCronMaintenanceEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCronMaintenanceEx indicates an expected call of GetCronMaintenanceEx.
+func (mr *MockCronMaintenanceReaderExMockRecorder) GetCronMaintenanceEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCronMaintenanceEx", reflect.TypeOf((*MockCronMaintenanceReaderEx)(nil).GetCronMaintenanceEx), ctx, name, resourceVersion)
+}
+
+// ListCronMaintenancesEx mocks base method.
+func (m *MockCronMaintenanceReaderEx) ListCronMaintenancesEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListCronMaintenancesEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListCronMaintenancesEx indicates an expected call of ListCronMaintenancesEx.
+func (mr *MockCronMaintenanceReaderExMockRecorder) ListCronMaintenancesEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListCronMaintenancesEx", reflect.TypeOf((*MockCronMaintenanceReaderEx)(nil).ListCronMaintenancesEx), ctx, query)
+}
+
+// MockCronMaintenanceWriter is a mock of CronMaintenanceWriter interface.
+type MockCronMaintenanceWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockCronMaintenanceWriterMockRecorder
+}
+
+// MockCronMaintenanceWriterMockRecorder is the mock recorder for MockCronMaintenanceWriter.
+type MockCronMaintenanceWriterMockRecorder struct {
+	mock *MockCronMaintenanceWriter
+}
+
+// NewMockCronMaintenanceWriter creates a new mock instance.
+func NewMockCronMaintenanceWriter(ctrl *gomock.Controller) *MockCronMaintenanceWriter {
+	mock := &MockCronMaintenanceWriter{ctrl: ctrl}
+	mock.recorder = &MockCronMaintenanceWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCronMaintenanceWriter) EXPECT() *MockCronMaintenanceWriterMockRecorder {
+	return m.recorder
+}
+
+// CreateCronMaintenance mocks base method.
+func (m *MockCronMaintenanceWriter) CreateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCronMaintenance", ctx, maintenance)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCronMaintenance indicates an expected call of CreateCronMaintenance.
+func (mr *MockCronMaintenanceWriterMockRecorder) CreateCronMaintenance(ctx, maintenance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCronMaintenance", reflect.TypeOf((*MockCronMaintenanceWriter)(nil).CreateCronMaintenance), ctx, maintenance)
+}
+
+// DeleteCronMaintenance mocks base method.
+func (m *MockCronMaintenanceWriter) DeleteCronMaintenance(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCronMaintenance", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCronMaintenance indicates an expected call of DeleteCronMaintenance.
+func (mr *MockCronMaintenanceWriterMockRecorder) DeleteCronMaintenance(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCronMaintenance", reflect.TypeOf((*MockCronMaintenanceWriter)(nil).DeleteCronMaintenance), ctx, name)
+}
+
+// UpdateCronMaintenance mocks base method.
+func (m *MockCronMaintenanceWriter) UpdateCronMaintenance(ctx context.Context, maintenance *v1.CronMaintenance) (*v1.CronMaintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCronMaintenance", ctx, maintenance)
+	ret0, _ := ret[0].(*v1.CronMaintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCronMaintenance indicates an expected call of UpdateCronMaintenance.
+func (mr *MockCronMaintenanceWriterMockRecorder) UpdateCronMaintenance(ctx, maintenance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCronMaintenance", reflect.TypeOf((*MockCronMaintenanceWriter)(nil).UpdateCronMaintenance), ctx, maintenance)
+}
+
+// MockPrecheckReader is a mock of PrecheckReader interface.
+type MockPrecheckReader struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrecheckReaderMockRecorder
+}
+
+// MockPrecheckReaderMockRecorder is the mock recorder for MockPrecheckReader.
+type MockPrecheckReaderMockRecorder struct {
+	mock *MockPrecheckReader
+}
+
+// NewMockPrecheckReader creates a new mock instance.
+func NewMockPrecheckReader(ctrl *gomock.Controller) *MockPrecheckReader {
+	mock := &MockPrecheckReader{ctrl: ctrl}
+	mock.recorder = &MockPrecheckReaderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrecheckReader) EXPECT() *MockPrecheckReaderMockRecorder {
+	return m.recorder
+}
+
+// GetPrecheck mocks base method.
+func (m *MockPrecheckReader) GetPrecheck(ctx context.Context, name string) (*v1.Precheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrecheck", ctx, name)
+	ret0, _ := ret[0].(*v1.Precheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrecheck indicates an expected call of GetPrecheck.
+func (mr *MockPrecheckReaderMockRecorder) GetPrecheck(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrecheck", reflect.TypeOf((*MockPrecheckReader)(nil).GetPrecheck), ctx, name)
+}
+
+// GetPrecheckEx mocks base method.
+func (m *MockPrecheckReader) GetPrecheckEx(ctx context.Context, name string, resourceVersion string) (*v1.Precheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrecheckEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.Precheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrecheckEx indicates an expected call of GetPrecheckEx.
+func (mr *MockPrecheckReaderMockRecorder) GetPrecheckEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrecheckEx", reflect.TypeOf((*MockPrecheckReader)(nil).GetPrecheckEx), ctx, name, resourceVersion)
+}
+
+// ListPrechecks mocks base method.
+func (m *MockPrecheckReader) ListPrechecks(ctx context.Context, query *query.Query) (*v1.PrecheckList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrechecks", ctx, query)
+	ret0, _ := ret[0].(*v1.PrecheckList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrechecks indicates an expected call of ListPrechecks.
+func (mr *MockPrecheckReaderMockRecorder) ListPrechecks(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrechecks", reflect.TypeOf((*MockPrecheckReader)(nil).ListPrechecks), ctx, query)
+}
+
+// ListPrechecksEx mocks base method.
+func (m *MockPrecheckReader) ListPrechecksEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrechecksEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrechecksEx indicates an expected call of ListPrechecksEx.
+func (mr *MockPrecheckReaderMockRecorder) ListPrechecksEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrechecksEx", reflect.TypeOf((*MockPrecheckReader)(nil).ListPrechecksEx), ctx, query)
+}
+
+// MockPrecheckReaderEx is a mock of PrecheckReaderEx interface.
+type MockPrecheckReaderEx struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrecheckReaderExMockRecorder
+}
+
+// MockPrecheckReaderExMockRecorder is the mock recorder for MockPrecheckReaderEx.
+type MockPrecheckReaderExMockRecorder struct {
+	mock *MockPrecheckReaderEx
+}
+
+// NewMockPrecheckReaderEx creates a new mock instance.
+func NewMockPrecheckReaderEx(ctrl *gomock.Controller) *MockPrecheckReaderEx {
+	mock := &MockPrecheckReaderEx{ctrl: ctrl}
+	mock.recorder = &MockPrecheckReaderExMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrecheckReaderEx) EXPECT() *MockPrecheckReaderExMockRecorder {
+	return m.recorder
+}
+
+// GetPrecheckEx mocks base method.
+func (m *MockPrecheckReaderEx) GetPrecheckEx(ctx context.Context, name string, resourceVersion string) (*v1.Precheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrecheckEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.Precheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrecheckEx indicates an expected call of GetPrecheckEx.
+func (mr *MockPrecheckReaderExMockRecorder) GetPrecheckEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrecheckEx", reflect.TypeOf((*MockPrecheckReaderEx)(nil).GetPrecheckEx), ctx, name, resourceVersion)
+}
+
+// ListPrechecksEx mocks base method.
+func (m *MockPrecheckReaderEx) ListPrechecksEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPrechecksEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPrechecksEx indicates an expected call of ListPrechecksEx.
+func (mr *MockPrecheckReaderExMockRecorder) ListPrechecksEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrechecksEx", reflect.TypeOf((*MockPrecheckReaderEx)(nil).ListPrechecksEx), ctx, query)
+}
+
+// MockPrecheckWriter is a mock of PrecheckWriter interface.
+type MockPrecheckWriter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPrecheckWriterMockRecorder
+}
+
+// MockPrecheckWriterMockRecorder is the mock recorder for MockPrecheckWriter.
+type MockPrecheckWriterMockRecorder struct {
+	mock *MockPrecheckWriter
+}
+
+// NewMockPrecheckWriter creates a new mock instance.
+func NewMockPrecheckWriter(ctrl *gomock.Controller) *MockPrecheckWriter {
+	mock := &MockPrecheckWriter{ctrl: ctrl}
+	mock.recorder = &MockPrecheckWriterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPrecheckWriter) EXPECT() *MockPrecheckWriterMockRecorder {
+	return m.recorder
+}
+
+// CreatePrecheck mocks base method.
+func (m *MockPrecheckWriter) CreatePrecheck(ctx context.Context, precheck *v1.Precheck) (*v1.Precheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePrecheck", ctx, precheck)
+	ret0, _ := ret[0].(*v1.Precheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePrecheck indicates an expected call of CreatePrecheck.
+func (mr *MockPrecheckWriterMockRecorder) CreatePrecheck(ctx, precheck interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePrecheck", reflect.TypeOf((*MockPrecheckWriter)(nil).CreatePrecheck), ctx, precheck)
+}
+
+// DeletePrecheck mocks base method.
+func (m *MockPrecheckWriter) DeletePrecheck(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePrecheck", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePrecheck indicates an expected call of DeletePrecheck.
+func (mr *MockPrecheckWriterMockRecorder) DeletePrecheck(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePrecheck", reflect.TypeOf((*MockPrecheckWriter)(nil).DeletePrecheck), ctx, name)
+}
@@ -169,6 +169,10 @@ func (i *iamOperator) GetRoleOfUser(ctx context.Context, username string) (*iamv
 	rolebindings, _ := list.(*iamv1.GlobalRoleBindingList)
 	result := make([]iamv1.GlobalRoleBinding, 0)
 	for i, item := range rolebindings.Items {
+		// project bindings grant roles inside a project only, they are not the role of the user.
+		if _, ok := item.Labels[common.LabelProject]; ok {
+			continue
+		}
 		if contains(item.Subjects, username, nil) {
 			result = append(result, rolebindings.Items[i])
 		}
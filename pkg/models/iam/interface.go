@@ -140,3 +140,26 @@ type LoginRecordWriter interface {
 	DeleteLoginRecord(ctx context.Context, name string) error
 	DeleteLoginRecordCollection(ctx context.Context, query *query.Query) error
 }
+
+type ProjectOperator interface {
+	ProjectReader
+	ProjectWriter
+}
+
+type ProjectReader interface {
+	ListProjects(ctx context.Context, query *query.Query) (*iamv1.ProjectList, error)
+	WatchProjects(ctx context.Context, query *query.Query) (watch.Interface, error)
+	GetProject(ctx context.Context, name string) (*iamv1.Project, error)
+	ProjectReaderEx
+}
+
+type ProjectReaderEx interface {
+	GetProjectEx(ctx context.Context, name string, resourceVersion string) (*iamv1.Project, error)
+	ListProjectEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+}
+
+type ProjectWriter interface {
+	CreateProject(ctx context.Context, project *iamv1.Project) (*iamv1.Project, error)
+	UpdateProject(ctx context.Context, project *iamv1.Project) (*iamv1.Project, error)
+	DeleteProject(ctx context.Context, name string) error
+}
@@ -20,7 +20,6 @@ package models
 
 import (
 	"context"
-	"sort"
 
 	"go.uber.org/zap"
 	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
@@ -128,6 +127,9 @@ func (o *Operator[OBJ, LIST]) ListEx(ctx context.Context, q *query.Query) (*Page
 	if o.compareFunc == nil {
 		o.compareFunc = DefaultCompareFunc
 	}
+	if q.Chunk {
+		return ListChunk(ctx, o.storage, q, o.listToSliceFunc, o.mutatingFunc)
+	}
 	list, err := o.storage.List(ctx, &metainternalversion.ListOptions{
 		LabelSelector:        q.GetLabelSelector(),
 		FieldSelector:        q.GetFieldSelector(),
@@ -141,12 +143,7 @@ func (o *Operator[OBJ, LIST]) ListEx(ctx context.Context, q *query.Query) (*Page
 	objs := o.listToSliceFunc(list, q)
 	totalCount := len(objs)
 	// sort
-	sort.Slice(objs, func(i, j int) bool {
-		if q.Reverse {
-			return !o.compareFunc(objs[i], objs[j], q.OrderBy)
-		}
-		return o.compareFunc(objs[i], objs[j], q.OrderBy)
-	})
+	SortObjects(objs, q, o.compareFunc)
 	// limit offset
 	start, end := q.Pagination.GetValidPagination(totalCount)
 
@@ -156,8 +153,9 @@ func (o *Operator[OBJ, LIST]) ListEx(ctx context.Context, q *query.Query) (*Page
 		items = append(items, o.mutatingFunc(objs[i]))
 	}
 	return &PageableResponse{
-		Items:      items,
-		TotalCount: totalCount,
+		Items:           items,
+		TotalCount:      totalCount,
+		ResourceVersion: listResourceVersion(list),
 	}, nil
 }
 
@@ -40,6 +40,12 @@ import (
 type PageableResponse struct {
 	Items      []interface{} `json:"items" description:"paging data"`
 	TotalCount int           `json:"totalCount" description:"total count"`
+	// ResourceVersion is the one of the list, watches started from it miss no change after the list.
+	ResourceVersion string `json:"resourceVersion,omitempty" description:"resource version of the list"`
+	// Continue reads the next chunk of a list limited by limit, it is empty for the last chunk.
+	Continue string `json:"continue,omitempty" description:"continue token of the next chunk"`
+	// RemainingItemCount is the number of resources after the chunk, it is unknown with selectors.
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty" description:"number of resources after the chunk"`
 }
 
 // Deprecated
@@ -103,6 +109,9 @@ func ListExV2(ctx context.Context, s rest.StandardStorage, q *query.Query, litTo
 	if compareFunc == nil {
 		compareFunc = DefaultCompareFunc
 	}
+	if q.Chunk {
+		return ListChunk(ctx, s, q, litToSliceFunc, mutatingFunc)
+	}
 	list, err := s.List(ctx, &metainternalversion.ListOptions{
 		LabelSelector:        q.GetLabelSelector(),
 		FieldSelector:        q.GetFieldSelector(),
@@ -116,12 +125,7 @@ func ListExV2(ctx context.Context, s rest.StandardStorage, q *query.Query, litTo
 	objs := litToSliceFunc(list, q)
 	totalCount := len(objs)
 	// sort
-	sort.Slice(objs, func(i, j int) bool {
-		if q.Reverse {
-			return !compareFunc(objs[i], objs[j], q.OrderBy)
-		}
-		return compareFunc(objs[i], objs[j], q.OrderBy)
-	})
+	SortObjects(objs, q, compareFunc)
 	// limit offset
 	start, end := q.Pagination.GetValidPagination(totalCount)
 
@@ -131,11 +135,94 @@ func ListExV2(ctx context.Context, s rest.StandardStorage, q *query.Query, litTo
 		items = append(items, mutatingFunc(objs[i]))
 	}
 	return &PageableResponse{
-		Items:      items,
-		TotalCount: totalCount,
+		Items:           items,
+		TotalCount:      totalCount,
+		ResourceVersion: listResourceVersion(list),
 	}, nil
 }
 
+// ListChunk reads at most q.Limit resources in the order of their names from the storage instead of all of
+// them, the continue token of the response reads the next chunk. The chunks are fuzzy searched but not sorted
+// or paged, so that the platforms with thousands of resources can list them piece by piece.
+func ListChunk(ctx context.Context, s rest.Lister, q *query.Query, litToSliceFunc ListToObjectSliceFunction, mutatingFunc MutatingFunc) (*PageableResponse, error) {
+	if mutatingFunc == nil {
+		mutatingFunc = DefaultMutatingFunc
+	}
+	// the chunks are read from etcd, a continue token keeps the revision of the first one
+	list, err := s.List(ctx, &metainternalversion.ListOptions{
+		LabelSelector: q.GetLabelSelector(),
+		FieldSelector: q.GetFieldSelector(),
+		Limit:         q.Limit,
+		Continue:      q.Continue,
+	})
+	if err != nil {
+		return nil, err
+	}
+	objs := litToSliceFunc(list, q)
+	items := make([]interface{}, 0, len(objs))
+	for i := range objs {
+		items = append(items, mutatingFunc(objs[i]))
+	}
+	resp := &PageableResponse{
+		Items:           items,
+		TotalCount:      len(items),
+		ResourceVersion: listResourceVersion(list),
+	}
+	if accessor, err := meta.ListAccessor(list); err == nil {
+		resp.Continue = accessor.GetContinue()
+		resp.RemainingItemCount = accessor.GetRemainingItemCount()
+		if resp.RemainingItemCount != nil {
+			resp.TotalCount += int(*resp.RemainingItemCount)
+		}
+	}
+	return resp, nil
+}
+
+// listResourceVersion returns the resource version of the list, it is empty for lists
+// which are not read from the storage.
+func listResourceVersion(list runtime.Object) string {
+	accessor, err := meta.ListAccessor(list)
+	if err != nil {
+		return ""
+	}
+	return accessor.GetResourceVersion()
+}
+
+// SortObjects orders the objects by the field path of q.OrderBy, the objects lacking the field last.
+// compareFunc orders the objects of equal values, and all of them when q.OrderBy is empty.
+func SortObjects(objs []runtime.Object, q *query.Query, compareFunc CompareFunc) {
+	var values []interface{}
+	if q.OrderBy != "" {
+		values = make([]interface{}, len(objs))
+		for i := range objs {
+			values[i], _ = query.FieldValue(objs[i], q.OrderBy)
+		}
+	}
+	less := func(i, j int) bool {
+		if values != nil {
+			if c := query.CompareFieldValues(values[i], values[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return compareFunc(objs[i], objs[j], q.OrderBy)
+	}
+	order := make([]int, len(objs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		if q.Reverse {
+			return less(order[b], order[a])
+		}
+		return less(order[a], order[b])
+	})
+	sorted := make([]runtime.Object, len(objs))
+	for i, j := range order {
+		sorted[i] = objs[j]
+	}
+	copy(objs, sorted)
+}
+
 type ListToObjectSliceFunction func(runtime.Object, *query.Query) []runtime.Object
 type CompareFunc func(left runtime.Object, right runtime.Object, orderBy string) bool
 type FilterFunc func(obj runtime.Object) bool
@@ -0,0 +1,191 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package models
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestSortObjects(t *testing.T) {
+	now := time.Now()
+	node := func(name, ip string, age time.Duration) runtime.Object {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-age))},
+			Status:     v1.NodeStatus{Ipv4DefaultIP: ip},
+		}
+	}
+	names := func(objs []runtime.Object) []string {
+		var names []string
+		for _, obj := range objs {
+			names = append(names, obj.(*v1.Node).Name)
+		}
+		return names
+	}
+	tests := []struct {
+		name    string
+		orderBy string
+		reverse bool
+		want    []string
+	}{
+		{"creation time", "", false, []string{"c", "b", "a", "d"}},
+		{"creation time reversed", "", true, []string{"d", "a", "b", "c"}},
+		{"field, newest first of equal values", "status.ipv4DefaultIP", false, []string{"c", "b", "a", "d"}},
+		{"field reversed", "status.ipv4DefaultIP", true, []string{"d", "a", "b", "c"}},
+		{"name", "metadata.name", false, []string{"a", "b", "c", "d"}},
+		{"missing field", "status.usage.cpuPercent", false, []string{"c", "b", "a", "d"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs := []runtime.Object{
+				node("a", "10.0.0.3", 3*time.Minute),
+				node("b", "10.0.0.1", 2*time.Minute),
+				node("c", "10.0.0.1", time.Minute),
+				node("d", "10.0.0.4", 4*time.Minute),
+			}
+			q := query.New()
+			q.OrderBy, q.Reverse = tt.orderBy, tt.reverse
+			SortObjects(objs, q, DefaultCompareFunc)
+			if got := names(objs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortObjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// chunkLister returns the nodes after the continue token, which is the name of the last node of the previous chunk.
+type chunkLister struct {
+	rest.TableConvertor
+	nodes []v1.Node
+}
+
+func (l *chunkLister) NewList() runtime.Object {
+	return &v1.NodeList{}
+}
+
+func (l *chunkLister) List(_ context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	list := &v1.NodeList{ListMeta: metav1.ListMeta{ResourceVersion: "7"}}
+	for _, node := range l.nodes {
+		if node.Name > options.Continue && (options.Limit == 0 || int64(len(list.Items)) < options.Limit) {
+			list.Items = append(list.Items, node)
+		}
+	}
+	if remaining := int64(len(l.nodes)) - int64(len(list.Items)); len(list.Items) > 0 && list.Items[len(list.Items)-1].Name != l.nodes[len(l.nodes)-1].Name {
+		list.Continue = list.Items[len(list.Items)-1].Name
+		list.RemainingItemCount = &remaining
+	}
+	return list, nil
+}
+
+func TestListChunk(t *testing.T) {
+	lister := &chunkLister{nodes: []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+	}}
+	toSlice := func(list runtime.Object, _ *query.Query) []runtime.Object {
+		var objs []runtime.Object
+		for i := range list.(*v1.NodeList).Items {
+			objs = append(objs, &list.(*v1.NodeList).Items[i])
+		}
+		return objs
+	}
+	q := query.New()
+	q.Limit = 2
+	first, err := ListChunk(context.TODO(), lister, q, toSlice, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first.Items) != 2 || first.Continue != "b" || first.TotalCount != 3 || first.ResourceVersion != "7" {
+		t.Fatalf("unexpected first chunk %+v", first)
+	}
+	q.Continue = first.Continue
+	last, err := ListChunk(context.TODO(), lister, q, toSlice, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(last.Items) != 1 || last.Items[0].(*v1.Node).Name != "c" || last.Continue != "" || last.RemainingItemCount != nil {
+		t.Fatalf("unexpected last chunk %+v", last)
+	}
+}
+
+// chunkStorage lists the nodes of the lister, the other storage methods are not used by the lists.
+type chunkStorage struct {
+	rest.StandardStorage
+	lister *chunkLister
+}
+
+func (s *chunkStorage) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	return s.lister.List(ctx, options)
+}
+
+func TestListExV2Paging(t *testing.T) {
+	storage := &chunkStorage{lister: &chunkLister{nodes: []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c"}},
+	}}}
+	toSlice := func(list runtime.Object, _ *query.Query) []runtime.Object {
+		var objs []runtime.Object
+		for i := range list.(*v1.NodeList).Items {
+			objs = append(objs, &list.(*v1.NodeList).Items[i])
+		}
+		return objs
+	}
+	tests := []struct {
+		name      string
+		rawQuery  string
+		want      []string
+		wantTotal int
+	}{
+		{"page", "limit=1&page=2&orderBy=metadata.name", []string{"b"}, 3},
+		{"paging", "paging=limit%3D1,page%3D3&orderBy=metadata.name", []string{"c"}, 3},
+		{"page ignores chunk", "limit=1&page=2&chunk=true&orderBy=metadata.name", []string{"b"}, 3},
+		{"chunk", "limit=2&chunk=true", []string{"a", "b"}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := restful.NewRequest(httptest.NewRequest(http.MethodGet, "/api/core.kubeclipper.io/v1/nodes?"+tt.rawQuery, nil))
+			got, err := ListExV2(context.TODO(), storage, query.ParseQueryParameter(req), toSlice, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var names []string
+			for _, item := range got.Items {
+				names = append(names, item.(*v1.Node).Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) || got.TotalCount != tt.wantTotal {
+				t.Errorf("ListExV2() = %v of %d, want %v of %d", names, got.TotalCount, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
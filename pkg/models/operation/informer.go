@@ -0,0 +1,72 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package operation
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	listerv1 "github.com/kubeclipper/kubeclipper/pkg/client/lister/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// NewOperationInformer returns an informer caching the operations read from the storage, its
+// indexes answer whether a cluster is running an operation without listing all operations.
+func NewOperationInformer(reader Reader, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
+				return reader.ListOperations(context.TODO(), listOptionsToQuery(options))
+			},
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				w, err := reader.WatchOperations(context.TODO(), listOptionsToQuery(options))
+				if err != nil {
+					return nil, err
+				}
+				// the storage sends cacheable objects to share their serialization, the cache wants the operations.
+				return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+					if co, ok := in.Object.(runtime.CacheableObject); ok {
+						in.Object = co.GetObject()
+					}
+					return in, true
+				}), nil
+			},
+		},
+		&v1.Operation{},
+		resyncPeriod,
+		listerv1.OperationIndexers(),
+	)
+}
+
+func listOptionsToQuery(options metav1.ListOptions) *query.Query {
+	q := query.New()
+	q.LabelSelector = options.LabelSelector
+	q.FieldSelector = options.FieldSelector
+	q.ResourceVersion = options.ResourceVersion
+	q.ResourceVersionMatch = string(options.ResourceVersionMatch)
+	q.Watch = options.Watch
+	q.AllowWatchBookmarks = options.AllowWatchBookmarks
+	q.TimeoutSeconds = options.TimeoutSeconds
+	return q
+}
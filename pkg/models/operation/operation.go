@@ -34,6 +34,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
 )
 
 var _ Operator = (*operationOperator)(nil)
@@ -74,6 +75,8 @@ func (l *operationOperator) GetOperation(ctx context.Context, name string) (*v1.
 }
 
 func (l *operationOperator) CreateOperation(ctx context.Context, operation *v1.Operation) (*v1.Operation, error) {
+	// operations are delivered apart from the request creating them, keep its trace to continue it
+	operation.Annotations = tracing.InjectAnnotations(ctx, operation.Annotations)
 	obj, err := l.storage.Create(ctx, operation, nil, &metav1.CreateOptions{})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,58 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package operation
+
+import (
+	"context"
+
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// ClusterGetter gets the cluster an operation runs on.
+type ClusterGetter interface {
+	GetClusterEx(ctx context.Context, name string, resourceVersion string) (*v1.Cluster, error)
+}
+
+// WithProjectLabel returns an Operator labeling the operations it creates with the project of
+// their cluster, so that the operations are listed with the other resources of the project.
+func WithProjectLabel(op Operator, clusters ClusterGetter) Operator {
+	return &projectLabeler{Operator: op, clusters: clusters}
+}
+
+type projectLabeler struct {
+	Operator
+	clusters ClusterGetter
+}
+
+func (p *projectLabeler) CreateOperation(ctx context.Context, operation *v1.Operation) (*v1.Operation, error) {
+	name := operation.Labels[common.LabelClusterName]
+	if _, ok := operation.Labels[common.LabelProject]; !ok && name != "" {
+		c, err := p.clusters.GetClusterEx(ctx, name, "0")
+		switch {
+		case apimachineryErrors.IsNotFound(err):
+		case err != nil:
+			return nil, err
+		case c.Labels[common.LabelProject] != "":
+			operation.Labels[common.LabelProject] = c.Labels[common.LabelProject]
+		}
+	}
+	return p.Operator.CreateOperation(ctx, operation)
+}
@@ -0,0 +1,77 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package operation
+
+import (
+	"context"
+	"testing"
+
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+type fakeClusters map[string]*v1.Cluster
+
+func (f fakeClusters) GetClusterEx(_ context.Context, name string, _ string) (*v1.Cluster, error) {
+	if c, ok := f[name]; ok {
+		return c, nil
+	}
+	return nil, apimachineryErrors.NewNotFound(v1.Resource("clusters"), name)
+}
+
+type createRecorder struct {
+	Operator
+	created *v1.Operation
+}
+
+func (r *createRecorder) CreateOperation(_ context.Context, operation *v1.Operation) (*v1.Operation, error) {
+	r.created = operation
+	return operation, nil
+}
+
+func TestWithProjectLabel(t *testing.T) {
+	clusters := fakeClusters{
+		"team": {ObjectMeta: metav1.ObjectMeta{Name: "team", Labels: map[string]string{common.LabelProject: "team-a"}}},
+		"free": {ObjectMeta: metav1.ObjectMeta{Name: "free"}},
+	}
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		project string
+	}{
+		{name: "cluster of project", labels: map[string]string{common.LabelClusterName: "team"}, project: "team-a"},
+		{name: "cluster without project", labels: map[string]string{common.LabelClusterName: "free"}},
+		{name: "deleted cluster", labels: map[string]string{common.LabelClusterName: "gone"}},
+		{name: "project set", labels: map[string]string{common.LabelClusterName: "team", common.LabelProject: "team-b"}, project: "team-b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &createRecorder{}
+			op := WithProjectLabel(recorder, clusters)
+			if _, err := op.CreateOperation(context.TODO(), &v1.Operation{ObjectMeta: metav1.ObjectMeta{Labels: tt.labels}}); err != nil {
+				t.Fatalf("CreateOperation() error = %v", err)
+			}
+			if got := recorder.created.Labels[common.LabelProject]; got != tt.project {
+				t.Errorf("CreateOperation() project = %q, want %q", got, tt.project)
+			}
+		})
+	}
+}
@@ -34,6 +34,12 @@ type Operator interface {
 
 	EventReader
 	EventWriter
+
+	NotifierReader
+	NotifierWriter
+
+	NotificationReader
+	NotificationWriter
 }
 
 type Reader interface {
@@ -64,3 +70,80 @@ type EventWriter interface {
 	DeleteEvent(ctx context.Context, name string) error
 	DeleteEventCollection(ctx context.Context, query *query.Query) error
 }
+
+type NotifierReader interface {
+	ListNotifiers(ctx context.Context, query *query.Query) (*v1.NotifierList, error)
+	GetNotifier(ctx context.Context, name string) (*v1.Notifier, error)
+	NotifierReaderEx
+}
+
+type NotifierReaderEx interface {
+	ListNotifiersEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+	GetNotifierEx(ctx context.Context, name string, resourceVersion string) (*v1.Notifier, error)
+}
+
+type NotifierWriter interface {
+	CreateNotifier(ctx context.Context, notifier *v1.Notifier) (*v1.Notifier, error)
+	UpdateNotifier(ctx context.Context, notifier *v1.Notifier) (*v1.Notifier, error)
+	DeleteNotifier(ctx context.Context, name string) error
+}
+
+type NotificationReader interface {
+	ListNotifications(ctx context.Context, query *query.Query) (*v1.NotificationList, error)
+	GetNotification(ctx context.Context, name string) (*v1.Notification, error)
+	NotificationReaderEx
+}
+
+type NotificationReaderEx interface {
+	ListNotificationsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+	GetNotificationEx(ctx context.Context, name string, resourceVersion string) (*v1.Notification, error)
+}
+
+type NotificationWriter interface {
+	CreateNotification(ctx context.Context, notification *v1.Notification) (*v1.Notification, error)
+	UpdateNotification(ctx context.Context, notification *v1.Notification) (*v1.Notification, error)
+	DeleteNotification(ctx context.Context, name string) error
+}
+
+type WebhookConfigurationOperator interface {
+	WebhookConfigurationReader
+	WebhookConfigurationWriter
+}
+
+type WebhookConfigurationReader interface {
+	ListWebhookConfigurations(ctx context.Context, query *query.Query) (*v1.WebhookConfigurationList, error)
+	GetWebhookConfiguration(ctx context.Context, name string) (*v1.WebhookConfiguration, error)
+	WebhookConfigurationReaderEx
+}
+
+type WebhookConfigurationReaderEx interface {
+	ListWebhookConfigurationsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+	GetWebhookConfigurationEx(ctx context.Context, name string, resourceVersion string) (*v1.WebhookConfiguration, error)
+}
+
+type WebhookConfigurationWriter interface {
+	CreateWebhookConfiguration(ctx context.Context, config *v1.WebhookConfiguration) (*v1.WebhookConfiguration, error)
+	UpdateWebhookConfiguration(ctx context.Context, config *v1.WebhookConfiguration) (*v1.WebhookConfiguration, error)
+	DeleteWebhookConfiguration(ctx context.Context, name string) error
+}
+
+type ObjectEventOperator interface {
+	ObjectEventReader
+	ObjectEventWriter
+}
+
+type ObjectEventReader interface {
+	ListObjectEvents(ctx context.Context, query *query.Query) (*v1.ObjectEventList, error)
+	GetObjectEvent(ctx context.Context, name string) (*v1.ObjectEvent, error)
+	ObjectEventReaderEx
+}
+
+type ObjectEventReaderEx interface {
+	ListObjectEventsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error)
+	GetObjectEventEx(ctx context.Context, name string, resourceVersion string) (*v1.ObjectEvent, error)
+}
+
+type ObjectEventWriter interface {
+	CreateObjectEvent(ctx context.Context, event *v1.ObjectEvent) (*v1.ObjectEvent, error)
+	UpdateObjectEvent(ctx context.Context, event *v1.ObjectEvent) (*v1.ObjectEvent, error)
+}
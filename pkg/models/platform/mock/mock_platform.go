@@ -499,3 +499,211 @@ func (mr *MockEventWriterMockRecorder) DeleteEventCollection(ctx, query interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEventCollection", reflect.TypeOf((*MockEventWriter)(nil).DeleteEventCollection), ctx, query)
 }
+
+// ListNotifiers mocks base method
+func (m *MockOperator) ListNotifiers(ctx context.Context, query *query.Query) (*v1.NotifierList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifiers", ctx, query)
+	ret0, _ := ret[0].(*v1.NotifierList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifiers indicates an expected call of ListNotifiers
+func (mr *MockOperatorMockRecorder) ListNotifiers(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifiers", reflect.TypeOf((*MockOperator)(nil).ListNotifiers), ctx, query)
+}
+
+// GetNotifier mocks base method
+func (m *MockOperator) GetNotifier(ctx context.Context, name string) (*v1.Notifier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifier", ctx, name)
+	ret0, _ := ret[0].(*v1.Notifier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifier indicates an expected call of GetNotifier
+func (mr *MockOperatorMockRecorder) GetNotifier(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifier", reflect.TypeOf((*MockOperator)(nil).GetNotifier), ctx, name)
+}
+
+// ListNotifiersEx mocks base method
+func (m *MockOperator) ListNotifiersEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifiersEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifiersEx indicates an expected call of ListNotifiersEx
+func (mr *MockOperatorMockRecorder) ListNotifiersEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifiersEx", reflect.TypeOf((*MockOperator)(nil).ListNotifiersEx), ctx, query)
+}
+
+// GetNotifierEx mocks base method
+func (m *MockOperator) GetNotifierEx(ctx context.Context, name string, resourceVersion string) (*v1.Notifier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotifierEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.Notifier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotifierEx indicates an expected call of GetNotifierEx
+func (mr *MockOperatorMockRecorder) GetNotifierEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotifierEx", reflect.TypeOf((*MockOperator)(nil).GetNotifierEx), ctx, name, resourceVersion)
+}
+
+// CreateNotifier mocks base method
+func (m *MockOperator) CreateNotifier(ctx context.Context, notifier *v1.Notifier) (*v1.Notifier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotifier", ctx, notifier)
+	ret0, _ := ret[0].(*v1.Notifier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotifier indicates an expected call of CreateNotifier
+func (mr *MockOperatorMockRecorder) CreateNotifier(ctx, notifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotifier", reflect.TypeOf((*MockOperator)(nil).CreateNotifier), ctx, notifier)
+}
+
+// UpdateNotifier mocks base method
+func (m *MockOperator) UpdateNotifier(ctx context.Context, notifier *v1.Notifier) (*v1.Notifier, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotifier", ctx, notifier)
+	ret0, _ := ret[0].(*v1.Notifier)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotifier indicates an expected call of UpdateNotifier
+func (mr *MockOperatorMockRecorder) UpdateNotifier(ctx, notifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotifier", reflect.TypeOf((*MockOperator)(nil).UpdateNotifier), ctx, notifier)
+}
+
+// DeleteNotifier mocks base method
+func (m *MockOperator) DeleteNotifier(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNotifier", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNotifier indicates an expected call of DeleteNotifier
+func (mr *MockOperatorMockRecorder) DeleteNotifier(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotifier", reflect.TypeOf((*MockOperator)(nil).DeleteNotifier), ctx, name)
+}
+
+// ListNotifications mocks base method
+func (m *MockOperator) ListNotifications(ctx context.Context, query *query.Query) (*v1.NotificationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotifications", ctx, query)
+	ret0, _ := ret[0].(*v1.NotificationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotifications indicates an expected call of ListNotifications
+func (mr *MockOperatorMockRecorder) ListNotifications(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotifications", reflect.TypeOf((*MockOperator)(nil).ListNotifications), ctx, query)
+}
+
+// GetNotification mocks base method
+func (m *MockOperator) GetNotification(ctx context.Context, name string) (*v1.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotification", ctx, name)
+	ret0, _ := ret[0].(*v1.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotification indicates an expected call of GetNotification
+func (mr *MockOperatorMockRecorder) GetNotification(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotification", reflect.TypeOf((*MockOperator)(nil).GetNotification), ctx, name)
+}
+
+// ListNotificationsEx mocks base method
+func (m *MockOperator) ListNotificationsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNotificationsEx", ctx, query)
+	ret0, _ := ret[0].(*models.PageableResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNotificationsEx indicates an expected call of ListNotificationsEx
+func (mr *MockOperatorMockRecorder) ListNotificationsEx(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNotificationsEx", reflect.TypeOf((*MockOperator)(nil).ListNotificationsEx), ctx, query)
+}
+
+// GetNotificationEx mocks base method
+func (m *MockOperator) GetNotificationEx(ctx context.Context, name string, resourceVersion string) (*v1.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNotificationEx", ctx, name, resourceVersion)
+	ret0, _ := ret[0].(*v1.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationEx indicates an expected call of GetNotificationEx
+func (mr *MockOperatorMockRecorder) GetNotificationEx(ctx, name, resourceVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEx", reflect.TypeOf((*MockOperator)(nil).GetNotificationEx), ctx, name, resourceVersion)
+}
+
+// CreateNotification mocks base method
+func (m *MockOperator) CreateNotification(ctx context.Context, notification *v1.Notification) (*v1.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateNotification", ctx, notification)
+	ret0, _ := ret[0].(*v1.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateNotification indicates an expected call of CreateNotification
+func (mr *MockOperatorMockRecorder) CreateNotification(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateNotification", reflect.TypeOf((*MockOperator)(nil).CreateNotification), ctx, notification)
+}
+
+// UpdateNotification mocks base method
+func (m *MockOperator) UpdateNotification(ctx context.Context, notification *v1.Notification) (*v1.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotification", ctx, notification)
+	ret0, _ := ret[0].(*v1.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotification indicates an expected call of UpdateNotification
+func (mr *MockOperatorMockRecorder) UpdateNotification(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotification", reflect.TypeOf((*MockOperator)(nil).UpdateNotification), ctx, notification)
+}
+
+// DeleteNotification mocks base method
+func (m *MockOperator) DeleteNotification(ctx context.Context, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteNotification", ctx, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteNotification indicates an expected call of DeleteNotification
+func (mr *MockOperatorMockRecorder) DeleteNotification(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteNotification", reflect.TypeOf((*MockOperator)(nil).DeleteNotification), ctx, name)
+}
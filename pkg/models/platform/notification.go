@@ -0,0 +1,167 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/models"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func (p *platformOperator) ListNotifiers(ctx context.Context, query *query.Query) (*v1.NotifierList, error) {
+	list, err := models.List(ctx, p.notifierStorage, query)
+	if err != nil {
+		return nil, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("NotifierList"))
+	return list.(*v1.NotifierList), nil
+}
+
+func (p *platformOperator) GetNotifier(ctx context.Context, name string) (*v1.Notifier, error) {
+	return p.GetNotifierEx(ctx, name, "")
+}
+
+func (p *platformOperator) ListNotifiersEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return models.ListExV2(ctx, p.notifierStorage, query, p.notifierFilter, nil, nil)
+}
+
+func (p *platformOperator) GetNotifierEx(ctx context.Context, name string, resourceVersion string) (*v1.Notifier, error) {
+	obj, err := models.GetV2(ctx, p.notifierStorage, name, resourceVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Notifier), nil
+}
+
+func (p *platformOperator) CreateNotifier(ctx context.Context, notifier *v1.Notifier) (*v1.Notifier, error) {
+	obj, err := p.notifierStorage.Create(ctx, notifier, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Notifier), nil
+}
+
+func (p *platformOperator) UpdateNotifier(ctx context.Context, notifier *v1.Notifier) (*v1.Notifier, error) {
+	obj, _, err := p.notifierStorage.Update(ctx, notifier.Name, rest.DefaultUpdatedObjectInfo(notifier), nil, nil, false, &metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Notifier), nil
+}
+
+func (p *platformOperator) DeleteNotifier(ctx context.Context, name string) error {
+	_, _, err := p.notifierStorage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
+		return nil
+	}, &metav1.DeleteOptions{})
+	return err
+}
+
+func (p *platformOperator) notifierFilter(obj runtime.Object, q *query.Query) []runtime.Object {
+	notifiers, ok := obj.(*v1.NotifierList)
+	if !ok {
+		return nil
+	}
+	objs := make([]runtime.Object, 0, len(notifiers.Items))
+	for index, notifier := range notifiers.Items {
+		selected := true
+		for k, v := range q.FuzzySearch {
+			if !models.ObjectMetaFilter(notifier.ObjectMeta, k, v) {
+				selected = false
+			}
+		}
+		if selected {
+			objs = append(objs, &notifiers.Items[index])
+		}
+	}
+	return objs
+}
+
+func (p *platformOperator) ListNotifications(ctx context.Context, query *query.Query) (*v1.NotificationList, error) {
+	list, err := models.List(ctx, p.notificationStorage, query)
+	if err != nil {
+		return nil, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("NotificationList"))
+	return list.(*v1.NotificationList), nil
+}
+
+func (p *platformOperator) GetNotification(ctx context.Context, name string) (*v1.Notification, error) {
+	return p.GetNotificationEx(ctx, name, "")
+}
+
+func (p *platformOperator) ListNotificationsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return models.ListExV2(ctx, p.notificationStorage, query, p.notificationFilter, nil, nil)
+}
+
+func (p *platformOperator) GetNotificationEx(ctx context.Context, name string, resourceVersion string) (*v1.Notification, error) {
+	obj, err := models.GetV2(ctx, p.notificationStorage, name, resourceVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Notification), nil
+}
+
+func (p *platformOperator) CreateNotification(ctx context.Context, notification *v1.Notification) (*v1.Notification, error) {
+	obj, err := p.notificationStorage.Create(ctx, notification, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Notification), nil
+}
+
+func (p *platformOperator) UpdateNotification(ctx context.Context, notification *v1.Notification) (*v1.Notification, error) {
+	obj, _, err := p.notificationStorage.Update(ctx, notification.Name, rest.DefaultUpdatedObjectInfo(notification), nil, nil, false, &metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.Notification), nil
+}
+
+func (p *platformOperator) DeleteNotification(ctx context.Context, name string) error {
+	_, _, err := p.notificationStorage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
+		return nil
+	}, &metav1.DeleteOptions{})
+	return err
+}
+
+func (p *platformOperator) notificationFilter(obj runtime.Object, q *query.Query) []runtime.Object {
+	notifications, ok := obj.(*v1.NotificationList)
+	if !ok {
+		return nil
+	}
+	objs := make([]runtime.Object, 0, len(notifications.Items))
+	for index, notification := range notifications.Items {
+		selected := true
+		for k, v := range q.FuzzySearch {
+			if !models.ObjectMetaFilter(notification.ObjectMeta, k, v) {
+				selected = false
+			}
+		}
+		if selected {
+			objs = append(objs, &notifications.Items[index])
+		}
+	}
+	return objs
+}
@@ -0,0 +1,102 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package platform
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/models"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+var _ ObjectEventOperator = (*objectEventOperator)(nil)
+
+type objectEventOperator struct {
+	storage rest.StandardStorage
+}
+
+func NewObjectEventOperator(storage rest.StandardStorage) ObjectEventOperator {
+	return &objectEventOperator{storage: storage}
+}
+
+func (o *objectEventOperator) ListObjectEvents(ctx context.Context, query *query.Query) (*v1.ObjectEventList, error) {
+	list, err := models.List(ctx, o.storage, query)
+	if err != nil {
+		return nil, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("ObjectEventList"))
+	return list.(*v1.ObjectEventList), nil
+}
+
+func (o *objectEventOperator) GetObjectEvent(ctx context.Context, name string) (*v1.ObjectEvent, error) {
+	return o.GetObjectEventEx(ctx, name, "")
+}
+
+func (o *objectEventOperator) ListObjectEventsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return models.ListExV2(ctx, o.storage, query, objectEventFilter, nil, nil)
+}
+
+func (o *objectEventOperator) GetObjectEventEx(ctx context.Context, name string, resourceVersion string) (*v1.ObjectEvent, error) {
+	obj, err := models.GetV2(ctx, o.storage, name, resourceVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.ObjectEvent), nil
+}
+
+func (o *objectEventOperator) CreateObjectEvent(ctx context.Context, event *v1.ObjectEvent) (*v1.ObjectEvent, error) {
+	obj, err := o.storage.Create(ctx, event, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.ObjectEvent), nil
+}
+
+func (o *objectEventOperator) UpdateObjectEvent(ctx context.Context, event *v1.ObjectEvent) (*v1.ObjectEvent, error) {
+	obj, _, err := o.storage.Update(ctx, event.Name, rest.DefaultUpdatedObjectInfo(event), nil, nil, false, &metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.ObjectEvent), nil
+}
+
+func objectEventFilter(obj runtime.Object, q *query.Query) []runtime.Object {
+	events, ok := obj.(*v1.ObjectEventList)
+	if !ok {
+		return nil
+	}
+	objs := make([]runtime.Object, 0, len(events.Items))
+	for index, e := range events.Items {
+		selected := true
+		for k, v := range q.FuzzySearch {
+			if !models.ObjectMetaFilter(e.ObjectMeta, k, v) {
+				selected = false
+			}
+		}
+		if selected {
+			objs = append(objs, &events.Items[index])
+		}
+	}
+	return objs
+}
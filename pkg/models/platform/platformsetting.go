@@ -40,14 +40,19 @@ import (
 var _ Operator = (*platformOperator)(nil)
 
 type platformOperator struct {
-	storage      rest.StandardStorage
-	eventStorage rest.StandardStorage
+	storage             rest.StandardStorage
+	eventStorage        rest.StandardStorage
+	notifierStorage     rest.StandardStorage
+	notificationStorage rest.StandardStorage
 }
 
-func NewPlatformOperator(operationStorage rest.StandardStorage, eventStorage rest.StandardStorage) Operator {
+func NewPlatformOperator(operationStorage rest.StandardStorage, eventStorage rest.StandardStorage,
+	notifierStorage rest.StandardStorage, notificationStorage rest.StandardStorage) Operator {
 	return &platformOperator{
-		storage:      operationStorage,
-		eventStorage: eventStorage,
+		storage:             operationStorage,
+		eventStorage:        eventStorage,
+		notifierStorage:     notifierStorage,
+		notificationStorage: notificationStorage,
 	}
 }
 
@@ -0,0 +1,108 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package platform
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/models"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+var _ WebhookConfigurationOperator = (*webhookOperator)(nil)
+
+type webhookOperator struct {
+	storage rest.StandardStorage
+}
+
+func NewWebhookConfigurationOperator(storage rest.StandardStorage) WebhookConfigurationOperator {
+	return &webhookOperator{storage: storage}
+}
+
+func (w *webhookOperator) ListWebhookConfigurations(ctx context.Context, query *query.Query) (*v1.WebhookConfigurationList, error) {
+	list, err := models.List(ctx, w.storage, query)
+	if err != nil {
+		return nil, err
+	}
+	list.GetObjectKind().SetGroupVersionKind(v1.SchemeGroupVersion.WithKind("WebhookConfigurationList"))
+	return list.(*v1.WebhookConfigurationList), nil
+}
+
+func (w *webhookOperator) GetWebhookConfiguration(ctx context.Context, name string) (*v1.WebhookConfiguration, error) {
+	return w.GetWebhookConfigurationEx(ctx, name, "")
+}
+
+func (w *webhookOperator) ListWebhookConfigurationsEx(ctx context.Context, query *query.Query) (*models.PageableResponse, error) {
+	return models.ListExV2(ctx, w.storage, query, webhookConfigurationFilter, nil, nil)
+}
+
+func (w *webhookOperator) GetWebhookConfigurationEx(ctx context.Context, name string, resourceVersion string) (*v1.WebhookConfiguration, error) {
+	obj, err := models.GetV2(ctx, w.storage, name, resourceVersion, nil)
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.WebhookConfiguration), nil
+}
+
+func (w *webhookOperator) CreateWebhookConfiguration(ctx context.Context, config *v1.WebhookConfiguration) (*v1.WebhookConfiguration, error) {
+	obj, err := w.storage.Create(ctx, config, nil, &metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.WebhookConfiguration), nil
+}
+
+func (w *webhookOperator) UpdateWebhookConfiguration(ctx context.Context, config *v1.WebhookConfiguration) (*v1.WebhookConfiguration, error) {
+	obj, _, err := w.storage.Update(ctx, config.Name, rest.DefaultUpdatedObjectInfo(config), nil, nil, false, &metav1.UpdateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return obj.(*v1.WebhookConfiguration), nil
+}
+
+func (w *webhookOperator) DeleteWebhookConfiguration(ctx context.Context, name string) error {
+	_, _, err := w.storage.Delete(ctx, name, func(ctx context.Context, obj runtime.Object) error {
+		return nil
+	}, &metav1.DeleteOptions{})
+	return err
+}
+
+func webhookConfigurationFilter(obj runtime.Object, q *query.Query) []runtime.Object {
+	configs, ok := obj.(*v1.WebhookConfigurationList)
+	if !ok {
+		return nil
+	}
+	objs := make([]runtime.Object, 0, len(configs.Items))
+	for index, config := range configs.Items {
+		selected := true
+		for k, v := range q.FuzzySearch {
+			if !models.ObjectMetaFilter(config.ObjectMeta, k, v) {
+				selected = false
+			}
+		}
+		if selected {
+			objs = append(objs, &configs.Items[index])
+		}
+	}
+	return objs
+}
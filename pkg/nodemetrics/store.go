@@ -0,0 +1,133 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package nodemetrics keeps a short window of the resource usage reported by
+// agents with their node status, and exports the latest samples to prometheus.
+package nodemetrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	// DefaultWindow is the number of samples kept for every node.
+	DefaultWindow = 60
+	// staleAfter drops the nodes which have not reported for a while from the exported metrics.
+	staleAfter = 15 * time.Minute
+)
+
+var (
+	cpuUsageDesc    = newDesc("cpu_usage_percent", "CPU usage of the node in percent.")
+	memoryUsedDesc  = newDesc("memory_used_bytes", "Memory used on the node in bytes.")
+	memoryUsageDesc = newDesc("memory_usage_percent", "Memory usage of the node in percent.")
+	diskUsedDesc    = newDesc("disk_used_bytes", "Disk space used on the node in bytes.")
+	diskUsageDesc   = newDesc("disk_usage_percent", "Disk usage of the node in percent.")
+	load1Desc       = newDesc("load1", "1 minute load average of the node.")
+	load5Desc       = newDesc("load5", "5 minute load average of the node.")
+	load15Desc      = newDesc("load15", "15 minute load average of the node.")
+)
+
+var _ prometheus.Collector = (*Store)(nil)
+
+func newDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc("kc_node_"+name, help, []string{"node"}, nil)
+}
+
+// Store keeps the latest samples of every node in memory. Every server only
+// sees the node status reports delivered to it.
+type Store struct {
+	mu      sync.RWMutex
+	window  int
+	samples map[string][]v1.NodeUsage
+	now     func() time.Time
+}
+
+func NewStore(window int) *Store {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Store{
+		window:  window,
+		samples: make(map[string][]v1.NodeUsage),
+		now:     time.Now,
+	}
+}
+
+// Add appends the sample of the node, a sample reported again is ignored.
+func (s *Store) Add(node string, u v1.NodeUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	samples := s.samples[node]
+	if n := len(samples); n > 0 && !u.Timestamp.After(samples[n-1].Timestamp.Time) {
+		return
+	}
+	if len(samples) >= s.window {
+		samples = append(samples[:0], samples[len(samples)-s.window+1:]...)
+	}
+	s.samples[node] = append(samples, u)
+}
+
+// Get returns the samples of the node, oldest first.
+func (s *Store) Get(node string) []v1.NodeUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	samples := make([]v1.NodeUsage, len(s.samples[node]))
+	copy(samples, s.samples[node])
+	return samples
+}
+
+func (s *Store) Delete(node string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.samples, node)
+}
+
+func (s *Store) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{cpuUsageDesc, memoryUsedDesc, memoryUsageDesc,
+		diskUsedDesc, diskUsageDesc, load1Desc, load5Desc, load15Desc} {
+		ch <- d
+	}
+}
+
+func (s *Store) Collect(ch chan<- prometheus.Metric) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for node, samples := range s.samples {
+		u := samples[len(samples)-1]
+		if s.now().Sub(u.Timestamp.Time) > staleAfter {
+			continue
+		}
+		for d, v := range map[*prometheus.Desc]float64{
+			cpuUsageDesc:    u.CPUPercent,
+			memoryUsedDesc:  float64(u.MemoryUsed),
+			memoryUsageDesc: u.MemoryPercent,
+			diskUsedDesc:    float64(u.DiskUsed),
+			diskUsageDesc:   u.DiskPercent,
+			load1Desc:       u.Load1,
+			load5Desc:       u.Load5,
+			load15Desc:      u.Load15,
+		} {
+			ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, node)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nodemetrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Now()
+	s := NewStore(3)
+	s.now = func() time.Time { return now }
+	for i := 5; i > 0; i-- {
+		s.Add("node1", v1.NodeUsage{Timestamp: metav1.NewTime(now.Add(-time.Duration(i) * time.Minute)), Load1: float64(i)})
+	}
+	// reported again with the next heartbeat
+	s.Add("node1", v1.NodeUsage{Timestamp: metav1.NewTime(now.Add(-time.Minute)), Load1: 100})
+	s.Add("node2", v1.NodeUsage{Timestamp: metav1.NewTime(now.Add(-time.Hour))})
+
+	samples := s.Get("node1")
+	if len(samples) != 3 || samples[0].Load1 != 3 || samples[2].Load1 != 1 {
+		t.Errorf("unexpected samples %+v", samples)
+	}
+	if samples := s.Get("node3"); samples == nil || len(samples) != 0 {
+		t.Errorf("expected empty samples, got %+v", samples)
+	}
+
+	ch := make(chan prometheus.Metric, 100)
+	s.Collect(ch)
+	close(ch)
+	if len(ch) != 8 {
+		t.Errorf("expected 8 metrics of node1, got %d", len(ch))
+	}
+
+	s.Delete("node1")
+	if samples := s.Get("node1"); len(samples) != 0 {
+		t.Errorf("expected node1 to be deleted, got %+v", samples)
+	}
+}
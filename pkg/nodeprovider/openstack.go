@@ -0,0 +1,423 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nodeprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const TypeOpenStack = "openstack"
+
+const (
+	serverStatusActive = "ACTIVE"
+	serverStatusError  = "ERROR"
+)
+
+func init() {
+	Register(TypeOpenStack, newOpenStack)
+}
+
+// openstack creates the servers of every pool with the desired worker count. The servers register
+// their agents through the cloud-init user data and join the pool once they show up as free nodes.
+// The servers of the members removed from the pool, and the ones failed to build, are deleted.
+type openstack struct {
+	opts     *ProviderOptions
+	client   *http.Client
+	endpoint string
+	// machine is the default spec of the servers.
+	machine v1.MachineSpec
+}
+
+func newOpenStack(opts *ProviderOptions) (Provider, error) {
+	if opts.Username == "" || opts.Password == "" || opts.param("project", "") == "" {
+		return nil, fmt.Errorf("username, password and param project must be specified")
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultRequestTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
+	}
+	return &openstack{
+		opts:     opts,
+		client:   &http.Client{Transport: transport, Timeout: timeout},
+		endpoint: strings.TrimSuffix(opts.Endpoint, "/"),
+		machine: v1.MachineSpec{
+			Flavor:           opts.param("flavor", ""),
+			Image:            opts.param("image", ""),
+			Networks:         splitParam(opts.param("networks", "")),
+			SecurityGroups:   splitParam(opts.param("securityGroups", "")),
+			KeyName:          opts.param("keyName", ""),
+			AvailabilityZone: opts.param("availabilityZone", ""),
+		},
+	}, nil
+}
+
+type novaServer struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Created   time.Time `json:"created"`
+	Addresses map[string][]struct {
+		Addr    string `json:"addr"`
+		Version int    `json:"version"`
+	} `json:"addresses"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func (s *novaServer) ips() []string {
+	var ips []string
+	for _, addrs := range s.Addresses {
+		for _, addr := range addrs {
+			if addr.Version == 4 {
+				ips = append(ips, addr.Addr)
+			}
+		}
+	}
+	return ips
+}
+
+// session is an authenticated connection to the compute service.
+type session struct {
+	o       *openstack
+	token   string
+	compute string
+}
+
+func (o *openstack) Provision(ctx context.Context, req *Request) ([]string, error) {
+	sess, err := o.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := sess.servers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	memberIPs := sets.NewString()
+	for _, n := range req.Members {
+		memberIPs.Insert(n.Status.Ipv4DefaultIP)
+	}
+	isMember := func(s *novaServer) bool {
+		return memberIPs.HasAny(s.ips()...)
+	}
+
+	var live, release []*novaServer
+	for _, s := range servers {
+		if s.Status == serverStatusError {
+			release = append(release, s)
+			continue
+		}
+		live = append(live, s)
+	}
+	// the servers of the removed members are released, the newest ones first
+	sort.SliceStable(live, func(i, j int) bool {
+		mi, mj := isMember(live[i]), isMember(live[j])
+		if mi != mj {
+			return !mi
+		}
+		return live[i].Created.After(live[j].Created)
+	})
+	for len(live) > req.Desired && !isMember(live[0]) {
+		release = append(release, live[0])
+		live = live[1:]
+	}
+	for _, s := range release {
+		if err = sess.deleteServer(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+	for i := len(live); i < req.Desired; i++ {
+		if err = sess.createServer(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	byIP := make(map[string]string)
+	for _, n := range selectNodes(req) {
+		byIP[n.Status.Ipv4DefaultIP] = n.Name
+	}
+	var names []string
+	for _, s := range live {
+		if s.Status != serverStatusActive {
+			continue
+		}
+		for _, ip := range s.ips() {
+			if name, ok := byIP[ip]; ok {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// Surplus returns the members of the newest servers, members openstack did not create come first.
+func (o *openstack) Surplus(ctx context.Context, req *Request, n int) ([]string, error) {
+	sess, err := o.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	servers, err := sess.servers(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	created := make(map[string]time.Time)
+	for _, s := range servers {
+		for _, ip := range s.ips() {
+			created[ip] = s.Created
+		}
+	}
+	members := append(req.Members[:0:0], req.Members...)
+	sort.SliceStable(members, func(i, j int) bool {
+		ti, oki := created[members[i].Status.Ipv4DefaultIP]
+		tj, okj := created[members[j].Status.Ipv4DefaultIP]
+		if oki != okj {
+			return !oki
+		}
+		return ti.After(tj)
+	})
+	var names []string
+	for i := 0; i < n && i < len(members); i++ {
+		names = append(names, members[i].Name)
+	}
+	return names, nil
+}
+
+type keystoneToken struct {
+	Token struct {
+		Catalog []struct {
+			Type      string `json:"type"`
+			Endpoints []struct {
+				Interface string `json:"interface"`
+				Region    string `json:"region"`
+				URL       string `json:"url"`
+			} `json:"endpoints"`
+		} `json:"catalog"`
+	} `json:"token"`
+}
+
+// authenticate gets a token of the project, the compute endpoint is found in its catalog.
+func (o *openstack) authenticate(ctx context.Context) (*session, error) {
+	body := map[string]interface{}{
+		"auth": map[string]interface{}{
+			"identity": map[string]interface{}{
+				"methods": []string{"password"},
+				"password": map[string]interface{}{
+					"user": map[string]interface{}{
+						"name":     o.opts.Username,
+						"password": o.opts.Password,
+						"domain":   map[string]string{"name": o.opts.param("userDomain", "Default")},
+					},
+				},
+			},
+			"scope": map[string]interface{}{
+				"project": map[string]interface{}{
+					"name":   o.opts.param("project", ""),
+					"domain": map[string]string{"name": o.opts.param("projectDomain", "Default")},
+				},
+			},
+		},
+	}
+	resp := keystoneToken{}
+	r, err := o.do(ctx, http.MethodPost, o.endpoint+"/v3/auth/tokens", "", body, &resp)
+	if err != nil {
+		return nil, err
+	}
+	iface, region := o.opts.param("interface", "public"), o.opts.param("region", "")
+	for _, service := range resp.Token.Catalog {
+		if service.Type != "compute" {
+			continue
+		}
+		for _, ep := range service.Endpoints {
+			if ep.Interface == iface && (region == "" || ep.Region == region) {
+				return &session{o: o, token: r.Header.Get("X-Subject-Token"), compute: strings.TrimSuffix(ep.URL, "/")}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no %s compute endpoint found in the catalog", iface)
+}
+
+// servers returns the servers created for the pool.
+func (s *session) servers(ctx context.Context, req *Request) ([]*novaServer, error) {
+	resp := struct {
+		Servers []*novaServer `json:"servers"`
+	}{}
+	query := url.Values{"name": []string{"^" + serverPrefix(req)}}
+	if _, err := s.o.do(ctx, http.MethodGet, s.compute+"/servers/detail?"+query.Encode(), s.token, nil, &resp); err != nil {
+		return nil, err
+	}
+	var servers []*novaServer
+	for _, srv := range resp.Servers {
+		if srv.Metadata[common.LabelClusterName] == req.Cluster && srv.Metadata[common.LabelNodePool] == req.Pool.Name {
+			servers = append(servers, srv)
+		}
+	}
+	return servers, nil
+}
+
+func (s *session) createServer(ctx context.Context, req *Request) error {
+	spec := s.o.machineSpec(req.Pool)
+	if spec.Flavor == "" || spec.Image == "" || len(spec.Networks) == 0 {
+		return fmt.Errorf("flavor, image and networks of the machines of node pool %s must be specified", req.Pool.Name)
+	}
+	name := serverPrefix(req) + rand.String(5)
+	server := map[string]interface{}{
+		"name":      name,
+		"flavorRef": spec.Flavor,
+		"imageRef":  spec.Image,
+		"metadata": map[string]string{
+			common.LabelClusterName: req.Cluster,
+			common.LabelNodePool:    req.Pool.Name,
+		},
+	}
+	networks := make([]map[string]string, 0, len(spec.Networks))
+	for _, n := range spec.Networks {
+		networks = append(networks, map[string]string{"uuid": n})
+	}
+	server["networks"] = networks
+	if len(spec.SecurityGroups) > 0 {
+		groups := make([]map[string]string, 0, len(spec.SecurityGroups))
+		for _, g := range spec.SecurityGroups {
+			groups = append(groups, map[string]string{"name": g})
+		}
+		server["security_groups"] = groups
+	}
+	if spec.KeyName != "" {
+		server["key_name"] = spec.KeyName
+	}
+	if spec.AvailabilityZone != "" {
+		server["availability_zone"] = spec.AvailabilityZone
+	}
+	if s.o.opts.CloudInit != "" {
+		userData, err := renderUserData(s.o.opts.CloudInit, req, name)
+		if err != nil {
+			return err
+		}
+		server["user_data"] = base64.StdEncoding.EncodeToString([]byte(userData))
+	}
+	_, err := s.o.do(ctx, http.MethodPost, s.compute+"/servers", s.token, map[string]interface{}{"server": server}, nil)
+	return err
+}
+
+func (s *session) deleteServer(ctx context.Context, srv *novaServer) error {
+	resp, err := s.o.do(ctx, http.MethodDelete, s.compute+"/servers/"+srv.ID, s.token, nil, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// machineSpec overrides the default spec of the servers with the one of the pool.
+func (o *openstack) machineSpec(pool *v1.NodePool) v1.MachineSpec {
+	spec := *o.machine.DeepCopy()
+	if pool.Provider == nil || pool.Provider.Machine == nil {
+		return spec
+	}
+	m := pool.Provider.Machine
+	if m.Flavor != "" {
+		spec.Flavor = m.Flavor
+	}
+	if m.Image != "" {
+		spec.Image = m.Image
+	}
+	if len(m.Networks) > 0 {
+		spec.Networks = m.Networks
+	}
+	if len(m.SecurityGroups) > 0 {
+		spec.SecurityGroups = m.SecurityGroups
+	}
+	if m.KeyName != "" {
+		spec.KeyName = m.KeyName
+	}
+	if m.AvailabilityZone != "" {
+		spec.AvailabilityZone = m.AvailabilityZone
+	}
+	return spec
+}
+
+// do sends the request and decodes the JSON response into out if it is not nil.
+func (o *openstack) do(ctx context.Context, method, rawURL, token string, body, out interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("X-Auth-Token", token)
+	}
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return resp, fmt.Errorf("%s %s: %s %s", method, req.URL.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if out != nil {
+		if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return resp, fmt.Errorf("%s %s: decode response: %v", method, req.URL.Path, err)
+		}
+	}
+	return resp, nil
+}
+
+func serverPrefix(req *Request) string {
+	return req.Cluster + "-" + req.Pool.Name + "-"
+}
+
+func splitParam(v string) []string {
+	var out []string
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nodeprovider
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+const (
+	DefaultTerraformBinary   = "terraform"
+	DefaultTerraformStateDir = "/var/lib/kubeclipper/terraform"
+	DefaultTerraformTimeout  = 30 * time.Minute
+	DefaultRequestTimeout    = 30 * time.Second
+)
+
+type Options struct {
+	Providers []ProviderOptions `json:"providers,omitempty" yaml:"providers,omitempty" mapstructure:"providers"`
+}
+
+// ProviderOptions configures a node provider, which of the settings are used depends on its type.
+type ProviderOptions struct {
+	Name string `json:"name" yaml:"name" mapstructure:"name"`
+	// Type is one of static, terraform and openstack.
+	Type string `json:"type" yaml:"type" mapstructure:"type"`
+	// Endpoint is the identity endpoint of openstack.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty" mapstructure:"endpoint"`
+	Username string `json:"username,omitempty" yaml:"username,omitempty" mapstructure:"username"`
+	Password string `json:"password,omitempty" yaml:"password,omitempty" mapstructure:"password"`
+	// Insecure skips the verification of the certificate of the endpoint.
+	Insecure bool `json:"insecure,omitempty" yaml:"insecure,omitempty" mapstructure:"insecure"`
+	// Params holds the settings of openstack, the project and domains, and the default
+	// flavor, image, networks, securityGroups, keyName and availabilityZone of the machines.
+	Params map[string]string `json:"params,omitempty" yaml:"params,omitempty" mapstructure:"params"`
+	// Dir is the terraform module creating the machines of a pool, it takes the variables cluster,
+	// pool, region, worker_count and user_data, and outputs the IPs of the machines as ips.
+	Dir string `json:"dir,omitempty" yaml:"dir,omitempty" mapstructure:"dir"`
+	// Binary is the terraform executable.
+	Binary string `json:"binary,omitempty" yaml:"binary,omitempty" mapstructure:"binary"`
+	// StateDir keeps the state of every pool created by the module.
+	StateDir string `json:"stateDir,omitempty" yaml:"stateDir,omitempty" mapstructure:"stateDir"`
+	// CloudInit is the template of the cloud-init user data of the machines, it should install
+	// the agent so the machines register as nodes. It is rendered with the Cluster, Pool and Region,
+	// and the Name of the machine created by openstack.
+	CloudInit string `json:"cloudInit,omitempty" yaml:"cloudInit,omitempty" mapstructure:"cloudInit"`
+	// Vars are passed to the module as they are.
+	Vars map[string]string `json:"vars,omitempty" yaml:"vars,omitempty" mapstructure:"vars"`
+	// Timeout bounds a run of terraform, or a request to openstack.
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" mapstructure:"timeout"`
+}
+
+func NewOptions() *Options {
+	return &Options{}
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	names := sets.NewString(TypeStatic)
+	for _, p := range o.Providers {
+		if p.Name == "" {
+			errs = append(errs, fmt.Errorf("node provider name must be specified"))
+			continue
+		}
+		if names.Has(p.Name) {
+			errs = append(errs, fmt.Errorf("node provider %s is duplicated", p.Name))
+		}
+		names.Insert(p.Name)
+		if _, ok := getFactory(p.Type); !ok {
+			errs = append(errs, fmt.Errorf("node provider %s: unsupported type %q, support %v", p.Name, p.Type, Types()))
+		}
+		switch p.Type {
+		case TypeTerraform:
+			if p.Dir == "" {
+				errs = append(errs, fmt.Errorf("node provider %s: terraform module dir must be specified", p.Name))
+			}
+		case TypeOpenStack:
+			if u, err := url.Parse(p.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+				errs = append(errs, fmt.Errorf("node provider %s: invalid endpoint %q", p.Name, p.Endpoint))
+			}
+			if p.Username == "" || p.Password == "" || p.param("project", "") == "" {
+				errs = append(errs, fmt.Errorf("node provider %s: username, password and param project must be specified", p.Name))
+			}
+		}
+	}
+	return errs
+}
+
+func (p *ProviderOptions) param(key, defaultValue string) string {
+	if v, ok := p.Params[key]; ok && v != "" {
+		return v
+	}
+	return defaultValue
+}
@@ -0,0 +1,144 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package nodeprovider supplies the machines of the node pools scaled to a desired number of workers.
+package nodeprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+// Request is a node pool scaled to a desired number of workers.
+type Request struct {
+	Cluster string
+	Region  string
+	Pool    *v1.NodePool
+	// Desired is the number of workers of the pool.
+	Desired int
+	// Members are the nodes of the pool.
+	Members []*v1.Node
+	// Free are the registered nodes of the region which belong to no cluster.
+	Free []*v1.Node
+}
+
+// Provider supplies the machines of node pools.
+type Provider interface {
+	// Provision makes the machines of the desired workers available and returns the free
+	// nodes which may join the pool, preferred ones first. Machines which are still booting
+	// register their agents later and are returned by later calls.
+	Provision(ctx context.Context, req *Request) ([]string, error)
+	// Surplus returns the n members of the pool which are removed when it shrinks.
+	Surplus(ctx context.Context, req *Request, n int) ([]string, error)
+}
+
+type Factory func(opts *ProviderOptions) (Provider, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register makes a provider type available to the configuration.
+func Register(typ string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[typ] = factory
+}
+
+func getFactory(typ string) (Factory, bool) {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	f, ok := factories[typ]
+	return f, ok
+}
+
+// Types returns the registered provider types.
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	types := make([]string, 0, len(factories))
+	for typ := range factories {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Registry holds the configured providers, the static provider is always available
+// and serves the pools which do not name a provider.
+type Registry struct {
+	providers map[string]Provider
+}
+
+func NewRegistry(opts *Options) (*Registry, error) {
+	r := &Registry{providers: map[string]Provider{TypeStatic: &static{}}}
+	if opts == nil {
+		return r, nil
+	}
+	for i := range opts.Providers {
+		p := &opts.Providers[i]
+		factory, ok := getFactory(p.Type)
+		if !ok {
+			return nil, fmt.Errorf("node provider %s: unsupported type %q", p.Name, p.Type)
+		}
+		provider, err := factory(p)
+		if err != nil {
+			return nil, fmt.Errorf("node provider %s: %v", p.Name, err)
+		}
+		r.providers[p.Name] = provider
+	}
+	return r, nil
+}
+
+// Get returns the provider of the name, the static provider if the name is empty.
+func (r *Registry) Get(name string) (Provider, error) {
+	if name == "" {
+		name = TypeStatic
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("node provider %q not found", name)
+	}
+	return p, nil
+}
+
+// renderUserData renders the cloud-init template with the Cluster, Pool and Region of the request,
+// and the Name of the machine if it is known.
+func renderUserData(path string, req *Request, name string) (string, error) {
+	tmpl, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	userData, err := tmplutil.New().Render(string(tmpl), map[string]string{
+		"Cluster": req.Cluster,
+		"Pool":    req.Pool.Name,
+		"Region":  req.Region,
+		"Name":    name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("render cloud-init user data: %v", err)
+	}
+	return userData, nil
+}
@@ -0,0 +1,277 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nodeprovider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func newNode(name, ip string, lbs map[string]string, created time.Time) *v1.Node {
+	n := &v1.Node{}
+	n.Name = name
+	n.Labels = lbs
+	n.CreationTimestamp = metav1.NewTime(created)
+	n.Status.Ipv4DefaultIP = ip
+	return n
+}
+
+func TestStatic(t *testing.T) {
+	now := time.Now()
+	req := &Request{
+		Cluster: "c1",
+		Pool:    &v1.NodePool{Name: "gpu", Provider: &v1.NodePoolProvider{Selector: map[string]string{"gpu": "true"}}},
+		Desired: 2,
+		Free: []*v1.Node{
+			newNode("n3", "10.0.0.3", map[string]string{"gpu": "true"}, now),
+			newNode("n2", "10.0.0.2", nil, now),
+			newNode("n1", "10.0.0.1", map[string]string{"gpu": "true"}, now),
+		},
+		Members: []*v1.Node{
+			newNode("m1", "10.0.1.1", nil, now.Add(-2*time.Hour)),
+			newNode("m3", "10.0.1.3", nil, now),
+			newNode("m2", "10.0.1.2", nil, now.Add(-time.Hour)),
+		},
+	}
+	p := &static{}
+	got, err := p.Provision(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if want := []string{"n1", "n3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Provision() = %v, want %v", got, want)
+	}
+	got, err = p.Surplus(context.TODO(), req, 2)
+	if err != nil {
+		t.Fatalf("Surplus() error = %v", err)
+	}
+	if want := []string{"m3", "m2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Surplus() = %v, want %v", got, want)
+	}
+}
+
+func TestTerraform(t *testing.T) {
+	dir := t.TempDir()
+	p, err := newTerraform(&ProviderOptions{Name: "cloud", Type: TypeTerraform, Dir: "module", StateDir: dir,
+		Vars: map[string]string{"flavor": "m1.large"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf := p.(*terraform)
+	var calls []string
+	tf.run = func(ctx context.Context, args ...string) ([]byte, error) {
+		calls = append(calls, args[0])
+		switch args[0] {
+		case "apply":
+			// the module writes its state once the machines are created
+			if err := os.WriteFile(filepath.Join(dir, "c1-pool.tfstate"), []byte("{}"), 0600); err != nil {
+				return nil, err
+			}
+		case "output":
+			return []byte(`["10.0.0.1","10.0.0.2","10.0.0.3"]`), nil
+		}
+		return nil, nil
+	}
+	req := &Request{
+		Cluster: "c1",
+		Pool:    &v1.NodePool{Name: "pool"},
+		Desired: 3,
+		Free: []*v1.Node{
+			newNode("n2", "10.0.0.2", nil, time.Now()),
+			newNode("other", "10.0.0.9", nil, time.Now()),
+		},
+		Members: []*v1.Node{newNode("n1", "10.0.0.1", nil, time.Now())},
+	}
+	got, err := p.Provision(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if want := []string{"n2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Provision() = %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "c1-pool.tfvars.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"worker_count":3`, `"flavor":"m1.large"`, `"cluster":"c1"`} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("var file %s does not contain %s", data, s)
+		}
+	}
+	// the count is applied already
+	if _, err = p.Provision(context.TODO(), req); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if want := []string{"init", "apply", "output", "output"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("terraform calls = %v, want %v", calls, want)
+	}
+
+	req.Members = []*v1.Node{
+		newNode("n1", "10.0.0.1", nil, time.Now()),
+		newNode("n3", "10.0.0.3", nil, time.Now()),
+		newNode("manual", "10.0.0.8", nil, time.Now()),
+	}
+	got, err = p.Surplus(context.TODO(), req, 2)
+	if err != nil {
+		t.Fatalf("Surplus() error = %v", err)
+	}
+	if want := []string{"manual", "n3"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Surplus() = %v, want %v", got, want)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want int
+	}{
+		{name: "empty", opts: Options{}, want: 0},
+		{name: "terraform", opts: Options{Providers: []ProviderOptions{{Name: "cloud", Type: TypeTerraform, Dir: "/opt/tf"}}}, want: 0},
+		{name: "no dir", opts: Options{Providers: []ProviderOptions{{Name: "cloud", Type: TypeTerraform}}}, want: 1},
+		{name: "unknown type", opts: Options{Providers: []ProviderOptions{{Name: "cloud", Type: "unknown"}}}, want: 1},
+		{name: "openstack", opts: Options{Providers: []ProviderOptions{{Name: "cloud", Type: TypeOpenStack, Endpoint: "https://keystone:5000",
+			Username: "admin", Password: "secret", Params: map[string]string{"project": "kc"}}}}, want: 0},
+		{name: "openstack no project", opts: Options{Providers: []ProviderOptions{{Name: "cloud", Type: TypeOpenStack, Endpoint: "https://keystone:5000",
+			Username: "admin", Password: "secret"}}}, want: 1},
+		{name: "reserved name", opts: Options{Providers: []ProviderOptions{{Name: TypeStatic, Type: TypeStatic}}}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.Validate(); len(got) != tt.want {
+				t.Errorf("Validate() = %v, want %d errors", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenStack(t *testing.T) {
+	var created []map[string]interface{}
+	var deleted []string
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v3/auth/tokens":
+			w.Header().Set("X-Subject-Token", "token")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token":{"catalog":[{"type":"compute","endpoints":[{"interface":"public","region":"r1","url":"%s/compute/"}]}]}}`, srv.URL)
+		case r.Header.Get("X-Auth-Token") != "token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == http.MethodGet && r.URL.Path == "/compute/servers/detail":
+			if r.URL.Query().Get("name") != "^c1-gpu-" {
+				t.Errorf("unexpected name filter %q", r.URL.Query().Get("name"))
+			}
+			fmt.Fprint(w, `{"servers":[
+{"id":"s1","name":"c1-gpu-a","status":"ACTIVE","created":"2021-01-01T00:00:00Z","addresses":{"net":[{"addr":"10.0.0.1","version":4}]},"metadata":{"kubeclipper.io/cluster":"c1","kubeclipper.io/nodepool":"gpu"}},
+{"id":"s2","name":"c1-gpu-b","status":"ACTIVE","created":"2021-01-02T00:00:00Z","addresses":{"net":[{"addr":"10.0.0.2","version":4}]},"metadata":{"kubeclipper.io/cluster":"c1","kubeclipper.io/nodepool":"gpu"}},
+{"id":"s3","name":"c1-gpu-c","status":"ERROR","created":"2021-01-03T00:00:00Z","metadata":{"kubeclipper.io/cluster":"c1","kubeclipper.io/nodepool":"gpu"}},
+{"id":"s4","name":"c1-gpu-x-d","status":"ACTIVE","created":"2021-01-03T00:00:00Z","addresses":{"net":[{"addr":"10.0.0.4","version":4}]},"metadata":{"kubeclipper.io/cluster":"c1","kubeclipper.io/nodepool":"gpu-x"}}]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/compute/servers":
+			body := map[string]map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			created = append(created, body["server"])
+			w.WriteHeader(http.StatusAccepted)
+			fmt.Fprint(w, `{"server":{"id":"new"}}`)
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/compute/servers/"))
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	cloudInit := filepath.Join(t.TempDir(), "cloud-init.yaml")
+	if err := os.WriteFile(cloudInit, []byte("#cloud-config\nhostname: {{.Name}}\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	p, err := newOpenStack(&ProviderOptions{Name: "cloud", Type: TypeOpenStack, Endpoint: srv.URL, Username: "admin",
+		Password: "secret", CloudInit: cloudInit,
+		Params: map[string]string{"project": "kc", "flavor": "f1", "image": "i1", "networks": "n1, n2"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &Request{
+		Cluster: "c1",
+		Pool:    &v1.NodePool{Name: "gpu", Provider: &v1.NodePoolProvider{Name: "cloud", Machine: &v1.MachineSpec{Flavor: "f2"}}},
+		Desired: 3,
+		Free:    []*v1.Node{newNode("n2", "10.0.0.2", nil, time.Now())},
+		Members: []*v1.Node{newNode("n1", "10.0.0.1", nil, time.Now())},
+	}
+	got, err := p.Provision(context.TODO(), req)
+	if err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if want := []string{"n2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Provision() = %v, want %v", got, want)
+	}
+	if want := []string{"s3"}; !reflect.DeepEqual(deleted, want) {
+		t.Errorf("deleted servers = %v, want %v", deleted, want)
+	}
+	if len(created) != 1 {
+		t.Fatalf("created %d servers, want 1", len(created))
+	}
+	if created[0]["flavorRef"] != "f2" || created[0]["imageRef"] != "i1" || len(created[0]["networks"].([]interface{})) != 2 {
+		t.Errorf("unexpected server %v", created[0])
+	}
+	userData, _ := base64.StdEncoding.DecodeString(created[0]["user_data"].(string))
+	if want := "hostname: " + created[0]["name"].(string); !strings.Contains(string(userData), want) {
+		t.Errorf("user data %q does not contain %q", userData, want)
+	}
+
+	// the server of the removed member n2 is released when the pool shrinks
+	created, deleted = nil, nil
+	req.Desired = 1
+	req.Free = []*v1.Node{newNode("n2", "10.0.0.2", nil, time.Now())}
+	if _, err = p.Provision(context.TODO(), req); err != nil {
+		t.Fatalf("Provision() error = %v", err)
+	}
+	if want := []string{"s3", "s2"}; !reflect.DeepEqual(deleted, want) || len(created) != 0 {
+		t.Errorf("deleted servers = %v, created %d, want %v", deleted, len(created), want)
+	}
+
+	req.Members = []*v1.Node{
+		newNode("n1", "10.0.0.1", nil, time.Now()),
+		newNode("n2", "10.0.0.2", nil, time.Now()),
+		newNode("manual", "10.0.0.9", nil, time.Now()),
+	}
+	got, err = p.Surplus(context.TODO(), req, 2)
+	if err != nil {
+		t.Fatalf("Surplus() error = %v", err)
+	}
+	if want := []string{"manual", "n2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Surplus() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nodeprovider
+
+import (
+	"context"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/labels"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const TypeStatic = "static"
+
+func init() {
+	Register(TypeStatic, func(opts *ProviderOptions) (Provider, error) {
+		return &static{}, nil
+	})
+}
+
+// static supplies the free registered nodes of the region, it neither creates nor deletes machines.
+type static struct{}
+
+func (s *static) Provision(ctx context.Context, req *Request) ([]string, error) {
+	free := selectNodes(req)
+	sort.Slice(free, func(i, j int) bool {
+		return free[i].Name < free[j].Name
+	})
+	names := make([]string, 0, len(free))
+	for _, n := range free {
+		names = append(names, n.Name)
+	}
+	return names, nil
+}
+
+// Surplus returns the members which registered last.
+func (s *static) Surplus(ctx context.Context, req *Request, n int) ([]string, error) {
+	members := append([]*v1.Node(nil), req.Members...)
+	sort.Slice(members, func(i, j int) bool {
+		ti, tj := members[i].CreationTimestamp, members[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return members[i].Name > members[j].Name
+	})
+	var names []string
+	for i := 0; i < n && i < len(members); i++ {
+		names = append(names, members[i].Name)
+	}
+	return names, nil
+}
+
+// selectNodes returns the free nodes matching the selector of the pool.
+func selectNodes(req *Request) []*v1.Node {
+	var selector labels.Selector = labels.Everything()
+	if req.Pool.Provider != nil && len(req.Pool.Provider.Selector) > 0 {
+		selector = labels.SelectorFromSet(req.Pool.Provider.Selector)
+	}
+	var out []*v1.Node
+	for _, n := range req.Free {
+		if selector.Matches(labels.Set(n.Labels)) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
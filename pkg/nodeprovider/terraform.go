@@ -0,0 +1,214 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package nodeprovider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+)
+
+const TypeTerraform = "terraform"
+
+func init() {
+	Register(TypeTerraform, newTerraform)
+}
+
+// terraform applies a module creating the machines of every pool with the desired worker count.
+// The machines register their agents through the cloud-init user data and join the pool once they
+// show up as free nodes. Modules creating the machines with count remove the last ones when the
+// pool shrinks, so the nodes of the last IPs are the surplus.
+type terraform struct {
+	opts *ProviderOptions
+	// run executes terraform and returns its stdout.
+	run func(ctx context.Context, args ...string) ([]byte, error)
+
+	mu          sync.Mutex
+	initialized bool
+	// applied is the worker count last applied for every pool.
+	applied map[string]int
+}
+
+func newTerraform(opts *ProviderOptions) (Provider, error) {
+	if opts.Dir == "" {
+		return nil, fmt.Errorf("terraform module dir must be specified")
+	}
+	t := &terraform{opts: opts, applied: make(map[string]int)}
+	t.run = t.exec
+	return t, nil
+}
+
+func (t *terraform) Provision(ctx context.Context, req *Request) ([]string, error) {
+	if err := t.apply(ctx, req); err != nil {
+		return nil, err
+	}
+	ips, err := t.ips(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	byIP := make(map[string]string)
+	for _, n := range selectNodes(req) {
+		byIP[n.Status.Ipv4DefaultIP] = n.Name
+	}
+	var names []string
+	for _, ip := range ips {
+		if name, ok := byIP[ip]; ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// Surplus returns the members created by the module last, members the module does not know come first.
+func (t *terraform) Surplus(ctx context.Context, req *Request, n int) ([]string, error) {
+	ips, err := t.ips(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(ips))
+	for i, ip := range ips {
+		index[ip] = i
+	}
+	rank := func(ip string) int {
+		if i, ok := index[ip]; ok {
+			return i
+		}
+		return len(ips)
+	}
+	members := append(req.Members[:0:0], req.Members...)
+	sort.SliceStable(members, func(i, j int) bool {
+		return rank(members[i].Status.Ipv4DefaultIP) > rank(members[j].Status.Ipv4DefaultIP)
+	})
+	var names []string
+	for i := 0; i < n && i < len(members); i++ {
+		names = append(names, members[i].Name)
+	}
+	return names, nil
+}
+
+// apply runs the module with the desired worker count of the pool, it is skipped when the count was applied already.
+func (t *terraform) apply(ctx context.Context, req *Request) error {
+	key := poolKey(req)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if count, ok := t.applied[key]; ok && count == req.Desired {
+		return nil
+	}
+	if err := os.MkdirAll(t.stateDir(), 0700); err != nil {
+		return err
+	}
+	vars, err := t.vars(req)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(vars)
+	if err != nil {
+		return err
+	}
+	varFile := filepath.Join(t.stateDir(), key+".tfvars.json")
+	if err = os.WriteFile(varFile, data, 0600); err != nil {
+		return err
+	}
+	if !t.initialized {
+		if _, err = t.run(ctx, "init", "-input=false"); err != nil {
+			return err
+		}
+		t.initialized = true
+	}
+	if _, err = t.run(ctx, "apply", "-auto-approve", "-input=false", "-state="+t.statePath(req), "-var-file="+varFile); err != nil {
+		return err
+	}
+	t.applied[key] = req.Desired
+	return nil
+}
+
+// ips returns the IPs of the machines of the pool in the order the module created them.
+func (t *terraform) ips(ctx context.Context, req *Request) ([]string, error) {
+	if _, err := os.Stat(t.statePath(req)); os.IsNotExist(err) {
+		return nil, nil
+	}
+	out, err := t.run(ctx, "output", "-state="+t.statePath(req), "-json", "ips")
+	if err != nil {
+		return nil, err
+	}
+	var ips []string
+	if err = json.Unmarshal(out, &ips); err != nil {
+		return nil, fmt.Errorf("invalid output ips of terraform module: %v", err)
+	}
+	return ips, nil
+}
+
+func (t *terraform) vars(req *Request) (map[string]interface{}, error) {
+	vars := map[string]interface{}{
+		"cluster":      req.Cluster,
+		"pool":         req.Pool.Name,
+		"region":       req.Region,
+		"worker_count": req.Desired,
+	}
+	for k, v := range t.opts.Vars {
+		vars[k] = v
+	}
+	if t.opts.CloudInit != "" {
+		userData, err := renderUserData(t.opts.CloudInit, req, "")
+		if err != nil {
+			return nil, err
+		}
+		vars["user_data"] = userData
+	}
+	return vars, nil
+}
+
+func (t *terraform) exec(ctx context.Context, args ...string) ([]byte, error) {
+	timeout := t.opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTerraformTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	binary := t.opts.Binary
+	if binary == "" {
+		binary = DefaultTerraformBinary
+	}
+	ec, err := cmdutil.RunCmdWithContext(ctx, false, binary, append([]string{"-chdir=" + t.opts.Dir}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("terraform %s: %v: %s", args[0], err, ec.StdErr())
+	}
+	return []byte(ec.StdOut()), nil
+}
+
+func (t *terraform) stateDir() string {
+	if t.opts.StateDir != "" {
+		return t.opts.StateDir
+	}
+	return DefaultTerraformStateDir
+}
+
+func (t *terraform) statePath(req *Request) string {
+	return filepath.Join(t.stateDir(), poolKey(req)+".tfstate")
+}
+
+func poolKey(req *Request) string {
+	return req.Cluster + "-" + req.Pool.Name
+}
@@ -27,6 +27,7 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sysutil"
 
 	"go.uber.org/zap"
@@ -45,6 +46,21 @@ import (
 // Setters may partially mutate the node before returning an error.
 type Setter func(node *v1.Node) error
 
+// ExternalID labels the node with its id in an external inventory, the label is removed when id is empty.
+func ExternalID(id string) Setter {
+	return func(node *v1.Node) error {
+		if id == "" {
+			delete(node.Labels, common.LabelNodeExternalID)
+			return nil
+		}
+		if node.Labels == nil {
+			node.Labels = map[string]string{}
+		}
+		node.Labels[common.LabelNodeExternalID] = id
+		return nil
+	}
+}
+
 func NodeAddress() Setter {
 	return func(node *v1.Node) error {
 		var nodeAddress []v1.NodeAddress
@@ -94,6 +110,7 @@ func MachineInfo() Setter {
 			node.Status.NodeInfo.Platform = info.Host.Platform
 			node.Status.NodeInfo.PlatformVersion = info.Host.PlatformVersion
 			node.Status.NodeInfo.PlatformFamily = info.Host.PlatformFamily
+			node.Status.NodeInfo.OSFamily = string(osutil.Detect(info.Host.Platform, info.Host.PlatformFamily))
 			node.Status.NodeInfo.KernelArch = info.Host.KernelArch
 			node.Status.NodeInfo.KernelVersion = info.Host.KernelVersion
 
@@ -111,6 +128,57 @@ func MachineInfo() Setter {
 	}
 }
 
+// GPUInfo returns a Setter that reports the GPUs of the node, and labels the node
+// with the vendor of its first GPU so GPU nodes can be selected.
+func GPUInfo() Setter {
+	return func(node *v1.Node) error {
+		gpus, err := sysutil.GPUInfo()
+		if err != nil {
+			logger.Error("Error getting gpu info", zap.Error(err))
+			return nil
+		}
+		node.Status.GPUs = nil
+		for _, gpu := range gpus {
+			node.Status.GPUs = append(node.Status.GPUs, v1.GPU{
+				Vendor:        gpu.Vendor,
+				Model:         gpu.Model,
+				DriverVersion: gpu.DriverVersion,
+			})
+		}
+		if len(gpus) == 0 {
+			delete(node.Labels, common.LabelNodeGPU)
+			return nil
+		}
+		if node.Labels == nil {
+			node.Labels = map[string]string{}
+		}
+		node.Labels[common.LabelNodeGPU] = gpus[0].Vendor
+		return nil
+	}
+}
+
+// ResourceUsage returns a Setter that samples the cpu, memory, disk and load of the node.
+func ResourceUsage(nowFunc func() time.Time) Setter {
+	return func(node *v1.Node) error {
+		u, err := sysutil.UsageInfo()
+		if err != nil {
+			return err
+		}
+		node.Status.Usage = &v1.NodeUsage{
+			Timestamp:     metav1.NewTime(nowFunc()),
+			CPUPercent:    u.CPUPercent,
+			MemoryUsed:    u.MemoryUsed,
+			MemoryPercent: u.MemoryPercent,
+			DiskUsed:      u.DiskUsed,
+			DiskPercent:   u.DiskPercent,
+			Load1:         u.Load1,
+			Load5:         u.Load5,
+			Load15:        u.Load15,
+		}
+		return nil
+	}
+}
+
 // ReadyCondition returns a Setter that updates the v1.NodeReady condition on the node.
 func ReadyCondition(
 	nowFunc func() time.Time, // typically Kubelet.clock.Now
@@ -0,0 +1,173 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package notification delivers platform events to the notifiers registered by admins.
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// Timeout bounds one delivery attempt.
+const Timeout = 15 * time.Second
+
+const maxResponseMessage = 1024
+
+// WebhookPayload is the body posted to generic webhooks.
+type WebhookPayload struct {
+	Notifier string               `json:"notifier"`
+	Event    v1.NotificationEvent `json:"event"`
+}
+
+// Send delivers the event to the notifier once, retrying is up to the caller.
+func Send(ctx context.Context, n *v1.Notifier, e *v1.NotificationEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	switch n.Type {
+	case v1.NotifierTypeWebhook:
+		return postJSON(ctx, n.Webhook, n.Webhook.URL, WebhookPayload{Notifier: n.Name, Event: *e}, nil)
+	case v1.NotifierTypeSlack:
+		return postJSON(ctx, n.Webhook, n.Webhook.URL, map[string]string{"text": Text(e)}, nil)
+	case v1.NotifierTypeDingTalk:
+		u, err := dingTalkURL(n.Webhook, time.Now())
+		if err != nil {
+			return err
+		}
+		return postJSON(ctx, n.Webhook, u, robotText(e), checkErrCode)
+	case v1.NotifierTypeWeCom:
+		return postJSON(ctx, n.Webhook, n.Webhook.URL, robotText(e), checkErrCode)
+	case v1.NotifierTypeEmail:
+		return sendMail(ctx, n.Email, Subject(e), Text(e))
+	}
+	return fmt.Errorf("unsupported notifier type %q", n.Type)
+}
+
+// Subject is the one line summary of the event.
+func Subject(e *v1.NotificationEvent) string {
+	if e.Cluster == "" {
+		return fmt.Sprintf("[KubeClipper] %s: %s", e.Type, e.Object)
+	}
+	return fmt.Sprintf("[KubeClipper] %s: %s/%s", e.Type, e.Cluster, e.Object)
+}
+
+// Text is the plain text message of the event, used by the chat and email notifiers.
+func Text(e *v1.NotificationEvent) string {
+	var b strings.Builder
+	b.WriteString(Subject(e))
+	b.WriteString("\n")
+	if e.Message != "" {
+		b.WriteString(e.Message)
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "Time: %s", e.Time.UTC().Format(time.RFC3339))
+	return b.String()
+}
+
+// robotText is the text message of DingTalk and WeCom group robots.
+func robotText(e *v1.NotificationEvent) interface{} {
+	return map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": Text(e)},
+	}
+}
+
+// dingTalkURL adds the signature required by robots with a secret to the webhook url.
+// see https://open.dingtalk.com/document/robots/customize-robot-security-settings
+func dingTalkURL(w *v1.NotifierWebhook, now time.Time) (string, error) {
+	if w.Secret == "" {
+		return w.URL, nil
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil {
+		return "", err
+	}
+	timestamp := strconv.FormatInt(now.UnixNano()/int64(time.Millisecond), 10)
+	mac := hmac.New(sha256.New, []byte(w.Secret))
+	mac.Write([]byte(timestamp + "\n" + w.Secret))
+	q := u.Query()
+	q.Set("timestamp", timestamp)
+	q.Set("sign", base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// checkErrCode checks the body of DingTalk and WeCom, which report errors with status 200.
+func checkErrCode(body []byte) error {
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("unexpected response %q: %v", responseMessage(body), err)
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("errcode %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+func postJSON(ctx context.Context, w *v1.NotifierWebhook, u string, payload interface{}, check func([]byte) error) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	for k, v := range w.Headers {
+		r.Header.Set(k, v)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if w.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseMessage))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %s: %s", resp.Status, responseMessage(msg))
+	}
+	if check != nil {
+		return check(msg)
+	}
+	return nil
+}
+
+func responseMessage(b []byte) string {
+	return strings.TrimSpace(string(b))
+}
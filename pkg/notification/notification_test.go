@@ -0,0 +1,118 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+var testEvent = v1.NotificationEvent{
+	Type:    v1.NotificationOperationFailed,
+	Cluster: "demo",
+	Object:  "op-1",
+	Message: "step kubeadm-init failed",
+	Time:    metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)),
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	n := &v1.Notifier{Type: v1.NotifierTypeWebhook, Webhook: &v1.NotifierWebhook{
+		URL:     srv.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+	}}
+	n.Name = "ops"
+	if err := Send(context.Background(), n, &testEvent); err != nil {
+		t.Fatal(err)
+	}
+	if got.Notifier != "ops" || got.Event.Object != "op-1" {
+		t.Errorf("unexpected payload %+v", got)
+	}
+
+	n.Webhook.Headers = nil
+	if err := Send(context.Background(), n, &testEvent); err == nil {
+		t.Error("expected an error for a non 2xx response")
+	}
+}
+
+func TestSendRobot(t *testing.T) {
+	var (
+		query url.Values
+		body  map[string]interface{}
+		resp  = `{"errcode":0,"errmsg":"ok"}`
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = io.WriteString(w, resp)
+	}))
+	defer srv.Close()
+
+	n := &v1.Notifier{Type: v1.NotifierTypeDingTalk, Webhook: &v1.NotifierWebhook{URL: srv.URL + "?access_token=abc", Secret: "SEC"}}
+	if err := Send(context.Background(), n, &testEvent); err != nil {
+		t.Fatal(err)
+	}
+	if body["msgtype"] != "text" {
+		t.Errorf("unexpected body %v", body)
+	}
+	if query.Get("access_token") != "abc" {
+		t.Errorf("access token is lost: %v", query)
+	}
+	mac := hmac.New(sha256.New, []byte("SEC"))
+	mac.Write([]byte(query.Get("timestamp") + "\nSEC"))
+	if query.Get("sign") != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Errorf("invalid signature %q", query.Get("sign"))
+	}
+
+	// robots report errors with status 200
+	resp = `{"errcode":93000,"errmsg":"invalid webhook url"}`
+	n.Type = v1.NotifierTypeWeCom
+	if err := Send(context.Background(), n, &testEvent); err == nil {
+		t.Error("expected an error for a non zero errcode")
+	}
+}
+
+func TestText(t *testing.T) {
+	want := "[KubeClipper] OperationFailed: demo/op-1\nstep kubeadm-init failed\nTime: 2022-01-01T00:00:00Z"
+	if got := Text(&testEvent); got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+}
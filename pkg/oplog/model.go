@@ -30,3 +30,17 @@ type LogContentResponse struct {
 	LogSize      int64  `json:"logSize"`
 	DeliverySize int64  `json:"deliverySize"`
 }
+
+// LogChunk is a piece of step log published by the agent while the step is running.
+type LogChunk struct {
+	OpID string `json:"opID"`
+	// StepID is the id of the step, not the name of its log file.
+	StepID string `json:"stepID"`
+	Node   string `json:"node"`
+	// Offset is where Data starts in the step log file, it lets watchers
+	// skip the content they have fetched already.
+	Offset int64  `json:"offset"`
+	Data   []byte `json:"data,omitempty"`
+	// EOF is set on the last chunk of the step run.
+	EOF bool `json:"eof,omitempty"`
+}
@@ -0,0 +1,116 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package precheck
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+// maxTimeLag is the largest clock difference between a node and the server
+// that still passes the time-lag check.
+const maxTimeLag = 5 * time.Second
+
+// Check is a single validation run against a candidate node over ssh.
+// Run returns a short description of what was found when the check passes.
+type Check struct {
+	Name string
+	Run  func(sshConfig *sshutils.SSH, host string) (string, error)
+}
+
+// DefaultChecks are the checks run on every candidate node, in order.
+// The first one verifies the node is reachable, the others are skipped when it fails.
+func DefaultChecks() []Check {
+	return []Check{
+		{Name: "ssh", Run: checkSSH},
+		{Name: "sudo", Run: checkSudo},
+		{Name: "kc-agent", Run: checkServiceAbsent("kc-agent")},
+		{Name: "kubelet", Run: checkServiceAbsent("kubelet")},
+		{Name: "time-lag", Run: checkTimeLag},
+		{Name: "ntp", Run: checkNtp},
+	}
+}
+
+func checkSSH(sshConfig *sshutils.SSH, host string) (string, error) {
+	ret, err := sshutils.SSHCmd(sshConfig, host, "uname -sm")
+	if err != nil {
+		return "", err
+	}
+	if err = ret.Error(); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(ret.Stdout), nil
+}
+
+func checkSudo(sshConfig *sshutils.SSH, host string) (string, error) {
+	ret, err := sshutils.SSHCmdWithSudo(sshConfig, host, "true")
+	if err != nil {
+		return "", err
+	}
+	if ret.ExitCode != 0 {
+		return "", fmt.Errorf("user %s can not run commands with sudo", sshConfig.User)
+	}
+	return "", nil
+}
+
+func checkServiceAbsent(name string) func(sshConfig *sshutils.SSH, host string) (string, error) {
+	return func(sshConfig *sshutils.SSH, host string) (string, error) {
+		ret, err := sshutils.SSHCmdWithSudo(sshConfig, host, fmt.Sprintf("systemctl --all --type service | grep -Fq %s", name))
+		if err != nil {
+			return "", err
+		}
+		if ret.ExitCode == 0 {
+			return "", fmt.Errorf("%s service exist, please clean old environment", name)
+		}
+		return "", nil
+	}
+}
+
+func checkTimeLag(sshConfig *sshutils.SSH, host string) (string, error) {
+	now := time.Now()
+	ret, err := sshutils.SSHCmd(sshConfig, host, "date +%s")
+	if err != nil {
+		return "", err
+	}
+	ts, err := strconv.ParseInt(strings.TrimSpace(ret.Stdout), 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("parse node time %q failed: %v", ret.Stdout, err)
+	}
+	lag := time.Unix(ts, 0).Sub(now)
+	if math.Abs(lag.Seconds()) > maxTimeLag.Seconds() {
+		return "", fmt.Errorf("node time differs from server by %v, more than %v", lag.Round(time.Second), maxTimeLag)
+	}
+	return fmt.Sprintf("%v", lag.Round(time.Second)), nil
+}
+
+func checkNtp(sshConfig *sshutils.SSH, host string) (string, error) {
+	ret, err := sshutils.SSHCmdWithSudo(sshConfig, host, "systemctl --all --type service --state running | grep -Fq -e chronyd -e ntpd")
+	if err != nil {
+		return "", err
+	}
+	if ret.ExitCode != 0 {
+		return "", fmt.Errorf("chronyd or ntpd service not running, may cause service internal error")
+	}
+	return "", nil
+}
@@ -0,0 +1,333 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package precheck validates candidate nodes over ssh before they are added to kubeclipper.
+// Checks run asynchronously in jobs kept in memory, results are recorded as soon as
+// each check completes so callers can stream them.
+package precheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+const (
+	// jobTTL is how long a completed job is kept before it is dropped.
+	jobTTL = time.Hour
+	// maxConcurrentNodes bounds the ssh sessions opened by a single job.
+	maxConcurrentNodes = 10
+	sshTimeout         = 10 * time.Second
+)
+
+var ErrJobNotFound = errors.New("precheck job not found")
+
+type JobPhase string
+
+const (
+	JobRunning   JobPhase = "Running"
+	JobCompleted JobPhase = "Completed"
+)
+
+// Credential is the ssh login used to reach the candidate nodes.
+type Credential struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	// Port defaults to 22.
+	Port int `json:"port,omitempty"`
+}
+
+// Request is a set of candidate nodes to validate.
+type Request struct {
+	// Nodes are the IPs of the candidate nodes.
+	Nodes []string   `json:"nodes"`
+	SSH   Credential `json:"ssh"`
+}
+
+func (r *Request) Validate() error {
+	if len(r.Nodes) == 0 {
+		return fmt.Errorf("no nodes to precheck")
+	}
+	if r.SSH.User == "" {
+		return fmt.Errorf("ssh user is required")
+	}
+	if r.SSH.Port < 0 || r.SSH.Port > 65535 {
+		return fmt.Errorf("invalid ssh port %d", r.SSH.Port)
+	}
+	seen := make(map[string]struct{}, len(r.Nodes))
+	for _, n := range r.Nodes {
+		if n == "" {
+			return fmt.Errorf("node ip can not be empty")
+		}
+		if _, ok := seen[n]; ok {
+			return fmt.Errorf("duplicate node %s", n)
+		}
+		seen[n] = struct{}{}
+	}
+	return nil
+}
+
+// Result is the outcome of one check on one node.
+type Result struct {
+	Node    string      `json:"node"`
+	Check   string      `json:"check"`
+	Passed  bool        `json:"passed"`
+	Message string      `json:"message,omitempty"`
+	Time    metav1.Time `json:"time"`
+}
+
+type Job struct {
+	ID     string   `json:"id"`
+	Nodes  []string `json:"nodes"`
+	Checks []string `json:"checks"`
+	Phase  JobPhase `json:"phase"`
+	// Total is the number of results the job produces once completed.
+	Total int `json:"total"`
+	// Failed is the number of results which did not pass.
+	Failed      int          `json:"failed"`
+	Results     []Result     `json:"results"`
+	CreatedAt   metav1.Time  `json:"createdAt"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty"`
+}
+
+type job struct {
+	Job
+	// updated is closed and replaced every time the job changes.
+	updated chan struct{}
+}
+
+type Manager struct {
+	checks []Check
+	mu     sync.Mutex
+	jobs   map[string]*job
+	// completed is called with every job once its checks are done.
+	completed func(*Job)
+}
+
+// NewManager returns a manager running the given checks, DefaultChecks when none are given.
+func NewManager(checks ...Check) *Manager {
+	if len(checks) == 0 {
+		checks = DefaultChecks()
+	}
+	return &Manager{
+		checks: checks,
+		jobs:   make(map[string]*job),
+	}
+}
+
+// OnCompleted registers fn to be called with a copy of every job once it is completed,
+// it must be set before any job is submitted.
+func (m *Manager) OnCompleted(fn func(*Job)) {
+	m.completed = fn
+}
+
+// Submit starts a job for the request and returns it without waiting for any check.
+func (m *Manager) Submit(req *Request) *Job {
+	now := metav1.Now()
+	j := &job{
+		Job: Job{
+			ID:        uuid.New().String(),
+			Nodes:     append([]string(nil), req.Nodes...),
+			Phase:     JobRunning,
+			Total:     len(req.Nodes) * len(m.checks),
+			Results:   make([]Result, 0, len(req.Nodes)*len(m.checks)),
+			CreatedAt: now,
+		},
+		updated: make(chan struct{}),
+	}
+	for _, c := range m.checks {
+		j.Checks = append(j.Checks, c.Name)
+	}
+
+	m.mu.Lock()
+	m.gc(now.Time)
+	m.jobs[j.ID] = j
+	snapshot := j.snapshot()
+	m.mu.Unlock()
+
+	go m.run(j, req)
+	return snapshot
+}
+
+// Get returns a copy of the job.
+func (m *Manager) Get(id string) (*Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, ok := m.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return j.snapshot(), nil
+}
+
+// Watch calls fn with every result of the job, the recorded ones first, then the
+// new ones as they complete. It returns once the job is completed, fn fails or ctx is done.
+func (m *Manager) Watch(ctx context.Context, id string, fn func(Result) error) error {
+	sent := 0
+	for {
+		m.mu.Lock()
+		j, ok := m.jobs[id]
+		if !ok {
+			m.mu.Unlock()
+			return ErrJobNotFound
+		}
+		pending := append([]Result(nil), j.Results[sent:]...)
+		completed := j.Phase == JobCompleted
+		updated := j.updated
+		m.mu.Unlock()
+
+		for _, r := range pending {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		sent += len(pending)
+		if completed {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-updated:
+		}
+	}
+}
+
+func (m *Manager) run(j *job, req *Request) {
+	port := req.SSH.Port
+	if port == 0 {
+		port = 22
+	}
+	timeout := sshTimeout
+	sem := make(chan struct{}, maxConcurrentNodes)
+	wg := sync.WaitGroup{}
+	for _, node := range req.Nodes {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(node string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			// every node gets its own config, sshutils sets defaults on it while connecting
+			sshConfig := &sshutils.SSH{
+				User:              req.SSH.User,
+				Password:          req.SSH.Password,
+				ConnectionTimeout: &timeout,
+			}
+			m.checkNode(j, sshConfig, net.JoinHostPort(node, strconv.Itoa(port)), node)
+		}(node)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	now := metav1.Now()
+	j.Phase = JobCompleted
+	j.CompletedAt = &now
+	j.notify()
+	snapshot := j.snapshot()
+	m.mu.Unlock()
+
+	if m.completed != nil {
+		m.completed(snapshot)
+	}
+}
+
+func (m *Manager) checkNode(j *job, sshConfig *sshutils.SSH, addr, node string) {
+	var unreachable error
+	for i, c := range m.checks {
+		r := Result{Node: node, Check: c.Name}
+		if unreachable != nil {
+			r.Message = fmt.Sprintf("skipped, node is unreachable: %v", unreachable)
+		} else {
+			msg, err := c.Run(sshConfig, addr)
+			if err != nil {
+				r.Message = err.Error()
+				if i == 0 {
+					unreachable = err
+				}
+			} else {
+				r.Passed = true
+				r.Message = msg
+			}
+		}
+		r.Time = metav1.Now()
+
+		m.mu.Lock()
+		j.Results = append(j.Results, r)
+		if !r.Passed {
+			j.Failed++
+		}
+		j.notify()
+		m.mu.Unlock()
+	}
+}
+
+// gc drops the jobs completed more than jobTTL ago, the caller must hold m.mu.
+func (m *Manager) gc(now time.Time) {
+	for id, j := range m.jobs {
+		if j.CompletedAt != nil && now.Sub(j.CompletedAt.Time) > jobTTL {
+			delete(m.jobs, id)
+		}
+	}
+}
+
+// Record converts the job to the precheck resource kept in the history, named after the job.
+func (j *Job) Record() *v1.Precheck {
+	p := &v1.Precheck{
+		Source:      v1.PrecheckSourceNode,
+		Nodes:       append([]string(nil), j.Nodes...),
+		Failed:      j.Failed,
+		StartedAt:   j.CreatedAt,
+		CompletedAt: j.CompletedAt,
+	}
+	p.Name = j.ID
+	for _, r := range j.Results {
+		p.Results = append(p.Results, v1.PrecheckResult{
+			Node:    r.Node,
+			Check:   r.Check,
+			Passed:  r.Passed,
+			Message: r.Message,
+			Time:    r.Time,
+		})
+	}
+	return p
+}
+
+func (j *job) notify() {
+	close(j.updated)
+	j.updated = make(chan struct{})
+}
+
+func (j *job) snapshot() *Job {
+	out := j.Job
+	out.Nodes = append([]string(nil), j.Nodes...)
+	out.Checks = append([]string(nil), j.Checks...)
+	out.Results = append(make([]Result, 0, len(j.Results)), j.Results...)
+	return &out
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package precheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sshutils"
+)
+
+func TestManagerWatch(t *testing.T) {
+	release := make(chan struct{})
+	m := NewManager(
+		Check{Name: "ssh", Run: func(_ *sshutils.SSH, host string) (string, error) {
+			if host == "10.0.0.2:22" {
+				return "", errors.New("connection refused")
+			}
+			return "Linux x86_64", nil
+		}},
+		Check{Name: "slow", Run: func(_ *sshutils.SSH, host string) (string, error) {
+			<-release
+			return "", nil
+		}},
+	)
+	job := m.Submit(&Request{Nodes: []string{"10.0.0.1", "10.0.0.2"}, SSH: Credential{User: "root"}})
+	if job.Phase != JobRunning || job.Total != 4 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+
+	got := make(chan Result, 4)
+	done := make(chan error, 1)
+	go func() {
+		done <- m.Watch(context.Background(), job.ID, func(r Result) error {
+			got <- r
+			return nil
+		})
+	}()
+	// the unreachable node does not wait for the slow check
+	for i := 0; i < 3; i++ {
+		select {
+		case <-got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("result %d not streamed before the slow check completed", i)
+		}
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	job, err := m.Get(job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Phase != JobCompleted || len(job.Results) != 4 || job.Failed != 2 {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	for _, r := range job.Results {
+		if r.Node == "10.0.0.2" && r.Passed {
+			t.Errorf("unexpected passed result %+v", r)
+		}
+		if r.Node == "10.0.0.1" && !r.Passed {
+			t.Errorf("unexpected failed result %+v", r)
+		}
+	}
+	if _, err = m.Get("unknown"); err != ErrJobNotFound {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	tests := []struct {
+		req     Request
+		wantErr bool
+	}{
+		{req: Request{Nodes: []string{"10.0.0.1"}, SSH: Credential{User: "root"}}},
+		{req: Request{SSH: Credential{User: "root"}}, wantErr: true},
+		{req: Request{Nodes: []string{"10.0.0.1"}}, wantErr: true},
+		{req: Request{Nodes: []string{"10.0.0.1", "10.0.0.1"}, SSH: Credential{User: "root"}}, wantErr: true},
+		{req: Request{Nodes: []string{"10.0.0.1"}, SSH: Credential{User: "root", Port: 70000}}, wantErr: true},
+	}
+	for i, tt := range tests {
+		if err := tt.req.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("case %d: Validate() error = %v, wantErr %v", i, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package query
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// ParseFields parses the value of the fields parameter,
+// e.g. "metadata.name,status.phase", into a list of field paths.
+func ParseFields(fieldsStr string) []string {
+	if fieldsStr == "" {
+		return nil
+	}
+	var fields []string
+	for _, f := range strings.Split(fieldsStr, ",") {
+		f = strings.Trim(strings.TrimSpace(f), ".")
+		if f == "" {
+			continue
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// ProjectFields returns a copy of obj which only contains the given field paths.
+// A field path is a dot separated list of json keys, when a path walks into an array
+// the rest of the path is applied to every element of the array.
+// obj is returned as is when fields is empty.
+func ProjectFields(obj interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 || obj == nil {
+		return obj, nil
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var raw interface{}
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	var result interface{}
+	for _, f := range fields {
+		result = mergeProjection(result, project(raw, strings.Split(f, ".")))
+	}
+	if result == nil {
+		return map[string]interface{}{}, nil
+	}
+	return result, nil
+}
+
+func project(in interface{}, path []string) interface{} {
+	if len(path) == 0 {
+		return in
+	}
+	switch v := in.(type) {
+	case map[string]interface{}:
+		child, ok := v[path[0]]
+		if !ok {
+			return nil
+		}
+		sub := project(child, path[1:])
+		if sub == nil && child != nil {
+			return nil
+		}
+		return map[string]interface{}{path[0]: sub}
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			out[i] = project(v[i], path)
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func mergeProjection(dst, src interface{}) interface{} {
+	if dst == nil {
+		return src
+	}
+	if src == nil {
+		return dst
+	}
+	switch d := dst.(type) {
+	case map[string]interface{}:
+		s, ok := src.(map[string]interface{})
+		if !ok {
+			return dst
+		}
+		for k, v := range s {
+			d[k] = mergeProjection(d[k], v)
+		}
+		return d
+	case []interface{}:
+		s, ok := src.([]interface{})
+		if !ok || len(s) != len(d) {
+			return dst
+		}
+		for i := range d {
+			d[i] = mergeProjection(d[i], s[i])
+		}
+		return d
+	default:
+		return dst
+	}
+}
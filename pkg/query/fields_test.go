@@ -0,0 +1,113 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package query
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldsStr string
+		want      []string
+	}{
+		{
+			name:      "empty",
+			fieldsStr: "",
+			want:      nil,
+		},
+		{
+			name:      "multi fields",
+			fieldsStr: "metadata.name, status.phase,,",
+			want:      []string{"metadata.name", "status.phase"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseFields(tt.fieldsStr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "foo",
+			"labels": map[string]interface{}{"a": "b"},
+		},
+		"status": map[string]interface{}{
+			"phase": "Running",
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": "True"},
+				map[string]interface{}{"type": "Healthy", "status": "False"},
+			},
+		},
+	}
+	tests := []struct {
+		name   string
+		fields []string
+		want   interface{}
+	}{
+		{
+			name:   "no fields",
+			fields: nil,
+			want:   obj,
+		},
+		{
+			name:   "nested fields",
+			fields: []string{"metadata.name", "status.phase"},
+			want: map[string]interface{}{
+				"metadata": map[string]interface{}{"name": "foo"},
+				"status":   map[string]interface{}{"phase": "Running"},
+			},
+		},
+		{
+			name:   "array fields",
+			fields: []string{"status.conditions.type"},
+			want: map[string]interface{}{
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready"},
+						map[string]interface{}{"type": "Healthy"},
+					},
+				},
+			},
+		},
+		{
+			name:   "not exist field",
+			fields: []string{"spec.foo"},
+			want:   map[string]interface{}{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProjectFields(obj, tt.fields)
+			if err != nil {
+				t.Fatalf("ProjectFields() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ProjectFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package query
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// ParseOrderBy parses the value of the orderBy parameter into a field path, it accepts
+// the field paths of the fields parameter as well as jsonpath expressions, e.g. "{.metadata.name}".
+func ParseOrderBy(orderBy string) string {
+	return strings.Trim(strings.TrimSpace(orderBy), "{}.")
+}
+
+// FieldValue returns the value of the dot separated field path of the json encoding of obj,
+// nil when obj has no such field.
+func FieldValue(obj interface{}, path string) (interface{}, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		if value, ok = m[key]; !ok {
+			return nil, nil
+		}
+	}
+	return value, nil
+}
+
+// CompareFieldValues compares two values returned by FieldValue, numbers by their value and the other
+// values by their json encoding, which orders strings and RFC 3339 timestamps as expected.
+// Missing values are greater than any other, so that they are ordered last.
+func CompareFieldValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	if x, ok := a.(float64); ok {
+		if y, ok := b.(float64); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			return strings.Compare(x, y)
+		}
+	}
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return bytes.Compare(x, y)
+}
@@ -0,0 +1,74 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package query
+
+import "testing"
+
+func TestParseOrderBy(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                  "",
+		"metadata.name":     "metadata.name",
+		".metadata.name":    "metadata.name",
+		"{.status.phase}":   "status.phase",
+		" {.spec.replicas}": "spec.replicas",
+	} {
+		if got := ParseOrderBy(in); got != want {
+			t.Errorf("ParseOrderBy(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestFieldValue(t *testing.T) {
+	obj := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "foo"},
+		"spec":     map[string]interface{}{"replicas": 3},
+	}
+	if v, err := FieldValue(obj, "metadata.name"); err != nil || v != "foo" {
+		t.Errorf("FieldValue(metadata.name) = %v, %v, want foo", v, err)
+	}
+	if v, err := FieldValue(obj, "spec.replicas"); err != nil || v != float64(3) {
+		t.Errorf("FieldValue(spec.replicas) = %v, %v, want 3", v, err)
+	}
+	if v, err := FieldValue(obj, "metadata.name.first"); err != nil || v != nil {
+		t.Errorf("FieldValue(metadata.name.first) = %v, %v, want nil", v, err)
+	}
+	if v, err := FieldValue(obj, "status.phase"); err != nil || v != nil {
+		t.Errorf("FieldValue(status.phase) = %v, %v, want nil", v, err)
+	}
+}
+
+func TestCompareFieldValues(t *testing.T) {
+	tests := []struct {
+		a, b interface{}
+		want int
+	}{
+		{float64(2), float64(10), -1},
+		{"10", "2", -1},
+		{"2022-01-02T00:00:00Z", "2022-01-01T00:00:00Z", 1},
+		{false, true, -1},
+		{"a", nil, -1},
+		{nil, "a", 1},
+		{nil, nil, 0},
+	}
+	for _, tt := range tests {
+		if got := CompareFieldValues(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareFieldValues(%v, %v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
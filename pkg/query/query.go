@@ -38,6 +38,8 @@ const (
 	ParameterFieldSelector        = "fieldSelector"
 	ParameterContinue             = "continue"
 	ParameterLimit                = "limit"
+	ParameterPage                 = "page"
+	ParameterChunk                = "chunk"
 	ParameterWatch                = "watch"
 	ParameterAllowWatchBookMark   = "allowWatchBookmarks"
 	ParameterResourceVersion      = "resourceVersion"
@@ -50,10 +52,15 @@ const (
 	OrderByParam                  = "orderBy"
 	ParamReverse                  = "reverse"
 	ParamDryRun                   = "dryRun"
+	ParamPropagationPolicy        = "propagationPolicy"
+	ParamDeleted                  = "deleted"
 	ParamRole                     = "role"
 	ParamOffline                  = "offline"
 	ParameterSubDomain            = "subdomain"
 	ParameterFuzzySearch          = "fuzzy"
+	ParameterFields               = "fields"
+	ParameterView                 = "view"
+	ParameterProject              = "project"
 )
 
 const (
@@ -77,13 +84,19 @@ type Query struct {
 	FieldSelector   string
 	Continue        string
 	Limit           int64
-	Reverse         bool
-	// TODO: add orderby
+	// Chunk reads at most Limit resources from the storage instead of a page of all of them,
+	// it is never set for the paged requests.
+	Chunk   bool
+	Reverse bool
+	// OrderBy is the field path the paged lists are sorted by, e.g. metadata.name,
+	// they are sorted by creation time when it is empty.
 	OrderBy              string
 	DryRun               string
 	AllowWatchBookmarks  bool
 	ResourceVersionMatch string
 	FuzzySearch          map[string]string
+	// Fields limit the response to the given field paths, e.g. metadata.name
+	Fields []string
 }
 
 var NoPagination = func() *Pagination {
@@ -189,7 +202,7 @@ func ParsePaging(req *restful.Request) (limit, offset int) {
 	} else {
 		// try to parse from format ?limit=10&page=1
 		limit = AtoiOrDefault(req.QueryParameter("limit"), DefaultLimit)
-		page = AtoiOrDefault(req.QueryParameter("page"), DefaultPage)
+		page = AtoiOrDefault(req.QueryParameter(ParameterPage), DefaultPage)
 	}
 	offset = (page - 1) * limit
 	return
@@ -205,11 +218,16 @@ func ParseQueryParameter(request *restful.Request) *Query {
 	query.Watch = GetBoolValueWithDefault(request, ParameterWatch, false)
 	query.ResourceVersion = request.QueryParameter(ParameterResourceVersion)
 	query.Reverse = GetBoolValueWithDefault(request, ParamReverse, false)
+	query.OrderBy = ParseOrderBy(request.QueryParameter(OrderByParam))
 	if query.Watch {
 		query.AllowWatchBookmarks = GetBoolValueWithDefault(request, ParameterAllowWatchBookMark, false)
 	}
 	query.Limit = GetInt64ValueWithDefault(request, ParameterLimit, 0)
 	query.Continue = request.QueryParameter(ParameterContinue)
+	// ?limit=N&page=M and paging=limit=N,page=M read a page, only the chunk and continue requests read a chunk
+	if request.QueryParameter(PagingParam) == "" && request.QueryParameter(ParameterPage) == "" {
+		query.Chunk = GetBoolValueWithDefault(request, ParameterChunk, false) || query.Continue != ""
+	}
 	query.ResourceVersionMatch = request.QueryParameter(ParameterResourceVersionMatch)
 	if ts := request.QueryParameter(ParameterTimeoutSeconds); ts != "" {
 		v, err := strconv.ParseInt(ts, 10, 64)
@@ -218,6 +236,7 @@ func ParseQueryParameter(request *restful.Request) *Query {
 		}
 	}
 	query.FuzzySearch = parseFuzzy(request.QueryParameter(ParameterFuzzySearch))
+	query.Fields = ParseFields(request.QueryParameter(ParameterFields))
 	return query
 }
 
@@ -0,0 +1,148 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+// Package quota enforces the resource quotas of regions when clusters, nodes and operations are admitted.
+package quota
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// Checker computes the quota and usage of regions and admits the requests which stay within them.
+type Checker struct {
+	platform   platform.Reader
+	regions    cluster.RegionReader
+	nodes      cluster.NodeReader
+	operations operation.Reader
+}
+
+func NewChecker(platformReader platform.Reader, regions cluster.RegionReader, nodes cluster.NodeReader, operations operation.Reader) *Checker {
+	return &Checker{
+		platform:   platformReader,
+		regions:    regions,
+		nodes:      nodes,
+		operations: operations,
+	}
+}
+
+// Quota merges the platform quota with the one of the region.
+func (c *Checker) Quota(ctx context.Context, region string) (v1.ResourceQuota, error) {
+	setting, err := c.platform.GetPlatformSetting(ctx)
+	if err != nil {
+		return v1.ResourceQuota{}, err
+	}
+	layers := []*v1.ResourceQuota{&setting.Quota}
+	r, err := c.regions.GetRegionEx(ctx, region, "0")
+	if err != nil && !apierrors.IsNotFound(err) {
+		return v1.ResourceQuota{}, err
+	}
+	if r != nil {
+		layers = append(layers, r.Quota)
+	}
+	return v1.MergeResourceQuotas(layers...), nil
+}
+
+// Usage counts the nodes and clusters of the region and its unfinished operations.
+// A cluster is in the region of its nodes.
+func (c *Checker) Usage(ctx context.Context, region string) (v1.QuotaUsage, error) {
+	q := query.New()
+	q.LabelSelector = fmt.Sprintf("%s=%s", common.LabelTopologyRegion, region)
+	nodes, err := c.nodes.ListNodes(ctx, q)
+	if err != nil {
+		return v1.QuotaUsage{}, err
+	}
+	clusters := sets.NewString()
+	for _, n := range nodes.Items {
+		if name := n.Labels[common.LabelClusterName]; name != "" {
+			clusters.Insert(name)
+		}
+	}
+	ops, err := c.operations.ListOperations(ctx, q)
+	if err != nil {
+		return v1.QuotaUsage{}, err
+	}
+	running := 0
+	for _, op := range ops.Items {
+		if op.Status.Status == v1.OperationStatusRunning || op.Status.Status == v1.OperationStatusPaused {
+			running++
+		}
+	}
+	return v1.QuotaUsage{Clusters: clusters.Len(), Nodes: len(nodes.Items), ConcurrentOperations: running}, nil
+}
+
+// RegionQuota returns the effective quota of the region and its usage.
+func (c *Checker) RegionQuota(ctx context.Context, region string) (*v1.RegionQuota, error) {
+	hard, err := c.Quota(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	used, err := c.Usage(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.RegionQuota{Region: region, Hard: hard, Used: used}, nil
+}
+
+// Admit checks that the request fits into the quota of the region, it returns a
+// *v1.QuotaExceededError when it does not.
+func (c *Checker) Admit(ctx context.Context, region string, request v1.QuotaUsage) error {
+	hard, err := c.Quota(ctx, region)
+	if err != nil {
+		return err
+	}
+	if hard.Clusters == nil && hard.Nodes == nil && hard.ConcurrentOperations == nil {
+		return nil
+	}
+	used, err := c.Usage(ctx, region)
+	if err != nil {
+		return err
+	}
+	return hard.Admit(region, used, request)
+}
+
+// AdmitCluster checks that another cluster and its operation fit into the quota of the region.
+func (c *Checker) AdmitCluster(ctx context.Context, region string) error {
+	return c.Admit(ctx, region, v1.QuotaUsage{Clusters: 1, ConcurrentOperations: 1})
+}
+
+// AdmitNode checks that another node may register in the region.
+func (c *Checker) AdmitNode(ctx context.Context, region string) error {
+	return c.Admit(ctx, region, v1.QuotaUsage{Nodes: 1})
+}
+
+// AdmitOperation checks that another operation may run in the region.
+func (c *Checker) AdmitOperation(ctx context.Context, region string) error {
+	return c.Admit(ctx, region, v1.QuotaUsage{ConcurrentOperations: 1})
+}
+
+// IsExceeded tells whether err is caused by exceeding a quota.
+func IsExceeded(err error) bool {
+	var exceeded *v1.QuotaExceededError
+	return errors.As(err, &exceeded)
+}
@@ -0,0 +1,96 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package quota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	mock_cluster "github.com/kubeclipper/kubeclipper/pkg/models/cluster/mock"
+	mock_operation "github.com/kubeclipper/kubeclipper/pkg/models/operation/mock"
+	mock_platform "github.com/kubeclipper/kubeclipper/pkg/models/platform/mock"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestChecker(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	platformReader := mock_platform.NewMockReader(ctrl)
+	platformReader.EXPECT().GetPlatformSetting(gomock.Any()).Return(&v1.PlatformSetting{
+		Quota: v1.ResourceQuota{Clusters: intPtr(1), ConcurrentOperations: intPtr(5)},
+	}, nil).AnyTimes()
+	regions := mock_cluster.NewMockOperator(ctrl)
+	regions.EXPECT().GetRegionEx(gomock.Any(), "r1", "0").Return(&v1.Region{
+		ObjectMeta: metav1.ObjectMeta{Name: "r1"},
+		Quota:      &v1.ResourceQuota{Clusters: intPtr(2), Nodes: intPtr(3)},
+	}, nil).AnyTimes()
+	regions.EXPECT().GetRegionEx(gomock.Any(), "r2", "0").
+		Return(nil, apierrors.NewNotFound(v1.Resource("region"), "r2")).AnyTimes()
+	regionLabels := func(cluster string) map[string]string {
+		labels := map[string]string{common.LabelTopologyRegion: "r1"}
+		if cluster != "" {
+			labels[common.LabelClusterName] = cluster
+		}
+		return labels
+	}
+	regions.EXPECT().ListNodes(gomock.Any(), gomock.Any()).Return(&v1.NodeList{Items: []v1.Node{
+		{ObjectMeta: metav1.ObjectMeta{Name: "n1", Labels: regionLabels("c1")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n2", Labels: regionLabels("c1")}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "n3", Labels: regionLabels("")}},
+	}}, nil).AnyTimes()
+	operations := mock_operation.NewMockReader(ctrl)
+	operations.EXPECT().ListOperations(gomock.Any(), gomock.Any()).Return(&v1.OperationList{Items: []v1.Operation{
+		{Status: v1.OperationStatus{Status: v1.OperationStatusRunning}},
+		{Status: v1.OperationStatus{Status: v1.OperationStatusSuccessful}},
+	}}, nil).AnyTimes()
+	checker := NewChecker(platformReader, regions, regions, operations)
+
+	q, err := checker.RegionQuota(context.TODO(), "r1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *q.Hard.Clusters != 2 || *q.Hard.Nodes != 3 || *q.Hard.ConcurrentOperations != 5 {
+		t.Errorf("unexpected quota of region r1: %+v", q.Hard)
+	}
+	if q.Used != (v1.QuotaUsage{Clusters: 1, Nodes: 3, ConcurrentOperations: 1}) {
+		t.Errorf("unexpected usage of region r1: %+v", q.Used)
+	}
+
+	if err = checker.AdmitCluster(context.TODO(), "r1"); err != nil {
+		t.Errorf("cluster within quota rejected: %v", err)
+	}
+	if err = checker.AdmitNode(context.TODO(), "r1"); !IsExceeded(err) {
+		t.Errorf("expected node quota of region r1 exceeded, got %v", err)
+	}
+	if err = checker.AdmitOperation(context.TODO(), "r1"); err != nil {
+		t.Errorf("operation within quota rejected: %v", err)
+	}
+	if err = checker.AdmitCluster(context.TODO(), "r2"); !IsExceeded(err) {
+		t.Errorf("expected platform cluster quota exceeded in region r2, got %v", err)
+	}
+}
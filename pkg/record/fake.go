@@ -0,0 +1,44 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package record
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var _ Recorder = (*FakeRecorder)(nil)
+
+// FakeRecorder keeps the events in memory as "<type> <reason> <message>", it is used by tests.
+type FakeRecorder struct {
+	lock   sync.Mutex
+	Events []string
+}
+
+func (f *FakeRecorder) Event(obj runtime.Object, eventType, reason, message string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.Events = append(f.Events, fmt.Sprintf("%s %s %s", eventType, reason, message))
+}
+
+func (f *FakeRecorder) Eventf(obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	f.Event(obj, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
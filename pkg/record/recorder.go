@@ -0,0 +1,160 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package record records the events of kubeclipper objects, like the event recorder of client-go.
+package record
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	// recentEvents is the number of recorded events a recorder remembers to count their repetitions.
+	recentEvents = 4096
+	// aggregationWindow is how long a repetition of an event increases its count instead of
+	// recording a new event.
+	aggregationWindow = 10 * time.Minute
+	recordTimeout     = 10 * time.Second
+)
+
+// Recorder records events of kubeclipper objects. Recording is best effort, the errors are
+// logged and never returned, so that the callers go on with their work.
+type Recorder interface {
+	// Event records an event about obj, eventType is v1.ObjectEventNormal or v1.ObjectEventWarning.
+	Event(obj runtime.Object, eventType, reason, message string)
+	// Eventf is Event with a formatted message.
+	Eventf(obj runtime.Object, eventType, reason, messageFmt string, args ...interface{})
+}
+
+type recorder struct {
+	writer platform.ObjectEventWriter
+	source v1.ObjectEventSource
+	now    func() time.Time
+
+	// lock serializes the recording, so that the repetitions of an event update the same one.
+	lock   sync.Mutex
+	recent *cache.LRUExpireCache
+}
+
+// NewRecorder returns a recorder of the events of component, the events of the same object,
+// type, reason and message within ten minutes are recorded as one with a count.
+func NewRecorder(writer platform.ObjectEventWriter, component string) Recorder {
+	host, _ := os.Hostname()
+	return &recorder{
+		writer: writer,
+		source: v1.ObjectEventSource{Component: component, Host: host},
+		now:    time.Now,
+		recent: cache.NewLRUExpireCache(recentEvents),
+	}
+}
+
+func (r *recorder) Eventf(obj runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	r.Event(obj, eventType, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+func (r *recorder) Event(obj runtime.Object, eventType, reason, message string) {
+	ref, labels, err := reference(obj)
+	if err != nil {
+		logger.Warn("record event failed", zap.String("reason", reason), zap.Error(err))
+		return
+	}
+	key := strings.Join([]string{ref.Kind, ref.Name, string(ref.UID), eventType, reason, message}, "/")
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), recordTimeout)
+	defer cancel()
+	now := metav1.NewTime(r.now())
+	if value, ok := r.recent.Get(key); ok {
+		e := value.(*v1.ObjectEvent).DeepCopy()
+		e.Count++
+		e.LastTimestamp = now
+		updated, err := r.writer.UpdateObjectEvent(ctx, e)
+		if err == nil {
+			r.recent.Add(key, updated, aggregationWindow)
+			return
+		}
+		// the event expired or was changed by another recorder, it is recorded anew
+		logger.Debug("update event failed", zap.String("event", e.Name), zap.Error(err))
+	}
+	e := &v1.ObjectEvent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   fmt.Sprintf("%s.%x", ref.Name, now.UnixNano()),
+			Labels: labels,
+		},
+		InvolvedObject: ref,
+		Type:           eventType,
+		Reason:         reason,
+		Message:        message,
+		Source:         r.source,
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	created, err := r.writer.CreateObjectEvent(ctx, e)
+	if err != nil {
+		logger.Warn("record event failed", zap.String("kind", ref.Kind), zap.String("name", ref.Name),
+			zap.String("reason", reason), zap.Error(err))
+		return
+	}
+	r.recent.Add(key, created, aggregationWindow)
+}
+
+// reference returns the reference to obj and the labels of its events. The events are labeled
+// with the cluster and project of the object, so that they are listed with the cluster and
+// scoped to the project.
+func reference(obj runtime.Object) (v1.ObjectReference, map[string]string, error) {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return v1.ObjectReference{}, nil, err
+	}
+	kind := obj.GetObjectKind().GroupVersionKind().Kind
+	if kind == "" {
+		gvks, _, err := scheme.Scheme.ObjectKinds(obj)
+		if err != nil {
+			return v1.ObjectReference{}, nil, err
+		}
+		kind = gvks[0].Kind
+	}
+	labels := map[string]string{}
+	for _, key := range []string{common.LabelClusterName, common.LabelProject} {
+		if value := accessor.GetLabels()[key]; value != "" {
+			labels[key] = value
+		}
+	}
+	if kind == "Cluster" {
+		labels[common.LabelClusterName] = accessor.GetName()
+	}
+	return v1.ObjectReference{Kind: kind, Name: accessor.GetName(), UID: accessor.GetUID()}, labels, nil
+}
@@ -0,0 +1,105 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package record
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+type fakeWriter struct {
+	events map[string]*v1.ObjectEvent
+}
+
+func (w *fakeWriter) CreateObjectEvent(ctx context.Context, e *v1.ObjectEvent) (*v1.ObjectEvent, error) {
+	w.events[e.Name] = e.DeepCopy()
+	return e, nil
+}
+
+func (w *fakeWriter) UpdateObjectEvent(ctx context.Context, e *v1.ObjectEvent) (*v1.ObjectEvent, error) {
+	if _, ok := w.events[e.Name]; !ok {
+		return nil, apierrors.NewNotFound(v1.Resource("objectevents"), e.Name)
+	}
+	w.events[e.Name] = e.DeepCopy()
+	return e, nil
+}
+
+func TestRecorder(t *testing.T) {
+	w := &fakeWriter{events: map[string]*v1.ObjectEvent{}}
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	r := &recorder{
+		writer: w,
+		source: v1.ObjectEventSource{Component: "test"},
+		now:    func() time.Time { return now },
+		recent: cache.NewLRUExpireCache(recentEvents),
+	}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{
+		Name:   "n1",
+		UID:    "uid-1",
+		Labels: map[string]string{common.LabelClusterName: "c1", common.LabelProject: "p1", "other": "x"},
+	}}
+
+	r.Event(node, v1.ObjectEventWarning, v1.EventReasonNodeHeartbeatLost, "agent is gone")
+	now = now.Add(time.Minute)
+	r.Event(node, v1.ObjectEventWarning, v1.EventReasonNodeHeartbeatLost, "agent is gone")
+	r.Eventf(node, v1.ObjectEventNormal, "Other", "%d", 1)
+	if len(w.events) != 2 {
+		t.Fatalf("recorded %d events, want the repeated one counted: %v", len(w.events), w.events)
+	}
+	var e *v1.ObjectEvent
+	for _, ev := range w.events {
+		if ev.Reason == v1.EventReasonNodeHeartbeatLost {
+			e = ev
+		}
+	}
+	if e == nil || e.Count != 2 || !e.LastTimestamp.Equal(&metav1.Time{Time: now}) || e.FirstTimestamp.Equal(&e.LastTimestamp) {
+		t.Fatalf("repeated event = %+v, want count 2 and the timestamps of the first and last", e)
+	}
+	want := v1.ObjectReference{Kind: "Node", Name: "n1", UID: "uid-1"}
+	if e.InvolvedObject != want {
+		t.Errorf("involved object = %+v, want %+v", e.InvolvedObject, want)
+	}
+	if len(e.Labels) != 2 || e.Labels[common.LabelClusterName] != "c1" || e.Labels[common.LabelProject] != "p1" {
+		t.Errorf("labels = %v, want the cluster and project of the node", e.Labels)
+	}
+
+	// an expired event is recorded anew
+	delete(w.events, e.Name)
+	now = now.Add(time.Minute)
+	r.Event(node, v1.ObjectEventWarning, v1.EventReasonNodeHeartbeatLost, "agent is gone")
+	if len(w.events) != 2 {
+		t.Fatalf("recorded %d events, want the expired one recorded again", len(w.events))
+	}
+
+	c := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c2"}}
+	r.Event(c, v1.ObjectEventNormal, "Test", "cluster event")
+	for _, ev := range w.events {
+		if ev.InvolvedObject.Kind == "Cluster" && ev.Labels[common.LabelClusterName] != "c2" {
+			t.Errorf("labels of cluster event = %v, want the cluster", ev.Labels)
+		}
+	}
+}
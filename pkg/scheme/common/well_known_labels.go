@@ -22,6 +22,7 @@ const (
 	LabelHostname        = "kubeclipper.io/hostname"
 	LabelOSStable        = "kubeclipper.io/os"
 	LabelArchStable      = "kubeclipper.io/arch"
+	LabelNodeGPU         = "kubeclipper.io/gpu"
 	LabelTopologyZone    = "topology.kubeclipper.io/zone"
 	LabelTopologyRegion  = "topology.kubeclipper.io/region"
 	LabelNodeRole        = "kubeclipper.io/nodeRole"
@@ -40,6 +41,25 @@ const (
 	LabelExternalIP      = "kubeclipper.io/externalIP"
 	LabelUpgradeVersion  = "kubeclipper.io/upgrade-version"
 	LabelBackupPoint     = "kubeclipper.io/backupPoint"
+	LabelClusterTemplate = "kubeclipper.io/cluster-template"
+	LabelCronMaintenance = "kubeclipper.io/cron-maintenance"
+	LabelPrecheckSource  = "kubeclipper.io/precheck-source"
+	LabelNotifier        = "kubeclipper.io/notifier"
+	// LabelKubeconfigScope marks the service accounts kubeconfigs of managed clusters are issued for.
+	LabelKubeconfigScope = "kubeclipper.io/kubeconfig-scope"
+	// LabelClusterImported marks the clusters adopted from existing kubeadm clusters instead of installed by kubeclipper.
+	LabelClusterImported = "kubeclipper.io/imported"
+	// LabelNotificationPhase mirrors the phase of a notification, so the pending ones can be listed.
+	LabelNotificationPhase = "kubeclipper.io/notification-phase"
+	// LabelNodeExternalID is the id of the node in an external inventory such as a CMDB.
+	LabelNodeExternalID = "kubeclipper.io/external-id"
+	// LabelNodePool is set on the kubernetes nodes of a node pool.
+	LabelNodePool = "kubeclipper.io/nodepool"
+	// LabelProject is the project a cluster, node, backup or operation belongs to. On a global role
+	// binding it limits the binding to the requests made in the project.
+	LabelProject = "kubeclipper.io/project"
+	// LabelDeleted marks the clusters and backups in the recycle bin, they are hidden from lists.
+	LabelDeleted = "kubeclipper.io/deleted"
 )
 
 const (
@@ -51,6 +71,25 @@ const (
 	RegoOverrideAnnotation     = "kubeclipper.io/rego-override"
 	RoleAnnotation             = "iam.kubeclipper.io/role"
 	AnnotationInternal         = "kubeclipper.io/internal"
+	// AnnotationOperationPause asks a running operation to pause at its next checkpoint step,
+	// or after the running step when the operation has no checkpoint.
+	AnnotationOperationPause = "kubeclipper.io/operation-pause"
+	// AnnotationOperationCancel asks a running operation to stop and skip its remaining steps.
+	AnnotationOperationCancel = "kubeclipper.io/operation-cancel"
+	// AnnotationStepPolicies holds a JSON list of step policies applied to the operations of a cluster.
+	AnnotationStepPolicies = "kubeclipper.io/step-policies"
+	// AnnotationOperationHooks holds a JSON list of hooks run before and after the operations of a cluster.
+	AnnotationOperationHooks = "kubeclipper.io/operation-hooks"
+	// AnnotationProxyMode set to "agent" makes the cluster proxy reach the apiserver through the agent of
+	// a master, for clusters whose apiserver is not reachable from kc-server.
+	AnnotationProxyMode = "kubeclipper.io/proxy-mode"
+	// AnnotationDeletionPropagation is the propagation policy the cluster is deleted with once it is uninstalled,
+	// one of Background, Foreground and Orphan.
+	AnnotationDeletionPropagation = "kubeclipper.io/deletion-propagation"
+	// AnnotationOrphaned marks a dependent orphaned by the deletion of its owner, it is neither adopted nor collected.
+	AnnotationOrphaned = "kubeclipper.io/orphaned"
+	// AnnotationDeletedAt is the RFC3339 time a cluster or backup was moved to the recycle bin.
+	AnnotationDeletedAt = "kubeclipper.io/deleted-at"
 )
 
 type NodeRole string // master/worker/ingress(worker)
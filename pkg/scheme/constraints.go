@@ -0,0 +1,257 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package scheme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// Selection is a resource of a certain version chosen by a cluster, e.g. k8s v1.23.6 or calico v3.21.2.
+type Selection struct {
+	Name    string
+	Version string
+}
+
+func (s Selection) String() string {
+	return s.Name + " " + s.Version
+}
+
+// comparator is a single condition of a version range, e.g. >=v1.20.0.
+type comparator struct {
+	op string
+	v  *version.Version
+}
+
+func (c comparator) match(v *version.Version) bool {
+	cmp := 0
+	if v.LessThan(c.v) {
+		cmp = -1
+	} else if c.v.LessThan(v) {
+		cmp = 1
+	}
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	case "!=":
+		return cmp != 0
+	default:
+		return cmp == 0
+	}
+}
+
+// VersionRange is a set of conditions separated by spaces which must all be met,
+// e.g. ">=v1.20.0 <v1.24.0". A version without operator must be matched exactly.
+type VersionRange []comparator
+
+// ParseVersionRange parses the range of the compatible field of resource metadata.
+func ParseVersionRange(s string) (VersionRange, error) {
+	var r VersionRange
+	for _, field := range strings.Fields(s) {
+		op := ""
+		for _, o := range []string{">=", "<=", "!=", ">", "<", "="} {
+			if strings.HasPrefix(field, o) {
+				op = o
+				break
+			}
+		}
+		v, err := version.ParseGeneric(strings.TrimPrefix(field, op))
+		if err != nil {
+			return nil, fmt.Errorf("invalid version range %q: %v", s, err)
+		}
+		r = append(r, comparator{op: op, v: v})
+	}
+	if len(r) == 0 {
+		return nil, fmt.Errorf("empty version range")
+	}
+	return r, nil
+}
+
+// Contains tells whether the version is in the range.
+func (r VersionRange) Contains(v string) (bool, error) {
+	parsed, err := version.ParseGeneric(v)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range r {
+		if !c.match(parsed) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compatibleRanges merges the compatible ranges of all architectures of the resource version.
+func (p ComponentMetaList) compatibleRanges(name, ver string) map[string]string {
+	ranges := make(map[string]string)
+	for _, m := range p {
+		if m.Name != name || m.Version != ver {
+			continue
+		}
+		for k, v := range m.Compatible {
+			ranges[k] = v
+		}
+	}
+	return ranges
+}
+
+// MissingFeature returns an error when the metadata of the resource version does not declare the feature.
+// Resources the metadata does not list are not gated.
+func (p ComponentMetaList) MissingFeature(s Selection, feature string) error {
+	found := false
+	for _, m := range p {
+		if m.Name != s.Name || m.Version != s.Version {
+			continue
+		}
+		found = true
+		for _, f := range m.Features {
+			if f == feature {
+				return nil
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return fmt.Errorf("%s does not support %s", s, feature)
+}
+
+// versions returns the distinct versions of the resource, the oldest first.
+func (p ComponentMetaList) versions(name string) []string {
+	seen := make(map[string]bool)
+	var versions []string
+	for _, m := range p {
+		if m.Name == name && !seen[m.Version] {
+			seen[m.Version] = true
+			versions = append(versions, m.Version)
+		}
+	}
+	sort.SliceStable(versions, func(i, j int) bool {
+		vi, erri := version.ParseGeneric(versions[i])
+		vj, errj := version.ParseGeneric(versions[j])
+		if erri != nil || errj != nil {
+			return versions[i] < versions[j]
+		}
+		return vi.LessThan(vj)
+	})
+	return versions
+}
+
+// CheckCompatibility validates every selected resource against the compatible ranges the metadata declares
+// for the other selected ones. The error lists each conflict together with the versions which would work.
+func (p ComponentMetaList) CheckCompatibility(selected []Selection) error {
+	var conflicts []string
+	for _, s := range selected {
+		ranges := p.compatibleRanges(s.Name, s.Version)
+		for _, other := range selected {
+			rs, ok := ranges[other.Name]
+			if !ok || other.Name == s.Name {
+				continue
+			}
+			r, err := ParseVersionRange(rs)
+			if err != nil {
+				return fmt.Errorf("metadata of %s: %v", s, err)
+			}
+			if ok, err := r.Contains(other.Version); err != nil || ok {
+				continue
+			}
+			msg := fmt.Sprintf("%s not supported with %s", s, other)
+			if alternatives := p.compatibleVersions(s.Name, other); len(alternatives) > 0 {
+				msg += fmt.Sprintf(", use %s %s", s.Name, strings.Join(alternatives, " or "))
+			} else {
+				msg += fmt.Sprintf(", use %s %s", other.Name, rs)
+			}
+			conflicts = append(conflicts, msg)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("incompatible versions: %s", strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// compatibleVersions returns the versions of the resource which work with the other selected resource.
+func (p ComponentMetaList) compatibleVersions(name string, other Selection) []string {
+	var versions []string
+	for _, v := range p.versions(name) {
+		rs, ok := p.compatibleRanges(name, v)[other.Name]
+		if !ok {
+			continue
+		}
+		r, err := ParseVersionRange(rs)
+		if err != nil {
+			continue
+		}
+		if contains, err := r.Contains(other.Version); err == nil && contains {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// MetadataCache serves the metadata file of the static server. The file is read again once it is
+// modified, so the published resources and their compatibility take effect without restarting kc-server.
+type MetadataCache struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	metas   ComponentMetaList
+}
+
+// NewMetadataCache returns the cache of the metadata file in the static server path.
+func NewMetadataCache(path string) *MetadataCache {
+	return &MetadataCache{path: path}
+}
+
+// Get returns the metadata, which is empty if the static server has no metadata file.
+func (c *MetadataCache) Get() (ComponentMetaList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	info, err := os.Stat(filepath.Join(c.path, "metadata.json"))
+	if os.IsNotExist(err) {
+		c.metas, c.modTime, c.size = nil, time.Time{}, 0
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if c.metas != nil && info.ModTime().Equal(c.modTime) && info.Size() == c.size {
+		return c.metas, nil
+	}
+	metas := ComponentMetaList{}
+	if err = metas.ReadFile(c.path, false); err != nil {
+		return nil, err
+	}
+	c.metas, c.modTime, c.size = metas, info.ModTime(), info.Size()
+	return c.metas, nil
+}
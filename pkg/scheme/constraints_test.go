@@ -0,0 +1,132 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package scheme
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestVersionRangeContains(t *testing.T) {
+	tests := []struct {
+		r    string
+		v    string
+		want bool
+	}{
+		{r: ">=v1.20.0 <v1.24.0", v: "v1.23.6", want: true},
+		{r: ">=v1.20.0 <v1.24.0", v: "v1.24.0", want: false},
+		{r: ">v3.21", v: "v3.21.2", want: true},
+		{r: "v1.23.6", v: "v1.23.6", want: true},
+		{r: "!=v1.23.6", v: "v1.23.6", want: false},
+	}
+	for _, tt := range tests {
+		r, err := ParseVersionRange(tt.r)
+		if err != nil {
+			t.Fatalf("ParseVersionRange(%q) error = %v", tt.r, err)
+		}
+		got, err := r.Contains(tt.v)
+		if err != nil {
+			t.Fatalf("Contains(%q) error = %v", tt.v, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q contains %q = %v, want %v", tt.r, tt.v, got, tt.want)
+		}
+	}
+	if _, err := ParseVersionRange(">=latest"); err == nil {
+		t.Error("expected an error for an invalid range")
+	}
+}
+
+func testMetas() ComponentMetaList {
+	return ComponentMetaList{
+		{Type: "k8s", Name: "k8s", Version: "v1.27.1", Arch: "amd64"},
+		{Type: "cni", Name: "calico", Version: "v3.21.2", Arch: "amd64", Compatible: map[string]string{"k8s": ">=v1.20.0 <v1.24.0"}},
+		{Type: "cni", Name: "calico", Version: "v3.24.5", Arch: "amd64", Compatible: map[string]string{"k8s": ">=v1.22.0 <v1.28.0"}},
+		{Type: "cni", Name: "calico", Version: "v3.24.5", Arch: "arm64", Compatible: map[string]string{"k8s": ">=v1.22.0 <v1.28.0"}, Features: []string{v1.FeatureIPv6}},
+	}
+}
+
+func TestMissingFeature(t *testing.T) {
+	metas := testMetas()
+	if err := metas.MissingFeature(Selection{Name: "calico", Version: "v3.21.2"}, v1.FeatureIPv6); err == nil {
+		t.Error("expected calico v3.21.2 to miss ipv6")
+	}
+	// the feature of any architecture counts
+	if err := metas.MissingFeature(Selection{Name: "calico", Version: "v3.24.5"}, v1.FeatureIPv6); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := metas.MissingFeature(Selection{Name: "flannel", Version: "v0.20.2"}, v1.FeatureIPv6); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	metas := testMetas()
+	err := metas.CheckCompatibility([]Selection{{Name: "k8s", Version: "v1.27.1"}, {Name: "calico", Version: "v3.21.2"}})
+	if err == nil {
+		t.Fatal("expected calico v3.21.2 to be rejected with k8s v1.27.1")
+	}
+	if !strings.Contains(err.Error(), "calico v3.21.2 not supported with k8s v1.27.1, use calico v3.24.5") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err = metas.CheckCompatibility([]Selection{{Name: "k8s", Version: "v1.27.1"}, {Name: "calico", Version: "v3.24.5"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// resources without declared ranges are not restricted
+	if err = metas.CheckCompatibility([]Selection{{Name: "k8s", Version: "v1.27.1"}, {Name: "flannel", Version: "v0.20.2"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMetadataCacheReload(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewMetadataCache(dir)
+	metas, err := cache.Get()
+	if err != nil || len(metas) != 0 {
+		t.Fatalf("Get() without metadata = %v, %v", metas, err)
+	}
+	write := func(list []v1.MetaResource, modTime time.Time) {
+		data, err := json.Marshal(list)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, "metadata.json")
+		if err = os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write(testMetas()[:1], now)
+	if metas, err = cache.Get(); err != nil || len(metas) != 1 {
+		t.Fatalf("Get() = %v, %v, want 1 resource", metas, err)
+	}
+	write(testMetas(), now.Add(time.Second))
+	if metas, err = cache.Get(); err != nil || len(metas) != 4 {
+		t.Fatalf("Get() after update = %v, %v, want 4 resources", metas, err)
+	}
+}
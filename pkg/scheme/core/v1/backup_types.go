@@ -39,12 +39,33 @@ type Backup struct {
 	// a node selected for executing backup tasks
 	PreferredNode   string `json:"preferredNode,omitempty" optional:"true"`
 	BackupPointName string `json:"backupPointName"`
+	// Verify downloads the snapshot back after it is saved and checks it can be restored.
+	Verify bool `json:"verify,omitempty" optional:"true"`
 }
 
 type BackupStatus struct {
 	BackupFileSize      int64  `json:"backupFileSize"`
 	BackupFileMD5       string `json:"backupFileMD5"`
 	ClusterBackupStatus `json:"status"`
+	// Verification is the result of the check requested by Backup.Verify.
+	Verification *BackupVerification `json:"verification,omitempty"`
+}
+
+type BackupVerificationStatus string
+
+const (
+	BackupVerified           BackupVerificationStatus = "verified"
+	BackupVerificationFailed BackupVerificationStatus = "failed"
+)
+
+type BackupVerification struct {
+	Status BackupVerificationStatus `json:"status"`
+	// SnapshotHash is the hash of the etcd snapshot reported by `etcdutl snapshot status`.
+	SnapshotHash string      `json:"snapshotHash,omitempty"`
+	Revision     int64       `json:"revision,omitempty"`
+	TotalKeys    int64       `json:"totalKeys,omitempty"`
+	Message      string      `json:"message,omitempty"`
+	VerifiedAt   metav1.Time `json:"verifiedAt"`
 }
 
 // ClusterBackupStatus describes the status of a cluster backup
@@ -18,7 +18,14 @@
 
 package v1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
 
 // +genclient
 // +genclient:nonNamespaced
@@ -33,6 +40,53 @@ type BackupPoint struct {
 	StorageType       string    `json:"storageType,omitempty"`
 	FsConfig          *FsConfig `json:"fsConfig,omitempty"`
 	S3Config          *S3Config `json:"s3Config,omitempty"`
+	// Quota limits the total size of the backups stored in the point,
+	// new backups are rejected once it is reached. No limit when unset.
+	Quota  *resource.Quantity `json:"quota,omitempty"`
+	Status BackupPointStatus  `json:"status,omitempty"`
+}
+
+type BackupPointStatus struct {
+	UsedBytes int64 `json:"usedBytes"`
+	// Usage is the storage used by each cluster, sorted by cluster name.
+	Usage []BackupPointUsage `json:"usage,omitempty"`
+	// Full is set when UsedBytes reaches the quota.
+	Full bool `json:"full,omitempty"`
+}
+
+type BackupPointUsage struct {
+	Cluster string `json:"cluster"`
+	Backups int    `json:"backups"`
+	Bytes   int64  `json:"bytes"`
+}
+
+// ComputeUsage returns the storage used by the backups stored in the point.
+func (bp *BackupPoint) ComputeUsage(backups []Backup) BackupPointStatus {
+	var status BackupPointStatus
+	usage := make(map[string]*BackupPointUsage)
+	for i := range backups {
+		b := &backups[i]
+		if b.BackupPointName != bp.Name {
+			continue
+		}
+		clu := b.Labels[common.LabelClusterName]
+		u, ok := usage[clu]
+		if !ok {
+			u = &BackupPointUsage{Cluster: clu}
+			usage[clu] = u
+		}
+		u.Backups++
+		u.Bytes += b.Status.BackupFileSize
+		status.UsedBytes += b.Status.BackupFileSize
+	}
+	for _, u := range usage {
+		status.Usage = append(status.Usage, *u)
+	}
+	sort.Slice(status.Usage, func(i, j int) bool {
+		return status.Usage[i].Cluster < status.Usage[j].Cluster
+	})
+	status.Full = bp.Quota != nil && status.UsedBytes >= bp.Quota.Value()
+	return status
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -0,0 +1,64 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+func TestBackupPointComputeUsage(t *testing.T) {
+	backup := func(point, cluster string, size int64) Backup {
+		return Backup{
+			ObjectMeta:      metav1.ObjectMeta{Labels: map[string]string{common.LabelClusterName: cluster}},
+			BackupPointName: point,
+			Status:          BackupStatus{BackupFileSize: size},
+		}
+	}
+	backups := []Backup{
+		backup("bp1", "c2", 300),
+		backup("bp1", "c1", 100),
+		backup("bp2", "c1", 1000),
+		backup("bp1", "c1", 200),
+	}
+
+	bp := &BackupPoint{ObjectMeta: metav1.ObjectMeta{Name: "bp1"}}
+	status := bp.ComputeUsage(backups)
+	if status.UsedBytes != 600 || status.Full {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if len(status.Usage) != 2 || status.Usage[0] != (BackupPointUsage{Cluster: "c1", Backups: 2, Bytes: 300}) ||
+		status.Usage[1] != (BackupPointUsage{Cluster: "c2", Backups: 1, Bytes: 300}) {
+		t.Errorf("unexpected usage %+v", status.Usage)
+	}
+
+	quota := resource.MustParse("600")
+	bp.Quota = &quota
+	if !bp.ComputeUsage(backups).Full {
+		t.Error("expected backup point to be full")
+	}
+	quota = resource.MustParse("1Ki")
+	if bp.ComputeUsage(backups).Full {
+		t.Error("expected backup point not to be full")
+	}
+}
@@ -0,0 +1,100 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// defaultAPIServerPort is the port of the external endpoint when it has none.
+const defaultAPIServerPort = "6443"
+
+// ValidateCertSANs checks every subject alternative name is an ip or a dns name, which may start with a wildcard.
+func ValidateCertSANs(sans []string) error {
+	for _, san := range sans {
+		if net.ParseIP(san) != nil {
+			continue
+		}
+		if errs := validation.IsDNS1123Subdomain(strings.TrimPrefix(san, "*.")); len(errs) > 0 {
+			return fmt.Errorf("invalid cert san %q: %s", san, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateExternalEndpoint checks the endpoint is host[:port], the host is an ip or a dns name.
+func ValidateExternalEndpoint(endpoint string) error {
+	if endpoint == "" {
+		return nil
+	}
+	host, port := splitEndpoint(endpoint)
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return fmt.Errorf("invalid port of external endpoint %q", endpoint)
+	}
+	if err := ValidateCertSANs([]string{host}); err != nil || strings.HasPrefix(host, "*.") {
+		return fmt.Errorf("invalid host of external endpoint %q", endpoint)
+	}
+	return nil
+}
+
+func splitEndpoint(endpoint string) (string, string) {
+	if host, port, err := net.SplitHostPort(endpoint); err == nil {
+		return host, port
+	}
+	return strings.Trim(endpoint, "[]"), defaultAPIServerPort
+}
+
+// APIServerCertSANs returns the extra subject alternative names of the apiserver certificate,
+// the host of the external endpoint and the control plane vip are added when they are not listed.
+func (k *Kubeadm) APIServerCertSANs() []string {
+	sans := append([]string(nil), k.CertSANs...)
+	var hosts []string
+	if k.ExternalEndpoint != "" {
+		host, _ := splitEndpoint(k.ExternalEndpoint)
+		hosts = append(hosts, host)
+	}
+	if k.ControlPlaneVIP != nil {
+		hosts = append(hosts, k.ControlPlaneVIP.Address)
+	}
+	for _, host := range hosts {
+		if !sets.NewString(sans...).Has(host) {
+			sans = append(sans, host)
+		}
+	}
+	return sans
+}
+
+// ExternalServer returns the url clients outside the cluster reach the apiserver with, the control plane
+// vip is used when the cluster has no external endpoint. It is empty when the cluster has neither.
+func (k *Kubeadm) ExternalServer() string {
+	if k.ExternalEndpoint == "" {
+		if k.ControlPlaneVIP != nil {
+			return "https://" + k.ControlPlaneVIP.Endpoint()
+		}
+		return ""
+	}
+	host, port := splitEndpoint(k.ExternalEndpoint)
+	return "https://" + net.JoinHostPort(host, port)
+}
@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateCertSANs(t *testing.T) {
+	if err := ValidateCertSANs([]string{"10.0.0.10", "fd00::10", "k8s.example.com", "*.example.com"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, san := range []string{"https://k8s.example.com", "Example.com", "a_b.example.com"} {
+		if err := ValidateCertSANs([]string{san}); err == nil {
+			t.Errorf("expected an error for %q", san)
+		}
+	}
+}
+
+func TestValidateExternalEndpoint(t *testing.T) {
+	for _, endpoint := range []string{"", "k8s.example.com", "k8s.example.com:443", "10.0.0.10:6443", "[fd00::10]:6443"} {
+		if err := ValidateExternalEndpoint(endpoint); err != nil {
+			t.Errorf("%q: unexpected error: %v", endpoint, err)
+		}
+	}
+	for _, endpoint := range []string{"k8s.example.com:0", "k8s.example.com:https", "*.example.com", "https://k8s.example.com"} {
+		if err := ValidateExternalEndpoint(endpoint); err == nil {
+			t.Errorf("expected an error for %q", endpoint)
+		}
+	}
+}
+
+func TestKubeadmExternalEndpoint(t *testing.T) {
+	k := &Kubeadm{CertSANs: []string{"10.0.0.10"}}
+	if got := k.APIServerCertSANs(); !reflect.DeepEqual(got, []string{"10.0.0.10"}) {
+		t.Errorf("APIServerCertSANs() = %v", got)
+	}
+	if got := k.ExternalServer(); got != "" {
+		t.Errorf("ExternalServer() = %q, want empty", got)
+	}
+	k.ExternalEndpoint = "k8s.example.com"
+	if got := k.APIServerCertSANs(); !reflect.DeepEqual(got, []string{"10.0.0.10", "k8s.example.com"}) {
+		t.Errorf("APIServerCertSANs() = %v", got)
+	}
+	if got := k.ExternalServer(); got != "https://k8s.example.com:6443" {
+		t.Errorf("ExternalServer() = %q", got)
+	}
+	k.ExternalEndpoint = "10.0.0.10:443"
+	if got := k.APIServerCertSANs(); !reflect.DeepEqual(got, []string{"10.0.0.10"}) {
+		t.Errorf("APIServerCertSANs() = %v", got)
+	}
+	if got := k.ExternalServer(); got != "https://10.0.0.10:443" {
+		t.Errorf("ExternalServer() = %q", got)
+	}
+}
+
+func TestControlPlaneVIP(t *testing.T) {
+	var vip *ControlPlaneVIP
+	if err := vip.Validate(); err != nil {
+		t.Errorf("nil vip: unexpected error: %v", err)
+	}
+	for _, v := range []ControlPlaneVIP{
+		{Mode: "lvs", Address: "10.0.0.100"},
+		{Mode: ControlPlaneVIPKubeVIP, Address: "fd00::100"},
+		{Mode: ControlPlaneVIPKeepalived, Address: "10.0.0.100", VirtualRouterID: 256},
+	} {
+		if err := v.Validate(); err == nil {
+			t.Errorf("expected an error for %+v", v)
+		}
+	}
+
+	k := &Kubeadm{CertSANs: []string{"10.0.0.10"}, ControlPlaneVIP: &ControlPlaneVIP{Mode: ControlPlaneVIPKeepalived, Address: "10.0.0.100"}}
+	if err := k.ControlPlaneVIP.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if got := k.APIServerCertSANs(); !reflect.DeepEqual(got, []string{"10.0.0.10", "10.0.0.100"}) {
+		t.Errorf("APIServerCertSANs() = %v", got)
+	}
+	if got := k.ExternalServer(); got != "https://10.0.0.100:8443" {
+		t.Errorf("ExternalServer() = %q", got)
+	}
+	if got := k.ControlPlaneVIP.RouterID(); got != 51 {
+		t.Errorf("RouterID() = %d", got)
+	}
+	k.ExternalEndpoint = "k8s.example.com"
+	if got := k.ExternalServer(); got != "https://k8s.example.com:6443" {
+		t.Errorf("ExternalServer() = %q", got)
+	}
+}
@@ -0,0 +1,178 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// ClusterDiff is the difference between an existing cluster and the desired one,
+// it tells kcctl apply which update APIs should be called.
+type ClusterDiff struct {
+	// Unchanged is true when nothing need to be applied.
+	Unchanged bool `json:"unchanged"`
+	// Labels and Annotations are the metadata changed or added by the desired cluster.
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	// AddWorkers and RemoveWorkers are the worker nodes to be added to or removed from the cluster.
+	AddWorkers    WorkerNodeList `json:"addWorkers,omitempty"`
+	RemoveWorkers WorkerNodeList `json:"removeWorkers,omitempty"`
+	// InstallComponents and UninstallComponents are the components to be installed or uninstalled.
+	InstallComponents   []Component `json:"installComponents,omitempty"`
+	UninstallComponents []Component `json:"uninstallComponents,omitempty"`
+	// UpgradeComponents are the installed components whose version or config is changed in place.
+	UpgradeComponents []Component `json:"upgradeComponents,omitempty"`
+	// KubernetesVersion is the version the cluster should be upgraded to, empty if unchanged.
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Forbidden is the changed fields which can not be updated on an existing cluster.
+	Forbidden []string `json:"forbidden,omitempty"`
+}
+
+// DiffCluster compares the desired cluster with the current one.
+// Labels and annotations are merged, so keys missing in desired cluster are not treated as changes.
+func DiffCluster(current, desired *Cluster) *ClusterDiff {
+	diff := &ClusterDiff{}
+	diff.Labels = diffStringMap(current.Labels, desired.Labels)
+	diff.Annotations = diffStringMap(current.Annotations, desired.Annotations)
+
+	if desired.ClusterType != "" && desired.ClusterType != current.ClusterType {
+		diff.Forbidden = append(diff.Forbidden, "type")
+	}
+	if current.Kubeadm != nil && desired.Kubeadm != nil {
+		diffKubeadm(current.Kubeadm, desired, diff)
+	}
+
+	diff.Unchanged = len(diff.Labels) == 0 && len(diff.Annotations) == 0 &&
+		len(diff.AddWorkers) == 0 && len(diff.RemoveWorkers) == 0 &&
+		len(diff.InstallComponents) == 0 && len(diff.UninstallComponents) == 0 && len(diff.UpgradeComponents) == 0 &&
+		diff.KubernetesVersion == "" && len(diff.Forbidden) == 0
+	return diff
+}
+
+func diffKubeadm(current *Kubeadm, desiredCluster *Cluster, diff *ClusterDiff) {
+	// fill the defaults which are filled at creation, otherwise they are always different.
+	desired := desiredCluster.Kubeadm.DeepCopy()
+	(&Cluster{ClusterType: ClusterKubeadm, Kubeadm: desired}).Complete()
+
+	if !reflect.DeepEqual(sortedIDs(current.Masters), sortedIDs(desired.Masters)) {
+		diff.Forbidden = append(diff.Forbidden, "kubeadm.masters")
+	}
+	for field, changed := range map[string]bool{
+		"kubeadm.networking":       !reflect.DeepEqual(current.Networking, desired.Networking),
+		"kubeadm.containerRuntime": !reflect.DeepEqual(current.ContainerRuntime, desired.ContainerRuntime),
+		"kubeadm.kubeComponents":   !reflect.DeepEqual(current.KubeComponents, desired.KubeComponents),
+		"kubeadm.localRegistry":    current.LocalRegistry != desired.LocalRegistry,
+		"kubeadm.offline":          current.Offline != desired.Offline,
+	} {
+		if changed {
+			diff.Forbidden = append(diff.Forbidden, field)
+		}
+	}
+	sort.Strings(diff.Forbidden)
+
+	if add := desired.Workers.Complement(current.Workers...); len(add) > 0 {
+		diff.AddWorkers = add
+	}
+	if remove := current.Workers.Complement(desired.Workers...); len(remove) > 0 {
+		diff.RemoveWorkers = remove
+	}
+
+	diff.InstallComponents, diff.UninstallComponents, diff.UpgradeComponents = diffComponents(current.Components, desired.Components)
+
+	if desired.KubernetesVersion != "" && desired.KubernetesVersion != current.KubernetesVersion {
+		diff.KubernetesVersion = desired.KubernetesVersion
+	}
+}
+
+func diffStringMap(current, desired map[string]string) map[string]string {
+	var changed map[string]string
+	for k, v := range desired {
+		if cv, ok := current[k]; ok && cv == v {
+			continue
+		}
+		if changed == nil {
+			changed = make(map[string]string)
+		}
+		changed[k] = v
+	}
+	return changed
+}
+
+func sortedIDs(l WorkerNodeList) []string {
+	ids := l.GetNodeIDs()
+	sort.Strings(ids)
+	return ids
+}
+
+// diffComponents matches the desired components with the current ones by name. A component whose version
+// or config is changed is upgraded in place, it is never uninstalled for that. The unchanged components
+// are matched first, the remaining ones of the same name are matched in order.
+func diffComponents(current, desired []Component) (install, uninstall, upgrade []Component) {
+	remaining := append([]Component(nil), current...)
+	take := func(match func(Component) bool) bool {
+		for i := range remaining {
+			if match(remaining[i]) {
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+	var changed []Component
+	for _, c := range desired {
+		if !take(c.Equal) {
+			changed = append(changed, c)
+		}
+	}
+	for _, c := range changed {
+		if take(func(v Component) bool { return v.Name == c.Name }) {
+			upgrade = append(upgrade, c)
+			continue
+		}
+		install = append(install, c)
+	}
+	if len(remaining) > 0 {
+		uninstall = remaining
+	}
+	return install, uninstall, upgrade
+}
+
+// Equal tells whether the two components are of the same version and config,
+// the key order and indent of the config are ignored.
+func (c Component) Equal(o Component) bool {
+	return c.Name == o.Name && c.Version == o.Version && jsonEqual(c.Config.Raw, o.Config.Raw)
+}
+
+// jsonEqual compares two json documents semantically, the key order and indent are ignored.
+func jsonEqual(a, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var av, bv interface{}
+	if err := json.Unmarshal(a, &av); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &bv); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
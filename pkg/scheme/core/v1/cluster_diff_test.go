@@ -0,0 +1,177 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func newDiffTestCluster() *Cluster {
+	return &Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "demo",
+			Labels: map[string]string{"topology.kubeclipper.io/region": "default"},
+		},
+		ClusterType: ClusterKubeadm,
+		Kubeadm: &Kubeadm{
+			Masters:           WorkerNodeList{{ID: "m1"}},
+			Workers:           WorkerNodeList{{ID: "w1"}},
+			KubernetesVersion: "v1.23.6",
+			KubeComponents: KubeComponents{
+				CNI: CNI{Type: "calico"},
+			},
+			Components: []Component{
+				{Name: "nfs", Version: "v1", Config: runtime.RawExtension{Raw: []byte(`{"a":1,"b":2}`)}},
+			},
+		},
+	}
+}
+
+func TestDiffCluster(t *testing.T) {
+	current := newDiffTestCluster()
+	current.Complete()
+
+	tests := []struct {
+		name    string
+		mutate  func(c *Cluster)
+		want    *ClusterDiff
+		changed bool
+	}{
+		{
+			name:   "unchanged",
+			mutate: func(c *Cluster) { c.Labels = nil },
+			want:   &ClusterDiff{Unchanged: true},
+		},
+		{
+			name: "component config with different key order",
+			mutate: func(c *Cluster) {
+				c.Kubeadm.Components[0].Config.Raw = []byte(`{"b": 2, "a": 1}`)
+			},
+			want: &ClusterDiff{Unchanged: true},
+		},
+		{
+			name: "component config changed in place",
+			mutate: func(c *Cluster) {
+				c.Kubeadm.Components[0].Config.Raw = []byte(`{"a":1,"b":3}`)
+			},
+			want: &ClusterDiff{
+				UpgradeComponents: []Component{
+					{Name: "nfs", Version: "v1", Config: runtime.RawExtension{Raw: []byte(`{"a":1,"b":3}`)}},
+				},
+			},
+		},
+		{
+			name: "another component of the same name",
+			mutate: func(c *Cluster) {
+				c.Kubeadm.Components = append(c.Kubeadm.Components,
+					Component{Name: "nfs", Version: "v1", Config: runtime.RawExtension{Raw: []byte(`{"a":2}`)}})
+			},
+			want: &ClusterDiff{
+				InstallComponents: []Component{
+					{Name: "nfs", Version: "v1", Config: runtime.RawExtension{Raw: []byte(`{"a":2}`)}},
+				},
+			},
+		},
+		{
+			name: "component upgraded and another of the same name added",
+			mutate: func(c *Cluster) {
+				c.Kubeadm.Components[0].Version = "v2"
+				c.Kubeadm.Components = append(c.Kubeadm.Components,
+					Component{Name: "nfs", Version: "v1", Config: runtime.RawExtension{Raw: []byte(`{"a":2}`)}})
+			},
+			want: &ClusterDiff{
+				InstallComponents: []Component{
+					{Name: "nfs", Version: "v1", Config: runtime.RawExtension{Raw: []byte(`{"a":2}`)}},
+				},
+				UpgradeComponents: []Component{
+					{Name: "nfs", Version: "v2", Config: runtime.RawExtension{Raw: []byte(`{"a":1,"b":2}`)}},
+				},
+			},
+		},
+		{
+			name: "component removed",
+			mutate: func(c *Cluster) {
+				c.Kubeadm.Components = nil
+			},
+			want: &ClusterDiff{
+				UninstallComponents: []Component{
+					{Name: "nfs", Version: "v1", Config: runtime.RawExtension{Raw: []byte(`{"a":1,"b":2}`)}},
+				},
+			},
+		},
+		{
+			name: "scale workers and add label",
+			mutate: func(c *Cluster) {
+				c.Labels = map[string]string{"foo": "bar"}
+				c.Kubeadm.Workers = WorkerNodeList{{ID: "w2"}}
+			},
+			want: &ClusterDiff{
+				Labels:        map[string]string{"foo": "bar"},
+				AddWorkers:    WorkerNodeList{{ID: "w2"}},
+				RemoveWorkers: WorkerNodeList{{ID: "w1"}},
+			},
+		},
+		{
+			name: "upgrade and forbidden fields",
+			mutate: func(c *Cluster) {
+				c.Kubeadm.KubernetesVersion = "v1.23.9"
+				c.Kubeadm.Masters = WorkerNodeList{{ID: "m2"}}
+				c.Kubeadm.Networking.PodSubnet = "10.0.0.0/16"
+			},
+			want: &ClusterDiff{
+				KubernetesVersion: "v1.23.9",
+				Forbidden:         []string{"kubeadm.masters", "kubeadm.networking"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			desired := newDiffTestCluster()
+			tt.mutate(desired)
+			if got := DiffCluster(current, desired); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffCluster() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterIsDeleteProtected(t *testing.T) {
+	protected, unprotected := true, false
+	tests := []struct {
+		name            string
+		protection      *bool
+		platformDefault bool
+		want            bool
+	}{
+		{name: "follow platform off", protection: nil, platformDefault: false, want: false},
+		{name: "follow platform on", protection: nil, platformDefault: true, want: true},
+		{name: "cluster on", protection: &protected, platformDefault: false, want: true},
+		{name: "cluster off overrides platform", protection: &unprotected, platformDefault: true, want: false},
+	}
+	for _, tt := range tests {
+		c := &Cluster{DeleteProtection: tt.protection}
+		if got := c.IsDeleteProtected(tt.platformDefault); got != tt.want {
+			t.Errorf("%s: IsDeleteProtected() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+type ClusterExportFormat string
+
+const (
+	// ClusterExportKubeadm exports the kubeadm configuration, the node inventory and the scripts to run by hand.
+	ClusterExportKubeadm ClusterExportFormat = "kubeadm"
+	// ClusterExportTerraform additionally wraps the scripts into a terraform configuration.
+	ClusterExportTerraform ClusterExportFormat = "terraform"
+)
+
+func (f ClusterExportFormat) Valid() bool {
+	return f == ClusterExportKubeadm || f == ClusterExportTerraform
+}
+
+// ClusterExport is a set of files to reproduce or keep maintaining a cluster without kubeclipper.
+type ClusterExport struct {
+	Format ClusterExportFormat `json:"format"`
+	Files  []ExportFile        `json:"files"`
+}
+
+type ExportFile struct {
+	// Path is relative to the export directory.
+	Path    string `json:"path"`
+	Mode    uint32 `json:"mode"`
+	Content string `json:"content"`
+}
@@ -0,0 +1,29 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+// ClusterImport is the request of adopting an existing kubeadm cluster. The topology is discovered with
+// the kubeconfig, every node of the cluster must already be registered as a kubeclipper node.
+type ClusterImport struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Kubeconfig has cluster-admin access to the cluster, it is only used during the import
+	// to create the service account kubeclipper manages the cluster with.
+	Kubeconfig string `json:"kubeconfig"`
+}
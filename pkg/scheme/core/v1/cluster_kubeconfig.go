@@ -0,0 +1,59 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// KubeconfigScope is the permission a kubeconfig issued for a managed cluster grants.
+type KubeconfigScope string
+
+const (
+	KubeconfigScopeAdmin KubeconfigScope = "admin"
+	KubeconfigScopeEdit  KubeconfigScope = "edit"
+	KubeconfigScopeView  KubeconfigScope = "view"
+)
+
+// KubeconfigServiceAccountPrefix prefixes the service accounts in kube-system the kubeconfig tokens are issued for.
+const KubeconfigServiceAccountPrefix = "kc-kubeconfig-"
+
+func (s KubeconfigScope) Valid() bool {
+	return s == KubeconfigScopeAdmin || s == KubeconfigScopeEdit || s == KubeconfigScopeView
+}
+
+// ClusterRole returns the kubernetes cluster role bound to the service account of the scope.
+func (s KubeconfigScope) ClusterRole() string {
+	if s == KubeconfigScopeAdmin {
+		return "cluster-admin"
+	}
+	return string(s)
+}
+
+// ServiceAccount returns the name of the service account whose tokens are handed out for the scope.
+func (s KubeconfigScope) ServiceAccount() string {
+	return KubeconfigServiceAccountPrefix + string(s)
+}
+
+// ClusterKubeconfig is a kubeconfig issued for a managed cluster. Its token is bound to a service
+// account of the scope, it expires at ExpirationTimestamp or when the cluster credentials are rotated.
+type ClusterKubeconfig struct {
+	Cluster             string          `json:"cluster"`
+	Scope               KubeconfigScope `json:"scope"`
+	ExpirationTimestamp metav1.Time     `json:"expirationTimestamp"`
+	Kubeconfig          string          `json:"kubeconfig"`
+}
@@ -36,6 +36,8 @@ type WorkerNode struct {
 	ID     string            `json:"id"`
 	Labels map[string]string `json:"labels,omitempty"`
 	Taints []Taint           `json:"taints,omitempty"`
+	// Pool is the name of the node pool the worker belongs to.
+	Pool string `json:"pool,omitempty" optional:"true"`
 }
 
 type WorkerNodeList []WorkerNode
@@ -97,7 +99,24 @@ type Cluster struct {
 	ClusterType ClusterType   `json:"type"`
 	Kubeadm     *Kubeadm      `json:"kubeadm,omitempty"`
 	Status      ClusterStatus `json:"status,omitempty" optional:"true"`
-	KubeConfig  []byte        `json:"kubeconfig,omitempty"`
+	// KubeConfig is the admin kubeconfig of the cluster, the cluster API never serves it,
+	// it is only issued by the adminkubeconfig route.
+	KubeConfig []byte `json:"kubeconfig,omitempty"`
+	// DeleteProtection rejects deletion of the cluster while it is true,
+	// the platform cluster policy applies when it is not set.
+	DeleteProtection *bool `json:"deleteProtection,omitempty"`
+	// NodeReconcileMode decides how labels and taints changed directly
+	// in kubernetes are handled, defaults to Ignore.
+	NodeReconcileMode NodeReconcileMode `json:"nodeReconcileMode,omitempty"`
+}
+
+// IsDeleteProtected reports whether the cluster can not be deleted,
+// platformDefault is used when the cluster does not set its own protection.
+func (c *Cluster) IsDeleteProtected(platformDefault bool) bool {
+	if c.DeleteProtection != nil {
+		return *c.DeleteProtection
+	}
+	return platformDefault
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -111,11 +130,31 @@ type ClusterList struct {
 	Items           []Cluster `json:"items"`
 }
 
+type NodeReconcileMode string
+
+const (
+	// NodeReconcileIgnore leaves out-of-band changes on nodes alone.
+	NodeReconcileIgnore NodeReconcileMode = "Ignore"
+	// NodeReconcileEnforce overwrites node labels and taints back to the declared state.
+	NodeReconcileEnforce NodeReconcileMode = "Enforce"
+	// NodeReconcileImport copies node labels and taints into the cluster spec.
+	NodeReconcileImport NodeReconcileMode = "Import"
+)
+
+func (m NodeReconcileMode) Valid() bool {
+	switch m {
+	case "", NodeReconcileIgnore, NodeReconcileEnforce, NodeReconcileImport:
+		return true
+	}
+	return false
+}
+
 type ClusterType string
 
 const (
 	ClusterKubeadm ClusterType = "kubeadm"
-	// TODO: other cluster type
+	// ClusterK3s runs k3s, the lightweight distribution for edge sites, on the nodes of the kubeadm spec.
+	ClusterK3s ClusterType = "k3s"
 )
 
 func (c ClusterType) String() string {
@@ -125,20 +164,28 @@ func (c ClusterType) String() string {
 func (c Cluster) Complete() {
 	switch c.ClusterType {
 	case ClusterKubeadm:
+		c.Kubeadm.Networking.Complete()
+		if len(c.Kubeadm.Networking.PodSubnets) > 0 {
+			c.Kubeadm.KubeComponents.CNI.SetPodCIDRs(c.Kubeadm.Networking)
+		}
 		if c.Kubeadm.Offline {
 			c.Kubeadm.KubeComponents.CNI.LocalRegistry = c.Kubeadm.LocalRegistry
 		}
 		matchCniVersion(c.Kubeadm.KubernetesVersion, &c.Kubeadm.KubeComponents.CNI)
+	case ClusterK3s:
+		c.Kubeadm.Networking.Complete()
+		// k3s runs its embedded flannel
+		c.Kubeadm.KubeComponents.CNI.Type = "flannel"
 	}
 }
 
 var k8sMatchCniVersion = map[string]map[string]string{
-	"118": {"calico": "v3.11.2"},
-	"119": {"calico": "v3.11.2"},
-	"120": {"calico": "v3.21.2"},
-	"121": {"calico": "v3.21.2"},
-	"122": {"calico": "v3.21.2"},
-	"123": {"calico": "v3.21.2"},
+	"118": {"calico": "v3.11.2", "flannel": "v0.20.2"},
+	"119": {"calico": "v3.11.2", "flannel": "v0.20.2"},
+	"120": {"calico": "v3.21.2", "flannel": "v0.20.2"},
+	"121": {"calico": "v3.21.2", "flannel": "v0.20.2"},
+	"122": {"calico": "v3.21.2", "flannel": "v0.20.2"},
+	"123": {"calico": "v3.21.2", "flannel": "v0.20.2"},
 }
 
 func matchCniVersion(k8sVersion string, cni *CNI) {
@@ -152,6 +199,8 @@ func matchCniVersion(k8sVersion string, cni *CNI) {
 	switch cni.Type {
 	case "calico":
 		cni.Calico.Version = k8sMatchCniVersion[k8sVersion][cni.Type]
+	case "flannel":
+		cni.Flannel.Version = k8sMatchCniVersion[k8sVersion][cni.Type]
 	}
 }
 
@@ -168,6 +217,21 @@ type Kubeadm struct {
 	Components        []Component      `json:"components" optional:"true"`
 	WorkerNodeVip     string           `json:"workerNodeVip" optional:"true"`
 	Offline           bool             `json:"offline" optional:"true"`
+	// NTPServers configures chrony on every node to sync time from the given servers.
+	NTPServers []string `json:"ntpServers,omitempty" optional:"true"`
+	// ConfigPatches are applied to the rendered kubeadm configuration on install and upgrade.
+	ConfigPatches KubeadmConfigPatches `json:"configPatches,omitempty" optional:"true"`
+	// ExternalEndpoint is the host[:port] of the load balancer or vip exposing the apiserver outside
+	// the cluster, its host is added to the certSANs and issued kubeconfigs connect to it.
+	ExternalEndpoint string `json:"externalEndpoint,omitempty" optional:"true"`
+	// ControlPlaneVIP is set up on the masters when the cluster is created.
+	ControlPlaneVIP *ControlPlaneVIP `json:"controlPlaneVIP,omitempty" optional:"true"`
+	// NodePools group the workers sharing labels, taints, kubelet arguments and container runtime.
+	NodePools []NodePool `json:"nodePools,omitempty" optional:"true"`
+	// Proxy is set up for the container runtime, the kubelet and the package downloads of every node.
+	Proxy *Proxy `json:"proxy,omitempty" optional:"true"`
+	// ToolImages run the kubectl and etcdctl commands of the cluster steps in containers.
+	ToolImages *ToolImages `json:"toolImages,omitempty" optional:"true"`
 }
 
 type ClusterStatusType string
@@ -212,6 +276,87 @@ type ClusterStatus struct {
 	// cluster component health status
 	ComponentConditions []ComponentConditions `json:"componentConditions,omitempty"`
 	Conditions          []ClusterCondition    `json:"conditions,omitempty"`
+	// Health is the result of the latest health probe of the running cluster.
+	Health []ClusterHealthCondition `json:"health,omitempty"`
+	// Certifications are the control plane certificates with the earliest expiration among the masters.
+	Certifications []Certification `json:"certifications,omitempty"`
+	// Workloads is the inventory of the namespaces and workloads of the running cluster,
+	// it is only collected when the workload inventory of kc-server is enabled.
+	Workloads *ClusterWorkloads `json:"workloads,omitempty"`
+	// ComponentHistory are the latest component installations, upgrades and removals, the oldest first.
+	ComponentHistory []ComponentRevision `json:"componentHistory,omitempty"`
+}
+
+// MaxComponentHistory is the number of component revisions kept in cluster status.
+const MaxComponentHistory = 20
+
+// ComponentRevision records a change of a component installed in the cluster.
+type ComponentRevision struct {
+	Name string `json:"name"`
+	// Action is the operation which changed the component, e.g. InstallComponents.
+	Action string `json:"action"`
+	// FromVersion is the version before the change, empty for an installation.
+	FromVersion string `json:"fromVersion,omitempty"`
+	// ToVersion is the version after the change, empty for a removal.
+	ToVersion string      `json:"toVersion,omitempty"`
+	Operation string      `json:"operation,omitempty"`
+	Time      metav1.Time `json:"time"`
+}
+
+// AddComponentRevision appends r to the component history, only the latest MaxComponentHistory are kept.
+func (s *ClusterStatus) AddComponentRevision(r ComponentRevision) {
+	s.ComponentHistory = append(s.ComponentHistory, r)
+	if n := len(s.ComponentHistory); n > MaxComponentHistory {
+		s.ComponentHistory = append([]ComponentRevision(nil), s.ComponentHistory[n-MaxComponentHistory:]...)
+	}
+}
+
+// ClusterWorkloads counts the namespaces and workloads of a cluster, so that an overview of the
+// clusters is served without calling their apiservers.
+type ClusterWorkloads struct {
+	Namespaces   int `json:"namespaces"`
+	Deployments  int `json:"deployments"`
+	StatefulSets int `json:"statefulSets"`
+	DaemonSets   int `json:"daemonSets"`
+	Pods         int `json:"pods"`
+	RunningPods  int `json:"runningPods"`
+	// Unhealthy are the first unhealthy workloads in the order of kind, namespace and name.
+	Unhealthy []UnhealthyWorkload `json:"unhealthy,omitempty"`
+	// UnhealthyTotal is the number of unhealthy workloads, including those left out of Unhealthy.
+	UnhealthyTotal int `json:"unhealthyTotal"`
+	// UpdateTime is when the inventory last changed, it is collected again every period.
+	UpdateTime metav1.Time `json:"updateTime"`
+}
+
+// UnhealthyWorkload is a workload with fewer ready replicas than desired.
+type UnhealthyWorkload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Ready     int32  `json:"ready"`
+	Desired   int32  `json:"desired"`
+}
+
+// Certification is a control plane certificate of the cluster.
+type Certification struct {
+	// Name is the path of the certificate relative to /etc/kubernetes/pki, or the kubeconfig embedding it.
+	Name string `json:"name"`
+	// Node is the master whose copy of the certificate expires first.
+	Node           string      `json:"node,omitempty"`
+	ExpirationTime metav1.Time `json:"expirationTime"`
+	// Expiring is set when the certificate expires within the warning threshold of the certificate monitor.
+	Expiring bool `json:"expiring,omitempty"`
+}
+
+// ExpiringCertifications returns the names of the certificates about to expire.
+func (s *ClusterStatus) ExpiringCertifications() []string {
+	var names []string
+	for _, c := range s.Certifications {
+		if c.Expiring {
+			names = append(names, c.Name)
+		}
+	}
+	return names
 }
 
 type ComponentStatus string
@@ -236,6 +381,57 @@ type ClusterCondition struct {
 	OperationStatus OperationStatusType `json:"operationStatus,omitempty"`
 }
 
+type ClusterHealthConditionType string
+
+const (
+	ClusterAPIServerReachable ClusterHealthConditionType = "APIServerReachable"
+	ClusterNodesReady         ClusterHealthConditionType = "NodesReady"
+	ClusterEtcdHealthy        ClusterHealthConditionType = "EtcdHealthy"
+	ClusterAddonsHealthy      ClusterHealthConditionType = "AddonsHealthy"
+)
+
+// ClusterHealthCondition contains the probed health of one aspect of a cluster.
+type ClusterHealthCondition struct {
+	Type ClusterHealthConditionType `json:"type"`
+	// Status of the condition, one of True, False, Unknown.
+	Status ConditionStatus `json:"status"`
+	// Last time the condition transit from one status to another.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// HealthCondition returns the health condition of the given type, or nil if it has not been probed.
+func (s *ClusterStatus) HealthCondition(t ClusterHealthConditionType) *ClusterHealthCondition {
+	for i := range s.Health {
+		if s.Health[i].Type == t {
+			return &s.Health[i]
+		}
+	}
+	return nil
+}
+
+// HealthSummary returns Healthy when every health condition is True, Unknown when the
+// cluster has not been probed, and otherwise the types of the failing conditions.
+func (s *ClusterStatus) HealthSummary() string {
+	if len(s.Health) == 0 {
+		return string(ConditionUnknown)
+	}
+	var failed []string
+	for _, c := range s.Health {
+		if c.Status != ConditionTrue {
+			failed = append(failed, string(c.Type))
+		}
+	}
+	if len(failed) == 0 {
+		return "Healthy"
+	}
+	return "Unhealthy(" + strings.Join(failed, ",") + ")"
+}
+
 type Component struct {
 	Name    string               `json:"name"`
 	Version string               `json:"version"`
@@ -246,19 +442,29 @@ type Networking struct {
 	ServiceSubnet string `json:"serviceSubnet"`
 	PodSubnet     string `json:"podSubnet"`
 	DNSDomain     string `json:"dnsDomain"`
+	// PodSubnets are the pod subnets of a dual-stack or ipv6-only cluster, one per ip family and
+	// the primary one first. They take the place of podSubnet.
+	PodSubnets []string `json:"podSubnets,omitempty" optional:"true"`
+	// ServiceSubnets are the service subnets, of the ip families of the pod subnets in the same order.
+	ServiceSubnets []string `json:"serviceSubnets,omitempty" optional:"true"`
 }
 
 type Kubelet struct {
 	RootDir string `json:"rootDir" yaml:"rootDir"`
+	// Swap lets the nodes keep swap on kubernetes 1.28 and later.
+	Swap Swap `json:"swap,omitempty" yaml:"swap,omitempty" optional:"true"`
 }
 
 type CNI struct {
-	LocalRegistry string `json:"localRegistry" optional:"true"`
-	Type          string `json:"type" enum:"calico"`
-	PodIPv4CIDR   string `json:"podIPv4CIDR"`
-	PodIPv6CIDR   string `json:"podIPv6CIDR"`
-	MTU           int    `json:"mtu"`
-	Calico        Calico `json:"calico" optional:"true"`
+	LocalRegistry string  `json:"localRegistry" optional:"true"`
+	Type          string  `json:"type" enum:"calico|flannel"`
+	PodIPv4CIDR   string  `json:"podIPv4CIDR"`
+	PodIPv6CIDR   string  `json:"podIPv6CIDR"`
+	MTU           int     `json:"mtu"`
+	Calico        Calico  `json:"calico" optional:"true"`
+	Flannel       Flannel `json:"flannel" optional:"true"`
+	// NodeInterface overrides the interface auto detection of the cni.
+	NodeInterface *NodeInterface `json:"nodeInterface,omitempty" optional:"true"`
 }
 
 type Calico struct {
@@ -270,6 +476,12 @@ type Calico struct {
 	Version           string `json:"version" enum:"v3.11.2"`
 }
 
+type Flannel struct {
+	// Backend is the type of the flannel backend, vxlan by default.
+	Backend string `json:"backend,omitempty" enum:"vxlan|host-gw" optional:"true"`
+	Version string `json:"version" enum:"v0.20.2"`
+}
+
 type Etcd struct {
 	DataDir string `json:"dataDir,omitempty" optional:"true"`
 }
@@ -312,4 +524,7 @@ type Containerd struct {
 	Version          string   `json:"version,omitempty" enum:"1.4.4"`
 	DataRootDir      string   `json:"rootDir,omitempty"`
 	InsecureRegistry []string `json:"insecureRegistry,omitempty"`
+	// Registries configures the mirrors, credentials and certificate authorities of registry hosts,
+	// it requires containerd 1.5 or later.
+	Registries []ContainerdRegistry `json:"registries,omitempty" optional:"true"`
 }
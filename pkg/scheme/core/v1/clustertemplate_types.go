@@ -0,0 +1,117 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=false
+
+// ClusterTemplate is a reusable cluster blueprint. Everything except the
+// node list is defined by the template, clusters are provisioned from it
+// with a small set of overrides.
+type ClusterTemplate struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string      `json:"description,omitempty" optional:"true"`
+	ClusterType ClusterType `json:"type"`
+	// Masters and workers of the blueprint are ignored, they are always
+	// taken from the overrides.
+	Kubeadm *Kubeadm `json:"kubeadm,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// ClusterTemplateList contains a list of ClusterTemplate
+
+type ClusterTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterTemplate `json:"items"`
+}
+
+// ClusterTemplateOverrides are the per-cluster values applied on top of a template.
+type ClusterTemplateOverrides struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty" optional:"true"`
+	Labels      map[string]string `json:"labels,omitempty" optional:"true"`
+	Annotations map[string]string `json:"annotations,omitempty" optional:"true"`
+	Masters     WorkerNodeList    `json:"masters"`
+	Workers     WorkerNodeList    `json:"workers,omitempty" optional:"true"`
+}
+
+// Render builds a new cluster from the template and the given overrides.
+// The template itself is left untouched.
+func (t *ClusterTemplate) Render(overrides *ClusterTemplateOverrides) (*Cluster, error) {
+	if overrides.Name == "" {
+		return nil, fmt.Errorf("cluster name must be specified")
+	}
+	if len(overrides.Masters) == 0 {
+		return nil, fmt.Errorf("at least one master node must be specified")
+	}
+	if t.Kubeadm == nil {
+		return nil, fmt.Errorf("cluster template %s has no kubeadm configuration", t.Name)
+	}
+
+	c := &Cluster{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Cluster",
+			APIVersion: SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        overrides.Name,
+			Labels:      map[string]string{},
+			Annotations: map[string]string{},
+		},
+		ClusterType: t.ClusterType,
+		Kubeadm:     t.Kubeadm.DeepCopy(),
+	}
+	for k, v := range t.Labels {
+		c.Labels[k] = v
+	}
+	for k, v := range overrides.Labels {
+		c.Labels[k] = v
+	}
+	for k, v := range overrides.Annotations {
+		c.Annotations[k] = v
+	}
+	c.Labels[common.LabelClusterTemplate] = t.Name
+
+	c.Kubeadm.Masters = overrides.Masters.DeepCopy()
+	c.Kubeadm.Workers = overrides.Workers.DeepCopy()
+	if c.Kubeadm.Workers == nil {
+		c.Kubeadm.Workers = WorkerNodeList{}
+	}
+	if overrides.Description != "" {
+		c.Kubeadm.Description = overrides.Description
+	}
+	return c, nil
+}
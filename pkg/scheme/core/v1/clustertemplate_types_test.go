@@ -0,0 +1,123 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+func newTestClusterTemplate() *ClusterTemplate {
+	return &ClusterTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "small",
+			Labels: map[string]string{common.LabelTopologyRegion: "default"},
+		},
+		ClusterType: ClusterKubeadm,
+		Kubeadm: &Kubeadm{
+			Masters:           WorkerNodeList{{ID: "ignored"}},
+			KubernetesVersion: "v1.23.6",
+			ContainerRuntime: ContainerRuntime{
+				Type:       CRIContainerd,
+				Containerd: Containerd{InsecureRegistry: []string{"mirror.local:5000"}},
+			},
+			KubeComponents: KubeComponents{
+				CNI: CNI{Type: "calico"},
+			},
+		},
+	}
+}
+
+func TestClusterTemplateRender(t *testing.T) {
+	tmpl := newTestClusterTemplate()
+	overrides := &ClusterTemplateOverrides{
+		Name:    "demo",
+		Labels:  map[string]string{"env": "dev"},
+		Masters: WorkerNodeList{{ID: "m1"}},
+		Workers: WorkerNodeList{{ID: "w1"}, {ID: "w2"}},
+	}
+	c, err := tmpl.Render(overrides)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if c.Name != "demo" {
+		t.Errorf("Render() name = %s, want demo", c.Name)
+	}
+	if got := c.Kubeadm.Masters.GetNodeIDs(); len(got) != 1 || got[0] != "m1" {
+		t.Errorf("Render() masters = %v, want [m1]", got)
+	}
+	if got := c.Kubeadm.Workers.GetNodeIDs(); len(got) != 2 {
+		t.Errorf("Render() workers = %v, want [w1 w2]", got)
+	}
+	if c.Kubeadm.KubernetesVersion != "v1.23.6" {
+		t.Errorf("Render() kubernetesVersion = %s, want v1.23.6", c.Kubeadm.KubernetesVersion)
+	}
+	for k, v := range map[string]string{
+		common.LabelTopologyRegion:  "default",
+		"env":                       "dev",
+		common.LabelClusterTemplate: "small",
+	} {
+		if c.Labels[k] != v {
+			t.Errorf("Render() label %s = %q, want %q", k, c.Labels[k], v)
+		}
+	}
+
+	// rendering must not leak back into the template
+	c.Kubeadm.ContainerRuntime.Containerd.InsecureRegistry[0] = "changed"
+	if tmpl.Kubeadm.ContainerRuntime.Containerd.InsecureRegistry[0] != "mirror.local:5000" {
+		t.Errorf("Render() modified the template")
+	}
+	if tmpl.Kubeadm.Masters[0].ID != "ignored" {
+		t.Errorf("Render() modified the template masters")
+	}
+}
+
+func TestClusterTemplateRenderInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  *ClusterTemplate
+		overrides *ClusterTemplateOverrides
+	}{
+		{
+			name:      "missing name",
+			template:  newTestClusterTemplate(),
+			overrides: &ClusterTemplateOverrides{Masters: WorkerNodeList{{ID: "m1"}}},
+		},
+		{
+			name:      "missing masters",
+			template:  newTestClusterTemplate(),
+			overrides: &ClusterTemplateOverrides{Name: "demo"},
+		},
+		{
+			name:      "missing kubeadm",
+			template:  &ClusterTemplate{ObjectMeta: metav1.ObjectMeta{Name: "empty"}},
+			overrides: &ClusterTemplateOverrides{Name: "demo", Masters: WorkerNodeList{{ID: "m1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.template.Render(tt.overrides); err == nil {
+				t.Errorf("Render() expected error")
+			}
+		})
+	}
+}
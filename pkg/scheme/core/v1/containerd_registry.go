@@ -0,0 +1,100 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// containerdHostsVersion is the first containerd reading registry hosts from config_path.
+var containerdHostsVersion = version.MustParseGeneric("1.5.0")
+
+// ContainerdRegistry is how containerd of the cluster nodes pulls images of a registry host.
+type ContainerdRegistry struct {
+	// Host is the registry host[:port] the images are named after, e.g. docker.io.
+	Host string `json:"host"`
+	// Mirrors are the endpoints tried in order before the host, e.g. https://mirror.example.com.
+	// Endpoints without scheme use https.
+	Mirrors []string `json:"mirrors,omitempty" optional:"true"`
+	// Insecure pulls from the host over plain http, and skips verifying the certificates of the mirrors.
+	Insecure bool `json:"insecure,omitempty" optional:"true"`
+	// CA is the pem encoded certificate authority of the host and its mirrors.
+	CA string `json:"ca,omitempty" optional:"true"`
+	// Username and Password are the basic auth credentials of the host and its mirrors.
+	Username string `json:"username,omitempty" optional:"true"`
+	Password string `json:"password,omitempty" optional:"true"`
+}
+
+// Validate checks the host, the mirrors and the certificate authority of the registry.
+func (r *ContainerdRegistry) Validate() error {
+	if r.Host == "" || strings.Contains(r.Host, "/") {
+		return fmt.Errorf("invalid registry host %q, it must be host[:port]", r.Host)
+	}
+	for _, m := range r.Mirrors {
+		u, err := url.Parse(MirrorEndpoint(m))
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid mirror %q of registry %s", m, r.Host)
+		}
+	}
+	if r.CA != "" {
+		if block, _ := pem.Decode([]byte(r.CA)); block == nil || block.Type != "CERTIFICATE" {
+			return fmt.Errorf("ca of registry %s is not a pem encoded certificate", r.Host)
+		}
+	}
+	if (r.Username == "") != (r.Password == "") {
+		return fmt.Errorf("username and password of registry %s must be set together", r.Host)
+	}
+	return nil
+}
+
+// MirrorEndpoint returns the mirror with https scheme when it has none.
+func MirrorEndpoint(mirror string) string {
+	if strings.Contains(mirror, "://") {
+		return mirror
+	}
+	return "https://" + mirror
+}
+
+// ValidateRegistries checks the registries can be configured on the containerd version,
+// and every host is configured once.
+func (c *Containerd) ValidateRegistries() error {
+	if len(c.Registries) == 0 {
+		return nil
+	}
+	if v, err := version.ParseGeneric(c.Version); err == nil && v.LessThan(containerdHostsVersion) {
+		return fmt.Errorf("registries require containerd %s or later, the cluster runs %s", containerdHostsVersion, c.Version)
+	}
+	hosts := sets.NewString()
+	for i := range c.Registries {
+		if err := c.Registries[i].Validate(); err != nil {
+			return err
+		}
+		if hosts.Has(c.Registries[i].Host) {
+			return fmt.Errorf("registry %s is configured more than once", c.Registries[i].Host)
+		}
+		hosts.Insert(c.Registries[i].Host)
+	}
+	return nil
+}
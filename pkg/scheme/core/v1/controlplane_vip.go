@@ -0,0 +1,91 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+type ControlPlaneVIPMode string
+
+const (
+	// ControlPlaneVIPKubeVIP announces the vip with kube-vip static pods, clients reach the apiserver
+	// of the leading master on port 6443.
+	ControlPlaneVIPKubeVIP ControlPlaneVIPMode = "kube-vip"
+	// ControlPlaneVIPKeepalived moves the vip with keepalived, haproxy balances port 8443 of the vip
+	// across the apiservers of every master.
+	ControlPlaneVIPKeepalived ControlPlaneVIPMode = "keepalived"
+
+	// haproxyPort is the port haproxy listens on, the apiserver holds 6443 of every address.
+	haproxyPort = 8443
+	// defaultVirtualRouterID is the vrrp router id of keepalived.
+	defaultVirtualRouterID = 51
+)
+
+// ControlPlaneVIP is a virtual ip floating between the masters, it gives the cluster a stable apiserver
+// endpoint without an external load balancer. Worker nodes reach the apiserver through it.
+type ControlPlaneVIP struct {
+	Mode ControlPlaneVIPMode `json:"mode" enum:"kube-vip|keepalived"`
+	// Address is a free ip of the subnet of the masters.
+	Address string `json:"address"`
+	// Interface the vip is bound to, the interface of the default route by default.
+	Interface string `json:"interface,omitempty" optional:"true"`
+	// VirtualRouterID is the vrrp router id of keepalived, it must be unique in the subnet. Defaults to 51.
+	VirtualRouterID int `json:"virtualRouterID,omitempty" optional:"true"`
+}
+
+// Validate checks the mode, the address and the router id of the vip.
+func (v *ControlPlaneVIP) Validate() error {
+	if v == nil {
+		return nil
+	}
+	if v.Mode != ControlPlaneVIPKubeVIP && v.Mode != ControlPlaneVIPKeepalived {
+		return fmt.Errorf("unsupported control plane vip mode %q, it must be kube-vip or keepalived", v.Mode)
+	}
+	if ip := net.ParseIP(v.Address); ip == nil || ip.To4() == nil {
+		return fmt.Errorf("invalid control plane vip %q, it must be an ipv4 address", v.Address)
+	}
+	if v.VirtualRouterID < 0 || v.VirtualRouterID > 255 {
+		return fmt.Errorf("invalid virtual router id %d, it must be between 1 and 255", v.VirtualRouterID)
+	}
+	return nil
+}
+
+// Port returns the port clients outside the cluster connect to on the vip.
+func (v *ControlPlaneVIP) Port() int {
+	if v.Mode == ControlPlaneVIPKeepalived {
+		return haproxyPort
+	}
+	return 6443
+}
+
+// RouterID returns the vrrp router id of keepalived.
+func (v *ControlPlaneVIP) RouterID() int {
+	if v.VirtualRouterID == 0 {
+		return defaultVirtualRouterID
+	}
+	return v.VirtualRouterID
+}
+
+// Endpoint returns the address:port clients outside the cluster connect to.
+func (v *ControlPlaneVIP) Endpoint() string {
+	return net.JoinHostPort(v.Address, strconv.Itoa(v.Port()))
+}
@@ -25,16 +25,17 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strings"
 	"time"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
 	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
 	"go.uber.org/zap"
@@ -46,6 +47,8 @@ type ContainerdRunnable struct {
 	LocalRegistry string `json:"localRegistry"`
 	KubeVersion   string `json:"kubeVersion"`
 	PauseVersion  string `json:"pauseVersion"`
+	// Registries are rendered to the hosts.toml files of containerdCertsDir.
+	Registries []v1.ContainerdRegistry `json:"registries,omitempty"`
 
 	installSteps   []v1.Step
 	uninstallSteps []v1.Step
@@ -59,53 +62,53 @@ func (runnable *ContainerdRunnable) InitStep(ctx context.Context, containerd *v1
 	runnable.DataRootDir = strutil.StringDefaultIfEmpty(containerdDefaultConfigDir, containerd.DataRootDir)
 	runnable.LocalRegistry = metadata.LocalRegistry
 	runnable.InsecureRegistry = containerd.InsecureRegistry
+	runnable.Registries = containerd.Registries
 	runnable.PauseVersion = runnable.matchPauseVersion(metadata.KubeVersion)
-	runtimeBytes, err := json.Marshal(runnable)
-	if err != nil {
-		return err
+	if len(runnable.installSteps) != 0 || len(runnable.uninstallSteps) != 0 {
+		return nil
 	}
-
-	//nodes := utils.UnwrapNodeList(metadata.GetAllNodes())
-	if len(runnable.installSteps) == 0 {
-		runnable.installSteps = []v1.Step{
-			{
-				ID:         strutil.GetUUID(),
-				Name:       "installRuntime",
-				Timeout:    metav1.Duration{Duration: 10 * time.Minute},
-				ErrIgnore:  false,
-				RetryTimes: 1,
-				Nodes:      nodes,
-				Action:     v1.ActionInstall,
-				Commands: []v1.Command{
-					{
-						Type:          v1.CommandCustom,
-						Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, criContainerd, criVersion, component.TypeStep),
-						CustomCommand: runtimeBytes,
-					},
+	// every group of nodes installs the runtime bundle of its own architecture
+	archs, groups := utils.GroupNodesByArch(nodes)
+	for _, arch := range archs {
+		runnable.Arch = arch
+		runtimeBytes, err := json.Marshal(runnable)
+		if err != nil {
+			return err
+		}
+		runnable.installSteps = append(runnable.installSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "installRuntime",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      groups[arch],
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, criContainerd, criVersion, component.TypeStep),
+					CustomCommand: runtimeBytes,
 				},
 			},
-		}
-	}
-	if len(runnable.uninstallSteps) == 0 {
-		runnable.uninstallSteps = []v1.Step{
-			{
-				ID:         strutil.GetUUID(),
-				Name:       "uninstallRuntime",
-				Timeout:    metav1.Duration{Duration: 10 * time.Minute},
-				ErrIgnore:  false,
-				RetryTimes: 1,
-				Nodes:      nodes,
-				Action:     v1.ActionUninstall,
-				Commands: []v1.Command{
-					{
-						Type:          v1.CommandCustom,
-						Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, criContainerd, criVersion, component.TypeStep),
-						CustomCommand: runtimeBytes,
-					},
+		})
+		runnable.uninstallSteps = append(runnable.uninstallSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "uninstallRuntime",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      groups[arch],
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, criContainerd, criVersion, component.TypeStep),
+					CustomCommand: runtimeBytes,
 				},
 			},
-		}
+		})
 	}
+	runnable.Arch = ""
 
 	return nil
 }
@@ -123,8 +126,9 @@ func (runnable *ContainerdRunnable) GetActionSteps(action v1.StepAction) []v1.St
 	return nil
 }
 
-func (runnable *ContainerdRunnable) setParams() {
-	runnable.Arch = component.OSArchAMD64
+func (runnable *ContainerdRunnable) setParams() (err error) {
+	runnable.Arch, err = downloader.ResolveArch(runnable.Arch)
+	return err
 }
 
 func (runnable *ContainerdRunnable) NewInstance() component.ObjectMeta {
@@ -132,8 +136,10 @@ func (runnable *ContainerdRunnable) NewInstance() component.ObjectMeta {
 }
 
 func (runnable ContainerdRunnable) Install(ctx context.Context, opts component.Options) ([]byte, error) {
-	runnable.setParams()
-	instance, err := downloader.NewInstance(ctx, criContainerd, runnable.Version, runtime.GOARCH, !runnable.Offline, opts.DryRun)
+	if err := runnable.setParams(); err != nil {
+		return nil, err
+	}
+	instance, err := downloader.NewInstance(downloader.WithProxy(ctx, runnable.Proxy), criContainerd, runnable.Version, runnable.Arch, !runnable.Offline, opts.DryRun)
 	if err != nil {
 		return nil, err
 	}
@@ -144,6 +150,9 @@ func (runnable ContainerdRunnable) Install(ctx context.Context, opts component.O
 	if err = runnable.setupContainerdConfig(ctx, opts.DryRun); err != nil {
 		return nil, err
 	}
+	if err = runnable.setupRegistryHosts(ctx, opts.DryRun); err != nil {
+		return nil, err
+	}
 	// launch and enable containerd service
 	if err = runnable.enableContainerdService(ctx, opts.DryRun); err != nil {
 		return nil, err
@@ -158,12 +167,14 @@ func (runnable ContainerdRunnable) Install(ctx context.Context, opts component.O
 }
 
 func (runnable ContainerdRunnable) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
-	runnable.setParams()
+	if err := runnable.setParams(); err != nil {
+		return nil, err
+	}
 	if err := runnable.disableContainerdService(ctx, opts.DryRun); err != nil {
 		return nil, err
 	}
 	// remove related binary configuration files
-	instance, err := downloader.NewInstance(ctx, criContainerd, runnable.Version, runtime.GOARCH, !runnable.Offline, opts.DryRun)
+	instance, err := downloader.NewInstance(ctx, criContainerd, runnable.Version, runnable.Arch, !runnable.Offline, opts.DryRun)
 	if err != nil {
 		return nil, err
 	}
@@ -218,16 +229,16 @@ func (runnable *ContainerdRunnable) setupContainerdConfig(ctx context.Context, d
 }
 
 func (runnable *ContainerdRunnable) enableContainerdService(ctx context.Context, dryRun bool) error {
-	_, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "daemon-reload")
+	_, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.DaemonReload())
 	if err != nil {
 		return err
 	}
-	_, err = cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "enable", "containerd")
+	_, err = cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Enable("containerd", false))
 	if err != nil {
 		return err
 	}
 	// restart containerd to active config, if it is already running
-	_, err = cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "restart", "containerd")
+	_, err = cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Restart("containerd"))
 	if err != nil {
 		return err
 	}
@@ -237,10 +248,10 @@ func (runnable *ContainerdRunnable) enableContainerdService(ctx context.Context,
 
 func (runnable *ContainerdRunnable) disableContainerdService(ctx context.Context, dryRun bool) error {
 	// the following command execution error is ignored
-	if _, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "stop", "containerd"); err != nil {
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Stop("containerd")); err != nil {
 		logger.Warn("stop systemd containerd service failed", zap.Error(err))
 	}
-	if _, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "disable", "containerd"); err != nil {
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Disable("containerd")); err != nil {
 		logger.Warn("disable systemd containerd service failed", zap.Error(err))
 	}
 	return nil
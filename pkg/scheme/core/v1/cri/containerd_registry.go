@@ -0,0 +1,229 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cri
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+// registryHosts is the template data of a hosts.toml.
+type registryHosts struct {
+	Server     string
+	Mirrors    []string
+	SkipVerify bool
+	// CA is the path of the certificate authority file.
+	CA string
+	// Auth is the base64 encoded basic auth credentials.
+	Auth string
+}
+
+// hostsRegistries returns the registries rendered to hosts.toml files. The insecure registries
+// are added as plain http hosts, since containerd ignores the mirrors of config.toml once
+// config_path is set.
+func (runnable *ContainerdRunnable) hostsRegistries() []v1.ContainerdRegistry {
+	if len(runnable.Registries) == 0 {
+		return nil
+	}
+	registries := append([]v1.ContainerdRegistry{}, runnable.Registries...)
+	for _, addr := range runnable.InsecureRegistry {
+		configured := false
+		for _, r := range runnable.Registries {
+			configured = configured || r.Host == addr
+		}
+		if !configured {
+			registries = append(registries, v1.ContainerdRegistry{Host: addr, Insecure: true})
+		}
+	}
+	return registries
+}
+
+func newRegistryHosts(r v1.ContainerdRegistry) registryHosts {
+	h := registryHosts{SkipVerify: r.Insecure}
+	scheme := "https"
+	if r.Insecure {
+		scheme = "http"
+	}
+	if r.Host == "docker.io" {
+		h.Server = scheme + "://registry-1.docker.io"
+	} else {
+		h.Server = scheme + "://" + r.Host
+	}
+	for _, m := range r.Mirrors {
+		h.Mirrors = append(h.Mirrors, v1.MirrorEndpoint(m))
+	}
+	if r.CA != "" {
+		h.CA = filepath.Join(containerdCertsDir, r.Host, "ca.crt")
+	}
+	if r.Username != "" {
+		h.Auth = base64.StdEncoding.EncodeToString([]byte(r.Username + ":" + r.Password))
+	}
+	return h
+}
+
+// setupRegistryHosts rewrites the hosts directories of containerdCertsDir, the directories
+// of the registries no longer configured are removed.
+func (runnable *ContainerdRunnable) setupRegistryHosts(ctx context.Context, dryRun bool) error {
+	registries := runnable.hostsRegistries()
+	if len(registries) == 0 {
+		return nil
+	}
+	if !dryRun {
+		if err := os.RemoveAll(containerdCertsDir); err != nil {
+			return err
+		}
+	}
+	for _, r := range registries {
+		dir := filepath.Join(containerdCertsDir, r.Host)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		hosts := newRegistryHosts(r)
+		err := fileutil.WriteFileWithContext(ctx, filepath.Join(dir, "hosts.toml"), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600,
+			func(w io.Writer) error {
+				_, err := tmplutil.New().RenderTo(w, hostsTomlTemplate, hosts)
+				return err
+			}, dryRun)
+		if err != nil {
+			return err
+		}
+		if r.CA == "" {
+			continue
+		}
+		err = fileutil.WriteFileWithContext(ctx, hosts.CA, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
+			func(w io.Writer) error {
+				_, err := io.WriteString(w, r.CA)
+				return err
+			}, dryRun)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ContainerdRegistryRunnable applies the registries of the cluster to the containerd of a node,
+// and restarts containerd.
+type ContainerdRegistryRunnable struct {
+	ContainerdRunnable
+}
+
+// InitStep creates a step for every node, so that containerd restarts on one node at a time.
+func (runnable *ContainerdRegistryRunnable) InitStep(ctx context.Context, containerd *v1.Containerd, nodes []v1.StepNode) error {
+	metadata := component.GetExtraMetadata(ctx)
+	runnable.Version = containerd.Version
+	runnable.DataRootDir = strutil.StringDefaultIfEmpty(containerdDefaultConfigDir, containerd.DataRootDir)
+	runnable.LocalRegistry = metadata.LocalRegistry
+	runnable.InsecureRegistry = containerd.InsecureRegistry
+	runnable.Registries = containerd.Registries
+	runnable.PauseVersion = runnable.matchPauseVersion(metadata.KubeVersion)
+	if len(runnable.installSteps) != 0 {
+		return nil
+	}
+	data, err := json.Marshal(runnable)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		runnable.installSteps = append(runnable.installSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "configureRegistries",
+			Timeout:    metav1.Duration{Duration: 3 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      []v1.StepNode{node},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, containerdRegistry, criVersion, component.TypeStep),
+					CustomCommand: data,
+				},
+			},
+		})
+	}
+	return nil
+}
+
+func (runnable *ContainerdRegistryRunnable) GetActionSteps(action v1.StepAction) []v1.Step {
+	if action == v1.ActionInstall {
+		return runnable.installSteps
+	}
+	return nil
+}
+
+func (runnable *ContainerdRegistryRunnable) NewInstance() component.ObjectMeta {
+	return &ContainerdRegistryRunnable{}
+}
+
+func (runnable ContainerdRegistryRunnable) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if err := runnable.setParams(); err != nil {
+		return nil, err
+	}
+	if err := runnable.setupContainerdConfig(ctx, opts.DryRun); err != nil {
+		return nil, err
+	}
+	if err := runnable.setupRegistryHosts(ctx, opts.DryRun); err != nil {
+		return nil, err
+	}
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Services.Restart("containerd")); err != nil {
+		return nil, err
+	}
+	// wait for the cri of containerd to serve again before moving on to the next node
+	err := utils.RetryFunc(ctx, opts, 2*time.Second, "containerdReady", func(ctx context.Context, opts component.Options) error {
+		_, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "crictl", "info")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	logger.Debugf("configure containerd registries %s successfully", strings.Join(registryHostNames(runnable.Registries), ","))
+	return nil, nil
+}
+
+func (runnable ContainerdRegistryRunnable) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, fmt.Errorf("no support uninstall containerd registries")
+}
+
+func registryHostNames(registries []v1.ContainerdRegistry) []string {
+	names := make([]string, 0, len(registries))
+	for _, r := range registries {
+		names = append(names, r.Host)
+	}
+	return names
+}
@@ -0,0 +1,127 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cri
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+const testCA = `-----BEGIN CERTIFICATE-----
+MIIBhTCCASugAwIBAgIQIRi6zePL6mKjOipn+dNuaTAKBggqhkjOPQQDAjASMRAw
+DgYDVQQKEwdBY21lIENvMB4XDTE3MTAyMDE5NDMwNloXDTE4MTAyMDE5NDMwNlow
+EjEQMA4GA1UEChMHQWNtZSBDbzBZMBMGByqGSM49AgEGCCqGSM49AwEHA0IABD0d
+7VNhbWvZLWPuj/RtHFjvtJBEwOkhbN/BnnE8rnZR8+sbwnc/KhCk3FhnpHZnQz7B
+5aETbbIgmuvewdjvSBSjYzBhMA4GA1UdDwEB/wQEAwICpDATBgNVHSUEDDAKBggr
+BgEFBQcDATAPBgNVHRMBAf8EBTADAQH/MCkGA1UdEQQiMCCCDmxvY2FsaG9zdDo1
+NDUzgg4xMjcuMC4wLjE6NTQ1MzAKBggqhkjOPQQDAgNIADBFAiEA2zpJEPQyz6/l
+Wf86aX6PepsntZv2GYlA5UpabfT2EZICICpJ5h/iI+i341gBmLiAFQOyTDT+/wQc
+6MF9+Yw1Yy0t
+-----END CERTIFICATE-----
+`
+
+func TestRenderRegistryHosts(t *testing.T) {
+	r := v1.ContainerdRegistry{
+		Host:     "docker.io",
+		Mirrors:  []string{"mirror.example.com", "http://10.0.0.10:5000"},
+		CA:       testCA,
+		Username: "admin",
+		Password: "secret",
+	}
+	c := &v1.Containerd{Version: "1.6.4", Registries: []v1.ContainerdRegistry{r}}
+	if err := c.ValidateRegistries(); err != nil {
+		t.Fatal(err)
+	}
+	w := &bytes.Buffer{}
+	if _, err := tmplutil.New().RenderTo(w, hostsTomlTemplate, newRegistryHosts(r)); err != nil {
+		t.Fatal(err)
+	}
+	want := `server = "https://registry-1.docker.io"
+ca = "/etc/containerd/certs.d/docker.io/ca.crt"
+
+[header]
+  Authorization = ["Basic YWRtaW46c2VjcmV0"]
+
+[host."https://mirror.example.com"]
+  capabilities = ["pull", "resolve"]
+  ca = "/etc/containerd/certs.d/docker.io/ca.crt"
+  [host."https://mirror.example.com".header]
+    Authorization = ["Basic YWRtaW46c2VjcmV0"]
+
+[host."http://10.0.0.10:5000"]
+  capabilities = ["pull", "resolve"]
+  ca = "/etc/containerd/certs.d/docker.io/ca.crt"
+  [host."http://10.0.0.10:5000".header]
+    Authorization = ["Basic YWRtaW46c2VjcmV0"]
+`
+	if w.String() != want {
+		t.Errorf("hosts.toml is\n%s\nwant\n%s", w.String(), want)
+	}
+}
+
+func TestContainerdConfigPath(t *testing.T) {
+	runnable := &ContainerdRunnable{
+		Base:         Base{Version: "1.6.4", InsecureRegistry: []string{"10.0.0.10:5000"}, Arch: "amd64"},
+		PauseVersion: "3.6",
+	}
+	w := &bytes.Buffer{}
+	if err := runnable.renderTo(w); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(w.String(), "config_path") || !strings.Contains(w.String(), `endpoint = ["http://10.0.0.10:5000"]`) {
+		t.Errorf("config.toml without registries should keep the mirrors:\n%s", w.String())
+	}
+
+	runnable.Registries = []v1.ContainerdRegistry{{Host: "docker.io", Mirrors: []string{"mirror.example.com"}}}
+	w.Reset()
+	if err := runnable.renderTo(w); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(w.String(), `config_path = "/etc/containerd/certs.d"`) || strings.Contains(w.String(), "registry.mirrors") {
+		t.Errorf("config.toml with registries should only set config_path:\n%s", w.String())
+	}
+	hosts := runnable.hostsRegistries()
+	if len(hosts) != 2 || hosts[1].Host != "10.0.0.10:5000" || !hosts[1].Insecure {
+		t.Errorf("insecure registry is not added to the hosts: %+v", hosts)
+	}
+}
+
+func TestValidateRegistries(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		c     v1.Containerd
+		valid bool
+	}{
+		{"old containerd", v1.Containerd{Version: "1.4.4", Registries: []v1.ContainerdRegistry{{Host: "docker.io"}}}, false},
+		{"duplicate host", v1.Containerd{Version: "1.6.4", Registries: []v1.ContainerdRegistry{{Host: "docker.io"}, {Host: "docker.io"}}}, false},
+		{"host with path", v1.Containerd{Version: "1.6.4", Registries: []v1.ContainerdRegistry{{Host: "docker.io/library"}}}, false},
+		{"bad mirror", v1.Containerd{Version: "1.6.4", Registries: []v1.ContainerdRegistry{{Host: "docker.io", Mirrors: []string{"ftp://mirror"}}}}, false},
+		{"bad ca", v1.Containerd{Version: "1.6.4", Registries: []v1.ContainerdRegistry{{Host: "docker.io", CA: "ca"}}}, false},
+		{"username only", v1.Containerd{Version: "1.6.4", Registries: []v1.ContainerdRegistry{{Host: "docker.io", Username: "admin"}}}, false},
+		{"no registries", v1.Containerd{Version: "1.4.4"}, true},
+	} {
+		if err := tt.c.ValidateRegistries(); (err == nil) != tt.valid {
+			t.Errorf("%s: valid = %v, err = %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
 
 func init() {
@@ -32,12 +33,17 @@ func init() {
 	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, criDocker, criVersion, component.TypeStep), &DockerRunnable{}); err != nil {
 		panic(err)
 	}
+
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, containerdRegistry, criVersion, component.TypeStep), &ContainerdRegistryRunnable{}); err != nil {
+		panic(err)
+	}
 }
 
 const (
-	criDocker     = "docker"
-	criContainerd = "containerd"
-	criVersion    = "v1"
+	criDocker          = "docker"
+	criContainerd      = "containerd"
+	containerdRegistry = "containerdRegistry"
+	criVersion         = "v1"
 )
 
 const (
@@ -51,6 +57,7 @@ const (
 	containerdDefaultConfigDir = "/etc/containerd"
 	//containerdDefaultSystemdDir = "/etc/systemd/system"
 	containerdDefaultDataDir = "/var/lib/containerd"
+	containerdCertsDir       = "/etc/containerd/certs.d"
 )
 
 var k8sMatchPauseVersion = map[string]string{
@@ -65,6 +72,7 @@ var k8sMatchPauseVersion = map[string]string{
 
 var _ component.StepRunnable = (*ContainerdRunnable)(nil)
 var _ component.StepRunnable = (*DockerRunnable)(nil)
+var _ component.StepRunnable = (*ContainerdRegistryRunnable)(nil)
 
 type Base struct {
 	Version          string   `json:"version,omitempty"`
@@ -72,4 +80,6 @@ type Base struct {
 	DataRootDir      string   `json:"rootDir"`
 	InsecureRegistry []string `json:"insecureRegistry,omitempty"`
 	Arch             string   `json:"arch"`
+	// Proxy is the http proxy of the online downloads, nil to connect directly.
+	Proxy *v1.ProxyEnv `json:"proxy,omitempty"`
 }
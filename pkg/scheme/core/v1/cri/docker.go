@@ -25,15 +25,16 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"runtime"
 	"time"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
 	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
 	"go.uber.org/zap"
@@ -56,51 +57,51 @@ func (runnable *DockerRunnable) InitStep(ctx context.Context, docker *v1.Docker,
 	runnable.DataRootDir = docker.DataRootDir
 	runnable.InsecureRegistry = docker.InsecureRegistry
 
-	runtimeBytes, err := json.Marshal(runnable)
-	if err != nil {
-		return err
+	if len(runnable.installSteps) != 0 || len(runnable.uninstallSteps) != 0 {
+		return nil
 	}
-
-	if len(runnable.installSteps) == 0 {
-		runnable.installSteps = []v1.Step{
-			{
-				ID:         strutil.GetUUID(),
-				Name:       "installRuntime",
-				Timeout:    metav1.Duration{Duration: 10 * time.Minute},
-				ErrIgnore:  false,
-				RetryTimes: 1,
-				Nodes:      nodes,
-				Action:     v1.ActionInstall,
-				Commands: []v1.Command{
-					{
-						Type:          v1.CommandCustom,
-						Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, criDocker, criVersion, component.TypeStep),
-						CustomCommand: runtimeBytes,
-					},
+	// every group of nodes installs the runtime bundle of its own architecture
+	archs, groups := utils.GroupNodesByArch(nodes)
+	for _, arch := range archs {
+		runnable.Arch = arch
+		runtimeBytes, err := json.Marshal(runnable)
+		if err != nil {
+			return err
+		}
+		runnable.installSteps = append(runnable.installSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "installRuntime",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      groups[arch],
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, criDocker, criVersion, component.TypeStep),
+					CustomCommand: runtimeBytes,
 				},
 			},
-		}
-	}
-	if len(runnable.uninstallSteps) == 0 {
-		runnable.uninstallSteps = []v1.Step{
-			{
-				ID:         strutil.GetUUID(),
-				Name:       "uninstallRuntime",
-				Timeout:    metav1.Duration{Duration: 10 * time.Minute},
-				ErrIgnore:  false,
-				RetryTimes: 1,
-				Nodes:      nodes,
-				Action:     v1.ActionUninstall,
-				Commands: []v1.Command{
-					{
-						Type:          v1.CommandCustom,
-						Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, criDocker, criVersion, component.TypeStep),
-						CustomCommand: runtimeBytes,
-					},
+		})
+		runnable.uninstallSteps = append(runnable.uninstallSteps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "uninstallRuntime",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      groups[arch],
+			Action:     v1.ActionUninstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, criDocker, criVersion, component.TypeStep),
+					CustomCommand: runtimeBytes,
 				},
 			},
-		}
+		})
 	}
+	runnable.Arch = ""
 
 	return nil
 }
@@ -118,8 +119,9 @@ func (runnable *DockerRunnable) GetActionSteps(action v1.StepAction) []v1.Step {
 	return nil
 }
 
-func (runnable *DockerRunnable) setParams() {
-	runnable.Arch = component.OSArchAMD64
+func (runnable *DockerRunnable) setParams() (err error) {
+	runnable.Arch, err = downloader.ResolveArch(runnable.Arch)
+	return err
 }
 
 func (runnable *DockerRunnable) NewInstance() component.ObjectMeta {
@@ -127,8 +129,10 @@ func (runnable *DockerRunnable) NewInstance() component.ObjectMeta {
 }
 
 func (runnable DockerRunnable) Install(ctx context.Context, opts component.Options) ([]byte, error) {
-	runnable.setParams()
-	instance, err := downloader.NewInstance(ctx, criDocker, runnable.Version, runtime.GOARCH, !runnable.Offline, opts.DryRun)
+	if err := runnable.setParams(); err != nil {
+		return nil, err
+	}
+	instance, err := downloader.NewInstance(downloader.WithProxy(ctx, runnable.Proxy), criDocker, runnable.Version, runnable.Arch, !runnable.Offline, opts.DryRun)
 	if err != nil {
 		return nil, err
 	}
@@ -148,12 +152,14 @@ func (runnable DockerRunnable) Install(ctx context.Context, opts component.Optio
 }
 
 func (runnable DockerRunnable) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
-	runnable.setParams()
+	if err := runnable.setParams(); err != nil {
+		return nil, err
+	}
 	if err := runnable.disableDockerService(ctx, opts.DryRun); err != nil {
 		return nil, err
 	}
 	// remove related binary configuration files
-	instance, err := downloader.NewInstance(ctx, criDocker, runnable.Version, runtime.GOARCH, !runnable.Offline, opts.DryRun)
+	instance, err := downloader.NewInstance(ctx, criDocker, runnable.Version, runnable.Arch, !runnable.Offline, opts.DryRun)
 	if err != nil {
 		return nil, err
 	}
@@ -204,11 +210,11 @@ func (runnable *DockerRunnable) renderTo(w io.Writer) error {
 }
 
 func (runnable *DockerRunnable) enableDockerService(ctx context.Context, dryRun bool) error {
-	_, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "daemon-reload")
+	_, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.DaemonReload())
 	if err != nil {
 		return err
 	}
-	_, err = cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "enable", "docker", "--now")
+	_, err = cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Enable("docker", true))
 	if err != nil {
 		return err
 	}
@@ -217,10 +223,10 @@ func (runnable *DockerRunnable) enableDockerService(ctx context.Context, dryRun
 
 func (runnable *DockerRunnable) disableDockerService(ctx context.Context, dryRun bool) error {
 	// the following command execution error is ignored
-	if _, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "stop", "docker"); err != nil {
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Stop("docker")); err != nil {
 		logger.Warn("stop systemd docker service failed", zap.Error(err))
 	}
-	if _, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "disable", "docker"); err != nil {
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Disable("docker")); err != nil {
 		logger.Warn("disable systemd docker service failed", zap.Error(err))
 	}
 	return nil
@@ -0,0 +1,112 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cri
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+const criPrewarm = "prewarmRuntime"
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, criPrewarm, criVersion, component.TypeStep), &PrewarmRunnable{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.StepRunnable = (*PrewarmRunnable)(nil)
+
+// PrewarmRunnable downloads the container runtime package into the downloader cache
+// without installing or starting the runtime.
+type PrewarmRunnable struct {
+	Type    v1.CRIType `json:"type"`
+	Version string     `json:"version"`
+	Offline bool       `json:"offline"`
+}
+
+func (runnable *PrewarmRunnable) NewInstance() component.ObjectMeta {
+	return &PrewarmRunnable{}
+}
+
+func (runnable *PrewarmRunnable) InitStep(cr *v1.ContainerRuntime, offline bool) error {
+	runnable.Type = cr.Type
+	runnable.Offline = offline
+	switch cr.Type {
+	case v1.CRIDocker:
+		runnable.Version = cr.Docker.Version
+	case v1.CRIContainerd:
+		runnable.Version = cr.Containerd.Version
+	default:
+		return fmt.Errorf("no support %v type cri", cr.Type)
+	}
+	return nil
+}
+
+func (runnable *PrewarmRunnable) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, runnable.Type.String(), runnable.Version, runtime.GOARCH, !runnable.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.Download(downloader.ConfigFilename); err != nil {
+		return nil, err
+	}
+	logger.Debugf("%s %s package prewarmed", runnable.Type, runnable.Version)
+	return nil, nil
+}
+
+func (runnable *PrewarmRunnable) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, nil
+}
+
+func (runnable *PrewarmRunnable) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
+	bytes, err := json.Marshal(runnable)
+	if err != nil {
+		return nil, err
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "prewarmRuntime",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, criPrewarm, criVersion, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+	}, nil
+}
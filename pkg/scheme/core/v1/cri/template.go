@@ -158,6 +158,9 @@ oom_score = 0
       max_conf_num = 1
       conf_template = ""
     [plugins."io.containerd.grpc.v1.cri".registry]
+{{- if .Registries}}
+      config_path = "/etc/containerd/certs.d"
+{{- else}}
       [plugins."io.containerd.grpc.v1.cri".registry.mirrors]
 {{- $n := len .InsecureRegistry -}}
 {{- if gt $n 0}}
@@ -168,6 +171,7 @@ oom_score = 0
 {{- else}}
         [plugins."io.containerd.grpc.v1.cri".registry.mirrors."docker.io"]
           endpoint = ["https://registry-1.docker.io"]
+{{- end}}
 {{- end}}
     [plugins."io.containerd.grpc.v1.cri".image_decryption]
       key_model = ""
@@ -197,3 +201,33 @@ oom_score = 0
     pool_name = ""
     base_image_size = ""
     async_remove = false`
+
+// hostsTomlTemplate is the hosts.toml of a registry host under the config_path of containerd.
+const hostsTomlTemplate = `server = "{{.Server}}"
+{{- if .SkipVerify}}
+skip_verify = true
+{{- end}}
+{{- with .CA}}
+ca = "{{.}}"
+{{- end}}
+{{- with .Auth}}
+
+[header]
+  Authorization = ["Basic {{.}}"]
+{{- end}}
+{{- range $mirror := .Mirrors}}
+
+[host."{{$mirror}}"]
+  capabilities = ["pull", "resolve"]
+{{- if $.SkipVerify}}
+  skip_verify = true
+{{- end}}
+{{- with $.CA}}
+  ca = "{{.}}"
+{{- end}}
+{{- with $.Auth}}
+  [host."{{$mirror}}".header]
+    Authorization = ["Basic {{.}}"]
+{{- end}}
+{{- end}}
+`
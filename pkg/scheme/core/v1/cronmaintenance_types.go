@@ -0,0 +1,217 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=false
+
+// CronMaintenance runs etcd maintenance tasks once in every occurrence of
+// its maintenance window. Each run is recorded as an operation.
+type CronMaintenance struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CronMaintenanceSpec   `json:"spec"`
+	Status CronMaintenanceStatus `json:"status,omitempty" optional:"true"`
+}
+
+type MaintenanceTask string
+
+const (
+	// MaintenanceTaskHealth checks that every etcd member is healthy.
+	MaintenanceTaskHealth MaintenanceTask = "health"
+	// MaintenanceTaskCompact compacts the etcd history up to the current revision.
+	MaintenanceTaskCompact MaintenanceTask = "compact"
+	// MaintenanceTaskDefrag defragments the etcd members one after another.
+	MaintenanceTaskDefrag MaintenanceTask = "defrag"
+)
+
+// maintenanceTaskOrder is the order tasks are run in, defrag only releases
+// the space freed by a compaction run before it.
+var maintenanceTaskOrder = []MaintenanceTask{MaintenanceTaskHealth, MaintenanceTaskCompact, MaintenanceTaskDefrag}
+
+const DefaultMaintenanceWindowDuration = time.Hour
+
+type CronMaintenanceSpec struct {
+	// ClusterName is the managed cluster whose etcd is maintained,
+	// the etcd of kubeclipper itself is maintained when it is empty.
+	ClusterName string            `json:"clusterName,omitempty" optional:"true"`
+	Tasks       []MaintenanceTask `json:"tasks"`
+	Window      MaintenanceWindow `json:"window"`
+	// Suspend stops scheduling new runs.
+	Suspend bool `json:"suspend,omitempty" optional:"true"`
+}
+
+// MaintenanceWindow is a recurring period of time, in the time zone of the kc-server.
+type MaintenanceWindow struct {
+	// Weekdays the window opens on, e.g. Saturday, every day when empty.
+	Weekdays []string `json:"weekdays,omitempty" optional:"true"`
+	// Start is the time of day the window opens at, e.g. 02:30.
+	Start string `json:"start"`
+	// Duration of the window, defaults to one hour.
+	Duration metav1.Duration `json:"duration,omitempty" optional:"true"`
+}
+
+type CronMaintenanceStatus struct {
+	// LastScheduleTime is the start of the window the last run belongs to.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+	// LastOperation is the name of the operation of the last run.
+	LastOperation string `json:"lastOperation,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// CronMaintenanceList contains a list of CronMaintenance
+
+type CronMaintenanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CronMaintenance `json:"items"`
+}
+
+func (s *CronMaintenanceSpec) Validate() error {
+	if len(s.Tasks) == 0 {
+		return fmt.Errorf("at least one maintenance task is required")
+	}
+	for _, t := range s.Tasks {
+		if !t.Valid() {
+			return fmt.Errorf("unsupported maintenance task %q", t)
+		}
+	}
+	return s.Window.Validate()
+}
+
+func (t MaintenanceTask) Valid() bool {
+	for _, v := range maintenanceTaskOrder {
+		if t == v {
+			return true
+		}
+	}
+	return false
+}
+
+// StepName is the name of the operation step running the task.
+func (t MaintenanceTask) StepName() string {
+	if t == "" {
+		return "etcd"
+	}
+	return "etcd" + strings.ToUpper(string(t[:1])) + string(t[1:])
+}
+
+// OrderedTasks returns the tasks of the spec without duplicates in the order they are run in.
+func (s *CronMaintenanceSpec) OrderedTasks() []MaintenanceTask {
+	var tasks []MaintenanceTask
+	for _, v := range maintenanceTaskOrder {
+		for _, t := range s.Tasks {
+			if t == v {
+				tasks = append(tasks, v)
+				break
+			}
+		}
+	}
+	return tasks
+}
+
+func (w *MaintenanceWindow) Validate() error {
+	if _, err := time.Parse("15:04", w.Start); err != nil {
+		return fmt.Errorf("invalid window start %q, expected HH:MM", w.Start)
+	}
+	if w.Duration.Duration < 0 || w.Duration.Duration > 24*time.Hour {
+		return fmt.Errorf("invalid window duration %s", w.Duration.Duration)
+	}
+	for _, d := range w.Weekdays {
+		if _, ok := parseWeekday(d); !ok {
+			return fmt.Errorf("invalid window weekday %q", d)
+		}
+	}
+	return nil
+}
+
+// OpenedAt returns the start of the window occurrence now falls into,
+// ok is false when the window is closed.
+func (w *MaintenanceWindow) OpenedAt(now time.Time) (start time.Time, ok bool) {
+	hm, err := time.Parse("15:04", w.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	duration := w.Duration.Duration
+	if duration == 0 {
+		duration = DefaultMaintenanceWindowDuration
+	}
+	// the window may have opened the day before and still be open
+	for _, day := range []int{0, -1} {
+		start = time.Date(now.Year(), now.Month(), now.Day()+day, hm.Hour(), hm.Minute(), 0, 0, now.Location())
+		if now.Before(start) || !now.Before(start.Add(duration)) || !w.onWeekday(start.Weekday()) {
+			continue
+		}
+		return start, true
+	}
+	return time.Time{}, false
+}
+
+func (w *MaintenanceWindow) onWeekday(day time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range w.Weekdays {
+		if v, ok := parseWeekday(d); ok && v == day {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := d.String()
+		if strings.EqualFold(s, name) || strings.EqualFold(s, name[:3]) {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+// Due reports whether a run should start now, it returns the start of the
+// current window which is recorded as the schedule time of the run.
+func (c *CronMaintenance) Due(now time.Time) (time.Time, bool) {
+	if c.Spec.Suspend {
+		return time.Time{}, false
+	}
+	start, ok := c.Spec.Window.OpenedAt(now)
+	if !ok {
+		return time.Time{}, false
+	}
+	if c.Status.LastScheduleTime != nil && !c.Status.LastScheduleTime.Time.Before(start) {
+		return time.Time{}, false
+	}
+	return start, true
+}
@@ -0,0 +1,125 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMaintenanceWindowOpenedAt(t *testing.T) {
+	// 2022-06-04 is a Saturday
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2022, 6, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name   string
+		window MaintenanceWindow
+		now    time.Time
+		start  time.Time
+		open   bool
+	}{
+		{
+			name:   "inside daily window",
+			window: MaintenanceWindow{Start: "02:00"},
+			now:    at(4, 2, 30),
+			start:  at(4, 2, 0),
+			open:   true,
+		},
+		{
+			name:   "after daily window",
+			window: MaintenanceWindow{Start: "02:00"},
+			now:    at(4, 3, 0),
+		},
+		{
+			name:   "window crossing midnight",
+			window: MaintenanceWindow{Start: "23:30", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			now:    at(5, 0, 45),
+			start:  at(4, 23, 30),
+			open:   true,
+		},
+		{
+			name:   "opened on matching weekday",
+			window: MaintenanceWindow{Start: "23:30", Weekdays: []string{"sat"}, Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			now:    at(5, 0, 45),
+			start:  at(4, 23, 30),
+			open:   true,
+		},
+		{
+			name:   "other weekday",
+			window: MaintenanceWindow{Start: "02:00", Weekdays: []string{"Sunday"}},
+			now:    at(4, 2, 30),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, open := tt.window.OpenedAt(tt.now)
+			if open != tt.open || !start.Equal(tt.start) {
+				t.Errorf("OpenedAt() = %v, %v, want %v, %v", start, open, tt.start, tt.open)
+			}
+		})
+	}
+}
+
+func TestCronMaintenanceDue(t *testing.T) {
+	now := time.Date(2022, 6, 4, 2, 30, 0, 0, time.UTC)
+	m := &CronMaintenance{Spec: CronMaintenanceSpec{
+		Tasks:  []MaintenanceTask{MaintenanceTaskDefrag},
+		Window: MaintenanceWindow{Start: "02:00"},
+	}}
+	start, due := m.Due(now)
+	if !due {
+		t.Fatal("expected maintenance to be due in an open window")
+	}
+	m.Status.LastScheduleTime = &metav1.Time{Time: start}
+	if _, due = m.Due(now.Add(10 * time.Minute)); due {
+		t.Error("maintenance must run once per window")
+	}
+	if _, due = m.Due(now.Add(24 * time.Hour)); !due {
+		t.Error("expected maintenance to be due in the next window")
+	}
+	m.Spec.Suspend = true
+	if _, due = m.Due(now.Add(24 * time.Hour)); due {
+		t.Error("suspended maintenance must not be due")
+	}
+}
+
+func TestCronMaintenanceSpec(t *testing.T) {
+	spec := CronMaintenanceSpec{
+		Tasks:  []MaintenanceTask{MaintenanceTaskDefrag, MaintenanceTaskHealth, MaintenanceTaskDefrag, MaintenanceTaskCompact},
+		Window: MaintenanceWindow{Start: "25:00"},
+	}
+	if err := spec.Validate(); err == nil {
+		t.Error("expected invalid window start to be rejected")
+	}
+	spec.Window.Start = "02:00"
+	if err := spec.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	want := []MaintenanceTask{MaintenanceTaskHealth, MaintenanceTaskCompact, MaintenanceTaskDefrag}
+	if got := spec.OrderedTasks(); !reflect.DeepEqual(got, want) {
+		t.Errorf("OrderedTasks() = %v, want %v", got, want)
+	}
+	if got := MaintenanceTaskDefrag.StepName(); got != "etcdDefrag" {
+		t.Errorf("StepName() = %s", got)
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+const (
+	verifyBackup = "verifyBackup"
+	// VerifyBackupStepName is the name of the step checking a backup just saved.
+	VerifyBackupStepName = "verifyBackup"
+	verifyBackupDir      = "/tmp/.kc-backup-verify"
+)
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, verifyBackup, version, component.TypeStep), &VerifyBackup{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.StepRunnable = (*VerifyBackup)(nil)
+
+// VerifyBackup downloads a saved snapshot back from the backup point and checks it
+// can be read by etcd, so that a broken upload is found before a restore needs it.
+type VerifyBackup struct {
+	ActBackup
+}
+
+// VerifyResult is the response of the verifyBackup step. Error is set when the
+// snapshot was downloaded but is not usable, the step itself still succeeds.
+type VerifyResult struct {
+	BackupFileSize int64
+	BackupFileMD5  string
+	SnapshotHash   string
+	Revision       int64
+	TotalKeys      int64
+	Error          string
+}
+
+// snapshotStatus is the json output of `etcdutl snapshot status`.
+type snapshotStatus struct {
+	Hash      uint32 `json:"hash"`
+	Revision  int64  `json:"revision"`
+	TotalKey  int64  `json:"totalKey"`
+	TotalSize int64  `json:"totalSize"`
+}
+
+func (stepper *VerifyBackup) NewInstance() component.ObjectMeta {
+	return &VerifyBackup{}
+}
+
+func (stepper *VerifyBackup) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if err := os.MkdirAll(verifyBackupDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	file := filepath.Join(verifyBackupDir, filepath.Base(stepper.BackupFileName))
+	defer os.Remove(file)
+
+	w, err := os.Create(file)
+	if err != nil {
+		return nil, err
+	}
+	store, err := stepper.BackupStoreCreate()
+	if err != nil {
+		w.Close()
+		return nil, err
+	}
+	err = store.Download(ctx, stepper.BackupFileName, w)
+	w.Close()
+	if err != nil {
+		logger.Errorf("download backup file %s failed: %s", stepper.BackupFileName, err.Error())
+		return nil, err
+	}
+
+	result, err := checksum(file)
+	if err != nil {
+		return nil, err
+	}
+	// etcdutl replaces the deprecated `etcdctl snapshot status` since etcd 3.5
+	cmd := fmt.Sprintf("if command -v etcdutl >/dev/null 2>&1; then etcdutl snapshot status %[1]s -w json; else ETCDCTL_API=3 etcdctl snapshot status %[1]s -w json; fi", file)
+	ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", cmd)
+	if err != nil {
+		result.Error = fmt.Sprintf("snapshot status failed: %v", err)
+		if ec != nil && ec.StdErr() != "" {
+			result.Error = fmt.Sprintf("snapshot status failed: %s", ec.StdErr())
+		}
+		return json.Marshal(result)
+	}
+	if !opts.DryRun {
+		status := snapshotStatus{}
+		if err = json.Unmarshal([]byte(ec.StdOut()), &status); err != nil {
+			result.Error = fmt.Sprintf("parse snapshot status failed: %v", err)
+			return json.Marshal(result)
+		}
+		result.SnapshotHash = fmt.Sprintf("%x", status.Hash)
+		result.Revision = status.Revision
+		result.TotalKeys = status.TotalKey
+	}
+	logger.Infof("etcd backup file %s verified", stepper.BackupFileName)
+	return json.Marshal(result)
+}
+
+func (stepper *VerifyBackup) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	return nil, fmt.Errorf("backup verification no support uninstall")
+}
+
+func (stepper *VerifyBackup) makeInstallStep(metadata *component.ExtraMetadata) (v1.Step, error) {
+	rBytes, err := json.Marshal(stepper)
+	if err != nil {
+		return v1.Step{}, err
+	}
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       VerifyBackupStepName,
+		Timeout:    metav1.Duration{Duration: 5 * time.Minute},
+		ErrIgnore:  false,
+		RetryTimes: 0,
+		Nodes:      utils.UnwrapNodeList(metadata.Masters[:1]),
+		Action:     v1.ActionInstall,
+		Commands: []v1.Command{
+			{
+				Type:          v1.CommandCustom,
+				Identity:      fmt.Sprintf(component.RegisterTemplateKeyFormat, verifyBackup, version, component.TypeStep),
+				CustomCommand: rBytes,
+			},
+		},
+	}, nil
+}
+
+func checksum(file string) (VerifyResult, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	defer f.Close()
+	h := md5.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	return VerifyResult{BackupFileSize: size, BackupFileMD5: fmt.Sprintf("%x", h.Sum(nil))}, nil
+}
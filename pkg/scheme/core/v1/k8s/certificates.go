@@ -0,0 +1,144 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+// CertificatesScript prints the control plane certificates of a master, each one after a "# <name>" line.
+// The client certificates of the kubeconfigs are embedded, kubelet.conf is left out as kubelet rotates its own.
+const CertificatesScript = `for f in /etc/kubernetes/pki/*.crt /etc/kubernetes/pki/etcd/*.crt; do
+  [ -f "$f" ] || continue
+  echo "# ${f#/etc/kubernetes/pki/}"
+  cat "$f"
+done
+for f in admin.conf controller-manager.conf scheduler.conf; do
+  [ -f "/etc/kubernetes/$f" ] || continue
+  echo "# $f"
+  grep client-certificate-data "/etc/kubernetes/$f" | awk '{print $2}' | base64 -d
+done`
+
+// renewCertificates renews every certificate kubeadm manages and restarts the static pods to load them,
+// then waits for the apiserver to come back before the next master is renewed.
+const renewCertificates = `if kubeadm certs --help >/dev/null 2>&1; then kubeadm certs renew all; else kubeadm alpha certs renew all; fi
+cp -f /etc/kubernetes/admin.conf $HOME/.kube/config
+mkdir -p /tmp/kc-manifests
+mv /etc/kubernetes/manifests/*.yaml /tmp/kc-manifests/
+sleep 20
+mv /tmp/kc-manifests/*.yaml /etc/kubernetes/manifests/
+for i in $(seq 60); do
+  kubectl get --raw=/readyz >/dev/null 2>&1 && exit 0
+  sleep 5
+done
+echo "apiserver is not ready after the certificates were renewed"
+exit 1`
+
+// RenewCertificatesSteps returns the steps renewing the control plane certificates, one master after
+// another so that the control plane of highly available clusters stays up.
+func RenewCertificatesSteps(masters []v1.StepNode) []v1.Step {
+	steps := make([]v1.Step, 0, len(masters))
+	for _, node := range masters {
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "renewCertificates",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      []v1.StepNode{node},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", renewCertificates},
+				},
+			},
+		})
+	}
+	return steps
+}
+
+// regenerateAPIServerCertificate issues the apiserver certificate again with the subject alternative names
+// of the rendered kubeadm configuration, the previous one is restored if kubeadm fails.
+const regenerateAPIServerCertificate = `set -e
+mkdir -p /tmp/kc-apiserver-certs
+cp -f /etc/kubernetes/pki/apiserver.crt /etc/kubernetes/pki/apiserver.key /tmp/kc-apiserver-certs/
+rm -f /etc/kubernetes/pki/apiserver.crt /etc/kubernetes/pki/apiserver.key
+if ! kubeadm init phase certs apiserver --config %[1]s/kubeadm.yaml; then
+  cp -f /tmp/kc-apiserver-certs/apiserver.crt /tmp/kc-apiserver-certs/apiserver.key /etc/kubernetes/pki/
+  exit 1
+fi
+mv /etc/kubernetes/manifests/kube-apiserver.yaml /tmp/kc-apiserver-certs/
+sleep 20
+mv /tmp/kc-apiserver-certs/kube-apiserver.yaml /etc/kubernetes/manifests/
+for i in $(seq 60); do
+  kubectl get --raw=/readyz >/dev/null 2>&1 && exit 0
+  sleep 5
+done
+echo "apiserver is not ready after its certificate was regenerated"
+exit 1`
+
+// UpdateCertSANsSteps returns the steps regenerating the apiserver certificate of every master, one after
+// another, with the subject alternative names of cfg. The kubeadm-config of the cluster is updated as well,
+// so masters joining later get the same names.
+func UpdateCertSANsSteps(masters []v1.StepNode, cfg *KubeadmConfig) ([]v1.Step, error) {
+	steps, err := cfg.InstallSteps(masters)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range masters {
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "regenerateAPIServerCertificate",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      []v1.StepNode{node},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf(regenerateAPIServerCertificate, ManifestDir)},
+				},
+			},
+		})
+	}
+	steps = append(steps, v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       "uploadKubeadmConfig",
+		Timeout:    metav1.Duration{Duration: 1 * time.Minute},
+		ErrIgnore:  false,
+		RetryTimes: 1,
+		Nodes:      masters[:1],
+		Action:     v1.ActionInstall,
+		Commands: []v1.Command{
+			{
+				Type:         v1.CommandShell,
+				ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf("kubeadm init phase upload-config kubeadm --config %s/kubeadm.yaml", ManifestDir)},
+			},
+		},
+	})
+	return steps, nil
+}
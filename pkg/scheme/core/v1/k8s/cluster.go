@@ -42,6 +42,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/sysutil"
 )
@@ -60,6 +61,13 @@ const (
 	afterRecovery  = "afterRecovery"
 )
 
+const (
+	upgradeControlPlanePrefix = "UpgradeControlPlane-"
+	upgradeWorkersPrefix      = "UpgradeWorkers-batch-"
+	rollbackWorkersPrefix     = "RollbackWorkers-batch-"
+	healthGateTimeout         = "300s"
+)
+
 func init() {
 	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, upgradePackage, version, component.TypeStep), &UpgradePackage{}); err != nil {
 		panic(err)
@@ -80,7 +88,10 @@ type Upgrade struct {
 	Offline       bool           `json:"offline"`
 	Version       string         `json:"version"`
 	LocalRegistry string         `json:"localRegistry"`
-	installSteps  []v1.Step
+	// WorkerBatchSize is the number of workers upgraded together.
+	WorkerBatchSize int `json:"workerBatchSize"`
+	installSteps    []v1.Step
+	toolImages      *v1.ToolImages
 }
 
 type UpgradePackage struct {
@@ -98,6 +109,8 @@ type ActBackup struct {
 	AccessKeySecret    string
 	Region             string
 	SSL                bool
+	// Verify adds a step checking the saved snapshot after the backup.
+	Verify bool
 
 	installSteps   []v1.Step
 	uninstallSteps []v1.Step
@@ -152,13 +165,15 @@ func (stepper *Upgrade) InitStepper(metadata *component.ExtraMetadata, kubeadm *
 		ClusterName:             metadata.ClusterName,
 		KubernetesVersion:       kubeadm.KubernetesVersion,
 		ControlPlaneEndpoint:    cpEndpoint,
-		CertSANs:                kubeadm.CertSANs,
+		CertSANs:                kubeadm.APIServerCertSANs(),
 		LocalRegistry:           kubeadm.LocalRegistry,
 		WorkerNodeVip:           kubeadm.WorkerNodeVip,
+		Patches:                 kubeadm.ConfigPatches,
 	}
 	stepper.Offline = metadata.Offline
 	stepper.Version = metadata.KubeVersion
 	stepper.LocalRegistry = metadata.LocalRegistry
+	stepper.toolImages = kubeadm.ToolImages
 }
 
 func (stepper *Upgrade) Validate() error {
@@ -261,126 +276,195 @@ func (stepper *Upgrade) InitSteps(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	stepper.installSteps = append(stepper.installSteps, []v1.Step{
-		{
-			ID:        strutil.GetUUID(),
-			Name:      "RenderUpgradeKubeadm",
-			Nodes:     []v1.StepNode{utils.UnwrapNodeList(masters)[0]},
-			Action:    v1.ActionInstall,
-			Timeout:   metav1.Duration{Duration: 10 * time.Minute},
-			ErrIgnore: false,
-			Commands: []v1.Command{
-				{
-					Type: v1.CommandTemplateRender,
-					Template: &v1.TemplateCommand{
-						Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, kubeadmConfig, version, component.TypeTemplate),
-						Data:     kubeadmBytes,
-					},
+	master0 := utils.UnwrapNodeList(masters)[0]
+	stepper.installSteps = append(stepper.installSteps, v1.Step{
+		ID:        strutil.GetUUID(),
+		Name:      "RenderUpgradeKubeadm",
+		Nodes:     []v1.StepNode{master0},
+		Action:    v1.ActionInstall,
+		Timeout:   metav1.Duration{Duration: 10 * time.Minute},
+		ErrIgnore: false,
+		Commands: []v1.Command{
+			{
+				Type: v1.CommandTemplateRender,
+				Template: &v1.TemplateCommand{
+					Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, kubeadmConfig, version, component.TypeTemplate),
+					Data:     kubeadmBytes,
 				},
 			},
-			RetryTimes: 1,
 		},
-		{
+		RetryTimes: 1,
+	})
+
+	// control plane nodes are upgraded one at a time, the next node is only
+	// touched after the apiserver is healthy again and the upgraded node is Ready.
+	for i := range masters {
+		hostname := extraMetadata.GetMasterHostname(masters[i].ID)
+		upgradeCmd := "kubeadm upgrade node"
+		if i == 0 {
+			upgradeCmd = fmt.Sprintf("kubeadm upgrade apply %s -f --ignore-preflight-errors all --config /tmp/.k8s/kubeadm.yaml", stepper.Version)
+		}
+		stepper.installSteps = append(stepper.installSteps, v1.Step{
 			ID:        strutil.GetUUID(),
-			Name:      fmt.Sprintf("UpgradeControlPlane-%s", extraMetadata.GetMasterHostname(masters[0].ID)),
-			Nodes:     []v1.StepNode{utils.UnwrapNodeList(masters)[0]},
+			Name:      upgradeControlPlanePrefix + hostname,
+			Nodes:     []v1.StepNode{utils.UnwrapNodeList(masters)[i]},
 			Action:    v1.ActionInstall,
 			Timeout:   metav1.Duration{Duration: 10 * time.Minute},
 			ErrIgnore: false,
 			Commands: []v1.Command{
+				kubectlScript(stepper.toolImages, fmt.Sprintf("kubectl drain %s --ignore-daemonsets || true", hostname)),
 				{
 					Type: v1.CommandShell,
 					ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf(`
-kubeadm upgrade apply %s -f --ignore-preflight-errors all --config /tmp/.k8s/kubeadm.yaml
+%s
 sleep 10
-kubectl drain %s --ignore-daemonsets || true
 systemctl stop kubelet
-systemctl daemon-reload && systemctl restart kubelet
-kubectl uncordon %s || true`,
-						stepper.Version, extraMetadata.GetMasterHostname(masters[0].ID), extraMetadata.GetMasterHostname(masters[0].ID))},
+systemctl daemon-reload && systemctl restart kubelet`, upgradeCmd)},
 				},
+				kubectlScript(stepper.toolImages, fmt.Sprintf("kubectl uncordon %s || true", hostname)),
 			},
 			RetryTimes: 0,
-		},
-	}...)
+		}, healthGateStep("HealthGate-"+hostname, master0, []string{hostname}, stepper.toolImages))
+	}
 
-	for i := 1; i < len(masters); i++ {
-		stepper.installSteps = append(stepper.installSteps, v1.Step{
+	for i, batch := range WorkerBatches(utils.UnwrapNodeList(workers), stepper.WorkerBatchSize) {
+		stepper.installSteps = append(stepper.installSteps, workerBatchSteps(i, master0, batch, `
+kubeadm upgrade node
+systemctl stop kubelet
+systemctl daemon-reload && systemctl restart kubelet`, upgradeWorkersPrefix, stepper.toolImages)...)
+	}
+	return nil
+}
+
+// WorkerBatches splits the worker nodes into batches of at most size nodes,
+// a size less than 1 upgrades one node at a time.
+func WorkerBatches(nodes []v1.StepNode, size int) [][]v1.StepNode {
+	if size < 1 {
+		size = 1
+	}
+	var batches [][]v1.StepNode
+	for i := 0; i < len(nodes); i += size {
+		end := i + size
+		if end > len(nodes) {
+			end = len(nodes)
+		}
+		batches = append(batches, nodes[i:end])
+	}
+	return batches
+}
+
+// workerBatchSteps drains a batch of workers, runs cmd on all of them at once,
+// brings them back and waits for them to be Ready. kubectl runs in the tool image when it is set.
+func workerBatchSteps(index int, master0 v1.StepNode, batch []v1.StepNode, cmd, namePrefix string, images *v1.ToolImages) []v1.Step {
+	hostnames := make([]string, 0, len(batch))
+	for _, node := range batch {
+		hostnames = append(hostnames, node.Hostname)
+	}
+	hosts := strings.Join(hostnames, " ")
+	return []v1.Step{
+		{
 			ID:        strutil.GetUUID(),
-			Name:      fmt.Sprintf("UpgradeControlPlane-%s", extraMetadata.GetMasterHostname(masters[i].ID)),
-			Nodes:     []v1.StepNode{utils.UnwrapNodeList(masters)[i]},
+			Name:      fmt.Sprintf("DrainNodes-batch-%d", index),
+			Nodes:     []v1.StepNode{master0},
+			Action:    v1.ActionInstall,
+			Timeout:   metav1.Duration{Duration: time.Duration(len(batch)) * time.Minute},
+			ErrIgnore: true,
+			Commands: []v1.Command{
+				kubectlScript(images, fmt.Sprintf("for n in %s; do kubectl drain $n --ignore-daemonsets; done", hosts)),
+			},
+			RetryTimes: 0,
+		},
+		{
+			ID:        strutil.GetUUID(),
+			Name:      fmt.Sprintf("%s%d", namePrefix, index),
+			Nodes:     batch,
 			Action:    v1.ActionInstall,
 			Timeout:   metav1.Duration{Duration: 10 * time.Minute},
 			ErrIgnore: false,
 			Commands: []v1.Command{
 				{
-					Type: v1.CommandShell,
-					ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf(`
-kubeadm upgrade node
-sleep 10
-kubectl drain %s --ignore-daemonsets || true
-systemctl stop kubelet
-systemctl daemon-reload && systemctl restart kubelet
-kubectl uncordon %s || true`,
-						extraMetadata.GetMasterHostname(masters[i].ID), extraMetadata.GetMasterHostname(masters[i].ID))},
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", cmd},
 				},
 			},
 			RetryTimes: 0,
-		})
+		},
+		{
+			ID:        strutil.GetUUID(),
+			Name:      fmt.Sprintf("UncordonNodes-batch-%d", index),
+			Nodes:     []v1.StepNode{master0},
+			Action:    v1.ActionInstall,
+			Timeout:   metav1.Duration{Duration: 1 * time.Minute},
+			ErrIgnore: true,
+			Commands: []v1.Command{
+				kubectlScript(images, fmt.Sprintf("for n in %s; do kubectl uncordon $n; done", hosts)),
+			},
+			RetryTimes: 0,
+		},
+		healthGateStep(fmt.Sprintf("HealthGate-batch-%d", index), master0, hostnames, images),
 	}
+}
 
-	for i := range workers {
-		stepper.installSteps = append(stepper.installSteps, []v1.Step{
-			{
-				ID:        strutil.GetUUID(),
-				Name:      fmt.Sprintf("DrainNode-%s", extraMetadata.GetWorkerHostname(workers[i].ID)),
-				Nodes:     []v1.StepNode{utils.UnwrapNodeList(masters)[0]},
-				Action:    v1.ActionInstall,
-				Timeout:   metav1.Duration{Duration: 1 * time.Minute},
-				ErrIgnore: true,
-				Commands: []v1.Command{
-					{
-						Type:         v1.CommandShell,
-						ShellCommand: []string{"kubectl", "drain", extraMetadata.GetWorkerHostname(workers[i].ID)},
-					},
-				},
-				RetryTimes: 0,
-			},
-			{
-				ID:        strutil.GetUUID(),
-				Name:      fmt.Sprintf("UpgradeWorker-%s", extraMetadata.GetWorkerHostname(workers[i].ID)),
-				Nodes:     []v1.StepNode{utils.UnwrapNodeList(workers)[i]},
-				Action:    v1.ActionInstall,
-				Timeout:   metav1.Duration{Duration: 10 * time.Minute},
-				ErrIgnore: false,
-				Commands: []v1.Command{
-					{
-						Type: v1.CommandShell,
-						ShellCommand: []string{"/bin/bash", "-c", `
-kubeadm upgrade node
+// healthGateStep fails unless the apiserver answers readyz and all the given
+// nodes turn Ready in time. It is a checkpoint, so a paused upgrade stops right after it.
+func healthGateStep(name string, node v1.StepNode, hostnames []string, images *v1.ToolImages) v1.Step {
+	nodes := make([]string, 0, len(hostnames))
+	for _, h := range hostnames {
+		nodes = append(nodes, "node/"+h)
+	}
+	return v1.Step{
+		ID:        strutil.GetUUID(),
+		Name:      name,
+		Nodes:     []v1.StepNode{node},
+		Action:    v1.ActionInstall,
+		Timeout:   metav1.Duration{Duration: 10 * time.Minute},
+		ErrIgnore: false,
+		Commands: []v1.Command{
+			kubectlScript(images, fmt.Sprintf(`
+for i in $(seq 1 36); do kubectl get --raw /readyz >/dev/null 2>&1 && break; sleep 5; done
+kubectl get --raw /readyz
+kubectl wait --for=condition=Ready %s --timeout=%s`, strings.Join(nodes, " "), healthGateTimeout)),
+		},
+		RetryTimes: 0,
+		Checkpoint: true,
+	}
+}
+
+// RollbackWorkerSteps builds the steps that put the kubernetes binaries saved by
+// UpgradePackage back on the workers of a paused upgrade. kubeadm cannot downgrade
+// a control plane, so the upgrade must have passed all control plane nodes.
+func RollbackWorkerSteps(done, pending []v1.Step, images *v1.ToolImages) ([]v1.Step, error) {
+	for _, step := range pending {
+		if strings.HasPrefix(step.Name, upgradeControlPlanePrefix) {
+			return nil, fmt.Errorf("control plane upgrade is not finished, %s is still pending", step.Name)
+		}
+	}
+	var (
+		master0 v1.StepNode
+		batches [][]v1.StepNode
+	)
+	for _, step := range done {
+		if step.Name == "RenderUpgradeKubeadm" && len(step.Nodes) > 0 {
+			master0 = step.Nodes[0]
+		}
+		if strings.HasPrefix(step.Name, upgradeWorkersPrefix) {
+			batches = append(batches, step.Nodes)
+		}
+	}
+	if len(batches) == 0 {
+		return nil, fmt.Errorf("no worker batch has been upgraded yet")
+	}
+	if master0.ID == "" {
+		return nil, fmt.Errorf("can not find the control plane node of the upgrade")
+	}
+	var steps []v1.Step
+	for i, batch := range batches {
+		steps = append(steps, workerBatchSteps(i, master0, batch, `
+cp -f /tmp/.k8s-bak/kubeadm /tmp/.k8s-bak/kubelet /tmp/.k8s-bak/kubectl /usr/bin/
 systemctl stop kubelet
-systemctl daemon-reload && systemctl restart kubelet`},
-					},
-				},
-				RetryTimes: 0,
-			},
-			{
-				ID:        strutil.GetUUID(),
-				Name:      fmt.Sprintf("UncordonNode-%s", extraMetadata.GetWorkerHostname(workers[i].ID)),
-				Nodes:     []v1.StepNode{utils.UnwrapNodeList(masters)[0]},
-				Action:    v1.ActionInstall,
-				Timeout:   metav1.Duration{Duration: 1 * time.Minute},
-				ErrIgnore: true,
-				Commands: []v1.Command{
-					{
-						Type:         v1.CommandShell,
-						ShellCommand: []string{"kubectl", "uncordon", extraMetadata.GetWorkerHostname(workers[i].ID)},
-					},
-				},
-				RetryTimes: 0,
-			}}...)
+systemctl daemon-reload && systemctl restart kubelet`, rollbackWorkersPrefix, images)...)
 	}
-	return nil
+	return steps, nil
 }
 
 func (stepper *Upgrade) GetInstallSteps() []v1.Step {
@@ -599,6 +683,15 @@ func (stepper *ActBackup) makeInstallSteps(metadata *component.ExtraMetadata) er
 
 	stepper.installSteps = append(stepper.installSteps, step)
 
+	if stepper.Verify {
+		verify := &VerifyBackup{ActBackup: *stepper}
+		verify.Verify = false
+		if step, err = verify.makeInstallStep(metadata); err != nil {
+			return err
+		}
+		stepper.installSteps = append(stepper.installSteps, step)
+	}
+
 	return nil
 }
 
@@ -954,7 +1047,7 @@ func (stepper *Recovery) Recovering(ctx context.Context, opts component.Options)
 	}
 
 	// systemctl restart kubelet
-	ec, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "systemctl", "restart", "kubelet")
+	ec, err = cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Services.Restart("kubelet"))
 	if err != nil {
 		if ec != nil {
 			logger.Errorf("restart kubelet cmd failed: %s", ec.StdErr())
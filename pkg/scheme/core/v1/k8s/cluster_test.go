@@ -0,0 +1,124 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestWorkerBatches(t *testing.T) {
+	nodes := []v1.StepNode{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}}
+	tests := []struct {
+		size int
+		want []int
+	}{
+		{size: 0, want: []int{1, 1, 1, 1, 1}},
+		{size: 2, want: []int{2, 2, 1}},
+		{size: 5, want: []int{5}},
+		{size: 10, want: []int{5}},
+	}
+	for _, tt := range tests {
+		batches := WorkerBatches(nodes, tt.size)
+		if len(batches) != len(tt.want) {
+			t.Fatalf("size %d: got %d batches, want %d", tt.size, len(batches), len(tt.want))
+		}
+		for i := range batches {
+			if len(batches[i]) != tt.want[i] {
+				t.Errorf("size %d: batch %d has %d nodes, want %d", tt.size, i, len(batches[i]), tt.want[i])
+			}
+		}
+	}
+}
+
+func newTestUpgrade(t *testing.T, batchSize int) []v1.Step {
+	metadata := component.ExtraMetadata{
+		ClusterName: "test",
+		Masters: component.NodeList{
+			{ID: "m1", Hostname: "master-1"},
+			{ID: "m2", Hostname: "master-2"},
+		},
+		Workers: component.NodeList{
+			{ID: "w1", Hostname: "worker-1"},
+			{ID: "w2", Hostname: "worker-2"},
+			{ID: "w3", Hostname: "worker-3"},
+		},
+	}
+	stepper := &Upgrade{
+		Kubeadm:         &KubeadmConfig{KubernetesVersion: "v1.23.6", ContainerRuntime: "containerd"},
+		Version:         "v1.24.1",
+		WorkerBatchSize: batchSize,
+	}
+	if err := stepper.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+		t.Fatal(err)
+	}
+	return stepper.GetInstallSteps()
+}
+
+func TestUpgradeInitSteps(t *testing.T) {
+	steps := newTestUpgrade(t, 2)
+	var names []string
+	for _, s := range steps {
+		names = append(names, s.Name)
+		if strings.HasPrefix(s.Name, "HealthGate-") != s.Checkpoint {
+			t.Errorf("step %s checkpoint = %v", s.Name, s.Checkpoint)
+		}
+	}
+	want := []string{
+		"DownloadMasterUpgradePackage", "DownloadWorkerUpgradePackage", "RenderUpgradeKubeadm",
+		"UpgradeControlPlane-master-1", "HealthGate-master-1",
+		"UpgradeControlPlane-master-2", "HealthGate-master-2",
+		"DrainNodes-batch-0", "UpgradeWorkers-batch-0", "UncordonNodes-batch-0", "HealthGate-batch-0",
+		"DrainNodes-batch-1", "UpgradeWorkers-batch-1", "UncordonNodes-batch-1", "HealthGate-batch-1",
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("got steps %v, want %v", names, want)
+	}
+	if n := len(steps[8].Nodes); n != 2 {
+		t.Errorf("first worker batch has %d nodes, want 2", n)
+	}
+}
+
+func TestRollbackWorkerSteps(t *testing.T) {
+	steps := newTestUpgrade(t, 2)
+	// paused after the second control plane node
+	if _, err := RollbackWorkerSteps(steps[:5], steps[5:], nil); err == nil {
+		t.Error("expected error when control plane upgrade is unfinished")
+	}
+	if _, err := RollbackWorkerSteps(steps[:7], steps[7:], nil); err == nil {
+		t.Error("expected error when no worker batch was upgraded")
+	}
+	rollback, err := RollbackWorkerSteps(steps[:11], steps[11:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rollback) != 4 {
+		t.Fatalf("got %d rollback steps, want 4", len(rollback))
+	}
+	if rollback[1].Name != "RollbackWorkers-batch-0" || len(rollback[1].Nodes) != 2 {
+		t.Errorf("unexpected rollback step %s on %d nodes", rollback[1].Name, len(rollback[1].Nodes))
+	}
+	if rollback[0].Nodes[0].Hostname != "master-1" {
+		t.Errorf("drain should run on master-1, got %s", rollback[0].Nodes[0].Hostname)
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+// CNIPlugin deploys a container network plugin to kubeadm clusters. Plugins are registered
+// by their name, which is the cni type of the cluster spec.
+type CNIPlugin interface {
+	// Name returns the cni type handled by the plugin.
+	Name() string
+	// Versions returns the versions of the plugin that can be deployed.
+	Versions() []string
+	// Validate checks the cni configuration of a cluster.
+	Validate(cni *v1.CNI) error
+	// RenderSteps returns the steps deploying the plugin from the nodes.
+	RenderSteps(cni *v1.CNI, nodes []v1.StepNode, images *v1.ToolImages) ([]v1.Step, error)
+	// Render writes the manifest of the plugin, the agent calls it for the template command of RenderSteps.
+	Render(w io.Writer, cni *v1.CNI) error
+	// CleanSteps returns the steps removing the network devices left by the plugin on the nodes.
+	CleanSteps(cni *v1.CNI, nodes []v1.StepNode) []v1.Step
+	// SupportedUpgrades returns the versions the plugin can be upgraded to from the version.
+	SupportedUpgrades(from string) []string
+}
+
+var cniPlugins = map[string]CNIPlugin{}
+
+func init() {
+	for _, p := range []CNIPlugin{calicoPlugin{}, flannelPlugin{}} {
+		if err := RegisterCNIPlugin(p); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// RegisterCNIPlugin registers the plugin of a cni type, it is meant to be called from init functions.
+func RegisterCNIPlugin(p CNIPlugin) error {
+	if _, exist := cniPlugins[p.Name()]; exist {
+		return fmt.Errorf("cni plugin %s already registered", p.Name())
+	}
+	cniPlugins[p.Name()] = p
+	return nil
+}
+
+// GetCNIPlugin returns the plugin registered for the cni type.
+func GetCNIPlugin(cniType string) (CNIPlugin, error) {
+	p, ok := cniPlugins[cniType]
+	if !ok {
+		return nil, fmt.Errorf("unsupported %s cni type", cniType)
+	}
+	return p, nil
+}
+
+// CNIPluginNames returns the sorted names of the registered plugins.
+func CNIPluginNames() []string {
+	names := make([]string, 0, len(cniPlugins))
+	for name := range cniPlugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateCNIVersion checks that the version is one of the versions of the plugin.
+func validateCNIVersion(p CNIPlugin, version string) error {
+	for _, v := range p.Versions() {
+		if v == version {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s no support %q version, supported versions are %v", p.Name(), version, p.Versions())
+}
+
+// manifestSteps returns the step rendering the manifest of the cni with the plugin registered
+// for its type, and applying it with kubectl.
+func manifestSteps(c *v1.CNI, nodes []v1.StepNode, images *v1.ToolImages) ([]v1.Step, error) {
+	bytes, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "installCNI",
+			Timeout:    metav1.Duration{Duration: 1 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Commands: []v1.Command{
+				{
+					Type: v1.CommandTemplateRender,
+					Template: &v1.TemplateCommand{
+						Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, cni, version, component.TypeTemplate),
+						Data:     bytes,
+					},
+				},
+				kubectlCommand(images, "apply", "-f", filepath.Join(ManifestDir, "cni.yaml")),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,82 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"fmt"
+	"io"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+var _ CNIPlugin = calicoPlugin{}
+
+// calicoTemplates are the manifests of the calico versions.
+var calicoTemplates = map[string]string{
+	"v3.11.2": calicoV3112,
+	"v3.21.2": calicoV3212,
+}
+
+type calicoPlugin struct{}
+
+func (calicoPlugin) Name() string {
+	return CniCalico
+}
+
+func (calicoPlugin) Versions() []string {
+	return []string{"v3.11.2", "v3.21.2"}
+}
+
+func (p calicoPlugin) Validate(cni *v1.CNI) error {
+	// check dualStack and ipv4
+	if cni.Calico.DualStack && (cni.PodIPv4CIDR == "" || cni.PodIPv6CIDR == "") {
+		return fmt.Errorf("ipv4 and ipv6 cidr are both required when calico dual-stack is on")
+	}
+	if !cni.Calico.DualStack && cni.PodIPv4CIDR == "" && cni.PodIPv6CIDR == "" {
+		return fmt.Errorf("calico ipv4 and ipv6 must have at least one")
+	}
+	return validateCNIVersion(p, cni.Calico.Version)
+}
+
+func (calicoPlugin) RenderSteps(cni *v1.CNI, nodes []v1.StepNode, images *v1.ToolImages) ([]v1.Step, error) {
+	return manifestSteps(cni, nodes, images)
+}
+
+func (calicoPlugin) Render(w io.Writer, cni *v1.CNI) error {
+	calicoTemp, ok := calicoTemplates[cni.Calico.Version]
+	if !ok {
+		return fmt.Errorf("calico no support %s version", cni.Calico.Version)
+	}
+	_, err := tmplutil.New().RenderTo(w, calicoTemp, cni)
+	return err
+}
+
+func (calicoPlugin) CleanSteps(cni *v1.CNI, nodes []v1.StepNode) []v1.Step {
+	return ClearCalico(cni.Calico, nodes)
+}
+
+// SupportedUpgrades only allows upgrading to newer manifests, calico does not support downgrades.
+func (calicoPlugin) SupportedUpgrades(from string) []string {
+	switch from {
+	case "v3.11.2":
+		return []string{"v3.21.2"}
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+const (
+	CniFlannel = "flannel"
+
+	FlannelBackendVXLAN  = "vxlan"
+	FlannelBackendHostGW = "host-gw"
+)
+
+var _ CNIPlugin = flannelPlugin{}
+
+// flannelTemplates are the manifests of the flannel versions.
+var flannelTemplates = map[string]string{
+	"v0.20.2": flannelV0202,
+}
+
+type flannelPlugin struct{}
+
+// flannelConfig is the template data of the flannel manifest.
+type flannelConfig struct {
+	*v1.CNI
+	Backend string
+	Args    []string
+}
+
+func (flannelPlugin) Name() string {
+	return CniFlannel
+}
+
+func (flannelPlugin) Versions() []string {
+	return []string{"v0.20.2"}
+}
+
+func (p flannelPlugin) Validate(cni *v1.CNI) error {
+	if cni.PodIPv4CIDR == "" {
+		return fmt.Errorf("flannel requires the pod ipv4 cidr")
+	}
+	switch cni.Flannel.Backend {
+	case "", FlannelBackendVXLAN, FlannelBackendHostGW:
+	default:
+		return fmt.Errorf("unsupported flannel backend %s", cni.Flannel.Backend)
+	}
+	if cni.NodeInterface != nil {
+		if _, err := cni.NodeInterface.FlannelArgs(); err != nil {
+			return err
+		}
+	}
+	return validateCNIVersion(p, cni.Flannel.Version)
+}
+
+func (flannelPlugin) RenderSteps(cni *v1.CNI, nodes []v1.StepNode, images *v1.ToolImages) ([]v1.Step, error) {
+	return manifestSteps(cni, nodes, images)
+}
+
+func (flannelPlugin) Render(w io.Writer, cni *v1.CNI) error {
+	flannelTemp, ok := flannelTemplates[cni.Flannel.Version]
+	if !ok {
+		return fmt.Errorf("flannel no support %s version", cni.Flannel.Version)
+	}
+	data := flannelConfig{
+		CNI:     cni,
+		Backend: strutil.StringDefaultIfEmpty(FlannelBackendVXLAN, cni.Flannel.Backend),
+	}
+	if cni.NodeInterface != nil {
+		args, err := cni.NodeInterface.FlannelArgs()
+		if err != nil {
+			return err
+		}
+		data.Args = args
+	}
+	_, err := tmplutil.New().RenderTo(w, flannelTemp, data)
+	return err
+}
+
+func (flannelPlugin) CleanSteps(cni *v1.CNI, nodes []v1.StepNode) []v1.Step {
+	links := []string{"flannel.1", "cni0"}
+	if cni.PodIPv6CIDR != "" {
+		links = append(links, "flannel-v6.1")
+	}
+	var commands []v1.Command
+	for _, link := range links {
+		commands = append(commands, v1.Command{
+			Type:         v1.CommandShell,
+			ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf("ip link show %[1]s && ip link delete %[1]s || true", link)},
+		})
+	}
+	commands = append(commands, v1.Command{
+		Type:         v1.CommandShell,
+		ShellCommand: []string{"rm", "-rf", "/run/flannel"},
+	})
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "removeFlannelLinks",
+			Timeout:    metav1.Duration{Duration: 10 * time.Second},
+			ErrIgnore:  true,
+			Nodes:      nodes,
+			Action:     v1.ActionUninstall,
+			RetryTimes: 1,
+			Commands:   commands,
+		},
+	}
+}
+
+func (flannelPlugin) SupportedUpgrades(from string) []string {
+	return nil
+}
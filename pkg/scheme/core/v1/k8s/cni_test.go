@@ -0,0 +1,104 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"sigs.k8s.io/yaml"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestCNIPluginNames(t *testing.T) {
+	if names := CNIPluginNames(); !reflect.DeepEqual(names, []string{CniCalico, CniFlannel}) {
+		t.Errorf("registered cni plugins = %v", names)
+	}
+	if err := RegisterCNIPlugin(flannelPlugin{}); err == nil {
+		t.Error("registering flannel twice should fail")
+	}
+	if _, err := GetCNIPlugin("weave"); err == nil {
+		t.Error("weave should not be supported")
+	}
+}
+
+func TestRenderFlannel(t *testing.T) {
+	c := &v1.CNI{
+		Type:          CniFlannel,
+		LocalRegistry: "10.0.0.10:5000",
+		PodIPv4CIDR:   "172.25.0.0/16",
+		PodIPv6CIDR:   "fd00::/108",
+		NodeInterface: &v1.NodeInterface{Method: v1.NodeInterfaceRegex, Value: "eth1"},
+		Flannel:       v1.Flannel{Version: "v0.20.2"},
+	}
+	p, err := GetCNIPlugin(c.Type)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Validate(c); err != nil {
+		t.Fatal(err)
+	}
+	w := &bytes.Buffer{}
+	if err = p.Render(w, c); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"Network": "172.25.0.0/16",`,
+		`"IPv6Network": "fd00::/108",`,
+		`"Type": "vxlan"`,
+		"image: 10.0.0.10:5000/flannel/flannel:v0.20.2",
+		"- --iface-regex=eth1",
+	} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("flannel manifest does not contain %q", want)
+		}
+	}
+	for _, doc := range strings.Split(w.String(), "\n---\n") {
+		obj := map[string]interface{}{}
+		if err = yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			t.Errorf("invalid manifest %s: %v", doc, err)
+		}
+	}
+}
+
+func TestValidateFlannel(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		cni   v1.CNI
+		valid bool
+	}{
+		{"default", v1.CNI{PodIPv4CIDR: "172.25.0.0/16", Flannel: v1.Flannel{Version: "v0.20.2"}}, true},
+		{"host-gw", v1.CNI{PodIPv4CIDR: "172.25.0.0/16", Flannel: v1.Flannel{Backend: "host-gw", Version: "v0.20.2"}}, true},
+		{"ipv6 only", v1.CNI{PodIPv6CIDR: "fd00::/108", Flannel: v1.Flannel{Version: "v0.20.2"}}, false},
+		{"unknown backend", v1.CNI{PodIPv4CIDR: "172.25.0.0/16", Flannel: v1.Flannel{Backend: "udp", Version: "v0.20.2"}}, false},
+		{"unknown version", v1.CNI{PodIPv4CIDR: "172.25.0.0/16", Flannel: v1.Flannel{Version: "v0.14.0"}}, false},
+		{"skip interface", v1.CNI{
+			PodIPv4CIDR:   "172.25.0.0/16",
+			NodeInterface: &v1.NodeInterface{Method: v1.NodeInterfaceSkip, Value: "docker.*"},
+			Flannel:       v1.Flannel{Version: "v0.20.2"},
+		}, false},
+	} {
+		if err := (flannelPlugin{}).Validate(&tt.cni); (err == nil) != tt.valid {
+			t.Errorf("%s: valid = %v, err = %v", tt.name, tt.valid, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+// renewAdminConf renews the client certificate of admin.conf, kubeadm before 1.20 only has the alpha command.
+const renewAdminConf = `if kubeadm certs --help >/dev/null 2>&1; then kubeadm certs renew admin.conf; else kubeadm alpha certs renew admin.conf; fi
+cp -f /etc/kubernetes/admin.conf $HOME/.kube/config`
+
+// RotateCredentialsSteps returns the steps renewing the admin.conf of every master and revoking
+// the kubeconfigs handed out by kubeclipper. The tokens of those kubeconfigs are bound to the uid
+// of their service account, deleting the service accounts invalidates them. The renewed admin.conf
+// is signed by the same CA and certificates are never revoked, so a copy of the former admin.conf
+// stays valid until it expires.
+func RotateCredentialsSteps(masters []v1.StepNode, images *v1.ToolImages) []v1.Step {
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "renewAdminConf",
+			Timeout:    metav1.Duration{Duration: 2 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      masters,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", renewAdminConf},
+				},
+			},
+		},
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "revokeKubeconfigs",
+			Timeout:    metav1.Duration{Duration: time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      masters[:1],
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				kubectlCommand(images, "-n", "kube-system", "delete", "serviceaccount",
+					"-l", common.LabelKubeconfigScope, "--ignore-not-found"),
+			},
+		},
+	}
+}
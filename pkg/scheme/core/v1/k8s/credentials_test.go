@@ -0,0 +1,52 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package k8s
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestRotateCredentialsSteps(t *testing.T) {
+	masters := []v1.StepNode{{ID: "m1"}, {ID: "m2"}}
+	steps := RotateCredentialsSteps(masters, nil)
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want renewAdminConf and revokeKubeconfigs", len(steps))
+	}
+
+	renew := steps[0]
+	if renew.Name != "renewAdminConf" || !reflect.DeepEqual(renew.Nodes, masters) {
+		t.Errorf("step %s runs on %v, want renewAdminConf on every master", renew.Name, renew.Nodes)
+	}
+	wantRenew := []v1.Command{{Type: v1.CommandShell, ShellCommand: []string{"/bin/bash", "-c", renewAdminConf}}}
+	if !reflect.DeepEqual(renew.Commands, wantRenew) {
+		t.Errorf("renewAdminConf commands = %v, want %v", renew.Commands, wantRenew)
+	}
+
+	revoke := steps[1]
+	if revoke.Name != "revokeKubeconfigs" || !reflect.DeepEqual(revoke.Nodes, masters[:1]) {
+		t.Errorf("step %s runs on %v, want revokeKubeconfigs on the first master", revoke.Name, revoke.Nodes)
+	}
+	wantRevoke := []v1.Command{{Type: v1.CommandShell, ShellCommand: []string{"kubectl", "-n", "kube-system", "delete", "serviceaccount",
+		"-l", "kubeclipper.io/kubeconfig-scope", "--ignore-not-found"}}}
+	if !reflect.DeepEqual(revoke.Commands, wantRevoke) {
+		t.Errorf("revokeKubeconfigs commands = %v, want %v", revoke.Commands, wantRevoke)
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+// etcdctlLocal talks to the etcd member running on the node with the kubeadm generated certificates.
+const etcdctlLocal = "ETCDCTL_API=3 etcdctl --endpoints=https://127.0.0.1:2379 --cacert=/etc/kubernetes/pki/etcd/ca.crt --cert=/etc/kubernetes/pki/etcd/server.crt --key=/etc/kubernetes/pki/etcd/server.key"
+
+// EtcdMaintenanceSteps returns the steps running the tasks on the etcd members of the masters.
+// Compaction is cluster wide and runs on the first master, defragmentation blocks the member
+// while it runs and is done one master after another.
+func EtcdMaintenanceSteps(masters []v1.StepNode, tasks []v1.MaintenanceTask, images *v1.ToolImages) []v1.Step {
+	var steps []v1.Step
+	for _, task := range tasks {
+		switch task {
+		case v1.MaintenanceTaskHealth:
+			steps = append(steps, etcdMaintenanceStep(task.StepName(), masters, time.Minute, images,
+				fmt.Sprintf("%s endpoint health && %s endpoint status -w table", etcdctlLocal, etcdctlLocal)))
+		case v1.MaintenanceTaskCompact:
+			steps = append(steps, etcdMaintenanceStep(task.StepName(), masters[:1], 5*time.Minute, images,
+				fmt.Sprintf(`rev=$(%s endpoint status -w json | grep -o '"revision":[0-9]*' | head -1 | cut -d: -f2)
+[ -n "$rev" ] || { echo "failed to read etcd revision"; exit 1; }
+%s compact "$rev" --physical`, etcdctlLocal, etcdctlLocal)))
+		case v1.MaintenanceTaskDefrag:
+			for _, node := range masters {
+				steps = append(steps, etcdMaintenanceStep(task.StepName(), []v1.StepNode{node}, 10*time.Minute, images,
+					fmt.Sprintf("%s defrag --command-timeout=10m", etcdctlLocal)))
+			}
+		}
+	}
+	return steps
+}
+
+func etcdMaintenanceStep(name string, nodes []v1.StepNode, timeout time.Duration, images *v1.ToolImages, cmd string) v1.Step {
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       name,
+		Timeout:    metav1.Duration{Duration: timeout},
+		ErrIgnore:  false,
+		RetryTimes: 1,
+		Nodes:      nodes,
+		Action:     v1.ActionInstall,
+		Commands:   []v1.Command{etcdctlCommand(images, cmd)},
+	}
+}
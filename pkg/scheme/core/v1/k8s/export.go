@@ -0,0 +1,284 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+type exportNode struct {
+	ID       string `json:"id"`
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+}
+
+type exportInventory struct {
+	ClusterName          string        `json:"clusterName"`
+	KubernetesVersion    string        `json:"kubernetesVersion"`
+	ContainerRuntime     string        `json:"containerRuntime"`
+	ControlPlaneEndpoint string        `json:"controlPlaneEndpoint"`
+	Masters              []exportNode  `json:"masters"`
+	Workers              []exportNode  `json:"workers,omitempty"`
+	Networking           v1.Networking `json:"networking"`
+}
+
+// ExportCluster renders the kubeadm configuration, inventory and scripts that reproduce the
+// cluster, or keep maintaining it, with plain kubeadm.
+func ExportCluster(c *v1.Cluster, metadata *component.ExtraMetadata, format v1.ClusterExportFormat) (*v1.ClusterExport, error) {
+	if c.Kubeadm == nil || len(metadata.Masters) == 0 {
+		return nil, fmt.Errorf("cluster %s has no kubeadm control plane to export", c.Name)
+	}
+	cfg := (&KubeadmConfig{}).InitStepper(c.Kubeadm, metadata)
+	apiVersion, err := cfg.matchClusterConfigAPIVersion()
+	if err != nil {
+		return nil, err
+	}
+	cfg.ClusterConfigAPIVersion = apiVersion
+	if cfg.Kubelet.RootDir == "" {
+		cfg.Kubelet.RootDir = KubeletDefaultDataDir
+	}
+	var kubeadmYAML bytes.Buffer
+	if err = cfg.renderTo(&kubeadmYAML); err != nil {
+		return nil, err
+	}
+
+	inv := exportInventory{
+		ClusterName:          c.Name,
+		KubernetesVersion:    c.Kubeadm.KubernetesVersion,
+		ContainerRuntime:     c.Kubeadm.ContainerRuntime.Type.String(),
+		ControlPlaneEndpoint: cfg.ControlPlaneEndpoint,
+		Masters:              toExportNodes(metadata.Masters),
+		Workers:              toExportNodes(metadata.Workers),
+		Networking:           c.Kubeadm.Networking,
+	}
+	invYAML, err := yaml.Marshal(inv)
+	if err != nil {
+		return nil, err
+	}
+
+	apiServerDomain := APIServerDomainPrefix + strutil.StringDefaultIfEmpty("cluster.local", c.Kubeadm.Networking.DNSDomain)
+	// exported scripts are rendered for the distribution of the first control plane node
+	dist := osutil.Get(osutil.Family(metadata.Masters[0].OSFamily))
+	out := &v1.ClusterExport{Format: format}
+	out.Files = []v1.ExportFile{
+		{Path: "README.md", Mode: 0644, Content: exportReadme(c.Name, format)},
+		{Path: "kubeadm.yaml", Mode: 0644, Content: kubeadmYAML.String()},
+		{Path: "inventory.yaml", Mode: 0644, Content: string(invYAML)},
+		{Path: "scripts/setup-node.sh", Mode: 0755, Content: "#!/bin/bash\nset -e\n" + nodeEnvSetupScript(dist, c.Kubeadm.KubeComponents.Kubelet.Swap) + "\n" +
+			fmt.Sprintf("grep -q ' %[2]s$' /etc/hosts || echo \"%[1]s %[2]s\" >> /etc/hosts\n", metadata.Masters[0].IPv4, apiServerDomain)},
+		{Path: "scripts/init-master.sh", Mode: 0755, Content: exportInitScript},
+		{Path: "scripts/print-join-command.sh", Mode: 0755, Content: exportJoinCommandScript},
+	}
+	if len(c.Kubeadm.NTPServers) > 0 {
+		out.Files = append(out.Files, v1.ExportFile{Path: "scripts/time-sync.sh", Mode: 0755,
+			Content: "#!/bin/bash\n" + timeSyncScript(dist, c.Kubeadm.NTPServers) + "\n"})
+	}
+	switch format {
+	case v1.ClusterExportKubeadm:
+	case v1.ClusterExportTerraform:
+		out.Files = append(out.Files,
+			v1.ExportFile{Path: "main.tf", Mode: 0644, Content: exportTerraformMain},
+			v1.ExportFile{Path: "terraform.tfvars", Mode: 0644, Content: exportTerraformVars(inv)})
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+	return out, nil
+}
+
+func toExportNodes(nodes component.NodeList) []exportNode {
+	var out []exportNode
+	for _, n := range nodes {
+		out = append(out, exportNode{ID: n.ID, Hostname: n.Hostname, IP: n.IPv4})
+	}
+	return out
+}
+
+func exportTerraformVars(inv exportInventory) string {
+	var b strings.Builder
+	hosts := func(name string, nodes []exportNode) {
+		b.WriteString(name + " = [")
+		for i, n := range nodes {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(fmt.Sprintf("%q", n.IP))
+		}
+		b.WriteString("]\n")
+	}
+	hosts("masters", inv.Masters)
+	hosts("workers", inv.Workers)
+	return b.String()
+}
+
+func exportReadme(cluster string, format v1.ClusterExportFormat) string {
+	s := fmt.Sprintf(`# %s
+
+Exported by kubeclipper. The files describe the cluster as it was created with kubeadm:
+
+- kubeadm.yaml: the configuration kubeadm init was run with on the first master
+- inventory.yaml: the masters and workers of the cluster
+- scripts/setup-node.sh: prepares a node, run it on every node before init or join
+- scripts/init-master.sh: initializes the first master from kubeadm.yaml
+- scripts/print-join-command.sh: run on a master to print the commands joining masters and workers
+
+The container runtime, kubelet, kubeadm and kubectl packages must be installed on
+the nodes, kubeclipper installed them from its offline package bundle.
+`, cluster)
+	if format == v1.ClusterExportTerraform {
+		s += `
+main.tf drives the same scripts over ssh. Nodes are prepared on every apply, the first
+master is only initialized with -var bootstrap=true, other nodes join when join_command
+and control_plane_join_command are set to the output of scripts/print-join-command.sh.
+`
+	}
+	return s
+}
+
+const exportInitScript = `#!/bin/bash
+set -e
+cd "$(dirname "$0")/.."
+kubeadm init --config kubeadm.yaml --upload-certs
+mkdir -p $HOME/.kube
+cp -f /etc/kubernetes/admin.conf $HOME/.kube/config
+`
+
+const exportJoinCommandScript = `#!/bin/bash
+set -e
+join=$(kubeadm token create --print-join-command)
+key=$(kubeadm init phase upload-certs --upload-certs 2>/dev/null | tail -1)
+echo "worker:        $join"
+echo "control-plane: $join --control-plane --certificate-key $key"
+`
+
+const exportTerraformMain = `variable "masters" {
+  type = list(string)
+}
+
+variable "workers" {
+  type    = list(string)
+  default = []
+}
+
+variable "ssh_user" {
+  type    = string
+  default = "root"
+}
+
+variable "ssh_private_key" {
+  type = string
+}
+
+variable "bootstrap" {
+  description = "initialize the first master, only for a new cluster"
+  type        = bool
+  default     = false
+}
+
+variable "join_command" {
+  description = "worker join command printed by scripts/print-join-command.sh"
+  type        = string
+  default     = ""
+}
+
+variable "control_plane_join_command" {
+  description = "control plane join command printed by scripts/print-join-command.sh"
+  type        = string
+  default     = ""
+}
+
+locals {
+  nodes = concat(var.masters, var.workers)
+}
+
+resource "null_resource" "setup" {
+  count = length(local.nodes)
+
+  connection {
+    host        = local.nodes[count.index]
+    user        = var.ssh_user
+    private_key = file(var.ssh_private_key)
+  }
+
+  provisioner "file" {
+    source      = "${path.module}/"
+    destination = "/tmp/kc-export"
+  }
+
+  provisioner "remote-exec" {
+    inline = [
+      "bash /tmp/kc-export/scripts/setup-node.sh",
+      "if [ -f /tmp/kc-export/scripts/time-sync.sh ]; then bash /tmp/kc-export/scripts/time-sync.sh; fi",
+    ]
+  }
+}
+
+resource "null_resource" "init" {
+  count      = var.bootstrap ? 1 : 0
+  depends_on = [null_resource.setup]
+
+  connection {
+    host        = var.masters[0]
+    user        = var.ssh_user
+    private_key = file(var.ssh_private_key)
+  }
+
+  provisioner "remote-exec" {
+    inline = ["bash /tmp/kc-export/scripts/init-master.sh"]
+  }
+}
+
+resource "null_resource" "join_master" {
+  count      = var.control_plane_join_command == "" ? 0 : length(var.masters) - 1
+  depends_on = [null_resource.init]
+
+  connection {
+    host        = var.masters[count.index + 1]
+    user        = var.ssh_user
+    private_key = file(var.ssh_private_key)
+  }
+
+  provisioner "remote-exec" {
+    inline = ["test -f /etc/kubernetes/kubelet.conf || ${var.control_plane_join_command}"]
+  }
+}
+
+resource "null_resource" "join_worker" {
+  count      = var.join_command == "" ? 0 : length(var.workers)
+  depends_on = [null_resource.join_master]
+
+  connection {
+    host        = var.workers[count.index]
+    user        = var.ssh_user
+    private_key = file(var.ssh_private_key)
+  }
+
+  provisioner "remote-exec" {
+    inline = ["test -f /etc/kubernetes/kubelet.conf || ${var.join_command}"]
+  }
+}
+`
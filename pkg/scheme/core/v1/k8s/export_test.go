@@ -0,0 +1,95 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestExportCluster(t *testing.T) {
+	c := &v1.Cluster{
+		Kubeadm: &v1.Kubeadm{
+			KubernetesVersion: "v1.23.6",
+			ContainerRuntime:  v1.ContainerRuntime{Type: v1.CRIContainerd},
+			Networking: v1.Networking{
+				ServiceSubnet: "10.96.0.0/12",
+				PodSubnet:     "172.25.0.0/16",
+				DNSDomain:     "cluster.local",
+			},
+		},
+	}
+	c.Name = "c1"
+	metadata := &component.ExtraMetadata{
+		ClusterName: "c1",
+		Masters:     component.NodeList{{ID: "m1", IPv4: "192.168.10.10", Hostname: "master-1"}},
+		Workers:     component.NodeList{{ID: "w1", IPv4: "192.168.10.11", Hostname: "worker-1"}},
+	}
+
+	tests := []struct {
+		format  v1.ClusterExportFormat
+		want    []string
+		wantErr bool
+	}{
+		{
+			format: v1.ClusterExportKubeadm,
+			want:   []string{"README.md", "kubeadm.yaml", "inventory.yaml", "scripts/setup-node.sh", "scripts/init-master.sh", "scripts/print-join-command.sh"},
+		},
+		{
+			format: v1.ClusterExportTerraform,
+			want:   []string{"README.md", "kubeadm.yaml", "inventory.yaml", "scripts/setup-node.sh", "scripts/init-master.sh", "scripts/print-join-command.sh", "main.tf", "terraform.tfvars"},
+		},
+		{
+			format:  "ansible",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			got, err := ExportCluster(c, metadata, tt.format)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExportCluster() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			files := make(map[string]string, len(got.Files))
+			for _, f := range got.Files {
+				files[f.Path] = f.Content
+			}
+			if len(files) != len(tt.want) {
+				t.Errorf("ExportCluster() exported %d files, want %d", len(files), len(tt.want))
+			}
+			for _, p := range tt.want {
+				if _, ok := files[p]; !ok {
+					t.Errorf("ExportCluster() missing file %s", p)
+				}
+			}
+			if !strings.Contains(files["kubeadm.yaml"], "kubernetesVersion: v1.23.6") {
+				t.Errorf("kubeadm.yaml does not pin the kubernetes version:\n%s", files["kubeadm.yaml"])
+			}
+			if !strings.Contains(files["inventory.yaml"], "192.168.10.11") {
+				t.Errorf("inventory.yaml does not list the worker:\n%s", files["inventory.yaml"])
+			}
+		})
+	}
+}
@@ -0,0 +1,572 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+const (
+	// K3s is the name of the offline package of k3s, its configs.tar.gz holds the k3s binary
+	// and the airgap images tarball which k3s imports on start.
+	K3s = "k3s"
+
+	k3sPackages = "k3sPackages"
+	k3sNode     = "k3sNode"
+
+	K3sBinary    = "/usr/local/bin/k3s"
+	K3sConfigDir = "/etc/rancher/k3s"
+	K3sDataDir   = "/var/lib/rancher/k3s"
+
+	k3sServerService = "k3s"
+	k3sAgentService  = "k3s-agent"
+)
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, k3sPackages, version, component.TypeStep), &K3sPackage{}); err != nil {
+		panic(err)
+	}
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, k3sNode, version, component.TypeStep), &K3sNode{}); err != nil {
+		panic(err)
+	}
+}
+
+var (
+	_ component.StepRunnable = (*K3sPackage)(nil)
+	_ component.StepRunnable = (*K3sNode)(nil)
+)
+
+// PackageName returns the name of the offline package of the kubernetes distribution of the cluster.
+func PackageName(t v1.ClusterType) string {
+	if t == v1.ClusterK3s {
+		return K3s
+	}
+	return K8s
+}
+
+// K3sPackage installs the k3s binary of a version, the binary serves kubectl, crictl and ctr as well.
+type K3sPackage struct {
+	Arch    string `json:"arch"`
+	Offline bool   `json:"offline"`
+	Version string `json:"version"`
+	// Proxy is the http proxy of the online downloads, nil to connect directly.
+	Proxy *v1.ProxyEnv `json:"proxy,omitempty"`
+}
+
+// K3sNode configures and starts k3s on a node, the first master initializes the cluster
+// and the other nodes join it through the server.
+type K3sNode struct {
+	NodeRole string `json:"nodeRole"`
+	// Server is the url of the first master, empty on the first master.
+	Server        string   `json:"server"`
+	Token         string   `json:"token"`
+	ClusterCIDR   string   `json:"clusterCIDR"`
+	ServiceCIDR   string   `json:"serviceCIDR"`
+	ClusterDomain string   `json:"clusterDomain"`
+	TLSSANs       []string `json:"tlsSANs"`
+	KubeletArgs   []string `json:"kubeletArgs"`
+	LocalRegistry string   `json:"localRegistry"`
+}
+
+func (stepper *K3sPackage) NewInstance() component.ObjectMeta {
+	return &K3sPackage{}
+}
+
+func (stepper *K3sPackage) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	arch, err := downloader.ResolveArch(stepper.Arch)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := downloader.NewInstance(downloader.WithProxy(ctx, stepper.Proxy), K3s, stepper.Version, arch, !stepper.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = instance.DownloadAndUnpackConfigs(); err != nil {
+		return nil, err
+	}
+	if opts.DryRun {
+		return nil, nil
+	}
+	if err = os.Chmod(K3sBinary, 0755); err != nil {
+		return nil, err
+	}
+	for _, link := range []string{"kubectl", "crictl", "ctr"} {
+		path := filepath.Join(filepath.Dir(K3sBinary), link)
+		_ = os.Remove(path)
+		if err = os.Symlink(K3sBinary, path); err != nil {
+			return nil, err
+		}
+	}
+	logger.Debug("k3s package install successfully", zap.String("version", stepper.Version))
+	return nil, nil
+}
+
+func (stepper *K3sPackage) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	arch, err := downloader.ResolveArch(stepper.Arch)
+	if err != nil {
+		return nil, err
+	}
+	instance, err := downloader.NewInstance(ctx, K3s, stepper.Version, arch, !stepper.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if err = instance.RemoveConfigs(); err != nil {
+		logger.Error("remove k3s package failed", zap.Error(err))
+	}
+	for _, link := range []string{"kubectl", "crictl", "ctr"} {
+		_, _ = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "rm", "-f", filepath.Join(filepath.Dir(K3sBinary), link))
+	}
+	return nil, nil
+}
+
+func (stepper *K3sNode) NewInstance() component.ObjectMeta {
+	return &K3sNode{}
+}
+
+func (stepper *K3sNode) service() string {
+	if stepper.NodeRole == NodeRoleMaster {
+		return k3sServerService
+	}
+	return k3sAgentService
+}
+
+func (stepper *K3sNode) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if err := os.MkdirAll(K3sConfigDir, 0755); err != nil {
+		return nil, err
+	}
+	files := map[string]string{
+		filepath.Join(K3sConfigDir, "config.yaml"):                     k3sConfigTemplate,
+		filepath.Join(KubeletSystemdDir, stepper.service()+".service"): k3sServiceTemplate,
+	}
+	if stepper.LocalRegistry != "" {
+		files[filepath.Join(K3sConfigDir, "registries.yaml")] = k3sRegistriesTemplate
+	}
+	for file, tmpl := range files {
+		tmpl := tmpl
+		// the config holds the token of the cluster
+		perm := os.FileMode(0644)
+		if filepath.Dir(file) == K3sConfigDir {
+			perm = 0600
+		}
+		if err := fileutil.WriteFileWithContext(ctx, file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm, func(w io.Writer) error {
+			_, err := tmplutil.New().RenderTo(w, tmpl, stepper)
+			return err
+		}, opts.DryRun); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Services.DaemonReload()); err != nil {
+		return nil, err
+	}
+	// the service is notified once k3s is ready, so the joining nodes find the server up
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Services.Enable(stepper.service(), true)); err != nil {
+		return nil, err
+	}
+	if stepper.NodeRole == NodeRoleMaster {
+		// kubectl of the agent and of the web terminal reads the kubeconfig of root
+		if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+			fmt.Sprintf("mkdir -p /root/%[1]s && cp -f %[2]s/k3s.yaml /root/%[1]s/config", KubeConfigDir, K3sConfigDir)); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (stepper *K3sNode) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	for _, service := range []string{k3sServerService, k3sAgentService} {
+		if _, err := cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Services.Stop(service)); err != nil {
+			logger.Warn("stop k3s service failed", zap.String("service", service), zap.Error(err))
+		}
+		if _, err := cmdutil.RunCmdSliceWithContext(ctx, opts.DryRun, osutil.Local().Services.Disable(service)); err != nil {
+			logger.Warn("disable k3s service failed", zap.String("service", service), zap.Error(err))
+		}
+	}
+	// the containers keep running when k3s stops, the shims are started from the data dir of k3s
+	if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c", k3sCleanScript); err != nil {
+		logger.Warn("clean k3s node failed", zap.Error(err))
+	}
+	return nil, nil
+}
+
+const k3sCleanScript = `
+pkill -9 -f '` + K3sDataDir + `/data/' || true
+awk '$2 ~ "^/(run/k3s|var/lib/rancher/k3s|var/lib/kubelet)" {print $2}' /proc/self/mounts | sort -r | xargs -r umount
+for link in cni0 flannel.1 flannel-v6.1; do ip link delete $link 2>/dev/null || true; done
+rm -rf /etc/rancher/k3s /var/lib/rancher/k3s /var/lib/kubelet /run/k3s /run/flannel /var/lib/cni /etc/cni/net.d /root/.kube
+rm -f /etc/systemd/system/k3s.service /etc/systemd/system/k3s-agent.service
+systemctl daemon-reload`
+
+const k3sConfigTemplate = `token: "{{.Token}}"
+{{- if .Server}}
+server: "{{.Server}}"
+{{- end}}
+{{- if eq .NodeRole "master"}}
+{{- if not .Server}}
+cluster-init: true
+{{- end}}
+write-kubeconfig-mode: "0600"
+{{- with .ClusterCIDR}}
+cluster-cidr: "{{.}}"
+{{- end}}
+{{- with .ServiceCIDR}}
+service-cidr: "{{.}}"
+{{- end}}
+{{- with .ClusterDomain}}
+cluster-domain: "{{.}}"
+{{- end}}
+{{- with .TLSSANs}}
+tls-san:
+{{- range .}}
+  - "{{.}}"
+{{- end}}
+{{- end}}
+{{- end}}
+{{- with .KubeletArgs}}
+kubelet-arg:
+{{- range .}}
+  - "{{.}}"
+{{- end}}
+{{- end}}
+`
+
+const k3sRegistriesTemplate = `mirrors:
+  docker.io:
+    endpoint:
+      - "http://{{.LocalRegistry}}"
+  "{{.LocalRegistry}}":
+    endpoint:
+      - "http://{{.LocalRegistry}}"
+`
+
+const k3sServiceTemplate = `[Unit]
+Description=Lightweight Kubernetes
+Documentation=https://k3s.io
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+ExecStartPre=-/sbin/modprobe br_netfilter
+ExecStartPre=-/sbin/modprobe overlay
+ExecStart=` + K3sBinary + ` {{if eq .NodeRole "master"}}server{{else}}agent{{end}}
+KillMode=process
+Delegate=yes
+LimitNOFILE=1048576
+LimitNPROC=infinity
+LimitCORE=infinity
+TasksMax=infinity
+TimeoutStartSec=0
+Restart=always
+RestartSec=5s
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// K3sRunnable builds the steps of the clusters running k3s, they share the spec of kubeadm clusters
+// but the container runtime and the cni are the ones embedded in k3s.
+type K3sRunnable v1.Kubeadm
+
+func (runnable *K3sRunnable) GetStep(ctx context.Context, action v1.StepAction) ([]v1.Step, error) {
+	if err := runnable.Validate(); err != nil {
+		return nil, err
+	}
+	metadata := component.GetExtraMetadata(ctx)
+	switch action {
+	case v1.ActionInstall:
+		return runnable.makeInstallSteps(&metadata)
+	case v1.ActionUninstall:
+		return runnable.makeUninstallSteps(&metadata)
+	}
+	return nil, nil
+}
+
+// Validate refuses the settings of the cluster k3s does not support.
+func (runnable *K3sRunnable) Validate() error {
+	if runnable == nil {
+		return fmt.Errorf("kubeadm object is empty")
+	}
+	if len(runnable.Masters) == 0 {
+		return fmt.Errorf("init step error, cluster contains at least one master node")
+	}
+	if t := runnable.KubeComponents.CNI.Type; t != "" && t != "flannel" {
+		return fmt.Errorf("k3s clusters run the embedded flannel, cni %s is not supported", t)
+	}
+	if runnable.ControlPlaneVIP != nil {
+		return fmt.Errorf("control plane vip is not supported by k3s clusters")
+	}
+	if len(runnable.ConfigPatches) > 0 {
+		return fmt.Errorf("kubeadm config patches are not supported by k3s clusters")
+	}
+	return nil
+}
+
+func (runnable *K3sRunnable) makeInstallSteps(metadata *component.ExtraMetadata) ([]v1.Step, error) {
+	kubeadm := (*v1.Kubeadm)(runnable)
+	nodes := utils.UnwrapNodeList(metadata.GetAllNodes())
+	masters := utils.UnwrapNodeList(metadata.Masters)
+
+	var steps []v1.Step
+	envSteps, err := EnvSetupSteps(nodes, kubeadm.KubeComponents.Kubelet.Swap)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, envSteps...)
+	steps = append(steps, TimeSyncSteps(kubeadm.NTPServers, nodes)...)
+	if kubeadm.Networking.HasIPv6() {
+		steps = append(steps, IPv6CheckSteps(nodes)...)
+	}
+
+	pkgSteps, err := runnable.packageSteps("installK3sPackage", kubeadm.KubernetesVersion, v1.ActionInstall, false, nodes)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, pkgSteps...)
+
+	token := strings.ReplaceAll(strutil.GetUUID(), "-", "")
+	server := fmt.Sprintf("https://%s:6443", masters[0].IPv4)
+	step, err := k3sNodeStep("initK3sServer", runnable.node(NodeRoleMaster, "", token), v1.ActionInstall, false, masters[:1])
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, step)
+	// etcd members join one at a time
+	for _, m := range masters[1:] {
+		step, err = k3sNodeStep("joinK3sServer", runnable.node(NodeRoleMaster, server, token), v1.ActionInstall, false, []v1.StepNode{m})
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+	pools, groups := GroupNodesByPool(kubeadm, utils.UnwrapNodeList(metadata.Workers))
+	for _, pool := range pools {
+		agent := runnable.node(NodeRoleWorker, server, token)
+		if p := kubeadm.NodePool(pool); p != nil {
+			for _, k := range sets.StringKeySet(p.KubeletArgs).List() {
+				agent.KubeletArgs = append(agent.KubeletArgs, k+"="+p.KubeletArgs[k])
+			}
+		}
+		step, err = k3sNodeStep("joinK3sAgent", agent, v1.ActionInstall, false, groups[pool])
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, step)
+	}
+
+	labelSteps, err := PatchTaintAndLabelStep(kubeadm.Masters, kubeadm.EffectiveWorkers(), metadata, nil)
+	if err != nil {
+		return nil, err
+	}
+	steps = append(steps, labelSteps...)
+	steps = append(steps, k3sHealthSteps(masters[0])...)
+	return steps, nil
+}
+
+func (runnable *K3sRunnable) makeUninstallSteps(metadata *component.ExtraMetadata) ([]v1.Step, error) {
+	kubeadm := (*v1.Kubeadm)(runnable)
+	nodes := utils.UnwrapNodeList(metadata.GetAllNodes())
+	step, err := k3sNodeStep("removeK3s", &K3sNode{}, v1.ActionUninstall, true, nodes)
+	if err != nil {
+		return nil, err
+	}
+	pkgSteps, err := runnable.packageSteps("removeK3sPackage", kubeadm.KubernetesVersion, v1.ActionUninstall, true, nodes)
+	if err != nil {
+		return nil, err
+	}
+	return append([]v1.Step{step}, pkgSteps...), nil
+}
+
+// UpgradeSteps installs the k3s binary of the version on every node, then restarts the masters
+// one at a time and the workers in batches, each followed by a health gate.
+func (runnable *K3sRunnable) UpgradeSteps(metadata *component.ExtraMetadata, k3sVersion string, workerBatchSize int) ([]v1.Step, error) {
+	masters := utils.UnwrapNodeList(metadata.Masters)
+	master0 := masters[0]
+	steps, err := runnable.packageSteps("DownloadK3sUpgradePackage", k3sVersion, v1.ActionInstall, false,
+		utils.UnwrapNodeList(metadata.GetAllNodes()))
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range masters {
+		hostname := metadata.GetMasterHostname(m.ID)
+		steps = append(steps, v1.Step{
+			ID:        strutil.GetUUID(),
+			Name:      upgradeControlPlanePrefix + hostname,
+			Nodes:     []v1.StepNode{m},
+			Action:    v1.ActionInstall,
+			Timeout:   metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore: false,
+			Commands: []v1.Command{
+				{
+					Type: v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf(`
+kubectl drain %[1]s --ignore-daemonsets || true
+systemctl restart %[2]s
+kubectl uncordon %[1]s || true`, hostname, k3sServerService)},
+				},
+			},
+			RetryTimes: 0,
+		}, healthGateStep("HealthGate-"+hostname, master0, []string{hostname}, nil))
+	}
+	for i, batch := range WorkerBatches(utils.UnwrapNodeList(metadata.Workers), workerBatchSize) {
+		steps = append(steps, workerBatchSteps(i, master0, batch, "systemctl restart "+k3sAgentService, upgradeWorkersPrefix, nil)...)
+	}
+	return steps, nil
+}
+
+func (runnable *K3sRunnable) node(role, server, token string) *K3sNode {
+	node := &K3sNode{
+		NodeRole:      role,
+		Server:        server,
+		Token:         token,
+		LocalRegistry: runnable.LocalRegistry,
+	}
+	if role == NodeRoleMaster {
+		kubeadm := (*v1.Kubeadm)(runnable)
+		node.ClusterCIDR = runnable.Networking.PodSubnet
+		node.ServiceCIDR = runnable.Networking.ServiceSubnet
+		node.ClusterDomain = runnable.Networking.DNSDomain
+		node.TLSSANs = kubeadm.APIServerCertSANs()
+	}
+	return node
+}
+
+func (runnable *K3sRunnable) packageSteps(name, k3sVersion string, action v1.StepAction, errIgnore bool, nodes []v1.StepNode) ([]v1.Step, error) {
+	archs, groups := utils.GroupNodesByArch(nodes)
+	steps := make([]v1.Step, 0, len(archs))
+	for _, arch := range archs {
+		b, err := json.Marshal(&K3sPackage{Arch: arch, Offline: runnable.Offline, Version: k3sVersion, Proxy: (*v1.Kubeadm)(runnable).ProxyEnv(nil)})
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       name,
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  errIgnore,
+			RetryTimes: 1,
+			Nodes:      groups[arch],
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, k3sPackages, version, component.TypeStep),
+					CustomCommand: b,
+				},
+			},
+		})
+	}
+	return steps, nil
+}
+
+func k3sNodeStep(name string, node *K3sNode, action v1.StepAction, errIgnore bool, nodes []v1.StepNode) (v1.Step, error) {
+	b, err := json.Marshal(node)
+	if err != nil {
+		return v1.Step{}, err
+	}
+	return v1.Step{
+		ID:         strutil.GetUUID(),
+		Name:       name,
+		Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+		ErrIgnore:  errIgnore,
+		RetryTimes: 1,
+		Nodes:      nodes,
+		Action:     action,
+		Commands: []v1.Command{
+			{
+				Type:          v1.CommandCustom,
+				Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, k3sNode, version, component.TypeStep),
+				CustomCommand: b,
+			},
+		},
+	}, nil
+}
+
+// k3sHealthSteps waits for the nodes to be ready, then registers the service account of kc-server
+// with a token secret, which kubernetes no longer creates for service accounts.
+func k3sHealthSteps(master0 v1.StepNode) []v1.Step {
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "checkHealth",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 0,
+			Nodes:      []v1.StepNode{master0},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", "kubectl wait --for=condition=Ready nodes --all --timeout=600s"},
+				},
+			},
+		},
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "registerServiceAccount",
+			Timeout:    metav1.Duration{Duration: 2 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      []v1.StepNode{master0},
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", k3sServiceAccountScript},
+				},
+			},
+		},
+	}
+}
+
+const k3sServiceAccountScript = `
+kubectl -n kube-system create sa kc-server --dry-run=client -o yaml | kubectl apply -f -
+kubectl create clusterrolebinding kc-server --clusterrole=cluster-admin --serviceaccount=kube-system:kc-server --dry-run=client -o yaml | kubectl apply -f -
+cat <<EOF | kubectl apply -f -
+apiVersion: v1
+kind: Secret
+metadata:
+  name: kc-server-token
+  namespace: kube-system
+  annotations:
+    kubernetes.io/service-account.name: kc-server
+type: kubernetes.io/service-account-token
+EOF
+kubectl -n kube-system patch sa kc-server -p '{"secrets":[{"name":"kc-server-token"}]}'`
@@ -0,0 +1,142 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+func TestK3sSteps(t *testing.T) {
+	runnable := &K3sRunnable{
+		KubernetesVersion: "v1.26.4+k3s1",
+		Masters:           v1.WorkerNodeList{{ID: "m1"}, {ID: "m2"}, {ID: "m3"}},
+		Workers:           v1.WorkerNodeList{{ID: "w1"}},
+		Networking:        v1.Networking{PodSubnet: "172.25.0.0/16", ServiceSubnet: "10.96.0.0/12", DNSDomain: "cluster.local"},
+	}
+	metadata := component.ExtraMetadata{
+		ClusterName: "edge",
+		Masters: component.NodeList{
+			{ID: "m1", IPv4: "192.168.10.10", Hostname: "master-1", Arch: "amd64"},
+			{ID: "m2", IPv4: "192.168.10.11", Hostname: "master-2", Arch: "amd64"},
+			{ID: "m3", IPv4: "192.168.10.12", Hostname: "master-3", Arch: "amd64"},
+		},
+		Workers: component.NodeList{{ID: "w1", IPv4: "192.168.10.13", Hostname: "worker-1", Arch: "arm64"}},
+	}
+	steps, err := runnable.GetStep(component.WithExtraMetadata(context.TODO(), metadata), v1.ActionInstall)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	nodes := make(map[string]*K3sNode)
+	for _, step := range steps {
+		if !strings.Contains(step.Name, "K3s") {
+			continue
+		}
+		names = append(names, step.Name)
+		if strings.HasPrefix(step.Name, "installK3sPackage") {
+			continue
+		}
+		node := &K3sNode{}
+		if err := json.Unmarshal(step.Commands[0].CustomCommand, node); err != nil {
+			t.Fatal(err)
+		}
+		nodes[step.Nodes[0].ID] = node
+	}
+	want := []string{"installK3sPackage", "installK3sPackage", "initK3sServer", "joinK3sServer", "joinK3sServer", "joinK3sAgent"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("install steps are %v, want %v", names, want)
+	}
+	if n := nodes["m1"]; n.Server != "" || n.Token == "" || n.ClusterCIDR != "172.25.0.0/16" {
+		t.Errorf("unexpected first server %+v", n)
+	}
+	for _, id := range []string{"m2", "w1"} {
+		if n := nodes[id]; n.Server != "https://192.168.10.10:6443" || n.Token != nodes["m1"].Token {
+			t.Errorf("node %s does not join the first server: %+v", id, n)
+		}
+	}
+	if nodes["w1"].NodeRole != NodeRoleWorker {
+		t.Errorf("worker joins as %s", nodes["w1"].NodeRole)
+	}
+
+	steps, err = runnable.UpgradeSteps(&metadata, "v1.27.1+k3s1", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkg := &K3sPackage{}
+	if err := json.Unmarshal(steps[0].Commands[0].CustomCommand, pkg); err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Version != "v1.27.1+k3s1" {
+		t.Errorf("upgrade downloads k3s %s", pkg.Version)
+	}
+	if got := steps[2].Name; got != upgradeControlPlanePrefix+"master-1" {
+		t.Errorf("first master step is %s", got)
+	}
+}
+
+func TestK3sValidate(t *testing.T) {
+	masters := v1.WorkerNodeList{{ID: "m1"}}
+	tests := []struct {
+		name     string
+		runnable *K3sRunnable
+		wantErr  bool
+	}{
+		{"valid", &K3sRunnable{Masters: masters}, false},
+		{"no masters", &K3sRunnable{}, true},
+		{"calico", &K3sRunnable{Masters: masters, KubeComponents: v1.KubeComponents{CNI: v1.CNI{Type: "calico"}}}, true},
+		{"vip", &K3sRunnable{Masters: masters, ControlPlaneVIP: &v1.ControlPlaneVIP{Address: "192.168.10.100"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.runnable.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderK3sConfig(t *testing.T) {
+	node := &K3sNode{
+		NodeRole:    NodeRoleMaster,
+		Token:       "secret",
+		ClusterCIDR: "172.25.0.0/16",
+		TLSSANs:     []string{"edge.example.com"},
+		KubeletArgs: []string{"max-pods=50"},
+	}
+	got, err := tmplutil.New().Render(k3sConfigTemplate, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`token: "secret"`, "cluster-init: true", `cluster-cidr: "172.25.0.0/16"`, `- "edge.example.com"`, `- "max-pods=50"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("config misses %s:\n%s", want, got)
+		}
+	}
+	node.Server = "https://192.168.10.10:6443"
+	if got, _ = tmplutil.New().Render(k3sConfigTemplate, node); strings.Contains(got, "cluster-init") {
+		t.Errorf("joining server inits the cluster:\n%s", got)
+	}
+}
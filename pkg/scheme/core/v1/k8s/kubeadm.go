@@ -24,7 +24,6 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -41,6 +40,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/ipvsutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
 	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
 )
@@ -93,6 +93,10 @@ type Package struct {
 	Version       string `json:"version"`
 	CriType       string `json:"criType"`
 	LocalRegistry string `json:"localRegistry"`
+	// Proxy is the http proxy of the online downloads, nil to connect directly.
+	Proxy *v1.ProxyEnv `json:"proxy,omitempty"`
+	// kubeadm resolves the container runtime of the node pools.
+	kubeadm *v1.Kubeadm
 }
 
 type KubeadmConfig struct {
@@ -110,6 +114,11 @@ type KubeadmConfig struct {
 	CertSANs             []string      `json:"certSANs"`
 	LocalRegistry        string        `json:"localRegistry"`
 	WorkerNodeVip        string        `json:"workerNodeVip"`
+	// Patches are applied to the rendered configuration.
+	Patches v1.KubeadmConfigPatches `json:"patches,omitempty"`
+	// AdvertiseAddress is the address the apiserver advertises, the first master resolves
+	// its default ipv6 address when the cluster is ipv6-only.
+	AdvertiseAddress string `json:"advertiseAddress,omitempty"`
 }
 
 type ControlPlane struct {
@@ -129,14 +138,24 @@ type ClusterNode struct {
 	APIServerDomainName string
 	JoinMasterIP        string
 	EtcdDataPath        string
+	// ControlPlaneVIP replaces the ipvs rules of the worker nodes when the masters announce a vip.
+	ControlPlaneVIP string
+	// ContainerRuntime of the workers, it may differ from the one of the cluster in a node pool.
+	ContainerRuntime string
+	// IPv6Only makes the masters advertise their default ipv6 address.
+	IPv6Only bool
+	kubeadm  *v1.Kubeadm
 }
 
 type CNI v1.CNI
 
-type Health struct{}
+type Health struct {
+	toolImages *v1.ToolImages
+}
 
 type Container struct {
 	CriType string
+	kubeadm *v1.Kubeadm
 }
 
 type Kubectl struct{}
@@ -145,13 +164,16 @@ func (stepper *Package) NewInstance() component.ObjectMeta {
 	return &Package{}
 }
 
-func (stepper *Package) setParams() {
-	stepper.Arch = component.OSArchAMD64
+func (stepper *Package) setParams() (err error) {
+	stepper.Arch, err = downloader.ResolveArch(stepper.Arch)
+	return err
 }
 
 func (stepper *Package) Install(ctx context.Context, opts component.Options) ([]byte, error) {
-	stepper.setParams()
-	instance, err := downloader.NewInstance(ctx, K8s, stepper.Version, runtime.GOARCH, !stepper.Offline, opts.DryRun)
+	if err := stepper.setParams(); err != nil {
+		return nil, err
+	}
+	instance, err := downloader.NewInstance(downloader.WithProxy(ctx, stepper.Proxy), K8s, stepper.Version, stepper.Arch, !stepper.Offline, opts.DryRun)
 	if err != nil {
 		return nil, err
 	}
@@ -184,12 +206,14 @@ func (stepper *Package) Install(ctx context.Context, opts component.Options) ([]
 }
 
 func (stepper *Package) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
-	stepper.setParams()
+	if err := stepper.setParams(); err != nil {
+		return nil, err
+	}
 	if err := stepper.disableKubeletService(ctx, opts.DryRun); err != nil {
 		return nil, err
 	}
 	// remove related binary configuration files
-	instance, err := downloader.NewInstance(ctx, K8s, stepper.Version, runtime.GOARCH, !stepper.Offline, opts.DryRun)
+	instance, err := downloader.NewInstance(ctx, K8s, stepper.Version, stepper.Arch, !stepper.Offline, opts.DryRun)
 	if err != nil {
 		return nil, err
 	}
@@ -213,11 +237,11 @@ func (stepper *Package) enableKubeletService(ctx context.Context, dryRun bool) e
 	}
 
 	// enable systemd containerd service
-	_, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "daemon-reload")
+	_, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.DaemonReload())
 	if err != nil {
 		return err
 	}
-	_, err = cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "enable", "kubelet", "--now")
+	_, err = cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Enable("kubelet", true))
 	if err != nil {
 		return err
 	}
@@ -227,10 +251,10 @@ func (stepper *Package) enableKubeletService(ctx context.Context, dryRun bool) e
 
 func (stepper *Package) disableKubeletService(ctx context.Context, dryRun bool) error {
 	// The following command execution error is ignored
-	if _, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "stop", "kubelet"); err != nil {
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Stop("kubelet")); err != nil {
 		logger.Warn("stop systemd kubelet service failed", zap.Error(err))
 	}
-	if _, err := cmdutil.RunCmdWithContext(ctx, dryRun, "systemctl", "disable", "kubelet"); err != nil {
+	if _, err := cmdutil.RunCmdSliceWithContext(ctx, dryRun, osutil.Local().Services.Disable("kubelet")); err != nil {
 		logger.Warn("disable systemd kubelet service failed", zap.Error(err))
 	}
 	return nil
@@ -250,6 +274,13 @@ func (stepper KubeadmConfig) Render(ctx context.Context, opts component.Options)
 	if stepper.Kubelet.RootDir == "" {
 		stepper.Kubelet.RootDir = KubeletDefaultDataDir
 	}
+	if stepper.Network.IPv6Only() && stepper.AdvertiseAddress == "" {
+		ip, err := netutil.GetDefaultIP(false)
+		if err != nil {
+			return fmt.Errorf("get default ipv6 address: %v", err)
+		}
+		stepper.AdvertiseAddress = ip.String()
+	}
 
 	if err := os.MkdirAll(ManifestDir, 0755); err != nil {
 		return err
@@ -261,10 +292,38 @@ func (stepper KubeadmConfig) Render(ctx context.Context, opts component.Options)
 
 func (stepper *KubeadmConfig) renderTo(w io.Writer) error {
 	at := tmplutil.New()
-	_, err := at.RenderTo(w, kubeadmTemplate, stepper)
+	config, err := at.Render(kubeadmTemplate, stepper)
+	if err != nil {
+		return err
+	}
+	patched, err := stepper.Patches.Apply([]byte(config))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(patched)
 	return err
 }
 
+// ValidatePatches renders the configuration to make sure every patch applies.
+func (stepper *KubeadmConfig) ValidatePatches() error {
+	if err := stepper.Patches.Validate(); err != nil {
+		return err
+	}
+	if len(stepper.Patches) == 0 {
+		return nil
+	}
+	cfg := *stepper
+	apiVersion, err := cfg.matchClusterConfigAPIVersion()
+	if err != nil {
+		return err
+	}
+	cfg.ClusterConfigAPIVersion = apiVersion
+	if cfg.Kubelet.RootDir == "" {
+		cfg.Kubelet.RootDir = KubeletDefaultDataDir
+	}
+	return cfg.renderTo(io.Discard)
+}
+
 func (stepper *KubeadmConfig) matchClusterConfigAPIVersion() (string, error) {
 	version := stepper.KubernetesVersion
 
@@ -319,7 +378,8 @@ func (stepper *ControlPlane) Install(ctx context.Context, opts component.Options
 		return nil, err
 	}
 
-	ec, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "kubeadm", "init", "--config", "/tmp/.k8s/kubeadm.yaml", "--upload-certs")
+	ec, err := cmdutil.RunCmdWithStdout(ctx, opts.DryRun, newKubeadmPhaseWriter(component.GetProgressReporter(ctx)),
+		"kubeadm", "init", "--config", "/tmp/.k8s/kubeadm.yaml", "--upload-certs")
 	if err != nil {
 		logger.Error("run kubeadm init error", zap.Error(err))
 		return nil, err
@@ -483,7 +543,15 @@ func (stepper *ClusterNode) Install(ctx context.Context, opts component.Options)
 		}
 
 		masterJoinCmd := strings.Split(cmds[0], " ")
-		_, err = cmdutil.RunCmdWithContext(ctx, opts.DryRun, masterJoinCmd[0], masterJoinCmd[1:]...)
+		if stepper.IPv6Only {
+			ip, err := netutil.GetDefaultIP(false)
+			if err != nil {
+				return nil, fmt.Errorf("get default ipv6 address: %v", err)
+			}
+			masterJoinCmd = append(masterJoinCmd, "--apiserver-advertise-address", ip.String())
+		}
+		_, err = cmdutil.RunCmdWithStdout(ctx, opts.DryRun, newKubeadmPhaseWriter(component.GetProgressReporter(ctx)),
+			masterJoinCmd[0], masterJoinCmd[1:]...)
 		if err != nil {
 			return nil, err
 		}
@@ -505,17 +573,22 @@ func (stepper *ClusterNode) Install(ctx context.Context, opts component.Options)
 		}
 	}
 	if stepper.NodeRole == NodeRoleWorker {
-		workerJoinCmd := strings.Split(cmds[1], " ")
+		workerJoinCmd := strings.Split(criJoinCmd(cmds[1], stepper.ContainerRuntime), " ")
 		hosts.AddHost(stepper.WorkerNodeVIP, stepper.APIServerDomainName)
 		if len(stepper.Masters) == 1 {
 			hosts.AddHost(stepper.JoinMasterIP, stepper.APIServerDomainName)
 		}
+		ipvs := len(stepper.Masters) > 1 && stepper.WorkerNodeVIP != ""
+		if stepper.ControlPlaneVIP != "" {
+			hosts.AddHost(stepper.ControlPlaneVIP, stepper.APIServerDomainName)
+			ipvs = false
+		}
 
 		if err := hosts.Save(); err != nil {
 			return nil, err
 		}
 
-		if len(stepper.Masters) > 1 && stepper.WorkerNodeVIP != "" {
+		if ipvs {
 			var rsList []ipvsutil.RealServer
 			for _, ip := range stepper.Masters {
 				dest := ipvsutil.RealServer{
@@ -540,11 +613,12 @@ func (stepper *ClusterNode) Install(ctx context.Context, opts component.Options)
 				return nil, err
 			}
 		}
-		if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, workerJoinCmd[0], workerJoinCmd[1:]...); err != nil {
+		if _, err := cmdutil.RunCmdWithStdout(ctx, opts.DryRun, newKubeadmPhaseWriter(component.GetProgressReporter(ctx)),
+			workerJoinCmd[0], workerJoinCmd[1:]...); err != nil {
 			return nil, err
 		}
 
-		if len(stepper.Masters) > 1 && stepper.WorkerNodeVIP != "" && !opts.DryRun {
+		if ipvs && !opts.DryRun {
 			err = stepper.generatesIPSOCareStaticPod(ctx)
 			if err != nil {
 				return nil, err
@@ -577,43 +651,18 @@ func (stepper *CNI) NewInstance() component.ObjectMeta {
 }
 
 func (stepper *CNI) Render(ctx context.Context, opts component.Options) error {
-	switch stepper.Type {
-	case CniCalico:
-		return stepper.renderCalico(ctx, opts.DryRun)
-	default:
-		return fmt.Errorf("unsupported %s cni type", stepper.Type)
-	}
-}
-
-func (stepper *CNI) renderCalicoTo(w io.Writer) error {
-	at := tmplutil.New()
-	calicoTemp, err := stepper.CalicoTemplate()
+	plugin, err := GetCNIPlugin(stepper.Type)
 	if err != nil {
 		return err
 	}
-	if _, err := at.RenderTo(w, calicoTemp, stepper); err != nil {
-		return err
-	}
-	return nil
-}
-
-func (stepper *CNI) CalicoTemplate() (string, error) {
-	switch stepper.Calico.Version {
-	case "v3.11.2":
-		return calicoV3112, nil
-	case "v3.21.2":
-		return calicoV3212, nil
-	}
-	return "", fmt.Errorf("calico no support %s version", stepper.Calico.Version)
-}
-
-func (stepper *CNI) renderCalico(ctx context.Context, dryRun bool) error {
 	if err := os.MkdirAll(ManifestDir, 0755); err != nil {
 		return err
 	}
 	manifestFile := filepath.Join(ManifestDir, "cni.yaml")
 	return fileutil.WriteFileWithContext(ctx, manifestFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644,
-		stepper.renderCalicoTo, dryRun)
+		func(w io.Writer) error {
+			return plugin.Render(w, (*v1.CNI)(stepper))
+		}, opts.DryRun)
 }
 
 func (stepper *Health) NewInstance() component.ObjectMeta {
@@ -713,6 +762,8 @@ func (stepper *Kubectl) Uninstall(ctx context.Context, opts component.Options) (
 
 type KubectlTerminal struct {
 	ImageRegistryAddr string
+
+	toolImages *v1.ToolImages
 }
 
 func (c *KubectlTerminal) NewInstance() component.ObjectMeta {
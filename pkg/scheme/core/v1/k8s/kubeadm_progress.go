@@ -0,0 +1,84 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+)
+
+// kubeadmPhases are the phases of kubeadm init and join worth reporting,
+// kubeadm prefixes the lines it prints in a phase with the phase name.
+var kubeadmPhases = sets.NewString(
+	"preflight",
+	"certs",
+	"kubeconfig",
+	"kubelet-start",
+	"control-plane",
+	"etcd",
+	"wait-control-plane",
+	"upload-config",
+	"upload-certs",
+	"mark-control-plane",
+	"bootstrap-token",
+	"kubelet-finalize",
+	"addons",
+	"download-certs",
+	"check-etcd",
+)
+
+var kubeadmPhaseLine = regexp.MustCompile(`^\[([a-z-]+)\]\s*(.*)$`)
+
+// kubeadmPhaseWriter parses kubeadm output written to it and reports every phase entered.
+type kubeadmPhaseWriter struct {
+	report component.ProgressReporter
+	phase  string
+	buf    []byte
+}
+
+func newKubeadmPhaseWriter(report component.ProgressReporter) *kubeadmPhaseWriter {
+	return &kubeadmPhaseWriter{report: report}
+}
+
+func (w *kubeadmPhaseWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.parseLine(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *kubeadmPhaseWriter) parseLine(line string) {
+	m := kubeadmPhaseLine.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil || !kubeadmPhases.Has(m[1]) || m[1] == w.phase {
+		return
+	}
+	w.phase = m[1]
+	w.report(m[1], m[2])
+}
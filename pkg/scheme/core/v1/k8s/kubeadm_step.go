@@ -31,6 +31,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
 )
 
@@ -75,21 +76,11 @@ func (runnable *KubeadmRunnable) Validate() error {
 		return fmt.Errorf("init step error, cluster contains at least one master node")
 	}
 
-	// check dualStack and ipv4
-	switch runnable.KubeComponents.CNI.Type {
-	case "calico":
-		if runnable.KubeComponents.CNI.Calico.DualStack &&
-			(runnable.KubeComponents.CNI.PodIPv4CIDR == "" || runnable.KubeComponents.CNI.PodIPv6CIDR == "") {
-			return fmt.Errorf("ipv4 and ipv6 cidr are both required when calico dual-stack is on")
-		}
-		if !runnable.KubeComponents.CNI.Calico.DualStack &&
-			runnable.KubeComponents.CNI.PodIPv4CIDR == "" &&
-			runnable.KubeComponents.CNI.PodIPv6CIDR == "" {
-			return fmt.Errorf("calico ipv4 and ipv6 must have at least one")
-		}
+	plugin, err := GetCNIPlugin(runnable.KubeComponents.CNI.Type)
+	if err != nil {
+		return err
 	}
-
-	return nil
+	return plugin.Validate(&runnable.KubeComponents.CNI)
 }
 
 func (runnable *KubeadmRunnable) GetInstallSteps(ctx context.Context) ([]v1.Step, error) {
@@ -133,11 +124,15 @@ func (runnable *KubeadmRunnable) makeInstallSteps(metadata *component.ExtraMetad
 	kubeadm := (*v1.Kubeadm)(runnable)
 
 	var installSteps []v1.Step
-	steps, err := EnvSetupSteps(nodes)
+	steps, err := EnvSetupSteps(nodes, kubeadm.KubeComponents.Kubelet.Swap)
 	if err != nil {
 		return nil, err
 	}
 	installSteps = append(installSteps, steps...)
+	installSteps = append(installSteps, TimeSyncSteps(kubeadm.NTPServers, nodes)...)
+	if kubeadm.Networking.HasIPv6() {
+		installSteps = append(installSteps, IPv6CheckSteps(nodes)...)
+	}
 
 	pack := Package{}
 	steps, err = pack.InitStepper(kubeadm).InstallSteps(nodes)
@@ -161,6 +156,7 @@ func (runnable *KubeadmRunnable) makeInstallSteps(metadata *component.ExtraMetad
 	installSteps = append(installSteps, steps...)
 
 	if len(kubeadm.Masters) > 1 {
+		installSteps = append(installSteps, KubeletArgsSteps(kubeadm, masters[1:])...)
 		cluNode := ClusterNode{}
 		steps, err = cluNode.InitStepper(kubeadm, metadata).InstallSteps(NodeRoleMaster, utils.UnwrapNodeList(metadata.Masters)[1:])
 		if err != nil {
@@ -168,7 +164,17 @@ func (runnable *KubeadmRunnable) makeInstallSteps(metadata *component.ExtraMetad
 		}
 		installSteps = append(installSteps, steps...)
 	}
+	// the vip comes up once every master runs, before the workers join through it
+	if kubeadm.ControlPlaneVIP != nil {
+		vip := ControlPlaneVIP{}
+		steps, err = vip.InitStepper(kubeadm, metadata).InstallSteps(masters)
+		if err != nil {
+			return nil, err
+		}
+		installSteps = append(installSteps, steps...)
+	}
 	if len(kubeadm.Workers) > 0 {
+		installSteps = append(installSteps, KubeletArgsSteps(kubeadm, utils.UnwrapNodeList(metadata.Workers))...)
 		cluNode := ClusterNode{}
 		steps, err = cluNode.InitStepper(kubeadm, metadata).InstallSteps(NodeRoleWorker, utils.UnwrapNodeList(metadata.Workers))
 		if err != nil {
@@ -178,13 +184,13 @@ func (runnable *KubeadmRunnable) makeInstallSteps(metadata *component.ExtraMetad
 	}
 
 	c := CNI{}
-	steps, err = c.InitStepper(kubeadm).InstallSteps([]v1.StepNode{masters[0]})
+	steps, err = c.InitStepper(kubeadm).InstallSteps([]v1.StepNode{masters[0]}, kubeadm.ToolImages)
 	if err != nil {
 		return nil, err
 	}
 	installSteps = append(installSteps, steps...)
 
-	steps, err = PatchTaintAndLabelStep(kubeadm.Masters, kubeadm.Workers, metadata)
+	steps, err = PatchTaintAndLabelStep(kubeadm.Masters, kubeadm.EffectiveWorkers(), metadata, kubeadm.ToolImages)
 	if err != nil {
 		return nil, err
 	}
@@ -223,6 +229,15 @@ func (runnable *KubeadmRunnable) makeUninstallSteps(metadata *component.ExtraMet
 	}
 	uninstallSteps = append(uninstallSteps, steps...)
 
+	if kubeadm.ControlPlaneVIP != nil {
+		vip := ControlPlaneVIP{}
+		steps, err = vip.InitStepper(kubeadm, metadata).UninstallSteps(masters)
+		if err != nil {
+			return nil, err
+		}
+		uninstallSteps = append(uninstallSteps, steps...)
+	}
+
 	// exec kubeadm reset
 	steps, err = KubeadmReset(nodes)
 	if err != nil {
@@ -292,50 +307,58 @@ func (stepper *Package) InitStepper(kubeadm *v1.Kubeadm) *Package {
 	stepper.Version = kubeadm.KubernetesVersion
 	stepper.CriType = kubeadm.ContainerRuntime.Type.String()
 	stepper.LocalRegistry = kubeadm.LocalRegistry
+	// downloads only reach the static servers, the node ips are not needed to bypass the proxy
+	stepper.Proxy = kubeadm.ProxyEnv(nil)
+	stepper.kubeadm = kubeadm
 	return stepper
 }
 
 func (stepper *Package) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
-	bytes, err := json.Marshal(&stepper)
-	if err != nil {
-		return nil, err
-	}
-
-	return []v1.Step{
-		{
-			ID:         strutil.GetUUID(),
-			Name:       "installPackages",
-			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
-			ErrIgnore:  false,
-			RetryTimes: 1,
-			Nodes:      nodes,
-			Action:     v1.ActionInstall,
-			Commands: []v1.Command{
-				{
-					Type:          v1.CommandCustom,
-					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, packages, version, component.TypeStep),
-					CustomCommand: bytes,
-				},
-			},
-		},
-	}, nil
+	return stepper.poolSteps(nodes, v1.ActionInstall)
 }
 
 func (stepper *Package) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
-	bytes, err := json.Marshal(stepper)
-	if err != nil {
-		return nil, err
+	return stepper.poolSteps(nodes, v1.ActionUninstall)
+}
+
+// poolSteps loads the images into the container runtime of the node pool of every node.
+func (stepper *Package) poolSteps(nodes []v1.StepNode, action v1.StepAction) ([]v1.Step, error) {
+	if stepper.kubeadm == nil || len(stepper.kubeadm.NodePools) == 0 {
+		return stepper.archSteps(nodes, action)
 	}
+	criType := stepper.CriType
+	defer func() { stepper.CriType = criType }()
+	var steps []v1.Step
+	pools, groups := GroupNodesByPool(stepper.kubeadm, nodes)
+	for _, pool := range pools {
+		stepper.CriType = stepper.kubeadm.ContainerRuntimeOf(groups[pool][0].ID).Type.String()
+		s, err := stepper.archSteps(groups[pool], action)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s...)
+	}
+	return steps, nil
+}
 
-	return []v1.Step{
-		{
+// archSteps hands every group of nodes the package bundle of its own architecture.
+func (stepper *Package) archSteps(nodes []v1.StepNode, action v1.StepAction) ([]v1.Step, error) {
+	archs, groups := utils.GroupNodesByArch(nodes)
+	steps := make([]v1.Step, 0, len(archs))
+	for _, arch := range archs {
+		stepper.Arch = arch
+		bytes, err := json.Marshal(stepper)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, v1.Step{
 			ID:         strutil.GetUUID(),
 			Name:       "installPackages",
 			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
 			ErrIgnore:  false,
 			RetryTimes: 1,
-			Nodes:      nodes,
-			Action:     v1.ActionUninstall,
+			Nodes:      groups[arch],
+			Action:     action,
 			Commands: []v1.Command{
 				{
 					Type:          v1.CommandCustom,
@@ -343,8 +366,10 @@ func (stepper *Package) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 					CustomCommand: bytes,
 				},
 			},
-		},
-	}, nil
+		})
+	}
+	stepper.Arch = ""
+	return steps, nil
 }
 
 func (stepper *KubeadmConfig) InitStepper(kubeadm *v1.Kubeadm, metadata *component.ExtraMetadata) *KubeadmConfig {
@@ -360,9 +385,10 @@ func (stepper *KubeadmConfig) InitStepper(kubeadm *v1.Kubeadm, metadata *compone
 	stepper.ClusterName = metadata.ClusterName
 	stepper.KubernetesVersion = kubeadm.KubernetesVersion
 	stepper.ControlPlaneEndpoint = cpEndpoint
-	stepper.CertSANs = kubeadm.CertSANs
+	stepper.CertSANs = kubeadm.APIServerCertSANs()
 	stepper.LocalRegistry = kubeadm.LocalRegistry
 	stepper.WorkerNodeVip = kubeadm.WorkerNodeVip
+	stepper.Patches = kubeadm.ConfigPatches
 
 	return stepper
 }
@@ -468,11 +494,37 @@ func (stepper *ClusterNode) InitStepper(kubeadm *v1.Kubeadm, metadata *component
 	stepper.APIServerDomainName = apiServerDomain
 	stepper.JoinMasterIP = metadata.Masters[0].IPv4
 	stepper.EtcdDataPath = kubeadm.KubeComponents.Etcd.DataDir
+	stepper.ControlPlaneVIP = ""
+	if kubeadm.ControlPlaneVIP != nil {
+		stepper.ControlPlaneVIP = kubeadm.ControlPlaneVIP.Address
+	}
+	stepper.ContainerRuntime = ""
+	stepper.IPv6Only = kubeadm.Networking.IPv6Only()
+	stepper.kubeadm = kubeadm
 
 	return stepper
 }
 
 func (stepper *ClusterNode) InstallSteps(role string, nodes []v1.StepNode) ([]v1.Step, error) {
+	if role != NodeRoleWorker || stepper.kubeadm == nil || len(stepper.kubeadm.NodePools) == 0 {
+		return stepper.joinSteps(role, nodes)
+	}
+	// the workers of every pool join with the socket of their own container runtime
+	var steps []v1.Step
+	pools, groups := GroupNodesByPool(stepper.kubeadm, nodes)
+	for _, pool := range pools {
+		stepper.ContainerRuntime = stepper.kubeadm.ContainerRuntimeOf(groups[pool][0].ID).Type.String()
+		s, err := stepper.joinSteps(role, groups[pool])
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s...)
+	}
+	stepper.ContainerRuntime = ""
+	return steps, nil
+}
+
+func (stepper *ClusterNode) joinSteps(role string, nodes []v1.StepNode) ([]v1.Step, error) {
 	stepper.setRole(role)
 	bytes, err := json.Marshal(stepper)
 	if err != nil {
@@ -509,35 +561,12 @@ func (stepper *CNI) InitStepper(kubeadm *v1.Kubeadm) *CNI {
 	return stepper
 }
 
-func (stepper *CNI) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
-	bytes, err := json.Marshal(stepper)
+func (stepper *CNI) InstallSteps(nodes []v1.StepNode, images *v1.ToolImages) ([]v1.Step, error) {
+	plugin, err := GetCNIPlugin(stepper.Type)
 	if err != nil {
 		return nil, err
 	}
-
-	return []v1.Step{
-		{
-			ID:         strutil.GetUUID(),
-			Name:       "installCNI",
-			Timeout:    metav1.Duration{Duration: 1 * time.Minute},
-			ErrIgnore:  false,
-			RetryTimes: 1,
-			Nodes:      nodes,
-			Commands: []v1.Command{
-				{
-					Type: v1.CommandTemplateRender,
-					Template: &v1.TemplateCommand{
-						Identity: fmt.Sprintf(component.RegisterTemplateKeyFormat, cni, version, component.TypeTemplate),
-						Data:     bytes,
-					},
-				},
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"kubectl", "apply", "-f", filepath.Join(ManifestDir, "cni.yaml")},
-				},
-			},
-		},
-	}, nil
+	return plugin.RenderSteps((*v1.CNI)(stepper), nodes, images)
 }
 
 func (stepper *CNI) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
@@ -545,6 +574,7 @@ func (stepper *CNI) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 }
 
 func (stepper *Health) InitStepper(kubeadm *v1.Kubeadm) *Health {
+	stepper.toolImages = kubeadm.ToolImages
 	return stepper
 }
 
@@ -579,14 +609,8 @@ func (stepper *Health) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 			Nodes:      nodes,
 			Action:     v1.ActionInstall,
 			Commands: []v1.Command{
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"kubectl", "create", "sa", "kc-server", "-n", "kube-system"},
-				},
-				{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"kubectl", "create", "clusterrolebinding", "kc-server", "--clusterrole=cluster-admin", "--serviceaccount=kube-system:kc-server"},
-				},
+				kubectlCommand(stepper.toolImages, "create", "sa", "kc-server", "-n", "kube-system"),
+				kubectlCommand(stepper.toolImages, "create", "clusterrolebinding", "kc-server", "--clusterrole=cluster-admin", "--serviceaccount=kube-system:kc-server"),
 			},
 		}}, nil
 }
@@ -664,6 +688,7 @@ func (stepper *Health) UninstallSteps(proxy *v1.KubeProxy, nodes ...v1.StepNode)
 
 func (stepper *Container) InitStepper(kubeadm *v1.Kubeadm) *Container {
 	stepper.CriType = kubeadm.ContainerRuntime.Type.String()
+	stepper.kubeadm = kubeadm
 	return stepper
 }
 
@@ -672,6 +697,25 @@ func (stepper *Container) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 }
 
 func (stepper *Container) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
+	if stepper.kubeadm == nil || len(stepper.kubeadm.NodePools) == 0 {
+		return stepper.cleanSteps(nodes)
+	}
+	criType := stepper.CriType
+	defer func() { stepper.CriType = criType }()
+	var steps []v1.Step
+	pools, groups := GroupNodesByPool(stepper.kubeadm, nodes)
+	for _, pool := range pools {
+		stepper.CriType = stepper.kubeadm.ContainerRuntimeOf(groups[pool][0].ID).Type.String()
+		s, err := stepper.cleanSteps(groups[pool])
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s...)
+	}
+	return steps, nil
+}
+
+func (stepper *Container) cleanSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 	b, err := json.Marshal(stepper)
 	if err != nil {
 		return nil, err
@@ -730,24 +774,50 @@ func (stepper *Kubectl) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
 	}, nil
 }
 
-func EnvSetupSteps(nodes []v1.StepNode) ([]v1.Step, error) {
-	var steps []v1.Step
-	steps = append(steps, v1.Step{
-		ID:         strutil.GetUUID(),
-		Name:       "nodeEnvSetup",
-		Timeout:    metav1.Duration{Duration: 10 * time.Second},
-		ErrIgnore:  false,
-		RetryTimes: 1,
-		Nodes:      nodes,
-		Action:     v1.ActionInstall,
-		Commands: []v1.Command{
-			{
-				Type: v1.CommandShell,
-				ShellCommand: []string{"/bin/bash", "-c", `
-systemctl stop firewalld || true
-systemctl disable firewalld || true
+// nodeEnvSetupScript prepares the kernel modules, sysctl, limits and swap kubernetes nodes require.
+func nodeEnvSetupScript(dist *osutil.OS, swap v1.Swap) string {
+	script := fmt.Sprintf(`
+%s || true
+%s || true`, strings.Join(dist.Services.Stop(dist.Firewall), " "), strings.Join(dist.Services.Disable(dist.Firewall), " "))
+	if dist.SELinux {
+		script += `
 setenforce 0
-sed -i s/^SELINUX=.*$/SELINUX=disabled/ /etc/selinux/config
+sed -i s/^SELINUX=.*$/SELINUX=disabled/ /etc/selinux/config`
+	}
+	return script + nodeEnvSysctlScript + nodeSwapScript(swap)
+}
+
+// nodeSwapScript turns swap off unless the cluster lets the nodes keep it,
+// then a zram swap device is set up if its size is given.
+func nodeSwapScript(swap v1.Swap) string {
+	if !swap.Enabled() {
+		return `
+systemctl disable --now kc-zram-swap.service 2>/dev/null || true
+swapoff -a
+sed -i /swap/d /etc/fstab`
+	}
+	size := swap.ZramBytes()
+	if size == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`
+cat > /etc/systemd/system/kc-zram-swap.service << 'EOF'
+[Unit]
+Description=zram swap of kubernetes node
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=/bin/sh -c 'modprobe zram && dev=$(zramctl --find --size %d) && mkswap $dev && swapon -p 100 $dev'
+ExecStop=/bin/sh -c 'for dev in $(swapon --show=NAME --noheadings | grep zram); do swapoff $dev && zramctl --reset $dev; done'
+[Install]
+WantedBy=multi-user.target
+EOF
+systemctl daemon-reload
+systemctl enable kc-zram-swap.service
+systemctl restart kc-zram-swap.service`, size)
+}
+
+const nodeEnvSysctlScript = `
 modprobe br_netfilter && modprobe nf_conntrack
 cat > /etc/sysctl.d/k8s.conf << EOF
 net.bridge.bridge-nf-call-ip6tables = 1
@@ -768,52 +838,137 @@ cat > /etc/security/limits.conf << EOF
 #IncreaseMaximumNumberOfFileDescriptors
 EOF
 sysctl --system
-sysctl -p
-swapoff -a
-sed -i /swap/d /etc/fstab`},
+sysctl -p`
+
+func EnvSetupSteps(nodes []v1.StepNode, swap v1.Swap) ([]v1.Step, error) {
+	var steps []v1.Step
+	families, groups := utils.GroupNodesByOSFamily(nodes)
+	for _, family := range families {
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "nodeEnvSetup",
+			Timeout:    metav1.Duration{Duration: 10 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      groups[family],
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", nodeEnvSetupScript(osutil.Get(osutil.Family(family)), swap)},
+				},
 			},
-		},
-	})
+		})
+	}
 
 	return steps, nil
 }
 
-func PatchTaintAndLabelStep(master, workers v1.WorkerNodeList, metadata *component.ExtraMetadata) ([]v1.Step, error) {
+// TimeSyncSteps points chrony on the nodes to the given ntp servers,
+// no step is returned when no server is configured.
+func TimeSyncSteps(servers []string, nodes []v1.StepNode) []v1.Step {
+	if len(servers) == 0 {
+		return nil
+	}
+	var steps []v1.Step
+	families, groups := utils.GroupNodesByOSFamily(nodes)
+	for _, family := range families {
+		steps = append(steps, v1.Step{
+			ID:         strutil.GetUUID(),
+			Name:       "nodeTimeSync",
+			Timeout:    metav1.Duration{Duration: 30 * time.Second},
+			ErrIgnore:  true,
+			RetryTimes: 1,
+			Nodes:      groups[family],
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", timeSyncScript(osutil.Get(osutil.Family(family)), servers)},
+				},
+			},
+		})
+	}
+	return steps
+}
+
+// IPv6CheckSteps makes sure the nodes of dual-stack and ipv6-only clusters have ipv6 enabled, a default
+// ipv6 route and ip6tables, which kube-proxy and the cni program the ipv6 rules with.
+func IPv6CheckSteps(nodes []v1.StepNode) []v1.Step {
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "nodeIPv6Check",
+			Timeout:    metav1.Duration{Duration: 10 * time.Second},
+			ErrIgnore:  false,
+			RetryTimes: 0,
+			Nodes:      nodes,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", ipv6CheckScript},
+				},
+			},
+		},
+	}
+}
+
+const ipv6CheckScript = `
+if [ "$(cat /proc/sys/net/ipv6/conf/all/disable_ipv6 2>/dev/null)" != "0" ]; then
+  echo "ipv6 is disabled, set net.ipv6.conf.all.disable_ipv6 = 0" >&2
+  exit 1
+fi
+if [ -z "$(ip -6 route show default)" ]; then
+  echo "no default ipv6 route" >&2
+  exit 1
+fi
+if ! command -v ip6tables >/dev/null; then
+  echo "ip6tables is not installed" >&2
+  exit 1
+fi`
+
+// timeSyncScript replaces the time sources of chrony with the given servers.
+func timeSyncScript(dist *osutil.OS, servers []string) string {
+	var conf strings.Builder
+	for _, server := range servers {
+		conf.WriteString(fmt.Sprintf("server %s iburst\n", server))
+	}
+	return fmt.Sprintf(`
+conf=%s
+[ -f "$conf" ] || exit 0
+sed -i -e '/^server /d' -e '/^pool /d' "$conf"
+printf '%s' >> "$conf"
+%s`, dist.Paths.ChronyConf, conf.String(), strings.Join(dist.Services.Restart(dist.ChronyService), " "))
+}
+
+func PatchTaintAndLabelStep(master, workers v1.WorkerNodeList, metadata *component.ExtraMetadata, images *v1.ToolImages) ([]v1.Step, error) {
 	var shellCommand []v1.Command
 
 	for _, v := range master {
 		hostname := metadata.GetMasterHostname(v.ID)
 		if len(v.Taints) == 0 {
-			shellCommand = append(shellCommand, v1.Command{
-				Type:         v1.CommandShell,
-				ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf("kubectl taint node %s node-role.kubernetes.io/master- || true", hostname)},
-			})
+			shellCommand = append(shellCommand, kubectlScript(images, fmt.Sprintf("kubectl taint node %s node-role.kubernetes.io/master- || true", hostname)))
 		} else {
 			for _, t := range v.Taints {
-				shellCommand = append(shellCommand, v1.Command{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf("kubectl taint node %s %s=%s:%s || true", hostname, t.Key, t.Value, t.Effect)},
-				})
+				shellCommand = append(shellCommand, kubectlScript(images, fmt.Sprintf("kubectl taint node %s %s=%s:%s || true", hostname, t.Key, t.Value, t.Effect)))
 			}
 		}
 		if len(v.Labels) != 0 {
 			for key, value := range v.Labels {
-				shellCommand = append(shellCommand, v1.Command{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf("kubectl label node %s %s=%s", hostname, key, value)},
-				})
+				shellCommand = append(shellCommand, kubectlScript(images, fmt.Sprintf("kubectl label node %s %s=%s", hostname, key, value)))
 			}
 		}
 	}
 
 	for _, v := range workers {
 		hostname := metadata.GetWorkerHostname(v.ID)
+		for _, t := range v.Taints {
+			shellCommand = append(shellCommand, kubectlScript(images, fmt.Sprintf("kubectl taint node %s %s=%s:%s --overwrite || true", hostname, t.Key, t.Value, t.Effect)))
+		}
 		if len(v.Labels) != 0 {
 			for key, value := range v.Labels {
-				shellCommand = append(shellCommand, v1.Command{
-					Type:         v1.CommandShell,
-					ShellCommand: []string{"/bin/bash", "-c", fmt.Sprintf("kubectl label node %s %s=%s", hostname, key, value)},
-				})
+				shellCommand = append(shellCommand, kubectlScript(images, fmt.Sprintf("kubectl label node %s %s=%s", hostname, key, value)))
 			}
 		}
 	}
@@ -912,12 +1067,11 @@ func Clear(kubeadm *v1.Kubeadm, metadata *component.ExtraMetadata) ([]v1.Step, e
 }
 
 func CleanCNI(c *v1.CNI, nodes []v1.StepNode) ([]v1.Step, error) {
-	switch c.Type {
-	case "calico":
-		return ClearCalico(c.Calico, nodes), nil
+	plugin, err := GetCNIPlugin(c.Type)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("no support cni type: %s", c.Type)
+	return plugin.CleanSteps(c, nodes), nil
 }
 
 func ClearCalico(calico v1.Calico, nodes []v1.StepNode) []v1.Step {
@@ -987,6 +1141,7 @@ func RemoveHostname(kubeadm *v1.Kubeadm, nodes []v1.StepNode) ([]v1.Step, error)
 
 func (c *KubectlTerminal) InitStepper(kubeadm *v1.Kubeadm) *KubectlTerminal {
 	c.ImageRegistryAddr = kubeadm.LocalRegistry
+	c.toolImages = kubeadm.ToolImages
 	return c
 }
 
@@ -1012,10 +1167,7 @@ func (c *KubectlTerminal) InstallSteps(stepMaster0 []v1.StepNode) ([]v1.Step, er
 					Data:     terminal,
 				},
 			},
-			{
-				Type:         v1.CommandShell,
-				ShellCommand: []string{"kubectl", "apply", "-f", filepath.Join(ManifestDir, "kc-kubectl.yaml")},
-			},
+			kubectlCommand(c.toolImages, "apply", "-f", filepath.Join(ManifestDir, "kc-kubectl.yaml")),
 		},
 	})
 	return installSteps, nil
@@ -20,7 +20,14 @@ package k8s
 
 import (
 	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
 )
 
 func Test_kubectlTerminal_renderTo(t *testing.T) {
@@ -35,3 +42,341 @@ func Test_kubectlTerminal_renderTo(t *testing.T) {
 	}
 	t.Log(w.String())
 }
+
+func TestRenderCalicoNodeInterface(t *testing.T) {
+	c := &CNI{
+		Type:        CniCalico,
+		PodIPv4CIDR: "172.25.0.0/24",
+		PodIPv6CIDR: "fd00::/108",
+		Calico: v1.Calico{
+			IPv4AutoDetection: "first-found",
+			IPv6AutoDetection: "first-found",
+			DualStack:         true,
+			Version:           "v3.21.2",
+		},
+	}
+	for _, tt := range []struct {
+		nodeInterface *v1.NodeInterface
+		ipv4, ipv6    string
+	}{
+		{nil, "first-found", "first-found"},
+		{&v1.NodeInterface{Method: v1.NodeInterfaceRegex, Value: "eth1"}, "interface=eth1", "interface=eth1"},
+		{&v1.NodeInterface{Method: v1.NodeInterfaceCanReach, Value: "10.0.0.1"}, "can-reach=10.0.0.1", "first-found"},
+	} {
+		c.NodeInterface = tt.nodeInterface
+		w := &bytes.Buffer{}
+		if err := (calicoPlugin{}).Render(w, (*v1.CNI)(c)); err != nil {
+			t.Fatal(err)
+		}
+		for name, want := range map[string]string{"IP_AUTODETECTION_METHOD": tt.ipv4, "IP6_AUTODETECTION_METHOD": tt.ipv6} {
+			expected := "- name: " + name + "\n              value: \"" + want + "\""
+			if !strings.Contains(w.String(), expected) {
+				t.Errorf("%s of %+v is not %s", name, tt.nodeInterface, want)
+			}
+		}
+	}
+}
+
+func Test_kubeadmPhaseWriter(t *testing.T) {
+	var phases []string
+	w := newKubeadmPhaseWriter(func(phase, message string) {
+		phases = append(phases, phase+": "+message)
+	})
+	output := `[init] Using Kubernetes version: v1.23.6
+[preflight] Running pre-flight checks
+[preflight] Pulling images required for setting up a Kubernetes cluster
+[certs] Using certificateDir folder "/etc/kubernetes/pki"
+[certs] Generating "ca" certificate and key
+[kubelet-start] Writing kubelet environment file with flags to file "/var/lib/kubelet/kubeadm-flags.env"
+[control-plane] Using manifest folder "/etc/kubernetes/manifests"
+[apiclient] All control plane components are healthy after 6.502 seconds
+[addons] Applied essential addon: CoreDNS
+[addons] Applied essential addon: kube-proxy
+`
+	// output arrives in arbitrary chunks
+	for i := 0; i < len(output); i += 7 {
+		end := i + 7
+		if end > len(output) {
+			end = len(output)
+		}
+		if _, err := w.Write([]byte(output[i:end])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{
+		"preflight: Running pre-flight checks",
+		`certs: Using certificateDir folder "/etc/kubernetes/pki"`,
+		`kubelet-start: Writing kubelet environment file with flags to file "/var/lib/kubelet/kubeadm-flags.env"`,
+		`control-plane: Using manifest folder "/etc/kubernetes/manifests"`,
+		"addons: Applied essential addon: CoreDNS",
+	}
+	if !reflect.DeepEqual(phases, want) {
+		t.Errorf("reported phases = %q, want %q", phases, want)
+	}
+}
+
+func TestPackageInstallStepsByArch(t *testing.T) {
+	nodes := []v1.StepNode{
+		{ID: "n1", Arch: "amd64"},
+		{ID: "n2", Arch: "arm64"},
+		{ID: "n3", Arch: "amd64"},
+	}
+	stepper := (&Package{}).InitStepper(&v1.Kubeadm{KubernetesVersion: "v1.23.6"})
+	steps, err := stepper.InstallSteps(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want one per architecture", len(steps))
+	}
+	for i, want := range []struct {
+		arch  string
+		nodes []string
+	}{{"amd64", []string{"n1", "n3"}}, {"arm64", []string{"n2"}}} {
+		var p Package
+		if err = json.Unmarshal(steps[i].Commands[0].CustomCommand, &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Arch != want.arch {
+			t.Errorf("step %d installs the %s bundle, want %s", i, p.Arch, want.arch)
+		}
+		var ids []string
+		for _, n := range steps[i].Nodes {
+			ids = append(ids, n.ID)
+		}
+		if !reflect.DeepEqual(ids, want.nodes) {
+			t.Errorf("step %d runs on %v, want %v", i, ids, want.nodes)
+		}
+	}
+}
+
+func TestEnvSetupStepsByOSFamily(t *testing.T) {
+	nodes := []v1.StepNode{
+		{ID: "n1", OSFamily: "rhel"},
+		{ID: "n2", OSFamily: "debian"},
+	}
+	steps, err := EnvSetupSteps(nodes, v1.Swap{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 {
+		t.Fatalf("got %d steps, want one per OS family", len(steps))
+	}
+	rhel, debian := steps[0].Commands[0].ShellCommand[2], steps[1].Commands[0].ShellCommand[2]
+	if !strings.Contains(rhel, "systemctl stop firewalld") || !strings.Contains(rhel, "setenforce 0") {
+		t.Errorf("rhel env setup script does not disable firewalld and selinux:\n%s", rhel)
+	}
+	if !strings.Contains(debian, "systemctl stop ufw") || strings.Contains(debian, "setenforce") {
+		t.Errorf("debian env setup script must disable ufw without touching selinux:\n%s", debian)
+	}
+	sync := TimeSyncSteps([]string{"ntp.example.com"}, nodes[1:])
+	if script := sync[0].Commands[0].ShellCommand[2]; !strings.Contains(script, "conf=/etc/chrony/chrony.conf") ||
+		!strings.Contains(script, "systemctl restart chrony") {
+		t.Errorf("debian time sync script uses wrong chrony paths:\n%s", script)
+	}
+}
+
+func TestRenderKubeletSwap(t *testing.T) {
+	for _, tt := range []struct {
+		version string
+		swap    v1.Swap
+		want    []string
+		script  string
+	}{
+		{"v1.27.4", v1.Swap{}, []string{"memorySwap: {}"}, "swapoff -a"},
+		{"v1.28.2", v1.Swap{Behavior: v1.SwapLimited, ZramSize: "1Gi"},
+			[]string{"failSwapOn: false", "NodeSwap: true", "swapBehavior: LimitedSwap"}, "zramctl --find --size 1073741824"},
+		{"v1.30.1", v1.Swap{Behavior: v1.SwapNoSwap}, []string{"failSwapOn: false", "swapBehavior: NoSwap"}, ""},
+	} {
+		c := &KubeadmConfig{KubernetesVersion: tt.version, Kubelet: v1.Kubelet{Swap: tt.swap}}
+		w := &bytes.Buffer{}
+		if err := c.renderTo(w); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range tt.want {
+			if !strings.Contains(w.String(), want) {
+				t.Errorf("kubelet config of %s %+v does not contain %q", tt.version, tt.swap, want)
+			}
+		}
+		if gate := strings.Contains(w.String(), "NodeSwap: true"); gate != tt.swap.NodeSwapFeatureGate(tt.version) {
+			t.Errorf("NodeSwap feature gate of %s %+v is %v", tt.version, tt.swap, gate)
+		}
+		script := nodeSwapScript(tt.swap)
+		if !strings.Contains(script, tt.script) || (tt.swap.Enabled() && strings.Contains(script, "swapoff -a")) {
+			t.Errorf("swap script of %+v:\n%s", tt.swap, script)
+		}
+	}
+}
+
+func TestKubeadmConfigRenderPatches(t *testing.T) {
+	cfg := &KubeadmConfig{
+		ClusterConfigAPIVersion: "v1beta3",
+		ContainerRuntime:        "containerd",
+		KubernetesVersion:       "v1.23.6",
+		Kubelet:                 v1.Kubelet{RootDir: KubeletDefaultDataDir},
+		Patches: v1.KubeadmConfigPatches{
+			{Kind: "ClusterConfiguration", Patch: "apiServer:\n  extraArgs:\n    feature-gates: EphemeralContainers=true\n"},
+			{Kind: "KubeletConfiguration", Type: v1.KubeadmPatchJSON6902, Patch: `[{"op": "replace", "path": "/cgroupDriver", "value": "cgroupfs"}]`},
+		},
+	}
+	w := &bytes.Buffer{}
+	if err := cfg.renderTo(w); err != nil {
+		t.Fatal(err)
+	}
+	out := w.String()
+	for _, want := range []string{"feature-gates: EphemeralContainers=true", "cgroupDriver: cgroupfs", "kind: KubeProxyConfiguration", "kind: InitConfiguration"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("rendered configuration misses %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "cgroupDriver: systemd") {
+		t.Errorf("kubelet cgroup driver is not patched:\n%s", out)
+	}
+
+	cfg.Patches = v1.KubeadmConfigPatches{{Kind: "KubeletConfiguration", Type: v1.KubeadmPatchJSON6902, Patch: `[{"op": "remove", "path": "/missing"}]`}}
+	if err := cfg.ValidatePatches(); err == nil {
+		t.Error("expected an error for a patch which does not apply")
+	}
+}
+
+func TestUpdateCertSANsSteps(t *testing.T) {
+	masters := []v1.StepNode{{ID: "m1"}, {ID: "m2"}}
+	cfg := &KubeadmConfig{KubernetesVersion: "v1.23.6", CertSANs: []string{"k8s.example.com"}}
+	steps, err := UpdateCertSANsSteps(masters, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, s := range steps {
+		names = append(names, s.Name)
+	}
+	want := []string{"renderKubeadmConfig", "regenerateAPIServerCertificate", "regenerateAPIServerCertificate", "uploadKubeadmConfig"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("steps = %v, want %v", names, want)
+	}
+	if len(steps[0].Nodes) != 2 || steps[1].Nodes[0].ID != "m1" || steps[2].Nodes[0].ID != "m2" || steps[3].Nodes[0].ID != "m1" {
+		t.Errorf("unexpected step nodes: %+v", steps)
+	}
+	data := &KubeadmConfig{}
+	if err = json.Unmarshal(steps[0].Commands[0].Template.Data, data); err != nil || !reflect.DeepEqual(data.CertSANs, cfg.CertSANs) {
+		t.Errorf("rendered cert SANs = %v, %v", data.CertSANs, err)
+	}
+}
+
+func TestRenderControlPlaneVIP(t *testing.T) {
+	vip := &ControlPlaneVIP{
+		Mode:            v1.ControlPlaneVIPKeepalived,
+		Address:         "10.0.0.100",
+		Interface:       "eth0",
+		VirtualRouterID: 51,
+		Port:            8443,
+		Masters:         []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+		NodeIP:          "10.0.0.2",
+		Priority:        149,
+	}
+	at := tmplutil.New()
+	conf, err := at.Render(keepalivedConfTemplate, vip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"priority 149", "unicast_src_ip 10.0.0.2", "    10.0.0.1\n    10.0.0.3\n  }", "10.0.0.100/32 dev eth0"} {
+		if !strings.Contains(conf, want) {
+			t.Errorf("keepalived.conf misses %q:\n%s", want, conf)
+		}
+	}
+	cfg, err := at.Render(haproxyCfgTemplate, vip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"bind *:8443", "server master0 10.0.0.1:6443", "server master2 10.0.0.3:6443"} {
+		if !strings.Contains(cfg, want) {
+			t.Errorf("haproxy.cfg misses %q:\n%s", want, cfg)
+		}
+	}
+}
+
+func TestNodePoolSteps(t *testing.T) {
+	kubeadm := &v1.Kubeadm{
+		ContainerRuntime: v1.ContainerRuntime{Type: v1.CRIDocker},
+		NodePools: []v1.NodePool{{
+			Name:             "gpu",
+			KubeletArgs:      map[string]string{"max-pods": "200"},
+			ContainerRuntime: &v1.ContainerRuntime{Type: v1.CRIContainerd},
+		}},
+		Workers: v1.WorkerNodeList{{ID: "w1", Pool: "gpu"}, {ID: "w2"}, {ID: "w3", Pool: "gpu"}},
+	}
+	nodes := []v1.StepNode{{ID: "w1", OSFamily: "debian"}, {ID: "w2"}, {ID: "w3", OSFamily: "debian"}}
+
+	steps, err := (&Package{}).InitStepper(kubeadm).InstallSteps(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || len(steps[0].Nodes) != 2 || len(steps[1].Nodes) != 1 {
+		t.Fatalf("got %d package steps, want one per pool", len(steps))
+	}
+	var pack Package
+	if err = json.Unmarshal(steps[0].Commands[0].CustomCommand, &pack); err != nil || pack.CriType != "containerd" {
+		t.Errorf("package step of the gpu pool loads images into %q, %v", pack.CriType, err)
+	}
+
+	steps, err = (&ClusterNode{}).InitStepper(kubeadm, &component.ExtraMetadata{Masters: component.NodeList{{ID: "m1"}}}).InstallSteps(NodeRoleWorker, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var join ClusterNode
+	if len(steps) != 2 || json.Unmarshal(steps[1].Commands[0].CustomCommand, &join) != nil || join.ContainerRuntime != "docker" {
+		t.Errorf("workers out of the pool must join with the runtime of the cluster: %+v", join)
+	}
+
+	args := KubeletArgsSteps(kubeadm, nodes)
+	if len(args) != 1 || len(args[0].Nodes) != 2 ||
+		!strings.Contains(args[0].Commands[0].ShellCommand[2], `KUBELET_EXTRA_ARGS="--max-pods=200"' >> /etc/default/kubelet`) {
+		t.Errorf("unexpected kubelet args steps: %+v", args)
+	}
+
+	cmd := "kubeadm join apiserver.cluster.local:6443 --token abc --discovery-token-ca-cert-hash sha256:1 --cri-socket /run/containerd/containerd.sock"
+	if got := criJoinCmd(cmd, "docker"); got != "kubeadm join apiserver.cluster.local:6443 --token abc --discovery-token-ca-cert-hash sha256:1" {
+		t.Errorf("criJoinCmd(docker) = %q", got)
+	}
+	if got := criJoinCmd(cmd, "containerd"); got != cmd {
+		t.Errorf("criJoinCmd(containerd) = %q", got)
+	}
+}
+
+func TestRenderIPv6Only(t *testing.T) {
+	networking := v1.Networking{PodSubnets: []string{"fd00:25::/56"}, ServiceSubnets: []string{"fd00:96::/112"}}
+	networking.Complete()
+	cfg := &KubeadmConfig{
+		KubernetesVersion: "v1.23.6",
+		Network:           networking,
+		Kubelet:           v1.Kubelet{RootDir: KubeletDefaultDataDir},
+		AdvertiseAddress:  "fd00::10",
+	}
+	w := &bytes.Buffer{}
+	if err := cfg.renderTo(w); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"podSubnet: fd00:25::/56", `advertiseAddress: "fd00::10"`, `node-ip: "::"`} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("kubeadm config misses %q:\n%s", want, w.String())
+		}
+	}
+
+	cni := &v1.CNI{Type: CniCalico, Calico: v1.Calico{IPManger: true, Version: "v3.21.2"}}
+	cni.SetPodCIDRs(networking)
+	w.Reset()
+	if err := (calicoPlugin{}).Render(w, cni); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"assign_ipv4": "false"`, "- name: IP\n              value: \"none\"", "- name: CALICO_IPV6POOL_CIDR\n              value: \"fd00:25::/56\"", "- name: FELIX_IPV6SUPPORT\n              value: \"true\""} {
+		if !strings.Contains(w.String(), want) {
+			t.Errorf("calico manifest misses %q", want)
+		}
+	}
+
+	kubeadm := &v1.Kubeadm{Networking: networking}
+	steps := KubeletArgsSteps(kubeadm, []v1.StepNode{{ID: "w1"}})
+	if len(steps) != 1 || !strings.Contains(steps[0].Commands[0].ShellCommand[2], `KUBELET_EXTRA_ARGS="--node-ip=::"`) {
+		t.Errorf("ipv6-only kubelet args steps %+v", steps)
+	}
+}
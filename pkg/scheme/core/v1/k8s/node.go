@@ -33,6 +33,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 )
 
 var (
@@ -112,11 +113,15 @@ func (stepper *GenNode) MakeSteps(metadata *component.ExtraMetadata, role string
 	// add node to cluster
 	if len(stepper.installSteps) == 0 {
 		// We should use kubeadm to create join token on the first control plane node.
-		steps, err := EnvSetupSteps(patchNodes)
+		steps, err := EnvSetupSteps(patchNodes, stepper.Kubeadm.KubeComponents.Kubelet.Swap)
 		if err != nil {
 			return err
 		}
 		stepper.installSteps = append(stepper.installSteps, steps...)
+		stepper.installSteps = append(stepper.installSteps, TimeSyncSteps(stepper.Kubeadm.NTPServers, patchNodes)...)
+		if stepper.Kubeadm.Networking.HasIPv6() {
+			stepper.installSteps = append(stepper.installSteps, IPv6CheckSteps(patchNodes)...)
+		}
 
 		joinCmd := JoinCmd{}
 		steps, err = joinCmd.InitStepper(stepper.Kubeadm).InstallSteps([]v1.StepNode{masters[0]})
@@ -125,12 +130,30 @@ func (stepper *GenNode) MakeSteps(metadata *component.ExtraMetadata, role string
 		}
 		stepper.installSteps = append(stepper.installSteps, steps...)
 
+		stepper.installSteps = append(stepper.installSteps, KubeletArgsSteps(stepper.Kubeadm, patchNodes)...)
+
 		join := ClusterNode{}
 		steps, err = join.InitStepper(stepper.Kubeadm, metadata).InstallSteps(role, patchNodes)
 		if err != nil {
 			return err
 		}
 		stepper.installSteps = append(stepper.installSteps, steps...)
+
+		if role == NodeRoleWorker {
+			// the labels and taints of the workers and their pools
+			ids := sets.NewString(stepper.Nodes.GetNodeIDs()...)
+			var workers v1.WorkerNodeList
+			for _, w := range stepper.Kubeadm.EffectiveWorkers() {
+				if ids.Has(w.ID) {
+					workers = append(workers, w)
+				}
+			}
+			steps, err = PatchTaintAndLabelStep(nil, workers, metadata, stepper.Kubeadm.ToolImages)
+			if err != nil {
+				return err
+			}
+			stepper.installSteps = append(stepper.installSteps, steps...)
+		}
 	}
 
 	if len(stepper.uninstallSteps) == 0 {
@@ -0,0 +1,120 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component/utils"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/osutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+var criSocketFlag = regexp.MustCompile(`\s*--cri-socket[ =]\S+`)
+
+// GroupNodesByPool splits the nodes by the node pool they belong to, the nodes in no pool are
+// grouped under the empty name. Pools are returned in the order they first appear.
+func GroupNodesByPool(kubeadm *v1.Kubeadm, nodes []v1.StepNode) ([]string, map[string][]v1.StepNode) {
+	var pools []string
+	groups := make(map[string][]v1.StepNode)
+	for _, node := range nodes {
+		pool := ""
+		if p := kubeadm.PoolOf(node.ID); p != nil {
+			pool = p.Name
+		}
+		if _, ok := groups[pool]; !ok {
+			pools = append(pools, pool)
+		}
+		groups[pool] = append(groups[pool], node)
+	}
+	return pools, groups
+}
+
+// KubeletArgsSteps writes the kubelet arguments of the node pools on their nodes,
+// they must run before the nodes join the cluster.
+func KubeletArgsSteps(kubeadm *v1.Kubeadm, nodes []v1.StepNode) []v1.Step {
+	var steps []v1.Step
+	pools, groups := GroupNodesByPool(kubeadm, nodes)
+	for _, pool := range pools {
+		args := kubeletExtraArgs(kubeadm, kubeadm.NodePool(pool))
+		if args == "" {
+			continue
+		}
+		families, familyGroups := utils.GroupNodesByOSFamily(groups[pool])
+		for _, family := range families {
+			steps = append(steps, v1.Step{
+				ID:         strutil.GetUUID(),
+				Name:       "kubeletExtraArgs",
+				Timeout:    metav1.Duration{Duration: 10 * time.Second},
+				ErrIgnore:  false,
+				RetryTimes: 1,
+				Nodes:      familyGroups[family],
+				Action:     v1.ActionInstall,
+				Commands: []v1.Command{
+					{
+						Type:         v1.CommandShell,
+						ShellCommand: []string{"/bin/bash", "-c", kubeletArgsScript(osutil.Get(osutil.Family(family)), args)},
+					},
+				},
+			})
+		}
+	}
+	return steps
+}
+
+// kubeletExtraArgs returns the kubelet flags of the nodes in the pool, kubelet of ipv6-only clusters
+// registers the default ipv6 address of the node.
+func kubeletExtraArgs(kubeadm *v1.Kubeadm, p *v1.NodePool) string {
+	var flags []string
+	if p != nil && len(p.KubeletArgs) > 0 {
+		flags = append(flags, p.KubeletExtraArgs())
+	}
+	if kubeadm.Networking.IPv6Only() {
+		flags = append(flags, "--node-ip=::")
+	}
+	return strings.Join(flags, " ")
+}
+
+// kubeletArgsScript sets KUBELET_EXTRA_ARGS in the environment file of the kubelet unit.
+func kubeletArgsScript(dist *osutil.OS, args string) string {
+	return fmt.Sprintf(`
+mkdir -p "$(dirname %[1]s)"
+touch %[1]s
+sed -i '/^KUBELET_EXTRA_ARGS=/d' %[1]s
+echo 'KUBELET_EXTRA_ARGS="%[2]s"' >> %[1]s`, dist.Paths.KubeletEnv, args)
+}
+
+// criJoinCmd points the worker join command at the socket of the container runtime,
+// the command is kept as it is when the runtime is unknown.
+func criJoinCmd(cmd, cri string) string {
+	if cri == "" {
+		return cmd
+	}
+	cmd = criSocketFlag.ReplaceAllString(cmd, "")
+	if cri == v1.CRIContainerd.String() {
+		cmd += " --cri-socket /run/containerd/containerd.sock"
+	}
+	return strings.TrimSpace(cmd)
+}
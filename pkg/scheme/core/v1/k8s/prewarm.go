@@ -0,0 +1,115 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/downloader"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+const prewarm = "prewarm"
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, prewarm, version, component.TypeStep), &Prewarm{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.StepRunnable = (*Prewarm)(nil)
+
+// Prewarm caches kubernetes packages and images on a node without installing them,
+// so that a later installPackages step finds them in the downloader cache.
+type Prewarm struct {
+	Version       string `json:"version"`
+	Offline       bool   `json:"offline"`
+	LocalRegistry string `json:"localRegistry"`
+}
+
+func (stepper *Prewarm) NewInstance() component.ObjectMeta {
+	return &Prewarm{}
+}
+
+func (stepper *Prewarm) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, K8s, stepper.Version, runtime.GOARCH, !stepper.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	files := []string{downloader.ConfigFilename}
+	// images are only loaded from the tarball when there is no registry to pull them from
+	if stepper.Offline && stepper.LocalRegistry == "" {
+		files = append(files, downloader.ImageFilename)
+	}
+	if err = instance.Download(files...); err != nil {
+		return nil, err
+	}
+	logger.Debugf("k8s %s packages prewarmed", stepper.Version)
+	return nil, nil
+}
+
+func (stepper *Prewarm) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	instance, err := downloader.NewInstance(ctx, K8s, stepper.Version, runtime.GOARCH, !stepper.Offline, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	return nil, instance.RemoveImages()
+}
+
+func (stepper *Prewarm) InitStepper(kubernetesVersion string, offline bool, localRegistry string) *Prewarm {
+	stepper.Version = kubernetesVersion
+	stepper.Offline = offline
+	stepper.LocalRegistry = localRegistry
+	return stepper
+}
+
+func (stepper *Prewarm) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
+	bytes, err := json.Marshal(stepper)
+	if err != nil {
+		return nil, err
+	}
+
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       "prewarmPackages",
+			Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+			ErrIgnore:  false,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Action:     v1.ActionInstall,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, prewarm, version, component.TypeStep),
+					CustomCommand: bytes,
+				},
+			},
+		},
+	}, nil
+}
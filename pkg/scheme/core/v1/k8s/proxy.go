@@ -0,0 +1,98 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+)
+
+// proxyDropIn is the systemd drop-in holding the proxy environment of a service.
+const proxyDropIn = "http-proxy.conf"
+
+// proxyServices are the services the proxy is set up for, the drop-ins of the services a node does not run are unused.
+var proxyServices = []string{"containerd", "docker", "kubelet", "k3s", "k3s-agent"}
+
+// ProxySteps sets the http proxy of the cluster up for the container runtimes, the kubelet and k3s of the nodes
+// with systemd drop-ins, so it must run before they start. The nodes of the cluster are reached directly.
+func ProxySteps(kubeadm *v1.Kubeadm, metadata *component.ExtraMetadata, action v1.StepAction, nodes []v1.StepNode) []v1.Step {
+	if kubeadm.Proxy == nil {
+		return nil
+	}
+	name, script := "nodeProxySetup", proxySetupScript(kubeadm.ProxyEnv(metadata.GetAllNodes().GetNodeIPs()))
+	if action == v1.ActionUninstall {
+		name, script = "nodeProxyCleanup", proxyCleanupScript()
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       name,
+			Timeout:    metav1.Duration{Duration: 10 * time.Second},
+			ErrIgnore:  action == v1.ActionUninstall,
+			RetryTimes: 0,
+			Nodes:      nodes,
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type:         v1.CommandShell,
+					ShellCommand: []string{"/bin/bash", "-c", script},
+				},
+			},
+		},
+	}
+}
+
+// proxySetupScript writes the proxy environment in both cases to the drop-ins, % is escaped from the
+// specifier expansion of systemd.
+func proxySetupScript(proxy *v1.ProxyEnv) string {
+	var conf strings.Builder
+	conf.WriteString("[Service]\n")
+	for _, env := range []struct{ name, value string }{
+		{"HTTP_PROXY", proxy.HTTPProxy},
+		{"HTTPS_PROXY", proxy.HTTPSProxy},
+		{"NO_PROXY", proxy.NoProxy},
+	} {
+		if env.value == "" {
+			continue
+		}
+		value := strings.ReplaceAll(env.value, "%", "%%")
+		conf.WriteString(fmt.Sprintf("Environment=\"%s=%s\" \"%s=%s\"\n", env.name, value, strings.ToLower(env.name), value))
+	}
+	return fmt.Sprintf(`
+for svc in %s; do
+  mkdir -p /etc/systemd/system/$svc.service.d
+  printf '%%s' '%s' > /etc/systemd/system/$svc.service.d/%s
+done
+systemctl daemon-reload`, strings.Join(proxyServices, " "), conf.String(), proxyDropIn)
+}
+
+func proxyCleanupScript() string {
+	return fmt.Sprintf(`
+for svc in %s; do
+  rm -f /etc/systemd/system/$svc.service.d/%s
+done
+systemctl daemon-reload`, strings.Join(proxyServices, " "), proxyDropIn)
+}
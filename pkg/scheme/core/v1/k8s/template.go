@@ -82,7 +82,17 @@ healthzPort: 10248
 imageGCHighThresholdPercent: 85
 imageGCLowThresholdPercent: 80
 imageMinimumGCAge: 2m0s
+{{- if .Kubelet.Swap.Enabled}}
+failSwapOn: false
+{{- if .Kubelet.Swap.NodeSwapFeatureGate .KubernetesVersion}}
+featureGates:
+  NodeSwap: true
+{{- end}}
+memorySwap:
+  swapBehavior: {{.Kubelet.Swap.Behavior}}
+{{- else}}
 memorySwap: {}
+{{- end}}
 staticPodPath: /etc/kubernetes/manifests
 streamingConnectionIdleTimeout: 0s
 syncFrequency: 3s
@@ -90,12 +100,19 @@ volumeStatsAggPeriod: 1m
 ---
 apiVersion: kubeadm.k8s.io/v1beta2
 kind: InitConfiguration
+{{- with .AdvertiseAddress}}
+localAPIEndpoint:
+  advertiseAddress: "{{.}}"
+{{- end}}
 nodeRegistration:
 {{- if eq .ContainerRuntime  "containerd"}}
   criSocket: /run/containerd/containerd.sock
 {{end}}
   kubeletExtraArgs:
     root-dir: {{.Kubelet.RootDir}}
+{{- if .Network.IPv6Only}}
+    node-ip: "::"
+{{- end}}
 `
 
 const lvscareV111 = `
@@ -170,6 +187,10 @@ data:
              "type": "calico-ipam",
              "assign_ipv4": "true",
              "assign_ipv6": "true"
+           {{else if not .PodIPv4CIDR}}
+             "type": "calico-ipam",
+             "assign_ipv4": "false",
+             "assign_ipv6": "true"
            {{else}}
              "type": "calico-ipam"
            {{end}}
@@ -690,16 +711,16 @@ spec:
            - name: CLUSTER_TYPE
              value: "k8s,bgp"
            - name: IP
-             value: "autodetect"
+             value: "{{if .PodIPv4CIDR}}autodetect{{else}}none{{end}}"
            - name: IP_AUTODETECTION_METHOD
-             value: "{{.Calico.IPv4AutoDetection}}"
-           {{if .Calico.DualStack}}
+             value: "{{with .NodeInterface}}{{or (.CalicoAutoDetection false) $.Calico.IPv4AutoDetection}}{{else}}{{.Calico.IPv4AutoDetection}}{{end}}"
+           {{if .CalicoIPv6}}
            - name: IP6
              value: "autodetect"
            - name: CALICO_IPV6POOL_CIDR
              value: "{{.PodIPv6CIDR}}"
            - name: IP6_AUTODETECTION_METHOD
-             value: "{{.Calico.IPv6AutoDetection}}"
+             value: "{{with .NodeInterface}}{{or (.CalicoAutoDetection true) $.Calico.IPv6AutoDetection}}{{else}}{{.Calico.IPv6AutoDetection}}{{end}}"
            {{end}}
            {{if eq .Calico.Mode "BGP"}}
            - name: CALICO_IPV4POOL_IPIP
@@ -736,7 +757,7 @@ spec:
            - name: FELIX_DEFAULTENDPOINTTOHOSTACTION
              value: "ACCEPT"
            - name: FELIX_IPV6SUPPORT
-             value: "{{.Calico.DualStack}}"
+             value: "{{.CalicoIPv6}}"
            - name: FELIX_LOGSEVERITYSCREEN
              value: "info"
            - name: FELIX_HEALTHENABLED
@@ -894,6 +915,10 @@ data:
              "type": "calico-ipam",
              "assign_ipv4": "true",
              "assign_ipv6": "true"
+           {{else if not .PodIPv4CIDR}}
+             "type": "calico-ipam",
+             "assign_ipv4": "false",
+             "assign_ipv6": "true"
            {{else}}
              "type": "calico-ipam"
            {{end}}
@@ -4956,16 +4981,16 @@ spec:
             - name: CLUSTER_TYPE
               value: "k8s,bgp"
             - name: IP
-              value: "autodetect"
+              value: "{{if .PodIPv4CIDR}}autodetect{{else}}none{{end}}"
             - name: IP_AUTODETECTION_METHOD
-              value: "{{.Calico.IPv4AutoDetection}}"
-            {{if .Calico.DualStack}}
+              value: "{{with .NodeInterface}}{{or (.CalicoAutoDetection false) $.Calico.IPv4AutoDetection}}{{else}}{{.Calico.IPv4AutoDetection}}{{end}}"
+            {{if .CalicoIPv6}}
             - name: IP6
               value: "autodetect"
             - name: CALICO_IPV6POOL_CIDR
               value: "{{.PodIPv6CIDR}}"
             - name: IP6_AUTODETECTION_METHOD
-              value: "{{.Calico.IPv6AutoDetection}}"
+              value: "{{with .NodeInterface}}{{or (.CalicoAutoDetection true) $.Calico.IPv6AutoDetection}}{{else}}{{.Calico.IPv6AutoDetection}}{{end}}"
             {{end}}
             {{if eq .Calico.Mode "BGP"}}
             - name: CALICO_IPV4POOL_IPIP
@@ -5010,7 +5035,7 @@ spec:
             - name: FELIX_DEFAULTENDPOINTTOHOSTACTION
               value: "ACCEPT"
             - name: FELIX_IPV6SUPPORT
-              value: "{{.Calico.DualStack}}"
+              value: "{{.CalicoIPv6}}"
             - name: FELIX_HEALTHENABLED
               value: "true"
           securityContext:
@@ -5247,3 +5272,385 @@ subjects:
     name: kc-kubectl
     namespace: kube-system
 `
+
+// https://github.com/flannel-io/flannel/blob/v0.20.2/Documentation/kube-flannel.yml
+const flannelV0202 = `
+---
+kind: Namespace
+apiVersion: v1
+metadata:
+  name: kube-flannel
+  labels:
+    pod-security.kubernetes.io/enforce: privileged
+---
+kind: ClusterRole
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: flannel
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - pods
+  verbs:
+  - get
+- apiGroups:
+  - ""
+  resources:
+  - nodes
+  verbs:
+  - get
+  - list
+  - watch
+- apiGroups:
+  - ""
+  resources:
+  - nodes/status
+  verbs:
+  - patch
+---
+kind: ClusterRoleBinding
+apiVersion: rbac.authorization.k8s.io/v1
+metadata:
+  name: flannel
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: flannel
+subjects:
+- kind: ServiceAccount
+  name: flannel
+  namespace: kube-flannel
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: flannel
+  namespace: kube-flannel
+---
+kind: ConfigMap
+apiVersion: v1
+metadata:
+  name: kube-flannel-cfg
+  namespace: kube-flannel
+  labels:
+    tier: node
+    app: flannel
+data:
+  cni-conf.json: |
+    {
+      "name": "cbr0",
+      "cniVersion": "0.3.1",
+      "plugins": [
+        {
+          "type": "flannel",
+          "delegate": {
+            "hairpinMode": true,
+            "isDefaultGateway": true
+          }
+        },
+        {
+          "type": "portmap",
+          "capabilities": {
+            "portMappings": true
+          }
+        }
+      ]
+    }
+  net-conf.json: |
+    {
+      "Network": "{{.PodIPv4CIDR}}",
+{{- with .PodIPv6CIDR}}
+      "EnableIPv6": true,
+      "IPv6Network": "{{.}}",
+{{- end}}
+      "Backend": {
+        "Type": "{{.Backend}}"
+      }
+    }
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: kube-flannel-ds
+  namespace: kube-flannel
+  labels:
+    tier: node
+    app: flannel
+spec:
+  selector:
+    matchLabels:
+      app: flannel
+  template:
+    metadata:
+      labels:
+        tier: node
+        app: flannel
+    spec:
+      affinity:
+        nodeAffinity:
+          requiredDuringSchedulingIgnoredDuringExecution:
+            nodeSelectorTerms:
+            - matchExpressions:
+              - key: kubernetes.io/os
+                operator: In
+                values:
+                - linux
+      hostNetwork: true
+      priorityClassName: system-node-critical
+      tolerations:
+      - operator: Exists
+        effect: NoSchedule
+      serviceAccountName: flannel
+      initContainers:
+      - name: install-cni-plugin
+        image: {{with .LocalRegistry}}{{.}}/{{end}}flannel/flannel-cni-plugin:v1.1.2
+        command:
+        - cp
+        args:
+        - -f
+        - /flannel
+        - /opt/cni/bin/flannel
+        volumeMounts:
+        - name: cni-plugin
+          mountPath: /opt/cni/bin
+      - name: install-cni
+        image: {{with .LocalRegistry}}{{.}}/{{end}}flannel/flannel:{{.Flannel.Version}}
+        command:
+        - cp
+        args:
+        - -f
+        - /etc/kube-flannel/cni-conf.json
+        - /etc/cni/net.d/10-flannel.conflist
+        volumeMounts:
+        - name: cni
+          mountPath: /etc/cni/net.d
+        - name: flannel-cfg
+          mountPath: /etc/kube-flannel/
+      containers:
+      - name: kube-flannel
+        image: {{with .LocalRegistry}}{{.}}/{{end}}flannel/flannel:{{.Flannel.Version}}
+        command:
+        - /opt/bin/flanneld
+        args:
+        - --ip-masq
+        - --kube-subnet-mgr
+{{- range .Args}}
+        - {{.}}
+{{- end}}
+        resources:
+          requests:
+            cpu: "100m"
+            memory: "50Mi"
+        securityContext:
+          privileged: false
+          capabilities:
+            add: ["NET_ADMIN", "NET_RAW"]
+        env:
+        - name: POD_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        - name: POD_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        - name: EVENT_QUEUE_DEPTH
+          value: "5000"
+        volumeMounts:
+        - name: run
+          mountPath: /run/flannel
+        - name: flannel-cfg
+          mountPath: /etc/kube-flannel/
+        - name: xtables-lock
+          mountPath: /run/xtables.lock
+      volumes:
+      - name: run
+        hostPath:
+          path: /run/flannel
+      - name: cni-plugin
+        hostPath:
+          path: /opt/cni/bin
+      - name: cni
+        hostPath:
+          path: /etc/cni/net.d
+      - name: flannel-cfg
+        configMap:
+          name: kube-flannel-cfg
+      - name: xtables-lock
+        hostPath:
+          path: /run/xtables.lock
+          type: FileOrCreate
+`
+
+const kubeVIPTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: kube-vip
+  namespace: kube-system
+  labels:
+    component: kube-vip
+    tier: control-plane
+spec:
+  containers:
+  - name: kube-vip
+    image: {{with .LocalRegistry}}{{.}}/{{end}}plndr/kube-vip:v0.4.4
+    imagePullPolicy: IfNotPresent
+    args:
+    - manager
+    env:
+    - name: vip_arp
+      value: "true"
+    - name: port
+      value: "6443"
+    - name: vip_interface
+      value: {{.Interface}}
+    - name: vip_cidr
+      value: "32"
+    - name: cp_enable
+      value: "true"
+    - name: cp_namespace
+      value: kube-system
+    - name: vip_leaderelection
+      value: "true"
+    - name: vip_leaseduration
+      value: "5"
+    - name: vip_renewdeadline
+      value: "3"
+    - name: vip_retryperiod
+      value: "1"
+    - name: address
+      value: {{.Address}}
+    securityContext:
+      capabilities:
+        add:
+        - NET_ADMIN
+        - NET_RAW
+    volumeMounts:
+    - mountPath: /etc/kubernetes/admin.conf
+      name: kubeconfig
+  hostAliases:
+  - hostnames:
+    - kubernetes
+    ip: 127.0.0.1
+  hostNetwork: true
+  volumes:
+  - hostPath:
+      path: /etc/kubernetes/admin.conf
+    name: kubeconfig
+`
+
+const keepalivedConfTemplate = `global_defs {
+  router_id {{.NodeIP}}
+  script_user root
+  enable_script_security
+}
+vrrp_script check_apiserver {
+  script "/usr/bin/wget -q -T 2 -O /dev/null --no-check-certificate https://127.0.0.1:6443/healthz"
+  interval 3
+  fall 10
+  rise 2
+}
+vrrp_instance kube_apiserver {
+  state BACKUP
+  interface {{.Interface}}
+  virtual_router_id {{.VirtualRouterID}}
+  priority {{.Priority}}
+  advert_int 1
+  unicast_src_ip {{.NodeIP}}
+  unicast_peer {
+{{- range .Masters}}{{if ne . $.NodeIP}}
+    {{.}}{{end}}{{end}}
+  }
+  virtual_ipaddress {
+    {{.Address}}/32 dev {{.Interface}}
+  }
+  track_script {
+    check_apiserver
+  }
+}
+`
+
+const haproxyCfgTemplate = `global
+  log stdout format raw local0
+  maxconn 4000
+defaults
+  mode tcp
+  log global
+  option tcplog
+  timeout connect 5s
+  timeout client 1h
+  timeout server 1h
+frontend kube-apiserver
+  bind *:{{.Port}}
+  default_backend kube-apiserver
+backend kube-apiserver
+  option httpchk GET /healthz
+  http-check expect status 200
+  balance roundrobin
+{{- range $i, $m := .Masters}}
+  server master{{$i}} {{$m}}:6443 check check-ssl verify none{{end}}
+`
+
+const keepalivedTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: keepalived
+  namespace: kube-system
+  labels:
+    component: keepalived
+    tier: control-plane
+spec:
+  containers:
+  - name: keepalived
+    image: {{with .LocalRegistry}}{{.}}/{{end}}osixia/keepalived:2.0.20
+    imagePullPolicy: IfNotPresent
+    args:
+    - --copy-service
+    securityContext:
+      capabilities:
+        add:
+        - NET_ADMIN
+        - NET_BROADCAST
+        - NET_RAW
+    volumeMounts:
+    - mountPath: /container/service/keepalived/assets/keepalived.conf
+      name: config
+      readOnly: true
+  hostNetwork: true
+  volumes:
+  - hostPath:
+      path: /etc/kubernetes/vip/keepalived.conf
+      type: File
+    name: config
+`
+
+const haproxyTemplate = `apiVersion: v1
+kind: Pod
+metadata:
+  name: haproxy
+  namespace: kube-system
+  labels:
+    component: haproxy
+    tier: control-plane
+spec:
+  containers:
+  - name: haproxy
+    image: {{with .LocalRegistry}}{{.}}/{{end}}haproxy:2.4.17
+    imagePullPolicy: IfNotPresent
+    livenessProbe:
+      failureThreshold: 8
+      tcpSocket:
+        host: 127.0.0.1
+        port: {{.Port}}
+    volumeMounts:
+    - mountPath: /usr/local/etc/haproxy/haproxy.cfg
+      name: config
+      readOnly: true
+  hostNetwork: true
+  volumes:
+  - hostPath:
+      path: /etc/kubernetes/vip/haproxy.cfg
+      type: File
+    name: config
+`
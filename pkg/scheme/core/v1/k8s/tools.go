@@ -0,0 +1,73 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// kubectlCommand returns the shell command running kubectl with args, in a container of the kubectl
+// tool image when it is set. The container reads the kubeconfig of root and the manifests of the steps.
+func kubectlCommand(images *v1.ToolImages, args ...string) v1.Command {
+	return v1.Command{
+		Type:         v1.CommandShell,
+		ShellCommand: append([]string{"kubectl"}, args...),
+		Container:    kubectlContainer(images),
+	}
+}
+
+// kubectlScript returns the shell command running the kubectl script with bash, in a container of
+// the kubectl tool image when it is set.
+func kubectlScript(images *v1.ToolImages, script string) v1.Command {
+	return v1.Command{
+		Type:         v1.CommandShell,
+		ShellCommand: []string{"/bin/bash", "-c", script},
+		Container:    kubectlContainer(images),
+	}
+}
+
+func kubectlContainer(images *v1.ToolImages) *v1.ContainerExecution {
+	if images == nil || images.Kubectl == "" {
+		return nil
+	}
+	return &v1.ContainerExecution{
+		Image: images.Kubectl,
+		Mounts: []v1.HostMount{
+			{HostPath: "/root/.kube", ReadOnly: true},
+			{HostPath: "/etc/kubernetes", ReadOnly: true},
+			{HostPath: ManifestDir, ReadOnly: true},
+		},
+	}
+}
+
+// etcdctlCommand returns the shell command running the etcdctl script with bash, in a container of
+// the etcdctl tool image when it is set. The container reads the etcd certificates of the node.
+func etcdctlCommand(images *v1.ToolImages, script string) v1.Command {
+	c := v1.Command{
+		Type:         v1.CommandShell,
+		ShellCommand: []string{"/bin/bash", "-c", script},
+	}
+	if images != nil && images.Etcdctl != "" {
+		c.Container = &v1.ContainerExecution{
+			Image:  images.Etcdctl,
+			Mounts: []v1.HostMount{{HostPath: "/etc/kubernetes/pki/etcd", ReadOnly: true}},
+		}
+	}
+	return c
+}
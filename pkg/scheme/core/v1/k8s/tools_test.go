@@ -0,0 +1,131 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestEtcdMaintenanceStepsToolImages(t *testing.T) {
+	masters := []v1.StepNode{{ID: "m1"}, {ID: "m2"}}
+	tasks := []v1.MaintenanceTask{v1.MaintenanceTaskHealth, v1.MaintenanceTaskDefrag}
+	for _, step := range EtcdMaintenanceSteps(masters, tasks, nil) {
+		if c := step.Commands[0]; c.Container != nil {
+			t.Errorf("step %s runs in container %s without tool images", step.Name, c.Container.Image)
+		}
+	}
+	images := &v1.ToolImages{Etcdctl: "etcd:3.5.6-0"}
+	for _, step := range EtcdMaintenanceSteps(masters, tasks, images) {
+		if c := step.Commands[0]; c.Container == nil || c.Container.Image != images.Etcdctl {
+			t.Errorf("step %s does not run in the etcdctl image", step.Name)
+		}
+	}
+}
+
+func TestRotateCredentialsStepsToolImages(t *testing.T) {
+	images := &v1.ToolImages{Kubectl: "kubectl:v1.23.6"}
+	for _, step := range RotateCredentialsSteps([]v1.StepNode{{ID: "m1"}}, images) {
+		for _, c := range step.Commands {
+			if len(c.ShellCommand) > 0 && c.ShellCommand[0] == "kubectl" && (c.Container == nil || c.Container.Image != images.Kubectl) {
+				t.Errorf("kubectl command of step %s does not run in the kubectl image", step.Name)
+			}
+		}
+	}
+}
+
+// kubectlCall matches kubectl run as a command, not as a path.
+var kubectlCall = regexp.MustCompile(`(^|[\s;])kubectl\s`)
+
+// kubectlCommands fails unless every command of the steps calling kubectl runs in the kubectl image,
+// it returns how many of them there are.
+func kubectlCommands(t *testing.T, steps []v1.Step, images *v1.ToolImages) int {
+	n := 0
+	for _, step := range steps {
+		for _, c := range step.Commands {
+			if c.Type != v1.CommandShell || !kubectlCall.MatchString(strings.Join(c.ShellCommand, " ")) {
+				continue
+			}
+			n++
+			if c.Container == nil || c.Container.Image != images.Kubectl {
+				t.Errorf("kubectl command %v of step %s does not run in the kubectl image", c.ShellCommand, step.Name)
+			}
+		}
+	}
+	return n
+}
+
+func TestUpgradeStepsToolImages(t *testing.T) {
+	images := &v1.ToolImages{Kubectl: "kubectl:v1.24.1"}
+	metadata := component.ExtraMetadata{
+		ClusterName: "test",
+		Masters:     component.NodeList{{ID: "m1", Hostname: "master-1"}},
+		Workers:     component.NodeList{{ID: "w1", Hostname: "worker-1"}},
+	}
+	stepper := &Upgrade{
+		Kubeadm:    &KubeadmConfig{KubernetesVersion: "v1.23.6", ContainerRuntime: "containerd"},
+		Version:    "v1.24.1",
+		toolImages: images,
+	}
+	if err := stepper.InitSteps(component.WithExtraMetadata(context.TODO(), metadata)); err != nil {
+		t.Fatal(err)
+	}
+	steps := stepper.GetInstallSteps()
+	// drain, uncordon and health gate of the master, of the worker batch
+	if n := kubectlCommands(t, steps, images); n != 6 {
+		t.Errorf("got %d kubectl commands, want 6", n)
+	}
+	rollback, err := RollbackWorkerSteps(steps, nil, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := kubectlCommands(t, rollback, images); n != 3 {
+		t.Errorf("got %d kubectl commands of the rollback, want 3", n)
+	}
+}
+
+func TestInstallStepsToolImages(t *testing.T) {
+	images := &v1.ToolImages{Kubectl: "kubectl:v1.23.6"}
+	cni := &v1.CNI{Type: "calico", Calico: v1.Calico{Version: "v3.21.2"}}
+	steps, err := manifestSteps(cni, []v1.StepNode{{ID: "m1"}}, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := kubectlCommands(t, steps, images); n != 1 {
+		t.Errorf("got %d kubectl commands applying the cni, want 1", n)
+	}
+	metadata := &component.ExtraMetadata{
+		Masters: component.NodeList{{ID: "m1", Hostname: "master-1"}},
+		Workers: component.NodeList{{ID: "w1", Hostname: "worker-1"}},
+	}
+	masters := v1.WorkerNodeList{{ID: "m1"}}
+	workers := v1.WorkerNodeList{{ID: "w1", Labels: map[string]string{"pool": "a"}, Taints: []v1.Taint{{Key: "k", Value: "v", Effect: "NoSchedule"}}}}
+	steps, err = PatchTaintAndLabelStep(masters, workers, metadata, images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := kubectlCommands(t, steps, images); n != 3 {
+		t.Errorf("got %d kubectl commands patching the nodes, want 3", n)
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/netutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/strutil"
+	tmplutil "github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+const (
+	controlPlaneVIP = "controlPlaneVIP"
+	// vipConfigDir holds the keepalived and haproxy configuration of the masters.
+	vipConfigDir = "/etc/kubernetes/vip"
+)
+
+func init() {
+	if err := component.RegisterAgentStep(fmt.Sprintf(component.RegisterStepKeyFormat, controlPlaneVIP, version, component.TypeStep), &ControlPlaneVIP{}); err != nil {
+		panic(err)
+	}
+}
+
+var _ component.StepRunnable = (*ControlPlaneVIP)(nil)
+
+// ControlPlaneVIP runs the static pods announcing the control plane vip on a master.
+type ControlPlaneVIP struct {
+	Mode            v1.ControlPlaneVIPMode `json:"mode"`
+	Address         string                 `json:"address"`
+	Interface       string                 `json:"interface"`
+	VirtualRouterID int                    `json:"virtualRouterID"`
+	Port            int                    `json:"port"`
+	// Masters are the ips of the masters, the order decides the keepalived priority.
+	Masters       []string `json:"masters"`
+	LocalRegistry string   `json:"localRegistry"`
+	// NodeIP and Priority are resolved on the node the step runs on.
+	NodeIP   string `json:"-"`
+	Priority int    `json:"-"`
+}
+
+func (stepper *ControlPlaneVIP) InitStepper(kubeadm *v1.Kubeadm, metadata *component.ExtraMetadata) *ControlPlaneVIP {
+	vip := kubeadm.ControlPlaneVIP
+	stepper.Mode = vip.Mode
+	stepper.Address = vip.Address
+	stepper.Interface = vip.Interface
+	stepper.VirtualRouterID = vip.RouterID()
+	stepper.Port = vip.Port()
+	stepper.Masters = nil
+	for _, m := range metadata.Masters {
+		stepper.Masters = append(stepper.Masters, m.IPv4)
+	}
+	stepper.LocalRegistry = kubeadm.LocalRegistry
+	return stepper
+}
+
+func (stepper *ControlPlaneVIP) InstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
+	return stepper.steps("deployControlPlaneVIP", v1.ActionInstall, false, nodes)
+}
+
+func (stepper *ControlPlaneVIP) UninstallSteps(nodes []v1.StepNode) ([]v1.Step, error) {
+	return stepper.steps("removeControlPlaneVIP", v1.ActionUninstall, true, nodes)
+}
+
+func (stepper *ControlPlaneVIP) steps(name string, action v1.StepAction, errIgnore bool, nodes []v1.StepNode) ([]v1.Step, error) {
+	b, err := json.Marshal(stepper)
+	if err != nil {
+		return nil, err
+	}
+	return []v1.Step{
+		{
+			ID:         strutil.GetUUID(),
+			Name:       name,
+			Timeout:    metav1.Duration{Duration: 3 * time.Minute},
+			ErrIgnore:  errIgnore,
+			RetryTimes: 1,
+			Nodes:      nodes,
+			Action:     action,
+			Commands: []v1.Command{
+				{
+					Type:          v1.CommandCustom,
+					Identity:      fmt.Sprintf(component.RegisterStepKeyFormat, controlPlaneVIP, version, component.TypeStep),
+					CustomCommand: b,
+				},
+			},
+		},
+	}, nil
+}
+
+func (stepper *ControlPlaneVIP) NewInstance() component.ObjectMeta {
+	return &ControlPlaneVIP{}
+}
+
+func (stepper *ControlPlaneVIP) Install(ctx context.Context, opts component.Options) ([]byte, error) {
+	if err := stepper.resolveNode(); err != nil {
+		return nil, err
+	}
+	files := map[string]string{filepath.Join(KubeManifestsDir, "kube-vip.yaml"): kubeVIPTemplate}
+	if stepper.Mode == v1.ControlPlaneVIPKeepalived {
+		if err := os.MkdirAll(vipConfigDir, 0755); err != nil {
+			return nil, err
+		}
+		files = map[string]string{
+			filepath.Join(vipConfigDir, "keepalived.conf"):     keepalivedConfTemplate,
+			filepath.Join(vipConfigDir, "haproxy.cfg"):         haproxyCfgTemplate,
+			filepath.Join(KubeManifestsDir, "keepalived.yaml"): keepalivedTemplate,
+			filepath.Join(KubeManifestsDir, "haproxy.yaml"):    haproxyTemplate,
+		}
+	}
+	if err := os.MkdirAll(KubeManifestsDir, 0755); err != nil {
+		return nil, err
+	}
+	for file, tmpl := range files {
+		tmpl := tmpl
+		if err := fileutil.WriteFileWithContext(ctx, file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644, func(w io.Writer) error {
+			_, err := tmplutil.New().RenderTo(w, tmpl, stepper)
+			return err
+		}, opts.DryRun); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (stepper *ControlPlaneVIP) Uninstall(ctx context.Context, opts component.Options) ([]byte, error) {
+	for _, f := range []string{"kube-vip.yaml", "keepalived.yaml", "haproxy.yaml"} {
+		if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "rm", "-f", filepath.Join(KubeManifestsDir, f)); err != nil {
+			logger.Warnf("remove control plane vip manifest %s error: %s", f, err.Error())
+		}
+	}
+	if _, err := cmdutil.RunCmdWithContext(ctx, opts.DryRun, "rm", "-rf", vipConfigDir); err != nil {
+		logger.Warnf("remove control plane vip config error: %s", err.Error())
+	}
+	// the vip stays on the interface when the static pods are killed before they release it
+	if err := stepper.resolveNode(); err == nil {
+		_, _ = cmdutil.RunCmdWithContext(ctx, opts.DryRun, "bash", "-c",
+			fmt.Sprintf("ip addr del %s/32 dev %s || true", stepper.Address, stepper.Interface))
+	}
+	return nil, nil
+}
+
+// resolveNode detects the interface of the vip and the keepalived priority of the node.
+func (stepper *ControlPlaneVIP) resolveNode() error {
+	if stepper.Interface == "" {
+		iface, err := netutil.GetDefaultInterface(true)
+		if err != nil {
+			return fmt.Errorf("detect the interface of the control plane vip: %v", err)
+		}
+		stepper.Interface = iface
+	}
+	ip, err := netutil.GetDefaultIP(true)
+	if err != nil {
+		return err
+	}
+	stepper.NodeIP = ip.String()
+	// the first master is preferred, the priority decreases in the order of the masters
+	stepper.Priority = 100
+	for i, m := range stepper.Masters {
+		if m == stepper.NodeIP {
+			stepper.Priority = 150 - i
+		}
+	}
+	return nil
+}
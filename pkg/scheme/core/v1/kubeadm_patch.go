@@ -0,0 +1,149 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/yaml"
+)
+
+type KubeadmPatchType string
+
+const (
+	// KubeadmPatchStrategic merges the patch into the document. The kubeadm configuration types declare
+	// no patch merge keys, so maps are merged recursively and lists are replaced as a whole.
+	KubeadmPatchStrategic KubeadmPatchType = "strategic"
+	// KubeadmPatchJSON6902 applies the RFC 6902 operations of the patch to the document.
+	KubeadmPatchJSON6902 KubeadmPatchType = "json6902"
+)
+
+var kubeadmPatchKinds = sets.NewString("ClusterConfiguration", "InitConfiguration", "KubeletConfiguration", "KubeProxyConfiguration")
+
+// documentSeparator splits the documents of a multi-document yaml.
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// KubeadmConfigPatch is applied to the kubeadm configuration rendered by kubeclipper, e.g. to turn on
+// feature gates, change the cgroup driver or add apiserver flags.
+type KubeadmConfigPatch struct {
+	// Kind is the document of kubeadm.yaml the patch is applied to.
+	Kind string `json:"kind" enum:"ClusterConfiguration|InitConfiguration|KubeletConfiguration|KubeProxyConfiguration"`
+	// Type is strategic by default.
+	Type KubeadmPatchType `json:"type,omitempty" enum:"strategic|json6902" optional:"true"`
+	// Patch is the yaml or json patch, a json6902 patch is a list of operations.
+	Patch string `json:"patch"`
+}
+
+// KubeadmConfigPatches are applied in order.
+type KubeadmConfigPatches []KubeadmConfigPatch
+
+// Validate checks the kind, the type and the syntax of every patch.
+func (p KubeadmConfigPatches) Validate() error {
+	for i, patch := range p {
+		if !kubeadmPatchKinds.Has(patch.Kind) {
+			return fmt.Errorf("config patch %d: unsupported kind %q, must be one of %s", i, patch.Kind, strings.Join(kubeadmPatchKinds.List(), ", "))
+		}
+		data, err := yaml.YAMLToJSON([]byte(patch.Patch))
+		if err != nil {
+			return fmt.Errorf("config patch %d: %v", i, err)
+		}
+		switch patch.Type {
+		case "", KubeadmPatchStrategic:
+			if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+				return fmt.Errorf("config patch %d: a strategic patch must be an object", i)
+			}
+		case KubeadmPatchJSON6902:
+			if _, err = jsonpatch.DecodePatch(data); err != nil {
+				return fmt.Errorf("config patch %d: %v", i, err)
+			}
+		default:
+			return fmt.Errorf("config patch %d: unsupported type %q", i, patch.Type)
+		}
+	}
+	return nil
+}
+
+// Apply patches the documents of the multi-document kubeadm configuration. Documents without patches are
+// returned as they are.
+func (p KubeadmConfigPatches) Apply(config []byte) ([]byte, error) {
+	if len(p) == 0 {
+		return config, nil
+	}
+	docs := documentSeparator.Split(string(config), -1)
+	applied := make([]bool, len(p))
+	for i, doc := range docs {
+		var meta struct {
+			Kind string `json:"kind"`
+		}
+		if err := yaml.Unmarshal([]byte(doc), &meta); err != nil {
+			return nil, err
+		}
+		data, patched := []byte(nil), false
+		for j, patch := range p {
+			if patch.Kind != meta.Kind || meta.Kind == "" {
+				continue
+			}
+			if data == nil {
+				var err error
+				if data, err = yaml.YAMLToJSON([]byte(doc)); err != nil {
+					return nil, err
+				}
+			}
+			var err error
+			if data, err = patch.apply(data); err != nil {
+				return nil, fmt.Errorf("patch %s: %v", patch.Kind, err)
+			}
+			applied[j], patched = true, true
+		}
+		if !patched {
+			continue
+		}
+		out, err := yaml.JSONToYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		docs[i] = "\n" + string(out)
+	}
+	for j, ok := range applied {
+		if !ok {
+			return nil, fmt.Errorf("kubeadm configuration has no %s to patch", p[j].Kind)
+		}
+	}
+	return []byte(strings.Join(docs, "---")), nil
+}
+
+func (p KubeadmConfigPatch) apply(doc []byte) ([]byte, error) {
+	patch, err := yaml.YAMLToJSON([]byte(p.Patch))
+	if err != nil {
+		return nil, err
+	}
+	if p.Type == KubeadmPatchJSON6902 {
+		ops, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, err
+		}
+		return ops.Apply(doc)
+	}
+	return jsonpatch.MergePatch(doc, patch)
+}
@@ -0,0 +1,72 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"strings"
+	"testing"
+)
+
+const testKubeadmConfig = `
+kind: ClusterConfiguration
+networking:
+  podSubnet: 172.25.0.0/16
+---
+kind: KubeletConfiguration
+cgroupDriver: systemd
+`
+
+func TestKubeadmConfigPatchesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		patches KubeadmConfigPatches
+		wantErr bool
+	}{
+		{name: "strategic", patches: KubeadmConfigPatches{{Kind: "ClusterConfiguration", Patch: "networking:\n  dnsDomain: example.local"}}},
+		{name: "json6902", patches: KubeadmConfigPatches{{Kind: "KubeletConfiguration", Type: KubeadmPatchJSON6902, Patch: `[{"op": "remove", "path": "/cgroupDriver"}]`}}},
+		{name: "unknown kind", patches: KubeadmConfigPatches{{Kind: "JoinConfiguration", Patch: "{}"}}, wantErr: true},
+		{name: "unknown type", patches: KubeadmConfigPatches{{Kind: "ClusterConfiguration", Type: "merge", Patch: "{}"}}, wantErr: true},
+		{name: "strategic list", patches: KubeadmConfigPatches{{Kind: "ClusterConfiguration", Patch: "- a"}}, wantErr: true},
+		{name: "invalid json6902", patches: KubeadmConfigPatches{{Kind: "ClusterConfiguration", Type: KubeadmPatchJSON6902, Patch: "op: add"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.patches.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestKubeadmConfigPatchesApply(t *testing.T) {
+	patches := KubeadmConfigPatches{
+		{Kind: "ClusterConfiguration", Patch: "networking:\n  dnsDomain: example.local"},
+		{Kind: "KubeletConfiguration", Type: KubeadmPatchJSON6902, Patch: `[{"op": "replace", "path": "/cgroupDriver", "value": "cgroupfs"}]`},
+	}
+	out, err := patches.Apply([]byte(testKubeadmConfig))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"dnsDomain: example.local", "podSubnet: 172.25.0.0/16", "cgroupDriver: cgroupfs"} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("patched configuration misses %q:\n%s", want, out)
+		}
+	}
+	if _, err = (KubeadmConfigPatches{{Kind: "InitConfiguration", Patch: "{}"}}).Apply([]byte(testKubeadmConfig)); err == nil {
+		t.Error("expected an error when the document to patch is missing")
+	}
+}
@@ -0,0 +1,180 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+type IPFamily string
+
+const (
+	IPv4Family IPFamily = "IPv4"
+	IPv6Family IPFamily = "IPv6"
+)
+
+// dualStackVersion is the first kubernetes version with dual-stack networking enabled by default.
+var dualStackVersion = version.MustParseGeneric("v1.21.0")
+
+// PodCIDRs returns the pod subnets of the cluster, the primary one first.
+func (n Networking) PodCIDRs() []string {
+	if len(n.PodSubnets) > 0 {
+		return n.PodSubnets
+	}
+	return splitCIDRs(n.PodSubnet)
+}
+
+// ServiceCIDRs returns the service subnets of the cluster, the primary one first.
+func (n Networking) ServiceCIDRs() []string {
+	if len(n.ServiceSubnets) > 0 {
+		return n.ServiceSubnets
+	}
+	return splitCIDRs(n.ServiceSubnet)
+}
+
+// IPFamilies returns the ip families of the pod subnets, the primary one first.
+func (n Networking) IPFamilies() []IPFamily {
+	var families []IPFamily
+	for _, cidr := range n.PodCIDRs() {
+		families = append(families, cidrFamily(cidr))
+	}
+	return families
+}
+
+// DualStack tells whether the pods and services get both ipv4 and ipv6 addresses.
+func (n Networking) DualStack() bool {
+	return len(n.IPFamilies()) == 2
+}
+
+// IPv6Only tells whether the cluster runs on ipv6 alone.
+func (n Networking) IPv6Only() bool {
+	families := n.IPFamilies()
+	return len(families) == 1 && families[0] == IPv6Family
+}
+
+// HasIPv6 tells whether the nodes must be ready for ipv6 traffic.
+func (n Networking) HasIPv6() bool {
+	for _, family := range n.IPFamilies() {
+		if family == IPv6Family {
+			return true
+		}
+	}
+	return false
+}
+
+// Complete joins the subnet lists into the subnets kubeadm reads, which are comma separated.
+func (n *Networking) Complete() {
+	if len(n.PodSubnets) > 0 {
+		n.PodSubnet = strings.Join(n.PodSubnets, ",")
+	}
+	if len(n.ServiceSubnets) > 0 {
+		n.ServiceSubnet = strings.Join(n.ServiceSubnets, ",")
+	}
+}
+
+// Validate checks the pod and service subnets are cidrs of at most one subnet per ip family,
+// the families of the services follow the ones of the pods in the same order.
+func (n Networking) Validate(kubernetesVersion string) error {
+	if len(n.PodSubnets) > 0 && n.PodSubnet != "" && n.PodSubnet != strings.Join(n.PodSubnets, ",") {
+		return fmt.Errorf("podSubnet %s conflicts with podSubnets %v", n.PodSubnet, n.PodSubnets)
+	}
+	if len(n.ServiceSubnets) > 0 && n.ServiceSubnet != "" && n.ServiceSubnet != strings.Join(n.ServiceSubnets, ",") {
+		return fmt.Errorf("serviceSubnet %s conflicts with serviceSubnets %v", n.ServiceSubnet, n.ServiceSubnets)
+	}
+	podFamilies, err := cidrFamilies("pod", n.PodCIDRs())
+	if err != nil {
+		return err
+	}
+	serviceFamilies, err := cidrFamilies("service", n.ServiceCIDRs())
+	if err != nil {
+		return err
+	}
+	if len(serviceFamilies) > 0 && strings.Join(podFamilies, ",") != strings.Join(serviceFamilies, ",") {
+		return fmt.Errorf("service subnets of %s do not match the pod subnets of %s",
+			strings.Join(serviceFamilies, ","), strings.Join(podFamilies, ","))
+	}
+	if len(podFamilies) < 2 {
+		return nil
+	}
+	v, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return fmt.Errorf("invalid kubernetes version %q: %v", kubernetesVersion, err)
+	}
+	if v.LessThan(dualStackVersion) {
+		return fmt.Errorf("dual-stack networking requires kubernetes %s or later", dualStackVersion)
+	}
+	return nil
+}
+
+// SetPodCIDRs sets the cidrs the cni assigns the pod addresses from, calico runs in dual-stack mode
+// when the pods get both ipv4 and ipv6 addresses.
+func (c *CNI) SetPodCIDRs(n Networking) {
+	c.PodIPv4CIDR, c.PodIPv6CIDR = "", ""
+	for _, cidr := range n.PodCIDRs() {
+		if cidrFamily(cidr) == IPv6Family {
+			c.PodIPv6CIDR = cidr
+		} else {
+			c.PodIPv4CIDR = cidr
+		}
+	}
+	c.Calico.DualStack = c.PodIPv4CIDR != "" && c.PodIPv6CIDR != ""
+}
+
+// CalicoIPv6 tells whether calico assigns ipv6 addresses, either in dual-stack mode or when
+// the pods have no ipv4 cidr.
+func (c CNI) CalicoIPv6() bool {
+	return c.Calico.DualStack || (c.PodIPv4CIDR == "" && c.PodIPv6CIDR != "")
+}
+
+func splitCIDRs(s string) []string {
+	var cidrs []string
+	for _, cidr := range strings.Split(s, ",") {
+		if cidr = strings.TrimSpace(cidr); cidr != "" {
+			cidrs = append(cidrs, cidr)
+		}
+	}
+	return cidrs
+}
+
+func cidrFamily(cidr string) IPFamily {
+	if ip, _, err := net.ParseCIDR(cidr); err == nil && ip.To4() == nil {
+		return IPv6Family
+	}
+	return IPv4Family
+}
+
+func cidrFamilies(kind string, cidrs []string) ([]string, error) {
+	if len(cidrs) > 2 {
+		return nil, fmt.Errorf("at most two %s subnets are supported, one per ip family", kind)
+	}
+	var families []string
+	for _, cidr := range cidrs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return nil, fmt.Errorf("invalid %s subnet %q: %v", kind, cidr, err)
+		}
+		families = append(families, string(cidrFamily(cidr)))
+	}
+	if len(families) == 2 && families[0] == families[1] {
+		return nil, fmt.Errorf("%s subnets %s must be of different ip families", kind, strings.Join(cidrs, ","))
+	}
+	return families, nil
+}
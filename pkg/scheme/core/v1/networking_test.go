@@ -0,0 +1,79 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import "testing"
+
+func TestNetworkingValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		version    string
+		networking Networking
+		wantErr    bool
+	}{
+		{"ipv4", "v1.23.6", Networking{PodSubnet: "172.25.0.0/16", ServiceSubnet: "10.96.0.0/16"}, false},
+		{"dual-stack", "v1.23.6", Networking{PodSubnets: []string{"172.25.0.0/16", "fd00:25::/56"}, ServiceSubnets: []string{"10.96.0.0/16", "fd00:96::/112"}}, false},
+		{"dual-stack string", "v1.23.6", Networking{PodSubnet: "fd00:25::/56,172.25.0.0/16", ServiceSubnet: "fd00:96::/112,10.96.0.0/16"}, false},
+		{"ipv6 only", "v1.23.6", Networking{PodSubnets: []string{"fd00:25::/56"}, ServiceSubnets: []string{"fd00:96::/112"}}, false},
+		{"old kubernetes", "v1.20.15", Networking{PodSubnets: []string{"172.25.0.0/16", "fd00:25::/56"}, ServiceSubnets: []string{"10.96.0.0/16", "fd00:96::/112"}}, true},
+		{"same family", "v1.23.6", Networking{PodSubnets: []string{"172.25.0.0/16", "172.26.0.0/16"}}, true},
+		{"invalid cidr", "v1.23.6", Networking{PodSubnets: []string{"172.25.0.0"}}, true},
+		{"family mismatch", "v1.23.6", Networking{PodSubnets: []string{"172.25.0.0/16", "fd00:25::/56"}, ServiceSubnets: []string{"fd00:96::/112", "10.96.0.0/16"}}, true},
+		{"conflict", "v1.23.6", Networking{PodSubnet: "172.25.0.0/16", PodSubnets: []string{"172.26.0.0/16"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.networking.Validate(tt.version); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNetworkingComplete(t *testing.T) {
+	c := Cluster{
+		ClusterType: ClusterKubeadm,
+		Kubeadm: &Kubeadm{
+			KubernetesVersion: "v1.23.6",
+			Networking: Networking{
+				PodSubnets:     []string{"fd00:25::/56", "172.25.0.0/16"},
+				ServiceSubnets: []string{"fd00:96::/112", "10.96.0.0/16"},
+			},
+			KubeComponents: KubeComponents{CNI: CNI{Type: "calico", PodIPv4CIDR: "172.26.0.0/24"}},
+		},
+	}
+	c.Complete()
+	n := c.Kubeadm.Networking
+	if n.PodSubnet != "fd00:25::/56,172.25.0.0/16" || n.ServiceSubnet != "fd00:96::/112,10.96.0.0/16" {
+		t.Errorf("subnets are %s and %s", n.PodSubnet, n.ServiceSubnet)
+	}
+	if !n.DualStack() || n.IPv6Only() || n.IPFamilies()[0] != IPv6Family {
+		t.Errorf("unexpected ip families %v", n.IPFamilies())
+	}
+	cni := c.Kubeadm.KubeComponents.CNI
+	if cni.PodIPv4CIDR != "172.25.0.0/16" || cni.PodIPv6CIDR != "fd00:25::/56" || !cni.Calico.DualStack {
+		t.Errorf("unexpected cni %+v", cni)
+	}
+
+	ipv6 := Networking{PodSubnets: []string{"fd00:25::/56"}}
+	cni = CNI{}
+	cni.SetPodCIDRs(ipv6)
+	if !ipv6.IPv6Only() || !cni.CalicoIPv6() || cni.Calico.DualStack || cni.PodIPv4CIDR != "" {
+		t.Errorf("unexpected ipv6-only cni %+v", cni)
+	}
+}
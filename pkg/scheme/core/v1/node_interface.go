@@ -0,0 +1,99 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+type NodeInterfaceMethod string
+
+const (
+	// NodeInterfaceRegex uses the first interface whose name matches the regex.
+	NodeInterfaceRegex NodeInterfaceMethod = "interface"
+	// NodeInterfaceCanReach uses the interface routing to the address.
+	NodeInterfaceCanReach NodeInterfaceMethod = "can-reach"
+	// NodeInterfaceSkip uses the first interface whose name does not match the regex.
+	NodeInterfaceSkip NodeInterfaceMethod = "skip-interface"
+)
+
+// NodeInterface selects the interface the cni uses on multi-homed nodes,
+// instead of the first one found.
+type NodeInterface struct {
+	Method NodeInterfaceMethod `json:"method" enum:"interface|can-reach|skip-interface"`
+	// Value is an interface name regex, or an ip address or domain name for can-reach.
+	Value string `json:"value"`
+}
+
+// ParseNodeInterface parses a selection in the form of METHOD=VALUE.
+func ParseNodeInterface(s string) (*NodeInterface, error) {
+	method, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return nil, fmt.Errorf("invalid node interface %q, expect METHOD=VALUE", s)
+	}
+	n := &NodeInterface{Method: NodeInterfaceMethod(method), Value: value}
+	if err := n.Validate(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (n *NodeInterface) Validate() error {
+	if n == nil {
+		return nil
+	}
+	if n.Value == "" {
+		return fmt.Errorf("node interface %s requires a value", n.Method)
+	}
+	switch n.Method {
+	case NodeInterfaceRegex, NodeInterfaceSkip:
+		if _, err := regexp.Compile(n.Value); err != nil {
+			return fmt.Errorf("invalid node interface regex %q: %v", n.Value, err)
+		}
+	case NodeInterfaceCanReach:
+	default:
+		return fmt.Errorf("unsupported node interface method %q", n.Method)
+	}
+	return nil
+}
+
+// CalicoAutoDetection returns the calico-node autodetection method of the ip family,
+// it is empty when the can-reach address belongs to the other family.
+func (n *NodeInterface) CalicoAutoDetection(ipv6 bool) string {
+	if n.Method == NodeInterfaceCanReach {
+		if ip := net.ParseIP(n.Value); ip != nil && (ip.To4() == nil) != ipv6 {
+			return ""
+		}
+	}
+	return fmt.Sprintf("%s=%s", n.Method, n.Value)
+}
+
+// FlannelArgs returns the flanneld arguments of the selection.
+func (n *NodeInterface) FlannelArgs() ([]string, error) {
+	switch n.Method {
+	case NodeInterfaceRegex:
+		return []string{"--iface-regex=" + n.Value}, nil
+	case NodeInterfaceCanReach:
+		return []string{"--iface-can-reach=" + n.Value}, nil
+	}
+	return nil, fmt.Errorf("flannel does not support node interface method %s", n.Method)
+}
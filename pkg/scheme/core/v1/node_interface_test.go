@@ -0,0 +1,40 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import "testing"
+
+func TestParseNodeInterface(t *testing.T) {
+	n, err := ParseNodeInterface("skip-interface=docker.*|veth.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Method != NodeInterfaceSkip || n.Value != "docker.*|veth.*" {
+		t.Errorf("unexpected node interface %+v", n)
+	}
+	if _, err = n.FlannelArgs(); err == nil {
+		t.Error("expected skip-interface to be rejected by flannel")
+	}
+
+	for _, s := range []string{"eth1", "interface=", "interface=eth[", "first-found=eth1"} {
+		if _, err = ParseNodeInterface(s); err == nil {
+			t.Errorf("expected %q to be invalid", s)
+		}
+	}
+}
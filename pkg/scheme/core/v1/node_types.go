@@ -150,6 +150,8 @@ type NodeSystemInfo struct {
 	KernelVersion   string `json:"kernelVersion"`   // version of the OS kernel (if available)
 	KernelArch      string `json:"kernelArch"`      // native cpu architecture queried at runtime, as returned by `uname -m` or empty string in case of error
 	HostID          string `json:"hostId"`          // MachineId
+	// OSFamily is the family steps are rendered for, ex: rhel, debian, openeuler, suse
+	OSFamily string `json:"osFamily,omitempty"`
 }
 
 type UniqueVolumeName string
@@ -198,4 +200,39 @@ type NodeStatus struct {
 	// +optional
 	VolumesAttached      []AttachedVolume `json:"volumesAttached,omitempty"`
 	ContainerRuntimeInfo ContainerRuntime `json:"containerRuntime"`
+	// GPUs found on the node, only NVIDIA and AMD devices are reported.
+	// +optional
+	GPUs []GPU `json:"gpus,omitempty"`
+	// Usage is the resource usage reported with the latest node status.
+	// +optional
+	Usage *NodeUsage `json:"usage,omitempty"`
+}
+
+// NodeUsage is a sample of the resource usage of the node, sizes are in bytes.
+type NodeUsage struct {
+	Timestamp     metav1.Time `json:"timestamp"`
+	CPUPercent    float64     `json:"cpuPercent"`
+	MemoryUsed    uint64      `json:"memoryUsed"`
+	MemoryPercent float64     `json:"memoryPercent"`
+	DiskUsed      uint64      `json:"diskUsed"`
+	DiskPercent   float64     `json:"diskPercent"`
+	Load1         float64     `json:"load1"`
+	Load5         float64     `json:"load5"`
+	Load15        float64     `json:"load15"`
+}
+
+// NodeMetrics is the recent resource usage of a node kept by the server, oldest first.
+type NodeMetrics struct {
+	Node  string      `json:"node"`
+	Usage []NodeUsage `json:"usage"`
+}
+
+// GPU describes a GPU device of the node.
+type GPU struct {
+	// Vendor is one of nvidia and amd.
+	Vendor string `json:"vendor"`
+	// Model is the product name reported by the driver, or the pci vendor:device id when no driver is loaded.
+	Model string `json:"model"`
+	// DriverVersion is the version of the loaded kernel driver, empty when no driver is loaded.
+	DriverVersion string `json:"driverVersion,omitempty"`
 }
@@ -0,0 +1,249 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+// NodePool is a group of workers managed as a unit, e.g. the gpu or the edge nodes of a cluster.
+// The settings of the pool apply to the workers when they join the cluster.
+type NodePool struct {
+	Name string `json:"name"`
+	// Labels and Taints are added to the nodes of the pool, the ones of a node take precedence.
+	Labels map[string]string `json:"labels,omitempty" optional:"true"`
+	Taints []Taint           `json:"taints,omitempty" optional:"true"`
+	// KubeletArgs are extra command line flags of kubelet without the leading dashes, e.g. {"max-pods": "200"}.
+	KubeletArgs map[string]string `json:"kubeletArgs,omitempty" optional:"true"`
+	// ContainerRuntime of the nodes of the pool, the one of the cluster by default.
+	ContainerRuntime *ContainerRuntime `json:"containerRuntime,omitempty" optional:"true"`
+	// WorkerCount is the desired number of workers of the pool. When it is set the pool is scaled
+	// by the server, which joins the nodes supplied by the provider or removes the surplus ones.
+	WorkerCount *int `json:"workerCount,omitempty" optional:"true"`
+	// Provider supplies the nodes of the pool scaled by the worker count.
+	Provider *NodePoolProvider `json:"provider,omitempty" optional:"true"`
+}
+
+// NodePoolProvider selects the node provider supplying the machines of a pool.
+type NodePoolProvider struct {
+	// Name of a node provider configured on the server, the static provider picking
+	// the free registered nodes of the region is used when it is empty.
+	Name string `json:"name,omitempty" optional:"true"`
+	// Selector restricts the nodes supplied to the ones having all of the labels.
+	Selector map[string]string `json:"selector,omitempty" optional:"true"`
+	// Machine describes the machines created for the pool by the providers managing virtual machines,
+	// it overrides the defaults configured for the provider.
+	Machine *MachineSpec `json:"machine,omitempty" optional:"true"`
+}
+
+// MachineSpec is the spec of the virtual machines created for a node pool.
+type MachineSpec struct {
+	// Flavor is the ID of the flavor of the machines.
+	Flavor string `json:"flavor,omitempty" optional:"true"`
+	// Image is the ID of the image the machines boot from.
+	Image string `json:"image,omitempty" optional:"true"`
+	// Networks are the IDs of the networks the machines are attached to, the IP of the first
+	// network is the IP the agent registers with.
+	Networks []string `json:"networks,omitempty" optional:"true"`
+	// SecurityGroups are the names of the security groups of the machines.
+	SecurityGroups []string `json:"securityGroups,omitempty" optional:"true"`
+	// KeyName is the name of the key pair injected into the machines.
+	KeyName string `json:"keyName,omitempty" optional:"true"`
+	// AvailabilityZone the machines are created in.
+	AvailabilityZone string `json:"availabilityZone,omitempty" optional:"true"`
+}
+
+// ProviderName returns the name of the node provider of the pool.
+func (p *NodePool) ProviderName() string {
+	if p.Provider == nil {
+		return ""
+	}
+	return p.Provider.Name
+}
+
+// Validate checks the name, the labels, the taints, the kubelet arguments and the container runtime of the pool.
+func (p *NodePool) Validate() error {
+	if errs := validation.IsDNS1123Label(p.Name); len(errs) > 0 {
+		return fmt.Errorf("invalid node pool name %q: %s", p.Name, strings.Join(errs, ", "))
+	}
+	if err := validateLabels(p.Labels); err != nil {
+		return fmt.Errorf("invalid labels of node pool %s: %v", p.Name, err)
+	}
+	for _, t := range p.Taints {
+		if errs := validation.IsQualifiedName(t.Key); len(errs) > 0 {
+			return fmt.Errorf("invalid taint %q of node pool %s: %s", t.Key, p.Name, strings.Join(errs, ", "))
+		}
+		switch t.Effect {
+		case TaintEffectNoSchedule, TaintEffectPreferNoSchedule, TaintEffectNoExecute:
+		default:
+			return fmt.Errorf("invalid effect %q of taint %s of node pool %s", t.Effect, t.Key, p.Name)
+		}
+	}
+	for k := range p.KubeletArgs {
+		if k == "" || strings.HasPrefix(k, "-") || strings.ContainsAny(k, " =") {
+			return fmt.Errorf("invalid kubelet argument %q of node pool %s, it must be the flag name without dashes", k, p.Name)
+		}
+	}
+	if p.ContainerRuntime != nil && p.ContainerRuntime.Type != CRIDocker && p.ContainerRuntime.Type != CRIContainerd {
+		return fmt.Errorf("unsupported container runtime %q of node pool %s", p.ContainerRuntime.Type, p.Name)
+	}
+	if p.WorkerCount != nil && *p.WorkerCount < 0 {
+		return fmt.Errorf("invalid worker count %d of node pool %s", *p.WorkerCount, p.Name)
+	}
+	if p.Provider != nil {
+		if errs := validation.IsDNS1123Label(p.Provider.Name); p.Provider.Name != "" && len(errs) > 0 {
+			return fmt.Errorf("invalid node provider %q of node pool %s: %s", p.Provider.Name, p.Name, strings.Join(errs, ", "))
+		}
+		if err := validateLabels(p.Provider.Selector); err != nil {
+			return fmt.Errorf("invalid node selector of node pool %s: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func validateLabels(labels map[string]string) error {
+	for k, v := range labels {
+		if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+			return fmt.Errorf("label %q: %s", k, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+			return fmt.Errorf("value of label %s: %s", k, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// KubeletExtraArgs returns the kubelet arguments of the pool as command line flags, sorted by name.
+func (p *NodePool) KubeletExtraArgs() string {
+	keys := make([]string, 0, len(p.KubeletArgs))
+	for k := range p.KubeletArgs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	flags := make([]string, 0, len(keys))
+	for _, k := range keys {
+		flags = append(flags, fmt.Sprintf("--%s=%s", k, p.KubeletArgs[k]))
+	}
+	return strings.Join(flags, " ")
+}
+
+// ValidateNodePools checks every pool, the pool names are unique and the workers belong to existing pools.
+func (k *Kubeadm) ValidateNodePools() error {
+	names := sets.NewString()
+	for i := range k.NodePools {
+		if err := k.NodePools[i].Validate(); err != nil {
+			return err
+		}
+		if names.Has(k.NodePools[i].Name) {
+			return fmt.Errorf("node pool %s is defined more than once", k.NodePools[i].Name)
+		}
+		names.Insert(k.NodePools[i].Name)
+	}
+	for _, w := range k.Workers {
+		if w.Pool != "" && !names.Has(w.Pool) {
+			return fmt.Errorf("worker %s belongs to node pool %s which does not exist", w.ID, w.Pool)
+		}
+	}
+	return nil
+}
+
+// NodePool returns the pool of the name, nil if the cluster has none.
+func (k *Kubeadm) NodePool(name string) *NodePool {
+	for i := range k.NodePools {
+		if k.NodePools[i].Name == name {
+			return &k.NodePools[i]
+		}
+	}
+	return nil
+}
+
+// PoolOf returns the pool of the worker, nil if the node is in no pool.
+func (k *Kubeadm) PoolOf(nodeID string) *NodePool {
+	for _, w := range k.Workers {
+		if w.ID == nodeID && w.Pool != "" {
+			return k.NodePool(w.Pool)
+		}
+	}
+	return nil
+}
+
+// PoolWorkers returns the workers of the pool.
+func (k *Kubeadm) PoolWorkers(name string) WorkerNodeList {
+	var out WorkerNodeList
+	for _, w := range k.Workers {
+		if w.Pool == name {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// ContainerRuntimeOf returns the container runtime of the node, the one of its pool or of the cluster.
+func (k *Kubeadm) ContainerRuntimeOf(nodeID string) *ContainerRuntime {
+	if p := k.PoolOf(nodeID); p != nil && p.ContainerRuntime != nil {
+		return p.ContainerRuntime
+	}
+	return &k.ContainerRuntime
+}
+
+// EffectiveWorker returns the worker with the labels and taints of its pool merged in,
+// and the pool label which identifies the nodes of the pool in kubernetes.
+func (k *Kubeadm) EffectiveWorker(w WorkerNode) WorkerNode {
+	p := k.NodePool(w.Pool)
+	if p == nil {
+		return w
+	}
+	out := *w.DeepCopy()
+	labels := map[string]string{common.LabelNodePool: p.Name}
+	for key, v := range p.Labels {
+		labels[key] = v
+	}
+	for key, v := range w.Labels {
+		labels[key] = v
+	}
+	out.Labels = labels
+	out.Taints = nil
+	keys := sets.NewString()
+	for _, t := range w.Taints {
+		keys.Insert(t.Key + ":" + string(t.Effect))
+		out.Taints = append(out.Taints, t)
+	}
+	for _, t := range p.Taints {
+		if !keys.Has(t.Key + ":" + string(t.Effect)) {
+			out.Taints = append(out.Taints, t)
+		}
+	}
+	return out
+}
+
+// EffectiveWorkers returns the workers with the settings of their pools merged in.
+func (k *Kubeadm) EffectiveWorkers() WorkerNodeList {
+	out := make(WorkerNodeList, 0, len(k.Workers))
+	for _, w := range k.Workers {
+		out = append(out, k.EffectiveWorker(w))
+	}
+	return out
+}
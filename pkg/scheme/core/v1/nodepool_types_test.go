@@ -0,0 +1,95 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+func TestValidateNodePools(t *testing.T) {
+	gpu := NodePool{
+		Name:             "gpu",
+		Labels:           map[string]string{"nvidia.com/gpu": "true"},
+		Taints:           []Taint{{Key: "nvidia.com/gpu", Effect: TaintEffectNoSchedule}},
+		KubeletArgs:      map[string]string{"max-pods": "200"},
+		ContainerRuntime: &ContainerRuntime{Type: CRIContainerd},
+		WorkerCount:      new(int),
+		Provider:         &NodePoolProvider{Name: "cloud", Selector: map[string]string{"gpu": "true"}},
+	}
+	k := &Kubeadm{NodePools: []NodePool{gpu}, Workers: WorkerNodeList{{ID: "w1", Pool: "gpu"}, {ID: "w2"}}}
+	if err := k.ValidateNodePools(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	negative := -1
+	for _, k := range []*Kubeadm{
+		{NodePools: []NodePool{{Name: "GPU"}}},
+		{NodePools: []NodePool{gpu, gpu}},
+		{NodePools: []NodePool{{Name: "edge", Taints: []Taint{{Key: "edge", Effect: "Never"}}}}},
+		{NodePools: []NodePool{{Name: "edge", KubeletArgs: map[string]string{"--max-pods": "10"}}}},
+		{NodePools: []NodePool{{Name: "edge", ContainerRuntime: &ContainerRuntime{Type: "cri-o"}}}},
+		{NodePools: []NodePool{gpu}, Workers: WorkerNodeList{{ID: "w1", Pool: "edge"}}},
+		{NodePools: []NodePool{{Name: "edge", WorkerCount: &negative}}},
+		{NodePools: []NodePool{{Name: "edge", Provider: &NodePoolProvider{Name: "Cloud"}}}},
+		{NodePools: []NodePool{{Name: "edge", Provider: &NodePoolProvider{Selector: map[string]string{"-x": "y"}}}}},
+	} {
+		if err := k.ValidateNodePools(); err == nil {
+			t.Errorf("expected an error for %+v", k)
+		}
+	}
+}
+
+func TestNodePoolOfWorkers(t *testing.T) {
+	k := &Kubeadm{
+		ContainerRuntime: ContainerRuntime{Type: CRIDocker},
+		NodePools: []NodePool{{
+			Name:             "gpu",
+			Labels:           map[string]string{"accelerator": "gpu", "zone": "a"},
+			Taints:           []Taint{{Key: "gpu", Effect: TaintEffectNoSchedule}},
+			KubeletArgs:      map[string]string{"max-pods": "200", "cpu-manager-policy": "static"},
+			ContainerRuntime: &ContainerRuntime{Type: CRIContainerd},
+		}},
+		Workers: WorkerNodeList{
+			{ID: "w1", Pool: "gpu", Labels: map[string]string{"zone": "b"}},
+			{ID: "w2"},
+		},
+	}
+	if got := k.ContainerRuntimeOf("w1").Type; got != CRIContainerd {
+		t.Errorf("ContainerRuntimeOf(w1) = %s, want the runtime of the pool", got)
+	}
+	if got := k.ContainerRuntimeOf("w2").Type; got != CRIDocker {
+		t.Errorf("ContainerRuntimeOf(w2) = %s, want the runtime of the cluster", got)
+	}
+	if got := k.NodePool("gpu").KubeletExtraArgs(); got != "--cpu-manager-policy=static --max-pods=200" {
+		t.Errorf("KubeletExtraArgs() = %q", got)
+	}
+	workers := k.EffectiveWorkers()
+	want := map[string]string{common.LabelNodePool: "gpu", "accelerator": "gpu", "zone": "b"}
+	if !reflect.DeepEqual(workers[0].Labels, want) || len(workers[0].Taints) != 1 {
+		t.Errorf("EffectiveWorkers()[0] = %+v", workers[0])
+	}
+	if !reflect.DeepEqual(workers[1], k.Workers[1]) {
+		t.Errorf("EffectiveWorkers()[1] = %+v, want the worker unchanged", workers[1])
+	}
+	if k.Workers[0].Labels["accelerator"] != "" {
+		t.Errorf("EffectiveWorkers() modified the workers of the cluster")
+	}
+}
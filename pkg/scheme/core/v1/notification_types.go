@@ -0,0 +1,282 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type NotificationEventType string
+
+const (
+	// NotificationOperationFailed is sent when an operation of a cluster fails.
+	NotificationOperationFailed NotificationEventType = "OperationFailed"
+	// NotificationClusterUnhealthy is sent when the kubernetes of a cluster stops reporting healthy.
+	NotificationClusterUnhealthy NotificationEventType = "ClusterUnhealthy"
+	// NotificationBackupFailed is sent when a backup of a cluster fails.
+	NotificationBackupFailed NotificationEventType = "BackupFailed"
+	// NotificationNodeOffline is sent when the agent of a node stops posting its status.
+	NotificationNodeOffline NotificationEventType = "NodeOffline"
+	// NotificationCertificateExpiring is sent when control plane certificates of a cluster are about to expire.
+	NotificationCertificateExpiring NotificationEventType = "CertificateExpiring"
+)
+
+func (t NotificationEventType) Valid() bool {
+	switch t {
+	case NotificationOperationFailed, NotificationClusterUnhealthy, NotificationBackupFailed, NotificationNodeOffline,
+		NotificationCertificateExpiring:
+		return true
+	}
+	return false
+}
+
+type NotifierType string
+
+const (
+	// NotifierWebhook posts the notification as JSON to a generic HTTP endpoint.
+	NotifierTypeWebhook NotifierType = "webhook"
+	NotifierTypeSlack   NotifierType = "slack"
+	// NotifierDingTalk posts to a DingTalk robot, signed when a secret is set.
+	NotifierTypeDingTalk NotifierType = "dingtalk"
+	NotifierTypeWeCom    NotifierType = "wecom"
+	NotifierTypeEmail    NotifierType = "email"
+)
+
+const (
+	DefaultNotifierRetryLimit   = 3
+	DefaultNotifierRetryBackoff = 30 * time.Second
+	maxNotifierRetryBackoff     = time.Hour
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=false
+
+// Notifier is a channel registered by admins to be told about platform events,
+// such as failed operations or offline nodes.
+type Notifier struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Type NotifierType `json:"type"`
+	// Events the notifier subscribes to, all events when empty.
+	Events []NotificationEventType `json:"events,omitempty" optional:"true"`
+	// Clusters limits the notifier to the events of these clusters, all clusters when empty.
+	Clusters []string         `json:"clusters,omitempty" optional:"true"`
+	Disabled bool             `json:"disabled,omitempty" optional:"true"`
+	Webhook  *NotifierWebhook `json:"webhook,omitempty" optional:"true"`
+	Email    *NotifierEmail   `json:"email,omitempty" optional:"true"`
+	Retry    NotifierRetry    `json:"retry,omitempty" optional:"true"`
+}
+
+// NotifierWebhook is the endpoint of the webhook, slack, dingtalk and wecom notifiers.
+type NotifierWebhook struct {
+	URL string `json:"url"`
+	// Headers are added to the requests of generic webhooks.
+	Headers map[string]string `json:"headers,omitempty" optional:"true"`
+	// Secret signs the requests to DingTalk robots with the signature security setting.
+	Secret             string `json:"secret,omitempty" optional:"true"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty" optional:"true"`
+}
+
+type NotifierEmail struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty" optional:"true"`
+	Password string   `json:"password,omitempty" optional:"true"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	// TLS connects with implicit TLS, usually on port 465. Otherwise STARTTLS
+	// is used when the server offers it.
+	TLS                bool `json:"tls,omitempty" optional:"true"`
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty" optional:"true"`
+}
+
+type NotifierRetry struct {
+	// Limit is the number of retries after the first failed delivery, 3 by default.
+	Limit int `json:"limit,omitempty" optional:"true"`
+	// Backoff is the wait before the first retry, 30s by default. It doubles for
+	// every further retry, up to an hour.
+	Backoff metav1.Duration `json:"backoff,omitempty" optional:"true"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// NotifierList contains a list of Notifier
+
+type NotifierList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Notifier `json:"items"`
+}
+
+type NotificationPhase string
+
+const (
+	NotificationPending   NotificationPhase = "Pending"
+	NotificationSucceeded NotificationPhase = "Succeeded"
+	NotificationFailed    NotificationPhase = "Failed"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=false
+
+// Notification is the delivery of one event to one notifier, the notifications
+// are kept as the delivery history.
+type Notification struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Notifier string             `json:"notifier"`
+	Event    NotificationEvent  `json:"event"`
+	Status   NotificationStatus `json:"status,omitempty"`
+}
+
+type NotificationEvent struct {
+	Type    NotificationEventType `json:"type"`
+	Cluster string                `json:"cluster,omitempty" optional:"true"`
+	// Object is the name of the operation, backup or node the event is about.
+	Object  string      `json:"object"`
+	Message string      `json:"message,omitempty" optional:"true"`
+	Time    metav1.Time `json:"time"`
+}
+
+type NotificationStatus struct {
+	Phase           NotificationPhase `json:"phase,omitempty"`
+	Attempts        int               `json:"attempts,omitempty"`
+	LastError       string            `json:"lastError,omitempty" optional:"true"`
+	LastAttemptTime *metav1.Time      `json:"lastAttemptTime,omitempty" optional:"true"`
+	// NextAttemptTime is when a pending notification is retried.
+	NextAttemptTime *metav1.Time `json:"nextAttemptTime,omitempty" optional:"true"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// NotificationList contains a list of Notification
+
+type NotificationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Notification `json:"items"`
+}
+
+func (n *Notifier) Validate() error {
+	for _, e := range n.Events {
+		if !e.Valid() {
+			return fmt.Errorf("unsupported notification event %q", e)
+		}
+	}
+	if n.Retry.Limit < 0 || n.Retry.Backoff.Duration < 0 {
+		return fmt.Errorf("retry limit and backoff must not be negative")
+	}
+	switch n.Type {
+	case NotifierTypeWebhook, NotifierTypeSlack, NotifierTypeDingTalk, NotifierTypeWeCom:
+		if n.Webhook == nil {
+			return fmt.Errorf("%s notifier must have a webhook", n.Type)
+		}
+		u, err := url.Parse(n.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url %q", n.Webhook.URL)
+		}
+	case NotifierTypeEmail:
+		e := n.Email
+		if e == nil {
+			return fmt.Errorf("email notifier must have the smtp settings")
+		}
+		if e.Host == "" || e.Port <= 0 || e.Port > 65535 {
+			return fmt.Errorf("invalid smtp server %s:%d", e.Host, e.Port)
+		}
+		if len(e.To) == 0 {
+			return fmt.Errorf("email notifier must have at least one recipient")
+		}
+		for _, addr := range append([]string{e.From}, e.To...) {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("invalid email address %q: %v", addr, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported notifier type %q", n.Type)
+	}
+	return nil
+}
+
+// Match reports whether the event has to be delivered to the notifier.
+func (n *Notifier) Match(e *NotificationEvent) bool {
+	if n.Disabled {
+		return false
+	}
+	if len(n.Events) > 0 && !eventTypesHas(n.Events, e.Type) {
+		return false
+	}
+	return len(n.Clusters) == 0 || sets.NewString(n.Clusters...).Has(e.Cluster)
+}
+
+func eventTypesHas(types []NotificationEventType, t NotificationEventType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func (r NotifierRetry) RetryLimit() int {
+	if r.Limit == 0 {
+		return DefaultNotifierRetryLimit
+	}
+	return r.Limit
+}
+
+// RetryBackoff returns the wait before the retry following the given number of attempts.
+func (r NotifierRetry) RetryBackoff(attempts int) time.Duration {
+	backoff := r.Backoff.Duration
+	if backoff == 0 {
+		backoff = DefaultNotifierRetryBackoff
+	}
+	for i := 1; i < attempts && backoff < maxNotifierRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxNotifierRetryBackoff {
+		backoff = maxNotifierRetryBackoff
+	}
+	return backoff
+}
+
+// Due reports whether a pending notification has to be delivered at now.
+func (n *Notification) Due(now time.Time) bool {
+	if n.Status.Phase != NotificationPending {
+		return false
+	}
+	return n.Status.NextAttemptTime == nil || !now.Before(n.Status.NextAttemptTime.Time)
+}
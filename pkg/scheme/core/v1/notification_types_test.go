@@ -0,0 +1,80 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNotifierValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		notifier Notifier
+		wantErr  bool
+	}{
+		{"webhook", Notifier{Type: NotifierTypeWebhook, Webhook: &NotifierWebhook{URL: "https://example.com/hook"}}, false},
+		{"webhook without url", Notifier{Type: NotifierTypeSlack, Webhook: &NotifierWebhook{URL: "example.com"}}, true},
+		{"missing webhook", Notifier{Type: NotifierTypeDingTalk}, true},
+		{"email", Notifier{Type: NotifierTypeEmail, Email: &NotifierEmail{Host: "smtp.example.com", Port: 465,
+			From: "kc@example.com", To: []string{"ops@example.com"}}}, false},
+		{"email without recipient", Notifier{Type: NotifierTypeEmail, Email: &NotifierEmail{Host: "smtp.example.com", Port: 465,
+			From: "kc@example.com"}}, true},
+		{"unknown event", Notifier{Type: NotifierTypeWebhook, Webhook: &NotifierWebhook{URL: "https://example.com/hook"},
+			Events: []NotificationEventType{"ClusterCreated"}}, true},
+		{"unknown type", Notifier{Type: "sms"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.notifier.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotifierMatch(t *testing.T) {
+	n := Notifier{Events: []NotificationEventType{NotificationNodeOffline}, Clusters: []string{"demo"}}
+	if !n.Match(&NotificationEvent{Type: NotificationNodeOffline, Cluster: "demo"}) {
+		t.Error("expected the subscribed event to match")
+	}
+	if n.Match(&NotificationEvent{Type: NotificationBackupFailed, Cluster: "demo"}) {
+		t.Error("an event not subscribed must not match")
+	}
+	if n.Match(&NotificationEvent{Type: NotificationNodeOffline, Cluster: "other"}) {
+		t.Error("an event of another cluster must not match")
+	}
+	n.Disabled = true
+	if n.Match(&NotificationEvent{Type: NotificationNodeOffline, Cluster: "demo"}) {
+		t.Error("a disabled notifier must not match")
+	}
+}
+
+func TestNotifierRetryBackoff(t *testing.T) {
+	r := NotifierRetry{}
+	if got := r.RetryBackoff(1); got != DefaultNotifierRetryBackoff {
+		t.Errorf("RetryBackoff(1) = %v, want %v", got, DefaultNotifierRetryBackoff)
+	}
+	if got := r.RetryBackoff(3); got != 4*DefaultNotifierRetryBackoff {
+		t.Errorf("RetryBackoff(3) = %v, want %v", got, 4*DefaultNotifierRetryBackoff)
+	}
+	if got := r.RetryBackoff(100); got != time.Hour {
+		t.Errorf("RetryBackoff(100) = %v, want 1h", got)
+	}
+}
@@ -0,0 +1,97 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ObjectEventNormal events are expected happenings, e.g. a backup deleted with its cluster.
+	ObjectEventNormal = "Normal"
+	// ObjectEventWarning events are happenings which may need attention, e.g. a failed step.
+	ObjectEventWarning = "Warning"
+
+	// ObjectEventTTL is how long an event is kept after it was last recorded, expired events are
+	// deleted by etcd.
+	ObjectEventTTL = time.Hour
+)
+
+// Reasons of the events recorded by kubeclipper.
+const (
+	EventReasonStepFailed        = "StepFailed"
+	EventReasonNodeHeartbeatLost = "NodeHeartbeatLost"
+	EventReasonGarbageCollected  = "GarbageCollected"
+)
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=false
+
+// ObjectEvent is a noteworthy happening of a kubeclipper object recorded by the controllers,
+// such as a failed step of an operation or a node whose agent stopped posting its status.
+// Repeated happenings are recorded once with a count. It is named apart from the audit Event.
+type ObjectEvent struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// InvolvedObject is the object the event is about.
+	InvolvedObject ObjectReference `json:"involvedObject"`
+	// Type is Normal or Warning.
+	Type string `json:"type"`
+	// Reason is a short CamelCase cause of the event, e.g. StepFailed.
+	Reason  string            `json:"reason"`
+	Message string            `json:"message,omitempty" optional:"true"`
+	Source  ObjectEventSource `json:"source,omitempty" optional:"true"`
+	// FirstTimestamp and LastTimestamp are when the event was first and last recorded.
+	FirstTimestamp metav1.Time `json:"firstTimestamp,omitempty"`
+	LastTimestamp  metav1.Time `json:"lastTimestamp,omitempty"`
+	// Count is the number of times the event was recorded.
+	Count int32 `json:"count,omitempty"`
+}
+
+// ObjectReference refers to a kubeclipper object.
+type ObjectReference struct {
+	Kind string    `json:"kind"`
+	Name string    `json:"name"`
+	UID  types.UID `json:"uid,omitempty" optional:"true"`
+}
+
+// ObjectEventSource is the component which recorded the event.
+type ObjectEventSource struct {
+	Component string `json:"component,omitempty" optional:"true"`
+	Host      string `json:"host,omitempty" optional:"true"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// ObjectEventList contains a list of ObjectEvent
+
+type ObjectEventList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ObjectEvent `json:"items"`
+}
@@ -0,0 +1,214 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+type HookPhase string
+
+const (
+	// HookPhasePre hooks run before the first step of an operation.
+	HookPhasePre HookPhase = "Pre"
+	// HookPhasePost hooks run once an operation has finished, whatever its result.
+	HookPhasePost HookPhase = "Post"
+)
+
+type HookFailurePolicy string
+
+const (
+	// HookFailureAbort fails the operation when the hook fails, a failed pre hook
+	// keeps the steps from running.
+	HookFailureAbort HookFailurePolicy = "Abort"
+	// HookFailureContinue only records the failure of the hook.
+	HookFailureContinue HookFailurePolicy = "Continue"
+)
+
+type HookTarget string
+
+const (
+	HookTargetFirstMaster HookTarget = "FirstMaster"
+	HookTargetMasters     HookTarget = "Masters"
+	HookTargetAllNodes    HookTarget = "AllNodes"
+)
+
+const defaultHookTimeout = time.Minute
+
+// OperationHook runs a webhook call or a script before or after the operations of a
+// cluster. Clusters carry their hooks as a JSON list in the operation hooks annotation.
+type OperationHook struct {
+	Name  string    `json:"name"`
+	Phase HookPhase `json:"phase"`
+	// Operations are the operation types the hook runs for, such as UpgradeCluster,
+	// it runs for all operations if empty.
+	Operations []string `json:"operations,omitempty"`
+	// Webhook and Script are exclusive.
+	Webhook *WebhookHook `json:"webhook,omitempty"`
+	Script  *ScriptHook  `json:"script,omitempty"`
+	// Timeout defaults to 1m.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// FailurePolicy defaults to Abort.
+	FailurePolicy HookFailurePolicy `json:"failurePolicy,omitempty"`
+}
+
+// WebhookHook posts a HookRequest to URL, any response other than 2xx fails the hook.
+type WebhookHook struct {
+	URL                string            `json:"url"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	InsecureSkipVerify bool              `json:"insecureSkipVerify,omitempty"`
+}
+
+// ScriptHook runs a bash script on the nodes of the cluster. The script gets the request
+// in the KC_CLUSTER, KC_OPERATION, KC_OPERATION_TYPE, KC_HOOK_PHASE and KC_OPERATION_STATUS
+// environment variables, a non-zero exit status fails the hook.
+type ScriptHook struct {
+	Content string `json:"content"`
+	// Target defaults to FirstMaster.
+	Target HookTarget `json:"target,omitempty"`
+}
+
+// HookRequest is sent to webhooks.
+type HookRequest struct {
+	Hook          string    `json:"hook"`
+	Phase         HookPhase `json:"phase"`
+	Cluster       string    `json:"cluster"`
+	Operation     string    `json:"operation"`
+	OperationType string    `json:"operationType"`
+	// Status is the result of the operation, empty for pre hooks.
+	Status OperationStatusType `json:"status,omitempty"`
+}
+
+type HookResultStatus string
+
+const (
+	HookResultSuccessful HookResultStatus = "Successful"
+	HookResultFailed     HookResultStatus = "Failed"
+)
+
+// HookResult records a run of a hook of an operation.
+type HookResult struct {
+	Name    string           `json:"name"`
+	Phase   HookPhase        `json:"phase"`
+	Status  HookResultStatus `json:"status"`
+	Message string           `json:"message,omitempty"`
+	StartAt metav1.Time      `json:"startAt,omitempty"`
+	EndAt   metav1.Time      `json:"endAt,omitempty"`
+}
+
+func (h OperationHook) Match(phase HookPhase, operationType string) bool {
+	if h.Phase != phase {
+		return false
+	}
+	if len(h.Operations) == 0 {
+		return true
+	}
+	for _, op := range h.Operations {
+		if op == operationType {
+			return true
+		}
+	}
+	return false
+}
+
+func (h OperationHook) TimeoutDuration() time.Duration {
+	if h.Timeout == nil || h.Timeout.Duration <= 0 {
+		return defaultHookTimeout
+	}
+	return h.Timeout.Duration
+}
+
+func (h OperationHook) Abort() bool {
+	return h.FailurePolicy != HookFailureContinue
+}
+
+func (h OperationHook) Validate() error {
+	if h.Name == "" {
+		return fmt.Errorf("hook name is required")
+	}
+	if h.Phase != HookPhasePre && h.Phase != HookPhasePost {
+		return fmt.Errorf("hook %s: unsupported phase %q, expect %s or %s", h.Name, h.Phase, HookPhasePre, HookPhasePost)
+	}
+	switch h.FailurePolicy {
+	case "", HookFailureAbort, HookFailureContinue:
+	default:
+		return fmt.Errorf("hook %s: unsupported failure policy %q", h.Name, h.FailurePolicy)
+	}
+	if (h.Webhook == nil) == (h.Script == nil) {
+		return fmt.Errorf("hook %s: exactly one of webhook and script is required", h.Name)
+	}
+	if h.Webhook != nil {
+		u, err := url.Parse(h.Webhook.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("hook %s: invalid webhook url %q", h.Name, h.Webhook.URL)
+		}
+	}
+	if h.Script != nil {
+		if h.Script.Content == "" {
+			return fmt.Errorf("hook %s: script content is required", h.Name)
+		}
+		switch h.Script.Target {
+		case "", HookTargetFirstMaster, HookTargetMasters, HookTargetAllNodes:
+		default:
+			return fmt.Errorf("hook %s: unsupported script target %q", h.Name, h.Script.Target)
+		}
+	}
+	return nil
+}
+
+// OperationHooks returns the hooks the cluster carries in its annotation.
+func (c *Cluster) OperationHooks() ([]OperationHook, error) {
+	v, ok := c.Annotations[common.AnnotationOperationHooks]
+	if !ok || v == "" {
+		return nil, nil
+	}
+	var hooks []OperationHook
+	if err := json.Unmarshal([]byte(v), &hooks); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %v", common.AnnotationOperationHooks, err)
+	}
+	names := make(map[string]struct{}, len(hooks))
+	for _, h := range hooks {
+		if err := h.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := names[h.Name]; ok {
+			return nil, fmt.Errorf("duplicate hook %s", h.Name)
+		}
+		names[h.Name] = struct{}{}
+	}
+	return hooks, nil
+}
+
+// HookSucceeded reports whether the hook has already run successfully in the phase,
+// so that resuming an operation does not run its pre hooks again.
+func (s *OperationStatus) HookSucceeded(name string, phase HookPhase) bool {
+	for _, r := range s.Hooks {
+		if r.Name == name && r.Phase == phase && r.Status == HookResultSuccessful {
+			return true
+		}
+	}
+	return false
+}
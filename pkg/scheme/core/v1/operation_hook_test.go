@@ -0,0 +1,89 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+func TestClusterOperationHooks(t *testing.T) {
+	tests := []struct {
+		name       string
+		annotation string
+		wantHooks  int
+		wantErr    bool
+	}{
+		{name: "none", wantHooks: 0},
+		{
+			name:       "webhook and script",
+			annotation: `[{"name":"cmdb","phase":"Pre","webhook":{"url":"https://cmdb.example.com"}},{"name":"smoke","phase":"Post","script":{"content":"true","target":"Masters"},"failurePolicy":"Continue"}]`,
+			wantHooks:  2,
+		},
+		{name: "invalid json", annotation: `{`, wantErr: true},
+		{name: "unknown phase", annotation: `[{"name":"a","phase":"During","script":{"content":"true"}}]`, wantErr: true},
+		{name: "both actions", annotation: `[{"name":"a","phase":"Pre","script":{"content":"true"},"webhook":{"url":"http://a"}}]`, wantErr: true},
+		{name: "no action", annotation: `[{"name":"a","phase":"Pre"}]`, wantErr: true},
+		{name: "bad url", annotation: `[{"name":"a","phase":"Pre","webhook":{"url":"ftp://a"}}]`, wantErr: true},
+		{name: "bad target", annotation: `[{"name":"a","phase":"Pre","script":{"content":"true","target":"Workers"}}]`, wantErr: true},
+		{name: "bad policy", annotation: `[{"name":"a","phase":"Pre","script":{"content":"true"},"failurePolicy":"Retry"}]`, wantErr: true},
+		{name: "duplicate", annotation: `[{"name":"a","phase":"Pre","script":{"content":"true"}},{"name":"a","phase":"Post","script":{"content":"true"}}]`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Cluster{}
+			if tt.annotation != "" {
+				c.Annotations = map[string]string{common.AnnotationOperationHooks: tt.annotation}
+			}
+			hooks, err := c.OperationHooks()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OperationHooks() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(hooks) != tt.wantHooks {
+				t.Errorf("OperationHooks() got %d hooks, want %d", len(hooks), tt.wantHooks)
+			}
+		})
+	}
+}
+
+func TestOperationHookMatch(t *testing.T) {
+	h := OperationHook{Name: "a", Phase: HookPhasePre, Operations: []string{OperationUpgradeCluster}}
+	if !h.Match(HookPhasePre, OperationUpgradeCluster) {
+		t.Error("hook should match its operation")
+	}
+	if h.Match(HookPhasePost, OperationUpgradeCluster) || h.Match(HookPhasePre, OperationAddNodes) {
+		t.Error("hook should not match other phases or operations")
+	}
+	h.Operations = nil
+	if !h.Match(HookPhasePre, OperationAddNodes) {
+		t.Error("hook without operations should match all of them")
+	}
+	if !h.Abort() || h.TimeoutDuration() != defaultHookTimeout {
+		t.Error("unexpected defaults")
+	}
+
+	s := OperationStatus{Hooks: []HookResult{
+		{Name: "a", Phase: HookPhasePre, Status: HookResultFailed},
+		{Name: "b", Phase: HookPhasePre, Status: HookResultSuccessful},
+	}}
+	if s.HookSucceeded("a", HookPhasePre) || !s.HookSucceeded("b", HookPhasePre) || s.HookSucceeded("b", HookPhasePost) {
+		t.Error("unexpected hook results")
+	}
+}
@@ -34,8 +34,10 @@ type Operation struct {
 	// Standard object's metadata.
 	// +optional
 	metav1.ObjectMeta `json:"metadata,omitempty"`
-	Steps             []Step          `json:"steps,omitempty"`
-	Status            OperationStatus `json:"status,omitempty"`
+	Steps             []Step `json:"steps,omitempty"`
+	// StepPolicies override the timeout and retry settings of matching steps.
+	StepPolicies []StepPolicy    `json:"stepPolicies,omitempty"`
+	Status       OperationStatus `json:"status,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -68,11 +70,42 @@ const (
 	OperationStatusFailed     OperationStatusType = "failed"
 	OperationStatusUnknown    OperationStatusType = "unknown"
 	OperationStatusSuccessful OperationStatusType = "successful"
+	// OperationStatusPaused means the operation stopped between two steps and can be resumed.
+	OperationStatusPaused OperationStatusType = "paused"
+	// OperationStatusCancelled means the operation was stopped on request, its remaining steps are skipped.
+	OperationStatusCancelled OperationStatusType = "cancelled"
 )
 
 type OperationStatus struct {
 	Status     OperationStatusType  `json:"status,omitempty"`
 	Conditions []OperationCondition `json:"conditions,omitempty"`
+	// Progress is the latest phase reported by the agents for the steps
+	// able to report one, such as kubeadm init and join.
+	// +optional
+	Progress []StepProgress `json:"progress,omitempty"`
+	// Hooks are the results of the hooks of the cluster run for the operation.
+	// +optional
+	Hooks []HookResult `json:"hooks,omitempty"`
+}
+
+// StepProgress is the phase a step has reached on a node while it is running.
+type StepProgress struct {
+	StepID    string      `json:"stepID"`
+	Node      string      `json:"node"`
+	Phase     string      `json:"phase"`
+	Message   string      `json:"message,omitempty"`
+	UpdatedAt metav1.Time `json:"updatedAt"`
+}
+
+// SetStepProgress records the progress of a step on a node, replacing the one reported before.
+func (s *OperationStatus) SetStepProgress(p StepProgress) {
+	for i := range s.Progress {
+		if s.Progress[i].StepID == p.StepID && s.Progress[i].Node == p.Node {
+			s.Progress[i] = p
+			return
+		}
+	}
+	s.Progress = append(s.Progress, p)
 }
 
 type StepAction string
@@ -89,12 +122,20 @@ const (
 	OperationCreateCluster       = "CreateCluster"
 	OperationDeleteCluster       = "DeleteCluster"
 	OperationUpgradeCluster      = "UpgradeCluster"
+	OperationRollbackUpgrade     = "RollbackUpgrade"
 	OperationAddNodes            = "AddNodes"
 	OperationRemoveNodes         = "RemoveNodes"
 	OperationBackupCluster       = "BackupCluster"
 	OperationRecoverCluster      = "RecoveryCluster"
 	OperationInstallComponents   = "InstallComponents"
 	OperationUninstallComponents = "UninstallComponents"
+	OperationUpgradeComponents   = "UpgradeComponents"
+	OperationPrewarmNodes        = "PrewarmNodes"
+	OperationEtcdMaintenance     = "EtcdMaintenance"
+	OperationRotateCredentials   = "RotateCredentials"
+	OperationRenewCertificates   = "RenewCertificates"
+	OperationUpdateRegistries    = "UpdateRegistries"
+	OperationUpdateCertSANs      = "UpdateCertSANs"
 )
 
 // Step TODO: add commands struct instead of string
@@ -109,12 +150,19 @@ type Step struct {
 	BeforeRunCommands []Command       `json:"beforeRunCommands,omitempty"`
 	AfterRunCommands  []Command       `json:"afterRunCommands,omitempty"`
 	RetryTimes        int32           `json:"retryTimes,omitempty"`
+	// RetryBackoff is the wait before the first retry, it doubles for every further retry.
+	RetryBackoff metav1.Duration `json:"retryBackoff,omitempty"`
+	// Checkpoint marks a batch boundary, a pause requested on the operation
+	// takes effect once this step finishes.
+	Checkpoint bool `json:"checkpoint,omitempty"`
 }
 
 type StepNode struct {
 	ID       string `json:"id,omitempty"`
 	IPv4     string `json:"ipv4,omitempty"`
 	Hostname string `json:"hostname,omitempty"`
+	Arch     string `json:"arch,omitempty"`
+	OSFamily string `json:"osFamily,omitempty"`
 }
 
 type CommandType string
@@ -123,6 +171,8 @@ const (
 	CommandShell          CommandType = "shell"
 	CommandTemplateRender CommandType = "templateRender"
 	CommandCustom         CommandType = "custom"
+	// CommandPowerShell runs a PowerShell script template on windows nodes.
+	CommandPowerShell CommandType = "powershell"
 )
 
 type TemplateCommand struct {
@@ -136,6 +186,43 @@ type Command struct {
 	Identity      string           `json:"identity,omitempty"`
 	CustomCommand []byte           `json:"customCommand,omitempty"`
 	Template      *TemplateCommand `json:"template,omitempty"`
+	// Container runs the shell command in an ephemeral container instead of the host shell,
+	// only works with shell command.
+	Container *ContainerExecution `json:"container,omitempty"`
+	// PowerShell is the script of powershell command.
+	PowerShell *PowerShellCommand `json:"powershell,omitempty"`
+}
+
+// PowerShellCommand is a script template rendered with Data on the node, values are
+// inserted with {{psquote .Key}} so they are never interpreted by PowerShell.
+type PowerShellCommand struct {
+	Template string            `json:"template"`
+	Data     map[string]string `json:"data,omitempty"`
+}
+
+// ContainerExecution describes the ephemeral container a shell command runs in.
+type ContainerExecution struct {
+	Image      string      `json:"image"`
+	Mounts     []HostMount `json:"mounts,omitempty"`
+	Privileged bool        `json:"privileged,omitempty"`
+	Env        []string    `json:"env,omitempty"`
+}
+
+// ToolImages are the images the kubectl and etcdctl commands of the cluster steps run in,
+// the commands run on the host shell when the image of their tool is empty. The agents of
+// the cluster must have the container executor enabled to run them in containers.
+// The steps the agent runs itself, e.g. draining removed nodes, backups and recoveries,
+// the scripts mixing kubectl with kubeadm and the steps of k3s clusters use the host binaries.
+type ToolImages struct {
+	Kubectl string `json:"kubectl,omitempty"`
+	Etcdctl string `json:"etcdctl,omitempty"`
+}
+
+type HostMount struct {
+	HostPath string `json:"hostPath"`
+	// ContainerPath defaults to HostPath.
+	ContainerPath string `json:"containerPath,omitempty"`
+	ReadOnly      bool   `json:"readOnly,omitempty"`
 }
 
 // OperationCondition contains condition information for a node.
@@ -149,6 +236,7 @@ type StepStatusType string
 const (
 	StepStatusSuccessful StepStatusType = "successful"
 	StepStatusFailed     StepStatusType = "failed"
+	StepStatusSkipped    StepStatusType = "skipped"
 )
 
 type StepStatus struct {
@@ -163,4 +251,120 @@ type StepStatus struct {
 	// +optional
 	Message  string `json:"message,omitempty"`
 	Response []byte `json:"response,omitempty"`
+	// Attempts records every try of a step that is allowed to retry.
+	// +optional
+	Attempts []StepAttempt `json:"attempts,omitempty"`
+}
+
+type StepAttempt struct {
+	StartAt metav1.Time    `json:"startAt,omitempty"`
+	EndAt   metav1.Time    `json:"endAt,omitempty"`
+	Status  StepStatusType `json:"status,omitempty"`
+	Reason  string         `json:"reason,omitempty"`
+	Message string         `json:"message,omitempty"`
+}
+
+func (c *OperationCondition) skipped() bool {
+	for _, st := range c.Status {
+		if st.Status != StepStatusSkipped {
+			return false
+		}
+	}
+	return len(c.Status) > 0
+}
+
+// TrimSkippedConditions drops the trailing conditions of the steps skipped by a cancel.
+func (s *OperationStatus) TrimSkippedConditions() {
+	n := len(s.Conditions)
+	for n > 0 && s.Conditions[n-1].skipped() {
+		n--
+	}
+	s.Conditions = s.Conditions[:n]
+}
+
+// CompletedSteps returns the number of leading steps that ran successfully on
+// every node, or whose failure is ignored. It is the index to resume the operation from.
+func (op *Operation) CompletedSteps() int {
+	for i, cond := range op.Status.Conditions {
+		if i >= len(op.Steps) {
+			return len(op.Steps)
+		}
+		if op.Steps[i].ErrIgnore {
+			continue
+		}
+		if len(cond.Status) == 0 {
+			return i
+		}
+		for _, st := range cond.Status {
+			if st.Status != StepStatusSuccessful {
+				return i
+			}
+		}
+	}
+	return len(op.Status.Conditions)
+}
+
+// ClusterLock tells which operation holds a cluster. The operations of a cluster run one at a time,
+// the cluster status is not Running while one of them is unfinished.
+type ClusterLock struct {
+	Cluster string            `json:"cluster"`
+	Status  ClusterStatusType `json:"status"`
+	Locked  bool              `json:"locked"`
+	// Stale is set when the cluster is locked but no operation of it is unfinished,
+	// e.g. the server running the operation crashed. A stale lock must be broken.
+	Stale bool `json:"stale,omitempty"`
+	// Holder is the unfinished operation started first.
+	Holder *LockHolder `json:"holder,omitempty"`
+	// Queue are the other unfinished operations of the cluster, oldest first.
+	Queue []LockHolder `json:"queue,omitempty"`
+}
+
+// LockHolder is an unfinished operation of a cluster.
+type LockHolder struct {
+	Operation string              `json:"operation"`
+	Action    string              `json:"action,omitempty"`
+	Status    OperationStatusType `json:"status"`
+	Since     metav1.Time         `json:"since"`
+}
+
+// OperationStep is a step of an operation with its status on every node.
+type OperationStep struct {
+	Index  int          `json:"index"`
+	Step   Step         `json:"step"`
+	Status []StepStatus `json:"status,omitempty"`
+}
+
+// Summary returns a copy of the operation without the step commands and the
+// responses of the step status, they are served by the steps sub-resource.
+func (op *Operation) Summary() *Operation {
+	out := op.DeepCopy()
+	for i := range out.Steps {
+		out.Steps[i] = out.Steps[i].Summary()
+	}
+	for i := range out.Status.Conditions {
+		for j := range out.Status.Conditions[i].Status {
+			out.Status.Conditions[i].Status[j].Response = nil
+		}
+	}
+	return out
+}
+
+func (s Step) Summary() Step {
+	s.Commands, s.BeforeRunCommands, s.AfterRunCommands = nil, nil, nil
+	return s
+}
+
+// OperationStep returns the step at index n together with its status.
+func (op *Operation) OperationStep(n int) (OperationStep, bool) {
+	if n < 0 || n >= len(op.Steps) {
+		return OperationStep{}, false
+	}
+	s := OperationStep{Index: n, Step: *op.Steps[n].DeepCopy()}
+	for _, cond := range op.Status.Conditions {
+		if cond.StepID == s.Step.ID {
+			s.Status = cond.DeepCopy().Status
+			break
+		}
+	}
+	return s, true
 }
@@ -0,0 +1,102 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import "testing"
+
+func conditionOf(status ...StepStatusType) OperationCondition {
+	cond := OperationCondition{}
+	for _, st := range status {
+		cond.Status = append(cond.Status, StepStatus{Status: st})
+	}
+	return cond
+}
+
+func TestOperationCompletedSteps(t *testing.T) {
+	steps := []Step{{Name: "a"}, {Name: "b", ErrIgnore: true}, {Name: "c"}, {Name: "d"}}
+	tests := []struct {
+		name       string
+		conditions []OperationCondition
+		want       int
+	}{
+		{name: "not started", want: 0},
+		{name: "paused", conditions: []OperationCondition{conditionOf(StepStatusSuccessful)}, want: 1},
+		{
+			name: "ignored failure",
+			conditions: []OperationCondition{
+				conditionOf(StepStatusSuccessful), conditionOf(StepStatusFailed), conditionOf(StepStatusSuccessful, StepStatusSuccessful),
+			},
+			want: 3,
+		},
+		{
+			name: "cancelled",
+			conditions: []OperationCondition{
+				conditionOf(StepStatusSuccessful), conditionOf(StepStatusSuccessful), conditionOf(StepStatusSuccessful, StepStatusFailed), conditionOf(StepStatusSkipped),
+			},
+			want: 2,
+		},
+		{
+			name: "stopped before the node replied",
+			conditions: []OperationCondition{
+				conditionOf(StepStatusSuccessful), conditionOf(StepStatusSuccessful), conditionOf(StepStatusSuccessful, ""),
+			},
+			want: 2,
+		},
+	}
+	for _, tt := range tests {
+		op := &Operation{Steps: steps, Status: OperationStatus{Conditions: tt.conditions}}
+		if got := op.CompletedSteps(); got != tt.want {
+			t.Errorf("%s: CompletedSteps() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTrimSkippedConditions(t *testing.T) {
+	s := OperationStatus{Conditions: []OperationCondition{
+		conditionOf(StepStatusSuccessful), conditionOf(StepStatusFailed), conditionOf(StepStatusSkipped, StepStatusSkipped), conditionOf(StepStatusSkipped),
+	}}
+	s.TrimSkippedConditions()
+	if len(s.Conditions) != 2 || s.Conditions[1].Status[0].Status != StepStatusFailed {
+		t.Errorf("TrimSkippedConditions() left %+v", s.Conditions)
+	}
+}
+
+func TestOperationSummary(t *testing.T) {
+	op := &Operation{
+		Steps: []Step{{ID: "s1", Name: "a", Commands: []Command{{Type: CommandShell, ShellCommand: []string{"true"}}}}},
+		Status: OperationStatus{Conditions: []OperationCondition{
+			{StepID: "s1", Status: []StepStatus{{Node: "n1", Status: StepStatusSuccessful, Response: []byte("ok")}}},
+		}},
+	}
+	summary := op.Summary()
+	if summary.Steps[0].Commands != nil || summary.Status.Conditions[0].Status[0].Response != nil {
+		t.Errorf("summary keeps step bodies: %+v", summary)
+	}
+	if op.Steps[0].Commands == nil || op.Status.Conditions[0].Status[0].Response == nil {
+		t.Error("summary changed the operation")
+	}
+
+	s, ok := op.OperationStep(0)
+	if !ok || s.Step.Name != "a" || len(s.Step.Commands) != 1 || string(s.Status[0].Response) != "ok" {
+		t.Errorf("unexpected step %+v", s)
+	}
+	if _, ok = op.OperationStep(1); ok {
+		t.Error("expected no step at index 1")
+	}
+}
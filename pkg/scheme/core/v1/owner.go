@@ -0,0 +1,69 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewClusterOwnerReference returns the controller reference of a resource owned by the cluster,
+// ex: the backups and operations of the cluster. The cluster must have been created.
+func NewClusterOwnerReference(c *Cluster) metav1.OwnerReference {
+	return *metav1.NewControllerRef(c, SchemeGroupVersion.WithKind("Cluster"))
+}
+
+// NewBackupOwnerReference returns the controller reference of a recovery restoring the backup.
+func NewBackupOwnerReference(b *Backup) metav1.OwnerReference {
+	return *metav1.NewControllerRef(b, SchemeGroupVersion.WithKind("Backup"))
+}
+
+// SetOwnerReference adds the reference to the owners of the object, an existing reference to
+// the same owner is replaced.
+func SetOwnerReference(obj metav1.Object, ref metav1.OwnerReference) {
+	refs := obj.GetOwnerReferences()
+	for i := range refs {
+		if refs[i].Kind == ref.Kind && refs[i].Name == ref.Name {
+			refs[i] = ref
+			obj.SetOwnerReferences(refs)
+			return
+		}
+	}
+	obj.SetOwnerReferences(append(refs, ref))
+}
+
+// RemoveOwnerReference removes the references to the owners of the kind from the owners of the object.
+func RemoveOwnerReference(obj metav1.Object, kind string) {
+	var refs []metav1.OwnerReference
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.APIVersion != SchemeGroupVersion.String() || ref.Kind != kind {
+			refs = append(refs, ref)
+		}
+	}
+	obj.SetOwnerReferences(refs)
+}
+
+// GetOwnerReference returns the reference to the owner of the kind, nil if the object has none.
+func GetOwnerReference(obj metav1.Object, kind string) *metav1.OwnerReference {
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.APIVersion == SchemeGroupVersion.String() && ref.Kind == kind {
+			return &ref
+		}
+	}
+	return nil
+}
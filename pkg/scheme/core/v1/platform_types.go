@@ -18,7 +18,11 @@
 
 package v1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // +genclient
 // +genclient:nonNamespaced
@@ -32,6 +36,14 @@ type PlatformSetting struct {
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 	Template          DockerRegistry `json:"template,omitempty"`
 	Terminal          WebTerminal    `json:"terminal,omitempty"`
+	Cluster           ClusterPolicy  `json:"cluster,omitempty"`
+	RegistrySync      RegistrySync   `json:"registrySync,omitempty"`
+	// ClusterDefaults are the global defaults of new clusters, region defaults override them.
+	ClusterDefaults ClusterDefaults `json:"clusterDefaults,omitempty"`
+	// Quota is the global quota of every region, region quotas override it.
+	Quota ResourceQuota `json:"quota,omitempty"`
+	// RecycleBin keeps the deleted clusters and backups for a while before they are purged.
+	RecycleBin RecycleBin `json:"recycleBin,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -63,9 +75,86 @@ type MetaResource struct {
 	Name    string `json:"name"`
 	Version string `json:"version"`
 	Arch    string `json:"arch"`
+	// Compatible are the version ranges of the other resources this one works with, keyed by their names,
+	// e.g. {"k8s": ">=v1.20.0 <v1.24.0"}.
+	Compatible map[string]string `json:"compatible,omitempty"`
+	// Features are the capabilities of the resource the platform gates on, e.g. ["ipv6"] for a cni
+	// which can assign ipv6 pod addresses.
+	Features []string `json:"features,omitempty"`
 }
 
+// FeatureIPv6 marks the cni resources which support dual-stack and ipv6-only clusters.
+const FeatureIPv6 = "ipv6"
+
 type WebTerminal struct {
 	PrivateKey string `json:"privateKey,omitempty"`
 	PublicKey  string `json:"publicKey,omitempty"`
 }
+
+// ClusterPolicy holds the platform wide defaults of clusters.
+type ClusterPolicy struct {
+	// DeleteProtection protects the clusters that do not set deleteProtection themselves.
+	DeleteProtection bool `json:"deleteProtection"`
+}
+
+// DefaultRecycleBinRetention is how long the deleted clusters and backups are kept when no retention is set.
+const DefaultRecycleBinRetention = 7 * 24 * time.Hour
+
+// RecycleBin moves the deleted clusters and backups to the recycle bin, they are hidden but can be
+// restored until the retention passes and they are purged. Deleting an object in the recycle bin purges it.
+type RecycleBin struct {
+	Enabled bool `json:"enabled"`
+	// Retention is how long the objects are kept in the recycle bin, defaults to 7 days.
+	Retention metav1.Duration `json:"retention,omitempty"`
+}
+
+// RetentionPeriod returns the retention, or the default when none is set.
+func (b *RecycleBin) RetentionPeriod() time.Duration {
+	if b.Retention.Duration <= 0 {
+		return DefaultRecycleBinRetention
+	}
+	return b.Retention.Duration
+}
+
+// RegistrySync keeps the embedded registry filled with copies of upstream images.
+type RegistrySync struct {
+	// Registry is the host[:port] of the embedded registry the images are pushed to.
+	Registry string `json:"registry"`
+	// Insecure talks to the embedded registry over plain http.
+	Insecure bool   `json:"insecure,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	// Proxy is the http proxy used to pull from upstream registries.
+	Proxy string `json:"proxy,omitempty"`
+	// Interval between two syncs, defaults to one hour.
+	Interval metav1.Duration     `json:"interval,omitempty"`
+	Images   []RegistrySyncImage `json:"images,omitempty"`
+	// +optional
+	Status RegistrySyncStatus `json:"status,omitempty"`
+}
+
+type RegistrySyncImage struct {
+	// Source is the upstream image reference. A reference with a digest
+	// (repo@sha256:...) pins the content, the sync fails if upstream differs.
+	Source string `json:"source"`
+	// Target is the repository[:tag] in the embedded registry,
+	// defaults to the repository and tag of the source.
+	Target   string `json:"target,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+type RegistrySyncStatus struct {
+	LastSyncTime metav1.Time               `json:"lastSyncTime,omitempty"`
+	Images       []RegistrySyncImageStatus `json:"images,omitempty"`
+}
+
+type RegistrySyncImageStatus struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// Digest of the manifest last pushed to the embedded registry.
+	Digest       string      `json:"digest,omitempty"`
+	Synced       bool        `json:"synced"`
+	Message      string      `json:"message,omitempty"`
+	LastSyncTime metav1.Time `json:"lastSyncTime,omitempty"`
+}
@@ -0,0 +1,144 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type PrecheckSource string
+
+const (
+	// PrecheckSourceDeploy is recorded by kcctl deploy for the server and agent nodes.
+	PrecheckSourceDeploy PrecheckSource = "deploy"
+	// PrecheckSourceJoin is recorded by kcctl join for the joined nodes.
+	PrecheckSourceJoin PrecheckSource = "join"
+	// PrecheckSourceNode is recorded by the asynchronous precheck of candidate nodes.
+	PrecheckSourceNode PrecheckSource = "node"
+	// PrecheckSourceClusterCreate is recorded by the server validating the nodes of a new cluster.
+	PrecheckSourceClusterCreate PrecheckSource = "cluster-create"
+)
+
+func (s PrecheckSource) Valid() bool {
+	switch s {
+	case PrecheckSourceDeploy, PrecheckSourceJoin, PrecheckSourceNode, PrecheckSourceClusterCreate:
+		return true
+	}
+	return false
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=false
+
+// Precheck records one run of the prechecks on a set of nodes, so the reason a node
+// was rejected can be looked up later and environments can be compared over time.
+type Precheck struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Source PrecheckSource `json:"source"`
+	// Nodes are the IPs of the checked nodes.
+	Nodes   []string         `json:"nodes"`
+	Results []PrecheckResult `json:"results,omitempty" optional:"true"`
+	// Failed is the number of results which did not pass.
+	Failed      int          `json:"failed"`
+	StartedAt   metav1.Time  `json:"startedAt"`
+	CompletedAt *metav1.Time `json:"completedAt,omitempty" optional:"true"`
+}
+
+// PrecheckResult is the outcome of one check on one node.
+type PrecheckResult struct {
+	Node    string      `json:"node"`
+	Check   string      `json:"check"`
+	Passed  bool        `json:"passed"`
+	Message string      `json:"message,omitempty" optional:"true"`
+	Time    metav1.Time `json:"time"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// PrecheckList contains a list of Precheck
+
+type PrecheckList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Precheck `json:"items"`
+}
+
+// AddResult appends the result of a check, the check passes when err is nil.
+func (p *Precheck) AddResult(node, check string, err error) {
+	r := PrecheckResult{Node: node, Check: check, Passed: err == nil, Time: metav1.Now()}
+	if err != nil {
+		r.Message = err.Error()
+		p.Failed++
+	}
+	p.Results = append(p.Results, r)
+}
+
+// Complete counts the failed results and marks the run completed.
+func (p *Precheck) Complete() {
+	p.Failed = 0
+	for _, r := range p.Results {
+		if !r.Passed {
+			p.Failed++
+		}
+	}
+	now := metav1.Now()
+	p.CompletedAt = &now
+}
+
+// HasNode reports whether the node was checked in the run.
+func (p *Precheck) HasNode(node string) bool {
+	return sets.NewString(p.Nodes...).Has(node)
+}
+
+// NodeResults returns the results of the node in the order they were recorded.
+func (p *Precheck) NodeResults(node string) []PrecheckResult {
+	var results []PrecheckResult
+	for _, r := range p.Results {
+		if r.Node == node {
+			results = append(results, r)
+		}
+	}
+	return results
+}
+
+func (p *Precheck) Validate() error {
+	if !p.Source.Valid() {
+		return fmt.Errorf("unsupported precheck source %q", p.Source)
+	}
+	if len(p.Nodes) == 0 {
+		return fmt.Errorf("precheck must have at least one node")
+	}
+	nodes := sets.NewString(p.Nodes...)
+	for _, r := range p.Results {
+		if !nodes.Has(r.Node) {
+			return fmt.Errorf("result of check %s is recorded for node %s which is not checked", r.Check, r.Node)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// invalidProxyChars can not be written to the systemd units and the shell scripts setting the proxy up.
+const invalidProxyChars = " \t\n\"'\\$`"
+
+// Proxy is the http proxy the nodes of a cluster reach the internet through.
+type Proxy struct {
+	// HTTPProxy is the proxy url of http requests, e.g. http://10.0.0.1:3128.
+	HTTPProxy string `json:"httpProxy,omitempty" optional:"true"`
+	// HTTPSProxy is the proxy url of https requests, the http proxy is used when it is empty.
+	HTTPSProxy string `json:"httpsProxy,omitempty" optional:"true"`
+	// NoProxy are the extra hosts, domains and cidrs reached directly. The cluster subnets, the nodes
+	// and the apiserver endpoints are always reached directly.
+	NoProxy []string `json:"noProxy,omitempty" optional:"true"`
+}
+
+// HTTPSProxyURL returns the proxy url of https requests.
+func (p *Proxy) HTTPSProxyURL() string {
+	if p.HTTPSProxy != "" {
+		return p.HTTPSProxy
+	}
+	return p.HTTPProxy
+}
+
+// Validate checks the proxies are http or https urls.
+func (p *Proxy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.HTTPProxy == "" && p.HTTPSProxy == "" {
+		return fmt.Errorf("proxy requires httpProxy or httpsProxy")
+	}
+	for _, proxy := range []string{p.HTTPProxy, p.HTTPSProxy} {
+		if proxy == "" {
+			continue
+		}
+		u, err := url.Parse(proxy)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.ContainsAny(proxy, invalidProxyChars) {
+			return fmt.Errorf("invalid proxy %q, it must be an http or https url", proxy)
+		}
+	}
+	for _, host := range p.NoProxy {
+		if host == "" || strings.ContainsAny(host, ","+invalidProxyChars) {
+			return fmt.Errorf("invalid noProxy entry %q", host)
+		}
+	}
+	return nil
+}
+
+// ProxyEnv is the proxy environment of the processes on the nodes.
+type ProxyEnv struct {
+	HTTPProxy  string `json:"httpProxy,omitempty"`
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+	// NoProxy is the comma separated hosts, domains and cidrs reached directly.
+	NoProxy string `json:"noProxy,omitempty"`
+}
+
+// ProxyEnv returns the proxy environment of the nodes with the given ips, nil when the cluster has no proxy.
+func (k *Kubeadm) ProxyEnv(nodeIPs []string) *ProxyEnv {
+	if k.Proxy == nil {
+		return nil
+	}
+	return &ProxyEnv{
+		HTTPProxy:  k.Proxy.HTTPProxy,
+		HTTPSProxy: k.Proxy.HTTPSProxyURL(),
+		NoProxy:    strings.Join(k.NoProxy(nodeIPs), ","),
+	}
+}
+
+// NoProxy returns the hosts, domains and cidrs the nodes reach directly: the loopback addresses,
+// the service and pod subnets, the given node ips, the cluster domain, the apiserver endpoints,
+// the local registry and the extra entries of the proxy.
+func (k *Kubeadm) NoProxy(nodeIPs []string) []string {
+	domain := k.Networking.DNSDomain
+	if domain == "" {
+		domain = "cluster.local"
+	}
+	entries := []string{"localhost", "127.0.0.1", "::1"}
+	entries = append(entries, k.Networking.ServiceCIDRs()...)
+	entries = append(entries, k.Networking.PodCIDRs()...)
+	entries = append(entries, nodeIPs...)
+	entries = append(entries, ".svc", "."+domain)
+	entries = append(entries, k.APIServerCertSANs()...)
+	if k.LocalRegistry != "" {
+		host, _ := splitEndpoint(k.LocalRegistry)
+		entries = append(entries, host)
+	}
+	if k.Proxy != nil {
+		entries = append(entries, k.Proxy.NoProxy...)
+	}
+	seen := sets.NewString()
+	var noProxy []string
+	for _, entry := range entries {
+		if entry == "" || seen.Has(entry) {
+			continue
+		}
+		seen.Insert(entry)
+		noProxy = append(noProxy, entry)
+	}
+	return noProxy
+}
@@ -0,0 +1,118 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import "fmt"
+
+// ResourceQuota limits what a region may hold, protecting the shared server and etcd from
+// runaway automation. Like the cluster defaults it is stored globally in the platform setting
+// and per region on the region, a limit set on the region overrides the global one.
+// A nil limit is unlimited.
+type ResourceQuota struct {
+	// Clusters is the number of clusters the region may hold.
+	Clusters *int `json:"clusters,omitempty" optional:"true"`
+	// Nodes is the number of nodes which may register in the region.
+	Nodes *int `json:"nodes,omitempty" optional:"true"`
+	// ConcurrentOperations is the number of operations which may run at the same time in the region.
+	ConcurrentOperations *int `json:"concurrentOperations,omitempty" optional:"true"`
+}
+
+// QuotaUsage counts what a region holds.
+type QuotaUsage struct {
+	Clusters             int `json:"clusters"`
+	Nodes                int `json:"nodes"`
+	ConcurrentOperations int `json:"concurrentOperations"`
+}
+
+// RegionQuota is the effective quota of a region and its usage.
+type RegionQuota struct {
+	Region string        `json:"region"`
+	Hard   ResourceQuota `json:"hard"`
+	Used   QuotaUsage    `json:"used"`
+}
+
+// Validate rejects negative limits.
+func (q *ResourceQuota) Validate() error {
+	for name, limit := range map[string]*int{
+		"clusters":             q.Clusters,
+		"nodes":                q.Nodes,
+		"concurrentOperations": q.ConcurrentOperations,
+	} {
+		if limit != nil && *limit < 0 {
+			return fmt.Errorf("quota of %s must not be negative", name)
+		}
+	}
+	return nil
+}
+
+// MergeResourceQuotas merges the given layers in order, a limit set by a later
+// layer overrides the same limit of the previous ones. Nil layers are skipped.
+func MergeResourceQuotas(layers ...*ResourceQuota) ResourceQuota {
+	var out ResourceQuota
+	for _, l := range layers {
+		if l == nil {
+			continue
+		}
+		if l.Clusters != nil {
+			out.Clusters = intPtr(*l.Clusters)
+		}
+		if l.Nodes != nil {
+			out.Nodes = intPtr(*l.Nodes)
+		}
+		if l.ConcurrentOperations != nil {
+			out.ConcurrentOperations = intPtr(*l.ConcurrentOperations)
+		}
+	}
+	return out
+}
+
+// Admit checks that adding the request to the usage stays within the quota of the region.
+func (q ResourceQuota) Admit(region string, used, request QuotaUsage) error {
+	for _, c := range []struct {
+		resource        string
+		limit           *int
+		used, requested int
+	}{
+		{"clusters", q.Clusters, used.Clusters, request.Clusters},
+		{"nodes", q.Nodes, used.Nodes, request.Nodes},
+		{"concurrentOperations", q.ConcurrentOperations, used.ConcurrentOperations, request.ConcurrentOperations},
+	} {
+		if c.requested > 0 && c.limit != nil && c.used+c.requested > *c.limit {
+			return &QuotaExceededError{Region: region, Resource: c.resource, Limit: *c.limit, Used: c.used, Requested: c.requested}
+		}
+	}
+	return nil
+}
+
+// QuotaExceededError tells which limit of the region a request would exceed.
+type QuotaExceededError struct {
+	Region    string
+	Resource  string
+	Limit     int
+	Used      int
+	Requested int
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("exceeded quota of region %s: requested %s %d, used %d, limited %d",
+		e.Region, e.Resource, e.Requested, e.Used, e.Limit)
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,66 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import "testing"
+
+func TestMergeResourceQuotas(t *testing.T) {
+	platform := &ResourceQuota{Clusters: intPtr(10), ConcurrentOperations: intPtr(5)}
+	region := &ResourceQuota{ConcurrentOperations: intPtr(2), Nodes: intPtr(20)}
+	q := MergeResourceQuotas(platform, nil, region)
+	if *q.Clusters != 10 || *q.Nodes != 20 || *q.ConcurrentOperations != 2 {
+		t.Errorf("unexpected merged quota %+v", q)
+	}
+	*region.Nodes = 30
+	if *q.Nodes != 20 {
+		t.Error("merged quota shares limits with its layers")
+	}
+}
+
+func TestResourceQuotaAdmit(t *testing.T) {
+	q := ResourceQuota{Clusters: intPtr(2), ConcurrentOperations: intPtr(0)}
+	tests := []struct {
+		name     string
+		used     QuotaUsage
+		request  QuotaUsage
+		exceeded string
+	}{
+		{name: "within", used: QuotaUsage{Clusters: 1}, request: QuotaUsage{Clusters: 1}},
+		{name: "clusters", used: QuotaUsage{Clusters: 2}, request: QuotaUsage{Clusters: 1}, exceeded: "clusters"},
+		{name: "unlimited nodes", used: QuotaUsage{Nodes: 1000}, request: QuotaUsage{Nodes: 1}},
+		{name: "no operation", request: QuotaUsage{ConcurrentOperations: 1}, exceeded: "concurrentOperations"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := q.Admit("r1", tt.used, tt.request)
+			if tt.exceeded == "" {
+				if err != nil {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			exceeded, ok := err.(*QuotaExceededError)
+			if !ok || exceeded.Resource != tt.exceeded {
+				t.Errorf("expected %s quota exceeded, got %v", tt.exceeded, err)
+			}
+		})
+	}
+	if err := (&ResourceQuota{Nodes: intPtr(-1)}).Validate(); err == nil {
+		t.Error("negative quota is valid")
+	}
+}
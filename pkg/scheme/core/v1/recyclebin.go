@@ -0,0 +1,71 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+// MarkDeleted moves the object to the recycle bin at the time.
+func MarkDeleted(obj metav1.Object, at time.Time) {
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[common.LabelDeleted] = "true"
+	obj.SetLabels(labels)
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[common.AnnotationDeletedAt] = at.UTC().Format(time.RFC3339)
+	obj.SetAnnotations(annotations)
+}
+
+// UnmarkDeleted takes the object out of the recycle bin.
+func UnmarkDeleted(obj metav1.Object) {
+	labels := obj.GetLabels()
+	delete(labels, common.LabelDeleted)
+	obj.SetLabels(labels)
+	annotations := obj.GetAnnotations()
+	delete(annotations, common.AnnotationDeletedAt)
+	obj.SetAnnotations(annotations)
+}
+
+// IsDeleted reports whether the object is in the recycle bin.
+func IsDeleted(obj metav1.Object) bool {
+	_, ok := obj.GetLabels()[common.LabelDeleted]
+	return ok
+}
+
+// PurgeTime returns when the object in the recycle bin is purged, false if it is not in the recycle bin.
+// An object without a valid deletion time is purged at once.
+func PurgeTime(obj metav1.Object, retention time.Duration) (time.Time, bool) {
+	if !IsDeleted(obj) {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(time.RFC3339, obj.GetAnnotations()[common.AnnotationDeletedAt])
+	if err != nil {
+		return time.Time{}, true
+	}
+	return at.Add(retention), true
+}
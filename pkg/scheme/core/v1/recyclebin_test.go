@@ -0,0 +1,62 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+)
+
+func TestRecycleBin(t *testing.T) {
+	c := &Cluster{}
+	if _, ok := PurgeTime(c, time.Hour); ok || IsDeleted(c) {
+		t.Fatal("expected cluster not in the recycle bin")
+	}
+
+	at := time.Date(2022, 3, 1, 8, 0, 0, 0, time.UTC)
+	MarkDeleted(c, at)
+	if !IsDeleted(c) {
+		t.Fatal("expected cluster in the recycle bin")
+	}
+	if purge, ok := PurgeTime(c, time.Hour); !ok || !purge.Equal(at.Add(time.Hour)) {
+		t.Errorf("unexpected purge time %s", purge)
+	}
+
+	c.Annotations[common.AnnotationDeletedAt] = "yesterday"
+	if purge, ok := PurgeTime(c, time.Hour); !ok || !purge.IsZero() {
+		t.Errorf("expected cluster with an invalid deletion time to be purged at once, got %s", purge)
+	}
+
+	UnmarkDeleted(c)
+	if IsDeleted(c) || c.Annotations[common.AnnotationDeletedAt] != "" {
+		t.Errorf("expected cluster restored, got labels %v annotations %v", c.Labels, c.Annotations)
+	}
+}
+
+func TestRecycleBinRetentionPeriod(t *testing.T) {
+	b := &RecycleBin{}
+	if b.RetentionPeriod() != DefaultRecycleBinRetention {
+		t.Errorf("expected default retention, got %s", b.RetentionPeriod())
+	}
+	b.Retention.Duration = time.Hour
+	if b.RetentionPeriod() != time.Hour {
+		t.Errorf("expected 1h retention, got %s", b.RetentionPeriod())
+	}
+}
@@ -30,6 +30,10 @@ type Region struct {
 	// Standard object's metadata.
 	// +optional
 	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// ClusterDefaults override the platform cluster defaults for clusters created in the region.
+	ClusterDefaults *ClusterDefaults `json:"clusterDefaults,omitempty"`
+	// Quota overrides the limits of the platform quota for the region.
+	Quota *ResourceQuota `json:"quota,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -42,3 +46,13 @@ type RegionList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []Region `json:"items"`
 }
+
+// RegionPrewarm describes the packages and images to cache on the free nodes of a region.
+type RegionPrewarm struct {
+	KubernetesVersion string           `json:"kubernetesVersion"`
+	ContainerRuntime  ContainerRuntime `json:"containerRuntime"`
+	LocalRegistry     string           `json:"localRegistry,omitempty" optional:"true"`
+	Offline           bool             `json:"offline" optional:"true"`
+	// Nodes restricts the prewarm to the given free nodes, all free nodes of the region are used when empty.
+	Nodes []string `json:"nodes,omitempty" optional:"true"`
+}
@@ -78,6 +78,20 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&BackupPointList{},
 		&Template{},
 		&TemplateList{},
+		&ClusterTemplate{},
+		&ClusterTemplateList{},
+		&CronMaintenance{},
+		&CronMaintenanceList{},
+		&Precheck{},
+		&PrecheckList{},
+		&Notifier{},
+		&NotifierList{},
+		&WebhookConfiguration{},
+		&WebhookConfigurationList{},
+		&Notification{},
+		&NotificationList{},
+		&ObjectEvent{},
+		&ObjectEventList{},
 	)
 	return nil
 }
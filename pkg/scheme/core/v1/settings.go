@@ -0,0 +1,115 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ClusterDefaults are the settings filled into a cluster spec that leaves them empty.
+// They are stored at several levels, global in the platform setting and per region
+// on the region, and merged from the most generic to the most specific level when
+// the install operation is rendered.
+type ClusterDefaults struct {
+	// LocalRegistry is used when the cluster does not set kubeadm.localRegistry.
+	LocalRegistry string `json:"localRegistry,omitempty" optional:"true"`
+	// InsecureRegistry is added to the insecure registries of the cluster container runtime.
+	InsecureRegistry []string `json:"insecureRegistry,omitempty" optional:"true"`
+	// DNSDomain is used when the cluster does not set networking.dnsDomain.
+	DNSDomain string `json:"dnsDomain,omitempty" optional:"true"`
+	// NTPServers is used when the cluster does not set kubeadm.ntpServers.
+	NTPServers []string `json:"ntpServers,omitempty" optional:"true"`
+}
+
+// Validate rejects values that can not be rendered into node configuration.
+func (d *ClusterDefaults) Validate() error {
+	for _, v := range append(append([]string{d.LocalRegistry, d.DNSDomain}, d.InsecureRegistry...), d.NTPServers...) {
+		if strings.ContainsAny(v, " \t\n'\"") {
+			return fmt.Errorf("invalid cluster default value %q", v)
+		}
+	}
+	return nil
+}
+
+// MergeClusterDefaults merges the given layers in order, a field set by a later
+// layer overrides the same field of the previous ones. Nil layers are skipped.
+func MergeClusterDefaults(layers ...*ClusterDefaults) ClusterDefaults {
+	var out ClusterDefaults
+	for _, l := range layers {
+		if l == nil {
+			continue
+		}
+		if l.LocalRegistry != "" {
+			out.LocalRegistry = l.LocalRegistry
+		}
+		if len(l.InsecureRegistry) != 0 {
+			out.InsecureRegistry = append([]string(nil), l.InsecureRegistry...)
+		}
+		if l.DNSDomain != "" {
+			out.DNSDomain = l.DNSDomain
+		}
+		if len(l.NTPServers) != 0 {
+			out.NTPServers = append([]string(nil), l.NTPServers...)
+		}
+	}
+	return out
+}
+
+// ApplyTo fills the empty fields of the cluster with the defaults,
+// values already set on the cluster always win.
+func (d ClusterDefaults) ApplyTo(c *Cluster) {
+	if c.Kubeadm == nil {
+		return
+	}
+	if c.Kubeadm.LocalRegistry == "" {
+		c.Kubeadm.LocalRegistry = d.LocalRegistry
+	}
+	if c.Kubeadm.Networking.DNSDomain == "" {
+		c.Kubeadm.Networking.DNSDomain = d.DNSDomain
+	}
+	if len(c.Kubeadm.NTPServers) == 0 && len(d.NTPServers) != 0 {
+		c.Kubeadm.NTPServers = append([]string(nil), d.NTPServers...)
+	}
+	if len(d.InsecureRegistry) == 0 {
+		return
+	}
+	cri := &c.Kubeadm.ContainerRuntime
+	switch cri.Type {
+	case CRIDocker:
+		cri.Docker.InsecureRegistry = appendMissing(cri.Docker.InsecureRegistry, d.InsecureRegistry...)
+	case CRIContainerd:
+		cri.Containerd.InsecureRegistry = appendMissing(cri.Containerd.InsecureRegistry, d.InsecureRegistry...)
+	}
+}
+
+func appendMissing(list []string, items ...string) []string {
+	seen := make(map[string]struct{}, len(list))
+	for _, v := range list {
+		seen[v] = struct{}{}
+	}
+	for _, v := range items {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		list = append(list, v)
+	}
+	return list
+}
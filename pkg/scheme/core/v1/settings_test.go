@@ -0,0 +1,85 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMergeClusterDefaults(t *testing.T) {
+	global := &ClusterDefaults{
+		LocalRegistry:    "global.local:5000",
+		InsecureRegistry: []string{"global.local:5000"},
+		DNSDomain:        "cluster.local",
+		NTPServers:       []string{"ntp.global"},
+	}
+	region := &ClusterDefaults{
+		LocalRegistry: "region.local:5000",
+		NTPServers:    []string{"ntp1.region", "ntp2.region"},
+	}
+	got := MergeClusterDefaults(global, nil, region)
+	want := ClusterDefaults{
+		LocalRegistry:    "region.local:5000",
+		InsecureRegistry: []string{"global.local:5000"},
+		DNSDomain:        "cluster.local",
+		NTPServers:       []string{"ntp1.region", "ntp2.region"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("MergeClusterDefaults() = %+v, want %+v", got, want)
+	}
+	got.NTPServers[0] = "changed"
+	if region.NTPServers[0] != "ntp1.region" {
+		t.Fatalf("merged defaults share memory with the layers")
+	}
+}
+
+func TestClusterDefaultsApplyTo(t *testing.T) {
+	d := ClusterDefaults{
+		LocalRegistry:    "mirror.local:5000",
+		InsecureRegistry: []string{"mirror.local:5000", "other.local"},
+		DNSDomain:        "corp.local",
+		NTPServers:       []string{"ntp.corp"},
+	}
+	c := &Cluster{Kubeadm: &Kubeadm{
+		LocalRegistry: "own.local",
+		ContainerRuntime: ContainerRuntime{
+			Type:       CRIContainerd,
+			Containerd: Containerd{InsecureRegistry: []string{"other.local"}},
+		},
+	}}
+	d.ApplyTo(c)
+
+	if c.Kubeadm.LocalRegistry != "own.local" {
+		t.Errorf("cluster local registry overridden: %s", c.Kubeadm.LocalRegistry)
+	}
+	if c.Kubeadm.Networking.DNSDomain != "corp.local" {
+		t.Errorf("dns domain not defaulted: %s", c.Kubeadm.Networking.DNSDomain)
+	}
+	if !reflect.DeepEqual(c.Kubeadm.NTPServers, []string{"ntp.corp"}) {
+		t.Errorf("ntp servers not defaulted: %v", c.Kubeadm.NTPServers)
+	}
+	want := []string{"other.local", "mirror.local:5000"}
+	if !reflect.DeepEqual(c.Kubeadm.ContainerRuntime.Containerd.InsecureRegistry, want) {
+		t.Errorf("insecure registry = %v, want %v", c.Kubeadm.ContainerRuntime.Containerd.InsecureRegistry, want)
+	}
+	if len(c.Kubeadm.ContainerRuntime.Docker.InsecureRegistry) != 0 {
+		t.Errorf("inactive runtime changed: %v", c.Kubeadm.ContainerRuntime.Docker.InsecureRegistry)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StepPolicy overrides the timeout and retry settings of the steps matching Step.
+type StepPolicy struct {
+	// Step is a step name, a trailing * matches every step with the prefix
+	// and a single * matches all steps.
+	Step         string           `json:"step"`
+	Timeout      *metav1.Duration `json:"timeout,omitempty"`
+	RetryTimes   *int32           `json:"retryTimes,omitempty"`
+	RetryBackoff *metav1.Duration `json:"retryBackoff,omitempty"`
+}
+
+func (p StepPolicy) Match(stepName string) bool {
+	if strings.HasSuffix(p.Step, "*") {
+		return strings.HasPrefix(stepName, strings.TrimSuffix(p.Step, "*"))
+	}
+	return p.Step == stepName
+}
+
+// ApplyStepPolicies overrides the steps with the policies of the operation,
+// later policies win when several of them match the same step.
+func (op *Operation) ApplyStepPolicies() {
+	for i := range op.Steps {
+		for _, p := range op.StepPolicies {
+			if !p.Match(op.Steps[i].Name) {
+				continue
+			}
+			if p.Timeout != nil {
+				op.Steps[i].Timeout = *p.Timeout
+			}
+			if p.RetryTimes != nil {
+				op.Steps[i].RetryTimes = *p.RetryTimes
+			}
+			if p.RetryBackoff != nil {
+				op.Steps[i].RetryBackoff = *p.RetryBackoff
+			}
+		}
+	}
+}
+
+// ParseStepPolicy parses a policy in the form of
+// STEP:timeout=10m,retries=3,backoff=10s, every setting is optional.
+func ParseStepPolicy(s string) (StepPolicy, error) {
+	name, settings, ok := strings.Cut(s, ":")
+	if !ok || name == "" {
+		return StepPolicy{}, fmt.Errorf("invalid step policy %q, expect STEP:key=value,...", s)
+	}
+	p := StepPolicy{Step: name}
+	for _, kv := range strings.Split(settings, ",") {
+		if kv == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok {
+			return StepPolicy{}, fmt.Errorf("invalid step policy setting %q", kv)
+		}
+		switch k {
+		case "timeout", "backoff":
+			d, err := time.ParseDuration(v)
+			if err != nil || d < 0 {
+				return StepPolicy{}, fmt.Errorf("invalid step policy %s %q", k, v)
+			}
+			if k == "timeout" {
+				p.Timeout = &metav1.Duration{Duration: d}
+			} else {
+				p.RetryBackoff = &metav1.Duration{Duration: d}
+			}
+		case "retries":
+			n, err := strconv.ParseInt(v, 10, 32)
+			if err != nil || n < 0 {
+				return StepPolicy{}, fmt.Errorf("invalid step policy retries %q", v)
+			}
+			retries := int32(n)
+			p.RetryTimes = &retries
+		default:
+			return StepPolicy{}, fmt.Errorf("unknown step policy setting %q", k)
+		}
+	}
+	return p, nil
+}
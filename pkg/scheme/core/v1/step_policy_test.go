@@ -0,0 +1,74 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseStepPolicy(t *testing.T) {
+	p, err := ParseStepPolicy("Download*:timeout=20m,retries=3,backoff=10s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Step != "Download*" || p.Timeout.Duration != 20*time.Minute ||
+		*p.RetryTimes != 3 || p.RetryBackoff.Duration != 10*time.Second {
+		t.Errorf("unexpected policy %+v", p)
+	}
+
+	p, err = ParseStepPolicy("*:retries=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Timeout != nil || p.RetryBackoff != nil || *p.RetryTimes != 1 {
+		t.Errorf("unexpected policy %+v", p)
+	}
+
+	for _, s := range []string{"", "Download", ":retries=1", "a:retries=-1", "a:timeout=abc", "a:unknown=1", "a:retries"} {
+		if _, err = ParseStepPolicy(s); err == nil {
+			t.Errorf("ParseStepPolicy(%q) expected error", s)
+		}
+	}
+}
+
+func TestApplyStepPolicies(t *testing.T) {
+	retries, moreRetries := int32(2), int32(5)
+	op := &Operation{
+		Steps: []Step{
+			{Name: "DownloadMasterUpgradePackage", Timeout: metav1.Duration{Duration: time.Minute}},
+			{Name: "RenderUpgradeKubeadm", Timeout: metav1.Duration{Duration: time.Minute}, RetryTimes: 1},
+		},
+		StepPolicies: []StepPolicy{
+			{Step: "*", RetryTimes: &retries},
+			{Step: "Download*", Timeout: &metav1.Duration{Duration: 20 * time.Minute}, RetryTimes: &moreRetries,
+				RetryBackoff: &metav1.Duration{Duration: 5 * time.Second}},
+		},
+	}
+	op.ApplyStepPolicies()
+	download, render := op.Steps[0], op.Steps[1]
+	if download.Timeout.Duration != 20*time.Minute || download.RetryTimes != 5 || download.RetryBackoff.Duration != 5*time.Second {
+		t.Errorf("unexpected download step %+v", download)
+	}
+	if render.Timeout.Duration != time.Minute || render.RetryTimes != 2 || render.RetryBackoff.Duration != 0 {
+		t.Errorf("unexpected render step %+v", render)
+	}
+}
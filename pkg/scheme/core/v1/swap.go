@@ -0,0 +1,117 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+type SwapBehavior string
+
+const (
+	// SwapDisabled turns swap off on the nodes, which kubelet requires by default.
+	SwapDisabled SwapBehavior = "Disabled"
+	// SwapNoSwap keeps the swap of the nodes for system daemons, pods do not use it.
+	SwapNoSwap SwapBehavior = "NoSwap"
+	// SwapLimited lets burstable pods use swap in proportion to their memory request.
+	SwapLimited SwapBehavior = "LimitedSwap"
+	// SwapUnlimited lets pods use swap up to the limit of the node, removed in kubernetes 1.30.
+	SwapUnlimited SwapBehavior = "UnlimitedSwap"
+)
+
+var (
+	swapSupportedVersion = version.MustParseGeneric("v1.28.0")
+	// swapNoSwapVersion introduced NoSwap, removed UnlimitedSwap and enabled the NodeSwap feature gate by default.
+	swapNoSwapVersion = version.MustParseGeneric("v1.30.0")
+)
+
+// Swap is how the nodes of the cluster use swap, it is disabled by default.
+type Swap struct {
+	Behavior SwapBehavior `json:"behavior,omitempty" enum:"Disabled|NoSwap|LimitedSwap|UnlimitedSwap" optional:"true"`
+	// ZramSize sets up a zram swap device of the size on the nodes, e.g. 2Gi.
+	// The existing swap of the nodes is used when it is empty.
+	ZramSize string `json:"zramSize,omitempty" optional:"true"`
+}
+
+// Enabled tells whether the nodes keep their swap.
+func (s Swap) Enabled() bool {
+	return s.Behavior != "" && s.Behavior != SwapDisabled
+}
+
+// NodeSwapFeatureGate tells whether kubelet of the version needs the NodeSwap feature gate
+// turned on for the swap behavior.
+func (s Swap) NodeSwapFeatureGate(kubernetesVersion string) bool {
+	if !s.Enabled() {
+		return false
+	}
+	v, err := version.ParseGeneric(kubernetesVersion)
+	return err == nil && v.LessThan(swapNoSwapVersion)
+}
+
+// Validate checks the swap behavior is supported by the kubernetes version.
+func (s Swap) Validate(kubernetesVersion string) error {
+	if !s.Enabled() {
+		if s.ZramSize != "" {
+			return fmt.Errorf("zram swap requires swap behavior other than %s", SwapDisabled)
+		}
+		return nil
+	}
+	v, err := version.ParseGeneric(kubernetesVersion)
+	if err != nil {
+		return fmt.Errorf("invalid kubernetes version %q: %v", kubernetesVersion, err)
+	}
+	if v.LessThan(swapSupportedVersion) {
+		return fmt.Errorf("swap behavior %s requires kubernetes %s or later", s.Behavior, swapSupportedVersion)
+	}
+	switch s.Behavior {
+	case SwapLimited:
+	case SwapNoSwap:
+		if v.LessThan(swapNoSwapVersion) {
+			return fmt.Errorf("swap behavior %s requires kubernetes %s or later", s.Behavior, swapNoSwapVersion)
+		}
+	case SwapUnlimited:
+		if !v.LessThan(swapNoSwapVersion) {
+			return fmt.Errorf("swap behavior %s is removed in kubernetes %s", s.Behavior, swapNoSwapVersion)
+		}
+	default:
+		return fmt.Errorf("unsupported swap behavior %q", s.Behavior)
+	}
+	if s.ZramSize != "" {
+		q, err := resource.ParseQuantity(s.ZramSize)
+		if err != nil || q.Sign() <= 0 {
+			return fmt.Errorf("invalid zram size %q", s.ZramSize)
+		}
+	}
+	return nil
+}
+
+// ZramBytes is the size of the zram swap device in bytes, 0 if none is set up.
+func (s Swap) ZramBytes() int64 {
+	if !s.Enabled() || s.ZramSize == "" {
+		return 0
+	}
+	q, err := resource.ParseQuantity(s.ZramSize)
+	if err != nil {
+		return 0
+	}
+	return q.Value()
+}
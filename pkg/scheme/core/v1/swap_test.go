@@ -0,0 +1,46 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import "testing"
+
+func TestSwapValidate(t *testing.T) {
+	tests := []struct {
+		version string
+		swap    Swap
+		wantErr bool
+	}{
+		{"v1.23.6", Swap{}, false},
+		{"v1.23.6", Swap{Behavior: SwapDisabled}, false},
+		{"v1.27.4", Swap{Behavior: SwapLimited}, true},
+		{"v1.28.2", Swap{Behavior: SwapLimited, ZramSize: "2Gi"}, false},
+		{"v1.28.2", Swap{Behavior: SwapUnlimited}, false},
+		{"v1.28.2", Swap{Behavior: SwapNoSwap}, true},
+		{"v1.30.0", Swap{Behavior: SwapNoSwap}, false},
+		{"v1.30.0", Swap{Behavior: SwapUnlimited}, true},
+		{"v1.30.0", Swap{Behavior: "Always"}, true},
+		{"v1.30.0", Swap{Behavior: SwapLimited, ZramSize: "lots"}, true},
+		{"v1.30.0", Swap{ZramSize: "2Gi"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.swap.Validate(tt.version); (err != nil) != tt.wantErr {
+			t.Errorf("Validate(%s) of %+v error = %v, wantErr %v", tt.version, tt.swap, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package v1
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+type AdmissionWebhookType string
+
+const (
+	// AdmissionWebhookMutating webhooks may patch the object, they are called before the validating ones.
+	AdmissionWebhookMutating   AdmissionWebhookType = "Mutating"
+	AdmissionWebhookValidating AdmissionWebhookType = "Validating"
+)
+
+type AdmissionOperation string
+
+const (
+	AdmissionCreate AdmissionOperation = "CREATE"
+	AdmissionUpdate AdmissionOperation = "UPDATE"
+	AdmissionDelete AdmissionOperation = "DELETE"
+	// AdmissionAll matches all operations.
+	AdmissionAll AdmissionOperation = "*"
+)
+
+type AdmissionFailurePolicy string
+
+const (
+	// AdmissionFail rejects the requests the webhook could not be called for.
+	AdmissionFail AdmissionFailurePolicy = "Fail"
+	// AdmissionIgnore admits the requests the webhook could not be called for.
+	AdmissionIgnore AdmissionFailurePolicy = "Ignore"
+)
+
+const (
+	DefaultAdmissionWebhookTimeoutSeconds = 10
+	maxAdmissionWebhookTimeoutSeconds     = 30
+)
+
+// AdmissionResources are the resources admission webhooks can be registered for.
+var AdmissionResources = sets.NewString("clusters", "nodes", "backups")
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=false
+
+// WebhookConfiguration registers admission webhooks, kc-server posts an AdmissionReview of the
+// admission.k8s.io/v1 API to them before it stores the clusters, nodes and backups changed by
+// API requests, so that platform teams enforce their own policies on these objects.
+type WebhookConfiguration struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Webhooks []AdmissionWebhook `json:"webhooks"`
+}
+
+type AdmissionWebhook struct {
+	// Name identifies the webhook in the errors of the requests it rejects.
+	Name string               `json:"name"`
+	Type AdmissionWebhookType `json:"type"`
+	URL  string               `json:"url"`
+	// CABundle is the PEM encoded CA verifying the certificate of the webhook, the system roots are used when empty.
+	CABundle           string          `json:"caBundle,omitempty" optional:"true"`
+	InsecureSkipVerify bool            `json:"insecureSkipVerify,omitempty" optional:"true"`
+	Rules              []AdmissionRule `json:"rules"`
+	// FailurePolicy decides the requests the webhook could not be called for, Fail by default.
+	FailurePolicy AdmissionFailurePolicy `json:"failurePolicy,omitempty" optional:"true"`
+	// TimeoutSeconds bounds a call of the webhook, 10 by default and 30 at most.
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty" optional:"true"`
+}
+
+// AdmissionRule selects the requests sent to a webhook.
+type AdmissionRule struct {
+	Operations []AdmissionOperation `json:"operations"`
+	Resources  []string             `json:"resources"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// WebhookConfigurationList contains a list of WebhookConfiguration
+
+type WebhookConfigurationList struct {
+	metav1.TypeMeta `json:",inline"`
+	// Standard object's metadata.
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []WebhookConfiguration `json:"items"`
+}
+
+func (c *WebhookConfiguration) Validate() error {
+	names := sets.NewString()
+	for _, w := range c.Webhooks {
+		if w.Name == "" {
+			return fmt.Errorf("webhook must have a name")
+		}
+		if names.Has(w.Name) {
+			return fmt.Errorf("duplicated webhook %q", w.Name)
+		}
+		names.Insert(w.Name)
+		if err := w.validate(); err != nil {
+			return fmt.Errorf("webhook %q: %v", w.Name, err)
+		}
+	}
+	return nil
+}
+
+func (w *AdmissionWebhook) validate() error {
+	switch w.Type {
+	case AdmissionWebhookMutating, AdmissionWebhookValidating:
+	default:
+		return fmt.Errorf("unsupported webhook type %q", w.Type)
+	}
+	u, err := url.Parse(w.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid webhook url %q", w.URL)
+	}
+	if w.CABundle != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(w.CABundle)) {
+		return fmt.Errorf("caBundle has no PEM encoded certificate")
+	}
+	switch w.FailurePolicy {
+	case "", AdmissionFail, AdmissionIgnore:
+	default:
+		return fmt.Errorf("unsupported failure policy %q", w.FailurePolicy)
+	}
+	if w.TimeoutSeconds < 0 || w.TimeoutSeconds > maxAdmissionWebhookTimeoutSeconds {
+		return fmt.Errorf("timeout must be between 1 and %d seconds", maxAdmissionWebhookTimeoutSeconds)
+	}
+	if len(w.Rules) == 0 {
+		return fmt.Errorf("webhook must have at least one rule")
+	}
+	for _, r := range w.Rules {
+		for _, op := range r.Operations {
+			switch op {
+			case AdmissionCreate, AdmissionUpdate, AdmissionDelete, AdmissionAll:
+			default:
+				return fmt.Errorf("unsupported operation %q", op)
+			}
+		}
+		for _, res := range r.Resources {
+			if res != "*" && !AdmissionResources.Has(res) {
+				return fmt.Errorf("unsupported resource %q, webhooks are supported for %v", res, AdmissionResources.List())
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether the operation on the resource has to be sent to the webhook.
+func (w *AdmissionWebhook) Match(resource string, op AdmissionOperation) bool {
+	for _, r := range w.Rules {
+		if admissionRuleHas(r.Resources, resource) && admissionRuleHasOperation(r.Operations, op) {
+			return true
+		}
+	}
+	return false
+}
+
+// Timeout bounds a call of the webhook.
+func (w *AdmissionWebhook) Timeout() time.Duration {
+	if w.TimeoutSeconds == 0 {
+		return DefaultAdmissionWebhookTimeoutSeconds * time.Second
+	}
+	return time.Duration(w.TimeoutSeconds) * time.Second
+}
+
+func admissionRuleHas(resources []string, resource string) bool {
+	for _, r := range resources {
+		if r == "*" || r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+func admissionRuleHasOperation(ops []AdmissionOperation, op AdmissionOperation) bool {
+	for _, o := range ops {
+		if o == AdmissionAll || o == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package v1
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWebhookConfigurationValidate(t *testing.T) {
+	rules := []AdmissionRule{{Operations: []AdmissionOperation{AdmissionCreate}, Resources: []string{"clusters"}}}
+	webhook := func(mutate func(w *AdmissionWebhook)) WebhookConfiguration {
+		w := AdmissionWebhook{Name: "policy", Type: AdmissionWebhookValidating, URL: "https://example.com/validate", Rules: rules}
+		if mutate != nil {
+			mutate(&w)
+		}
+		return WebhookConfiguration{Webhooks: []AdmissionWebhook{w}}
+	}
+	tests := []struct {
+		name    string
+		config  WebhookConfiguration
+		wantErr bool
+	}{
+		{"valid", webhook(nil), false},
+		{"without name", webhook(func(w *AdmissionWebhook) { w.Name = "" }), true},
+		{"unknown type", webhook(func(w *AdmissionWebhook) { w.Type = "Audit" }), true},
+		{"invalid url", webhook(func(w *AdmissionWebhook) { w.URL = "example.com" }), true},
+		{"invalid ca bundle", webhook(func(w *AdmissionWebhook) { w.CABundle = "not a pem" }), true},
+		{"unknown failure policy", webhook(func(w *AdmissionWebhook) { w.FailurePolicy = "Retry" }), true},
+		{"timeout too long", webhook(func(w *AdmissionWebhook) { w.TimeoutSeconds = 60 }), true},
+		{"without rules", webhook(func(w *AdmissionWebhook) { w.Rules = nil }), true},
+		{"unsupported resource", webhook(func(w *AdmissionWebhook) {
+			w.Rules = []AdmissionRule{{Operations: []AdmissionOperation{AdmissionAll}, Resources: []string{"regions"}}}
+		}), true},
+		{"unknown operation", webhook(func(w *AdmissionWebhook) {
+			w.Rules = []AdmissionRule{{Operations: []AdmissionOperation{"PATCH"}, Resources: []string{"*"}}}
+		}), true},
+		{"duplicated webhook", WebhookConfiguration{Webhooks: append(webhook(nil).Webhooks, webhook(nil).Webhooks...)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAdmissionWebhookMatch(t *testing.T) {
+	w := AdmissionWebhook{Rules: []AdmissionRule{
+		{Operations: []AdmissionOperation{AdmissionCreate, AdmissionUpdate}, Resources: []string{"clusters"}},
+		{Operations: []AdmissionOperation{AdmissionAll}, Resources: []string{"backups"}},
+	}}
+	tests := []struct {
+		resource string
+		op       AdmissionOperation
+		want     bool
+	}{
+		{"clusters", AdmissionCreate, true},
+		{"clusters", AdmissionDelete, false},
+		{"backups", AdmissionDelete, true},
+		{"nodes", AdmissionCreate, false},
+	}
+	for _, tt := range tests {
+		if got := w.Match(tt.resource, tt.op); got != tt.want {
+			t.Errorf("Match(%s, %s) = %v, want %v", tt.resource, tt.op, got, tt.want)
+		}
+	}
+	if got := w.Timeout(); got != DefaultAdmissionWebhookTimeoutSeconds*time.Second {
+		t.Errorf("Timeout() = %v, want the default timeout", got)
+	}
+}
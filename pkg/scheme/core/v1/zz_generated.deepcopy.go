@@ -23,9 +23,59 @@
 package v1
 
 import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdmissionRule) DeepCopyInto(out *AdmissionRule) {
+	*out = *in
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]AdmissionOperation, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdmissionRule.
+func (in *AdmissionRule) DeepCopy() *AdmissionRule {
+	if in == nil {
+		return nil
+	}
+	out := new(AdmissionRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdmissionWebhook) DeepCopyInto(out *AdmissionWebhook) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]AdmissionRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdmissionWebhook.
+func (in *AdmissionWebhook) DeepCopy() *AdmissionWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(AdmissionWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AttachedVolume) DeepCopyInto(out *AttachedVolume) {
 	*out = *in
@@ -47,7 +97,7 @@ func (in *Backup) DeepCopyInto(out *Backup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	if in.ClusterNodes != nil {
 		in, out := &in.ClusterNodes, &out.ClusterNodes
 		*out = make(map[string]string, len(*in))
@@ -124,6 +174,12 @@ func (in *BackupPoint) DeepCopyInto(out *BackupPoint) {
 		*out = new(S3Config)
 		**out = **in
 	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -178,9 +234,51 @@ func (in *BackupPointList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPointStatus) DeepCopyInto(out *BackupPointStatus) {
+	*out = *in
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]BackupPointUsage, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPointStatus.
+func (in *BackupPointStatus) DeepCopy() *BackupPointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPointUsage) DeepCopyInto(out *BackupPointUsage) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPointUsage.
+func (in *BackupPointUsage) DeepCopy() *BackupPointUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPointUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupStatus) DeepCopyInto(out *BackupStatus) {
 	*out = *in
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(BackupVerification)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -194,10 +292,33 @@ func (in *BackupStatus) DeepCopy() *BackupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupVerification) DeepCopyInto(out *BackupVerification) {
+	*out = *in
+	in.VerifiedAt.DeepCopyInto(&out.VerifiedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupVerification.
+func (in *BackupVerification) DeepCopy() *BackupVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CNI) DeepCopyInto(out *CNI) {
 	*out = *in
 	out.Calico = in.Calico
+	out.Flannel = in.Flannel
+	if in.NodeInterface != nil {
+		in, out := &in.NodeInterface, &out.NodeInterface
+		*out = new(NodeInterface)
+		**out = **in
+	}
 	return
 }
 
@@ -227,6 +348,23 @@ func (in *Calico) DeepCopy() *Calico {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Certification) DeepCopyInto(out *Certification) {
+	*out = *in
+	in.ExpirationTime.DeepCopyInto(&out.ExpirationTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Certification.
+func (in *Certification) DeepCopy() *Certification {
+	if in == nil {
+		return nil
+	}
+	out := new(Certification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Cluster) DeepCopyInto(out *Cluster) {
 	*out = *in
@@ -243,6 +381,11 @@ func (in *Cluster) DeepCopyInto(out *Cluster) {
 		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
+	if in.DeleteProtection != nil {
+		in, out := &in.DeleteProtection, &out.DeleteProtection
+		*out = new(bool)
+		**out = **in
+	}
 	return
 }
 
@@ -282,235 +425,293 @@ func (in *ClusterCondition) DeepCopy() *ClusterCondition {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterList) DeepCopyInto(out *ClusterList) {
+func (in *ClusterDefaults) DeepCopyInto(out *ClusterDefaults) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Cluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.InsecureRegistry != nil {
+		in, out := &in.InsecureRegistry, &out.InsecureRegistry
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NTPServers != nil {
+		in, out := &in.NTPServers, &out.NTPServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterList.
-func (in *ClusterList) DeepCopy() *ClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDefaults.
+func (in *ClusterDefaults) DeepCopy() *ClusterDefaults {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterList)
+	out := new(ClusterDefaults)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
+func (in *ClusterDiff) DeepCopyInto(out *ClusterDiff) {
 	*out = *in
-	if in.ComponentConditions != nil {
-		in, out := &in.ComponentConditions, &out.ComponentConditions
-		*out = make([]ComponentConditions, len(*in))
-		copy(*out, *in)
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]ClusterCondition, len(*in))
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AddWorkers != nil {
+		in, out := &in.AddWorkers, &out.AddWorkers
+		*out = make(WorkerNodeList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoveWorkers != nil {
+		in, out := &in.RemoveWorkers, &out.RemoveWorkers
+		*out = make(WorkerNodeList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InstallComponents != nil {
+		in, out := &in.InstallComponents, &out.InstallComponents
+		*out = make([]Component, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UninstallComponents != nil {
+		in, out := &in.UninstallComponents, &out.UninstallComponents
+		*out = make([]Component, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Forbidden != nil {
+		in, out := &in.Forbidden, &out.Forbidden
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
-func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDiff.
+func (in *ClusterDiff) DeepCopy() *ClusterDiff {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterStatus)
+	out := new(ClusterDiff)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Command) DeepCopyInto(out *Command) {
+func (in *ClusterExport) DeepCopyInto(out *ClusterExport) {
 	*out = *in
-	if in.ShellCommand != nil {
-		in, out := &in.ShellCommand, &out.ShellCommand
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.CustomCommand != nil {
-		in, out := &in.CustomCommand, &out.CustomCommand
-		*out = make([]byte, len(*in))
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]ExportFile, len(*in))
 		copy(*out, *in)
 	}
-	if in.Template != nil {
-		in, out := &in.Template, &out.Template
-		*out = new(TemplateCommand)
-		(*in).DeepCopyInto(*out)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Command.
-func (in *Command) DeepCopy() *Command {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExport.
+func (in *ClusterExport) DeepCopy() *ClusterExport {
 	if in == nil {
 		return nil
 	}
-	out := new(Command)
+	out := new(ClusterExport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Component) DeepCopyInto(out *Component) {
+func (in *ClusterHealthCondition) DeepCopyInto(out *ClusterHealthCondition) {
 	*out = *in
-	in.Config.DeepCopyInto(&out.Config)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Component.
-func (in *Component) DeepCopy() *Component {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHealthCondition.
+func (in *ClusterHealthCondition) DeepCopy() *ClusterHealthCondition {
 	if in == nil {
 		return nil
 	}
-	out := new(Component)
+	out := new(ClusterHealthCondition)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ComponentConditions) DeepCopyInto(out *ComponentConditions) {
+func (in *ClusterImport) DeepCopyInto(out *ClusterImport) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentConditions.
-func (in *ComponentConditions) DeepCopy() *ComponentConditions {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterImport.
+func (in *ClusterImport) DeepCopy() *ClusterImport {
 	if in == nil {
 		return nil
 	}
-	out := new(ComponentConditions)
+	out := new(ClusterImport)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ContainerRuntime) DeepCopyInto(out *ContainerRuntime) {
+func (in *ClusterKubeconfig) DeepCopyInto(out *ClusterKubeconfig) {
 	*out = *in
-	in.Docker.DeepCopyInto(&out.Docker)
-	in.Containerd.DeepCopyInto(&out.Containerd)
+	in.ExpirationTimestamp.DeepCopyInto(&out.ExpirationTimestamp)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRuntime.
-func (in *ContainerRuntime) DeepCopy() *ContainerRuntime {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterKubeconfig.
+func (in *ClusterKubeconfig) DeepCopy() *ClusterKubeconfig {
 	if in == nil {
 		return nil
 	}
-	out := new(ContainerRuntime)
+	out := new(ClusterKubeconfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Containerd) DeepCopyInto(out *Containerd) {
+func (in *ClusterList) DeepCopyInto(out *ClusterList) {
 	*out = *in
-	if in.InsecureRegistry != nil {
-		in, out := &in.InsecureRegistry, &out.InsecureRegistry
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Cluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Containerd.
-func (in *Containerd) DeepCopy() *Containerd {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterList.
+func (in *ClusterList) DeepCopy() *ClusterList {
 	if in == nil {
 		return nil
 	}
-	out := new(Containerd)
+	out := new(ClusterList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Docker) DeepCopyInto(out *Docker) {
+func (in *ClusterPolicy) DeepCopyInto(out *ClusterPolicy) {
 	*out = *in
-	if in.InsecureRegistry != nil {
-		in, out := &in.InsecureRegistry, &out.InsecureRegistry
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Docker.
-func (in *Docker) DeepCopy() *Docker {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterPolicy.
+func (in *ClusterPolicy) DeepCopy() *ClusterPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(Docker)
+	out := new(ClusterPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DockerRegistry) DeepCopyInto(out *DockerRegistry) {
+func (in *ClusterStatus) DeepCopyInto(out *ClusterStatus) {
 	*out = *in
-	if in.InsecureRegistry != nil {
-		in, out := &in.InsecureRegistry, &out.InsecureRegistry
-		*out = make([]InsecureRegistry, len(*in))
+	if in.ComponentConditions != nil {
+		in, out := &in.ComponentConditions, &out.ComponentConditions
+		*out = make([]ComponentConditions, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]ClusterCondition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerRegistry.
-func (in *DockerRegistry) DeepCopy() *DockerRegistry {
-	if in == nil {
-		return nil
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = make([]ClusterHealthCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(DockerRegistry)
-	in.DeepCopyInto(out)
+	if in.Certifications != nil {
+		in, out := &in.Certifications, &out.Certifications
+		*out = make([]Certification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = new(ClusterWorkloads)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComponentHistory != nil {
+		in, out := &in.ComponentHistory, &out.ComponentHistory
+		*out = make([]ComponentRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatus.
+func (in *ClusterStatus) DeepCopy() *ClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatus)
+	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Domain) DeepCopyInto(out *Domain) {
+func (in *ClusterTemplate) DeepCopyInto(out *ClusterTemplate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	if in.Kubeadm != nil {
+		in, out := &in.Kubeadm, &out.Kubeadm
+		*out = new(Kubeadm)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Domain.
-func (in *Domain) DeepCopy() *Domain {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplate.
+func (in *ClusterTemplate) DeepCopy() *ClusterTemplate {
 	if in == nil {
 		return nil
 	}
-	out := new(Domain)
+	out := new(ClusterTemplate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Domain) DeepCopyObject() runtime.Object {
+func (in *ClusterTemplate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -518,13 +719,13 @@ func (in *Domain) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DomainList) DeepCopyInto(out *DomainList) {
+func (in *ClusterTemplateList) DeepCopyInto(out *ClusterTemplateList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Domain, len(*in))
+		*out = make([]ClusterTemplate, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -532,18 +733,18 @@ func (in *DomainList) DeepCopyInto(out *DomainList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainList.
-func (in *DomainList) DeepCopy() *DomainList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplateList.
+func (in *ClusterTemplateList) DeepCopy() *ClusterTemplateList {
 	if in == nil {
 		return nil
 	}
-	out := new(DomainList)
+	out := new(ClusterTemplateList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *DomainList) DeepCopyObject() runtime.Object {
+func (in *ClusterTemplateList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -551,223 +752,217 @@ func (in *DomainList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
+func (in *ClusterTemplateOverrides) DeepCopyInto(out *ClusterTemplateOverrides) {
 	*out = *in
-	if in.Records != nil {
-		in, out := &in.Records, &out.Records
-		*out = make(map[string]Record, len(*in))
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
 		for key, val := range *in {
-			(*out)[key] = *val.DeepCopy()
+			(*out)[key] = val
 		}
 	}
-	if in.SyncCluster != nil {
-		in, out := &in.SyncCluster, &out.SyncCluster
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainSpec.
-func (in *DomainSpec) DeepCopy() *DomainSpec {
-	if in == nil {
-		return nil
+	if in.Masters != nil {
+		in, out := &in.Masters, &out.Masters
+		*out = make(WorkerNodeList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = make(WorkerNodeList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	out := new(DomainSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *DomainStatus) DeepCopyInto(out *DomainStatus) {
-	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatus.
-func (in *DomainStatus) DeepCopy() *DomainStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTemplateOverrides.
+func (in *ClusterTemplateOverrides) DeepCopy() *ClusterTemplateOverrides {
 	if in == nil {
 		return nil
 	}
-	out := new(DomainStatus)
+	out := new(ClusterTemplateOverrides)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Etcd) DeepCopyInto(out *Etcd) {
+func (in *ClusterWorkloads) DeepCopyInto(out *ClusterWorkloads) {
 	*out = *in
+	if in.Unhealthy != nil {
+		in, out := &in.Unhealthy, &out.Unhealthy
+		*out = make([]UnhealthyWorkload, len(*in))
+		copy(*out, *in)
+	}
+	in.UpdateTime.DeepCopyInto(&out.UpdateTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Etcd.
-func (in *Etcd) DeepCopy() *Etcd {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkloads.
+func (in *ClusterWorkloads) DeepCopy() *ClusterWorkloads {
 	if in == nil {
 		return nil
 	}
-	out := new(Etcd)
+	out := new(ClusterWorkloads)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Event) DeepCopyInto(out *Event) {
+func (in *Command) DeepCopyInto(out *Command) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.RequestReceivedTimestamp.DeepCopyInto(&out.RequestReceivedTimestamp)
-	in.StageTimestamp.DeepCopyInto(&out.StageTimestamp)
+	if in.ShellCommand != nil {
+		in, out := &in.ShellCommand, &out.ShellCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomCommand != nil {
+		in, out := &in.CustomCommand, &out.CustomCommand
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.Template != nil {
+		in, out := &in.Template, &out.Template
+		*out = new(TemplateCommand)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Container != nil {
+		in, out := &in.Container, &out.Container
+		*out = new(ContainerExecution)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PowerShell != nil {
+		in, out := &in.PowerShell, &out.PowerShell
+		*out = new(PowerShellCommand)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Event.
-func (in *Event) DeepCopy() *Event {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Command.
+func (in *Command) DeepCopy() *Command {
 	if in == nil {
 		return nil
 	}
-	out := new(Event)
+	out := new(Command)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Event) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EventList) DeepCopyInto(out *EventList) {
+func (in *Component) DeepCopyInto(out *Component) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Event, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.Config.DeepCopyInto(&out.Config)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventList.
-func (in *EventList) DeepCopy() *EventList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Component.
+func (in *Component) DeepCopy() *Component {
 	if in == nil {
 		return nil
 	}
-	out := new(EventList)
+	out := new(Component)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EventList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FsConfig) DeepCopyInto(out *FsConfig) {
+func (in *ComponentConditions) DeepCopyInto(out *ComponentConditions) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FsConfig.
-func (in *FsConfig) DeepCopy() *FsConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentConditions.
+func (in *ComponentConditions) DeepCopy() *ComponentConditions {
 	if in == nil {
 		return nil
 	}
-	out := new(FsConfig)
+	out := new(ComponentConditions)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InsecureRegistry) DeepCopyInto(out *InsecureRegistry) {
+func (in *ComponentRevision) DeepCopyInto(out *ComponentRevision) {
 	*out = *in
-	in.CreateAt.DeepCopyInto(&out.CreateAt)
+	in.Time.DeepCopyInto(&out.Time)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InsecureRegistry.
-func (in *InsecureRegistry) DeepCopy() *InsecureRegistry {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentRevision.
+func (in *ComponentRevision) DeepCopy() *ComponentRevision {
 	if in == nil {
 		return nil
 	}
-	out := new(InsecureRegistry)
+	out := new(ComponentRevision)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubeComponents) DeepCopyInto(out *KubeComponents) {
+func (in *ContainerExecution) DeepCopyInto(out *ContainerExecution) {
 	*out = *in
-	out.KubeProxy = in.KubeProxy
-	out.Etcd = in.Etcd
-	out.Kubelet = in.Kubelet
-	out.CNI = in.CNI
+	if in.Mounts != nil {
+		in, out := &in.Mounts, &out.Mounts
+		*out = make([]HostMount, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeComponents.
-func (in *KubeComponents) DeepCopy() *KubeComponents {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerExecution.
+func (in *ContainerExecution) DeepCopy() *ContainerExecution {
 	if in == nil {
 		return nil
 	}
-	out := new(KubeComponents)
+	out := new(ContainerExecution)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KubeProxy) DeepCopyInto(out *KubeProxy) {
+func (in *ContainerRuntime) DeepCopyInto(out *ContainerRuntime) {
 	*out = *in
+	in.Docker.DeepCopyInto(&out.Docker)
+	in.Containerd.DeepCopyInto(&out.Containerd)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeProxy.
-func (in *KubeProxy) DeepCopy() *KubeProxy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerRuntime.
+func (in *ContainerRuntime) DeepCopy() *ContainerRuntime {
 	if in == nil {
 		return nil
 	}
-	out := new(KubeProxy)
+	out := new(ContainerRuntime)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Kubeadm) DeepCopyInto(out *Kubeadm) {
+func (in *Containerd) DeepCopyInto(out *Containerd) {
 	*out = *in
-	if in.Masters != nil {
-		in, out := &in.Masters, &out.Masters
-		*out = make(WorkerNodeList, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Workers != nil {
-		in, out := &in.Workers, &out.Workers
-		*out = make(WorkerNodeList, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.CertSANs != nil {
-		in, out := &in.CertSANs, &out.CertSANs
+	if in.InsecureRegistry != nil {
+		in, out := &in.InsecureRegistry, &out.InsecureRegistry
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.ContainerRuntime.DeepCopyInto(&out.ContainerRuntime)
-	out.Networking = in.Networking
-	out.KubeComponents = in.KubeComponents
-	if in.Components != nil {
-		in, out := &in.Components, &out.Components
-		*out = make([]Component, len(*in))
+	if in.Registries != nil {
+		in, out := &in.Registries, &out.Registries
+		*out = make([]ContainerdRegistry, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -775,85 +970,1441 @@ func (in *Kubeadm) DeepCopyInto(out *Kubeadm) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kubeadm.
-func (in *Kubeadm) DeepCopy() *Kubeadm {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Containerd.
+func (in *Containerd) DeepCopy() *Containerd {
 	if in == nil {
 		return nil
 	}
-	out := new(Kubeadm)
+	out := new(Containerd)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Kubelet) DeepCopyInto(out *Kubelet) {
+func (in *ContainerdRegistry) DeepCopyInto(out *ContainerdRegistry) {
 	*out = *in
+	if in.Mirrors != nil {
+		in, out := &in.Mirrors, &out.Mirrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kubelet.
-func (in *Kubelet) DeepCopy() *Kubelet {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerdRegistry.
+func (in *ContainerdRegistry) DeepCopy() *ContainerdRegistry {
 	if in == nil {
 		return nil
 	}
-	out := new(Kubelet)
+	out := new(ContainerdRegistry)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MetaResource) DeepCopyInto(out *MetaResource) {
+func (in *CronMaintenance) DeepCopyInto(out *CronMaintenance) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaResource.
-func (in *MetaResource) DeepCopy() *MetaResource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMaintenance.
+func (in *CronMaintenance) DeepCopy() *CronMaintenance {
 	if in == nil {
 		return nil
 	}
-	out := new(MetaResource)
+	out := new(CronMaintenance)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CronMaintenance) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Networking) DeepCopyInto(out *Networking) {
+func (in *CronMaintenanceList) DeepCopyInto(out *CronMaintenanceList) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]CronMaintenance, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Networking.
-func (in *Networking) DeepCopy() *Networking {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMaintenanceList.
+func (in *CronMaintenanceList) DeepCopy() *CronMaintenanceList {
 	if in == nil {
 		return nil
 	}
-	out := new(Networking)
+	out := new(CronMaintenanceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *CronMaintenanceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMaintenanceSpec) DeepCopyInto(out *CronMaintenanceSpec) {
+	*out = *in
+	if in.Tasks != nil {
+		in, out := &in.Tasks, &out.Tasks
+		*out = make([]MaintenanceTask, len(*in))
+		copy(*out, *in)
+	}
+	in.Window.DeepCopyInto(&out.Window)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMaintenanceSpec.
+func (in *CronMaintenanceSpec) DeepCopy() *CronMaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CronMaintenanceStatus) DeepCopyInto(out *CronMaintenanceStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CronMaintenanceStatus.
+func (in *CronMaintenanceStatus) DeepCopy() *CronMaintenanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CronMaintenanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Docker) DeepCopyInto(out *Docker) {
+	*out = *in
+	if in.InsecureRegistry != nil {
+		in, out := &in.InsecureRegistry, &out.InsecureRegistry
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Docker.
+func (in *Docker) DeepCopy() *Docker {
+	if in == nil {
+		return nil
+	}
+	out := new(Docker)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DockerRegistry) DeepCopyInto(out *DockerRegistry) {
+	*out = *in
+	if in.InsecureRegistry != nil {
+		in, out := &in.InsecureRegistry, &out.InsecureRegistry
+		*out = make([]InsecureRegistry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DockerRegistry.
+func (in *DockerRegistry) DeepCopy() *DockerRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(DockerRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Domain) DeepCopyInto(out *Domain) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Domain.
+func (in *Domain) DeepCopy() *Domain {
+	if in == nil {
+		return nil
+	}
+	out := new(Domain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Domain) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainList) DeepCopyInto(out *DomainList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Domain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainList.
+func (in *DomainList) DeepCopy() *DomainList {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DomainList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainSpec) DeepCopyInto(out *DomainSpec) {
+	*out = *in
+	if in.Records != nil {
+		in, out := &in.Records, &out.Records
+		*out = make(map[string]Record, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.SyncCluster != nil {
+		in, out := &in.SyncCluster, &out.SyncCluster
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainSpec.
+func (in *DomainSpec) DeepCopy() *DomainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DomainStatus) DeepCopyInto(out *DomainStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DomainStatus.
+func (in *DomainStatus) DeepCopy() *DomainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DomainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Etcd) DeepCopyInto(out *Etcd) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Etcd.
+func (in *Etcd) DeepCopy() *Etcd {
+	if in == nil {
+		return nil
+	}
+	out := new(Etcd)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Event) DeepCopyInto(out *Event) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.RequestReceivedTimestamp.DeepCopyInto(&out.RequestReceivedTimestamp)
+	in.StageTimestamp.DeepCopyInto(&out.StageTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Event.
+func (in *Event) DeepCopy() *Event {
+	if in == nil {
+		return nil
+	}
+	out := new(Event)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Event) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventList) DeepCopyInto(out *EventList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Event, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventList.
+func (in *EventList) DeepCopy() *EventList {
+	if in == nil {
+		return nil
+	}
+	out := new(EventList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EventList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExportFile) DeepCopyInto(out *ExportFile) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExportFile.
+func (in *ExportFile) DeepCopy() *ExportFile {
+	if in == nil {
+		return nil
+	}
+	out := new(ExportFile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Flannel) DeepCopyInto(out *Flannel) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Flannel.
+func (in *Flannel) DeepCopy() *Flannel {
+	if in == nil {
+		return nil
+	}
+	out := new(Flannel)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FsConfig) DeepCopyInto(out *FsConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FsConfig.
+func (in *FsConfig) DeepCopy() *FsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GPU) DeepCopyInto(out *GPU) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GPU.
+func (in *GPU) DeepCopy() *GPU {
+	if in == nil {
+		return nil
+	}
+	out := new(GPU)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookRequest) DeepCopyInto(out *HookRequest) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookRequest.
+func (in *HookRequest) DeepCopy() *HookRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(HookRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookResult) DeepCopyInto(out *HookResult) {
+	*out = *in
+	in.StartAt.DeepCopyInto(&out.StartAt)
+	in.EndAt.DeepCopyInto(&out.EndAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookResult.
+func (in *HookResult) DeepCopy() *HookResult {
+	if in == nil {
+		return nil
+	}
+	out := new(HookResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostMount) DeepCopyInto(out *HostMount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostMount.
+func (in *HostMount) DeepCopy() *HostMount {
+	if in == nil {
+		return nil
+	}
+	out := new(HostMount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InsecureRegistry) DeepCopyInto(out *InsecureRegistry) {
+	*out = *in
+	in.CreateAt.DeepCopyInto(&out.CreateAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InsecureRegistry.
+func (in *InsecureRegistry) DeepCopy() *InsecureRegistry {
+	if in == nil {
+		return nil
+	}
+	out := new(InsecureRegistry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeComponents) DeepCopyInto(out *KubeComponents) {
+	*out = *in
+	out.KubeProxy = in.KubeProxy
+	out.Etcd = in.Etcd
+	out.Kubelet = in.Kubelet
+	in.CNI.DeepCopyInto(&out.CNI)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeComponents.
+func (in *KubeComponents) DeepCopy() *KubeComponents {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeComponents)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeProxy) DeepCopyInto(out *KubeProxy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeProxy.
+func (in *KubeProxy) DeepCopy() *KubeProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kubeadm) DeepCopyInto(out *Kubeadm) {
+	*out = *in
+	if in.Masters != nil {
+		in, out := &in.Masters, &out.Masters
+		*out = make(WorkerNodeList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Workers != nil {
+		in, out := &in.Workers, &out.Workers
+		*out = make(WorkerNodeList, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CertSANs != nil {
+		in, out := &in.CertSANs, &out.CertSANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.ContainerRuntime.DeepCopyInto(&out.ContainerRuntime)
+	in.Networking.DeepCopyInto(&out.Networking)
+	in.KubeComponents.DeepCopyInto(&out.KubeComponents)
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]Component, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NTPServers != nil {
+		in, out := &in.NTPServers, &out.NTPServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConfigPatches != nil {
+		in, out := &in.ConfigPatches, &out.ConfigPatches
+		*out = make(KubeadmConfigPatches, len(*in))
+		copy(*out, *in)
+	}
+	if in.ControlPlaneVIP != nil {
+		in, out := &in.ControlPlaneVIP, &out.ControlPlaneVIP
+		*out = new(ControlPlaneVIP)
+		**out = **in
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]NodePool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(Proxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ToolImages != nil {
+		in, out := &in.ToolImages, &out.ToolImages
+		*out = new(ToolImages)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kubeadm.
+func (in *Kubeadm) DeepCopy() *Kubeadm {
+	if in == nil {
+		return nil
+	}
+	out := new(Kubeadm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeadmConfigPatch) DeepCopyInto(out *KubeadmConfigPatch) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeadmConfigPatch.
+func (in *KubeadmConfigPatch) DeepCopy() *KubeadmConfigPatch {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeadmConfigPatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in KubeadmConfigPatches) DeepCopyInto(out *KubeadmConfigPatches) {
+	{
+		in := &in
+		*out = make(KubeadmConfigPatches, len(*in))
+		copy(*out, *in)
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeadmConfigPatches.
+func (in KubeadmConfigPatches) DeepCopy() KubeadmConfigPatches {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeadmConfigPatches)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kubelet) DeepCopyInto(out *Kubelet) {
+	*out = *in
+	out.Swap = in.Swap
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kubelet.
+func (in *Kubelet) DeepCopy() *Kubelet {
+	if in == nil {
+		return nil
+	}
+	out := new(Kubelet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineSpec) DeepCopyInto(out *MachineSpec) {
+	*out = *in
+	if in.Networks != nil {
+		in, out := &in.Networks, &out.Networks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineSpec.
+func (in *MachineSpec) DeepCopy() *MachineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Weekdays != nil {
+		in, out := &in.Weekdays, &out.Weekdays
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetaResource) DeepCopyInto(out *MetaResource) {
+	*out = *in
+	if in.Compatible != nil {
+		in, out := &in.Compatible, &out.Compatible
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetaResource.
+func (in *MetaResource) DeepCopy() *MetaResource {
+	if in == nil {
+		return nil
+	}
+	out := new(MetaResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Networking) DeepCopyInto(out *Networking) {
+	*out = *in
+	if in.PodSubnets != nil {
+		in, out := &in.PodSubnets, &out.PodSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceSubnets != nil {
+		in, out := &in.ServiceSubnets, &out.ServiceSubnets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Networking.
+func (in *Networking) DeepCopy() *Networking {
+	if in == nil {
+		return nil
+	}
+	out := new(Networking)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Node) DeepCopyInto(out *Node) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Node.
+func (in *Node) DeepCopy() *Node {
+	if in == nil {
+		return nil
+	}
+	out := new(Node)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Node) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeAddress) DeepCopyInto(out *NodeAddress) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAddress.
+func (in *NodeAddress) DeepCopy() *NodeAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeCondition) DeepCopyInto(out *NodeCondition) {
+	*out = *in
+	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeCondition.
+func (in *NodeCondition) DeepCopy() *NodeCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeInterface) DeepCopyInto(out *NodeInterface) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeInterface.
+func (in *NodeInterface) DeepCopy() *NodeInterface {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeInterface)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeList) DeepCopyInto(out *NodeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Node, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeList.
+func (in *NodeList) DeepCopy() *NodeList {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NodeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeMetrics) DeepCopyInto(out *NodeMetrics) {
+	*out = *in
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make([]NodeUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeMetrics.
+func (in *NodeMetrics) DeepCopy() *NodeMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePool) DeepCopyInto(out *NodePool) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]Taint, len(*in))
+		copy(*out, *in)
+	}
+	if in.KubeletArgs != nil {
+		in, out := &in.KubeletArgs, &out.KubeletArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ContainerRuntime != nil {
+		in, out := &in.ContainerRuntime, &out.ContainerRuntime
+		*out = new(ContainerRuntime)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkerCount != nil {
+		in, out := &in.WorkerCount, &out.WorkerCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(NodePoolProvider)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePool.
+func (in *NodePool) DeepCopy() *NodePool {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodePoolProvider) DeepCopyInto(out *NodePoolProvider) {
+	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Machine != nil {
+		in, out := &in.Machine, &out.Machine
+		*out = new(MachineSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodePoolProvider.
+func (in *NodePoolProvider) DeepCopy() *NodePoolProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(NodePoolProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Allocatable != nil {
+		in, out := &in.Allocatable, &out.Allocatable
+		*out = make(ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]NodeCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]NodeAddress, len(*in))
+		copy(*out, *in)
+	}
+	out.NodeInfo = in.NodeInfo
+	if in.VolumesAttached != nil {
+		in, out := &in.VolumesAttached, &out.VolumesAttached
+		*out = make([]AttachedVolume, len(*in))
+		copy(*out, *in)
+	}
+	in.ContainerRuntimeInfo.DeepCopyInto(&out.ContainerRuntimeInfo)
+	if in.GPUs != nil {
+		in, out := &in.GPUs, &out.GPUs
+		*out = make([]GPU, len(*in))
+		copy(*out, *in)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(NodeUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
+func (in *NodeStatus) DeepCopy() *NodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeSystemInfo) DeepCopyInto(out *NodeSystemInfo) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSystemInfo.
+func (in *NodeSystemInfo) DeepCopy() *NodeSystemInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeSystemInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeUsage) DeepCopyInto(out *NodeUsage) {
+	*out = *in
+	in.Timestamp.DeepCopyInto(&out.Timestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeUsage.
+func (in *NodeUsage) DeepCopy() *NodeUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notification) DeepCopyInto(out *Notification) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Event.DeepCopyInto(&out.Event)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notification.
+func (in *Notification) DeepCopy() *Notification {
+	if in == nil {
+		return nil
+	}
+	out := new(Notification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Notification) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationEvent) DeepCopyInto(out *NotificationEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationEvent.
+func (in *NotificationEvent) DeepCopy() *NotificationEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationList) DeepCopyInto(out *NotificationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Notification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationList.
+func (in *NotificationList) DeepCopy() *NotificationList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotificationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationStatus) DeepCopyInto(out *NotificationStatus) {
+	*out = *in
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextAttemptTime != nil {
+		in, out := &in.NextAttemptTime, &out.NextAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationStatus.
+func (in *NotificationStatus) DeepCopy() *NotificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Notifier) DeepCopyInto(out *Notifier) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEventType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(NotifierWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Email != nil {
+		in, out := &in.Email, &out.Email
+		*out = new(NotifierEmail)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Retry = in.Retry
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Notifier.
+func (in *Notifier) DeepCopy() *Notifier {
+	if in == nil {
+		return nil
+	}
+	out := new(Notifier)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Notifier) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifierEmail) DeepCopyInto(out *NotifierEmail) {
+	*out = *in
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierEmail.
+func (in *NotifierEmail) DeepCopy() *NotifierEmail {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifierEmail)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifierList) DeepCopyInto(out *NotifierList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Notifier, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierList.
+func (in *NotifierList) DeepCopy() *NotifierList {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifierList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NotifierList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifierRetry) DeepCopyInto(out *NotifierRetry) {
+	*out = *in
+	out.Backoff = in.Backoff
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierRetry.
+func (in *NotifierRetry) DeepCopy() *NotifierRetry {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifierRetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotifierWebhook) DeepCopyInto(out *NotifierWebhook) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotifierWebhook.
+func (in *NotifierWebhook) DeepCopy() *NotifierWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(NotifierWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectEvent) DeepCopyInto(out *ObjectEvent) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.InvolvedObject = in.InvolvedObject
+	out.Source = in.Source
+	in.FirstTimestamp.DeepCopyInto(&out.FirstTimestamp)
+	in.LastTimestamp.DeepCopyInto(&out.LastTimestamp)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectEvent.
+func (in *ObjectEvent) DeepCopy() *ObjectEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ObjectEvent) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectEventList) DeepCopyInto(out *ObjectEventList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ObjectEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectEventList.
+func (in *ObjectEventList) DeepCopy() *ObjectEventList {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectEventList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ObjectEventList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectEventSource) DeepCopyInto(out *ObjectEventSource) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectEventSource.
+func (in *ObjectEventSource) DeepCopy() *ObjectEventSource {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectEventSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Node) DeepCopyInto(out *Node) {
+func (in *ObjectReference) DeepCopyInto(out *ObjectReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectReference.
+func (in *ObjectReference) DeepCopy() *ObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Operation) DeepCopyInto(out *Operation) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]Step, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StepPolicies != nil {
+		in, out := &in.StepPolicies, &out.StepPolicies
+		*out = make([]StepPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Node.
-func (in *Node) DeepCopy() *Node {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Operation.
+func (in *Operation) DeepCopy() *Operation {
 	if in == nil {
 		return nil
 	}
-	out := new(Node)
+	out := new(Operation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Node) DeepCopyObject() runtime.Object {
+func (in *Operation) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -861,47 +2412,314 @@ func (in *Node) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeAddress) DeepCopyInto(out *NodeAddress) {
+func (in *OperationCondition) DeepCopyInto(out *OperationCondition) {
+	*out = *in
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = make([]StepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationCondition.
+func (in *OperationCondition) DeepCopy() *OperationCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationHook) DeepCopyInto(out *OperationHook) {
+	*out = *in
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(ScriptHook)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationHook.
+func (in *OperationHook) DeepCopy() *OperationHook {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationList) DeepCopyInto(out *OperationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Operation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationList.
+func (in *OperationList) DeepCopy() *OperationList {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OperationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]OperationCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = make([]StepProgress, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = make([]HookResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStatus.
+func (in *OperationStatus) DeepCopy() *OperationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationStep) DeepCopyInto(out *OperationStep) {
+	*out = *in
+	in.Step.DeepCopyInto(&out.Step)
+	if in.Status != nil {
+		in, out := &in.Status, &out.Status
+		*out = make([]StepStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStep.
+func (in *OperationStep) DeepCopy() *OperationStep {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ParseRecord) DeepCopyInto(out *ParseRecord) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParseRecord.
+func (in *ParseRecord) DeepCopy() *ParseRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(ParseRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlatformSetting) DeepCopyInto(out *PlatformSetting) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Template.DeepCopyInto(&out.Template)
+	out.Terminal = in.Terminal
+	out.Cluster = in.Cluster
+	in.RegistrySync.DeepCopyInto(&out.RegistrySync)
+	in.ClusterDefaults.DeepCopyInto(&out.ClusterDefaults)
+	in.Quota.DeepCopyInto(&out.Quota)
+	out.RecycleBin = in.RecycleBin
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformSetting.
+func (in *PlatformSetting) DeepCopy() *PlatformSetting {
+	if in == nil {
+		return nil
+	}
+	out := new(PlatformSetting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PlatformSetting) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlatformSettingList) DeepCopyInto(out *PlatformSettingList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PlatformSetting, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformSettingList.
+func (in *PlatformSettingList) DeepCopy() *PlatformSettingList {
+	if in == nil {
+		return nil
+	}
+	out := new(PlatformSettingList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PlatformSettingList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerShellCommand) DeepCopyInto(out *PowerShellCommand) {
 	*out = *in
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeAddress.
-func (in *NodeAddress) DeepCopy() *NodeAddress {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerShellCommand.
+func (in *PowerShellCommand) DeepCopy() *PowerShellCommand {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeAddress)
+	out := new(PowerShellCommand)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeCondition) DeepCopyInto(out *NodeCondition) {
+func (in *Precheck) DeepCopyInto(out *Precheck) {
 	*out = *in
-	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
-	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]PrecheckResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.StartedAt.DeepCopyInto(&out.StartedAt)
+	if in.CompletedAt != nil {
+		in, out := &in.CompletedAt, &out.CompletedAt
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeCondition.
-func (in *NodeCondition) DeepCopy() *NodeCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Precheck.
+func (in *Precheck) DeepCopy() *Precheck {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeCondition)
+	out := new(Precheck)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *Precheck) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeList) DeepCopyInto(out *NodeList) {
+func (in *PrecheckList) DeepCopyInto(out *PrecheckList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Node, len(*in))
+		*out = make([]Precheck, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -909,18 +2727,18 @@ func (in *NodeList) DeepCopyInto(out *NodeList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeList.
-func (in *NodeList) DeepCopy() *NodeList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrecheckList.
+func (in *PrecheckList) DeepCopy() *PrecheckList {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeList)
+	out := new(PrecheckList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NodeList) DeepCopyObject() runtime.Object {
+func (in *PrecheckList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -928,154 +2746,101 @@ func (in *NodeList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+func (in *PrecheckResult) DeepCopyInto(out *PrecheckResult) {
 	*out = *in
-	if in.Capacity != nil {
-		in, out := &in.Capacity, &out.Capacity
-		*out = make(ResourceList, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
-		}
-	}
-	if in.Allocatable != nil {
-		in, out := &in.Allocatable, &out.Allocatable
-		*out = make(ResourceList, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
-		}
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]NodeCondition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Addresses != nil {
-		in, out := &in.Addresses, &out.Addresses
-		*out = make([]NodeAddress, len(*in))
-		copy(*out, *in)
-	}
-	out.NodeInfo = in.NodeInfo
-	if in.VolumesAttached != nil {
-		in, out := &in.VolumesAttached, &out.VolumesAttached
-		*out = make([]AttachedVolume, len(*in))
-		copy(*out, *in)
-	}
-	in.ContainerRuntimeInfo.DeepCopyInto(&out.ContainerRuntimeInfo)
+	in.Time.DeepCopyInto(&out.Time)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
-func (in *NodeStatus) DeepCopy() *NodeStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PrecheckResult.
+func (in *PrecheckResult) DeepCopy() *PrecheckResult {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeStatus)
+	out := new(PrecheckResult)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NodeSystemInfo) DeepCopyInto(out *NodeSystemInfo) {
+func (in *Proxy) DeepCopyInto(out *Proxy) {
 	*out = *in
+	if in.NoProxy != nil {
+		in, out := &in.NoProxy, &out.NoProxy
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeSystemInfo.
-func (in *NodeSystemInfo) DeepCopy() *NodeSystemInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Proxy.
+func (in *Proxy) DeepCopy() *Proxy {
 	if in == nil {
 		return nil
 	}
-	out := new(NodeSystemInfo)
+	out := new(Proxy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Operation) DeepCopyInto(out *Operation) {
+func (in *QuotaUsage) DeepCopyInto(out *QuotaUsage) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	if in.Steps != nil {
-		in, out := &in.Steps, &out.Steps
-		*out = make([]Step, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Operation.
-func (in *Operation) DeepCopy() *Operation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaUsage.
+func (in *QuotaUsage) DeepCopy() *QuotaUsage {
 	if in == nil {
 		return nil
 	}
-	out := new(Operation)
+	out := new(QuotaUsage)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Operation) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OperationCondition) DeepCopyInto(out *OperationCondition) {
+func (in *Record) DeepCopyInto(out *Record) {
 	*out = *in
-	if in.Status != nil {
-		in, out := &in.Status, &out.Status
-		*out = make([]StepStatus, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	in.CreateTime.DeepCopyInto(&out.CreateTime)
+	if in.ParseRecord != nil {
+		in, out := &in.ParseRecord, &out.ParseRecord
+		*out = make([]ParseRecord, len(*in))
+		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationCondition.
-func (in *OperationCondition) DeepCopy() *OperationCondition {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Record.
+func (in *Record) DeepCopy() *Record {
 	if in == nil {
 		return nil
 	}
-	out := new(OperationCondition)
+	out := new(Record)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OperationList) DeepCopyInto(out *OperationList) {
+func (in *Recovery) DeepCopyInto(out *Recovery) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Operation, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationList.
-func (in *OperationList) DeepCopy() *OperationList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Recovery.
+func (in *Recovery) DeepCopy() *Recovery {
 	if in == nil {
 		return nil
 	}
-	out := new(OperationList)
+	out := new(Recovery)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *OperationList) DeepCopyObject() runtime.Object {
+func (in *Recovery) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1083,11 +2848,13 @@ func (in *OperationList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
+func (in *RecoveryList) DeepCopyInto(out *RecoveryList) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]OperationCondition, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Recovery, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1095,54 +2862,71 @@ func (in *OperationStatus) DeepCopyInto(out *OperationStatus) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationStatus.
-func (in *OperationStatus) DeepCopy() *OperationStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryList.
+func (in *RecoveryList) DeepCopy() *RecoveryList {
 	if in == nil {
 		return nil
 	}
-	out := new(OperationStatus)
+	out := new(RecoveryList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ParseRecord) DeepCopyInto(out *ParseRecord) {
+func (in *RecycleBin) DeepCopyInto(out *RecycleBin) {
 	*out = *in
+	out.Retention = in.Retention
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ParseRecord.
-func (in *ParseRecord) DeepCopy() *ParseRecord {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecycleBin.
+func (in *RecycleBin) DeepCopy() *RecycleBin {
 	if in == nil {
 		return nil
 	}
-	out := new(ParseRecord)
+	out := new(RecycleBin)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PlatformSetting) DeepCopyInto(out *PlatformSetting) {
+func (in *Region) DeepCopyInto(out *Region) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Template.DeepCopyInto(&out.Template)
-	out.Terminal = in.Terminal
+	if in.ClusterDefaults != nil {
+		in, out := &in.ClusterDefaults, &out.ClusterDefaults
+		*out = new(ClusterDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(ResourceQuota)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformSetting.
-func (in *PlatformSetting) DeepCopy() *PlatformSetting {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Region.
+func (in *Region) DeepCopy() *Region {
 	if in == nil {
 		return nil
 	}
-	out := new(PlatformSetting)
+	out := new(Region)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PlatformSetting) DeepCopyObject() runtime.Object {
+func (in *Region) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1150,13 +2934,13 @@ func (in *PlatformSetting) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PlatformSettingList) DeepCopyInto(out *PlatformSettingList) {
+func (in *RegionList) DeepCopyInto(out *RegionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]PlatformSetting, len(*in))
+		*out = make([]Region, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1164,18 +2948,18 @@ func (in *PlatformSettingList) DeepCopyInto(out *PlatformSettingList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformSettingList.
-func (in *PlatformSettingList) DeepCopy() *PlatformSettingList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionList.
+func (in *RegionList) DeepCopy() *RegionList {
 	if in == nil {
 		return nil
 	}
-	out := new(PlatformSettingList)
+	out := new(RegionList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PlatformSettingList) DeepCopyObject() runtime.Object {
+func (in *RegionList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1183,179 +2967,206 @@ func (in *PlatformSettingList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Record) DeepCopyInto(out *Record) {
+func (in *RegionPrewarm) DeepCopyInto(out *RegionPrewarm) {
+	*out = *in
+	in.ContainerRuntime.DeepCopyInto(&out.ContainerRuntime)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionPrewarm.
+func (in *RegionPrewarm) DeepCopy() *RegionPrewarm {
+	if in == nil {
+		return nil
+	}
+	out := new(RegionPrewarm)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in ResourceList) DeepCopyInto(out *ResourceList) {
+	{
+		in := &in
+		*out = make(ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+		return
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceList.
+func (in ResourceList) DeepCopy() ResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceList)
+	in.DeepCopyInto(out)
+	return *out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceQuota) DeepCopyInto(out *ResourceQuota) {
+	*out = *in
+	if in.Clusters != nil {
+		in, out := &in.Clusters, &out.Clusters
+		*out = new(int)
+		**out = **in
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = new(int)
+		**out = **in
+	}
+	if in.ConcurrentOperations != nil {
+		in, out := &in.ConcurrentOperations, &out.ConcurrentOperations
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuota.
+func (in *ResourceQuota) DeepCopy() *ResourceQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Config) DeepCopyInto(out *S3Config) {
 	*out = *in
-	in.CreateTime.DeepCopyInto(&out.CreateTime)
-	if in.ParseRecord != nil {
-		in, out := &in.ParseRecord, &out.ParseRecord
-		*out = make([]ParseRecord, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Record.
-func (in *Record) DeepCopy() *Record {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Config.
+func (in *S3Config) DeepCopy() *S3Config {
 	if in == nil {
 		return nil
 	}
-	out := new(Record)
+	out := new(S3Config)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Recovery) DeepCopyInto(out *Recovery) {
+func (in *RegionQuota) DeepCopyInto(out *RegionQuota) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Hard.DeepCopyInto(&out.Hard)
+	out.Used = in.Used
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Recovery.
-func (in *Recovery) DeepCopy() *Recovery {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionQuota.
+func (in *RegionQuota) DeepCopy() *RegionQuota {
 	if in == nil {
 		return nil
 	}
-	out := new(Recovery)
+	out := new(RegionQuota)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Recovery) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RecoveryList) DeepCopyInto(out *RecoveryList) {
+func (in *RegistrySync) DeepCopyInto(out *RegistrySync) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Recovery, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	out.Interval = in.Interval
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]RegistrySyncImage, len(*in))
+		copy(*out, *in)
 	}
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryList.
-func (in *RecoveryList) DeepCopy() *RecoveryList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySync.
+func (in *RegistrySync) DeepCopy() *RegistrySync {
 	if in == nil {
 		return nil
 	}
-	out := new(RecoveryList)
+	out := new(RegistrySync)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RecoveryList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Region) DeepCopyInto(out *Region) {
+func (in *RegistrySyncImage) DeepCopyInto(out *RegistrySyncImage) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Region.
-func (in *Region) DeepCopy() *Region {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySyncImage.
+func (in *RegistrySyncImage) DeepCopy() *RegistrySyncImage {
 	if in == nil {
 		return nil
 	}
-	out := new(Region)
+	out := new(RegistrySyncImage)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *Region) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegionList) DeepCopyInto(out *RegionList) {
+func (in *RegistrySyncImageStatus) DeepCopyInto(out *RegistrySyncImageStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]Region, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegionList.
-func (in *RegionList) DeepCopy() *RegionList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySyncImageStatus.
+func (in *RegistrySyncImageStatus) DeepCopy() *RegistrySyncImageStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(RegionList)
+	out := new(RegistrySyncImageStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *RegionList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in ResourceList) DeepCopyInto(out *ResourceList) {
-	{
-		in := &in
-		*out = make(ResourceList, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val.DeepCopy()
+func (in *RegistrySyncStatus) DeepCopyInto(out *RegistrySyncStatus) {
+	*out = *in
+	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]RegistrySyncImageStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
-		return
 	}
+	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceList.
-func (in ResourceList) DeepCopy() ResourceList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrySyncStatus.
+func (in *RegistrySyncStatus) DeepCopy() *RegistrySyncStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(ResourceList)
+	out := new(RegistrySyncStatus)
 	in.DeepCopyInto(out)
-	return *out
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *S3Config) DeepCopyInto(out *S3Config) {
+func (in *ScriptHook) DeepCopyInto(out *ScriptHook) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Config.
-func (in *S3Config) DeepCopy() *S3Config {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScriptHook.
+func (in *ScriptHook) DeepCopy() *ScriptHook {
 	if in == nil {
 		return nil
 	}
-	out := new(S3Config)
+	out := new(ScriptHook)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1390,6 +3201,7 @@ func (in *Step) DeepCopyInto(out *Step) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	out.RetryBackoff = in.RetryBackoff
 	return
 }
 
@@ -1403,6 +3215,24 @@ func (in *Step) DeepCopy() *Step {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepAttempt) DeepCopyInto(out *StepAttempt) {
+	*out = *in
+	in.StartAt.DeepCopyInto(&out.StartAt)
+	in.EndAt.DeepCopyInto(&out.EndAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepAttempt.
+func (in *StepAttempt) DeepCopy() *StepAttempt {
+	if in == nil {
+		return nil
+	}
+	out := new(StepAttempt)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepNode) DeepCopyInto(out *StepNode) {
 	*out = *in
@@ -1419,6 +3249,54 @@ func (in *StepNode) DeepCopy() *StepNode {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepPolicy) DeepCopyInto(out *StepPolicy) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.RetryTimes != nil {
+		in, out := &in.RetryTimes, &out.RetryTimes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepPolicy.
+func (in *StepPolicy) DeepCopy() *StepPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StepPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepProgress) DeepCopyInto(out *StepProgress) {
+	*out = *in
+	in.UpdatedAt.DeepCopyInto(&out.UpdatedAt)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepProgress.
+func (in *StepProgress) DeepCopy() *StepProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(StepProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepStatus) DeepCopyInto(out *StepStatus) {
 	*out = *in
@@ -1429,6 +3307,13 @@ func (in *StepStatus) DeepCopyInto(out *StepStatus) {
 		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
+	if in.Attempts != nil {
+		in, out := &in.Attempts, &out.Attempts
+		*out = make([]StepAttempt, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1442,6 +3327,22 @@ func (in *StepStatus) DeepCopy() *StepStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Swap) DeepCopyInto(out *Swap) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Swap.
+func (in *Swap) DeepCopy() *Swap {
+	if in == nil {
+		return nil
+	}
+	out := new(Swap)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Taint) DeepCopyInto(out *Taint) {
 	*out = *in
@@ -1539,6 +3440,38 @@ func (in *TemplateList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ToolImages) DeepCopyInto(out *ToolImages) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolImages.
+func (in *ToolImages) DeepCopy() *ToolImages {
+	if in == nil {
+		return nil
+	}
+	out := new(ToolImages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnhealthyWorkload) DeepCopyInto(out *UnhealthyWorkload) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyWorkload.
+func (in *UnhealthyWorkload) DeepCopy() *UnhealthyWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WebTerminal) DeepCopyInto(out *WebTerminal) {
 	*out = *in
@@ -1555,6 +3488,95 @@ func (in *WebTerminal) DeepCopy() *WebTerminal {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookConfiguration) DeepCopyInto(out *WebhookConfiguration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]AdmissionWebhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfiguration.
+func (in *WebhookConfiguration) DeepCopy() *WebhookConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WebhookConfiguration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookConfigurationList) DeepCopyInto(out *WebhookConfigurationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WebhookConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookConfigurationList.
+func (in *WebhookConfigurationList) DeepCopy() *WebhookConfigurationList {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookConfigurationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WebhookConfigurationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookHook) DeepCopyInto(out *WebhookHook) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookHook.
+func (in *WebhookHook) DeepCopy() *WebhookHook {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerNode) DeepCopyInto(out *WorkerNode) {
 	*out = *in
@@ -19,6 +19,9 @@
 package v1
 
 import (
+	"fmt"
+	"strings"
+
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -29,6 +32,7 @@ const (
 	KindLoginRecord       = "LoginRecord"
 	KindGlobalRole        = "GlobalRole"
 	KindGlobalRoleBinding = "GlobalRoleBinding"
+	KindProject           = "Project"
 )
 
 // +genclient
@@ -65,6 +69,36 @@ type UserSpec struct {
 	Groups []string `json:"groups,omitempty"`
 	// password will be encrypted by mutating admission webhook
 	EncryptedPassword string `json:"password,omitempty"`
+	// Preferences of the user, used by kcctl and the console as request defaults.
+	// +optional
+	Preferences *UserPreferences `json:"preferences,omitempty"`
+}
+
+// UserPreferences are the defaults applied to the requests of a user when they are not given explicitly.
+type UserPreferences struct {
+	// Region limits the nodes, clusters and operations listed by default.
+	// +optional
+	Region string `json:"region,omitempty"`
+	// Project the user works in by default.
+	// +optional
+	Project string `json:"project,omitempty"`
+	// Output is the default output format of kcctl, one of table, wide, json and yaml.
+	// +optional
+	Output string `json:"output,omitempty"`
+}
+
+var validPreferenceOutputs = []string{"table", "wide", "json", "yaml"}
+
+func (p *UserPreferences) Validate() error {
+	if p.Output == "" {
+		return nil
+	}
+	for _, o := range validPreferenceOutputs {
+		if p.Output == o {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported output %q, must be one of %s", p.Output, strings.Join(validPreferenceOutputs, ", "))
 }
 
 type UserState string
@@ -162,6 +196,36 @@ type GlobalRoleBindingList struct {
 // +genclient:nonNamespaced
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 
+// Project isolates the clusters, nodes, backups and operations of a team sharing the platform.
+// Resources join a project with the kubeclipper.io/project label, and global role bindings
+// labeled with the project grant their roles only to the requests made in the project.
+type Project struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ProjectSpec `json:"spec"`
+}
+
+type ProjectSpec struct {
+	// Description of the project.
+	Description string `json:"description,omitempty"`
+	// Manager is the user responsible for the project.
+	Manager string `json:"manager,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProjectList contains a list of Project
+type ProjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Project `json:"items"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
 type LoginRecord struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -60,6 +60,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&TokenList{},
 		&LoginRecord{},
 		&LoginRecordList{},
+		&Project{},
+		&ProjectList{},
 		&metav1.ListOptions{},
 		&metav1.GetOptions{},
 		&metav1.WatchEvent{},
@@ -0,0 +1,32 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package validation
+
+import (
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/core/validation"
+	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ValidateProjectName requires a DNS label, so that the name is also a valid value of the project label.
+var ValidateProjectName = apimachineryvalidation.NameIsDNSLabel
+
+func ValidateProject(p *iamv1.Project) field.ErrorList {
+	return validation.ValidateObjectMeta(&p.ObjectMeta, false, ValidateProjectName, field.NewPath("metadata"))
+}
@@ -128,15 +128,26 @@ func (p *ComponentMetaList) WriteFile(path string, sort bool) error {
 func (p *ComponentMetaList) Exist(name, version, arch string) bool {
 	metaMap := make(map[string]struct{}, len(*p))
 	for _, v := range *p {
-		key := fmt.Sprintf("%s-%s-%s", v.Name, v.Version, v.Arch)
+		key := fmt.Sprintf("%s-%s-%s", v.Name, v.Version, downloader.NormalizeArch(v.Arch))
 		metaMap[key] = struct{}{}
 	}
-	if _, ok := metaMap[fmt.Sprintf("%s-%s-%s", name, version, arch)]; ok {
+	if _, ok := metaMap[fmt.Sprintf("%s-%s-%s", name, version, downloader.NormalizeArch(arch))]; ok {
 		return true
 	}
 	return false
 }
 
+// MissingArchs returns the architectures out of archs the resource has no bundle for.
+func (p *ComponentMetaList) MissingArchs(name, version string, archs []string) []string {
+	var missing []string
+	for _, arch := range archs {
+		if !p.Exist(name, version, arch) {
+			missing = append(missing, arch)
+		}
+	}
+	return missing
+}
+
 func (p *ComponentMetaList) AppendOnly(typeName, name, version, arch string) {
 	if !p.Exist(name, version, arch) {
 		*p = append(*p, v1.MetaResource{Type: typeName, Name: name, Version: version, Arch: arch})
@@ -22,11 +22,18 @@ import (
 	"reflect"
 	"strings"
 
+	"github.com/kubeclipper/kubeclipper/pkg/auditing"
 	authoptions "github.com/kubeclipper/kubeclipper/pkg/authentication/options"
+	"github.com/kubeclipper/kubeclipper/pkg/controller/workloadinventory"
+	"github.com/kubeclipper/kubeclipper/pkg/faultinject"
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
+	"github.com/kubeclipper/kubeclipper/pkg/nodeprovider"
 	bs "github.com/kubeclipper/kubeclipper/pkg/simple/backupstore"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/cache"
 
 	"github.com/kubeclipper/kubeclipper/pkg/simple/staticserver"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
 
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 
@@ -49,24 +56,38 @@ const (
 
 // Config defines everything needed for apiserver to deal with external services
 type Config struct {
-	GenericServerRunOptions *generic.ServerRunOptions          `json:"generic" yaml:"generic" mapstructure:"generic"`
-	StaticServerOptions     *staticserver.Options              `json:"staticServer" yaml:"staticServer" mapstructure:"staticServer"`
-	EtcdOptions             *etcd.Options                      `json:"etcd,omitempty" yaml:"etcd,omitempty" mapstructure:"etcd"`
-	CacheOptions            *cache.Options                     `json:"cache,omitempty" yaml:"cache,omitempty" mapstructure:"cache"`
-	MQOptions               *natsio.NatsOptions                `json:"mq,omitempty" yaml:"mq,omitempty"  mapstructure:"mq"`
-	LogOptions              *logger.Options                    `json:"log,omitempty" yaml:"log,omitempty" mapstructure:"log"`
-	AuthenticationOptions   *authoptions.AuthenticationOptions `json:"authentication,omitempty" yaml:"authentication,omitempty" mapstructure:"authentication"`
+	GenericServerRunOptions  *generic.ServerRunOptions          `json:"generic" yaml:"generic" mapstructure:"generic"`
+	StaticServerOptions      *staticserver.Options              `json:"staticServer" yaml:"staticServer" mapstructure:"staticServer"`
+	EtcdOptions              *etcd.Options                      `json:"etcd,omitempty" yaml:"etcd,omitempty" mapstructure:"etcd"`
+	CacheOptions             *cache.Options                     `json:"cache,omitempty" yaml:"cache,omitempty" mapstructure:"cache"`
+	MQOptions                *natsio.NatsOptions                `json:"mq,omitempty" yaml:"mq,omitempty"  mapstructure:"mq"`
+	LogOptions               *logger.Options                    `json:"log,omitempty" yaml:"log,omitempty" mapstructure:"log"`
+	AuthenticationOptions    *authoptions.AuthenticationOptions `json:"authentication,omitempty" yaml:"authentication,omitempty" mapstructure:"authentication"`
+	AuditOptions             *auditing.Options                  `json:"audit,omitempty" yaml:"audit,omitempty" mapstructure:"audit"`
+	FaultInjectionOptions    *faultinject.Options               `json:"faultInjection,omitempty" yaml:"faultInjection,omitempty" mapstructure:"faultInjection"`
+	InventoryOptions         *inventory.Options                 `json:"inventory,omitempty" yaml:"inventory,omitempty" mapstructure:"inventory"`
+	MetricsOptions           *metrics.Options                   `json:"metrics,omitempty" yaml:"metrics,omitempty" mapstructure:"metrics"`
+	TracingOptions           *tracing.Options                   `json:"tracing,omitempty" yaml:"tracing,omitempty" mapstructure:"tracing"`
+	WorkloadInventoryOptions *workloadinventory.Options         `json:"workloadInventory,omitempty" yaml:"workloadInventory,omitempty" mapstructure:"workloadInventory"`
+	NodeProviderOptions      *nodeprovider.Options              `json:"nodeProvider,omitempty" yaml:"nodeProvider,omitempty" mapstructure:"nodeProvider"`
 }
 
 func New() *Config {
 	return &Config{
-		GenericServerRunOptions: generic.NewServerRunOptions(),
-		StaticServerOptions:     staticserver.NewOptions(),
-		EtcdOptions:             etcd.NewEtcdOptions(),
-		CacheOptions:            cache.NewEtcdOptions(),
-		MQOptions:               natsio.NewOptions(),
-		LogOptions:              logger.NewLogOptions(),
-		AuthenticationOptions:   authoptions.NewAuthenticateOptions(),
+		GenericServerRunOptions:  generic.NewServerRunOptions(),
+		StaticServerOptions:      staticserver.NewOptions(),
+		EtcdOptions:              etcd.NewEtcdOptions(),
+		CacheOptions:             cache.NewEtcdOptions(),
+		MQOptions:                natsio.NewOptions(),
+		LogOptions:               logger.NewLogOptions(),
+		AuthenticationOptions:    authoptions.NewAuthenticateOptions(),
+		AuditOptions:             auditing.NewOptions(),
+		FaultInjectionOptions:    faultinject.NewOptions(),
+		InventoryOptions:         inventory.NewOptions(),
+		MetricsOptions:           metrics.NewOptions(0),
+		TracingOptions:           tracing.NewOptions(),
+		WorkloadInventoryOptions: workloadinventory.NewOptions(),
+		NodeProviderOptions:      nodeprovider.NewOptions(),
 	}
 }
 
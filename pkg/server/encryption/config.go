@@ -0,0 +1,165 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+// Package encryption encrypts the resources kc-server stores in etcd, like the EncryptionConfiguration
+// of kube-apiserver. The resources are encrypted by AES-GCM envelope encryption: every value is sealed
+// with a random data key, which is sealed with a key of the providers file and stored alongside.
+package encryption
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/storage/value"
+	"k8s.io/apiserver/pkg/storage/value/encrypt/identity"
+	"sigs.k8s.io/yaml"
+)
+
+// Configuration is the providers file, it lists the providers of the encrypted resources.
+type Configuration struct {
+	Kind       string `json:"kind,omitempty"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	// Resources are the encrypted resources with their providers.
+	Resources []ResourceConfiguration `json:"resources"`
+}
+
+// ResourceConfiguration lists the providers of some resources.
+type ResourceConfiguration struct {
+	// Resources are the lowercase plural names of the resources, e.g. backuppoints, optionally
+	// qualified by their group, e.g. backuppoints.core.kubeclipper.io.
+	Resources []string `json:"resources"`
+	// Providers read the values in order, the first one writes them.
+	Providers []ProviderConfiguration `json:"providers"`
+}
+
+// ProviderConfiguration is one of the providers, only one field is set.
+type ProviderConfiguration struct {
+	// AESGCM encrypts the values with AES-GCM envelope encryption.
+	AESGCM *AESConfiguration `json:"aesgcm,omitempty"`
+	// Identity stores the values unencrypted, it reads the values stored before encryption was enabled.
+	Identity *IdentityConfiguration `json:"identity,omitempty"`
+}
+
+// AESConfiguration holds the keys sealing the data keys, the first one seals the written values.
+type AESConfiguration struct {
+	Keys []Key `json:"keys"`
+}
+
+// Key is a named key encryption key.
+type Key struct {
+	// Name is stored with the values the key sealed, it must be unique among the keys of the resource.
+	Name string `json:"name"`
+	// Secret is the base64 encoded key of 16, 24 or 32 bytes.
+	Secret string `json:"secret"`
+}
+
+type IdentityConfiguration struct{}
+
+// Transformers are the transformers of the encrypted resources.
+type Transformers map[schema.GroupResource]value.Transformer
+
+// For returns the transformer of the resource, nil when it is not encrypted. A resource
+// configured without a group matches the resource of any group.
+func (t Transformers) For(resource schema.GroupResource) value.Transformer {
+	if transformer, ok := t[resource]; ok {
+		return transformer
+	}
+	return t[schema.GroupResource{Resource: resource.Resource}]
+}
+
+// LoadConfiguration reads the providers file and returns the transformers of the resources.
+func LoadConfiguration(path string) (Transformers, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read encryption provider config %s failed: %v", path, err)
+	}
+	c := &Configuration{}
+	if err = yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("parse encryption provider config %s failed: %v", path, err)
+	}
+	transformers, err := c.Transformers()
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption provider config %s: %v", path, err)
+	}
+	return transformers, nil
+}
+
+// Transformers builds the transformers of the resources of the configuration.
+func (c *Configuration) Transformers() (Transformers, error) {
+	transformers := make(Transformers)
+	for i, rc := range c.Resources {
+		if len(rc.Resources) == 0 {
+			return nil, fmt.Errorf("resources[%d] lists no resource", i)
+		}
+		prefixed, err := rc.prefixTransformers()
+		if err != nil {
+			return nil, fmt.Errorf("resources[%d]: %v", i, err)
+		}
+		transformer := value.NewPrefixTransformers(fmt.Errorf("no provider of resources[%d] can read the value", i), prefixed...)
+		for _, r := range rc.Resources {
+			gr := schema.ParseGroupResource(r)
+			if _, ok := transformers[gr]; ok {
+				return nil, fmt.Errorf("resource %s is listed more than once", r)
+			}
+			transformers[gr] = transformer
+		}
+	}
+	return transformers, nil
+}
+
+func (rc *ResourceConfiguration) prefixTransformers() ([]value.PrefixTransformer, error) {
+	if len(rc.Providers) == 0 {
+		return nil, fmt.Errorf("no provider")
+	}
+	var prefixed []value.PrefixTransformer
+	names := make(map[string]bool)
+	for i, p := range rc.Providers {
+		switch {
+		case p.AESGCM != nil && p.Identity != nil:
+			return nil, fmt.Errorf("providers[%d] sets more than one provider", i)
+		case p.Identity != nil:
+			prefixed = append(prefixed, value.PrefixTransformer{Transformer: identity.NewEncryptCheckTransformer()})
+		case p.AESGCM != nil:
+			if len(p.AESGCM.Keys) == 0 {
+				return nil, fmt.Errorf("providers[%d] has no key", i)
+			}
+			for _, k := range p.AESGCM.Keys {
+				if k.Name == "" || names[k.Name] {
+					return nil, fmt.Errorf("providers[%d] has an empty or duplicate key name %q", i, k.Name)
+				}
+				names[k.Name] = true
+				secret, err := base64.StdEncoding.DecodeString(k.Secret)
+				if err != nil {
+					return nil, fmt.Errorf("secret of key %s is not base64 encoded: %v", k.Name, err)
+				}
+				transformer, err := NewEnvelopeTransformer(secret)
+				if err != nil {
+					return nil, fmt.Errorf("key %s: %v", k.Name, err)
+				}
+				prefixed = append(prefixed, value.PrefixTransformer{
+					Prefix:      []byte(envelopePrefix + k.Name + ":"),
+					Transformer: transformer,
+				})
+			}
+		default:
+			return nil, fmt.Errorf("providers[%d] sets no provider", i)
+		}
+	}
+	return prefixed, nil
+}
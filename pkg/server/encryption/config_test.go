@@ -0,0 +1,194 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/storage/storagebackend"
+	"k8s.io/apiserver/pkg/storage/value"
+)
+
+var (
+	key1 = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	key2 = base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+)
+
+func writeConfig(t *testing.T, content string) string {
+	p := filepath.Join(t.TempDir(), "encryption.yaml")
+	if err := os.WriteFile(p, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadConfiguration(t *testing.T) {
+	p := writeConfig(t, `
+kind: EncryptionConfiguration
+apiVersion: apiserver.config.kubeclipper.io/v1
+resources:
+- resources: [backuppoints, platformsettings.core.kubeclipper.io]
+  providers:
+  - aesgcm:
+      keys:
+      - name: key1
+        secret: `+key1+`
+  - identity: {}
+`)
+	transformers, err := LoadConfiguration(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, gr := range []schema.GroupResource{
+		{Group: "core.kubeclipper.io", Resource: "backuppoints"},
+		{Group: "core.kubeclipper.io", Resource: "platformsettings"},
+	} {
+		if transformers.For(gr) == nil {
+			t.Errorf("expected %s encrypted", gr)
+		}
+	}
+	if transformers.For(schema.GroupResource{Group: "iam.kubeclipper.io", Resource: "platformsettings"}) != nil {
+		t.Error("expected platformsettings of another group unencrypted")
+	}
+	if transformers.For(schema.GroupResource{Group: "core.kubeclipper.io", Resource: "clusters"}) != nil {
+		t.Error("expected clusters unencrypted")
+	}
+
+	// the values stored before encryption are read by the identity provider and stale
+	transformer := transformers.For(schema.GroupResource{Group: "core.kubeclipper.io", Resource: "backuppoints"})
+	ctx := value.DefaultContext("/registry/core.kubeclipper.io/backuppoints/bp1")
+	out, stale, err := transformer.TransformFromStorage([]byte(`{"kind":"BackupPoint"}`), ctx)
+	if err != nil || !stale || string(out) != `{"kind":"BackupPoint"}` {
+		t.Errorf("TransformFromStorage() = %s, %v, %v", out, stale, err)
+	}
+	stored, err := transformer.TransformToStorage([]byte(`{"kind":"BackupPoint"}`), ctx)
+	if err != nil || !strings.HasPrefix(string(stored), envelopePrefix+"key1:") {
+		t.Errorf("TransformToStorage() = %q, %v", stored, err)
+	}
+}
+
+func TestLoadConfigurationInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unknown field", content: "resources:\n- resources: [clusters]\n  provider: []\n"},
+		{name: "no provider", content: "resources:\n- resources: [clusters]\n"},
+		{name: "no resource", content: "resources:\n- providers:\n  - identity: {}\n"},
+		{name: "short key", content: "resources:\n- resources: [clusters]\n  providers:\n  - aesgcm:\n      keys:\n      - name: k\n        secret: " +
+			base64.StdEncoding.EncodeToString([]byte("short")) + "\n"},
+		{name: "duplicate key", content: "resources:\n- resources: [clusters]\n  providers:\n  - aesgcm:\n      keys:\n      - name: k\n        secret: " +
+			key1 + "\n      - name: k\n        secret: " + key2 + "\n"},
+		{name: "duplicate resource", content: "resources:\n- resources: [clusters]\n  providers:\n  - identity: {}\n" +
+			"- resources: [clusters]\n  providers:\n  - identity: {}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadConfiguration(writeConfig(t, tt.content)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	load := func(keys ...string) value.Transformer {
+		content := "resources:\n- resources: [clusters]\n  providers:\n  - aesgcm:\n      keys:\n"
+		for _, k := range keys {
+			secret := key1
+			if k == "key2" {
+				secret = key2
+			}
+			content += "      - name: " + k + "\n        secret: " + secret + "\n"
+		}
+		transformers, err := LoadConfiguration(writeConfig(t, content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return transformers.For(schema.GroupResource{Resource: "clusters"})
+	}
+	ctx := value.DefaultContext("/registry/core.kubeclipper.io/clusters/c1")
+	stored, err := load("key1").TransformToStorage([]byte("data"), ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotated := load("key2", "key1")
+	out, stale, err := rotated.TransformFromStorage(stored, ctx)
+	if err != nil || !stale || string(out) != "data" {
+		t.Fatalf("TransformFromStorage() = %s, %v, %v", out, stale, err)
+	}
+	stored, err = rotated.TransformToStorage(out, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out, stale, err = load("key2").TransformFromStorage(stored, ctx); err != nil || stale || string(out) != "data" {
+		t.Errorf("TransformFromStorage() = %s, %v, %v", out, stale, err)
+	}
+	if _, _, err = load("key1").TransformFromStorage(stored, ctx); err == nil {
+		t.Error("expected the removed key unable to read the rewritten value")
+	}
+}
+
+type fakeRESTOptionsGetter struct {
+	config *storagebackend.Config
+}
+
+func (g *fakeRESTOptionsGetter) GetRESTOptions(resource schema.GroupResource) (generic.RESTOptions, error) {
+	return generic.RESTOptions{StorageConfig: g.config}, nil
+}
+
+func TestRESTOptionsGetter(t *testing.T) {
+	transformer := value.NewMutableTransformer(value.IdentityTransformer)
+	shared := &storagebackend.Config{}
+	g := &RESTOptionsGetter{
+		RESTOptionsGetter: &fakeRESTOptionsGetter{config: shared},
+		Transformers:      Transformers{{Resource: "backuppoints"}: transformer},
+	}
+	opts, err := g.GetRESTOptions(schema.GroupResource{Group: "core.kubeclipper.io", Resource: "backuppoints"})
+	if err != nil || opts.StorageConfig.Transformer != transformer {
+		t.Fatalf("expected backuppoints encrypted, got %v, %v", opts.StorageConfig, err)
+	}
+	if shared.Transformer != nil {
+		t.Error("expected the shared storage config unchanged")
+	}
+	if opts, _ = g.GetRESTOptions(schema.GroupResource{Group: "core.kubeclipper.io", Resource: "clusters"}); opts.StorageConfig.Transformer != nil {
+		t.Error("expected clusters unencrypted")
+	}
+}
+
+func TestResourceOf(t *testing.T) {
+	prefix := "/registry/kubeclipper-server/"
+	tests := map[string]schema.GroupResource{
+		prefix + "core.kubeclipper.io/clusters/c1":           {Group: "core.kubeclipper.io", Resource: "clusters"},
+		prefix + "coordination.k8s.io/leases/default/node-1": {Group: "coordination.k8s.io", Resource: "leases"},
+		prefix + "compact_rev_key":                           {},
+	}
+	for key, want := range tests {
+		if got := resourceOf(prefix, key); got != want {
+			t.Errorf("resourceOf(%s) = %v, want %v", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+
+	"k8s.io/apiserver/pkg/storage/value"
+)
+
+const (
+	// envelopePrefix is followed by the name of the key sealing the data key and a colon.
+	envelopePrefix = "k8s:enc:aesgcm-envelope:v1:"
+	dataKeySize    = 32
+)
+
+// envelopeTransformer seals every value with a new random data key, the data key sealed by the
+// key encryption key is stored before the value as a 2 bytes big endian length and the sealed key.
+type envelopeTransformer struct {
+	kek cipher.AEAD
+}
+
+// NewEnvelopeTransformer returns the AES-GCM envelope transformer of the key encryption key.
+func NewEnvelopeTransformer(key []byte) (value.Transformer, error) {
+	kek, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &envelopeTransformer{kek: kek}, nil
+}
+
+func (t *envelopeTransformer) TransformFromStorage(data []byte, context value.Context) ([]byte, bool, error) {
+	if len(data) < 2 {
+		return nil, false, fmt.Errorf("the stored data is too short to hold a data key")
+	}
+	n := int(binary.BigEndian.Uint16(data))
+	if len(data) < 2+n {
+		return nil, false, fmt.Errorf("the stored data is too short to hold a data key")
+	}
+	key, err := open(t.kek, data[2:2+n], context.AuthenticatedData())
+	if err != nil {
+		return nil, false, fmt.Errorf("unseal data key failed: %v", err)
+	}
+	dek, err := newGCM(key)
+	if err != nil {
+		return nil, false, err
+	}
+	out, err := open(dek, data[2+n:], context.AuthenticatedData())
+	return out, false, err
+}
+
+func (t *envelopeTransformer) TransformToStorage(data []byte, context value.Context) ([]byte, error) {
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	dek, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	sealedKey, err := seal(t.kek, key, context.AuthenticatedData())
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := seal(dek, data, context.AuthenticatedData())
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, 2, 2+len(sealedKey)+len(sealed))
+	binary.BigEndian.PutUint16(out, uint16(len(sealedKey)))
+	out = append(out, sealedKey...)
+	return append(out, sealed...), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal prepends a random nonce to the sealed data.
+func seal(aead cipher.AEAD, plain, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plain)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plain, additional), nil
+}
+
+func open(aead cipher.AEAD, data, additional []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("the sealed data is shorter than a nonce")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], additional)
+}
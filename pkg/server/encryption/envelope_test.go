@@ -0,0 +1,66 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/apiserver/pkg/storage/value"
+)
+
+func TestEnvelopeTransformer(t *testing.T) {
+	transformer, err := NewEnvelopeTransformer(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := value.DefaultContext("/registry/core.kubeclipper.io/clusters/c1")
+	plain := []byte(`{"kind":"Cluster"}`)
+	first, err := transformer.TransformToStorage(plain, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := transformer.TransformToStorage(plain, ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(first, plain) || bytes.Equal(first, second) {
+		t.Error("expected every value sealed with a new data key")
+	}
+	out, stale, err := transformer.TransformFromStorage(first, ctx)
+	if err != nil || stale || !bytes.Equal(out, plain) {
+		t.Fatalf("TransformFromStorage() = %s, %v, %v", out, stale, err)
+	}
+
+	// the values are bound to their keys
+	if _, _, err = transformer.TransformFromStorage(first, value.DefaultContext("/registry/core.kubeclipper.io/clusters/c2")); err == nil {
+		t.Error("expected error reading the value of another key")
+	}
+	if _, _, err = transformer.TransformFromStorage(first[:10], ctx); err == nil {
+		t.Error("expected error reading a truncated value")
+	}
+	tampered := append([]byte(nil), first...)
+	tampered[len(tampered)-1] ^= 1
+	if _, _, err = transformer.TransformFromStorage(tampered, ctx); err == nil {
+		t.Error("expected error reading a tampered value")
+	}
+
+	if _, err = NewEnvelopeTransformer([]byte("short")); err == nil {
+		t.Error("expected error of an invalid key size")
+	}
+}
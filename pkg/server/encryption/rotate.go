@@ -0,0 +1,116 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/storage/value"
+)
+
+const rotatePageSize = 500
+
+// RESTOptionsGetter sets the transformers of the encrypted resources on their storage config.
+type RESTOptionsGetter struct {
+	generic.RESTOptionsGetter
+	Transformers Transformers
+}
+
+func (g *RESTOptionsGetter) GetRESTOptions(resource schema.GroupResource) (generic.RESTOptions, error) {
+	opts, err := g.RESTOptionsGetter.GetRESTOptions(resource)
+	if err != nil {
+		return opts, err
+	}
+	if transformer := g.Transformers.For(resource); transformer != nil {
+		// the getter may share the config between the resources
+		c := *opts.StorageConfig
+		c.Transformer = transformer
+		opts.StorageConfig = &c
+	}
+	return opts, nil
+}
+
+// Rotate rewrites the values of the encrypted resources under the etcd prefix which were not written
+// by the first provider of their resource, e.g. after a new key is put first or the resource is newly
+// encrypted. kc-server must run with the same providers file. It returns the number of rewritten values.
+func Rotate(ctx context.Context, cli *clientv3.Client, prefix string, transformers Transformers) (int, error) {
+	prefix = path.Join("/", prefix) + "/"
+	end := clientv3.GetPrefixRangeEnd(prefix)
+	rewritten := 0
+	for key := prefix; ; {
+		resp, err := cli.Get(ctx, key, clientv3.WithRange(end), clientv3.WithLimit(rotatePageSize))
+		if err != nil {
+			return rewritten, err
+		}
+		for _, kv := range resp.Kvs {
+			transformer := transformers.For(resourceOf(prefix, string(kv.Key)))
+			if transformer == nil {
+				continue
+			}
+			changed, err := rewrite(ctx, cli, transformer, string(kv.Key), kv.Value, kv.ModRevision)
+			if err != nil {
+				return rewritten, fmt.Errorf("rewrite %s failed: %v", kv.Key, err)
+			}
+			if changed {
+				rewritten++
+			}
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return rewritten, nil
+		}
+		key = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// rewrite stores the value again if it is stale, unless it changed since it was read, kc-server
+// wrote it with the first provider then.
+func rewrite(ctx context.Context, cli *clientv3.Client, transformer value.Transformer, key string, data []byte, rev int64) (bool, error) {
+	// kc-server authenticates the values with their keys
+	authenticated := value.DefaultContext(key)
+	plain, stale, err := transformer.TransformFromStorage(data, authenticated)
+	if err != nil || !stale {
+		return false, err
+	}
+	out, err := transformer.TransformToStorage(plain, authenticated)
+	if err != nil {
+		return false, err
+	}
+	resp, err := cli.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", rev)).
+		Then(clientv3.OpPut(key, string(out))).
+		Commit()
+	if err != nil {
+		return false, err
+	}
+	return resp.Succeeded, nil
+}
+
+// resourceOf returns the resource of the key, which is the prefix followed by group/resource/name.
+func resourceOf(prefix, key string) schema.GroupResource {
+	parts := strings.SplitN(strings.TrimPrefix(key, prefix), "/", 3)
+	if len(parts) < 3 {
+		return schema.GroupResource{}
+	}
+	return schema.GroupResource{Group: parts[0], Resource: parts[1]}
+}
@@ -81,6 +81,7 @@ func getAuthorizerAttributes(ctx context.Context) (authorizer.Attributes, error)
 	attribs.Resource = requestInfo.Resource
 	attribs.Subresource = requestInfo.Subresource
 	attribs.Name = requestInfo.Name
+	attribs.Project = requestInfo.Project
 
 	return &attribs, nil
 }
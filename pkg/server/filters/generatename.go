@@ -0,0 +1,87 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package filters
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+
+	"github.com/emicklei/go-restful"
+	"k8s.io/apiserver/pkg/storage/names"
+
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// WithGenerateName names the resources created with metadata.generateName and without
+// metadata.name, the name is the prefix followed by random characters like kubernetes does.
+// The name is set in the request body so that the handlers, which need the name before
+// storing the resource, see the resource as if the client had named it.
+func WithGenerateName() restful.FilterFunction {
+	return func(req *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+		info, ok := request.InfoFrom(req.Request.Context())
+		if !ok || !info.IsResourceRequest || info.Verb != "create" || info.Name != "" || req.Request.Body == nil {
+			chain.ProcessFilter(req, response)
+			return
+		}
+		if mediaType, _, _ := mime.ParseMediaType(req.Request.Header.Get("Content-Type")); mediaType != restful.MIME_JSON {
+			chain.ProcessFilter(req, response)
+			return
+		}
+		body, err := io.ReadAll(req.Request.Body)
+		_ = req.Request.Body.Close()
+		if err != nil {
+			restplus.HandleBadRequest(response, req, err)
+			return
+		}
+		if named, ok := generateName(body); ok {
+			body = named
+		}
+		req.Request.Body = io.NopCloser(bytes.NewReader(body))
+		req.Request.ContentLength = int64(len(body))
+		chain.ProcessFilter(req, response)
+	}
+}
+
+// generateName returns the body with the generated name, and false if the body does not ask for one.
+// Bodies which are not json objects are left to the handlers to reject.
+func generateName(body []byte) ([]byte, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// keep the numbers as written, float64 would round the large ones
+	decoder.UseNumber()
+	var obj map[string]interface{}
+	if err := decoder.Decode(&obj); err != nil {
+		return nil, false
+	}
+	metadata, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	prefix, _ := metadata["generateName"].(string)
+	if name, _ := metadata["name"].(string); prefix == "" || name != "" {
+		return nil, false
+	}
+	metadata["name"] = names.SimpleNameGenerator.GenerateName(prefix)
+	named, err := json.Marshal(obj)
+	if err != nil {
+		return nil, false
+	}
+	return named, true
+}
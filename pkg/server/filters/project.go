@@ -0,0 +1,106 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package filters
+
+import (
+	"fmt"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// WithProjectScope restricts the requests made in a project to the resources of the project.
+// Lists and watches of the scoped resources only select the objects labeled with the project,
+// and the named objects of another project are reported as not found. Objects which do not
+// exist yet, e.g. the one a create request is about to make, are left to the handlers.
+// Lists of projects only return the project of the request, and the other projects are not found.
+func WithProjectScope(scoped map[string]rest.Getter) restful.FilterFunction {
+	return func(req *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+		info, ok := request.InfoFrom(req.Request.Context())
+		if !ok || !info.IsResourceRequest || info.Project == "" {
+			chain.ProcessFilter(req, response)
+			return
+		}
+		if info.APIGroup == iamv1.GroupName && info.Resource == "projects" {
+			switch info.Name {
+			case "":
+				scopeQuery(req, query.ParameterFieldSelector, fmt.Sprintf("metadata.name=%s", info.Project))
+			case info.Project:
+			default:
+				restplus.HandleNotFound(response, req, apimachineryErrors.NewNotFound(iamv1.Resource("projects"), info.Name))
+				return
+			}
+			chain.ProcessFilter(req, response)
+			return
+		}
+		getter, ok := scoped[info.Resource]
+		if !ok || info.APIGroup != corev1.GroupName {
+			chain.ProcessFilter(req, response)
+			return
+		}
+		if info.Name == "" {
+			scopeQuery(req, query.ParameterLabelSelector,
+				labels.SelectorFromSet(labels.Set{common.LabelProject: info.Project}).String())
+			chain.ProcessFilter(req, response)
+			return
+		}
+		obj, err := getter.Get(req.Request.Context(), info.Name, &metav1.GetOptions{ResourceVersion: "0"})
+		if err != nil {
+			if apimachineryErrors.IsNotFound(err) {
+				chain.ProcessFilter(req, response)
+				return
+			}
+			restplus.HandleInternalError(response, req, err)
+			return
+		}
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			restplus.HandleInternalError(response, req, err)
+			return
+		}
+		if accessor.GetLabels()[common.LabelProject] != info.Project {
+			restplus.HandleNotFound(response, req, apimachineryErrors.NewNotFound(
+				corev1.Resource(info.Resource), info.Name))
+			return
+		}
+		chain.ProcessFilter(req, response)
+	}
+}
+
+// scopeQuery adds the requirement to the selector of the query parameter param.
+func scopeQuery(req *restful.Request, param, requirement string) {
+	values := req.Request.URL.Query()
+	if selector := values.Get(param); selector != "" {
+		requirement = selector + "," + requirement
+	}
+	values.Set(param, requirement)
+	req.Request.URL.RawQuery = values.Encode()
+	// drop the form parsed from the previous query, if any.
+	req.Request.Form = nil
+}
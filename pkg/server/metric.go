@@ -19,16 +19,23 @@
 package server
 
 import (
+	"strconv"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	etcd3metrics "k8s.io/apiserver/pkg/storage/etcd3/metrics"
 	compbasemetrics "k8s.io/component-base/metrics"
 
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+	"github.com/kubeclipper/kubeclipper/pkg/service/delivery"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
 )
 
 var (
 	RequestCounter = compbasemetrics.NewCounterVec(
 		&compbasemetrics.CounterOpts{
-			Name:           "ks_server_request_total",
-			Help:           "Counter of ks_server requests broken out for each verb, group, version, resource and HTTP response code.",
+			Name:           "kc_server_request_total",
+			Help:           "Counter of kc-server requests broken out for each verb, group, version, resource and HTTP response code.",
 			StabilityLevel: compbasemetrics.ALPHA,
 		},
 		[]string{"verb", "group", "version", "resource", "code"},
@@ -36,7 +43,7 @@ var (
 
 	RequestLatencies = compbasemetrics.NewHistogramVec(
 		&compbasemetrics.HistogramOpts{
-			Name: "ks_server_request_duration_seconds",
+			Name: "kc_server_request_duration_seconds",
 			Help: "Response latency distribution in seconds for each verb, group, version, resource",
 			// This metric is used for verifying api call latencies SLO,
 			// as well as tracking regressions in this aspects.
@@ -58,4 +65,29 @@ func registerMetrics() {
 	for _, m := range metricsList {
 		metrics.MustRegister(m)
 	}
+	// etcd_request_duration_seconds is recorded by the etcd storage of resources
+	etcd3metrics.Register()
+	delivery.RegisterMetrics()
+}
+
+// monitorRequest records the count and latency of requests. It runs before the request info
+// is resolved, which is read back from the request once the chain returns.
+func monitorRequest(r *restful.Request, response *restful.Response, chain *restful.FilterChain) {
+	start := time.Now()
+	chain.ProcessFilter(r, response)
+
+	info, ok := request.InfoFrom(r.Request.Context())
+	if !ok || !info.IsResourceRequest {
+		return
+	}
+	resource := info.Resource
+	if info.Subresource != "" {
+		resource += "/" + info.Subresource
+	}
+	code := response.StatusCode()
+	if code == 0 {
+		code = 200
+	}
+	RequestCounter.WithLabelValues(info.Verb, info.APIGroup, info.APIVersion, resource, strconv.Itoa(code)).Inc()
+	RequestLatencies.WithLabelValues(info.Verb, info.APIGroup, info.APIVersion, resource).Observe(time.Since(start).Seconds())
 }
@@ -0,0 +1,262 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package openapi builds the OpenAPI spec of the kc-server REST API from its go-restful routes,
+// serves it at /openapi/v3 for client generation and validates the request bodies against it.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	restfulspec "github.com/emicklei/go-restful-openapi"
+	"github.com/go-openapi/spec"
+	"k8s.io/component-base/version"
+)
+
+// V3Path serves the OpenAPI v3 spec.
+const V3Path = "/openapi/v3"
+
+const (
+	modulePackagePrefix = "github.com/kubeclipper/kubeclipper/pkg/"
+	definitionsPrefix   = "#/definitions/"
+)
+
+// BuildSwagger builds the swagger 2.0 spec of the routes of the web services.
+func BuildSwagger(webServices []*restful.WebService) *spec.Swagger {
+	return restfulspec.BuildSwagger(restfulspec.Config{
+		WebServices:                   webServices,
+		ModelTypeNameHandler:          modelTypeName,
+		SchemaFormatHandler:           schemaFormat,
+		PostBuildSwaggerObjectHandler: enrichSwaggerObject,
+	})
+}
+
+// Install serves the OpenAPI v3 spec of the web services registered in the container and validates
+// the request bodies of their routes, it is called once all the APIs are installed.
+func Install(c *restful.Container) error {
+	webServices := c.RegisteredWebServices()
+	swagger := BuildSwagger(webServices)
+	doc, err := ConvertToV3(swagger)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	validator := NewValidator(swagger)
+	for _, ws := range webServices {
+		ws.Filter(validator.Filter)
+	}
+	c.HandleWithFilter(V3Path, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	return nil
+}
+
+// modelTypeName names the definitions after the package of their types, the default names only keep
+// the last element of the package path and the types of different API groups overwrite each other,
+// e.g. core.v1.Cluster, iam.v1.User and io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta.
+func modelTypeName(t reflect.Type) (string, bool) {
+	pkg := t.PkgPath()
+	// builtin and standard library types keep their default names, the spec builder maps some of them to primitives
+	if t.Name() == "" || !strings.Contains(pkg, ".") {
+		return "", false
+	}
+	switch {
+	case strings.HasPrefix(pkg, modulePackagePrefix+"scheme/"):
+		pkg = strings.TrimPrefix(pkg, modulePackagePrefix+"scheme/")
+	case strings.HasPrefix(pkg, modulePackagePrefix):
+		pkg = strings.TrimPrefix(pkg, modulePackagePrefix)
+	case strings.HasPrefix(pkg, "k8s.io/"):
+		pkg = "io.k8s." + strings.TrimPrefix(pkg, "k8s.io/")
+	}
+	return strings.ReplaceAll(pkg, "/", ".") + "." + t.Name(), true
+}
+
+const (
+	quantityFormat     = "quantity"
+	rawExtensionFormat = "raw-extension"
+	fieldsV1Format     = "fields-v1"
+)
+
+// customTypeFormats marks the types with their own JSON encoding, the spec builder describes
+// their fields as strings, or their go fields for the values of maps, and enrichSwaggerObject
+// replaces the marked schemas with customSchemas.
+var customTypeFormats = map[string]string{
+	"*resource.Quantity":                            quantityFormat,
+	"io.k8s.apimachinery.pkg.api.resource.Quantity": quantityFormat,
+	"*runtime.RawExtension":                         rawExtensionFormat,
+	"io.k8s.apimachinery.pkg.runtime.RawExtension":  rawExtensionFormat,
+	"*v1.FieldsV1":                                  fieldsV1Format,
+	"io.k8s.apimachinery.pkg.apis.meta.v1.FieldsV1": fieldsV1Format,
+}
+
+var customSchemas = map[string]spec.Schema{
+	quantityFormat: {
+		SchemaProps: spec.SchemaProps{
+			Description: "Quantity is a fixed-point representation of a number, e.g. 500m or 2Gi.",
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{"x-kubernetes-int-or-string": true}},
+	},
+	rawExtensionFormat: {
+		SchemaProps: spec.SchemaProps{
+			Description: "RawExtension holds an arbitrary JSON value.",
+		},
+		VendorExtensible: spec.VendorExtensible{Extensions: spec.Extensions{"x-kubernetes-preserve-unknown-fields": true}},
+	},
+	fieldsV1Format: {
+		SchemaProps: spec.SchemaProps{
+			Description: "FieldsV1 stores a set of fields in a data structure like a Trie, in JSON format.",
+			Type:        spec.StringOrArray{"object"},
+		},
+	},
+}
+
+func schemaFormat(typeName string) string {
+	return customTypeFormats[typeName]
+}
+
+// replaceCustomSchemas replaces the schemas marked by customTypeFormats in the properties, items and map values of s.
+func replaceCustomSchemas(s *spec.Schema) {
+	replace := func(schema *spec.Schema) {
+		if custom, ok := customSchemas[schema.Format]; ok {
+			description := schema.Description
+			*schema = custom
+			if description != "" {
+				schema.Description = description
+			}
+			return
+		}
+		replaceCustomSchemas(schema)
+	}
+	for name, prop := range s.Properties {
+		replace(&prop)
+		s.Properties[name] = prop
+	}
+	if s.Items != nil && s.Items.Schema != nil {
+		replace(s.Items.Schema)
+	}
+	if s.AdditionalProperties != nil && s.AdditionalProperties.Schema != nil {
+		replace(s.AdditionalProperties.Schema)
+	}
+}
+
+func enrichSwaggerObject(swo *spec.Swagger) {
+	swo.Info = &spec.Info{
+		InfoProps: spec.InfoProps{
+			Title:       "KubeClipper",
+			Description: "KubeClipper OpenAPI",
+			Version:     version.Get().GitVersion,
+			Contact: &spec.ContactInfo{
+				// TODO: add url and email
+				ContactInfoProps: spec.ContactInfoProps{
+					Name:  "KubeClipper",
+					URL:   "github.com/kubeclipper-labs/kubeclipper",
+					Email: "",
+				},
+			},
+			License: &spec.License{
+				LicenseProps: spec.LicenseProps{
+					Name: "Apache 2.0",
+					URL:  "https://www.apache.org/licenses/LICENSE-2.0.html",
+				},
+			},
+		},
+	}
+
+	// setup security definitions
+	swo.SecurityDefinitions = map[string]*spec.SecurityScheme{
+		"jwt": spec.APIKeyAuth("Authorization", "header"),
+	}
+	swo.Security = []map[string][]string{{"jwt": []string{}}}
+
+	for name, schema := range swo.Definitions {
+		if format, ok := customTypeFormats[name]; ok {
+			swo.Definitions[name] = customSchemas[format]
+			continue
+		}
+		replaceCustomSchemas(&schema)
+		swo.Definitions[name] = schema
+	}
+	pruneDefinitions(swo)
+}
+
+// pruneDefinitions removes the definitions the routes do not refer to, like the ones of the go fields of the custom types.
+func pruneDefinitions(swo *spec.Swagger) {
+	used := make(map[string]bool)
+	var queue []string
+	collect := func(v interface{}) {
+		for _, name := range definitionRefs(v) {
+			if !used[name] {
+				used[name] = true
+				queue = append(queue, name)
+			}
+		}
+	}
+	collect(swo.Paths)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if schema, ok := swo.Definitions[name]; ok {
+			collect(schema)
+		}
+	}
+	for name := range swo.Definitions {
+		if !used[name] {
+			delete(swo.Definitions, name)
+		}
+	}
+}
+
+// definitionRefs returns the names of the definitions referred to by v.
+func definitionRefs(v interface{}) []string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var doc interface{}
+	if err = json.Unmarshal(data, &doc); err != nil {
+		return nil
+	}
+	var names []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			for k, e := range t {
+				if ref, ok := e.(string); ok && k == "$ref" && strings.HasPrefix(ref, definitionsPrefix) {
+					names = append(names, strings.TrimPrefix(ref, definitionsPrefix))
+					continue
+				}
+				walk(e)
+			}
+		case []interface{}:
+			for _, e := range t {
+				walk(e)
+			}
+		}
+	}
+	walk(doc)
+	return names
+}
@@ -0,0 +1,232 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	fuzz "github.com/google/gofuzz"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	configv1 "github.com/kubeclipper/kubeclipper/pkg/apis/config/v1"
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/apis/core/v1"
+	iamv1 "github.com/kubeclipper/kubeclipper/pkg/apis/iam/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/apis/oauth"
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func testContainer(t *testing.T, handler restful.RouteFunction) *restful.Container {
+	ws := new(restful.WebService)
+	ws.Path("/api/core.kubeclipper.io/v1").Consumes(restful.MIME_JSON).Produces(restful.MIME_JSON)
+	ws.Route(ws.POST("/regions/{name}/prewarm").To(handler).
+		Param(ws.PathParameter("name", "region name")).
+		Reads(v1.RegionPrewarm{}).
+		Returns(http.StatusOK, http.StatusText(http.StatusOK), v1.Operation{}))
+	c := restful.NewContainer()
+	c.Add(ws)
+	if err := Install(c); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	return c
+}
+
+func TestConvertToV3(t *testing.T) {
+	c := testContainer(t, func(req *restful.Request, resp *restful.Response) {})
+	doc, err := ConvertToV3(BuildSwagger(c.RegisteredWebServices()))
+	if err != nil {
+		t.Fatalf("ConvertToV3() error = %v", err)
+	}
+	op := doc.Paths["/api/core.kubeclipper.io/v1/regions/{name}/prewarm"]["post"]
+	if op == nil {
+		t.Fatalf("missing the prewarm operation in %v", doc.Paths)
+	}
+	if len(op.Parameters) != 1 || op.Parameters[0].In != "path" || op.Parameters[0].Schema.Type[0] != "string" {
+		t.Errorf("unexpected parameters %+v", op.Parameters)
+	}
+	body := op.RequestBody.Content[restful.MIME_JSON].Schema
+	if got := body.Ref.String(); got != componentsSchemaPrefix+"core.v1.RegionPrewarm" {
+		t.Errorf("request body refers to %q", got)
+	}
+	if got := op.Responses["200"].Content[restful.MIME_JSON].Schema.Ref.String(); got != componentsSchemaPrefix+"core.v1.Operation" {
+		t.Errorf("response refers to %q", got)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal document: %v", err)
+	}
+	if strings.Contains(string(data), definitionsPrefix) {
+		t.Error("the document still refers to swagger definitions")
+	}
+	if _, ok := doc.Components.Schemas["io.k8s.apimachinery.pkg.apis.meta.v1.ObjectMeta"]; !ok {
+		t.Error("expected the object meta schema to be named after its package")
+	}
+}
+
+func TestValidatorFilter(t *testing.T) {
+	c := testContainer(t, func(req *restful.Request, resp *restful.Response) {
+		var prewarm v1.RegionPrewarm
+		if err := req.ReadEntity(&prewarm); err != nil {
+			_ = resp.WriteHeaderAndEntity(http.StatusBadRequest, errors.HTTPError{Code: http.StatusBadRequest, Reason: err.Error()})
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+	})
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantFields []errors.FieldError
+	}{
+		{
+			name:       "valid",
+			body:       `{"kubernetesVersion":"v1.23.6","offline":true,"nodes":["n1"]}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"kubernetesVersion":"v1.23.6","containerRuntime":{"typo":"docker"}}`,
+			wantStatus: http.StatusBadRequest,
+			wantFields: []errors.FieldError{{Field: "containerRuntime.typo", Message: "unknown field"}},
+		},
+		{
+			name:       "type errors",
+			body:       `{"nodes":["n1", 1],"offline":"true"}`,
+			wantStatus: http.StatusBadRequest,
+			wantFields: []errors.FieldError{
+				{Field: "nodes[1]", Message: "expected string, got number"},
+				{Field: "offline", Message: "expected boolean, got string"},
+			},
+		},
+		{
+			name:       "malformed json is left to the handler",
+			body:       `{"nodes":`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/core.kubeclipper.io/v1/regions/r1/prewarm", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", restful.MIME_JSON)
+			rec := httptest.NewRecorder()
+			c.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if rec.Code == http.StatusOK {
+				return
+			}
+			var httpErr errors.HTTPError
+			if err := json.Unmarshal(rec.Body.Bytes(), &httpErr); err != nil {
+				t.Fatalf("decode error: %v", err)
+			}
+			if !reflect.DeepEqual(httpErr.Fields, tt.wantFields) {
+				t.Errorf("fields = %v, want %v", httpErr.Fields, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestServeV3(t *testing.T) {
+	c := testContainer(t, func(req *restful.Request, resp *restful.Response) {})
+	rec := httptest.NewRecorder()
+	c.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, V3Path, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	doc := &Document{}
+	if err := json.Unmarshal(rec.Body.Bytes(), doc); err != nil {
+		t.Fatalf("decode document: %v", err)
+	}
+	if doc.OpenAPI != openAPIVersion || len(doc.Paths) != 1 {
+		t.Errorf("unexpected document %s %v", doc.OpenAPI, doc.Paths)
+	}
+}
+
+// TestValidatorAcceptsRouteBodies encodes populated values of the types read by the routes of the
+// API, the validator must accept them.
+func TestValidatorAcceptsRouteBodies(t *testing.T) {
+	c := restful.NewContainer()
+	if err := corev1.AddToContainer(c, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := iamv1.AddToContainer(c, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := configv1.AddToContainer(c, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := oauth.AddToContainer(c, nil, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	v := NewValidator(BuildSwagger(c.RegisteredWebServices()))
+	f := fuzz.New().NilChance(0).NumElements(1, 2).Funcs(
+		func(q *resource.Quantity, c fuzz.Continue) {
+			*q = resource.MustParse("2Gi")
+		},
+		func(t *metav1.Time, c fuzz.Continue) {
+			*t = metav1.Unix(c.Int63n(1<<31), 0)
+		},
+		func(e *runtime.RawExtension, c fuzz.Continue) {
+			e.Raw = []byte(`{}`)
+		},
+		func(m *metav1.ObjectMeta, c fuzz.Continue) {
+			c.FuzzNoCustom(m)
+			m.ManagedFields = nil
+		},
+		func(i *interface{}, c fuzz.Continue) {
+			*i = c.RandString()
+		},
+	)
+	checked := 0
+	for _, ws := range c.RegisteredWebServices() {
+		for _, route := range ws.Routes() {
+			if route.ReadSample == nil || (route.Method != http.MethodPost && route.Method != http.MethodPut) {
+				continue
+			}
+			schema, ok := v.bodies[routeKey(route.Method, route.Path)]
+			if !ok {
+				t.Errorf("no body schema for %s %s", route.Method, route.Path)
+				continue
+			}
+			obj := reflect.New(reflect.TypeOf(route.ReadSample))
+			f.Fuzz(obj.Interface())
+			data, err := json.Marshal(obj.Interface())
+			if err != nil {
+				t.Errorf("%s %s: marshal %T: %v", route.Method, route.Path, route.ReadSample, err)
+				continue
+			}
+			if fields := v.Validate(schema, data); len(fields) > 0 {
+				t.Errorf("%s %s: %T rejected: %v", route.Method, route.Path, route.ReadSample, fields)
+			}
+			checked++
+		}
+	}
+	if checked == 0 {
+		t.Error("no route body checked")
+	}
+}
@@ -0,0 +1,276 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/spec"
+)
+
+const (
+	openAPIVersion         = "3.0.3"
+	componentsSchemaPrefix = "#/components/schemas/"
+	defaultMediaType       = "application/json"
+)
+
+// Document is an OpenAPI v3 document, only the parts of the spec generated from the routes are modeled.
+type Document struct {
+	OpenAPI    string                `json:"openapi"`
+	Info       *spec.Info            `json:"info,omitempty"`
+	Paths      map[string]PathItem   `json:"paths"`
+	Components Components            `json:"components"`
+	Security   []map[string][]string `json:"security,omitempty"`
+	Tags       []spec.Tag            `json:"tags,omitempty"`
+}
+
+// PathItem maps the lower case http methods of a path to their operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string       `json:"name"`
+	In          string       `json:"in"`
+	Description string       `json:"description,omitempty"`
+	Required    bool         `json:"required,omitempty"`
+	Schema      *spec.Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Description string               `json:"description,omitempty"`
+	Required    bool                 `json:"required,omitempty"`
+	Content     map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *spec.Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*spec.Schema    `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`
+	In          string `json:"in,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
+}
+
+// ConvertToV3 converts the swagger 2.0 spec built from the routes to an OpenAPI v3 document:
+// the definitions become component schemas, the body and form parameters become request bodies
+// and the schemas of the responses are keyed by the media types the operations produce.
+func ConvertToV3(swagger *spec.Swagger) (*Document, error) {
+	doc := &Document{
+		OpenAPI:  openAPIVersion,
+		Info:     swagger.Info,
+		Paths:    make(map[string]PathItem),
+		Security: swagger.Security,
+		Tags:     swagger.Tags,
+		Components: Components{
+			Schemas:         make(map[string]*spec.Schema, len(swagger.Definitions)),
+			SecuritySchemes: make(map[string]*SecurityScheme, len(swagger.SecurityDefinitions)),
+		},
+	}
+	for name := range swagger.Definitions {
+		schema := swagger.Definitions[name]
+		converted, err := convertSchema(&schema)
+		if err != nil {
+			return nil, err
+		}
+		doc.Components.Schemas[name] = converted
+	}
+	for name, s := range swagger.SecurityDefinitions {
+		doc.Components.SecuritySchemes[name] = convertSecurityScheme(s)
+	}
+	if swagger.Paths == nil {
+		return doc, nil
+	}
+	for path, item := range swagger.Paths.Paths {
+		converted := make(PathItem)
+		for method, op := range map[string]*spec.Operation{
+			http.MethodGet: item.Get, http.MethodPut: item.Put, http.MethodPost: item.Post, http.MethodDelete: item.Delete,
+			http.MethodOptions: item.Options, http.MethodHead: item.Head, http.MethodPatch: item.Patch,
+		} {
+			if op == nil {
+				continue
+			}
+			o, err := convertOperation(swagger, op)
+			if err != nil {
+				return nil, err
+			}
+			converted[strings.ToLower(method)] = o
+		}
+		doc.Paths[path] = converted
+	}
+	return doc, nil
+}
+
+func convertOperation(swagger *spec.Swagger, op *spec.Operation) (*Operation, error) {
+	out := &Operation{
+		Tags:        op.Tags,
+		Summary:     op.Summary,
+		Description: op.Description,
+		OperationID: op.ID,
+		Deprecated:  op.Deprecated,
+		Security:    op.Security,
+		Responses:   make(map[string]*Response),
+	}
+	consumes := mediaTypes(op.Consumes, swagger.Consumes)
+	var form *spec.Schema
+	for i := range op.Parameters {
+		p := &op.Parameters[i]
+		switch p.In {
+		case "body":
+			schema, err := convertSchema(p.Schema)
+			if err != nil {
+				return nil, err
+			}
+			out.RequestBody = &RequestBody{Description: p.Description, Required: p.Required, Content: content(consumes, schema)}
+		case "formData":
+			if form == nil {
+				form = &spec.Schema{SchemaProps: spec.SchemaProps{Type: spec.StringOrArray{"object"}, Properties: map[string]spec.Schema{}}}
+			}
+			form.Properties[p.Name] = *simpleSchema(&p.SimpleSchema, p.Enum, p.Description)
+			if p.Required {
+				form.Required = append(form.Required, p.Name)
+			}
+		default:
+			out.Parameters = append(out.Parameters, &Parameter{
+				Name:        p.Name,
+				In:          p.In,
+				Description: p.Description,
+				Required:    p.Required,
+				Schema:      simpleSchema(&p.SimpleSchema, p.Enum, ""),
+			})
+		}
+	}
+	if form != nil {
+		formTypes := []string{"application/x-www-form-urlencoded"}
+		if len(op.Consumes) > 0 {
+			formTypes = op.Consumes
+		}
+		out.RequestBody = &RequestBody{Required: len(form.Required) > 0, Content: content(formTypes, form)}
+	}
+	if op.Responses == nil {
+		return out, nil
+	}
+	produces := mediaTypes(op.Produces, swagger.Produces)
+	responses := make(map[string]spec.Response, len(op.Responses.StatusCodeResponses)+1)
+	for code, r := range op.Responses.StatusCodeResponses {
+		responses[strconv.Itoa(code)] = r
+	}
+	if op.Responses.Default != nil {
+		responses["default"] = *op.Responses.Default
+	}
+	for code, r := range responses {
+		resp := &Response{Description: r.Description}
+		if r.Schema != nil {
+			schema, err := convertSchema(r.Schema)
+			if err != nil {
+				return nil, err
+			}
+			resp.Content = content(produces, schema)
+		}
+		out.Responses[code] = resp
+	}
+	return out, nil
+}
+
+// convertSchema copies the schema with its references pointing to the component schemas.
+func convertSchema(s *spec.Schema) (*spec.Schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.ReplaceAll(data, []byte(`"`+definitionsPrefix), []byte(`"`+componentsSchemaPrefix))
+	out := &spec.Schema{}
+	if err = json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// simpleSchema converts the type of a path, query, header or form parameter to a schema.
+func simpleSchema(s *spec.SimpleSchema, enum []interface{}, description string) *spec.Schema {
+	schema := &spec.Schema{SchemaProps: spec.SchemaProps{
+		Description: description,
+		Format:      s.Format,
+		Enum:        enum,
+		Default:     s.Default,
+	}}
+	if s.Type != "" {
+		schema.Type = spec.StringOrArray{s.Type}
+	}
+	if s.Items != nil {
+		schema.Items = &spec.SchemaOrArray{Schema: simpleSchema(&s.Items.SimpleSchema, s.Items.Enum, "")}
+	}
+	return schema
+}
+
+func convertSecurityScheme(s *spec.SecurityScheme) *SecurityScheme {
+	out := &SecurityScheme{Type: s.Type, Description: s.Description, Name: s.Name, In: s.In}
+	if s.Type == "basic" {
+		out.Type, out.Scheme = "http", "basic"
+	}
+	return out
+}
+
+func mediaTypes(types, defaults []string) []string {
+	if len(types) > 0 {
+		return types
+	}
+	if len(defaults) > 0 {
+		return defaults
+	}
+	return []string{defaultMediaType}
+}
+
+func content(types []string, schema *spec.Schema) map[string]MediaType {
+	c := make(map[string]MediaType, len(types))
+	for _, t := range types {
+		c[t] = MediaType{Schema: schema}
+	}
+	return c
+}
@@ -0,0 +1,233 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/go-restful"
+	"github.com/go-openapi/spec"
+
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/server/restplus"
+)
+
+// maxFieldErrors bounds the invalid fields reported for a request body.
+const maxFieldErrors = 20
+
+// Validator checks the JSON bodies of the requests against the schemas of the routes reading them,
+// it reports the unknown fields and the values of the wrong type, the handlers validate the values.
+type Validator struct {
+	definitions spec.Definitions
+	// bodies maps the method and path of the routes to the schemas of their bodies.
+	bodies map[string]*spec.Schema
+}
+
+func NewValidator(swagger *spec.Swagger) *Validator {
+	v := &Validator{definitions: swagger.Definitions, bodies: make(map[string]*spec.Schema)}
+	if swagger.Paths == nil {
+		return v
+	}
+	for path, item := range swagger.Paths.Paths {
+		for method, op := range map[string]*spec.Operation{http.MethodPost: item.Post, http.MethodPut: item.Put} {
+			if op == nil {
+				continue
+			}
+			for i := range op.Parameters {
+				if p := op.Parameters[i]; p.In == "body" && p.Schema != nil {
+					v.bodies[routeKey(method, path)] = p.Schema
+				}
+			}
+		}
+	}
+	return v
+}
+
+// Filter rejects the create and update requests whose JSON body does not match the schema of the route.
+// Bodies which are not valid JSON are left to the handlers.
+func (v *Validator) Filter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
+	schema, ok := v.bodies[routeKey(req.Request.Method, req.SelectedRoutePath())]
+	if !ok || req.Request.Body == nil || !isJSON(req.Request.Header.Get("Content-Type")) {
+		chain.ProcessFilter(req, resp)
+		return
+	}
+	body, err := io.ReadAll(req.Request.Body)
+	if err != nil {
+		restplus.HandleBadRequest(resp, req, err)
+		return
+	}
+	req.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if fields := v.Validate(schema, body); len(fields) > 0 {
+		restplus.HandleInvalidFields(resp, req, fields)
+		return
+	}
+	chain.ProcessFilter(req, resp)
+}
+
+// Validate returns the invalid fields of the JSON document, it returns nothing when the document is not valid JSON.
+func (v *Validator) Validate(schema *spec.Schema, document []byte) []errors.FieldError {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	var fields []errors.FieldError
+	v.validate("", value, schema, &fields)
+	return fields
+}
+
+func (v *Validator) validate(path string, value interface{}, schema *spec.Schema, fields *[]errors.FieldError) {
+	if len(*fields) >= maxFieldErrors || value == nil {
+		return
+	}
+	schema = v.resolve(schema)
+	if schema == nil {
+		return
+	}
+	typ := ""
+	if len(schema.Type) > 0 {
+		typ = schema.Type[0]
+	} else if len(schema.Properties) > 0 {
+		typ = "object"
+	}
+	switch typ {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			addFieldError(fields, path, "expected object, got %s", jsonType(value))
+			return
+		}
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			field := joinField(path, k)
+			if prop, ok := schema.Properties[k]; ok {
+				v.validate(field, obj[k], &prop, fields)
+				continue
+			}
+			if schema.AdditionalProperties != nil {
+				if schema.AdditionalProperties.Schema != nil {
+					v.validate(field, obj[k], schema.AdditionalProperties.Schema, fields)
+				}
+				continue
+			}
+			// objects without properties are free form
+			if len(schema.Properties) > 0 {
+				addFieldError(fields, field, "unknown field")
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			addFieldError(fields, path, "expected array, got %s", jsonType(value))
+			return
+		}
+		if schema.Items == nil || schema.Items.Schema == nil {
+			return
+		}
+		for i, item := range items {
+			v.validate(fmt.Sprintf("%s[%d]", path, i), item, schema.Items.Schema, fields)
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			addFieldError(fields, path, "expected string, got %s", jsonType(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			addFieldError(fields, path, "expected boolean, got %s", jsonType(value))
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			addFieldError(fields, path, "expected integer, got %s", jsonType(value))
+			return
+		}
+		if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+			addFieldError(fields, path, "expected integer, got %s", n)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			addFieldError(fields, path, "expected number, got %s", jsonType(value))
+		}
+	}
+}
+
+// resolve follows the reference of the schema to its definition.
+func (v *Validator) resolve(schema *spec.Schema) *spec.Schema {
+	ref := schema.Ref.String()
+	if ref == "" {
+		return schema
+	}
+	def, ok := v.definitions[strings.TrimPrefix(ref, definitionsPrefix)]
+	if !ok {
+		return nil
+	}
+	return &def
+}
+
+func addFieldError(fields *[]errors.FieldError, field string, format string, args ...interface{}) {
+	*fields = append(*fields, errors.FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+func joinField(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	}
+	return "null"
+}
+
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == restful.MIME_JSON
+}
+
+func routeKey(method, path string) string {
+	return method + " " + path
+}
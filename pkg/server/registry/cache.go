@@ -0,0 +1,183 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package registry
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+// deleteStaleness is how long reads keep going to etcd after a delete, since
+// the deleted revision is not known and the watch cache may still hold the object.
+const deleteStaleness = time.Second
+
+var _ rest.StandardStorage = (*cachedReadStorage)(nil)
+
+// cachedReadStorage serves API get and list requests that do not ask for a
+// specific resourceVersion from the watch cache instead of etcd. The watch
+// cache is kept up to date by an etcd watch, so no explicit invalidation is
+// needed; to keep read-your-writes for clients of this server, reads are made
+// at least as new as the last write done through it.
+//
+// Only requests carrying API request info are served from the cache, internal
+// callers doing read-modify-write keep reading from etcd.
+type cachedReadStorage struct {
+	rest.StandardStorage
+
+	mu          sync.Mutex
+	lastWriteRV uint64
+	bypassUntil time.Time
+	nowFunc     func() time.Time
+}
+
+func newCachedReadStorage(storage rest.StandardStorage) *cachedReadStorage {
+	return &cachedReadStorage{
+		StandardStorage: storage,
+		nowFunc:         time.Now,
+	}
+}
+
+func objectResourceVersion(obj runtime.Object) uint64 {
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return 0
+	}
+	rv, err := strconv.ParseUint(accessor.GetResourceVersion(), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return rv
+}
+
+// cachedResourceVersion returns the resourceVersion to read from the watch
+// cache with, or false if the read must go to etcd.
+func (s *cachedReadStorage) cachedResourceVersion(ctx context.Context, verb, rv string) (string, bool) {
+	if rv != "" {
+		return "", false
+	}
+	info, ok := request.InfoFrom(ctx)
+	if !ok || !info.IsResourceRequest || info.Verb != verb {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nowFunc().Before(s.bypassUntil) {
+		return "", false
+	}
+	if s.lastWriteRV == 0 {
+		return "0", true
+	}
+	return strconv.FormatUint(s.lastWriteRV, 10), true
+}
+
+func (s *cachedReadStorage) observeWrite(obj runtime.Object) {
+	if obj == nil {
+		return
+	}
+	rv := objectResourceVersion(obj)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if rv > s.lastWriteRV {
+		s.lastWriteRV = rv
+	}
+}
+
+func (s *cachedReadStorage) observeDelete() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bypassUntil = s.nowFunc().Add(deleteStaleness)
+}
+
+func (s *cachedReadStorage) Get(ctx context.Context, name string, options *metav1.GetOptions) (runtime.Object, error) {
+	if options == nil {
+		options = &metav1.GetOptions{}
+	}
+	if rv, ok := s.cachedResourceVersion(ctx, "get", options.ResourceVersion); ok {
+		opts := *options
+		opts.ResourceVersion = rv
+		options = &opts
+	}
+	return s.StandardStorage.Get(ctx, name, options)
+}
+
+func (s *cachedReadStorage) List(ctx context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	if options == nil {
+		options = &metainternalversion.ListOptions{}
+	}
+	// the watch cache ignores limit, paged lists keep going to etcd
+	if options.Limit == 0 && options.Continue == "" && options.ResourceVersionMatch == "" {
+		if rv, ok := s.cachedResourceVersion(ctx, "list", options.ResourceVersion); ok {
+			opts := *options
+			opts.ResourceVersion = rv
+			options = &opts
+		}
+	}
+	return s.StandardStorage.List(ctx, options)
+}
+
+func (s *cachedReadStorage) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc,
+	options *metav1.CreateOptions) (runtime.Object, error) {
+	out, err := s.StandardStorage.Create(ctx, obj, createValidation, options)
+	if err == nil {
+		s.observeWrite(out)
+	}
+	return out, err
+}
+
+func (s *cachedReadStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc,
+	updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	out, created, err := s.StandardStorage.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+	if err == nil {
+		s.observeWrite(out)
+	}
+	return out, created, err
+}
+
+func (s *cachedReadStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc,
+	options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	out, deleted, err := s.StandardStorage.Delete(ctx, name, deleteValidation, options)
+	if err == nil {
+		// a graceful delete is an update and returns the new revision
+		if !deleted {
+			s.observeWrite(out)
+		} else {
+			s.observeDelete()
+		}
+	}
+	return out, deleted, err
+}
+
+func (s *cachedReadStorage) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc,
+	options *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
+	out, err := s.StandardStorage.DeleteCollection(ctx, deleteValidation, options, listOptions)
+	if err == nil {
+		s.observeDelete()
+	}
+	return out, err
+}
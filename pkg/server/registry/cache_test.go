@@ -0,0 +1,123 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	metainternalversion "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+type fakeStorage struct {
+	rest.StandardStorage
+	getRV         string
+	listRV        string
+	out           runtime.Object
+	preconditions *metav1.Preconditions
+}
+
+func (f *fakeStorage) Get(_ context.Context, _ string, options *metav1.GetOptions) (runtime.Object, error) {
+	f.getRV = options.ResourceVersion
+	return f.out, nil
+}
+
+func (f *fakeStorage) List(_ context.Context, options *metainternalversion.ListOptions) (runtime.Object, error) {
+	f.listRV = options.ResourceVersion
+	return &v1.NodeList{}, nil
+}
+
+func (f *fakeStorage) Create(_ context.Context, _ runtime.Object, _ rest.ValidateObjectFunc, _ *metav1.CreateOptions) (runtime.Object, error) {
+	return f.out, nil
+}
+
+func (f *fakeStorage) Update(_ context.Context, _ string, objInfo rest.UpdatedObjectInfo, _ rest.ValidateObjectFunc, _ rest.ValidateObjectUpdateFunc, _ bool, _ *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	f.preconditions = objInfo.Preconditions()
+	return f.out, false, nil
+}
+
+func (f *fakeStorage) Delete(_ context.Context, _ string, _ rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	f.preconditions = nil
+	if options != nil {
+		f.preconditions = options.Preconditions
+	}
+	return &metav1.Status{}, true, nil
+}
+
+func apiContext(verb string) context.Context {
+	return request.WithInfo(context.Background(), &request.Info{IsResourceRequest: true, Verb: verb})
+}
+
+func TestCachedReadStorage(t *testing.T) {
+	fake := &fakeStorage{out: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "n1", ResourceVersion: "42"}}}
+	s := newCachedReadStorage(fake)
+	now := time.Unix(1000, 0)
+	s.nowFunc = func() time.Time { return now }
+
+	// internal callers always read etcd
+	_, _ = s.Get(context.Background(), "n1", &metav1.GetOptions{})
+	if fake.getRV != "" {
+		t.Errorf("internal get: resourceVersion = %q, want empty", fake.getRV)
+	}
+	// api reads without a resourceVersion use the watch cache
+	_, _ = s.Get(apiContext("get"), "n1", &metav1.GetOptions{})
+	if fake.getRV != "0" {
+		t.Errorf("api get: resourceVersion = %q, want 0", fake.getRV)
+	}
+	// an explicit resourceVersion is kept
+	_, _ = s.Get(apiContext("get"), "n1", &metav1.GetOptions{ResourceVersion: "7"})
+	if fake.getRV != "7" {
+		t.Errorf("explicit get: resourceVersion = %q, want 7", fake.getRV)
+	}
+	// paged lists keep going to etcd
+	_, _ = s.List(apiContext("list"), &metainternalversion.ListOptions{Limit: 10})
+	if fake.listRV != "" {
+		t.Errorf("paged list: resourceVersion = %q, want empty", fake.listRV)
+	}
+
+	// reads after a write are at least as new as the write
+	if _, err := s.Create(apiContext("create"), fake.out, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = s.List(apiContext("list"), &metainternalversion.ListOptions{})
+	if fake.listRV != "42" {
+		t.Errorf("list after write: resourceVersion = %q, want 42", fake.listRV)
+	}
+
+	// reads right after a delete bypass the cache
+	if _, _, err := s.Delete(apiContext("delete"), "n1", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = s.Get(apiContext("get"), "n1", &metav1.GetOptions{})
+	if fake.getRV != "" {
+		t.Errorf("get after delete: resourceVersion = %q, want empty", fake.getRV)
+	}
+	now = now.Add(deleteStaleness)
+	_, _ = s.Get(apiContext("get"), "n1", &metav1.GetOptions{})
+	if fake.getRV != "42" {
+		t.Errorf("get after staleness window: resourceVersion = %q, want 42", fake.getRV)
+	}
+}
@@ -43,7 +43,7 @@ func NewStorage(scheme *runtime.Scheme, optsGetter generic.RESTOptionsGetter) (r
 		ObjectNameFunc:           nil,
 		TTLFunc:                  nil,
 		PredicateFunc:            nil,
-		EnableGarbageCollection:  false,
+		EnableGarbageCollection:  true,
 		DeleteCollectionWorkers:  0,
 		Decorator:                nil,
 		CreateStrategy:           strategy,
@@ -65,8 +65,18 @@ func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
 	return c.ObjectMeta.Labels, SelectableFields(c), nil
 }
 
+// SelectableFields are the fields of a cluster the field selectors filter on, status.phase is
+// status.status in the naming of kubernetes.
 func SelectableFields(obj *v1.Cluster) fields.Set {
-	return generic.ObjectMetaFieldsSet(&obj.ObjectMeta, false)
+	set := fields.Set{
+		"type":          string(obj.ClusterType),
+		"status.status": string(obj.Status.Status),
+		"status.phase":  string(obj.Status.Status),
+	}
+	if obj.Kubeadm != nil {
+		set["kubeadm.kubernetesVersion"] = obj.Kubeadm.KubernetesVersion
+	}
+	return generic.AddObjectMetaFieldsSet(set, &obj.ObjectMeta, false)
 }
 
 func MatchCluster(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
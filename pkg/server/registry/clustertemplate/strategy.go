@@ -0,0 +1,107 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package clustertemplate
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/apiserver/pkg/storage/names"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+var (
+	_ rest.RESTCreateStrategy = ClusterTemplateStrategy{}
+	_ rest.RESTUpdateStrategy = ClusterTemplateStrategy{}
+	_ rest.RESTDeleteStrategy = ClusterTemplateStrategy{}
+)
+
+type ClusterTemplateStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+}
+
+func NewStrategy(typer runtime.ObjectTyper) ClusterTemplateStrategy {
+	return ClusterTemplateStrategy{typer, names.SimpleNameGenerator}
+}
+
+func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
+	c, ok := obj.(*v1.ClusterTemplate)
+	if !ok {
+		return nil, nil, fmt.Errorf("given object is not a ClusterTemplate")
+	}
+	return c.ObjectMeta.Labels, SelectableFields(c), nil
+}
+
+func SelectableFields(obj *v1.ClusterTemplate) fields.Set {
+	return generic.ObjectMetaFieldsSet(&obj.ObjectMeta, false)
+}
+
+func MatchClusterTemplate(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+	return storage.SelectionPredicate{
+		Label:    label,
+		Field:    field,
+		GetAttrs: GetAttrs,
+	}
+}
+
+func (ClusterTemplateStrategy) NamespaceScoped() bool {
+	return false
+}
+
+func (ClusterTemplateStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+}
+
+func (ClusterTemplateStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+}
+
+func (ClusterTemplateStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return field.ErrorList{}
+}
+
+func (ClusterTemplateStrategy) AllowCreateOnUpdate() bool {
+	return false
+}
+
+func (ClusterTemplateStrategy) AllowUnconditionalUpdate() bool {
+	return false
+}
+
+func (ClusterTemplateStrategy) Canonicalize(obj runtime.Object) {
+}
+
+func (ClusterTemplateStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	return field.ErrorList{}
+}
+
+func (s ClusterTemplateStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	return nil
+}
+
+func (s ClusterTemplateStrategy) WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string {
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package cronmaintenance
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/apiserver/pkg/storage/names"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+var (
+	_ rest.RESTCreateStrategy = CronMaintenanceStrategy{}
+	_ rest.RESTUpdateStrategy = CronMaintenanceStrategy{}
+	_ rest.RESTDeleteStrategy = CronMaintenanceStrategy{}
+)
+
+type CronMaintenanceStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+}
+
+func NewStrategy(typer runtime.ObjectTyper) CronMaintenanceStrategy {
+	return CronMaintenanceStrategy{typer, names.SimpleNameGenerator}
+}
+
+func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
+	c, ok := obj.(*v1.CronMaintenance)
+	if !ok {
+		return nil, nil, fmt.Errorf("given object is not a CronMaintenance")
+	}
+	return c.ObjectMeta.Labels, SelectableFields(c), nil
+}
+
+func SelectableFields(obj *v1.CronMaintenance) fields.Set {
+	return generic.ObjectMetaFieldsSet(&obj.ObjectMeta, false)
+}
+
+func MatchCronMaintenance(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+	return storage.SelectionPredicate{
+		Label:    label,
+		Field:    field,
+		GetAttrs: GetAttrs,
+	}
+}
+
+func (CronMaintenanceStrategy) NamespaceScoped() bool {
+	return false
+}
+
+func (CronMaintenanceStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+}
+
+func (CronMaintenanceStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+}
+
+func (CronMaintenanceStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return validateCronMaintenance(obj.(*v1.CronMaintenance))
+}
+
+func (CronMaintenanceStrategy) AllowCreateOnUpdate() bool {
+	return false
+}
+
+func (CronMaintenanceStrategy) AllowUnconditionalUpdate() bool {
+	return false
+}
+
+func (CronMaintenanceStrategy) Canonicalize(obj runtime.Object) {
+}
+
+func (CronMaintenanceStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	return validateCronMaintenance(obj.(*v1.CronMaintenance))
+}
+
+func validateCronMaintenance(c *v1.CronMaintenance) field.ErrorList {
+	if err := c.Spec.Validate(); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec"), c.Spec, err.Error())}
+	}
+	return nil
+}
+
+func (s CronMaintenanceStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	return nil
+}
+
+func (s CronMaintenanceStrategy) WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string {
+	return nil
+}
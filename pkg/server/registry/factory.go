@@ -26,7 +26,9 @@ import (
 
 	coordinationv1 "k8s.io/api/coordination/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/registry/generic"
+	genericregistry "k8s.io/apiserver/pkg/registry/generic/registry"
 	"k8s.io/apiserver/pkg/registry/rest"
 
 	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
@@ -37,18 +39,26 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/backup"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/backuppoint"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/cluster"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/clustertemplate"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/cronmaintenance"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/event"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/globalrole"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/globalrolebinding"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/lease"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/loginrecord"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/node"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/notification"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/notifier"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/objectevent"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/operation"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/platformsetting"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/precheck"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/project"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/recovery"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/region"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/token"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry/user"
+	"github.com/kubeclipper/kubeclipper/pkg/server/registry/webhookconfiguration"
 )
 
 type NewStorageFunc func(scheme *runtime.Scheme, restOptionsGetter generic.RESTOptionsGetter) (rest.StandardStorage, error)
@@ -71,6 +81,14 @@ type SharedStorageFactory interface {
 	BackupPoints() rest.StandardStorage
 	DNSDomains() rest.StandardStorage
 	Template() rest.StandardStorage
+	ClusterTemplates() rest.StandardStorage
+	CronMaintenances() rest.StandardStorage
+	Prechecks() rest.StandardStorage
+	Notifiers() rest.StandardStorage
+	Notifications() rest.StandardStorage
+	ObjectEvents() rest.StandardStorage
+	Projects() rest.StandardStorage
+	WebhookConfigurations() rest.StandardStorage
 }
 
 var _ SharedStorageFactory = (*sharedStorageFactory)(nil)
@@ -79,12 +97,16 @@ type sharedStorageFactory struct {
 	lock              sync.Mutex
 	restOptionsGetter generic.RESTOptionsGetter
 	storages          map[reflect.Type]rest.StandardStorage
+	cachedReads       sets.String
 }
 
-func NewSharedStorageFactory(optsGetter generic.RESTOptionsGetter) SharedStorageFactory {
+// NewSharedStorageFactory returns a factory whose storages for cachedReads
+// resources (e.g. nodes, clusters) serve API reads from the watch cache.
+func NewSharedStorageFactory(optsGetter generic.RESTOptionsGetter, cachedReads ...string) SharedStorageFactory {
 	factory := &sharedStorageFactory{
 		restOptionsGetter: optsGetter,
 		storages:          make(map[reflect.Type]rest.StandardStorage),
+		cachedReads:       sets.NewString(cachedReads...),
 	}
 	return factory
 }
@@ -103,6 +125,12 @@ func (s *sharedStorageFactory) StorageFor(obj interface{}, storageFunc NewStorag
 	if err != nil {
 		panic(err)
 	}
+	if store, ok := storage.(*genericregistry.Store); ok {
+		if s.cachedReads.Has(store.DefaultQualifiedResource.Resource) {
+			storage = newCachedReadStorage(storage)
+		}
+		storage = newPreconditionStorage(storage, store.DefaultQualifiedResource.Resource)
+	}
 	s.storages[storageType] = storage
 	return storage
 }
@@ -174,3 +202,35 @@ func (s *sharedStorageFactory) DNSDomains() rest.StandardStorage {
 func (s *sharedStorageFactory) Template() rest.StandardStorage {
 	return s.StorageFor(&corev1.Template{}, template.NewStorage)
 }
+
+func (s *sharedStorageFactory) ClusterTemplates() rest.StandardStorage {
+	return s.StorageFor(&corev1.ClusterTemplate{}, clustertemplate.NewStorage)
+}
+
+func (s *sharedStorageFactory) CronMaintenances() rest.StandardStorage {
+	return s.StorageFor(&corev1.CronMaintenance{}, cronmaintenance.NewStorage)
+}
+
+func (s *sharedStorageFactory) Prechecks() rest.StandardStorage {
+	return s.StorageFor(&corev1.Precheck{}, precheck.NewStorage)
+}
+
+func (s *sharedStorageFactory) Notifiers() rest.StandardStorage {
+	return s.StorageFor(&corev1.Notifier{}, notifier.NewStorage)
+}
+
+func (s *sharedStorageFactory) Notifications() rest.StandardStorage {
+	return s.StorageFor(&corev1.Notification{}, notification.NewStorage)
+}
+
+func (s *sharedStorageFactory) ObjectEvents() rest.StandardStorage {
+	return s.StorageFor(&corev1.ObjectEvent{}, objectevent.NewStorage)
+}
+
+func (s *sharedStorageFactory) Projects() rest.StandardStorage {
+	return s.StorageFor(&iamv1.Project{}, project.NewStorage)
+}
+
+func (s *sharedStorageFactory) WebhookConfigurations() rest.StandardStorage {
+	return s.StorageFor(&corev1.WebhookConfiguration{}, webhookconfiguration.NewStorage)
+}
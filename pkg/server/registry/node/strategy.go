@@ -67,7 +67,11 @@ func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
 
 func SelectableFields(obj *v1.Node) fields.Set {
 	return generic.AddObjectMetaFieldsSet(fields.Set{
-		"ip": obj.Status.Ipv4DefaultIP,
+		"ip":                       obj.Status.Ipv4DefaultIP,
+		"status.ipv4DefaultIP":     obj.Status.Ipv4DefaultIP,
+		"status.nodeInfo.hostname": obj.Status.NodeInfo.Hostname,
+		"status.nodeInfo.arch":     obj.Status.NodeInfo.Arch,
+		"status.nodeInfo.osFamily": obj.Status.NodeInfo.OSFamily,
 	}, &obj.ObjectMeta, false)
 }
 
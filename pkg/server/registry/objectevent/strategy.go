@@ -0,0 +1,133 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package objectevent
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/storage"
+	"k8s.io/apiserver/pkg/storage/names"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+var (
+	_ rest.RESTCreateStrategy = ObjectEventStrategy{}
+	_ rest.RESTUpdateStrategy = ObjectEventStrategy{}
+	_ rest.RESTDeleteStrategy = ObjectEventStrategy{}
+)
+
+type ObjectEventStrategy struct {
+	runtime.ObjectTyper
+	names.NameGenerator
+}
+
+func NewStrategy(typer runtime.ObjectTyper) ObjectEventStrategy {
+	return ObjectEventStrategy{typer, names.SimpleNameGenerator}
+}
+
+func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
+	e, ok := obj.(*v1.ObjectEvent)
+	if !ok {
+		return nil, nil, fmt.Errorf("given object is not an ObjectEvent")
+	}
+	return e.ObjectMeta.Labels, SelectableFields(e), nil
+}
+
+// SelectableFields are the fields of an event the field selectors filter on, the events of
+// an object are selected by involvedObject.kind and involvedObject.name.
+func SelectableFields(obj *v1.ObjectEvent) fields.Set {
+	return generic.AddObjectMetaFieldsSet(fields.Set{
+		"involvedObject.kind": obj.InvolvedObject.Kind,
+		"involvedObject.name": obj.InvolvedObject.Name,
+		"involvedObject.uid":  string(obj.InvolvedObject.UID),
+		"type":                obj.Type,
+		"reason":              obj.Reason,
+		"source.component":    obj.Source.Component,
+	}, &obj.ObjectMeta, false)
+}
+
+func MatchObjectEvent(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
+	return storage.SelectionPredicate{
+		Label:    label,
+		Field:    field,
+		GetAttrs: GetAttrs,
+	}
+}
+
+func (ObjectEventStrategy) NamespaceScoped() bool {
+	return false
+}
+
+func (ObjectEventStrategy) PrepareForCreate(ctx context.Context, obj runtime.Object) {
+}
+
+func (ObjectEventStrategy) PrepareForUpdate(ctx context.Context, obj, old runtime.Object) {
+}
+
+func (ObjectEventStrategy) Validate(ctx context.Context, obj runtime.Object) field.ErrorList {
+	return validate(obj.(*v1.ObjectEvent))
+}
+
+func (ObjectEventStrategy) AllowCreateOnUpdate() bool {
+	return false
+}
+
+func (ObjectEventStrategy) AllowUnconditionalUpdate() bool {
+	return false
+}
+
+func (ObjectEventStrategy) Canonicalize(obj runtime.Object) {
+}
+
+func (ObjectEventStrategy) ValidateUpdate(ctx context.Context, obj, old runtime.Object) field.ErrorList {
+	return validate(obj.(*v1.ObjectEvent))
+}
+
+func (s ObjectEventStrategy) WarningsOnCreate(ctx context.Context, obj runtime.Object) []string {
+	return nil
+}
+
+func (s ObjectEventStrategy) WarningsOnUpdate(ctx context.Context, obj, old runtime.Object) []string {
+	return nil
+}
+
+func validate(e *v1.ObjectEvent) field.ErrorList {
+	var errs field.ErrorList
+	if e.InvolvedObject.Kind == "" {
+		errs = append(errs, field.Required(field.NewPath("involvedObject", "kind"), ""))
+	}
+	if e.InvolvedObject.Name == "" {
+		errs = append(errs, field.Required(field.NewPath("involvedObject", "name"), ""))
+	}
+	if e.Type != v1.ObjectEventNormal && e.Type != v1.ObjectEventWarning {
+		errs = append(errs, field.NotSupported(field.NewPath("type"), e.Type, []string{v1.ObjectEventNormal, v1.ObjectEventWarning}))
+	}
+	if e.Reason == "" {
+		errs = append(errs, field.Required(field.NewPath("reason"), ""))
+	}
+	return errs
+}
@@ -65,8 +65,13 @@ func GetAttrs(obj runtime.Object) (labels.Set, fields.Set, error) {
 	return c.ObjectMeta.Labels, SelectableFields(c), nil
 }
 
+// SelectableFields are the fields of an operation the field selectors filter on, status.phase is
+// status.status in the naming of kubernetes.
 func SelectableFields(obj *v1.Operation) fields.Set {
-	return generic.ObjectMetaFieldsSet(&obj.ObjectMeta, false)
+	return generic.AddObjectMetaFieldsSet(fields.Set{
+		"status.status": string(obj.Status.Status),
+		"status.phase":  string(obj.Status.Status),
+	}, &obj.ObjectMeta, false)
 }
 
 func MatchOperation(label labels.Selector, field fields.Selector) storage.SelectionPredicate {
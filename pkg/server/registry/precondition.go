@@ -0,0 +1,94 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package registry
+
+import (
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+var _ rest.StandardStorage = (*preconditionStorage)(nil)
+
+// preconditionStorage makes the updates and deletes of API requests carrying an If-Match
+// header conditional on the resource version of the header, they fail with a conflict when
+// the resource was changed since the client read it.
+//
+// The precondition only applies to the resource named by the request path, the other
+// resources written by the handler and the writes of subresources stay unconditional.
+type preconditionStorage struct {
+	rest.StandardStorage
+	resource string
+}
+
+func newPreconditionStorage(storage rest.StandardStorage, resource string) *preconditionStorage {
+	return &preconditionStorage{
+		StandardStorage: storage,
+		resource:        resource,
+	}
+}
+
+func (s *preconditionStorage) preconditions(ctx context.Context, name string) *metav1.Preconditions {
+	info, ok := request.InfoFrom(ctx)
+	if !ok || !info.IsResourceRequest || info.IfMatch == "" {
+		return nil
+	}
+	if info.Resource != s.resource || info.Name != name || info.Subresource != "" {
+		return nil
+	}
+	rv := info.IfMatch
+	return &metav1.Preconditions{ResourceVersion: &rv}
+}
+
+func (s *preconditionStorage) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc,
+	updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	if preconditions := s.preconditions(ctx, name); preconditions != nil {
+		objInfo = &preconditionObjectInfo{UpdatedObjectInfo: objInfo, preconditions: preconditions}
+	}
+	return s.StandardStorage.Update(ctx, name, objInfo, createValidation, updateValidation, forceAllowCreate, options)
+}
+
+func (s *preconditionStorage) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
+	if preconditions := s.preconditions(ctx, name); preconditions != nil {
+		if options == nil {
+			options = &metav1.DeleteOptions{}
+		} else {
+			options = options.DeepCopy()
+		}
+		if options.Preconditions == nil {
+			options.Preconditions = preconditions
+		}
+	}
+	return s.StandardStorage.Delete(ctx, name, deleteValidation, options)
+}
+
+// preconditionObjectInfo adds the preconditions to the update, the storage checks them
+// against the stored resource in the same transaction as the write.
+type preconditionObjectInfo struct {
+	rest.UpdatedObjectInfo
+	preconditions *metav1.Preconditions
+}
+
+func (i *preconditionObjectInfo) Preconditions() *metav1.Preconditions {
+	return i.preconditions
+}
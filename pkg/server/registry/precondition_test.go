@@ -0,0 +1,72 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apiserver/pkg/registry/rest"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+func TestPreconditionStorage(t *testing.T) {
+	fake := &fakeStorage{out: &v1.Cluster{}}
+	s := newPreconditionStorage(fake, "clusters")
+	objInfo := rest.DefaultUpdatedObjectInfo(&v1.Cluster{})
+	ifMatch := func(resource, name, subresource string) context.Context {
+		return request.WithInfo(context.Background(), &request.Info{
+			IsResourceRequest: true,
+			Resource:          resource,
+			Name:              name,
+			Subresource:       subresource,
+			IfMatch:           "42",
+		})
+	}
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want bool
+	}{
+		{name: "requested resource", ctx: ifMatch("clusters", "c1", ""), want: true},
+		{name: "internal write", ctx: context.Background(), want: false},
+		{name: "other resource", ctx: ifMatch("nodes", "c1", ""), want: false},
+		{name: "other name", ctx: ifMatch("clusters", "c2", ""), want: false},
+		{name: "subresource", ctx: ifMatch("clusters", "c1", "certsans"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := s.Update(tt.ctx, "c1", objInfo, nil, nil, false, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := fake.preconditions != nil && *fake.preconditions.ResourceVersion == "42"; got != tt.want {
+				t.Errorf("update preconditions = %v, want %v", fake.preconditions, tt.want)
+			}
+			if _, _, err := s.Delete(tt.ctx, "c1", nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			if got := fake.preconditions != nil && *fake.preconditions.ResourceVersion == "42"; got != tt.want {
+				t.Errorf("delete preconditions = %v, want %v", fake.preconditions, tt.want)
+			}
+		})
+	}
+}
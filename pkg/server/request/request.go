@@ -20,6 +20,7 @@ package request
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -53,6 +54,12 @@ type Info struct {
 	Subresource string
 	// Name is empty for some verbs, but if the request directly indicates a name (not in body content) then this field is filled in.
 	Name string
+	// Project is the project the request is scoped to, set by the project query parameter or by the name of a requested project.
+	// It is empty for requests across all projects.
+	Project string
+	// IfMatch is the resource version the resource must have to be written by the request, given
+	// by the If-Match header with the ETag of the resource. It is empty for unconditional writes.
+	IfMatch string
 	// Parts are the path parts for the request, always starting with /{resource}/{name}
 	//Parts []string
 }
@@ -71,6 +78,7 @@ eg: /api/core.kubeclipper.io/v1/nodes/node1
 NonResource paths
 /healthz
 /metrics
+/openapi/v3
 /oauth/login
 /oauth/token
 */
@@ -146,9 +154,34 @@ func (i *InfoFactory) NewRequestInfo(req *http.Request) (*Info, error) {
 			}
 		}
 	}
+
+	requestInfo.Project = req.URL.Query().Get(query.ParameterProject)
+	if requestInfo.Project == "" && requestInfo.Resource == "projects" {
+		requestInfo.Project = requestInfo.Name
+	}
+	requestInfo.IfMatch = ParseETag(req.Header.Get("If-Match"))
 	return &requestInfo, nil
 }
 
+// ETag returns the entity tag of a resource with the resource version, the resource
+// is written only if it is unchanged when the ETag is sent back by If-Match.
+func ETag(resourceVersion string) string {
+	if resourceVersion == "" {
+		return ""
+	}
+	return strconv.Quote(resourceVersion)
+}
+
+// ParseETag returns the resource version of an entity tag, weak tags are accepted since
+// resources have no other representation.
+func ParseETag(etag string) string {
+	etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+	if rv, err := strconv.Unquote(etag); err == nil {
+		return rv
+	}
+	return etag
+}
+
 // splitPath returns the segments for a URL path.
 func splitPath(path string) []string {
 	path = strings.Trim(path, "/")
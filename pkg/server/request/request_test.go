@@ -34,6 +34,13 @@ var (
 	req5, _ = http.NewRequest("GET", "/api/core.kubeclipper.io/v1/nodes?watch=true&fieldSelector=metadata.name=node1", nil)
 	req6, _ = http.NewRequest("GET", "/api/core.kubeclipper.io/v1/nodes/node1/terminal", nil)
 	req7, _ = http.NewRequest("DELETE", "/api/core.kubeclipper.io/v1/nodes/node1/plugins/plugin1", nil)
+	req8, _ = http.NewRequest("GET", "/api/core.kubeclipper.io/v1/clusters?project=team-a", nil)
+	req9, _ = http.NewRequest("PUT", "/api/iam.kubeclipper.io/v1/projects/team-a/members/user1", nil)
+	req10   = func() *http.Request {
+		req, _ := http.NewRequest("PUT", "/api/core.kubeclipper.io/v1/clusters/c1", nil)
+		req.Header.Set("If-Match", `"42"`)
+		return req
+	}()
 )
 
 func TestInfoFactory_NewRequestInfo(t *testing.T) {
@@ -150,6 +157,54 @@ func TestInfoFactory_NewRequestInfo(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "",
+			args: args{req: req8},
+			want: &Info{
+				IsResourceRequest: true,
+				Path:              req8.URL.Path,
+				Verb:              "list",
+				APIPrefix:         "api",
+				APIGroup:          "core.kubeclipper.io",
+				APIVersion:        "v1",
+				Resource:          "clusters",
+				Project:           "team-a",
+			},
+			wantErr: false,
+		},
+		{
+			name: "",
+			args: args{req: req9},
+			want: &Info{
+				IsResourceRequest: true,
+				Path:              req9.URL.Path,
+				Verb:              "update",
+				APIPrefix:         "api",
+				APIGroup:          "iam.kubeclipper.io",
+				APIVersion:        "v1",
+				Resource:          "projects",
+				Name:              "team-a",
+				Subresource:       "members",
+				Project:           "team-a",
+			},
+			wantErr: false,
+		},
+		{
+			name: "",
+			args: args{req: req10},
+			want: &Info{
+				IsResourceRequest: true,
+				Path:              req10.URL.Path,
+				Verb:              "update",
+				APIPrefix:         "api",
+				APIGroup:          "core.kubeclipper.io",
+				APIVersion:        "v1",
+				Resource:          "clusters",
+				Name:              "c1",
+				IfMatch:           "42",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
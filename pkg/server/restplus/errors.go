@@ -20,8 +20,10 @@ package restplus
 
 import (
 	"net/http"
+	"strings"
 
 	"go.uber.org/zap"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 
@@ -31,6 +33,17 @@ import (
 )
 
 func HandleInternalError(response *restful.Response, req *restful.Request, err error) {
+	// the storage rejects the writes denied by admission webhooks with a forbidden error
+	if apimachineryErrors.IsForbidden(err) {
+		HandleForbidden(response, req, err)
+		return
+	}
+	// the storage rejects the writes of stale resources, e.g. with an outdated If-Match, and
+	// the creates of existing names with a conflict
+	if apimachineryErrors.IsConflict(err) || apimachineryErrors.IsAlreadyExists(err) {
+		HandleConflict(response, req, err)
+		return
+	}
 	handle(http.StatusInternalServerError, response, req, http.StatusInternalServerError, "Internal server error", err)
 }
 
@@ -39,6 +52,20 @@ func HandleBadRequest(response *restful.Response, req *restful.Request, err erro
 	handle(http.StatusBadRequest, response, req, http.StatusBadRequest, "Bad request", err)
 }
 
+// HandleInvalidFields writes http.StatusBadRequest with the invalid fields of the request body.
+func HandleInvalidFields(response *restful.Response, req *restful.Request, fields []errors.FieldError) {
+	reasons := make([]string, 0, len(fields))
+	for _, f := range fields {
+		reasons = append(reasons, f.Error())
+	}
+	_ = response.WriteHeaderAndEntity(http.StatusBadRequest, errors.HTTPError{
+		Code:    http.StatusBadRequest,
+		Message: "Invalid request body",
+		Reason:  strings.Join(reasons, "; "),
+		Fields:  fields,
+	})
+}
+
 func HandleNotFound(response *restful.Response, req *restful.Request, err error) {
 	handle(http.StatusNotFound, response, req, http.StatusNotFound, "Object not found", err)
 }
@@ -55,6 +82,12 @@ func HandleTooManyRequests(response *restful.Response, req *restful.Request, err
 	handle(http.StatusTooManyRequests, response, req, http.StatusTooManyRequests, "Too many request", err)
 }
 
+// HandleGone writes http.StatusGone, watches answer it when the resource version they start from
+// is compacted, the client needs to list again and watch from the resource version of the list.
+func HandleGone(response *restful.Response, req *restful.Request, err error) {
+	handle(http.StatusGone, response, req, http.StatusGone, "Resource version expired", err)
+}
+
 func HandleConflict(response *restful.Response, req *restful.Request, err error) {
 	handle(http.StatusConflict, response, req, http.StatusConflict, "Request conflict", err)
 }
@@ -0,0 +1,38 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package restplus
+
+import (
+	"github.com/emicklei/go-restful"
+	"k8s.io/apimachinery/pkg/api/meta"
+
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+// SetETag sets the ETag header to the resource version of a resource entity, clients send it back
+// with If-Match to write the resource only if nobody changed it since. Other entities have no ETag.
+func SetETag(response *restful.Response, entity interface{}) {
+	accessor, err := meta.Accessor(entity)
+	if err != nil {
+		return
+	}
+	if etag := request.ETag(accessor.GetResourceVersion()); etag != "" {
+		response.Header().Set("ETag", etag)
+	}
+}
@@ -0,0 +1,120 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package restplus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+
+	"github.com/emicklei/go-restful"
+	jsonpatch "github.com/evanphx/json-patch"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+const (
+	// MIMEMergePatch is the content type of the JSON merge patches of RFC 7386.
+	MIMEMergePatch = "application/merge-patch+json"
+	// MIMEJSONPatch is the content type of the JSON patches of RFC 6902.
+	MIMEJSONPatch = "application/json-patch+json"
+)
+
+// PatchMIMETypes are the content types the PATCH routes of the resources consume.
+var PatchMIMETypes = []string{MIMEMergePatch, MIMEJSONPatch}
+
+// PatchHandler returns the handler of the PATCH route of a resource named by the name path
+// parameter. The patch is applied to the resource returned by get and the patched resource
+// is passed to update, the handler of the PUT route, so that patches follow the same rules as
+// updates. The update is conditional on the resource version the patch was applied to, a
+// concurrent change fails the patch with a conflict instead of being overwritten.
+func PatchHandler(get func(ctx context.Context, name string) (runtime.Object, error), update restful.RouteFunction) restful.RouteFunction {
+	return func(req *restful.Request, response *restful.Response) {
+		patch, err := io.ReadAll(req.Request.Body)
+		if err != nil {
+			HandleBadRequest(response, req, err)
+			return
+		}
+		ctx := req.Request.Context()
+		current, err := get(ctx, req.PathParameter("name"))
+		if err != nil {
+			if apimachineryErrors.IsNotFound(err) {
+				HandleNotFound(response, req, err)
+				return
+			}
+			HandleInternalError(response, req, err)
+			return
+		}
+		accessor, err := meta.Accessor(current)
+		if err != nil {
+			HandleInternalError(response, req, err)
+			return
+		}
+		resourceVersion := accessor.GetResourceVersion()
+		info, ok := request.InfoFrom(ctx)
+		if ok && info.IfMatch != "" && info.IfMatch != resourceVersion {
+			HandleConflict(response, req, fmt.Errorf("the resource version is %s, not %s", resourceVersion, info.IfMatch))
+			return
+		}
+		original, err := json.Marshal(current)
+		if err != nil {
+			HandleInternalError(response, req, err)
+			return
+		}
+		patched, err := ApplyPatch(req.Request.Header.Get(restful.HEADER_ContentType), original, patch)
+		if err != nil {
+			HandleBadRequest(response, req, err)
+			return
+		}
+		if ok {
+			conditional := *info
+			conditional.IfMatch = resourceVersion
+			req.Request = req.Request.WithContext(request.WithInfo(ctx, &conditional))
+		}
+		req.Request.Body = io.NopCloser(bytes.NewReader(patched))
+		req.Request.ContentLength = int64(len(patched))
+		req.Request.Header.Set(restful.HEADER_ContentType, restful.MIME_JSON)
+		update(req, response)
+	}
+}
+
+// ApplyPatch applies the patch of the content type to the json document.
+func ApplyPatch(contentType string, doc, patch []byte) ([]byte, error) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+	switch mediaType {
+	case MIMEMergePatch:
+		return jsonpatch.MergePatch(doc, patch)
+	case MIMEJSONPatch:
+		p, err := jsonpatch.DecodePatch(patch)
+		if err != nil {
+			return nil, err
+		}
+		return p.Apply(doc)
+	}
+	return nil, fmt.Errorf("unsupported patch type %s, use %s or %s", mediaType, MIMEMergePatch, MIMEJSONPatch)
+}
@@ -0,0 +1,143 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package restplus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/emicklei/go-restful"
+	apimachineryErrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
+)
+
+func TestPatchHandler(t *testing.T) {
+	current := &v1.Cluster{ObjectMeta: metav1.ObjectMeta{
+		Name:            "c1",
+		ResourceVersion: "42",
+		Labels:          map[string]string{"env": "dev", "team": "a"},
+	}}
+	get := func(ctx context.Context, name string) (runtime.Object, error) {
+		if name != current.Name {
+			return nil, apimachineryErrors.NewNotFound(v1.Resource("clusters"), name)
+		}
+		return current.DeepCopy(), nil
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		ifMatch     string
+		patch       string
+		wantCode    int
+		wantLabels  map[string]string
+	}{
+		{
+			name:        "merge patch",
+			path:        "c1",
+			contentType: MIMEMergePatch,
+			patch:       `{"metadata":{"labels":{"env":"prod","team":null}}}`,
+			wantCode:    http.StatusOK,
+			wantLabels:  map[string]string{"env": "prod"},
+		},
+		{
+			name:        "json patch",
+			path:        "c1",
+			contentType: MIMEJSONPatch,
+			ifMatch:     `"42"`,
+			patch:       `[{"op":"add","path":"/metadata/labels/tier","value":"web"}]`,
+			wantCode:    http.StatusOK,
+			wantLabels:  map[string]string{"env": "dev", "team": "a", "tier": "web"},
+		},
+		{
+			name:        "stale if-match",
+			path:        "c1",
+			contentType: MIMEMergePatch,
+			ifMatch:     `"41"`,
+			patch:       `{}`,
+			wantCode:    http.StatusConflict,
+		},
+		{
+			name:        "invalid patch",
+			path:        "c1",
+			contentType: MIMEJSONPatch,
+			patch:       `{"op":"add"}`,
+			wantCode:    http.StatusBadRequest,
+		},
+		{
+			name:        "missing resource",
+			path:        "c2",
+			contentType: MIMEMergePatch,
+			patch:       `{}`,
+			wantCode:    http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var updated v1.Cluster
+			var precondition string
+			update := func(req *restful.Request, resp *restful.Response) {
+				if err := req.ReadEntity(&updated); err != nil {
+					t.Fatalf("read patched cluster: %v", err)
+				}
+				if info, ok := request.InfoFrom(req.Request.Context()); ok {
+					precondition = info.IfMatch
+				}
+				resp.WriteHeader(http.StatusOK)
+			}
+			ws := new(restful.WebService)
+			ws.Route(ws.PATCH("/clusters/{name}").To(PatchHandler(get, update)).Consumes(PatchMIMETypes...).Produces(restful.MIME_JSON))
+			c := restful.NewContainer()
+			c.Add(ws)
+
+			req := httptest.NewRequest(http.MethodPatch, "/clusters/"+tt.path, strings.NewReader(tt.patch))
+			req.Header.Set("Content-Type", tt.contentType)
+			info := &request.Info{IfMatch: request.ParseETag(tt.ifMatch)}
+			req = req.WithContext(request.WithInfo(req.Context(), info))
+			rec := httptest.NewRecorder()
+			c.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("code = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			if len(updated.Labels) != len(tt.wantLabels) {
+				t.Errorf("labels = %v, want %v", updated.Labels, tt.wantLabels)
+			}
+			for k, v := range tt.wantLabels {
+				if updated.Labels[k] != v {
+					t.Errorf("labels = %v, want %v", updated.Labels, tt.wantLabels)
+				}
+			}
+			if precondition != "42" {
+				t.Errorf("update precondition = %q, want the patched resource version", precondition)
+			}
+		})
+	}
+}
@@ -26,10 +26,12 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/kubeclipper/kubeclipper/pkg/admission"
 	corev1 "github.com/kubeclipper/kubeclipper/pkg/apis/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/authentication/mfa"
 	"github.com/kubeclipper/kubeclipper/pkg/controller/tokencontroller"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/cache"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/etcd"
 
 	"github.com/google/uuid"
 
@@ -41,7 +43,9 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/controller/clustercontroller"
 	"github.com/kubeclipper/kubeclipper/pkg/controller/dnscontroller"
 	"github.com/kubeclipper/kubeclipper/pkg/controller/nodecontroller"
+	"github.com/kubeclipper/kubeclipper/pkg/controller/notificationcontroller"
 	"github.com/kubeclipper/kubeclipper/pkg/controller/operationcontroller"
+	"github.com/kubeclipper/kubeclipper/pkg/controller/workloadinventory"
 
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
 
@@ -50,7 +54,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apiserver/pkg/apis/audit"
 	unionauth "k8s.io/apiserver/pkg/authentication/request/union"
-	etcdRESTOptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/apiserver/pkg/registry/generic"
+	"k8s.io/apiserver/pkg/registry/rest"
 	"k8s.io/component-base/version"
 
 	"github.com/emicklei/go-restful"
@@ -70,20 +75,27 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/authorization/rbac"
 	"github.com/kubeclipper/kubeclipper/pkg/client/informers"
 	"github.com/kubeclipper/kubeclipper/pkg/healthz"
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
 	"github.com/kubeclipper/kubeclipper/pkg/models/iam"
 	"github.com/kubeclipper/kubeclipper/pkg/models/lease"
 	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
 	"github.com/kubeclipper/kubeclipper/pkg/models/platform"
+	"github.com/kubeclipper/kubeclipper/pkg/nodemetrics"
+	"github.com/kubeclipper/kubeclipper/pkg/nodeprovider"
 	"github.com/kubeclipper/kubeclipper/pkg/query"
+	"github.com/kubeclipper/kubeclipper/pkg/quota"
+	"github.com/kubeclipper/kubeclipper/pkg/record"
 	"github.com/kubeclipper/kubeclipper/pkg/server/config"
 	"github.com/kubeclipper/kubeclipper/pkg/server/filters"
+	"github.com/kubeclipper/kubeclipper/pkg/server/openapi"
 	"github.com/kubeclipper/kubeclipper/pkg/server/registry"
 	"github.com/kubeclipper/kubeclipper/pkg/server/request"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 	"github.com/kubeclipper/kubeclipper/pkg/service/delivery"
 	"github.com/kubeclipper/kubeclipper/pkg/service/staticresource"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/hashutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
 )
@@ -94,7 +106,7 @@ type APIServer struct {
 	Config                *config.Config
 	Services              []service.Interface
 	cache                 cache.Interface
-	RESTOptionsGetter     *etcdRESTOptions.SimpleRestOptionsFactory
+	RESTOptionsGetter     generic.RESTOptionsGetter
 	informerFactory       informers.SharedInformerFactory
 	storageFactory        registry.SharedStorageFactory
 	rbacAuthorizer        authorizer.Authorizer
@@ -106,7 +118,11 @@ type APIServer struct {
 func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
 	s.internalInformerUser = "system:kc-server"
 	s.InternalInformerToken = uuid.New().String()
-	s.storageFactory = registry.NewSharedStorageFactory(s.RESTOptionsGetter)
+	var cachedReads []string
+	if s.Config.EtcdOptions.EnableWatchCache {
+		cachedReads = s.Config.EtcdOptions.CachedReadResources
+	}
+	s.storageFactory = registry.NewSharedStorageFactory(s.RESTOptionsGetter, cachedReads...)
 
 	var err error
 	switch s.Config.CacheOptions.CacheProvider {
@@ -138,6 +154,9 @@ func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
 	if err := s.installAPIs(stopCh); err != nil {
 		return err
 	}
+	if err := openapi.Install(s.container); err != nil {
+		return err
+	}
 	healthz.InstallRootHealthz(s.container)
 	s.installMetricsAPI()
 	s.installVersionAPI()
@@ -148,6 +167,9 @@ func (s *APIServer) PrepareRun(stopCh <-chan struct{}) error {
 	}
 
 	s.Server.Handler = s.container
+	if s.Config.TracingOptions.Enabled() {
+		s.Server.Handler = tracing.Handler(s.container, "kc-server")
+	}
 
 	for _, svc := range s.Services {
 		if err := svc.PrepareRun(stopCh); err != nil {
@@ -167,12 +189,22 @@ func (s *APIServer) Run(stopCh <-chan struct{}) (err error) {
 		_ = s.Server.Shutdown(ctx)
 	}()
 
+	shutdownTracing, err := tracing.Setup(ctx, s.Config.TracingOptions, "kc-server")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = shutdownTracing(context.Background())
+	}()
+
 	for _, svc := range s.Services {
 		if err := svc.Run(stopCh); err != nil {
 			return err
 		}
 	}
 
+	metrics.Serve(s.Config.MetricsOptions, stopCh)
+
 	logger.Info("Server start", zap.String("addr", s.Server.Addr), zap.String("version", version.Get().String()))
 
 	if s.Server.TLSConfig != nil {
@@ -191,7 +223,7 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) error {
 		s.storageFactory.GlobalRoleBindings(), s.storageFactory.Tokens(), s.storageFactory.LoginRecords())
 	tokenOperator := auth.NewTokenOperator(iamOperator, s.Config.AuthenticationOptions)
 
-	authnPathAuthenticator, err := authnpath.NewAuthenticator([]string{"/oauth/login", "/version", "/metrics", "/healthz"})
+	authnPathAuthenticator, err := authnpath.NewAuthenticator([]string{"/oauth/login", "/version", "/metrics", "/healthz", "/openapi/*"})
 	if err != nil {
 		return err
 	}
@@ -202,12 +234,29 @@ func (s *APIServer) buildHandlerChain(stopCh <-chan struct{}) error {
 		anonymous.NewAuthenticator(), bearertoken.New(tokenAuthn), wstoken.New(tokenAuthn))))
 
 	s.container.Filter(filters.WithAuthorization(s.rbacAuthorizer))
+	s.container.Filter(filters.WithProjectScope(map[string]rest.Getter{
+		"clusters":   s.storageFactory.Clusters(),
+		"nodes":      s.storageFactory.Nodes(),
+		"backups":    s.storageFactory.Backups(),
+		"operations": s.storageFactory.Operations(),
+		"events":     s.storageFactory.ObjectEvents(),
+	}))
+	s.container.Filter(filters.WithGenerateName())
 
 	a := auditing.NewAuditing(audit.LevelRequest)
 	a.AddBackend(auditing.ConsoleBackend{})
 	if s.databaseAuditBackend != nil {
 		a.AddBackend(s.databaseAuditBackend)
 	}
+	if s.Config.AuditOptions != nil {
+		for _, opts := range s.Config.AuditOptions.Sinks {
+			backend, err := auditing.NewSinkBackend(opts, stopCh)
+			if err != nil {
+				return err
+			}
+			a.AddBackend(backend)
+		}
+	}
 	s.container.Filter(filters.WithAudit(a))
 	return nil
 }
@@ -229,32 +278,44 @@ func (s *APIServer) installVersionAPI() {
 	}))
 }
 
-func monitorRequest(r *restful.Request, response *restful.Response, chain *restful.FilterChain) {
-	// start := time.Now()
-	chain.ProcessFilter(r, response)
-	// TODO: Add metrics
-}
-
 func (s *APIServer) installAPIs(stopCh <-chan struct{}) error {
-	clusterOperator := cluster.NewClusterOperator(s.storageFactory.Clusters(),
-		s.storageFactory.Nodes(),
+	webhookOperator := platform.NewWebhookConfigurationOperator(s.storageFactory.WebhookConfigurations())
+	eventOperator := platform.NewObjectEventOperator(s.storageFactory.ObjectEvents())
+	admitter := admission.NewAdmitter(webhookOperator)
+	clusterOperator := cluster.NewClusterOperator(admission.NewStorage(s.storageFactory.Clusters(), "clusters", admitter),
+		admission.NewStorage(s.storageFactory.Nodes(), "nodes", admitter),
 		s.storageFactory.Regions(),
-		s.storageFactory.Backups(),
+		admission.NewStorage(s.storageFactory.Backups(), "backups", admitter),
 		s.storageFactory.Recoveries(),
 		s.storageFactory.BackupPoints(),
 		s.storageFactory.DNSDomains(),
 		s.storageFactory.Template(),
+		s.storageFactory.ClusterTemplates(),
+		s.storageFactory.CronMaintenances(),
+		s.storageFactory.Prechecks(),
 	)
 	leaseOperator := lease.NewLeaseOperator(s.storageFactory.Leases())
-	opOperator := operation.NewOperationOperator(s.storageFactory.Operations())
+	opOperator := operation.WithProjectLabel(operation.NewOperationOperator(s.storageFactory.Operations()), clusterOperator)
 	iamOperator := iam.NewOperator(s.storageFactory.Users(), s.storageFactory.GlobalRoles(),
 		s.storageFactory.GlobalRoleBindings(), s.storageFactory.Tokens(), s.storageFactory.LoginRecords())
 	s.rbacAuthorizer = rbac.NewAuthorizer(iamOperator)
+	projectOperator := iam.NewProjectOperator(s.storageFactory.Projects())
 
-	deliverySvc := delivery.NewService(s.Config.MQOptions, clusterOperator, leaseOperator, opOperator)
+	faults, err := s.Config.FaultInjectionOptions.Injector()
+	if err != nil {
+		return err
+	}
+	nodeMetrics := nodemetrics.NewStore(nodemetrics.DefaultWindow)
+	metrics.RawMustRegister(nodeMetrics)
+	platformOperator := platform.NewPlatformOperator(s.storageFactory.PlatformSettings(), s.storageFactory.Events(),
+		s.storageFactory.Notifiers(), s.storageFactory.Notifications())
+	deliverySvc := delivery.NewService(s.Config.MQOptions, clusterOperator, leaseOperator, opOperator,
+		delivery.WithFaultInjector(faults), delivery.WithNodeMetrics(nodeMetrics),
+		delivery.WithRecorder(record.NewRecorder(eventOperator, "kc-server")),
+		delivery.WithQuota(quota.NewChecker(platformOperator, clusterOperator, clusterOperator, opOperator)))
+	metrics.RawMustRegister(deliverySvc.QueueCollector())
 	s.Services = append(s.Services, deliverySvc)
 
-	platformOperator := platform.NewPlatformOperator(s.storageFactory.PlatformSettings(), s.storageFactory.Events())
 	if err := configv1.AddToContainer(s.container, platformOperator, s.Config); err != nil {
 		return err
 	}
@@ -263,7 +324,7 @@ func (s *APIServer) installAPIs(stopCh <-chan struct{}) error {
 
 	tokenOperator := auth.NewTokenOperator(iamOperator, s.Config.AuthenticationOptions)
 
-	if err := iamv1.AddToContainer(s.container, iamOperator, s.rbacAuthorizer, tokenOperator); err != nil {
+	if err := iamv1.AddToContainer(s.container, iamOperator, projectOperator, s.rbacAuthorizer, tokenOperator); err != nil {
 		return err
 	}
 
@@ -277,12 +338,30 @@ func (s *APIServer) installAPIs(stopCh <-chan struct{}) error {
 		return err
 	}
 
-	ctrl, err := manager.NewControllerManager(s.internalInformerUser, s.InternalInformerToken, s.storageFactory, deliverySvc, SetupController)
+	nodeProviders, err := nodeprovider.NewRegistry(s.Config.NodeProviderOptions)
+	if err != nil {
+		return err
+	}
+	nodePoolPatcher := corev1.NewNodePoolPatcher(clusterOperator, opOperator, deliverySvc, s.Config.StaticServerOptions.Path)
+	recycleBinPurger := corev1.NewRecycleBinPurger(clusterOperator, opOperator, deliverySvc, s.Config.StaticServerOptions.Path)
+	ctrl, err := manager.NewControllerManager(s.internalInformerUser, s.InternalInformerToken, s.storageFactory, deliverySvc,
+		func(mgr manager.Manager, informerFactory informers.SharedInformerFactory, storageFactory registry.SharedStorageFactory) error {
+			return SetupController(mgr, informerFactory, storageFactory, s.Config.EtcdOptions, s.Config.WorkloadInventoryOptions,
+				nodeProviders, nodePoolPatcher, recycleBinPurger)
+		})
 	if err != nil {
 		return err
 	}
 	s.Services = append(s.Services, ctrl)
-	if err = corev1.AddToContainer(s.container, clusterOperator, opOperator, platformOperator, leaseOperator, deliverySvc); err != nil {
+	discoverer, err := inventory.NewDiscoverer(s.Config.InventoryOptions)
+	if err != nil {
+		return err
+	}
+	// the API server caches the operations to find the ones running on a cluster without listing them all.
+	opInformer := operation.NewOperationInformer(opOperator, 0)
+	go opInformer.Run(stopCh)
+	if err = corev1.AddToContainer(s.container, clusterOperator, opOperator, opInformer, platformOperator, leaseOperator, deliverySvc,
+		nodeMetrics, discoverer, projectOperator, webhookOperator, eventOperator, admitter, s.Config.StaticServerOptions.Path); err != nil {
 		return err
 	}
 	staticResourceSvc, err := staticresource.NewService(s.Config.StaticServerOptions)
@@ -423,7 +502,9 @@ func fileExist(file string) bool {
 	return err == nil
 }
 
-func SetupController(mgr manager.Manager, informerFactory informers.SharedInformerFactory, storageFactory registry.SharedStorageFactory) error {
+func SetupController(mgr manager.Manager, informerFactory informers.SharedInformerFactory, storageFactory registry.SharedStorageFactory,
+	platformEtcd *etcd.Options, workloadInventory *workloadinventory.Options, nodeProviders *nodeprovider.Registry,
+	nodePoolPatcher controller.NodePoolPatcher, recycleBinPurger controller.RecycleBinPurger) error {
 	var err error
 	clusterOperator := cluster.NewClusterOperator(storageFactory.Clusters(),
 		storageFactory.Nodes(),
@@ -433,8 +514,14 @@ func SetupController(mgr manager.Manager, informerFactory informers.SharedInform
 		storageFactory.BackupPoints(),
 		storageFactory.DNSDomains(),
 		storageFactory.Template(),
+		storageFactory.ClusterTemplates(),
+		storageFactory.CronMaintenances(),
+		storageFactory.Prechecks(),
 	)
-	opOperator := operation.NewOperationOperator(storageFactory.Operations())
+	opOperator := operation.WithProjectLabel(operation.NewOperationOperator(storageFactory.Operations()), clusterOperator)
+	platformOperator := platform.NewPlatformOperator(storageFactory.PlatformSettings(), storageFactory.Events(),
+		storageFactory.Notifiers(), storageFactory.Notifications())
+	eventOperator := platform.NewObjectEventOperator(storageFactory.ObjectEvents())
 	iamOperator := iam.NewOperator(storageFactory.Users(),
 		storageFactory.GlobalRoles(),
 		storageFactory.GlobalRoleBindings(),
@@ -464,12 +551,11 @@ func SetupController(mgr manager.Manager, informerFactory informers.SharedInform
 		return err
 	}
 	if err = (&clustercontroller.ClusterReconciler{
-		CmdDelivery:     mgr.GetCmdDelivery(),
-		ClusterLister:   informerFactory.Core().V1().Clusters().Lister(),
-		NodeLister:      informerFactory.Core().V1().Nodes().Lister(),
-		NodeWriter:      clusterOperator,
-		ClusterWriter:   clusterOperator,
-		OperationWriter: opOperator,
+		CmdDelivery:   mgr.GetCmdDelivery(),
+		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
+		NodeLister:    informerFactory.Core().V1().Nodes().Lister(),
+		NodeWriter:    clusterOperator,
+		ClusterWriter: clusterOperator,
 	}).SetupWithManager(mgr, informerFactory); err != nil {
 		return err
 	}
@@ -487,6 +573,11 @@ func SetupController(mgr manager.Manager, informerFactory informers.SharedInform
 	}).SetupWithManager(mgr, informerFactory); err != nil {
 		return err
 	}
+	if err = (&notificationcontroller.NotificationReconciler{
+		PlatformOperator: platformOperator,
+	}).SetupWithManager(mgr, informerFactory); err != nil {
+		return err
+	}
 	if err = (&tokencontroller.TokenReconciler{
 		TokenLister: informerFactory.Iam().V1().Tokens().Lister(),
 		TokenWriter: iamOperator,
@@ -497,10 +588,68 @@ func SetupController(mgr manager.Manager, informerFactory informers.SharedInform
 		ClusterWriter: clusterOperator,
 		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
 	}).SetupWithManager(mgr)
+	(&controller.ClusterHealthMon{
+		ClusterWriter: clusterOperator,
+		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
+	}).SetupWithManager(mgr)
+	(&workloadinventory.Collector{
+		Options:       workloadInventory,
+		ClusterWriter: clusterOperator,
+		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
+	}).SetupWithManager(mgr)
+	(&controller.NodeDriftMon{
+		ClusterWriter: clusterOperator,
+		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
+		NodeLister:    informerFactory.Core().V1().Nodes().Lister(),
+	}).SetupWithManager(mgr)
+	(&controller.NodePoolScaleMon{
+		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
+		NodeLister:    informerFactory.Core().V1().Nodes().Lister(),
+		Providers:     nodeProviders,
+		Patcher:       nodePoolPatcher,
+	}).SetupWithManager(mgr)
+	(&controller.RegistrySyncMon{
+		PlatformOperator: platformOperator,
+	}).SetupWithManager(mgr)
+	(&controller.RecycleBinMon{
+		PlatformOperator: platformOperator,
+		ClusterLister:    informerFactory.Core().V1().Clusters().Lister(),
+		BackupLister:     informerFactory.Core().V1().Backups().Lister(),
+		Purger:           recycleBinPurger,
+	}).SetupWithManager(mgr)
+	(&controller.BackupPointUsageMon{
+		BackupPointReader: clusterOperator,
+		BackupPointWriter: clusterOperator,
+		BackupLister:      informerFactory.Core().V1().Backups().Lister(),
+	}).SetupWithManager(mgr)
+	(&controller.GarbageCollector{
+		ClusterLister:   informerFactory.Core().V1().Clusters().Lister(),
+		ClusterWriter:   clusterOperator,
+		BackupLister:    informerFactory.Core().V1().Backups().Lister(),
+		OperationLister: informerFactory.Core().V1().Operations().Lister(),
+		BackupWriter:    clusterOperator,
+		OperationWriter: opOperator,
+		Recorder:        record.NewRecorder(eventOperator, "garbage-collector"),
+	}).SetupWithManager(mgr)
+	(&controller.EtcdMaintenanceMon{
+		MaintenanceReader: clusterOperator,
+		MaintenanceWriter: clusterOperator,
+		ClusterLister:     informerFactory.Core().V1().Clusters().Lister(),
+		NodeLister:        informerFactory.Core().V1().Nodes().Lister(),
+		OperationWriter:   opOperator,
+		CmdDelivery:       mgr.GetCmdDelivery(),
+		PlatformEtcd:      platformEtcd,
+	}).SetupWithManager(mgr)
+	(&controller.CertificateMon{
+		ClusterWriter: clusterOperator,
+		ClusterLister: informerFactory.Core().V1().Clusters().Lister(),
+		CmdDelivery:   mgr.GetCmdDelivery(),
+	}).SetupWithManager(mgr)
 	(&controller.NodeStatusMon{
 		NodeLister:  informerFactory.Core().V1().Nodes().Lister(),
 		LeaseLister: informerFactory.Core().V1().Leases().Lister(),
 		NodeWriter:  clusterOperator,
+		Recorder:    record.NewRecorder(eventOperator, "node-status-monitor"),
 	}).SetupWithManager(mgr)
 	return nil
 }
@@ -27,23 +27,32 @@ import (
 	"sync"
 	"time"
 
+	"github.com/kubeclipper/kubeclipper/pkg/faultinject"
 	"github.com/kubeclipper/kubeclipper/pkg/oplog"
 
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/models/cluster"
 	"github.com/kubeclipper/kubeclipper/pkg/models/lease"
 	"github.com/kubeclipper/kubeclipper/pkg/models/operation"
+	"github.com/kubeclipper/kubeclipper/pkg/nodemetrics"
+	"github.com/kubeclipper/kubeclipper/pkg/quota"
+	"github.com/kubeclipper/kubeclipper/pkg/record"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
 )
 
 var _ service.Interface = (*Service)(nil)
@@ -51,12 +60,15 @@ var _ service.IDelivery = (*Service)(nil)
 
 const (
 	updateOperationStatusRetry = 10
+	cancelTaskTimeout          = 10 * time.Second
+	browseFileTimeout          = 30 * time.Second
 )
 
 type stepStatus struct {
 	OperationIdentity  string
 	OperationCondition v1.OperationCondition
 	DryRun             bool
+	TraceContext       tracing.Carrier
 }
 
 type Service struct {
@@ -69,9 +81,53 @@ type Service struct {
 	leaseOperator     lease.Operator
 	opOperator        operation.Operator
 	stepStatusChan    chan stepStatus
+	// running maps the operations delivered by this server to the cancel func of their steps
+	running sync.Map
+	// nodeMetrics keeps the resource usage reported with node status
+	nodeMetrics *nodemetrics.Store
+	// serverID tells apart the replies to the terminal sessions opened by this server
+	serverID string
+	// terminals maps session ID to the terminal sessions opened by this server
+	terminals sync.Map
+	// logWatchers holds the watchers of the step logs of running operations
+	logWatchers *logWatchers
+	// quota admits the nodes registering within the quota of their region
+	quota *quota.Checker
+	// recorder records the steps failed on the nodes as events of their operation
+	recorder record.Recorder
 }
 
-func NewService(opts *natsio.NatsOptions, clusterOperator cluster.Operator, leaseOperator lease.Operator, opOperator operation.Operator) *Service {
+type Option func(*Service)
+
+// WithFaultInjector injects the faults of message rules into the messages sent to agents.
+func WithFaultInjector(injector *faultinject.Injector) Option {
+	return func(s *Service) {
+		s.client = faultinject.WrapMQ(s.client, injector)
+	}
+}
+
+// WithNodeMetrics records the resource usage reported with node status into store.
+func WithNodeMetrics(store *nodemetrics.Store) Option {
+	return func(s *Service) {
+		s.nodeMetrics = store
+	}
+}
+
+// WithQuota rejects the registration of nodes exceeding the quota of their region.
+func WithQuota(checker *quota.Checker) Option {
+	return func(s *Service) {
+		s.quota = checker
+	}
+}
+
+// WithRecorder records the steps failed on the nodes as events of their operation.
+func WithRecorder(recorder record.Recorder) Option {
+	return func(s *Service) {
+		s.recorder = recorder
+	}
+}
+
+func NewService(opts *natsio.NatsOptions, clusterOperator cluster.Operator, leaseOperator lease.Operator, opOperator operation.Operator, options ...Option) *Service {
 	s := &Service{
 		external:          opts.External,
 		client:            natsio.NewNats(opts),
@@ -82,11 +138,18 @@ func NewService(opts *natsio.NatsOptions, clusterOperator cluster.Operator, leas
 		leaseOperator:     leaseOperator,
 		opOperator:        opOperator,
 		stepStatusChan:    make(chan stepStatus, 256),
+		serverID:          uuid.New().String(),
+		logWatchers:       newLogWatchers(),
 	}
 	s.client.SetReconnectHandler(s.defaultMQReconnectHandler)
 	s.client.SetDisconnectErrHandler(s.defaultMQDisconnectHandler)
 	s.client.SetErrorHandler(s.defaultMQErrorHandler)
 	s.client.SetClosedHandler(s.defaultMQClosedHandler)
+	for _, opt := range options {
+		opt(s)
+	}
+	// instrument last so that injected faults are counted as well
+	s.client = instrumentMQ(s.client)
 	return s
 }
 
@@ -122,6 +185,13 @@ func (s *Service) Run(stopCh <-chan struct{}) error {
 	if err := s.client.QueueSubscribe(s.nodeReportSubject, s.queueGroup, s.nodeStateReportInHandler); err != nil {
 		return err
 	}
+	if err := s.client.Subscribe(s.terminalSubject(), s.terminalOutputHandler); err != nil {
+		return err
+	}
+	// every server receives the chunks, the websocket of a watcher may be served by any of them
+	if err := s.client.Subscribe(s.operationLogSubject(), s.operationLogHandler); err != nil {
+		return err
+	}
 	go s.stepStatusChannelController()
 	return nil
 }
@@ -134,13 +204,14 @@ func (s *Service) Close() {
 	s.client.Close()
 }
 
-func initPayload(operationIdentity string, operation service.Operation, step *v1.Step, lastStepReply []byte, cmds []string, dryRun, retry bool) ([]byte, error) {
+func initPayload(ctx context.Context, operationIdentity string, operation service.Operation, step *v1.Step, lastStepReply []byte, cmds []string, dryRun, retry bool) ([]byte, error) {
 	payload := service.MsgPayload{
 		Op:                operation,
 		OperationIdentity: operationIdentity,
 		DryRun:            dryRun,
 		Retry:             retry,
 		Cmds:              cmds,
+		TraceContext:      tracing.Inject(ctx),
 	}
 	if step != nil {
 		payload.Step = *step
@@ -158,30 +229,38 @@ func (s *Service) stepStatusChannelController() {
 				zap.String("step", status.OperationCondition.StepID), zap.Any("step_status", status.OperationCondition))
 			return
 		}
-		// TODO: 简化更新,允许强制更新?
-		for i := 0; i < updateOperationStatusRetry; i++ {
-			o, err := s.opOperator.GetOperation(context.TODO(), status.OperationIdentity)
-			if err != nil {
-				logger.Error("update operation step condition failed", zap.String("op", status.OperationIdentity),
-					zap.String("step", status.OperationCondition.StepID), zap.Any("step_status", status.OperationCondition), zap.Error(err))
-				continue
-			}
+		s.saveStepStatus(status)
+	}
+}
 
-			stepLen := len(o.Status.Conditions)
-			if stepLen > 0 && status.OperationCondition.StepID == o.Status.Conditions[stepLen-1].StepID {
-				o.Status.Conditions[stepLen-1].Status = append(o.Status.Conditions[stepLen-1].Status, status.OperationCondition.Status...)
-			} else {
-				o.Status.Conditions = append(o.Status.Conditions, status.OperationCondition)
-			}
+func (s *Service) saveStepStatus(status stepStatus) {
+	_, span := tracing.Start(tracing.Extract(context.TODO(), status.TraceContext), "save step status",
+		attribute.String("operation", status.OperationIdentity), attribute.String("step.id", status.OperationCondition.StepID))
+	defer span.End()
+	// TODO: 简化更新,允许强制更新?
+	for i := 0; i < updateOperationStatusRetry; i++ {
+		o, err := s.opOperator.GetOperation(context.TODO(), status.OperationIdentity)
+		if err != nil {
+			logger.Error("update operation step condition failed", zap.String("op", status.OperationIdentity),
+				zap.String("step", status.OperationCondition.StepID), zap.Any("step_status", status.OperationCondition), zap.Error(err))
+			continue
+		}
 
-			if _, err := s.opOperator.UpdateOperation(context.TODO(), o); err != nil {
-				logger.Error("update operation step condition failed", zap.String("op", status.OperationIdentity),
-					zap.String("step", status.OperationCondition.StepID), zap.Any("step_status", status.OperationCondition), zap.Error(err))
-				continue
-			}
-			break
+		stepLen := len(o.Status.Conditions)
+		if stepLen > 0 && status.OperationCondition.StepID == o.Status.Conditions[stepLen-1].StepID {
+			o.Status.Conditions[stepLen-1].Status = append(o.Status.Conditions[stepLen-1].Status, status.OperationCondition.Status...)
+		} else {
+			o.Status.Conditions = append(o.Status.Conditions, status.OperationCondition)
 		}
+
+		if _, err := s.opOperator.UpdateOperation(context.TODO(), o); err != nil {
+			logger.Error("update operation step condition failed", zap.String("op", status.OperationIdentity),
+				zap.String("step", status.OperationCondition.StepID), zap.Any("step_status", status.OperationCondition), zap.Error(err))
+			continue
+		}
+		return
 	}
+	span.SetStatus(codes.Error, "update operation step condition failed")
 }
 
 func (s *Service) updateOperationStatus(op string, status v1.OperationStatusType, dryRun bool) {
@@ -198,6 +277,9 @@ func (s *Service) updateOperationStatus(op string, status v1.OperationStatusType
 			continue
 		}
 		o.Status.Status = status
+		// pause and cancel requests are consumed by any status change
+		delete(o.Annotations, common.AnnotationOperationPause)
+		delete(o.Annotations, common.AnnotationOperationCancel)
 		if o, err = s.opOperator.UpdateOperation(context.TODO(), o); err != nil {
 			logger.Error("update operation status type failed", zap.String("op", op), zap.String("status", string(status)), zap.Error(err))
 			continue
@@ -209,6 +291,10 @@ func (s *Service) updateOperationStatus(op string, status v1.OperationStatusType
 
 func (s *Service) SyncClusterCondition(op *v1.Operation) {
 	defer service.HandlerCrash()
+	// operations such as prewarm are not bound to a cluster
+	if op.Labels[common.LabelClusterName] == "" {
+		return
+	}
 	for i := 0; i < updateOperationStatusRetry; i++ {
 		clu, err := s.clusterOperator.GetClusterEx(context.TODO(), op.Labels[common.LabelClusterName], "0")
 		if err != nil {
@@ -223,6 +309,10 @@ func (s *Service) SyncClusterCondition(op *v1.Operation) {
 }
 
 func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error {
+	if op.Status.Status == v1.OperationStatusPaused {
+		// the cluster keeps its in progress status until the operation is resumed or cancelled
+		return nil
+	}
 	switch v := op.Labels[common.LabelOperationAction]; v {
 	case v1.OperationCreateCluster:
 		if op.Status.Status == v1.OperationStatusSuccessful {
@@ -297,7 +387,7 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 			return err
 		}
 		return nil
-	case v1.OperationUpgradeCluster:
+	case v1.OperationUpgradeCluster, v1.OperationRollbackUpgrade:
 		if op.Status.Status == v1.OperationStatusSuccessful {
 			clu.Status.Status = v1.ClusterStatusRunning
 			clu.Kubeadm.KubernetesVersion = op.Labels[common.LabelUpgradeVersion]
@@ -318,7 +408,11 @@ func (s *Service) syncClusterCondition(op *v1.Operation, clu *v1.Cluster) error
 		}
 		_, err := s.clusterOperator.UpdateCluster(context.TODO(), clu)
 		return err
-	case v1.OperationInstallComponents, v1.OperationUninstallComponents:
+	case v1.OperationEtcdMaintenance:
+		// maintenance runs do not change the cluster status
+		return nil
+	case v1.OperationInstallComponents, v1.OperationUninstallComponents, v1.OperationUpgradeComponents,
+		v1.OperationRotateCredentials, v1.OperationRenewCertificates, v1.OperationUpdateRegistries, v1.OperationUpdateCertSANs:
 		if op.Status.Status == v1.OperationStatusSuccessful {
 			clu.Status.Status = v1.ClusterStatusRunning
 		} else {
@@ -373,17 +467,40 @@ func (s *Service) DeliverTaskOperation(ctx context.Context, operation *v1.Operat
 	if opts == nil {
 		opts = &service.Options{DryRun: false}
 	}
+	// continue the trace of the request that created the operation
+	ctx, span := tracing.Start(tracing.ExtractAnnotations(ctx, operation.Annotations), "deliver operation",
+		attribute.String("operation", operation.Name), attribute.String("operation.action", operation.Labels[common.LabelOperationAction]),
+		attribute.Int("operation.steps", len(operation.Steps)), attribute.Bool("dry_run", opts.DryRun))
+	defer span.End()
 	timeoutSecs := operation.Labels[common.LabelTimeoutSeconds]
 	secs, _ := strconv.Atoi(timeoutSecs)
 	ctx, cancelFn := context.WithTimeout(ctx, time.Duration(secs)*time.Second)
 	defer cancelFn()
-	// new empty context, pass retry value
-	stepCtx, stepCtxCancel := context.WithCancel(component.WithRetry(context.TODO(), component.GetRetry(ctx)))
+	if !opts.DryRun {
+		if err := s.runHooks(ctx, operation, v1.HookPhasePre, ""); err != nil {
+			logger.Error("operation aborted by pre hook", zap.String("operation", operation.Name), zap.Error(err))
+			span.SetStatus(codes.Error, err.Error())
+			_ = s.runPostHooks(span, operation, err, false)
+			s.updateOperationStatus(operation.Name, v1.OperationStatusFailed, false)
+			return nil
+		}
+	}
+	// new empty context, pass retry value and the span of the operation
+	stepCtx, stepCtxCancel := context.WithCancel(tracing.WithSpan(component.WithRetry(context.TODO(), component.GetRetry(ctx)), span))
 	defer stepCtxCancel()
 	doneChan := make(chan struct{}, 1)
 	defer close(doneChan)
 	errChan := make(chan error, 1)
 	defer close(errChan)
+	pausedChan := make(chan struct{}, 1)
+	defer close(pausedChan)
+	cancelledChan := make(chan struct{}, 1)
+	defer close(cancelledChan)
+	if !opts.DryRun {
+		s.running.Store(operation.Name, stepCtxCancel)
+		defer s.running.Delete(operation.Name)
+	}
+	operation.ApplyStepPolicies()
 	operation.Status.Conditions = make([]v1.OperationCondition, len(operation.Steps))
 	go func() {
 		for {
@@ -401,10 +518,22 @@ func (s *Service) DeliverTaskOperation(ctx context.Context, operation *v1.Operat
 				// step run error and step ignoreError flag is false
 				go s.updateOperationStatus(operation.Name, v1.OperationStatusFailed, opts.DryRun)
 				return
+			case <-pausedChan:
+				// stopped between two steps, the remaining steps run when the operation is resumed
+				go s.updateOperationStatus(operation.Name, v1.OperationStatusPaused, opts.DryRun)
+				return
+			case <-cancelledChan:
+				go s.updateOperationStatus(operation.Name, v1.OperationStatusCancelled, opts.DryRun)
+				return
 			}
 		}
 	}()
-	var err error
+	var (
+		err       error
+		paused    bool
+		cancelled bool
+	)
+	checkpoints := hasCheckpoint(operation.Steps)
 	for i, step := range operation.Steps {
 		// TODO: add retry steps
 		// TODO: refactor
@@ -425,8 +554,18 @@ func (s *Service) DeliverTaskOperation(ctx context.Context, operation *v1.Operat
 				component.GetExtraData(ctx), &operation.Status.Conditions[i], opts.DryRun)
 		}
 		logger.Debug("after delivery task step", zap.Error(err))
+		pause, cancel := s.operationControl(operation.Name, opts.DryRun)
+		if cancel {
+			logger.Info("operation cancelled", zap.String("operation", operation.Name), zap.String("step", step.Name))
+			s.skipSteps(operation.Name, operation.Steps[i+1:], opts.DryRun)
+			err, cancelled = nil, true
+			break
+		}
 		if err != nil {
 			logger.Error("delivery task step error", zap.Error(err), zap.String("step", step.Name))
+			if !opts.DryRun {
+				s.recordFailedStep(operation, &step, &operation.Status.Conditions[i])
+			}
 			if step.ErrIgnore {
 				logger.Debug("delivery task step, ignore the error", zap.Error(err), zap.String("step", step.Name))
 				// reset error
@@ -435,17 +574,149 @@ func (s *Service) DeliverTaskOperation(ctx context.Context, operation *v1.Operat
 			}
 			break
 		}
+		if pause && i < len(operation.Steps)-1 && (step.Checkpoint || !checkpoints) {
+			logger.Info("operation paused", zap.String("operation", operation.Name), zap.String("step", step.Name))
+			paused = true
+			break
+		}
+	}
+	if !opts.DryRun && !paused {
+		if hookErr := s.runPostHooks(span, operation, err, cancelled); hookErr != nil && err == nil && !cancelled {
+			err = hookErr
+		}
 	}
 	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
 		errChan <- err
+	} else if cancelled {
+		cancelledChan <- struct{}{}
+	} else if paused {
+		pausedChan <- struct{}{}
 	} else {
 		doneChan <- struct{}{}
 	}
 	return nil
 }
 
+// recordFailedStep records the failure of the step on each node as an event of the operation.
+func (s *Service) recordFailedStep(operation *v1.Operation, step *v1.Step, cond *v1.OperationCondition) {
+	if s.recorder == nil {
+		return
+	}
+	for _, st := range cond.Status {
+		if st.Status == v1.StepStatusFailed {
+			s.recorder.Eventf(operation, v1.ObjectEventWarning, v1.EventReasonStepFailed,
+				"step %s failed on node %s: %s", step.Name, st.Node, st.Message)
+		}
+	}
+}
+
+// operationControl reports whether the operation has been asked to pause or to cancel.
+func (s *Service) operationControl(op string, dryRun bool) (pause, cancel bool) {
+	if dryRun {
+		return false, false
+	}
+	o, err := s.opOperator.GetOperation(context.TODO(), op)
+	if err != nil {
+		logger.Error("get operation failed", zap.String("op", op), zap.Error(err))
+		return false, false
+	}
+	_, pause = o.Annotations[common.AnnotationOperationPause]
+	_, cancel = o.Annotations[common.AnnotationOperationCancel]
+	return pause, cancel
+}
+
+// hasCheckpoint reports whether a pause must wait for a checkpoint step.
+func hasCheckpoint(steps []v1.Step) bool {
+	for _, step := range steps {
+		if step.Checkpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// skipSteps records the steps that will not run because the operation was cancelled.
+func (s *Service) skipSteps(op string, steps []v1.Step, dryRun bool) {
+	for _, cond := range skippedConditions(steps) {
+		s.sendStepStatusToChannel(stepStatus{
+			OperationIdentity:  op,
+			OperationCondition: cond,
+			DryRun:             dryRun,
+		})
+	}
+}
+
+func skippedConditions(steps []v1.Step) []v1.OperationCondition {
+	now := metav1.Now()
+	conds := make([]v1.OperationCondition, 0, len(steps))
+	for _, step := range steps {
+		cond := v1.OperationCondition{StepID: step.ID}
+		for _, node := range step.Nodes {
+			cond.Status = append(cond.Status, v1.StepStatus{
+				StartAt: now,
+				EndAt:   now,
+				Node:    node.ID,
+				Status:  v1.StepStatusSkipped,
+				Reason:  "operation cancelled",
+				Message: "step skipped because the operation was cancelled",
+			})
+		}
+		conds = append(conds, cond)
+	}
+	return conds
+}
+
+// CancelOperation stops the operation. A paused operation is cancelled at once. For a
+// running one the caller marks it with the cancel annotation first, so the server
+// delivering it skips the remaining steps; the step is stopped at once when this server
+// delivers the operation, and the agents running the step are asked to stop it.
+func (s *Service) CancelOperation(ctx context.Context, operation *v1.Operation) error {
+	done := len(operation.Status.Conditions)
+	if done > len(operation.Steps) {
+		done = len(operation.Steps)
+	}
+	if operation.Status.Status == v1.OperationStatusPaused {
+		operation.Status.Conditions = append(operation.Status.Conditions, skippedConditions(operation.Steps[done:])...)
+		operation.Status.Status = v1.OperationStatusCancelled
+		delete(operation.Annotations, common.AnnotationOperationPause)
+		op, err := s.opOperator.UpdateOperation(ctx, operation)
+		if err != nil {
+			return err
+		}
+		go s.SyncClusterCondition(op)
+		return nil
+	}
+
+	if cancel, ok := s.running.Load(operation.Name); ok {
+		cancel.(context.CancelFunc)()
+	}
+	if done == len(operation.Steps) {
+		return nil
+	}
+	payload, err := initPayload(ctx, operation.Name, service.OperationCancelTask, nil, nil, nil, false, false)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, cancelTaskTimeout)
+	defer cancel()
+	var errs []error
+	for _, node := range operation.Steps[done].Nodes {
+		msg := &natsio.Msg{
+			Subject: fmt.Sprintf(service.MsgSubjectFormat, node.ID, s.subjectSuffix),
+			Data:    payload,
+		}
+		if _, err = s.client.RequestWithContext(ctx, msg); err != nil {
+			logger.Warn("send cancel task to agent failed", zap.String("operation", operation.Name),
+				zap.String("node", node.ID), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func (s *Service) DeliverLogRequest(ctx context.Context, operation *service.LogOperation) (opResp oplog.LogContentResponse, err error) {
-	pb, err := initPayload(operation.OperationIdentity, operation.Op, nil, nil, nil, false, component.GetRetry(ctx))
+	pb, err := initPayload(ctx, operation.OperationIdentity, operation.Op, nil, nil, nil, false, component.GetRetry(ctx))
 	if err != nil {
 		return
 	}
@@ -471,7 +742,7 @@ func (s *Service) DeliverLogRequest(ctx context.Context, operation *service.LogO
 }
 
 func (s *Service) DeliverCmd(ctx context.Context, toNode string, cmds []string, timeout time.Duration) ([]byte, error) {
-	payload, err := initPayload("", service.OperationRunCmd, &v1.Step{Timeout: metav1.Duration{Duration: timeout}}, nil, cmds, false, component.GetRetry(ctx))
+	payload, err := initPayload(ctx, "", service.OperationRunCmd, &v1.Step{Timeout: metav1.Duration{Duration: timeout}}, nil, cmds, false, component.GetRetry(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -492,14 +763,99 @@ func (s *Service) DeliverCmd(ctx context.Context, toNode string, cmds []string,
 	return resp.Data, nil
 }
 
-func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.Step, lastStepReply []byte, cond *v1.OperationCondition, dryRun bool) error {
-	payloadBytes, err := initPayload(opName, service.OperationRunTask, step, lastStepReply, nil, dryRun, component.GetRetry(ctx))
+func (s *Service) DeliverAgentConfig(ctx context.Context, toNode string, patch *service.AgentConfigPatch, timeout time.Duration) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(service.MsgPayload{
+		Op:   service.OperationReconfigure,
+		Step: v1.Step{Timeout: metav1.Duration{Duration: timeout}},
+		Data: data,
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	reply, err := s.client.RequestWithContext(ctx, &natsio.Msg{
+		Subject: fmt.Sprintf(service.MsgSubjectFormat, toNode, s.subjectSuffix),
+		Data:    payload,
+	})
+	if err != nil {
+		return err
+	}
+	resp := &service.CommonReply{}
+	if err = json.Unmarshal(reply, resp); err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return resp.Error
+	}
+	return nil
+}
+
+func (s *Service) BrowseFile(ctx context.Context, toNode string, req *service.FileRequest) (*service.FileResponse, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(service.MsgPayload{
+		Op:   service.OperationBrowseFile,
+		Step: v1.Step{Timeout: metav1.Duration{Duration: browseFileTimeout}},
+		Data: data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, browseFileTimeout)
+	defer cancel()
+	reply, err := s.client.RequestWithContext(ctx, &natsio.Msg{
+		Subject: fmt.Sprintf(service.MsgSubjectFormat, toNode, s.subjectSuffix),
+		Data:    payload,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := &service.CommonReply{}
+	if err = json.Unmarshal(reply, resp); err != nil {
+		return nil, err
+	}
+	if resp.Error != nil {
+		return nil, resp.Error
+	}
+	fileResp := &service.FileResponse{}
+	if err = json.Unmarshal(resp.Data, fileResp); err != nil {
+		return nil, err
+	}
+	return fileResp, nil
+}
+
+func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.Step, lastStepReply []byte, cond *v1.OperationCondition, dryRun bool) (err error) {
+	ctx, span := tracing.Start(ctx, "deliver step "+step.Name, attribute.String("operation", opName),
+		attribute.String("step.id", step.ID), attribute.Int("step.nodes", len(step.Nodes)))
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+	// retries are driven from here, so the agent runs every attempt only once
+	agentStep := *step
+	agentStep.RetryTimes = 0
+	payloadBytes, err := initPayload(ctx, opName, service.OperationRunTask, &agentStep, lastStepReply, nil, dryRun, component.GetRetry(ctx))
+	if err != nil {
+		return err
+	}
+	retryPayloadBytes, err := initPayload(ctx, opName, service.OperationRunTask, &agentStep, lastStepReply, nil, dryRun, true)
 	if err != nil {
 		return err
 	}
 
 	doneChan := make(chan struct{}, 1)
 	defer close(doneChan)
+	// closed once the step condition has been queued, conditions of later steps must follow it
+	reported := make(chan struct{})
 	status := make([]v1.StepStatus, len(step.Nodes))
 	cond.Status = status
 	cond.StepID = step.ID
@@ -508,6 +864,7 @@ func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.
 	//	Status: status,
 	// }
 	go func(op string, cond *v1.OperationCondition) {
+		defer close(reported)
 		for {
 			select {
 			case <-ctx.Done():
@@ -516,6 +873,7 @@ func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.
 					OperationIdentity:  op,
 					OperationCondition: *cond,
 					DryRun:             dryRun,
+					TraceContext:       tracing.Inject(ctx),
 				})
 				return
 			case <-doneChan:
@@ -525,6 +883,7 @@ func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.
 					OperationIdentity:  op,
 					OperationCondition: *cond,
 					DryRun:             dryRun,
+					TraceContext:       tracing.Inject(ctx),
 				})
 				return
 			}
@@ -539,24 +898,66 @@ func (s *Service) deliveryTaskStep(ctx context.Context, opName string, step *v1.
 	for i, node := range step.Nodes {
 		wg.Add(1)
 		// notice: make sure step timeout less than operation timeout
-		// TODO: add step retry
-		go s.deliveryStepToNode(&wg, node.ID, payloadBytes, step.Timeout.Duration+2*time.Second, &status[i], errChan)
+		go s.deliveryStepToNodeWithRetry(ctx, &wg, node.ID, step, payloadBytes, retryPayloadBytes, &status[i], errChan)
 	}
 
 	wg.Wait()
 
+	doneChan <- struct{}{}
+	<-reported
 	if len(errChan) > 0 {
 		logger.Debug("err chan has value...")
 		return <-errChan
 	}
-	doneChan <- struct{}{}
 	logger.Debug("deliveryTaskStep method end...")
 	return nil
 }
 
-func (s *Service) deliveryStepToNode(wg *sync.WaitGroup, node string, payload []byte, timeout time.Duration, stepStatus *v1.StepStatus, errChan chan error) {
+// deliveryStepToNodeWithRetry runs the step on the node up to RetryTimes+1 times,
+// waiting RetryBackoff before the first retry and twice as long before each further one.
+func (s *Service) deliveryStepToNodeWithRetry(ctx context.Context, wg *sync.WaitGroup, node string, step *v1.Step, payload, retryPayload []byte, stepStatus *v1.StepStatus, errChan chan error) {
 	defer wg.Done()
 
+	var err error
+	backoff := step.RetryBackoff.Duration
+	for i := 0; i <= int(step.RetryTimes); i++ {
+		if i > 0 {
+			logger.Info("retry step", zap.String("step", step.Name), zap.String("node", node),
+				zap.Int("attempt", i+1), zap.Duration("backoff", backoff), zap.Error(err))
+			select {
+			case <-ctx.Done():
+				errChan <- err
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			payload = retryPayload
+		}
+		err = s.deliveryStepToNode(ctx, node, payload, step.Timeout.Duration+2*time.Second, stepStatus)
+		if step.RetryTimes > 0 {
+			stepStatus.Attempts = append(stepStatus.Attempts, v1.StepAttempt{
+				StartAt: stepStatus.StartAt,
+				EndAt:   stepStatus.EndAt,
+				Status:  stepStatus.Status,
+				Reason:  stepStatus.Reason,
+				Message: stepStatus.Message,
+			})
+		}
+		if err == nil {
+			return
+		}
+	}
+	errChan <- err
+}
+
+func (s *Service) deliveryStepToNode(ctx context.Context, node string, payload []byte, timeout time.Duration, stepStatus *v1.StepStatus) (err error) {
+	_, span := tracing.Start(ctx, "request agent", attribute.String("node", node))
+	defer func() {
+		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 	now := time.Now()
 	stepStatus.StartAt = metav1.NewTime(now)
 	stepStatus.Node = node
@@ -575,22 +976,20 @@ func (s *Service) deliveryStepToNode(wg *sync.WaitGroup, node string, payload []
 	})
 	if err != nil {
 		setStepStatus(stepStatus, v1.StepStatusFailed, err.Error(), "internal server error for send request to agent", nil)
-		errChan <- err
-		return
+		return err
 	}
 	resp := &service.CommonReply{}
 	if err = json.Unmarshal(data, resp); err != nil {
 		logger.Error("unmarshal agent reply error", zap.Error(err))
 		setStepStatus(stepStatus, v1.StepStatusFailed, "unmarshal agent reply error", err.Error(), nil)
-		errChan <- err
-		return
+		return err
 	}
 	if resp.Error != nil {
 		setStepStatus(stepStatus, v1.StepStatusFailed, resp.Error.Message, resp.Error.Error(), nil)
-		errChan <- resp.Error
-		return
+		return resp.Error
 	}
 	setStepStatus(stepStatus, v1.StepStatusSuccessful, "run step successfully", "run step successfully", resp.Data)
+	return nil
 }
 
 func setStepStatus(status *v1.StepStatus, statusType v1.StepStatusType, message, reason string, response []byte) {
@@ -19,6 +19,7 @@
 package delivery
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
@@ -137,7 +138,7 @@ func mustJSONMarshal(v interface{}) []byte {
 }
 
 func mustInitPayload(step *v1.Step) []byte {
-	if data, err := initPayload("", service.OperationRunTask, step, nil, nil, false, false); err != nil {
+	if data, err := initPayload(context.TODO(), "", service.OperationRunTask, step, nil, nil, false, false); err != nil {
 		panic(err)
 	} else {
 		return data
@@ -27,10 +27,13 @@ import (
 	"go.uber.org/zap"
 	coordinationv1 "k8s.io/api/coordination/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
 
 	"github.com/kubeclipper/kubeclipper/pkg/errors"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/server/request"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
 )
 
@@ -92,6 +95,8 @@ func (s *Service) nodeStateReportInHandler(msg *nats.Msg) {
 			logger.Error("failed to reply message to notify server", zap.Error(err))
 			return
 		}
+	case service.OperationReportStepProgress:
+		s.updateStepProgress(payload.Data)
 	case service.OperationCreateNodeLease:
 		resp := s.createNodeLeaseOperation(msg, payload.Data)
 		respBytes, err := json.Marshal(resp)
@@ -182,6 +187,9 @@ func (s *Service) updateNodeStatusOperation(msg *nats.Msg, nodeName string, data
 		logger.Error("failed to update node", zap.Error(err))
 		return err
 	}
+	if s.nodeMetrics != nil && targetNode.Status.Usage != nil {
+		s.nodeMetrics.Add(targetNode.Name, *targetNode.Status.Usage)
+	}
 	return nil
 }
 
@@ -245,7 +253,15 @@ func (s *Service) registerNode(node *v1.Node) error {
 		logger.Debug("node already register", zap.String("node", node.Name))
 		return nil
 	}
-	_, err = s.clusterOperator.CreateNode(context.TODO(), node)
+	if s.quota != nil {
+		if err = s.quota.AdmitNode(context.TODO(), node.Labels[common.LabelTopologyRegion]); err != nil {
+			logger.Warn("node registration rejected", zap.String("node", node.Name), zap.Error(err))
+			return err
+		}
+	}
+	// the registration is made on behalf of the agent, so that the admission webhooks see it
+	ctx := request.WithUser(context.TODO(), &user.DefaultInfo{Name: "system:kc-agent:" + node.Name})
+	_, err = s.clusterOperator.CreateNode(ctx, node)
 	if err != nil {
 		logger.Error("create node error", zap.Error(err))
 		return err
@@ -469,3 +485,28 @@ func (s *Service) createNodeLeaseOperation(msg *nats.Msg, data []byte) *service.
 	}
 	return resp
 }
+
+func (s *Service) updateStepProgress(data []byte) {
+	payload := &service.StepProgressPayload{}
+	if err := json.Unmarshal(data, payload); err != nil {
+		logger.Error("failed to unmarshal step progress", zap.Error(err))
+		return
+	}
+	for i := 0; i < updateOperationStatusRetry; i++ {
+		o, err := s.opOperator.GetOperation(context.TODO(), payload.OperationIdentity)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				return
+			}
+			logger.Error("get operation failed when update step progress", zap.String("op", payload.OperationIdentity), zap.Error(err))
+			continue
+		}
+		o.Status.SetStepProgress(payload.Progress)
+		if _, err = s.opOperator.UpdateOperation(context.TODO(), o); err != nil {
+			logger.Error("update step progress failed", zap.String("op", payload.OperationIdentity),
+				zap.String("step", payload.Progress.StepID), zap.String("phase", payload.Progress.Phase), zap.Error(err))
+			continue
+		}
+		return
+	}
+}
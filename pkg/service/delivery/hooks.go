@@ -0,0 +1,232 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
+)
+
+// maxHookMessage bounds the output of a failed hook kept in the operation status.
+const maxHookMessage = 1024
+
+// runHooks runs the hooks of the cluster of the operation matching phase, one after another.
+// It returns an error once a hook with the Abort policy fails, the remaining hooks are skipped.
+// status is the result of the operation for post hooks.
+func (s *Service) runHooks(ctx context.Context, op *v1.Operation, phase v1.HookPhase, status v1.OperationStatusType) error {
+	clusterName := op.Labels[common.LabelClusterName]
+	if clusterName == "" {
+		return nil
+	}
+	c, err := s.clusterOperator.GetCluster(ctx, clusterName)
+	if err != nil {
+		// the cluster is gone once deleted, there is nothing left to run hooks for
+		logger.Warn("get cluster of operation hooks failed", zap.String("operation", op.Name),
+			zap.String("cluster", clusterName), zap.Error(err))
+		return nil
+	}
+	hooks, err := c.OperationHooks()
+	if err != nil {
+		return err
+	}
+	req := v1.HookRequest{
+		Phase:         phase,
+		Cluster:       clusterName,
+		Operation:     op.Name,
+		OperationType: op.Labels[common.LabelOperationAction],
+		Status:        status,
+	}
+	for _, h := range hooks {
+		if !h.Match(phase, req.OperationType) || op.Status.HookSucceeded(h.Name, phase) {
+			continue
+		}
+		req.Hook = h.Name
+		result := s.runHook(ctx, c, h, req)
+		s.recordHookResult(op.Name, result)
+		if result.Status == v1.HookResultSuccessful {
+			continue
+		}
+		logger.Error("operation hook failed", zap.String("operation", op.Name), zap.String("hook", h.Name),
+			zap.String("phase", string(phase)), zap.String("message", result.Message))
+		if h.Abort() {
+			return fmt.Errorf("%s hook %s failed: %s", strings.ToLower(string(phase)), h.Name, result.Message)
+		}
+	}
+	return nil
+}
+
+func (s *Service) runHook(ctx context.Context, c *v1.Cluster, h v1.OperationHook, req v1.HookRequest) v1.HookResult {
+	ctx, cancel := context.WithTimeout(ctx, h.TimeoutDuration())
+	defer cancel()
+	ctx, span := tracing.Start(ctx, "run hook "+h.Name, attribute.String("operation", req.Operation),
+		attribute.String("hook.phase", string(h.Phase)))
+	defer span.End()
+
+	result := v1.HookResult{
+		Name:    h.Name,
+		Phase:   h.Phase,
+		Status:  v1.HookResultSuccessful,
+		StartAt: metav1.Now(),
+	}
+	var err error
+	if h.Webhook != nil {
+		err = callWebhook(ctx, h.Webhook, req)
+	} else {
+		err = s.runHookScript(ctx, c, h.Script, req, h.TimeoutDuration())
+	}
+	result.EndAt = metav1.Now()
+	if err != nil {
+		result.Status = v1.HookResultFailed
+		result.Message = err.Error()
+		if len(result.Message) > maxHookMessage {
+			result.Message = result.Message[:maxHookMessage]
+		}
+		span.SetStatus(codes.Error, result.Message)
+	}
+	return result
+}
+
+func callWebhook(ctx context.Context, hook *v1.WebhookHook, req v1.HookRequest) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	for k, v := range hook.Headers {
+		r.Header.Set(k, v)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if hook.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402
+	}
+	resp, err := (&http.Client{Transport: transport}).Do(r)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxHookMessage))
+		return fmt.Errorf("webhook responded %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+func hookScriptNodes(c *v1.Cluster, target v1.HookTarget) []string {
+	switch target {
+	case v1.HookTargetMasters:
+		return c.Kubeadm.Masters.GetNodeIDs()
+	case v1.HookTargetAllNodes:
+		return append(c.Kubeadm.Masters.GetNodeIDs(), c.Kubeadm.Workers.GetNodeIDs()...)
+	default:
+		return c.Kubeadm.Masters.GetNodeIDs()[:1]
+	}
+}
+
+// hookScript prepends the request to the script as environment variables.
+func hookScript(script *v1.ScriptHook, req v1.HookRequest) string {
+	var b strings.Builder
+	for _, kv := range [][2]string{
+		{"KC_CLUSTER", req.Cluster},
+		{"KC_OPERATION", req.Operation},
+		{"KC_OPERATION_TYPE", req.OperationType},
+		{"KC_HOOK_PHASE", string(req.Phase)},
+		{"KC_OPERATION_STATUS", string(req.Status)},
+	} {
+		fmt.Fprintf(&b, "export %s='%s'\n", kv[0], strings.ReplaceAll(kv[1], "'", `'\''`))
+	}
+	b.WriteString(script.Content)
+	return b.String()
+}
+
+func (s *Service) runHookScript(ctx context.Context, c *v1.Cluster, script *v1.ScriptHook, req v1.HookRequest, timeout time.Duration) error {
+	cmds := []string{"/bin/bash", "-c", hookScript(script, req)}
+	payload, err := initPayload(ctx, req.Operation, service.OperationRunCmd, &v1.Step{Timeout: metav1.Duration{Duration: timeout}}, nil, cmds, false, false)
+	if err != nil {
+		return err
+	}
+	for _, node := range hookScriptNodes(c, script.Target) {
+		data, err := s.client.RequestWithContext(ctx, &natsio.Msg{
+			Subject: fmt.Sprintf(service.MsgSubjectFormat, node, s.subjectSuffix),
+			Data:    payload,
+		})
+		if err != nil {
+			return fmt.Errorf("node %s: %v", node, err)
+		}
+		resp := &service.CommonReply{}
+		if err = json.Unmarshal(data, resp); err != nil {
+			return fmt.Errorf("node %s: %v", node, err)
+		}
+		if resp.Error != nil {
+			return fmt.Errorf("node %s: %s: %s", node, resp.Error.Error(), strings.TrimSpace(string(resp.Data)))
+		}
+	}
+	return nil
+}
+
+func (s *Service) recordHookResult(opName string, result v1.HookResult) {
+	for i := 0; i < updateOperationStatusRetry; i++ {
+		o, err := s.opOperator.GetOperation(context.TODO(), opName)
+		if err != nil {
+			logger.Error("record operation hook result failed", zap.String("op", opName), zap.Error(err))
+			continue
+		}
+		o.Status.Hooks = append(o.Status.Hooks, result)
+		if _, err = s.opOperator.UpdateOperation(context.TODO(), o); err != nil {
+			logger.Error("record operation hook result failed", zap.String("op", opName), zap.Error(err))
+			continue
+		}
+		return
+	}
+}
+
+// runPostHooks runs the post hooks once the steps of the operation are over. They get a
+// context of their own, the one of the operation may have timed out already.
+func (s *Service) runPostHooks(span trace.Span, op *v1.Operation, stepErr error, cancelled bool) error {
+	status := v1.OperationStatusSuccessful
+	if stepErr != nil {
+		status = v1.OperationStatusFailed
+	} else if cancelled {
+		status = v1.OperationStatusCancelled
+	}
+	return s.runHooks(tracing.WithSpan(context.TODO(), span), op, v1.HookPhasePost, status)
+}
@@ -0,0 +1,64 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestCallWebhook(t *testing.T) {
+	var got v1.HookRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("bad token"))
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	req := v1.HookRequest{Hook: "cmdb", Phase: v1.HookPhasePost, Cluster: "demo", Operation: "op", Status: v1.OperationStatusSuccessful}
+	if err := callWebhook(context.TODO(), &v1.WebhookHook{URL: srv.URL, Headers: map[string]string{"X-Token": "secret"}}, req); err != nil {
+		t.Fatal(err)
+	}
+	if got != req {
+		t.Errorf("webhook got %+v, want %+v", got, req)
+	}
+	err := callWebhook(context.TODO(), &v1.WebhookHook{URL: srv.URL}, req)
+	if err == nil || !strings.Contains(err.Error(), "bad token") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestHookScript(t *testing.T) {
+	script := hookScript(&v1.ScriptHook{Content: "echo $KC_CLUSTER"}, v1.HookRequest{Cluster: "it's", Phase: v1.HookPhasePre})
+	for _, want := range []string{`export KC_CLUSTER='it'\''s'`, "export KC_HOOK_PHASE='Pre'", "echo $KC_CLUSTER"} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script %q misses %q", script, want)
+		}
+	}
+}
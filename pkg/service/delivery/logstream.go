@@ -0,0 +1,107 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+// logWatcherBuffer is the number of chunks a slow watcher may fall behind before chunks are dropped.
+const logWatcherBuffer = 256
+
+type logWatcher struct {
+	ch chan oplog.LogChunk
+}
+
+// logWatchers fans out the step log chunks to the watchers of the operation.
+type logWatchers struct {
+	mu       sync.RWMutex
+	watchers map[string]map[*logWatcher]struct{}
+}
+
+func newLogWatchers() *logWatchers {
+	return &logWatchers{watchers: make(map[string]map[*logWatcher]struct{})}
+}
+
+func (l *logWatchers) add(opID string) *logWatcher {
+	w := &logWatcher{ch: make(chan oplog.LogChunk, logWatcherBuffer)}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.watchers[opID] == nil {
+		l.watchers[opID] = make(map[*logWatcher]struct{})
+	}
+	l.watchers[opID][w] = struct{}{}
+	return w
+}
+
+func (l *logWatchers) remove(opID string, w *logWatcher) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.watchers[opID], w)
+	if len(l.watchers[opID]) == 0 {
+		delete(l.watchers, opID)
+	}
+	close(w.ch)
+}
+
+func (l *logWatchers) dispatch(chunk oplog.LogChunk) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for w := range l.watchers[chunk.OpID] {
+		select {
+		case w.ch <- chunk:
+		default:
+			logger.Warn("step log watcher is too slow, chunk dropped",
+				zap.String("operation", chunk.OpID), zap.String("step", chunk.StepID), zap.Int64("offset", chunk.Offset))
+		}
+	}
+}
+
+// operationLogSubject matches the step log chunks published by all agents.
+func (s *Service) operationLogSubject() string {
+	return fmt.Sprintf(service.OperationLogSubjectFormat, fmt.Sprintf(service.MsgSubjectFormat, "*", s.subjectSuffix))
+}
+
+func (s *Service) operationLogHandler(msg *nats.Msg) {
+	chunk := oplog.LogChunk{}
+	if err := json.Unmarshal(msg.Data, &chunk); err != nil {
+		logger.Error("unmarshal step log chunk error", zap.Error(err))
+		return
+	}
+	s.logWatchers.dispatch(chunk)
+}
+
+func (s *Service) WatchOperationLog(ctx context.Context, opID string) <-chan oplog.LogChunk {
+	w := s.logWatchers.add(opID)
+	go func() {
+		<-ctx.Done()
+		s.logWatchers.remove(opID, w)
+	}()
+	return w.ch
+}
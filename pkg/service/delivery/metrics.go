@@ -0,0 +1,125 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
+	compbasemetrics "k8s.io/component-base/metrics"
+
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
+)
+
+var (
+	mqDeliveryErrors = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "kc_server_mq_delivery_errors_total",
+			Help:           "Counter of messages the server failed to deliver to agents, broken out for method and reason.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"method", "reason"},
+	)
+
+	runningOperationsDesc = prometheus.NewDesc("kc_server_running_operations",
+		"Number of operations whose steps are being delivered by this server.", nil, nil)
+	stepStatusQueueDesc = prometheus.NewDesc("kc_server_step_status_queue_length",
+		"Number of step status updates waiting to be saved to operations.", nil, nil)
+
+	registerOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the delivery of messages to agents.
+func RegisterMetrics() {
+	registerOnce.Do(func() {
+		metrics.MustRegister(mqDeliveryErrors)
+	})
+}
+
+// QueueCollector returns the collector of the depth of the queues of operations.
+func (s *Service) QueueCollector() prometheus.Collector {
+	return &queueCollector{s: s}
+}
+
+type queueCollector struct {
+	s *Service
+}
+
+func (c *queueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- runningOperationsDesc
+	ch <- stepStatusQueueDesc
+}
+
+func (c *queueCollector) Collect(ch chan<- prometheus.Metric) {
+	running := 0
+	c.s.running.Range(func(key, value interface{}) bool {
+		running++
+		return true
+	})
+	ch <- prometheus.MustNewConstMetric(runningOperationsDesc, prometheus.GaugeValue, float64(running))
+	ch <- prometheus.MustNewConstMetric(stepStatusQueueDesc, prometheus.GaugeValue, float64(len(c.s.stepStatusChan)))
+}
+
+// instrumentMQ counts the errors of the messages sent through client.
+func instrumentMQ(client natsio.Interface) natsio.Interface {
+	return &instrumentedMQ{Interface: client}
+}
+
+type instrumentedMQ struct {
+	natsio.Interface
+}
+
+func recordDeliveryError(method string, err error) {
+	if err == nil {
+		return
+	}
+	reason := "error"
+	if errors.Is(err, nats.ErrTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		reason = "timeout"
+	}
+	mqDeliveryErrors.WithLabelValues(method, reason).Inc()
+}
+
+func (m *instrumentedMQ) Publish(msg *natsio.Msg) error {
+	err := m.Interface.Publish(msg)
+	recordDeliveryError("publish", err)
+	return err
+}
+
+func (m *instrumentedMQ) Request(msg *natsio.Msg, timeoutHandler natsio.TimeoutHandler) ([]byte, error) {
+	data, err := m.Interface.Request(msg, timeoutHandler)
+	recordDeliveryError("request", err)
+	return data, err
+}
+
+func (m *instrumentedMQ) RequestWithContext(ctx context.Context, msg *natsio.Msg) ([]byte, error) {
+	data, err := m.Interface.RequestWithContext(ctx, msg)
+	recordDeliveryError("request", err)
+	return data, err
+}
+
+func (m *instrumentedMQ) RequestAsync(msg *natsio.Msg, handler natsio.ReplyHandler, timeoutHandler natsio.TimeoutHandler) error {
+	err := m.Interface.RequestAsync(msg, handler, timeoutHandler)
+	recordDeliveryError("request_async", err)
+	return err
+}
@@ -0,0 +1,74 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"k8s.io/component-base/metrics/testutil"
+
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+type failingMQ struct {
+	natsio.Interface
+	err error
+}
+
+func (f *failingMQ) Publish(msg *natsio.Msg) error {
+	return f.err
+}
+
+func (f *failingMQ) RequestWithContext(ctx context.Context, msg *natsio.Msg) ([]byte, error) {
+	return nil, f.err
+}
+
+func deliveryErrors(t *testing.T, method, reason string) float64 {
+	v, err := testutil.GetCounterMetricValue(mqDeliveryErrors.WithLabelValues(method, reason))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestInstrumentMQ(t *testing.T) {
+	RegisterMetrics()
+
+	timeouts := deliveryErrors(t, "request", "timeout")
+	errs := deliveryErrors(t, "publish", "error")
+
+	client := instrumentMQ(&failingMQ{err: nats.ErrTimeout})
+	if _, err := client.RequestWithContext(context.TODO(), &natsio.Msg{}); !errors.Is(err, nats.ErrTimeout) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	client = instrumentMQ(&failingMQ{err: errors.New("connection closed")})
+	_ = client.Publish(&natsio.Msg{})
+	client = instrumentMQ(&failingMQ{})
+	_ = client.Publish(&natsio.Msg{})
+
+	if got := deliveryErrors(t, "request", "timeout") - timeouts; got != 1 {
+		t.Errorf("request timeouts = %v, want 1", got)
+	}
+	if got := deliveryErrors(t, "publish", "error") - errs; got != 1 {
+		t.Errorf("publish errors = %v, want 1", got)
+	}
+}
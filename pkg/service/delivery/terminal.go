@@ -0,0 +1,310 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+var _ service.Terminal = (*terminal)(nil)
+
+const (
+	openTerminalTimeout = 10 * time.Second
+	// terminalPingPeriod must stay below the idle timeout of the agent
+	terminalPingPeriod = 30 * time.Second
+	// terminalFrameSize bounds the data frames coalesced while the reader is busy
+	terminalFrameSize = 32 * 1024
+	// terminalPendingLimit bounds the output held for a session, the output beyond it is dropped
+	terminalPendingLimit = 1024 * 1024
+	// terminalStallTimeout is how long a reader may not take any output before its session is closed
+	terminalStallTimeout = 2 * time.Minute
+)
+
+// terminalDropped is written to the console in place of the dropped output.
+var terminalDropped = []byte("\r\n*** output dropped, the console falls behind ***\r\n")
+
+type terminal struct {
+	id      string
+	subject string
+	client  natsio.Interface
+	output  chan service.TerminalFrame
+	done    chan struct{}
+	once    sync.Once
+	onClose func()
+
+	// mu guards the output from the agent which is not yet passed to the reader
+	mu           sync.Mutex
+	pending      []service.TerminalFrame
+	pendingBytes int
+	dropped      bool
+	ended        bool
+	wake         chan struct{}
+}
+
+func newTerminal(id, subject string, client natsio.Interface, onClose func()) *terminal {
+	t := &terminal{
+		id:      id,
+		subject: subject,
+		client:  client,
+		output:  make(chan service.TerminalFrame, 64),
+		done:    make(chan struct{}),
+		onClose: onClose,
+		wake:    make(chan struct{}, 1),
+	}
+	go t.pump()
+	return t
+}
+
+func (t *terminal) ID() string {
+	return t.id
+}
+
+func (t *terminal) Output() <-chan service.TerminalFrame {
+	return t.output
+}
+
+func (t *terminal) Send(frame service.TerminalFrame) error {
+	select {
+	case <-t.done:
+		return fmt.Errorf("terminal session %s closed", t.id)
+	default:
+	}
+	return t.send(frame)
+}
+
+func (t *terminal) send(frame service.TerminalFrame) error {
+	frame.Session = t.id
+	data, err := json.Marshal(frame)
+	if err != nil {
+		return err
+	}
+	return t.client.Publish(&natsio.Msg{Subject: t.subject, Data: data})
+}
+
+// release frees a session the agent never opened.
+func (t *terminal) release() {
+	t.once.Do(func() {
+		close(t.done)
+		t.onClose()
+	})
+}
+
+func (t *terminal) Close() {
+	t.once.Do(func() {
+		close(t.done)
+		if err := t.send(service.TerminalFrame{Type: service.TerminalClose}); err != nil {
+			logger.Warn("send terminal close frame error", zap.String("session", t.id), zap.Error(err))
+		}
+		t.onClose()
+	})
+}
+
+// deliver queues a frame from the agent for the reader of the session. It never blocks, the subscription
+// is shared by every session of the server. Data frames are coalesced while the reader is busy, and the
+// output beyond terminalPendingLimit is dropped and replaced by a marker.
+func (t *terminal) deliver(frame service.TerminalFrame) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.ended {
+		return
+	}
+	if frame.Type != service.TerminalData {
+		if frame.Type == service.TerminalClose {
+			t.ended = true
+			if t.dropped {
+				t.queue(terminalDropped)
+			}
+		}
+		t.pending = append(t.pending, frame)
+		t.notify()
+		return
+	}
+	if t.pendingBytes+len(frame.Data) > terminalPendingLimit {
+		if !t.dropped {
+			logger.Warn("terminal reader falls behind, drop output", zap.String("session", t.id))
+			t.dropped = true
+		}
+		return
+	}
+	if t.dropped {
+		t.dropped = false
+		t.queue(terminalDropped)
+	}
+	t.queue(frame.Data)
+	t.notify()
+}
+
+// queue appends data to the pending output, mu must be held.
+func (t *terminal) queue(data []byte) {
+	t.pendingBytes += len(data)
+	if n := len(t.pending); n > 0 {
+		last := &t.pending[n-1]
+		if last.Type == service.TerminalData && len(last.Data)+len(data) <= terminalFrameSize {
+			last.Data = append(last.Data, data...)
+			return
+		}
+	}
+	t.pending = append(t.pending, service.TerminalFrame{
+		Session: t.id,
+		Type:    service.TerminalData,
+		Data:    append([]byte(nil), data...),
+	})
+}
+
+func (t *terminal) notify() {
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump passes the pending output to the reader. The session is closed when the reader does not take
+// any output for terminalStallTimeout, the output is closed after the close frame of the agent.
+func (t *terminal) pump() {
+	defer close(t.output)
+	stall := time.NewTimer(terminalStallTimeout)
+	defer stall.Stop()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-t.wake:
+		}
+		t.mu.Lock()
+		frames := t.pending
+		t.pending = nil
+		t.pendingBytes = 0
+		t.mu.Unlock()
+		for _, frame := range frames {
+			if !stall.Stop() {
+				select {
+				case <-stall.C:
+				default:
+				}
+			}
+			stall.Reset(terminalStallTimeout)
+			select {
+			case t.output <- frame:
+			case <-t.done:
+				return
+			case <-stall.C:
+				logger.Warn("terminal reader stalls, close the session", zap.String("session", t.id))
+				t.Close()
+				return
+			}
+			if frame.Type == service.TerminalClose {
+				return
+			}
+		}
+	}
+}
+
+func (t *terminal) keepalive() {
+	ticker := time.NewTicker(terminalPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+			if err := t.send(service.TerminalFrame{Type: service.TerminalPing}); err != nil {
+				logger.Warn("send terminal ping error", zap.String("session", t.id), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (s *Service) terminalSubject() string {
+	return fmt.Sprintf(service.TerminalSubjectFormat, fmt.Sprintf(service.MsgSubjectFormat, s.serverID, s.subjectSuffix))
+}
+
+// terminalOutputHandler dispatches the frames agents send to the sessions opened by this server.
+func (s *Service) terminalOutputHandler(msg *nats.Msg) {
+	frame := service.TerminalFrame{}
+	if err := json.Unmarshal(msg.Data, &frame); err != nil {
+		logger.Error("unmarshal terminal frame error", zap.Error(err))
+		return
+	}
+	v, ok := s.terminals.Load(frame.Session)
+	if !ok {
+		return
+	}
+	v.(*terminal).deliver(frame)
+}
+
+func (s *Service) OpenTerminal(ctx context.Context, toNode string, rows, cols uint16) (service.Terminal, error) {
+	id := uuid.New().String()
+	data, err := json.Marshal(service.TerminalOpen{
+		Session:      id,
+		ReplySubject: s.terminalSubject(),
+		Rows:         rows,
+		Cols:         cols,
+	})
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(service.MsgPayload{
+		Op:                service.OperationOpenTerminal,
+		OperationIdentity: id,
+		Step:              v1.Step{Timeout: metav1.Duration{Duration: openTerminalTimeout}},
+		Data:              data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	t := newTerminal(id, fmt.Sprintf(service.TerminalSubjectFormat, fmt.Sprintf(service.MsgSubjectFormat, toNode, s.subjectSuffix)),
+		s.client, func() { s.terminals.Delete(id) })
+	// register before the agent starts the shell, so that no output is lost
+	s.terminals.Store(id, t)
+	ctx, cancel := context.WithTimeout(ctx, openTerminalTimeout)
+	defer cancel()
+	reply, err := s.client.RequestWithContext(ctx, &natsio.Msg{
+		Subject: fmt.Sprintf(service.MsgSubjectFormat, toNode, s.subjectSuffix),
+		Data:    payload,
+	})
+	if err != nil {
+		t.release()
+		return nil, err
+	}
+	resp := &service.CommonReply{}
+	if err = json.Unmarshal(reply, resp); err != nil {
+		t.release()
+		return nil, err
+	}
+	if resp.Error != nil {
+		t.release()
+		return nil, resp.Error
+	}
+	go t.keepalive()
+	return t, nil
+}
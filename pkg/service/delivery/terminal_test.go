@@ -0,0 +1,108 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package delivery
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+// publishCounter counts the published messages, the other methods of the client are not used by terminals.
+type publishCounter struct {
+	natsio.Interface
+	published int
+}
+
+func (c *publishCounter) Publish(_ *natsio.Msg) error {
+	c.published++
+	return nil
+}
+
+// deliverAll delivers the frames and fails when the shared subscription would be blocked.
+func deliverAll(t *testing.T, term *terminal, frames ...service.TerminalFrame) {
+	delivered := make(chan struct{})
+	go func() {
+		defer close(delivered)
+		for _, frame := range frames {
+			term.deliver(frame)
+		}
+	}()
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deliver blocks on a session whose reader falls behind")
+	}
+}
+
+// readAll reads the output of the session until it is closed.
+func readAll(t *testing.T, term *terminal) []byte {
+	var data []byte
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case frame, ok := <-term.Output():
+			if !ok {
+				return data
+			}
+			data = append(data, frame.Data...)
+		case <-timeout:
+			t.Fatal("terminal output is not closed after the close frame")
+		}
+	}
+}
+
+func dataFrames(n, size int) []service.TerminalFrame {
+	frames := make([]service.TerminalFrame, 0, n+1)
+	for i := 0; i < n; i++ {
+		frames = append(frames, service.TerminalFrame{Type: service.TerminalData, Data: bytes.Repeat([]byte("x"), size)})
+	}
+	return append(frames, service.TerminalFrame{Type: service.TerminalClose})
+}
+
+func TestTerminalDeliverBurst(t *testing.T) {
+	client := &publishCounter{}
+	closed := false
+	term := newTerminal("session", "subject", client, func() { closed = true })
+	deliverAll(t, term, dataFrames(1000, 16)...)
+	data := readAll(t, term)
+	if closed || client.published != 0 {
+		t.Errorf("session is closed on a burst, closed %v, published %d frames", closed, client.published)
+	}
+	if len(data) != 1000*16 || bytes.Contains(data, terminalDropped) {
+		t.Errorf("read %d bytes, want the whole burst of %d bytes", len(data), 1000*16)
+	}
+}
+
+func TestTerminalDeliverDrop(t *testing.T) {
+	client := &publishCounter{}
+	closed := false
+	term := newTerminal("session", "subject", client, func() { closed = true })
+	deliverAll(t, term, dataFrames(200, terminalFrameSize)...)
+	data := readAll(t, term)
+	if closed || client.published != 0 {
+		t.Errorf("session is closed on dropped output, closed %v, published %d frames", closed, client.published)
+	}
+	if !bytes.HasSuffix(data, terminalDropped) || len(data) >= 200*terminalFrameSize {
+		t.Errorf("read %d bytes, want the output beyond the limit replaced by the marker", len(data))
+	}
+}
@@ -23,6 +23,7 @@ import (
 
 	"github.com/kubeclipper/kubeclipper/pkg/errors"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
 )
 
 type Operation int32
@@ -40,6 +41,16 @@ const (
 	OperationBackup
 	OperationRecovery
 	OperationRunCmd
+	// OperationCancelTask stops the task the agent runs for an operation
+	OperationCancelTask
+	// OperationReportStepProgress reports the phase reached by a running step
+	OperationReportStepProgress
+	// OperationReconfigure applies the agent config changes pushed by the server
+	OperationReconfigure
+	// OperationOpenTerminal starts a shell session on the agent
+	OperationOpenTerminal
+	// OperationBrowseFile lists directories and reads files on the agent node
+	OperationBrowseFile
 )
 
 const (
@@ -50,6 +61,13 @@ const (
 	MsgDeleteBackupFormat = "%s:%s:%s"
 	// downloadDir:filename:id
 	MsgStepRecoveryFormat = "%s:%s:%s"
+	// TerminalSubjectFormat is followed by the subject of the receiver of terminal frames
+	TerminalSubjectFormat = "terminal.%s"
+	// TerminalLogStep is the step the transcript of a terminal session is logged to,
+	// the operation of the log is the session.
+	TerminalLogStep = "terminal"
+	// OperationLogSubjectFormat is followed by the subject of the agent publishing step log chunks
+	OperationLogSubjectFormat = "oplog.%s"
 )
 
 type NodeStatusPayload struct {
@@ -71,6 +89,102 @@ type MsgPayload struct {
 	Retry             bool      `json:"retry,omitempty"`
 	Step              v1.Step   `json:"step,omitempty"`
 	Cmds              []string  `json:"cmds,omitempty"`
+	Data              []byte    `json:"data,omitempty"`
+	// TraceContext continues the trace of the server on agent.
+	TraceContext tracing.Carrier `json:"traceContext,omitempty"`
+}
+
+// AgentConfigPatch holds the agent settings the server can change at runtime,
+// empty fields are left untouched.
+type AgentConfigPatch struct {
+	// MQServers replaces the message queue addresses, e.g. after the servers are scaled out.
+	MQServers []string `json:"mqServers,omitempty"`
+	// LogLevel is one of debug, info, warn and error.
+	LogLevel string `json:"logLevel,omitempty"`
+	// OpLogDir is the directory operation logs are written to.
+	OpLogDir string `json:"oplogDir,omitempty"`
+	// StaticServerAddress is where the agent downloads packages from.
+	StaticServerAddress string `json:"staticServerAddress,omitempty"`
+}
+
+func (p *AgentConfigPatch) IsEmpty() bool {
+	return len(p.MQServers) == 0 && p.LogLevel == "" && p.OpLogDir == "" && p.StaticServerAddress == ""
+}
+
+// StepProgressPayload is published by an agent when a running step enters a new phase.
+type StepProgressPayload struct {
+	OperationIdentity string          `json:"operationIdentity"`
+	Progress          v1.StepProgress `json:"progress"`
+}
+
+// TerminalOpen is the payload of OperationOpenTerminal.
+type TerminalOpen struct {
+	Session string `json:"session"`
+	// ReplySubject receives the output frames of the session.
+	ReplySubject string `json:"replySubject"`
+	Rows         uint16 `json:"rows"`
+	Cols         uint16 `json:"cols"`
+}
+
+type TerminalFrameType string
+
+const (
+	TerminalData   TerminalFrameType = "data"
+	TerminalResize TerminalFrameType = "resize"
+	// TerminalPing keeps the session open, agents close sessions not hearing from the server.
+	TerminalPing  TerminalFrameType = "ping"
+	TerminalClose TerminalFrameType = "close"
+)
+
+// TerminalFrame is a message of a terminal session in either direction.
+type TerminalFrame struct {
+	Session string            `json:"session"`
+	Type    TerminalFrameType `json:"type"`
+	Data    []byte            `json:"data,omitempty"`
+	Rows    uint16            `json:"rows,omitempty"`
+	Cols    uint16            `json:"cols,omitempty"`
+}
+
+type FileAction string
+
+const (
+	FileList FileAction = "list"
+	// FileTail returns the last lines of a file.
+	FileTail FileAction = "tail"
+	// FileRead returns a range of a file, files are downloaded a range at a time.
+	FileRead FileAction = "read"
+)
+
+const (
+	// MaxFileReadLength bounds the data of a single reply, which has to fit into a message.
+	MaxFileReadLength = 256 * 1024
+	MaxFileTailLines  = 5000
+)
+
+// FileRequest is the payload of OperationBrowseFile.
+type FileRequest struct {
+	Action FileAction `json:"action"`
+	// Path is absolute.
+	Path   string `json:"path"`
+	Lines  int    `json:"lines,omitempty"`
+	Offset int64  `json:"offset,omitempty"`
+	Length int64  `json:"length,omitempty"`
+}
+
+type FileInfo struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	Mode    string    `json:"mode"`
+	ModTime time.Time `json:"modTime"`
+	IsDir   bool      `json:"isDir"`
+}
+
+type FileResponse struct {
+	Path string `json:"path"`
+	// Size is the size of the file read or tailed.
+	Size  int64      `json:"size"`
+	Files []FileInfo `json:"files,omitempty"`
+	Data  []byte     `json:"data,omitempty"`
 }
 
 type LogOperation struct {
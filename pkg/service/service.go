@@ -42,9 +42,30 @@ type Interface interface {
 
 type IDelivery interface {
 	DeliverLogRequest(ctx context.Context, operation *LogOperation) (oplog.LogContentResponse, error) // request & response synchronously.
+	CancelOperation(ctx context.Context, operation *v1.Operation) error
+	// DeliverAgentConfig pushes config changes to the agent, which saves and reloads them.
+	DeliverAgentConfig(ctx context.Context, toNode string, patch *AgentConfigPatch, timeout time.Duration) error
+	// OpenTerminal starts a shell session on the node, frames are exchanged over the message queue.
+	OpenTerminal(ctx context.Context, toNode string, rows, cols uint16) (Terminal, error)
+	// BrowseFile lists a directory or reads a file on the node.
+	BrowseFile(ctx context.Context, toNode string, req *FileRequest) (*FileResponse, error)
+	// WatchOperationLog returns the step log chunks published by the agents running the operation,
+	// the channel is closed once ctx is done.
+	WatchOperationLog(ctx context.Context, opID string) <-chan oplog.LogChunk
 	CmdDelivery
 }
 
+// Terminal is a shell session opened on a node.
+type Terminal interface {
+	// ID is the session, the transcript is logged to the operation of the same name.
+	ID() string
+	// Send writes data and resize frames to the session.
+	Send(frame TerminalFrame) error
+	// Output returns the frames from the node, it is closed with the session.
+	Output() <-chan TerminalFrame
+	Close()
+}
+
 type CmdDelivery interface {
 	DeliverTaskOperation(ctx context.Context, operation *v1.Operation, opts *Options) error
 	DeliverCmd(ctx context.Context, toNode string, cmds []string, timeout time.Duration) ([]byte, error)
@@ -0,0 +1,107 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+const (
+	ContainerRuntimeDocker  = "docker"
+	ContainerRuntimeNerdctl = "nerdctl"
+)
+
+// ContainerExecutorOptions configures running shell commands in ephemeral containers.
+type ContainerExecutorOptions struct {
+	Enabled bool `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	// Runtime is the container cli used to run the container, docker or nerdctl.
+	Runtime string `json:"runtime,omitempty" yaml:"runtime,omitempty" mapstructure:"runtime"`
+	// Namespace is the containerd namespace used by nerdctl.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty" mapstructure:"namespace"`
+}
+
+func NewContainerExecutorOptions() *ContainerExecutorOptions {
+	return &ContainerExecutorOptions{
+		Enabled:   false,
+		Runtime:   ContainerRuntimeDocker,
+		Namespace: "k8s.io",
+	}
+}
+
+func (o *ContainerExecutorOptions) Validate() []error {
+	var errs []error
+	if o == nil || !o.Enabled {
+		return errs
+	}
+	if o.Runtime != ContainerRuntimeDocker && o.Runtime != ContainerRuntimeNerdctl {
+		errs = append(errs, fmt.Errorf("unsupported container executor runtime %q", o.Runtime))
+	}
+	return errs
+}
+
+func (o *ContainerExecutorOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&o.Enabled, "container-executor-enabled", o.Enabled, "run shell commands which request a container in ephemeral containers")
+	fs.StringVar(&o.Runtime, "container-executor-runtime", o.Runtime, "container cli used to run ephemeral containers, docker or nerdctl")
+	fs.StringVar(&o.Namespace, "container-executor-namespace", o.Namespace, "containerd namespace used by nerdctl")
+}
+
+// wrapContainerCommand converts shell command to a command which runs it in an ephemeral container,
+// the container shares host network and is removed after exit.
+func wrapContainerCommand(opts *ContainerExecutorOptions, c *v1.ContainerExecution, cmds []string) ([]string, error) {
+	if c.Image == "" {
+		return nil, fmt.Errorf("container image must be specified")
+	}
+	if len(cmds) == 0 {
+		return nil, fmt.Errorf("container command must be specified")
+	}
+	args := []string{opts.Runtime}
+	if opts.Runtime == ContainerRuntimeNerdctl && opts.Namespace != "" {
+		args = append(args, "--namespace", opts.Namespace)
+	}
+	args = append(args, "run", "--rm", "--network", "host")
+	if c.Privileged {
+		args = append(args, "--privileged")
+	}
+	for _, e := range c.Env {
+		args = append(args, "-e", e)
+	}
+	for _, m := range c.Mounts {
+		if m.HostPath == "" {
+			return nil, fmt.Errorf("mount host path must be specified")
+		}
+		containerPath := m.ContainerPath
+		if containerPath == "" {
+			containerPath = m.HostPath
+		}
+		mount := []string{m.HostPath, containerPath}
+		if m.ReadOnly {
+			mount = append(mount, "ro")
+		}
+		args = append(args, "-v", strings.Join(mount, ":"))
+	}
+	// override image entrypoint, so that command behaves the same as it runs on host.
+	args = append(args, "--entrypoint", cmds[0], c.Image)
+	args = append(args, cmds[1:]...)
+	return args, nil
+}
@@ -0,0 +1,131 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func Test_wrapContainerCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      *ContainerExecutorOptions
+		container *v1.ContainerExecution
+		cmds      []string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name: "docker with mounts",
+			opts: &ContainerExecutorOptions{Enabled: true, Runtime: ContainerRuntimeDocker},
+			container: &v1.ContainerExecution{
+				Image:      "etcd:3.5.0",
+				Privileged: true,
+				Mounts: []v1.HostMount{
+					{HostPath: "/etc/kubernetes/pki", ReadOnly: true},
+					{HostPath: "/var/lib/etcd", ContainerPath: "/data"},
+				},
+			},
+			cmds: []string{"etcdctl", "endpoint", "health"},
+			want: []string{"docker", "run", "--rm", "--network", "host", "--privileged",
+				"-v", "/etc/kubernetes/pki:/etc/kubernetes/pki:ro", "-v", "/var/lib/etcd:/data",
+				"--entrypoint", "etcdctl", "etcd:3.5.0", "endpoint", "health"},
+		},
+		{
+			name:      "nerdctl with namespace",
+			opts:      &ContainerExecutorOptions{Enabled: true, Runtime: ContainerRuntimeNerdctl, Namespace: "k8s.io"},
+			container: &v1.ContainerExecution{Image: "kubectl:v1.23.6", Env: []string{"KUBECONFIG=/etc/kubernetes/admin.conf"}},
+			cmds:      []string{"kubectl", "get", "nodes"},
+			want: []string{"nerdctl", "--namespace", "k8s.io", "run", "--rm", "--network", "host",
+				"-e", "KUBECONFIG=/etc/kubernetes/admin.conf",
+				"--entrypoint", "kubectl", "kubectl:v1.23.6", "get", "nodes"},
+		},
+		{
+			name:      "empty image",
+			opts:      NewContainerExecutorOptions(),
+			container: &v1.ContainerExecution{},
+			cmds:      []string{"kubectl"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wrapContainerCommand(tt.opts, tt.container, tt.cmds)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("wrapContainerCommand() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("wrapContainerCommand() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestService_shellCommand(t *testing.T) {
+	container := &v1.ContainerExecution{Image: "kubectl:v1.23.6"}
+	tests := []struct {
+		name     string
+		executor *ContainerExecutorOptions
+		command  v1.Command
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "host command",
+			executor: NewContainerExecutorOptions(),
+			command:  v1.Command{ShellCommand: []string{"kubectl", "get", "nodes"}},
+			want:     []string{"kubectl", "get", "nodes"},
+		},
+		{
+			name:     "container command with executor disabled",
+			executor: NewContainerExecutorOptions(),
+			command:  v1.Command{ShellCommand: []string{"kubectl", "get", "nodes"}, Container: container},
+			wantErr:  true,
+		},
+		{
+			name:    "container command without executor",
+			command: v1.Command{ShellCommand: []string{"kubectl", "get", "nodes"}, Container: container},
+			wantErr: true,
+		},
+		{
+			name:     "container command with executor enabled",
+			executor: &ContainerExecutorOptions{Enabled: true, Runtime: ContainerRuntimeDocker},
+			command:  v1.Command{ShellCommand: []string{"kubectl", "get", "nodes"}, Container: container},
+			want: []string{"docker", "run", "--rm", "--network", "host",
+				"--entrypoint", "kubectl", "kubectl:v1.23.6", "get", "nodes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Service{containerExecutor: tt.executor}
+			got, err := s.shellCommand(&tt.command)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("shellCommand() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shellCommand() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
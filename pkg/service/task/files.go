@@ -0,0 +1,214 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+const defaultFileTailLines = 100
+
+func browseFile(payload *service.MsgPayload) ([]byte, *errors.StatusError) {
+	errMsg := "browse file error"
+	req := &service.FileRequest{}
+	if err := json.Unmarshal(payload.Data, req); err != nil {
+		return nil, doStatusError(errMsg, "unmarshal file request error", errors.Unmarshal, 500, err)
+	}
+	if !filepath.IsAbs(req.Path) {
+		return nil, doStatusError(errMsg, "invalid path", errors.BrowseFile, 400, fmt.Errorf("path %q is not absolute", req.Path))
+	}
+	req.Path = filepath.Clean(req.Path)
+	var (
+		resp *service.FileResponse
+		err  error
+	)
+	switch req.Action {
+	case service.FileList:
+		resp, err = listDir(req.Path)
+	case service.FileTail:
+		resp, err = tailFile(req.Path, req.Lines)
+	case service.FileRead:
+		resp, err = readFile(req.Path, req.Offset, req.Length)
+	default:
+		err = fmt.Errorf("unsupported file action %q", req.Action)
+	}
+	if err != nil {
+		code := int32(500)
+		if os.IsNotExist(err) {
+			code = 404
+		} else if _, ok := err.(badFileRequest); ok {
+			code = 400
+		}
+		return nil, doStatusError(errMsg, errMsg, errors.BrowseFile, code, err)
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return nil, doStatusError(errMsg, "marshal file response error", errors.Marshal, 500, err)
+	}
+	return data, nil
+}
+
+// badFileRequest is a request the path does not fit, e.g. reading a directory.
+type badFileRequest string
+
+func (e badFileRequest) Error() string {
+	return string(e)
+}
+
+func listDir(path string) (*service.FileResponse, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	resp := &service.FileResponse{Path: path, Files: make([]service.FileInfo, 0, len(entries))}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			// removed since read
+			continue
+		}
+		resp.Files = append(resp.Files, service.FileInfo{
+			Name:    entry.Name(),
+			Size:    info.Size(),
+			Mode:    info.Mode().String(),
+			ModTime: info.ModTime(),
+			IsDir:   info.IsDir(),
+		})
+	}
+	sort.Slice(resp.Files, func(i, j int) bool {
+		return resp.Files[i].Name < resp.Files[j].Name
+	})
+	return resp, nil
+}
+
+// openRegularFile opens the file after checking it is a regular one, opening a fifo or a device
+// may block the agent forever.
+func openRegularFile(path string) (*os.File, int64, error) {
+	before, err := os.Stat(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !before.Mode().IsRegular() {
+		return nil, 0, badFileRequest(fmt.Sprintf("%s is not a regular file", path))
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, 0, err
+	}
+	if !os.SameFile(before, info) {
+		_ = f.Close()
+		return nil, 0, fmt.Errorf("%s is replaced while being opened", path)
+	}
+	return f, info.Size(), nil
+}
+
+// tailFile returns the last lines of the file, at most MaxFileReadLength bytes of them.
+func tailFile(path string, lines int) (*service.FileResponse, error) {
+	if lines <= 0 {
+		lines = defaultFileTailLines
+	}
+	if lines > service.MaxFileTailLines {
+		lines = service.MaxFileTailLines
+	}
+	f, size, err := openRegularFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	offset := size - service.MaxFileReadLength
+	if offset < 0 {
+		offset = 0
+	}
+	data := make([]byte, size-offset)
+	if _, err = f.ReadAt(data, offset); err != nil && err != io.EOF {
+		return nil, err
+	}
+	// the read window starts mid-line unless the byte before it ends a line
+	midLine := false
+	if offset > 0 {
+		prev := make([]byte, 1)
+		if _, err = f.ReadAt(prev, offset-1); err != nil {
+			return nil, err
+		}
+		midLine = prev[0] != '\n'
+	}
+	// a trailing newline ends the last line rather than starting another one
+	end := len(data)
+	if end > 0 && data[end-1] == '\n' {
+		end--
+	}
+	start := -1
+	for n := 0; n < lines; n++ {
+		i := bytes.LastIndexByte(data[:end], '\n')
+		if i < 0 {
+			switch {
+			case !midLine:
+				// the first line of the window is a whole one, e.g. the file has fewer lines
+				start = -1
+			case n == 0:
+				// the only line of the window is cut by the read limit
+				start = len(data) - 1
+			}
+			// otherwise the first line is cut by the read limit and dropped
+			break
+		}
+		start, end = i, i
+	}
+	return &service.FileResponse{Path: path, Size: size, Data: data[start+1:]}, nil
+}
+
+func readFile(path string, offset, length int64) (*service.FileResponse, error) {
+	if offset < 0 {
+		return nil, badFileRequest(fmt.Sprintf("invalid offset %d", offset))
+	}
+	if length <= 0 || length > service.MaxFileReadLength {
+		length = service.MaxFileReadLength
+	}
+	f, size, err := openRegularFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if offset > size {
+		offset = size
+	}
+	if offset+length > size {
+		length = size - offset
+	}
+	data := make([]byte, length)
+	n, err := f.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return &service.FileResponse{Path: path, Size: size, Data: data[:n]}, nil
+}
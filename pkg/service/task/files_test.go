@@ -0,0 +1,115 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+)
+
+func Test_tailFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "messages")
+	if err := os.WriteFile(path, []byte("a\nb\nc\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		lines int
+		want  string
+	}{
+		{name: "last line", lines: 1, want: "c\n"},
+		{name: "last two lines", lines: 2, want: "b\nc\n"},
+		{name: "more lines than the file", lines: 10, want: "a\nb\nc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tailFile(path, tt.lines)
+			if err != nil {
+				t.Fatalf("tailFile() error = %v", err)
+			}
+			if string(resp.Data) != tt.want {
+				t.Errorf("tailFile() = %q, want %q", resp.Data, tt.want)
+			}
+		})
+	}
+}
+
+func Test_tailFileCutLine(t *testing.T) {
+	dir := t.TempDir()
+	line := strings.Repeat("x", 1000) + "\n"
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "window starts mid-line",
+			content: "head" + strings.Repeat(line, service.MaxFileReadLength/len(line)) + "tail\n",
+			want:    strings.Repeat(line, 2) + "tail\n",
+		},
+		{
+			name:    "window starts at a line",
+			content: "head\n" + "w\n" + strings.Repeat("y", service.MaxFileReadLength-3) + "\n",
+			want:    "w\n" + strings.Repeat("y", service.MaxFileReadLength-3) + "\n",
+		},
+		{
+			name:    "line longer than the window",
+			content: strings.Repeat("x", service.MaxFileReadLength+1) + "\n",
+			want:    "",
+		},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("messages-%d", i))
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := tailFile(path, 3)
+			if err != nil {
+				t.Fatalf("tailFile() error = %v", err)
+			}
+			if string(resp.Data) != tt.want {
+				t.Errorf("tailFile() = %d bytes, want %d bytes", len(resp.Data), len(tt.want))
+			}
+		})
+	}
+}
+
+func Test_readFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "kubelet.log")
+	if err := os.WriteFile(path, []byte("0123456789"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := readFile(path, 8, 100)
+	if err != nil {
+		t.Fatalf("readFile() error = %v", err)
+	}
+	if string(resp.Data) != "89" || resp.Size != 10 {
+		t.Errorf("readFile() = %q of %d, want %q of %d", resp.Data, resp.Size, "89", 10)
+	}
+	if _, err = readFile(dir, 0, 0); err == nil {
+		t.Errorf("readFile() of a directory should fail")
+	}
+}
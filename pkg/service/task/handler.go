@@ -25,31 +25,55 @@ import (
 	"time"
 
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kubeclipper/kubeclipper/pkg/oplog"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/faultinject"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+	"github.com/kubeclipper/kubeclipper/pkg/tracing"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/powershell"
 )
 
-func (s *Service) runTaskStep(ctx context.Context, payload *service.MsgPayload, subject string) ([]byte, *errors.StatusError) {
+func (s *Service) runTaskStep(ctx context.Context, payload *service.MsgPayload, subject string) (reply []byte, statusErr *errors.StatusError) {
+	ctx, span := tracing.Start(ctx, "run step "+payload.Step.Name, attribute.String("operation", payload.OperationIdentity),
+		attribute.String("step.id", payload.Step.ID), attribute.String("node", s.AgentID), attribute.Bool("retry", payload.Retry))
+	defer func(start time.Time) {
+		observeStep(payload.Step.Name, start, statusErr != nil)
+		if statusErr != nil {
+			span.SetStatus(codes.Error, statusErr.Message)
+		}
+		span.End()
+	}(time.Now())
 	// stepKey to distinguish which step the log file belongs to
 	stepKey := fmt.Sprintf("%s-%s", payload.Step.ID, payload.Step.Name)
 	ctx = component.WithOperationID(ctx, payload.OperationIdentity) // put operation ID into context
 	ctx = component.WithStepID(ctx, stepKey)                        // put step ID into context
-	ctx = component.WithOplog(ctx, s.oplog)                         // put operation log object into context
+	ctx = component.WithOplog(ctx, s.getOplog())                    // put operation log object into context
+	ctx = component.WithProgressReporter(ctx, s.stepProgressReporter(payload.OperationIdentity, payload.Step.ID))
+
+	stopStream := make(chan struct{})
+	streamed := s.streamStepLog(payload.OperationIdentity, payload.Step.ID, stepKey, stopStream)
+	defer func() {
+		close(stopStream)
+		<-streamed
+	}()
 
 	var entry string
 	// truncate step log file
 	if payload.Retry {
 		entry = fmt.Sprintf("\n\n--------------------> steps retry %s <--------------------\n\n", time.Now().Format(time.RFC3339))
 	}
-	if err := s.oplog.CreateStepLogFileAndAppend(payload.OperationIdentity, stepKey, []byte(entry)); err != nil {
+	if err := s.getOplog().CreateStepLogFileAndAppend(payload.OperationIdentity, stepKey, []byte(entry)); err != nil {
 		// log errors do not affect the execution of main processes
 		logger.Error("create operation step log file failed: "+err.Error(),
 			zap.String("operation", payload.OperationIdentity),
@@ -65,10 +89,19 @@ func (s *Service) runTaskStep(ctx context.Context, payload *service.MsgPayload,
 	cmds = append(cmds, payload.Step.AfterRunCommands...)
 	var replyData []byte
 	for _, c := range cmds {
+		if ctx.Err() == context.Canceled {
+			errMsg := "run step canceled"
+			return nil, doStatusError(errMsg, errMsg, errors.ShellCommand, 500, ctx.Err())
+		}
 		switch c.Type {
 		case v1.CommandShell:
-			logger.Debug("run shell command", zap.Strings("cmd", c.ShellCommand))
-			if err := runShellCommand(ctx, c.ShellCommand, payload.DryRun); err != nil {
+			shellCommand, err := s.shellCommand(&c)
+			if err != nil {
+				errMsg := "run shell command error"
+				return nil, doStatusError(errMsg, errMsg, errors.ShellCommand, 500, err)
+			}
+			logger.Debug("run shell command", zap.Strings("cmd", shellCommand))
+			if err := runShellCommand(ctx, shellCommand, payload.DryRun); err != nil {
 				errMsg := "run shell command error"
 				return nil, doStatusError(errMsg, errMsg, errors.ShellCommand, 500, err)
 			}
@@ -85,6 +118,11 @@ func (s *Service) runTaskStep(ctx context.Context, payload *service.MsgPayload,
 			if statusError := runTemplateRenderCommand(ctx, c.Template, payload.DryRun); statusError != nil {
 				return nil, statusError
 			}
+		case v1.CommandPowerShell:
+			if err := runPowerShellCommand(ctx, c.PowerShell, payload.DryRun); err != nil {
+				errMsg := "run powershell command error"
+				return nil, doStatusError(errMsg, errMsg, errors.ShellCommand, 500, err)
+			}
 		}
 	}
 	return replyData, nil
@@ -105,7 +143,7 @@ func (s *Service) taskHandler(msg *nats.Msg) {
 	}
 	logger.Debug("in coming task payload", zap.Int("operation", int(payload.Op)),
 		zap.String("step", payload.Step.Name), zap.ByteString("lastResponse", payload.LastTaskReply), zap.Duration("timeout", payload.Step.Timeout.Duration))
-	ctx, cancel := context.WithTimeout(context.TODO(), payload.Step.Timeout.Duration)
+	ctx, cancel := context.WithTimeout(tracing.Extract(context.TODO(), payload.TraceContext), payload.Step.Timeout.Duration)
 	defer cancel()
 	var statusError *errors.StatusError
 
@@ -133,7 +171,7 @@ func (s *Service) taskHandler(msg *nats.Msg) {
 			statusError = doStatusError(errMsg, "parse step log message error", errors.StepLog, 500, err)
 			return
 		}
-		content, deliverySize, logSize, err := s.oplog.GetStepLogContent(req.OpID, req.StepID, req.Offset, req.Length)
+		content, deliverySize, logSize, err := s.getOplog().GetStepLogContent(req.OpID, req.StepID, req.Offset, req.Length)
 		if err != nil {
 			logger.Error("read log file error", zap.Error(err))
 			statusError = doStatusError(errMsg, "read log file error", errors.StepLog, 500, err)
@@ -149,8 +187,48 @@ func (s *Service) taskHandler(msg *nats.Msg) {
 			statusError = doStatusError(errMsg, "marshal log content response error", errors.StepLog, 500, err)
 			return
 		}
+	case service.OperationCancelTask:
+		if cancelTask, ok := s.runningTasks.LoadAndDelete(payload.OperationIdentity); ok {
+			logger.Info("cancel running task", zap.String("operation", payload.OperationIdentity))
+			cancelTask.(context.CancelFunc)()
+		}
+		responseMessage(msg, nil, nil)
+	case service.OperationReconfigure:
+		errMsg := "reconfigure agent error"
+		if s.reconfigure == nil {
+			responseMessage(msg, nil, doStatusError(errMsg, errMsg, errors.AgentConfig, 500, fmt.Errorf("agent does not support reconfiguration")))
+			return
+		}
+		patch := &service.AgentConfigPatch{}
+		if err := json.Unmarshal(payload.Data, patch); err != nil {
+			responseMessage(msg, nil, doStatusError(errMsg, "unmarshal agent config patch error", errors.Unmarshal, 500, err))
+			return
+		}
+		if err := s.reconfigure(ctx, patch); err != nil {
+			logger.Error("reconfigure agent failed", zap.Error(err))
+			responseMessage(msg, nil, doStatusError(errMsg, errMsg, errors.AgentConfig, 500, err))
+			return
+		}
+		responseMessage(msg, nil, nil)
+	case service.OperationOpenTerminal:
+		responseMessage(msg, nil, s.openTerminal(payload))
+	case service.OperationBrowseFile:
+		data, statusErr := browseFile(payload)
+		responseMessage(msg, data, statusErr)
 	case service.OperationRunTask:
 		var replyData []byte
+		s.runningTasks.Store(payload.OperationIdentity, cancel)
+		defer s.runningTasks.Delete(payload.OperationIdentity)
+		duplicate, err := s.faults.Inject(ctx, faultinject.TargetStep, payload.Step.Name)
+		if err != nil {
+			errMsg := "run task step error"
+			responseMessage(msg, nil, doStatusError(errMsg, errMsg, errors.ShellCommand, 500, err))
+			return
+		}
+		if duplicate {
+			// a step may be delivered twice, it must tolerate running again
+			_, _ = s.runTaskStep(ctx, payload, msg.Subject)
+		}
 		for i := 0; i <= int(payload.Step.RetryTimes); i++ {
 			// reset retry field
 			if i > 0 {
@@ -172,11 +250,36 @@ func (s *Service) taskHandler(msg *nats.Msg) {
 	}
 }
 
+// shellCommand returns the command to be run for shell command, the command is wrapped to run in an
+// ephemeral container if it requests one. It never falls back to the host shell, the tools of the host
+// may not be the ones the command asks for.
+func (s *Service) shellCommand(c *v1.Command) ([]string, error) {
+	if c.Container == nil {
+		return c.ShellCommand, nil
+	}
+	if s.containerExecutor == nil || !s.containerExecutor.Enabled {
+		return nil, fmt.Errorf("shell command requests container image %s but container executor is disabled", c.Container.Image)
+	}
+	return wrapContainerCommand(s.containerExecutor, c.Container, c.ShellCommand)
+}
+
 func runShellCommand(ctx context.Context, cmds []string, dryRun bool) error {
 	_, err := cmdutil.RunCmdWithContext(ctx, dryRun, cmds[0], cmds[1:]...)
 	return err
 }
 
+func runPowerShellCommand(ctx context.Context, cmd *v1.PowerShellCommand, dryRun bool) error {
+	if cmd == nil {
+		return fmt.Errorf("powershell command has no script")
+	}
+	script, err := powershell.Render(cmd.Template, cmd.Data)
+	if err != nil {
+		return err
+	}
+	logger.Debug("run powershell command", zap.String("script", script))
+	return runShellCommand(ctx, powershell.Command(script), dryRun)
+}
+
 func runTemplateRenderCommand(ctx context.Context, cmd *v1.TemplateCommand, dryRun bool) *errors.StatusError {
 	errMsg := "render template error"
 	tmplRender, ok := component.LoadTemplate(cmd.Identity)
@@ -275,3 +378,43 @@ func doStatusError(errMsg, errReason string, errType errors.CauseType, errCode i
 		Code: errCode,
 	}
 }
+
+// stepProgressReporter publishes the phases reached by the step to the server,
+// a report that can not be delivered is only logged.
+func (s *Service) stepProgressReporter(opID, stepID string) component.ProgressReporter {
+	return func(phase, message string) {
+		logger.Debug("step progress", zap.String("operation", opID), zap.String("step", stepID), zap.String("phase", phase))
+		data, err := json.Marshal(service.StepProgressPayload{
+			OperationIdentity: opID,
+			Progress: v1.StepProgress{
+				StepID:    stepID,
+				Node:      s.AgentID,
+				Phase:     phase,
+				Message:   message,
+				UpdatedAt: metav1.Now(),
+			},
+		})
+		if err != nil {
+			logger.Error("marshal step progress error", zap.Error(err))
+			return
+		}
+		payload, err := json.Marshal(service.NodeStatusPayload{
+			Op:       service.OperationReportStepProgress,
+			NodeName: s.AgentID,
+			Data:     data,
+		})
+		if err != nil {
+			logger.Error("marshal step progress payload error", zap.Error(err))
+			return
+		}
+		err = s.mqClient.Publish(&natsio.Msg{
+			Subject: s.NodeReportSubject,
+			From:    s.AgentID,
+			Timeout: 1 * time.Second,
+			Data:    payload,
+		})
+		if err != nil {
+			logger.Warn("report step progress failed", zap.String("operation", opID), zap.String("phase", phase), zap.Error(err))
+		}
+	}
+}
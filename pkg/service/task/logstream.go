@@ -0,0 +1,113 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+)
+
+const (
+	// stepLogPollInterval is how often the step log file is checked for new content.
+	stepLogPollInterval = 500 * time.Millisecond
+	// maxStepLogChunk bounds the size of a published chunk, well below the message size limit.
+	maxStepLogChunk = 64 * 1024
+)
+
+// streamStepLog publishes what is appended to the step log file until stop is closed,
+// the returned channel is closed once the rest of the file and the EOF chunk are published.
+// Following the file catches the output of every writer of the step log.
+func (s *Service) streamStepLog(opID, stepID, stepKey string, stop <-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+	path, err := s.getOplog().GetStepLogFile(opID, stepKey)
+	if err != nil {
+		close(done)
+		return done
+	}
+	var offset int64
+	if stat, err := os.Stat(path); err == nil {
+		offset = stat.Size()
+	}
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(stepLogPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				offset = s.publishStepLog(path, opID, stepID, offset)
+			case <-stop:
+				offset = s.publishStepLog(path, opID, stepID, offset)
+				s.publishLogChunk(oplog.LogChunk{OpID: opID, StepID: stepID, Node: s.AgentID, Offset: offset, EOF: true})
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// publishStepLog publishes the content of the file after offset and returns the new offset.
+func (s *Service) publishStepLog(path, opID, stepID string, offset int64) int64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return offset
+	}
+	defer f.Close()
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return offset
+	}
+	buf := make([]byte, maxStepLogChunk)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			s.publishLogChunk(oplog.LogChunk{OpID: opID, StepID: stepID, Node: s.AgentID, Offset: offset, Data: buf[:n]})
+			offset += int64(n)
+		}
+		if err != nil {
+			return offset
+		}
+	}
+}
+
+// publishLogChunk is fire and forget, watchers fetch the missed content from the step log.
+func (s *Service) publishLogChunk(chunk oplog.LogChunk) {
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		logger.Error("marshal step log chunk error", zap.Error(err))
+		return
+	}
+	err = s.mqClient.Publish(&natsio.Msg{
+		Subject: fmt.Sprintf(service.OperationLogSubjectFormat, s.AgentSubject),
+		From:    s.AgentID,
+		Data:    data,
+	})
+	if err != nil {
+		logger.Debug("publish step log chunk failed", zap.String("operation", chunk.OpID), zap.String("step", chunk.StepID), zap.Error(err))
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"sync"
+	"time"
+
+	compbasemetrics "k8s.io/component-base/metrics"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/metrics"
+)
+
+const (
+	heartbeatLease      = "lease"
+	heartbeatNodeStatus = "node_status"
+)
+
+var (
+	stepDuration = compbasemetrics.NewHistogramVec(
+		&compbasemetrics.HistogramOpts{
+			Name:           "kc_agent_step_duration_seconds",
+			Help:           "Duration distribution in seconds of the steps run by kc-agent, broken out for step and result.",
+			Buckets:        []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"step", "result"},
+	)
+
+	heartbeatFailures = compbasemetrics.NewCounterVec(
+		&compbasemetrics.CounterOpts{
+			Name:           "kc_agent_heartbeat_failures_total",
+			Help:           "Counter of node lease renewals and node status reports kc-agent failed to send to kc-server.",
+			StabilityLevel: compbasemetrics.ALPHA,
+		},
+		[]string{"kind"},
+	)
+
+	registerOnce sync.Once
+)
+
+// RegisterMetrics registers the metrics of the steps and heartbeats of agent.
+func RegisterMetrics() {
+	registerOnce.Do(func() {
+		metrics.MustRegister(stepDuration, heartbeatFailures)
+	})
+}
+
+func observeStep(step string, start time.Time, failed bool) {
+	result := "success"
+	if failed {
+		result = "failure"
+	}
+	stepDuration.WithLabelValues(step, result).Observe(time.Since(start).Seconds())
+}
@@ -72,6 +72,7 @@ func (s *Service) tryUpdateNodeLease(base *coordinationv1.Lease) error {
 			s.latestLease = lease
 			return nil
 		}
+		heartbeatFailures.WithLabelValues(heartbeatLease).Inc()
 		// etcd OptimisticLockError requires getting the newer version of lease to proceed.
 		if errors.IsConflict(err) {
 			base, _ = s.backoffEnsureNodeLease()
@@ -40,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
+	"github.com/kubeclipper/kubeclipper/pkg/faultinject"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
 	"github.com/kubeclipper/kubeclipper/pkg/nodestatus"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
@@ -96,8 +97,22 @@ type Service struct {
 	onRepeatedHeartbeatFailure func()
 	// latestLease is the latest lease which the controller updated or created
 	latestLease *coordinationv1.Lease
+	// oplogMux guards oplog which may be replaced when agent is reconfigured
+	oplogMux    sync.RWMutex
 	oplog       component.OperationLogFile
 	backupStore bs.BackupStore
+	// containerExecutor runs shell commands which request a container in ephemeral containers
+	containerExecutor *ContainerExecutorOptions
+	// runningTasks maps operation ID to the cancel func of the task running for it
+	runningTasks sync.Map
+	// terminals maps session ID to the terminal sessions opened by the server
+	terminals sync.Map
+	// faults disturbs steps and mq messages on purpose, only set for tests
+	faults *faultinject.Injector
+	// reconfigure applies the agent config patch pushed by server
+	reconfigure func(ctx context.Context, patch *service.AgentConfigPatch) error
+	// externalID is the id of the node in an external inventory, the node is labeled with it
+	externalID string
 }
 
 type ServiceOption func(*Service)
@@ -120,6 +135,24 @@ func WithBackupStore(backupStore bs.BackupStore) ServiceOption {
 	}
 }
 
+func WithFaultInjector(injector *faultinject.Injector) ServiceOption {
+	return func(s *Service) {
+		s.faults = injector
+	}
+}
+
+func WithContainerExecutor(opts *ContainerExecutorOptions) ServiceOption {
+	return func(s *Service) {
+		s.containerExecutor = opts
+	}
+}
+
+func WithExternalID(id string) ServiceOption {
+	return func(s *Service) {
+		s.externalID = id
+	}
+}
+
 func WithLeaseDurationSeconds(seconds int32) ServiceOption {
 	return func(s *Service) {
 		s.leaseDurationSeconds = seconds
@@ -166,6 +199,7 @@ func NewService(agentID, region string, registerNode bool, natOpts *natsio.NatsO
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.mqClient = faultinject.WrapMQ(s.mqClient, s.faults)
 
 	s.leaseRenewInterval = time.Duration(float64(time.Duration(s.leaseDurationSeconds)*time.Second) * nodeLeaseRenewIntervalFraction)
 	s.setNodeStatusFuncs = s.defaultNodeStatusFuncs()
@@ -173,8 +207,30 @@ func NewService(agentID, region string, registerNode bool, natOpts *natsio.NatsO
 	return s
 }
 
+// SetReconfigure sets the handler for agent config patches pushed by server,
+// agent rejects reconfiguration requests until it is set.
+func (s *Service) SetReconfigure(fn func(ctx context.Context, patch *service.AgentConfigPatch) error) {
+	s.reconfigure = fn
+}
+
+// SetOplog replaces the operation log used by subsequent steps.
+func (s *Service) SetOplog(ol component.OperationLogFile) {
+	s.oplogMux.Lock()
+	defer s.oplogMux.Unlock()
+	s.oplog = ol
+}
+
+func (s *Service) getOplog() component.OperationLogFile {
+	s.oplogMux.RLock()
+	defer s.oplogMux.RUnlock()
+	return s.oplog
+}
+
 func (s *Service) Run(stopCh <-chan struct{}) error {
 	logger.Debug("mq client subscribe", zap.String("subject", s.AgentSubject))
+	if err := s.mqClient.Subscribe(s.terminalSubject(), s.terminalHandler); err != nil {
+		return err
+	}
 	if err := s.mqClient.Subscribe(s.AgentSubject, s.msgHandler); err != nil {
 		return err
 	}
@@ -199,6 +255,9 @@ func (s *Service) defaultNodeStatusFuncs() []func(*v1.Node) error {
 	setters = append(setters,
 		nodestatus.NodeAddress(),
 		nodestatus.MachineInfo(),
+		nodestatus.ExternalID(s.externalID),
+		nodestatus.GPUInfo(),
+		nodestatus.ResourceUsage(s.clock.Now),
 		nodestatus.ReadyCondition(s.clock.Now, TODO, TODO, TODO))
 
 	return setters
@@ -216,6 +275,7 @@ func (s *Service) updateNodeStatus() error {
 	logger.Debugf("Updating node status")
 	for i := 0; i < nodeStatusUpdateRetry; i++ {
 		if err := s.tryUpdateNodeStatus(i); err != nil {
+			heartbeatFailures.WithLabelValues(heartbeatNodeStatus).Inc()
 			//if i > 0 && s.onRepeatedHeartbeatFailure != nil {
 			//	s.onRepeatedHeartbeatFailure()
 			//}
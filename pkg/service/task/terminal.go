@@ -0,0 +1,188 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package task
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/errors"
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/service"
+	"github.com/kubeclipper/kubeclipper/pkg/simple/client/natsio"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/ptyutil"
+)
+
+// sessions not hearing from the server are closed, the server pings every 30s
+const terminalIdleTimeout = 2 * time.Minute
+
+type terminalSession struct {
+	open service.TerminalOpen
+	cmd  *exec.Cmd
+	ptm  *os.File
+	log  *os.File
+	// active is the unix nano of the last frame from the server
+	active int64
+}
+
+func (t *terminalSession) touch() {
+	atomic.StoreInt64(&t.active, time.Now().UnixNano())
+}
+
+func (t *terminalSession) idle() bool {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&t.active))) > terminalIdleTimeout
+}
+
+// terminate ends the shell, the pump sees the pty closed and reports it to the server.
+func (t *terminalSession) terminate() {
+	_ = t.ptm.Close()
+	if t.cmd.Process != nil {
+		_ = t.cmd.Process.Kill()
+	}
+}
+
+func terminalShell() string {
+	for _, sh := range []string{"/bin/bash", "/bin/sh"} {
+		if _, err := os.Stat(sh); err == nil {
+			return sh
+		}
+	}
+	return "sh"
+}
+
+func (s *Service) terminalSubject() string {
+	return fmt.Sprintf(service.TerminalSubjectFormat, s.AgentSubject)
+}
+
+func (s *Service) openTerminal(payload *service.MsgPayload) *errors.StatusError {
+	errMsg := "open terminal error"
+	open := service.TerminalOpen{}
+	if err := json.Unmarshal(payload.Data, &open); err != nil {
+		return doStatusError(errMsg, "unmarshal terminal payload error", errors.Unmarshal, 500, err)
+	}
+	if err := s.getOplog().CreateOperationDir(open.Session); err != nil {
+		return doStatusError(errMsg, "create terminal log error", errors.Terminal, 500, err)
+	}
+	log, err := s.getOplog().CreateStepLogFile(open.Session, service.TerminalLogStep)
+	if err != nil {
+		return doStatusError(errMsg, "create terminal log error", errors.Terminal, 500, err)
+	}
+	cmd := exec.Command(terminalShell(), "-l")
+	cmd.Env = append(os.Environ(), "TERM=xterm")
+	if home, err := os.UserHomeDir(); err == nil {
+		cmd.Dir = home
+	}
+	ptm, err := ptyutil.Start(cmd, open.Rows, open.Cols)
+	if err != nil {
+		_ = log.Close()
+		return doStatusError(errMsg, "start shell error", errors.Terminal, 500, err)
+	}
+	t := &terminalSession{open: open, cmd: cmd, ptm: ptm, log: log}
+	t.touch()
+	s.terminals.Store(open.Session, t)
+	logger.Info("terminal session opened", zap.String("session", open.Session))
+	go s.pumpTerminal(t)
+	go s.watchTerminal(t)
+	return nil
+}
+
+// pumpTerminal sends the shell output to the server until the shell exits.
+func (s *Service) pumpTerminal(t *terminalSession) {
+	defer s.terminals.Delete(t.open.Session)
+	defer t.log.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := t.ptm.Read(buf)
+		if n > 0 {
+			_, _ = t.log.Write(buf[:n])
+			s.sendTerminalFrame(t, service.TerminalFrame{Type: service.TerminalData, Data: buf[:n]})
+		}
+		if err != nil {
+			break
+		}
+	}
+	t.terminate()
+	msg := "session closed"
+	if err := t.cmd.Wait(); err != nil {
+		msg = err.Error()
+	}
+	s.sendTerminalFrame(t, service.TerminalFrame{Type: service.TerminalClose, Data: []byte(msg)})
+	logger.Info("terminal session closed", zap.String("session", t.open.Session), zap.String("reason", msg))
+}
+
+func (s *Service) watchTerminal(t *terminalSession) {
+	ticker := time.NewTicker(terminalIdleTimeout / 4)
+	defer ticker.Stop()
+	for range ticker.C {
+		if _, ok := s.terminals.Load(t.open.Session); !ok {
+			return
+		}
+		if t.idle() {
+			logger.Warn("close idle terminal session", zap.String("session", t.open.Session))
+			t.terminate()
+			return
+		}
+	}
+}
+
+func (s *Service) sendTerminalFrame(t *terminalSession, frame service.TerminalFrame) {
+	frame.Session = t.open.Session
+	data, err := json.Marshal(frame)
+	if err != nil {
+		logger.Error("marshal terminal frame error", zap.Error(err))
+		return
+	}
+	if err = s.mqClient.Publish(&natsio.Msg{Subject: t.open.ReplySubject, Data: data}); err != nil {
+		logger.Error("send terminal frame error", zap.String("session", t.open.Session), zap.Error(err))
+	}
+}
+
+// terminalHandler handles the frames the server sends to the sessions of this agent.
+func (s *Service) terminalHandler(msg *nats.Msg) {
+	frame := service.TerminalFrame{}
+	if err := json.Unmarshal(msg.Data, &frame); err != nil {
+		logger.Error("unmarshal terminal frame error", zap.Error(err))
+		return
+	}
+	v, ok := s.terminals.Load(frame.Session)
+	if !ok {
+		return
+	}
+	t := v.(*terminalSession)
+	t.touch()
+	switch frame.Type {
+	case service.TerminalData:
+		if _, err := t.ptm.Write(frame.Data); err != nil {
+			logger.Error("write terminal error", zap.String("session", frame.Session), zap.Error(err))
+		}
+	case service.TerminalResize:
+		if err := ptyutil.Resize(t.ptm, frame.Rows, frame.Cols); err != nil {
+			logger.Error("resize terminal error", zap.String("session", frame.Session), zap.Error(err))
+		}
+	case service.TerminalClose:
+		t.terminate()
+	}
+}
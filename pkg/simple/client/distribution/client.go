@@ -0,0 +1,409 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package distribution is a minimal client of the registry HTTP API V2,
+// see https://docs.docker.com/registry/spec/api/.
+package distribution
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+
+	headerContentDigest = "Docker-Content-Digest"
+)
+
+var manifestAccept = strings.Join([]string{
+	MediaTypeDockerManifest,
+	MediaTypeDockerManifestList,
+	MediaTypeOCIManifest,
+	MediaTypeOCIIndex,
+}, ", ")
+
+// Client talks to registries with a single set of credentials.
+type Client struct {
+	client   *http.Client
+	username string
+	password string
+	insecure bool
+
+	mu     sync.Mutex
+	tokens map[string]string
+}
+
+type Option func(*Client) error
+
+// WithProxy sends every request through the http proxy.
+func WithProxy(proxy string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(proxy)
+		if err != nil {
+			return fmt.Errorf("invalid proxy %s: %v", proxy, err)
+		}
+		c.client.Transport.(*http.Transport).Proxy = http.ProxyURL(u)
+		return nil
+	}
+}
+
+func WithBasicAuth(username, password string) Option {
+	return func(c *Client) error {
+		c.username, c.password = username, password
+		return nil
+	}
+}
+
+// WithInsecure uses plain http and skips the verification of certificates.
+func WithInsecure() Option {
+	return func(c *Client) error {
+		c.insecure = true
+		c.client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+		return nil
+	}
+}
+
+func NewClient(opts ...Option) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	c := &Client{
+		client: &http.Client{Transport: transport, Timeout: 30 * time.Minute},
+		tokens: make(map[string]string),
+	}
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Manifest is an image manifest, manifest list or index kept in its raw form,
+// so it can be pushed elsewhere without changing its digest.
+type Manifest struct {
+	MediaType string
+	Digest    string
+	Raw       []byte
+}
+
+type descriptor struct {
+	MediaType string   `json:"mediaType"`
+	Digest    string   `json:"digest"`
+	Size      int64    `json:"size"`
+	URLs      []string `json:"urls,omitempty"`
+}
+
+type manifestContent struct {
+	Config    descriptor   `json:"config"`
+	Layers    []descriptor `json:"layers"`
+	Manifests []descriptor `json:"manifests"`
+}
+
+// IsIndex reports whether the manifest references other manifests instead of blobs.
+func (m *Manifest) IsIndex() bool {
+	return m.MediaType == MediaTypeDockerManifestList || m.MediaType == MediaTypeOCIIndex
+}
+
+func (m *Manifest) content() (*manifestContent, error) {
+	mc := &manifestContent{}
+	if err := json.Unmarshal(m.Raw, mc); err != nil {
+		return nil, fmt.Errorf("decode manifest %s: %v", m.Digest, err)
+	}
+	return mc, nil
+}
+
+func (c *Client) GetManifest(ctx context.Context, ref Reference) (*Manifest, error) {
+	resp, err := c.do(ctx, ref, false, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, c.url(ref, "manifests", ref.Identifier()), nil)
+		if err == nil {
+			req.Header.Set("Accept", manifestAccept)
+		}
+		return req, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, "get manifest "+ref.String())
+	}
+	raw, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manifest{
+		MediaType: strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0]),
+		Digest:    Digest(raw),
+		Raw:       raw,
+	}
+	if ref.Digest != "" && ref.Digest != m.Digest {
+		return nil, fmt.Errorf("manifest of %s has digest %s", ref.String(), m.Digest)
+	}
+	return m, nil
+}
+
+// ManifestDigest returns the digest of the manifest, or an empty string when it does not exist.
+func (c *Client) ManifestDigest(ctx context.Context, ref Reference) (string, error) {
+	resp, err := c.do(ctx, ref, false, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodHead, c.url(ref, "manifests", ref.Identifier()), nil)
+		if err == nil {
+			req.Header.Set("Accept", manifestAccept)
+		}
+		return req, err
+	})
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Header.Get(headerContentDigest), nil
+	case http.StatusNotFound:
+		return "", nil
+	default:
+		return "", responseError(resp, "head manifest "+ref.String())
+	}
+}
+
+func (c *Client) PutManifest(ctx context.Context, ref Reference, m *Manifest) error {
+	resp, err := c.do(ctx, ref, true, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPut, c.url(ref, "manifests", ref.Identifier()), strings.NewReader(string(m.Raw)))
+		if err == nil {
+			req.Header.Set("Content-Type", m.MediaType)
+		}
+		return req, err
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return responseError(resp, "put manifest "+ref.String())
+	}
+	return nil
+}
+
+func (c *Client) BlobExists(ctx context.Context, ref Reference, digest string) (bool, error) {
+	resp, err := c.do(ctx, ref, true, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodHead, c.url(ref, "blobs", digest), nil)
+	})
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, responseError(resp, "head blob "+digest)
+	}
+}
+
+// GetBlob returns the blob content and its size, the caller must close the reader.
+func (c *Client) GetBlob(ctx context.Context, ref Reference, digest string) (io.ReadCloser, int64, error) {
+	resp, err := c.do(ctx, ref, false, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodGet, c.url(ref, "blobs", digest), nil)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, 0, responseError(resp, "get blob "+digest)
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// PutBlob uploads the blob in a single request.
+func (c *Client) PutBlob(ctx context.Context, ref Reference, digest string, content io.Reader, size int64) error {
+	resp, err := c.do(ctx, ref, true, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodPost, c.url(ref, "blobs", "uploads")+"/", nil)
+	})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted {
+		return responseError(resp, "start blob upload "+digest)
+	}
+	location, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		return fmt.Errorf("invalid blob upload location: %v", err)
+	}
+	q := location.Query()
+	q.Set("digest", digest)
+	location.RawQuery = q.Encode()
+
+	// the body can not be replayed, the token fetched for the upload request is reused
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, location.String(), content)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	c.authorize(req, ref)
+	resp, err = c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		return responseError(resp, "upload blob "+digest)
+	}
+	return nil
+}
+
+func (c *Client) url(ref Reference, kind, id string) string {
+	scheme := "https"
+	if c.insecure {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s/%s/%s", scheme, ref.Registry, ref.Repository, kind, id)
+}
+
+// do sends the request built by newReq, answering an authentication challenge once.
+func (c *Client) do(ctx context.Context, ref Reference, push bool, newReq func() (*http.Request, error)) (*http.Response, error) {
+	req, err := newReq()
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	c.authorize(req, ref)
+	resp, err := c.client.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if err = c.login(ctx, ref, push, challenge); err != nil {
+		return nil, err
+	}
+	if req, err = newReq(); err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+	c.authorize(req, ref)
+	return c.client.Do(req)
+}
+
+func (c *Client) authorize(req *http.Request, ref Reference) {
+	c.mu.Lock()
+	token := c.tokens[ref.Registry+"/"+ref.Repository]
+	c.mu.Unlock()
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+}
+
+// login fetches a bearer token for the repository as described by the challenge.
+// Basic challenges are answered by the credentials of the client on the retry.
+func (c *Client) login(ctx context.Context, ref Reference, push bool, challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	if !strings.EqualFold(scheme, "bearer") {
+		if c.username == "" {
+			return fmt.Errorf("registry %s requires authentication", ref.Registry)
+		}
+		return nil
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("invalid authentication challenge %q", challenge)
+	}
+	actions := "pull"
+	if push {
+		actions = "pull,push"
+	}
+	q := realm.Query()
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	q.Set("scope", fmt.Sprintf("repository:%s:%s", ref.Repository, actions))
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp, "get token of "+ref.Registry)
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("decode token of %s: %v", ref.Registry, err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	c.mu.Lock()
+	c.tokens[ref.Registry+"/"+ref.Repository] = token.Token
+	c.mu.Unlock()
+	return nil
+}
+
+// parseChallenge parses `Bearer realm="...",service="...",scope="..."`.
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		var key, value string
+		key, rest, _ = strings.Cut(rest, "=")
+		if strings.HasPrefix(rest, `"`) {
+			// quoted values may contain commas, e.g. scope="repository:foo:pull,push"
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+			_, rest, _ = strings.Cut(rest, ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+		rest = strings.TrimSpace(rest)
+	}
+	return scheme, params
+}
+
+func responseError(resp *http.Response, action string) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("%s: unexpected status %d: %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,98 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package distribution
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+)
+
+// Digest returns the sha256 digest of content in the registry format.
+func Digest(content []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(content))
+}
+
+// Copy copies the image src points to, with all its platforms, to dst and returns
+// the manifest digest. Nothing is uploaded when dst already has that manifest.
+func Copy(ctx context.Context, srcClient *Client, src Reference, dstClient *Client, dst Reference) (string, error) {
+	m, err := srcClient.GetManifest(ctx, src)
+	if err != nil {
+		return "", err
+	}
+	current, err := dstClient.ManifestDigest(ctx, dst)
+	if err != nil {
+		return "", err
+	}
+	if current == m.Digest {
+		return m.Digest, nil
+	}
+	if err = copyManifest(ctx, srcClient, src, dstClient, dst, m); err != nil {
+		return "", err
+	}
+	return m.Digest, nil
+}
+
+func copyManifest(ctx context.Context, srcClient *Client, src Reference, dstClient *Client, dst Reference, m *Manifest) error {
+	content, err := m.content()
+	if err != nil {
+		return err
+	}
+	if m.IsIndex() {
+		for _, d := range content.Manifests {
+			child, err := srcClient.GetManifest(ctx, src.withDigest(d.Digest))
+			if err != nil {
+				return err
+			}
+			if err = copyManifest(ctx, srcClient, src, dstClient, dst.withDigest(d.Digest), child); err != nil {
+				return err
+			}
+		}
+		return dstClient.PutManifest(ctx, dst, m)
+	}
+	blobs := append([]descriptor{content.Config}, content.Layers...)
+	for _, d := range blobs {
+		// foreign layers are not distributable, clients download them from their urls
+		if d.Digest == "" || len(d.URLs) > 0 {
+			continue
+		}
+		if err = copyBlob(ctx, srcClient, src, dstClient, dst, d.Digest); err != nil {
+			return err
+		}
+	}
+	return dstClient.PutManifest(ctx, dst, m)
+}
+
+func copyBlob(ctx context.Context, srcClient *Client, src Reference, dstClient *Client, dst Reference, digest string) error {
+	exist, err := dstClient.BlobExists(ctx, dst, digest)
+	if err != nil || exist {
+		return err
+	}
+	content, size, err := srcClient.GetBlob(ctx, src, digest)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+	return dstClient.PutBlob(ctx, dst, digest, content, size)
+}
+
+func (r Reference) withDigest(digest string) Reference {
+	r.Tag, r.Digest = "", digest
+	return r
+}
@@ -0,0 +1,144 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package distribution
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry keeps manifests and blobs in memory and counts uploads.
+type fakeRegistry struct {
+	mu          sync.Mutex
+	manifests   map[string][]byte
+	types       map[string]string
+	blobs       map[string][]byte
+	blobUploads int
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{manifests: map[string][]byte{}, types: map[string]string{}, blobs: map[string][]byte{}}
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case strings.Contains(path, "/blobs/uploads/"):
+		if r.Method == http.MethodPost {
+			w.Header().Set("Location", "/v2/upload/1")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+	case strings.HasPrefix(path, "upload/"):
+		body, _ := ioutil.ReadAll(r.Body)
+		f.blobs[r.URL.Query().Get("digest")] = body
+		f.blobUploads++
+		w.WriteHeader(http.StatusCreated)
+		return
+	case strings.Contains(path, "/blobs/"):
+		body, ok := f.blobs[path[strings.LastIndex(path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+		return
+	case strings.Contains(path, "/manifests/"):
+		if r.Method == http.MethodPut {
+			body, _ := ioutil.ReadAll(r.Body)
+			f.manifests[path] = body
+			f.types[path] = r.Header.Get("Content-Type")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		body, ok := f.manifests[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", f.types[path])
+		w.Header().Set(headerContentDigest, Digest(body))
+		_, _ = w.Write(body)
+		return
+	}
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func TestCopy(t *testing.T) {
+	upstream := newFakeRegistry()
+	config, layer := []byte(`{"architecture":"amd64"}`), []byte("layer")
+	upstream.blobs[Digest(config)] = config
+	upstream.blobs[Digest(layer)] = layer
+	manifest := []byte(fmt.Sprintf(`{"config":{"digest":%q},"layers":[{"digest":%q},{"digest":"sha256:foreign","urls":["https://example.com"]}]}`,
+		Digest(config), Digest(layer)))
+	upstream.manifests["library/app/manifests/"+Digest(manifest)] = manifest
+	upstream.types["library/app/manifests/"+Digest(manifest)] = MediaTypeDockerManifest
+	index := []byte(fmt.Sprintf(`{"manifests":[{"digest":%q}]}`, Digest(manifest)))
+	upstream.manifests["library/app/manifests/v1"] = index
+	upstream.types["library/app/manifests/v1"] = MediaTypeOCIIndex
+
+	embedded := newFakeRegistry()
+	srcServer, dstServer := httptest.NewServer(upstream), httptest.NewServer(embedded)
+	defer srcServer.Close()
+	defer dstServer.Close()
+	client, err := NewClient(WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := Reference{Registry: strings.TrimPrefix(srcServer.URL, "http://"), Repository: "library/app", Tag: "v1"}
+	dst := Reference{Registry: strings.TrimPrefix(dstServer.URL, "http://"), Repository: "mirror/app", Tag: "v1"}
+
+	digest, err := Copy(context.TODO(), client, src, client, dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digest != Digest(index) {
+		t.Errorf("digest = %s, want %s", digest, Digest(index))
+	}
+	if string(embedded.manifests["mirror/app/manifests/v1"]) != string(index) {
+		t.Errorf("index not pushed unchanged")
+	}
+	if embedded.types["mirror/app/manifests/"+Digest(manifest)] != MediaTypeDockerManifest {
+		t.Errorf("platform manifest not pushed with its media type")
+	}
+	if embedded.blobUploads != 2 {
+		t.Errorf("uploaded %d blobs, want 2", embedded.blobUploads)
+	}
+
+	if _, err = Copy(context.TODO(), client, src, client, dst); err != nil {
+		t.Fatal(err)
+	}
+	if embedded.blobUploads != 2 {
+		t.Errorf("unchanged image uploaded again")
+	}
+
+	pinned := src
+	pinned.Digest = "sha256:0000"
+	if _, err = Copy(context.TODO(), client, pinned, client, dst); err == nil {
+		t.Errorf("expected error for a pinned digest not served upstream")
+	}
+}
@@ -0,0 +1,67 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package distribution
+
+import "testing"
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Reference
+	}{
+		{in: "nginx", want: Reference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "latest"}},
+		{in: "docker.io/bitnami/redis:7.0", want: Reference{Registry: dockerHubRegistry, Repository: "bitnami/redis", Tag: "7.0"}},
+		{in: "10.0.0.1:5000/caas4/etcd:3.5", want: Reference{Registry: "10.0.0.1:5000", Repository: "caas4/etcd", Tag: "3.5"}},
+		{in: "localhost/pause", want: Reference{Registry: "localhost", Repository: "pause", Tag: "latest"}},
+		{in: "quay.io/coreos/etcd:v3.5@sha256:abc", want: Reference{Registry: "quay.io", Repository: "coreos/etcd", Tag: "v3.5", Digest: "sha256:abc"}},
+		{in: "quay.io/coreos/etcd@sha256:abc", want: Reference{Registry: "quay.io", Repository: "coreos/etcd", Digest: "sha256:abc"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseReference(tt.in)
+		if err != nil {
+			t.Errorf("ParseReference(%s) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseReference(%s) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	for _, in := range []string{"", "nginx@md5:abc"} {
+		if _, err := ParseReference(in); err == nil {
+			t.Errorf("ParseReference(%q) expected error", in)
+		}
+	}
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull,push"`)
+	if scheme != "Bearer" {
+		t.Errorf("scheme = %s", scheme)
+	}
+	want := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull,push",
+	}
+	for k, v := range want {
+		if params[k] != v {
+			t.Errorf("params[%s] = %q, want %q", k, params[k], v)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const dialTimeout = 5 * time.Second
+
+// NewClient returns a client of the etcd kc-server stores its resources in.
+func (s *Options) NewClient() (*clientv3.Client, error) {
+	cfg := clientv3.Config{
+		Endpoints:   s.ServerList,
+		DialTimeout: dialTimeout,
+	}
+	if s.CertFile != "" || s.KeyFile != "" || s.TrustedCAFile != "" {
+		tlsInfo := transport.TLSInfo{
+			CertFile:      s.CertFile,
+			KeyFile:       s.KeyFile,
+			TrustedCAFile: s.TrustedCAFile,
+		}
+		tlsConfig, err := tlsInfo.ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("load etcd client tls config failed: %v", err)
+		}
+		cfg.TLS = tlsConfig
+	}
+	return clientv3.New(cfg)
+}
+
+// CheckHealth reads the status of the member behind the endpoint and reports the alarms raised on it.
+func CheckHealth(ctx context.Context, cli *clientv3.Client, endpoint string) (*clientv3.StatusResponse, error) {
+	status, err := cli.Status(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if len(status.Errors) > 0 {
+		return status, fmt.Errorf("member %s reports errors: %v", endpoint, status.Errors)
+	}
+	alarms, err := cli.AlarmList(ctx)
+	if err != nil {
+		return status, err
+	}
+	for _, a := range alarms.Alarms {
+		if a.MemberID == status.Header.MemberId {
+			return status, fmt.Errorf("member %s raised alarm %s", endpoint, a.Alarm)
+		}
+	}
+	return status, nil
+}
+
+// Compact compacts the history of the whole cluster up to the current revision, it returns the revision.
+func Compact(ctx context.Context, cli *clientv3.Client, endpoint string) (int64, error) {
+	status, err := cli.Status(ctx, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	rev := status.Header.Revision
+	if _, err = cli.Compact(ctx, rev, clientv3.WithCompactPhysical()); err != nil {
+		return 0, err
+	}
+	return rev, nil
+}
+
+// Defragment defragments the member behind the endpoint and returns the database size released.
+func Defragment(ctx context.Context, cli *clientv3.Client, endpoint string) (int64, error) {
+	before, err := cli.Status(ctx, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	if _, err = cli.Defragment(ctx, endpoint); err != nil {
+		return 0, err
+	}
+	after, err := cli.Status(ctx, endpoint)
+	if err != nil {
+		return 0, err
+	}
+	return before.DbSize - after.DbSize, nil
+}
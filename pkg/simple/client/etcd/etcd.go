@@ -53,6 +53,11 @@ type Options struct {
 	DefaultWatchCacheSize int `json:"defaultWatchCacheSize" yaml:"defaultWatchCacheSize"`
 	// WatchCacheSizes represents override to a given resource
 	WatchCacheSizes []string `json:"watchCacheSizes" yaml:"watchCacheSizes"`
+	// CachedReadResources are served from the watch cache for API get and list
+	// requests without a resourceVersion, instead of reading etcd
+	CachedReadResources []string `json:"cachedReadResources" yaml:"cachedReadResources"`
+	// EncryptionProviderConfig is the file of the providers encrypting the resources at rest
+	EncryptionProviderConfig string `json:"encryptionProviderConfig" yaml:"encryptionProviderConfig"`
 }
 
 func NewEtcdOptions() *Options {
@@ -66,6 +71,7 @@ func NewEtcdOptions() *Options {
 		EnableGarbageCollection: true,
 		EnableWatchCache:        true,
 		DefaultWatchCacheSize:   100,
+		CachedReadResources:     []string{"nodes", "clusters"},
 	}
 }
 
@@ -112,6 +118,14 @@ func (s *Options) AddFlags(fs *pflag.FlagSet) {
 		"Some resources (replicationcontrollers, endpoints, nodes, pods, services, apiservices.apiregistration.k8s.io) "+
 		"have system defaults set by heuristics, others default to default-watch-cache-size")
 
+	fs.StringSliceVar(&s.CachedReadResources, "cached-read-resources", s.CachedReadResources, ""+
+		"Resources (lowercase plural, e.g. nodes,clusters) whose API get and list requests are served from the watch cache "+
+		"instead of etcd. It takes effect when watch-cache is enabled.")
+
+	fs.StringVar(&s.EncryptionProviderConfig, "encryption-provider-config", s.EncryptionProviderConfig, ""+
+		"The file containing the configuration of the encryption providers of the resources stored in etcd, "+
+		"the resources it does not list are stored unencrypted.")
+
 	fs.StringSliceVar(&s.ServerList, "etcd-servers", s.ServerList,
 		"List of etcd servers to connect with (scheme://ip:port), comma separated.")
 
@@ -22,6 +22,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 
 	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
 
@@ -32,12 +33,20 @@ import (
 
 const (
 	listNodesPath     = "/api/core.kubeclipper.io/v1/nodes"
+	discoveredPath    = "/api/core.kubeclipper.io/v1/discoverednodes"
 	clustersPath      = "/api/core.kubeclipper.io/v1/clusters"
+	regionsPath       = "/api/core.kubeclipper.io/v1/regions"
+	operationsPath    = "/api/core.kubeclipper.io/v1/operations"
+	backupsPath       = "/api/core.kubeclipper.io/v1/backups"
 	usersPath         = "/api/iam.kubeclipper.io/v1/users"
 	rolesPath         = "/api/iam.kubeclipper.io/v1/roles"
+	roleBindingsPath  = "/api/iam.kubeclipper.io/v1/rolebindings"
 	platformPath      = "/api/config.kubeclipper.io/v1/template"
 	versionPath       = "/version"
 	componentMetaPath = "/api/config.kubeclipper.io/v1/componentmeta"
+	prechecksPath     = "/api/core.kubeclipper.io/v1/prechecks"
+	stepLogPath       = "/api/core.kubeclipper.io/v1/logs"
+	eventsPath        = "/api/core.kubeclipper.io/v1/events"
 )
 
 func (cli *Client) ListNodes(ctx context.Context, query Queries) (*NodesList, error) {
@@ -107,6 +116,11 @@ func (cli *Client) ListClusters(ctx context.Context, query Queries) (*ClustersLi
 	return &users, err
 }
 
+// ClusterProxyPath is where kc-server proxies requests to the apiserver of the cluster.
+func ClusterProxyPath(name string) string {
+	return fmt.Sprintf("%s/%s/proxy", clustersPath, name)
+}
+
 func (cli *Client) DescribeCluster(ctx context.Context, name string) (*ClustersList, error) {
 	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", clustersPath, name), nil, nil)
 	defer ensureReaderClosed(serverResp)
@@ -121,6 +135,36 @@ func (cli *Client) DescribeCluster(ctx context.Context, name string) (*ClustersL
 	return &clusters, err
 }
 
+// ListDiscoveredNodes lists the machines of the inventory provider, or of all providers if provider is empty.
+func (cli *Client) ListDiscoveredNodes(ctx context.Context, provider string, refresh bool) (*DiscoveredNodeList, error) {
+	query := url.Values{}
+	if provider != "" {
+		query.Set("provider", provider)
+	}
+	if refresh {
+		query.Set("refresh", "true")
+	}
+	serverResp, err := cli.get(ctx, discoveredPath, query, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	machines := DiscoveredNodeList{}
+	err = json.NewDecoder(serverResp.body).Decode(&machines)
+	return &machines, err
+}
+
+func (cli *Client) ListRegions(ctx context.Context, query Queries) (*RegionList, error) {
+	serverResp, err := cli.get(ctx, regionsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	regions := RegionList{}
+	err = json.NewDecoder(serverResp.body).Decode(&regions)
+	return &regions, err
+}
+
 func (cli *Client) ListRoles(ctx context.Context, query Queries) (*RoleList, error) {
 	serverResp, err := cli.get(ctx, rolesPath, query.ToRawQuery(), nil)
 	defer ensureReaderClosed(serverResp)
@@ -146,6 +190,31 @@ func (cli *Client) DescribeRole(ctx context.Context, name string) (*RoleList, er
 	return &roles, err
 }
 
+func (cli *Client) ListRoleBindings(ctx context.Context, query Queries) (*RoleBindingList, error) {
+	serverResp, err := cli.get(ctx, roleBindingsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	bindings := RoleBindingList{}
+	err = json.NewDecoder(serverResp.body).Decode(&bindings)
+	return &bindings, err
+}
+
+func (cli *Client) DescribeRoleBinding(ctx context.Context, name string) (*RoleBindingList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", roleBindingsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	binding := iamv1.GlobalRoleBinding{}
+	err = json.NewDecoder(serverResp.body).Decode(&binding)
+	bindings := RoleBindingList{
+		Items: []iamv1.GlobalRoleBinding{binding},
+	}
+	return &bindings, err
+}
+
 func (cli *Client) Version(ctx context.Context) (*apimachineryversion.Info, error) {
 	serverResp, err := cli.get(ctx, versionPath, nil, nil)
 	defer ensureReaderClosed(serverResp)
@@ -171,6 +240,22 @@ func (cli *Client) CreateCluster(ctx context.Context, cluster *v1.Cluster) (*Clu
 	return &clusters, err
 }
 
+// ImportCluster adopts an existing kubeadm cluster, with dryRun the discovered cluster is returned without importing it.
+func (cli *Client) ImportCluster(ctx context.Context, req *v1.ClusterImport, dryRun bool) (*ClustersList, error) {
+	query := url.Values{}
+	if dryRun {
+		query.Set("dryRun", "true")
+	}
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/import", clustersPath), query, req, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Cluster{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	return &ClustersList{Items: []v1.Cluster{v}}, err
+}
+
 func (cli *Client) CreateUser(ctx context.Context, user *iamv1.User) (*UsersList, error) {
 	serverResp, err := cli.post(ctx, usersPath, nil, user, nil)
 	defer ensureReaderClosed(serverResp)
@@ -199,6 +284,45 @@ func (cli *Client) CreateRole(ctx context.Context, role *iamv1.GlobalRole) (*Rol
 	return &roles, err
 }
 
+// UpdateUser updates the user profile, the user is bound to the role in the
+// role annotation if it is set.
+func (cli *Client) UpdateUser(ctx context.Context, user *iamv1.User) (*UsersList, error) {
+	serverResp, err := cli.put(ctx, fmt.Sprintf("%s/%s", usersPath, user.Name), nil, user, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := iamv1.User{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	users := UsersList{
+		Items: []iamv1.User{v},
+	}
+	return &users, err
+}
+
+func (cli *Client) UpdateUserPassword(ctx context.Context, name string, reset *PasswordReset) error {
+	serverResp, err := cli.put(ctx, fmt.Sprintf("%s/%s/password", usersPath, name), nil, reset, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) GetUserPreferences(ctx context.Context, name string) (*iamv1.UserPreferences, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s/preferences", usersPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	prefs := &iamv1.UserPreferences{}
+	err = json.NewDecoder(serverResp.body).Decode(prefs)
+	return prefs, err
+}
+
+func (cli *Client) UpdateUserPreferences(ctx context.Context, name string, prefs *iamv1.UserPreferences) error {
+	serverResp, err := cli.put(ctx, fmt.Sprintf("%s/%s/preferences", usersPath, name), nil, prefs, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
 func (cli *Client) DeleteUser(ctx context.Context, name string) error {
 	serverResp, err := cli.delete(ctx, fmt.Sprintf("%s/%s", usersPath, name), nil, nil)
 	defer ensureReaderClosed(serverResp)
@@ -226,6 +350,124 @@ func (cli *Client) DeleteCluster(ctx context.Context, name string) error {
 	return nil
 }
 
+// UndeleteCluster restores the cluster from the recycle bin.
+func (cli *Client) UndeleteCluster(ctx context.Context, name string) (*v1.Cluster, error) {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/undelete", clustersPath, name), nil, nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Cluster{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	return &v, err
+}
+
+// UndeleteBackup restores the backup of the cluster from the recycle bin.
+func (cli *Client) UndeleteBackup(ctx context.Context, cluster, name string) (*v1.Backup, error) {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/backups/%s/undelete", clustersPath, cluster, name), nil, nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.Backup{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	return &v, err
+}
+
+func (cli *Client) UpdateCluster(ctx context.Context, cluster *v1.Cluster) error {
+	serverResp, err := cli.put(ctx, fmt.Sprintf("%s/%s", clustersPath, cluster.Name), nil, cluster, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) DiffCluster(ctx context.Context, cluster *v1.Cluster) (*v1.ClusterDiff, error) {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/diff", clustersPath, cluster.Name), nil, cluster, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.ClusterDiff{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	return &v, err
+}
+
+// GetKubeconfig issues a kubeconfig of the cluster for the scope, expiration is left to the server when empty.
+func (cli *Client) GetKubeconfig(ctx context.Context, name string, scope v1.KubeconfigScope, expiration string) (*v1.ClusterKubeconfig, error) {
+	query := url.Values{}
+	if expiration != "" {
+		query.Set("expiration", expiration)
+	}
+	path := fmt.Sprintf("%s/%s/kubeconfig", clustersPath, name)
+	if scope == v1.KubeconfigScopeAdmin {
+		path = fmt.Sprintf("%s/%s/adminkubeconfig", clustersPath, name)
+	} else {
+		query.Set("scope", string(scope))
+	}
+	serverResp, err := cli.get(ctx, path, query, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.ClusterKubeconfig{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	return &v, err
+}
+
+func (cli *Client) ExportCluster(ctx context.Context, name string, format v1.ClusterExportFormat) (*v1.ClusterExport, error) {
+	query := url.Values{}
+	query.Set("format", string(format))
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s/export", clustersPath, name), query, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	v := v1.ClusterExport{}
+	err = json.NewDecoder(serverResp.body).Decode(&v)
+	return &v, err
+}
+
+func (cli *Client) PatchClusterNodes(ctx context.Context, name string, patch *PatchNodes) error {
+	serverResp, err := cli.put(ctx, fmt.Sprintf("%s/%s/nodes", clustersPath, name), nil, patch, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) PatchClusterComponents(ctx context.Context, name string, patch *PatchComponents) error {
+	serverResp, err := cli.patch(ctx, fmt.Sprintf("%s/%s/plugins", clustersPath, name), nil, patch, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) UpgradeClusterComponents(ctx context.Context, name string, upgrade *UpgradeComponents) error {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/plugins/upgrade", clustersPath, name), nil, upgrade, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) UpgradeCluster(ctx context.Context, name string, upgrade *ClusterUpgrade) error {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/upgrade", clustersPath, name), nil, upgrade, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+func (cli *Client) PauseOperation(ctx context.Context, name string) error {
+	return cli.operationAction(ctx, name, "pause")
+}
+
+func (cli *Client) ResumeOperation(ctx context.Context, name string) error {
+	return cli.operationAction(ctx, name, "resume")
+}
+
+func (cli *Client) CancelOperation(ctx context.Context, name string) error {
+	return cli.operationAction(ctx, name, "cancel")
+}
+
+func (cli *Client) operationAction(ctx context.Context, name, action string) error {
+	serverResp, err := cli.post(ctx, fmt.Sprintf("%s/%s/%s", operationsPath, name, action), nil, nil, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
 func (cli *Client) GetPlatformSetting(ctx context.Context) (*v1.DockerRegistry, error) {
 	serverResp, err := cli.get(ctx, platformPath, nil, nil)
 	defer ensureReaderClosed(serverResp)
@@ -247,3 +489,59 @@ func (cli *Client) GetComponentMeta(ctx context.Context) (*ComponentMeta, error)
 	err = json.NewDecoder(serverResp.body).Decode(&v)
 	return &v, err
 }
+
+func (cli *Client) ListPrechecks(ctx context.Context, query Queries) (*PrechecksList, error) {
+	serverResp, err := cli.get(ctx, prechecksPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	prechecks := PrechecksList{}
+	err = json.NewDecoder(serverResp.body).Decode(&prechecks)
+	return &prechecks, err
+}
+
+func (cli *Client) DescribePrecheck(ctx context.Context, name string) (*PrechecksList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", prechecksPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	precheck := v1.Precheck{}
+	err = json.NewDecoder(serverResp.body).Decode(&precheck)
+	prechecks := PrechecksList{
+		Items: []v1.Precheck{precheck},
+	}
+	return &prechecks, err
+}
+
+func (cli *Client) ListEvents(ctx context.Context, query Queries) (*EventsList, error) {
+	serverResp, err := cli.get(ctx, eventsPath, query.ToRawQuery(), nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	events := EventsList{}
+	err = json.NewDecoder(serverResp.body).Decode(&events)
+	return &events, err
+}
+
+func (cli *Client) DescribeEvent(ctx context.Context, name string) (*EventsList, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s", eventsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	event := v1.ObjectEvent{}
+	err = json.NewDecoder(serverResp.body).Decode(&event)
+	events := EventsList{
+		Items: []v1.ObjectEvent{event},
+	}
+	return &events, err
+}
+
+func (cli *Client) CreatePrecheck(ctx context.Context, precheck *v1.Precheck) error {
+	serverResp, err := cli.post(ctx, prechecksPath, nil, precheck, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
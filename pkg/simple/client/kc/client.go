@@ -34,6 +34,7 @@ type Client struct {
 	bearerToken string
 	basePath    string
 	scheme      string
+	retry       retryPolicy
 }
 
 func NewClientWithOpts(opts ...Opt) (*Client, error) {
@@ -65,6 +66,11 @@ func (cli *Client) Scheme() string {
 	return cli.scheme
 }
 
+// BearerToken returns the token used to authenticate against the server
+func (cli *Client) BearerToken() string {
+	return cli.bearerToken
+}
+
 // getAPIPath returns the versioned request path to call the api.
 // It appends the query parameters to the path if they are not empty.
 func (cli *Client) getAPIPath(ctx context.Context, p string, query url.Values) string {
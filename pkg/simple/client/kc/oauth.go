@@ -21,14 +21,22 @@ package kc
 import (
 	"context"
 	"encoding/json"
+	"net/url"
+	"strings"
 
 	"github.com/kubeclipper/kubeclipper/pkg/authentication/oauth"
 )
 
 const (
-	loginPath = "/oauth/login"
+	loginPath  = "/oauth/login"
+	logoutPath = "/oauth/logout"
+	tokenPath  = "/oauth/token"
 )
 
+var formContentTypeHeader = map[string][]string{
+	"Content-Type": {"application/x-www-form-urlencoded"},
+}
+
 func (cli *Client) Login(ctx context.Context, body LoginRequest) (*oauth.Token, error) {
 	serverResp, err := cli.post(ctx, loginPath, nil, body, JSONContentTypeHeader)
 	defer ensureReaderClosed(serverResp)
@@ -39,3 +47,25 @@ func (cli *Client) Login(ctx context.Context, body LoginRequest) (*oauth.Token,
 	err = json.NewDecoder(serverResp.body).Decode(&token)
 	return &token, err
 }
+
+// RefreshToken exchanges the refresh token for a new token pair.
+func (cli *Client) RefreshToken(ctx context.Context, refreshToken string) (*oauth.Token, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	serverResp, err := cli.postRaw(ctx, tokenPath, nil, strings.NewReader(form.Encode()), formContentTypeHeader)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	token := oauth.Token{}
+	err = json.NewDecoder(serverResp.body).Decode(&token)
+	return &token, err
+}
+
+// Logout revokes all tokens of the current user.
+func (cli *Client) Logout(ctx context.Context) error {
+	serverResp, err := cli.post(ctx, logoutPath, nil, nil, nil)
+	defer ensureReaderClosed(serverResp)
+	return err
+}
@@ -0,0 +1,120 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package kc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/kubeclipper/kubeclipper/pkg/oplog"
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func (cli *Client) ListOperationSteps(ctx context.Context, name string) ([]v1.OperationStep, error) {
+	serverResp, err := cli.get(ctx, fmt.Sprintf("%s/%s/steps", operationsPath, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	var steps []v1.OperationStep
+	err = json.NewDecoder(serverResp.body).Decode(&steps)
+	return steps, err
+}
+
+// GetStepLog returns the step log of the node after offset, the size of the content is limited by the agent.
+func (cli *Client) GetStepLog(ctx context.Context, node, operation, step string, offset int64) (*oplog.LogContentResponse, error) {
+	values := url.Values{}
+	values.Set(query.ParameterNode, node)
+	values.Set(query.ParameterOperation, operation)
+	values.Set(query.ParameterStep, step)
+	values.Set(query.ParameterOffset, strconv.FormatInt(offset, 10))
+	serverResp, err := cli.get(ctx, stepLogPath, values, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	resp := oplog.LogContentResponse{}
+	err = json.NewDecoder(serverResp.body).Decode(&resp)
+	return &resp, err
+}
+
+// LogStream receives the step log chunks of a running operation.
+type LogStream struct {
+	conn *websocket.Conn
+}
+
+// Next blocks until the next chunk, io.EOF is returned once the operation is finished.
+func (s *LogStream) Next() (*oplog.LogChunk, error) {
+	chunk := oplog.LogChunk{}
+	if err := s.conn.ReadJSON(&chunk); err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	return &chunk, nil
+}
+
+func (s *LogStream) Close() error {
+	return s.conn.Close()
+}
+
+// WatchOperationLog streams the step logs of the running operation, node and step filter the chunks if not empty.
+func (cli *Client) WatchOperationLog(ctx context.Context, name, node, step string) (*LogStream, error) {
+	values := url.Values{}
+	if node != "" {
+		values.Set(query.ParameterNode, node)
+	}
+	if step != "" {
+		values.Set(query.ParameterStep, step)
+	}
+	u := url.URL{
+		Scheme:   "ws",
+		Host:     cli.host,
+		Path:     fmt.Sprintf("%s/%s/logs", operationsPath, name),
+		RawQuery: values.Encode(),
+	}
+	dialer := *websocket.DefaultDialer
+	if cli.scheme == "https" {
+		u.Scheme = "wss"
+		if t, ok := cli.client.Transport.(*http.Transport); ok {
+			dialer.TLSClientConfig = t.TLSClientConfig
+		}
+	}
+	header := http.Header{}
+	if cli.bearerToken != "" {
+		header.Set("Authorization", fmt.Sprintf("Bearer %s", cli.bearerToken))
+	}
+	conn, resp, err := dialer.DialContext(ctx, u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("watch operation log failed: %s", resp.Status)
+		}
+		return nil, err
+	}
+	return &LogStream{conn: conn}, nil
+}
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 )
 
 var (
@@ -89,3 +90,16 @@ func WithBearerAuth(token string) Opt {
 		return nil
 	}
 }
+
+// WithRetry retries the requests up to maxRetries times when the server is unreachable
+// or busy, waiting backoff before the first retry and doubling it before each next one.
+// Only the requests safe to repeat are retried after connection errors.
+func WithRetry(maxRetries int, backoff time.Duration) Opt {
+	return func(c *Client) error {
+		if maxRetries < 0 || backoff < 0 {
+			return fmt.Errorf("invalid retry %d with backoff %s", maxRetries, backoff)
+		}
+		c.retry = retryPolicy{maxRetries: maxRetries, backoff: backoff}
+		return nil
+	}
+}
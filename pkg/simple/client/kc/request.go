@@ -27,6 +27,9 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/kubeclipper/kubeclipper/pkg/query"
 
@@ -47,6 +50,19 @@ func (q *Queries) ToRawQuery() url.Values {
 	if q.Watch {
 		queryParameters.Set(query.ParameterWatch, "true")
 	}
+	if q.ResourceVersion != "" {
+		queryParameters.Set(query.ParameterResourceVersion, q.ResourceVersion)
+	}
+	if q.AllowWatchBookmarks {
+		queryParameters.Set(query.ParameterAllowWatchBookMark, "true")
+	}
+	if q.Limit > 0 {
+		queryParameters.Set(query.ParameterLimit, fmt.Sprintf("%d", q.Limit))
+		queryParameters.Set(query.ParameterChunk, "true")
+	}
+	if q.Continue != "" {
+		queryParameters.Set(query.ParameterContinue, q.Continue)
+	}
 	if q.DryRun != "" {
 		queryParameters.Set(query.ParamDryRun, q.DryRun)
 	}
@@ -59,6 +75,23 @@ func (q *Queries) ToRawQuery() url.Values {
 	if q.FieldSelector != "" {
 		queryParameters.Set(query.ParameterFieldSelector, q.FieldSelector)
 	}
+	if q.OrderBy != "" {
+		queryParameters.Set(query.OrderByParam, q.OrderBy)
+	}
+	if q.Reverse {
+		queryParameters.Set(query.ParamReverse, "true")
+	}
+	if len(q.Fields) > 0 {
+		queryParameters.Set(query.ParameterFields, strings.Join(q.Fields, ","))
+	}
+	if len(q.FuzzySearch) > 0 {
+		conditions := make([]string, 0, len(q.FuzzySearch))
+		for k, v := range q.FuzzySearch {
+			conditions = append(conditions, fmt.Sprintf("%s~%s", k, v))
+		}
+		sort.Strings(conditions)
+		queryParameters.Set(query.ParameterFuzzySearch, strings.Join(conditions, ","))
+	}
 	return queryParameters
 }
 
@@ -107,22 +140,60 @@ func (cli *Client) putRaw(ctx context.Context, path string, query url.Values, bo
 	return cli.sendRequest(ctx, "PUT", path, query, body, headers)
 }
 
+// patch sends an http request to the docker API using the method PATCH.
+func (cli *Client) patch(ctx context.Context, path string, query url.Values, obj interface{}, headers map[string][]string) (serverResponse, error) {
+	body, headers, err := encodeBody(obj, headers)
+	if err != nil {
+		return serverResponse{}, err
+	}
+	return cli.sendRequest(ctx, "PATCH", path, query, body, headers)
+}
+
+// patchRaw sends an http request to the docker API using the method PATCH.
+func (cli *Client) patchRaw(ctx context.Context, path string, query url.Values, body io.Reader, headers map[string][]string) (serverResponse, error) {
+	return cli.sendRequest(ctx, "PATCH", path, query, body, headers)
+}
+
 // delete sends an http request to the docker API using the method DELETE.
 func (cli *Client) delete(ctx context.Context, path string, query url.Values, headers map[string][]string) (serverResponse, error) {
 	return cli.sendRequest(ctx, "DELETE", path, query, nil, headers)
 }
 
 func (cli *Client) sendRequest(ctx context.Context, method, path string, query url.Values, body io.Reader, headers headers) (serverResponse, error) {
-	req, err := cli.buildRequest(method, cli.getAPIPath(ctx, path, query), body, headers)
-	if err != nil {
-		return serverResponse{}, err
+	var payload []byte
+	if body != nil && cli.retry.maxRetries > 0 {
+		// keep the body to send it again on retries
+		data, err := ioutil.ReadAll(body)
+		if err != nil {
+			return serverResponse{}, err
+		}
+		payload = data
 	}
-	resp, err := cli.doRequest(ctx, req)
-	if err != nil {
-		return resp, err
+	for attempt := 0; ; attempt++ {
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+		req, err := cli.buildRequest(method, cli.getAPIPath(ctx, path, query), body, headers)
+		if err != nil {
+			return serverResponse{}, err
+		}
+		resp, err := cli.doRequest(ctx, req)
+		if err == nil {
+			err = cli.checkResponseErr(resp)
+		}
+		wait, retry := cli.retry.next(ctx, method, attempt, resp, err)
+		if !retry {
+			return resp, err
+		}
+		ensureReaderClosed(resp)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return serverResponse{}, ctx.Err()
+		case <-timer.C:
+		}
 	}
-	err = cli.checkResponseErr(resp)
-	return resp, err
 }
 
 func (cli *Client) checkResponseErr(serverResp serverResponse) error {
@@ -161,7 +232,7 @@ func (cli *Client) checkResponseErr(serverResp serverResponse) error {
 }
 
 func (cli *Client) buildRequest(method, path string, body io.Reader, h headers) (*http.Request, error) {
-	expectedPayload := method == "POST" || method == "PUT"
+	expectedPayload := method == "POST" || method == "PUT" || method == "PATCH"
 	if expectedPayload && body == nil {
 		body = bytes.NewReader([]byte{})
 	}
@@ -0,0 +1,280 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package kc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/kubeclipper/kubeclipper/pkg/query"
+)
+
+// Object is a resource of type T served by kc-server, e.g. Object[v1.Cluster] is *v1.Cluster.
+type Object[T any] interface {
+	*T
+	runtime.Object
+}
+
+// List is a page of the resources of type T.
+type List[T any] struct {
+	Items      []T `json:"items"`
+	TotalCount int `json:"totalCount,omitempty"`
+	// ResourceVersion is the one to watch the changes after the list from.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	// Continue reads the next chunk of a list limited by ListOptions.Limit, it is empty for the last chunk.
+	Continue           string `json:"continue,omitempty"`
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
+}
+
+// ListOptions filter, sort and page the resources to list or watch.
+type ListOptions struct {
+	LabelSelector string
+	FieldSelector string
+	// FuzzySearch matches the resources whose fields contain the values, e.g. name: web.
+	FuzzySearch map[string]string
+	// OrderBy is the field path the pages are sorted by, e.g. metadata.name.
+	OrderBy string
+	Reverse bool
+	// Pagination reads a page of the list, the whole list is read without it.
+	Pagination *query.Pagination
+	// Limit and Continue read the list by chunks instead of pages.
+	Limit    int64
+	Continue string
+	// ResourceVersion is the one to watch the changes after.
+	ResourceVersion     string
+	AllowWatchBookmarks bool
+	TimeoutSeconds      *int64
+	// Fields limit the listed resources to the given field paths, e.g. metadata.name.
+	Fields []string
+	// Deleted lists the clusters or backups in the recycle bin instead of the others.
+	Deleted bool
+}
+
+func (o ListOptions) queries() Queries {
+	return Queries{
+		Pagination:          o.Pagination,
+		TimeoutSeconds:      o.TimeoutSeconds,
+		ResourceVersion:     o.ResourceVersion,
+		LabelSelector:       o.LabelSelector,
+		FieldSelector:       o.FieldSelector,
+		Continue:            o.Continue,
+		Limit:               o.Limit,
+		Reverse:             o.Reverse,
+		OrderBy:             o.OrderBy,
+		AllowWatchBookmarks: o.AllowWatchBookmarks,
+		FuzzySearch:         o.FuzzySearch,
+		Fields:              o.Fields,
+	}
+}
+
+// CreateOptions change how a resource is created.
+type CreateOptions struct {
+	// DryRun validates the resource without storing it.
+	DryRun bool
+}
+
+// UpdateOptions change how a resource is updated.
+type UpdateOptions struct {
+	// DryRun validates the update without storing it.
+	DryRun bool
+}
+
+// PatchType is the content type of a patch.
+type PatchType string
+
+const (
+	// MergePatchType patches with a JSON merge patch of RFC 7386.
+	MergePatchType PatchType = "application/merge-patch+json"
+	// JSONPatchType patches with a JSON patch of RFC 6902.
+	JSONPatchType PatchType = "application/json-patch+json"
+)
+
+// PatchOptions change how a resource is patched.
+type PatchOptions struct {
+	// DryRun validates the patch without storing it.
+	DryRun bool
+	// ResourceVersion patches the resource only if it still has this resource version.
+	ResourceVersion string
+}
+
+// DeleteOptions change how a resource is deleted.
+type DeleteOptions struct {
+	// DryRun validates the deletion without deleting the resource.
+	DryRun bool
+	// ResourceVersion deletes the resource only if it still has this resource version.
+	ResourceVersion string
+	// PropagationPolicy decides whether and when the dependents of the resource are deleted,
+	// the server default applies when it is empty.
+	PropagationPolicy metav1.DeletionPropagation
+}
+
+// ifMatchHeaders makes the write conditional on the resource version, whose quoted form is the ETag of the resource.
+func ifMatchHeaders(resourceVersion string) headers {
+	if resourceVersion == "" {
+		return nil
+	}
+	return headers{"If-Match": {strconv.Quote(resourceVersion)}}
+}
+
+func dryRunQueries(dryRun bool) Queries {
+	if dryRun {
+		return Queries{DryRun: "true"}
+	}
+	return Queries{}
+}
+
+// ResourceClient reads and writes the resources of type T served under a path of kc-server.
+// The verbs a resource does not support fail with the error of the server, e.g. NotFound.
+type ResourceClient[T any, PT Object[T]] struct {
+	cli  *Client
+	path string
+}
+
+func newResourceClient[T any, PT Object[T]](cli *Client, path string) *ResourceClient[T, PT] {
+	return &ResourceClient[T, PT]{cli: cli, path: path}
+}
+
+func (c *ResourceClient[T, PT]) resourcePath() string {
+	return c.path
+}
+
+func (c *ResourceClient[T, PT]) objectType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// Get returns the resource with the name.
+func (c *ResourceClient[T, PT]) Get(ctx context.Context, name string) (*T, error) {
+	serverResp, err := c.cli.get(ctx, fmt.Sprintf("%s/%s", c.path, name), nil, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	obj := new(T)
+	err = json.NewDecoder(serverResp.body).Decode(obj)
+	return obj, err
+}
+
+// List returns the resources matching opts.
+func (c *ResourceClient[T, PT]) List(ctx context.Context, opts ListOptions) (*List[T], error) {
+	q := opts.queries()
+	values := q.ToRawQuery()
+	if opts.Deleted {
+		values.Set(query.ParamDeleted, "true")
+	}
+	serverResp, err := c.cli.get(ctx, c.path, values, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	list := List[T]{}
+	err = json.NewDecoder(serverResp.body).Decode(&list)
+	return &list, err
+}
+
+// Create creates the resource and returns it as stored by the server.
+func (c *ResourceClient[T, PT]) Create(ctx context.Context, obj *T, opts CreateOptions) (*T, error) {
+	q := dryRunQueries(opts.DryRun)
+	serverResp, err := c.cli.post(ctx, c.path, q.ToRawQuery(), obj, nil)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(serverResp, obj)
+}
+
+// Update replaces the resource with the name of obj and returns it as stored by the server.
+// The update fails with a conflict if the resource was changed since obj was read, unless
+// the resource version of obj is empty.
+func (c *ResourceClient[T, PT]) Update(ctx context.Context, obj *T, opts UpdateOptions) (*T, error) {
+	accessor, err := meta.Accessor(PT(obj))
+	if err != nil {
+		return nil, err
+	}
+	q := dryRunQueries(opts.DryRun)
+	serverResp, err := c.cli.put(ctx, fmt.Sprintf("%s/%s", c.path, accessor.GetName()), q.ToRawQuery(), obj,
+		ifMatchHeaders(accessor.GetResourceVersion()))
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	return decodeObject(serverResp, obj)
+}
+
+// Patch applies the patch of type pt to the resource with the name and returns it as stored by the server.
+// Only the fields in the patch are changed, the patch fails with a conflict instead of overwriting a
+// concurrent change of the resource.
+func (c *ResourceClient[T, PT]) Patch(ctx context.Context, name string, pt PatchType, data []byte, opts PatchOptions) (*T, error) {
+	q := dryRunQueries(opts.DryRun)
+	h := ifMatchHeaders(opts.ResourceVersion)
+	if h == nil {
+		h = headers{}
+	}
+	h["Content-Type"] = []string{string(pt)}
+	serverResp, err := c.cli.patchRaw(ctx, fmt.Sprintf("%s/%s", c.path, name), q.ToRawQuery(), bytes.NewReader(data), h)
+	defer ensureReaderClosed(serverResp)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := decodeObject[T](serverResp, nil)
+	if err != nil || obj != nil {
+		return obj, err
+	}
+	// the server answered without a body, read the patched resource
+	return c.Get(ctx, name)
+}
+
+// Delete deletes the resource with the name.
+func (c *ResourceClient[T, PT]) Delete(ctx context.Context, name string, opts DeleteOptions) error {
+	q := dryRunQueries(opts.DryRun)
+	values := q.ToRawQuery()
+	if opts.PropagationPolicy != "" {
+		values.Set(query.ParamPropagationPolicy, string(opts.PropagationPolicy))
+	}
+	serverResp, err := c.cli.delete(ctx, fmt.Sprintf("%s/%s", c.path, name), values, ifMatchHeaders(opts.ResourceVersion))
+	defer ensureReaderClosed(serverResp)
+	return err
+}
+
+// Watch streams the changes of the resources matching opts after opts.ResourceVersion.
+func (c *ResourceClient[T, PT]) Watch(ctx context.Context, opts ListOptions) (watch.Interface, error) {
+	return c.cli.watch(ctx, c.path, opts.queries(), func() runtime.Object { return PT(new(T)) })
+}
+
+// decodeObject decodes the resource written by the server, some writes answer
+// without a body and the sent resource is returned then.
+func decodeObject[T any](serverResp serverResponse, sent *T) (*T, error) {
+	obj := new(T)
+	if err := json.NewDecoder(serverResp.body).Decode(obj); err != nil {
+		if err == io.EOF {
+			return sent, nil
+		}
+		return nil, err
+	}
+	return obj, nil
+}
@@ -0,0 +1,217 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package kc
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/emicklei/go-restful"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	corev1 "github.com/kubeclipper/kubeclipper/pkg/apis/core/v1"
+	apiiamv1 "github.com/kubeclipper/kubeclipper/pkg/apis/iam/v1"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Opt) *Client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	cli, err := NewClientWithOpts(append([]Opt{WithHost(srv.URL)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cli
+}
+
+func TestResourceClient(t *testing.T) {
+	var requests []string
+	cli := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, strings.TrimSpace(r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("If-Match")))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+			if strings.HasSuffix(r.URL.Path, "/clusters") {
+				_, _ = io.WriteString(w, `{"items":[{"metadata":{"name":"c1"}}],"totalCount":1,"resourceVersion":"7"}`)
+				return
+			}
+			_, _ = io.WriteString(w, `{"metadata":{"name":"c1","resourceVersion":"7"}}`)
+		case http.MethodPost, http.MethodPut:
+			_, _ = io.Copy(w, r.Body)
+		case http.MethodPatch:
+			if r.Header.Get("Content-Type") != string(MergePatchType) {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+			_, _ = io.WriteString(w, `{"metadata":{"name":"c2","resourceVersion":"9","labels":{"env":"prod"}}}`)
+		}
+	})
+	ctx := context.Background()
+	clusters := cli.CoreV1().Clusters()
+
+	c, err := clusters.Get(ctx, "c1")
+	if err != nil || c.Name != "c1" || c.ResourceVersion != "7" {
+		t.Fatalf("Get() = %+v, %v", c, err)
+	}
+	list, err := clusters.List(ctx, ListOptions{LabelSelector: "env=prod"})
+	if err != nil || len(list.Items) != 1 || list.Items[0].Name != "c1" || list.ResourceVersion != "7" {
+		t.Fatalf("List() = %+v, %v", list, err)
+	}
+	created, err := clusters.Create(ctx, &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c2"}}, CreateOptions{DryRun: true})
+	if err != nil || created.Name != "c2" {
+		t.Fatalf("Create() = %+v, %v", created, err)
+	}
+	if _, err = clusters.Update(ctx, &v1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c2", ResourceVersion: "8"}}, UpdateOptions{}); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	patched, err := clusters.Patch(ctx, "c2", MergePatchType, []byte(`{"metadata":{"labels":{"env":"prod"}}}`), PatchOptions{ResourceVersion: "8"})
+	if err != nil || patched.Labels["env"] != "prod" || patched.ResourceVersion != "9" {
+		t.Fatalf("Patch() = %+v, %v", patched, err)
+	}
+	if err = clusters.Delete(ctx, "c2", DeleteOptions{PropagationPolicy: metav1.DeletePropagationForeground}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err = clusters.List(ctx, ListOptions{Deleted: true}); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+
+	want := []string{
+		"GET /api/core.kubeclipper.io/v1/clusters/c1",
+		"GET /api/core.kubeclipper.io/v1/clusters?labelSelector=env%3Dprod",
+		"POST /api/core.kubeclipper.io/v1/clusters?dryRun=true",
+		`PUT /api/core.kubeclipper.io/v1/clusters/c2 "8"`,
+		`PATCH /api/core.kubeclipper.io/v1/clusters/c2 "8"`,
+		"DELETE /api/core.kubeclipper.io/v1/clusters/c2?propagationPolicy=Foreground",
+		"GET /api/core.kubeclipper.io/v1/clusters?deleted=true",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		wantSent int32
+	}{
+		{name: "busy server", method: http.MethodPost, status: http.StatusServiceUnavailable, wantSent: 3},
+		{name: "bad gateway on get", method: http.MethodGet, status: http.StatusBadGateway, wantSent: 3},
+		{name: "bad gateway on post", method: http.MethodPost, status: http.StatusBadGateway, wantSent: 1},
+		{name: "internal error", method: http.MethodGet, status: http.StatusInternalServerError, wantSent: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent int32
+			cli := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if r.Method == http.MethodPost && string(body) != "{\"name\":\"x\"}\n" {
+					t.Errorf("retried with body %q", body)
+				}
+				if atomic.AddInt32(&sent, 1) < 3 {
+					w.WriteHeader(tt.status)
+					_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": tt.status, "message": "try again"})
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}, WithRetry(2, time.Millisecond))
+			var err error
+			if tt.method == http.MethodPost {
+				var resp serverResponse
+				resp, err = cli.post(context.Background(), "/x", nil, map[string]string{"name": "x"}, nil)
+				ensureReaderClosed(resp)
+			} else {
+				var resp serverResponse
+				resp, err = cli.get(context.Background(), "/x", nil, nil)
+				ensureReaderClosed(resp)
+			}
+			if got := atomic.LoadInt32(&sent); got != tt.wantSent {
+				t.Errorf("sent %d requests, want %d", got, tt.wantSent)
+			}
+			if (err == nil) != (tt.wantSent == 3) {
+				t.Errorf("unexpected error %v", err)
+			}
+		})
+	}
+}
+
+// TestResourceClientsMatchRoutes keeps the typed clients in line with the routes of
+// kc-server, every resource served with list and get routes must have a client.
+func TestResourceClientsMatchRoutes(t *testing.T) {
+	c := restful.NewContainer()
+	if err := corev1.AddToContainer(c, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := apiiamv1.AddToContainer(c, nil, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// the types returned by the get routes of the resources, keyed by the collection path
+	served := map[string]reflect.Type{}
+	lists := map[string]bool{}
+	for _, ws := range c.RegisteredWebServices() {
+		for _, r := range ws.Routes() {
+			if r.Method != http.MethodGet {
+				continue
+			}
+			if collection := strings.TrimSuffix(r.Path, "/{name}"); collection != r.Path && !strings.Contains(collection, "{") {
+				if resp, ok := r.ResponseErrors[http.StatusOK]; ok && resp.Model != nil {
+					served[collection] = reflect.TypeOf(resp.Model)
+				}
+				continue
+			}
+			lists[r.Path] = true
+		}
+	}
+
+	cli := &Client{}
+	covered := map[string]bool{}
+	for _, group := range []interface{}{cli.CoreV1(), cli.IAMV1()} {
+		v := reflect.ValueOf(group)
+		for i := 0; i < v.NumMethod(); i++ {
+			name := v.Type().Method(i).Name
+			rc := v.Method(i).Call(nil)[0].Interface().(interface {
+				resourcePath() string
+				objectType() reflect.Type
+			})
+			want, ok := served[rc.resourcePath()]
+			if !ok || !lists[rc.resourcePath()] {
+				t.Errorf("%s: no list and get routes for %s", name, rc.resourcePath())
+				continue
+			}
+			if rc.objectType() != want {
+				t.Errorf("%s: client of %s, the server returns %s", name, rc.objectType(), want)
+			}
+			covered[rc.resourcePath()] = true
+		}
+	}
+	for path := range served {
+		if lists[path] && !covered[path] {
+			t.Errorf("no typed client for %s", path)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package kc
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxRetryBackoff caps the growing wait between the retries.
+const maxRetryBackoff = 30 * time.Second
+
+// retryPolicy decides whether a failed request is sent again, the zero value never retries.
+type retryPolicy struct {
+	maxRetries int
+	backoff    time.Duration
+}
+
+// next returns how long to wait before retrying the request which failed with err
+// after attempt retries, and false if it must not be retried.
+func (p retryPolicy) next(ctx context.Context, method string, attempt int, resp serverResponse, err error) (time.Duration, bool) {
+	if err == nil || attempt >= p.maxRetries || ctx.Err() != nil {
+		return 0, false
+	}
+	switch resp.statusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		// the server rejected the request before handling it
+	case -1, http.StatusBadGateway, http.StatusGatewayTimeout:
+		// the request may have been handled, only repeat it when it does no harm
+		if !idempotent(method) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if wait := retryAfter(resp.header); wait > 0 {
+		return wait, true
+	}
+	wait := p.backoff
+	for i := 0; i < attempt && wait < maxRetryBackoff; i++ {
+		wait *= 2
+	}
+	if wait > maxRetryBackoff {
+		wait = maxRetryBackoff
+	}
+	return wait, true
+}
+
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the wait asked by the Retry-After header in seconds, or 0 without it.
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
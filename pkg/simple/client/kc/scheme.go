@@ -20,6 +20,8 @@ package kc
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/kubeclipper/kubeclipper/pkg/scheme/common"
 
@@ -28,6 +30,7 @@ import (
 	"github.com/kubeclipper/kubeclipper/pkg/scheme"
 
 	"github.com/kubeclipper/kubeclipper/pkg/cli/printer"
+	"github.com/kubeclipper/kubeclipper/pkg/inventory"
 	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 )
 
@@ -36,11 +39,48 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+const (
+	NodesOperationAdd    = "add"
+	NodesOperationRemove = "remove"
+)
+
+type PatchNodes struct {
+	Operation string            `json:"operation"`
+	Nodes     v1.WorkerNodeList `json:"nodes"`
+	Role      common.NodeRole   `json:"role"`
+	Pool      string            `json:"pool,omitempty"`
+}
+
+type PatchComponents struct {
+	Uninstall  bool           `json:"uninstall"`
+	Components []v1.Component `json:"components"`
+}
+
+type UpgradeComponents struct {
+	Components []v1.Component `json:"components"`
+}
+
+type ClusterUpgrade struct {
+	Version         string `json:"version"`
+	Offline         bool   `json:"offline"`
+	LocalRegistry   string `json:"localRegistry"`
+	WorkerBatchSize int    `json:"workerBatchSize,omitempty"`
+}
+
+// PasswordReset changes the password of a user, the current password is not
+// needed by users allowed to update the password of others.
+type PasswordReset struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
 var _ printer.ResourcePrinter = (*NodesList)(nil)
 
 type NodesList struct {
 	Items      []v1.Node `json:"items" description:"paging data"`
 	TotalCount int       `json:"totalCount,omitempty" description:"total count"`
+	// ResourceVersion is the one to watch the changes after the list from.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
 }
 
 func (n *NodesList) JSONPrint() ([]byte, error) {
@@ -118,10 +158,13 @@ func (n *UsersList) JSONPrint() ([]byte, error) {
 }
 
 var _ printer.ResourcePrinter = (*ClustersList)(nil)
+var _ printer.WidePrinter = (*ClustersList)(nil)
 
 type ClustersList struct {
 	Items      []v1.Cluster `json:"items" description:"paging data"`
 	TotalCount int          `json:"totalCount,omitempty" description:"total count"`
+	// ResourceVersion is the one to watch the changes after the list from.
+	ResourceVersion string `json:"resourceVersion,omitempty"`
 }
 
 func (n *ClustersList) JSONPrint() ([]byte, error) {
@@ -141,6 +184,22 @@ func (n *ClustersList) TablePrint() ([]string, [][]string) {
 	return headers, data
 }
 
+func (n *ClustersList) WideTablePrint() ([]string, [][]string) {
+	headers := []string{"name", "status", "health", "version", "masters", "workers", "create_timestamp"}
+	var data [][]string
+	for _, cluster := range n.Items {
+		var version string
+		var masters, workers int
+		if cluster.Kubeadm != nil {
+			version = cluster.Kubeadm.KubernetesVersion
+			masters, workers = len(cluster.Kubeadm.Masters), len(cluster.Kubeadm.Workers)
+		}
+		data = append(data, []string{cluster.Name, string(cluster.Status.Status), cluster.Status.HealthSummary(),
+			version, strconv.Itoa(masters), strconv.Itoa(workers), cluster.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
 func (n *ClustersList) YAMLPrint() ([]byte, error) {
 	if len(n.Items) == 1 {
 		return printer.YAMLPrinter(n.Items[0])
@@ -197,6 +256,75 @@ func (n *RoleList) YAMLPrint() ([]byte, error) {
 	return printer.YAMLPrinter(n)
 }
 
+var _ printer.ResourcePrinter = (*RoleBindingList)(nil)
+
+type RoleBindingList struct {
+	Items      []iamv1.GlobalRoleBinding `json:"items" description:"paging data"`
+	TotalCount int                       `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *RoleBindingList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *RoleBindingList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "role", "users", "create_timestamp"}
+	var data [][]string
+	for _, binding := range n.Items {
+		users := make([]string, 0, len(binding.Subjects))
+		for _, subject := range binding.Subjects {
+			users = append(users, subject.Name)
+		}
+		data = append(data, []string{binding.Name,
+			binding.RoleRef.Name,
+			strings.Join(users, ","),
+			binding.CreationTimestamp.String()})
+	}
+	return headers, data
+}
+
+func (n *RoleBindingList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+var _ printer.ResourcePrinter = (*DiscoveredNodeList)(nil)
+
+type DiscoveredNodeList struct {
+	inventory.MachineList
+}
+
+func (n *DiscoveredNodeList) JSONPrint() ([]byte, error) {
+	return printer.JSONPrinter(n)
+}
+
+func (n *DiscoveredNodeList) TablePrint() ([]string, [][]string) {
+	headers := []string{"provider", "id", "name", "ips", "cpu", "memory_mb", "disk_gb", "os", "power_state", "node"}
+	var data [][]string
+	for _, m := range n.Items {
+		data = append(data, []string{m.Provider,
+			m.ID,
+			m.Name,
+			strings.Join(m.IPs, ","),
+			fmt.Sprint(m.CPU),
+			fmt.Sprint(m.MemoryMB),
+			fmt.Sprint(m.DiskGB),
+			m.OS,
+			m.PowerState,
+			m.Node})
+	}
+	return headers, data
+}
+
+func (n *DiscoveredNodeList) YAMLPrint() ([]byte, error) {
+	return printer.YAMLPrinter(n)
+}
+
 type ComponentMetas struct {
 	Node                     string `json:"-"`
 	scheme.ComponentMetaList `json:"items" description:"paging data"`
@@ -230,3 +358,77 @@ func (n *ComponentMetas) TablePrint() ([]string, [][]string) {
 type ComponentMeta struct {
 	Items []v1.MetaResource `json:"items"`
 }
+
+type RegionList struct {
+	Items      []v1.Region `json:"items" description:"paging data"`
+	TotalCount int         `json:"totalCount,omitempty" description:"total count"`
+}
+
+var _ printer.ResourcePrinter = (*PrechecksList)(nil)
+
+type PrechecksList struct {
+	Items      []v1.Precheck `json:"items" description:"paging data"`
+	TotalCount int           `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *PrechecksList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *PrechecksList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *PrechecksList) TablePrint() ([]string, [][]string) {
+	headers := []string{"name", "source", "nodes", "failed", "start_timestamp"}
+	var data [][]string
+	for _, p := range n.Items {
+		data = append(data, []string{p.Name,
+			string(p.Source),
+			strings.Join(p.Nodes, ","),
+			fmt.Sprintf("%d/%d", p.Failed, len(p.Results)),
+			p.StartedAt.String()})
+	}
+	return headers, data
+}
+
+var _ printer.ResourcePrinter = (*EventsList)(nil)
+
+type EventsList struct {
+	Items      []v1.ObjectEvent `json:"items" description:"paging data"`
+	TotalCount int              `json:"totalCount,omitempty" description:"total count"`
+}
+
+func (n *EventsList) JSONPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.JSONPrinter(n.Items[0])
+	}
+	return printer.JSONPrinter(n)
+}
+
+func (n *EventsList) YAMLPrint() ([]byte, error) {
+	if len(n.Items) == 1 {
+		return printer.YAMLPrinter(n.Items[0])
+	}
+	return printer.YAMLPrinter(n)
+}
+
+func (n *EventsList) TablePrint() ([]string, [][]string) {
+	headers := []string{"last_seen", "type", "reason", "object", "message", "count"}
+	var data [][]string
+	for _, e := range n.Items {
+		data = append(data, []string{e.LastTimestamp.String(),
+			e.Type,
+			e.Reason,
+			fmt.Sprintf("%s/%s", strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name),
+			e.Message,
+			strconv.Itoa(int(e.Count))})
+	}
+	return headers, data
+}
@@ -0,0 +1,135 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package kc
+
+import (
+	coordinationv1 "k8s.io/api/coordination/v1"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+	iamv1 "github.com/kubeclipper/kubeclipper/pkg/scheme/iam/v1"
+)
+
+const (
+	coreV1Path = "/api/core.kubeclipper.io/v1"
+	iamV1Path  = "/api/iam.kubeclipper.io/v1"
+)
+
+// CoreV1Client is the typed client of the resources of the core.kubeclipper.io/v1 API.
+type CoreV1Client struct {
+	cli *Client
+}
+
+// CoreV1 returns the client of the core.kubeclipper.io/v1 resources.
+func (cli *Client) CoreV1() *CoreV1Client {
+	return &CoreV1Client{cli: cli}
+}
+
+func (c *CoreV1Client) BackupPoints() *ResourceClient[v1.BackupPoint, *v1.BackupPoint] {
+	return newResourceClient[v1.BackupPoint](c.cli, coreV1Path+"/backuppoints")
+}
+
+func (c *CoreV1Client) Backups() *ResourceClient[v1.Backup, *v1.Backup] {
+	return newResourceClient[v1.Backup](c.cli, backupsPath)
+}
+
+func (c *CoreV1Client) Clusters() *ResourceClient[v1.Cluster, *v1.Cluster] {
+	return newResourceClient[v1.Cluster](c.cli, clustersPath)
+}
+
+func (c *CoreV1Client) ClusterTemplates() *ResourceClient[v1.ClusterTemplate, *v1.ClusterTemplate] {
+	return newResourceClient[v1.ClusterTemplate](c.cli, coreV1Path+"/clustertemplates")
+}
+
+func (c *CoreV1Client) CronMaintenances() *ResourceClient[v1.CronMaintenance, *v1.CronMaintenance] {
+	return newResourceClient[v1.CronMaintenance](c.cli, coreV1Path+"/cronmaintenances")
+}
+
+func (c *CoreV1Client) Domains() *ResourceClient[v1.Domain, *v1.Domain] {
+	return newResourceClient[v1.Domain](c.cli, coreV1Path+"/domains")
+}
+
+func (c *CoreV1Client) Events() *ResourceClient[v1.ObjectEvent, *v1.ObjectEvent] {
+	return newResourceClient[v1.ObjectEvent](c.cli, coreV1Path+"/events")
+}
+
+func (c *CoreV1Client) Leases() *ResourceClient[coordinationv1.Lease, *coordinationv1.Lease] {
+	return newResourceClient[coordinationv1.Lease](c.cli, coreV1Path+"/leases")
+}
+
+func (c *CoreV1Client) Nodes() *ResourceClient[v1.Node, *v1.Node] {
+	return newResourceClient[v1.Node](c.cli, listNodesPath)
+}
+
+func (c *CoreV1Client) Notifications() *ResourceClient[v1.Notification, *v1.Notification] {
+	return newResourceClient[v1.Notification](c.cli, coreV1Path+"/notifications")
+}
+
+func (c *CoreV1Client) Notifiers() *ResourceClient[v1.Notifier, *v1.Notifier] {
+	return newResourceClient[v1.Notifier](c.cli, coreV1Path+"/notifiers")
+}
+
+func (c *CoreV1Client) Operations() *ResourceClient[v1.Operation, *v1.Operation] {
+	return newResourceClient[v1.Operation](c.cli, operationsPath)
+}
+
+func (c *CoreV1Client) Prechecks() *ResourceClient[v1.Precheck, *v1.Precheck] {
+	return newResourceClient[v1.Precheck](c.cli, prechecksPath)
+}
+
+func (c *CoreV1Client) Regions() *ResourceClient[v1.Region, *v1.Region] {
+	return newResourceClient[v1.Region](c.cli, regionsPath)
+}
+
+func (c *CoreV1Client) Templates() *ResourceClient[v1.Template, *v1.Template] {
+	return newResourceClient[v1.Template](c.cli, coreV1Path+"/templates")
+}
+
+func (c *CoreV1Client) WebhookConfigurations() *ResourceClient[v1.WebhookConfiguration, *v1.WebhookConfiguration] {
+	return newResourceClient[v1.WebhookConfiguration](c.cli, coreV1Path+"/webhookconfigurations")
+}
+
+// IAMV1Client is the typed client of the resources of the iam.kubeclipper.io/v1 API.
+type IAMV1Client struct {
+	cli *Client
+}
+
+// IAMV1 returns the client of the iam.kubeclipper.io/v1 resources.
+func (cli *Client) IAMV1() *IAMV1Client {
+	return &IAMV1Client{cli: cli}
+}
+
+func (c *IAMV1Client) Projects() *ResourceClient[iamv1.Project, *iamv1.Project] {
+	return newResourceClient[iamv1.Project](c.cli, iamV1Path+"/projects")
+}
+
+func (c *IAMV1Client) RoleBindings() *ResourceClient[iamv1.GlobalRoleBinding, *iamv1.GlobalRoleBinding] {
+	return newResourceClient[iamv1.GlobalRoleBinding](c.cli, roleBindingsPath)
+}
+
+func (c *IAMV1Client) Roles() *ResourceClient[iamv1.GlobalRole, *iamv1.GlobalRole] {
+	return newResourceClient[iamv1.GlobalRole](c.cli, rolesPath)
+}
+
+func (c *IAMV1Client) Tokens() *ResourceClient[iamv1.Token, *iamv1.Token] {
+	return newResourceClient[iamv1.Token](c.cli, iamV1Path+"/tokens")
+}
+
+func (c *IAMV1Client) Users() *ResourceClient[iamv1.User, *iamv1.User] {
+	return newResourceClient[iamv1.User](c.cli, usersPath)
+}
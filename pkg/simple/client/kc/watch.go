@@ -0,0 +1,113 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package kc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+// WatchNodes streams the node changes after query.ResourceVersion, usually the one of a node list.
+// The watch ends when the timeout of the server expires or it is stopped.
+func (cli *Client) WatchNodes(ctx context.Context, query Queries) (watch.Interface, error) {
+	return cli.watch(ctx, listNodesPath, query, func() runtime.Object { return &v1.Node{} })
+}
+
+// WatchClusters streams the cluster changes after query.ResourceVersion.
+func (cli *Client) WatchClusters(ctx context.Context, query Queries) (watch.Interface, error) {
+	return cli.watch(ctx, clustersPath, query, func() runtime.Object { return &v1.Cluster{} })
+}
+
+// WatchOperations streams the operation changes after query.ResourceVersion.
+func (cli *Client) WatchOperations(ctx context.Context, query Queries) (watch.Interface, error) {
+	return cli.watch(ctx, operationsPath, query, func() runtime.Object { return &v1.Operation{} })
+}
+
+// WatchBackups streams the backup changes after query.ResourceVersion.
+func (cli *Client) WatchBackups(ctx context.Context, query Queries) (watch.Interface, error) {
+	return cli.watch(ctx, backupsPath, query, func() runtime.Object { return &v1.Backup{} })
+}
+
+func (cli *Client) watch(ctx context.Context, path string, query Queries, newObject func() runtime.Object) (watch.Interface, error) {
+	query.Watch = true
+	serverResp, err := cli.get(ctx, path, query.ToRawQuery(), nil)
+	if err != nil {
+		ensureReaderClosed(serverResp)
+		return nil, err
+	}
+	return watch.NewStreamWatcher(newWatchDecoder(serverResp.body, newObject), watchErrorReporter{}), nil
+}
+
+// watchDecoder decodes the json watch events of the server, which are written one per line.
+type watchDecoder struct {
+	body      io.ReadCloser
+	decoder   *json.Decoder
+	newObject func() runtime.Object
+}
+
+func newWatchDecoder(body io.ReadCloser, newObject func() runtime.Object) *watchDecoder {
+	return &watchDecoder{
+		body:      body,
+		decoder:   json.NewDecoder(body),
+		newObject: newObject,
+	}
+}
+
+func (d *watchDecoder) Decode() (watch.EventType, runtime.Object, error) {
+	var event metav1.WatchEvent
+	if err := d.decoder.Decode(&event); err != nil {
+		return "", nil, err
+	}
+	var obj runtime.Object
+	switch eventType := watch.EventType(event.Type); eventType {
+	case watch.Added, watch.Modified, watch.Deleted, watch.Bookmark:
+		obj = d.newObject()
+	case watch.Error:
+		// the server ends the watch with the status of the error, e.g. the resource version is compacted
+		obj = &metav1.Status{}
+	default:
+		return "", nil, fmt.Errorf("got invalid watch event type: %v", eventType)
+	}
+	if err := json.Unmarshal(event.Object.Raw, obj); err != nil {
+		return "", nil, fmt.Errorf("unable to decode watch event %s: %v", event.Type, err)
+	}
+	return watch.EventType(event.Type), obj, nil
+}
+
+func (d *watchDecoder) Close() {
+	_ = d.body.Close()
+}
+
+// watchErrorReporter reports the broken streams as error events.
+type watchErrorReporter struct{}
+
+func (watchErrorReporter) AsObject(err error) runtime.Object {
+	return &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Reason:  metav1.StatusReasonInternalError,
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,59 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+package kc
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestWatchDecoder(t *testing.T) {
+	stream := `{"type":"ADDED","object":{"kind":"Node","metadata":{"name":"node1","resourceVersion":"10"}}}
+{"type":"BOOKMARK","object":{"kind":"Node","metadata":{"resourceVersion":"12"}}}
+{"type":"ERROR","object":{"kind":"Status","status":"Failure","reason":"Expired","code":410}}
+`
+	w := watch.NewStreamWatcher(newWatchDecoder(io.NopCloser(strings.NewReader(stream)), func() runtime.Object {
+		return &v1.Node{}
+	}), watchErrorReporter{})
+	defer w.Stop()
+
+	event := <-w.ResultChan()
+	node, ok := event.Object.(*v1.Node)
+	if event.Type != watch.Added || !ok || node.Name != "node1" || node.ResourceVersion != "10" {
+		t.Fatalf("unexpected event %s %#v", event.Type, event.Object)
+	}
+	event = <-w.ResultChan()
+	if node, ok = event.Object.(*v1.Node); event.Type != watch.Bookmark || !ok || node.ResourceVersion != "12" {
+		t.Fatalf("unexpected event %s %#v", event.Type, event.Object)
+	}
+	event = <-w.ResultChan()
+	status, ok := event.Object.(*metav1.Status)
+	if event.Type != watch.Error || !ok || status.Code != 410 || status.Reason != metav1.StatusReasonExpired {
+		t.Fatalf("unexpected event %s %#v", event.Type, event.Object)
+	}
+	if _, ok = <-w.ResultChan(); ok {
+		t.Fatal("the watch should end with the stream")
+	}
+}
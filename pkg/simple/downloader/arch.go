@@ -0,0 +1,49 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package downloader
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// NormalizeArch maps the machine names reported by `uname -m` to the GOARCH
+// style names used by the static server layout, e.g. x86_64 to amd64.
+func NormalizeArch(arch string) string {
+	switch arch {
+	case "x86_64", "x86-64", "x64":
+		return "amd64"
+	case "aarch64", "arm64v8", "armv8":
+		return "arm64"
+	}
+	return arch
+}
+
+// ResolveArch returns the architecture of the bundle a step asks for, the
+// architecture of the running node is used when the step does not ask for one.
+func ResolveArch(arch string) (string, error) {
+	if arch == "" {
+		return runtime.GOARCH, nil
+	}
+	arch = NormalizeArch(arch)
+	if arch != runtime.GOARCH {
+		return "", fmt.Errorf("%s bundle can not be installed on %s node", arch, runtime.GOARCH)
+	}
+	return arch, nil
+}
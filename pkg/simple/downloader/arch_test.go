@@ -0,0 +1,40 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package downloader
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestResolveArch(t *testing.T) {
+	if got := NormalizeArch("aarch64"); got != "arm64" {
+		t.Errorf("NormalizeArch(aarch64) = %s, want arm64", got)
+	}
+	if got, err := ResolveArch(""); err != nil || got != runtime.GOARCH {
+		t.Errorf("ResolveArch() = %s, %v, want the node architecture", got, err)
+	}
+	other := "arm64"
+	if runtime.GOARCH == other {
+		other = "amd64"
+	}
+	if _, err := ResolveArch(other); err == nil {
+		t.Errorf("ResolveArch(%s) should refuse a bundle of another architecture", other)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
 	"path"
 	"path/filepath"
@@ -32,11 +33,14 @@ import (
 	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/net/http/httpproxy"
 
 	"github.com/kubeclipper/kubeclipper/pkg/component"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/cmdutil"
 	"github.com/kubeclipper/kubeclipper/pkg/utils/fileutil"
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sigutil"
 )
 
 const (
@@ -66,17 +70,56 @@ type Downloader struct {
 	dryRun       bool
 	// enable remote download
 	online bool
+	// keyring verifies the manifest and the resource files, it is empty when no key is trusted
+	keyring sigutil.Keyring
 	// inherits the component context
 	ctx context.Context
+	// proxy resolves the http proxy of remote downloads, nil to connect directly
+	proxy func(*url.URL) (*url.URL, error)
+}
+
+type proxyKey struct{}
+
+// WithProxy returns a copy of ctx whose remote downloads go through the given http proxy,
+// ctx is returned as is when proxy is nil.
+func WithProxy(ctx context.Context, proxy *v1.ProxyEnv) context.Context {
+	if proxy == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyKey{}, &httpproxy.Config{
+		HTTPProxy:  proxy.HTTPProxy,
+		HTTPSProxy: proxy.HTTPSProxy,
+		NoProxy:    proxy.NoProxy,
+	})
+}
+
+// proxyFromContext returns the proxy func of the http proxy set by WithProxy, nil if it is not set.
+func proxyFromContext(ctx context.Context) func(*url.URL) (*url.URL, error) {
+	if ctx == nil {
+		return nil
+	}
+	cfg, ok := ctx.Value(proxyKey{}).(*httpproxy.Config)
+	if !ok {
+		return nil
+	}
+	return cfg.ProxyFunc()
 }
 
 func NewInstance(ctx context.Context, name, version, arch string, online, dryRun bool) (*Downloader, error) {
 	if options == nil {
 		return nil, fmt.Errorf("the required downloader configuration is missing, you need to call SetOptions before calling NewInstance")
 	}
+	keyring, err := sigutil.ParseKeyring(options.TrustedKeys)
+	if err != nil {
+		return nil, err
+	}
 	var baseURI, dstDir, manifestDir, cManifestDir string
+	var proxy func(*url.URL) (*url.URL, error)
+	arch = NormalizeArch(arch)
 	if online {
 		baseURI = CloudStaticServer
+		// the static server of kc-server is always reached directly
+		proxy = proxyFromContext(ctx)
 	} else {
 		baseURI = options.Address
 	}
@@ -96,6 +139,9 @@ func NewInstance(ctx context.Context, name, version, arch string, online, dryRun
 		dstDir:       dstDir,
 		manifestDir:  manifestDir,
 		cManifestDir: cManifestDir,
+		keyring:      keyring,
+		online:       online,
+		proxy:        proxy,
 	}, nil
 }
 
@@ -204,6 +250,11 @@ func (dl *Downloader) Download(fileList ...string) (err error) {
 	for _, filename := range fileList {
 		absolutePath := filepath.Join(dl.dstDir, filename)
 		files = append(files, absolutePath)
+		// resource file may already be cached, e.g. by a prewarm operation
+		if dl.cached(mElements, absolutePath) {
+			logger.Debugf("%s already cached, skip download", absolutePath)
+			continue
+		}
 		// download resource file
 		if err = dl.DownloadFile(dl.dstDir, filename); err != nil {
 			logger.Errorf("download %s resource file failed: %s", absolutePath)
@@ -214,10 +265,24 @@ func (dl *Downloader) Download(fileList ...string) (err error) {
 		logger.Errorf("check %v digest failed: %v", files, err)
 		return
 	}
+	for _, filename := range fileList {
+		if err = dl.verifySignature(dl.dstDir, filename); err != nil {
+			logger.Errorf("verify %s signature failed: %v", filename, err)
+			return
+		}
+	}
 	logger.Debugf("download %v package successfully", files)
 	return
 }
 
+// cached reports whether the file already exists and matches the manifest digest
+func (dl *Downloader) cached(manifest []ManifestElement, file string) bool {
+	if _, err := os.Stat(file); err != nil {
+		return false
+	}
+	return dl.validateMd5Digest(manifest, []string{file}) == nil
+}
+
 // validateMd5Digest validates md5 digest of file list
 // files param: the value must be an absolute path
 func (dl *Downloader) validateMd5Digest(manifest []ManifestElement, files []string) (err error) {
@@ -255,6 +320,9 @@ func (dl *Downloader) getManifestElements(prefix string) (manifest []ManifestEle
 			logger.Debugf("download [%s] file failed: %v", filePath, err)
 			return
 		}
+		if err = dl.verifySignature(prefix, ManifestFilename); err != nil {
+			return
+		}
 	}
 	data, err := ioutil.ReadFile(filePath)
 	if err != nil {
@@ -268,6 +336,18 @@ func (dl *Downloader) getManifestElements(prefix string) (manifest []ManifestEle
 	return
 }
 
+// verifySignature downloads the signature of the file in dir and verifies the file with the trusted keys.
+func (dl *Downloader) verifySignature(dir, filename string) error {
+	if len(dl.keyring) == 0 {
+		return nil
+	}
+	sigFilename := filename + sigutil.SignatureSuffix
+	if err := dl.DownloadFile(dir, sigFilename); err != nil {
+		return fmt.Errorf("download signature of %s failed: %v", filename, err)
+	}
+	return dl.keyring.VerifyFile(filepath.Join(dir, filename), filepath.Join(dir, sigFilename))
+}
+
 // DownloadFile download the file to the specified directory
 func (dl *Downloader) DownloadFile(dstDir, filename string) (err error) {
 	prefix := fmt.Sprintf("backsource.%d-%.3f.", os.Getpid(), float64(time.Now().UnixNano())/float64(time.Second))
@@ -279,7 +359,7 @@ func (dl *Downloader) DownloadFile(dstDir, filename string) (err error) {
 	defer file.Close()
 	fullURL := fmt.Sprintf("%s/%s", dl.baseURI, filename)
 	logger.Debug("start to download file", zap.String("download from", fullURL))
-	resp, err := httpGet(fullURL, 0)
+	resp, err := httpGet(fullURL, 0, dl.proxy)
 	if err != nil {
 		return fmt.Errorf("download failed: %v", err)
 	}
@@ -320,12 +400,19 @@ func (dl *Downloader) DownloadFile(dstDir, filename string) (err error) {
 	return fileutil.MoveFile(file.Name(), dstFile)
 }
 
-func httpGet(url string, timeout time.Duration) (resp *http.Response, err error) {
+func httpGet(rawURL string, timeout time.Duration, proxy func(*url.URL) (*url.URL, error)) (resp *http.Response, err error) {
 	var (
 		cancel func()
 		client = &http.Client{}
 	)
-	req, err := http.NewRequest("GET", url, nil)
+	if proxy != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxy(req.URL)
+		}
+		client.Transport = transport
+	}
+	req, err := http.NewRequest("GET", rawURL, nil)
 	if err != nil {
 		return
 	}
@@ -0,0 +1,60 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package downloader
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	v1 "github.com/kubeclipper/kubeclipper/pkg/scheme/core/v1"
+)
+
+func TestWithProxy(t *testing.T) {
+	SetOptions(&Options{Address: "http://192.168.10.10:8081"})
+	defer SetOptions(nil)
+	ctx := WithProxy(context.Background(), &v1.ProxyEnv{
+		HTTPProxy:  "http://10.0.0.1:3128",
+		HTTPSProxy: "http://10.0.0.1:3128",
+		NoProxy:    "192.168.10.0/24",
+	})
+
+	online, err := NewInstance(ctx, "k8s", "v1.23.6", "amd64", true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if online.proxy == nil {
+		t.Fatal("online downloads should go through the proxy")
+	}
+	proxy, err := online.proxy(&url.URL{Scheme: "https", Host: "oss.kubeclipper.io"})
+	if err != nil || proxy == nil || proxy.Host != "10.0.0.1:3128" {
+		t.Errorf("proxy of the static server = %v, %v, want 10.0.0.1:3128", proxy, err)
+	}
+
+	offline, err := NewInstance(ctx, "k8s", "v1.23.6", "amd64", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offline.proxy != nil {
+		t.Error("the static server of kc-server should be reached directly")
+	}
+	if direct, _ := NewInstance(context.Background(), "k8s", "v1.23.6", "amd64", true, true); direct.proxy != nil {
+		t.Error("downloads without proxy should connect directly")
+	}
+}
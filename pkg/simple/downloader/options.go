@@ -26,6 +26,9 @@ type Options struct {
 	Address       string `json:"address" yaml:"address"`
 	TLSCertFile   string `json:"tlsCertFile" yaml:"tlsCertFile"`
 	TLSPrivateKey string `json:"tlsPrivateKey" yaml:"tlsPrivateKey"`
+	// TrustedKeys are the cosign or minisign public keys the manifests and the resources must be signed with,
+	// their signatures are downloaded next to them. Signatures are not verified when it is empty.
+	TrustedKeys []string `json:"trustedKeys,omitempty" yaml:"trustedKeys,omitempty"`
 }
 
 func NewOptions() *Options {
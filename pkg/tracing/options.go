@@ -0,0 +1,70 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/spf13/pflag"
+)
+
+// Options configures the export of traces over OTLP. Tracing is off unless an endpoint is set.
+type Options struct {
+	// Endpoint is the host:port of the OTLP gRPC collector.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty" mapstructure:"endpoint"`
+	// Insecure sends traces without TLS.
+	Insecure bool `json:"insecure,omitempty" yaml:"insecure,omitempty" mapstructure:"insecure"`
+	// SamplingRatePerMillion is the number of traces sampled out of every million started here,
+	// traces started by a caller keep the decision of the caller.
+	SamplingRatePerMillion int32 `json:"samplingRatePerMillion,omitempty" yaml:"samplingRatePerMillion,omitempty" mapstructure:"samplingRatePerMillion"`
+}
+
+func NewOptions() *Options {
+	return &Options{
+		SamplingRatePerMillion: 1000000,
+	}
+}
+
+func (o *Options) Enabled() bool {
+	return o != nil && o.Endpoint != ""
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	if o.Endpoint != "" {
+		if _, _, err := net.SplitHostPort(o.Endpoint); err != nil {
+			errs = append(errs, fmt.Errorf("tracing endpoint %q must be host:port: %v", o.Endpoint, err))
+		}
+	}
+	if o.SamplingRatePerMillion < 0 || o.SamplingRatePerMillion > 1000000 {
+		errs = append(errs, fmt.Errorf("tracing sampling rate per million %d must be in [0, 1000000]", o.SamplingRatePerMillion))
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Endpoint, "tracing-endpoint", o.Endpoint, "host:port of the OTLP gRPC collector traces are exported to, tracing is disabled if empty")
+	fs.BoolVar(&o.Insecure, "tracing-insecure", o.Insecure, "export traces without TLS")
+	fs.Int32Var(&o.SamplingRatePerMillion, "tracing-sampling-rate-per-million", o.SamplingRatePerMillion,
+		"number of traces sampled out of every million started by this component")
+}
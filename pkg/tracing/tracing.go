@@ -0,0 +1,171 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package tracing sets up OpenTelemetry tracing and carries trace context across kc-server,
+// the message queue and kc-agent, so that an operation is traced from the API request that
+// created it down to the steps run on every node.
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+
+	"k8s.io/component-base/version"
+)
+
+const instrumentationName = "github.com/kubeclipper/kubeclipper"
+
+// AnnotationTraceContext holds the trace context of the request that created an object,
+// encoded as a JSON Carrier.
+const AnnotationTraceContext = "kubeclipper.io/trace-context"
+
+func init() {
+	// propagate context even when this component exports nothing, the peers may
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Setup installs the global tracer provider exporting to the configured collector.
+// The returned func flushes and stops the exporter, it is a no-op if tracing is disabled.
+func Setup(ctx context.Context, o *Options, serviceName string) (func(context.Context) error, error) {
+	if !o.Enabled() {
+		return func(context.Context) error { return nil }, nil
+	}
+	opts := []otlpgrpc.Option{otlpgrpc.WithEndpoint(o.Endpoint)}
+	if o.Insecure {
+		opts = append(opts, otlpgrpc.WithInsecure())
+	}
+	exporter, err := otlp.NewExporter(ctx, otlpgrpc.NewDriver(opts...))
+	if err != nil {
+		return nil, err
+	}
+	res, err := resource.New(ctx, resource.WithAttributes(
+		semconv.ServiceNameKey.String(serviceName),
+		semconv.ServiceVersionKey.String(version.Get().GitVersion),
+	))
+	if err != nil {
+		return nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(float64(o.SamplingRatePerMillion)/1000000))),
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// Start starts a span of kubeclipper, a child of the span in ctx if there is one.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// WithSpan returns ctx carrying span, used where work outlives the context it is traced in.
+func WithSpan(ctx context.Context, span trace.Span) context.Context {
+	return trace.ContextWithSpan(ctx, span)
+}
+
+// Handler traces the requests served by h, continuing the trace of the caller if any.
+func Handler(h http.Handler, serverName string) http.Handler {
+	return otelhttp.NewHandler(h, serverName, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return r.Method + " " + r.URL.Path
+	}))
+}
+
+// Carrier holds a serialized trace context.
+type Carrier map[string]string
+
+func (c Carrier) Get(key string) string {
+	return c[key]
+}
+
+func (c Carrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c Carrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Inject returns the trace context of ctx, nil if ctx is not traced.
+func Inject(ctx context.Context) Carrier {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return nil
+	}
+	c := Carrier{}
+	otel.GetTextMapPropagator().Inject(ctx, c)
+	return c
+}
+
+// Extract returns ctx continuing the trace of c.
+func Extract(ctx context.Context, c Carrier) context.Context {
+	if len(c) == 0 {
+		return ctx
+	}
+	return otel.GetTextMapPropagator().Extract(ctx, c)
+}
+
+// InjectAnnotations records the trace context of ctx into annotations, which are
+// allocated if nil.
+func InjectAnnotations(ctx context.Context, annotations map[string]string) map[string]string {
+	c := Inject(ctx)
+	if c == nil {
+		return annotations
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[AnnotationTraceContext] = string(data)
+	return annotations
+}
+
+// ExtractAnnotations returns ctx continuing the trace recorded into annotations. The span
+// already in ctx is kept if there is one.
+func ExtractAnnotations(ctx context.Context, annotations map[string]string) context.Context {
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	data, ok := annotations[AnnotationTraceContext]
+	if !ok {
+		return ctx
+	}
+	c := Carrier{}
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return ctx
+	}
+	return Extract(ctx, c)
+}
@@ -0,0 +1,71 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAnnotationsRoundTrip(t *testing.T) {
+	if got := InjectAnnotations(context.TODO(), nil); got != nil {
+		t.Fatalf("untraced context injected %v", got)
+	}
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.TODO(), "create operation")
+	defer span.End()
+
+	annotations := InjectAnnotations(ctx, map[string]string{"foo": "bar"})
+	if annotations["foo"] != "bar" || annotations[AnnotationTraceContext] == "" {
+		t.Fatalf("unexpected annotations %v", annotations)
+	}
+
+	got := trace.SpanContextFromContext(ExtractAnnotations(context.TODO(), annotations))
+	want := span.SpanContext()
+	if got.TraceID() != want.TraceID() || got.SpanID() != want.SpanID() || !got.IsRemote() {
+		t.Errorf("extracted span context %v, want remote %v", got, want)
+	}
+
+	// the span of the caller wins over the recorded one
+	_, other := tp.Tracer("test").Start(context.TODO(), "deliver operation")
+	defer other.End()
+	got = trace.SpanContextFromContext(ExtractAnnotations(trace.ContextWithSpan(context.TODO(), other), annotations))
+	if got.TraceID() != other.SpanContext().TraceID() {
+		t.Errorf("span of context was replaced")
+	}
+}
+
+func TestCarrierRoundTrip(t *testing.T) {
+	tp := sdktrace.NewTracerProvider()
+	ctx, span := tp.Tracer("test").Start(context.TODO(), "deliver step")
+	defer span.End()
+
+	c := Inject(ctx)
+	if c.Get("traceparent") == "" {
+		t.Fatalf("traceparent is missing from %v", c)
+	}
+	got := trace.SpanContextFromContext(Extract(context.TODO(), c))
+	if got.TraceID() != span.SpanContext().TraceID() {
+		t.Errorf("trace id %s, want %s", got.TraceID(), span.SpanContext().TraceID())
+	}
+}
@@ -23,9 +23,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
-	"syscall"
 	"time"
 
 	"go.uber.org/zap"
@@ -35,6 +35,21 @@ import (
 )
 
 func RunCmdWithContext(ctx context.Context, dryRun bool, command string, args ...string) (*ExecCmd, error) {
+	return runCmd(ctx, dryRun, nil, command, args...)
+}
+
+// RunCmdSliceWithContext runs the command given as a slice of its name and arguments.
+func RunCmdSliceWithContext(ctx context.Context, dryRun bool, cmd []string) (*ExecCmd, error) {
+	return runCmd(ctx, dryRun, nil, cmd[0], cmd[1:]...)
+}
+
+// RunCmdWithStdout runs the command like RunCmdWithContext, its stdout is also
+// written to w while the command runs.
+func RunCmdWithStdout(ctx context.Context, dryRun bool, w io.Writer, command string, args ...string) (*ExecCmd, error) {
+	return runCmd(ctx, dryRun, w, command, args...)
+}
+
+func runCmd(ctx context.Context, dryRun bool, stdout io.Writer, command string, args ...string) (*ExecCmd, error) {
 	ec := NewExecCmd(ctx, command, args...)
 	logger.Debug("running command", zap.String("cmd", ec.String()))
 	if dryRun {
@@ -42,6 +57,10 @@ func RunCmdWithContext(ctx context.Context, dryRun bool, command string, args ..
 		return ec, err
 	}
 	// check context, get log file if conditions permit
+	var stdoutWriters []io.Writer
+	if stdout != nil {
+		stdoutWriters = append(stdoutWriters, stdout)
+	}
 	f, check, err := CheckContextAndGetStepLogFile(ctx)
 	if err != nil {
 		// detect context content and distinguish errors
@@ -63,14 +82,16 @@ func RunCmdWithContext(ctx context.Context, dryRun bool, command string, args ..
 		// ignore the error
 		defer f.Close()
 		// Set the file descriptor to the receiver of the commands stdout and stderr, to synchronize output to log file.
-		ec.SetStdoutMultiWriter(f)
+		stdoutWriters = append(stdoutWriters, f)
 		ec.SetStderrMultiWriter(f)
 		logger.Debug("set log file to the receiver of the commands stdout and stderr, start sync log", zap.String("cmd", command))
 	}
+	if len(stdoutWriters) > 0 {
+		ec.SetStdoutMultiWriter(stdoutWriters...)
+	}
 	doneCh := make(chan struct{})
 	defer close(doneCh)
-	// set Setpgid=true to create new process group.
-	ec.Cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	setProcessGroup(ec.Cmd)
 	// kill all child process after context is done.
 	go func() {
 		select {
@@ -80,10 +101,7 @@ func RunCmdWithContext(ctx context.Context, dryRun bool, command string, args ..
 				logger.Debug("the current command is not running", zap.String("cmd", ec.String()))
 				return
 			}
-			// If pid is less than -1, then sig is sent to every process in the process group whose ID is -pid.
-			// https://man7.org/linux/man-pages/man2/kill.2.html
-			err = syscall.Kill(-ec.Cmd.Process.Pid, syscall.SIGKILL)
-			if err != nil {
+			if err := killProcessGroup(ec.Cmd); err != nil {
 				logger.Error("kill child process error", zap.String("cmd", ec.String()))
 				return
 			}
@@ -23,9 +23,9 @@ import (
 
 	"github.com/emicklei/go-restful"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	compbasemetrics "k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
 )
 
 type DefaultMetrics struct{}
@@ -49,12 +49,13 @@ func init() {
 
 // Install adds the DefaultMetrics handler
 func (m DefaultMetrics) Install(c *restful.Container) {
-	RawMustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
-	RawMustRegister(collectors.NewGoCollector())
-
 	c.HandleWithFilter("/metrics", Handler())
 }
 
+// Handler serves the metrics of defaultRegistry together with the ones of the legacy
+// registry, which holds the process and go runtime metrics and the metrics libraries
+// from kubernetes like etcd storage record.
 func Handler() http.Handler {
-	return promhttp.InstrumentMetricHandler(prometheus.NewRegistry(), promhttp.HandlerFor(defaultRegistry, promhttp.HandlerOpts{}))
+	gatherer := prometheus.Gatherers{defaultRegistry, legacyregistry.DefaultGatherer}
+	return promhttp.InstrumentMetricHandler(prometheus.NewRegistry(), promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
 }
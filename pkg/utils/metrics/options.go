@@ -0,0 +1,89 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"github.com/kubeclipper/kubeclipper/pkg/logger"
+)
+
+// Options configures the dedicated listener metrics are exposed on, so they can be
+// scraped without credentials of the API. Port 0 disables the listener.
+type Options struct {
+	BindAddress string `json:"bindAddress,omitempty" yaml:"bindAddress,omitempty" mapstructure:"bindAddress"`
+	Port        int    `json:"port,omitempty" yaml:"port,omitempty" mapstructure:"port"`
+}
+
+func NewOptions(port int) *Options {
+	return &Options{
+		BindAddress: "0.0.0.0",
+		Port:        port,
+	}
+}
+
+func (o *Options) Validate() []error {
+	if o == nil {
+		return nil
+	}
+	var errs []error
+	if o.Port < 0 || o.Port > 65535 {
+		errs = append(errs, fmt.Errorf("metrics port %d is out of range", o.Port))
+	}
+	if o.BindAddress != "" && net.ParseIP(o.BindAddress) == nil {
+		errs = append(errs, fmt.Errorf("metrics bind address %q is not an ip", o.BindAddress))
+	}
+	return errs
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.BindAddress, "metrics-bind-address", o.BindAddress, "address the prometheus metrics listener binds to")
+	fs.IntVar(&o.Port, "metrics-port", o.Port, "port prometheus metrics are exposed on at /metrics, 0 disables it")
+}
+
+func (o *Options) Addr() string {
+	return net.JoinHostPort(o.BindAddress, fmt.Sprintf("%d", o.Port))
+}
+
+// Serve exposes the metrics on the configured listener until stopCh is closed.
+// It returns at once, a listener failing later is only logged.
+func Serve(o *Options, stopCh <-chan struct{}) {
+	if o == nil || o.Port == 0 {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	srv := &http.Server{Addr: o.Addr(), Handler: mux}
+	go func() {
+		<-stopCh
+		_ = srv.Shutdown(context.TODO())
+	}()
+	go func() {
+		logger.Info("metrics server start", zap.String("addr", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("metrics server exit", zap.Error(err))
+		}
+	}()
+}
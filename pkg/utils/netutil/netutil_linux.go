@@ -71,3 +71,26 @@ func GetDefaultGateway(ipv4 bool) (ip net.IP, err error) {
 	}
 	return nil, errors.New("no default gateway")
 }
+
+// GetDefaultInterface returns the name of the interface the default route goes through.
+func GetDefaultInterface(ipv4 bool) (string, error) {
+	family := netlink.FAMILY_V4
+	if !ipv4 {
+		family = netlink.FAMILY_V6
+	}
+	rl, err := netlink.RouteList(nil, family)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range rl {
+		if r.Gw == nil {
+			continue
+		}
+		link, err := netlink.LinkByIndex(r.LinkIndex)
+		if err != nil {
+			return "", err
+		}
+		return link.Attrs().Name, nil
+	}
+	return "", errors.New("no default interface")
+}
@@ -32,3 +32,7 @@ func GetDefaultIP(ipv4 bool) (net.IP, error) {
 func GetDefaultGateway(ipv4 bool) (net.IP, error) {
 	return net.IPv4zero, nil
 }
+
+func GetDefaultInterface(ipv4 bool) (string, error) {
+	return "", nil
+}
@@ -0,0 +1,216 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+// Package osutil describes the differences between the linux distributions nodes run on,
+// so that steps are rendered with the package manager, services and paths of the node.
+package osutil
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/sysutil"
+)
+
+type Family string
+
+const (
+	FamilyRHEL      Family = "rhel"
+	FamilyDebian    Family = "debian"
+	FamilyOpenEuler Family = "openeuler"
+	FamilySUSE      Family = "suse"
+	// FamilyWindows nodes run PowerShell steps, they have none of the linux tooling below.
+	FamilyWindows Family = "windows"
+)
+
+// Detect maps the platform and platform family reported by the host to an OS family,
+// an empty family is returned for distributions which are not supported.
+func Detect(platform, platformFamily string) Family {
+	// windows reports its product name, ex: Microsoft Windows Server 2022 Datacenter
+	if strings.Contains(strings.ToLower(platform), "windows") {
+		return FamilyWindows
+	}
+	switch strings.ToLower(platform) {
+	case "openeuler":
+		return FamilyOpenEuler
+	case "centos", "rocky", "almalinux", "redhat", "oracle", "fedora":
+		return FamilyRHEL
+	case "ubuntu", "debian", "linuxmint":
+		return FamilyDebian
+	case "opensuse", "opensuse-leap", "sles", "sled":
+		return FamilySUSE
+	}
+	switch strings.ToLower(platformFamily) {
+	case "rhel", "fedora":
+		return FamilyRHEL
+	case "debian":
+		return FamilyDebian
+	case "suse":
+		return FamilySUSE
+	}
+	return ""
+}
+
+// PackageManager builds the commands installing and removing system packages.
+type PackageManager struct {
+	Name string
+	// install, remove and installLocal are the command prefixes the package names are appended to
+	install      []string
+	remove       []string
+	installLocal []string
+}
+
+func (p PackageManager) Install(pkgs ...string) []string {
+	return append(append([]string{}, p.install...), pkgs...)
+}
+
+func (p PackageManager) Remove(pkgs ...string) []string {
+	return append(append([]string{}, p.remove...), pkgs...)
+}
+
+// InstallLocal installs package files shipped in the offline bundles.
+func (p PackageManager) InstallLocal(files ...string) []string {
+	return append(append([]string{}, p.installLocal...), files...)
+}
+
+// ServiceManager builds the commands managing system services,
+// every supported family boots with systemd.
+type ServiceManager struct{}
+
+func (ServiceManager) DaemonReload() []string {
+	return []string{"systemctl", "daemon-reload"}
+}
+
+// Enable enables the service, it is started at once if now is set.
+func (ServiceManager) Enable(name string, now bool) []string {
+	if now {
+		return []string{"systemctl", "enable", name, "--now"}
+	}
+	return []string{"systemctl", "enable", name}
+}
+
+func (ServiceManager) Disable(name string) []string {
+	return []string{"systemctl", "disable", name}
+}
+
+func (ServiceManager) Stop(name string) []string {
+	return []string{"systemctl", "stop", name}
+}
+
+func (ServiceManager) Restart(name string) []string {
+	return []string{"systemctl", "restart", name}
+}
+
+// Paths holds the well known files and directories that differ between families.
+type Paths struct {
+	SystemdUnitDir string
+	ChronyConf     string
+	// KubeletEnv is the environment file the kubelet unit reads KUBELET_EXTRA_ARGS from.
+	KubeletEnv string
+}
+
+type OS struct {
+	Family   Family
+	Packages PackageManager
+	Services ServiceManager
+	Paths    Paths
+	// ChronyService is the unit name of chrony daemon
+	ChronyService string
+	// Firewall is the firewall service enabled by default, it is stopped before kubernetes is installed.
+	Firewall string
+	// SELinux reports whether SELinux is enabled by default and must be set to permissive.
+	SELinux bool
+}
+
+var (
+	rpmPaths = Paths{
+		SystemdUnitDir: "/usr/lib/systemd/system",
+		ChronyConf:     "/etc/chrony.conf",
+		KubeletEnv:     "/etc/sysconfig/kubelet",
+	}
+	families = map[Family]*OS{
+		FamilyRHEL: {
+			Family: FamilyRHEL,
+			Packages: PackageManager{Name: "yum",
+				install: []string{"yum", "install", "-y"}, remove: []string{"yum", "remove", "-y"},
+				installLocal: []string{"rpm", "-Uvh", "--force", "--nodeps"}},
+			Paths:         rpmPaths,
+			ChronyService: "chronyd",
+			Firewall:      "firewalld",
+			SELinux:       true,
+		},
+		FamilyOpenEuler: {
+			Family: FamilyOpenEuler,
+			Packages: PackageManager{Name: "dnf",
+				install: []string{"dnf", "install", "-y"}, remove: []string{"dnf", "remove", "-y"},
+				installLocal: []string{"rpm", "-Uvh", "--force", "--nodeps"}},
+			Paths:         rpmPaths,
+			ChronyService: "chronyd",
+			Firewall:      "firewalld",
+			SELinux:       true,
+		},
+		FamilySUSE: {
+			Family: FamilySUSE,
+			Packages: PackageManager{Name: "zypper",
+				install: []string{"zypper", "--non-interactive", "install"}, remove: []string{"zypper", "--non-interactive", "remove"},
+				installLocal: []string{"rpm", "-Uvh", "--force", "--nodeps"}},
+			Paths:         rpmPaths,
+			ChronyService: "chronyd",
+			Firewall:      "firewalld",
+		},
+		FamilyDebian: {
+			Family: FamilyDebian,
+			Packages: PackageManager{Name: "apt",
+				install: []string{"apt-get", "install", "-y"}, remove: []string{"apt-get", "remove", "-y"},
+				installLocal: []string{"dpkg", "-i", "--force-all"}},
+			Paths: Paths{
+				SystemdUnitDir: "/lib/systemd/system",
+				ChronyConf:     "/etc/chrony/chrony.conf",
+				KubeletEnv:     "/etc/default/kubelet",
+			},
+			ChronyService: "chrony",
+			Firewall:      "ufw",
+		},
+	}
+)
+
+// Get returns the OS of the family. Nodes registered by agents which did not report
+// a family are treated as rhel, the only family supported before. Windows nodes are
+// refused by cluster operations before any step is rendered for them.
+func Get(family Family) *OS {
+	if os, ok := families[family]; ok {
+		return os
+	}
+	return families[FamilyRHEL]
+}
+
+var (
+	localOnce sync.Once
+	local     *OS
+)
+
+// Local returns the OS of the host the process runs on.
+func Local() *OS {
+	localOnce.Do(func() {
+		var family Family
+		if info, err := sysutil.HostInfo(); err == nil {
+			family = Detect(info.Platform, info.PlatformFamily)
+		}
+		local = Get(family)
+	})
+	return local
+}
@@ -0,0 +1,65 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package osutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		platform       string
+		platformFamily string
+		want           Family
+	}{
+		{platform: "centos", platformFamily: "rhel", want: FamilyRHEL},
+		{platform: "rocky", platformFamily: "rhel", want: FamilyRHEL},
+		{platform: "ubuntu", platformFamily: "debian", want: FamilyDebian},
+		{platform: "openEuler", platformFamily: "", want: FamilyOpenEuler},
+		{platform: "opensuse-leap", platformFamily: "suse", want: FamilySUSE},
+		{platform: "sles", platformFamily: "", want: FamilySUSE},
+		{platform: "kylin", platformFamily: "rhel", want: FamilyRHEL},
+		{platform: "arch", platformFamily: "arch", want: ""},
+		{platform: "Microsoft Windows Server 2022 Datacenter", platformFamily: "Server", want: FamilyWindows},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.platform, tt.platformFamily); got != tt.want {
+			t.Errorf("Detect(%q, %q) = %q, want %q", tt.platform, tt.platformFamily, got, tt.want)
+		}
+	}
+}
+
+func TestGet(t *testing.T) {
+	if got := Get(""); got.Family != FamilyRHEL {
+		t.Errorf("Get(\"\") family = %s, want %s", got.Family, FamilyRHEL)
+	}
+	debian := Get(FamilyDebian)
+	if debian.SELinux || debian.Firewall != "ufw" || debian.Paths.ChronyConf != "/etc/chrony/chrony.conf" {
+		t.Errorf("unexpected debian OS %+v", debian)
+	}
+	if got, want := debian.Packages.Install("conntrack", "socat"), []string{"apt-get", "install", "-y", "conntrack", "socat"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Install() = %v, want %v", got, want)
+	}
+	// building a command must not modify the prefix shared by later calls
+	_ = Get(FamilySUSE).Packages.Remove("a")
+	if got, want := Get(FamilySUSE).Packages.Remove("b"), []string{"zypper", "--non-interactive", "remove", "b"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Remove() = %v, want %v", got, want)
+	}
+}
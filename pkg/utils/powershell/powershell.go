@@ -0,0 +1,64 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package powershell renders and wraps the PowerShell scripts run on windows nodes,
+// both by the agent executing steps and by kcctl provisioning hosts over ssh.
+package powershell
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/kubeclipper/kubeclipper/pkg/utils/template"
+)
+
+// Executable is the Windows PowerShell shipped with every supported windows release.
+const Executable = "powershell.exe"
+
+// Quote returns s as a single quoted PowerShell string, nothing in it is expanded.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// Encode encodes the script for -EncodedCommand, so it passes through cmd.exe,
+// the default shell of windows OpenSSH, without any quoting.
+func Encode(script string) string {
+	u := utf16.Encode([]rune(script))
+	b := make([]byte, 0, len(u)*2)
+	for _, c := range u {
+		b = append(b, byte(c), byte(c>>8))
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// Command returns the command line running the script, scripts stop at the first error.
+func Command(script string) []string {
+	return []string{Executable, "-NoProfile", "-NonInteractive", "-ExecutionPolicy", "Bypass",
+		"-EncodedCommand", Encode("$ErrorActionPreference = 'Stop'\n" + script)}
+}
+
+// Render renders the script template with data, values are inserted with the
+// psquote function to keep them from being interpreted by PowerShell.
+func Render(tmpl string, data interface{}) (string, error) {
+	at := template.New()
+	if err := at.RegisterFunc("psquote", Quote); err != nil {
+		return "", err
+	}
+	return at.Render(tmpl, data)
+}
@@ -0,0 +1,41 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package powershell
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, `'C:\ProgramData'`, Quote(`C:\ProgramData`))
+	assert.Equal(t, `'it''s $env:PATH'`, Quote(`it's $env:PATH`))
+}
+
+func TestEncode(t *testing.T) {
+	// [Convert]::ToBase64String([Text.Encoding]::Unicode.GetBytes('dir'))
+	assert.Equal(t, "ZABpAHIA", Encode("dir"))
+}
+
+func TestRender(t *testing.T) {
+	script, err := Render(`Remove-Item -Path {{psquote .Dir}}`, map[string]string{"Dir": `C:\it's`})
+	assert.NoError(t, err)
+	assert.Equal(t, `Remove-Item -Path 'C:\it''s'`, script)
+}
@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package ptyutil starts commands attached to a pseudo terminal.
+package ptyutil
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// Start runs cmd as the leader of a new session whose controlling terminal is
+// a new pty of the size, and returns the master side of the pty.
+func Start(cmd *exec.Cmd, rows, cols uint16) (*os.File, error) {
+	ptm, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, err
+	}
+	pts, err := openSlave(ptm)
+	if err != nil {
+		_ = ptm.Close()
+		return nil, err
+	}
+	defer pts.Close()
+	if err = Resize(ptm, rows, cols); err != nil {
+		_ = ptm.Close()
+		return nil, err
+	}
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = pts, pts, pts
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+	if err = cmd.Start(); err != nil {
+		_ = ptm.Close()
+		return nil, err
+	}
+	return ptm, nil
+}
+
+func openSlave(ptm *os.File) (*os.File, error) {
+	fd := int(ptm.Fd())
+	if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+		return nil, fmt.Errorf("unlock pty: %v", err)
+	}
+	n, err := unix.IoctlGetUint32(fd, unix.TIOCGPTN)
+	if err != nil {
+		return nil, fmt.Errorf("get pty number: %v", err)
+	}
+	return os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+}
+
+// Resize sets the window size of the pty.
+func Resize(ptm *os.File, rows, cols uint16) error {
+	return unix.IoctlSetWinsize(int(ptm.Fd()), unix.TIOCSWINSZ, &unix.Winsize{Row: rows, Col: cols})
+}
@@ -0,0 +1,51 @@
+//go:build linux
+// +build linux
+
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ptyutil
+
+import (
+	"bytes"
+	"io"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestStart(t *testing.T) {
+	if _, err := exec.LookPath("stty"); err != nil {
+		t.Skip("stty not found")
+	}
+	cmd := exec.Command("stty", "size")
+	ptm, err := Start(cmd, 24, 100)
+	if err != nil {
+		t.Skipf("pty not supported: %v", err)
+	}
+	defer ptm.Close()
+	out := &bytes.Buffer{}
+	// reading the master returns EIO once the command exits
+	_, _ = io.Copy(out, ptm)
+	if err = cmd.Wait(); err != nil {
+		t.Fatalf("wait stty error: %v", err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "24 100" {
+		t.Errorf("Start() size = %q, want %q", got, "24 100")
+	}
+}
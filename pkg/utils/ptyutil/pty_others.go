@@ -0,0 +1,39 @@
+//go:build !linux
+// +build !linux
+
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package ptyutil
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+var errUnsupported = fmt.Errorf("pty is not supported on %s", runtime.GOOS)
+
+func Start(cmd *exec.Cmd, rows, cols uint16) (*os.File, error) {
+	return nil, errUnsupported
+}
+
+func Resize(ptm *os.File, rows, cols uint16) error {
+	return errUnsupported
+}
@@ -0,0 +1,206 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+// Package sigutil verifies detached signatures of release artifacts. Keys and signatures of
+// cosign (sign-blob with an ECDSA key) and minisign are supported.
+package sigutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// SignatureSuffix is appended to the name of an artifact to locate its signature.
+const SignatureSuffix = ".sig"
+
+const (
+	minisignUntrustedComment = "untrusted comment:"
+	minisignTrustedComment   = "trusted comment:"
+)
+
+// PublicKey verifies detached signatures.
+type PublicKey interface {
+	// Verify checks sig, the content of a signature file, against the content read from r.
+	Verify(r io.Reader, sig []byte) error
+}
+
+// ParsePublicKey parses a cosign public key in PEM, or a minisign public key given as the
+// base64 key or as the content of its .pub file.
+func ParsePublicKey(data []byte) (PublicKey, error) {
+	data = bytes.TrimSpace(data)
+	if block, _ := pem.Decode(data); block != nil {
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse public key: %v", err)
+		}
+		key, ok := pub.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("unsupported public key type %T", pub)
+		}
+		return &cosignKey{key: key}, nil
+	}
+	return parseMinisignKey(string(data))
+}
+
+// Keyring is the set of trusted keys, an artifact is trusted when any of them verifies it.
+type Keyring []PublicKey
+
+// ParseKeyring parses the trusted keys, see ParsePublicKey for their formats.
+func ParseKeyring(keys []string) (Keyring, error) {
+	var k Keyring
+	for i, v := range keys {
+		key, err := ParsePublicKey([]byte(v))
+		if err != nil {
+			return nil, fmt.Errorf("trusted key %d: %v", i, err)
+		}
+		k = append(k, key)
+	}
+	return k, nil
+}
+
+// VerifyFile verifies file against the signature stored in sigFile.
+func (k Keyring) VerifyFile(file, sigFile string) error {
+	if len(k) == 0 {
+		return errors.New("no trusted key configured")
+	}
+	sig, err := ioutil.ReadFile(sigFile)
+	if err != nil {
+		return fmt.Errorf("read signature of %s: %v", file, err)
+	}
+	var errs []string
+	for _, key := range k {
+		err = verifyFile(key, file, sig)
+		if err == nil {
+			return nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return fmt.Errorf("%s is not signed by a trusted key: %s", file, strings.Join(errs, "; "))
+}
+
+func verifyFile(key PublicKey, file string, sig []byte) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return key.Verify(f, sig)
+}
+
+// cosignKey verifies the base64 ASN.1 signatures written by cosign sign-blob.
+type cosignKey struct {
+	key *ecdsa.PublicKey
+}
+
+func (c *cosignKey) Verify(r io.Reader, sig []byte) error {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sig)))
+	if err != nil {
+		return fmt.Errorf("decode cosign signature: %v", err)
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, r); err != nil {
+		return err
+	}
+	if !ecdsa.VerifyASN1(c.key, h.Sum(nil), raw) {
+		return errors.New("invalid cosign signature")
+	}
+	return nil
+}
+
+// minisignKey verifies minisign signatures, both the legacy and the prehashed algorithms.
+type minisignKey struct {
+	id  [8]byte
+	key ed25519.PublicKey
+}
+
+func parseMinisignKey(s string) (*minisignKey, error) {
+	lines := strings.Split(s, "\n")
+	if strings.HasPrefix(lines[0], minisignUntrustedComment) {
+		lines = lines[1:]
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("empty minisign public key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return nil, fmt.Errorf("decode minisign public key: %v", err)
+	}
+	if len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, errors.New("invalid minisign public key")
+	}
+	k := &minisignKey{key: ed25519.PublicKey(raw[10:])}
+	copy(k.id[:], raw[2:10])
+	return k, nil
+}
+
+func (m *minisignKey) Verify(r io.Reader, sig []byte) error {
+	lines := strings.Split(strings.TrimSpace(string(sig)), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], minisignUntrustedComment) ||
+		!strings.HasPrefix(lines[2], minisignTrustedComment) {
+		return errors.New("invalid minisign signature")
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return errors.New("invalid minisign signature")
+	}
+	if !bytes.Equal(raw[2:10], m.id[:]) {
+		return fmt.Errorf("minisign signature of key %X", raw[2:10])
+	}
+	var message []byte
+	switch string(raw[:2]) {
+	case "Ed":
+		if message, err = ioutil.ReadAll(r); err != nil {
+			return err
+		}
+	case "ED":
+		h, _ := blake2b.New512(nil)
+		if _, err = io.Copy(h, r); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	default:
+		return fmt.Errorf("unsupported minisign algorithm %q", raw[:2])
+	}
+	signature := raw[10:]
+	if !ed25519.Verify(m.key, message, signature) {
+		return errors.New("invalid minisign signature")
+	}
+	// the trusted comment is signed together with the signature
+	global, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil {
+		return errors.New("invalid minisign trusted comment signature")
+	}
+	comment := strings.TrimSpace(strings.TrimPrefix(lines[2], minisignTrustedComment))
+	if !ed25519.Verify(m.key, append(append([]byte{}, signature...), comment...), global) {
+		return errors.New("invalid minisign trusted comment signature")
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package sigutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+func cosignPair(t *testing.T) (string, func([]byte) []byte) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&priv.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	return pub, func(data []byte) []byte {
+		digest := sha256.Sum256(data)
+		sig, err := ecdsa.SignASN1(rand.Reader, priv, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return []byte(base64.StdEncoding.EncodeToString(sig))
+	}
+}
+
+func minisignPair(t *testing.T, alg string) (string, func([]byte) []byte) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	pub := "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), id...), pk...))
+	return pub, func(data []byte) []byte {
+		message := data
+		if alg == "ED" {
+			sum := blake2b.Sum512(data)
+			message = sum[:]
+		}
+		signature := ed25519.Sign(sk, message)
+		comment := "timestamp:1700000000\tfile:kc.tar.gz"
+		global := ed25519.Sign(sk, append(append([]byte{}, signature...), comment...))
+		return []byte(fmt.Sprintf("untrusted comment: signature from minisign secret key\n%s\ntrusted comment: %s\n%s\n",
+			base64.StdEncoding.EncodeToString(append(append([]byte(alg), id...), signature...)),
+			comment, base64.StdEncoding.EncodeToString(global)))
+	}
+}
+
+func TestKeyringVerifyFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "kc.tar.gz")
+	content := []byte("kubeclipper release")
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _ := cosignPair(t)
+
+	pairs := map[string]func(*testing.T) (string, func([]byte) []byte){
+		"cosign":             cosignPair,
+		"minisign":           func(t *testing.T) (string, func([]byte) []byte) { return minisignPair(t, "Ed") },
+		"minisign prehashed": func(t *testing.T) (string, func([]byte) []byte) { return minisignPair(t, "ED") },
+	}
+	for name, pair := range pairs {
+		t.Run(name, func(t *testing.T) {
+			pub, sign := pair(t)
+			keyring, err := ParseKeyring([]string{otherPub, pub})
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigFile := file + SignatureSuffix
+			if err = ioutil.WriteFile(sigFile, sign(content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err = keyring.VerifyFile(file, sigFile); err != nil {
+				t.Errorf("VerifyFile() = %v", err)
+			}
+			if err = keyring[:1].VerifyFile(file, sigFile); err == nil {
+				t.Error("file verified by an untrusted key")
+			}
+			if err = ioutil.WriteFile(sigFile, sign([]byte("tampered")), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err = keyring.VerifyFile(file, sigFile); err == nil {
+				t.Error("tampered file verified")
+			}
+		})
+	}
+}
+
+func TestParsePublicKey(t *testing.T) {
+	for _, v := range []string{"", "not a key", "-----BEGIN PUBLIC KEY-----\nAAAA\n-----END PUBLIC KEY-----"} {
+		if _, err := ParsePublicKey([]byte(v)); err == nil {
+			t.Errorf("ParsePublicKey(%q) expects an error", v)
+		}
+	}
+}
@@ -20,6 +20,7 @@ package sshutils
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -40,6 +41,9 @@ type SSH struct {
 	PkFile            string         `json:"pkFile" yaml:"pkFile,omitempty"`
 	PkPassword        string         `json:"pkPassword" yaml:"pkPassword,omitempty"`
 	ConnectionTimeout *time.Duration `json:"connectionTimeout,omitempty" yaml:"connectionTimeout,omitempty"`
+	// Transfer is the way files are sent to hosts, one of auto, pipeline, sftp and rsync.
+	// Hosts are probed for the transfer they support when it is empty or auto.
+	Transfer string `json:"transfer,omitempty" yaml:"transfer,omitempty"`
 }
 
 func (ss *SSH) Connect(host string) (*ssh.Session, error) {
@@ -264,6 +268,43 @@ func (ss *SSH) CmdAsync(host string, cmd string) error {
 	return session.Wait()
 }
 
+// CmdStream runs cmd with sudo and copies its stdout and stderr to out until it exits
+// or ctx is done, which is how a command that never exits, like tail -f, is stopped.
+// stdout and stderr are copied concurrently, out must be safe for that.
+func (ss *SSH) CmdStream(ctx context.Context, host, cmd string, out io.Writer) error {
+	cmd, err := fillCmd(ss, cmd)
+	if err != nil {
+		return err
+	}
+	logger.V(2).Infof("[%s] %s", host, printCmd(ss.Password, cmd))
+	client, err := ss.NewClient(host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	session.Stdout, session.Stderr = out, out
+	if err = session.Start(cmd); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	select {
+	case err = <-done:
+		return err
+	case <-ctx.Done():
+		// closing the client ends the remote command with the session
+		_ = client.Close()
+		return ctx.Err()
+	}
+}
+
 func (ss *SSH) CmdOutput(host string, cmd string) ([]byte, error) {
 	logger.V(2).Infof("[%s] %s", host, cmd)
 	session, err := ss.Connect(host)
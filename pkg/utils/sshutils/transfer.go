@@ -0,0 +1,320 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package sshutils
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/kubeclipper/kubeclipper/pkg/cli/logger"
+)
+
+const (
+	// TransferAuto probes every host for the transfer it supports
+	TransferAuto = "auto"
+	// TransferPipeline copies files over sftp and verifies them with shell pipelines run over ssh exec
+	TransferPipeline = "pipeline"
+	// TransferSFTP only relies on the sftp subsystem and plain commands, for hosts restricting shell pipelines
+	TransferSFTP = "sftp"
+	// TransferRsync runs local rsync over ssh, the remote host needs rsync installed
+	TransferRsync = "rsync"
+)
+
+// Transfer sends local files to remote hosts.
+type Transfer interface {
+	Name() string
+	// Send copies the local file to the remote path unless an identical file is already there,
+	// localMD5 is computed from the local file when it is empty.
+	Send(host, localPath, remotePath, localMD5 string) error
+}
+
+// transfers caches the transfer selected for each user@host
+var transfers sync.Map
+
+// TransferFor returns the transfer used to send files to host. The transfer configured
+// in ssh config is used if set, otherwise the host is probed once and the result cached.
+func (ss *SSH) TransferFor(host string) Transfer {
+	switch ss.Transfer {
+	case TransferPipeline:
+		return &pipelineTransfer{ss: ss}
+	case TransferSFTP:
+		return &sftpTransfer{ss: ss}
+	case TransferRsync:
+		return &rsyncTransfer{ss: ss}
+	}
+	key := ss.User + "@" + host
+	if t, ok := transfers.Load(key); ok {
+		return t.(Transfer)
+	}
+	t := ss.probeTransfer(host)
+	logger.V(2).Infof("[%s]use %s transfer", host, t.Name())
+	transfers.Store(key, t)
+	return t
+}
+
+// probeTransfer prefers the pipeline transfer which has always been used, hosts refusing
+// shell pipelines fall back to rsync when both sides have it, then to plain sftp.
+func (ss *SSH) probeTransfer(host string) Transfer {
+	ret, err := SSHCmd(ss, host, "echo kc-probe | cut -c1-2")
+	if err == nil && ret.ExitCode == 0 && strings.TrimSpace(ret.Stdout) == "kc" {
+		return &pipelineTransfer{ss: ss}
+	}
+	if rsyncAvailable(ss) {
+		ret, err = SSHCmd(ss, host, "rsync --version")
+		if err == nil && ret.ExitCode == 0 {
+			return &rsyncTransfer{ss: ss}
+		}
+	}
+	return &sftpTransfer{ss: ss}
+}
+
+// rsyncAvailable reports whether rsync can authenticate from local, password login
+// needs sshpass to feed the password to ssh.
+func rsyncAvailable(ss *SSH) bool {
+	if _, err := exec.LookPath("rsync"); err != nil {
+		return false
+	}
+	if ss.PkFile == "" && ss.Password != "" {
+		if _, err := exec.LookPath("sshpass"); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+type pipelineTransfer struct {
+	ss *SSH
+}
+
+func (t *pipelineTransfer) Name() string {
+	return TransferPipeline
+}
+
+func (t *pipelineTransfer) Send(host, localPath, remotePath, localMD5 string) error {
+	exists, err := t.ss.IsFileExistV2(host, remotePath)
+	if err != nil {
+		return errors.WithMessage(err, "IsFileExistV2")
+	}
+	if exists {
+		validate, err := t.ss.ValidateMd5sumLocalWithRemote(host, localPath, remotePath)
+		if err != nil {
+			return errors.WithMessage(err, "ValidateMd5sumLocalWithRemote")
+		}
+		if validate {
+			logger.Infof("[%s]SendPackage:  %s file is exist and ValidateMd5 success", host, remotePath)
+			return nil
+		}
+		// del then copy
+		ret, err := SSHCmdWithSudo(t.ss, host, fmt.Sprintf("rm -rf %s", remotePath))
+		if err != nil {
+			return errors.WithMessagef(err, "remove old file(%s)", remotePath)
+		}
+		if err = ret.Error(); err != nil {
+			return errors.WithMessagef(err, "remove old file(%s)", remotePath)
+		}
+	}
+	ok, err := t.ss.CopyForMD5V2(host, localPath, remotePath, localMD5)
+	if err != nil {
+		return errors.WithMessagef(err, "copy file(%s)", localPath)
+	}
+	if !ok {
+		return fmt.Errorf("[%s]copy file(%s) md5 validate failed", host, localPath)
+	}
+	logger.Infof("[%s]copy file(%s) md5 validate success", host, localPath)
+	return nil
+}
+
+// sftpTransfer avoids shell pipelines, files are written over sftp and only single
+// commands without redirection run over ssh exec to move them in place with sudo.
+type sftpTransfer struct {
+	ss *SSH
+}
+
+func (t *sftpTransfer) Name() string {
+	return TransferSFTP
+}
+
+func (t *sftpTransfer) Send(host, localPath, remotePath, localMD5 string) error {
+	var err error
+	if localMD5 == "" {
+		if localMD5, err = fileMD5(localPath); err != nil {
+			return err
+		}
+	}
+	if remoteMD5, err := t.remoteMD5(host, remotePath); err == nil && remoteMD5 == localMD5 {
+		logger.Infof("[%s]SendPackage:  %s file is exist and ValidateMd5 success", host, remotePath)
+		return nil
+	}
+	target := remotePath
+	if t.ss.User != "root" {
+		// write to a place the user owns first, then sudo mv to target
+		target = filepath.Join("/tmp", remotePath)
+	}
+	if err = t.upload(host, localPath, target); err != nil {
+		return errors.WithMessagef(err, "upload file(%s)", localPath)
+	}
+	if target != remotePath {
+		for _, cmd := range []string{
+			fmt.Sprintf("mkdir -p %s", filepath.Dir(remotePath)),
+			fmt.Sprintf("mv -f %s %s", target, remotePath),
+		} {
+			ret, err := SSHCmdWithSudo(t.ss, host, cmd)
+			if err != nil {
+				return err
+			}
+			if err = ret.Error(); err != nil {
+				return err
+			}
+		}
+	}
+	remoteMD5, err := t.remoteMD5(host, remotePath)
+	if err != nil {
+		return err
+	}
+	if remoteMD5 != localMD5 {
+		return fmt.Errorf("[%s]copy file(%s) md5 validate failed localMd5:%s remoteMd5:%s", host, localPath, localMD5, remoteMD5)
+	}
+	logger.Infof("[%s]copy file(%s) md5 validate success", host, localPath)
+	return nil
+}
+
+// Upload writes the local file to the remote path over sftp as the ssh user, no remote
+// command is run, so it works for hosts without a posix shell like windows.
+func (ss *SSH) Upload(host, localPath, remotePath string) error {
+	return (&sftpTransfer{ss: ss}).upload(host, localPath, remotePath)
+}
+
+func (t *sftpTransfer) upload(host, localPath, remotePath string) error {
+	client, err := t.ss.sftpConnect(host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err = client.MkdirAll(filepath.Dir(remotePath)); err != nil {
+		return err
+	}
+	src, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := client.Create(remotePath)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+// remoteMD5 runs md5sum alone and parses its output locally instead of piping it to cut.
+func (t *sftpTransfer) remoteMD5(host, remotePath string) (string, error) {
+	ret, err := SSHCmdWithSudo(t.ss, host, fmt.Sprintf("md5sum %s", remotePath))
+	if err != nil {
+		return "", err
+	}
+	if err = ret.Error(); err != nil {
+		return "", err
+	}
+	return parseMD5Sum(ret.Stdout)
+}
+
+func parseMD5Sum(out string) (string, error) {
+	fields := strings.Fields(out)
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected md5sum output %q", out)
+	}
+	return fields[0], nil
+}
+
+// rsyncTransfer runs local rsync over ssh, unchanged files are skipped by checksum
+// and interrupted transfers of large packages are resumed. Users other than root
+// need passwordless sudo for rsync on the remote host.
+type rsyncTransfer struct {
+	ss *SSH
+}
+
+func (t *rsyncTransfer) Name() string {
+	return TransferRsync
+}
+
+func (t *rsyncTransfer) Send(host, localPath, remotePath, localMD5 string) error {
+	ret, err := SSHCmdWithSudo(t.ss, host, fmt.Sprintf("mkdir -p %s", filepath.Dir(remotePath)))
+	if err != nil {
+		return err
+	}
+	if err = ret.Error(); err != nil {
+		return err
+	}
+	name, args := t.command(host, localPath, remotePath)
+	cmd := exec.Command(name, args...)
+	if name == "sshpass" {
+		// pass the password by environment so that it never shows in process list or logs
+		cmd.Env = append(os.Environ(), "SSHPASS="+t.ss.Password)
+	}
+	logger.V(2).Infof("[%s]exec cmd is %s", host, cmd.String())
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.WithMessagef(err, "rsync file(%s): %s", localPath, strings.TrimSpace(string(out)))
+	}
+	logger.Infof("[%s]rsync file(%s) success", host, localPath)
+	return nil
+}
+
+func (t *rsyncTransfer) command(host, localPath, remotePath string) (string, []string) {
+	addr, port, err := net.SplitHostPort(host)
+	if err != nil {
+		addr, port = host, "22"
+	}
+	rsh := fmt.Sprintf("ssh -p %s -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null", port)
+	if t.ss.PkFile != "" {
+		rsh += " -i " + t.ss.PkFile
+	}
+	args := []string{"-a", "--checksum", "--partial", "-e", rsh}
+	if t.ss.User != "root" {
+		args = append(args, "--rsync-path", "sudo rsync")
+	}
+	args = append(args, localPath, fmt.Sprintf("%s@%s:%s", t.ss.User, addr, remotePath))
+	if t.ss.PkFile == "" && t.ss.Password != "" {
+		return "sshpass", append([]string{"-e", "rsync"}, args...)
+	}
+	return "rsync", args
+}
+
+func fileMD5(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := md5.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,80 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package sshutils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTransferFor(t *testing.T) {
+	for _, name := range []string{TransferPipeline, TransferSFTP, TransferRsync} {
+		ss := &SSH{User: "root", Transfer: name}
+		if got := ss.TransferFor("10.0.0.1").Name(); got != name {
+			t.Errorf("TransferFor() with %s configured = %s", name, got)
+		}
+	}
+}
+
+func TestParseMD5Sum(t *testing.T) {
+	got, err := parseMD5Sum("d41d8cd98f00b204e9800998ecf8427e  /tmp/kc/kc.tar.gz\n")
+	if err != nil || got != "d41d8cd98f00b204e9800998ecf8427e" {
+		t.Errorf("parseMD5Sum() = %s, %v", got, err)
+	}
+	if _, err = parseMD5Sum(""); err == nil {
+		t.Errorf("parseMD5Sum() of empty output must fail")
+	}
+}
+
+func TestRsyncCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		ss       *SSH
+		host     string
+		wantName string
+		wantArgs []string
+	}{
+		{
+			name:     "root with private key",
+			ss:       &SSH{User: "root", PkFile: "/root/.ssh/id_rsa"},
+			host:     "10.0.0.1",
+			wantName: "rsync",
+			wantArgs: []string{"-a", "--checksum", "--partial", "-e",
+				"ssh -p 22 -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i /root/.ssh/id_rsa",
+				"/tmp/kc.tar.gz", "root@10.0.0.1:/root/kc.tar.gz"},
+		},
+		{
+			name:     "sudo user with password and port",
+			ss:       &SSH{User: "kc", Password: "secret"},
+			host:     "10.0.0.1:2222",
+			wantName: "sshpass",
+			wantArgs: []string{"-e", "rsync", "-a", "--checksum", "--partial", "-e",
+				"ssh -p 2222 -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null",
+				"--rsync-path", "sudo rsync", "/tmp/kc.tar.gz", "kc@10.0.0.1:/root/kc.tar.gz"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args := (&rsyncTransfer{ss: tt.ss}).command(tt.host, "/tmp/kc.tar.gz", "/root/kc.tar.gz")
+			if name != tt.wantName || !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("command() = %s %v, want %s %v", name, args, tt.wantName, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package sysutil
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	GPUVendorNVIDIA = "nvidia"
+	GPUVendorAMD    = "amd"
+)
+
+// gpuVendors maps pci vendor ids to the GPU vendors reported. Display controllers of
+// other vendors, e.g. the VGA of the baseboard management controller, are left out.
+var gpuVendors = map[string]string{
+	"0x10de": GPUVendorNVIDIA,
+	"0x1002": GPUVendorAMD,
+}
+
+var nvidiaDriverVersionRegex = regexp.MustCompile(`Kernel Module\s+([0-9.]+)`)
+
+type GPU struct {
+	Vendor string `json:"vendor"`
+	// Model is the product name reported by the driver, or the pci vendor:device id without driver.
+	Model         string `json:"model"`
+	DriverVersion string `json:"driverVersion"`
+	// BusID is the pci address of the device, ex: 0000:3b:00.0
+	BusID string `json:"busID"`
+}
+
+func GPUInfo() ([]GPU, error) {
+	gpus, err := pciGPUs("/sys/bus/pci/devices")
+	if err != nil || len(gpus) == 0 {
+		return nil, err
+	}
+	driverVersion := nvidiaDriverVersion("/proc/driver/nvidia/version")
+	models := nvidiaModels()
+	for i := range gpus {
+		if gpus[i].Vendor != GPUVendorNVIDIA {
+			continue
+		}
+		gpus[i].DriverVersion = driverVersion
+		if model, ok := models[shortBusID(gpus[i].BusID)]; ok {
+			gpus[i].Model = model
+		}
+	}
+	return gpus, nil
+}
+
+// pciGPUs lists the display controllers found in sysfs.
+func pciGPUs(root string) ([]GPU, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var gpus []GPU
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		// class 0x03xxxx is display controller
+		if !strings.HasPrefix(readTrimmed(filepath.Join(dir, "class")), "0x03") {
+			continue
+		}
+		vendorID := readTrimmed(filepath.Join(dir, "vendor"))
+		vendor, ok := gpuVendors[vendorID]
+		if !ok {
+			continue
+		}
+		gpus = append(gpus, GPU{
+			Vendor: vendor,
+			Model:  strings.TrimPrefix(vendorID, "0x") + ":" + strings.TrimPrefix(readTrimmed(filepath.Join(dir, "device")), "0x"),
+			BusID:  entry.Name(),
+		})
+	}
+	return gpus, nil
+}
+
+func nvidiaDriverVersion(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	if m := nvidiaDriverVersionRegex.FindSubmatch(data); m != nil {
+		return string(m[1])
+	}
+	return ""
+}
+
+// nvidiaModels returns the product names keyed by short bus id, empty if nvidia-smi is unavailable.
+func nvidiaModels() map[string]string {
+	models := make(map[string]string)
+	if _, err := exec.LookPath("nvidia-smi"); err != nil {
+		return models
+	}
+	out, err := exec.Command("nvidia-smi", "--query-gpu=pci.bus_id,name", "--format=csv,noheader").Output()
+	if err != nil {
+		return models
+	}
+	return parseNvidiaSMI(out)
+}
+
+func parseNvidiaSMI(out []byte) map[string]string {
+	models := make(map[string]string)
+	for _, line := range bytes.Split(out, []byte("\n")) {
+		parts := strings.SplitN(string(line), ",", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		models[shortBusID(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return models
+}
+
+// shortBusID drops the pci domain, which is printed with different widths by sysfs and nvidia-smi.
+func shortBusID(id string) string {
+	id = strings.ToLower(strings.TrimSpace(id))
+	if i := strings.Index(id, ":"); i >= 0 && strings.Count(id, ":") == 2 {
+		return id[i+1:]
+	}
+	return id
+}
+
+func readTrimmed(file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
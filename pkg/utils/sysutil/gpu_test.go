@@ -0,0 +1,73 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package sysutil
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPciGPUs(t *testing.T) {
+	root := t.TempDir()
+	devices := map[string][3]string{
+		"0000:3b:00.0": {"0x030200", "0x10de", "0x20b5"}, // nvidia 3D controller
+		"0000:03:00.0": {"0x030000", "0x102b", "0x0536"}, // BMC VGA
+		"0000:00:1f.0": {"0x060100", "0x8086", "0xa1c1"}, // ISA bridge
+	}
+	for dev, attrs := range devices {
+		dir := filepath.Join(root, dev)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		for i, name := range []string{"class", "vendor", "device"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(attrs[i]+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	gpus, err := pciGPUs(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []GPU{{Vendor: GPUVendorNVIDIA, Model: "10de:20b5", BusID: "0000:3b:00.0"}}
+	if !reflect.DeepEqual(gpus, want) {
+		t.Errorf("pciGPUs() = %+v, want %+v", gpus, want)
+	}
+
+	if gpus, err = pciGPUs(filepath.Join(root, "missing")); err != nil || gpus != nil {
+		t.Errorf("pciGPUs() on missing root = %v, %v", gpus, err)
+	}
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	out := []byte("00000000:3B:00.0, NVIDIA A100-PCIE-40GB\n00000000:AF:00.0, Tesla T4\n\n")
+	got := parseNvidiaSMI(out)
+	want := map[string]string{
+		"3b:00.0": "NVIDIA A100-PCIE-40GB",
+		"af:00.0": "Tesla T4",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseNvidiaSMI() = %v, want %v", got, want)
+	}
+	if id := shortBusID("0000:3b:00.0"); id != "3b:00.0" {
+		t.Errorf("shortBusID() = %s, want 3b:00.0", id)
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ *
+ *  * Copyright 2021 KubeClipper Authors.
+ *  *
+ *  * Licensed under the Apache License, Version 2.0 (the "License");
+ *  * you may not use this file except in compliance with the License.
+ *  * You may obtain a copy of the License at
+ *  *
+ *  *     http://www.apache.org/licenses/LICENSE-2.0
+ *  *
+ *  * Unless required by applicable law or agreed to in writing, software
+ *  * distributed under the License is distributed on an "AS IS" BASIS,
+ *  * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  * See the License for the specific language governing permissions and
+ *  * limitations under the License.
+ *
+ */
+
+package sysutil
+
+import (
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/load"
+	"github.com/shirou/gopsutil/v3/mem"
+)
+
+// Usage is a sample of the resource usage of the host, sizes are in bytes.
+type Usage struct {
+	CPUPercent    float64 `json:"cpuPercent"`
+	MemoryUsed    uint64  `json:"memoryUsed"`
+	MemoryPercent float64 `json:"memoryPercent"`
+	DiskUsed      uint64  `json:"diskUsed"`
+	DiskPercent   float64 `json:"diskPercent"`
+	Load1         float64 `json:"load1"`
+	Load5         float64 `json:"load5"`
+	Load15        float64 `json:"load15"`
+}
+
+// UsageInfo samples the host, the cpu usage is averaged since the previous call.
+func UsageInfo() (Usage, error) {
+	u := Usage{}
+	percents, err := cpu.Percent(0, false)
+	if err != nil {
+		return u, err
+	}
+	if len(percents) > 0 {
+		u.CPUPercent = percents[0]
+	}
+	vm, err := mem.VirtualMemory()
+	if err != nil {
+		return u, err
+	}
+	u.MemoryUsed, u.MemoryPercent = vm.Used, vm.UsedPercent
+
+	parts, err := disk.Partitions(false)
+	if err != nil {
+		return u, err
+	}
+	var total uint64
+	devices := make(map[string]struct{}, len(parts))
+	for _, part := range parts {
+		// bind mounts report the same device more than once
+		if _, ok := devices[part.Device]; ok {
+			continue
+		}
+		devices[part.Device] = struct{}{}
+		usage, err := disk.Usage(part.Mountpoint)
+		if err != nil {
+			return u, err
+		}
+		total += usage.Total
+		u.DiskUsed += usage.Used
+	}
+	if total > 0 {
+		u.DiskPercent = float64(u.DiskUsed) / float64(total) * 100
+	}
+
+	avg, err := load.Avg()
+	if err != nil {
+		return u, err
+	}
+	u.Load1, u.Load5, u.Load15 = avg.Load1, avg.Load5, avg.Load15
+	return u, nil
+}
@@ -49,7 +49,7 @@ var roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"iam.kubeclipper.io"},
-				Resources: []string{"users", "users/loginrecords"},
+				Resources: []string{"users", "users/loginrecords", "users/preferences"},
 				Verbs:     []string{"get", "list", "watch"},
 			},
 		},
@@ -75,7 +75,7 @@ var roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"iam.kubeclipper.io"},
-				Resources: []string{"roles"},
+				Resources: []string{"roles", "rolebindings"},
 				Verbs:     []string{"get", "list", "watch"},
 			},
 		},
@@ -205,7 +205,7 @@ var roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"iam.kubeclipper.io"},
-				Resources: []string{"users", "users/password", "users/enable", "users/disable"},
+				Resources: []string{"users", "users/password", "users/enable", "users/disable", "users/preferences"},
 				Verbs:     []string{"update", "patch"},
 			},
 		},
@@ -256,7 +256,7 @@ var roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"clusters", "nodes", "regions", "operations", "logs", "clusters/upgrade", "nodes/terminal"},
+				Resources: []string{"clusters", "nodes", "regions", "operations", "logs", "operations/logs", "operations/steps", "clusters/upgrade", "clusters/lock", "nodes/terminal", "discoverednodes", "clustertemplates", "clusters/nodepools", "clusters/registries", "clusters/export", "nodes/metrics", "regions/clusterdefaults", "cronmaintenances", "backuppoints/usage", "regions/quota"},
 				Verbs:     []string{"get", "list", "watch"},
 			},
 			{
@@ -287,7 +287,7 @@ var roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"clusters", "nodes", "regions", "operations/retry", "clusters/backups", "clusters/upgrade"},
+				Resources: []string{"clusters", "nodes", "regions", "operations/retry", "operations/pause", "operations/resume", "operations/cancel", "clusters/backups", "clusters/upgrade", "clusters/resubmit", "clusters/credentials", "clusters/certificates", "clusters/diff", "clustertemplates", "clustertemplates/clusters", "regions/prewarm"},
 				Verbs:     []string{"create"},
 			},
 			{
@@ -300,6 +300,11 @@ var roles = []iamv1.GlobalRole{
 				Resources: []string{"templates"},
 				Verbs:     []string{"get", "list", "watch", "create"},
 			},
+			{
+				APIGroups: []string{"core.kubeclipper.io"},
+				Resources: []string{"prechecks", "nodeprechecks"},
+				Verbs:     []string{"get", "list", "watch", "create"},
+			},
 		},
 	},
 	{
@@ -323,17 +328,22 @@ var roles = []iamv1.GlobalRole{
 		Rules: []rbacv1.PolicyRule{
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"clusters", "clusters/plugins", "clusters/nodes", "clusters/status", "nodes/disable", "nodes/enable"},
+				Resources: []string{"clusters", "clusters/plugins", "clusters/nodes", "clusters/status", "clusters/certsans", "clusters/nodepools", "clusters/registries", "nodes/disable", "nodes/enable"},
 				Verbs:     []string{"update", "patch"},
 			},
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"clusters/plugins", "clusters/nodes"},
+				Resources: []string{"clusters/plugins", "clusters/nodes", "clusters/proxy", "cronmaintenances"},
 				Verbs:     []string{"*"},
 			},
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"clusters/terminal"},
+				Resources: []string{"clusters/diff"},
+				Verbs:     []string{"create"},
+			},
+			{
+				APIGroups: []string{"core.kubeclipper.io"},
+				Resources: []string{"clusters/terminal", "clusters/kubeconfig", "clusters/adminkubeconfig", "nodes/shell", "nodes/files"},
 				Verbs:     []string{"get"},
 			},
 		},
@@ -367,6 +377,11 @@ var roles = []iamv1.GlobalRole{
 				Resources: []string{"clusters/plugins", "clusters/nodes"},
 				Verbs:     []string{"*"},
 			},
+			{
+				APIGroups: []string{"core.kubeclipper.io"},
+				Resources: []string{"clusters/undelete"},
+				Verbs:     []string{"create"},
+			},
 		},
 	},
 	{
@@ -394,7 +409,7 @@ var roles = []iamv1.GlobalRole{
 			},
 			{
 				APIGroups: []string{"core.kubeclipper.io"},
-				Resources: []string{"templates"},
+				Resources: []string{"templates", "notifiers", "notifications", "webhookconfigurations"},
 				Verbs:     []string{"get", "list", "watch"},
 			},
 		},
@@ -433,6 +448,21 @@ var roles = []iamv1.GlobalRole{
 				Resources: []string{"templates"},
 				Verbs:     []string{"update", "patch", "create", "delete"},
 			},
+			{
+				APIGroups: []string{"iam.kubeclipper.io"},
+				Resources: []string{"projects", "projects/members"},
+				Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+			},
+			{
+				APIGroups: []string{"core.kubeclipper.io"},
+				Resources: []string{"nodes/project"},
+				Verbs:     []string{"update", "patch"},
+			},
+			{
+				APIGroups: []string{"core.kubeclipper.io"},
+				Resources: []string{"webhookconfigurations"},
+				Verbs:     []string{"create", "update", "patch", "delete"},
+			},
 		},
 	},
 	{
@@ -35,22 +35,25 @@ import (
 	corev1 "github.com/kubeclipper/kubeclipper/pkg/apis/core/v1"
 
 	"github.com/emicklei/go-restful"
-	restfulspec "github.com/emicklei/go-restful-openapi"
 	"github.com/go-openapi/loads"
 	"github.com/go-openapi/spec"
 	"github.com/go-openapi/strfmt"
 	"github.com/go-openapi/validate"
 	urlruntime "k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/component-base/version"
 
 	configv1 "github.com/kubeclipper/kubeclipper/pkg/apis/config/v1"
 	"github.com/kubeclipper/kubeclipper/pkg/logger"
+	"github.com/kubeclipper/kubeclipper/pkg/server/openapi"
 )
 
-var output string
+var (
+	output   string
+	outputV3 string
+)
 
 func init() {
 	flag.StringVar(&output, "output", "./api/openapi-spec/swagger.json", "--output=./api.json")
+	flag.StringVar(&outputV3, "output-v3", "./api/openapi-spec/openapi.json", "--output-v3=./openapi.json")
 }
 
 func main() {
@@ -98,17 +101,13 @@ func validateSpec(apiSpec []byte) error {
 func generateSwaggerJSON() []byte {
 
 	container := restful.NewContainer()
-	urlruntime.Must(corev1.AddToContainer(container, nil, nil, nil, nil, nil))
-	urlruntime.Must(iamv1.AddToContainer(container, nil, nil, nil))
+	urlruntime.Must(corev1.AddToContainer(container, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, ""))
+	urlruntime.Must(iamv1.AddToContainer(container, nil, nil, nil, nil))
 	urlruntime.Must(configv1.AddToContainer(container, nil, nil))
 	urlruntime.Must(oauth.AddToContainer(container, nil, nil, nil, nil, nil))
 	urlruntime.Must(auditingv1.AddToContainer(container, nil))
 
-	config := restfulspec.Config{
-		WebServices:                   container.RegisteredWebServices(),
-		PostBuildSwaggerObjectHandler: enrichSwaggerObject}
-
-	swagger := restfulspec.BuildSwagger(config)
+	swagger := openapi.BuildSwagger(container.RegisteredWebServices())
 	swagger.Info.Extensions = make(spec.Extensions)
 	swagger.Info.Extensions.Add("x-tagGroups", []struct {
 		Name string   `json:"name"`
@@ -129,37 +128,17 @@ func generateSwaggerJSON() []byte {
 	}
 	log.Printf("successfully written to %s", output)
 
-	return data
-}
-
-func enrichSwaggerObject(swo *spec.Swagger) {
-	swo.Info = &spec.Info{
-		InfoProps: spec.InfoProps{
-			Title:       "KubeClipper",
-			Description: "KubeClipper OpenAPI",
-			Version:     version.Get().GitVersion,
-			Contact: &spec.ContactInfo{
-				// TODO: add url and email
-				ContactInfoProps: spec.ContactInfoProps{
-					Name:  "KubeClipper",
-					URL:   "github.com/kubeclipper-labs/kubeclipper",
-					Email: "",
-				},
-			},
-			License: &spec.License{
-				LicenseProps: spec.LicenseProps{
-					Name: "Apache 2.0",
-					URL:  "https://www.apache.org/licenses/LICENSE-2.0.html",
-				},
-			},
-		},
+	doc, err := openapi.ConvertToV3(swagger)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	// setup security definitions
-	swo.SecurityDefinitions = map[string]*spec.SecurityScheme{
-		"jwt": spec.APIKeyAuth("Authorization", "header"),
+	v3Data, _ := json.MarshalIndent(doc, "", "  ")
+	if err = ioutil.WriteFile(outputV3, v3Data, 0644); err != nil {
+		log.Fatal(err)
 	}
-	swo.Security = []map[string][]string{{"jwt": []string{}}}
+	log.Printf("successfully written to %s", outputV3)
+
+	return data
 }
 
 // func apiTree(container *restful.Container) {